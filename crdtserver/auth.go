@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+)
+
+// Permission 네임스페이스에 대한 권한 수준
+// 높은 권한은 낮은 권한을 포함한다.
+type Permission int
+
+const (
+	// PermissionNone 권한 없음
+	PermissionNone Permission = iota
+	// PermissionRead 키 조회
+	PermissionRead
+	// PermissionWrite 키 저장과 삭제
+	PermissionWrite
+	// PermissionAdmin 서버 상태 조회 등 관리 작업
+	PermissionAdmin
+)
+
+// ParsePermission 문자열을 권한으로 변환
+func ParsePermission(s string) (Permission, error) {
+	switch strings.ToLower(s) {
+	case "read":
+		return PermissionRead, nil
+	case "write":
+		return PermissionWrite, nil
+	case "admin":
+		return PermissionAdmin, nil
+	default:
+		return PermissionNone, fmt.Errorf("unknown permission: %q", s)
+	}
+}
+
+// String 권한의 문자열 표현
+func (p Permission) String() string {
+	switch p {
+	case PermissionRead:
+		return "read"
+	case PermissionWrite:
+		return "write"
+	case PermissionAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// Principal 인증된 요청 주체
+type Principal struct {
+	// Name API 키 이름 또는 JWT subject
+	Name string
+	// Permissions 네임스페이스(키 접두사)별 권한
+	Permissions map[string]Permission
+}
+
+// Can 키에 대해 주어진 권한이 있는지 확인
+// 키를 포함하는 네임스페이스의 권한 중 가장 높은 권한을 사용한다.
+func (p *Principal) Can(key string, perm Permission) bool {
+	for namespace, granted := range p.Permissions {
+		if granted >= perm && inNamespace(key, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// inNamespace 키가 네임스페이스에 속하는지 확인
+// 키와 네임스페이스는 datastore 키 형식으로 정규화해 비교하므로 앞의 "/"가 없어도 된다.
+// 네임스페이스 "/a"는 "/a/b"를 포함하지만 "/ab"는 포함하지 않는다.
+func inNamespace(key, namespace string) bool {
+	namespace = ds.NewKey(namespace).String()
+	key = ds.NewKey(key).String()
+	return namespace == "/" || key == namespace || strings.HasPrefix(key, namespace+"/")
+}
+
+// AuthConfig 인증 설정 파일 형식
+type AuthConfig struct {
+	// APIKeys 허용된 API 키
+	APIKeys []APIKeyConfig `json:"apiKeys"`
+	// JWTSecret HS256 JWT 서명 검증 키 (비어 있으면 JWT 비활성화)
+	JWTSecret string `json:"jwtSecret"`
+	// JWTIssuer 허용된 JWT 발급자 (비어 있으면 검사하지 않음)
+	JWTIssuer string `json:"jwtIssuer"`
+}
+
+// APIKeyConfig API 키 설정
+type APIKeyConfig struct {
+	Key         string            `json:"key"`
+	Name        string            `json:"name"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// jwtClaims 서버가 사용하는 JWT 클레임
+type jwtClaims struct {
+	Subject     string            `json:"sub"`
+	Issuer      string            `json:"iss"`
+	ExpiresAt   int64             `json:"exp"`
+	NotBefore   int64             `json:"nbf"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// LoadAuthConfig 인증 설정 파일 로드
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+	var config AuthConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config: %w", err)
+	}
+	return &config, nil
+}
+
+// Authenticator API 키와 JWT 베어러 토큰으로 요청을 인증
+type Authenticator struct {
+	apiKeys   map[string]*Principal
+	jwtSecret []byte
+	jwtIssuer string
+	now       func() time.Time
+}
+
+// NewAuthenticator 새 인증기 생성
+func NewAuthenticator(config *AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		apiKeys:   make(map[string]*Principal),
+		jwtSecret: []byte(config.JWTSecret),
+		jwtIssuer: config.JWTIssuer,
+		now:       time.Now,
+	}
+
+	for _, keyConfig := range config.APIKeys {
+		if keyConfig.Key == "" {
+			return nil, fmt.Errorf("api key %q has no key", keyConfig.Name)
+		}
+		permissions, err := parsePermissions(keyConfig.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("api key %q: %w", keyConfig.Name, err)
+		}
+		a.apiKeys[keyConfig.Key] = &Principal{Name: keyConfig.Name, Permissions: permissions}
+	}
+
+	return a, nil
+}
+
+// parsePermissions 네임스페이스별 권한 문자열을 변환
+func parsePermissions(raw map[string]string) (map[string]Permission, error) {
+	permissions := make(map[string]Permission, len(raw))
+	for namespace, value := range raw {
+		perm, err := ParsePermission(value)
+		if err != nil {
+			return nil, err
+		}
+		permissions[namespace] = perm
+	}
+	return permissions, nil
+}
+
+// Authenticate 요청의 자격 증명으로 주체를 확인
+// 자격 증명은 X-API-Key 헤더, Authorization: Bearer 헤더 또는 access_token 쿼리 파라미터
+// (EventSource, WebSocket 등 헤더를 설정할 수 없는 클라이언트용)로 전달한다.
+// 베어러 토큰은 API 키이거나 HS256 JWT일 수 있다.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := r.Header.Get("X-API-Key")
+	if token == "" {
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = strings.TrimSpace(bearer)
+		}
+	}
+	if token == "" {
+		token = r.URL.Query().Get("access_token")
+	}
+	return a.AuthenticateToken(token)
+}
+
+// AuthenticateToken API 키 또는 HS256 JWT로 주체를 확인
+func (a *Authenticator) AuthenticateToken(token string) (*Principal, error) {
+	if token == "" {
+		return nil, fmt.Errorf("missing credentials")
+	}
+
+	for key, principal := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			return principal, nil
+		}
+	}
+
+	if strings.Count(token, ".") == 2 && len(a.jwtSecret) > 0 {
+		return a.verifyJWT(token)
+	}
+	return nil, fmt.Errorf("invalid credentials")
+}
+
+// verifyJWT HS256 JWT를 검증하고 주체를 반환
+func (a *Authenticator) verifyJWT(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+
+	// 헤더 확인
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported token algorithm")
+	}
+
+	// 서명 확인
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+
+	// 클레임 확인
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	now := a.now().Unix()
+	if claims.ExpiresAt != 0 && now >= claims.ExpiresAt {
+		return nil, fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return nil, fmt.Errorf("token not yet valid")
+	}
+	if a.jwtIssuer != "" && claims.Issuer != a.jwtIssuer {
+		return nil, fmt.Errorf("invalid token issuer")
+	}
+
+	permissions, err := parsePermissions(claims.Permissions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token permissions: %w", err)
+	}
+	return &Principal{Name: claims.Subject, Permissions: permissions}, nil
+}
+
+// principalContextKey 요청 컨텍스트에 주체를 저장하는 키
+type principalContextKey struct{}
+
+// principalFromRequest 요청의 인증된 주체 반환
+func principalFromRequest(r *http.Request) *Principal {
+	return principalFromContext(r.Context())
+}
+
+// principalFromContext 컨텍스트에 저장된 인증된 주체 반환
+func principalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+// authMiddleware 인증 미들웨어
+// 인증이 설정되지 않았거나 공개 경로(/health, /livez, /readyz)인 경우 그대로 통과시킨다.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil || isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := s.auth.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="crdtserver"`)
+			writeJSONError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalContextKey{}, principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authorize 요청 주체가 키에 대한 권한을 가졌는지 확인
+// 권한이 없으면 403 응답을 작성하고 false를 반환한다.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, key string, perm Permission) bool {
+	if s.auth == nil {
+		return true
+	}
+	principal := principalFromRequest(r)
+	if principal == nil || !principal.Can(key, perm) {
+		writeJSONError(w, http.StatusForbidden, fmt.Sprintf("%s permission required for %s", perm, key))
+		return false
+	}
+	return true
+}
+
+// canAccess 요청 주체가 키에 대한 권한을 가졌는지 확인 (응답을 작성하지 않음)
+func (s *Server) canAccess(r *http.Request, key string, perm Permission) bool {
+	if s.auth == nil {
+		return true
+	}
+	principal := principalFromRequest(r)
+	return principal != nil && principal.Can(key, perm)
+}
+
+// corsOrigin 요청 Origin에 대해 허용할 Access-Control-Allow-Origin 값
+func (s *Server) corsOrigin(r *http.Request) string {
+	origins := s.config.CORSOrigins
+	if len(origins) == 0 {
+		return "*"
+	}
+	origin := r.Header.Get("Origin")
+	for _, allowed := range origins {
+		if allowed == "*" || allowed == origin {
+			return allowed
+		}
+	}
+	return ""
+}
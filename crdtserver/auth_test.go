@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret"
+
+// signTestJWT 헤더와 클레임으로 HS256 서명한 JWT 생성
+func signTestJWT(t *testing.T, secret string, header, claims map[string]interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newTestAuthServer 인증이 설정된 서버와 현재 시각
+// 핸들러는 key 쿼리 파라미터의 키에 대해 perm 쿼리 파라미터의 권한을 확인한다.
+func newTestAuthServer(t *testing.T) (http.Handler, time.Time) {
+	t.Helper()
+	auth, err := NewAuthenticator(&AuthConfig{
+		APIKeys: []APIKeyConfig{
+			{Key: "reader-key", Name: "reader", Permissions: map[string]string{"/game": "read"}},
+			{Key: "writer-key", Name: "writer", Permissions: map[string]string{"/game": "write"}},
+		},
+		JWTSecret: testJWTSecret,
+		JWTIssuer: "boss-raid",
+	})
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+	auth.now = func() time.Time { return now }
+
+	s := &Server{auth: auth}
+	handler := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		perm, err := ParsePermission(r.URL.Query().Get("perm"))
+		if err != nil {
+			perm = PermissionRead
+		}
+		if !s.authorize(w, r, r.URL.Query().Get("key"), perm) {
+			return
+		}
+		w.Write([]byte(principalFromRequest(r).Name))
+	}))
+	return handler, now
+}
+
+// serveAuth 자격 증명을 설정한 요청을 처리하고 응답 반환
+func serveAuth(handler http.Handler, target string, setup func(r *http.Request)) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if setup != nil {
+		setup(r)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// bearer Authorization 헤더에 베어러 토큰 설정
+func bearer(token string) func(r *http.Request) {
+	return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+}
+
+// TestAuthMiddlewareAPIKey API 키 인증과 누락되거나 알 수 없는 키 거부 확인
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	handler, _ := newTestAuthServer(t)
+
+	tests := []struct {
+		name  string
+		setup func(r *http.Request)
+		code  int
+	}{
+		{"header", func(r *http.Request) { r.Header.Set("X-API-Key", "reader-key") }, http.StatusOK},
+		{"bearer", bearer("reader-key"), http.StatusOK},
+		{"missing", nil, http.StatusUnauthorized},
+		{"unknown", func(r *http.Request) { r.Header.Set("X-API-Key", "other-key") }, http.StatusUnauthorized},
+		{"unknown bearer", bearer("other-key"), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveAuth(handler, "/api/data/get?key=/game/gold", tt.setup)
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+			if tt.code == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			} else {
+				assert.Equal(t, "reader", w.Body.String())
+			}
+		})
+	}
+
+	// 쿼리 파라미터로 전달한 키
+	w := serveAuth(handler, "/api/data/get?key=/game/gold&access_token=writer-key", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "writer", w.Body.String())
+
+	// 공개 경로는 자격 증명 없이 통과
+	w = serveAuth(handler, "/health", nil)
+	assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestAuthMiddlewareJWT JWT 서명, 알고리즘, 유효 기간, 발급자 검증 확인
+func TestAuthMiddlewareJWT(t *testing.T) {
+	handler, now := newTestAuthServer(t)
+	hs256 := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":         "alice",
+			"iss":         "boss-raid",
+			"exp":         now.Add(time.Hour).Unix(),
+			"nbf":         now.Add(-time.Minute).Unix(),
+			"permissions": map[string]string{"/game": "write"},
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		code  int
+		err   string
+	}{
+		{"valid", signTestJWT(t, testJWTSecret, hs256, claims(nil)), http.StatusOK, ""},
+		{"wrong signature", signTestJWT(t, "other-secret", hs256, claims(nil)), http.StatusUnauthorized, "invalid token signature"},
+		{"alg none", signTestJWT(t, testJWTSecret, map[string]interface{}{"alg": "none"}, claims(nil)), http.StatusUnauthorized, "unsupported token algorithm"},
+		{"alg HS512", signTestJWT(t, testJWTSecret, map[string]interface{}{"alg": "HS512"}, claims(nil)), http.StatusUnauthorized, "unsupported token algorithm"},
+		{"expired", signTestJWT(t, testJWTSecret, hs256, claims(map[string]interface{}{"exp": now.Unix()})), http.StatusUnauthorized, "token expired"},
+		{"not yet valid", signTestJWT(t, testJWTSecret, hs256, claims(map[string]interface{}{"nbf": now.Add(time.Minute).Unix()})), http.StatusUnauthorized, "token not yet valid"},
+		{"wrong issuer", signTestJWT(t, testJWTSecret, hs256, claims(map[string]interface{}{"iss": "other"})), http.StatusUnauthorized, "invalid token issuer"},
+		{"unknown permission", signTestJWT(t, testJWTSecret, hs256, claims(map[string]interface{}{"permissions": map[string]string{"/game": "owner"}})), http.StatusUnauthorized, "invalid token permissions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveAuth(handler, "/api/data/put?key=/game/gold&perm=write", bearer(tt.token))
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+			if tt.err != "" {
+				assert.Contains(t, w.Body.String(), tt.err)
+			} else {
+				assert.Equal(t, "alice", w.Body.String())
+			}
+		})
+	}
+
+	// 서명을 바꾸지 않고 클레임만 바꾼 토큰
+	valid := signTestJWT(t, testJWTSecret, hs256, claims(nil))
+	forged := signTestJWT(t, testJWTSecret, hs256, claims(map[string]interface{}{"permissions": map[string]string{"/": "admin"}}))
+	tampered := forged[:strings.LastIndex(forged, ".")] + valid[strings.LastIndex(valid, "."):]
+	w := serveAuth(handler, "/api/data/put?key=/game/gold&perm=write", bearer(tampered))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestAuthMiddlewareScope 네임스페이스 밖의 키와 부족한 권한 거부 확인
+func TestAuthMiddlewareScope(t *testing.T) {
+	handler, now := newTestAuthServer(t)
+	token := signTestJWT(t, testJWTSecret, map[string]interface{}{"alg": "HS256"}, map[string]interface{}{
+		"sub":         "bob",
+		"iss":         "boss-raid",
+		"exp":         now.Add(time.Hour).Unix(),
+		"permissions": map[string]string{"/game/bob": "write", "/leaderboard": "read"},
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		setup  func(r *http.Request)
+		code   int
+	}{
+		{"read with read key", "/?key=/game/gold&perm=read", bearer("reader-key"), http.StatusOK},
+		{"write with read key", "/?key=/game/gold&perm=write", bearer("reader-key"), http.StatusForbidden},
+		{"write with write key", "/?key=/game/gold&perm=write", bearer("writer-key"), http.StatusOK},
+		{"admin with write key", "/?key=/game/gold&perm=admin", bearer("writer-key"), http.StatusForbidden},
+		{"key outside namespace", "/?key=/shop/items&perm=read", bearer("writer-key"), http.StatusForbidden},
+		{"sibling prefix", "/?key=/gameplay/state&perm=read", bearer("writer-key"), http.StatusForbidden},
+		{"jwt own key", "/?key=/game/bob/gold&perm=write", bearer(token), http.StatusOK},
+		{"jwt other player", "/?key=/game/alice/gold&perm=read", bearer(token), http.StatusForbidden},
+		{"jwt read scope", "/?key=/leaderboard/top&perm=read", bearer(token), http.StatusOK},
+		{"jwt write on read scope", "/?key=/leaderboard/top&perm=write", bearer(token), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveAuth(handler, tt.target, tt.setup)
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+			if tt.code == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), "permission required")
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxBatchOperations 배치 하나에 허용되는 최대 작업 수
+const maxBatchOperations = 1000
+
+// 배치 작업 유형
+const (
+	BatchOpPut    = "put"
+	BatchOpDelete = "delete"
+)
+
+// BatchRequest 배치 API 요청
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation 배치 작업
+//
+// IfValue와 IfAbsent는 작업의 전제 조건이다. 전제 조건이 하나라도 맞지 않으면
+// 배치의 어떤 작업도 적용하지 않는다.
+type BatchOperation struct {
+	// Op 작업 유형 (put, delete)
+	Op string `json:"op"`
+	// Key 대상 키
+	Key string `json:"key"`
+	// Value 저장할 값 (put)
+	Value *string `json:"value,omitempty"`
+	// Encoding Value와 IfValue의 인코딩 ("base64" 또는 빈 문자열)
+	Encoding string `json:"encoding,omitempty"`
+	// IfValue 현재 값이 이 값과 같을 때만 적용
+	IfValue *string `json:"ifValue,omitempty"`
+	// IfAbsent 키가 없을 때만 적용
+	IfAbsent bool `json:"ifAbsent,omitempty"`
+	// TTL 만료 시간(초, put). 0이면 만료되지 않는다.
+	TTL int64 `json:"ttl,omitempty"`
+}
+
+// BatchFailure 실패한 배치 작업
+type BatchFailure struct {
+	Index int    `json:"index"`
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// decodeValue 인코딩에 따라 값을 디코딩
+func (op *BatchOperation) decodeValue(value string) ([]byte, error) {
+	switch op.Encoding {
+	case "":
+		return []byte(value), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(value)
+	default:
+		return nil, fmt.Errorf("unknown encoding: %q", op.Encoding)
+	}
+}
+
+// validate 작업 형식 확인
+func (op *BatchOperation) validate() error {
+	if op.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if isReservedKey(ds.NewKey(op.Key)) {
+		return fmt.Errorf("reserved key: %s", op.Key)
+	}
+	if op.TTL < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+	switch op.Op {
+	case BatchOpPut:
+		if op.Value == nil {
+			return fmt.Errorf("value is required")
+		}
+	case BatchOpDelete:
+		if op.Value != nil || op.TTL != 0 {
+			return fmt.Errorf("delete does not take a value or ttl")
+		}
+	default:
+		return fmt.Errorf("unknown op: %q", op.Op)
+	}
+	if op.IfAbsent && op.IfValue != nil {
+		return fmt.Errorf("ifAbsent and ifValue are mutually exclusive")
+	}
+	return nil
+}
+
+// checkPrecondition 작업의 전제 조건 확인
+func (s *Server) checkPrecondition(op *BatchOperation) error {
+	if !op.IfAbsent && op.IfValue == nil {
+		return nil
+	}
+
+	key := ds.NewKey(op.Key)
+	current, err := s.data.crdt.Get(s.ctx, key)
+	if err != nil && err != ds.ErrNotFound {
+		return err
+	}
+	exists := err == nil && !s.isExpired(s.ctx, s.data, key)
+
+	if op.IfAbsent {
+		if exists {
+			return fmt.Errorf("precondition failed: key exists")
+		}
+		return nil
+	}
+
+	expected, err := op.decodeValue(*op.IfValue)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("precondition failed: key not found")
+	}
+	if !bytes.Equal(current, expected) {
+		return fmt.Errorf("precondition failed: value mismatch")
+	}
+	return nil
+}
+
+// handleBatch 배치 API 핸들러
+//
+//	POST /api/data:batch
+//
+// 여러 키의 저장과 삭제를 CRDT 델타 하나로 커밋한다. 델타는 DAG 노드 하나로 다른 노드에
+// 복제되므로 다른 노드는 배치 전체를 함께 적용한다. 전제 조건은 이 서버에서 배치를
+// 직렬화하여 확인하므로, 다른 노드에서 동시에 쓴 값과의 충돌은 CRDT 병합 규칙을 따른다.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBodyError(w, err, "invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Operations) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "operations is required")
+		return
+	}
+	if len(req.Operations) > maxBatchOperations {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("too many operations (max %d)", maxBatchOperations))
+		return
+	}
+
+	// 작업 검증
+	values := make([][]byte, len(req.Operations))
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		err := op.validate()
+		if err == nil && op.Value != nil {
+			values[i], err = op.decodeValue(*op.Value)
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("operation %d: %v", i, err))
+			return
+		}
+		op.Key = ds.NewKey(op.Key).String()
+		if !s.authorize(w, r, op.Key, PermissionWrite) {
+			return
+		}
+	}
+
+	// 쓰기 훅 확인 (하나라도 거부되면 배치 전체를 적용하지 않음)
+	failures := []BatchFailure{}
+	for i, op := range req.Operations {
+		if err := s.hooks.PreWrite(op.Key, values[i]); err != nil {
+			failures = append(failures, BatchFailure{Index: i, Key: op.Key, Error: err.Error()})
+		}
+	}
+	if len(failures) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    "write rejected",
+			"failures": failures,
+		})
+		return
+	}
+
+	// 전제 조건 확인부터 커밋까지 다른 배치가 끼어들지 않도록 직렬화
+	s.data.batchMu.Lock()
+	defer s.data.batchMu.Unlock()
+
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		if err := s.checkPrecondition(op); err != nil {
+			failures = append(failures, BatchFailure{Index: i, Key: op.Key, Error: err.Error()})
+		}
+	}
+	if len(failures) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    "precondition failed",
+			"failures": failures,
+		})
+		return
+	}
+
+	// 배치 적용
+	ctx, span := tracer.Start(s.withRequestSpan(r), "crdt.batch",
+		trace.WithAttributes(attribute.Int("crdt.operations", len(req.Operations))))
+	err := s.commitBatchLocked(ctx, s.data, func(batch ds.Batch) error {
+		for i, op := range req.Operations {
+			key := ds.NewKey(op.Key)
+			var err error
+			if op.Op == BatchOpPut {
+				var stored []byte
+				stored, err = s.stageFields(ctx, s.data, batch, key, values[i])
+				if err == nil {
+					err = batch.Put(ctx, key, stored)
+				}
+			} else {
+				err = batch.Delete(ctx, key)
+				if err == nil {
+					_, err = s.stageFields(ctx, s.data, batch, key, nil)
+				}
+			}
+			if err == nil {
+				err = s.stageTTL(ctx, s.data, batch, key, time.Duration(op.TTL)*time.Second)
+			}
+			if err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+	endSpan(span, err)
+	if err != nil {
+		logger.Errorf("Failed to commit batch: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 쓰기 훅과 이벤트 알림
+	for i, op := range req.Operations {
+		s.hooks.PostWrite(op.Key, values[i])
+		s.broadcastSSEEvent(op.Op, op.Key, values[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "ok",
+		"applied": len(req.Operations),
+	})
+}
+
+// commitBatch 배치에 작업을 추가하고 하나의 CRDT 델타로 커밋
+// ctx의 스팬은 델타 블록 생성과 브로드캐스트까지 이어진다.
+func (s *Server) commitBatch(ctx context.Context, d *dataStore, stage func(batch ds.Batch) error) error {
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+	return s.commitBatchLocked(ctx, d, stage)
+}
+
+// commitBatchLocked d.batchMu를 잡은 상태에서 배치 커밋
+func (s *Server) commitBatchLocked(ctx context.Context, d *dataStore, stage func(batch ds.Batch) error) error {
+	batch, err := d.crdt.Batch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create batch: %w", err)
+	}
+	if err := stage(batch); err != nil {
+		return err
+	}
+	return batch.Commit(ctx)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	ds "github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postBatch 배치 API 호출
+func postBatch(t *testing.T, s *Server, operations ...BatchOperation) (int, map[string]interface{}) {
+	t.Helper()
+	body, err := json.Marshal(BatchRequest{Operations: operations})
+	require.NoError(t, err)
+	w := serveTest(s, http.MethodPost, "/api/data:batch", string(body))
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+	return w.Code, response
+}
+
+// strPtr 문자열 포인터
+func strPtr(value string) *string {
+	return &value
+}
+
+// requireValue 기본 데이터스토어의 키 값 확인 (want가 nil이면 키가 없어야 함)
+func requireValue(t *testing.T, s *Server, key string, want *string) {
+	t.Helper()
+	value, err := s.getValue(context.Background(), s.data, ds.NewKey(key))
+	if want == nil {
+		require.Equal(t, ds.ErrNotFound, err, key)
+		return
+	}
+	require.NoError(t, err, key)
+	assert.Equal(t, *want, string(value), key)
+}
+
+// TestBatchCommit 배치의 저장과 삭제가 델타 하나로 커밋되고 이벤트가 전달되는지 확인
+func TestBatchCommit(t *testing.T) {
+	s := newTestServer(t, Config{})
+	putData(t, s, "boss/hp", "100")
+	events := watchEvents(t, s)
+
+	code, response := postBatch(t, s,
+		BatchOperation{Op: BatchOpPut, Key: "boss/hp", Value: strPtr("90"), IfValue: strPtr("100")},
+		BatchOperation{Op: BatchOpPut, Key: "raid/loot", Value: strPtr("Z29sZA=="), Encoding: "base64", IfAbsent: true, TTL: 60},
+		BatchOperation{Op: BatchOpDelete, Key: "boss/hp"},
+		BatchOperation{Op: BatchOpPut, Key: "raid/state", Value: strPtr("started")},
+	)
+	require.Equal(t, http.StatusOK, code, response)
+	assert.Equal(t, float64(4), response["applied"])
+
+	requireValue(t, s, "/boss/hp", nil)
+	requireValue(t, s, "/raid/loot", strPtr("gold"))
+	requireValue(t, s, "/raid/state", strPtr("started"))
+	expiresAt, err := s.expiresAt(context.Background(), s.data, ds.NewKey("/raid/loot"))
+	require.NoError(t, err)
+	assert.False(t, expiresAt.IsZero())
+
+	// 배치 전체가 DAG 노드 하나
+	assert.Equal(t, uint64(2), s.crdt.InternalStats(context.Background()).MaxHeight)
+
+	for _, want := range [][2]string{{"put", "/boss/hp"}, {"put", "/raid/loot"}, {"delete", "/boss/hp"}, {"put", "/raid/state"}} {
+		event, key, _ := nextEvent(t, events)
+		assert.Equal(t, want, [2]string{event, key})
+	}
+}
+
+// TestBatchPreconditionRollback 전제 조건이 하나라도 맞지 않으면 어떤 작업도 적용하지 않는지 확인
+func TestBatchPreconditionRollback(t *testing.T) {
+	s := newTestServer(t, Config{})
+	putData(t, s, "boss/hp", "100")
+	putData(t, s, "raid/state", "waiting")
+	height := s.crdt.InternalStats(context.Background()).MaxHeight
+
+	code, response := postBatch(t, s,
+		BatchOperation{Op: BatchOpPut, Key: "boss/hp", Value: strPtr("90"), IfValue: strPtr("100")},
+		BatchOperation{Op: BatchOpPut, Key: "raid/state", Value: strPtr("started"), IfAbsent: true},
+		BatchOperation{Op: BatchOpDelete, Key: "raid/loot", IfValue: strPtr("gold")},
+		BatchOperation{Op: BatchOpPut, Key: "raid/leader", Value: strPtr("alice")},
+	)
+	require.Equal(t, http.StatusConflict, code, response)
+	failures, ok := response["failures"].([]interface{})
+	require.True(t, ok, response)
+	require.Len(t, failures, 2)
+	assert.Equal(t, map[string]interface{}{"index": float64(1), "key": "/raid/state", "error": "precondition failed: key exists"}, failures[0])
+	assert.Equal(t, map[string]interface{}{"index": float64(2), "key": "/raid/loot", "error": "precondition failed: key not found"}, failures[1])
+
+	requireValue(t, s, "/boss/hp", strPtr("100"))
+	requireValue(t, s, "/raid/state", strPtr("waiting"))
+	requireValue(t, s, "/raid/leader", nil)
+	assert.Equal(t, height, s.crdt.InternalStats(context.Background()).MaxHeight)
+
+	// 값이 다르면 ifValue도 실패
+	code, response = postBatch(t, s, BatchOperation{Op: BatchOpPut, Key: "boss/hp", Value: strPtr("90"), IfValue: strPtr("99")})
+	require.Equal(t, http.StatusConflict, code, response)
+	assert.Contains(t, response["failures"].([]interface{})[0].(map[string]interface{})["error"], "value mismatch")
+	requireValue(t, s, "/boss/hp", strPtr("100"))
+}
+
+// TestBatchValidation 형식이 잘못된 배치를 적용하지 않고 400으로 거부하는지 확인
+func TestBatchValidation(t *testing.T) {
+	s := newTestServer(t, Config{})
+
+	for name, op := range map[string]BatchOperation{
+		"missing key":    {Op: BatchOpPut, Value: strPtr("1")},
+		"reserved key":   {Op: BatchOpPut, Key: ttlPrefix + "/gold", Value: strPtr("1")},
+		"unknown op":     {Op: "increment", Key: "gold"},
+		"missing value":  {Op: BatchOpPut, Key: "gold"},
+		"delete value":   {Op: BatchOpDelete, Key: "gold", Value: strPtr("1")},
+		"negative ttl":   {Op: BatchOpPut, Key: "gold", Value: strPtr("1"), TTL: -1},
+		"both checks":    {Op: BatchOpPut, Key: "gold", Value: strPtr("1"), IfAbsent: true, IfValue: strPtr("0")},
+		"bad encoding":   {Op: BatchOpPut, Key: "gold", Value: strPtr("1"), Encoding: "hex"},
+		"invalid base64": {Op: BatchOpPut, Key: "gold", Value: strPtr("!"), Encoding: "base64"},
+	} {
+		code, response := postBatch(t, s, BatchOperation{Op: BatchOpPut, Key: "silver", Value: strPtr("1")}, op)
+		assert.Equal(t, http.StatusBadRequest, code, name)
+		assert.Contains(t, response["error"], "operation 1", name)
+	}
+	requireValue(t, s, "/silver", nil)
+
+	code, _ := postBatch(t, s)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serveTest(s, http.MethodGet, "/api/data:batch", "").Code)
+}
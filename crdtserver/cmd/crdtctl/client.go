@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sseReconnectDelay SSE 연결이 끊겼을 때 다시 연결하기 전 대기 시간
+const sseReconnectDelay = time.Second
+
+// APIError crdtserver가 반환한 에러 응답
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// Entry 키와 값
+type Entry struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Bytes 인코딩에 따라 값을 디코딩
+func (e Entry) Bytes() ([]byte, error) {
+	switch e.Encoding {
+	case "":
+		return []byte(e.Value), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(e.Value)
+	default:
+		return nil, fmt.Errorf("unknown encoding: %q", e.Encoding)
+	}
+}
+
+// BatchOperation 배치 API 작업 (crdtserver의 BatchOperation과 같은 형식)
+type BatchOperation struct {
+	Op       string  `json:"op"`
+	Key      string  `json:"key"`
+	Value    *string `json:"value,omitempty"`
+	Encoding string  `json:"encoding,omitempty"`
+}
+
+// Client crdtserver HTTP API 클라이언트
+type Client struct {
+	baseURL *url.URL
+	// token API 키 또는 JWT (비어 있으면 인증 없이 요청)
+	token string
+	// namespace 데이터 네임스페이스 (비어 있으면 기본 데이터 네임스페이스)
+	namespace string
+	http      *http.Client
+}
+
+// NewClient 새 클라이언트 생성
+func NewClient(server, token, namespace string) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(server, "/"))
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid server URL: %q", server)
+	}
+	return &Client{
+		baseURL:   baseURL,
+		token:     token,
+		namespace: namespace,
+		http:      &http.Client{},
+	}, nil
+}
+
+// dataPath 데이터 API 경로
+func (c *Client) dataPath(key string) string {
+	base := "/api/data"
+	if c.namespace != "" {
+		base = "/api/ns/" + c.namespace + "/data"
+	}
+	if key = strings.Trim(key, "/"); key != "" {
+		return base + "/" + key
+	}
+	return base
+}
+
+// do API 요청 전송
+// 상태 코드가 400 이상이면 응답 본문의 error 메시지로 APIError를 반환한다.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader, header http.Header) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			message = errResp.Error
+		}
+		return nil, &APIError{Status: resp.StatusCode, Message: message}
+	}
+	return resp, nil
+}
+
+// doJSON API 요청을 보내고 JSON 응답을 out에 디코딩 (out이 nil이면 응답 무시)
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body io.Reader, out interface{}) error {
+	resp, err := c.do(ctx, method, path, query, body, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Get 키의 값 조회
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.dataPath(key), nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Put 키에 값 저장 (ttl이 0보다 크면 그 시간 뒤에 만료)
+func (c *Client) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	query := url.Values{}
+	if ttl > 0 {
+		query.Set("ttl", strconv.FormatInt(int64(ttl/time.Second), 10))
+	}
+	return c.doJSON(ctx, http.MethodPost, c.dataPath(key), query, bytes.NewReader(value), nil)
+}
+
+// Delete 키 삭제
+func (c *Client) Delete(ctx context.Context, key string) error {
+	return c.doJSON(ctx, http.MethodDelete, c.dataPath(key), nil, nil, nil)
+}
+
+// List 접두사로 시작하는 키 목록 조회
+func (c *Client) List(ctx context.Context, prefix string) ([]Entry, error) {
+	query := url.Values{}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	var entries []Entry
+	if err := c.doJSON(ctx, http.MethodGet, c.dataPath(""), query, nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Batch 배치 API로 여러 작업을 한 번에 적용
+// 배치 API는 기본 데이터 네임스페이스에서만 지원한다.
+func (c *Client) Batch(ctx context.Context, ops []BatchOperation) error {
+	if c.namespace != "" {
+		return fmt.Errorf("batch is not supported in namespace %s", c.namespace)
+	}
+	body, err := json.Marshal(map[string]interface{}{"operations": ops})
+	if err != nil {
+		return err
+	}
+	return c.doJSON(ctx, http.MethodPost, "/api/data:batch", nil, bytes.NewReader(body), nil)
+}
+
+// Peers 서버의 피어 정보 조회
+func (c *Client) Peers(ctx context.Context) (map[string]interface{}, error) {
+	var peers map[string]interface{}
+	if err := c.doJSON(ctx, http.MethodGet, "/peers", nil, nil, &peers); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// Tail SSE 이벤트를 받아 handle에 전달
+//
+// 연결이 끊기면 마지막으로 받은 이벤트의 id를 Last-Event-ID로 보내며 다시 연결하므로
+// 서버에 남아 있는 동안의 이벤트는 유실되지 않는다. ctx가 취소될 때까지 반환하지 않는다.
+func (c *Client) Tail(ctx context.Context, query url.Values, handle func(data []byte)) error {
+	lastEventID := ""
+	for {
+		err := c.tailOnce(ctx, query, &lastEventID, handle)
+		if ctx.Err() != nil {
+			return nil
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Status < http.StatusInternalServerError {
+			return err
+		}
+		logf("SSE connection lost (%v), reconnecting", err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(sseReconnectDelay):
+		}
+	}
+}
+
+// tailOnce SSE 연결 하나에서 이벤트 수신
+func (c *Client) tailOnce(ctx context.Context, query url.Values, lastEventID *string, handle func(data []byte)) error {
+	header := http.Header{}
+	header.Set("Accept", "text/event-stream")
+	if *lastEventID != "" {
+		header.Set("Last-Event-ID", *lastEventID)
+	}
+	resp, err := c.do(ctx, http.MethodGet, "/events", query, nil, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			*lastEventID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			handle([]byte(strings.TrimPrefix(line, "data: ")))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
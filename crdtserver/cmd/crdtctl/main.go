@@ -0,0 +1,366 @@
+// crdtctl은 crdtserver HTTP API를 사용하는 관리 도구다.
+//
+// 사용법:
+//
+//	crdtctl [flags] <command> [args]
+//
+// 서버 주소와 인증 토큰은 --server, --token 플래그 또는 CRDTCTL_SERVER, CRDTCTL_TOKEN
+// 환경 변수로 지정한다.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// importBatchSize import가 배치 하나로 보내는 최대 키 수 (crdtserver의 배치 최대 작업 수)
+const importBatchSize = 1000
+
+const usage = `Usage: crdtctl [flags] <command> [args]
+
+Commands:
+  get <key>                              Print the value of a key
+  put [--ttl 30s] <key> [value]          Store a value (reads stdin if value is omitted or "-")
+  delete <key>                           Delete a key
+  list [--json] [prefix]                 List keys and values
+  tail [--prefix p]... [--ops put,...]   Stream change events (reconnects with Last-Event-ID)
+  peers                                  Show peer information
+  export [--out file] [prefix]           Export keys and values as JSON lines
+  import [--in file]                     Import keys written by export
+
+Flags:
+`
+
+// command 하위 명령
+type command func(ctx context.Context, client *Client, args []string) error
+
+var commands = map[string]command{
+	"get":    runGet,
+	"put":    runPut,
+	"delete": runDelete,
+	"list":   runList,
+	"tail":   runTail,
+	"peers":  runPeers,
+	"export": runExport,
+	"import": runImport,
+}
+
+func main() {
+	server := flag.String("server", envOr("CRDTCTL_SERVER", "http://localhost:8080"), "crdtserver URL (env CRDTCTL_SERVER)")
+	token := flag.String("token", os.Getenv("CRDTCTL_TOKEN"), "API key or JWT (env CRDTCTL_TOKEN)")
+	namespace := flag.String("ns", "", "Data namespace to use instead of the default one")
+	timeout := flag.Duration("timeout", 30*time.Second, "Request timeout (not applied to tail)")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		logf("unknown command: %s", name)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client, err := NewClient(*server, *token, *namespace)
+	if err != nil {
+		logf("%v", err)
+		os.Exit(2)
+	}
+
+	// Ctrl+C로 종료
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if name != "tail" {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if err := cmd(ctx, client, flag.Args()[1:]); err != nil {
+		logf("%s: %v", name, err)
+		os.Exit(1)
+	}
+}
+
+// runGet 키의 값 출력
+func runGet(ctx context.Context, client *Client, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: get <key>")
+	}
+	value, err := client.Get(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(value)
+	return err
+}
+
+// runPut 키에 값 저장
+func runPut(ctx context.Context, client *Client, args []string) error {
+	flags := flag.NewFlagSet("put", flag.ContinueOnError)
+	ttl := flags.Duration("ttl", 0, "Expire the key after this duration (rounded down to seconds)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return fmt.Errorf("usage: put [--ttl 30s] <key> [value]")
+	}
+	if *ttl > 0 && *ttl < time.Second {
+		return fmt.Errorf("ttl must be at least 1s")
+	}
+
+	var value []byte
+	if flags.NArg() == 2 && flags.Arg(1) != "-" {
+		value = []byte(flags.Arg(1))
+	} else {
+		var err error
+		if value, err = io.ReadAll(os.Stdin); err != nil {
+			return err
+		}
+	}
+	return client.Put(ctx, flags.Arg(0), value, *ttl)
+}
+
+// runDelete 키 삭제
+func runDelete(ctx context.Context, client *Client, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: delete <key>")
+	}
+	return client.Delete(ctx, args[0])
+}
+
+// runList 키와 값 목록 출력
+func runList(ctx context.Context, client *Client, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print the entries as a JSON array")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("usage: list [--json] [prefix]")
+	}
+
+	entries, err := client.List(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	for _, entry := range entries {
+		if entry.Encoding != "" {
+			fmt.Printf("%s\t(%s) %s\n", entry.Key, entry.Encoding, entry.Value)
+		} else {
+			fmt.Printf("%s\t%s\n", entry.Key, entry.Value)
+		}
+	}
+	return nil
+}
+
+// runTail SSE 이벤트 출력
+// 이벤트는 한 줄에 JSON 하나씩 표준 출력으로, 연결 상태는 표준 에러로 출력한다.
+func runTail(ctx context.Context, client *Client, args []string) error {
+	flags := flag.NewFlagSet("tail", flag.ContinueOnError)
+	var prefixes stringList
+	flags.Var(&prefixes, "prefix", "Only stream events for keys with this prefix (repeatable)")
+	ops := flags.String("ops", "", "Comma-separated event types to stream (put, get, delete, patch)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("usage: tail [--prefix p]... [--ops put,delete]")
+	}
+
+	query := url.Values{}
+	for _, prefix := range prefixes {
+		query.Add("prefix", prefix)
+	}
+	if *ops != "" {
+		query.Set("ops", *ops)
+	}
+
+	return client.Tail(ctx, query, func(data []byte) {
+		var event struct {
+			Event    string `json:"event"`
+			Resumed  bool   `json:"resumed"`
+			ClientID string `json:"clientId"`
+		}
+		json.Unmarshal(data, &event)
+		switch event.Event {
+		case "connected":
+			logf("connected (client %s, resumed: %t)", event.ClientID, event.Resumed)
+		case "reset":
+			logf("missed events could not be replayed; re-read the data to resync")
+		default:
+			fmt.Printf("%s\n", data)
+		}
+	})
+}
+
+// runPeers 피어 정보 출력
+func runPeers(ctx context.Context, client *Client, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: peers")
+	}
+	peers, err := client.Peers(ctx)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(peers)
+}
+
+// runExport 키와 값을 JSON lines로 내보내기
+// 만료 시간은 내보내지 않는다.
+func runExport(ctx context.Context, client *Client, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", "-", "Output file (stdout if -)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("usage: export [--out file] [prefix]")
+	}
+
+	entries, err := client.List(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	logf("exported %d keys", len(entries))
+	return nil
+}
+
+// runImport export로 내보낸 키와 값 가져오기
+// 기본 데이터 네임스페이스에서는 배치 API로 1000개씩, 네임스페이스에서는 키마다 저장한다.
+func runImport(ctx context.Context, client *Client, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	in := flags.String("in", "-", "Input file (stdin if -)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("usage: import [--in file]")
+	}
+
+	r := io.Reader(os.Stdin)
+	if *in != "-" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	imported := 0
+	var batch []BatchOperation
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := client.Batch(ctx, batch); err != nil {
+			return err
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var entry Entry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("entry %d: %w", line, err)
+		}
+		value, err := entry.Bytes()
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", line, err)
+		}
+
+		if client.namespace != "" {
+			if err := client.Put(ctx, entry.Key, value, 0); err != nil {
+				return fmt.Errorf("entry %d (%s): %w", line, entry.Key, err)
+			}
+			imported++
+			continue
+		}
+
+		batch = append(batch, BatchOperation{Op: "put", Key: entry.Key, Value: &entry.Value, Encoding: entry.Encoding})
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	logf("imported %d keys", imported)
+	return nil
+}
+
+// stringList 여러 번 지정할 수 있는 문자열 플래그
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// envOr 환경 변수 값 (없으면 기본값)
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// logf 표준 에러로 메시지 출력
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "crdtctl: "+format+"\n", args...)
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRequest 스텁 서버가 받은 요청
+type stubRequest struct {
+	Method        string
+	Path          string
+	Query         string
+	Body          string
+	Authorization string
+	LastEventID   string
+}
+
+// stubServer 받은 요청을 기록하고 경로별 응답을 돌려주는 crdtserver 스텁
+type stubServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []stubRequest
+	// responses "METHOD PATH" -> 응답 핸들러 (없으면 200 {"status":"ok"})
+	responses map[string]http.HandlerFunc
+}
+
+// newStubServer 새 스텁 서버 시작
+func newStubServer(t *testing.T) *stubServer {
+	t.Helper()
+	s := &stubServer{responses: make(map[string]http.HandlerFunc)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, stubRequest{
+			Method:        r.Method,
+			Path:          r.URL.Path,
+			Query:         r.URL.RawQuery,
+			Body:          string(body),
+			Authorization: r.Header.Get("Authorization"),
+			LastEventID:   r.Header.Get("Last-Event-ID"),
+		})
+		handler, ok := s.responses[r.Method+" "+r.URL.Path]
+		s.mu.Unlock()
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"status":"ok"}`)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// respond 요청에 돌려줄 응답 설정
+func (s *stubServer) respond(method, path string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[method+" "+path] = handler
+}
+
+// respondJSON 요청에 돌려줄 JSON 응답 설정
+func (s *stubServer) respondJSON(method, path string, status int, body string) {
+	s.respond(method, path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+}
+
+// received 지금까지 받은 요청
+func (s *stubServer) received() []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubRequest(nil), s.requests...)
+}
+
+// runCommand 표준 입력을 stdin으로 바꿔 명령을 실행하고 표준 출력 반환
+func runCommand(t *testing.T, client *Client, stdin string, args ...string) (string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	in, err := os.Create(filepath.Join(dir, "stdin"))
+	require.NoError(t, err)
+	_, err = in.WriteString(stdin)
+	require.NoError(t, err)
+	_, err = in.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	out, err := os.Create(filepath.Join(dir, "stdout"))
+	require.NoError(t, err)
+
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = in, out
+	defer func() {
+		os.Stdin, os.Stdout = oldStdin, oldStdout
+		in.Close()
+		out.Close()
+	}()
+
+	cmd, ok := commands[args[0]]
+	require.True(t, ok, args[0])
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	runErr := cmd(ctx, client, args[1:])
+
+	output, err := os.ReadFile(out.Name())
+	require.NoError(t, err)
+	return string(output), runErr
+}
+
+// newTestClient 스텁 서버에 요청하는 클라이언트
+func newTestClient(t *testing.T, server *stubServer, namespace string) *Client {
+	t.Helper()
+	client, err := NewClient(server.URL+"/", "game-key", namespace)
+	require.NoError(t, err)
+	return client
+}
+
+// TestNewClient 서버 URL 검증
+func TestNewClient(t *testing.T) {
+	for _, server := range []string{"", "localhost:8080", "http://", "://bad"} {
+		_, err := NewClient(server, "", "")
+		assert.Error(t, err, server)
+	}
+	client, err := NewClient("https://raid.example:8443/", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://raid.example:8443", client.baseURL.String())
+}
+
+// TestCommandUsage 잘못된 인자는 서버에 요청하지 않고 사용법 에러를 반환하는지 확인
+func TestCommandUsage(t *testing.T) {
+	server := newStubServer(t)
+	client := newTestClient(t, server, "")
+
+	for _, args := range [][]string{
+		{"get"},
+		{"get", "a", "b"},
+		{"put"},
+		{"put", "key", "value", "extra"},
+		{"put", "--ttl", "500ms", "key", "value"},
+		{"put", "--ttl", "soon", "key", "value"},
+		{"delete"},
+		{"list", "a", "b"},
+		{"list", "--unknown"},
+		{"tail", "boss"},
+		{"peers", "extra"},
+		{"export", "a", "b"},
+		{"import", "file"},
+	} {
+		_, err := runCommand(t, client, "", args...)
+		assert.Error(t, err, args)
+	}
+	assert.Empty(t, server.received())
+}
+
+// TestDataCommands get, put, delete가 데이터 API로 요청하고 결과를 출력하는지 확인
+func TestDataCommands(t *testing.T) {
+	server := newStubServer(t)
+	client := newTestClient(t, server, "")
+	server.respond(http.MethodGet, "/api/data/boss/hp", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "100")
+	})
+
+	output, err := runCommand(t, client, "", "get", "/boss/hp")
+	require.NoError(t, err)
+	assert.Equal(t, "100", output)
+
+	_, err = runCommand(t, client, "", "put", "--ttl", "90s", "boss/hp", "90")
+	require.NoError(t, err)
+	_, err = runCommand(t, client, "from stdin", "put", "boss/name")
+	require.NoError(t, err)
+	_, err = runCommand(t, client, "dash", "put", "boss/title", "-")
+	require.NoError(t, err)
+	_, err = runCommand(t, client, "", "delete", "boss/hp")
+	require.NoError(t, err)
+
+	assert.Equal(t, []stubRequest{
+		{Method: http.MethodGet, Path: "/api/data/boss/hp", Authorization: "Bearer game-key"},
+		{Method: http.MethodPost, Path: "/api/data/boss/hp", Query: "ttl=90", Body: "90", Authorization: "Bearer game-key"},
+		{Method: http.MethodPost, Path: "/api/data/boss/name", Body: "from stdin", Authorization: "Bearer game-key"},
+		{Method: http.MethodPost, Path: "/api/data/boss/title", Body: "dash", Authorization: "Bearer game-key"},
+		{Method: http.MethodDelete, Path: "/api/data/boss/hp", Authorization: "Bearer game-key"},
+	}, server.received())
+
+	// 네임스페이스를 지정하면 네임스페이스 데이터 API 사용
+	nsClient := newTestClient(t, server, "raid-1")
+	_, err = runCommand(t, nsClient, "", "delete", "boss/hp")
+	require.NoError(t, err)
+	requests := server.received()
+	assert.Equal(t, "/api/ns/raid-1/data/boss/hp", requests[len(requests)-1].Path)
+}
+
+// TestAPIError 서버의 에러 응답을 상태 코드와 메시지로 반환하는지 확인
+func TestAPIError(t *testing.T) {
+	server := newStubServer(t)
+	client := newTestClient(t, server, "")
+	server.respondJSON(http.MethodGet, "/api/data/missing", http.StatusNotFound, `{"error":"key not found"}`)
+	server.respond(http.MethodDelete, "/api/data/boss", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	_, err := runCommand(t, client, "", "get", "missing")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr), err)
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+	assert.Equal(t, "404 Not Found: key not found", apiErr.Error())
+
+	_, err = runCommand(t, client, "", "delete", "boss")
+	require.True(t, errors.As(err, &apiErr), err)
+	assert.Equal(t, "Method not allowed", apiErr.Message)
+}
+
+// TestListCommand 목록을 탭 구분 형식과 JSON으로 출력하는지 확인
+func TestListCommand(t *testing.T) {
+	server := newStubServer(t)
+	client := newTestClient(t, server, "")
+	server.respondJSON(http.MethodGet, "/api/data", http.StatusOK,
+		`[{"key":"/boss/hp","value":"100"},{"key":"/boss/icon","value":"AAE=","encoding":"base64"}]`)
+
+	output, err := runCommand(t, client, "", "list", "boss")
+	require.NoError(t, err)
+	assert.Equal(t, "/boss/hp\t100\n/boss/icon\t(base64) AAE=\n", output)
+	assert.Equal(t, "prefix=boss", server.received()[0].Query)
+
+	output, err = runCommand(t, client, "", "list", "--json")
+	require.NoError(t, err)
+	var entries []Entry
+	require.NoError(t, json.Unmarshal([]byte(output), &entries))
+	assert.Equal(t, []Entry{{Key: "/boss/hp", Value: "100"}, {Key: "/boss/icon", Value: "AAE=", Encoding: "base64"}}, entries)
+	assert.Equal(t, "", server.received()[1].Query)
+}
+
+// TestExportImport export로 내보낸 파일을 import가 배치 API로, 네임스페이스에서는 키마다 저장하는지 확인
+func TestExportImport(t *testing.T) {
+	server := newStubServer(t)
+	client := newTestClient(t, server, "")
+	server.respondJSON(http.MethodGet, "/api/data", http.StatusOK,
+		`[{"key":"/boss/hp","value":"100"},{"key":"/boss/icon","value":"AAE=","encoding":"base64"}]`)
+
+	file := filepath.Join(t.TempDir(), "export.jsonl")
+	_, err := runCommand(t, client, "", "export", "--out", file, "boss")
+	require.NoError(t, err)
+	exported, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"key\":\"/boss/hp\",\"value\":\"100\"}\n{\"key\":\"/boss/icon\",\"value\":\"AAE=\",\"encoding\":\"base64\"}\n", string(exported))
+
+	_, err = runCommand(t, client, "", "import", "--in", file)
+	require.NoError(t, err)
+	requests := server.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/api/data:batch", requests[1].Path)
+	assert.JSONEq(t, `{"operations":[
+		{"op":"put","key":"/boss/hp","value":"100"},
+		{"op":"put","key":"/boss/icon","value":"AAE=","encoding":"base64"}
+	]}`, requests[1].Body)
+
+	// 네임스페이스에서는 배치 대신 키마다 디코딩한 값을 저장
+	_, err = runCommand(t, newTestClient(t, server, "raid-1"), string(exported), "import")
+	require.NoError(t, err)
+	requests = server.received()[2:]
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/api/ns/raid-1/data/boss/hp", requests[0].Path)
+	assert.Equal(t, "100", requests[0].Body)
+	assert.Equal(t, "/api/ns/raid-1/data/boss/icon", requests[1].Path)
+	assert.Equal(t, "\x00\x01", requests[1].Body)
+
+	_, err = runCommand(t, client, "{\"key\":\"/a\",\"value\":\"1\",\"encoding\":\"hex\"}\n", "import")
+	assert.ErrorContains(t, err, "entry 1: unknown encoding")
+}
+
+// TestTailCommand tail이 필터를 전달하고, 연결이 끊기면 마지막 이벤트 id로 다시 연결하는지 확인
+func TestTailCommand(t *testing.T) {
+	server := newStubServer(t)
+	client := newTestClient(t, server, "")
+	server.respond(http.MethodGet, "/events", func(w http.ResponseWriter, r *http.Request) {
+		if len(server.received()) > 1 {
+			// 다시 연결한 뒤에는 4xx로 tail 종료
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"stop"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: e-0\ndata: {\"event\": \"connected\", \"clientId\": \"c1\", \"seq\": 0, \"resumed\": false}\n\n")
+		fmt.Fprint(w, "id: e-1\ndata: {\"event\":\"put\",\"key\":\"boss/hp\",\"value\":\"90\",\"seq\":1,\"prev\":0}\n\n")
+	})
+
+	output, err := runCommand(t, client, "", "tail", "--prefix", "boss", "--prefix", "raid", "--ops", "put,delete")
+	assert.ErrorContains(t, err, "400 Bad Request: stop")
+	assert.Equal(t, "{\"event\":\"put\",\"key\":\"boss/hp\",\"value\":\"90\",\"seq\":1,\"prev\":0}\n", output)
+
+	requests := server.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "ops=put%2Cdelete&prefix=boss&prefix=raid", requests[0].Query)
+	assert.Empty(t, requests[0].LastEventID)
+	assert.Equal(t, requests[0].Query, requests[1].Query)
+	assert.Equal(t, "e-1", requests[1].LastEventID)
+}
+
+// TestEntryBytes 내보낸 값의 인코딩 디코딩
+func TestEntryBytes(t *testing.T) {
+	value, err := Entry{Value: "plain"}.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plain"), value)
+	value, err = Entry{Value: "AAE=", Encoding: "base64"}.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1}, value)
+	_, err = Entry{Value: "!", Encoding: "base64"}.Bytes()
+	assert.Error(t, err)
+	_, err = Entry{Value: "0001", Encoding: "hex"}.Bytes()
+	assert.ErrorContains(t, err, "unknown encoding")
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig 설정 파일 형식 (YAML 또는 JSON)
+//
+// 모든 항목은 선택 사항이며, 파일에 없는 항목은 명령줄 플래그의 값(지정하지 않았으면
+// 기본값)을 사용한다. 명령줄에서 지정한 플래그는 파일의 값보다 우선한다.
+type FileConfig struct {
+	Server     serverFileConfig  `json:"server"`
+	Redis      redisFileConfig   `json:"redis"`
+	P2P        p2pFileConfig     `json:"p2p"`
+	CRDT       crdtFileConfig    `json:"crdt"`
+	TLS        tlsFileConfig     `json:"tls"`
+	Log        logFileConfig     `json:"log"`
+	Auth       authFileConfig    `json:"auth"`
+	Hooks      hooksFileConfig   `json:"hooks"`
+	Limits     limitsFileConfig  `json:"limits"`
+	Game       gameFileConfig    `json:"game"`
+	Tracing    tracingFileConfig `json:"tracing"`
+	Namespaces []NamespaceConfig `json:"namespaces"`
+}
+
+// serverFileConfig HTTP와 gRPC 서버 설정
+type serverFileConfig struct {
+	Port        *int      `json:"port"`
+	GRPCPort    *int      `json:"grpcPort"`
+	CORSOrigins *[]string `json:"corsOrigins"`
+	SSEReplay   *int      `json:"sseReplay"`
+}
+
+// redisFileConfig Redis 연결 설정
+type redisFileConfig struct {
+	Addr     *string `json:"addr"`
+	Password *string `json:"password"`
+	DB       *int    `json:"db"`
+}
+
+// p2pFileConfig libp2p 네트워크 설정
+type p2pFileConfig struct {
+	Topic     *string   `json:"topic"`
+	Bootstrap *[]string `json:"bootstrap"`
+	Listen    *[]string `json:"listen"`
+	Announce  *[]string `json:"announce"`
+	Security  *[]string `json:"security"`
+	IPFSLite  *bool     `json:"ipfsLite"`
+}
+
+// crdtFileConfig CRDT 데이터스토어 설정
+type crdtFileConfig struct {
+	Namespace    *string   `json:"namespace"`
+	TTLInterval  *duration `json:"ttlInterval"`
+	MergeLogSize *int      `json:"mergeLogSize"`
+	JSONPrefixes *[]string `json:"jsonPrefixes"`
+}
+
+// tlsFileConfig HTTP TLS 설정
+type tlsFileConfig struct {
+	Cert            *string   `json:"cert"`
+	Key             *string   `json:"key"`
+	AutocertDomains *[]string `json:"autocertDomains"`
+	AutocertCache   *string   `json:"autocertCache"`
+}
+
+// logFileConfig 로깅 설정 (다시 읽기 가능)
+type logFileConfig struct {
+	// Level 로그 레벨 (debug, info, warn, error; Debug보다 우선)
+	Level *string `json:"level"`
+	Debug *bool   `json:"debug"`
+}
+
+// authFileConfig 인증 설정
+// 인증 설정 파일 경로(file) 또는 인증 설정 파일과 같은 형식의 항목을 직접 적는다.
+type authFileConfig struct {
+	File *string `json:"file"`
+	*AuthConfig
+}
+
+// hooksFileConfig 검증 규칙 설정
+// 검증 규칙 파일(file)의 규칙과 rules의 규칙이 모두 적용된다.
+type hooksFileConfig struct {
+	File  *string          `json:"file"`
+	Rules []ValidationRule `json:"rules"`
+}
+
+// limitsFileConfig 요청 제한 설정 (다시 읽기 가능)
+type limitsFileConfig struct {
+	Rate       *float64 `json:"rate"`
+	Burst      *int     `json:"burst"`
+	MaxBody    *int64   `json:"maxBody"`
+	TrustProxy *bool    `json:"trustProxy"`
+}
+
+// gameFileConfig 보스 레이드 게임 서버 설정
+type gameFileConfig struct {
+	Enabled   *bool   `json:"enabled"`
+	Port      *int    `json:"port"`
+	ClientDir *string `json:"clientDir"`
+}
+
+// tracingFileConfig OpenTelemetry 트레이싱 설정
+type tracingFileConfig struct {
+	Endpoint    *string  `json:"endpoint"`
+	Insecure    *bool    `json:"insecure"`
+	SampleRatio *float64 `json:"sampleRatio"`
+}
+
+// duration "10s" 같은 문자열로 적는 시간 간격
+type duration time.Duration
+
+// UnmarshalJSON 시간 간격 문자열 파싱
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("duration must be a string like \"10s\"")
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// LoadFileConfig 설정 파일 로드
+// YAML을 JSON으로 바꿔 해석하므로 인증, 검증 규칙, 네임스페이스 항목은 각 설정 파일과 같은 이름을 쓴다.
+func LoadFileConfig(path string) (*FileConfig, error) {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return nil, fmt.Errorf("TOML config files are not supported; use YAML or JSON")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	var config FileConfig
+	if raw == nil {
+		return &config, nil
+	}
+	converted, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return &config, nil
+}
+
+// validate 설정 파일 형식 확인
+func (f *FileConfig) validate() error {
+	if f.Log.Level != nil {
+		if _, err := logging.LevelFromString(*f.Log.Level); err != nil {
+			return fmt.Errorf("log.level: %w", err)
+		}
+	}
+	if f.Auth.File != nil && f.Auth.AuthConfig != nil {
+		return errors.New("auth: set either file or inline apiKeys/jwtSecret, not both")
+	}
+	for i, rule := range f.Hooks.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("hooks rule %d (%s): %w", i, rule.Prefix, err)
+		}
+	}
+	return nil
+}
+
+// override 명령줄에서 지정하지 않은 플래그의 설정을 파일의 값으로 바꿈
+func override[T any](setFlags map[string]bool, flagName string, dst *T, value *T) {
+	if value != nil && !setFlags[flagName] {
+		*dst = *value
+	}
+}
+
+// apply 설정 파일의 값을 서버 설정에 적용
+func (f *FileConfig) apply(c *Config) {
+	set := c.SetFlags
+
+	override(set, "port", &c.HTTPPort, f.Server.Port)
+	override(set, "grpc-port", &c.GRPCPort, f.Server.GRPCPort)
+	override(set, "cors-origins", &c.CORSOrigins, f.Server.CORSOrigins)
+	override(set, "sse-replay", &c.SSEReplaySize, f.Server.SSEReplay)
+
+	override(set, "redis", &c.RedisAddr, f.Redis.Addr)
+	override(set, "redis-password", &c.RedisPassword, f.Redis.Password)
+	override(set, "redis-db", &c.RedisDB, f.Redis.DB)
+
+	override(set, "topic", &c.PubSubTopic, f.P2P.Topic)
+	if f.P2P.Bootstrap != nil {
+		bootstrap := strings.Join(*f.P2P.Bootstrap, ",")
+		override(set, "bootstrap", &c.BootstrapPeers, &bootstrap)
+	}
+	override(set, "listen", &c.ListenAddrs, f.P2P.Listen)
+	override(set, "announce", &c.AnnounceAddrs, f.P2P.Announce)
+	override(set, "p2p-security", &c.P2PSecurity, f.P2P.Security)
+	override(set, "ipfs-lite", &c.UseIPFSLite, f.P2P.IPFSLite)
+
+	override(set, "namespace", &c.DataNamespace, f.CRDT.Namespace)
+	override(set, "ttl-interval", &c.TTLInterval, (*time.Duration)(f.CRDT.TTLInterval))
+	override(set, "merge-log-size", &c.MergeLogSize, f.CRDT.MergeLogSize)
+	override(set, "json-prefixes", &c.JSONPrefixes, f.CRDT.JSONPrefixes)
+
+	override(set, "tls-cert", &c.TLSCertFile, f.TLS.Cert)
+	override(set, "tls-key", &c.TLSKeyFile, f.TLS.Key)
+	override(set, "autocert-domains", &c.AutocertDomains, f.TLS.AutocertDomains)
+	override(set, "autocert-cache", &c.AutocertCacheDir, f.TLS.AutocertCache)
+
+	override(set, "log-level", &c.LogLevel, f.Log.Level)
+	override(set, "debug", &c.Debug, f.Log.Debug)
+
+	override(set, "auth-config", &c.AuthConfigPath, f.Auth.File)
+	c.Auth = f.Auth.AuthConfig
+	override(set, "hooks-config", &c.HookRulesPath, f.Hooks.File)
+	c.HookRules = f.Hooks.Rules
+
+	override(set, "rate-limit", &c.RateLimit, f.Limits.Rate)
+	override(set, "rate-burst", &c.RateBurst, f.Limits.Burst)
+	override(set, "max-body", &c.MaxBodyBytes, f.Limits.MaxBody)
+	override(set, "trust-proxy", &c.TrustProxy, f.Limits.TrustProxy)
+
+	override(set, "enable-game", &c.EnableGame, f.Game.Enabled)
+	override(set, "game-port", &c.GamePort, f.Game.Port)
+	override(set, "client-dir", &c.ClientDir, f.Game.ClientDir)
+
+	override(set, "otlp-endpoint", &c.TracingEndpoint, f.Tracing.Endpoint)
+	override(set, "otlp-insecure", &c.TracingInsecure, f.Tracing.Insecure)
+	override(set, "trace-sample", &c.TracingSampleRatio, f.Tracing.SampleRatio)
+
+	c.Namespaces = f.Namespaces
+}
+
+// withFile 명령줄 설정에 설정 파일을 적용한 설정
+// 설정 파일이 없으면 그대로 반환한다.
+func (c Config) withFile() (Config, error) {
+	if c.ConfigFile == "" {
+		return c, nil
+	}
+	file, err := LoadFileConfig(c.ConfigFile)
+	if err != nil {
+		return c, err
+	}
+	file.apply(&c)
+	return c, nil
+}
+
+// logLevel 적용할 로그 레벨
+func (c Config) logLevel() string {
+	if c.LogLevel != "" {
+		return c.LogLevel
+	}
+	if c.Debug {
+		return "debug"
+	}
+	return "info"
+}
+
+// reloadableSettings 설정 다시 읽기로 실행 중에 바뀌는 설정
+var reloadableSettings = map[string]bool{
+	"LogLevel":     true,
+	"Debug":        true,
+	"RateLimit":    true,
+	"RateBurst":    true,
+	"MaxBodyBytes": true,
+	"TrustProxy":   true,
+	"Namespaces":   true,
+}
+
+// restartRequired 실행 중에 적용할 수 없는 설정 중 바뀐 항목
+func restartRequired(running, reloaded Config) []string {
+	var changed []string
+	a, b := reflect.ValueOf(running), reflect.ValueOf(reloaded)
+	for i := 0; i < a.NumField(); i++ {
+		name := a.Type().Field(i).Name
+		if reloadableSettings[name] {
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Reload 설정 파일을 다시 읽어 로그 레벨과 요청 제한을 적용하고 새 네임스페이스를 만듦
+// 그 밖의 설정이 바뀌었으면 재시작이 필요하다는 경고만 남긴다.
+func (s *Server) Reload() error {
+	if s.config.ConfigFile == "" {
+		return errors.New("no config file to reload (start the server with --config)")
+	}
+	config, err := s.flagConfig.withFile()
+	if err != nil {
+		return err
+	}
+
+	if err := logging.SetLogLevel("*", config.logLevel()); err != nil {
+		return fmt.Errorf("failed to set log level: %w", err)
+	}
+	s.setRequestLimits(config)
+	s.ensureNamespaces(config.Namespaces)
+
+	logger.Infof("Reloaded %s (log level %s, rate limit %g/s, max body %d bytes)",
+		s.config.ConfigFile, config.logLevel(), config.RateLimit, config.MaxBodyBytes)
+	if changed := restartRequired(s.config, config); len(changed) > 0 {
+		logger.Warnf("Changed settings that require a restart: %s", strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+// runReload SIGHUP을 받을 때마다 설정 파일 다시 읽기
+func (s *Server) runReload() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-hup:
+			if err := s.Reload(); err != nil {
+				logger.Errorf("Failed to reload config: %v", err)
+			}
+		}
+	}
+}
+
+// ensureNamespaces 설정 파일의 데이터 네임스페이스 중 없는 것을 만듦
+// 이미 있는 네임스페이스의 설정은 바꾸지 않으며, 파일에서 빠진 네임스페이스도 삭제하지 않는다.
+func (s *Server) ensureNamespaces(configs []NamespaceConfig) {
+	for _, config := range configs {
+		if s.namespaces.Get(config.Name) != nil {
+			continue
+		}
+		if _, err := s.namespaces.Create(s.ctx, config); err != nil {
+			if !errors.Is(err, ErrNamespaceExists) {
+				logger.Errorf("Failed to create namespace %s from config file: %v", config.Name, err)
+			}
+			continue
+		}
+		logger.Infof("Namespace %s created from config file", config.Name)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// writeConfigFile 테스트 디렉터리에 설정 파일 작성
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// TestLoadFileConfig YAML과 JSON 설정 파일을 읽어 명령줄에서 지정하지 않은 설정에만 적용하는지 확인
+func TestLoadFileConfig(t *testing.T) {
+	path := writeConfigFile(t, "crdtserver.yaml", `
+server:
+  port: 9090
+  corsOrigins: [https://game.example]
+redis:
+  addr: redis.internal:6379
+p2p:
+  bootstrap:
+    - /ip4/10.0.0.1/tcp/4001/p2p/peer-a
+    - /ip4/10.0.0.2/tcp/4001/p2p/peer-b
+  listen: [/ip4/0.0.0.0/tcp/4001]
+crdt:
+  ttlInterval: 30s
+  jsonPrefixes: [/players]
+log:
+  level: warn
+auth:
+  apiKeys:
+    - key: game-key
+      name: game
+      permissions: {"/": write}
+hooks:
+  rules:
+    - prefix: /players
+      json: true
+limits:
+  rate: 5
+  maxBody: 4096
+namespaces:
+  - name: raid-1
+`)
+	file, err := LoadFileConfig(path)
+	require.NoError(t, err)
+
+	config := Config{HTTPPort: 8080, RedisAddr: "localhost:6379", SetFlags: map[string]bool{"port": true}}
+	file.apply(&config)
+	assert.Equal(t, 8080, config.HTTPPort, "command line flag wins")
+	assert.Equal(t, []string{"https://game.example"}, config.CORSOrigins)
+	assert.Equal(t, "redis.internal:6379", config.RedisAddr)
+	assert.Equal(t, "/ip4/10.0.0.1/tcp/4001/p2p/peer-a,/ip4/10.0.0.2/tcp/4001/p2p/peer-b", config.BootstrapPeers)
+	assert.Equal(t, []string{"/ip4/0.0.0.0/tcp/4001"}, config.ListenAddrs)
+	assert.Equal(t, 30*time.Second, config.TTLInterval)
+	assert.Equal(t, []string{"/players"}, config.JSONPrefixes)
+	assert.Equal(t, "warn", config.logLevel())
+	require.NotNil(t, config.Auth)
+	assert.Equal(t, "game", config.Auth.APIKeys[0].Name)
+	require.Len(t, config.HookRules, 1)
+	assert.True(t, config.HookRules[0].JSON)
+	assert.Equal(t, 5.0, config.RateLimit)
+	assert.Equal(t, int64(4096), config.MaxBodyBytes)
+	assert.Equal(t, []NamespaceConfig{{Name: "raid-1"}}, config.Namespaces)
+
+	// JSON도 같은 형식
+	path = writeConfigFile(t, "crdtserver.json", `{"server": {"grpcPort": 9091}, "log": {"debug": true}}`)
+	file, err = LoadFileConfig(path)
+	require.NoError(t, err)
+	config = Config{}
+	file.apply(&config)
+	assert.Equal(t, 9091, config.GRPCPort)
+	assert.Equal(t, "debug", config.logLevel())
+
+	// 빈 파일은 아무것도 바꾸지 않음
+	file, err = LoadFileConfig(writeConfigFile(t, "empty.yaml", ""))
+	require.NoError(t, err)
+	config = Config{HTTPPort: 8080}
+	file.apply(&config)
+	assert.Equal(t, 8080, config.HTTPPort)
+}
+
+// TestLoadFileConfigErrors 잘못된 설정 파일을 거부하는지 확인
+func TestLoadFileConfigErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown field":  "server:\n  prot: 9090\n",
+		"wrong type":     "server:\n  port: http\n",
+		"bad duration":   "crdt:\n  ttlInterval: 10\n",
+		"bad log level":  "log:\n  level: loud\n",
+		"auth conflict":  "auth:\n  file: auth.json\n  jwtSecret: secret\n",
+		"bad hook rule":  "hooks:\n  rules:\n    - prefix: /players\n      maxBytes: -1\n",
+		"invalid syntax": "server: [\n",
+	} {
+		_, err := LoadFileConfig(writeConfigFile(t, "crdtserver.yaml", content))
+		assert.Error(t, err, name)
+	}
+
+	_, err := LoadFileConfig(writeConfigFile(t, "crdtserver.toml", "[server]\nport = 9090\n"))
+	assert.ErrorContains(t, err, "TOML config files are not supported")
+	_, err = LoadFileConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config file")
+}
+
+// TestRestartRequired 실행 중에 바꿀 수 없는 설정만 재시작 필요로 보고하는지 확인
+func TestRestartRequired(t *testing.T) {
+	running := Config{HTTPPort: 8080, RateLimit: 5, LogLevel: "info"}
+	reloaded := Config{HTTPPort: 9090, RateLimit: 10, LogLevel: "debug", RedisAddr: "redis:6379"}
+	assert.Equal(t, []string{"HTTPPort", "RedisAddr"}, restartRequired(running, reloaded))
+	assert.Empty(t, restartRequired(running, running))
+}
+
+// newTestReloadServer 설정 파일로 시작한 것처럼 설정한 서버
+func newTestReloadServer(t *testing.T, content string) (*Server, string) {
+	t.Helper()
+	path := writeConfigFile(t, "crdtserver.yaml", content)
+	flagConfig := Config{ConfigFile: path, SetFlags: map[string]bool{}}
+	config, err := flagConfig.withFile()
+	require.NoError(t, err)
+
+	s := newTestServer(t, config)
+	s.flagConfig = flagConfig
+	t.Cleanup(func() { logging.SetLogLevel("*", "info") })
+	return s, path
+}
+
+// putStatus 데이터 API로 값을 저장하고 상태 코드 반환
+func putStatus(s *Server, key, value string) int {
+	return serveTest(s, http.MethodPut, "/api/data/"+key, value).Code
+}
+
+// TestReload 설정 파일을 다시 읽으면 요청 제한과 로그 레벨이 바뀌는지 확인
+func TestReload(t *testing.T) {
+	s, path := newTestReloadServer(t, "limits:\n  maxBody: 8\n")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, putStatus(s, "boss/name", "Ancient Dragon"))
+
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  maxBody: 64\n  rate: 1\n  burst: 1\nlog:\n  level: debug\nserver:\n  port: 9999\n"), 0o600))
+	require.NoError(t, s.Reload())
+	assert.Equal(t, http.StatusOK, putStatus(s, "boss/name", "Ancient Dragon"))
+	assert.Equal(t, http.StatusTooManyRequests, putStatus(s, "boss/name", "Ancient Dragon"))
+	assert.True(t, logger.Desugar().Core().Enabled(zapcore.DebugLevel))
+
+	// 잘못된 파일은 적용하지 않음
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  maxBody: many\n"), 0o600))
+	assert.Error(t, s.Reload())
+	assert.Equal(t, int64(64), s.requestLimits().maxBodyBytes)
+
+	// 설정 파일 없이 시작하면 다시 읽을 수 없음
+	plain := newTestServer(t, Config{})
+	assert.ErrorContains(t, plain.Reload(), "no config file to reload")
+}
+
+// TestRunReload SIGHUP을 받으면 설정 파일을 다시 읽는지 확인
+func TestRunReload(t *testing.T) {
+	s, path := newTestReloadServer(t, "limits:\n  maxBody: 8\n")
+
+	// 서버가 신호를 받기 전에 보낸 SIGHUP으로 테스트 프로세스가 종료되지 않게 함
+	hup := make(chan os.Signal, 16)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go s.runReload()
+
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  maxBody: 64\n"), 0o600))
+	require.Eventually(t, func() bool {
+		assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+		time.Sleep(10 * time.Millisecond)
+		return s.requestLimits().maxBodyBytes == 64
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, http.StatusOK, putStatus(s, "boss/name", strings.Repeat("x", 32)))
+}
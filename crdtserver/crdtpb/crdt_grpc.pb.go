@@ -0,0 +1,295 @@
+// crdtserver gRPC API
+//
+// HTTP 데이터 API(/api/data, /events)와 같은 기본 데이터 네임스페이스를 사용한다.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: crdt.proto
+
+package crdtpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CRDTStore_Get_FullMethodName         = "/crdtserver.v1.CRDTStore/Get"
+	CRDTStore_Put_FullMethodName         = "/crdtserver.v1.CRDTStore/Put"
+	CRDTStore_Delete_FullMethodName      = "/crdtserver.v1.CRDTStore/Delete"
+	CRDTStore_List_FullMethodName        = "/crdtserver.v1.CRDTStore/List"
+	CRDTStore_WatchPrefix_FullMethodName = "/crdtserver.v1.CRDTStore/WatchPrefix"
+)
+
+// CRDTStoreClient is the client API for CRDTStore service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CRDTStore CRDT 키-값 저장소
+type CRDTStoreClient interface {
+	// Get 키의 값 조회 (키가 없거나 만료되었으면 NOT_FOUND)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// Put 키에 값 저장
+	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
+	// Delete 키 삭제
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// List 접두사로 시작하는 키와 값 조회
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// WatchPrefix 접두사로 시작하는 키의 변경 이벤트 스트리밍
+	WatchPrefix(ctx context.Context, in *WatchPrefixRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
+}
+
+type cRDTStoreClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCRDTStoreClient(cc grpc.ClientConnInterface) CRDTStoreClient {
+	return &cRDTStoreClient{cc}
+}
+
+func (c *cRDTStoreClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, CRDTStore_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTStoreClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutResponse)
+	err := c.cc.Invoke(ctx, CRDTStore_Put_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTStoreClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, CRDTStore_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTStoreClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, CRDTStore_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTStoreClient) WatchPrefix(ctx context.Context, in *WatchPrefixRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CRDTStore_ServiceDesc.Streams[0], CRDTStore_WatchPrefix_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchPrefixRequest, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CRDTStore_WatchPrefixClient = grpc.ServerStreamingClient[WatchEvent]
+
+// CRDTStoreServer is the server API for CRDTStore service.
+// All implementations must embed UnimplementedCRDTStoreServer
+// for forward compatibility.
+//
+// CRDTStore CRDT 키-값 저장소
+type CRDTStoreServer interface {
+	// Get 키의 값 조회 (키가 없거나 만료되었으면 NOT_FOUND)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// Put 키에 값 저장
+	Put(context.Context, *PutRequest) (*PutResponse, error)
+	// Delete 키 삭제
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// List 접두사로 시작하는 키와 값 조회
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// WatchPrefix 접두사로 시작하는 키의 변경 이벤트 스트리밍
+	WatchPrefix(*WatchPrefixRequest, grpc.ServerStreamingServer[WatchEvent]) error
+	mustEmbedUnimplementedCRDTStoreServer()
+}
+
+// UnimplementedCRDTStoreServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCRDTStoreServer struct{}
+
+func (UnimplementedCRDTStoreServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedCRDTStoreServer) Put(context.Context, *PutRequest) (*PutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (UnimplementedCRDTStoreServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedCRDTStoreServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedCRDTStoreServer) WatchPrefix(*WatchPrefixRequest, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPrefix not implemented")
+}
+func (UnimplementedCRDTStoreServer) mustEmbedUnimplementedCRDTStoreServer() {}
+func (UnimplementedCRDTStoreServer) testEmbeddedByValue()                   {}
+
+// UnsafeCRDTStoreServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CRDTStoreServer will
+// result in compilation errors.
+type UnsafeCRDTStoreServer interface {
+	mustEmbedUnimplementedCRDTStoreServer()
+}
+
+func RegisterCRDTStoreServer(s grpc.ServiceRegistrar, srv CRDTStoreServer) {
+	// If the following call pancis, it indicates UnimplementedCRDTStoreServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CRDTStore_ServiceDesc, srv)
+}
+
+func _CRDTStore_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CRDTStoreServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CRDTStore_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CRDTStoreServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CRDTStore_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CRDTStoreServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CRDTStore_Put_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CRDTStoreServer).Put(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CRDTStore_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CRDTStoreServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CRDTStore_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CRDTStoreServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CRDTStore_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CRDTStoreServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CRDTStore_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CRDTStoreServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CRDTStore_WatchPrefix_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPrefixRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CRDTStoreServer).WatchPrefix(m, &grpc.GenericServerStream[WatchPrefixRequest, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CRDTStore_WatchPrefixServer = grpc.ServerStreamingServer[WatchEvent]
+
+// CRDTStore_ServiceDesc is the grpc.ServiceDesc for CRDTStore service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CRDTStore_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "crdtserver.v1.CRDTStore",
+	HandlerType: (*CRDTStoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _CRDTStore_Get_Handler,
+		},
+		{
+			MethodName: "Put",
+			Handler:    _CRDTStore_Put_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _CRDTStore_Delete_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _CRDTStore_List_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPrefix",
+			Handler:       _CRDTStore_WatchPrefix_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "crdt.proto",
+}
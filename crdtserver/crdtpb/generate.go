@@ -0,0 +1,7 @@
+// Package crdtpb crdtserver gRPC API의 메시지와 서비스 정의
+//
+// crdt.proto를 수정한 뒤 protoc, protoc-gen-go, protoc-gen-go-grpc를 설치하고
+// 이 디렉터리에서 go generate를 실행하여 코드를 다시 생성한다.
+package crdtpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative crdt.proto
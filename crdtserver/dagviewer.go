@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	dshelp "github.com/ipfs/boxo/datastore/dshelp"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	pb "github.com/ipfs/go-ds-crdt/pb"
+	format "github.com/ipfs/go-ipld-format"
+	dag "github.com/ipfs/go-merkledag"
+	"google.golang.org/protobuf/proto"
+)
+
+// dagFetchTimeout DAG 노드 조회 제한 시간
+// 로컬에 없는 노드는 IPFS-Lite를 통해 다른 피어에서 가져올 수 있으므로 시간을 제한한다.
+const dagFetchTimeout = 5 * time.Second
+
+// processedBlocksNs go-ds-crdt가 처리한 블록을 기록하는 네임스페이스
+// (go-ds-crdt 내부 키 구조: <namespace>/b/<multihash>)
+const processedBlocksNs = "b"
+
+// dagLinkInfo DAG 노드의 링크 정보
+type dagLinkInfo struct {
+	CID       string `json:"cid"`
+	Local     bool   `json:"local"`
+	Processed bool   `json:"processed"`
+}
+
+// dagElementInfo 델타의 요소 정보
+type dagElementInfo struct {
+	Key      string `json:"key"`
+	ID       string `json:"id"`
+	Value    string `json:"value,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Size     int    `json:"size"`
+}
+
+// processedBlockKey 블록이 처리되었는지 기록하는 키
+func (s *Server) processedBlockKey(c cid.Cid) ds.Key {
+	return ds.NewKey(s.config.DataNamespace).ChildString(processedBlocksNs).ChildString(dshelp.MultihashToDsKey(c.Hash()).String())
+}
+
+// blockState 블록이 로컬 블록스토어에 있는지, CRDT에 병합되었는지 확인
+func (s *Server) blockState(ctx context.Context, c cid.Cid) (local, processed bool) {
+	local, _ = s.bstore.Has(ctx, c)
+	processed, _ = s.store.Has(ctx, s.processedBlockKey(c))
+	return local, processed
+}
+
+// getDelta DAG 노드와 노드에 담긴 CRDT 델타 조회
+func (s *Server) getDelta(ctx context.Context, c cid.Cid) (format.Node, *pb.Delta, error) {
+	ctx, cancel := context.WithTimeout(ctx, dagFetchTimeout)
+	defer cancel()
+
+	node, err := s.dagService.Get(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	delta, err := decodeDelta(node)
+	return node, delta, err
+}
+
+// decodeDelta DAG 노드에 담긴 CRDT 델타 디코딩
+// DAG 서비스마다 노드 타입(go-merkledag, boxo)이 다르므로 원시 블록에서 디코딩한다.
+func decodeDelta(node format.Node) (*pb.Delta, error) {
+	protoNode, err := dag.DecodeProtobuf(node.RawData())
+	if err != nil {
+		return nil, fmt.Errorf("node is not a ProtoNode: %w", err)
+	}
+	delta := &pb.Delta{}
+	if err := proto.Unmarshal(protoNode.Data(), delta); err != nil {
+		return nil, fmt.Errorf("failed to decode delta: %w", err)
+	}
+	return delta, nil
+}
+
+// handleCRDTViewerHeads 현재 heads와 복제 상태 조회
+//
+// 로컬 heads, DAG 높이, 대기 중인 DAG 작업 수와 함께 피어와 주고받은 브로드캐스트
+// heads를 반환한다. 피어가 브로드캐스트한 head 중 아직 병합하지 못한 블록은 pending에
+// 표시되므로 복제가 멈춘 위치를 찾을 수 있다.
+func (s *Server) handleCRDTViewerHeads(w http.ResponseWriter, r *http.Request) {
+	stats := s.crdt.InternalStats(s.ctx)
+
+	heads := make([]map[string]interface{}, 0, len(stats.Heads))
+	for _, head := range stats.Heads {
+		info := map[string]interface{}{"cid": head.String()}
+		if _, delta, err := s.getDelta(s.ctx, head); err == nil {
+			info["priority"] = delta.Priority
+		}
+		heads = append(heads, info)
+	}
+
+	broadcast := map[string]interface{}{}
+	if sent := s.broadcaster.Sent(); sent != nil {
+		broadcast["sent"] = map[string]interface{}{
+			"heads": cidStrings(sent.Heads),
+			"at":    sent.At,
+			"count": sent.Count,
+		}
+	}
+	received := []map[string]interface{}{}
+	for _, record := range s.broadcaster.Received() {
+		pending := []string{}
+		for _, head := range record.Heads {
+			if _, processed := s.blockState(s.ctx, head); !processed {
+				pending = append(pending, head.String())
+			}
+		}
+		received = append(received, map[string]interface{}{
+			"peer":    record.Peer,
+			"heads":   cidStrings(record.Heads),
+			"pending": pending,
+			"at":      record.At,
+			"count":   record.Count,
+		})
+	}
+	broadcast["received"] = received
+
+	response := map[string]interface{}{
+		"heads":      heads,
+		"maxHeight":  stats.MaxHeight,
+		"queuedJobs": stats.QueuedJobs,
+		"dirty":      s.crdt.IsDirty(s.ctx),
+		"broadcast":  broadcast,
+	}
+
+	// JSON 응답 반환
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleCRDTViewerDAG DAG 노드와 링크 조회
+//
+// 노드가 로컬 블록스토어에 있는지, CRDT에 병합되었는지와 함께 델타의 요소와 링크를
+// 반환한다. 링크마다 같은 정보를 표시하므로 링크를 따라가며 누락된 블록을 찾을 수 있다.
+func (s *Server) handleCRDTViewerDAG(w http.ResponseWriter, r *http.Request, cidStr string) {
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid cid: "+err.Error())
+		return
+	}
+
+	local, processed := s.blockState(s.ctx, c)
+	response := map[string]interface{}{
+		"cid":       c.String(),
+		"local":     local,
+		"processed": processed,
+	}
+
+	isHead := false
+	for _, head := range s.crdt.InternalStats(s.ctx).Heads {
+		if head.Equals(c) {
+			isHead = true
+			break
+		}
+	}
+	response["head"] = isHead
+
+	node, delta, err := s.getDelta(s.ctx, c)
+	if err != nil {
+		// 노드를 가져올 수 없어도 로컬 상태는 반환
+		response["error"] = err.Error()
+		status := http.StatusInternalServerError
+		if !local || format.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	links := make([]dagLinkInfo, 0, len(node.Links()))
+	for _, link := range node.Links() {
+		linkLocal, linkProcessed := s.blockState(s.ctx, link.Cid)
+		links = append(links, dagLinkInfo{
+			CID:       link.Cid.String(),
+			Local:     linkLocal,
+			Processed: linkProcessed,
+		})
+	}
+
+	response["size"] = len(node.RawData())
+	response["priority"] = delta.Priority
+	response["links"] = links
+	response["elements"] = dagElements(delta.Elements)
+	response["tombstones"] = dagElements(delta.Tombstones)
+
+	// JSON 응답 반환
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// dagElements 델타 요소를 응답 형식으로 변환
+func dagElements(elements []*pb.Element) []dagElementInfo {
+	infos := make([]dagElementInfo, 0, len(elements))
+	for _, element := range elements {
+		info := dagElementInfo{
+			Key:  element.Key,
+			ID:   element.Id,
+			Size: len(element.Value),
+		}
+		// 값이 유효한 UTF-8 문자열이 아니면 base64로 인코딩
+		if utf8.Valid(element.Value) {
+			info.Value = string(element.Value)
+		} else {
+			info.Value = base64.StdEncoding.EncodeToString(element.Value)
+			info.Encoding = "base64"
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// cidStrings CID 목록을 문자열 목록으로 변환
+func cidStrings(cids []cid.Cid) []string {
+	strs := make([]string, 0, len(cids))
+	for _, c := range cids {
+		strs = append(strs, c.String())
+	}
+	return strs
+}
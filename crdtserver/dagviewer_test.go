@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// viewerHeads heads 엔드포인트 응답
+type viewerHeads struct {
+	Heads []struct {
+		CID      string `json:"cid"`
+		Priority uint64 `json:"priority"`
+	} `json:"heads"`
+	MaxHeight uint64 `json:"maxHeight"`
+	Broadcast struct {
+		Sent *struct {
+			Heads []string `json:"heads"`
+			Count int      `json:"count"`
+		} `json:"sent"`
+		Received []struct {
+			Peer    string   `json:"peer"`
+			Heads   []string `json:"heads"`
+			Pending []string `json:"pending"`
+			Count   int      `json:"count"`
+		} `json:"received"`
+	} `json:"broadcast"`
+}
+
+// injectHeads 다른 피어가 브로드캐스트한 것처럼 heads를 전달하고 병합이 끝날 때까지 대기
+func injectHeads(t *testing.T, s *Server, from string, heads ...string) {
+	t.Helper()
+	cids := make([]cid.Cid, 0, len(heads))
+	for _, head := range heads {
+		c, err := cid.Decode(head)
+		require.NoError(t, err)
+		cids = append(cids, c)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done, err := s.broadcaster.Inject(ctx, from, cids)
+	require.NoError(t, err)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for injected heads")
+	}
+}
+
+// TestCRDTViewerHeads 로컬 쓰기로 만든 head와 다른 피어에서 받은 heads의 병합 상태 확인
+func TestCRDTViewerHeads(t *testing.T) {
+	bstore := blockstore.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	a := newTestReplica(t, Config{}, newTestBroadcaster(t), bstore)
+	b := newTestReplica(t, Config{}, newTestBroadcaster(t), bstore)
+
+	putData(t, a, "boss/hp", "100")
+	var heads viewerHeads
+	require.Equal(t, http.StatusOK, getJSON(t, a, "/api/crdt-viewer/heads", &heads))
+	require.Len(t, heads.Heads, 1)
+	head := heads.Heads[0].CID
+	assert.Equal(t, uint64(1), heads.Heads[0].Priority)
+	assert.Equal(t, uint64(1), heads.MaxHeight)
+	require.NotNil(t, heads.Broadcast.Sent)
+	assert.Equal(t, []string{head}, heads.Broadcast.Sent.Heads)
+	assert.Equal(t, 1, heads.Broadcast.Sent.Count)
+	assert.Empty(t, heads.Broadcast.Received)
+
+	// 블록이 있는 head는 병합되고, 가져올 수 없는 head는 pending에 남음
+	missing := blocks.NewBlock([]byte("missing")).Cid().String()
+	injectHeads(t, b, "peer-a", head, missing)
+
+	heads = viewerHeads{}
+	require.Equal(t, http.StatusOK, getJSON(t, b, "/api/crdt-viewer/heads", &heads))
+	require.Len(t, heads.Heads, 1)
+	assert.Equal(t, head, heads.Heads[0].CID)
+	assert.Nil(t, heads.Broadcast.Sent)
+	require.Len(t, heads.Broadcast.Received, 1)
+	received := heads.Broadcast.Received[0]
+	assert.Equal(t, "peer-a", received.Peer)
+	assert.Equal(t, []string{head, missing}, received.Heads)
+	assert.Equal(t, []string{missing}, received.Pending)
+
+	value, err := b.getValue(context.Background(), b.data, ds.NewKey("/boss/hp"))
+	require.NoError(t, err)
+	assert.Equal(t, "100", string(value))
+}
+
+// TestCRDTViewerDAG DAG 노드의 델타, 링크와 로컬 상태 조회
+func TestCRDTViewerDAG(t *testing.T) {
+	s := newTestReplica(t, Config{}, newTestBroadcaster(t), nil)
+	putData(t, s, "boss/hp", "100")
+	var heads viewerHeads
+	getJSON(t, s, "/api/crdt-viewer/heads", &heads)
+	first := heads.Heads[0].CID
+
+	w := serveTest(s, http.MethodDelete, "/api/data/boss/hp", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	heads = viewerHeads{}
+	getJSON(t, s, "/api/crdt-viewer/heads", &heads)
+	second := heads.Heads[0].CID
+
+	var node struct {
+		CID        string           `json:"cid"`
+		Local      bool             `json:"local"`
+		Processed  bool             `json:"processed"`
+		Head       bool             `json:"head"`
+		Priority   uint64           `json:"priority"`
+		Links      []dagLinkInfo    `json:"links"`
+		Elements   []dagElementInfo `json:"elements"`
+		Tombstones []dagElementInfo `json:"tombstones"`
+	}
+	require.Equal(t, http.StatusOK, getJSON(t, s, "/api/crdt-viewer/dag/"+second, &node))
+	assert.True(t, node.Local)
+	assert.True(t, node.Processed)
+	assert.True(t, node.Head)
+	assert.Equal(t, uint64(2), node.Priority)
+	assert.Equal(t, []dagLinkInfo{{CID: first, Local: true, Processed: true}}, node.Links)
+	assert.Empty(t, node.Elements)
+	require.Len(t, node.Tombstones, 1)
+	assert.Equal(t, "/boss/hp", node.Tombstones[0].Key)
+
+	require.Equal(t, http.StatusOK, getJSON(t, s, "/api/crdt-viewer/dag/"+first, &node))
+	assert.False(t, node.Head)
+	require.Len(t, node.Elements, 1)
+	assert.Equal(t, dagElementInfo{Key: "/boss/hp", ID: node.Elements[0].ID, Value: "100", Size: 3}, node.Elements[0])
+
+	// 로컬에 없는 블록은 404, 잘못된 CID는 400
+	missing := blocks.NewBlock([]byte("missing")).Cid().String()
+	var notFound map[string]interface{}
+	assert.Equal(t, http.StatusNotFound, getJSON(t, s, "/api/crdt-viewer/dag/"+missing, &notFound))
+	assert.Equal(t, false, notFound["local"])
+	assert.Equal(t, http.StatusBadRequest, serveTest(s, http.MethodGet, "/api/crdt-viewer/dag/not-a-cid", "").Code)
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tictactoe/luvjson/common"
+	luvcrdt "tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+
+	ds "github.com/ipfs/go-datastore"
+	dsquery "github.com/ipfs/go-datastore/query"
+)
+
+// docsPrefix CRDT 문서가 저장되는 키 접두사
+const docsPrefix = "/docs"
+
+// ErrDocumentNotFound 문서가 없을 때 반환되는 에러
+var ErrDocumentNotFound = fmt.Errorf("document not found")
+
+// ErrPatchConflict 같은 ID로 내용이 다른 패치가 이미 저장되어 있을 때 반환되는 에러
+var ErrPatchConflict = fmt.Errorf("patch id already used by a different patch")
+
+// DocumentStore luvjson CRDT 문서를 CRDT 데이터스토어에 저장하는 저장소
+//
+// 문서는 불투명한 바이트가 아니라 패치 로그로 저장된다. 패치마다 고유한 키
+// (/docs/<id>/patches/<sid>/<cnt>)를 사용하므로 여러 노드에서 동시에 받은 패치도
+// go-ds-crdt의 LWW 규칙에 의해 유실되지 않고 모든 노드로 복제되며,
+// 각 노드는 패치를 재생해서 같은 문서로 병합한다.
+type DocumentStore struct {
+	store ds.Datastore
+
+	mu   sync.Mutex
+	docs map[string]*storedDocument
+}
+
+// storedDocument 패치를 재생해 만든 문서
+type storedDocument struct {
+	mu sync.Mutex
+
+	// doc 재생된 문서
+	doc *luvcrdt.Document
+
+	// applied 문서에 적용된 패치 키
+	applied map[string]bool
+
+	// pending 의존하는 패치가 아직 없어 적용하지 못한 패치
+	pending map[string]*crdtpatch.Patch
+}
+
+// NewDocumentStore 새 문서 저장소 생성
+func NewDocumentStore(store ds.Datastore) *DocumentStore {
+	return &DocumentStore{
+		store: store,
+		docs:  make(map[string]*storedDocument),
+	}
+}
+
+// validDocumentID 문서 ID가 유효한지 확인
+func validDocumentID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, "/?#")
+}
+
+// patchesKey 문서의 패치가 저장되는 키
+func patchesKey(id string) ds.Key {
+	return ds.NewKey(docsPrefix).ChildString(id).ChildString("patches")
+}
+
+// patchKey 패치가 저장되는 키
+func patchKey(id string, patchID common.LogicalTimestamp) ds.Key {
+	return patchesKey(id).ChildString(patchID.SID.String()).ChildString(strconv.FormatUint(patchID.Counter, 10))
+}
+
+// ApplyPatch 패치를 검증해 저장하고 문서에 병합
+//
+// 패치는 저장하기 전에 현재 문서의 복사본에 적용해 본다. 적용할 수 없는 패치와
+// 같은 ID로 이미 저장된 다른 패치는 거부한다. 봉투의 의존 패치가 아직 이 노드에
+// 없으면 패치를 저장하고 의존 패치가 도착할 때까지 보류하며, 보류된 의존성을 반환한다.
+func (s *DocumentStore) ApplyPatch(ctx context.Context, id string, data []byte) (*crdtpatch.Patch, []common.LogicalTimestamp, error) {
+	if !validDocumentID(id) {
+		return nil, nil, fmt.Errorf("invalid document id: %q", id)
+	}
+
+	// 패치 검증
+	patch := &crdtpatch.Patch{}
+	if err := json.Unmarshal(data, patch); err != nil {
+		return nil, nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	if len(patch.Operations()) == 0 {
+		return nil, nil, fmt.Errorf("invalid patch: no operations")
+	}
+
+	// 정규화된 형식으로 저장
+	encoded, err := json.Marshal(patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	entry := s.document(id)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if err := s.load(ctx, id, entry); err != nil {
+		return nil, nil, err
+	}
+	entry.applyPending()
+
+	// 같은 ID의 패치는 내용이 같을 때만 다시 받음
+	key := patchKey(id, patch.ID())
+	stored, err := s.store.Get(ctx, key)
+	switch {
+	case err == nil:
+		if !bytes.Equal(stored, encoded) {
+			return nil, nil, ErrPatchConflict
+		}
+		if pending := entry.pending[key.String()]; pending != nil {
+			missing, _ := pending.MissingDependencies(entry.doc)
+			return patch, missing, nil
+		}
+		return patch, nil, nil
+	case err != ds.ErrNotFound:
+		return nil, nil, fmt.Errorf("failed to get patch: %w", err)
+	}
+
+	missing, err := patch.MissingDependencies(entry.doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	if len(missing) == 0 {
+		if err := dryRunPatch(entry.doc, encoded); err != nil {
+			return nil, nil, fmt.Errorf("patch does not apply: %w", err)
+		}
+	}
+
+	if err := s.store.Put(ctx, key, encoded); err != nil {
+		return nil, nil, fmt.Errorf("failed to store patch: %w", err)
+	}
+
+	// 의존 패치가 없으면 보류하고, 적용되면 이 패치를 기다리던 패치도 적용
+	entry.pending[key.String()] = patch
+	entry.applyPending()
+	if entry.pending[key.String()] != nil {
+		return patch, missing, nil
+	}
+	return patch, nil, nil
+}
+
+// dryRunPatch 문서의 복사본에 패치를 적용해 문서를 바꾸지 않고 적용 가능한지 확인
+// 호출하기 전에 패치의 의존성을 확인해야 한다. 복사본은 적용된 연산 기록이 없으므로
+// 봉투를 뺀 패치를 적용한다.
+func dryRunPatch(doc *luvcrdt.Document, encoded []byte) error {
+	fork, err := doc.Fork(common.NewSessionID())
+	if err != nil {
+		return fmt.Errorf("failed to copy document: %w", err)
+	}
+	trial := &crdtpatch.Patch{}
+	if err := json.Unmarshal(encoded, trial); err != nil {
+		return err
+	}
+	trial.SetMetadata(nil)
+	return trial.Apply(fork)
+}
+
+// View 문서의 현재 뷰를 반환
+func (s *DocumentStore) View(ctx context.Context, id string) (interface{}, error) {
+	if !validDocumentID(id) {
+		return nil, ErrDocumentNotFound
+	}
+
+	entry, err := s.sync(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	return entry.doc.View()
+}
+
+// Delete 문서와 패치를 모두 삭제
+func (s *DocumentStore) Delete(ctx context.Context, id string) error {
+	if !validDocumentID(id) {
+		return ErrDocumentNotFound
+	}
+
+	keys, err := s.patchKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return ErrDocumentNotFound
+	}
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, ds.NewKey(key)); err != nil {
+			return fmt.Errorf("failed to delete patch: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.docs, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// patchKeys 문서의 패치 키 목록 조회
+func (s *DocumentStore) patchKeys(ctx context.Context, id string) ([]string, error) {
+	results, err := s.store.Query(ctx, dsquery.Query{Prefix: patchesKey(id).String(), KeysOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patches: %w", err)
+	}
+	defer results.Close()
+
+	keys := []string{}
+	for result := range results.Next() {
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to query patches: %w", result.Error)
+		}
+		keys = append(keys, result.Key)
+	}
+	return keys, nil
+}
+
+// document 문서의 재생 상태를 반환하고 없으면 생성
+func (s *DocumentStore) document(id string) *storedDocument {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.docs[id]
+	if !ok {
+		entry = &storedDocument{
+			doc:     luvcrdt.NewDocument(common.NewSessionID()),
+			applied: make(map[string]bool),
+			pending: make(map[string]*crdtpatch.Patch),
+		}
+		s.docs[id] = entry
+	}
+	return entry
+}
+
+// sync 저장된 패치 중 아직 적용하지 않은 패치를 문서에 적용
+// 다른 노드에서 복제된 패치도 이 시점에 병합된다.
+func (s *DocumentStore) sync(ctx context.Context, id string) (*storedDocument, error) {
+	entry := s.document(id)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if err := s.load(ctx, id, entry); err != nil {
+		return nil, err
+	}
+	if len(entry.applied) == 0 && len(entry.pending) == 0 {
+		s.mu.Lock()
+		delete(s.docs, id)
+		s.mu.Unlock()
+		return nil, ErrDocumentNotFound
+	}
+
+	entry.applyPending()
+	return entry, nil
+}
+
+// load 저장된 패치 중 아직 읽지 않은 패치를 보류 목록에 추가
+// 호출자는 entry.mu를 잠가야 한다.
+func (s *DocumentStore) load(ctx context.Context, id string, entry *storedDocument) error {
+	keys, err := s.patchKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if entry.applied[key] || entry.pending[key] != nil {
+			continue
+		}
+		data, err := s.store.Get(ctx, ds.NewKey(key))
+		if err != nil {
+			if err == ds.ErrNotFound {
+				continue
+			}
+			return fmt.Errorf("failed to get patch: %w", err)
+		}
+		patch := &crdtpatch.Patch{}
+		if err := json.Unmarshal(data, patch); err != nil {
+			logger.Warnf("Skipping invalid patch %s: %v", key, err)
+			entry.applied[key] = true
+			continue
+		}
+		entry.pending[key] = patch
+	}
+	return nil
+}
+
+// applyPending 보류된 패치를 패치 ID 순서로 적용
+// 다른 패치에 의존해 실패한 패치는 진행이 없을 때까지 재시도한다. 호출자는 entry.mu를 잠가야 한다.
+func (entry *storedDocument) applyPending() {
+	for len(entry.pending) > 0 {
+		pendingKeys := make([]string, 0, len(entry.pending))
+		for key := range entry.pending {
+			pendingKeys = append(pendingKeys, key)
+		}
+		sort.Slice(pendingKeys, func(i, j int) bool {
+			return entry.pending[pendingKeys[i]].ID().CompareTime(entry.pending[pendingKeys[j]].ID()) < 0
+		})
+
+		progress := false
+		for _, key := range pendingKeys {
+			patch := entry.pending[key]
+			// 의존 패치가 없는 패치는 적용해 보지 않고 기다림
+			if missing, err := patch.MissingDependencies(entry.doc); err == nil && len(missing) > 0 {
+				continue
+			}
+			// 실패한 연산 이전까지 적용된 연산은 다시 적용할 때 건너뛴다
+			if err := patch.Apply(entry.doc); err != nil {
+				logger.Debugf("Deferring patch %s: %v", key, err)
+				continue
+			}
+			entry.applied[key] = true
+			delete(entry.pending, key)
+			progress = true
+		}
+		if !progress {
+			return
+		}
+	}
+}
+
+// handleDocs 문서 API 핸들러
+//
+//	GET    /api/docs/{id}  문서의 현재 뷰 조회
+//	POST   /api/docs/{id}  crdtpatch 패치를 문서에 병합
+//	                       (적용할 수 없으면 400, 같은 ID의 다른 패치면 409, 의존 패치를 기다리면 202)
+//	DELETE /api/docs/{id}  문서 삭제
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/docs/")
+
+	// 권한 확인
+	perm := PermissionWrite
+	if r.Method == http.MethodGet {
+		perm = PermissionRead
+	}
+	if !s.authorize(w, r, docsPrefix+"/"+id, perm) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.handleGetDoc(w, r, id)
+	case http.MethodPost:
+		s.handlePatchDoc(w, r, id)
+	case http.MethodDelete:
+		s.handleDeleteDoc(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleGetDoc 문서 뷰 조회 핸들러
+func (s *Server) handleGetDoc(w http.ResponseWriter, r *http.Request, id string) {
+	view, err := s.docs.View(s.ctx, id)
+	if err != nil {
+		if err == ErrDocumentNotFound {
+			writeJSONError(w, http.StatusNotFound, "document not found")
+			return
+		}
+		logger.Errorf("Failed to get document: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(view)
+}
+
+// handlePatchDoc 패치 병합 핸들러
+func (s *Server) handlePatchDoc(w http.ResponseWriter, r *http.Request, id string) {
+	if !validDocumentID(id) {
+		writeJSONError(w, http.StatusBadRequest, "invalid document id")
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Errorf("Failed to read request body: %v", err)
+		writeBodyError(w, err, "failed to read request body")
+		return
+	}
+
+	patch, missing, err := s.docs.ApplyPatch(s.ctx, id, data)
+	if err != nil {
+		logger.Errorf("Failed to apply patch: %v", err)
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrPatchConflict) {
+			status = http.StatusConflict
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	// 이벤트 알림
+	s.broadcastSSEEvent("patch", docsPrefix+"/"+id, data)
+
+	// 의존 패치를 기다리는 패치는 저장되었지만 아직 문서에 반영되지 않음
+	if len(missing) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "pending",
+			"patchId": patch.ID(),
+			"missing": missing,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "ok",
+		"patchId": patch.ID(),
+	})
+}
+
+// handleDeleteDoc 문서 삭제 핸들러
+func (s *Server) handleDeleteDoc(w http.ResponseWriter, r *http.Request, id string) {
+	if err := s.docs.Delete(s.ctx, id); err != nil {
+		if err == ErrDocumentNotFound {
+			writeJSONError(w, http.StatusNotFound, "document not found")
+			return
+		}
+		logger.Errorf("Failed to delete document: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 이벤트 알림
+	s.broadcastSSEEvent("delete", docsPrefix+"/"+id, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// writeJSONError JSON 에러 응답 작성
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
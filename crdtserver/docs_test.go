@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdtpatch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodePatch 패치를 API 요청 본문으로 인코딩
+func encodePatch(t *testing.T, patch *crdtpatch.Patch) string {
+	t.Helper()
+	data, err := json.Marshal(patch)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// newBossPatch hp 필드가 있는 객체를 문서의 루트로 만드는 패치
+func newBossPatch(sid common.SessionID, hp float64) (*crdtpatch.Patch, common.LogicalTimestamp) {
+	builder := crdtpatch.NewPatchBuilder(sid, 1)
+	obj := builder.NewObject().ID
+	builder.InsertValue(common.RootID, obj)
+	builder.InsertObjectField(obj, "hp", hp)
+	return builder.Flush(), obj
+}
+
+// docView 문서 API로 조회한 문서 뷰
+func docView(t *testing.T, s *Server, id string) interface{} {
+	t.Helper()
+	w := serveTest(s, http.MethodGet, "/api/docs/"+id, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var view interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+	return view
+}
+
+// TestDocsPatchRoundTrip 패치를 병합하고 같은 패치를 다시 받아도 한 번만 적용하는지 확인
+func TestDocsPatchRoundTrip(t *testing.T) {
+	s := newTestServer(t, Config{})
+	patch, _ := newBossPatch(common.NewSessionID(), 100)
+	body := encodePatch(t, patch)
+
+	w := serveTest(s, http.MethodPost, "/api/docs/boss", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"status":"ok"`)
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, docView(t, s, "boss"))
+
+	// 같은 패치를 다시 보내면 성공하지만 새로 저장하지 않음
+	w = serveTest(s, http.MethodPost, "/api/docs/boss", body)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	keys, err := s.docs.patchKeys(context.Background(), "boss")
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	w = serveTest(s, http.MethodGet, "/api/docs/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestDocsRejectPatchThatDoesNotApply 적용할 수 없는 패치를 저장하지 않고 400으로 거부하는지 확인
+func TestDocsRejectPatchThatDoesNotApply(t *testing.T) {
+	s := newTestServer(t, Config{})
+	sid := common.NewSessionID()
+	patch, _ := newBossPatch(sid, 100)
+	require.Equal(t, http.StatusOK, serveTest(s, http.MethodPost, "/api/docs/boss", encodePatch(t, patch)).Code)
+
+	// 없는 노드 cnt:999에 값을 넣는 패치
+	invalid := crdtpatch.NewPatch(common.LogicalTimestamp{SID: sid, Counter: 10})
+	invalid.AddOperation(&crdtpatch.InsOperation{
+		ID:       invalid.ID(),
+		TargetID: common.LogicalTimestamp{SID: sid, Counter: 999},
+		Value:    map[string]interface{}{"hp": float64(0)},
+	})
+	w := serveTest(s, http.MethodPost, "/api/docs/boss", encodePatch(t, invalid))
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "patch does not apply")
+
+	// 거부된 패치는 저장되지 않고 문서도 바뀌지 않음
+	keys, err := s.docs.patchKeys(context.Background(), "boss")
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, docView(t, s, "boss"))
+
+	// 새 문서에 대한 적용할 수 없는 패치는 문서를 만들지 않음
+	w = serveTest(s, http.MethodPost, "/api/docs/other", encodePatch(t, invalid))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusNotFound, serveTest(s, http.MethodGet, "/api/docs/other", "").Code)
+}
+
+// TestDocsRejectReusedPatchID 같은 ID로 내용이 다른 패치를 409로 거부하는지 확인
+func TestDocsRejectReusedPatchID(t *testing.T) {
+	s := newTestServer(t, Config{})
+	sid := common.NewSessionID()
+	patch, _ := newBossPatch(sid, 100)
+	require.Equal(t, http.StatusOK, serveTest(s, http.MethodPost, "/api/docs/boss", encodePatch(t, patch)).Code)
+
+	reused, _ := newBossPatch(sid, 1)
+	w := serveTest(s, http.MethodPost, "/api/docs/boss", encodePatch(t, reused))
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, docView(t, s, "boss"))
+}
+
+// TestDocsDeferPatchWithUnknownDependencies 의존 패치가 없는 패치를 보류했다가 의존 패치가 오면 적용하는지 확인
+func TestDocsDeferPatchWithUnknownDependencies(t *testing.T) {
+	s := newTestServer(t, Config{})
+	first, obj := newBossPatch(common.NewSessionID(), 100)
+	ops := first.Operations()
+	dep := ops[len(ops)-1].GetID()
+
+	builder := crdtpatch.NewPatchBuilder(common.NewSessionID(), 10)
+	builder.InsertObjectField(obj, "hp", float64(80))
+	second := builder.Flush()
+	second.SetEnvelope(&crdtpatch.Envelope{Origin: "bob", Deps: []common.LogicalTimestamp{dep}})
+
+	// 의존 패치를 모르면 저장하고 202로 보류
+	w := serveTest(s, http.MethodPost, "/api/docs/boss", encodePatch(t, second))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var pending struct {
+		Status  string                    `json:"status"`
+		Missing []common.LogicalTimestamp `json:"missing"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+	assert.Equal(t, "pending", pending.Status)
+	assert.Equal(t, []common.LogicalTimestamp{dep}, pending.Missing)
+
+	// 의존 패치가 오면 보류된 패치도 적용
+	w = serveTest(s, http.MethodPost, "/api/docs/boss", encodePatch(t, first))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]interface{}{"hp": float64(80)}, docView(t, s, "boss"))
+
+	// 의존성을 이미 적용한 패치는 바로 적용되고, 그 상태에서 적용할 수 없으면 거부
+	builder = crdtpatch.NewPatchBuilder(common.NewSessionID(), 20)
+	builder.InsertValue(common.LogicalTimestamp{SID: dep.SID, Counter: 999}, float64(1))
+	broken := builder.Flush()
+	broken.SetEnvelope(&crdtpatch.Envelope{Origin: "carol", Deps: []common.LogicalTimestamp{dep}})
+	w = serveTest(s, http.MethodPost, "/api/docs/boss", encodePatch(t, broken))
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
+
+// TestDocumentStoreDryRunKeepsDocument 적용해 보는 동안 문서가 바뀌지 않는지 확인
+func TestDocumentStoreDryRunKeepsDocument(t *testing.T) {
+	store := NewDocumentStore(newTestServer(t, Config{}).crdt)
+	ctx := context.Background()
+	sid := common.NewSessionID()
+	patch, obj := newBossPatch(sid, 100)
+	_, missing, err := store.ApplyPatch(ctx, "boss", []byte(encodePatch(t, patch)))
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	// 앞의 연산은 적용되고 뒤의 연산이 실패하는 패치
+	builder := crdtpatch.NewPatchBuilder(sid, 10)
+	builder.InsertObjectField(obj, "hp", float64(1))
+	builder.InsertValue(common.LogicalTimestamp{SID: sid, Counter: 999}, float64(1))
+	_, _, err = store.ApplyPatch(ctx, "boss", []byte(encodePatch(t, builder.Flush())))
+	require.Error(t, err)
+
+	view, err := store.View(ctx, "boss")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, view)
+}
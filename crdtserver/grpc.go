@@ -0,0 +1,401 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"tictactoe/crdtserver/crdtpb"
+)
+
+// grpcMessageOverhead 요청 본문 최대 크기에 더하는 키와 필드 인코딩용 여유 공간
+const grpcMessageOverhead = 64 << 10
+
+// watchEventTypes SSE 이벤트 유형에 대응하는 WatchEvent 유형
+var watchEventTypes = map[string]crdtpb.WatchEvent_Type{
+	"put":    crdtpb.WatchEvent_PUT,
+	"get":    crdtpb.WatchEvent_GET,
+	"delete": crdtpb.WatchEvent_DELETE,
+	"patch":  crdtpb.WatchEvent_PATCH,
+}
+
+// grpcService gRPC CRDTStore 서비스
+// HTTP 데이터 API와 같은 기본 데이터 네임스페이스, 인증, 요청 제한을 사용한다.
+type grpcService struct {
+	crdtpb.UnimplementedCRDTStoreServer
+	server *Server
+}
+
+// setupGRPC gRPC 서버 설정
+func (s *Server) setupGRPC() {
+	// 최대 메시지 크기는 시작할 때의 설정으로 정해지며, 설정 다시 읽기로 줄인 제한은 Put에서 확인한다.
+	maxRecv := math.MaxInt32
+	if limit := s.config.MaxBodyBytes; limit > 0 && limit < math.MaxInt32-grpcMessageOverhead {
+		maxRecv = int(limit) + grpcMessageOverhead
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxRecv),
+		grpc.ChainUnaryInterceptor(s.grpcUnaryInterceptor),
+		grpc.ChainStreamInterceptor(s.grpcStreamInterceptor),
+	}
+	if s.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+
+	s.grpcServer = grpc.NewServer(opts...)
+	crdtpb.RegisterCRDTStoreServer(s.grpcServer, &grpcService{server: s})
+}
+
+// serveGRPC gRPC 서버 시작
+func (s *Server) serveGRPC() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.GRPCPort))
+	if err != nil {
+		return err
+	}
+	logger.Infof("gRPC server listening on %s", listener.Addr())
+	return s.grpcServer.Serve(listener)
+}
+
+// stopGRPC gRPC 서버 종료
+// 진행 중인 호출(WatchPrefix 스트림 포함)이 ctx가 끝날 때까지 끝나지 않으면 강제로 종료한다.
+func (s *Server) stopGRPC(ctx context.Context) {
+	if s.grpcServer == nil {
+		return
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logger.Warn("gRPC server forced to shutdown")
+		s.grpcServer.Stop()
+	}
+}
+
+// grpcUnaryInterceptor 단일 호출의 인증과 요청 제한
+func (s *Server) grpcUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.grpcAuthenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.grpcRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// grpcStreamInterceptor 스트리밍 호출의 인증
+// SSE와 같이 오래 유지되는 스트림에는 요청 제한을 적용하지 않는다.
+func (s *Server) grpcStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.grpcAuthenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticatedStream 인증된 주체가 저장된 컨텍스트를 반환하는 서버 스트림
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcAuthenticate 메타데이터의 자격 증명으로 주체를 확인하여 컨텍스트에 저장
+// 자격 증명은 HTTP API와 같이 x-api-key 또는 authorization: Bearer 메타데이터로 전달한다.
+func (s *Server) grpcAuthenticate(ctx context.Context) (context.Context, error) {
+	if s.auth == nil {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := firstMetadata(md, "x-api-key")
+	if token == "" {
+		if bearer, ok := strings.CutPrefix(firstMetadata(md, "authorization"), "Bearer "); ok {
+			token = strings.TrimSpace(bearer)
+		}
+	}
+
+	principal, err := s.auth.AuthenticateToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return context.WithValue(ctx, principalContextKey{}, principal), nil
+}
+
+// firstMetadata 메타데이터 키의 첫 번째 값
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// grpcRateLimit 호출 주체 또는 클라이언트 IP별 요청 제한
+// 제한을 넘으면 retry-after 헤더(초)와 함께 ResourceExhausted를 반환한다.
+func (s *Server) grpcRateLimit(ctx context.Context) error {
+	limiter := s.requestLimits().rateLimiter
+	if limiter == nil {
+		return nil
+	}
+
+	client := "ip:unknown"
+	if principal := principalFromContext(ctx); principal != nil {
+		client = "principal:" + principal.Name
+	} else if p, ok := grpcpeer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		client = "ip:" + host
+	}
+
+	if ok, wait := limiter.Allow(client); !ok {
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(wait.Seconds())))))
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+// grpcAuthorize 호출 주체가 키에 대한 권한을 가졌는지 확인
+func (s *Server) grpcAuthorize(ctx context.Context, key string, perm Permission) error {
+	if s.auth == nil {
+		return nil
+	}
+	principal := principalFromContext(ctx)
+	if principal == nil || !principal.Can(key, perm) {
+		return status.Errorf(codes.PermissionDenied, "%s permission required for %s", perm, key)
+	}
+	return nil
+}
+
+// grpcDataKey 요청 키 확인 후 권한 확인
+// 쓰기 요청에서는 만료 시간 키 같은 예약된 키를 거부한다.
+func (s *Server) grpcDataKey(ctx context.Context, key string, perm Permission) (ds.Key, error) {
+	if key == "" {
+		return ds.Key{}, status.Error(codes.InvalidArgument, "key is required")
+	}
+	dsKey := ds.NewKey(key)
+	if err := s.grpcAuthorize(ctx, dsKey.String(), perm); err != nil {
+		return ds.Key{}, err
+	}
+	if perm >= PermissionWrite && isReservedKey(dsKey) {
+		return ds.Key{}, status.Error(codes.InvalidArgument, "reserved key: "+dsKey.String())
+	}
+	return dsKey, nil
+}
+
+// grpcWriteError 쓰기 실패를 gRPC 상태로 변환
+// 쓰기 훅이 거부한 쓰기는 InvalidArgument, 그 외에는 Internal을 반환한다.
+func grpcWriteError(op string, err error) error {
+	var rejected *WriteRejectedError
+	if errors.As(err, &rejected) {
+		return status.Error(codes.InvalidArgument, rejected.Error())
+	}
+	logger.Errorf("Failed to %s data: %v", op, err)
+	return status.Error(codes.Internal, err.Error())
+}
+
+// Get 키의 값 조회
+func (g *grpcService) Get(ctx context.Context, req *crdtpb.GetRequest) (*crdtpb.GetResponse, error) {
+	s := g.server
+	key, err := s.grpcDataKey(ctx, req.Key, PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := s.getValue(ctx, s.data, key)
+	if err == ds.ErrNotFound {
+		return nil, status.Error(codes.NotFound, "key not found")
+	}
+	if err != nil {
+		logger.Errorf("Failed to get data: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// 이벤트 알림
+	s.broadcastSSEEvent("get", req.Key, value)
+
+	return &crdtpb.GetResponse{Key: key.String(), Value: value}, nil
+}
+
+// Put 키에 값 저장
+func (g *grpcService) Put(ctx context.Context, req *crdtpb.PutRequest) (*crdtpb.PutResponse, error) {
+	s := g.server
+	key, err := s.grpcDataKey(ctx, req.Key, PermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+	if req.TtlSeconds < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ttl: %d (must be a positive number of seconds)", req.TtlSeconds)
+	}
+	if limit := s.requestLimits().maxBodyBytes; limit > 0 && int64(len(req.Value)) > limit {
+		return nil, status.Errorf(codes.ResourceExhausted, "value too large (max %d bytes)", limit)
+	}
+
+	// 쓰기 훅에서 삭제(nil)와 구분되도록 빈 값은 빈 슬라이스로 전달
+	if req.Value == nil {
+		req.Value = []byte{}
+	}
+
+	// 호출이 취소되어도 커밋이 중간에 끊기지 않도록 서버 컨텍스트 사용
+	if err := s.putValue(s.ctx, s.data, key, req.Value, time.Duration(req.TtlSeconds)*time.Second); err != nil {
+		return nil, grpcWriteError("put", err)
+	}
+
+	// 이벤트 알림
+	s.broadcastSSEEvent("put", req.Key, req.Value)
+
+	return &crdtpb.PutResponse{}, nil
+}
+
+// Delete 키 삭제
+func (g *grpcService) Delete(ctx context.Context, req *crdtpb.DeleteRequest) (*crdtpb.DeleteResponse, error) {
+	s := g.server
+	key, err := s.grpcDataKey(ctx, req.Key, PermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deleteValue(s.ctx, s.data, key); err != nil {
+		return nil, grpcWriteError("delete", err)
+	}
+
+	// 이벤트 알림
+	s.broadcastSSEEvent("delete", req.Key, nil)
+
+	return &crdtpb.DeleteResponse{}, nil
+}
+
+// List 접두사로 시작하는 키와 값 조회
+func (g *grpcService) List(ctx context.Context, req *crdtpb.ListRequest) (*crdtpb.ListResponse, error) {
+	s := g.server
+	if err := s.grpcAuthorize(ctx, ds.NewKey(req.Prefix).String(), PermissionRead); err != nil {
+		return nil, err
+	}
+
+	results, err := s.listValues(ctx, s.data, req.Prefix)
+	if err != nil {
+		logger.Errorf("Failed to query data: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	entries := make([]*crdtpb.Entry, 0, len(results))
+	for _, result := range results {
+		entries = append(entries, &crdtpb.Entry{Key: result.Key, Value: result.Value})
+	}
+	return &crdtpb.ListResponse{Entries: entries}, nil
+}
+
+// WatchPrefix 접두사로 시작하는 키의 변경 이벤트 스트리밍
+//
+// SSE(/events)와 같은 이벤트 시퀀스를 사용하므로 cursor로 재연결하면 SSE처럼 놓친 이벤트를
+// 이어서 받고, 다시 보낼 수 없으면 RESET 이벤트를 받는다.
+func (g *grpcService) WatchPrefix(req *crdtpb.WatchPrefixRequest, stream crdtpb.CRDTStore_WatchPrefixServer) error {
+	s := g.server
+	prefixes, ops, err := newSSEFilter(req.Prefixes, req.Ops)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := stream.Context()
+	client := &sseClient{
+		messages:  make(chan sseMessage, sseSendBuffer),
+		principal: principalFromContext(ctx),
+		prefixes:  prefixes,
+		ops:       ops,
+	}
+	clientID, seq, replay, resumed, reset := s.registerSSEClient(client, req.Cursor)
+	defer s.unregisterSSEClient(clientID)
+
+	// 연결 확인 이벤트 전송 (이어서 받을 시퀀스 번호 포함)
+	err = stream.Send(&crdtpb.WatchEvent{
+		Type:    crdtpb.WatchEvent_CONNECTED,
+		Seq:     seq,
+		Cursor:  s.sseCursor(seq),
+		Resumed: resumed,
+	})
+	if err != nil {
+		return err
+	}
+	if reset {
+		if err := stream.Send(&crdtpb.WatchEvent{Type: crdtpb.WatchEvent_RESET, Seq: seq}); err != nil {
+			return err
+		}
+	}
+	for _, msg := range replay {
+		if err := s.sendWatchEvent(stream, msg); err != nil {
+			return err
+		}
+	}
+
+	// 메시지 수신 및 전송
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.ctx.Done():
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case msg := <-client.messages:
+			if err := s.sendWatchEvent(stream, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendWatchEvent SSE 메시지를 WatchEvent로 변환하여 전송
+func (s *Server) sendWatchEvent(stream crdtpb.CRDTStore_WatchPrefixServer, msg sseMessage) error {
+	var event struct {
+		Event    string `json:"event"`
+		Key      string `json:"key"`
+		Value    string `json:"value"`
+		Encoding string `json:"encoding"`
+		Prev     uint64 `json:"prev"`
+	}
+	if err := json.Unmarshal(msg.data, &event); err != nil {
+		logger.Errorf("Failed to decode SSE event: %v", err)
+		return nil
+	}
+
+	value := []byte(event.Value)
+	if event.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(event.Value)
+		if err != nil {
+			logger.Errorf("Failed to decode SSE event value: %v", err)
+			return nil
+		}
+		value = decoded
+	}
+
+	return stream.Send(&crdtpb.WatchEvent{
+		Type:   watchEventTypes[event.Event],
+		Key:    event.Key,
+		Value:  value,
+		Seq:    msg.seq,
+		Prev:   event.Prev,
+		Cursor: s.sseCursor(msg.seq),
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"tictactoe/crdtserver/crdtpb"
+)
+
+// newTestGRPCClient 메모리 연결로 서버의 gRPC 서비스에 접속하는 클라이언트
+func newTestGRPCClient(t *testing.T, s *Server) crdtpb.CRDTStoreClient {
+	t.Helper()
+	s.setupGRPC()
+	listener := bufconn.Listen(1 << 20)
+	go s.grpcServer.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		s.grpcServer.Stop()
+	})
+	return crdtpb.NewCRDTStoreClient(conn)
+}
+
+// TestGRPCAuthentication 자격 증명이 없거나 잘못된 호출을 Unauthenticated로 거부하는지 확인
+func TestGRPCAuthentication(t *testing.T) {
+	s := newTestServer(t, Config{})
+	auth, err := NewAuthenticator(&AuthConfig{APIKeys: []APIKeyConfig{
+		{Key: "game-key", Name: "game", Permissions: map[string]string{"/": "write"}},
+	}})
+	require.NoError(t, err)
+	s.auth = auth
+	client := newTestGRPCClient(t, s)
+	ctx := context.Background()
+
+	_, err = client.Get(ctx, &crdtpb.GetRequest{Key: "/gold"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.Put(metadata.AppendToOutgoingContext(ctx, "x-api-key", "wrong-key"), &crdtpb.PutRequest{Key: "/gold", Value: []byte("10")})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// 스트리밍 호출도 인증이 필요
+	stream, err := client.WatchPrefix(ctx, &crdtpb.WatchPrefixRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authorized := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer game-key")
+	_, err = client.Put(authorized, &crdtpb.PutRequest{Key: "/gold", Value: []byte("10")})
+	require.NoError(t, err)
+	got, err := client.Get(authorized, &crdtpb.GetRequest{Key: "/gold"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("10"), got.Value)
+}
+
+// TestGRPCDataErrors 예약된 키, 없는 키와 크기 제한을 넘는 값의 상태 코드 확인
+func TestGRPCDataErrors(t *testing.T) {
+	s := newTestServer(t, Config{MaxBodyBytes: 16})
+	client := newTestGRPCClient(t, s)
+	ctx := context.Background()
+
+	for _, key := range []string{ttlPrefix + "/gold", fieldsPrefix + "/gold/name"} {
+		_, err := client.Put(ctx, &crdtpb.PutRequest{Key: key, Value: []byte("1")})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), key)
+		_, err = client.Delete(ctx, &crdtpb.DeleteRequest{Key: key})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), key)
+	}
+
+	_, err := client.Get(ctx, &crdtpb.GetRequest{Key: "/missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.Get(ctx, &crdtpb.GetRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Put(ctx, &crdtpb.PutRequest{Key: "/big", Value: make([]byte, 17)})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = client.Put(ctx, &crdtpb.PutRequest{Key: "/small", Value: make([]byte, 16)})
+	assert.NoError(t, err)
+	_, err = client.Put(ctx, &crdtpb.PutRequest{Key: "/ttl", Value: []byte("1"), TtlSeconds: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestGRPCWatchPrefix 스트림을 연 뒤의 Put이 접두사가 일치하는 스트림에 전달되는지 확인
+func TestGRPCWatchPrefix(t *testing.T) {
+	s := newTestServer(t, Config{})
+	client := newTestGRPCClient(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchPrefix(ctx, &crdtpb.WatchPrefixRequest{Prefixes: []string{"/boss"}, Ops: []string{"put"}})
+	require.NoError(t, err)
+	connected, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, crdtpb.WatchEvent_CONNECTED, connected.Type)
+
+	_, err = client.Put(ctx, &crdtpb.PutRequest{Key: "/player/hp", Value: []byte("10")})
+	require.NoError(t, err)
+	_, err = client.Put(ctx, &crdtpb.PutRequest{Key: "/boss/hp", Value: []byte("100")})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, crdtpb.WatchEvent_PUT, event.Type)
+	assert.Equal(t, "/boss/hp", event.Key)
+	assert.Equal(t, []byte("100"), event.Value)
+	assert.Greater(t, event.Seq, connected.Seq)
+	assert.NotEmpty(t, event.Cursor)
+}
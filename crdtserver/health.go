@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+)
+
+// readinessProbeTimeout 준비 상태 확인에서 의존성 하나를 확인하는 제한 시간
+const readinessProbeTimeout = 2 * time.Second
+
+// 구성 요소 상태
+const (
+	ComponentOK       = "ok"
+	ComponentDegraded = "degraded"
+	ComponentError    = "error"
+)
+
+// ComponentStatus 의존성 확인 결과
+type ComponentStatus struct {
+	// Status ok, degraded(동작하지만 주의 필요), error(요청을 처리할 수 없음)
+	Status string `json:"status"`
+	// LatencyMs 확인에 걸린 시간 (밀리초)
+	LatencyMs float64 `json:"latencyMs"`
+	// Error 실패 원인
+	Error string `json:"error,omitempty"`
+	// Details 추가 정보
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// isPublicPath 인증 없이 접근할 수 있는 경로인지 확인
+func isPublicPath(path string) bool {
+	switch path {
+	case "/health", "/livez", "/readyz":
+		return true
+	}
+	return false
+}
+
+// handleLivez 프로세스 생존 확인 핸들러 (GET /livez)
+// 의존성을 확인하지 않으므로 Redis 장애로 프로세스가 재시작되지 않는다.
+func (s *Server) handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": ComponentOK,
+		"peerId": s.host.ID().String(),
+		"uptime": time.Since(s.startTime).Round(time.Second).String(),
+	})
+}
+
+// handleReadyz 준비 상태 확인 핸들러 (GET /readyz)
+// 의존성을 직접 확인하여 하나라도 error이면 503으로 응답한다.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	probes := map[string]func(context.Context) (map[string]interface{}, error){
+		"redis":     s.probeRedis,
+		"pubsub":    s.probePubSub,
+		"datastore": s.probeDatastore,
+	}
+
+	status := ComponentOK
+	components := make(map[string]ComponentStatus, len(probes)+1)
+	for name, probe := range probes {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessProbeTimeout)
+		start := time.Now()
+		details, err := probe(ctx)
+		cancel()
+
+		component := ComponentStatus{
+			Status:    ComponentOK,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			Details:   details,
+		}
+		if err != nil {
+			component.Status = ComponentError
+			component.Error = err.Error()
+			status = ComponentError
+		}
+		components[name] = component
+	}
+
+	// dirty 상태는 복구 중에도 요청을 처리할 수 있으므로 준비 상태에 영향을 주지 않는다.
+	crdtStatus := ComponentStatus{Status: ComponentOK}
+	if s.crdt.IsDirty(r.Context()) {
+		crdtStatus.Status = ComponentDegraded
+		crdtStatus.Error = "datastore is dirty and being repaired"
+	}
+	components["crdt"] = crdtStatus
+
+	w.Header().Set("Content-Type", "application/json")
+	if status != ComponentOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     status,
+		"peerId":     s.host.ID().String(),
+		"components": components,
+	})
+}
+
+// probeRedis Redis 연결 확인
+func (s *Server) probeRedis(ctx context.Context) (map[string]interface{}, error) {
+	if err := s.redisClient.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// probePubSub CRDT 동기화 토픽 구독 확인
+// 연결된 피어가 없어도 단일 노드로 동작할 수 있으므로 피어 수는 정보로만 제공한다.
+func (s *Server) probePubSub(ctx context.Context) (map[string]interface{}, error) {
+	joined := false
+	for _, topic := range s.pubsub.GetTopics() {
+		if topic == s.config.PubSubTopic {
+			joined = true
+			break
+		}
+	}
+	if !joined {
+		return nil, fmt.Errorf("not subscribed to topic %s", s.config.PubSubTopic)
+	}
+	return map[string]interface{}{
+		"topic": s.config.PubSubTopic,
+		"peers": len(s.topic.ListPeers()),
+	}, nil
+}
+
+// probeDatastore 데이터스토어 쓰기와 읽기 확인
+// 확인용 키가 다른 노드로 복제되지 않도록 CRDT가 아닌 하위 데이터스토어를 사용한다.
+func (s *Server) probeDatastore(ctx context.Context) (map[string]interface{}, error) {
+	key := ds.NewKey("/_health").ChildString(s.host.ID().String())
+	value := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	if err := s.store.Put(ctx, key, value); err != nil {
+		return nil, fmt.Errorf("write failed: %w", err)
+	}
+	got, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("read failed: %w", err)
+	}
+	if !bytes.Equal(got, value) {
+		return nil, fmt.Errorf("read returned a different value")
+	}
+	if err := s.store.Delete(ctx, key); err != nil {
+		return nil, fmt.Errorf("delete failed: %w", err)
+	}
+	return nil, nil
+}
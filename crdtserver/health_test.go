@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	ds "github.com/ipfs/go-datastore"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis 모든 명령에 PONG으로 응답하는 Redis 서버 (PING 확인용)
+type fakeRedis struct {
+	listener net.Listener
+	conns    chan net.Conn
+}
+
+// newFakeRedis 로컬 포트에서 fakeRedis 시작
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{listener: listener, conns: make(chan net.Conn, 16)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			f.conns <- conn
+			go f.serve(conn)
+		}
+	}()
+	t.Cleanup(f.stop)
+	return f
+}
+
+// serve RESP 배열 명령을 읽고 PONG으로 응답
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+		if err != nil {
+			return
+		}
+		// 인자마다 길이와 값 두 줄
+		for i := 0; i < 2*n; i++ {
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+		}
+		if _, err := conn.Write([]byte("+PONG\r\n")); err != nil {
+			return
+		}
+	}
+}
+
+// stop 서버와 열린 연결 종료
+func (f *fakeRedis) stop() {
+	f.listener.Close()
+	for {
+		select {
+		case conn := <-f.conns:
+			conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// newTestHealthServer 준비 상태 확인에 필요한 Redis 클라이언트, libp2p 호스트와 PubSub 구독을 갖춘 서버
+func newTestHealthServer(t *testing.T, redisAddr string) *Server {
+	t.Helper()
+	s := newTestServer(t, Config{})
+	s.host = newClosingHost(t, Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}})
+	ps, err := pubsub.NewGossipSub(s.ctx, s.host)
+	require.NoError(t, err)
+	s.pubsub = ps
+	s.topic, err = ps.Join(s.config.PubSubTopic)
+	require.NoError(t, err)
+	s.subscription, err = s.topic.Subscribe()
+	require.NoError(t, err)
+	s.redisClient = redis.NewClient(&redis.Options{Addr: redisAddr, MaxRetries: -1})
+	t.Cleanup(func() { s.redisClient.Close() })
+	return s
+}
+
+// readiness 준비 상태 확인 응답
+type readiness struct {
+	Status     string                     `json:"status"`
+	PeerID     string                     `json:"peerId"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// TestReadyz 의존성이 모두 동작하면 200, 하나라도 실패하면 503과 실패한 구성 요소를 반환하는지 확인
+func TestReadyz(t *testing.T) {
+	redisServer := newFakeRedis(t)
+	s := newTestHealthServer(t, redisServer.listener.Addr().String())
+
+	var ready readiness
+	require.Equal(t, http.StatusOK, getJSON(t, s, "/readyz", &ready))
+	assert.Equal(t, ComponentOK, ready.Status)
+	assert.Equal(t, s.host.ID().String(), ready.PeerID)
+	for _, name := range []string{"redis", "pubsub", "datastore", "crdt"} {
+		assert.Equal(t, ComponentOK, ready.Components[name].Status, name)
+	}
+	assert.Equal(t, "crdt-sync", ready.Components["pubsub"].Details["topic"])
+	assert.Equal(t, float64(0), ready.Components["pubsub"].Details["peers"])
+
+	// 확인용 키는 남지 않음
+	exists, err := s.store.Has(s.ctx, ds.NewKey("/_health").ChildString(s.host.ID().String()))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Redis가 멈추면 준비되지 않음
+	redisServer.stop()
+	ready = readiness{}
+	require.Equal(t, http.StatusServiceUnavailable, getJSON(t, s, "/readyz", &ready))
+	assert.Equal(t, ComponentError, ready.Status)
+	assert.Equal(t, ComponentError, ready.Components["redis"].Status)
+	assert.NotEmpty(t, ready.Components["redis"].Error)
+	assert.Equal(t, ComponentOK, ready.Components["datastore"].Status)
+
+	// 토픽 구독이 끝나도 준비되지 않음
+	s.redisClient.Close()
+	s.redisClient = redis.NewClient(&redis.Options{Addr: newFakeRedis(t).listener.Addr().String()})
+	s.subscription.Cancel()
+	ready = readiness{}
+	require.Equal(t, http.StatusServiceUnavailable, getJSON(t, s, "/readyz", &ready))
+	assert.Equal(t, ComponentOK, ready.Components["redis"].Status)
+	assert.Equal(t, ComponentError, ready.Components["pubsub"].Status)
+	assert.Contains(t, ready.Components["pubsub"].Error, "not subscribed to topic crdt-sync")
+}
+
+// TestLivez 의존성 상태와 관계없이 생존 확인에 응답하고, 상태 확인 경로는 인증 없이 접근할 수 있는지 확인
+func TestLivez(t *testing.T) {
+	redisServer := newFakeRedis(t)
+	s := newTestHealthServer(t, redisServer.listener.Addr().String())
+	redisServer.stop()
+
+	for _, path := range []string{"/livez", "/health"} {
+		var live map[string]interface{}
+		require.Equal(t, http.StatusOK, getJSON(t, s, path, &live), path)
+		assert.Equal(t, ComponentOK, live["status"], path)
+		assert.Equal(t, s.host.ID().String(), live["peerId"], path)
+	}
+
+	var err error
+	s.auth, err = NewAuthenticator(&AuthConfig{APIKeys: []APIKeyConfig{
+		{Key: "game-key", Name: "game", Permissions: map[string]string{"/": "write"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serveTest(s, http.MethodGet, "/livez", "").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveTest(s, http.MethodGet, "/readyz", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveTest(s, http.MethodGet, "/api/data/boss/hp", "").Code)
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	dshelp "github.com/ipfs/boxo/datastore/dshelp"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	pb "github.com/ipfs/go-ds-crdt/pb"
+)
+
+const (
+	// blockOriginsPrefix 블록 출처를 기록하는 키 접두사
+	blockOriginsPrefix = "/crdtserver/origins"
+	// defaultHistoryLimit 키 이력 조회의 기본 최대 항목 수
+	defaultHistoryLimit = 100
+	// historyMaxNodes 키 이력 조회 한 번에 읽는 최대 DAG 노드 수
+	historyMaxNodes = 100000
+)
+
+// BlockOrigins DAG 블록이 어느 피어에서 왔는지 기록
+//
+// go-ds-crdt 블록에는 작성자가 담기지 않으므로 블록을 처음 받은 경로를 기록한다.
+// 로컬 쓰기로 만든 블록은 이 노드, 병합으로 가져온 블록은 heads를 보낸 피어가 출처다.
+// 다른 피어를 거쳐 전달된 블록은 전달한 피어로 기록된다.
+type BlockOrigins struct {
+	store     ds.Datastore
+	localPeer string
+}
+
+// NewBlockOrigins 새 블록 출처 기록 생성
+func NewBlockOrigins(store ds.Datastore, localPeer string) *BlockOrigins {
+	return &BlockOrigins{store: store, localPeer: localPeer}
+}
+
+// key 블록의 출처를 저장하는 키
+func (o *BlockOrigins) key(c cid.Cid) ds.Key {
+	return ds.NewKey(blockOriginsPrefix).ChildString(c.String())
+}
+
+// Record 블록의 출처 기록 (이미 기록된 블록은 그대로 둠)
+func (o *BlockOrigins) Record(ctx context.Context, c cid.Cid, peer string) {
+	if o == nil || peer == "" {
+		return
+	}
+	key := o.key(c)
+	if has, err := o.store.Has(ctx, key); err != nil || has {
+		return
+	}
+	if err := o.store.Put(ctx, key, []byte(peer)); err != nil {
+		logger.Warnf("Failed to record origin of block %s: %v", c, err)
+	}
+}
+
+// RecordLocal 로컬 쓰기로 만든 블록의 출처 기록
+func (o *BlockOrigins) RecordLocal(ctx context.Context, c cid.Cid) {
+	if o == nil {
+		return
+	}
+	o.Record(ctx, c, o.localPeer)
+}
+
+// Get 블록의 출처 (기록이 없으면 빈 문자열)
+func (o *BlockOrigins) Get(ctx context.Context, c cid.Cid) string {
+	if o == nil {
+		return ""
+	}
+	value, err := o.store.Get(ctx, o.key(c))
+	if err != nil {
+		return ""
+	}
+	return string(value)
+}
+
+// historyEntry 키 이력 항목 (DAG 노드 하나의 저장 또는 삭제)
+type historyEntry struct {
+	// CID 변경이 담긴 DAG 노드
+	CID string `json:"cid"`
+	// Priority 노드의 DAG 높이 (높을수록 나중의 변경)
+	Priority uint64 `json:"priority"`
+	// Op put 또는 delete
+	Op string `json:"op"`
+	// Value, Encoding, Size 저장된 값 (put만 해당)
+	Value    string `json:"value,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Size     int    `json:"size,omitempty"`
+	// Deletes 삭제한 저장의 CID (delete만 해당)
+	Deletes []string `json:"deletes,omitempty"`
+	// DeletedBy 이 저장을 삭제한 노드의 CID
+	DeletedBy string `json:"deletedBy,omitempty"`
+	// Current 현재 값으로 선택된 저장인지 여부
+	Current bool `json:"current,omitempty"`
+	// Origin 노드를 처음 받은 피어 (기록이 없으면 비어 있음)
+	Origin string `json:"origin,omitempty"`
+
+	value []byte
+}
+
+// historyNode 이력 조회 중 방문할 DAG 노드
+type historyNode struct {
+	cid   cid.Cid
+	delta *pb.Delta
+	links []cid.Cid
+}
+
+// historyQueue 우선순위가 높은 노드부터 꺼내는 큐
+type historyQueue []*historyNode
+
+func (q historyQueue) Len() int { return len(q) }
+func (q historyQueue) Less(i, j int) bool {
+	if q[i].delta.Priority != q[j].delta.Priority {
+		return q[i].delta.Priority > q[j].delta.Priority
+	}
+	return q[i].cid.KeyString() < q[j].cid.KeyString()
+}
+func (q historyQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *historyQueue) Push(x interface{}) { *q = append(*q, x.(*historyNode)) }
+func (q *historyQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// keyHistory heads에서 DAG를 우선순위 순서로 내려가며 키의 저장과 삭제를 최신 순으로 수집
+// limit개를 모으거나 DAG를 historyMaxNodes개 읽으면 멈추고 truncated를 반환한다.
+// 로컬에서 가져올 수 없는 노드는 missing에 담긴다.
+func (s *Server) keyHistory(ctx context.Context, key string, limit int) (entries []*historyEntry, scanned int, truncated bool, missing []string) {
+	entries = []*historyEntry{}
+	missing = []string{}
+
+	queue := &historyQueue{}
+	visited := make(map[cid.Cid]bool)
+	visit := func(c cid.Cid) {
+		if visited[c] {
+			return
+		}
+		visited[c] = true
+		node, delta, err := s.getDelta(ctx, c)
+		if err != nil {
+			missing = append(missing, c.String())
+			return
+		}
+		links := make([]cid.Cid, 0, len(node.Links()))
+		for _, link := range node.Links() {
+			links = append(links, link.Cid)
+		}
+		heap.Push(queue, &historyNode{cid: c, delta: delta, links: links})
+	}
+	for _, head := range s.crdt.InternalStats(ctx).Heads {
+		visit(head)
+	}
+
+	// 저장 노드의 블록 ID(go-ds-crdt 요소 ID) -> 이력 항목
+	puts := make(map[string]*historyEntry)
+	// 블록 ID -> 그 저장을 삭제한 노드의 CID
+	deletedBy := make(map[string]string)
+
+	for queue.Len() > 0 {
+		if len(entries) >= limit || scanned >= historyMaxNodes {
+			truncated = true
+			break
+		}
+		node := heap.Pop(queue).(*historyNode)
+		scanned++
+
+		blockID := dshelp.MultihashToDsKey(node.cid.Hash()).String()
+		for _, tomb := range node.delta.Tombstones {
+			if ds.NewKey(tomb.Key).String() != key {
+				continue
+			}
+			entry := s.historyEntryFor(ctx, node, "delete")
+			entry.Deletes = append(entry.Deletes, blockIDToCID(tomb.Id, node.cid))
+			deletedBy[tomb.Id] = node.cid.String()
+			entries = appendHistoryEntry(entries, entry)
+		}
+		for _, elem := range node.delta.Elements {
+			if ds.NewKey(elem.Key).String() != key {
+				continue
+			}
+			entry := s.historyEntryFor(ctx, node, "put")
+			entry.value = elem.Value
+			entry.Size = len(elem.Value)
+			if utf8.Valid(elem.Value) {
+				entry.Value = string(elem.Value)
+			} else {
+				entry.Value = base64.StdEncoding.EncodeToString(elem.Value)
+				entry.Encoding = "base64"
+			}
+			entry.DeletedBy = deletedBy[blockID]
+			puts[blockID] = entry
+			entries = append(entries, entry)
+		}
+
+		for _, link := range node.links {
+			visit(link)
+		}
+	}
+
+	// go-ds-crdt와 같은 규칙으로 현재 값 표시
+	// (삭제되지 않은 저장 중 우선순위가 가장 높고, 같으면 값이 큰 것)
+	var current *historyEntry
+	for _, entry := range puts {
+		if entry.DeletedBy != "" {
+			continue
+		}
+		if current == nil || entry.Priority > current.Priority ||
+			(entry.Priority == current.Priority && bytes.Compare(entry.value, current.value) > 0) {
+			current = entry
+		}
+	}
+	if current != nil {
+		current.Current = true
+	}
+	return entries, scanned, truncated, missing
+}
+
+// historyEntryFor DAG 노드의 이력 항목 생성
+func (s *Server) historyEntryFor(ctx context.Context, node *historyNode, op string) *historyEntry {
+	return &historyEntry{
+		CID:      node.cid.String(),
+		Priority: node.delta.Priority,
+		Op:       op,
+		Origin:   s.merges.origins.Get(ctx, node.cid),
+	}
+}
+
+// appendHistoryEntry 삭제 항목 추가 (같은 노드의 삭제는 하나로 합침)
+func appendHistoryEntry(entries []*historyEntry, entry *historyEntry) []*historyEntry {
+	if n := len(entries); n > 0 && entries[n-1].CID == entry.CID && entries[n-1].Op == "delete" {
+		entries[n-1].Deletes = append(entries[n-1].Deletes, entry.Deletes...)
+		return entries
+	}
+	return append(entries, entry)
+}
+
+// blockIDToCID go-ds-crdt 요소 ID(블록 멀티해시 키)를 CID 문자열로 변환
+// 같은 DAG의 블록은 CID 버전과 코덱이 같으므로 참조한 노드의 것을 사용한다.
+func blockIDToCID(id string, ref cid.Cid) string {
+	mh, err := dshelp.DsKeyToMultihash(ds.NewKey(id))
+	if err != nil {
+		return id
+	}
+	if ref.Version() == 0 {
+		return cid.NewCidV0(mh).String()
+	}
+	return cid.NewCidV1(ref.Type(), mh).String()
+}
+
+// handleCRDTViewerHistory 키의 변경 이력 조회
+//
+// 쿼리 파라미터:
+//
+//	limit  최대 항목 수 (기본값 100)
+//
+// Merkle-DAG를 heads에서부터 내려가며 키에 값을 저장하거나 키를 삭제한 노드를 최신 순으로
+// 반환한다. 항목마다 노드를 받은 피어(origin)가 담기므로 누가 값을 바꿨는지 확인할 수 있다.
+func (s *Server) handleCRDTViewerHistory(w http.ResponseWriter, r *http.Request, key string) {
+	if key == "" {
+		writeJSONError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	limit := defaultHistoryLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid limit: "+raw)
+			return
+		}
+		limit = n
+	}
+
+	dsKey := ds.NewKey(key).String()
+	entries, scanned, truncated, missing := s.keyHistory(r.Context(), dsKey, limit)
+	response := map[string]interface{}{
+		"key":       dsKey,
+		"entries":   entries,
+		"scanned":   scanned,
+		"truncated": truncated,
+		"missing":   missing,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyHistoryResponse 이력 엔드포인트 응답
+type keyHistoryResponse struct {
+	Key       string         `json:"key"`
+	Entries   []historyEntry `json:"entries"`
+	Scanned   int            `json:"scanned"`
+	Truncated bool           `json:"truncated"`
+	Missing   []string       `json:"missing"`
+}
+
+// TestCRDTViewerHistory 키의 저장과 삭제를 최신 순으로, 현재 값과 삭제 관계를 표시하는지 확인
+func TestCRDTViewerHistory(t *testing.T) {
+	s := newTestServer(t, Config{})
+	putData(t, s, "boss/hp", "100")
+	first := headCIDs(t, s)[0]
+	putData(t, s, "raid/state", "started")
+	putData(t, s, "boss/hp", "90")
+	second := headCIDs(t, s)[0]
+	w := serveTest(s, http.MethodDelete, "/api/data/boss/hp", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	deleted := headCIDs(t, s)[0]
+	putData(t, s, "boss/hp", "80")
+	third := headCIDs(t, s)[0]
+
+	var history keyHistoryResponse
+	require.Equal(t, http.StatusOK, getJSON(t, s, "/api/crdt-viewer/history/boss/hp", &history))
+	assert.Equal(t, "/boss/hp", history.Key)
+	assert.Equal(t, 5, history.Scanned)
+	assert.False(t, history.Truncated)
+	assert.Empty(t, history.Missing)
+	// 삭제 하나가 남아 있던 두 저장을 모두 삭제
+	require.Len(t, history.Entries, 4)
+	assert.ElementsMatch(t, []string{first, second}, history.Entries[1].Deletes)
+	history.Entries[1].Deletes = nil
+	assert.Equal(t, []historyEntry{
+		{CID: third, Priority: 5, Op: "put", Value: "80", Size: 2, Current: true, Origin: testLocalPeer},
+		{CID: deleted, Priority: 4, Op: "delete", Origin: testLocalPeer},
+		{CID: second, Priority: 3, Op: "put", Value: "90", Size: 2, DeletedBy: deleted, Origin: testLocalPeer},
+		{CID: first, Priority: 1, Op: "put", Value: "100", Size: 3, DeletedBy: deleted, Origin: testLocalPeer},
+	}, history.Entries)
+
+	// limit개를 모으면 멈춤
+	history = keyHistoryResponse{}
+	getJSON(t, s, "/api/crdt-viewer/history/boss/hp?limit=2", &history)
+	assert.Len(t, history.Entries, 2)
+	assert.True(t, history.Truncated)
+
+	history = keyHistoryResponse{}
+	getJSON(t, s, "/api/crdt-viewer/history/boss/mp", &history)
+	assert.Empty(t, history.Entries)
+	assert.Equal(t, http.StatusBadRequest, serveTest(s, http.MethodGet, "/api/crdt-viewer/history/boss/hp?limit=-1", "").Code)
+}
+
+// TestCRDTViewerHistoryOrigin 다른 피어에서 병합한 노드에 그 피어가 출처로 표시되는지 확인
+func TestCRDTViewerHistoryOrigin(t *testing.T) {
+	bstore := blockstore.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	a := newTestReplica(t, Config{}, nopBroadcaster{}, bstore)
+	b := newTestReplica(t, Config{}, newTestBroadcaster(t), bstore)
+
+	putData(t, a, "boss/hp", "100")
+	injectHeads(t, b, "peer-a", headCIDs(t, a)...)
+	putData(t, b, "boss/hp", "90")
+
+	var history keyHistoryResponse
+	require.Equal(t, http.StatusOK, getJSON(t, b, "/api/crdt-viewer/history/boss/hp", &history))
+	require.Len(t, history.Entries, 2)
+	assert.Equal(t, "90", history.Entries[0].Value)
+	assert.Equal(t, testLocalPeer, history.Entries[0].Origin)
+	assert.True(t, history.Entries[0].Current)
+	assert.Equal(t, "100", history.Entries[1].Value)
+	assert.Equal(t, "peer-a", history.Entries[1].Origin)
+	assert.False(t, history.Entries[1].Current)
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+)
+
+// WriteHook 키 접두사별로 등록하는 쓰기 플러그인
+//
+// 데이터 API(HTTP, 배치, gRPC, 네임스페이스)로 들어온 로컬 쓰기에만 호출된다. 다른 노드에서
+// 병합된 값은 그 노드에서 이미 확인되었으므로 다시 호출하지 않는다. 삭제는 value가 nil이다.
+type WriteHook interface {
+	// PreWriteHook 값이 CRDT에 들어가기 전에 호출 (에러를 반환하면 쓰기를 거부)
+	PreWriteHook(key string, value []byte) error
+	// PostWriteHook 쓰기가 커밋된 뒤 호출
+	PostWriteHook(key string, value []byte)
+}
+
+// WriteRejectedError 쓰기 훅이 거부한 쓰기
+type WriteRejectedError struct {
+	Key string
+	Err error
+}
+
+func (e *WriteRejectedError) Error() string {
+	return fmt.Sprintf("write rejected for %s: %v", e.Key, e.Err)
+}
+
+func (e *WriteRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// writeRejectedError 쓰기 훅이 거부한 쓰기이면 422 응답을 작성하고 true를 반환
+func writeRejectedError(w http.ResponseWriter, err error) bool {
+	var rejected *WriteRejectedError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	writeJSONError(w, http.StatusUnprocessableEntity, rejected.Error())
+	return true
+}
+
+// registeredHook 접두사에 등록된 쓰기 훅
+type registeredHook struct {
+	prefix string
+	hook   WriteHook
+}
+
+// pluginHooks RegisterWriteHook으로 등록한 플러그인 (서버를 만들 때 WriteHooks에 추가됨)
+var pluginHooks []registeredHook
+
+// RegisterWriteHook 서버에 함께 컴파일되는 플러그인의 쓰기 훅 등록
+// 플러그인 파일의 init 함수에서 호출한다.
+func RegisterWriteHook(prefix string, hook WriteHook) {
+	pluginHooks = append(pluginHooks, registeredHook{prefix: ds.NewKey(prefix).String(), hook: hook})
+}
+
+// defaultHookTimeout 훅 하나를 기다리는 최대 시간
+const defaultHookTimeout = 5 * time.Second
+
+// WriteHooks 접두사별 쓰기 훅 목록
+type WriteHooks struct {
+	mu      sync.RWMutex
+	hooks   []registeredHook
+	timeout time.Duration // 훅 하나를 기다리는 최대 시간 (0이면 제한 없음)
+}
+
+// NewWriteHooks 플러그인 훅이 등록된 새 쓰기 훅 목록 생성
+func NewWriteHooks() *WriteHooks {
+	return &WriteHooks{
+		hooks:   append([]registeredHook(nil), pluginHooks...),
+		timeout: defaultHookTimeout,
+	}
+}
+
+// Register 접두사에 쓰기 훅 등록
+// 접두사에 속하는 키를 쓸 때마다 일치하는 모든 훅이 등록 순서대로 호출된다.
+func (h *WriteHooks) Register(prefix string, hook WriteHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, registeredHook{prefix: ds.NewKey(prefix).String(), hook: hook})
+}
+
+// matching 키에 일치하는 훅 목록
+func (h *WriteHooks) matching(key string) []WriteHook {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var hooks []WriteHook
+	for _, registered := range h.hooks {
+		if inNamespace(key, registered.prefix) {
+			hooks = append(hooks, registered.hook)
+		}
+	}
+	return hooks
+}
+
+// PreWrite 키에 일치하는 훅의 PreWriteHook을 등록 순서대로 호출
+// 훅 하나라도 에러를 반환하거나 패닉이 발생하거나 시간 안에 끝나지 않으면 WriteRejectedError를 반환한다.
+func (h *WriteHooks) PreWrite(key string, value []byte) error {
+	for _, hook := range h.matching(key) {
+		err := h.wait(key, func() error { return callPreWriteHook(hook, key, value) })
+		if err != nil {
+			return &WriteRejectedError{Key: key, Err: err}
+		}
+	}
+	return nil
+}
+
+// wait 훅 호출이 끝나거나 제한 시간이 지날 때까지 대기
+// 시간이 지나도 훅은 계속 실행되지만 결과는 버린다.
+func (h *WriteHooks) wait(key string, call func() error) error {
+	if h.timeout <= 0 {
+		return call()
+	}
+	done := make(chan error, 1)
+	go func() { done <- call() }()
+
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		logger.Warnf("Write hook timed out for %s after %s", key, h.timeout)
+		return fmt.Errorf("write hook timed out")
+	}
+}
+
+// callPreWriteHook 패닉을 에러로 바꾸어 PreWriteHook 호출
+func callPreWriteHook(hook WriteHook, key string, value []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Write hook panicked for %s: %v", key, r)
+			err = fmt.Errorf("write hook failed")
+		}
+	}()
+	return hook.PreWriteHook(key, value)
+}
+
+// PostWrite 키에 일치하는 훅의 PostWriteHook을 등록 순서대로 호출
+// 쓰기는 이미 커밋되었으므로 훅의 패닉과 시간 초과는 기록만 한다.
+func (h *WriteHooks) PostWrite(key string, value []byte) {
+	for _, hook := range h.matching(key) {
+		h.wait(key, func() error {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf("Write hook panicked for %s: %v", key, r)
+				}
+			}()
+			hook.PostWriteHook(key, value)
+			return nil
+		})
+	}
+}
+
+// HooksConfig 검증 규칙 설정 파일 형식
+type HooksConfig struct {
+	Rules []ValidationRule `json:"rules"`
+}
+
+// ValidationRule 접두사에 속하는 키의 값 검증 규칙
+type ValidationRule struct {
+	// Prefix 규칙을 적용할 키 접두사
+	Prefix string `json:"prefix"`
+	// MaxBytes 값의 최대 크기 (0이면 제한 없음)
+	MaxBytes int `json:"maxBytes,omitempty"`
+	// JSON 값이 JSON이어야 하는지 여부 (Fields가 있으면 JSON 객체여야 함)
+	JSON bool `json:"json,omitempty"`
+	// Fields JSON 객체의 필드별 규칙
+	Fields map[string]FieldRule `json:"fields,omitempty"`
+}
+
+// FieldRule JSON 필드 검증 규칙
+type FieldRule struct {
+	// Type 필드 유형 (string, number, integer, boolean, object, array; 비어 있으면 검사하지 않음)
+	Type string `json:"type,omitempty"`
+	// Required 필드가 반드시 있어야 하는지 여부
+	Required bool `json:"required,omitempty"`
+	// Min, Max 숫자 필드의 최솟값과 최댓값
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// MaxLength 문자열 필드의 최대 길이 (0이면 제한 없음)
+	MaxLength int `json:"maxLength,omitempty"`
+}
+
+// LoadHooksConfig 검증 규칙 설정 파일 로드
+func LoadHooksConfig(path string) (*HooksConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks config: %w", err)
+	}
+	var config HooksConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks config: %w", err)
+	}
+	for i, rule := range config.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("hooks config rule %d (%s): %w", i, rule.Prefix, err)
+		}
+	}
+	return &config, nil
+}
+
+// validate 규칙 형식 확인
+func (r ValidationRule) validate() error {
+	if r.MaxBytes < 0 {
+		return fmt.Errorf("maxBytes must not be negative")
+	}
+	for name, field := range r.Fields {
+		switch field.Type {
+		case "", "string", "number", "integer", "boolean", "object", "array":
+		default:
+			return fmt.Errorf("field %s: unknown type %q", name, field.Type)
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			return fmt.Errorf("field %s: min is greater than max", name)
+		}
+	}
+	return nil
+}
+
+// ValidationHook 설정 파일의 검증 규칙을 적용하는 쓰기 훅
+type ValidationHook struct {
+	rule ValidationRule
+}
+
+// NewValidationHook 새 검증 훅 생성
+func NewValidationHook(rule ValidationRule) *ValidationHook {
+	return &ValidationHook{rule: rule}
+}
+
+// PreWriteHook 값이 규칙에 맞는지 확인 (삭제는 항상 허용)
+func (h *ValidationHook) PreWriteHook(key string, value []byte) error {
+	if value == nil {
+		return nil
+	}
+	rule := h.rule
+	if rule.MaxBytes > 0 && len(value) > rule.MaxBytes {
+		return fmt.Errorf("value too large (max %d bytes)", rule.MaxBytes)
+	}
+	if !rule.JSON && len(rule.Fields) == 0 {
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return fmt.Errorf("value is not valid JSON")
+	}
+	if len(rule.Fields) == 0 {
+		return nil
+	}
+	object, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("value must be a JSON object")
+	}
+
+	// 에러 메시지가 항상 같도록 필드 이름 순서로 확인
+	names := make([]string, 0, len(rule.Fields))
+	for name := range rule.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, exists := object[name]
+		if !exists {
+			if rule.Fields[name].Required {
+				return fmt.Errorf("field %s is required", name)
+			}
+			continue
+		}
+		if err := rule.Fields[name].check(field); err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// PostWriteHook 검증 훅은 쓰기 후 작업이 없음
+func (h *ValidationHook) PostWriteHook(key string, value []byte) {}
+
+// check JSON 필드 값이 규칙에 맞는지 확인
+func (f FieldRule) check(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		if f.Type != "" && f.Type != "string" {
+			return fmt.Errorf("must be %s", f.Type)
+		}
+		if f.MaxLength > 0 && len([]rune(v)) > f.MaxLength {
+			return fmt.Errorf("longer than %d characters", f.MaxLength)
+		}
+	case float64:
+		switch f.Type {
+		case "", "number":
+		case "integer":
+			if v != math.Trunc(v) {
+				return fmt.Errorf("must be integer")
+			}
+		default:
+			return fmt.Errorf("must be %s", f.Type)
+		}
+		if f.Min != nil && v < *f.Min {
+			return fmt.Errorf("must be at least %v", *f.Min)
+		}
+		if f.Max != nil && v > *f.Max {
+			return fmt.Errorf("must be at most %v", *f.Max)
+		}
+	default:
+		if f.Type != "" && f.Type != jsonType(value) {
+			return fmt.Errorf("must be %s", f.Type)
+		}
+	}
+	return nil
+}
+
+// jsonType 디코딩한 JSON 값의 유형 이름
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHook 호출 순서를 기록하는 테스트용 쓰기 훅
+type recordingHook struct {
+	name  string
+	mu    *sync.Mutex
+	calls *[]string
+	err   error
+	delay time.Duration
+}
+
+func (h recordingHook) record(call string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.calls = append(*h.calls, call)
+}
+
+func (h recordingHook) PreWriteHook(key string, value []byte) error {
+	time.Sleep(h.delay)
+	h.record("pre:" + h.name)
+	return h.err
+}
+
+func (h recordingHook) PostWriteHook(key string, value []byte) {
+	time.Sleep(h.delay)
+	h.record("post:" + h.name)
+}
+
+// newRecordingHooks 호출을 기록하는 훅을 만드는 함수와 기록 조회 함수
+func newRecordingHooks() (func(name string) recordingHook, func() []string) {
+	var mu sync.Mutex
+	var calls []string
+	newHook := func(name string) recordingHook {
+		return recordingHook{name: name, mu: &mu, calls: &calls}
+	}
+	recorded := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), calls...)
+	}
+	return newHook, recorded
+}
+
+// TestWriteHooksOrder 일치하는 훅만 등록 순서대로 호출되는지 확인
+func TestWriteHooksOrder(t *testing.T) {
+	newHook, recorded := newRecordingHooks()
+	hooks := NewWriteHooks()
+	hooks.Register("/game", newHook("game"))
+	hooks.Register("/gameplay", newHook("gameplay"))
+	hooks.Register("/game/gold", newHook("gold"))
+	hooks.Register("/", newHook("root"))
+
+	require.NoError(t, hooks.PreWrite("/game/gold/alice", []byte("10")))
+	hooks.PostWrite("/game/gold/alice", []byte("10"))
+
+	assert.Equal(t, []string{
+		"pre:game", "pre:gold", "pre:root",
+		"post:game", "post:gold", "post:root",
+	}, recorded())
+}
+
+// TestWriteHooksRejection 훅의 에러나 패닉이 쓰기를 거부하고 이후 훅을 호출하지 않는지 확인
+func TestWriteHooksRejection(t *testing.T) {
+	errTooMuchGold := errors.New("too much gold")
+
+	t.Run("error", func(t *testing.T) {
+		newHook, recorded := newRecordingHooks()
+		hooks := NewWriteHooks()
+		hooks.Register("/game", newHook("first"))
+		rejecting := newHook("reject")
+		rejecting.err = errTooMuchGold
+		hooks.Register("/game", rejecting)
+		hooks.Register("/game", newHook("last"))
+
+		err := hooks.PreWrite("/game/gold", []byte("1000000"))
+		var rejected *WriteRejectedError
+		require.ErrorAs(t, err, &rejected)
+		assert.Equal(t, "/game/gold", rejected.Key)
+		assert.ErrorIs(t, err, errTooMuchGold)
+		assert.Equal(t, []string{"pre:first", "pre:reject"}, recorded())
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		hooks := NewWriteHooks()
+		hooks.Register("/game", panicHook{})
+
+		err := hooks.PreWrite("/game/gold", []byte("1"))
+		var rejected *WriteRejectedError
+		require.ErrorAs(t, err, &rejected)
+
+		// 쓰기 후 훅의 패닉은 기록만 함
+		assert.NotPanics(t, func() { hooks.PostWrite("/game/gold", []byte("1")) })
+	})
+
+	t.Run("response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		assert.True(t, writeRejectedError(w, &WriteRejectedError{Key: "/game/gold", Err: errTooMuchGold}))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.False(t, writeRejectedError(httptest.NewRecorder(), errTooMuchGold))
+	})
+}
+
+// panicHook 항상 패닉이 발생하는 테스트용 쓰기 훅
+type panicHook struct{}
+
+func (panicHook) PreWriteHook(key string, value []byte) error { panic("boom") }
+func (panicHook) PostWriteHook(key string, value []byte)      { panic("boom") }
+
+// TestWriteHooksTimeout 제한 시간 안에 끝나지 않는 훅이 쓰기를 거부하는지 확인
+func TestWriteHooksTimeout(t *testing.T) {
+	newHook, recorded := newRecordingHooks()
+	hooks := NewWriteHooks()
+	hooks.timeout = 20 * time.Millisecond
+	slow := newHook("slow")
+	slow.delay = time.Second
+	hooks.Register("/game", slow)
+	hooks.Register("/game", newHook("after"))
+
+	start := time.Now()
+	err := hooks.PreWrite("/game/gold", []byte("1"))
+	var rejected *WriteRejectedError
+	require.ErrorAs(t, err, &rejected)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// 쓰기 후 훅은 느린 훅을 기다리지 않고 다음 훅을 호출
+	start = time.Now()
+	hooks.PostWrite("/game/gold", []byte("1"))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, []string{"post:after"}, recorded())
+
+	// 제한 시간 안에 끝나는 훅은 그대로 통과
+	fast := NewWriteHooks()
+	fast.timeout = time.Second
+	fast.Register("/game", newHook("fast"))
+	assert.NoError(t, fast.PreWrite("/game/gold", []byte("1")))
+}
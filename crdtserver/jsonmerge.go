@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+)
+
+// fieldsPrefix JSON 값의 필드별 버전이 저장되는 예약된 키 접두사
+//
+// JSON 모드의 키는 값과 함께 /_fields/<key> 키에 필드별 값과 버전을 같은 델타로 저장한다.
+// go-ds-crdt는 키 단위로 마지막 쓰기를 선택하므로 다른 노드에서 동시에 쓴 값 중 하나는
+// 통째로 버려지지만, 필드별 버전이 함께 복제되므로 PutHook에서 필드 단위로 다시 병합할 수 있다.
+const fieldsPrefix = "/_fields"
+
+// jsonMergeCachePrefix 노드가 마지막으로 병합한 필드 상태를 보관하는 키 접두사 (복제되지 않음)
+const jsonMergeCachePrefix = "/crdtserver/jsonfields"
+
+// namespaceJSONMergeCachePrefix 네임스페이스 키의 병합한 필드 상태를 보관하는 키 접두사
+// (/crdtserver/nsjsonfields/<네임스페이스 이름>/<key>)
+const namespaceJSONMergeCachePrefix = "/crdtserver/nsjsonfields"
+
+// fieldsKey 키의 필드별 버전이 저장되는 키
+func fieldsKey(key ds.Key) ds.Key {
+	return ds.NewKey(fieldsPrefix).Child(key)
+}
+
+// fieldVersion JSON 필드 하나의 마지막 변경
+type fieldVersion struct {
+	// Value 필드 값 (삭제된 필드는 비어 있음)
+	Value json.RawMessage `json:"value,omitempty"`
+	// Deleted 필드가 삭제되었는지 여부
+	Deleted bool `json:"deleted,omitempty"`
+	// Time 변경 시간 (Unix 밀리초, 같은 필드의 이전 버전보다 항상 큼)
+	Time int64 `json:"t"`
+	// Peer 변경한 피어 (시간이 같으면 큰 피어 ID가 이김)
+	Peer string `json:"p"`
+}
+
+// newer 다른 버전보다 나중의 변경인지 확인
+func (v fieldVersion) newer(other fieldVersion) bool {
+	if v.Time != other.Time {
+		return v.Time > other.Time
+	}
+	return v.Peer > other.Peer
+}
+
+// jsonFields JSON 객체의 필드별 버전
+type jsonFields map[string]fieldVersion
+
+// mergeJSONFields 두 필드 상태를 필드마다 마지막 변경을 선택해 병합
+func mergeJSONFields(a, b jsonFields) jsonFields {
+	merged := make(jsonFields, len(a)+len(b))
+	for name, version := range a {
+		merged[name] = version
+	}
+	for name, version := range b {
+		if current, ok := merged[name]; !ok || version.newer(current) {
+			merged[name] = version
+		}
+	}
+	return merged
+}
+
+// object 삭제되지 않은 필드로 만든 JSON 객체 (필드 이름 순서로 정렬)
+func (f jsonFields) object() []byte {
+	object := make(map[string]json.RawMessage, len(f))
+	for name, version := range f {
+		if !version.Deleted {
+			object[name] = version.Value
+		}
+	}
+	data, _ := json.Marshal(object)
+	return data
+}
+
+// live 삭제되지 않은 필드가 있는지 확인
+func (f jsonFields) live() bool {
+	for _, version := range f {
+		if !version.Deleted {
+			return true
+		}
+	}
+	return false
+}
+
+// encode 필드 상태를 저장 형식으로 변환
+func (f jsonFields) encode() []byte {
+	data, _ := json.Marshal(f)
+	return data
+}
+
+// decodeJSONFields 저장된 필드 상태 디코딩
+func decodeJSONFields(data []byte) (jsonFields, error) {
+	var fields jsonFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid field versions: %w", err)
+	}
+	return fields, nil
+}
+
+// parseJSONObject 값을 필드별 JSON 값으로 파싱 (공백을 제거해 같은 값은 같은 바이트가 되게 함)
+func parseJSONObject(value []byte) (map[string]json.RawMessage, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(value, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("value must be a JSON object")
+	}
+	for name, raw := range object {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return nil, err
+		}
+		object[name] = compact.Bytes()
+	}
+	return object, nil
+}
+
+// jsonObjectHook JSON 모드 접두사에 JSON 객체가 아닌 값의 쓰기를 거부하는 훅
+type jsonObjectHook struct{}
+
+// PreWriteHook 값이 JSON 객체인지 확인 (삭제는 항상 허용)
+func (jsonObjectHook) PreWriteHook(key string, value []byte) error {
+	if value == nil {
+		return nil
+	}
+	_, err := parseJSONObject(value)
+	return err
+}
+
+// PostWriteHook 쓰기 후 작업 없음
+func (jsonObjectHook) PostWriteHook(key string, value []byte) {}
+
+// JSONMerger JSON 모드 키의 필드 단위 병합
+//
+// CRDT의 PutHook은 go-ds-crdt가 값을 반영하는 도중에 호출되어 그 안에서 다시 쓸 수 없으므로,
+// 변경된 키를 모아 두었다가 별도 고루틴(runJSONMerger)에서 병합한다.
+// JSON 모드 접두사는 데이터스토어의 범위를 붙인 키(dataStore.scopedKey)에 적용된다.
+type JSONMerger struct {
+	prefixes []string
+
+	mu      sync.Mutex
+	pending map[pendingMerge]struct{}
+	wake    chan struct{}
+}
+
+// pendingMerge 병합할 데이터스토어와 키
+type pendingMerge struct {
+	data *dataStore
+	key  string
+}
+
+// NewJSONMerger 새 JSON 병합기 생성 (접두사가 없으면 nil)
+func NewJSONMerger(prefixes []string) *JSONMerger {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	normalized := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		normalized = append(normalized, ds.NewKey(prefix).String())
+	}
+	return &JSONMerger{
+		prefixes: normalized,
+		pending:  make(map[pendingMerge]struct{}),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// matches 데이터스토어의 키가 JSON 모드 접두사에 속하는지 확인 (예약된 키는 제외)
+func (m *JSONMerger) matches(d *dataStore, key ds.Key) bool {
+	if m == nil || isReservedKey(key) {
+		return false
+	}
+	scoped := d.scopedKey(key)
+	for _, prefix := range m.prefixes {
+		if inNamespace(scoped, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleChange CRDT가 필드 버전 키에 값을 반영하면 병합할 키로 등록 (CRDT PutHook에서 호출)
+func (m *JSONMerger) handleChange(d *dataStore, k ds.Key) {
+	if m == nil || !inNamespace(k.String(), fieldsPrefix) {
+		return
+	}
+	key := ds.NewKey(strings.TrimPrefix(k.String(), fieldsPrefix))
+	if !m.matches(d, key) {
+		return
+	}
+
+	m.mu.Lock()
+	m.pending[pendingMerge{data: d, key: key.String()}] = struct{}{}
+	m.mu.Unlock()
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take 병합할 키를 모두 꺼냄 (키 순서)
+func (m *JSONMerger) take() []pendingMerge {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	merges := make([]pendingMerge, 0, len(m.pending))
+	for merge := range m.pending {
+		merges = append(merges, merge)
+	}
+	m.pending = make(map[pendingMerge]struct{})
+	sort.Slice(merges, func(i, j int) bool { return merges[i].key < merges[j].key })
+	return merges
+}
+
+// runJSONMerger 변경된 JSON 모드 키를 필드 단위로 병합
+func (s *Server) runJSONMerger() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.jsonMerger.wake:
+			for _, merge := range s.jsonMerger.take() {
+				key := ds.NewKey(merge.key)
+				if err := s.mergeJSONKey(s.ctx, merge.data, key); err != nil {
+					logger.Errorf("Failed to merge JSON fields of %s: %v", merge.data.scopedKey(key), err)
+				}
+			}
+		}
+	}
+}
+
+// jsonMergeCacheKey 노드가 마지막으로 병합한 필드 상태를 보관하는 키
+func (d *dataStore) jsonMergeCacheKey(key ds.Key) ds.Key {
+	return d.cachePrefix.Child(key)
+}
+
+// knownFields 노드가 알고 있는 필드 상태 (복제된 필드 버전과 마지막으로 병합한 상태를 병합)
+// 필드 버전이 저장된 적 없는 키는 nil을 반환한다.
+func (s *Server) knownFields(ctx context.Context, d *dataStore, key ds.Key) (jsonFields, error) {
+	data, err := d.crdt.Get(ctx, fieldsKey(key))
+	if err == ds.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	replicated, err := decodeJSONFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := s.store.Get(ctx, d.jsonMergeCacheKey(key))
+	if err == ds.ErrNotFound {
+		return replicated, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	local, err := decodeJSONFields(cached)
+	if err != nil {
+		return replicated, nil
+	}
+	return mergeJSONFields(local, replicated), nil
+}
+
+// mergeJSONKey 복제된 필드 버전과 노드가 알고 있던 필드 버전을 병합
+//
+// 다른 노드의 동시 쓰기가 키 단위로 이겨 이 노드에서 쓴 필드가 사라졌으면, 필드 단위로
+// 병합한 값을 새 델타로 다시 쓴다. 병합은 결정적이므로 모든 노드가 같은 값에 수렴하고,
+// 병합한 값이 이미 저장된 값과 같으면 다시 쓰지 않으므로 반복되지 않는다.
+func (s *Server) mergeJSONKey(ctx context.Context, d *dataStore, key ds.Key) error {
+	d.batchMu.Lock()
+	defer d.batchMu.Unlock()
+
+	merged, err := s.knownFields(ctx, d, key)
+	if err != nil || merged == nil {
+		return err
+	}
+	if err := s.store.Put(ctx, d.jsonMergeCacheKey(key), merged.encode()); err != nil {
+		return err
+	}
+
+	replicated, _ := d.crdt.Get(ctx, fieldsKey(key))
+	current, err := d.crdt.Get(ctx, key)
+	exists := err == nil
+	if bytes.Equal(replicated, merged.encode()) {
+		if merged.live() && exists && bytes.Equal(current, merged.object()) {
+			return nil
+		}
+		if !merged.live() && !exists {
+			return nil
+		}
+	}
+
+	logger.Debugf("Rewriting JSON fields of %s after concurrent writes", key)
+	return s.commitBatchLocked(ctx, d, func(batch ds.Batch) error {
+		if err := batch.Put(ctx, fieldsKey(key), merged.encode()); err != nil {
+			return err
+		}
+		if merged.live() {
+			return batch.Put(ctx, key, merged.object())
+		}
+		if exists {
+			return batch.Delete(ctx, key)
+		}
+		return nil
+	})
+}
+
+// stageFields JSON 모드 키의 필드 버전을 배치에 추가하고 키에 저장할 값을 반환
+//
+// 새 값을 노드가 알고 있는 현재 값과 필드별로 비교하여 바뀐 필드와 빠진 필드(삭제)만 새
+// 버전을 붙이고, 나머지 필드는 이전 버전을 유지한다. value가 nil이면(키 삭제) 모든 필드를
+// 삭제된 버전으로 남겨, 나중에 같은 키를 다시 만들거나 동시 쓰기와 병합할 때 삭제 전의 필드가
+// 되살아나지 않게 한다. JSON 모드가 아닌 키는 값을 그대로 반환한다.
+// d.batchMu를 잡은 상태에서 호출해야 한다.
+func (s *Server) stageFields(ctx context.Context, d *dataStore, batch ds.Batch, key ds.Key, value []byte) ([]byte, error) {
+	if !s.jsonMerger.matches(d, key) {
+		return value, nil
+	}
+
+	var object map[string]json.RawMessage
+	if value != nil {
+		var err error
+		object, err = parseJSONObject(value)
+		if err != nil {
+			return nil, &WriteRejectedError{Key: d.scopedKey(key), Err: err}
+		}
+	}
+	known, err := s.knownFields(ctx, d, key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil && known == nil {
+		return nil, nil
+	}
+
+	now := time.Now().UnixMilli()
+	self := s.host.ID().String()
+	next := func(previous fieldVersion) fieldVersion {
+		t := now
+		if t <= previous.Time {
+			t = previous.Time + 1
+		}
+		return fieldVersion{Time: t, Peer: self}
+	}
+
+	fields := make(jsonFields, len(object)+len(known))
+	for name, previous := range known {
+		fields[name] = previous
+		if _, ok := object[name]; !ok && !previous.Deleted {
+			version := next(previous)
+			version.Deleted = true
+			fields[name] = version
+		}
+	}
+	for name, raw := range object {
+		previous, ok := known[name]
+		if ok && !previous.Deleted && bytes.Equal(previous.Value, raw) {
+			continue
+		}
+		version := next(previous)
+		version.Value = raw
+		fields[name] = version
+	}
+
+	if err := batch.Put(ctx, fieldsKey(key), fields.encode()); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return fields.object(), nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// relayBroadcaster 테스트 서버끼리 델타를 전달하는 브로드캐스터
+// 연결을 끊으면 보낼 델타를 모아 두었다가 다시 연결할 때 전달하므로 동시 쓰기를 만들 수 있다.
+type relayBroadcaster struct {
+	inbox chan []byte
+
+	mu           sync.Mutex
+	peer         *relayBroadcaster
+	disconnected bool
+	held         [][]byte
+}
+
+// newRelayPair 서로 연결된 브로드캐스터 두 개
+func newRelayPair() (*relayBroadcaster, *relayBroadcaster) {
+	a := &relayBroadcaster{inbox: make(chan []byte, 1024)}
+	b := &relayBroadcaster{inbox: make(chan []byte, 1024), peer: a}
+	a.peer = b
+	return a, b
+}
+
+func (r *relayBroadcaster) Broadcast(ctx context.Context, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.disconnected {
+		r.held = append(r.held, data)
+		return nil
+	}
+	r.peer.inbox <- data
+	return nil
+}
+
+func (r *relayBroadcaster) Next(ctx context.Context) ([]byte, error) {
+	select {
+	case data := <-r.inbox:
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// setConnected 연결을 끊거나 다시 연결 (다시 연결하면 모아 둔 델타 전달)
+func (r *relayBroadcaster) setConnected(connected bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disconnected = !connected
+	if connected {
+		for _, data := range r.held {
+			r.peer.inbox <- data
+		}
+		r.held = nil
+	}
+}
+
+// newTestReplicaPair 델타를 서로 전달하는 테스트 서버 두 개
+func newTestReplicaPair(t *testing.T, config Config) (a, b *Server, relayA, relayB *relayBroadcaster) {
+	t.Helper()
+	bstore := blockstore.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	relayA, relayB = newRelayPair()
+	a = newTestReplica(t, config, relayA, bstore)
+	b = newTestReplica(t, config, relayB, bstore)
+	return a, b, relayA, relayB
+}
+
+// disconnect 두 브로드캐스터의 연결을 끊고, 반환한 함수로 다시 연결
+func disconnect(relays ...*relayBroadcaster) func() {
+	for _, relay := range relays {
+		relay.setConnected(false)
+	}
+	return func() {
+		for _, relay := range relays {
+			relay.setConnected(true)
+		}
+	}
+}
+
+// putData 데이터 API로 값 저장
+func putData(t *testing.T, s *Server, key, value string) {
+	t.Helper()
+	w := serveTest(s, http.MethodPut, "/api/data/"+key, value)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+// requireConverged 두 서버의 키 값이 want로 수렴할 때까지 대기 (want가 비어 있으면 같은 값이면 됨)
+func requireConverged(t *testing.T, a, b *Server, key, want string) string {
+	t.Helper()
+	var value string
+	require.Eventually(t, func() bool {
+		va, errA := a.getValue(context.Background(), a.data, ds.NewKey(key))
+		vb, errB := b.getValue(context.Background(), b.data, ds.NewKey(key))
+		if errA != nil || errB != nil || string(va) != string(vb) {
+			return false
+		}
+		value = string(va)
+		return want == "" || jsonEqual(value, want)
+	}, 10*time.Second, 20*time.Millisecond, "%s did not converge to %s", key, want)
+	return value
+}
+
+// jsonEqual 두 JSON 값이 같은지 확인
+func jsonEqual(a, b string) bool {
+	objectA, errA := parseJSONObject([]byte(a))
+	objectB, errB := parseJSONObject([]byte(b))
+	if errA != nil || errB != nil || len(objectA) != len(objectB) {
+		return false
+	}
+	for name, value := range objectA {
+		if string(objectB[name]) != string(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestJSONMergeConcurrentFieldEdits 두 노드가 같은 키의 다른 필드를 동시에 바꾸면 두 변경이 모두 남는지 확인
+func TestJSONMergeConcurrentFieldEdits(t *testing.T) {
+	a, b, relayA, relayB := newTestReplicaPair(t, Config{JSONPrefixes: []string{"/players"}})
+	putData(t, a, "players/alice", `{"hp":100,"mp":50}`)
+	requireConverged(t, a, b, "/players/alice", `{"hp":100,"mp":50}`)
+
+	reconnect := disconnect(relayA, relayB)
+	putData(t, a, "players/alice", `{"hp":90,"mp":50}`)
+	putData(t, b, "players/alice", `{"hp":100,"mp":40,"gold":5}`)
+	reconnect()
+
+	requireConverged(t, a, b, "/players/alice", `{"hp":90,"mp":40,"gold":5}`)
+
+	// 같은 필드를 동시에 바꾸면 한쪽 값으로 수렴
+	reconnect = disconnect(relayA, relayB)
+	putData(t, a, "players/alice", `{"hp":80,"mp":40,"gold":5}`)
+	putData(t, b, "players/alice", `{"hp":70,"mp":40,"gold":5}`)
+	reconnect()
+	value := requireConverged(t, a, b, "/players/alice", "")
+	assert.True(t, jsonEqual(value, `{"hp":80,"mp":40,"gold":5}`) || jsonEqual(value, `{"hp":70,"mp":40,"gold":5}`), value)
+}
+
+// TestJSONMergeOmittedFieldTombstone PUT에서 빠진 필드가 삭제된 필드로 남아 동시 쓰기와 병합해도 되살아나지 않는지 확인
+func TestJSONMergeOmittedFieldTombstone(t *testing.T) {
+	a, b, relayA, relayB := newTestReplicaPair(t, Config{JSONPrefixes: []string{"/players"}})
+	putData(t, a, "players/alice", `{"hp":100,"mp":50}`)
+	requireConverged(t, a, b, "/players/alice", `{"hp":100,"mp":50}`)
+
+	// a는 mp를 빼고, b는 mp를 그대로 둔 채 gold를 추가
+	reconnect := disconnect(relayA, relayB)
+	putData(t, a, "players/alice", `{"hp":100}`)
+	putData(t, b, "players/alice", `{"hp":100,"mp":50,"gold":5}`)
+	reconnect()
+
+	requireConverged(t, a, b, "/players/alice", `{"hp":100,"gold":5}`)
+	fields, err := b.knownFields(context.Background(), b.data, ds.NewKey("/players/alice"))
+	require.NoError(t, err)
+	assert.True(t, fields["mp"].Deleted)
+
+	// 키를 삭제하고 다시 만들어도 삭제 전의 필드는 되살아나지 않음
+	w := serveTest(a, http.MethodDelete, "/api/data/players/alice", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Eventually(t, func() bool {
+		_, err := b.getValue(context.Background(), b.data, ds.NewKey("/players/alice"))
+		return err == ds.ErrNotFound
+	}, 10*time.Second, 20*time.Millisecond)
+	putData(t, b, "players/alice", `{"level":1}`)
+	requireConverged(t, a, b, "/players/alice", `{"level":1}`)
+}
+
+// TestJSONMergeNonJSONFallback JSON 모드가 아닌 키는 값 전체 중 하나로 수렴하고, JSON 모드 키에는 JSON 객체만 쓸 수 있는지 확인
+func TestJSONMergeNonJSONFallback(t *testing.T) {
+	a, b, relayA, relayB := newTestReplicaPair(t, Config{JSONPrefixes: []string{"/players"}})
+
+	reconnect := disconnect(relayA, relayB)
+	putData(t, a, "scores/alice", `{"hp":90}`)
+	putData(t, b, "scores/alice", `{"mp":40}`)
+	putData(t, a, "banner", "spring event")
+	putData(t, b, "banner", "summer event")
+	reconnect()
+
+	value := requireConverged(t, a, b, "/scores/alice", "")
+	assert.Contains(t, []string{`{"hp":90}`, `{"mp":40}`}, value)
+	value = requireConverged(t, a, b, "/banner", "")
+	assert.Contains(t, []string{"spring event", "summer event"}, value)
+
+	// 필드 버전은 JSON 모드 키에만 저장됨
+	fields, err := a.knownFields(context.Background(), a.data, ds.NewKey("/scores/alice"))
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+
+	for _, value := range []string{"100", `"alice"`, `[1,2]`, "not json"} {
+		w := serveTest(a, http.MethodPut, "/api/data/players/alice", value)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, value)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"tictactoe/luvjson/p2phost"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// NewHost 설정에 따라 libp2p 호스트 생성
+//
+// ListenAddrs의 멀티주소 형식에 따라 TCP(/tcp), QUIC(/udp/.../quic-v1),
+// WebSocket(/tcp/.../ws) 전송이 사용된다. AnnounceAddrs가 있으면 다른 피어와
+// 피어 레지스트리에 수신 주소 대신 이 주소를 알린다 (NAT, 로드 밸런서 뒤에서 실행하는 경우).
+// 연결 보안은 P2PSecurity 순서대로 협상한다. crdtpubsub의 libp2p 전송과 같은
+// p2phost 패키지로 호스트를 만든다.
+func NewHost(config Config) (host.Host, error) {
+	return p2phost.New(p2phost.Options{
+		ListenAddrs:   config.ListenAddrs,
+		AnnounceAddrs: config.AnnounceAddrs,
+		Security:      config.P2PSecurity,
+	})
+}
+
+// validateTLSConfig HTTP TLS 설정 확인
+func validateTLSConfig(config Config) error {
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return fmt.Errorf("both --tls-cert and --tls-key are required")
+	}
+	if config.TLSCertFile != "" && len(config.AutocertDomains) > 0 {
+		return fmt.Errorf("--tls-cert and --autocert-domains are mutually exclusive")
+	}
+	return nil
+}
+
+// newTLSConfig 설정에 따라 HTTP와 gRPC 서버가 함께 사용하는 TLS 설정 생성
+//
+// 인증서 파일이 있으면 그 인증서를, AutocertDomains가 있으면 Let's Encrypt에서
+// 자동으로 발급받은 인증서를 사용한다. 자동 발급은 TLS-ALPN-01 챌린지를 사용하므로
+// 서버가 해당 도메인의 443 포트로 접근 가능해야 한다. TLS를 사용하지 않으면 nil을 반환한다.
+func newTLSConfig(config Config) (*tls.Config, error) {
+	switch {
+	case config.TLSCertFile != "":
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+
+	case len(config.AutocertDomains) > 0:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.AutocertDomains...),
+			Cache:      autocert.DirCache(config.AutocertCacheDir),
+		}
+		return manager.TLSConfig(), nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// listenAndServe TLS 설정에 따라 HTTP 또는 HTTPS로 서버 시작
+func (s *Server) listenAndServe() error {
+	switch {
+	case s.tlsConfig == nil:
+		logger.Infof("HTTP server listening on %s", s.server.Addr)
+		return s.server.ListenAndServe()
+
+	case len(s.config.AutocertDomains) > 0:
+		s.server.TLSConfig = s.tlsConfig
+		logger.Infof("HTTPS server listening on %s (autocert: %s)", s.server.Addr, strings.Join(s.config.AutocertDomains, ", "))
+		return s.server.ListenAndServeTLS("", "")
+
+	default:
+		s.server.TLSConfig = s.tlsConfig
+		logger.Infof("HTTPS server listening on %s", s.server.Addr)
+		return s.server.ListenAndServeTLS("", "")
+	}
+}
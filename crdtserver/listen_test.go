@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClosingHost 테스트가 끝나면 닫히는 libp2p 호스트
+func newClosingHost(t *testing.T, config Config) host.Host {
+	t.Helper()
+	h, err := NewHost(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+// TestNewHostListenAddrs 멀티주소 형식에 따라 TCP, QUIC, WebSocket으로 수신하고 알릴 주소를 바꾸는지 확인
+func TestNewHostListenAddrs(t *testing.T) {
+	h := newClosingHost(t, Config{ListenAddrs: []string{
+		"/ip4/127.0.0.1/tcp/0",
+		"/ip4/127.0.0.1/udp/0/quic-v1",
+		"/ip4/127.0.0.1/tcp/0/ws",
+	}})
+	var transports []string
+	for _, addr := range h.Addrs() {
+		switch s := addr.String(); {
+		case strings.HasSuffix(s, "/ws"):
+			transports = append(transports, "ws")
+		case strings.HasSuffix(s, "/quic-v1"):
+			transports = append(transports, "quic")
+		case strings.Contains(s, "/tcp/"):
+			transports = append(transports, "tcp")
+		}
+	}
+	assert.ElementsMatch(t, []string{"tcp", "quic", "ws"}, transports)
+
+	announced := newClosingHost(t, Config{
+		ListenAddrs:   []string{"/ip4/127.0.0.1/tcp/0"},
+		AnnounceAddrs: []string{"/dns4/raid.example/tcp/4001"},
+	})
+	require.Len(t, announced.Addrs(), 1)
+	assert.Equal(t, "/dns4/raid.example/tcp/4001", announced.Addrs()[0].String())
+
+	_, err := NewHost(Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}, AnnounceAddrs: []string{"raid.example:4001"}})
+	assert.ErrorContains(t, err, "invalid announce address")
+	_, err = NewHost(Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}, P2PSecurity: []string{"plaintext"}})
+	assert.ErrorContains(t, err, "unknown p2p security protocol")
+}
+
+// TestNewHostSecurity P2PSecurity에 공통 프로토콜이 있는 피어끼리만 연결되는지 확인
+func TestNewHostSecurity(t *testing.T) {
+	connect := func(a, b host.Host) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return a.Connect(ctx, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()})
+	}
+	listen := []string{"/ip4/127.0.0.1/tcp/0"}
+	tlsOnly := newClosingHost(t, Config{ListenAddrs: listen, P2PSecurity: []string{"tls"}})
+	noiseOnly := newClosingHost(t, Config{ListenAddrs: listen, P2PSecurity: []string{"noise"}})
+	both := newClosingHost(t, Config{ListenAddrs: listen, P2PSecurity: []string{"noise", "tls"}})
+
+	assert.Error(t, connect(tlsOnly, noiseOnly))
+	assert.NoError(t, connect(tlsOnly, both))
+	assert.NoError(t, connect(noiseOnly, both))
+}
+
+// writeTestCert 127.0.0.1용 자체 서명 인증서와 키 파일 생성
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "crdtserver test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+// TestValidateTLSConfig 인증서와 키가 함께 설정되고 자동 발급과 함께 쓰이지 않는지 확인
+func TestValidateTLSConfig(t *testing.T) {
+	assert.NoError(t, validateTLSConfig(Config{}))
+	assert.NoError(t, validateTLSConfig(Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}))
+	assert.NoError(t, validateTLSConfig(Config{AutocertDomains: []string{"raid.example"}}))
+	assert.Error(t, validateTLSConfig(Config{TLSCertFile: "cert.pem"}))
+	assert.Error(t, validateTLSConfig(Config{TLSKeyFile: "key.pem"}))
+	assert.Error(t, validateTLSConfig(Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", AutocertDomains: []string{"raid.example"}}))
+}
+
+// TestNewTLSConfig 인증서 파일로 만든 TLS 설정으로 HTTPS 요청을 처리하는지 확인
+func TestNewTLSConfig(t *testing.T) {
+	tlsConfig, err := newTLSConfig(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	certFile, keyFile := writeTestCert(t)
+	tlsConfig, err = newTLSConfig(Config{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	require.NoError(t, err)
+
+	s := newTestServer(t, Config{})
+	putData(t, s, "boss/hp", "100")
+	server := httptest.NewUnstartedServer(s.server.Handler)
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	certPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	require.True(t, pool.AppendCertsFromPEM(certPEM))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(server.URL + "/api/data/boss/hp")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// 자동 발급은 TLS-ALPN-01 챌린지를 처리
+	tlsConfig, err = newTLSConfig(Config{AutocertDomains: []string{"raid.example"}, AutocertCacheDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.GetCertificate)
+	assert.Contains(t, tlsConfig.NextProtos, "acme-tls/1")
+
+	_, err = newTLSConfig(Config{TLSCertFile: filepath.Join(t.TempDir(), "missing.pem"), TLSKeyFile: keyFile})
+	assert.ErrorContains(t, err, "failed to load TLS certificate")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"flag"
@@ -13,23 +14,26 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 	"unicode/utf8"
 
 	"github.com/go-redis/redis/v8"
-	"github.com/google/uuid"
 	ds "github.com/ipfs/go-datastore"
 	dsquery "github.com/ipfs/go-datastore/query"
 	crdt "github.com/ipfs/go-ds-crdt"
 	blockstore "github.com/ipfs/go-ipfs-blockstore"
 	format "github.com/ipfs/go-ipld-format"
 	logging "github.com/ipfs/go-log/v2"
-	"github.com/libp2p/go-libp2p"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 )
 
 var logger = logging.Logger("crdtserver")
@@ -37,6 +41,7 @@ var logger = logging.Logger("crdtserver")
 // Config 서버 설정
 type Config struct {
 	HTTPPort       int
+	GRPCPort       int // gRPC 서버 포트 (0이면 비활성화)
 	RedisAddr      string
 	RedisPassword  string
 	RedisDB        int
@@ -44,12 +49,55 @@ type Config struct {
 	BootstrapPeers string
 	DataNamespace  string
 	Debug          bool
-	UseIPFSLite    bool // IPFS-Lite 사용 여부
+	LogLevel       string           // 로그 레벨 (비어 있으면 Debug에 따라 debug 또는 info)
+	UseIPFSLite    bool             // IPFS-Lite 사용 여부
+	AuthConfigPath string           // 인증 설정 파일 경로 (비어 있으면 인증 비활성화)
+	HookRulesPath  string           // 검증 규칙 설정 파일 경로 (비어 있으면 플러그인 훅만 사용)
+	CORSOrigins    []string         // 허용된 CORS와 WebSocket Origin (비어 있으면 모든 Origin 허용, WebSocket은 인증이 꺼져 있으면 같은 Origin만)
+	JSONPrefixes   []string         // 값을 JSON 객체로 보고 필드 단위로 병합할 키 접두사 (비어 있으면 비활성화)
+	ConfigFile     string           // 설정 파일 경로 (비어 있으면 명령줄 설정만 사용)
+	SetFlags       map[string]bool  // 명령줄에서 지정한 플래그 (설정 파일의 값보다 우선)
+	Auth           *AuthConfig      // 설정 파일에 적은 인증 설정 (AuthConfigPath가 없을 때 사용)
+	HookRules      []ValidationRule // 설정 파일에 적은 검증 규칙
+	// Namespaces 시작할 때 없으면 만들 데이터 네임스페이스
+	Namespaces []NamespaceConfig
+
+	// libp2p 네트워크 설정
+	ListenAddrs   []string // libp2p 수신 멀티주소 (비어 있으면 TCP, QUIC, WebSocket 임의 포트)
+	AnnounceAddrs []string // 다른 피어에게 알릴 외부 멀티주소 (비어 있으면 수신 주소)
+	P2PSecurity   []string // 연결 보안 프로토콜 우선순위 (noise, tls; 비어 있으면 libp2p 기본값)
+
+	// HTTP TLS 설정
+	TLSCertFile      string   // TLS 인증서 파일
+	TLSKeyFile       string   // TLS 키 파일
+	AutocertDomains  []string // Let's Encrypt 자동 인증서 도메인
+	AutocertCacheDir string   // 자동 인증서 캐시 디렉터리
+
+	TTLInterval   time.Duration // 만료된 키 정리 주기
+	MergeLogSize  int           // 보관할 원격 병합 기록 수
+	SSEReplaySize int           // 재연결한 SSE 클라이언트에게 다시 보낼 수 있도록 보관하는 이벤트 수
+
+	// 요청 제한 설정
+	RateLimit    float64 // 클라이언트별 초당 요청 수 (0이면 제한 없음)
+	RateBurst    int     // 클라이언트별 최대 연속 요청 수
+	MaxBodyBytes int64   // 데이터 API 요청 본문 최대 크기 (0이면 제한 없음)
+	TrustProxy   bool    // X-Forwarded-For 헤더로 클라이언트 IP 확인
+
+	// OpenTelemetry 트레이싱 설정
+	TracingEndpoint    string  // OTLP gRPC 수집기 주소 (비어 있으면 트레이싱 비활성화)
+	TracingInsecure    bool    // 수집기에 TLS 없이 연결
+	TracingSampleRatio float64 // 새 트레이스를 기록하는 비율 (0~1)
+
+	// 보스 레이드 게임 서버 설정
+	EnableGame bool
+	GamePort   int
+	ClientDir  string
 }
 
 // Server CRDT 서버 구조체
 type Server struct {
 	config       Config
+	flagConfig   Config // 설정 파일을 적용하기 전의 명령줄 설정 (설정 다시 읽기에 사용)
 	host         host.Host
 	pubsub       *pubsub.PubSub
 	topic        *pubsub.Topic
@@ -58,17 +106,47 @@ type Server struct {
 	crdt         *crdt.Datastore
 	bstore       blockstore.Blockstore
 	dagService   format.DAGService
+	broadcaster  *PubSubBroadcaster
+	merges       *MergeLog
 	server       *http.Server
+	grpcServer   *grpc.Server
+	tlsConfig    *tls.Config // HTTP와 gRPC 서버의 TLS 설정 (nil이면 TLS 비활성화)
 	mux          *http.ServeMux
 	ctx          context.Context
 	cancel       context.CancelFunc
 	redisClient  *redis.Client
 	peerRegistry *PeerRegistry
+	// luvjson 문서 저장소
+	docs *DocumentStore
+	// WebSocket 동기화 허브
+	syncHub *SyncHub
+	// 인증기 (nil이면 인증 비활성화)
+	auth *Authenticator
+	// 데이터 API 요청 제한 (설정 다시 읽기로 바뀔 수 있음)
+	limits atomic.Pointer[requestLimits]
+	// 데이터 API 쓰기 훅
+	hooks *WriteHooks
+	// JSON 모드 키의 필드 단위 병합기 (nil이면 JSON 모드 비활성화)
+	jsonMerger *JSONMerger
+	// 데이터 네임스페이스 관리자
+	namespaces *NamespaceManager
+	// 기본 데이터 네임스페이스의 데이터 API 저장소
+	data *dataStore
+	// 안티 엔트로피 복구를 한 번에 하나만 실행
+	repairMu sync.Mutex
 	// SSE 관련 필드
-	sseClients   map[string]chan []byte
+	sseClients   map[string]*sseClient
 	sseClientsMu sync.Mutex
+	// sseSeq 마지막 SSE 이벤트의 시퀀스 번호 (sseClientsMu로 보호)
+	sseSeq uint64
+	// sseEpoch 재연결 커서에 붙는 서버 실행 식별자
+	sseEpoch string
+	// sseHistory 재연결한 클라이언트에게 다시 보낼 최근 이벤트 (sseClientsMu로 보호, nil이면 비활성화)
+	sseHistory *sseHistory
 	// 서버 시작 시간
 	startTime time.Time
+	// 트레이스 내보내기 (nil이면 트레이싱 비활성화)
+	tracerProvider *sdktrace.TracerProvider
 }
 
 func NewDAGService(bs blockstore.Blockstore, useIPFSLite bool, ctx context.Context) (format.DAGService, error) {
@@ -87,23 +165,37 @@ func NewDAGService(bs blockstore.Blockstore, useIPFSLite bool, ctx context.Conte
 
 // NewServer 새 서버 인스턴스 생성
 func NewServer(config Config) (*Server, error) {
+	// 설정 파일 적용
+	flagConfig := config
+	config, err := config.withFile()
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// 로깅 설정
-	if config.Debug {
-		logging.SetLogLevel("*", "debug")
-	} else {
-		logging.SetLogLevel("*", "info")
+	if err := logging.SetLogLevel("*", config.logLevel()); err != nil {
+		cancel()
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	// TLS 설정 확인
+	if err := validateTLSConfig(config); err != nil {
+		cancel()
+		return nil, err
+	}
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
 
 	// libp2p 호스트 생성
-	h, err := libp2p.New(
-		libp2p.ListenAddrStrings("/ip4/0.0.0.0/tcp/0"),
-		libp2p.DisableRelay(),
-	)
+	h, err := NewHost(config)
 	if err != nil {
 		cancel()
-		return nil, fmt.Errorf("failed to create libp2p host: %w", err)
+		return nil, err
 	}
 
 	// 호스트 정보 출력
@@ -202,23 +294,27 @@ func NewServer(config Config) (*Server, error) {
 	// PubSub 브로드캐스터 생성
 	broadcaster := NewPubSubBroadcaster(ctx, topic, subscription)
 
+	// 원격 병합 기록
+	merges := NewMergeLog(config.MergeLogSize)
+	merges.origins = NewBlockOrigins(redisDatastore, h.ID().String())
+	broadcaster.merges = merges
+
 	// CRDT 데이터스토어 생성
-	opts := crdt.DefaultOptions()
-	// 재브로드캐스트 주기 (기본값 5분)
-	opts.RebroadcastInterval = time.Minute * 5
-	// 동기화 주기 (기본값 1초)
-	opts.DAGSyncerTimeout = time.Second * 1
-	// 병합 주기 (기본값 1초)
-	opts.RepairInterval = time.Second * 1
+	opts := newCRDTOptions()
+	syncHub := NewSyncHub(ctx)
+	jsonMerger := NewJSONMerger(config.JSONPrefixes)
+	data := &dataStore{scope: ds.NewKey("/"), cachePrefix: ds.NewKey(jsonMergeCachePrefix)}
 	opts.PutHook = func(k ds.Key, v []byte) {
 		logger.Debugf("CRDT Put: %s", k)
+		syncHub.handlePut(k, v)
+		jsonMerger.handleChange(data, k)
 	}
 	opts.DeleteHook = func(k ds.Key) {
 		logger.Debugf("CRDT Delete: %s", k)
 	}
 
 	namespace := ds.NewKey(config.DataNamespace)
-	crdtDatastore, err := crdt.New(redisDatastore, namespace, dagService, broadcaster, opts)
+	crdtDatastore, err := crdt.New(redisDatastore, namespace, newMergeAuditDAGService(dagService, merges), broadcaster, opts)
 	if err != nil {
 		h.Close()
 		redisClient.Close()
@@ -227,31 +323,121 @@ func NewServer(config Config) (*Server, error) {
 		cancel()
 		return nil, fmt.Errorf("failed to create CRDT datastore: %w", err)
 	}
+	data.crdt = crdtDatastore
 
 	server := &Server{
 		config:       config,
+		flagConfig:   flagConfig,
 		host:         h,
 		pubsub:       ps,
 		topic:        topic,
 		subscription: subscription,
 		store:        redisDatastore,
 		crdt:         crdtDatastore,
+		data:         data,
 		bstore:       bstore,
 		dagService:   dagService,
+		broadcaster:  broadcaster,
+		merges:       merges,
 		ctx:          ctx,
 		cancel:       cancel,
 		redisClient:  redisClient,
 		peerRegistry: peerRegistry,
-		sseClients:   make(map[string]chan []byte),
+		docs:         NewDocumentStore(crdtDatastore),
+		syncHub:      syncHub,
+		hooks:        NewWriteHooks(),
+		jsonMerger:   jsonMerger,
+		sseClients:   make(map[string]*sseClient),
+		sseHistory:   newSSEHistory(config.SSEReplaySize),
+		tlsConfig:    tlsConfig,
 		startTime:    time.Now(),
 	}
+	server.sseEpoch = newSSEEpoch(server.startTime)
+
+	server.namespaces = NewNamespaceManager(server)
+	syncHub.docs = server.docs
+	syncHub.peerID = h.ID().String()
+
+	// 트레이싱 설정
+	server.tracerProvider, err = setupTracing(ctx, config, h.ID().String())
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	// 인증 설정 (인증 설정 파일이 설정 파일의 인증 항목보다 우선)
+	authConfig := config.Auth
+	if config.AuthConfigPath != "" {
+		authConfig, err = LoadAuthConfig(config.AuthConfigPath)
+		if err != nil {
+			server.Close()
+			return nil, err
+		}
+	}
+	if authConfig != nil {
+		server.auth, err = NewAuthenticator(authConfig)
+		if err != nil {
+			server.Close()
+			return nil, fmt.Errorf("failed to create authenticator: %w", err)
+		}
+	} else {
+		logger.Warn("Authentication is disabled; set --auth-config to require API keys or JWTs")
+	}
+
+	// 검증 규칙 설정
+	rules := config.HookRules
+	if config.HookRulesPath != "" {
+		hooksConfig, err := LoadHooksConfig(config.HookRulesPath)
+		if err != nil {
+			server.Close()
+			return nil, err
+		}
+		rules = append(hooksConfig.Rules, rules...)
+	}
+	if len(rules) > 0 {
+		for _, rule := range rules {
+			server.hooks.Register(rule.Prefix, NewValidationHook(rule))
+		}
+		logger.Infof("Loaded %d validation rules", len(rules))
+	}
+
+	// JSON 모드 설정 (JSON 객체가 아닌 값은 쓰기 훅으로 거부)
+	if jsonMerger != nil {
+		for _, prefix := range jsonMerger.prefixes {
+			server.hooks.Register(prefix, jsonObjectHook{})
+		}
+		logger.Infof("Merging JSON values field by field under %s", strings.Join(jsonMerger.prefixes, ", "))
+	}
+
+	// 요청 제한 설정
+	server.setRequestLimits(config)
+
+	// 데이터 네임스페이스 시작
+	if err := server.namespaces.Sync(ctx); err != nil {
+		logger.Warnf("Failed to load namespaces: %v", err)
+	}
+	server.ensureNamespaces(config.Namespaces)
 
 	// API 라우트 설정
 	server.setupRoutes()
+	server.setupGRPC()
+	server.setupRepair()
 
 	return server, nil
 }
 
+// newCRDTOptions 서버와 네임스페이스의 CRDT 데이터스토어가 공통으로 사용하는 옵션
+func newCRDTOptions() *crdt.Options {
+	opts := crdt.DefaultOptions()
+	// 재브로드캐스트 주기 (기본값 5분)
+	opts.RebroadcastInterval = time.Minute * 5
+	// 동기화 주기 (기본값 1초)
+	opts.DAGSyncerTimeout = time.Second * 1
+	// 병합 주기 (기본값 1초)
+	opts.RepairInterval = time.Second * 1
+	return opts
+}
+
 // GetCRDTDatastore returns the CRDT datastore
 func (s *Server) GetCRDTDatastore() ds.Datastore {
 	return s.crdt
@@ -265,9 +451,14 @@ func (s *Server) setupRoutes() {
 	// CORS 미들웨어 적용
 	corsMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
+			if origin := s.corsOrigin(r); origin != "" {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if origin != "*" {
+					w.Header().Add("Vary", "Origin")
+				}
+			}
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusNoContent)
@@ -278,16 +469,10 @@ func (s *Server) setupRoutes() {
 		})
 	}
 
-	// 상태 확인 엔드포인트
-	s.mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		response := map[string]interface{}{
-			"status": "ok",
-			"peerId": s.host.ID().String(),
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	})
+	// 상태 확인 엔드포인트 (/health는 이전 버전 호환을 위한 /livez의 별칭)
+	s.mux.HandleFunc("/health", s.handleLivez)
+	s.mux.HandleFunc("/livez", s.handleLivez)
+	s.mux.HandleFunc("/readyz", s.handleReadyz)
 
 	// 피어 정보 엔드포인트
 	s.mux.HandleFunc("/peers", func(w http.ResponseWriter, r *http.Request) {
@@ -317,10 +502,24 @@ func (s *Server) setupRoutes() {
 
 		// 키가 비어있고 쿼리 파라미터가 있는 경우 목록 조회
 		if key == "" && r.Method == http.MethodGet {
+			if !s.authorize(w, r, ds.NewKey(r.URL.Query().Get("prefix")).String(), PermissionRead) {
+				return
+			}
 			s.handleListData(w, r)
 			return
 		}
 
+		// 권한 확인
+		if key != "" {
+			perm := PermissionWrite
+			if r.Method == http.MethodGet {
+				perm = PermissionRead
+			}
+			if !s.authorize(w, r, ds.NewKey(key).String(), perm) {
+				return
+			}
+		}
+
 		// 요청 메서드에 따른 핸들러 호출
 		switch r.Method {
 		case http.MethodGet:
@@ -334,17 +533,151 @@ func (s *Server) setupRoutes() {
 		}
 	})
 
+	// 배치 API
+	s.mux.HandleFunc("/api/data:batch", s.handleBatch)
+
+	// 문서 API
+	s.mux.HandleFunc("/api/docs/", s.handleDocs)
+
+	// 데이터 네임스페이스 API
+	s.mux.HandleFunc("/api/admin/namespaces", s.handleAdminNamespaces)
+	s.mux.HandleFunc("/api/admin/namespaces/", s.handleAdminNamespaces)
+	s.mux.HandleFunc("/api/ns/", s.handleNamespaceData)
+
+	// 안티 엔트로피 복구 API
+	s.mux.HandleFunc("/api/admin/repair", s.handleAdminRepair)
+
+	// WebSocket 동기화 엔드포인트
+	s.syncHub.upgrader.CheckOrigin = s.checkWebSocketOrigin
+	s.mux.HandleFunc("/ws", s.handleWebSocket)
+
 	// CRDT 데이터 뷰어 API
 	s.mux.HandleFunc("/api/crdt-viewer/", s.handleCRDTViewer)
 
 	// CORS 미들웨어 적용
 	s.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", s.config.HTTPPort),
-		Handler: corsMiddleware(s.mux),
+		Handler: s.tracingMiddleware(corsMiddleware(s.authMiddleware(s.limitMiddleware(s.mux)))),
 	}
 
 	// SSE 클라이언트 초기화
-	s.sseClients = make(map[string]chan []byte)
+	s.sseClients = make(map[string]*sseClient)
+}
+
+// dataStore 데이터 API가 읽고 쓰는 CRDT 데이터스토어
+//
+// 기본 데이터 네임스페이스와 각 네임스페이스의 데이터스토어는 같은 읽기/쓰기 함수를 사용하므로
+// 만료 시간, JSON 필드 병합, 예약된 키와 쓰기 훅이 똑같이 적용된다.
+type dataStore struct {
+	crdt *crdt.Datastore
+	// scope 쓰기 훅, 권한, JSON 모드와 이벤트에 사용하는 키 접두사 (기본 데이터스토어는 /)
+	scope ds.Key
+	// cachePrefix 노드가 마지막으로 병합한 JSON 필드 상태를 보관하는 키 접두사 (복제되지 않음)
+	cachePrefix ds.Key
+	// hookEvents 이벤트를 쓰기 요청 대신 CRDT 훅에서 보내는지 여부
+	// 훅에서 보내면 다른 노드에서 복제된 변경도 이벤트로 전달된다.
+	hookEvents bool
+	// batchMu 배치 커밋을 직렬화 (배치 API의 전제 조건 확인 포함)
+	// go-ds-crdt 배치는 데이터스토어 전체에서 델타를 공유하므로 동시에 커밋하면 섞인다.
+	batchMu sync.Mutex
+}
+
+// scopedKey 데이터스토어의 키를 쓰기 훅, 권한 확인과 이벤트에 사용하는 키로 변환
+func (d *dataStore) scopedKey(key ds.Key) string {
+	return d.scope.Child(key).String()
+}
+
+// getValue 키의 값 조회
+// 정리 작업이 아직 삭제하지 않은 만료된 키는 ds.ErrNotFound를 반환한다.
+func (s *Server) getValue(ctx context.Context, d *dataStore, key ds.Key) ([]byte, error) {
+	value, err := d.crdt.Get(ctx, key)
+	if err == nil && s.isExpired(ctx, d, key) {
+		err = ds.ErrNotFound
+	}
+	return value, err
+}
+
+// putValue 값과 만료 시간을 하나의 델타로 저장 (ttl이 0이면 만료되지 않음)
+// 쓰기 훅이 거부하면 WriteRejectedError를 반환한다.
+func (s *Server) putValue(ctx context.Context, d *dataStore, key ds.Key, value []byte, ttl time.Duration) (err error) {
+	scoped := d.scopedKey(key)
+	if err := s.hooks.PreWrite(scoped, value); err != nil {
+		return err
+	}
+	ctx, span := tracer.Start(ctx, "crdt.put", trace.WithAttributes(attribute.String("crdt.key", scoped)))
+	defer func() { endSpan(span, err) }()
+
+	err = s.commitBatch(ctx, d, func(batch ds.Batch) error {
+		stored, err := s.stageFields(ctx, d, batch, key, value)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(ctx, key, stored); err != nil {
+			return err
+		}
+		return s.stageTTL(ctx, d, batch, key, ttl)
+	})
+	if err != nil {
+		return err
+	}
+	s.hooks.PostWrite(scoped, value)
+	return nil
+}
+
+// deleteValue 값과 만료 시간을 함께 삭제
+// 쓰기 훅이 거부하면 WriteRejectedError를 반환한다.
+func (s *Server) deleteValue(ctx context.Context, d *dataStore, key ds.Key) (err error) {
+	scoped := d.scopedKey(key)
+	if err := s.hooks.PreWrite(scoped, nil); err != nil {
+		return err
+	}
+	ctx, span := tracer.Start(ctx, "crdt.delete", trace.WithAttributes(attribute.String("crdt.key", scoped)))
+	defer func() { endSpan(span, err) }()
+
+	err = s.commitBatch(ctx, d, func(batch ds.Batch) error {
+		if err := batch.Delete(ctx, key); err != nil {
+			return err
+		}
+		if _, err := s.stageFields(ctx, d, batch, key, nil); err != nil {
+			return err
+		}
+		return s.stageTTL(ctx, d, batch, key, 0)
+	})
+	if err != nil {
+		return err
+	}
+	s.hooks.PostWrite(scoped, nil)
+	return nil
+}
+
+// listValues 접두사로 시작하는 키와 값 조회 (만료 시간 키와 만료된 키는 제외)
+func (s *Server) listValues(ctx context.Context, d *dataStore, prefix string) ([]dsquery.Entry, error) {
+	results, err := d.crdt.Query(ctx, dsquery.Query{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	var entries []dsquery.Entry
+	for {
+		result, ok := results.NextSync()
+		if !ok {
+			break
+		}
+
+		if result.Error != nil {
+			logger.Errorf("Error in query result: %v", result.Error)
+			continue
+		}
+
+		resultKey := ds.NewKey(result.Key)
+		if isReservedKey(resultKey) || s.isExpired(ctx, d, resultKey) {
+			continue
+		}
+
+		entries = append(entries, result.Entry)
+	}
+	return entries, nil
 }
 
 // handleGetData 데이터 조회 핸들러
@@ -356,8 +689,7 @@ func (s *Server) handleGetData(w http.ResponseWriter, r *http.Request, key strin
 		return
 	}
 
-	dsKey := ds.NewKey(key)
-	value, err := s.crdt.Get(s.ctx, dsKey)
+	value, err := s.getValue(s.ctx, s.data, ds.NewKey(key))
 	if err != nil {
 		if err == ds.ErrNotFound {
 			w.Header().Set("Content-Type", "application/json")
@@ -381,6 +713,7 @@ func (s *Server) handleGetData(w http.ResponseWriter, r *http.Request, key strin
 }
 
 // handlePutData 데이터 저장 핸들러
+// ttl 쿼리 파라미터(초)를 지정하면 그 시간이 지난 뒤 키가 모든 노드에서 삭제된다.
 func (s *Server) handlePutData(w http.ResponseWriter, r *http.Request, key string) {
 	if key == "" {
 		w.Header().Set("Content-Type", "application/json")
@@ -389,19 +722,30 @@ func (s *Server) handlePutData(w http.ResponseWriter, r *http.Request, key strin
 		return
 	}
 
+	dsKey := ds.NewKey(key)
+	if isReservedKey(dsKey) {
+		writeJSONError(w, http.StatusBadRequest, "reserved key: "+dsKey.String())
+		return
+	}
+
+	ttl, err := parseTTL(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// 요청 본문에서 데이터 읽기
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
 		logger.Errorf("Failed to read request body: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "failed to read request body"})
+		writeBodyError(w, err, "failed to read request body")
 		return
 	}
 
-	dsKey := ds.NewKey(key)
-	err = s.crdt.Put(s.ctx, dsKey, data)
-	if err != nil {
+	if err := s.putValue(s.withRequestSpan(r), s.data, dsKey, data, ttl); err != nil {
+		if writeRejectedError(w, err) {
+			return
+		}
 		logger.Errorf("Failed to put data: %v", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -427,8 +771,15 @@ func (s *Server) handleDeleteData(w http.ResponseWriter, r *http.Request, key st
 	}
 
 	dsKey := ds.NewKey(key)
-	err := s.crdt.Delete(s.ctx, dsKey)
-	if err != nil {
+	if isReservedKey(dsKey) {
+		writeJSONError(w, http.StatusBadRequest, "reserved key: "+dsKey.String())
+		return
+	}
+
+	if err := s.deleteValue(s.withRequestSpan(r), s.data, dsKey); err != nil {
+		if writeRejectedError(w, err) {
+			return
+		}
 		logger.Errorf("Failed to delete data: %v", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -448,8 +799,7 @@ func (s *Server) handleDeleteData(w http.ResponseWriter, r *http.Request, key st
 func (s *Server) handleListData(w http.ResponseWriter, r *http.Request) {
 	prefix := r.URL.Query().Get("prefix")
 
-	q := dsquery.Query{Prefix: prefix}
-	results, err := s.crdt.Query(s.ctx, q)
+	results, err := s.listValues(s.ctx, s.data, prefix)
 	if err != nil {
 		logger.Errorf("Failed to query data: %v", err)
 		w.Header().Set("Content-Type", "application/json")
@@ -457,24 +807,10 @@ func (s *Server) handleListData(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
-	defer results.Close()
-
-	entries := []map[string]interface{}{}
-	for {
-		result, ok := results.NextSync()
-		if !ok {
-			break
-		}
-
-		if result.Error != nil {
-			logger.Errorf("Error in query result: %v", result.Error)
-			continue
-		}
 
-		entries = append(entries, map[string]interface{}{
-			"key":   result.Key,
-			"value": string(result.Value), // 문자열로 변환 (JSON 응답용)
-		})
+	entries := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		entries = append(entries, listEntry(result.Key, result.Value))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -482,35 +818,63 @@ func (s *Server) handleListData(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(entries)
 }
 
+// listEntry 목록 조회 응답 항목
+// UTF-8이 아닌 값은 base64로 인코딩하고 encoding을 표시한다.
+func listEntry(key string, value []byte) map[string]interface{} {
+	if !utf8.Valid(value) {
+		return map[string]interface{}{
+			"key":      key,
+			"value":    base64.StdEncoding.EncodeToString(value),
+			"encoding": "base64",
+		}
+	}
+	return map[string]interface{}{
+		"key":   key,
+		"value": string(value),
+	}
+}
+
 // handleSSE SSE 핸들러 - 실시간 업데이트 수신
+//
+// 쿼리 파라미터로 받을 이벤트를 제한할 수 있다.
+//
+//	prefix  키 접두사 (여러 번 지정 가능, 하나라도 일치하면 전달)
+//	ops     이벤트 유형 목록 (쉼표로 구분: put, get, delete, patch)
 func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	// 필터 파싱
+	prefixes, ops, err := parseSSEFilter(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// SSE 헤더 설정
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
-	// 클라이언트 ID 생성
-	clientID := uuid.New().String()
-
-	// 클라이언트용 메시지 채널 생성
-	messageChan := make(chan []byte)
 
 	// 클라이언트 등록
-	s.sseClientsMu.Lock()
-	s.sseClients[clientID] = messageChan
-	s.sseClientsMu.Unlock()
+	client := &sseClient{
+		messages:  make(chan sseMessage, sseSendBuffer),
+		principal: principalFromRequest(r),
+		prefixes:  prefixes,
+		ops:       ops,
+	}
+	clientID, seq, replay, resumed, reset := s.registerSSEClient(client, lastEventID(r))
 
 	// 클라이언트 연결 종료 시 정리
-	defer func() {
-		s.sseClientsMu.Lock()
-		delete(s.sseClients, clientID)
-		close(messageChan)
-		s.sseClientsMu.Unlock()
-	}()
-
-	// 클라이언트에게 초기 연결 확인 메시지 전송
-	fmt.Fprintf(w, "data: {\"event\": \"connected\", \"clientId\": \"%s\"}\n\n", clientID)
+	defer s.unregisterSSEClient(clientID)
+
+	// 클라이언트에게 초기 연결 확인 메시지 전송 (이어서 받을 시퀀스 번호 포함)
+	// 재연결 커서를 되돌릴 수 없으면 reset을 보내 클라이언트가 데이터를 다시 조회하게 한다.
+	fmt.Fprintf(w, "id: %s\ndata: {\"event\": \"connected\", \"clientId\": \"%s\", \"seq\": %d, \"resumed\": %t}\n\n",
+		s.sseCursor(seq), clientID, seq, resumed)
+	if reset {
+		fmt.Fprintf(w, "data: {\"event\": \"reset\", \"seq\": %d}\n\n", seq)
+	}
+	for _, msg := range replay {
+		s.writeSSEMessage(w, msg)
+	}
 	w.(http.Flusher).Flush()
 
 	// 클라이언트 연결 상태 확인
@@ -521,14 +885,96 @@ func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-clientGone:
 			return
-		case msg := <-messageChan:
-			fmt.Fprintf(w, "data: %s\n\n", msg)
+		case msg := <-client.messages:
+			s.writeSSEMessage(w, msg)
 			w.(http.Flusher).Flush()
 		}
 	}
 }
 
+// sseSendBuffer 클라이언트별 SSE 전송 버퍼 크기
+const sseSendBuffer = 64
+
+// sseEventTypes SSE 필터에 사용할 수 있는 이벤트 유형
+var sseEventTypes = map[string]bool{"put": true, "get": true, "delete": true, "patch": true}
+
+// sseClient SSE 클라이언트
+type sseClient struct {
+	messages  chan sseMessage
+	principal *Principal
+	// prefixes 구독한 키 접두사 (비어 있으면 모든 키)
+	prefixes []string
+	// ops 구독한 이벤트 유형 (nil이면 모든 유형)
+	ops map[string]bool
+	// lastSeq 클라이언트 필터에 일치한 마지막 이벤트의 시퀀스 번호
+	lastSeq uint64
+}
+
+// sseMessage SSE 클라이언트로 보낼 메시지
+type sseMessage struct {
+	seq  uint64
+	data []byte
+}
+
+// parseSSEFilter SSE 요청의 prefix, ops 쿼리 파라미터 파싱
+func parseSSEFilter(r *http.Request) ([]string, map[string]bool, error) {
+	query := r.URL.Query()
+	var ops []string
+	if raw := query.Get("ops"); raw != "" {
+		ops = strings.Split(raw, ",")
+	}
+	return newSSEFilter(query["prefix"], ops)
+}
+
+// newSSEFilter 키 접두사와 이벤트 유형 목록을 SSE 클라이언트 필터로 변환
+func newSSEFilter(rawPrefixes, rawOps []string) ([]string, map[string]bool, error) {
+	var prefixes []string
+	for _, prefix := range rawPrefixes {
+		if prefix == "" {
+			continue
+		}
+		if !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	var ops map[string]bool
+	if len(rawOps) > 0 {
+		ops = make(map[string]bool)
+		for _, op := range rawOps {
+			op = strings.TrimSpace(op)
+			if !sseEventTypes[op] {
+				return nil, nil, fmt.Errorf("unknown event type: %q", op)
+			}
+			ops[op] = true
+		}
+	}
+
+	return prefixes, ops, nil
+}
+
+// matches 이벤트가 클라이언트 필터에 일치하는지 확인
+func (c *sseClient) matches(eventType, key string) bool {
+	if c.ops != nil && !c.ops[eventType] {
+		return false
+	}
+	if len(c.prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // broadcastSSEEvent SSE 이벤트 브로드캐스트
+//
+// 모든 이벤트에는 서버 시퀀스 번호(seq)가 붙고, 클라이언트마다 필터에 일치한 직전
+// 이벤트의 시퀀스 번호(prev)가 함께 전달된다. 클라이언트는 prev가 마지막으로 받은
+// seq와 다르면 이벤트가 유실되었음을 알 수 있다.
 func (s *Server) broadcastSSEEvent(eventType, key string, data []byte) {
 	// 이벤트 데이터 생성
 	event := map[string]interface{}{
@@ -548,24 +994,50 @@ func (s *Server) broadcastSSEEvent(eventType, key string, data []byte) {
 		}
 	}
 
-	// JSON으로 직렬화
-	eventJSON, err := json.Marshal(event)
-	if err != nil {
-		logger.Errorf("Failed to marshal SSE event: %v", err)
-		return
-	}
-
-	// 모든 클라이언트에게 이벤트 전송
+	// 키를 읽을 수 있고 필터에 일치하는 클라이언트에게 이벤트 전송
+	dsKey := ds.NewKey(key).String()
 	s.sseClientsMu.Lock()
-	for _, clientChan := range s.sseClients {
+	defer s.sseClientsMu.Unlock()
+
+	s.sseSeq++
+	event["seq"] = s.sseSeq
+	for id, client := range s.sseClients {
+		if !s.canReceiveSSEEvent(client, eventType, dsKey) {
+			continue
+		}
+
+		event["prev"] = client.lastSeq
+		client.lastSeq = s.sseSeq
+
+		// JSON으로 직렬화
+		eventJSON, err := json.Marshal(event)
+		if err != nil {
+			logger.Errorf("Failed to marshal SSE event: %v", err)
+			return
+		}
+
 		select {
-		case clientChan <- eventJSON:
+		case client.messages <- sseMessage{seq: s.sseSeq, data: eventJSON}:
 			// 성공적으로 전송
 		default:
-			// 채널이 차면 건너뛰기 (블록하지 않음)
+			// 버퍼가 차면 건너뛰기 (블록하지 않음, 클라이언트는 prev로 유실을 감지)
+			logger.Debugf("SSE client %s buffer full, dropping event %d", id, s.sseSeq)
 		}
 	}
-	s.sseClientsMu.Unlock()
+
+	// 재연결한 클라이언트에게 다시 보낼 수 있도록 보관
+	if s.sseHistory != nil {
+		delete(event, "prev")
+		s.sseHistory.add(sseHistoryEntry{seq: s.sseSeq, eventType: eventType, key: dsKey, event: event})
+	}
+}
+
+// canReceiveSSEEvent 클라이언트가 키를 읽을 수 있고 이벤트가 필터에 일치하는지 확인
+func (s *Server) canReceiveSSEEvent(client *sseClient, eventType, key string) bool {
+	if s.auth != nil && (client.principal == nil || !client.principal.Can(key, PermissionRead)) {
+		return false
+	}
+	return client.matches(eventType, key)
 }
 
 // handleCRDTViewer CRDT 데이터 뷰어 핸들러
@@ -584,13 +1056,41 @@ func (s *Server) handleCRDTViewer(w http.ResponseWriter, r *http.Request) {
 	case strings.HasPrefix(path, "value/"):
 		// 특정 키의 값 조회
 		key := strings.TrimPrefix(path, "value/")
+		if !s.authorize(w, r, ds.NewKey(key).String(), PermissionRead) {
+			return
+		}
 		s.handleCRDTViewerValue(w, r, key)
 	case path == "stats":
 		// CRDT 상태 정보 조회
+		if !s.authorize(w, r, "/", PermissionAdmin) {
+			return
+		}
 		s.handleCRDTViewerStats(w, r)
 	case path == "heads":
 		// 현재 heads 목록 조회
+		if !s.authorize(w, r, "/", PermissionAdmin) {
+			return
+		}
 		s.handleCRDTViewerHeads(w, r)
+	case path == "merges":
+		// 원격 heads 병합 기록 조회
+		if !s.authorize(w, r, "/", PermissionAdmin) {
+			return
+		}
+		s.handleCRDTViewerMerges(w, r)
+	case strings.HasPrefix(path, "dag/"):
+		// DAG 노드와 링크 조회
+		if !s.authorize(w, r, "/", PermissionAdmin) {
+			return
+		}
+		s.handleCRDTViewerDAG(w, r, strings.TrimPrefix(path, "dag/"))
+	case strings.HasPrefix(path, "history/"):
+		// 키의 변경 이력 조회
+		key := strings.TrimPrefix(path, "history/")
+		if !s.authorize(w, r, ds.NewKey(key).String(), PermissionRead) {
+			return
+		}
+		s.handleCRDTViewerHistory(w, r, key)
 	default:
 		http.NotFound(w, r)
 	}
@@ -649,9 +1149,22 @@ func (s *Server) handleCRDTViewerUI(w http.ResponseWriter, r *http.Request) {
             <button onclick="loadHeads()">Heads 새로고침</button>
             <div id="heads" class="stats-display">로딩 중...</div>
         </div>
+
+        <div class="card">
+            <h2>DAG 노드</h2>
+            <input id="dag-cid" type="text" size="70" placeholder="CID">
+            <button onclick="loadDAG(document.getElementById('dag-cid').value)">조회</button>
+            <div id="dag" class="value-display">CID를 입력하세요...</div>
+        </div>
     </div>
 
     <script>
+        // access_token 쿼리 파라미터를 API 요청에 전달
+        const accessToken = new URLSearchParams(window.location.search).get('access_token');
+        function apiURL(path) {
+            return accessToken ? path + '?access_token=' + encodeURIComponent(accessToken) : path;
+        }
+
         // 페이지 로드 시 초기 데이터 로드
         document.addEventListener('DOMContentLoaded', function() {
             loadKeys();
@@ -661,7 +1174,7 @@ func (s *Server) handleCRDTViewerUI(w http.ResponseWriter, r *http.Request) {
 
         // 키 목록 로드
         function loadKeys() {
-            fetch('/api/crdt-viewer/keys')
+            fetch(apiURL('/api/crdt-viewer/keys'))
                 .then(response => response.json())
                 .then(data => {
                     const keyList = document.getElementById('key-list');
@@ -688,7 +1201,7 @@ func (s *Server) handleCRDTViewerUI(w http.ResponseWriter, r *http.Request) {
 
         // 특정 키의 값 로드
         function loadValue(key) {
-            fetch('/api/crdt-viewer/value/' + encodeURIComponent(key))
+            fetch(apiURL('/api/crdt-viewer/value/' + encodeURIComponent(key)))
                 .then(response => response.json())
                 .then(data => {
                     const valueDisplay = document.getElementById('value-display');
@@ -716,7 +1229,7 @@ func (s *Server) handleCRDTViewerUI(w http.ResponseWriter, r *http.Request) {
 
         // 상태 정보 로드
         function loadStats() {
-            fetch('/api/crdt-viewer/stats')
+            fetch(apiURL('/api/crdt-viewer/stats'))
                 .then(response => response.json())
                 .then(data => {
                     const statsDisplay = document.getElementById('stats');
@@ -730,7 +1243,7 @@ func (s *Server) handleCRDTViewerUI(w http.ResponseWriter, r *http.Request) {
 
         // Heads 정보 로드
         function loadHeads() {
-            fetch('/api/crdt-viewer/heads')
+            fetch(apiURL('/api/crdt-viewer/heads'))
                 .then(response => response.json())
                 .then(data => {
                     const headsDisplay = document.getElementById('heads');
@@ -741,6 +1254,33 @@ func (s *Server) handleCRDTViewerUI(w http.ResponseWriter, r *http.Request) {
                     document.getElementById('heads').innerHTML = '<div>Heads 정보 로드 오류</div>';
                 });
         }
+
+        // DAG 노드 로드
+        function loadDAG(cid) {
+            if (!cid) {
+                return;
+            }
+            document.getElementById('dag-cid').value = cid;
+            fetch(apiURL('/api/crdt-viewer/dag/' + encodeURIComponent(cid)))
+                .then(response => response.json())
+                .then(data => {
+                    const dagDisplay = document.getElementById('dag');
+                    dagDisplay.innerHTML = '<pre>' + JSON.stringify(data, null, 2) + '</pre>';
+
+                    // 링크를 클릭하면 해당 노드로 이동
+                    (data.links || []).forEach(link => {
+                        const linkItem = document.createElement('div');
+                        linkItem.className = 'key-item';
+                        linkItem.textContent = link.cid + (link.processed ? '' : ' (병합 안 됨)');
+                        linkItem.onclick = function() { loadDAG(link.cid); };
+                        dagDisplay.appendChild(linkItem);
+                    });
+                })
+                .catch(error => {
+                    console.error('Error loading DAG node:', error);
+                    document.getElementById('dag').innerHTML = '<div>DAG 노드 로드 오류</div>';
+                });
+        }
     </script>
 </body>
 </html>`
@@ -776,6 +1316,11 @@ func (s *Server) handleCRDTViewerKeys(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		// 읽기 권한이 있는 키만 포함
+		if !s.canAccess(r, result.Key, PermissionRead) {
+			continue
+		}
+
 		keys = append(keys, result.Key)
 	}
 
@@ -865,21 +1410,6 @@ func (s *Server) handleCRDTViewerStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
-// handleCRDTViewerHeads 현재 heads 목록 조회
-func (s *Server) handleCRDTViewerHeads(w http.ResponseWriter, r *http.Request) {
-	// 참고: go-ds-crdt에는 현재 heads를 직접 조회하는 API가 없으므로
-	// 여기서는 기본적인 정보만 제공합니다.
-
-	// 더미 데이터 반환
-	heads := map[string]interface{}{
-		"note": "현재 go-ds-crdt에서는 heads 정보를 직접 조회할 수 없습니다.",
-	}
-
-	// JSON 응답 반환
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(heads)
-}
-
 // Start 서버 시작
 func (s *Server) Start() error {
 	// 부트스트랩 피어 연결
@@ -887,13 +1417,33 @@ func (s *Server) Start() error {
 		go s.connectToBootstrapPeers()
 	}
 
+	// 만료된 키 정리
+	go s.runTTLJanitor(s.config.TTLInterval)
+
+	// 다른 서버에서 변경한 네임스페이스 반영
+	go s.namespaces.runSync(s.ctx)
+
+	// SIGHUP을 받으면 설정 파일 다시 읽기
+	go s.runReload()
+
+	// 동시에 쓴 JSON 모드 키의 필드 단위 병합
+	if s.jsonMerger != nil {
+		go s.runJSONMerger()
+	}
+
 	// 비동기로 서버 시작
 	go func() {
-		logger.Infof("HTTP server listening on %s", s.server.Addr)
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.listenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Errorf("HTTP server error: %v", err)
 		}
 	}()
+	if s.config.GRPCPort > 0 {
+		go func() {
+			if err := s.serveGRPC(); err != nil {
+				logger.Errorf("gRPC server error: %v", err)
+			}
+		}()
+	}
 
 	// 종료 신호 처리
 	quit := make(chan os.Signal, 1)
@@ -908,6 +1458,7 @@ func (s *Server) Start() error {
 	if err := s.server.Shutdown(ctx); err != nil {
 		logger.Errorf("Server forced to shutdown: %v", err)
 	}
+	s.stopGRPC(ctx)
 
 	// 리소스 정리
 	s.Close()
@@ -953,6 +1504,10 @@ func (s *Server) connectToBootstrapPeers() {
 func (s *Server) Close() {
 	logger.Info("Cleaning up resources...")
 
+	if s.namespaces != nil {
+		s.namespaces.Close()
+	}
+
 	if s.crdt != nil {
 		s.crdt.Close()
 	}
@@ -977,14 +1532,36 @@ func (s *Server) Close() {
 		s.host.Close()
 	}
 
+	// 남은 스팬 내보내기
+	if s.tracerProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := s.tracerProvider.Shutdown(ctx); err != nil {
+			logger.Warnf("Failed to flush traces: %v", err)
+		}
+		cancel()
+	}
+
 	s.cancel()
 
 	logger.Info("Server stopped")
 }
 
+// splitList 쉼표로 구분된 목록 파싱 (빈 항목 제외)
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func main() {
 	// 커맨드 라인 플래그 파싱
+	configFile := flag.String("config", "", "Path to the YAML or JSON config file (flags given on the command line take precedence)")
 	httpPort := flag.Int("port", 8080, "HTTP server port")
+	grpcPort := flag.Int("grpc-port", 0, "gRPC server port (disabled if 0)")
 	gamePort := flag.Int("game-port", 8081, "Game server port")
 	redisAddr := flag.String("redis", "localhost:6379", "Redis server address")
 	redisPassword := flag.String("redis-password", "", "Redis password")
@@ -993,23 +1570,80 @@ func main() {
 	bootstrapPeers := flag.String("bootstrap", "", "Comma-separated list of bootstrap peers")
 	dataNamespace := flag.String("namespace", "/crdt-data", "Namespace for CRDT data")
 	debug := flag.Bool("debug", true, "Enable debug logging")
+	logLevel := flag.String("log-level", "", "Log level (debug, info, warn, error); overrides --debug")
 	useIPFSLite := flag.Bool("ipfs-lite", false, "Use IPFS-Lite as DAGSyncer")
 	enableGameServer := flag.Bool("enable-game", true, "Enable boss raid game server")
 	clientDir := flag.String("client-dir", "../client", "Directory containing client files")
+	jsonPrefixes := flag.String("json-prefixes", "", "Comma-separated key prefixes whose values are JSON objects merged field by field")
+	hooksConfig := flag.String("hooks-config", "", "Path to the JSON file with validation rules applied to data API writes")
+	authConfig := flag.String("auth-config", "", "Path to the auth config file with API keys and the JWT secret (disables auth if empty)")
+	corsOrigins := flag.String("cors-origins", "", "Comma-separated list of allowed CORS origins (allows all if empty)")
+	listenAddrs := flag.String("listen", "", "Comma-separated libp2p listen multiaddrs (TCP, QUIC and WebSocket on random ports if empty)")
+	announceAddrs := flag.String("announce", "", "Comma-separated external multiaddrs to announce to peers")
+	p2pSecurity := flag.String("p2p-security", "", "Comma-separated libp2p security protocols in order of preference (noise, tls)")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file for HTTPS")
+	tlsKey := flag.String("tls-key", "", "TLS key file for HTTPS")
+	autocertDomains := flag.String("autocert-domains", "", "Comma-separated domains to get Let's Encrypt certificates for")
+	rateLimit := flag.Float64("rate-limit", 0, "Requests per second allowed per client (API key or IP) on the data API (unlimited if 0)")
+	rateBurst := flag.Int("rate-burst", 0, "Maximum burst of requests per client (defaults to the rate limit)")
+	maxBody := flag.Int64("max-body", defaultMaxBodyBytes, "Maximum request body size in bytes on the data API (unlimited if 0)")
+	trustProxy := flag.Bool("trust-proxy", false, "Use the X-Forwarded-For header as the client IP for rate limiting")
+	ttlInterval := flag.Duration("ttl-interval", defaultTTLInterval, "Interval of removing expired keys")
+	mergeLogSize := flag.Int("merge-log-size", defaultMergeLogSize, "Number of remote head merges to keep for /api/crdt-viewer/merges")
+	sseReplay := flag.Int("sse-replay", defaultSSEReplaySize, "Number of recent SSE events to replay to clients reconnecting with Last-Event-ID (disabled if 0)")
+	autocertCache := flag.String("autocert-cache", "autocert-cache", "Directory to cache Let's Encrypt certificates in")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "OTLP gRPC collector address to export traces to, e.g. localhost:4317 (tracing disabled if empty)")
+	otlpInsecure := flag.Bool("otlp-insecure", false, "Connect to the OTLP collector without TLS")
+	traceSample := flag.Float64("trace-sample", 1, "Fraction of new traces to record (0 to 1)")
 
 	flag.Parse()
 
+	// 명령줄에서 지정한 플래그 (설정 파일의 값보다 우선)
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
 	// 서버 설정
 	config := Config{
-		HTTPPort:       *httpPort,
-		RedisAddr:      *redisAddr,
-		RedisPassword:  *redisPassword,
-		RedisDB:        *redisDB,
-		PubSubTopic:    *pubSubTopic,
-		BootstrapPeers: *bootstrapPeers,
-		DataNamespace:  *dataNamespace,
-		Debug:          *debug,
-		UseIPFSLite:    *useIPFSLite,
+		HTTPPort:         *httpPort,
+		GRPCPort:         *grpcPort,
+		RedisAddr:        *redisAddr,
+		RedisPassword:    *redisPassword,
+		RedisDB:          *redisDB,
+		PubSubTopic:      *pubSubTopic,
+		BootstrapPeers:   *bootstrapPeers,
+		DataNamespace:    *dataNamespace,
+		Debug:            *debug,
+		LogLevel:         *logLevel,
+		UseIPFSLite:      *useIPFSLite,
+		AuthConfigPath:   *authConfig,
+		HookRulesPath:    *hooksConfig,
+		CORSOrigins:      splitList(*corsOrigins),
+		JSONPrefixes:     splitList(*jsonPrefixes),
+		ListenAddrs:      splitList(*listenAddrs),
+		AnnounceAddrs:    splitList(*announceAddrs),
+		P2PSecurity:      splitList(*p2pSecurity),
+		TLSCertFile:      *tlsCert,
+		TLSKeyFile:       *tlsKey,
+		AutocertDomains:  splitList(*autocertDomains),
+		AutocertCacheDir: *autocertCache,
+		TTLInterval:      *ttlInterval,
+		RateLimit:        *rateLimit,
+		RateBurst:        *rateBurst,
+		MaxBodyBytes:     *maxBody,
+		TrustProxy:       *trustProxy,
+		MergeLogSize:     *mergeLogSize,
+		SSEReplaySize:    *sseReplay,
+		ConfigFile:       *configFile,
+		SetFlags:         setFlags,
+		EnableGame:       *enableGameServer,
+		GamePort:         *gamePort,
+		ClientDir:        *clientDir,
+
+		TracingEndpoint:    *otlpEndpoint,
+		TracingInsecure:    *otlpInsecure,
+		TracingSampleRatio: *traceSample,
 	}
 
 	// 서버 생성
@@ -1018,16 +1652,16 @@ func main() {
 		log.Fatalf("Failed to create server: %v", err)
 	}
 
-	// 게임 서버 활성화 여부 확인
-	if *enableGameServer {
-		log.Printf("Starting boss raid game server on port %d", *gamePort)
+	// 게임 서버 활성화 여부 확인 (설정 파일을 적용한 설정 사용)
+	if server.config.EnableGame {
+		log.Printf("Starting boss raid game server on port %d", server.config.GamePort)
 
 		// 게임 서버 생성
-		gameServer := NewGameServer(server.ctx, server.GetCRDTDatastore(), *clientDir)
+		gameServer := NewGameServer(server.ctx, server.GetCRDTDatastore(), server.config.ClientDir)
 
 		// 게임 서버 시작 (별도 고루틴으로 실행)
 		go func() {
-			if err := gameServer.Start(*gamePort); err != nil {
+			if err := gameServer.Start(server.config.GamePort); err != nil {
 				log.Printf("Game server error: %v", err)
 			}
 		}()
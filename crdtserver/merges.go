@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	format "github.com/ipfs/go-ipld-format"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultMergeLogSize 병합 기록을 보관하는 기본 개수
+	defaultMergeLogSize = 256
+	// mergeLogMaxKeys 병합 기록 하나에 보관하는 최대 키 수 (저장과 삭제 각각)
+	mergeLogMaxKeys = 100
+)
+
+// MergeRecord 다른 피어에서 받은 heads의 병합 기록
+type MergeRecord struct {
+	// Peer heads를 브로드캐스트한 피어
+	Peer string `json:"peer"`
+	// Heads 브로드캐스트된 heads
+	Heads []string `json:"heads"`
+	// Blocks 병합한 DAG 블록 수
+	Blocks int `json:"blocks"`
+	// Puts 병합으로 값이 저장된 키
+	Puts []string `json:"puts"`
+	// Deletes 병합으로 삭제된 키
+	Deletes []string `json:"deletes"`
+	// Truncated 키가 너무 많아 일부만 기록했는지 여부
+	Truncated bool `json:"truncated,omitempty"`
+	// StartedAt 병합 시작 시간
+	StartedAt time.Time `json:"startedAt"`
+	// DurationMs 병합에 걸린 시간 (밀리초)
+	DurationMs float64 `json:"durationMs"`
+}
+
+// touches 병합이 키를 변경했는지 확인
+func (r *MergeRecord) touches(key string) bool {
+	for _, keys := range [][]string{r.Puts, r.Deletes} {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeInProgress 진행 중인 병합
+type mergeInProgress struct {
+	record  MergeRecord
+	blocks  map[cid.Cid]struct{}
+	puts    map[string]struct{}
+	deletes map[string]struct{}
+}
+
+// MergeLog 원격 heads 병합 기록 (링 버퍼)
+//
+// go-ds-crdt는 브로드캐스트 메시지 하나의 heads를 모두 처리한 뒤에 다음 메시지를
+// 받으므로(MultiHeadProcessing 비활성화), 브로드캐스터가 메시지를 반환할 때 병합을
+// 시작하고 다음 메시지를 기다릴 때 병합을 끝낸다. 그 사이에 CRDT가 처리하려고 가져온
+// DAG 블록의 델타가 병합으로 변경된 키다.
+type MergeLog struct {
+	mu      sync.Mutex
+	records []MergeRecord
+	next    int
+	current *mergeInProgress
+
+	// origins 병합으로 가져온 블록의 출처 기록 (nil이면 기록하지 않음)
+	origins *BlockOrigins
+}
+
+// NewMergeLog 새 병합 기록 생성
+func NewMergeLog(size int) *MergeLog {
+	if size <= 0 {
+		size = defaultMergeLogSize
+	}
+	return &MergeLog{records: make([]MergeRecord, 0, size)}
+}
+
+// begin 피어가 브로드캐스트한 heads의 병합 시작
+func (l *MergeLog) begin(peer string, heads []cid.Cid) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.finishLocked()
+	l.current = &mergeInProgress{
+		record: MergeRecord{
+			Peer:      peer,
+			Heads:     cidStrings(heads),
+			StartedAt: time.Now(),
+		},
+		blocks:  make(map[cid.Cid]struct{}),
+		puts:    make(map[string]struct{}),
+		deletes: make(map[string]struct{}),
+	}
+}
+
+// end 진행 중인 병합 종료
+func (l *MergeLog) end() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.finishLocked()
+}
+
+// finishLocked 진행 중인 병합을 기록에 추가
+// 이미 병합한 heads의 재브로드캐스트처럼 처리한 블록이 없는 병합은 기록하지 않는다.
+func (l *MergeLog) finishLocked() {
+	current := l.current
+	l.current = nil
+	if current == nil || len(current.blocks) == 0 {
+		return
+	}
+
+	record := current.record
+	record.Blocks = len(current.blocks)
+	record.Puts = sortedKeys(current.puts)
+	record.Deletes = sortedKeys(current.deletes)
+	record.DurationMs = float64(time.Since(record.StartedAt).Microseconds()) / 1000
+
+	if len(l.records) < cap(l.records) {
+		l.records = append(l.records, record)
+	} else {
+		l.records[l.next] = record
+	}
+	l.next = (l.next + 1) % cap(l.records)
+
+	logger.Infof("Merged %d blocks from %s (%d puts, %d deletes) in %.1fms",
+		record.Blocks, record.Peer, len(record.Puts), len(record.Deletes), record.DurationMs)
+}
+
+// observe CRDT가 처리하려고 가져온 DAG 블록 기록
+func (l *MergeLog) observe(node format.Node) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := l.current
+	if current == nil {
+		return
+	}
+	if _, ok := current.blocks[node.Cid()]; ok {
+		return
+	}
+	current.blocks[node.Cid()] = struct{}{}
+
+	delta, err := decodeDelta(node)
+	if err != nil {
+		return
+	}
+	add := func(keys map[string]struct{}, key string) {
+		if len(keys) >= mergeLogMaxKeys {
+			current.record.Truncated = true
+			return
+		}
+		keys[ds.NewKey(key).String()] = struct{}{}
+	}
+	for _, elem := range delta.Elements {
+		add(current.puts, elem.Key)
+	}
+	for _, tomb := range delta.Tombstones {
+		add(current.deletes, tomb.Key)
+	}
+}
+
+// recordOrigin 진행 중인 병합의 피어를 블록 출처로 기록
+func (l *MergeLog) recordOrigin(ctx context.Context, c cid.Cid) {
+	l.mu.Lock()
+	current := l.current
+	l.mu.Unlock()
+	if current == nil {
+		return
+	}
+	l.origins.Record(ctx, c, current.record.Peer)
+}
+
+// Records 병합 기록 (최신 순)
+func (l *MergeLog) Records() []MergeRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]MergeRecord, 0, len(l.records))
+	for i := 1; i <= len(l.records); i++ {
+		records = append(records, l.records[(l.next-i+len(l.records))%len(l.records)])
+	}
+	return records
+}
+
+// sortedKeys 집합의 키를 정렬된 목록으로 변환
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergeAuditDAGService CRDT가 처리하려고 가져온 블록을 병합 기록에 알리는 DAG 서비스
+// go-ds-crdt는 처리할 블록을 GetMany로 가져오므로 GetMany 결과만 기록한다.
+type mergeAuditDAGService struct {
+	format.DAGService
+	log *MergeLog
+}
+
+// newMergeAuditDAGService 병합 기록용 DAG 서비스 생성
+func newMergeAuditDAGService(dagService format.DAGService, log *MergeLog) *mergeAuditDAGService {
+	return &mergeAuditDAGService{DAGService: dagService, log: log}
+}
+
+// Add 로컬 쓰기로 만든 노드를 저장하며 출처 기록
+func (d *mergeAuditDAGService) Add(ctx context.Context, node format.Node) error {
+	ctx, span := tracer.Start(ctx, "dag.add", trace.WithAttributes(attribute.String("crdt.cid", node.Cid().String())))
+	err := d.DAGService.Add(ctx, node)
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	d.log.origins.RecordLocal(ctx, node.Cid())
+	return nil
+}
+
+// GetMany 여러 노드를 가져오며 병합 기록에 알림
+func (d *mergeAuditDAGService) GetMany(ctx context.Context, cids []cid.Cid) <-chan *format.NodeOption {
+	return d.log.observeMany(ctx, d.DAGService.GetMany(ctx, cids))
+}
+
+// Session 세션 기반 NodeGetter 반환 (go-ds-crdt SessionDAGService)
+// 내부 DAG 서비스가 세션을 지원하지 않으면 DAG 서비스를 그대로 사용한다.
+func (d *mergeAuditDAGService) Session(ctx context.Context) format.NodeGetter {
+	sessionMaker, ok := d.DAGService.(interface {
+		Session(context.Context) format.NodeGetter
+	})
+	if !ok {
+		return d
+	}
+	return &mergeAuditNodeGetter{NodeGetter: sessionMaker.Session(ctx), log: d.log}
+}
+
+// mergeAuditNodeGetter 세션 NodeGetter용 병합 기록 래퍼
+type mergeAuditNodeGetter struct {
+	format.NodeGetter
+	log *MergeLog
+}
+
+// GetMany 여러 노드를 가져오며 병합 기록에 알림
+func (g *mergeAuditNodeGetter) GetMany(ctx context.Context, cids []cid.Cid) <-chan *format.NodeOption {
+	return g.log.observeMany(ctx, g.NodeGetter.GetMany(ctx, cids))
+}
+
+// observeMany GetMany 결과를 전달하면서 가져온 블록 기록
+func (l *MergeLog) observeMany(ctx context.Context, in <-chan *format.NodeOption) <-chan *format.NodeOption {
+	out := make(chan *format.NodeOption, cap(in))
+	go func() {
+		defer close(out)
+		for opt := range in {
+			if opt.Err == nil {
+				l.observe(opt.Node)
+				l.recordOrigin(ctx, opt.Node.Cid())
+			}
+			select {
+			case out <- opt:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// handleCRDTViewerMerges 원격 heads 병합 기록 조회
+//
+// 쿼리 파라미터:
+//
+//	key    이 키를 변경한 병합만 조회
+//	limit  최대 기록 수
+//
+// 파티션이 복구된 뒤 값이 예상과 다르게 바뀌었을 때 어느 피어의 병합이 값을 바꿨는지
+// 찾는 데 사용한다.
+func (s *Server) handleCRDTViewerMerges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid limit: "+raw)
+			return
+		}
+		limit = n
+	}
+
+	records := []MergeRecord{}
+	for _, record := range s.merges.Records() {
+		if key := query.Get("key"); key != "" && !record.touches(ds.NewKey(key).String()) {
+			continue
+		}
+		records = append(records, record)
+		if limit > 0 && len(records) == limit {
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
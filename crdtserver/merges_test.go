@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// headCIDs 서버의 현재 heads
+func headCIDs(t *testing.T, s *Server) []string {
+	t.Helper()
+	return cidStrings(s.crdt.InternalStats(context.Background()).Heads)
+}
+
+// TestCRDTViewerMerges 다른 피어의 heads를 병합한 기록과 키, 개수 필터 확인
+func TestCRDTViewerMerges(t *testing.T) {
+	bstore := blockstore.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	a := newTestReplica(t, Config{}, nopBroadcaster{}, bstore)
+	b := newTestReplica(t, Config{}, newTestBroadcaster(t), bstore)
+
+	putData(t, a, "boss/hp", "100")
+	putData(t, a, "raid/state", "started")
+	first := headCIDs(t, a)
+	injectHeads(t, b, "peer-a", first...)
+
+	w := serveTest(a, http.MethodDelete, "/api/data/boss/hp", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	second := headCIDs(t, a)
+	injectHeads(t, b, "peer-c", second...)
+
+	// 이미 병합한 heads의 재브로드캐스트는 기록하지 않음
+	injectHeads(t, b, "peer-a", second...)
+
+	var records []MergeRecord
+	require.Equal(t, http.StatusOK, getJSON(t, b, "/api/crdt-viewer/merges", &records))
+	require.Len(t, records, 2)
+	assert.Equal(t, "peer-c", records[0].Peer)
+	assert.Equal(t, second, records[0].Heads)
+	assert.Equal(t, 1, records[0].Blocks)
+	assert.Empty(t, records[0].Puts)
+	assert.Equal(t, []string{"/boss/hp"}, records[0].Deletes)
+	assert.Equal(t, "peer-a", records[1].Peer)
+	assert.Equal(t, first, records[1].Heads)
+	assert.Equal(t, 2, records[1].Blocks)
+	assert.Equal(t, []string{"/boss/hp", "/raid/state"}, records[1].Puts)
+	assert.Empty(t, records[1].Deletes)
+
+	records = nil
+	getJSON(t, b, "/api/crdt-viewer/merges?key=raid/state", &records)
+	require.Len(t, records, 1)
+	assert.Equal(t, "peer-a", records[0].Peer)
+
+	records = nil
+	getJSON(t, b, "/api/crdt-viewer/merges?key=/boss/hp&limit=1", &records)
+	require.Len(t, records, 1)
+	assert.Equal(t, "peer-c", records[0].Peer)
+
+	records = nil
+	getJSON(t, b, "/api/crdt-viewer/merges?key=/boss/mp", &records)
+	assert.Empty(t, records)
+
+	// 로컬 쓰기는 병합 기록에 남지 않음
+	records = nil
+	getJSON(t, a, "/api/crdt-viewer/merges", &records)
+	assert.Empty(t, records)
+
+	assert.Equal(t, http.StatusBadRequest, serveTest(b, http.MethodGet, "/api/crdt-viewer/merges?limit=0", "").Code)
+}
+
+// TestMergeLogSize 병합 기록이 가득 차면 가장 오래된 기록부터 덮어쓰는지 확인
+func TestMergeLogSize(t *testing.T) {
+	bstore := blockstore.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	a := newTestReplica(t, Config{}, nopBroadcaster{}, bstore)
+	b := newTestReplica(t, Config{MergeLogSize: 2}, newTestBroadcaster(t), bstore)
+
+	for _, peer := range []string{"peer-1", "peer-2", "peer-3"} {
+		putData(t, a, "boss/hp", peer)
+		injectHeads(t, b, peer, headCIDs(t, a)...)
+	}
+
+	var records []MergeRecord
+	getJSON(t, b, "/api/crdt-viewer/merges", &records)
+	require.Len(t, records, 2)
+	assert.Equal(t, "peer-3", records[0].Peer)
+	assert.Equal(t, "peer-2", records[1].Peer)
+}
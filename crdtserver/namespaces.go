@@ -0,0 +1,549 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	ds "github.com/ipfs/go-datastore"
+	crdt "github.com/ipfs/go-ds-crdt"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+const (
+	// namespaceSetKey 데이터 네임스페이스 설정이 저장되는 Redis 해시 키
+	namespaceSetKey = "crdt:namespaces"
+	// namespaceSyncInterval 다른 서버에서 변경한 네임스페이스 설정을 반영하는 주기
+	namespaceSyncInterval = 30 * time.Second
+)
+
+var (
+	// ErrNamespaceNotFound 네임스페이스가 없을 때 반환되는 에러
+	ErrNamespaceNotFound = fmt.Errorf("namespace not found")
+	// ErrNamespaceExists 네임스페이스가 이미 있을 때 반환되는 에러
+	ErrNamespaceExists = fmt.Errorf("namespace already exists")
+)
+
+// namespaceNamePattern 네임스페이스 이름 형식
+var namespaceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// NamespaceConfig 데이터 네임스페이스 설정
+type NamespaceConfig struct {
+	// Name 네임스페이스 이름 (/api/ns/{name}/...)
+	Name string `json:"name"`
+	// Topic CRDT 동기화 PubSub 토픽 (기본값: <기본 토픽>/<name>)
+	Topic string `json:"topic"`
+	// DataNamespace CRDT 데이터 네임스페이스 (기본값: /crdt-ns/<name>)
+	DataNamespace string `json:"dataNamespace"`
+	// AuthScope 권한을 확인할 키 접두사 (기본값: /ns/<name>)
+	// 네임스페이스의 키 /k는 <AuthScope>/k 키로 권한을 확인한다.
+	AuthScope string `json:"authScope"`
+	// CreatedAt 생성 시간
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Namespace 실행 중인 데이터 네임스페이스
+// 네임스페이스마다 별도의 PubSub 토픽과 CRDT 데이터스토어를 사용하며,
+// Redis 데이터스토어와 DAG 서비스는 서버와 공유한다.
+type Namespace struct {
+	config       NamespaceConfig
+	topic        *pubsub.Topic
+	subscription *pubsub.Subscription
+	// data 네임스페이스의 데이터스토어 (키 범위는 AuthScope)
+	data *dataStore
+}
+
+// scopedKey 네임스페이스의 키를 권한 확인과 이벤트에 사용하는 키로 변환
+func (n *Namespace) scopedKey(key ds.Key) string {
+	return n.data.scopedKey(key)
+}
+
+// close 네임스페이스 종료
+func (n *Namespace) close() {
+	if err := n.data.crdt.Close(); err != nil {
+		logger.Warnf("Failed to close CRDT datastore of namespace %s: %v", n.config.Name, err)
+	}
+	n.subscription.Cancel()
+	if err := n.topic.Close(); err != nil {
+		logger.Warnf("Failed to close topic of namespace %s: %v", n.config.Name, err)
+	}
+}
+
+// NamespaceManager 데이터 네임스페이스 관리자
+//
+// 네임스페이스 설정은 Redis에 저장되어 같은 Redis를 사용하는 모든 서버가 공유한다.
+// 한 서버에서 만들거나 삭제한 네임스페이스는 다른 서버에서 다음 동기화 때 반영된다.
+type NamespaceManager struct {
+	server *Server
+
+	mu         sync.RWMutex
+	namespaces map[string]*Namespace
+}
+
+// NewNamespaceManager 새 네임스페이스 관리자 생성
+func NewNamespaceManager(server *Server) *NamespaceManager {
+	return &NamespaceManager{
+		server:     server,
+		namespaces: make(map[string]*Namespace),
+	}
+}
+
+// withDefaults 설정의 빈 항목을 기본값으로 채우고 키 형식을 정규화
+func (m *NamespaceManager) withDefaults(config NamespaceConfig) NamespaceConfig {
+	if config.Topic == "" {
+		config.Topic = m.server.config.PubSubTopic + "/" + config.Name
+	}
+	if config.DataNamespace == "" {
+		config.DataNamespace = "/crdt-ns/" + config.Name
+	}
+	if config.AuthScope == "" {
+		config.AuthScope = "/ns/" + config.Name
+	}
+	config.DataNamespace = ds.NewKey(config.DataNamespace).String()
+	config.AuthScope = ds.NewKey(config.AuthScope).String()
+	return config
+}
+
+// validate 설정이 서버와 다른 네임스페이스와 겹치지 않는지 확인
+func (m *NamespaceManager) validate(config NamespaceConfig) error {
+	if !namespaceNamePattern.MatchString(config.Name) {
+		return fmt.Errorf("invalid namespace name: %q", config.Name)
+	}
+
+	overlaps := func(a, b string) bool {
+		return inNamespace(a, b) || inNamespace(b, a)
+	}
+	if config.DataNamespace == "/" || overlaps(config.DataNamespace, ds.NewKey(m.server.config.DataNamespace).String()) {
+		return fmt.Errorf("data namespace %s overlaps the server data namespace", config.DataNamespace)
+	}
+	if config.Topic == m.server.config.PubSubTopic {
+		return fmt.Errorf("topic %s is used by the server", config.Topic)
+	}
+	for _, other := range m.namespaces {
+		if other.config.Topic == config.Topic {
+			return fmt.Errorf("topic %s is used by namespace %s", config.Topic, other.config.Name)
+		}
+		if overlaps(other.config.DataNamespace, config.DataNamespace) {
+			return fmt.Errorf("data namespace %s overlaps namespace %s", config.DataNamespace, other.config.Name)
+		}
+	}
+	return nil
+}
+
+// start 네임스페이스의 토픽에 참여하고 CRDT 데이터스토어 생성
+func (m *NamespaceManager) start(config NamespaceConfig) (*Namespace, error) {
+	s := m.server
+
+	topic, err := s.pubsub.Join(config.Topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join pubsub topic: %w", err)
+	}
+	subscription, err := topic.Subscribe()
+	if err != nil {
+		topic.Close()
+		return nil, fmt.Errorf("failed to subscribe to topic: %w", err)
+	}
+
+	data, err := m.openData(config, NewPubSubBroadcaster(s.ctx, topic, subscription))
+	if err != nil {
+		subscription.Cancel()
+		topic.Close()
+		return nil, err
+	}
+
+	logger.Infof("Namespace %s started (topic: %s, data: %s)", config.Name, config.Topic, config.DataNamespace)
+	return &Namespace{
+		config:       config,
+		topic:        topic,
+		subscription: subscription,
+		data:         data,
+	}, nil
+}
+
+// openData 네임스페이스의 CRDT 데이터스토어 생성
+// 다른 노드에서 복제된 변경도 이벤트로 전달되도록 이벤트는 CRDT 훅에서 보낸다.
+func (m *NamespaceManager) openData(config NamespaceConfig, broadcaster crdt.Broadcaster) (*dataStore, error) {
+	s := m.server
+	data := &dataStore{
+		scope:       ds.NewKey(config.AuthScope),
+		cachePrefix: ds.NewKey(namespaceJSONMergeCachePrefix).ChildString(config.Name),
+		hookEvents:  true,
+	}
+
+	opts := newCRDTOptions()
+	opts.PutHook = func(k ds.Key, v []byte) {
+		logger.Debugf("CRDT Put [%s]: %s", config.Name, k)
+		s.jsonMerger.handleChange(data, k)
+		s.broadcastHookEvent(data, "put", k, v)
+	}
+	opts.DeleteHook = func(k ds.Key) {
+		logger.Debugf("CRDT Delete [%s]: %s", config.Name, k)
+		s.broadcastHookEvent(data, "delete", k, nil)
+	}
+
+	store, err := crdt.New(s.store, ds.NewKey(config.DataNamespace), s.dagService, broadcaster, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CRDT datastore: %w", err)
+	}
+	data.crdt = store
+	return data, nil
+}
+
+// Create 네임스페이스 생성
+func (m *NamespaceManager) Create(ctx context.Context, config NamespaceConfig) (NamespaceConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.namespaces[config.Name]; ok {
+		return NamespaceConfig{}, ErrNamespaceExists
+	}
+	config = m.withDefaults(config)
+	config.CreatedAt = time.Now()
+	if err := m.validate(config); err != nil {
+		return NamespaceConfig{}, err
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return NamespaceConfig{}, fmt.Errorf("failed to marshal namespace: %w", err)
+	}
+	created, err := m.server.redisClient.HSetNX(ctx, namespaceSetKey, config.Name, data).Result()
+	if err != nil {
+		return NamespaceConfig{}, fmt.Errorf("failed to store namespace: %w", err)
+	}
+	if !created {
+		// 다른 서버에서 먼저 만든 네임스페이스
+		return NamespaceConfig{}, ErrNamespaceExists
+	}
+
+	namespace, err := m.start(config)
+	if err != nil {
+		m.server.redisClient.HDel(ctx, namespaceSetKey, config.Name)
+		return NamespaceConfig{}, err
+	}
+	m.namespaces[config.Name] = namespace
+	return config, nil
+}
+
+// Delete 네임스페이스 삭제
+// 네임스페이스의 데이터는 Redis에 남으며, 같은 설정으로 다시 만들면 다시 사용된다.
+func (m *NamespaceManager) Delete(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	namespace, ok := m.namespaces[name]
+	if !ok {
+		return ErrNamespaceNotFound
+	}
+	if err := m.server.redisClient.HDel(ctx, namespaceSetKey, name).Err(); err != nil {
+		return fmt.Errorf("failed to delete namespace: %w", err)
+	}
+	namespace.close()
+	delete(m.namespaces, name)
+	logger.Infof("Namespace %s stopped", name)
+	return nil
+}
+
+// Get 실행 중인 네임스페이스 조회 (없으면 nil)
+func (m *NamespaceManager) Get(name string) *Namespace {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.namespaces[name]
+}
+
+// List 실행 중인 네임스페이스 설정 목록 (이름 순)
+func (m *NamespaceManager) List() []NamespaceConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	configs := make([]NamespaceConfig, 0, len(m.namespaces))
+	for _, namespace := range m.namespaces {
+		configs = append(configs, namespace.config)
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Name < configs[j].Name })
+	return configs
+}
+
+// dataStores 실행 중인 네임스페이스의 데이터스토어 목록
+func (m *NamespaceManager) dataStores() []*dataStore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stores := make([]*dataStore, 0, len(m.namespaces))
+	for _, namespace := range m.namespaces {
+		stores = append(stores, namespace.data)
+	}
+	return stores
+}
+
+// Sync Redis에 저장된 설정에 맞춰 네임스페이스를 시작하거나 종료
+func (m *NamespaceManager) Sync(ctx context.Context) error {
+	stored, err := m.server.redisClient.HGetAll(ctx, namespaceSetKey).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to load namespaces: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 삭제된 네임스페이스 종료
+	for name, namespace := range m.namespaces {
+		if _, ok := stored[name]; !ok {
+			namespace.close()
+			delete(m.namespaces, name)
+			logger.Infof("Namespace %s stopped", name)
+		}
+	}
+
+	// 새 네임스페이스 시작
+	for name, data := range stored {
+		if _, ok := m.namespaces[name]; ok {
+			continue
+		}
+		var config NamespaceConfig
+		if err := json.Unmarshal([]byte(data), &config); err != nil {
+			logger.Errorf("Invalid namespace config %s: %v", name, err)
+			continue
+		}
+		if err := m.validate(config); err != nil {
+			logger.Errorf("Invalid namespace config %s: %v", name, err)
+			continue
+		}
+		namespace, err := m.start(config)
+		if err != nil {
+			logger.Errorf("Failed to start namespace %s: %v", name, err)
+			continue
+		}
+		m.namespaces[name] = namespace
+	}
+	return nil
+}
+
+// runSync 주기적으로 네임스페이스 설정 동기화
+func (m *NamespaceManager) runSync(ctx context.Context) {
+	ticker := time.NewTicker(namespaceSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Sync(ctx); err != nil {
+				logger.Errorf("Failed to sync namespaces: %v", err)
+			}
+		}
+	}
+}
+
+// Close 모든 네임스페이스 종료
+func (m *NamespaceManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, namespace := range m.namespaces {
+		namespace.close()
+		delete(m.namespaces, name)
+	}
+}
+
+// handleAdminNamespaces 네임스페이스 관리 API 핸들러
+//
+//	GET    /api/admin/namespaces         네임스페이스 목록
+//	POST   /api/admin/namespaces         네임스페이스 생성
+//	GET    /api/admin/namespaces/{name}  네임스페이스 조회
+//	DELETE /api/admin/namespaces/{name}  네임스페이스 삭제
+func (s *Server) handleAdminNamespaces(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, "/", PermissionAdmin) {
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/namespaces"), "/")
+	switch {
+	case name == "" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.namespaces.List())
+
+	case name == "" && r.Method == http.MethodPost:
+		var config NamespaceConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			writeBodyError(w, err, "invalid request body: "+err.Error())
+			return
+		}
+		created, err := s.namespaces.Create(s.ctx, config)
+		if err != nil {
+			status := http.StatusBadRequest
+			if err == ErrNamespaceExists {
+				status = http.StatusConflict
+			}
+			writeJSONError(w, status, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	case name != "" && r.Method == http.MethodGet:
+		namespace := s.namespaces.Get(name)
+		if namespace == nil {
+			writeJSONError(w, http.StatusNotFound, ErrNamespaceNotFound.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(namespace.config)
+
+	case name != "" && r.Method == http.MethodDelete:
+		if err := s.namespaces.Delete(s.ctx, name); err != nil {
+			status := http.StatusInternalServerError
+			if err == ErrNamespaceNotFound {
+				status = http.StatusNotFound
+			}
+			writeJSONError(w, status, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleNamespaceData 네임스페이스 데이터 API 핸들러
+// 기본 데이터 API와 같은 방식으로 만료 시간(ttl), JSON 필드 병합과 예약된 키를 처리한다.
+//
+//	GET    /api/ns/{name}/data?prefix=   데이터 목록 조회
+//	GET    /api/ns/{name}/data/{key}     데이터 조회
+//	POST   /api/ns/{name}/data/{key}     데이터 저장 (PUT도 허용, ?ttl=초)
+//	DELETE /api/ns/{name}/data/{key}     데이터 삭제
+func (s *Server) handleNamespaceData(w http.ResponseWriter, r *http.Request) {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/ns/"), "/")
+	namespace := s.namespaces.Get(name)
+	if namespace == nil {
+		writeJSONError(w, http.StatusNotFound, ErrNamespaceNotFound.Error())
+		return
+	}
+	if rest != "data" && !strings.HasPrefix(rest, "data/") {
+		http.NotFound(w, r)
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(rest, "data"), "/")
+
+	// 목록 조회
+	if key == "" {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusBadRequest, "key is required")
+			return
+		}
+		prefix := ds.NewKey(r.URL.Query().Get("prefix"))
+		if !s.authorize(w, r, namespace.scopedKey(prefix), PermissionRead) {
+			return
+		}
+		s.handleNamespaceList(w, namespace, prefix.String())
+		return
+	}
+
+	// 권한 확인
+	dsKey := ds.NewKey(key)
+	perm := PermissionWrite
+	if r.Method == http.MethodGet {
+		perm = PermissionRead
+	}
+	if !s.authorize(w, r, namespace.scopedKey(dsKey), perm) {
+		return
+	}
+	if perm >= PermissionWrite && isReservedKey(dsKey) {
+		writeJSONError(w, http.StatusBadRequest, "reserved key: "+dsKey.String())
+		return
+	}
+
+	// 이벤트는 네임스페이스 데이터스토어의 CRDT 훅에서 보낸다.
+	switch r.Method {
+	case http.MethodGet:
+		value, err := s.getValue(s.ctx, namespace.data, dsKey)
+		if err != nil {
+			if err == ds.ErrNotFound {
+				writeJSONError(w, http.StatusNotFound, "key not found")
+				return
+			}
+			logger.Errorf("Failed to get data: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write(value)
+
+	case http.MethodPost, http.MethodPut:
+		ttl, err := parseTTL(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBodyError(w, err, "failed to read request body")
+			return
+		}
+		if err := s.putValue(s.withRequestSpan(r), namespace.data, dsKey, data, ttl); err != nil {
+			if writeRejectedError(w, err) {
+				return
+			}
+			logger.Errorf("Failed to put data: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	case http.MethodDelete:
+		if err := s.deleteValue(s.withRequestSpan(r), namespace.data, dsKey); err != nil {
+			if writeRejectedError(w, err) {
+				return
+			}
+			logger.Errorf("Failed to delete data: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleNamespaceList 네임스페이스 데이터 목록 조회 (만료 시간 키와 만료된 키는 제외)
+func (s *Server) handleNamespaceList(w http.ResponseWriter, namespace *Namespace, prefix string) {
+	results, err := s.listValues(s.ctx, namespace.data, prefix)
+	if err != nil {
+		logger.Errorf("Failed to query data: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	entries := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		entries = append(entries, listEntry(result.Key, result.Value))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// broadcastHookEvent CRDT 훅에서 받은 변경을 이벤트로 알림 (만료 시간과 필드 버전 키는 제외)
+// 로컬 쓰기와 다른 노드에서 복제된 변경 모두 전달된다.
+func (s *Server) broadcastHookEvent(d *dataStore, eventType string, key ds.Key, value []byte) {
+	if isReservedKey(key) {
+		return
+	}
+	s.broadcastSSEEvent(eventType, d.scopedKey(key), value)
+}
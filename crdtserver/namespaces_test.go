@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInNamespace 키가 네임스페이스에 속하는지 확인
+func TestInNamespace(t *testing.T) {
+	tests := []struct {
+		key, namespace string
+		want           bool
+	}{
+		{"/a", "/a", true},
+		{"/a/b", "/a", true},
+		{"/a/b/c", "/a/b", true},
+		{"/ab", "/a", false},
+		{"/ab/c", "/a", false},
+		{"/a", "/ab", false},
+		{"/a", "/a/b", false},
+		{"/b/a", "/a", false},
+
+		// 끝의 "/"와 빠진 앞의 "/"
+		{"/a/b", "/a/", true},
+		{"/a/b", "a", true},
+		{"a/b", "/a", true},
+		{"a/b", "a", true},
+		{"/ab", "a", false},
+		{"ab", "/a", false},
+
+		// 빈 네임스페이스와 루트는 모든 키를 포함
+		{"/a/b", "", true},
+		{"/a/b", "/", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, inNamespace(tt.key, tt.namespace), "inNamespace(%q, %q)", tt.key, tt.namespace)
+	}
+}
+
+// TestPrincipalCanWithoutLeadingSlash 설정 파일의 네임스페이스에 앞의 "/"가 없어도 권한이 적용되는지 확인
+func TestPrincipalCanWithoutLeadingSlash(t *testing.T) {
+	auth, err := NewAuthenticator(&AuthConfig{APIKeys: []APIKeyConfig{
+		{Key: "key", Name: "game", Permissions: map[string]string{"game": "write"}},
+	}})
+	require.NoError(t, err)
+	principal, err := auth.AuthenticateToken("key")
+	require.NoError(t, err)
+
+	assert.True(t, principal.Can("/game/gold", PermissionWrite))
+	assert.False(t, principal.Can("/gameplay/gold", PermissionRead))
+	assert.False(t, principal.Can("/game/gold", PermissionAdmin))
+}
+
+// nopBroadcaster 다른 노드와 통신하지 않는 테스트용 브로드캐스터
+type nopBroadcaster struct{}
+
+func (nopBroadcaster) Broadcast(ctx context.Context, data []byte) error { return nil }
+
+func (nopBroadcaster) Next(ctx context.Context) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// newTestNamespaceServer 메모리 CRDT 데이터스토어를 사용하는 네임스페이스가 있는 서버
+func newTestNamespaceServer(t *testing.T, auth *Authenticator, names ...string) (*Server, http.Handler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := dssync.MutexWrap(ds.NewMapDatastore())
+	s := &Server{
+		ctx:        ctx,
+		auth:       auth,
+		store:      store,
+		dagService: NewSimpleDAGService(blockstore.NewBlockstore(store)),
+		hooks:      NewWriteHooks(),
+		config:     Config{PubSubTopic: "crdt-sync", DataNamespace: "/crdt-data"},
+		sseClients: make(map[string]*sseClient),
+	}
+	s.namespaces = NewNamespaceManager(s)
+	for _, name := range names {
+		config := s.namespaces.withDefaults(NamespaceConfig{Name: name})
+		require.NoError(t, s.namespaces.validate(config))
+		data, err := s.namespaces.openData(config, nopBroadcaster{})
+		require.NoError(t, err)
+		t.Cleanup(func() { data.crdt.Close() })
+		s.namespaces.namespaces[name] = &Namespace{config: config, data: data}
+	}
+	return s, s.authMiddleware(http.HandlerFunc(s.handleNamespaceData))
+}
+
+// serveNamespace API 키로 네임스페이스 데이터 API 요청을 처리하고 응답 반환
+func serveNamespace(handler http.Handler, method, target, apiKey, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("X-API-Key", apiKey)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// TestNamespaceDataIsolation 네임스페이스의 권한으로 이름이 비슷한 다른 네임스페이스를 읽거나 쓸 수 없는지 확인
+func TestNamespaceDataIsolation(t *testing.T) {
+	auth, err := NewAuthenticator(&AuthConfig{APIKeys: []APIKeyConfig{
+		{Key: "a-key", Name: "a", Permissions: map[string]string{"/ns/a": "write"}},
+		{Key: "ab-key", Name: "ab", Permissions: map[string]string{"ns/ab": "write"}},
+	}})
+	require.NoError(t, err)
+	_, handler := newTestNamespaceServer(t, auth, "a", "ab")
+
+	// 자기 네임스페이스는 읽고 쓸 수 있음
+	w := serveNamespace(handler, http.MethodPut, "/api/ns/a/data/gold", "a-key", "10")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = serveNamespace(handler, http.MethodPut, "/api/ns/ab/data/gold", "ab-key", "20")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = serveNamespace(handler, http.MethodGet, "/api/ns/a/data/gold", "a-key", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "10", w.Body.String())
+
+	// 네임스페이스 /ns/a의 권한은 /ns/ab에 적용되지 않음
+	for _, tt := range []struct {
+		method, target, key string
+	}{
+		{http.MethodGet, "/api/ns/ab/data/gold", "a-key"},
+		{http.MethodGet, "/api/ns/ab/data", "a-key"},
+		{http.MethodPut, "/api/ns/ab/data/gold", "a-key"},
+		{http.MethodDelete, "/api/ns/ab/data/gold", "a-key"},
+		{http.MethodGet, "/api/ns/a/data/gold", "ab-key"},
+		{http.MethodGet, "/api/ns/a/data", "ab-key"},
+		{http.MethodPut, "/api/ns/a/data/gold", "ab-key"},
+		{http.MethodDelete, "/api/ns/a/data/gold", "ab-key"},
+	} {
+		w := serveNamespace(handler, tt.method, tt.target, tt.key, "999")
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s %s with %s", tt.method, tt.target, tt.key)
+	}
+
+	// 거부된 쓰기는 값을 바꾸지 않고, 같은 키라도 네임스페이스마다 값이 따로 있음
+	w = serveNamespace(handler, http.MethodGet, "/api/ns/a/data/gold", "a-key", "")
+	assert.Equal(t, "10", w.Body.String())
+	w = serveNamespace(handler, http.MethodGet, "/api/ns/ab/data", "ab-key", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var entries []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/gold", entries[0]["key"])
+	assert.Equal(t, "20", entries[0]["value"])
+
+	// 없는 네임스페이스
+	w = serveNamespace(handler, http.MethodGet, "/api/ns/abc/data/gold", "a-key", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestNamespaceValidateOverlap 데이터 네임스페이스가 겹치는 설정을 거부하는지 확인
+func TestNamespaceValidateOverlap(t *testing.T) {
+	s := &Server{config: Config{PubSubTopic: "crdt-sync", DataNamespace: "/crdt-data"}}
+	m := NewNamespaceManager(s)
+	m.namespaces["a"] = &Namespace{config: m.withDefaults(NamespaceConfig{Name: "a", DataNamespace: "/shard/a"})}
+
+	assert.NoError(t, m.validate(m.withDefaults(NamespaceConfig{Name: "ab", DataNamespace: "/shard/ab"})))
+	assert.NoError(t, m.validate(m.withDefaults(NamespaceConfig{Name: "b", DataNamespace: "shard/b"})))
+	assert.Error(t, m.validate(m.withDefaults(NamespaceConfig{Name: "b", DataNamespace: "/shard/a/b"})))
+	assert.Error(t, m.validate(m.withDefaults(NamespaceConfig{Name: "b", DataNamespace: "shard"})))
+	assert.Error(t, m.validate(m.withDefaults(NamespaceConfig{Name: "b", DataNamespace: "crdt-data"})))
+	assert.Error(t, m.validate(m.withDefaults(NamespaceConfig{Name: "b", DataNamespace: "/"})))
+	assert.Error(t, m.validate(m.withDefaults(NamespaceConfig{Name: "b", Topic: "crdt-sync"})))
+	assert.Error(t, m.validate(m.withDefaults(NamespaceConfig{Name: "b", Topic: "crdt-sync/a"})))
+	assert.Error(t, m.validate(m.withDefaults(NamespaceConfig{Name: "B"})))
+}
+
+// TestNamespaceDataWritePath 네임스페이스 쓰기가 기본 데이터 API와 같이 예약된 키, 만료 시간과 JSON 필드를 처리하는지 확인
+func TestNamespaceDataWritePath(t *testing.T) {
+	s, handler := newTestNamespaceServer(t, nil, "a")
+	h, err := NewHost(Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}})
+	require.NoError(t, err)
+	t.Cleanup(func() { h.Close() })
+	s.host = h
+	s.jsonMerger = NewJSONMerger([]string{"/ns/a/players"})
+	s.hooks.Register("/ns/a/players", jsonObjectHook{})
+	namespace := s.namespaces.Get("a")
+	ctx := context.Background()
+
+	// 예약된 키와 잘못된 ttl은 거부
+	w := serveNamespace(handler, http.MethodPut, "/api/ns/a/data/_ttl/gold", "", "1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serveNamespace(handler, http.MethodDelete, "/api/ns/a/data/_fields/gold", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serveNamespace(handler, http.MethodPut, "/api/ns/a/data/gold?ttl=0", "", "1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 만료된 키는 정리 작업이 삭제하기 전에도 조회되지 않음
+	w = serveNamespace(handler, http.MethodPut, "/api/ns/a/data/gold?ttl=60", "", "10")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	expiresAt, err := s.expiresAt(ctx, namespace.data, ds.NewKey("/gold"))
+	require.NoError(t, err)
+	assert.False(t, expiresAt.IsZero())
+	require.NoError(t, namespace.data.crdt.Put(ctx, ttlKey(ds.NewKey("/gold")), []byte("1")))
+	w = serveNamespace(handler, http.MethodGet, "/api/ns/a/data/gold", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serveNamespace(handler, http.MethodGet, "/api/ns/a/data", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	// JSON 모드 키는 필드 버전을 남기고, 빠진 필드는 삭제된 필드가 됨
+	w = serveNamespace(handler, http.MethodPut, "/api/ns/a/data/players/alice", "", "hp")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serveNamespace(handler, http.MethodPut, "/api/ns/a/data/players/alice", "", `{"hp":100,"mp":50}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = serveNamespace(handler, http.MethodPut, "/api/ns/a/data/players/alice", "", `{"hp":90}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	fields, err := s.knownFields(ctx, namespace.data, ds.NewKey("/players/alice"))
+	require.NoError(t, err)
+	assert.True(t, fields["mp"].Deleted)
+	assert.JSONEq(t, `90`, string(fields["hp"].Value))
+	w = serveNamespace(handler, http.MethodGet, "/api/ns/a/data/players/alice", "", "")
+	assert.JSONEq(t, `{"hp":90}`, w.Body.String())
+}
+
+// TestNamespaceDataEvents 로컬 쓰기와 복제된 변경이 모두 네임스페이스 범위의 키로 이벤트에 전달되는지 확인
+func TestNamespaceDataEvents(t *testing.T) {
+	s, handler := newTestNamespaceServer(t, nil, "a")
+	namespace := s.namespaces.Get("a")
+	events := watchEvents(t, s)
+
+	w := serveNamespace(handler, http.MethodPut, "/api/ns/a/data/gold?ttl=60", "", "10")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	event, key, value := nextEvent(t, events)
+	assert.Equal(t, "put", event)
+	assert.Equal(t, "/ns/a/gold", key)
+	assert.Equal(t, "10", value)
+
+	// 다른 노드에서 복제된 변경은 요청 없이 CRDT 훅으로만 전달됨
+	require.NoError(t, namespace.data.crdt.Put(context.Background(), ds.NewKey("/silver"), []byte("5")))
+	event, key, value = nextEvent(t, events)
+	assert.Equal(t, "put", event)
+	assert.Equal(t, "/ns/a/silver", key)
+	assert.Equal(t, "5", value)
+
+	w = serveNamespace(handler, http.MethodDelete, "/api/ns/a/data/gold", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	event, key, _ = nextEvent(t, events)
+	assert.Equal(t, "delete", event)
+	assert.Equal(t, "/ns/a/gold", key)
+
+	// 만료 시간 키의 변경은 이벤트로 보내지 않음
+	select {
+	case msg := <-events:
+		t.Fatalf("unexpected event: %s", msg.data)
+	default:
+	}
+}
@@ -2,8 +2,16 @@ package main
 
 import (
 	"context"
+	"sync"
+	"time"
 
+	"github.com/ipfs/go-cid"
+	crdt "github.com/ipfs/go-ds-crdt"
+	pb "github.com/ipfs/go-ds-crdt/pb"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 )
 
 // PubSubBroadcaster는 libp2p PubSub을 사용하는 CRDT 브로드캐스터
@@ -11,41 +19,244 @@ type PubSubBroadcaster struct {
 	ctx          context.Context
 	topic        *pubsub.Topic
 	subscription *pubsub.Subscription
+
+	// incoming CRDT에 전달할 메시지 (PubSub 메시지와 Inject로 넣은 heads)
+	incoming chan broadcastMessage
+	// stopped 구독이 끝나면 닫힘
+	stopped chan struct{}
+	// processing CRDT가 처리 중인 메시지의 완료 알림 (Next에서만 사용)
+	processing chan struct{}
+	// applySpan CRDT가 처리 중인 메시지의 스팬 (Next에서만 사용)
+	applySpan trace.Span
+
+	// 브로드캐스트된 heads 기록 (복제 지연 디버깅용)
+	mu       sync.Mutex
+	sent     *BroadcastHeads
+	received map[string]*BroadcastHeads
+
+	// 원격 heads 병합 기록 (nil이면 기록하지 않음)
+	merges *MergeLog
+}
+
+// broadcastMessage 브로드캐스터가 CRDT에 전달하는 메시지
+type broadcastMessage struct {
+	from string
+	data []byte
+	// done CRDT가 메시지의 heads를 모두 처리하면 닫힘 (nil이면 알리지 않음)
+	done chan struct{}
+	// parent 메시지를 보낸 쪽의 스팬 (없으면 빈 값)
+	parent trace.SpanContext
+}
+
+// BroadcastHeads 브로드캐스트 메시지로 주고받은 heads
+type BroadcastHeads struct {
+	// Peer 메시지를 보낸 피어 (보낸 메시지는 비어 있음)
+	Peer string
+	// Heads 마지막 메시지의 heads
+	Heads []cid.Cid
+	// At 마지막 메시지 시간
+	At time.Time
+	// Count 메시지 수
+	Count int
 }
 
 // NewPubSubBroadcaster는 새 PubSub 브로드캐스터를 생성
 func NewPubSubBroadcaster(ctx context.Context, topic *pubsub.Topic, subscription *pubsub.Subscription) *PubSubBroadcaster {
-	return &PubSubBroadcaster{
+	b := &PubSubBroadcaster{
 		ctx:          ctx,
 		topic:        topic,
 		subscription: subscription,
+		incoming:     make(chan broadcastMessage),
+		stopped:      make(chan struct{}),
+		received:     make(map[string]*BroadcastHeads),
+	}
+	go b.receive()
+	return b
+}
+
+// receive PubSub 메시지를 받아 CRDT에 전달
+// Inject로 넣은 heads와 같은 순서로 처리되도록 모든 메시지는 incoming을 거친다.
+func (b *PubSubBroadcaster) receive() {
+	defer close(b.stopped)
+	for {
+		msg, err := b.subscription.Next(b.ctx)
+		if err != nil {
+			logger.Debugf("PubSub subscription ended: %v", err)
+			return
+		}
+
+		// 자신이 보낸 메시지는 무시
+		peers := b.topic.ListPeers()
+		if len(peers) > 0 && msg.ReceivedFrom == peers[0] {
+			continue
+		}
+
+		parent, data, err := unwrapTraceEnvelope(msg.Data)
+		if err != nil {
+			logger.Warnf("Invalid broadcast from %s: %v", msg.GetFrom(), err)
+			continue
+		}
+
+		select {
+		case b.incoming <- broadcastMessage{from: msg.GetFrom().String(), data: data, parent: parent}:
+		case <-b.ctx.Done():
+			return
+		}
 	}
 }
 
 // Broadcast는 데이터를 브로드캐스트
+// 로컬 쓰기처럼 컨텍스트에 스팬이 있으면 받는 피어가 이어갈 수 있도록 트레이스 컨텍스트를 붙인다.
+// 재브로드캐스트는 스팬 없이 원래 형식으로 보낸다.
 func (b *PubSubBroadcaster) Broadcast(ctx context.Context, data []byte) error {
-	return b.topic.Publish(ctx, data)
+	message := data
+	var span trace.Span
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		ctx, span = tracer.Start(ctx, "pubsub.publish",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(attribute.String("pubsub.topic", b.topic.String()), headsAttribute(data)),
+		)
+		message = wrapTraceEnvelope(ctx, data)
+	}
+	err := b.topic.Publish(ctx, message)
+	if span != nil {
+		endSpan(span, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.sent == nil {
+		b.sent = &BroadcastHeads{}
+	}
+	b.sent.record(data)
+	b.mu.Unlock()
+	return nil
 }
 
 // Next는 다음 브로드캐스트 메시지를 수신
+// go-ds-crdt는 이전 메시지의 heads를 모두 처리한 뒤에 호출하므로 이전 병합을 여기서 끝낸다.
 func (b *PubSubBroadcaster) Next(ctx context.Context) ([]byte, error) {
+	b.merges.end()
+	if b.processing != nil {
+		close(b.processing)
+		b.processing = nil
+	}
+	if b.applySpan != nil {
+		b.applySpan.End()
+		b.applySpan = nil
+	}
+
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-b.ctx.Done():
 		return nil, b.ctx.Err()
-	default:
-		msg, err := b.subscription.Next(ctx)
-		if err != nil {
-			return nil, err
+	case <-b.stopped:
+		return nil, crdt.ErrNoMoreBroadcast
+	case msg := <-b.incoming:
+		b.mu.Lock()
+		record, ok := b.received[msg.from]
+		if !ok {
+			record = &BroadcastHeads{Peer: msg.from}
+			b.received[msg.from] = record
 		}
+		record.record(msg.data)
+		heads := record.Heads
+		b.mu.Unlock()
 
-		// 자신이 보낸 메시지는 무시
-		peers := b.topic.ListPeers()
-		if len(peers) > 0 && msg.ReceivedFrom == peers[0] {
-			return b.Next(ctx)
+		b.merges.begin(msg.from, heads)
+		b.processing = msg.done
+		if msg.parent.IsValid() {
+			// 보낸 피어의 스팬을 이어 다음 메시지를 기다릴 때까지의 병합을 기록
+			_, b.applySpan = tracer.Start(trace.ContextWithRemoteSpanContext(b.ctx, msg.parent), "crdt.apply",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attribute.String("libp2p.peer", msg.from), headsAttribute(msg.data)),
+			)
 		}
 
-		return msg.Data, nil
+		return msg.data, nil
+	}
+}
+
+// Inject PubSub 밖에서 받은 피어의 heads를 브로드캐스트 메시지처럼 CRDT에 전달
+// CRDT가 heads를 모두 처리하면 반환한 채널이 닫힌다.
+func (b *PubSubBroadcaster) Inject(ctx context.Context, from string, heads []cid.Cid) (<-chan struct{}, error) {
+	msg := pb.CRDTBroadcast{}
+	for _, head := range heads {
+		msg.Heads = append(msg.Heads, &pb.Head{Cid: head.Bytes()})
+	}
+	data, err := proto.Marshal(&msg)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	parent := trace.SpanContextFromContext(ctx)
+	select {
+	case b.incoming <- broadcastMessage{from: from, data: data, done: done, parent: parent}:
+		return done, nil
+	case <-b.stopped:
+		return nil, crdt.ErrNoMoreBroadcast
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Sent 마지막으로 브로드캐스트한 heads (아직 없으면 nil)
+func (b *PubSubBroadcaster) Sent() *BroadcastHeads {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.sent == nil {
+		return nil
+	}
+	sent := *b.sent
+	return &sent
+}
+
+// Received 피어별로 마지막으로 수신한 heads
+func (b *PubSubBroadcaster) Received() []BroadcastHeads {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	received := make([]BroadcastHeads, 0, len(b.received))
+	for _, record := range b.received {
+		received = append(received, *record)
+	}
+	return received
+}
+
+// record 브로드캐스트 메시지 기록
+func (h *BroadcastHeads) record(data []byte) {
+	h.Heads = decodeBroadcastHeads(data)
+	h.At = time.Now()
+	h.Count++
+}
+
+// decodeBroadcastHeads go-ds-crdt 브로드캐스트 메시지에서 heads 추출
+// 디코딩할 수 없는 메시지는 nil을 반환한다.
+func decodeBroadcastHeads(data []byte) []cid.Cid {
+	msg := pb.CRDTBroadcast{}
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil
+	}
+
+	// 이전 버전은 CID를 그대로 브로드캐스트
+	if unknown := msg.ProtoReflect().GetUnknown(); len(unknown) > 0 {
+		c, err := cid.Cast(unknown)
+		if err != nil {
+			return nil
+		}
+		return []cid.Cid{c}
+	}
+
+	heads := make([]cid.Cid, 0, len(msg.Heads))
+	for _, head := range msg.Heads {
+		c, err := cid.Cast(head.Cid)
+		if err != nil {
+			return nil
+		}
+		heads = append(heads, c)
 	}
+	return heads
 }
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxBodyBytes 데이터 API 요청 본문의 기본 최대 크기
+	defaultMaxBodyBytes = 1 << 20
+	// rateLimitIdleTimeout 이 시간 동안 요청이 없는 클라이언트의 버킷은 제거
+	rateLimitIdleTimeout = 10 * time.Minute
+)
+
+// RateLimiter 클라이언트별 토큰 버킷 요청 제한기
+type RateLimiter struct {
+	// rate 초당 채워지는 토큰 수
+	rate float64
+	// burst 버킷 크기 (한 번에 허용되는 최대 요청 수)
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// tokenBucket 클라이언트의 토큰 버킷
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 새 요청 제한기 생성
+// burst가 1보다 작으면 rate를 올림한 값을 사용한다.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow 클라이언트의 요청을 허용할지 확인
+// 허용하지 않으면 다음 요청까지 기다려야 하는 시간을 반환한다.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+
+	// 경과 시간만큼 토큰 채우기
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep 오래 사용하지 않은 버킷 제거
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitIdleTimeout {
+		return
+	}
+	l.lastSweep = now
+	for client, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= rateLimitIdleTimeout {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// requestLimits 데이터 API 요청 제한 설정
+// 설정 다시 읽기로 바뀔 수 있으므로 요청마다 Server.requestLimits로 읽는다.
+type requestLimits struct {
+	rate  float64
+	burst int
+	// rateLimiter 요청 수 제한기 (nil이면 제한 없음)
+	rateLimiter  *RateLimiter
+	maxBodyBytes int64
+	trustProxy   bool
+}
+
+// setRequestLimits 요청 제한 설정 적용
+// 초당 요청 수와 최대 연속 요청 수가 그대로면 클라이언트별 버킷을 유지한다.
+func (s *Server) setRequestLimits(config Config) {
+	limits := &requestLimits{
+		rate:         config.RateLimit,
+		burst:        config.RateBurst,
+		maxBodyBytes: config.MaxBodyBytes,
+		trustProxy:   config.TrustProxy,
+	}
+	if old := s.limits.Load(); old != nil && old.rate == limits.rate && old.burst == limits.burst {
+		limits.rateLimiter = old.rateLimiter
+	} else if config.RateLimit > 0 {
+		limits.rateLimiter = NewRateLimiter(config.RateLimit, config.RateBurst)
+	}
+	s.limits.Store(limits)
+}
+
+// requestLimits 현재 요청 제한 설정
+func (s *Server) requestLimits() *requestLimits {
+	if limits := s.limits.Load(); limits != nil {
+		return limits
+	}
+	return &requestLimits{}
+}
+
+// rateLimitClient 요청 제한에 사용할 클라이언트 식별자
+// 인증된 요청은 주체 이름, 그 외에는 클라이언트 IP를 사용한다.
+func (s *Server) rateLimitClient(r *http.Request) string {
+	if principal := principalFromRequest(r); principal != nil {
+		return "principal:" + principal.Name
+	}
+
+	if s.requestLimits().trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			ip, _, _ := strings.Cut(forwarded, ",")
+			return "ip:" + strings.TrimSpace(ip)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// isLimitedPath 요청 제한과 본문 크기 제한을 적용할 경로인지 확인
+// 상태 확인과 오래 유지되는 SSE, WebSocket 연결은 제외한다.
+func isLimitedPath(path string) bool {
+	return strings.HasPrefix(path, "/api/data") || strings.HasPrefix(path, "/api/docs/") ||
+		strings.HasPrefix(path, "/api/ns/")
+}
+
+// limitMiddleware 요청 제한과 본문 크기 제한 미들웨어
+// 인증 미들웨어 안쪽에서 실행되어야 API 키별로 제한할 수 있다.
+func (s *Server) limitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLimitedPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limits := s.requestLimits()
+		if limits.rateLimiter != nil {
+			if ok, wait := limits.rateLimiter.Allow(s.rateLimitClient(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+		}
+
+		if limit := limits.maxBodyBytes; limit > 0 {
+			if r.ContentLength > limit {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body too large (max %d bytes)", limit))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeBodyError 요청 본문 읽기 실패 응답 작성
+// 본문이 최대 크기를 넘으면 413, 그 외에는 400으로 응답한다.
+func writeBodyError(w http.ResponseWriter, err error, message string) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body too large (max %d bytes)", maxBytesErr.Limit))
+		return
+	}
+	writeJSONError(w, http.StatusBadRequest, message)
+}
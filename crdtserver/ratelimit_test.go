@@ -0,0 +1,218 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock 테스트에서 직접 움직이는 시계
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestRateLimiter 테스트 시계를 사용하는 요청 제한기
+func newTestRateLimiter(rate float64, burst int) (*RateLimiter, *testClock) {
+	clock := &testClock{now: time.Unix(1_700_000_000, 0)}
+	limiter := NewRateLimiter(rate, burst)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+// TestRateLimiterRefill 버킷이 비면 거부하고 경과 시간만큼 다시 채우는지 확인
+func TestRateLimiterRefill(t *testing.T) {
+	limiter, clock := newTestRateLimiter(2, 3)
+
+	for i := 0; i < 3; i++ {
+		ok, wait := limiter.Allow("alice")
+		require.True(t, ok, "request %d", i)
+		assert.Zero(t, wait)
+	}
+	ok, wait := limiter.Allow("alice")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// 토큰 하나가 채워지기 전에는 계속 거부
+	clock.Advance(250 * time.Millisecond)
+	ok, wait = limiter.Allow("alice")
+	assert.False(t, ok)
+	assert.Equal(t, 250*time.Millisecond, wait)
+
+	clock.Advance(250 * time.Millisecond)
+	ok, _ = limiter.Allow("alice")
+	assert.True(t, ok)
+	ok, _ = limiter.Allow("alice")
+	assert.False(t, ok)
+
+	// 오래 쉬어도 버킷 크기까지만 채움
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ := limiter.Allow("alice")
+		require.True(t, ok, "request %d after idle", i)
+	}
+	ok, _ = limiter.Allow("alice")
+	assert.False(t, ok)
+}
+
+// TestRateLimiterDefaultBurst 버킷 크기를 지정하지 않으면 초당 요청 수를 올림해 사용하는지 확인
+func TestRateLimiterDefaultBurst(t *testing.T) {
+	limiter, _ := newTestRateLimiter(1.5, 0)
+	for i := 0; i < 2; i++ {
+		ok, _ := limiter.Allow("alice")
+		require.True(t, ok, "request %d", i)
+	}
+	ok, _ := limiter.Allow("alice")
+	assert.False(t, ok)
+}
+
+// TestRateLimiterPerKey 클라이언트마다 버킷이 따로 있고 쉬고 있는 버킷은 정리되는지 확인
+func TestRateLimiterPerKey(t *testing.T) {
+	limiter, clock := newTestRateLimiter(1, 1)
+
+	ok, _ := limiter.Allow("alice")
+	require.True(t, ok)
+	ok, _ = limiter.Allow("alice")
+	require.False(t, ok)
+
+	// 다른 클라이언트는 영향을 받지 않음
+	ok, _ = limiter.Allow("bob")
+	assert.True(t, ok)
+	ok, _ = limiter.Allow("bob")
+	assert.False(t, ok)
+
+	clock.Advance(rateLimitIdleTimeout)
+	ok, _ = limiter.Allow("carol")
+	assert.True(t, ok)
+	assert.Len(t, limiter.buckets, 1)
+}
+
+// newTestLimitServer 요청 제한이 설정된 서버와 요청 제한기의 시계
+func newTestLimitServer(t *testing.T, config Config) (*Server, http.Handler, *testClock) {
+	t.Helper()
+	s := &Server{}
+	s.setRequestLimits(config)
+	clock := &testClock{now: time.Unix(1_700_000_000, 0)}
+	if limiter := s.requestLimits().rateLimiter; limiter != nil {
+		limiter.now = clock.Now
+	}
+	handler := s.limitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			writeBodyError(w, err, "invalid body")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return s, handler, clock
+}
+
+// serveLimited 클라이언트 주소에서 보낸 요청을 처리하고 응답 반환
+func serveLimited(handler http.Handler, remoteAddr, path string, setup func(r *http.Request)) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader("{}"))
+	r.RemoteAddr = remoteAddr
+	if setup != nil {
+		setup(r)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// TestLimitMiddlewareTooManyRequests 제한을 넘은 요청에 429와 Retry-After로 응답하는지 확인
+func TestLimitMiddlewareTooManyRequests(t *testing.T) {
+	_, handler, clock := newTestLimitServer(t, Config{RateLimit: 0.5, RateBurst: 2})
+
+	for i := 0; i < 2; i++ {
+		w := serveLimited(handler, "10.0.0.1:1234", "/api/data/put", nil)
+		require.Equal(t, http.StatusNoContent, w.Code, "request %d", i)
+	}
+	w := serveLimited(handler, "10.0.0.1:1234", "/api/data/put", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "rate limit exceeded")
+
+	// Retry-After는 초 단위로 올림
+	clock.Advance(1500 * time.Millisecond)
+	w = serveLimited(handler, "10.0.0.1:1234", "/api/data/put", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	clock.Advance(500 * time.Millisecond)
+	w = serveLimited(handler, "10.0.0.1:1234", "/api/data/put", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// 다른 IP와 제한하지 않는 경로는 통과
+	w = serveLimited(handler, "10.0.0.2:1234", "/api/data/put", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serveLimited(handler, "10.0.0.1:1234", "/health", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// TestLimitMiddlewareClientKey 인증된 주체와 X-Forwarded-For로 클라이언트를 구분하는지 확인
+func TestLimitMiddlewareClientKey(t *testing.T) {
+	s, handler, _ := newTestLimitServer(t, Config{RateLimit: 1, RateBurst: 1, TrustProxy: true})
+	auth, err := NewAuthenticator(&AuthConfig{APIKeys: []APIKeyConfig{
+		{Key: "alice-key", Name: "alice", Permissions: map[string]string{"/": "write"}},
+		{Key: "bob-key", Name: "bob", Permissions: map[string]string{"/": "write"}},
+	}})
+	require.NoError(t, err)
+	s.auth = auth
+	handler = s.authMiddleware(handler)
+
+	apiKey := func(key string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("X-API-Key", key) }
+	}
+	forwardedFor := func(ip string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set("X-API-Key", "alice-key")
+			r.Header.Set("X-Forwarded-For", ip)
+		}
+	}
+
+	// 같은 IP라도 API 키마다 따로 제한
+	assert.Equal(t, http.StatusNoContent, serveLimited(handler, "10.0.0.1:1", "/api/data/put", apiKey("alice-key")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serveLimited(handler, "10.0.0.1:1", "/api/data/put", apiKey("alice-key")).Code)
+	assert.Equal(t, http.StatusNoContent, serveLimited(handler, "10.0.0.1:1", "/api/data/put", apiKey("bob-key")).Code)
+
+	// 인증된 요청은 IP가 바뀌어도 같은 버킷 사용
+	assert.Equal(t, http.StatusTooManyRequests, serveLimited(handler, "10.0.0.9:1", "/api/data/put", forwardedFor("10.0.0.9")).Code)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "192.0.2.7, 10.0.0.1")
+	assert.Equal(t, "ip:192.0.2.7", s.rateLimitClient(r))
+
+	s.setRequestLimits(Config{RateLimit: 1, RateBurst: 1})
+	assert.Equal(t, "ip:10.0.0.1", s.rateLimitClient(r))
+}
+
+// TestLimitMiddlewareBodySize 최대 크기를 넘는 본문에 413으로 응답하는지 확인
+func TestLimitMiddlewareBodySize(t *testing.T) {
+	_, handler, _ := newTestLimitServer(t, Config{MaxBodyBytes: 8})
+
+	w := serveLimited(handler, "10.0.0.1:1", "/api/data/put", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// Content-Length가 큰 요청은 본문을 읽기 전에 거부
+	r := httptest.NewRequest(http.MethodPost, "/api/data/put", strings.NewReader(`{"gold":1000000}`))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Content-Length가 없는 요청은 읽는 도중 거부
+	r = httptest.NewRequest(http.MethodPost, "/api/data/put", io.NopCloser(strings.NewReader(`{"gold":1000000}`)))
+	r.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "max 8 bytes")
+}
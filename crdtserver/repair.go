@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	dag "github.com/ipfs/go-merkledag"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// repairProtocol 피어 간 안티 엔트로피 복구 프로토콜
+	repairProtocol = protocol.ID("/crdtserver/repair/1.0.0")
+	// repairBuckets 다이제스트를 비교하는 키 해시 범위 수
+	repairBuckets = 256
+	// repairBatchSize 요청 하나로 가져오는 최대 블록 수
+	repairBatchSize = 64
+	// repairTimeout 복구 한 번의 제한 시간
+	repairTimeout = 5 * time.Minute
+	// repairMaxKeys 복구 결과에 보관하는 최대 키 수
+	repairMaxKeys = 100
+)
+
+// repairRequest 복구 프로토콜 요청
+type repairRequest struct {
+	// Type 요청 유형 (digest: heads와 키 범위 다이제스트, blocks: DAG 블록)
+	Type string `json:"type"`
+	// Prefix 다이제스트를 계산할 키 접두사
+	Prefix string `json:"prefix,omitempty"`
+	// CIDs 가져올 블록
+	CIDs []string `json:"cids,omitempty"`
+}
+
+// repairResponse 복구 프로토콜 응답
+type repairResponse struct {
+	Error   string        `json:"error,omitempty"`
+	Heads   []string      `json:"heads,omitempty"`
+	Digests []string      `json:"digests,omitempty"`
+	Blocks  []repairBlock `json:"blocks,omitempty"`
+}
+
+// repairBlock 복구 프로토콜로 주고받는 DAG 블록
+type repairBlock struct {
+	CID  string `json:"cid"`
+	Data []byte `json:"data"`
+}
+
+// RepairResult 안티 엔트로피 복구 결과
+type RepairResult struct {
+	// Peer 복구에 사용한 피어
+	Peer string `json:"peer"`
+	// Prefix 비교한 키 접두사
+	Prefix string `json:"prefix"`
+	// Buckets 비교한 키 해시 범위 수
+	Buckets int `json:"buckets"`
+	// DivergentBuckets 복구 전에 다이제스트가 달랐던 범위 수
+	DivergentBuckets int `json:"divergentBuckets"`
+	// Heads 피어의 heads
+	Heads []string `json:"heads"`
+	// BlocksFetched 피어에서 가져온 DAG 블록 수
+	BlocksFetched int `json:"blocksFetched"`
+	// Reconciled 복구로 값이 바뀐 키 수
+	Reconciled int `json:"reconciled"`
+	// Keys 복구로 값이 바뀐 키
+	Keys []string `json:"keys"`
+	// Truncated 키가 너무 많아 일부만 기록했는지 여부
+	Truncated bool `json:"truncated,omitempty"`
+	// RemainingBuckets 복구 후에도 다이제스트가 다른 범위 수
+	// 피어가 아직 받지 못한 로컬 쓰기가 있으면 0이 아닐 수 있다.
+	RemainingBuckets int `json:"remainingBuckets"`
+	// DurationMs 복구에 걸린 시간 (밀리초)
+	DurationMs float64 `json:"durationMs"`
+}
+
+// repairSnapshot 키별 값 해시
+type repairSnapshot map[string][sha256.Size]byte
+
+// snapshotForRepair 접두사에 속하는 키의 값 해시 계산
+func (s *Server) snapshotForRepair(ctx context.Context, prefix string) (repairSnapshot, error) {
+	entries, err := s.listValues(ctx, s.data, prefix)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(repairSnapshot, len(entries))
+	for _, entry := range entries {
+		snapshot[ds.NewKey(entry.Key).String()] = sha256.Sum256(entry.Value)
+	}
+	return snapshot, nil
+}
+
+// repairBucket 키가 속하는 키 해시 범위
+func repairBucket(key string) int {
+	sum := sha256.Sum256([]byte(key))
+	return int(binary.BigEndian.Uint32(sum[:4]) % repairBuckets)
+}
+
+// digests 키 해시 범위별 다이제스트 (키가 없는 범위는 빈 문자열)
+func (snapshot repairSnapshot) digests() []string {
+	buckets := make([][]string, repairBuckets)
+	for key := range snapshot {
+		bucket := repairBucket(key)
+		buckets[bucket] = append(buckets[bucket], key)
+	}
+
+	digests := make([]string, repairBuckets)
+	for i, keys := range buckets {
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+		h := sha256.New()
+		for _, key := range keys {
+			value := snapshot[key]
+			h.Write([]byte(key))
+			h.Write([]byte{0})
+			h.Write(value[:])
+		}
+		digests[i] = hex.EncodeToString(h.Sum(nil))
+	}
+	return digests
+}
+
+// divergentBuckets 다이제스트가 다른 키 해시 범위
+func divergentBuckets(local, remote []string) map[int]bool {
+	divergent := make(map[int]bool)
+	for i := range local {
+		if local[i] != remote[i] {
+			divergent[i] = true
+		}
+	}
+	return divergent
+}
+
+// setupRepair 다른 피어의 복구 요청을 처리하는 스트림 핸들러 등록
+func (s *Server) setupRepair() {
+	s.host.SetStreamHandler(repairProtocol, s.handleRepairStream)
+}
+
+// handleRepairStream 복구 프로토콜 스트림 처리
+// 스트림 하나에서 요청을 JSON으로 하나씩 주고받는다.
+func (s *Server) handleRepairStream(stream network.Stream) {
+	defer stream.Close()
+	stream.SetDeadline(time.Now().Add(repairTimeout))
+
+	decoder := json.NewDecoder(stream)
+	encoder := json.NewEncoder(stream)
+	for {
+		var req repairRequest
+		if err := decoder.Decode(&req); err != nil {
+			if err != io.EOF {
+				logger.Warnf("Invalid repair request from %s: %v", stream.Conn().RemotePeer(), err)
+			}
+			return
+		}
+		if err := encoder.Encode(s.answerRepair(s.ctx, req)); err != nil {
+			logger.Warnf("Failed to send repair response to %s: %v", stream.Conn().RemotePeer(), err)
+			return
+		}
+	}
+}
+
+// answerRepair 복구 프로토콜 요청에 대한 응답 생성
+func (s *Server) answerRepair(ctx context.Context, req repairRequest) repairResponse {
+	switch req.Type {
+	case "digest":
+		snapshot, err := s.snapshotForRepair(ctx, ds.NewKey(req.Prefix).String())
+		if err != nil {
+			return repairResponse{Error: err.Error()}
+		}
+		return repairResponse{
+			Heads:   cidStrings(s.crdt.InternalStats(ctx).Heads),
+			Digests: snapshot.digests(),
+		}
+
+	case "blocks":
+		if len(req.CIDs) > repairBatchSize {
+			return repairResponse{Error: fmt.Sprintf("too many blocks (max %d)", repairBatchSize)}
+		}
+		resp := repairResponse{Blocks: []repairBlock{}}
+		for _, raw := range req.CIDs {
+			c, err := cid.Decode(raw)
+			if err != nil {
+				return repairResponse{Error: fmt.Sprintf("invalid cid %q", raw)}
+			}
+			block, err := s.bstore.Get(ctx, c)
+			if err != nil {
+				// 없는 블록은 응답에서 빠지고 요청한 피어가 에러로 처리한다.
+				continue
+			}
+			resp.Blocks = append(resp.Blocks, repairBlock{CID: raw, Data: block.RawData()})
+		}
+		return resp
+
+	default:
+		return repairResponse{Error: fmt.Sprintf("unknown request type %q", req.Type)}
+	}
+}
+
+// repairSession 복구할 피어와 연 스트림
+type repairSession struct {
+	encoder *json.Encoder
+	decoder *json.Decoder
+}
+
+// call 요청을 보내고 응답을 기다림
+func (rs *repairSession) call(req repairRequest) (*repairResponse, error) {
+	if err := rs.encoder.Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send repair request: %w", err)
+	}
+	var resp repairResponse
+	if err := rs.decoder.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read repair response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("peer rejected repair request: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// Repair 피어와 키 범위 다이제스트를 비교하고 다른 범위가 있으면 피어의 heads까지 DAG를 가져와 병합
+//
+// 오랫동안 PubSub 메시지를 받지 못해 놓친 heads를 다음 재브로드캐스트까지 기다리지 않고
+// 복구한다. 피어의 상태를 이 노드로 가져오는 단방향 복구이므로 피어가 받지 못한 로컬
+// 쓰기는 피어에서 이 노드를 대상으로 복구를 실행해야 전달된다.
+func (s *Server) Repair(ctx context.Context, target peer.ID, prefix string) (*RepairResult, error) {
+	started := time.Now()
+	result := &RepairResult{
+		Peer:    target.String(),
+		Prefix:  prefix,
+		Buckets: repairBuckets,
+		Heads:   []string{},
+		Keys:    []string{},
+	}
+
+	stream, err := s.host.NewStream(ctx, target, repairProtocol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repair stream: %w", err)
+	}
+	defer stream.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+	session := &repairSession{
+		encoder: json.NewEncoder(stream),
+		decoder: json.NewDecoder(stream),
+	}
+
+	// 키 범위 다이제스트 비교
+	before, err := s.snapshotForRepair(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := session.call(repairRequest{Type: "digest", Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	if len(remote.Digests) != repairBuckets {
+		return nil, fmt.Errorf("peer sent %d digests, expected %d", len(remote.Digests), repairBuckets)
+	}
+	divergent := divergentBuckets(before.digests(), remote.Digests)
+	result.DivergentBuckets = len(divergent)
+	if remote.Heads != nil {
+		result.Heads = remote.Heads
+	}
+	if len(divergent) == 0 {
+		result.DurationMs = float64(time.Since(started).Microseconds()) / 1000
+		return result, nil
+	}
+
+	// 피어의 heads에서 로컬에 없는 블록을 따라 내려가며 가져옴
+	heads := make([]cid.Cid, 0, len(remote.Heads))
+	for _, raw := range remote.Heads {
+		c, err := cid.Decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf("peer sent invalid head %q", raw)
+		}
+		heads = append(heads, c)
+	}
+	result.BlocksFetched, err = s.fetchRepairBlocks(ctx, session, heads)
+	if err != nil {
+		return nil, err
+	}
+
+	// 가져온 heads를 CRDT에 병합
+	if len(heads) > 0 {
+		done, err := s.broadcaster.Inject(ctx, target.String(), heads)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge peer heads: %w", err)
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out merging peer heads: %w", ctx.Err())
+		}
+	}
+
+	// 다른 범위에서 값이 바뀐 키 집계
+	after, err := s.snapshotForRepair(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, snapshot := range []repairSnapshot{before, after} {
+		for key := range snapshot {
+			if !divergent[repairBucket(key)] {
+				continue
+			}
+			oldValue, hadOld := before[key]
+			newValue, hasNew := after[key]
+			if hadOld != hasNew || oldValue != newValue {
+				changed = append(changed, key)
+			}
+		}
+	}
+	sort.Strings(changed)
+	changed = dedupSorted(changed)
+	result.Reconciled = len(changed)
+	if len(changed) > repairMaxKeys {
+		changed = changed[:repairMaxKeys]
+		result.Truncated = true
+	}
+	result.Keys = changed
+	result.RemainingBuckets = len(divergentBuckets(after.digests(), remote.Digests))
+	result.DurationMs = float64(time.Since(started).Microseconds()) / 1000
+
+	logger.Infof("Repaired from %s: %d divergent buckets, %d blocks fetched, %d keys reconciled",
+		target, result.DivergentBuckets, result.BlocksFetched, result.Reconciled)
+	return result, nil
+}
+
+// fetchRepairBlocks heads에서 시작해 로컬 블록스토어에 없는 DAG 블록을 피어에서 가져옴
+// 이미 있는 블록에서는 더 내려가지 않는다.
+func (s *Server) fetchRepairBlocks(ctx context.Context, session *repairSession, heads []cid.Cid) (int, error) {
+	seen := make(map[cid.Cid]bool)
+	queue := append([]cid.Cid(nil), heads...)
+	fetched := 0
+
+	for len(queue) > 0 {
+		var batch []cid.Cid
+		for len(queue) > 0 && len(batch) < repairBatchSize {
+			c := queue[0]
+			queue = queue[1:]
+			if seen[c] {
+				continue
+			}
+			seen[c] = true
+			has, err := s.bstore.Has(ctx, c)
+			if err != nil {
+				return fetched, err
+			}
+			if !has {
+				batch = append(batch, c)
+			}
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		resp, err := session.call(repairRequest{Type: "blocks", CIDs: cidStrings(batch)})
+		if err != nil {
+			return fetched, err
+		}
+		received := make(map[cid.Cid][]byte, len(resp.Blocks))
+		for _, block := range resp.Blocks {
+			c, err := cid.Decode(block.CID)
+			if err != nil {
+				return fetched, fmt.Errorf("peer sent invalid cid %q", block.CID)
+			}
+			received[c] = block.Data
+		}
+
+		for _, c := range batch {
+			data, ok := received[c]
+			if !ok {
+				return fetched, fmt.Errorf("peer does not have block %s", c)
+			}
+			// 피어가 보낸 데이터가 CID와 일치하는지 확인
+			sum, err := c.Prefix().Sum(data)
+			if err != nil || !sum.Equals(c) {
+				return fetched, fmt.Errorf("peer sent corrupt block %s", c)
+			}
+			block, err := blocks.NewBlockWithCid(data, c)
+			if err != nil {
+				return fetched, err
+			}
+			if err := s.bstore.Put(ctx, block); err != nil {
+				return fetched, fmt.Errorf("failed to store block %s: %w", c, err)
+			}
+			fetched++
+
+			node, err := dag.DecodeProtobuf(data)
+			if err != nil {
+				return fetched, fmt.Errorf("failed to decode block %s: %w", c, err)
+			}
+			for _, link := range node.Links() {
+				queue = append(queue, link.Cid)
+			}
+		}
+	}
+	return fetched, nil
+}
+
+// dedupSorted 정렬된 목록에서 중복 제거
+func dedupSorted(values []string) []string {
+	out := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// parseRepairPeer 피어 ID 또는 /p2p/ 멀티주소를 피어 정보로 변환
+func parseRepairPeer(raw string) (*peer.AddrInfo, error) {
+	if strings.HasPrefix(raw, "/") {
+		addr, err := multiaddr.NewMultiaddr(raw)
+		if err != nil {
+			return nil, err
+		}
+		return peer.AddrInfoFromP2pAddr(addr)
+	}
+	id, err := peer.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &peer.AddrInfo{ID: id}, nil
+}
+
+// handleAdminRepair 선택한 피어와 안티 엔트로피 복구 실행
+//
+//	POST /api/admin/repair  {"peer": "<피어 ID 또는 멀티주소>", "prefix": "/game"}
+//
+// 복구는 한 번에 하나만 실행한다.
+func (s *Server) handleAdminRepair(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, "/", PermissionAdmin) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Peer   string `json:"peer"`
+		Prefix string `json:"prefix"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBodyError(w, err, "invalid request body: "+err.Error())
+		return
+	}
+	if req.Peer == "" {
+		writeJSONError(w, http.StatusBadRequest, "peer is required")
+		return
+	}
+	info, err := parseRepairPeer(req.Peer)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid peer: "+err.Error())
+		return
+	}
+	if info.ID == s.host.ID() {
+		writeJSONError(w, http.StatusBadRequest, "cannot repair from self")
+		return
+	}
+
+	if !s.repairMu.TryLock() {
+		writeJSONError(w, http.StatusConflict, "repair already in progress")
+		return
+	}
+	defer s.repairMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.Context(), repairTimeout)
+	defer cancel()
+
+	if len(info.Addrs) > 0 {
+		if err := s.host.Connect(ctx, *info); err != nil {
+			writeJSONError(w, http.StatusBadGateway, "failed to connect to peer: "+err.Error())
+			return
+		}
+	}
+
+	result, err := s.Repair(ctx, info.ID, ds.NewKey(req.Prefix).String())
+	if err != nil {
+		logger.Errorf("Repair from %s failed: %v", info.ID, err)
+		status := http.StatusBadGateway
+		if errors.Is(err, context.DeadlineExceeded) {
+			status = http.StatusGatewayTimeout
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
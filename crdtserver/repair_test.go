@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	crdt "github.com/ipfs/go-ds-crdt"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	format "github.com/ipfs/go-ipld-format"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepairServer 메모리 데이터스토어와 로컬 libp2p 호스트를 사용하는 서버
+// 서버마다 다른 토픽을 사용하므로 복구로만 데이터를 주고받는다.
+func newTestRepairServer(t *testing.T, topicName string) *Server {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h, err := NewHost(Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}})
+	require.NoError(t, err)
+	ps, err := pubsub.NewGossipSub(ctx, h)
+	require.NoError(t, err)
+	topic, err := ps.Join(topicName)
+	require.NoError(t, err)
+	subscription, err := topic.Subscribe()
+	require.NoError(t, err)
+	broadcaster := NewPubSubBroadcaster(ctx, topic, subscription)
+
+	store := dssync.MutexWrap(ds.NewMapDatastore())
+	bstore := blockstore.NewBlockstore(store)
+	datastore, err := crdt.New(store, ds.NewKey("/crdt-data"), NewSimpleDAGService(bstore), broadcaster, newCRDTOptions())
+	require.NoError(t, err)
+
+	s := &Server{
+		host:        h,
+		crdt:        datastore,
+		data:        &dataStore{crdt: datastore, scope: ds.NewKey("/"), cachePrefix: ds.NewKey(jsonMergeCachePrefix)},
+		bstore:      bstore,
+		broadcaster: broadcaster,
+		hooks:       NewWriteHooks(),
+		ctx:         ctx,
+	}
+	s.setupRepair()
+	t.Cleanup(func() {
+		datastore.Close()
+		cancel()
+		h.Close()
+	})
+	return s
+}
+
+// connectRepairPeers 복구를 실행할 수 있도록 두 서버를 연결
+func connectRepairPeers(t *testing.T, local, remote *Server) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, local.host.Connect(ctx, peer.AddrInfo{ID: remote.host.ID(), Addrs: remote.host.Addrs()}))
+}
+
+// repairFrom 제한 시간 안에 피어에서 복구
+func repairFrom(local, remote *Server, prefix string) (*RepairResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return local.Repair(ctx, remote.host.ID(), prefix)
+}
+
+// faultyBlockstore 지정한 블록을 손상되거나 없는 것으로 응답하는 블록스토어
+type faultyBlockstore struct {
+	blockstore.Blockstore
+	corrupt map[cid.Cid]bool
+	missing map[cid.Cid]bool
+}
+
+func (b *faultyBlockstore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	if b.missing[c] {
+		return nil, format.ErrNotFound{Cid: c}
+	}
+	block, err := b.Blockstore.Get(ctx, c)
+	if err != nil || !b.corrupt[c] {
+		return block, err
+	}
+	data := append([]byte(nil), block.RawData()...)
+	data[len(data)-1] ^= 0xff
+	return blocks.NewBlockWithCid(data, c)
+}
+
+// TestRepairRoundTrip 피어의 값을 가져와 병합하고 바뀐 키를 보고하는지 확인
+func TestRepairRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRepairServer(t, "repair-remote")
+	local := newTestRepairServer(t, "repair-local")
+	connectRepairPeers(t, local, remote)
+
+	require.NoError(t, remote.putValue(ctx, remote.data, ds.NewKey("/game/alice"), []byte("100"), 0))
+	require.NoError(t, remote.putValue(ctx, remote.data, ds.NewKey("/game/bob"), []byte("50"), 0))
+	require.NoError(t, remote.putValue(ctx, remote.data, ds.NewKey("/shop/sword"), []byte("10"), 0))
+	require.NoError(t, local.putValue(ctx, local.data, ds.NewKey("/game/carol"), []byte("70"), 0))
+
+	result, err := repairFrom(local, remote, "/game")
+	require.NoError(t, err)
+	assert.Equal(t, remote.host.ID().String(), result.Peer)
+	assert.Equal(t, repairBuckets, result.Buckets)
+	assert.Positive(t, result.DivergentBuckets)
+	assert.Positive(t, result.BlocksFetched)
+	assert.Equal(t, 2, result.Reconciled)
+	assert.Equal(t, []string{"/game/alice", "/game/bob"}, result.Keys)
+	// 피어가 받지 못한 로컬 쓰기는 남아 있음
+	assert.Equal(t, 1, result.RemainingBuckets)
+
+	for key, want := range map[string]string{"/game/alice": "100", "/game/bob": "50", "/game/carol": "70"} {
+		value, err := local.getValue(ctx, local.data, ds.NewKey(key))
+		require.NoError(t, err, key)
+		assert.Equal(t, want, string(value), key)
+	}
+
+	// 이미 가져온 블록은 다시 가져오지 않음
+	result, err = repairFrom(local, remote, "/game")
+	require.NoError(t, err)
+	assert.Zero(t, result.BlocksFetched)
+	assert.Zero(t, result.Reconciled)
+	assert.Empty(t, result.Keys)
+
+	// 반대 방향으로 복구하면 두 피어가 같아짐
+	result, err = repairFrom(remote, local, "/game")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/game/carol"}, result.Keys)
+	assert.Zero(t, result.RemainingBuckets)
+}
+
+// TestRepairCorruptBlock 피어가 손상되거나 없는 블록을 보내면 저장하지 않고 실패한 뒤 다시 복구할 수 있는지 확인
+func TestRepairCorruptBlock(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRepairServer(t, "repair-remote")
+	local := newTestRepairServer(t, "repair-local")
+	connectRepairPeers(t, local, remote)
+
+	require.NoError(t, remote.putValue(ctx, remote.data, ds.NewKey("/game/alice"), []byte("100"), 0))
+	require.NoError(t, remote.putValue(ctx, remote.data, ds.NewKey("/game/bob"), []byte("50"), 0))
+	heads := remote.crdt.InternalStats(ctx).Heads
+	require.NotEmpty(t, heads)
+	head := heads[0]
+
+	healthy := remote.bstore
+	faulty := &faultyBlockstore{Blockstore: healthy, corrupt: map[cid.Cid]bool{head: true}}
+	remote.bstore = faulty
+
+	_, err := repairFrom(local, remote, "/game")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "corrupt block")
+	has, err := local.bstore.Has(ctx, head)
+	require.NoError(t, err)
+	assert.False(t, has, "corrupt block must not be stored")
+	entries, err := local.listValues(ctx, local.data, "/game")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	faulty.corrupt = nil
+	faulty.missing = map[cid.Cid]bool{head: true}
+	_, err = repairFrom(local, remote, "/game")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not have block")
+
+	// 피어가 정상 블록을 보내면 복구됨
+	remote.bstore = healthy
+	result, err := repairFrom(local, remote, "/game")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/game/alice", "/game/bob"}, result.Keys)
+	value, err := local.getValue(ctx, local.data, ds.NewKey("/game/alice"))
+	require.NoError(t, err)
+	assert.Equal(t, "100", string(value))
+}
+
+// TestRepairDigests 다이제스트가 값이 다른 키의 범위에서만 달라지는지 확인
+func TestRepairDigests(t *testing.T) {
+	a := repairSnapshot{"/game/alice": {1}, "/game/bob": {2}}
+	b := repairSnapshot{"/game/alice": {1}, "/game/bob": {3}}
+
+	assert.Empty(t, divergentBuckets(a.digests(), a.digests()))
+	assert.Equal(t, map[int]bool{repairBucket("/game/bob"): true}, divergentBuckets(a.digests(), b.digests()))
+	assert.Equal(t, []string{"a", "b"}, dedupSorted([]string{"a", "a", "b", "b"}))
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	crdt "github.com/ipfs/go-ds-crdt"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer 메모리 CRDT 데이터스토어를 사용하는 서버
+// 다른 노드와 통신하지 않으며 라우트와 저장소 구성은 NewServer와 같다.
+func newTestServer(t *testing.T, config Config) *Server {
+	t.Helper()
+	return newTestReplica(t, config, nopBroadcaster{}, nil)
+}
+
+// testLocalPeer 테스트 서버가 로컬 쓰기 블록의 출처로 기록하는 피어
+const testLocalPeer = "local-peer"
+
+// newTestBroadcaster 로컬 libp2p 호스트의 PubSub 토픽을 사용하는 브로드캐스터
+// 토픽을 구독하는 다른 피어가 없으므로 다른 노드의 heads는 Inject로만 받는다.
+func newTestBroadcaster(t *testing.T) *PubSubBroadcaster {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	h, err := NewHost(Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}})
+	require.NoError(t, err)
+	ps, err := pubsub.NewGossipSub(ctx, h)
+	require.NoError(t, err)
+	topic, err := ps.Join("crdt-sync")
+	require.NoError(t, err)
+	subscription, err := topic.Subscribe()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		h.Close()
+	})
+	return NewPubSubBroadcaster(ctx, topic, subscription)
+}
+
+// newTestReplica 브로드캐스터로 다른 테스트 서버와 델타를 주고받는 서버
+// 블록은 bstore에 저장하며, bstore가 nil이면 서버의 메모리 데이터스토어에 저장한다.
+// 브로드캐스터가 PubSubBroadcaster이면 NewServer처럼 원격 병합을 기록한다.
+// JSON 모드를 사용하면 필드 버전에 쓸 피어 ID를 위해 로컬 libp2p 호스트를 만든다.
+func newTestReplica(t *testing.T, config Config, broadcaster crdt.Broadcaster, bstore blockstore.Blockstore) *Server {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	if config.PubSubTopic == "" {
+		config.PubSubTopic = "crdt-sync"
+	}
+	if config.DataNamespace == "" {
+		config.DataNamespace = "/crdt-data"
+	}
+
+	store := dssync.MutexWrap(ds.NewMapDatastore())
+	if bstore == nil {
+		bstore = blockstore.NewBlockstore(store)
+	}
+	dagService := NewSimpleDAGService(bstore)
+	merges := NewMergeLog(config.MergeLogSize)
+	merges.origins = NewBlockOrigins(store, testLocalPeer)
+	if pubsubBroadcaster, ok := broadcaster.(*PubSubBroadcaster); ok {
+		pubsubBroadcaster.merges = merges
+	}
+	syncHub := NewSyncHub(ctx)
+	jsonMerger := NewJSONMerger(config.JSONPrefixes)
+	data := &dataStore{scope: ds.NewKey("/"), cachePrefix: ds.NewKey(jsonMergeCachePrefix)}
+	opts := newCRDTOptions()
+	opts.PutHook = func(k ds.Key, v []byte) {
+		syncHub.handlePut(k, v)
+		jsonMerger.handleChange(data, k)
+	}
+	datastore, err := crdt.New(store, ds.NewKey(config.DataNamespace), newMergeAuditDAGService(dagService, merges), broadcaster, opts)
+	require.NoError(t, err)
+	data.crdt = datastore
+
+	s := &Server{
+		config:     config,
+		store:      store,
+		crdt:       datastore,
+		data:       data,
+		bstore:     bstore,
+		dagService: dagService,
+		merges:     merges,
+		ctx:        ctx,
+		cancel:     cancel,
+		docs:       NewDocumentStore(datastore),
+		syncHub:    syncHub,
+		hooks:      NewWriteHooks(),
+		jsonMerger: jsonMerger,
+		sseClients: make(map[string]*sseClient),
+		sseHistory: newSSEHistory(config.SSEReplaySize),
+		startTime:  time.Now(),
+	}
+	s.sseEpoch = newSSEEpoch(s.startTime)
+	if pubsubBroadcaster, ok := broadcaster.(*PubSubBroadcaster); ok {
+		s.broadcaster = pubsubBroadcaster
+	}
+	s.namespaces = NewNamespaceManager(s)
+	syncHub.docs = s.docs
+	s.setRequestLimits(config)
+	s.setupRoutes()
+
+	t.Cleanup(func() {
+		cancel()
+		datastore.Close()
+	})
+	if jsonMerger != nil {
+		h, err := NewHost(Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}})
+		require.NoError(t, err)
+		t.Cleanup(func() { h.Close() })
+		s.host = h
+		for _, prefix := range jsonMerger.prefixes {
+			s.hooks.Register(prefix, jsonObjectHook{})
+		}
+		go s.runJSONMerger()
+	}
+	return s
+}
+
+// serveTest 서버의 HTTP 핸들러로 요청을 처리하고 응답 반환
+func serveTest(s *Server, method, target, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, r)
+	return w
+}
+
+// watchEvents 이벤트를 받는 SSE 클라이언트를 등록하고 이벤트 채널 반환
+func watchEvents(t *testing.T, s *Server) <-chan sseMessage {
+	t.Helper()
+	client := &sseClient{messages: make(chan sseMessage, sseSendBuffer)}
+	id, _, _, _, _ := s.registerSSEClient(client, "")
+	t.Cleanup(func() { s.unregisterSSEClient(id) })
+	return client.messages
+}
+
+// nextEvent 다음 이벤트의 유형, 키와 값
+func nextEvent(t *testing.T, events <-chan sseMessage) (event, key, value string) {
+	t.Helper()
+	select {
+	case msg := <-events:
+		var data struct {
+			Event string `json:"event"`
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		require.NoError(t, json.Unmarshal(msg.data, &data))
+		return data.Event, data.Key, data.Value
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return "", "", ""
+	}
+}
+
+// getJSON GET 요청의 JSON 응답을 v에 디코딩하고 상태 코드 반환
+func getJSON(t *testing.T, s *Server, target string, v interface{}) int {
+	t.Helper()
+	w := serveTest(s, http.MethodGet, target, "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), v), w.Body.String())
+	return w.Code
+}
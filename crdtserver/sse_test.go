@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent SSE 스트림에서 받은 이벤트
+type sseEvent struct {
+	ID   string
+	Data map[string]interface{}
+}
+
+// openSSE 테스트 서버의 SSE 엔드포인트에 연결하고 받은 이벤트 채널 반환
+func openSSE(t *testing.T, url string, header http.Header) <-chan sseEvent {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	t.Cleanup(func() {
+		cancel()
+		resp.Body.Close()
+	})
+
+	events := make(chan sseEvent, sseSendBuffer)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				event.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.Data); err != nil {
+					return
+				}
+			case line == "":
+				events <- event
+				event = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+// nextSSE 다음 SSE 이벤트
+func nextSSE(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "SSE stream closed")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for SSE event")
+		return sseEvent{}
+	}
+}
+
+// requireSSE 다음 SSE 이벤트의 유형, 키, seq와 prev 확인
+func requireSSE(t *testing.T, events <-chan sseEvent, eventType, key string, seq, prev float64) sseEvent {
+	t.Helper()
+	event := nextSSE(t, events)
+	require.Equal(t, eventType, event.Data["event"], event.Data)
+	assert.Equal(t, key, event.Data["key"], event.Data)
+	assert.Equal(t, seq, event.Data["seq"], event.Data)
+	assert.Equal(t, prev, event.Data["prev"], event.Data)
+	return event
+}
+
+// TestSSEFilter prefix와 ops 필터에 일치하는 이벤트만 전달되고 prev가 직전 전달 이벤트를 가리키는지 확인
+func TestSSEFilter(t *testing.T) {
+	s := newTestServer(t, Config{})
+	server := httptest.NewServer(s.server.Handler)
+	t.Cleanup(server.Close)
+
+	all := openSSE(t, server.URL+"/events", nil)
+	filtered := openSSE(t, server.URL+"/events?prefix=boss&prefix=/raid/loot&ops=put,delete", nil)
+	putsOnly := openSSE(t, server.URL+"/events?prefix=boss&ops=put", nil)
+	for _, events := range []<-chan sseEvent{all, filtered, putsOnly} {
+		connected := nextSSE(t, events)
+		assert.Equal(t, "connected", connected.Data["event"])
+		assert.Equal(t, float64(0), connected.Data["seq"])
+	}
+
+	putData(t, s, "player/alice", "1")
+	putData(t, s, "boss/hp", "100")
+	w := serveTest(s, http.MethodDelete, "/api/data/boss/hp", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	putData(t, s, "raid/loot/sword", "1")
+	putData(t, s, "boss/mp", "50")
+
+	requireSSE(t, all, "put", "player/alice", 1, 0)
+	requireSSE(t, all, "put", "boss/hp", 2, 1)
+	requireSSE(t, all, "delete", "boss/hp", 3, 2)
+	requireSSE(t, all, "put", "raid/loot/sword", 4, 3)
+	requireSSE(t, all, "put", "boss/mp", 5, 4)
+
+	event := requireSSE(t, filtered, "put", "boss/hp", 2, 0)
+	assert.Equal(t, "100", event.Data["value"])
+	requireSSE(t, filtered, "delete", "boss/hp", 3, 2)
+	requireSSE(t, filtered, "put", "raid/loot/sword", 4, 3)
+	requireSSE(t, filtered, "put", "boss/mp", 5, 4)
+
+	// 필터에 걸러진 이벤트는 prev에서도 건너뜀
+	requireSSE(t, putsOnly, "put", "boss/hp", 2, 0)
+	requireSSE(t, putsOnly, "put", "boss/mp", 5, 2)
+}
+
+// TestSSEFilterValidation 알 수 없는 이벤트 유형을 400으로 거부하는지 확인
+func TestSSEFilterValidation(t *testing.T) {
+	s := newTestServer(t, Config{})
+	w := serveTest(s, http.MethodGet, "/events?ops=put,update", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown event type: \"update\"`)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultSSEReplaySize 재연결한 클라이언트에게 다시 보낼 수 있도록 보관하는 기본 이벤트 수
+const defaultSSEReplaySize = 1024
+
+// sseHistoryEntry 재전송용으로 보관한 SSE 이벤트
+type sseHistoryEntry struct {
+	seq       uint64
+	eventType string
+	key       string
+	// event prev를 제외한 이벤트 데이터
+	event map[string]interface{}
+}
+
+// sseHistory 최근 SSE 이벤트 링 버퍼 (sseClientsMu로 보호)
+type sseHistory struct {
+	entries []sseHistoryEntry
+	next    int
+}
+
+// newSSEHistory 새 SSE 이벤트 기록 생성 (size가 0 이하이면 nil)
+func newSSEHistory(size int) *sseHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &sseHistory{entries: make([]sseHistoryEntry, 0, size)}
+}
+
+// add 이벤트 추가 (가득 차면 가장 오래된 이벤트를 덮어씀)
+func (h *sseHistory) add(entry sseHistoryEntry) {
+	if len(h.entries) < cap(h.entries) {
+		h.entries = append(h.entries, entry)
+	} else {
+		h.entries[h.next] = entry
+	}
+	h.next = (h.next + 1) % cap(h.entries)
+}
+
+// since after 이후의 이벤트를 오래된 순으로 반환
+// 그 사이의 이벤트가 이미 버퍼에서 밀려났으면 false를 반환한다.
+func (h *sseHistory) since(after, current uint64) ([]sseHistoryEntry, bool) {
+	if after == current {
+		return nil, true
+	}
+	if h == nil || len(h.entries) == 0 {
+		return nil, false
+	}
+
+	oldest := 0
+	if len(h.entries) == cap(h.entries) {
+		oldest = h.next
+	}
+	if h.entries[oldest].seq > after+1 {
+		return nil, false
+	}
+
+	var entries []sseHistoryEntry
+	for i := 0; i < len(h.entries); i++ {
+		entry := h.entries[(oldest+i)%len(h.entries)]
+		if entry.seq > after {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, true
+}
+
+// newSSEEpoch 서버 시작 시간으로 재연결 커서의 epoch 생성
+func newSSEEpoch(startTime time.Time) string {
+	return strconv.FormatInt(startTime.UnixNano(), 36)
+}
+
+// sseCursor SSE 이벤트 id로 사용하는 재연결 커서
+// 서버가 재시작하면 시퀀스 번호가 다시 시작되므로 서버 실행마다 다른 epoch를 붙인다.
+func (s *Server) sseCursor(seq uint64) string {
+	return s.sseEpoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseSSECursor 클라이언트가 보낸 재연결 커서 파싱
+// 다른 서버 실행에서 받은 커서이거나 형식이 잘못되었으면 false를 반환한다.
+func (s *Server) parseSSECursor(cursor string) (uint64, bool) {
+	epoch, rawSeq, ok := strings.Cut(cursor, "-")
+	if !ok || epoch != s.sseEpoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
+
+// lastEventID 재연결 커서 조회
+// EventSource가 재연결할 때 보내는 Last-Event-ID 헤더를 사용하고, 헤더를 설정할 수 없는
+// 클라이언트를 위해 lastEventId 쿼리 파라미터도 허용한다.
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
+
+// registerSSEClient SSE 클라이언트 등록
+//
+// cursor가 있으면 그 이후에 놓친 이벤트를 함께 반환한다. 놓친 이벤트 조회와 등록을 함께
+// 잠가 재전송과 새 이벤트 사이에 빠지는 이벤트가 없게 한다. seq는 클라이언트가 이어서
+// 받을 시퀀스 번호이고, cursor를 되돌릴 수 없으면 reset이 true이다.
+func (s *Server) registerSSEClient(client *sseClient, cursor string) (id string, seq uint64, replay []sseMessage, resumed, reset bool) {
+	id = uuid.New().String()
+
+	s.sseClientsMu.Lock()
+	defer s.sseClientsMu.Unlock()
+
+	seq = s.sseSeq
+	if cursor != "" {
+		if after, ok := s.parseSSECursor(cursor); ok {
+			client.lastSeq = after
+			replay, resumed = s.replaySSEEvents(client, after)
+			if resumed {
+				seq = after
+			}
+		}
+		if !resumed {
+			client.lastSeq = s.sseSeq
+			reset = true
+		}
+	} else {
+		client.lastSeq = seq
+	}
+	s.sseClients[id] = client
+	return id, seq, replay, resumed, reset
+}
+
+// unregisterSSEClient SSE 클라이언트 등록 해제 및 메시지 채널 닫기
+func (s *Server) unregisterSSEClient(id string) {
+	s.sseClientsMu.Lock()
+	defer s.sseClientsMu.Unlock()
+	if client, ok := s.sseClients[id]; ok {
+		delete(s.sseClients, id)
+		close(client.messages)
+	}
+}
+
+// replaySSEEvents 재연결한 클라이언트가 놓친 이벤트 조회 (sseClientsMu를 잡은 상태에서 호출)
+//
+// 클라이언트가 읽을 수 있고 필터에 일치하는 이벤트만 반환하며, client.lastSeq를 마지막으로
+// 반환한 이벤트로 갱신한다. 놓친 이벤트가 버퍼에 남아 있지 않으면 false를 반환한다.
+func (s *Server) replaySSEEvents(client *sseClient, after uint64) ([]sseMessage, bool) {
+	if after > s.sseSeq {
+		return nil, false
+	}
+	entries, ok := s.sseHistory.since(after, s.sseSeq)
+	if !ok {
+		return nil, false
+	}
+
+	var messages []sseMessage
+	for _, entry := range entries {
+		if !s.canReceiveSSEEvent(client, entry.eventType, entry.key) {
+			continue
+		}
+
+		event := make(map[string]interface{}, len(entry.event)+1)
+		for k, v := range entry.event {
+			event[k] = v
+		}
+		event["prev"] = client.lastSeq
+		client.lastSeq = entry.seq
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			logger.Errorf("Failed to marshal SSE event: %v", err)
+			continue
+		}
+		messages = append(messages, sseMessage{seq: entry.seq, data: data})
+	}
+	return messages, true
+}
+
+// writeSSEMessage SSE 메시지 전송
+func (s *Server) writeSSEMessage(w http.ResponseWriter, msg sseMessage) {
+	fmt.Fprintf(w, "id: %s\ndata: %s\n\n", s.sseCursor(msg.seq), msg.data)
+}
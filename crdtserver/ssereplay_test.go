@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireConnected 연결 이벤트의 이어서 받을 seq와 재개 여부 확인
+func requireConnected(t *testing.T, events <-chan sseEvent, seq float64, resumed bool) {
+	t.Helper()
+	connected := nextSSE(t, events)
+	require.Equal(t, "connected", connected.Data["event"], connected.Data)
+	assert.Equal(t, seq, connected.Data["seq"], connected.Data)
+	assert.Equal(t, resumed, connected.Data["resumed"], connected.Data)
+}
+
+// TestSSEReplay Last-Event-ID로 재연결하면 놓친 이벤트 중 필터에 일치하는 것만 다시 받는지 확인
+func TestSSEReplay(t *testing.T) {
+	s := newTestServer(t, Config{SSEReplaySize: 16})
+	server := httptest.NewServer(s.server.Handler)
+	t.Cleanup(server.Close)
+
+	events := openSSE(t, server.URL+"/events?prefix=boss", nil)
+	requireConnected(t, events, 0, false)
+	putData(t, s, "boss/hp", "100")
+	cursor := requireSSE(t, events, "put", "boss/hp", 1, 0).ID
+	assert.Equal(t, s.sseCursor(1), cursor)
+
+	// 연결이 끊긴 사이의 이벤트
+	putData(t, s, "player/alice", "1")
+	putData(t, s, "boss/mp", "50")
+	w := serveTest(s, http.MethodDelete, "/api/data/boss/hp", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	resumed := openSSE(t, server.URL+"/events?prefix=boss", http.Header{"Last-Event-ID": []string{cursor}})
+	requireConnected(t, resumed, 1, true)
+	event := requireSSE(t, resumed, "put", "boss/mp", 3, 1)
+	assert.Equal(t, s.sseCursor(3), event.ID)
+	assert.Equal(t, "50", event.Data["value"])
+	requireSSE(t, resumed, "delete", "boss/hp", 4, 3)
+
+	// 재전송 뒤의 새 이벤트는 재전송한 마지막 이벤트에 이어짐
+	putData(t, s, "boss/hp", "90")
+	requireSSE(t, resumed, "put", "boss/hp", 5, 4)
+
+	// 헤더를 설정할 수 없는 클라이언트는 쿼리 파라미터로 커서 전달
+	query := url.Values{"ops": {"delete"}, "lastEventId": {cursor}}
+	byQuery := openSSE(t, server.URL+"/events?"+query.Encode(), nil)
+	requireConnected(t, byQuery, 1, true)
+	requireSSE(t, byQuery, "delete", "boss/hp", 4, 1)
+
+	// 놓친 이벤트가 없으면 재전송 없이 이어서 받음
+	current := openSSE(t, server.URL+"/events", http.Header{"Last-Event-ID": []string{s.sseCursor(5)}})
+	requireConnected(t, current, 5, true)
+	putData(t, s, "raid/state", "started")
+	requireSSE(t, current, "put", "raid/state", 6, 5)
+}
+
+// TestSSEReplayReset 되돌릴 수 없는 커서로 재연결하면 reset을 받는지 확인
+func TestSSEReplayReset(t *testing.T) {
+	s := newTestServer(t, Config{SSEReplaySize: 2})
+	server := httptest.NewServer(s.server.Handler)
+	t.Cleanup(server.Close)
+
+	for _, value := range []string{"100", "90", "80"} {
+		putData(t, s, "boss/hp", value)
+	}
+
+	for _, cursor := range []string{s.sseCursor(0), s.sseCursor(100), "previous-run-2", "not-a-cursor"} {
+		s.sseClientsMu.Lock()
+		seq := float64(s.sseSeq)
+		s.sseClientsMu.Unlock()
+
+		events := openSSE(t, server.URL+"/events", http.Header{"Last-Event-ID": []string{cursor}})
+		requireConnected(t, events, seq, false)
+		reset := nextSSE(t, events)
+		assert.Equal(t, "reset", reset.Data["event"], cursor)
+		assert.Equal(t, seq, reset.Data["seq"], cursor)
+
+		// 이후 이벤트의 prev는 현재 seq에서 이어짐
+		putData(t, s, "raid/state", cursor)
+		requireSSE(t, events, "put", "raid/state", seq+1, seq)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracingServiceName 스팬에 기록되는 서비스 이름
+	tracingServiceName = "crdtserver"
+	// traceEnvelopeMagic 트레이스 컨텍스트를 담은 브로드캐스트 메시지의 첫 바이트
+	// go-ds-crdt 메시지(protobuf)와 이전 버전의 CID 메시지는 0으로 시작하지 않으므로 구분된다.
+	traceEnvelopeMagic = 0x00
+)
+
+// tracer crdtserver 스팬 생성기
+// 트레이싱을 설정하지 않으면 스팬을 기록하지 않는 전역 TracerProvider를 사용한다.
+var tracer = otel.Tracer("crdtserver")
+
+// tracePropagator HTTP 요청과 브로드캐스트 메시지로 트레이스 컨텍스트를 전달하는 형식
+var tracePropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// setupTracing OTLP gRPC로 스팬을 내보내는 TracerProvider 설정
+// 엔드포인트가 없으면 트레이싱을 켜지 않고 nil을 반환한다.
+func setupTracing(ctx context.Context, config Config, peerID string) (*sdktrace.TracerProvider, error) {
+	if config.TracingEndpoint == "" {
+		return nil, nil
+	}
+	if config.TracingSampleRatio < 0 || config.TracingSampleRatio > 1 {
+		return nil, fmt.Errorf("invalid trace sample ratio %g (must be between 0 and 1)", config.TracingSampleRatio)
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.TracingEndpoint)}
+	if config.TracingInsecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(tracingServiceName),
+		semconv.ServiceInstanceID(peerID),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(tracePropagator)
+	logger.Infof("Exporting traces to %s (sample ratio %g)", config.TracingEndpoint, config.TracingSampleRatio)
+	return provider, nil
+}
+
+// endSpan 에러를 스팬에 기록하고 스팬 종료
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// withRequestSpan 서버 컨텍스트에 요청의 스팬을 담은 컨텍스트
+// 요청이 끊겨도 쓰기가 취소되지 않도록 서버 컨텍스트를 유지한다.
+func (s *Server) withRequestSpan(r *http.Request) context.Context {
+	return trace.ContextWithSpan(s.ctx, trace.SpanFromContext(r.Context()))
+}
+
+// isTracedPath HTTP 요청 스팬을 만들 경로인지 확인
+// 오래 유지되는 SSE, WebSocket 연결과 상태 확인은 제외한다.
+func isTracedPath(path string) bool {
+	switch path {
+	case "/events", "/ws", "/health", "/livez", "/readyz":
+		return false
+	}
+	return true
+}
+
+// tracingMiddleware 요청마다 서버 스팬을 만드는 미들웨어
+// 요청 헤더의 traceparent를 이어받으며, 스팬 이름은 요청이 연결된 라우트 패턴을 사용한다.
+func (s *Server) tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isTracedPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := tracePropagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		_, route := s.mux.Handler(r)
+		if route == "" {
+			route = r.URL.Path
+		}
+		ctx, span := tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// statusRecorder 응답 상태 코드를 기록하는 ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader 상태 코드 기록
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap http.ResponseController가 원래 ResponseWriter를 사용하도록 반환
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// wrapTraceEnvelope 브로드캐스트 메시지 앞에 컨텍스트의 트레이스 컨텍스트를 붙임
+// 형식: 0x00, 트레이스 컨텍스트 JSON 길이(uvarint), 트레이스 컨텍스트 JSON, 원래 메시지
+func wrapTraceEnvelope(ctx context.Context, data []byte) []byte {
+	carrier := propagation.MapCarrier{}
+	tracePropagator.Inject(ctx, carrier)
+	header, err := json.Marshal(carrier)
+	if err != nil || len(carrier) == 0 {
+		return data
+	}
+
+	envelope := make([]byte, 0, 1+binary.MaxVarintLen64+len(header)+len(data))
+	envelope = append(envelope, traceEnvelopeMagic)
+	envelope = binary.AppendUvarint(envelope, uint64(len(header)))
+	envelope = append(envelope, header...)
+	return append(envelope, data...)
+}
+
+// unwrapTraceEnvelope 브로드캐스트 메시지에서 트레이스 컨텍스트와 원래 메시지를 분리
+// 트레이스 컨텍스트가 없는 메시지는 빈 SpanContext와 메시지를 그대로 반환한다.
+func unwrapTraceEnvelope(data []byte) (trace.SpanContext, []byte, error) {
+	if len(data) == 0 || data[0] != traceEnvelopeMagic {
+		return trace.SpanContext{}, data, nil
+	}
+	size, n := binary.Uvarint(data[1:])
+	if n <= 0 || uint64(len(data)-1-n) < size {
+		return trace.SpanContext{}, nil, errors.New("malformed trace envelope")
+	}
+	header := data[1+n : 1+n+int(size)]
+	carrier := propagation.MapCarrier{}
+	if err := json.Unmarshal(header, &carrier); err != nil {
+		return trace.SpanContext{}, nil, fmt.Errorf("malformed trace envelope: %w", err)
+	}
+	remote := trace.SpanContextFromContext(tracePropagator.Extract(context.Background(), carrier))
+	return remote, data[1+n+int(size):], nil
+}
+
+// headsAttribute 스팬에 기록할 heads 속성
+func headsAttribute(data []byte) attribute.KeyValue {
+	heads := decodeBroadcastHeads(data)
+	return attribute.StringSlice("crdt.heads", cidStrings(heads))
+}
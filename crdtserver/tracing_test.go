@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanRecorderOnce sync.Once
+	spanRecorder     *tracetest.SpanRecorder
+)
+
+// recordSpans 끝난 스팬을 메모리에 기록하는 TracerProvider 설정
+// 전역 tracer는 처음 설정한 TracerProvider에 연결되므로 테스트 전체에서 하나만 설정한다.
+func recordSpans() *tracetest.SpanRecorder {
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	})
+	return spanRecorder
+}
+
+// spansInTrace 트레이스에 속한 끝난 스팬 (이름 -> 스팬)
+func spansInTrace(recorder *tracetest.SpanRecorder, traceID trace.TraceID) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() == traceID {
+			spans[span.Name()] = span
+		}
+	}
+	return spans
+}
+
+// spanAttribute 스팬 속성 값
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// testSpanContext 원격에서 받은 것처럼 표시한 샘플링된 스팬 컨텍스트
+func testSpanContext(t *testing.T, traceHex, spanHex string) trace.SpanContext {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex(traceHex)
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex(spanHex)
+	require.NoError(t, err)
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled, Remote: true})
+}
+
+// TestTracingHTTPSpans traceparent 헤더를 이어받은 요청 스팬 아래에 쓰기와 브로드캐스트 스팬이 기록되는지 확인
+func TestTracingHTTPSpans(t *testing.T) {
+	recorder := recordSpans()
+	s := newTestReplica(t, Config{}, newTestBroadcaster(t), nil)
+	parent := testSpanContext(t, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+
+	r := httptest.NewRequest(http.MethodPut, "/api/data/boss/hp", strings.NewReader("100"))
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	spans := spansInTrace(recorder, parent.TraceID())
+	server := spans["PUT /api/data/"]
+	require.NotNil(t, server, "spans: %v", spans)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, parent.SpanID(), server.Parent().SpanID())
+	assert.Equal(t, int64(http.StatusOK), spanAttribute(server, "http.response.status_code").AsInt64())
+
+	put := spans["crdt.put"]
+	require.NotNil(t, put, "spans: %v", spans)
+	assert.Equal(t, server.SpanContext().SpanID(), put.Parent().SpanID())
+	assert.Equal(t, "/boss/hp", spanAttribute(put, "crdt.key").AsString())
+	for _, name := range []string{"dag.add", "pubsub.publish"} {
+		require.Contains(t, spans, name)
+		assert.Equal(t, put.SpanContext().SpanID(), spans[name].Parent().SpanID(), name)
+	}
+	head := cidStrings(s.crdt.InternalStats(context.Background()).Heads)
+	assert.Equal(t, head, spanAttribute(spans["pubsub.publish"], "crdt.heads").AsStringSlice())
+
+	// SSE, WebSocket, 상태 확인 경로는 스팬을 만들지 않음
+	before := len(recorder.Ended())
+	assert.Equal(t, http.StatusBadRequest, serveTest(s, http.MethodGet, "/events?ops=bogus", "").Code)
+	assert.Len(t, recorder.Ended(), before)
+}
+
+// TestTracingBroadcastPropagation 브로드캐스트 메시지로 받은 트레이스 컨텍스트를 병합 스팬이 이어가는지 확인
+func TestTracingBroadcastPropagation(t *testing.T) {
+	recorder := recordSpans()
+	a := newTestServer(t, Config{})
+	b := newTestReplica(t, Config{}, newTestBroadcaster(t), a.bstore)
+	putData(t, a, "boss/hp", "100")
+	heads := a.crdt.InternalStats(context.Background()).Heads
+
+	// 보낸 쪽의 스팬 컨텍스트를 브로드캐스트 메시지에 붙였다가 받은 쪽에서 꺼냄
+	parent := testSpanContext(t, "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331")
+	ctx := trace.ContextWithSpanContext(context.Background(), parent)
+	message := wrapTraceEnvelope(ctx, []byte("delta"))
+	remote, data, err := unwrapTraceEnvelope(message)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("delta"), data)
+	assert.Equal(t, parent.TraceID(), remote.TraceID())
+	assert.Equal(t, parent.SpanID(), remote.SpanID())
+	assert.True(t, remote.IsRemote())
+
+	// 받은 트레이스 컨텍스트로 heads를 전달하면 병합 스팬이 이어짐
+	injectCtx := trace.ContextWithRemoteSpanContext(context.Background(), remote)
+	done, err := b.broadcaster.Inject(injectCtx, "peer-a", heads)
+	require.NoError(t, err)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for injected heads")
+	}
+	require.Eventually(t, func() bool {
+		_, ok := spansInTrace(recorder, parent.TraceID())["crdt.apply"]
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	apply := spansInTrace(recorder, parent.TraceID())["crdt.apply"]
+	assert.Equal(t, trace.SpanKindConsumer, apply.SpanKind())
+	assert.Equal(t, parent.SpanID(), apply.Parent().SpanID())
+	assert.Equal(t, "peer-a", spanAttribute(apply, "libp2p.peer").AsString())
+}
+
+// TestTraceEnvelope 트레이스 컨텍스트가 없는 메시지와 잘못된 봉투 처리 확인
+func TestTraceEnvelope(t *testing.T) {
+	// 스팬이 없으면 원래 메시지 그대로
+	plain := []byte{0x0a, 0x01, 0x02}
+	assert.Equal(t, plain, wrapTraceEnvelope(context.Background(), plain))
+	remote, data, err := unwrapTraceEnvelope(plain)
+	require.NoError(t, err)
+	assert.False(t, remote.IsValid())
+	assert.Equal(t, plain, data)
+
+	for _, malformed := range [][]byte{
+		{traceEnvelopeMagic},
+		{traceEnvelopeMagic, 10, '{'},
+		append([]byte{traceEnvelopeMagic, 3}, "abc"...),
+	} {
+		_, _, err := unwrapTraceEnvelope(malformed)
+		assert.Error(t, err, malformed)
+	}
+
+	// 이전 버전의 CID 메시지는 봉투로 오인하지 않음
+	c, err := cid.Decode("bafybeien7lbrxqplyw2cfw5bgossyorr2lvqnt6m6wn2rriz4gtffp4zpy")
+	require.NoError(t, err)
+	_, data, err = unwrapTraceEnvelope(c.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, c.Bytes(), data)
+}
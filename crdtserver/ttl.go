@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	dsquery "github.com/ipfs/go-datastore/query"
+)
+
+// ttlPrefix 키의 만료 시간이 저장되는 예약된 키 접두사
+//
+// 만료 시간은 CRDT 데이터스토어에 /_ttl/<key> 키로 저장되어 데이터와 함께 모든 노드로
+// 복제되므로, 어느 노드의 정리 작업이든 만료된 키를 CRDT 삭제로 모든 노드에서 제거한다.
+const ttlPrefix = "/_ttl"
+
+// defaultTTLInterval 만료된 키를 정리하는 기본 주기
+const defaultTTLInterval = 10 * time.Second
+
+// ttlKey 키의 만료 시간이 저장되는 키
+func ttlKey(key ds.Key) ds.Key {
+	return ds.NewKey(ttlPrefix).Child(key)
+}
+
+// isReservedKey 데이터 API로 직접 쓸 수 없는 예약된 키인지 확인
+func isReservedKey(key ds.Key) bool {
+	return inNamespace(key.String(), ttlPrefix) || inNamespace(key.String(), fieldsPrefix)
+}
+
+// parseTTL ttl 쿼리 파라미터(초) 파싱 (없으면 0)
+func parseTTL(r *http.Request) (time.Duration, error) {
+	raw := r.URL.Query().Get("ttl")
+	if raw == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid ttl: %q (must be a positive number of seconds)", raw)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// stageTTL 키의 만료 시간을 배치에 추가
+// ttl이 0이면 기존 만료 시간을 제거하여 키가 만료되지 않게 한다.
+func (s *Server) stageTTL(ctx context.Context, d *dataStore, batch ds.Batch, key ds.Key, ttl time.Duration) error {
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl).UnixMilli()
+		return batch.Put(ctx, ttlKey(key), []byte(strconv.FormatInt(expiresAt, 10)))
+	}
+
+	exists, err := d.crdt.Has(ctx, ttlKey(key))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return batch.Delete(ctx, ttlKey(key))
+	}
+	return nil
+}
+
+// expiresAt 키의 만료 시간 조회 (만료 시간이 없으면 zero time)
+func (s *Server) expiresAt(ctx context.Context, d *dataStore, key ds.Key) (time.Time, error) {
+	value, err := d.crdt.Get(ctx, ttlKey(key))
+	if err == ds.ErrNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseExpiry(value)
+}
+
+// isExpired 키가 만료되었는지 확인
+// 정리 작업이 아직 삭제하지 않은 만료된 키를 조회 결과에서 숨기는 데 사용한다.
+func (s *Server) isExpired(ctx context.Context, d *dataStore, key ds.Key) bool {
+	expiresAt, err := s.expiresAt(ctx, d, key)
+	if err != nil {
+		logger.Warnf("Failed to get expiration of %s: %v", key, err)
+		return false
+	}
+	return !expiresAt.IsZero() && !time.Now().Before(expiresAt)
+}
+
+// parseExpiry 저장된 만료 시간(Unix 밀리초) 파싱
+func parseExpiry(value []byte) (time.Time, error) {
+	ms, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiration: %q", value)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// runTTLJanitor 주기적으로 기본 데이터스토어와 모든 네임스페이스에서 만료된 키를 삭제
+func (s *Server) runTTLJanitor(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultTTLInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, d := range append([]*dataStore{s.data}, s.namespaces.dataStores()...) {
+				if err := s.expireKeys(s.ctx, d); err != nil {
+					logger.Errorf("Failed to expire keys under %s: %v", d.scope, err)
+				}
+			}
+		}
+	}
+}
+
+// expireKeys 만료된 키와 만료 시간을 CRDT 삭제로 제거
+func (s *Server) expireKeys(ctx context.Context, d *dataStore) error {
+	results, err := d.crdt.Query(ctx, dsquery.Query{Prefix: ttlPrefix})
+	if err != nil {
+		return err
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		expiresAt, err := parseExpiry(entry.Value)
+		if err != nil {
+			logger.Warnf("Removing invalid expiration %s: %v", entry.Key, err)
+		} else if now.Before(expiresAt) {
+			continue
+		}
+
+		key := ds.NewKey(strings.TrimPrefix(entry.Key, ttlPrefix))
+
+		// 조회 이후 키가 새 만료 시간으로 다시 저장되었으면 건너뜀
+		d.batchMu.Lock()
+		current, err := d.crdt.Get(ctx, ds.NewKey(entry.Key))
+		if err != nil || string(current) != string(entry.Value) {
+			d.batchMu.Unlock()
+			continue
+		}
+
+		err = s.commitBatchLocked(ctx, d, func(batch ds.Batch) error {
+			if err := batch.Delete(ctx, key); err != nil {
+				return err
+			}
+			if _, err := s.stageFields(ctx, d, batch, key, nil); err != nil {
+				return err
+			}
+			return batch.Delete(ctx, ds.NewKey(entry.Key))
+		})
+		d.batchMu.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to expire %s: %w", key, err)
+		}
+
+		logger.Debugf("Expired key %s", d.scopedKey(key))
+		if !d.hookEvents {
+			s.broadcastSSEEvent("delete", key.String(), nil)
+		}
+	}
+	return nil
+}
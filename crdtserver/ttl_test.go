@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTTLJanitor 정리 작업이 기본 데이터스토어와 네임스페이스에서 만료된 키와 만료 시간을 삭제하는지 확인
+func TestTTLJanitor(t *testing.T) {
+	s := newTestServer(t, Config{})
+	config := s.namespaces.withDefaults(NamespaceConfig{Name: "raid"})
+	data, err := s.namespaces.openData(config, nopBroadcaster{})
+	require.NoError(t, err)
+	t.Cleanup(func() { data.crdt.Close() })
+	s.namespaces.namespaces[config.Name] = &Namespace{config: config, data: data}
+	ctx := context.Background()
+
+	for _, target := range []string{"/api/data/lobby/1?ttl=1", "/api/ns/raid/data/lobby/1?ttl=1", "/api/data/lobby/2?ttl=3600"} {
+		w := serveTest(s, http.MethodPut, target, "waiting")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	events := watchEvents(t, s)
+
+	go s.runTTLJanitor(20 * time.Millisecond)
+	key := ds.NewKey("/lobby/1")
+	for _, d := range []*dataStore{s.data, data} {
+		require.Eventually(t, func() bool {
+			exists, err := d.crdt.Has(ctx, key)
+			return err == nil && !exists
+		}, 5*time.Second, 20*time.Millisecond, "%s was not expired", d.scopedKey(key))
+		exists, err := d.crdt.Has(ctx, ttlKey(key))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	}
+
+	// 만료된 키마다 삭제 이벤트를 한 번씩 보냄
+	deleted := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		event, key, _ := nextEvent(t, events)
+		assert.Equal(t, "delete", event)
+		deleted[key] = true
+	}
+	assert.Equal(t, map[string]bool{"/lobby/1": true, "/ns/raid/lobby/1": true}, deleted)
+
+	// 만료되지 않은 키는 남음
+	value, err := s.getValue(ctx, s.data, ds.NewKey("/lobby/2"))
+	require.NoError(t, err)
+	assert.Equal(t, "waiting", string(value))
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdtpatch"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	ds "github.com/ipfs/go-datastore"
+)
+
+// WebSocket 동기화 프로토콜 메시지 유형
+//
+// 클라이언트 → 서버:
+//
+//	hello             연결 시작. 서버는 hello로 응답한다.
+//	subscribe         docId 문서 구독. 서버는 ack와 snapshot으로 응답한다.
+//	unsubscribe       docId 문서 구독 해제. 서버는 ack로 응답한다.
+//	patch             docId 문서에 patch 병합. 서버는 ack로 응답하고 다른 구독자에게 patch를 보낸다.
+//	snapshot-request  docId 문서의 현재 뷰 요청. 서버는 snapshot으로 응답한다.
+//
+// 서버 → 클라이언트:
+//
+//	hello     clientId와 서버 peerId 전달
+//	ack       ref 메시지 처리 완료. patch의 경우 patchId와, 의존 패치를 기다리는 경우 missing 포함
+//	patch     구독한 문서에 병합된 다른 클라이언트나 다른 노드의 패치
+//	snapshot  문서의 현재 뷰
+//	error     ref 메시지 처리 실패
+//
+// 클라이언트가 메시지를 받지 못해 전송 버퍼가 차면 서버는 1013(Try Again Later)으로
+// 연결을 끊는다. 클라이언트는 다시 연결해 구독하면 snapshot부터 다시 동기화한다.
+const (
+	SyncMessageHello           = "hello"
+	SyncMessageSubscribe       = "subscribe"
+	SyncMessageUnsubscribe     = "unsubscribe"
+	SyncMessagePatch           = "patch"
+	SyncMessageAck             = "ack"
+	SyncMessageSnapshotRequest = "snapshot-request"
+	SyncMessageSnapshot        = "snapshot"
+	SyncMessageError           = "error"
+)
+
+// syncProtocolVersion WebSocket 동기화 프로토콜 버전
+const syncProtocolVersion = 1
+
+const (
+	// wsWriteTimeout 메시지 전송 제한 시간
+	wsWriteTimeout = 10 * time.Second
+	// wsPongTimeout pong 응답 대기 시간
+	wsPongTimeout = 60 * time.Second
+	// wsPingInterval ping 전송 주기
+	wsPingInterval = wsPongTimeout * 9 / 10
+	// wsSendBuffer 클라이언트별 전송 버퍼 크기
+	wsSendBuffer = 64
+)
+
+// SyncMessage WebSocket 동기화 프로토콜 메시지
+type SyncMessage struct {
+	Type     string                    `json:"type"`
+	ID       string                    `json:"id,omitempty"`
+	Ref      string                    `json:"ref,omitempty"`
+	DocID    string                    `json:"docId,omitempty"`
+	ClientID string                    `json:"clientId,omitempty"`
+	PeerID   string                    `json:"peerId,omitempty"`
+	Version  int                       `json:"version,omitempty"`
+	Patch    json.RawMessage           `json:"patch,omitempty"`
+	PatchID  *common.LogicalTimestamp  `json:"patchId,omitempty"`
+	Missing  []common.LogicalTimestamp `json:"missing,omitempty"`
+	View     interface{}               `json:"view,omitempty"`
+	Error    string                    `json:"error,omitempty"`
+}
+
+// SyncHub WebSocket 클라이언트와 문서 구독 관리
+type SyncHub struct {
+	ctx      context.Context
+	docs     *DocumentStore
+	peerID   string
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[string]*syncClient
+	// origins 클라이언트가 보낸 패치 키와 클라이언트 ID (자신에게 되돌려 보내지 않기 위함)
+	origins map[string]string
+}
+
+// syncClient WebSocket 클라이언트
+type syncClient struct {
+	id   string
+	conn *websocket.Conn
+	send chan SyncMessage
+	// subs 구독한 문서 ID (SyncHub.mu로 보호)
+	subs map[string]bool
+	// principal 인증된 주체 (인증이 비활성화된 경우 nil)
+	principal *Principal
+}
+
+// NewSyncHub 새 동기화 허브 생성
+// 문서 저장소, 피어 ID와 Origin 확인은 서버 생성 후 설정된다. 설정하기 전에는
+// 같은 Origin의 연결만 허용한다.
+func NewSyncHub(ctx context.Context) *SyncHub {
+	return &SyncHub{
+		ctx: ctx,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+		},
+		clients: make(map[string]*syncClient),
+		origins: make(map[string]string),
+	}
+}
+
+// checkWebSocketOrigin WebSocket 연결을 허용할 Origin인지 확인
+//
+// Origin 헤더가 없는 요청(브라우저가 아닌 클라이언트)은 허용한다. CORSOrigins가 있으면
+// 그 Origin만 허용하고, 없으면 인증이 켜진 경우 모든 Origin을 허용한다 (연결 요청이
+// 자격 증명을 직접 전달하므로). 인증도 허용 목록도 없으면 같은 Origin만 허용한다.
+func (s *Server) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	switch {
+	case origin == "":
+		return true
+	case len(s.config.CORSOrigins) > 0:
+		return s.corsOrigin(r) != ""
+	case s.auth != nil:
+		return true
+	default:
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// handleWebSocket WebSocket 동기화 엔드포인트 핸들러
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.syncHub.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Errorf("Failed to upgrade WebSocket connection: %v", err)
+		return
+	}
+
+	client := &syncClient{
+		id:        uuid.New().String(),
+		conn:      conn,
+		send:      make(chan SyncMessage, wsSendBuffer),
+		subs:      make(map[string]bool),
+		principal: principalFromRequest(r),
+	}
+
+	s.syncHub.mu.Lock()
+	s.syncHub.clients[client.id] = client
+	s.syncHub.mu.Unlock()
+
+	go s.syncHub.writeLoop(client)
+	s.syncHub.readLoop(client)
+}
+
+// readLoop 클라이언트 메시지 수신 및 처리
+func (h *SyncHub) readLoop(client *syncClient) {
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, client.id)
+		close(client.send)
+		h.mu.Unlock()
+		client.conn.Close()
+	}()
+
+	client.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	client.conn.SetPongHandler(func(string) error {
+		client.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		return nil
+	})
+
+	for {
+		var msg SyncMessage
+		if err := client.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warnf("WebSocket client %s error: %v", client.id, err)
+			}
+			return
+		}
+		h.handleMessage(client, msg)
+	}
+}
+
+// writeLoop 클라이언트로 메시지 전송 및 주기적인 ping
+func (h *SyncHub) writeLoop(client *syncClient) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-client.send:
+			client.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if !ok {
+				client.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := client.conn.WriteJSON(msg); err != nil {
+				logger.Warnf("Failed to write to WebSocket client %s: %v", client.id, err)
+				client.conn.Close()
+				return
+			}
+		case <-ticker.C:
+			client.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				client.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// reply 클라이언트에게 메시지 전송
+func (h *SyncHub) reply(client *syncClient, msg SyncMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[client.id]; !ok {
+		return
+	}
+	h.enqueue(client, msg)
+}
+
+// enqueue 클라이언트 전송 버퍼에 메시지 추가 (h.mu를 잠근 상태에서 호출)
+//
+// 버퍼가 차면 메시지를 버리지 않고 연결을 끊는다. 메시지를 버리면 클라이언트가 모르게
+// 문서가 어긋나지만, 다시 연결해 구독한 클라이언트는 스냅샷을 받아 다시 동기화된다.
+func (h *SyncHub) enqueue(client *syncClient, msg SyncMessage) {
+	select {
+	case client.send <- msg:
+	default:
+		logger.Warnf("WebSocket client %s send buffer full, closing connection", client.id)
+		delete(h.clients, client.id)
+		go client.closeOverflowed()
+	}
+}
+
+// closeOverflowed 전송 버퍼가 찬 클라이언트에게 다시 연결하라는 종료 메시지를 보내고 연결 종료
+// 읽기 루프가 종료되면서 전송 채널을 닫는다.
+func (c *syncClient) closeOverflowed() {
+	message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "send buffer full, reconnect to resync")
+	c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteTimeout))
+	c.conn.Close()
+}
+
+// replyError 에러 메시지 전송
+func (h *SyncHub) replyError(client *syncClient, ref, docID, message string) {
+	h.reply(client, SyncMessage{Type: SyncMessageError, Ref: ref, DocID: docID, Error: message})
+}
+
+// handleMessage 클라이언트 메시지 처리
+func (h *SyncHub) handleMessage(client *syncClient, msg SyncMessage) {
+	switch msg.Type {
+	case SyncMessageHello:
+		h.reply(client, SyncMessage{
+			Type:     SyncMessageHello,
+			Ref:      msg.ID,
+			ClientID: client.id,
+			PeerID:   h.peerID,
+			Version:  syncProtocolVersion,
+		})
+
+	case SyncMessageSubscribe:
+		if !validDocumentID(msg.DocID) {
+			h.replyError(client, msg.ID, msg.DocID, "invalid document id")
+			return
+		}
+		if !client.can(msg.DocID, PermissionRead) {
+			h.replyError(client, msg.ID, msg.DocID, "read permission required")
+			return
+		}
+		h.mu.Lock()
+		client.subs[msg.DocID] = true
+		h.mu.Unlock()
+		h.reply(client, SyncMessage{Type: SyncMessageAck, Ref: msg.ID, DocID: msg.DocID})
+		h.sendSnapshot(client, msg.ID, msg.DocID)
+
+	case SyncMessageUnsubscribe:
+		h.mu.Lock()
+		delete(client.subs, msg.DocID)
+		h.mu.Unlock()
+		h.reply(client, SyncMessage{Type: SyncMessageAck, Ref: msg.ID, DocID: msg.DocID})
+
+	case SyncMessagePatch:
+		h.handlePatch(client, msg)
+
+	case SyncMessageSnapshotRequest:
+		if !client.can(msg.DocID, PermissionRead) {
+			h.replyError(client, msg.ID, msg.DocID, "read permission required")
+			return
+		}
+		h.sendSnapshot(client, msg.ID, msg.DocID)
+
+	default:
+		h.replyError(client, msg.ID, msg.DocID, "unknown message type: "+msg.Type)
+	}
+}
+
+// handlePatch 클라이언트 패치 병합
+func (h *SyncHub) handlePatch(client *syncClient, msg SyncMessage) {
+	if !validDocumentID(msg.DocID) {
+		h.replyError(client, msg.ID, msg.DocID, "invalid document id")
+		return
+	}
+	if !client.can(msg.DocID, PermissionWrite) {
+		h.replyError(client, msg.ID, msg.DocID, "write permission required")
+		return
+	}
+
+	// 자신의 패치가 되돌아오지 않도록 패치 키 기록
+	patch := &crdtpatch.Patch{}
+	if err := json.Unmarshal(msg.Patch, patch); err != nil {
+		h.replyError(client, msg.ID, msg.DocID, "invalid patch: "+err.Error())
+		return
+	}
+	key := patchKey(msg.DocID, patch.ID()).String()
+	h.mu.Lock()
+	h.origins[key] = client.id
+	h.mu.Unlock()
+
+	applied, missing, err := h.docs.ApplyPatch(h.ctx, msg.DocID, msg.Patch)
+	h.mu.Lock()
+	delete(h.origins, key)
+	h.mu.Unlock()
+	if err != nil {
+		h.replyError(client, msg.ID, msg.DocID, err.Error())
+		return
+	}
+
+	patchID := applied.ID()
+	h.reply(client, SyncMessage{Type: SyncMessageAck, Ref: msg.ID, DocID: msg.DocID, PatchID: &patchID, Missing: missing})
+}
+
+// sendSnapshot 문서의 현재 뷰 전송
+func (h *SyncHub) sendSnapshot(client *syncClient, ref, docID string) {
+	view, err := h.docs.View(h.ctx, docID)
+	if err != nil && err != ErrDocumentNotFound {
+		h.replyError(client, ref, docID, err.Error())
+		return
+	}
+	h.reply(client, SyncMessage{Type: SyncMessageSnapshot, Ref: ref, DocID: docID, View: view})
+}
+
+// can 클라이언트가 문서에 대한 권한을 가졌는지 확인
+func (c *syncClient) can(docID string, perm Permission) bool {
+	return c.principal == nil || c.principal.Can(docsPrefix+"/"+docID, perm)
+}
+
+// handlePut CRDT 데이터스토어에 저장된 키 처리
+// 로컬과 다른 노드에서 복제된 문서 패치를 구독한 클라이언트에게 전달한다.
+func (h *SyncHub) handlePut(k ds.Key, v []byte) {
+	namespaces := k.Namespaces()
+	if len(namespaces) != 5 || "/"+namespaces[0] != docsPrefix || namespaces[2] != "patches" {
+		return
+	}
+	docID := namespaces[1]
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	origin := h.origins[k.String()]
+	for id, client := range h.clients {
+		if id == origin || !client.subs[docID] {
+			continue
+		}
+		h.enqueue(client, SyncMessage{Type: SyncMessagePatch, DocID: docID, Patch: json.RawMessage(v)})
+	}
+}
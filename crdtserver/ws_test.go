@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tictactoe/luvjson/common"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialSync 테스트 서버의 WebSocket 동기화 엔드포인트에 연결
+func dialSync(t *testing.T, url string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/ws", header)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// readSync 다음 동기화 메시지 수신
+func readSync(t *testing.T, conn *websocket.Conn) SyncMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg SyncMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+// TestSyncProtocol 구독, 패치, ack와 다른 구독자로의 패치 전달 확인
+func TestSyncProtocol(t *testing.T) {
+	s := newTestServer(t, Config{})
+	server := httptest.NewServer(s.server.Handler)
+	defer server.Close()
+
+	alice, _, err := dialSync(t, server.URL, nil)
+	require.NoError(t, err)
+	bob, _, err := dialSync(t, server.URL, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, alice.WriteJSON(SyncMessage{Type: SyncMessageHello, ID: "1"}))
+	hello := readSync(t, alice)
+	assert.Equal(t, SyncMessageHello, hello.Type)
+	assert.Equal(t, "1", hello.Ref)
+	assert.NotEmpty(t, hello.ClientID)
+	assert.Equal(t, syncProtocolVersion, hello.Version)
+
+	// 구독하면 ack와 현재 스냅샷을 받음
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		require.NoError(t, conn.WriteJSON(SyncMessage{Type: SyncMessageSubscribe, ID: "2", DocID: "boss"}))
+		ack := readSync(t, conn)
+		assert.Equal(t, SyncMessageAck, ack.Type)
+		assert.Equal(t, "2", ack.Ref)
+		snapshot := readSync(t, conn)
+		assert.Equal(t, SyncMessageSnapshot, snapshot.Type)
+		assert.Nil(t, snapshot.View)
+	}
+
+	// 패치를 보내면 ack를 받고 다른 구독자에게만 패치가 전달됨
+	patch, _ := newBossPatch(common.NewSessionID(), 100)
+	require.NoError(t, alice.WriteJSON(SyncMessage{Type: SyncMessagePatch, ID: "3", DocID: "boss", Patch: json.RawMessage(encodePatch(t, patch))}))
+	ack := readSync(t, alice)
+	assert.Equal(t, SyncMessageAck, ack.Type)
+	assert.Equal(t, "3", ack.Ref)
+	require.NotNil(t, ack.PatchID)
+	assert.Equal(t, patch.ID(), *ack.PatchID)
+	assert.Empty(t, ack.Missing)
+
+	received := readSync(t, bob)
+	assert.Equal(t, SyncMessagePatch, received.Type)
+	assert.Equal(t, "boss", received.DocID)
+	assert.JSONEq(t, encodePatch(t, patch), string(received.Patch))
+
+	require.NoError(t, alice.WriteJSON(SyncMessage{Type: SyncMessageSnapshotRequest, ID: "4", DocID: "boss"}))
+	snapshot := readSync(t, alice)
+	assert.Equal(t, SyncMessageSnapshot, snapshot.Type)
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, snapshot.View)
+
+	// 적용할 수 없는 패치는 error로 응답
+	require.NoError(t, alice.WriteJSON(SyncMessage{Type: SyncMessagePatch, ID: "5", DocID: "boss", Patch: json.RawMessage(`{}`)}))
+	failed := readSync(t, alice)
+	assert.Equal(t, SyncMessageError, failed.Type)
+	assert.Equal(t, "5", failed.Ref)
+}
+
+// TestSyncSendBufferOverflow 전송 버퍼가 찬 클라이언트의 연결을 메시지를 버리지 않고 끊는지 확인
+func TestSyncSendBufferOverflow(t *testing.T) {
+	s := newTestServer(t, Config{})
+	hub := s.syncHub
+	registered := make(chan *syncClient, 1)
+
+	// 전송 루프 없이 클라이언트를 등록해 버퍼가 비워지지 않게 함
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := hub.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := &syncClient{
+			id:   "slow",
+			conn: conn,
+			send: make(chan SyncMessage, wsSendBuffer),
+			subs: map[string]bool{"boss": true},
+		}
+		hub.mu.Lock()
+		hub.clients[client.id] = client
+		hub.mu.Unlock()
+		registered <- client
+		hub.readLoop(client)
+	}))
+	defer server.Close()
+
+	conn, _, err := dialSync(t, server.URL, nil)
+	require.NoError(t, err)
+	client := <-registered
+
+	key := patchKey("boss", common.LogicalTimestamp{SID: common.NewSessionID(), Counter: 1})
+	for i := 0; i <= wsSendBuffer; i++ {
+		hub.handlePut(key, []byte(`{}`))
+	}
+	hub.mu.Lock()
+	_, ok := hub.clients[client.id]
+	hub.mu.Unlock()
+	assert.False(t, ok)
+
+	// 클라이언트는 다시 연결하라는 종료 메시지를 받음
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	require.Error(t, err)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err.Error())
+}
+
+// TestSyncCheckOrigin 허용된 Origin과 인증 설정에 따라 WebSocket 연결을 허용하는지 확인
+func TestSyncCheckOrigin(t *testing.T) {
+	origin := func(value string) http.Header {
+		return http.Header{"Origin": []string{value}}
+	}
+
+	// 허용 목록이 있으면 그 Origin만 허용
+	s := newTestServer(t, Config{CORSOrigins: []string{"https://game.example"}})
+	server := httptest.NewServer(s.server.Handler)
+	defer server.Close()
+
+	_, _, err := dialSync(t, server.URL, origin("https://game.example"))
+	assert.NoError(t, err)
+	_, resp, err := dialSync(t, server.URL, origin("https://evil.example"))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	_, _, err = dialSync(t, server.URL, nil)
+	assert.NoError(t, err)
+
+	// 인증도 허용 목록도 없으면 같은 Origin만 허용
+	s = newTestServer(t, Config{})
+	open := httptest.NewServer(s.server.Handler)
+	defer open.Close()
+
+	_, _, err = dialSync(t, open.URL, origin(open.URL))
+	assert.NoError(t, err)
+	_, resp, err = dialSync(t, open.URL, origin("https://evil.example"))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// 인증이 켜져 있으면 자격 증명을 가진 모든 Origin 허용
+	s.auth, err = NewAuthenticator(&AuthConfig{APIKeys: []APIKeyConfig{
+		{Key: "game-key", Name: "game", Permissions: map[string]string{"/": "write"}},
+	}})
+	require.NoError(t, err)
+	_, resp, err = dialSync(t, open.URL, origin("https://evil.example"))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	header := origin("https://game.example")
+	header.Set("X-API-Key", "game-key")
+	_, _, err = dialSync(t, open.URL, header)
+	assert.NoError(t, err)
+}
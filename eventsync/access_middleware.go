@@ -0,0 +1,211 @@
+package eventsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessTokenQueryParam은 헤더를 설정할 수 없는 클라이언트(브라우저 WebSocket, EventSource)가
+// 토큰을 전달할 때 사용하는 쿼리 파라미터 이름입니다.
+const AccessTokenQueryParam = "access_token"
+
+// DocumentIDQueryParam은 경로에 문서 ID가 없는 엔드포인트(WebSocket 핸드셰이크, SSE)에서
+// 문서 ID를 전달할 때 사용하는 쿼리 파라미터 이름입니다.
+const DocumentIDQueryParam = "documentId"
+
+// DefaultAccessRevalidateInterval은 연결이 유지되는 동안 토큰을 다시 검증하는 기본 주기입니다.
+const DefaultAccessRevalidateInterval = 30 * time.Second
+
+// DocumentAccessOption은 RequireDocumentAccess의 동작을 설정하는 옵션입니다.
+type DocumentAccessOption func(*documentAccessOptions)
+
+// documentAccessOptions는 RequireDocumentAccess의 설정입니다.
+type documentAccessOptions struct {
+	readPostPrefixes   []string
+	revalidateInterval time.Duration
+}
+
+// WithReadOnlyPost는 prefix로 시작하는 경로의 POST 요청을 읽기 요청으로 허용합니다.
+// 클라이언트 벡터 시계를 보내고 누락 이벤트를 받는 REST 동기화 엔드포인트처럼
+// 본문만 POST로 보내는 읽기 엔드포인트에만 사용합니다.
+func WithReadOnlyPost(prefix string) DocumentAccessOption {
+	return func(o *documentAccessOptions) {
+		o.readPostPrefixes = append(o.readPostPrefixes, prefix)
+	}
+}
+
+// WithRevalidateInterval은 WebSocket, SSE처럼 오래 유지되는 연결에서 토큰을 다시 검증하는 주기를 설정합니다.
+func WithRevalidateInterval(interval time.Duration) DocumentAccessOption {
+	return func(o *documentAccessOptions) {
+		o.revalidateInterval = interval
+	}
+}
+
+// accessTokenContextKey는 요청 컨텍스트에 검증된 토큰을 저장할 때 사용하는 키입니다.
+type accessTokenContextKey struct{}
+
+// DocumentIDExtractor는 요청에서 동기화 대상 문서 ID를 추출합니다.
+type DocumentIDExtractor func(r *http.Request) (primitive.ObjectID, error)
+
+// AccessTokenFromRequest는 요청에서 접근 토큰을 추출합니다.
+// Authorization: Bearer 헤더를 우선 사용하고, 없으면 access_token 쿼리 파라미터를 사용합니다.
+// WebSocket 업그레이드와 SSE 요청은 브라우저에서 헤더를 설정할 수 없으므로 쿼리 파라미터를 사용합니다.
+func AccessTokenFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	return r.URL.Query().Get(AccessTokenQueryParam)
+}
+
+// AccessTokenFromContext는 RequireDocumentAccess가 검증한 토큰을 컨텍스트에서 조회합니다.
+func AccessTokenFromContext(ctx context.Context) (*AccessToken, bool) {
+	token, ok := ctx.Value(accessTokenContextKey{}).(*AccessToken)
+	return token, ok
+}
+
+// RequireDocumentAccess는 읽기 전용 접근 토큰을 검증하는 HTTP 미들웨어를 반환합니다.
+// REST, SSE, WebSocket 동기화 엔드포인트 모두 일반 http.Handler이므로 같은 미들웨어로 보호할 수 있습니다.
+//
+// 토큰이 없거나 유효하지 않으면 401, 토큰의 범위에 문서가 없거나 읽기 외의 메서드로 요청하면 403을 반환합니다.
+// POST는 WithReadOnlyPost로 지정한 경로에서만 읽기 요청으로 취급합니다.
+//
+// 요청이 처리되는 동안 토큰이 만료되거나 폐기되면 요청 컨텍스트를 취소합니다.
+// context.Cause로 원인(ErrAccessTokenExpired, ErrAccessTokenRevoked 등)을 확인할 수 있으며,
+// WebSocket, SSE 핸들러는 요청 컨텍스트가 취소되면 연결을 닫아야 합니다.
+func RequireDocumentAccess(service *AccessTokenService, extractID DocumentIDExtractor, next http.Handler, opts ...DocumentAccessOption) http.Handler {
+	options := &documentAccessOptions{
+		revalidateInterval: DefaultAccessRevalidateInterval,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := AccessTokenFromRequest(r)
+		if token == "" {
+			http.Error(w, "Access token is required", http.StatusUnauthorized)
+			return
+		}
+
+		if !options.isReadRequest(r) {
+			http.Error(w, "Access token is read-only", http.StatusForbidden)
+			return
+		}
+
+		documentID, err := extractID(r)
+		if err != nil {
+			http.Error(w, "Invalid document ID", http.StatusBadRequest)
+			return
+		}
+
+		accessToken, err := service.AuthorizeDocument(r.Context(), token, documentID)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrDocumentAccessDenied):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			case errors.Is(err, ErrAccessTokenNotFound),
+				errors.Is(err, ErrAccessTokenExpired),
+				errors.Is(err, ErrAccessTokenRevoked):
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// 연결이 유지되는 동안 토큰을 다시 검증
+		ctx, cancel := context.WithCancelCause(r.Context())
+		defer cancel(nil)
+		go watchDocumentAccess(ctx, cancel, service, token, documentID, accessToken.ExpiresAt, options.revalidateInterval)
+
+		ctx = context.WithValue(ctx, accessTokenContextKey{}, accessToken)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// watchDocumentAccess는 토큰이 만료되거나 주기적인 재검증에 실패하면 요청 컨텍스트를 취소합니다.
+// 저장소 오류처럼 토큰과 무관한 실패는 다음 주기에 다시 검증합니다.
+func watchDocumentAccess(
+	ctx context.Context,
+	cancel context.CancelCauseFunc,
+	service *AccessTokenService,
+	token string,
+	documentID primitive.ObjectID,
+	expiresAt time.Time,
+	interval time.Duration,
+) {
+	expiry := time.NewTimer(time.Until(expiresAt))
+	defer expiry.Stop()
+
+	var revalidate <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		revalidate = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-expiry.C:
+			cancel(ErrAccessTokenExpired)
+			return
+		case <-revalidate:
+			_, err := service.AuthorizeDocument(ctx, token, documentID)
+			switch {
+			case errors.Is(err, ErrAccessTokenNotFound),
+				errors.Is(err, ErrAccessTokenExpired),
+				errors.Is(err, ErrAccessTokenRevoked),
+				errors.Is(err, ErrDocumentAccessDenied):
+				cancel(err)
+				return
+			}
+		}
+	}
+}
+
+// DocumentIDFromPathPrefix는 prefix 뒤에 오는 경로 세그먼트를 문서 ID로 해석하는 추출기를 반환합니다.
+// 예: DocumentIDFromPathPrefix("/api/sync/")는 "/api/sync/{id}"에서 {id}를 추출합니다.
+func DocumentIDFromPathPrefix(prefix string) DocumentIDExtractor {
+	return func(r *http.Request) (primitive.ObjectID, error) {
+		idStr := strings.TrimPrefix(r.URL.Path, prefix)
+		if i := strings.Index(idStr, "/"); i >= 0 {
+			idStr = idStr[:i]
+		}
+		return primitive.ObjectIDFromHex(idStr)
+	}
+}
+
+// DocumentIDFromQuery는 쿼리 파라미터 값을 문서 ID로 해석하는 추출기를 반환합니다.
+// 예: DocumentIDFromQuery(DocumentIDQueryParam)는 "/events?documentId={id}"에서 {id}를 추출합니다.
+func DocumentIDFromQuery(param string) DocumentIDExtractor {
+	return func(r *http.Request) (primitive.ObjectID, error) {
+		return primitive.ObjectIDFromHex(r.URL.Query().Get(param))
+	}
+}
+
+// isReadRequest는 읽기 전용 토큰으로 허용되는 요청인지 확인합니다.
+func (o *documentAccessOptions) isReadRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		for _, prefix := range o.readPostPrefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
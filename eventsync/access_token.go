@@ -0,0 +1,406 @@
+package eventsync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// 접근 토큰 관련 오류
+var (
+	// ErrAccessTokenNotFound는 토큰이 존재하지 않을 때 반환됩니다.
+	ErrAccessTokenNotFound = errors.New("access token not found")
+
+	// ErrAccessTokenExpired는 토큰이 만료되었을 때 반환됩니다.
+	ErrAccessTokenExpired = errors.New("access token expired")
+
+	// ErrAccessTokenRevoked는 토큰이 폐기되었을 때 반환됩니다.
+	ErrAccessTokenRevoked = errors.New("access token revoked")
+
+	// ErrDocumentAccessDenied는 토큰의 범위에 문서가 포함되지 않을 때 반환됩니다.
+	ErrDocumentAccessDenied = errors.New("document access denied")
+)
+
+// AccessScope는 토큰이 허용하는 작업 범위를 나타냅니다.
+type AccessScope string
+
+const (
+	// AccessScopeReadOnly는 동기화 이벤트 조회만 허용합니다.
+	AccessScopeReadOnly AccessScope = "read_only"
+)
+
+// AccessToken은 지정된 문서 집합에 대한 읽기 전용 동기화 접근을 허용하는 토큰입니다.
+// 공개 대시보드(예: 레이드 리더보드 페이지)처럼 인증되지 않은 클라이언트에게
+// 특정 문서의 동기화만 허용할 때 사용합니다.
+type AccessToken struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Token       string               `bson:"token" json:"token"`
+	Scope       AccessScope          `bson:"scope" json:"scope"`
+	DocumentIDs []primitive.ObjectID `bson:"document_ids" json:"documentIds"`
+	Description string               `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt   time.Time            `bson:"created_at" json:"createdAt"`
+	ExpiresAt   time.Time            `bson:"expires_at" json:"expiresAt"`
+	RevokedAt   *time.Time           `bson:"revoked_at,omitempty" json:"revokedAt,omitempty"`
+}
+
+// Copy는 토큰의 깊은 복사본을 생성합니다.
+func (t *AccessToken) Copy() *AccessToken {
+	if t == nil {
+		return nil
+	}
+
+	documentIDs := make([]primitive.ObjectID, len(t.DocumentIDs))
+	copy(documentIDs, t.DocumentIDs)
+
+	var revokedAt *time.Time
+	if t.RevokedAt != nil {
+		ra := *t.RevokedAt
+		revokedAt = &ra
+	}
+
+	return &AccessToken{
+		ID:          t.ID,
+		Token:       t.Token,
+		Scope:       t.Scope,
+		DocumentIDs: documentIDs,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt,
+		ExpiresAt:   t.ExpiresAt,
+		RevokedAt:   revokedAt,
+	}
+}
+
+// AllowsDocument는 토큰의 범위에 문서가 포함되는지 확인합니다.
+func (t *AccessToken) AllowsDocument(documentID primitive.ObjectID) bool {
+	for _, id := range t.DocumentIDs {
+		if id == documentID {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessTokenStore 인터페이스는 접근 토큰 저장소의 기능을 정의합니다.
+type AccessTokenStore interface {
+	// StoreToken은 토큰을 저장합니다.
+	StoreToken(ctx context.Context, token *AccessToken) error
+
+	// GetToken은 토큰 문자열로 토큰을 조회합니다.
+	GetToken(ctx context.Context, token string) (*AccessToken, error)
+
+	// RevokeToken은 토큰을 폐기합니다.
+	RevokeToken(ctx context.Context, token string, revokedAt time.Time) error
+
+	// DeleteExpiredTokens는 만료된 토큰을 삭제하고 삭제된 개수를 반환합니다.
+	DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error)
+
+	// Close는 저장소를 닫습니다.
+	Close() error
+}
+
+// MongoAccessTokenStore는 MongoDB 기반 접근 토큰 저장소 구현체입니다.
+type MongoAccessTokenStore struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewMongoAccessTokenStore는 새로운 MongoDB 접근 토큰 저장소를 생성합니다.
+func NewMongoAccessTokenStore(ctx context.Context, client *mongo.Client, database, collection string, logger *zap.Logger) (*MongoAccessTokenStore, error) {
+	// 컬렉션 가져오기
+	coll := client.Database(database).Collection(collection)
+
+	// 인덱스 생성
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "expires_at", Value: 1}},
+		},
+	}
+
+	_, err := coll.Indexes().CreateMany(ctx, indexModels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoAccessTokenStore{
+		collection: coll,
+		logger:     logger,
+	}, nil
+}
+
+// StoreToken은 토큰을 저장합니다.
+func (s *MongoAccessTokenStore) StoreToken(ctx context.Context, token *AccessToken) error {
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+
+	_, err := s.collection.InsertOne(ctx, token)
+	if err != nil {
+		return fmt.Errorf("failed to insert access token: %w", err)
+	}
+
+	return nil
+}
+
+// GetToken은 토큰 문자열로 토큰을 조회합니다.
+func (s *MongoAccessTokenStore) GetToken(ctx context.Context, token string) (*AccessToken, error) {
+	var accessToken AccessToken
+	err := s.collection.FindOne(ctx, bson.M{"token": token}).Decode(&accessToken)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrAccessTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to find access token: %w", err)
+	}
+
+	return &accessToken, nil
+}
+
+// RevokeToken은 토큰을 폐기합니다.
+func (s *MongoAccessTokenStore) RevokeToken(ctx context.Context, token string, revokedAt time.Time) error {
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"token": token},
+		bson.M{"$set": bson.M{"revoked_at": revokedAt}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredTokens는 만료된 토큰을 삭제하고 삭제된 개수를 반환합니다.
+func (s *MongoAccessTokenStore) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired access tokens: %w", err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Close는 저장소를 닫습니다.
+func (s *MongoAccessTokenStore) Close() error {
+	return nil
+}
+
+// MemoryAccessTokenStore는 메모리 기반 접근 토큰 저장소 구현체입니다.
+// 단일 서버 환경이나 테스트에서 사용합니다.
+type MemoryAccessTokenStore struct {
+	tokens map[string]*AccessToken
+	mutex  sync.RWMutex
+}
+
+// NewMemoryAccessTokenStore는 새로운 메모리 접근 토큰 저장소를 생성합니다.
+func NewMemoryAccessTokenStore() *MemoryAccessTokenStore {
+	return &MemoryAccessTokenStore{
+		tokens: make(map[string]*AccessToken),
+	}
+}
+
+// StoreToken은 토큰을 저장합니다.
+func (s *MemoryAccessTokenStore) StoreToken(ctx context.Context, token *AccessToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.tokens[token.Token]; exists {
+		return fmt.Errorf("access token already exists")
+	}
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+
+	s.tokens[token.Token] = token.Copy()
+	return nil
+}
+
+// GetToken은 토큰 문자열로 토큰을 조회합니다.
+func (s *MemoryAccessTokenStore) GetToken(ctx context.Context, token string) (*AccessToken, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	accessToken, exists := s.tokens[token]
+	if !exists {
+		return nil, ErrAccessTokenNotFound
+	}
+
+	return accessToken.Copy(), nil
+}
+
+// RevokeToken은 토큰을 폐기합니다.
+func (s *MemoryAccessTokenStore) RevokeToken(ctx context.Context, token string, revokedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	accessToken, exists := s.tokens[token]
+	if !exists {
+		return ErrAccessTokenNotFound
+	}
+
+	accessToken.RevokedAt = &revokedAt
+	return nil
+}
+
+// DeleteExpiredTokens는 만료된 토큰을 삭제하고 삭제된 개수를 반환합니다.
+func (s *MemoryAccessTokenStore) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var deleted int64
+	for key, accessToken := range s.tokens {
+		if accessToken.ExpiresAt.Before(before) {
+			delete(s.tokens, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Close는 저장소를 닫습니다.
+func (s *MemoryAccessTokenStore) Close() error {
+	return nil
+}
+
+// AccessTokenService는 읽기 전용 접근 토큰의 발급, 검증, 폐기를 관리합니다.
+type AccessTokenService struct {
+	store  AccessTokenStore
+	logger *zap.Logger
+}
+
+// NewAccessTokenService는 새로운 접근 토큰 서비스를 생성합니다.
+func NewAccessTokenService(store AccessTokenStore, logger *zap.Logger) *AccessTokenService {
+	return &AccessTokenService{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// IssueReadOnlyToken은 지정된 문서에 대한 읽기 전용 토큰을 발급합니다.
+func (s *AccessTokenService) IssueReadOnlyToken(ctx context.Context, documentIDs []primitive.ObjectID, ttl time.Duration, description string) (*AccessToken, error) {
+	if len(documentIDs) == 0 {
+		return nil, fmt.Errorf("at least one document ID is required")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("token TTL must be positive")
+	}
+
+	// 토큰 문자열 생성
+	value, err := generateTokenValue()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	now := time.Now()
+	token := &AccessToken{
+		ID:          primitive.NewObjectID(),
+		Token:       value,
+		Scope:       AccessScopeReadOnly,
+		DocumentIDs: documentIDs,
+		Description: description,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	if err := s.store.StoreToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Access token issued",
+		zap.String("token_id", token.ID.Hex()),
+		zap.Int("document_count", len(documentIDs)),
+		zap.Time("expires_at", token.ExpiresAt))
+
+	return token, nil
+}
+
+// ValidateToken은 토큰이 존재하고 만료되거나 폐기되지 않았는지 확인합니다.
+func (s *AccessTokenService) ValidateToken(ctx context.Context, token string) (*AccessToken, error) {
+	accessToken, err := s.store.GetToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if accessToken.RevokedAt != nil {
+		return nil, ErrAccessTokenRevoked
+	}
+
+	if !time.Now().Before(accessToken.ExpiresAt) {
+		return nil, ErrAccessTokenExpired
+	}
+
+	return accessToken, nil
+}
+
+// AuthorizeDocument는 토큰이 문서의 동기화 이벤트를 읽을 수 있는지 확인합니다.
+func (s *AccessTokenService) AuthorizeDocument(ctx context.Context, token string, documentID primitive.ObjectID) (*AccessToken, error) {
+	accessToken, err := s.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !accessToken.AllowsDocument(documentID) {
+		s.logger.Debug("Access token denied for document",
+			zap.String("token_id", accessToken.ID.Hex()),
+			zap.String("document_id", documentID.Hex()))
+		return nil, ErrDocumentAccessDenied
+	}
+
+	return accessToken, nil
+}
+
+// RevokeToken은 토큰을 폐기합니다. 폐기된 토큰은 즉시 모든 동기화 엔드포인트에서 거부됩니다.
+func (s *AccessTokenService) RevokeToken(ctx context.Context, token string) error {
+	if err := s.store.RevokeToken(ctx, token, time.Now()); err != nil {
+		return err
+	}
+
+	s.logger.Debug("Access token revoked")
+	return nil
+}
+
+// CleanupExpiredTokens는 만료된 토큰을 삭제합니다.
+func (s *AccessTokenService) CleanupExpiredTokens(ctx context.Context) (int64, error) {
+	deleted, err := s.store.DeleteExpiredTokens(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if deleted > 0 {
+		s.logger.Debug("Expired access tokens deleted", zap.Int64("count", deleted))
+	}
+
+	return deleted, nil
+}
+
+// Close는 접근 토큰 서비스를 닫습니다.
+func (s *AccessTokenService) Close() error {
+	return s.store.Close()
+}
+
+// generateTokenValue는 추측할 수 없는 토큰 문자열을 생성합니다.
+func generateTokenValue() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,226 @@
+package eventsync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// TestAccessTokenService는 읽기 전용 토큰의 발급, 검증, 폐기를 테스트합니다.
+func TestAccessTokenService(t *testing.T) {
+	ctx := context.Background()
+	service := NewAccessTokenService(NewMemoryAccessTokenStore(), zap.NewNop())
+
+	allowedDoc := primitive.NewObjectID()
+	otherDoc := primitive.NewObjectID()
+
+	token, err := service.IssueReadOnlyToken(ctx, []primitive.ObjectID{allowedDoc}, time.Hour, "raid leaderboard")
+	require.NoError(t, err)
+	assert.Equal(t, AccessScopeReadOnly, token.Scope)
+	assert.NotEmpty(t, token.Token)
+
+	// 범위 내 문서는 허용
+	_, err = service.AuthorizeDocument(ctx, token.Token, allowedDoc)
+	assert.NoError(t, err)
+
+	// 범위 밖 문서는 거부
+	_, err = service.AuthorizeDocument(ctx, token.Token, otherDoc)
+	assert.ErrorIs(t, err, ErrDocumentAccessDenied)
+
+	// 존재하지 않는 토큰
+	_, err = service.ValidateToken(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrAccessTokenNotFound)
+
+	// 폐기된 토큰
+	require.NoError(t, service.RevokeToken(ctx, token.Token))
+	_, err = service.AuthorizeDocument(ctx, token.Token, allowedDoc)
+	assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+	// 만료된 토큰
+	expired, err := service.IssueReadOnlyToken(ctx, []primitive.ObjectID{allowedDoc}, time.Nanosecond, "")
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = service.ValidateToken(ctx, expired.Token)
+	assert.ErrorIs(t, err, ErrAccessTokenExpired)
+
+	deleted, err := service.CleanupExpiredTokens(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+// TestRequireDocumentAccess는 동기화 엔드포인트 미들웨어의 토큰 검증을 테스트합니다.
+func TestRequireDocumentAccess(t *testing.T) {
+	ctx := context.Background()
+	service := NewAccessTokenService(NewMemoryAccessTokenStore(), zap.NewNop())
+
+	allowedDoc := primitive.NewObjectID()
+	token, err := service.IssueReadOnlyToken(ctx, []primitive.ObjectID{allowedDoc}, time.Hour, "")
+	require.NoError(t, err)
+
+	handler := RequireDocumentAccess(service, DocumentIDFromPathPrefix("/api/sync/"),
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accessToken, ok := AccessTokenFromContext(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, token.Token, accessToken.Token)
+			w.WriteHeader(http.StatusOK)
+		}), WithReadOnlyPost("/api/sync/"))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		status int
+	}{
+		{"헤더 토큰", http.MethodPost, "/api/sync/" + allowedDoc.Hex(), "Bearer " + token.Token, http.StatusOK},
+		{"쿼리 토큰 (SSE/WebSocket)", http.MethodGet, "/api/sync/" + allowedDoc.Hex() + "?access_token=" + token.Token, "", http.StatusOK},
+		{"토큰 없음", http.MethodGet, "/api/sync/" + allowedDoc.Hex(), "", http.StatusUnauthorized},
+		{"잘못된 토큰", http.MethodGet, "/api/sync/" + allowedDoc.Hex(), "Bearer invalid", http.StatusUnauthorized},
+		{"범위 밖 문서", http.MethodGet, "/api/sync/" + primitive.NewObjectID().Hex(), "Bearer " + token.Token, http.StatusForbidden},
+		{"쓰기 메서드", http.MethodPut, "/api/sync/" + allowedDoc.Hex(), "Bearer " + token.Token, http.StatusForbidden},
+		{"잘못된 문서 ID", http.MethodGet, "/api/sync/invalid", "Bearer " + token.Token, http.StatusBadRequest},
+		{"읽기로 지정되지 않은 경로의 POST", http.MethodPost, "/api/syncx/" + allowedDoc.Hex(), "Bearer " + token.Token, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+// TestRequireDocumentAccessPost는 POST가 지정된 읽기 경로에서만 허용되는지 테스트합니다.
+func TestRequireDocumentAccessPost(t *testing.T) {
+	ctx := context.Background()
+	service := NewAccessTokenService(NewMemoryAccessTokenStore(), zap.NewNop())
+
+	doc := primitive.NewObjectID()
+	token, err := service.IssueReadOnlyToken(ctx, []primitive.ObjectID{doc}, time.Hour, "")
+	require.NoError(t, err)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// 옵션 없이는 POST를 거부
+	games := RequireDocumentAccess(service, DocumentIDFromPathPrefix("/api/games/"), ok)
+	req := httptest.NewRequest(http.MethodPost, "/api/games/"+doc.Hex(), nil)
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	rec := httptest.NewRecorder()
+	games.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// 지정된 경로의 POST만 허용
+	sync := RequireDocumentAccess(service, DocumentIDFromPathPrefix("/api/sync/"), ok, WithReadOnlyPost("/api/sync/"))
+	req = httptest.NewRequest(http.MethodPost, "/api/sync/"+doc.Hex(), nil)
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	rec = httptest.NewRecorder()
+	sync.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		req = httptest.NewRequest(method, "/api/sync/"+doc.Hex(), nil)
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		rec = httptest.NewRecorder()
+		sync.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code, method)
+	}
+}
+
+// TestRequireDocumentAccessQuery는 경로에 문서 ID가 없는 WebSocket, SSE 엔드포인트를 테스트합니다.
+func TestRequireDocumentAccessQuery(t *testing.T) {
+	ctx := context.Background()
+	service := NewAccessTokenService(NewMemoryAccessTokenStore(), zap.NewNop())
+
+	doc := primitive.NewObjectID()
+	token, err := service.IssueReadOnlyToken(ctx, []primitive.ObjectID{doc}, time.Hour, "")
+	require.NoError(t, err)
+
+	handler := RequireDocumentAccess(service, DocumentIDFromQuery(DocumentIDQueryParam),
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"SSE", "/events?documentId=" + doc.Hex() + "&access_token=" + token.Token, http.StatusOK},
+		{"WebSocket 핸드셰이크", "/sync?access_token=" + token.Token + "&documentId=" + doc.Hex(), http.StatusOK},
+		{"문서 ID 없음", "/events?access_token=" + token.Token, http.StatusBadRequest},
+		{"범위 밖 문서", "/events?documentId=" + primitive.NewObjectID().Hex() + "&access_token=" + token.Token, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+// TestRequireDocumentAccessLongLived는 연결이 유지되는 동안 토큰이 만료되거나 폐기되면
+// 요청 컨텍스트가 취소되는지 테스트합니다.
+func TestRequireDocumentAccessLongLived(t *testing.T) {
+	ctx := context.Background()
+	service := NewAccessTokenService(NewMemoryAccessTokenStore(), zap.NewNop())
+	doc := primitive.NewObjectID()
+
+	// SSE 스트림처럼 요청 컨텍스트가 취소될 때까지 연결을 유지
+	serve := func(token string) <-chan error {
+		causes := make(chan error, 1)
+		handler := RequireDocumentAccess(service, DocumentIDFromQuery(DocumentIDQueryParam),
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+				causes <- context.Cause(r.Context())
+			}), WithRevalidateInterval(10*time.Millisecond))
+
+		req := httptest.NewRequest(http.MethodGet, "/events?documentId="+doc.Hex()+"&access_token="+token, nil)
+		go handler.ServeHTTP(httptest.NewRecorder(), req)
+		return causes
+	}
+
+	waitCause := func(causes <-chan error) error {
+		select {
+		case err := <-causes:
+			return err
+		case <-time.After(2 * time.Second):
+			t.Fatal("연결이 닫히지 않음")
+			return nil
+		}
+	}
+
+	// 폐기
+	revoked, err := service.IssueReadOnlyToken(ctx, []primitive.ObjectID{doc}, time.Hour, "")
+	require.NoError(t, err)
+	causes := serve(revoked.Token)
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case err := <-causes:
+		t.Fatalf("유효한 토큰의 연결이 닫힘: %v", err)
+	default:
+	}
+	require.NoError(t, service.RevokeToken(ctx, revoked.Token))
+	assert.ErrorIs(t, waitCause(causes), ErrAccessTokenRevoked)
+
+	// 만료
+	expiring, err := service.IssueReadOnlyToken(ctx, []primitive.ObjectID{doc}, 50*time.Millisecond, "")
+	require.NoError(t, err)
+	assert.ErrorIs(t, waitCause(serve(expiring.Token)), ErrAccessTokenExpired)
+}
@@ -110,6 +110,14 @@ func main() {
 	// 동기화 서비스 생성
 	syncService := eventsync.NewSyncService(eventStore, stateVectorManager, logger)
 
+	// 동기화 엔드포인트용 읽기 전용 접근 토큰 서비스 생성
+	accessTokenStore, err := eventsync.NewMongoAccessTokenStore(ctx, client, "eventsync_example", "access_tokens", logger)
+	if err != nil {
+		logger.Fatal("접근 토큰 저장소 생성 실패", zap.Error(err))
+	}
+	accessTokens := eventsync.NewAccessTokenService(accessTokenStore, logger)
+	defer accessTokens.Close()
+
 	// 스토리지 리스너 생성 및 시작
 	storageListener := eventsync.NewStorageListener[*GameState](storage, syncService, logger)
 	if err := storageListener.Start(); err != nil {
@@ -123,13 +131,15 @@ func main() {
 	// 정적 파일 제공
 	mux.Handle("/", http.FileServer(http.Dir("./client")))
 
-	// WebSocket 핸들러
+	// WebSocket 핸들러 (경로에 문서 ID가 없으므로 핸드셰이크 URL의 documentId 쿼리 파라미터 사용)
 	wsHandler := eventsync.NewWebSocketHandler(syncService, logger)
-	mux.Handle("/sync", wsHandler)
+	mux.Handle("/sync", eventsync.RequireDocumentAccess(accessTokens,
+		eventsync.DocumentIDFromQuery(eventsync.DocumentIDQueryParam), wsHandler))
 
 	// SSE 핸들러
 	sseHandler := eventsync.NewSSEHandler(syncService, logger)
-	mux.Handle("/events", sseHandler)
+	mux.Handle("/events", eventsync.RequireDocumentAccess(accessTokens,
+		eventsync.DocumentIDFromQuery(eventsync.DocumentIDQueryParam), sseHandler))
 
 	// API 핸들러
 	mux.HandleFunc("/api/games", func(w http.ResponseWriter, r *http.Request) {
@@ -249,8 +259,8 @@ func main() {
 		}
 	})
 
-	// 동기화 API
-	mux.HandleFunc("/api/sync/", func(w http.ResponseWriter, r *http.Request) {
+	// 동기화 API (벡터 시계를 POST로 보내는 읽기 요청)
+	syncAPI := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
@@ -288,6 +298,8 @@ func main() {
 
 		w.WriteHeader(http.StatusOK)
 	})
+	mux.Handle("/api/sync/", eventsync.RequireDocumentAccess(accessTokens,
+		eventsync.DocumentIDFromPathPrefix("/api/sync/"), syncAPI, eventsync.WithReadOnlyPost("/api/sync/")))
 
 	// HTTP 서버 시작
 	server := &http.Server{
@@ -3,6 +3,7 @@ package common
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -142,11 +143,11 @@ func TestSessionIDJSON(t *testing.T) {
 	data, err := json.Marshal(sid)
 	require.NoError(t, err)
 
-	// Verify the JSON structure (should be a byte array)
-	var byteArray []byte
-	err = json.Unmarshal(data, &byteArray)
+	// Verify the JSON structure (should be the canonical string)
+	var text string
+	err = json.Unmarshal(data, &text)
 	require.NoError(t, err)
-	require.Equal(t, 16, len(byteArray), "SessionID should be 16 bytes")
+	require.Equal(t, sid.String(), text)
 
 	// Unmarshal back to a SessionID
 	var sid2 SessionID
@@ -156,17 +157,50 @@ func TestSessionIDJSON(t *testing.T) {
 	// Verify the unmarshaled SessionID
 	assert.Equal(t, sid, sid2)
 
-	// Test unmarshaling with invalid JSON
+	// Test unmarshaling the byte array written by earlier versions
+	legacy, err := json.Marshal([16]byte(sid))
+	require.NoError(t, err)
 	var sid3 SessionID
-	err = json.Unmarshal([]byte(`"not-a-byte-array"`), &sid3)
+	err = json.Unmarshal(legacy, &sid3)
+	require.NoError(t, err)
+	assert.Equal(t, sid, sid3)
+
+	// Test unmarshaling with invalid JSON
+	var sid4 SessionID
+	err = json.Unmarshal([]byte(`"not-a-session-id"`), &sid4)
 	assert.Error(t, err, "should fail with invalid format")
 
 	// Test unmarshaling with invalid length
-	var sid4 SessionID
-	err = json.Unmarshal([]byte(`[1,2,3]`), &sid4)
+	var sid5 SessionID
+	err = json.Unmarshal([]byte(`[1,2,3]`), &sid5)
 	assert.Error(t, err, "should fail with invalid length")
 }
 
+func TestParseSessionID(t *testing.T) {
+	sid := NewSessionID()
+
+	// Parse the canonical string
+	parsed, err := ParseSessionID(sid.String())
+	require.NoError(t, err)
+	assert.Equal(t, sid, parsed)
+	assert.Len(t, sid.String(), 36)
+
+	// Session IDs in logical timestamps use the same format
+	data, err := json.Marshal(LogicalTimestamp{SID: sid, Counter: 1})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sid":"`+sid.String()+`","cnt":1}`, string(data))
+
+	var ts LogicalTimestamp
+	require.NoError(t, json.Unmarshal(data, &ts))
+	assert.Equal(t, sid, ts.SID)
+
+	// Invalid strings
+	_, err = ParseSessionID("")
+	assert.Error(t, err)
+	_, err = ParseSessionID("not-a-session-id")
+	assert.Error(t, err)
+}
+
 func TestErrors(t *testing.T) {
 	// Create UUID for testing
 	uuidVal, err := uuid.NewV7()
@@ -201,3 +235,45 @@ func TestErrors(t *testing.T) {
 	err6 := ErrInvalidNode{Message: "test error"}
 	assert.Equal(t, "invalid node: test error", err6.Error())
 }
+
+func TestHybridClock(t *testing.T) {
+	sid := NewSessionID()
+	clock := NewHybridClock(sid)
+	wall := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock.now = func() time.Time { return wall }
+
+	// Timestamps carry the wall-clock time
+	ts1 := clock.Now()
+	assert.Equal(t, sid, ts1.SID)
+	assert.True(t, ts1.IsHybrid())
+	assert.True(t, wall.Equal(ts1.WallTime()))
+	assert.Equal(t, uint16(0), ts1.Logical())
+
+	// Timestamps in the same millisecond increase the logical counter
+	ts2 := clock.Now()
+	assert.True(t, wall.Equal(ts2.WallTime()))
+	assert.Equal(t, uint16(1), ts2.Logical())
+	assert.Equal(t, -1, ts1.CompareTime(ts2))
+
+	// Timestamps keep increasing when the wall clock goes backwards
+	wall = wall.Add(-time.Second)
+	ts3 := clock.Now()
+	assert.Equal(t, -1, ts2.CompareTime(ts3))
+
+	// Timestamps are ordered after observed timestamps
+	remote := LogicalTimestamp{SID: NewSessionID(), Counter: HybridCounter(wall.Add(time.Hour), 7)}
+	clock.Observe(remote)
+	ts4 := clock.Now()
+	assert.Equal(t, -1, remote.CompareTime(ts4))
+	assert.Equal(t, uint16(8), ts4.Logical())
+
+	// A new clock after a restart continues after the last counter
+	restarted := NewHybridClock(sid)
+	restarted.now = func() time.Time { return wall }
+	assert.Greater(t, restarted.Next(ts4.Counter), ts4.Counter)
+
+	// Plain logical counters are not hybrid
+	plain := LogicalTimestamp{SID: sid, Counter: 42}
+	assert.False(t, plain.IsHybrid())
+	assert.True(t, plain.WallTime().IsZero())
+}
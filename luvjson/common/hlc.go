@@ -0,0 +1,136 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// HybridLogicalBits is the number of low bits of a hybrid counter that hold the logical
+// counter. The other bits hold the wall-clock time in milliseconds since the Unix epoch.
+const HybridLogicalBits = 16
+
+// hybridLogicalMask is the mask of the logical counter bits of a hybrid counter.
+const hybridLogicalMask = 1<<HybridLogicalBits - 1
+
+// hybridEpoch is the earliest wall-clock time of hybrid counters.
+// Counters below it are plain logical counters.
+var hybridEpoch = HybridCounter(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+
+// HybridCounter returns the hybrid counter of the given wall-clock time and logical counter.
+func HybridCounter(wall time.Time, logical uint16) uint64 {
+	return uint64(wall.UnixMilli())<<HybridLogicalBits | uint64(logical)
+}
+
+// IsHybrid reports whether the counter of the timestamp is a hybrid counter.
+// Plain logical counters start at 1 and stay far below the counters of hybrid clocks.
+func (t LogicalTimestamp) IsHybrid() bool {
+	return t.Counter >= hybridEpoch
+}
+
+// WallTime returns the wall-clock time of a hybrid timestamp, with millisecond precision.
+// It returns the zero time if the timestamp is not hybrid.
+func (t LogicalTimestamp) WallTime() time.Time {
+	if !t.IsHybrid() {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(t.Counter >> HybridLogicalBits))
+}
+
+// Logical returns the logical counter of a hybrid timestamp, which orders timestamps
+// with the same wall-clock time.
+func (t LogicalTimestamp) Logical() uint16 {
+	return uint16(t.Counter & hybridLogicalMask)
+}
+
+// CompareTime compares two timestamps by counter first and session second.
+// For hybrid timestamps this is the order of their wall-clock times, which also respects
+// causality between sessions whose clocks observe each other's timestamps.
+// Returns:
+//
+//	-1 if t < other
+//	 0 if t == other
+//	 1 if t > other
+func (t LogicalTimestamp) CompareTime(other LogicalTimestamp) int {
+	if t.Counter < other.Counter {
+		return -1
+	}
+	if t.Counter > other.Counter {
+		return 1
+	}
+	return t.SID.Compare(other.SID)
+}
+
+// HybridClock is a hybrid logical clock that generates the counters of a session's
+// timestamps from the wall-clock time.
+//
+// The counters it generates are LogicalTimestamp counters, so hybrid timestamps are
+// encoded, compared and stored like plain logical timestamps. It guarantees that:
+//
+//   - each counter is greater than the previous counter of the clock, even if the
+//     wall clock goes backwards;
+//   - each counter is greater than every counter passed to Observe, so a timestamp
+//     created after receiving a patch is ordered after the patch by CompareTime;
+//   - a clock started after a restart generates greater counters than before the
+//     restart, as long as the wall clock has advanced or the last counter is observed.
+//
+// It is safe for concurrent use.
+type HybridClock struct {
+	mu sync.Mutex
+
+	// sid is the session of the timestamps of the clock.
+	sid SessionID
+
+	// last is the last counter generated or observed by the clock.
+	last uint64
+
+	// now returns the wall-clock time.
+	now func() time.Time
+}
+
+// NewHybridClock creates a hybrid logical clock for the session.
+func NewHybridClock(sid SessionID) *HybridClock {
+	return &HybridClock{
+		sid: sid,
+		now: time.Now,
+	}
+}
+
+// Now returns the next timestamp of the clock.
+func (c *HybridClock) Now() LogicalTimestamp {
+	return LogicalTimestamp{SID: c.sid, Counter: c.Next(0)}
+}
+
+// Next returns the next counter of the clock, which is also greater than after.
+func (c *HybridClock) Next(after uint64) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if after > c.last {
+		c.last = after
+	}
+	if wall := HybridCounter(c.now(), 0); wall > c.last {
+		c.last = wall
+	} else {
+		c.last++
+	}
+	return c.last
+}
+
+// Observe records a timestamp received from another session, so that the next
+// timestamps of the clock are ordered after it.
+func (c *HybridClock) Observe(t LogicalTimestamp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t.Counter > c.last {
+		c.last = t.Counter
+	}
+}
+
+// Last returns the last counter generated or observed by the clock.
+func (c *HybridClock) Last() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.last
+}
@@ -65,11 +65,24 @@ func SessionIDFromUint64(id uint64) SessionID {
 	return SessionID(uuidVal)
 }
 
-// String returns the string representation of the SessionID.
+// String returns the canonical string representation of the SessionID,
+// the UUID text format such as "0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b".
+// It is the format used by the JSON and text encodings, and is safe to use in URLs and logs.
 func (s SessionID) String() string {
 	return uuid.UUID(s).String()
 }
 
+// ParseSessionID parses a SessionID from its string representation.
+// It accepts the canonical UUID text format returned by String, as well as the other
+// formats accepted by uuid.Parse.
+func ParseSessionID(s string) (SessionID, error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return NilSessionID, fmt.Errorf("invalid session ID %q: %w", s, err)
+	}
+	return SessionID(u), nil
+}
+
 // Compare compares two SessionIDs.
 // Returns:
 //
@@ -91,31 +104,48 @@ func (s SessionID) Compare(other SessionID) int {
 }
 
 // MarshalJSON implements the json.Marshaler interface.
+// The SessionID is encoded as a JSON string in the format returned by String.
 func (s SessionID) MarshalJSON() ([]byte, error) {
-	type alias SessionID
-	return json.Marshal(alias(s))
+	return json.Marshal(s.String())
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (s *SessionID) UnmarshalText(text []byte) error {
-	// Parse the UUID from string
-	u, err := uuid.Parse(string(text))
+	id, err := ParseSessionID(string(text))
 	if err != nil {
-		return fmt.Errorf("invalid UUID format: %w", err)
+		return err
 	}
-	*s = SessionID(u)
+	*s = id
 	return nil
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
 func (s SessionID) MarshalText() ([]byte, error) {
-	return []byte(uuid.UUID(s).String()), nil
+	return []byte(s.String()), nil
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts a JSON string, and the array of 16 bytes written by earlier versions.
 func (s *SessionID) UnmarshalJSON(data []byte) error {
-	type alias SessionID
-	return json.Unmarshal(data, (*alias)(s))
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return s.UnmarshalText([]byte(text))
+	}
+
+	// Decode the byte arrays written by earlier versions
+	var bytes []byte
+	if err := json.Unmarshal(data, &bytes); err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+	if len(bytes) != len(s) {
+		return fmt.Errorf("invalid session ID length: %d", len(bytes))
+	}
+	copy(s[:], bytes)
+	return nil
 }
 
 // LogicalTimestamp represents a globally unique identifier that can be partially ordered.
@@ -250,6 +280,14 @@ const (
 	NodeTypeBin NodeType = "bin"
 	// NodeTypeArr represents an RGA-Array.
 	NodeTypeArr NodeType = "arr"
+	// NodeTypeList represents a movable list.
+	NodeTypeList NodeType = "list"
+	// NodeTypeGCounter represents a grow-only counter.
+	NodeTypeGCounter NodeType = "gcnt"
+	// NodeTypePNCounter represents a counter that can be incremented and decremented.
+	NodeTypePNCounter NodeType = "pncnt"
+	// NodeTypeMVReg represents a multi-value register.
+	NodeTypeMVReg NodeType = "mvreg"
 	// NodeTypeRoot represents the root node of a document.
 	NodeTypeRoot NodeType = "root"
 )
@@ -0,0 +1,210 @@
+package crdt
+
+import (
+	"sort"
+	"strconv"
+
+	"tictactoe/luvjson/common"
+)
+
+// Annotation is metadata attached to a node, e.g. its author or a UI hint.
+// Annotations are not part of the document value.
+type Annotation struct {
+	// Value is the value of the annotation
+	Value interface{}
+	// Timestamp is the ID of the write of a replicated annotation
+	Timestamp common.LogicalTimestamp
+	// Replicated reports whether the annotation is shared with other replicas
+	Replicated bool
+}
+
+// SetAnnotation attaches a local annotation to the node nodeID. Local annotations
+// are kept by this replica only: they are not encoded and not merged. A nil value
+// removes the annotation.
+func (d *Document) SetAnnotation(nodeID common.LogicalTimestamp, key string, value interface{}) error {
+	if _, err := d.GetNode(nodeID); err != nil {
+		return err
+	}
+
+	if value == nil {
+		if ann, ok := d.annotations[nodeID][key]; ok && !ann.Replicated {
+			d.deleteAnnotation(nodeID, key)
+		}
+		return nil
+	}
+	d.putAnnotation(nodeID, key, &Annotation{Value: value})
+	return nil
+}
+
+// SetReplicatedAnnotation attaches an annotation to the node nodeID that is shared
+// with other replicas. It is encoded with the document and merged by MergeFrom;
+// concurrent writes of the same key are resolved by last-writer-wins. It returns
+// the ID of the write, which other replicas pass to ApplyAnnotation.
+func (d *Document) SetReplicatedAnnotation(nodeID common.LogicalTimestamp, key string, value interface{}) (common.LogicalTimestamp, error) {
+	if _, err := d.GetNode(nodeID); err != nil {
+		return common.LogicalTimestamp{}, err
+	}
+
+	ts := d.NextTimestamp()
+	d.ApplyAnnotation(nodeID, key, value, ts)
+	return ts, nil
+}
+
+// ApplyAnnotation applies a write of a replicated annotation received from
+// another replica. It reports whether the write was newer than the current one.
+// The annotation is kept even if the node is not known yet.
+func (d *Document) ApplyAnnotation(nodeID common.LogicalTimestamp, key string, value interface{}, ts common.LogicalTimestamp) bool {
+	d.UpdateClock(ts)
+	if ann, ok := d.annotations[nodeID][key]; ok && ann.Replicated && ts.Compare(ann.Timestamp) <= 0 {
+		return false
+	}
+	d.putAnnotation(nodeID, key, &Annotation{Value: value, Timestamp: ts, Replicated: true})
+	return true
+}
+
+// GetAnnotation returns the annotation key of the node nodeID.
+func (d *Document) GetAnnotation(nodeID common.LogicalTimestamp, key string) (*Annotation, bool) {
+	ann, ok := d.annotations[nodeID][key]
+	if !ok || ann.Value == nil {
+		return nil, false
+	}
+	return ann, true
+}
+
+// Annotations returns the values of the annotations of the node nodeID.
+func (d *Document) Annotations(nodeID common.LogicalTimestamp) map[string]interface{} {
+	result := make(map[string]interface{}, len(d.annotations[nodeID]))
+	for key, ann := range d.annotations[nodeID] {
+		if ann.Value != nil {
+			result[key] = ann.Value
+		}
+	}
+	return result
+}
+
+// ViewWithAnnotations returns the view of the document together with the
+// annotations of the nodes in it. Annotations are keyed by the dot-separated path
+// of the value they are attached to, in the format used by LoadSubtree; "" is the
+// whole document.
+func (d *Document) ViewWithAnnotations() (interface{}, map[string]map[string]interface{}, error) {
+	view, err := d.View()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotations := make(map[string]map[string]interface{})
+	if d.root != nil && len(d.annotations) > 0 {
+		d.annotatePaths(d.root, "", annotations, make(map[Node]bool))
+	}
+	return view, annotations, nil
+}
+
+// putAnnotation stores an annotation.
+func (d *Document) putAnnotation(nodeID common.LogicalTimestamp, key string, ann *Annotation) {
+	if d.annotations == nil {
+		d.annotations = make(map[common.LogicalTimestamp]map[string]*Annotation)
+	}
+	anns, ok := d.annotations[nodeID]
+	if !ok {
+		anns = make(map[string]*Annotation)
+		d.annotations[nodeID] = anns
+	}
+	anns[key] = ann
+}
+
+// deleteAnnotation removes an annotation.
+func (d *Document) deleteAnnotation(nodeID common.LogicalTimestamp, key string) {
+	delete(d.annotations[nodeID], key)
+	if len(d.annotations[nodeID]) == 0 {
+		delete(d.annotations, nodeID)
+	}
+}
+
+// replicatedAnnotation is a replicated annotation of a node, used for encoding.
+type replicatedAnnotation struct {
+	NodeID    common.LogicalTimestamp `json:"node"`
+	Key       string                  `json:"key"`
+	Value     interface{}             `json:"value"`
+	Timestamp common.LogicalTimestamp `json:"time"`
+}
+
+// replicatedAnnotations returns the replicated annotations ordered by node and key.
+func (d *Document) replicatedAnnotations() []replicatedAnnotation {
+	var result []replicatedAnnotation
+	for nodeID, anns := range d.annotations {
+		for key, ann := range anns {
+			if ann.Replicated {
+				result = append(result, replicatedAnnotation{NodeID: nodeID, Key: key, Value: ann.Value, Timestamp: ann.Timestamp})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if c := result[i].NodeID.Compare(result[j].NodeID); c != 0 {
+			return c < 0
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// annotatePaths collects the annotations of node and the nodes below it by path.
+func (d *Document) annotatePaths(node Node, path string, result map[string]map[string]interface{}, visiting map[Node]bool) {
+	if node == nil || visiting[node] {
+		return
+	}
+	visiting[node] = true
+	defer delete(visiting, node)
+
+	for key, value := range d.Annotations(node.ID()) {
+		if result[path] == nil {
+			result[path] = make(map[string]interface{})
+		}
+		result[path][key] = value
+	}
+
+	child := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	visitValue := func(value interface{}, path string) {
+		switch v := value.(type) {
+		case Node:
+			d.annotatePaths(v, path, result, visiting)
+		case common.LogicalTimestamp:
+			if node, ok := d.index[v]; ok {
+				d.annotatePaths(node, path, result, visiting)
+			}
+		}
+	}
+
+	switch n := node.(type) {
+	case *RootNode:
+		d.annotatePaths(n.NodeValue, path, result, visiting)
+	case *LWWValueNode:
+		d.annotatePaths(n.NodeValue, path, result, visiting)
+	case *ConstantNode:
+		visitValue(n.NodeValue, path)
+	case *LWWObjectNode:
+		for _, key := range n.Keys() {
+			d.annotatePaths(n.NodeFields[key].NodeValue, child(key), result, visiting)
+		}
+	case *LWWVectorNode:
+		for index, field := range n.NodeFields {
+			d.annotatePaths(field.NodeValue, child(strconv.Itoa(index)), result, visiting)
+		}
+	case *RGAArrayNode:
+		i := 0
+		for _, elem := range n.NodeElements {
+			if !elem.NodeDeleted {
+				visitValue(elem.NodeValue, child(strconv.Itoa(i)))
+				i++
+			}
+		}
+	case *MovableListNode:
+		for i, elem := range n.Elements() {
+			visitValue(elem.ElementValue, child(strconv.Itoa(i)))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package crdt
+
+import (
+	"sort"
+	"sync"
+
+	"tictactoe/luvjson/common"
+)
+
+// AppliedStore records the IDs of the operations applied to a document, so that
+// operations delivered more than once, e.g. by an at-least-once pubsub, are
+// applied only once. Remote patches are applied concurrently with local edits,
+// so implementations must be safe for concurrent use.
+type AppliedStore interface {
+	// HasApplied reports whether the operation with the specified ID was applied.
+	HasApplied(id common.LogicalTimestamp) bool
+	// MarkApplied records that the operations with the IDs from id to
+	// id.Increment(span-1) were applied.
+	MarkApplied(id common.LogicalTimestamp, span uint64)
+}
+
+// AppliedRanges is an in-memory AppliedStore that keeps the applied counters of
+// each session as ranges, so that a session applying its operations in order
+// takes a single range.
+type AppliedRanges struct {
+	mu     sync.RWMutex
+	ranges map[common.SessionID][]appliedRange
+}
+
+// appliedRange is a range of applied counters, both ends included.
+type appliedRange struct {
+	start, end uint64
+}
+
+// NewAppliedRanges creates an empty AppliedRanges.
+func NewAppliedRanges() *AppliedRanges {
+	return &AppliedRanges{ranges: make(map[common.SessionID][]appliedRange)}
+}
+
+// HasApplied reports whether the operation with the specified ID was applied.
+func (a *AppliedRanges) HasApplied(id common.LogicalTimestamp) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	ranges := a.ranges[id.SID]
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].end >= id.Counter })
+	return i < len(ranges) && ranges[i].start <= id.Counter
+}
+
+// MarkApplied records that the operations with the IDs from id to
+// id.Increment(span-1) were applied.
+func (a *AppliedRanges) MarkApplied(id common.LogicalTimestamp, span uint64) {
+	if span == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	added := appliedRange{start: id.Counter, end: id.Counter + span - 1}
+	ranges := a.ranges[id.SID]
+	// 새 범위와 겹치거나 맞닿은 범위를 하나로 합침
+	first := sort.Search(len(ranges), func(i int) bool { return ranges[i].end+1 >= added.start })
+	last := first
+	for last < len(ranges) && ranges[last].start <= added.end+1 {
+		if ranges[last].start < added.start {
+			added.start = ranges[last].start
+		}
+		if ranges[last].end > added.end {
+			added.end = ranges[last].end
+		}
+		last++
+	}
+
+	merged := make([]appliedRange, 0, len(ranges)-(last-first)+1)
+	merged = append(merged, ranges[:first]...)
+	merged = append(merged, added)
+	merged = append(merged, ranges[last:]...)
+	a.ranges[id.SID] = merged
+}
+
+// SetAppliedStore sets the store recording the operations applied to the
+// document. By default the document keeps them in memory with AppliedRanges; a
+// store backed by storage keeps them across restarts, which the default does not,
+// since they are not encoded with the document.
+func (d *Document) SetAppliedStore(store AppliedStore) {
+	d.applied = store
+}
+
+// HasApplied reports whether the operation with the specified ID was applied to
+// the document by a patch.
+func (d *Document) HasApplied(opID common.LogicalTimestamp) bool {
+	if d.applied == nil {
+		return false
+	}
+	return d.applied.HasApplied(opID)
+}
+
+// MarkApplied records that the operations with the IDs from opID to
+// opID.Increment(span-1) were applied to the document.
+func (d *Document) MarkApplied(opID common.LogicalTimestamp, span uint64) {
+	if d.applied == nil {
+		d.applied = NewAppliedRanges()
+	}
+	d.applied.MarkApplied(opID, span)
+}
@@ -233,6 +233,14 @@ func (n *RGAArrayNode) UnmarshalJSON(data []byte) error {
 				valueNode = &RGAStringNode{}
 			case common.NodeTypeArr:
 				valueNode = &RGAArrayNode{}
+			case common.NodeTypeList:
+				valueNode = &MovableListNode{}
+			case common.NodeTypeGCounter:
+				valueNode = &GCounterNode{}
+			case common.NodeTypePNCounter:
+				valueNode = &PNCounterNode{}
+			case common.NodeTypeMVReg:
+				valueNode = &MVRegisterNode{}
 			case common.NodeTypeVec:
 				valueNode = &LWWVectorNode{}
 			case common.NodeTypeBin:
@@ -0,0 +1,242 @@
+package crdt
+
+import (
+	"encoding/json"
+	"tictactoe/luvjson/common"
+)
+
+// CounterNode is implemented by the counter node types.
+//
+// Counters keep one total per session and only the session itself increases its
+// totals, so merging takes the larger total of each session and concurrent
+// increments from different sessions are summed instead of overwriting each other.
+type CounterNode interface {
+	Node
+
+	// Totals returns the increments and decrements recorded by a session.
+	Totals(sid common.SessionID) (increments, decrements uint64)
+
+	// Merge raises the totals of a session to the given values.
+	// Lower totals are ignored, so merging is idempotent and commutative.
+	Merge(sid common.SessionID, increments, decrements uint64)
+
+	// Count returns the value of the counter.
+	Count() int64
+}
+
+// GCounterNode represents a grow-only counter.
+type GCounterNode struct {
+	NodeId     common.LogicalTimestamp     `json:"id"`
+	NodeCounts map[common.SessionID]uint64 `json:"counts,omitempty"`
+}
+
+// NewGCounterNode creates a new grow-only counter node.
+func NewGCounterNode(id common.LogicalTimestamp) *GCounterNode {
+	return &GCounterNode{
+		NodeId:     id,
+		NodeCounts: make(map[common.SessionID]uint64),
+	}
+}
+
+// ID returns the unique identifier of the node.
+func (n *GCounterNode) ID() common.LogicalTimestamp {
+	return n.NodeId
+}
+
+// Type returns the type of the node.
+func (n *GCounterNode) Type() common.NodeType {
+	return common.NodeTypeGCounter
+}
+
+// Value returns the value of the counter as an int64.
+func (n *GCounterNode) Value() interface{} {
+	return n.Count()
+}
+
+// Count returns the sum of the increments of all sessions.
+func (n *GCounterNode) Count() int64 {
+	var sum uint64
+	for _, count := range n.NodeCounts {
+		sum += count
+	}
+	return int64(sum)
+}
+
+// Inc adds delta to the total of the session and returns the new total.
+func (n *GCounterNode) Inc(sid common.SessionID, delta uint64) uint64 {
+	n.NodeCounts[sid] += delta
+	return n.NodeCounts[sid]
+}
+
+// Totals returns the increments recorded by a session; decrements are always 0.
+func (n *GCounterNode) Totals(sid common.SessionID) (uint64, uint64) {
+	return n.NodeCounts[sid], 0
+}
+
+// Merge raises the total of a session to increments.
+// Decrements are ignored since a grow-only counter cannot decrease.
+func (n *GCounterNode) Merge(sid common.SessionID, increments, _ uint64) {
+	if increments > n.NodeCounts[sid] {
+		n.NodeCounts[sid] = increments
+	}
+}
+
+// MarshalJSON returns a JSON representation of the node.
+func (n *GCounterNode) MarshalJSON() ([]byte, error) {
+	type jsonNode struct {
+		Type   string                      `json:"type"`
+		ID     common.LogicalTimestamp     `json:"id"`
+		Counts map[common.SessionID]uint64 `json:"counts,omitempty"`
+	}
+
+	return json.Marshal(jsonNode{
+		Type:   string(n.Type()),
+		ID:     n.NodeId,
+		Counts: n.NodeCounts,
+	})
+}
+
+// UnmarshalJSON parses a JSON representation of the node.
+func (n *GCounterNode) UnmarshalJSON(data []byte) error {
+	type jsonNode struct {
+		Type   string                      `json:"type"`
+		ID     common.LogicalTimestamp     `json:"id"`
+		Counts map[common.SessionID]uint64 `json:"counts,omitempty"`
+	}
+
+	var node jsonNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	if node.Type != string(common.NodeTypeGCounter) {
+		return common.ErrInvalidNodeType{Type: node.Type}
+	}
+
+	n.NodeId = node.ID
+	n.NodeCounts = node.Counts
+	if n.NodeCounts == nil {
+		n.NodeCounts = make(map[common.SessionID]uint64)
+	}
+
+	return nil
+}
+
+// PNCounterNode represents a counter that can be incremented and decremented.
+// It keeps separate grow-only totals of the increments and decrements of each session.
+type PNCounterNode struct {
+	NodeId         common.LogicalTimestamp     `json:"id"`
+	NodeIncrements map[common.SessionID]uint64 `json:"increments,omitempty"`
+	NodeDecrements map[common.SessionID]uint64 `json:"decrements,omitempty"`
+}
+
+// NewPNCounterNode creates a new PN-counter node.
+func NewPNCounterNode(id common.LogicalTimestamp) *PNCounterNode {
+	return &PNCounterNode{
+		NodeId:         id,
+		NodeIncrements: make(map[common.SessionID]uint64),
+		NodeDecrements: make(map[common.SessionID]uint64),
+	}
+}
+
+// ID returns the unique identifier of the node.
+func (n *PNCounterNode) ID() common.LogicalTimestamp {
+	return n.NodeId
+}
+
+// Type returns the type of the node.
+func (n *PNCounterNode) Type() common.NodeType {
+	return common.NodeTypePNCounter
+}
+
+// Value returns the value of the counter as an int64.
+func (n *PNCounterNode) Value() interface{} {
+	return n.Count()
+}
+
+// Count returns the sum of the increments minus the sum of the decrements.
+func (n *PNCounterNode) Count() int64 {
+	var value int64
+	for _, count := range n.NodeIncrements {
+		value += int64(count)
+	}
+	for _, count := range n.NodeDecrements {
+		value -= int64(count)
+	}
+	return value
+}
+
+// Inc adds delta to the increments of the session and returns the new total.
+func (n *PNCounterNode) Inc(sid common.SessionID, delta uint64) uint64 {
+	n.NodeIncrements[sid] += delta
+	return n.NodeIncrements[sid]
+}
+
+// Dec adds delta to the decrements of the session and returns the new total.
+func (n *PNCounterNode) Dec(sid common.SessionID, delta uint64) uint64 {
+	n.NodeDecrements[sid] += delta
+	return n.NodeDecrements[sid]
+}
+
+// Totals returns the increments and decrements recorded by a session.
+func (n *PNCounterNode) Totals(sid common.SessionID) (uint64, uint64) {
+	return n.NodeIncrements[sid], n.NodeDecrements[sid]
+}
+
+// Merge raises the totals of a session to the given values.
+func (n *PNCounterNode) Merge(sid common.SessionID, increments, decrements uint64) {
+	if increments > n.NodeIncrements[sid] {
+		n.NodeIncrements[sid] = increments
+	}
+	if decrements > n.NodeDecrements[sid] {
+		n.NodeDecrements[sid] = decrements
+	}
+}
+
+// MarshalJSON returns a JSON representation of the node.
+func (n *PNCounterNode) MarshalJSON() ([]byte, error) {
+	type jsonNode struct {
+		Type       string                      `json:"type"`
+		ID         common.LogicalTimestamp     `json:"id"`
+		Increments map[common.SessionID]uint64 `json:"increments,omitempty"`
+		Decrements map[common.SessionID]uint64 `json:"decrements,omitempty"`
+	}
+
+	return json.Marshal(jsonNode{
+		Type:       string(n.Type()),
+		ID:         n.NodeId,
+		Increments: n.NodeIncrements,
+		Decrements: n.NodeDecrements,
+	})
+}
+
+// UnmarshalJSON parses a JSON representation of the node.
+func (n *PNCounterNode) UnmarshalJSON(data []byte) error {
+	type jsonNode struct {
+		Type       string                      `json:"type"`
+		ID         common.LogicalTimestamp     `json:"id"`
+		Increments map[common.SessionID]uint64 `json:"increments,omitempty"`
+		Decrements map[common.SessionID]uint64 `json:"decrements,omitempty"`
+	}
+
+	var node jsonNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	if node.Type != string(common.NodeTypePNCounter) {
+		return common.ErrInvalidNodeType{Type: node.Type}
+	}
+
+	n.NodeId = node.ID
+	n.NodeIncrements = node.Increments
+	if n.NodeIncrements == nil {
+		n.NodeIncrements = make(map[common.SessionID]uint64)
+	}
+	n.NodeDecrements = node.Decrements
+	if n.NodeDecrements == nil {
+		n.NodeDecrements = make(map[common.SessionID]uint64)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package crdt
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"tictactoe/luvjson/common"
 )
@@ -20,6 +21,30 @@ type Document struct {
 
 	// localSessionID is the session ID of the local user.
 	localSessionID common.SessionID
+
+	// gc keeps the garbage found by CollectGarbage that is not yet stable.
+	gc *garbageCollector
+
+	// forkParent is the document this document was forked from, if any.
+	forkParent *Document
+
+	// forkBase is the state shared with forkParent, used as the merge base.
+	forkBase *Document
+
+	// loader loads the nodes of a partially loaded document.
+	loader NodeLoader
+
+	// lazyParents maps the IDs of placeholder nodes to the nodes holding them.
+	lazyParents map[common.LogicalTimestamp]Node
+
+	// annotations maps node IDs to the metadata attached to the nodes.
+	annotations map[common.LogicalTimestamp]map[string]*Annotation
+
+	// applied records the operations applied to the document by patches.
+	applied AppliedStore
+
+	// hybridClock generates the counters of local timestamps, if set.
+	hybridClock *common.HybridClock
 }
 
 // NewDocument creates a new JSON CRDT document.
@@ -29,6 +54,7 @@ func NewDocument(sessionID common.SessionID) *Document {
 		index:          make(map[common.LogicalTimestamp]Node),
 		clock:          make(map[string]uint64),
 		localSessionID: sessionID,
+		applied:        NewAppliedRanges(),
 	}
 
 	// Create the root node pointing to the constant node's ID
@@ -54,6 +80,10 @@ func (d *Document) GetNode(id common.LogicalTimestamp) (Node, error) {
 
 	// Otherwise, look up in the index
 	node, ok := d.index[id]
+	if _, lazy := node.(*LazyNode); d.loader != nil && (!ok || lazy) {
+		// 부분 로드된 문서는 필요할 때 노드를 로드
+		return d.hydrate(id)
+	}
 	if !ok {
 		return nil, common.ErrNodeNotFound{ID: id}
 	}
@@ -63,22 +93,51 @@ func (d *Document) GetNode(id common.LogicalTimestamp) (Node, error) {
 // AddNode adds a node to the document.
 func (d *Document) AddNode(node Node) {
 	d.index[node.ID()] = node
+	d.UpdateClock(node.ID())
+}
 
-	// Update the clock
-	sessionID := node.ID().SID
-	counter := node.ID().Counter
+// UpdateClock records that the operation with the specified ID was applied.
+func (d *Document) UpdateClock(id common.LogicalTimestamp) {
 	// Use string representation of UUID as map key
-	sidStr := sessionID.String()
-	if currentCounter, ok := d.clock[sidStr]; !ok || counter > currentCounter {
-		d.clock[sidStr] = counter
+	sidStr := id.SID.String()
+	if currentCounter, ok := d.clock[sidStr]; !ok || id.Counter > currentCounter {
+		d.clock[sidStr] = id.Counter
+	}
+
+	// Order the next local timestamps after the timestamps of other sessions
+	if d.hybridClock != nil && id.SID != d.localSessionID {
+		d.hybridClock.Observe(id)
 	}
 }
 
+// Clock returns the greatest ID of each session known to the document, ordered by
+// session.
+func (d *Document) Clock() []common.LogicalTimestamp {
+	clock := make([]common.LogicalTimestamp, 0, len(d.clock))
+	for sidStr, counter := range d.clock {
+		var sid common.SessionID
+		if err := sid.UnmarshalText([]byte(sidStr)); err != nil {
+			continue
+		}
+		clock = append(clock, common.LogicalTimestamp{SID: sid, Counter: counter})
+	}
+	sort.Slice(clock, func(i, j int) bool { return clock[i].Compare(clock[j]) < 0 })
+	return clock
+}
+
+// ClockCovers reports whether the clock of the document has reached id.
+func (d *Document) ClockCovers(id common.LogicalTimestamp) bool {
+	return id.Counter <= d.clock[id.SID.String()]
+}
+
 // NextTimestamp returns the next logical timestamp for the local session.
 func (d *Document) NextTimestamp() common.LogicalTimestamp {
 	// Use string representation of UUID as map key
 	sidStr := d.localSessionID.String()
 	counter := d.clock[sidStr] + 1
+	if d.hybridClock != nil {
+		counter = d.hybridClock.Next(d.clock[sidStr])
+	}
 	d.clock[sidStr] = counter
 	return common.LogicalTimestamp{
 		SID:     d.localSessionID,
@@ -86,6 +145,13 @@ func (d *Document) NextTimestamp() common.LogicalTimestamp {
 	}
 }
 
+// SetHybridClock makes the document generate local timestamps with a hybrid logical clock,
+// so that their counters carry the wall-clock time and keep increasing across restarts.
+// The clock must belong to the local session of the document.
+func (d *Document) SetHybridClock(clock *common.HybridClock) {
+	d.hybridClock = clock
+}
+
 // GetSessionID returns the local session ID of the document.
 func (d *Document) GetSessionID() common.SessionID {
 	return d.localSessionID
@@ -96,21 +162,15 @@ func (d *Document) GetSessionIDString() string {
 	return d.localSessionID.String()
 }
 
-// View returns a JSON view of the document.
+// View returns a JSON view of the document. Objects are returned as
+// map[string]interface{}, sequences as []interface{} and node IDs held by
+// constants and sequences are resolved to the view of the node they refer to.
+// Use ViewOrdered to iterate object fields in a deterministic order.
 func (d *Document) View() (interface{}, error) {
 	if d.root == nil {
 		return nil, nil
 	}
-
-	// If the root is a LWWValueNode, get the value of its value
-	if lwwVal, ok := d.root.(*LWWValueNode); ok {
-		if lwwVal.NodeValue == nil {
-			return nil, nil
-		}
-		return lwwVal.NodeValue.Value(), nil
-	}
-
-	return d.root.Value(), nil
+	return d.viewOf(d.root, false, make(map[Node]bool)), nil
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -129,8 +189,9 @@ func (d *Document) UnmarshalJSON(data []byte) error {
 func (d *Document) toVerboseJSON() ([]byte, error) {
 	// For now, we'll implement a simplified version
 	type verboseDoc struct {
-		Time map[string]uint64 `json:"time"`
-		Root json.RawMessage   `json:"root"`
+		Time        map[string]uint64      `json:"time"`
+		Root        json.RawMessage        `json:"root"`
+		Annotations []replicatedAnnotation `json:"annotations,omitempty"`
 	}
 
 	// Clock is already map[string]uint64
@@ -143,8 +204,9 @@ func (d *Document) toVerboseJSON() ([]byte, error) {
 	}
 
 	doc := verboseDoc{
-		Time: timeMap,
-		Root: rootJSON,
+		Time:        timeMap,
+		Root:        rootJSON,
+		Annotations: d.replicatedAnnotations(),
 	}
 
 	return json.Marshal(doc)
@@ -153,8 +215,9 @@ func (d *Document) toVerboseJSON() ([]byte, error) {
 // fromVerboseJSON parses a verbose JSON representation of the document.
 func (d *Document) fromVerboseJSON(data []byte) error {
 	type verboseDoc struct {
-		Time map[string]uint64 `json:"time"`
-		Root json.RawMessage   `json:"root"`
+		Time        map[string]uint64      `json:"time"`
+		Root        json.RawMessage        `json:"root"`
+		Annotations []replicatedAnnotation `json:"annotations,omitempty"`
 	}
 
 	var doc verboseDoc
@@ -200,6 +263,11 @@ func (d *Document) fromVerboseJSON(data []byte) error {
 	d.index = make(map[common.LogicalTimestamp]Node)
 	d.index[root.ID()] = root
 
+	d.annotations = nil
+	for _, ann := range doc.Annotations {
+		d.putAnnotation(ann.NodeID, ann.Key, &Annotation{Value: ann.Value, Timestamp: ann.Timestamp, Replicated: true})
+	}
+
 	// Parse other nodes recursively
 	// For LWWValueNode, we need to extract the value and add it to the index
 	if lwwNode, ok := root.(*LWWValueNode); ok && lwwNode.NodeValue != nil {
@@ -0,0 +1,1031 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"tictactoe/luvjson/common"
+)
+
+// Binary snapshot layout:
+//
+//	magic "LJDB", version (1 byte)
+//	session table: count, 16-byte session IDs
+//	string table: count, length-prefixed strings
+//	clock: count, (string index, counter)
+//	root node, index: count, (id, node)
+//	replicated annotations (version 2): count, (node id, key, write id, value)
+//
+// Integers are unsigned varints and timestamps are a session table index followed
+// by the counter. A node that is referenced more than once is written once and
+// referenced by its position afterwards, so shared nodes stay shared after decoding.
+const (
+	binaryMagic = "LJDB"
+
+	// binaryVersion is the version written by MarshalBinary.
+	// UnmarshalBinary rejects snapshots with a greater version.
+	binaryVersion = 2
+)
+
+// Node markers.
+const (
+	binNodeNil byte = iota
+	binNodeRef
+	binNodeInline
+)
+
+// Node type tags.
+const (
+	binTypeRoot byte = iota + 1
+	binTypeVal
+	binTypeCon
+	binTypeObj
+	binTypeVec
+	binTypeStr
+	binTypeArr
+	binTypeBin
+	binTypeList
+	binTypeGCounter
+	binTypePNCounter
+	binTypeMVReg
+)
+
+// Value tags.
+const (
+	binValueNil byte = iota
+	binValueFalse
+	binValueTrue
+	binValueFloat
+	binValueInt
+	binValueString
+	binValueTimestamp
+	binValueArray
+	binValueObject
+	binValueBytes
+	binValueNode
+	binValueJSON
+)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The binary snapshot is a compact alternative to the verbose JSON encoding that
+// also keeps the nodes only referenced by ID, such as array elements.
+func (d *Document) MarshalBinary() ([]byte, error) {
+	enc := newBinaryEncoder()
+
+	sids := make([]string, 0, len(d.clock))
+	for sid := range d.clock {
+		sids = append(sids, sid)
+	}
+	sort.Strings(sids)
+	enc.uvarint(uint64(len(sids)))
+	for _, sid := range sids {
+		enc.stringRef(sid)
+		enc.uvarint(d.clock[sid])
+	}
+
+	if err := enc.node(d.root); err != nil {
+		return nil, err
+	}
+
+	ids := make([]common.LogicalTimestamp, 0, len(d.index))
+	for id := range d.index {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Compare(ids[j]) < 0
+	})
+	enc.uvarint(uint64(len(ids)))
+	for _, id := range ids {
+		enc.timestamp(id)
+		if err := enc.node(d.index[id]); err != nil {
+			return nil, err
+		}
+	}
+
+	anns := d.replicatedAnnotations()
+	enc.uvarint(uint64(len(anns)))
+	for _, ann := range anns {
+		enc.timestamp(ann.NodeID)
+		enc.stringRef(ann.Key)
+		enc.timestamp(ann.Timestamp)
+		if err := enc.value(ann.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	// 테이블은 본문을 인코딩하면서 수집되므로 마지막에 앞에 붙임
+	out := &binaryEncoder{}
+	out.buf.WriteString(binaryMagic)
+	out.buf.WriteByte(binaryVersion)
+	out.uvarint(uint64(len(enc.sessionList)))
+	for _, sid := range enc.sessionList {
+		out.buf.Write(sid[:])
+	}
+	out.uvarint(uint64(len(enc.stringList)))
+	for _, s := range enc.stringList {
+		out.rawString(s)
+	}
+	out.buf.Write(enc.buf.Bytes())
+
+	return out.buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It replaces the state of the document with a snapshot written by MarshalBinary.
+// The local session ID of the document is kept.
+func (d *Document) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return fmt.Errorf("invalid binary snapshot: missing header")
+	}
+	version := data[len(binaryMagic)]
+	if version > binaryVersion {
+		return fmt.Errorf("unsupported binary snapshot version %d", version)
+	}
+
+	dec := &binaryDecoder{r: bytes.NewReader(data[len(binaryMagic)+1:])}
+	if err := dec.tables(); err != nil {
+		return fmt.Errorf("failed to decode binary snapshot: %w", err)
+	}
+
+	clock, root, index, err := dec.document()
+	if err != nil {
+		return fmt.Errorf("failed to decode binary snapshot: %w", err)
+	}
+
+	var anns []replicatedAnnotation
+	if version >= 2 {
+		if anns, err = dec.annotations(); err != nil {
+			return fmt.Errorf("failed to decode binary snapshot: %w", err)
+		}
+	}
+
+	d.clock = clock
+	d.root = root
+	d.index = index
+	d.gc = nil
+	d.annotations = nil
+	for _, ann := range anns {
+		d.putAnnotation(ann.NodeID, ann.Key, &Annotation{Value: ann.Value, Timestamp: ann.Timestamp, Replicated: true})
+	}
+	return nil
+}
+
+// binaryEncoder writes the body of a binary snapshot and collects its tables.
+type binaryEncoder struct {
+	buf         bytes.Buffer
+	sessions    map[common.SessionID]uint64
+	sessionList []common.SessionID
+	strings     map[string]uint64
+	stringList  []string
+	nodes       map[Node]uint64
+}
+
+func newBinaryEncoder() *binaryEncoder {
+	return &binaryEncoder{
+		sessions: make(map[common.SessionID]uint64),
+		strings:  make(map[string]uint64),
+		nodes:    make(map[Node]uint64),
+	}
+}
+
+func (e *binaryEncoder) uvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	e.buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func (e *binaryEncoder) varint(v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	e.buf.Write(tmp[:binary.PutVarint(tmp[:], v)])
+}
+
+func (e *binaryEncoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(1)
+	} else {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *binaryEncoder) rawString(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *binaryEncoder) rawBytes(b []byte) {
+	e.uvarint(uint64(len(b)))
+	e.buf.Write(b)
+}
+
+// stringRef writes the string table index of s, adding s to the table if needed.
+func (e *binaryEncoder) stringRef(s string) {
+	ref, ok := e.strings[s]
+	if !ok {
+		ref = uint64(len(e.stringList))
+		e.strings[s] = ref
+		e.stringList = append(e.stringList, s)
+	}
+	e.uvarint(ref)
+}
+
+func (e *binaryEncoder) session(sid common.SessionID) {
+	ref, ok := e.sessions[sid]
+	if !ok {
+		ref = uint64(len(e.sessionList))
+		e.sessions[sid] = ref
+		e.sessionList = append(e.sessionList, sid)
+	}
+	e.uvarint(ref)
+}
+
+func (e *binaryEncoder) timestamp(ts common.LogicalTimestamp) {
+	e.session(ts.SID)
+	e.uvarint(ts.Counter)
+}
+
+// node writes a node, or a reference to it if it was already written.
+func (e *binaryEncoder) node(node Node) error {
+	if node == nil {
+		e.buf.WriteByte(binNodeNil)
+		return nil
+	}
+	if ref, ok := e.nodes[node]; ok {
+		e.buf.WriteByte(binNodeRef)
+		e.uvarint(ref)
+		return nil
+	}
+	e.nodes[node] = uint64(len(e.nodes))
+	e.buf.WriteByte(binNodeInline)
+
+	switch n := node.(type) {
+	case *RootNode:
+		e.buf.WriteByte(binTypeRoot)
+		e.timestamp(n.NodeId)
+		e.timestamp(n.NodeTimestamp)
+		return e.node(n.NodeValue)
+	case *LWWValueNode:
+		e.buf.WriteByte(binTypeVal)
+		e.timestamp(n.NodeId)
+		e.timestamp(n.NodeTimestamp)
+		return e.node(n.NodeValue)
+	case *ConstantNode:
+		e.buf.WriteByte(binTypeCon)
+		e.timestamp(n.NodeId)
+		return e.value(n.NodeValue)
+	case *LWWObjectNode:
+		e.buf.WriteByte(binTypeObj)
+		e.timestamp(n.NodeId)
+		keys := n.Keys()
+		sort.Strings(keys)
+		e.uvarint(uint64(len(keys)))
+		for _, key := range keys {
+			field := n.NodeFields[key]
+			e.stringRef(key)
+			e.timestamp(field.NodeTimestamp)
+			if err := e.node(field.NodeValue); err != nil {
+				return err
+			}
+		}
+	case *LWWVectorNode:
+		e.buf.WriteByte(binTypeVec)
+		e.timestamp(n.NodeId)
+		indices := n.Indices()
+		sort.Ints(indices)
+		e.uvarint(uint64(len(indices)))
+		for _, index := range indices {
+			field := n.NodeFields[index]
+			e.varint(int64(index))
+			e.timestamp(field.NodeTimestamp)
+			if err := e.node(field.NodeValue); err != nil {
+				return err
+			}
+		}
+	case *RGAStringNode:
+		e.buf.WriteByte(binTypeStr)
+		e.timestamp(n.NodeId)
+		e.uvarint(uint64(len(n.NodeElements)))
+		for _, elem := range n.NodeElements {
+			e.timestamp(elem.NodeId)
+			e.bool(elem.NodeDeleted)
+			e.rawString(chunkText(elem))
+		}
+	case *RGAArrayNode:
+		e.buf.WriteByte(binTypeArr)
+		e.timestamp(n.NodeId)
+		e.uvarint(uint64(len(n.NodeElements)))
+		for _, elem := range n.NodeElements {
+			e.timestamp(elem.NodeId)
+			e.bool(elem.NodeDeleted)
+			if err := e.value(elem.NodeValue); err != nil {
+				return err
+			}
+		}
+	case *RGABinaryNode:
+		e.buf.WriteByte(binTypeBin)
+		e.timestamp(n.NodeId)
+		e.uvarint(uint64(len(n.NodeElements)))
+		for _, elem := range n.NodeElements {
+			e.timestamp(elem.NodeId)
+			e.bool(elem.NodeDeleted)
+			e.rawBytes(elem.NodeValue)
+		}
+	case *MovableListNode:
+		e.buf.WriteByte(binTypeList)
+		e.timestamp(n.NodeId)
+		e.uvarint(uint64(len(n.NodeSlots)))
+		for _, slot := range n.NodeSlots {
+			elemID, _ := slot.NodeValue.(common.LogicalTimestamp)
+			e.timestamp(slot.NodeId)
+			e.timestamp(elemID)
+		}
+		ids := make([]common.LogicalTimestamp, 0, len(n.NodeItems))
+		for id := range n.NodeItems {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i].Compare(ids[j]) < 0
+		})
+		e.uvarint(uint64(len(ids)))
+		for _, id := range ids {
+			elem := n.NodeItems[id]
+			e.timestamp(elem.ElementId)
+			e.timestamp(elem.ElementSlot)
+			e.bool(elem.ElementDeleted)
+			if err := e.value(elem.ElementValue); err != nil {
+				return err
+			}
+		}
+	case *GCounterNode:
+		e.buf.WriteByte(binTypeGCounter)
+		e.timestamp(n.NodeId)
+		e.counts(n.NodeCounts)
+	case *PNCounterNode:
+		e.buf.WriteByte(binTypePNCounter)
+		e.timestamp(n.NodeId)
+		e.counts(n.NodeIncrements)
+		e.counts(n.NodeDecrements)
+	case *MVRegisterNode:
+		e.buf.WriteByte(binTypeMVReg)
+		e.timestamp(n.NodeId)
+		e.uvarint(uint64(len(n.NodeEntries)))
+		for _, entry := range n.NodeEntries {
+			e.timestamp(entry.Timestamp)
+			if err := e.value(entry.Value); err != nil {
+				return err
+			}
+		}
+		superseded := make([]common.LogicalTimestamp, 0, len(n.NodeSuperseded))
+		for ts := range n.NodeSuperseded {
+			superseded = append(superseded, ts)
+		}
+		sort.Slice(superseded, func(i, j int) bool {
+			return superseded[i].Compare(superseded[j]) < 0
+		})
+		e.uvarint(uint64(len(superseded)))
+		for _, ts := range superseded {
+			e.timestamp(ts)
+		}
+	default:
+		return common.ErrInvalidNodeType{Type: string(node.Type())}
+	}
+	return nil
+}
+
+// counts writes per-session counter totals ordered by session ID.
+func (e *binaryEncoder) counts(counts map[common.SessionID]uint64) {
+	sids := make([]common.SessionID, 0, len(counts))
+	for sid := range counts {
+		sids = append(sids, sid)
+	}
+	sort.Slice(sids, func(i, j int) bool {
+		return sids[i].Compare(sids[j]) < 0
+	})
+	e.uvarint(uint64(len(sids)))
+	for _, sid := range sids {
+		e.session(sid)
+		e.uvarint(counts[sid])
+	}
+}
+
+// value writes a node element value. Values that have no binary form are
+// written as JSON.
+func (e *binaryEncoder) value(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		e.buf.WriteByte(binValueNil)
+	case bool:
+		if v {
+			e.buf.WriteByte(binValueTrue)
+		} else {
+			e.buf.WriteByte(binValueFalse)
+		}
+	case float64:
+		e.buf.WriteByte(binValueFloat)
+		var tmp [8]byte
+		binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(v))
+		e.buf.Write(tmp[:])
+	case int:
+		e.buf.WriteByte(binValueInt)
+		e.varint(int64(v))
+	case int64:
+		e.buf.WriteByte(binValueInt)
+		e.varint(v)
+	case string:
+		e.buf.WriteByte(binValueString)
+		e.stringRef(v)
+	case common.LogicalTimestamp:
+		e.buf.WriteByte(binValueTimestamp)
+		e.timestamp(v)
+	case []interface{}:
+		e.buf.WriteByte(binValueArray)
+		e.uvarint(uint64(len(v)))
+		for _, item := range v {
+			if err := e.value(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		e.buf.WriteByte(binValueObject)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		e.uvarint(uint64(len(keys)))
+		for _, key := range keys {
+			e.stringRef(key)
+			if err := e.value(v[key]); err != nil {
+				return err
+			}
+		}
+	case []byte:
+		e.buf.WriteByte(binValueBytes)
+		e.rawBytes(v)
+	case Node:
+		e.buf.WriteByte(binValueNode)
+		return e.node(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		e.buf.WriteByte(binValueJSON)
+		e.rawBytes(data)
+	}
+	return nil
+}
+
+// binaryDecoder reads a binary snapshot.
+type binaryDecoder struct {
+	r        *bytes.Reader
+	sessions []common.SessionID
+	strings  []string
+	nodes    []Node
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	return binary.ReadUvarint(d.r)
+}
+
+// length reads a count or a length and checks it against the remaining data,
+// so that corrupt snapshots do not cause huge allocations.
+func (d *binaryDecoder) length() (int, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(d.r.Len()) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+func (d *binaryDecoder) byte() (byte, error) {
+	return d.r.ReadByte()
+}
+
+func (d *binaryDecoder) bool() (bool, error) {
+	b, err := d.r.ReadByte()
+	return b != 0, err
+}
+
+func (d *binaryDecoder) rawBytes() ([]byte, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (d *binaryDecoder) stringRef() (string, error) {
+	ref, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if ref >= uint64(len(d.strings)) {
+		return "", fmt.Errorf("string reference %d out of range", ref)
+	}
+	return d.strings[ref], nil
+}
+
+func (d *binaryDecoder) session() (common.SessionID, error) {
+	ref, err := d.uvarint()
+	if err != nil {
+		return common.SessionID{}, err
+	}
+	if ref >= uint64(len(d.sessions)) {
+		return common.SessionID{}, fmt.Errorf("session reference %d out of range", ref)
+	}
+	return d.sessions[ref], nil
+}
+
+func (d *binaryDecoder) timestamp() (common.LogicalTimestamp, error) {
+	sid, err := d.session()
+	if err != nil {
+		return common.LogicalTimestamp{}, err
+	}
+	counter, err := d.uvarint()
+	if err != nil {
+		return common.LogicalTimestamp{}, err
+	}
+	return common.LogicalTimestamp{SID: sid, Counter: counter}, nil
+}
+
+// tables reads the session and string tables.
+func (d *binaryDecoder) tables() error {
+	n, err := d.length()
+	if err != nil {
+		return err
+	}
+	d.sessions = make([]common.SessionID, n)
+	for i := range d.sessions {
+		if _, err := io.ReadFull(d.r, d.sessions[i][:]); err != nil {
+			return err
+		}
+	}
+
+	n, err = d.length()
+	if err != nil {
+		return err
+	}
+	d.strings = make([]string, n)
+	for i := range d.strings {
+		b, err := d.rawBytes()
+		if err != nil {
+			return err
+		}
+		d.strings[i] = string(b)
+	}
+	return nil
+}
+
+// document reads the clock, the root node and the index.
+func (d *binaryDecoder) document() (map[string]uint64, Node, map[common.LogicalTimestamp]Node, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	clock := make(map[string]uint64, n)
+	for i := 0; i < n; i++ {
+		sid, err := d.stringRef()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if clock[sid], err = d.uvarint(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	root, err := d.node()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	n, err = d.length()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	index := make(map[common.LogicalTimestamp]Node, n)
+	for i := 0; i < n; i++ {
+		id, err := d.timestamp()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		node, err := d.node()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if node != nil {
+			index[id] = node
+		}
+	}
+
+	return clock, root, index, nil
+}
+
+// annotations reads the replicated annotations.
+func (d *binaryDecoder) annotations() ([]replicatedAnnotation, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	anns := make([]replicatedAnnotation, n)
+	for i := range anns {
+		if anns[i].NodeID, err = d.timestamp(); err != nil {
+			return nil, err
+		}
+		if anns[i].Key, err = d.stringRef(); err != nil {
+			return nil, err
+		}
+		if anns[i].Timestamp, err = d.timestamp(); err != nil {
+			return nil, err
+		}
+		if anns[i].Value, err = d.value(); err != nil {
+			return nil, err
+		}
+	}
+	return anns, nil
+}
+
+// node reads a node written by binaryEncoder.node.
+func (d *binaryDecoder) node() (Node, error) {
+	marker, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch marker {
+	case binNodeNil:
+		return nil, nil
+	case binNodeRef:
+		ref, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if ref >= uint64(len(d.nodes)) {
+			return nil, fmt.Errorf("node reference %d out of range", ref)
+		}
+		return d.nodes[ref], nil
+	case binNodeInline:
+	default:
+		return nil, fmt.Errorf("invalid node marker %d", marker)
+	}
+
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	id, err := d.timestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	// 자식보다 먼저 등록해야 참조 번호가 인코딩 순서와 일치함
+	slot := len(d.nodes)
+	d.nodes = append(d.nodes, nil)
+
+	node, err := d.nodeBody(tag, id)
+	if err != nil {
+		return nil, err
+	}
+	d.nodes[slot] = node
+	return node, nil
+}
+
+// nodeBody reads the fields of a node after its ID.
+func (d *binaryDecoder) nodeBody(tag byte, id common.LogicalTimestamp) (Node, error) {
+	switch tag {
+	case binTypeRoot, binTypeVal:
+		timestamp, err := d.timestamp()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.node()
+		if err != nil {
+			return nil, err
+		}
+		if tag == binTypeRoot {
+			return &RootNode{LWWValueNode: LWWValueNode{NodeId: id, NodeTimestamp: timestamp, NodeValue: value}}, nil
+		}
+		return NewLWWValueNode(id, timestamp, value), nil
+	case binTypeCon:
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		return NewConstantNode(id, value), nil
+	case binTypeObj:
+		node := NewLWWObjectNode(id)
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			key, err := d.stringRef()
+			if err != nil {
+				return nil, err
+			}
+			timestamp, err := d.timestamp()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.node()
+			if err != nil {
+				return nil, err
+			}
+			node.NodeFields[key] = &LWWObjectField{NodeTimestamp: timestamp, NodeValue: value}
+		}
+		return node, nil
+	case binTypeVec:
+		node := NewLWWVectorNode(id)
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			index, err := binary.ReadVarint(d.r)
+			if err != nil {
+				return nil, err
+			}
+			timestamp, err := d.timestamp()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.node()
+			if err != nil {
+				return nil, err
+			}
+			node.NodeFields[int(index)] = &LWWVectorField{NodeTimestamp: timestamp, NodeValue: value}
+		}
+		return node, nil
+	case binTypeStr:
+		node := NewRGAStringNode(id)
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			elemID, err := d.timestamp()
+			if err != nil {
+				return nil, err
+			}
+			deleted, err := d.bool()
+			if err != nil {
+				return nil, err
+			}
+			text, err := d.rawBytes()
+			if err != nil {
+				return nil, err
+			}
+			node.NodeElements = append(node.NodeElements, &RGAElement{NodeId: elemID, NodeValue: string(text), NodeDeleted: deleted})
+		}
+		return node, nil
+	case binTypeArr:
+		node := NewRGAArrayNode(id)
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			elemID, err := d.timestamp()
+			if err != nil {
+				return nil, err
+			}
+			deleted, err := d.bool()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			node.NodeElements = append(node.NodeElements, &RGAElement{NodeId: elemID, NodeValue: value, NodeDeleted: deleted})
+		}
+		return node, nil
+	case binTypeBin:
+		node := NewRGABinaryNode(id)
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			elemID, err := d.timestamp()
+			if err != nil {
+				return nil, err
+			}
+			deleted, err := d.bool()
+			if err != nil {
+				return nil, err
+			}
+			data, err := d.rawBytes()
+			if err != nil {
+				return nil, err
+			}
+			node.NodeElements = append(node.NodeElements, &RGABinaryElement{NodeId: elemID, NodeValue: data, NodeDeleted: deleted})
+		}
+		return node, nil
+	case binTypeList:
+		return d.listBody(id)
+	case binTypeGCounter:
+		node := NewGCounterNode(id)
+		if err := d.counts(node.NodeCounts); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case binTypePNCounter:
+		node := NewPNCounterNode(id)
+		if err := d.counts(node.NodeIncrements); err != nil {
+			return nil, err
+		}
+		if err := d.counts(node.NodeDecrements); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case binTypeMVReg:
+		return d.mvRegisterBody(id)
+	default:
+		return nil, fmt.Errorf("invalid node type tag %d", tag)
+	}
+}
+
+// listBody reads the slots and elements of a movable list.
+func (d *binaryDecoder) listBody(id common.LogicalTimestamp) (Node, error) {
+	node := NewMovableListNode(id)
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		slotID, err := d.timestamp()
+		if err != nil {
+			return nil, err
+		}
+		elemID, err := d.timestamp()
+		if err != nil {
+			return nil, err
+		}
+		node.NodeSlots = append(node.NodeSlots, &RGAElement{NodeId: slotID, NodeValue: elemID})
+	}
+
+	n, err = d.length()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		elemID, err := d.timestamp()
+		if err != nil {
+			return nil, err
+		}
+		slot, err := d.timestamp()
+		if err != nil {
+			return nil, err
+		}
+		deleted, err := d.bool()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		node.NodeItems[elemID] = &MovableListElement{
+			ElementId:      elemID,
+			ElementValue:   value,
+			ElementSlot:    slot,
+			ElementDeleted: deleted,
+		}
+	}
+	return node, nil
+}
+
+// mvRegisterBody reads the entries and superseded writes of a register.
+func (d *binaryDecoder) mvRegisterBody(id common.LogicalTimestamp) (Node, error) {
+	node := NewMVRegisterNode(id)
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		timestamp, err := d.timestamp()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		node.NodeEntries = append(node.NodeEntries, &MVRegisterEntry{Timestamp: timestamp, Value: value})
+	}
+
+	n, err = d.length()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		ts, err := d.timestamp()
+		if err != nil {
+			return nil, err
+		}
+		node.NodeSuperseded[ts] = true
+	}
+	return node, nil
+}
+
+// counts reads per-session counter totals into counts.
+func (d *binaryDecoder) counts(counts map[common.SessionID]uint64) error {
+	n, err := d.length()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		sid, err := d.session()
+		if err != nil {
+			return err
+		}
+		if counts[sid], err = d.uvarint(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value reads a value written by binaryEncoder.value.
+// Integers are decoded as int64.
+func (d *binaryDecoder) value() (interface{}, error) {
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case binValueNil:
+		return nil, nil
+	case binValueFalse:
+		return false, nil
+	case binValueTrue:
+		return true, nil
+	case binValueFloat:
+		var tmp [8]byte
+		if _, err := io.ReadFull(d.r, tmp[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(tmp[:])), nil
+	case binValueInt:
+		return binary.ReadVarint(d.r)
+	case binValueString:
+		return d.stringRef()
+	case binValueTimestamp:
+		return d.timestamp()
+	case binValueArray:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = d.value(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case binValueObject:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		obj := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			key, err := d.stringRef()
+			if err != nil {
+				return nil, err
+			}
+			if obj[key], err = d.value(); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	case binValueBytes:
+		return d.rawBytes()
+	case binValueNode:
+		return d.node()
+	case binValueJSON:
+		data, err := d.rawBytes()
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("invalid value tag %d", tag)
+	}
+}
@@ -0,0 +1,614 @@
+package crdt
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+
+	"tictactoe/luvjson/common"
+)
+
+// MergeOperation describes a change made by MergeFrom.
+type MergeOperation struct {
+	// Type is new for an added node, ins for a write or an insertion and del for a deletion
+	Type common.OperationType
+	// NodeID is the ID of the node that was added or changed
+	NodeID common.LogicalTimestamp
+	// Key is the object key or vector index of a field write or deletion
+	Key string
+	// ID is the ID of the write, of the inserted or deleted element, or of the list slot
+	ID common.LogicalTimestamp
+}
+
+// MergeReport lists the changes made by MergeFrom.
+type MergeReport struct {
+	Operations []MergeOperation
+}
+
+// Count returns the number of operations of the specified type.
+func (r *MergeReport) Count(opType common.OperationType) int {
+	count := 0
+	for _, op := range r.Operations {
+		if op.Type == opType {
+			count++
+		}
+	}
+	return count
+}
+
+// Fork returns an independent copy of the document that creates nodes with the
+// session newSessionID, e.g. to simulate edits before committing them.
+//
+// The fork remembers the state it was created from. MergeFrom uses that state as
+// the base of a three-way merge between the fork and its parent, so fields deleted
+// on one side are deleted by the merge and fields changed on one side only are not
+// overwritten by the unchanged value of the other side.
+func (d *Document) Fork(newSessionID common.SessionID) (*Document, error) {
+	if newSessionID == d.localSessionID {
+		return nil, common.ErrInvalidOperation{Message: "fork must use a different session ID"}
+	}
+
+	fork, err := d.clone(newSessionID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := d.clone(d.localSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	fork.forkParent = d
+	fork.forkBase = base
+	return fork, nil
+}
+
+// MergeFrom merges the state of other into the document and reports the changes.
+// other is not modified. Documents that are not a fork of each other are merged
+// without a base, so deletions made in other are only merged for sequences.
+func (d *Document) MergeFrom(other *Document) (*MergeReport, error) {
+	report := &MergeReport{}
+	if other == d {
+		return report, nil
+	}
+
+	// 상대 문서의 노드를 그대로 가져오기 위해 복사본을 사용
+	src, err := other.clone(other.localSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var base *Document
+	switch {
+	case other.forkParent == d:
+		base = other.forkBase
+	case d.forkParent == other:
+		base = d.forkBase
+	}
+
+	m := &documentMerge{dst: d, src: src, base: base, report: report}
+	m.run()
+
+	for _, ann := range src.replicatedAnnotations() {
+		d.ApplyAnnotation(ann.NodeID, ann.Key, ann.Value, ann.Timestamp)
+	}
+
+	for sid, counter := range other.clock {
+		if counter > d.clock[sid] {
+			d.clock[sid] = counter
+		}
+	}
+
+	// 병합 결과가 상대 문서의 상태를 모두 포함하므로 그 상태가 다음 병합의 기준점
+	if base != nil {
+		newBase, err := other.clone(other.localSessionID)
+		if err != nil {
+			return nil, err
+		}
+		if other.forkParent == d {
+			other.forkBase = newBase
+		} else {
+			d.forkBase = newBase
+		}
+	}
+
+	return report, nil
+}
+
+// clone returns a deep copy of the document with the specified local session.
+func (d *Document) clone(sessionID common.SessionID) (*Document, error) {
+	data, err := d.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	doc := NewDocument(sessionID)
+	if err := doc.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// documentMerge merges the nodes of src into dst.
+type documentMerge struct {
+	dst    *Document
+	src    *Document
+	base   *Document
+	report *MergeReport
+}
+
+func (m *documentMerge) record(opType common.OperationType, nodeID common.LogicalTimestamp, key string, id common.LogicalTimestamp) {
+	m.report.Operations = append(m.report.Operations, MergeOperation{Type: opType, NodeID: nodeID, Key: key, ID: id})
+}
+
+func (m *documentMerge) run() {
+	ids := make([]common.LogicalTimestamp, 0, len(m.src.index))
+	for id := range m.src.index {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Compare(ids[j]) < 0
+	})
+
+	adopted := make(map[common.LogicalTimestamp]bool)
+	for _, id := range ids {
+		if _, ok := m.dst.index[id]; !ok {
+			m.dst.index[id] = m.src.index[id]
+			adopted[id] = true
+			m.record(common.OperationTypeNew, id, "", id)
+		}
+	}
+
+	for _, id := range ids {
+		if adopted[id] {
+			m.relink(m.dst.index[id])
+			continue
+		}
+		m.mergeNode(m.dst.index[id], m.src.index[id])
+	}
+}
+
+// resolve returns the node of dst that a child node of src stands for.
+// Constant nodes are kept as they are, since one operation may create several
+// constants with the same ID.
+func (m *documentMerge) resolve(node Node) Node {
+	if node == nil {
+		return nil
+	}
+	if _, ok := node.(*ConstantNode); ok {
+		return node
+	}
+	if existing, ok := m.dst.index[node.ID()]; ok {
+		return existing
+	}
+	return node
+}
+
+// relink points the children of a node adopted from src to the nodes of dst.
+func (m *documentMerge) relink(node Node) {
+	switch n := node.(type) {
+	case *RootNode:
+		n.NodeValue = m.resolve(n.NodeValue)
+	case *LWWValueNode:
+		n.NodeValue = m.resolve(n.NodeValue)
+	case *LWWObjectNode:
+		for _, field := range n.NodeFields {
+			field.NodeValue = m.resolve(field.NodeValue)
+		}
+	case *LWWVectorNode:
+		for _, field := range n.NodeFields {
+			field.NodeValue = m.resolve(field.NodeValue)
+		}
+	}
+}
+
+// baseNode returns the node with the specified ID in the merge base, if any.
+func (m *documentMerge) baseNode(id common.LogicalTimestamp) Node {
+	if m.base == nil {
+		return nil
+	}
+	return m.base.index[id]
+}
+
+// mergeNode merges a node of src into the node of dst with the same ID.
+func (m *documentMerge) mergeNode(dst, src Node) {
+	switch n := dst.(type) {
+	case *RootNode:
+		if s, ok := src.(*RootNode); ok {
+			m.mergeRoot(n, s)
+		}
+	case *LWWValueNode:
+		if s, ok := src.(*LWWValueNode); ok && s.NodeTimestamp.Compare(n.NodeTimestamp) > 0 {
+			n.SetValue(s.NodeTimestamp, m.resolve(s.NodeValue))
+			m.record(common.OperationTypeIns, n.NodeId, "", s.NodeTimestamp)
+		}
+	case *ConstantNode:
+		if s, ok := src.(*ConstantNode); ok {
+			m.mergeConstant(n, s)
+		}
+	case *LWWObjectNode:
+		if s, ok := src.(*LWWObjectNode); ok {
+			m.mergeObject(n, s)
+		}
+	case *LWWVectorNode:
+		if s, ok := src.(*LWWVectorNode); ok {
+			m.mergeVector(n, s)
+		}
+	case *RGAStringNode:
+		if s, ok := src.(*RGAStringNode); ok {
+			m.mergeString(n, s)
+		}
+	case *RGAArrayNode:
+		if s, ok := src.(*RGAArrayNode); ok {
+			m.mergeArray(n, s)
+		}
+	case *RGABinaryNode:
+		if s, ok := src.(*RGABinaryNode); ok {
+			m.mergeBinary(n, s)
+		}
+	case *MovableListNode:
+		if s, ok := src.(*MovableListNode); ok {
+			m.mergeList(n, s)
+		}
+	case *GCounterNode:
+		if s, ok := src.(*GCounterNode); ok {
+			m.mergeCounter(n, s.NodeCounts, nil)
+		}
+	case *PNCounterNode:
+		if s, ok := src.(*PNCounterNode); ok {
+			m.mergeCounter(n, s.NodeIncrements, s.NodeDecrements)
+		}
+	case *MVRegisterNode:
+		if s, ok := src.(*MVRegisterNode); ok {
+			m.mergeMVRegister(n, s)
+		}
+	}
+}
+
+// mergeRoot takes the root value of src like a field, see takeField.
+func (m *documentMerge) mergeRoot(n, s *RootNode) {
+	if s.NodeValue == nil {
+		return
+	}
+
+	var current, baseID *common.LogicalTimestamp
+	if n.NodeValue != nil {
+		id := n.NodeValue.ID()
+		current = &id
+	}
+	if base, ok := m.baseNode(common.RootID).(*RootNode); ok && base.NodeValue != nil {
+		id := base.NodeValue.ID()
+		baseID = &id
+	}
+	if !takeField(s.NodeValue.ID(), current, baseID) {
+		return
+	}
+
+	n.NodeValue = m.resolve(s.NodeValue)
+	m.record(common.OperationTypeIns, n.NodeId, "", s.NodeValue.ID())
+}
+
+// takeField reports whether the write srcTS of src replaces the write current of
+// dst, or nil if dst has no such field. baseTS is the write in the merge base, or
+// nil. A side that kept the base write did not change the field, so the other
+// side wins; otherwise the greater write wins.
+func takeField(srcTS common.LogicalTimestamp, current, baseTS *common.LogicalTimestamp) bool {
+	if baseTS != nil && *baseTS == srcTS {
+		// src는 변경하지 않음 (dst가 삭제했을 수도 있음)
+		return false
+	}
+	if current == nil {
+		return true
+	}
+	if *current == srcTS {
+		return false
+	}
+	if baseTS != nil && *baseTS == *current {
+		return true
+	}
+	return srcTS.Compare(*current) > 0
+}
+
+// mergeConstant takes the value of src if only src changed it since the base.
+func (m *documentMerge) mergeConstant(n, s *ConstantNode) {
+	base, ok := m.baseNode(n.NodeId).(*ConstantNode)
+	if !ok || reflect.DeepEqual(n.NodeValue, s.NodeValue) || !reflect.DeepEqual(n.NodeValue, base.NodeValue) {
+		return
+	}
+	n.NodeValue = s.NodeValue
+	m.record(common.OperationTypeIns, n.NodeId, "", n.NodeId)
+}
+
+// mergeObject merges the fields of an object, see takeField. With a base, fields
+// deleted by src are deleted unless dst changed them.
+func (m *documentMerge) mergeObject(n, s *LWWObjectNode) {
+	base, _ := m.baseNode(n.NodeId).(*LWWObjectNode)
+
+	keys := s.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := s.NodeFields[key]
+		var current, baseTS *common.LogicalTimestamp
+		if existing, ok := n.NodeFields[key]; ok {
+			current = &existing.NodeTimestamp
+		}
+		if base != nil {
+			if baseField, ok := base.NodeFields[key]; ok {
+				baseTS = &baseField.NodeTimestamp
+			}
+		}
+		if !takeField(field.NodeTimestamp, current, baseTS) {
+			continue
+		}
+
+		n.NodeFields[key] = &LWWObjectField{NodeTimestamp: field.NodeTimestamp, NodeValue: m.resolve(field.NodeValue)}
+		m.record(common.OperationTypeIns, n.NodeId, key, field.NodeTimestamp)
+	}
+
+	if base == nil {
+		return
+	}
+	baseKeys := base.Keys()
+	sort.Strings(baseKeys)
+	for _, key := range baseKeys {
+		if _, ok := s.NodeFields[key]; ok {
+			continue
+		}
+		if current, ok := n.NodeFields[key]; ok && current.NodeTimestamp == base.NodeFields[key].NodeTimestamp {
+			delete(n.NodeFields, key)
+			m.record(common.OperationTypeDel, n.NodeId, key, current.NodeTimestamp)
+		}
+	}
+}
+
+// mergeVector merges the fields of a vector like mergeObject.
+func (m *documentMerge) mergeVector(n, s *LWWVectorNode) {
+	base, _ := m.baseNode(n.NodeId).(*LWWVectorNode)
+
+	indices := s.Indices()
+	sort.Ints(indices)
+	for _, index := range indices {
+		field := s.NodeFields[index]
+		var current, baseTS *common.LogicalTimestamp
+		if existing, ok := n.NodeFields[index]; ok {
+			current = &existing.NodeTimestamp
+		}
+		if base != nil {
+			if baseField, ok := base.NodeFields[index]; ok {
+				baseTS = &baseField.NodeTimestamp
+			}
+		}
+		if !takeField(field.NodeTimestamp, current, baseTS) {
+			continue
+		}
+
+		n.NodeFields[index] = &LWWVectorField{NodeTimestamp: field.NodeTimestamp, NodeValue: m.resolve(field.NodeValue)}
+		m.record(common.OperationTypeIns, n.NodeId, strconv.Itoa(index), field.NodeTimestamp)
+	}
+
+	if base == nil {
+		return
+	}
+	baseIndices := base.Indices()
+	sort.Ints(baseIndices)
+	for _, index := range baseIndices {
+		if _, ok := s.NodeFields[index]; ok {
+			continue
+		}
+		if current, ok := n.NodeFields[index]; ok && current.NodeTimestamp == base.NodeFields[index].NodeTimestamp {
+			delete(n.NodeFields, index)
+			m.record(common.OperationTypeDel, n.NodeId, strconv.Itoa(index), current.NodeTimestamp)
+		}
+	}
+}
+
+// mergeString inserts the characters of src that dst is missing after the
+// character preceding them in src, and deletes the characters src deleted.
+func (m *documentMerge) mergeString(n, s *RGAStringNode) {
+	prev := common.RootID
+	var run *RGAElement
+
+	// 연속된 새 문자는 한 번에 삽입
+	flush := func() {
+		if run == nil {
+			return
+		}
+		text := chunkText(run)
+		length := uint64(len([]rune(text)))
+		n.Insert(prev, run.NodeId, text)
+		m.record(common.OperationTypeIns, n.NodeId, "", run.NodeId)
+		prev = run.NodeId.Increment(length - 1)
+		if run.NodeDeleted {
+			n.Delete(run.NodeId, prev)
+			m.record(common.OperationTypeDel, n.NodeId, "", run.NodeId)
+		}
+		run = nil
+	}
+
+	for _, c := range s.Characters() {
+		text := string(c.NodeValue.(rune))
+		if chunk, _, ok := n.findChar(c.NodeId); ok {
+			flush()
+			if c.NodeDeleted && !n.NodeElements[chunk].NodeDeleted {
+				n.Delete(c.NodeId, c.NodeId)
+				m.record(common.OperationTypeDel, n.NodeId, "", c.NodeId)
+			}
+			prev = c.NodeId
+			continue
+		}
+
+		if run != nil && chunkContinues(run, c.NodeId, c.NodeDeleted) {
+			run.NodeValue = chunkText(run) + text
+			continue
+		}
+		flush()
+		run = &RGAElement{NodeId: c.NodeId, NodeValue: text, NodeDeleted: c.NodeDeleted}
+	}
+	flush()
+
+	n.Compact()
+}
+
+// mergeArray inserts the elements of src that dst is missing after the element
+// preceding them in src, and deletes the elements src deleted.
+func (m *documentMerge) mergeArray(n, s *RGAArrayNode) {
+	positions := make(map[common.LogicalTimestamp]*RGAElement, len(n.NodeElements))
+	for _, elem := range n.NodeElements {
+		positions[elem.NodeId] = elem
+	}
+
+	prev := common.RootID
+	for _, elem := range s.NodeElements {
+		existing, ok := positions[elem.NodeId]
+		if !ok {
+			n.Insert(prev, elem.NodeId, elem.NodeValue)
+			m.record(common.OperationTypeIns, n.NodeId, "", elem.NodeId)
+			if elem.NodeDeleted {
+				n.Delete(elem.NodeId)
+				m.record(common.OperationTypeDel, n.NodeId, "", elem.NodeId)
+			}
+		} else if elem.NodeDeleted && !existing.NodeDeleted {
+			existing.NodeDeleted = true
+			m.record(common.OperationTypeDel, n.NodeId, "", elem.NodeId)
+		}
+		prev = elem.NodeId
+	}
+}
+
+// mergeBinary merges the chunks of a binary node like mergeArray.
+func (m *documentMerge) mergeBinary(n, s *RGABinaryNode) {
+	positions := make(map[common.LogicalTimestamp]*RGABinaryElement, len(n.NodeElements))
+	for _, elem := range n.NodeElements {
+		positions[elem.NodeId] = elem
+	}
+
+	prev := common.RootID
+	for _, elem := range s.NodeElements {
+		existing, ok := positions[elem.NodeId]
+		if !ok {
+			n.Insert(prev, elem.NodeId, elem.NodeValue)
+			m.record(common.OperationTypeIns, n.NodeId, "", elem.NodeId)
+			if elem.NodeDeleted {
+				n.Delete(elem.NodeId, elem.NodeId)
+				m.record(common.OperationTypeDel, n.NodeId, "", elem.NodeId)
+			}
+		} else if elem.NodeDeleted && !existing.NodeDeleted {
+			existing.NodeDeleted = true
+			m.record(common.OperationTypeDel, n.NodeId, "", elem.NodeId)
+		}
+		prev = elem.NodeId
+	}
+}
+
+// mergeList adds the elements and slots of src that dst is missing. Element
+// slots are last-writer-wins registers and deletions are kept; element values
+// are taken from src if only src changed them since the base.
+func (m *documentMerge) mergeList(n, s *MovableListNode) {
+	base, _ := m.baseNode(n.NodeId).(*MovableListNode)
+
+	ids := make([]common.LogicalTimestamp, 0, len(s.NodeItems))
+	for id := range s.NodeItems {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Compare(ids[j]) < 0
+	})
+
+	for _, id := range ids {
+		elem := s.NodeItems[id]
+		existing, ok := n.NodeItems[id]
+		if !ok {
+			copied := *elem
+			n.NodeItems[id] = &copied
+			m.record(common.OperationTypeIns, n.NodeId, "", id)
+			continue
+		}
+
+		if elem.ElementSlot.Compare(existing.ElementSlot) > 0 {
+			existing.ElementSlot = elem.ElementSlot
+		}
+		if elem.ElementDeleted && !existing.ElementDeleted {
+			existing.ElementDeleted = true
+			m.record(common.OperationTypeDel, n.NodeId, "", id)
+		}
+		if base != nil {
+			if baseElem, ok := base.NodeItems[id]; ok &&
+				reflect.DeepEqual(existing.ElementValue, baseElem.ElementValue) &&
+				!reflect.DeepEqual(existing.ElementValue, elem.ElementValue) {
+				existing.ElementValue = elem.ElementValue
+				m.record(common.OperationTypeIns, n.NodeId, "", id)
+			}
+		}
+	}
+
+	slots := make(map[common.LogicalTimestamp]bool, len(n.NodeSlots))
+	for _, slot := range n.NodeSlots {
+		slots[slot.NodeId] = true
+	}
+	prev := common.RootID
+	for _, slot := range s.NodeSlots {
+		if !slots[slot.NodeId] {
+			elemID, _ := slot.NodeValue.(common.LogicalTimestamp)
+			n.insertSlot(prev, slot.NodeId, elemID)
+			if slot.NodeId != elemID {
+				// 이동으로 생긴 슬롯
+				m.record(common.OperationTypeIns, n.NodeId, "", slot.NodeId)
+			}
+		}
+		prev = slot.NodeId
+	}
+}
+
+// mergeCounter raises the per-session totals of a counter to those of src.
+func (m *documentMerge) mergeCounter(n CounterNode, increments, decrements map[common.SessionID]uint64) {
+	sids := make([]common.SessionID, 0, len(increments)+len(decrements))
+	seen := make(map[common.SessionID]bool)
+	for _, totals := range []map[common.SessionID]uint64{increments, decrements} {
+		for sid := range totals {
+			if !seen[sid] {
+				seen[sid] = true
+				sids = append(sids, sid)
+			}
+		}
+	}
+	sort.Slice(sids, func(i, j int) bool {
+		return sids[i].Compare(sids[j]) < 0
+	})
+
+	for _, sid := range sids {
+		inc, dec := n.Totals(sid)
+		n.Merge(sid, increments[sid], decrements[sid])
+		if newInc, newDec := n.Totals(sid); newInc != inc || newDec != dec {
+			m.record(common.OperationTypeIns, n.ID(), "", common.LogicalTimestamp{SID: sid})
+		}
+	}
+}
+
+// mergeMVRegister adds the writes of src that dst has not superseded and removes
+// the writes src superseded.
+func (m *documentMerge) mergeMVRegister(n, s *MVRegisterNode) {
+	for ts := range s.NodeSuperseded {
+		n.NodeSuperseded[ts] = true
+	}
+
+	entries := make([]*MVRegisterEntry, 0, len(n.NodeEntries)+len(s.NodeEntries))
+	present := make(map[common.LogicalTimestamp]bool)
+	for _, entry := range n.NodeEntries {
+		if n.NodeSuperseded[entry.Timestamp] {
+			m.record(common.OperationTypeDel, n.NodeId, "", entry.Timestamp)
+			continue
+		}
+		present[entry.Timestamp] = true
+		entries = append(entries, entry)
+	}
+	for _, entry := range s.NodeEntries {
+		if n.NodeSuperseded[entry.Timestamp] || present[entry.Timestamp] {
+			continue
+		}
+		entries = append(entries, &MVRegisterEntry{Timestamp: entry.Timestamp, Value: entry.Value})
+		m.record(common.OperationTypeIns, n.NodeId, "", entry.Timestamp)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Compare(entries[j].Timestamp) < 0
+	})
+	n.NodeEntries = entries
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"tictactoe/luvjson/common"
 
@@ -73,6 +74,27 @@ func TestNextTimestamp(t *testing.T) {
 	assert.Equal(t, uint64(2), ts.Counter)
 }
 
+func TestNextTimestampHybrid(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := NewDocument(sid)
+	doc.SetHybridClock(common.NewHybridClock(sid))
+
+	// Timestamps carry the wall-clock time and increase
+	before := time.Now().Truncate(time.Millisecond)
+	ts1 := doc.NextTimestamp()
+	ts2 := doc.NextTimestamp()
+	assert.Equal(t, sid, ts1.SID)
+	assert.True(t, ts1.IsHybrid())
+	assert.False(t, ts1.WallTime().Before(before))
+	assert.Less(t, ts1.Counter, ts2.Counter)
+
+	// Timestamps are ordered after the timestamps of other sessions
+	remoteID := common.LogicalTimestamp{SID: common.NewSessionID(), Counter: ts2.Counter + 1000}
+	doc.AddNode(NewConstantNode(remoteID, "remote"))
+	ts3 := doc.NextTimestamp()
+	assert.Equal(t, -1, remoteID.CompareTime(ts3))
+}
+
 func TestMarshalJSON(t *testing.T) {
 	sid := common.NewSessionID()
 	doc := NewDocument(sid)
@@ -255,6 +277,93 @@ func TestView(t *testing.T) {
 	assert.Nil(t, view)
 }
 
+func TestViewOrderedAndStateHash(t *testing.T) {
+	// build creates {"name": "boss", "hp": 100, "tags": ["fire"]}, setting the
+	// fields in the given order
+	build := func(keys []string, typo bool) *Document {
+		sid := common.NewSessionID()
+		doc := NewDocument(sid)
+		ts := func(counter uint64) common.LogicalTimestamp {
+			return common.LogicalTimestamp{SID: sid, Counter: counter}
+		}
+
+		obj := NewLWWObjectNode(ts(1))
+		doc.AddNode(obj)
+		rootValue := NewConstantNode(ts(2), obj.ID())
+		doc.AddNode(rootValue)
+		doc.Root().(*RootNode).NodeValue = rootValue
+
+		str := NewRGAStringNode(ts(3))
+		if typo {
+			str.Insert(common.RootID, ts(4), "bosss")
+			str.Delete(ts(8), ts(8))
+		} else {
+			str.Insert(common.RootID, ts(4), "boss")
+		}
+		doc.AddNode(str)
+
+		hp := NewConstantNode(ts(9), 100)
+		doc.AddNode(hp)
+
+		arr := NewRGAArrayNode(ts(10))
+		doc.AddNode(arr)
+		fire := NewConstantNode(ts(11), "fire")
+		doc.AddNode(fire)
+		arr.Insert(common.RootID, ts(12), fire.ID())
+
+		values := map[string]Node{"name": str, "hp": hp, "tags": arr}
+		for i, key := range keys {
+			obj.Set(key, ts(uint64(20+i)), values[key])
+		}
+		return doc
+	}
+
+	doc := build([]string{"name", "hp", "tags"}, false)
+	other := build([]string{"tags", "hp", "name"}, true)
+
+	view, err := doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name": "boss",
+		"hp":   float64(100),
+		"tags": []interface{}{"fire"},
+	}, view)
+
+	ordered, err := other.ViewOrdered()
+	assert.NoError(t, err)
+	assert.Equal(t, OrderedObject{
+		{Key: "hp", Value: float64(100)},
+		{Key: "name", Value: "boss"},
+		{Key: "tags", Value: []interface{}{"fire"}},
+	}, ordered)
+
+	data, err := json.Marshal(ordered)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"hp":100,"name":"boss","tags":["fire"]}`, string(data))
+
+	value, ok := ordered.(OrderedObject).Get("name")
+	assert.True(t, ok)
+	assert.Equal(t, "boss", value)
+	_, ok = ordered.(OrderedObject).Get("mp")
+	assert.False(t, ok)
+
+	// 적용 순서와 톰스톤이 달라도 같은 상태면 같은 해시
+	hash, err := doc.StateHash()
+	assert.NoError(t, err)
+	otherHash, err := other.StateHash()
+	assert.NoError(t, err)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, otherHash)
+
+	obj, err := other.GetNode(other.Root().(*RootNode).NodeValue.(*ConstantNode).NodeValue.(common.LogicalTimestamp))
+	assert.NoError(t, err)
+	hpID := common.LogicalTimestamp{SID: obj.ID().SID, Counter: 30}
+	obj.(*LWWObjectNode).Set("hp", hpID, NewConstantNode(hpID, float64(90)))
+	otherHash, err = other.StateHash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+}
+
 func TestApplyPatch(t *testing.T) {
 	// Skip this test for now as we need to update the JSON format
 	t.Skip("Need to update JSON format for SessionID")
@@ -454,3 +563,466 @@ func TestApplyPatch(t *testing.T) {
 	err = doc.ApplyPatch(patchData)
 	assert.NoError(t, err)
 }
+
+func TestCollectGarbage(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := NewDocument(sid)
+	ts := func(counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	// {"name": "hello", "items": ["x"], "old": "gone"}
+	obj := NewLWWObjectNode(ts(1))
+	str := NewRGAStringNode(ts(2))
+	arr := NewRGAArrayNode(ts(3))
+	for _, node := range []Node{obj, str, arr} {
+		doc.AddNode(node)
+	}
+	rootValue := NewConstantNode(ts(4), obj.ID())
+	doc.AddNode(rootValue)
+	doc.Root().(*RootNode).NodeValue = rootValue
+	obj.Set("name", ts(2), str)
+	obj.Set("items", ts(3), arr)
+
+	assert.True(t, str.Insert(common.RootID, ts(5), "hello"))
+	doc.UpdateClock(ts(9))
+	x := NewConstantNode(ts(10), "x")
+	doc.AddNode(x)
+	assert.True(t, arr.Insert(common.RootID, ts(11), x.ID()))
+	old := NewConstantNode(ts(12), "gone")
+	doc.AddNode(old)
+	obj.Set("old", ts(12), old)
+
+	// "hello" -> "hlo", ["x"] -> [], "old" 필드 삭제
+	assert.True(t, str.Delete(ts(6), ts(7)))
+	assert.True(t, arr.Delete(ts(11)))
+	assert.True(t, obj.Delete("old", ts(13)))
+	doc.UpdateClock(ts(13))
+
+	// 안정화되지 않은 가비지는 표시만 함
+	stats := doc.CollectGarbage(StabilityFrontier{})
+	assert.Equal(t, GCStats{Pending: 4}, stats)
+	assert.Equal(t, 5, len(str.Characters()))
+
+	// 표시 이후의 삭제는 다음 epoch에서 처리
+	assert.True(t, str.Delete(ts(9), ts(9)))
+	doc.UpdateClock(ts(14))
+
+	view, err := doc.View()
+	assert.NoError(t, err)
+
+	stats = doc.CollectGarbage(StabilityFrontier{sid: 13})
+	assert.Equal(t, 2+1, stats.Tombstones)
+	assert.Equal(t, 2, stats.Nodes)
+	assert.Equal(t, 1, stats.Pending)
+	assert.Equal(t, "hl", str.String())
+	assert.Equal(t, 3, len(str.Characters()))
+	assert.Equal(t, 0, len(arr.NodeElements))
+	_, err = doc.GetNode(x.ID())
+	assert.Error(t, err)
+	_, err = doc.GetNode(old.ID())
+	assert.Error(t, err)
+
+	stats = doc.CollectGarbage(StabilityFrontier{sid: 14})
+	assert.Equal(t, GCStats{Tombstones: 1}, stats)
+	assert.Equal(t, 2, len(str.Characters()))
+
+	newView, err := doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, view, newView)
+	assert.Equal(t, map[string]interface{}{"name": "hl", "items": []interface{}{}}, newView)
+
+	// 제거된 문자 다음의 문자를 기준으로 계속 편집 가능
+	assert.True(t, str.Insert(ts(8), ts(15), "!"))
+	assert.Equal(t, "hl!", str.String())
+}
+
+func TestCollectGarbage_ListAndRegister(t *testing.T) {
+	sidA := common.NewSessionID()
+	sidB := common.NewSessionID()
+	doc := NewDocument(sidA)
+	ts := func(sid common.SessionID, counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	obj := NewLWWObjectNode(ts(sidA, 1))
+	list := NewMovableListNode(ts(sidA, 2))
+	reg := NewMVRegisterNode(ts(sidA, 3))
+	for _, node := range []Node{obj, list, reg} {
+		doc.AddNode(node)
+	}
+	rootValue := NewConstantNode(ts(sidA, 4), obj.ID())
+	doc.AddNode(rootValue)
+	doc.Root().(*RootNode).NodeValue = rootValue
+	obj.Set("list", ts(sidA, 2), list)
+	obj.Set("reg", ts(sidA, 3), reg)
+
+	// [a, b] -> [b, a] -> [a]
+	assert.True(t, list.Insert(common.RootID, ts(sidA, 5), "a"))
+	assert.True(t, list.Insert(ts(sidA, 5), ts(sidA, 6), "b"))
+	assert.True(t, list.Move(ts(sidA, 5), ts(sidA, 6), ts(sidA, 7)))
+	assert.True(t, list.Delete(ts(sidA, 6)))
+
+	// 동시 쓰기 후 충돌 해소
+	assert.True(t, reg.Set(ts(sidA, 8), "x", nil))
+	assert.True(t, reg.Set(ts(sidB, 1), "y", nil))
+	assert.True(t, reg.Assign(ts(sidA, 9), "z"))
+	doc.UpdateClock(ts(sidA, 9))
+	doc.UpdateClock(ts(sidB, 1))
+
+	// 모든 연산이 안정화되었으면 즉시 수집
+	stats := doc.CollectGarbage(StabilityFrontier{sidA: 9, sidB: 1})
+	assert.Equal(t, 3, stats.Tombstones)
+	assert.Equal(t, 2, stats.Superseded)
+	assert.Equal(t, 0, stats.Pending)
+
+	assert.Equal(t, []interface{}{"a"}, list.Value())
+	assert.Equal(t, 1, len(list.NodeItems))
+	assert.Equal(t, 1, len(list.NodeSlots))
+	assert.Equal(t, "z", reg.Value())
+	assert.Empty(t, reg.NodeSuperseded)
+}
+
+func TestMarshalBinary(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := NewDocument(sid)
+	ts := func(counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	// {"name": "boss", "hp": 100, "tags": ["fire"], "log": [...], "dmg": <counter>}
+	obj := NewLWWObjectNode(ts(1))
+	doc.AddNode(obj)
+	rootValue := NewConstantNode(ts(2), obj.ID())
+	doc.AddNode(rootValue)
+	doc.Root().(*RootNode).NodeValue = rootValue
+
+	str := NewRGAStringNode(ts(3))
+	str.Insert(common.RootID, ts(4), "boss!")
+	str.Delete(ts(8), ts(8))
+	doc.AddNode(str)
+	obj.Set("name", ts(3), str)
+
+	hp := NewConstantNode(ts(9), float64(100))
+	doc.AddNode(hp)
+	obj.Set("hp", ts(9), hp)
+
+	arr := NewRGAArrayNode(ts(10))
+	doc.AddNode(arr)
+	fire := NewConstantNode(ts(11), "fire")
+	doc.AddNode(fire)
+	arr.Insert(common.RootID, ts(12), fire.ID())
+	obj.Set("tags", ts(10), arr)
+
+	list := NewMovableListNode(ts(13))
+	doc.AddNode(list)
+	list.Insert(common.RootID, ts(14), map[string]interface{}{"hit": true, "by": "alice"})
+	list.Insert(ts(14), ts(15), []interface{}{float64(1), nil})
+	list.Move(ts(14), ts(15), ts(16))
+	obj.Set("log", ts(13), list)
+
+	dmg := NewPNCounterNode(ts(17))
+	dmg.Inc(sid, 30)
+	dmg.Dec(sid, 5)
+	doc.AddNode(dmg)
+	obj.Set("dmg", ts(17), dmg)
+
+	data, err := doc.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, "LJDB", string(data[:4]))
+
+	jsonData, err := doc.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Less(t, len(data), len(jsonData))
+
+	decoded := NewDocument(common.NewSessionID())
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+
+	view, err := doc.View()
+	assert.NoError(t, err)
+	decodedView, err := decoded.View()
+	assert.NoError(t, err)
+	assert.Equal(t, view, decodedView)
+	assert.Equal(t, len(doc.index), len(decoded.index))
+
+	// 배열 요소처럼 ID로만 참조되는 노드도 복원
+	node, err := decoded.GetNode(fire.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, "fire", node.Value())
+
+	// 객체 필드와 인덱스는 같은 노드를 가리킴
+	decodedObj := decoded.index[obj.ID()].(*LWWObjectNode)
+	assert.Same(t, decoded.index[str.ID()], decodedObj.Get("name"))
+
+	// 시계가 복원되어 다음 타임스탬프가 이어짐
+	assert.Equal(t, doc.clock, decoded.clock)
+
+	// 인코딩은 결정적
+	again, err := decoded.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, data, again)
+
+	// 지원하지 않는 버전과 잘린 데이터는 거부
+	newer := append([]byte{}, data...)
+	newer[4] = binaryVersion + 1
+	assert.Error(t, decoded.UnmarshalBinary(newer))
+	assert.Error(t, decoded.UnmarshalBinary(data[:len(data)/2]))
+	assert.Error(t, decoded.UnmarshalBinary([]byte("{}")))
+}
+
+func TestForkAndMergeFrom(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := NewDocument(sid)
+
+	// {"hp": 100, "name": "boss", "phase": 1}
+	obj := NewLWWObjectNode(doc.NextTimestamp())
+	doc.AddNode(obj)
+	rootValue := NewConstantNode(doc.NextTimestamp(), obj.ID())
+	doc.AddNode(rootValue)
+	doc.Root().(*RootNode).NodeValue = rootValue
+	setField := func(d *Document, key string, value interface{}) {
+		id := d.NextTimestamp()
+		node := NewConstantNode(id, value)
+		d.AddNode(node)
+		d.index[obj.ID()].(*LWWObjectNode).Set(key, id, node)
+	}
+	setField(doc, "hp", float64(100))
+	setField(doc, "phase", float64(1))
+	name := NewRGAStringNode(doc.NextTimestamp())
+	doc.AddNode(name)
+	name.Insert(common.RootID, doc.NextTimestamp(), "boss")
+	doc.clock[sid.String()] += 3
+	obj.Set("name", name.ID(), name)
+
+	_, err := doc.Fork(sid)
+	assert.Error(t, err)
+
+	forkSID := common.NewSessionID()
+	fork, err := doc.Fork(forkSID)
+	assert.NoError(t, err)
+	assert.Equal(t, forkSID, fork.GetSessionID())
+
+	// 포크: hp 변경, phase 삭제, 이름 뒤에 " king" 추가
+	setField(fork, "hp", float64(40))
+	forkObj := fork.index[obj.ID()].(*LWWObjectNode)
+	assert.True(t, forkObj.Delete("phase", fork.NextTimestamp()))
+	forkName := fork.index[name.ID()].(*RGAStringNode)
+	ids := forkName.VisibleIDs()
+	forkName.Insert(ids[len(ids)-1], fork.NextTimestamp(), " king")
+	fork.clock[forkSID.String()] += 4
+
+	// 원본은 포크의 영향을 받지 않음
+	view := doc.index[obj.ID()].Value()
+	assert.Equal(t, map[string]interface{}{"hp": float64(100), "phase": float64(1), "name": "boss"}, view)
+
+	// 원본: 이름 앞에 "big " 추가
+	name.Insert(common.RootID, doc.NextTimestamp(), "big ")
+	doc.clock[sid.String()] += 3
+
+	report, err := doc.MergeFrom(fork)
+	assert.NoError(t, err)
+	view = doc.index[obj.ID()].Value()
+	assert.Equal(t, map[string]interface{}{"hp": float64(40), "name": "big boss king"}, view)
+	assert.Equal(t, 1, report.Count(common.OperationTypeNew))
+	assert.Equal(t, 2, report.Count(common.OperationTypeIns))
+	assert.Equal(t, 1, report.Count(common.OperationTypeDel))
+	assert.Equal(t, fork.clock[forkSID.String()], doc.clock[forkSID.String()])
+
+	// 이미 병합한 상태를 다시 병합하면 변경 없음
+	report, err = doc.MergeFrom(fork)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Operations)
+
+	// 포크 쪽으로 병합하면 같은 상태로 수렴
+	_, err = fork.MergeFrom(doc)
+	assert.NoError(t, err)
+	forkView := fork.index[obj.ID()].Value()
+	assert.Equal(t, view, forkView)
+
+	// 원본에서 삭제한 필드는 포크의 변경되지 않은 값으로 되살리지 않음
+	assert.True(t, obj.Delete("name", doc.NextTimestamp()))
+	report, err = doc.MergeFrom(fork)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Operations)
+	assert.Equal(t, map[string]interface{}{"hp": float64(40)}, doc.index[obj.ID()].Value())
+}
+
+// countingLoader records the nodes loaded through it.
+func TestAnnotations(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := NewDocument(sid)
+
+	// {"boss": {"name": "dragon"}, "log": ["hit"]}
+	obj := NewLWWObjectNode(doc.NextTimestamp())
+	doc.AddNode(obj)
+	rootValue := NewConstantNode(doc.NextTimestamp(), obj.ID())
+	doc.AddNode(rootValue)
+	doc.Root().(*RootNode).NodeValue = rootValue
+	boss := NewLWWObjectNode(doc.NextTimestamp())
+	doc.AddNode(boss)
+	obj.Set("boss", boss.ID(), boss)
+	name := NewRGAStringNode(doc.NextTimestamp())
+	doc.AddNode(name)
+	name.Insert(common.RootID, doc.NextTimestamp(), "dragon")
+	doc.clock[sid.String()] += 5
+	boss.Set("name", name.ID(), name)
+	arr := NewRGAArrayNode(doc.NextTimestamp())
+	doc.AddNode(arr)
+	hit := NewConstantNode(doc.NextTimestamp(), "hit")
+	doc.AddNode(hit)
+	arr.Insert(common.RootID, doc.NextTimestamp(), hit.ID())
+	obj.Set("log", arr.ID(), arr)
+
+	err := doc.SetAnnotation(common.LogicalTimestamp{SID: sid, Counter: 100}, "author", "alice")
+	assert.Error(t, err)
+
+	assert.NoError(t, doc.SetAnnotation(name.ID(), "cursor", float64(3)))
+	_, err = doc.SetReplicatedAnnotation(name.ID(), "author", "alice")
+	assert.NoError(t, err)
+	_, err = doc.SetReplicatedAnnotation(hit.ID(), "author", "bob")
+	assert.NoError(t, err)
+
+	ann, ok := doc.GetAnnotation(name.ID(), "author")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", ann.Value)
+	assert.True(t, ann.Replicated)
+	assert.Equal(t, map[string]interface{}{"author": "alice", "cursor": float64(3)}, doc.Annotations(name.ID()))
+
+	view, annotations, err := doc.ViewWithAnnotations()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"boss": map[string]interface{}{"name": "dragon"},
+		"log":  []interface{}{"hit"},
+	}, view)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"boss.name": {"author": "alice", "cursor": float64(3)},
+		"log.0":     {"author": "bob"},
+	}, annotations)
+
+	// 복제되는 주석만 인코딩
+	data, err := doc.MarshalBinary()
+	assert.NoError(t, err)
+	decoded := NewDocument(common.NewSessionID())
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, map[string]interface{}{"author": "alice"}, decoded.Annotations(name.ID()))
+
+	jsonData, err := doc.MarshalJSON()
+	assert.NoError(t, err)
+	decoded = NewDocument(common.NewSessionID())
+	assert.NoError(t, decoded.UnmarshalJSON(jsonData))
+	assert.Equal(t, map[string]interface{}{"author": "alice"}, decoded.Annotations(name.ID()))
+
+	// 동시 쓰기는 LWW, 병합으로 전파
+	fork, err := doc.Fork(common.NewSessionID())
+	assert.NoError(t, err)
+	writeID, err := fork.SetReplicatedAnnotation(name.ID(), "author", "carol")
+	assert.NoError(t, err)
+	assert.NoError(t, fork.SetAnnotation(name.ID(), "cursor", float64(1)))
+	_, err = doc.MergeFrom(fork)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"author": "carol", "cursor": float64(3)}, doc.Annotations(name.ID()))
+
+	assert.False(t, doc.ApplyAnnotation(name.ID(), "author", "dave", common.LogicalTimestamp{SID: writeID.SID, Counter: writeID.Counter - 1}))
+	assert.True(t, doc.ApplyAnnotation(name.ID(), "author", nil, common.LogicalTimestamp{SID: writeID.SID, Counter: writeID.Counter + 1}))
+	_, ok = doc.GetAnnotation(name.ID(), "author")
+	assert.False(t, ok)
+
+	assert.NoError(t, doc.SetAnnotation(name.ID(), "cursor", nil))
+	assert.Empty(t, doc.Annotations(name.ID()))
+}
+
+type countingLoader struct {
+	loader NodeLoader
+	loaded []common.LogicalTimestamp
+}
+
+func (l *countingLoader) LoadNode(id common.LogicalTimestamp) (Node, error) {
+	l.loaded = append(l.loaded, id)
+	return l.loader.LoadNode(id)
+}
+
+func TestLoadSubtree(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := NewDocument(sid)
+	newObject := func() *LWWObjectNode {
+		obj := NewLWWObjectNode(doc.NextTimestamp())
+		doc.AddNode(obj)
+		return obj
+	}
+	newConstant := func(value interface{}) *ConstantNode {
+		node := NewConstantNode(doc.NextTimestamp(), value)
+		doc.AddNode(node)
+		return node
+	}
+
+	// {"players": {"alice": {"inventory": ["sword", "shield"]}, "bob": {"gold": 5}}, "alliance": "red"}
+	root := newObject()
+	players := newObject()
+	alice := newObject()
+	bob := newObject()
+	inventory := NewRGAArrayNode(doc.NextTimestamp())
+	doc.AddNode(inventory)
+	sword := newConstant("sword")
+	shield := newConstant("shield")
+	inventory.Insert(common.RootID, doc.NextTimestamp(), sword.ID())
+	inventory.Insert(inventory.NodeElements[0].NodeId, doc.NextTimestamp(), shield.ID())
+	alliance := NewRGAStringNode(doc.NextTimestamp())
+	doc.AddNode(alliance)
+	alliance.Insert(common.RootID, doc.NextTimestamp(), "red")
+
+	root.Set("players", players.ID(), players)
+	root.Set("alliance", alliance.ID(), alliance)
+	players.Set("alice", alice.ID(), alice)
+	players.Set("bob", bob.ID(), bob)
+	alice.Set("inventory", inventory.ID(), inventory)
+	gold := newConstant(float64(5))
+	bob.Set("gold", gold.ID(), gold)
+	rootValue := newConstant(root.ID())
+	doc.Root().(*RootNode).NodeValue = rootValue
+
+	snapshot, err := doc.MarshalBinary()
+	assert.NoError(t, err)
+	snapshotLoader, err := NewSnapshotLoader(snapshot)
+	assert.NoError(t, err)
+	loader := &countingLoader{loader: snapshotLoader}
+
+	partial := NewDocument(common.NewSessionID())
+	node, err := partial.LoadSubtree("players.alice", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID(), node.ID())
+
+	// 경로와 하위 트리만 로드
+	loadedInventory, err := partial.GetNode(inventory.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{sword.ID(), shield.ID()}, loadedInventory.Value())
+	loadedSword, err := partial.GetNode(sword.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, "sword", loadedSword.Value())
+	assert.Len(t, loader.loaded, 7)
+
+	partialPlayers := partial.index[players.ID()].(*LWWObjectNode)
+	assert.IsType(t, &LazyNode{}, partialPlayers.Get("bob"))
+	assert.IsType(t, &LazyNode{}, partial.index[root.ID()].(*LWWObjectNode).Get("alliance"))
+	assert.Same(t, partial.index[alice.ID()], partialPlayers.Get("alice"))
+
+	// 나머지 노드는 조회할 때 로드
+	loadedBob, err := partial.GetNode(bob.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"gold": float64(5)}, loadedBob.Value())
+	assert.Same(t, loadedBob, partialPlayers.Get("bob"))
+	assert.Len(t, loader.loaded, 8)
+
+	// 로드한 노드는 편집해도 스냅샷에 영향 없음
+	loadedBob.(*LWWObjectNode).Delete("gold", partial.NextTimestamp())
+	assert.Equal(t, map[string]interface{}{"gold": float64(5)}, bob.Value())
+
+	// 전체 로드 후에는 원본과 같음
+	_, err = partial.LoadSubtree("", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "red", partial.index[alliance.ID()].Value())
+
+	_, err = partial.LoadSubtree("players.carol", loader)
+	assert.Error(t, err)
+	_, err = partial.GetNode(common.LogicalTimestamp{SID: sid, Counter: 999})
+	assert.Error(t, err)
+}
@@ -0,0 +1,378 @@
+package crdt
+
+import (
+	"unicode/utf8"
+
+	"tictactoe/luvjson/common"
+)
+
+// StabilityFrontier maps each session to the greatest counter of its operations
+// that are causally stable: every replica has applied them, and every operation
+// still to be delivered was generated after them.
+type StabilityFrontier map[common.SessionID]uint64
+
+// Covers reports whether the operation with the specified ID is stable.
+func (f StabilityFrontier) Covers(id common.LogicalTimestamp) bool {
+	return id.Counter <= f[id.SID]
+}
+
+// coversClock reports whether every operation counted by a document clock is stable.
+func (f StabilityFrontier) coversClock(clock map[string]uint64) bool {
+	counters := make(map[string]uint64, len(f))
+	for sid, counter := range f {
+		counters[sid.String()] = counter
+	}
+	for sid, counter := range clock {
+		if counter > counters[sid] {
+			return false
+		}
+	}
+	return true
+}
+
+// GCStats reports the result of a garbage collection.
+type GCStats struct {
+	// Tombstones is the number of deleted characters, elements and list slots removed
+	Tombstones int
+	// Nodes is the number of unreachable nodes removed from the index
+	Nodes int
+	// Superseded is the number of superseded register writes forgotten
+	Superseded int
+	// Pending is the number of garbage items waiting for the frontier to advance
+	Pending int
+}
+
+// gcKind is the kind of a garbage item.
+type gcKind int
+
+const (
+	// gcNode is a node that is not reachable from the root
+	gcNode gcKind = iota
+	// gcElement is a run of deleted elements of a sequence node
+	gcElement
+	// gcSlot is a list slot that no longer displays its element
+	gcSlot
+)
+
+// gcTarget identifies a garbage item.
+type gcTarget struct {
+	kind gcKind
+	node common.LogicalTimestamp
+	elem common.LogicalTimestamp
+	span uint64
+}
+
+// gcEpoch holds the garbage found by a collection together with the document
+// clock at that time. The operations that made the items garbage are covered by
+// the clock, so the items can be removed once the frontier covers it.
+type gcEpoch struct {
+	clock   map[string]uint64
+	targets []gcTarget
+}
+
+// garbageCollector keeps the garbage that is not yet stable between collections.
+type garbageCollector struct {
+	epochs []gcEpoch
+	marked map[gcTarget]bool
+}
+
+// CollectGarbage physically removes garbage whose removal is stable under frontier:
+// deleted characters and elements of strings, arrays, binaries and lists, list slots
+// left behind by moves, nodes that are no longer reachable from the root, and
+// superseded register writes.
+//
+// The operation that made an item garbage is not recorded, so garbage found by a
+// collection is only removed by a later collection whose frontier covers the
+// document clock at the time it was found. A document whose clock is already
+// covered by the frontier is collected at once. Nodes must be attached to the
+// document by the patch that creates them, since unattached nodes are garbage.
+func (d *Document) CollectGarbage(frontier StabilityFrontier) GCStats {
+	if d.gc == nil {
+		d.gc = &garbageCollector{marked: make(map[gcTarget]bool)}
+	}
+
+	var stats GCStats
+	reachable := d.reachableNodes()
+
+	// 다시 연결된 노드는 표시 해제 (이전 epoch의 항목은 제거 시 무시됨)
+	for target := range d.gc.marked {
+		if target.kind == gcNode && reachable[target.node] {
+			delete(d.gc.marked, target)
+		}
+	}
+
+	var found []gcTarget
+	for _, target := range d.findGarbage(reachable) {
+		if !d.gc.marked[target] {
+			d.gc.marked[target] = true
+			found = append(found, target)
+		}
+	}
+	if len(found) > 0 {
+		clock := make(map[string]uint64, len(d.clock))
+		for sid, counter := range d.clock {
+			clock[sid] = counter
+		}
+		d.gc.epochs = append(d.gc.epochs, gcEpoch{clock: clock, targets: found})
+	}
+
+	// 시계는 단조 증가하므로 앞쪽 epoch부터 안정화됨
+	elements := make(map[common.LogicalTimestamp]map[common.LogicalTimestamp]bool)
+	slots := make(map[common.LogicalTimestamp]map[common.LogicalTimestamp]bool)
+	stable := 0
+	for ; stable < len(d.gc.epochs) && frontier.coversClock(d.gc.epochs[stable].clock); stable++ {
+		for _, target := range d.gc.epochs[stable].targets {
+			if !d.gc.marked[target] {
+				continue
+			}
+			delete(d.gc.marked, target)
+
+			switch target.kind {
+			case gcNode:
+				delete(d.index, target.node)
+				delete(d.annotations, target.node)
+				stats.Nodes++
+			case gcElement:
+				addGCIDs(elements, target)
+			case gcSlot:
+				addGCIDs(slots, target)
+			}
+		}
+	}
+	d.gc.epochs = d.gc.epochs[stable:]
+
+	for id, node := range d.index {
+		if len(elements[id]) > 0 || len(slots[id]) > 0 {
+			stats.Tombstones += purgeTombstones(node, elements[id], slots[id])
+		}
+		if reg, ok := node.(*MVRegisterNode); ok && reachable[id] {
+			stats.Superseded += reg.forgetSuperseded(frontier)
+		}
+	}
+
+	for _, epoch := range d.gc.epochs {
+		stats.Pending += len(epoch.targets)
+	}
+	return stats
+}
+
+// addGCIDs adds the IDs covered by a target to the set of its node.
+func addGCIDs(sets map[common.LogicalTimestamp]map[common.LogicalTimestamp]bool, target gcTarget) {
+	ids, ok := sets[target.node]
+	if !ok {
+		ids = make(map[common.LogicalTimestamp]bool)
+		sets[target.node] = ids
+	}
+	for i := uint64(0); i < target.span; i++ {
+		ids[common.LogicalTimestamp{SID: target.elem.SID, Counter: target.elem.Counter + i}] = true
+	}
+}
+
+// reachableNodes returns the IDs of the nodes reachable from the root through
+// visible values.
+func (d *Document) reachableNodes() map[common.LogicalTimestamp]bool {
+	reachable := map[common.LogicalTimestamp]bool{common.RootID: true}
+	visited := make(map[Node]bool)
+
+	var visit func(node Node)
+	visitValue := func(value interface{}) {
+		switch v := value.(type) {
+		case Node:
+			visit(v)
+		case common.LogicalTimestamp:
+			if node, ok := d.index[v]; ok {
+				visit(node)
+			}
+		}
+	}
+
+	visit = func(node Node) {
+		if node == nil || visited[node] {
+			return
+		}
+		visited[node] = true
+		reachable[node.ID()] = true
+
+		switch n := node.(type) {
+		case *RootNode:
+			visit(n.NodeValue)
+		case *LWWValueNode:
+			visit(n.NodeValue)
+		case *ConstantNode:
+			visitValue(n.NodeValue)
+		case *LWWObjectNode:
+			for _, field := range n.NodeFields {
+				visit(field.NodeValue)
+			}
+		case *LWWVectorNode:
+			for _, field := range n.NodeFields {
+				visit(field.NodeValue)
+			}
+		case *RGAArrayNode:
+			for _, elem := range n.NodeElements {
+				if !elem.NodeDeleted {
+					visitValue(elem.NodeValue)
+				}
+			}
+		case *MovableListNode:
+			for _, elem := range n.Elements() {
+				visitValue(elem.ElementValue)
+			}
+		case *MVRegisterNode:
+			for _, entry := range n.NodeEntries {
+				visitValue(entry.Value)
+			}
+		}
+	}
+
+	visit(d.root)
+	return reachable
+}
+
+// findGarbage returns the unreachable nodes and the tombstones of reachable nodes.
+func (d *Document) findGarbage(reachable map[common.LogicalTimestamp]bool) []gcTarget {
+	var targets []gcTarget
+	for id, node := range d.index {
+		if !reachable[id] {
+			targets = append(targets, gcTarget{kind: gcNode, node: id})
+			continue
+		}
+
+		switch n := node.(type) {
+		case *RGAStringNode:
+			for _, elem := range n.NodeElements {
+				if elem.NodeDeleted {
+					span := uint64(utf8.RuneCountInString(chunkText(elem)))
+					targets = append(targets, gcTarget{kind: gcElement, node: id, elem: elem.NodeId, span: span})
+				}
+			}
+		case *RGAArrayNode:
+			for _, elem := range n.NodeElements {
+				if elem.NodeDeleted {
+					targets = append(targets, gcTarget{kind: gcElement, node: id, elem: elem.NodeId, span: 1})
+				}
+			}
+		case *RGABinaryNode:
+			for _, elem := range n.NodeElements {
+				if elem.NodeDeleted {
+					targets = append(targets, gcTarget{kind: gcElement, node: id, elem: elem.NodeId, span: 1})
+				}
+			}
+		case *MovableListNode:
+			for elemID, elem := range n.NodeItems {
+				if elem.ElementDeleted {
+					targets = append(targets, gcTarget{kind: gcElement, node: id, elem: elemID, span: 1})
+				}
+			}
+			for _, slot := range n.NodeSlots {
+				if _, visible := n.visibleAt(slot); !visible {
+					targets = append(targets, gcTarget{kind: gcSlot, node: id, elem: slot.NodeId, span: 1})
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// purgeTombstones removes the deleted elements and hidden slots of a node whose
+// IDs are in the given sets. It returns the number of removed items.
+func purgeTombstones(node Node, elements, slots map[common.LogicalTimestamp]bool) int {
+	switch n := node.(type) {
+	case *RGAStringNode:
+		return n.purge(elements)
+	case *RGAArrayNode:
+		kept := make([]*RGAElement, 0, len(n.NodeElements))
+		for _, elem := range n.NodeElements {
+			if !elem.NodeDeleted || !elements[elem.NodeId] {
+				kept = append(kept, elem)
+			}
+		}
+		removed := len(n.NodeElements) - len(kept)
+		n.NodeElements = kept
+		return removed
+	case *RGABinaryNode:
+		kept := make([]*RGABinaryElement, 0, len(n.NodeElements))
+		for _, elem := range n.NodeElements {
+			if !elem.NodeDeleted || !elements[elem.NodeId] {
+				kept = append(kept, elem)
+			}
+		}
+		removed := len(n.NodeElements) - len(kept)
+		n.NodeElements = kept
+		return removed
+	case *MovableListNode:
+		return n.purge(elements, slots)
+	default:
+		return 0
+	}
+}
+
+// purge removes the deleted characters whose IDs are in ids and merges the
+// remaining chunks. It returns the number of removed characters.
+func (n *RGAStringNode) purge(ids map[common.LogicalTimestamp]bool) int {
+	removed := 0
+	kept := make([]*RGAElement, 0, len(n.NodeElements))
+	for _, elem := range n.NodeElements {
+		if !elem.NodeDeleted {
+			kept = append(kept, elem)
+			continue
+		}
+
+		i := uint64(0)
+		for _, c := range chunkText(elem) {
+			id := common.LogicalTimestamp{SID: elem.NodeId.SID, Counter: elem.NodeId.Counter + i}
+			i++
+			if ids[id] {
+				removed++
+				continue
+			}
+			kept = append(kept, &RGAElement{NodeId: id, NodeValue: string(c), NodeDeleted: true})
+		}
+	}
+
+	n.NodeElements = kept
+	n.Compact()
+	return removed
+}
+
+// purge removes the deleted elements whose IDs are in elements, the hidden slots
+// whose IDs are in slots and the slots of removed elements. It returns the number
+// of removed elements and slots.
+func (n *MovableListNode) purge(elements, slots map[common.LogicalTimestamp]bool) int {
+	removed := 0
+	for elemID, elem := range n.NodeItems {
+		if elem.ElementDeleted && elements[elemID] {
+			delete(n.NodeItems, elemID)
+			removed++
+		}
+	}
+
+	kept := make([]*RGAElement, 0, len(n.NodeSlots))
+	for _, slot := range n.NodeSlots {
+		elemID, _ := slot.NodeValue.(common.LogicalTimestamp)
+		if _, exists := n.NodeItems[elemID]; !exists {
+			removed++
+			continue
+		}
+		if _, visible := n.visibleAt(slot); !visible && slots[slot.NodeId] {
+			removed++
+			continue
+		}
+		kept = append(kept, slot)
+	}
+	n.NodeSlots = kept
+	return removed
+}
+
+// forgetSuperseded forgets the superseded writes that are stable, since they can
+// no longer be delivered. It returns the number of forgotten writes.
+func (n *MVRegisterNode) forgetSuperseded(frontier StabilityFrontier) int {
+	forgotten := 0
+	for ts := range n.NodeSuperseded {
+		if frontier.Covers(ts) {
+			delete(n.NodeSuperseded, ts)
+			forgotten++
+		}
+	}
+	return forgotten
+}
@@ -0,0 +1,401 @@
+package crdt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tictactoe/luvjson/common"
+)
+
+// NodeLoader loads the nodes of a partially loaded document, e.g. from storage.
+type NodeLoader interface {
+	// LoadNode returns the node with the specified ID, or common.ErrNodeNotFound.
+	// Child nodes that the node holds directly may be returned as LazyNode
+	// placeholders; they are loaded when they are needed.
+	LoadNode(id common.LogicalTimestamp) (Node, error)
+}
+
+// LazyNode is a placeholder for a node of a partially loaded document that has
+// not been loaded yet. Document.GetNode loads the node it stands for.
+//
+// A document holding placeholders cannot be encoded; load it completely with
+// LoadSubtree("") first.
+type LazyNode struct {
+	NodeId   common.LogicalTimestamp
+	NodeKind common.NodeType
+}
+
+// NewLazyNode creates a placeholder for the node id of type nodeType.
+func NewLazyNode(id common.LogicalTimestamp, nodeType common.NodeType) *LazyNode {
+	return &LazyNode{NodeId: id, NodeKind: nodeType}
+}
+
+// ID returns the ID of the node the placeholder stands for.
+func (n *LazyNode) ID() common.LogicalTimestamp {
+	return n.NodeId
+}
+
+// Type returns the type of the node the placeholder stands for.
+func (n *LazyNode) Type() common.NodeType {
+	return n.NodeKind
+}
+
+// Value returns nil, since the node is not loaded.
+func (n *LazyNode) Value() interface{} {
+	return nil
+}
+
+// MarshalJSON returns an error, since the node is not loaded.
+func (n *LazyNode) MarshalJSON() ([]byte, error) {
+	return nil, common.ErrInvalidOperation{Message: fmt.Sprintf("node %s is not loaded", n.NodeId)}
+}
+
+// UnmarshalJSON returns an error, since placeholders are not encoded.
+func (n *LazyNode) UnmarshalJSON(data []byte) error {
+	return common.ErrInvalidOperation{Message: "placeholder nodes cannot be decoded"}
+}
+
+// LoadSubtree loads the node at path and every node below it with loader, and
+// returns it. path is a dot-separated list of object keys and indices; "" is the
+// whole document.
+//
+// The first call loads the root into an empty document. Nodes along the path are
+// loaded without their other children, which are left as LazyNode placeholders and
+// loaded by GetNode when they are looked up, so a client editing one player's
+// inventory does not load the whole document. The document keeps loader for that.
+func (d *Document) LoadSubtree(path string, loader NodeLoader) (Node, error) {
+	if d.loader == nil {
+		root, err := loader.LoadNode(common.RootID)
+		if err != nil {
+			return nil, err
+		}
+		d.root = root
+		d.index[common.RootID] = root
+		d.loader = loader
+		d.registerPlaceholders(root)
+	}
+	d.loader = loader
+
+	node, err := d.resolveLazy(d.root)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			child, err := d.childAt(node, key)
+			if err != nil {
+				return nil, err
+			}
+			if child == nil {
+				return nil, common.ErrInvalidOperation{Message: fmt.Sprintf("path %q not found", path)}
+			}
+			if node, err = d.resolveLazy(child); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := d.loadAll(node, make(map[Node]bool)); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// hydrate loads the node id with the loader of the document and puts it in
+// place of its placeholder.
+func (d *Document) hydrate(id common.LogicalTimestamp) (Node, error) {
+	node, err := d.loader.LoadNode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	d.index[id] = node
+	d.UpdateClock(id)
+	if parent, ok := d.lazyParents[id]; ok {
+		replaceLazyChild(parent, id, node)
+		delete(d.lazyParents, id)
+	}
+	d.registerPlaceholders(node)
+	return node, nil
+}
+
+// registerPlaceholders indexes the placeholder children of a loaded node so that
+// GetNode finds them.
+func (d *Document) registerPlaceholders(node Node) {
+	register := func(child Node) {
+		lazy, ok := child.(*LazyNode)
+		if !ok {
+			return
+		}
+		if existing, loaded := d.index[lazy.NodeId]; loaded {
+			if _, stillLazy := existing.(*LazyNode); !stillLazy {
+				// 이미 로드된 노드로 교체
+				replaceLazyChild(node, lazy.NodeId, existing)
+				return
+			}
+		}
+		if d.lazyParents == nil {
+			d.lazyParents = make(map[common.LogicalTimestamp]Node)
+		}
+		d.index[lazy.NodeId] = lazy
+		d.lazyParents[lazy.NodeId] = node
+	}
+
+	switch n := node.(type) {
+	case *RootNode:
+		register(n.NodeValue)
+	case *LWWValueNode:
+		register(n.NodeValue)
+	case *LWWObjectNode:
+		for _, field := range n.NodeFields {
+			register(field.NodeValue)
+		}
+	case *LWWVectorNode:
+		for _, field := range n.NodeFields {
+			register(field.NodeValue)
+		}
+	}
+}
+
+// replaceLazyChild replaces the placeholder child id of parent with node.
+func replaceLazyChild(parent Node, id common.LogicalTimestamp, node Node) {
+	isPlaceholder := func(child Node) bool {
+		lazy, ok := child.(*LazyNode)
+		return ok && lazy.NodeId == id
+	}
+
+	switch p := parent.(type) {
+	case *RootNode:
+		if isPlaceholder(p.NodeValue) {
+			p.NodeValue = node
+		}
+	case *LWWValueNode:
+		if isPlaceholder(p.NodeValue) {
+			p.NodeValue = node
+		}
+	case *LWWObjectNode:
+		for _, field := range p.NodeFields {
+			if isPlaceholder(field.NodeValue) {
+				field.NodeValue = node
+			}
+		}
+	case *LWWVectorNode:
+		for _, field := range p.NodeFields {
+			if isPlaceholder(field.NodeValue) {
+				field.NodeValue = node
+			}
+		}
+	}
+}
+
+// resolveLazy returns the node a value stands for, loading placeholders and
+// following the root, value registers and constants holding node IDs.
+func (d *Document) resolveLazy(node Node) (Node, error) {
+	for {
+		switch n := node.(type) {
+		case *LazyNode:
+			loaded, err := d.GetNode(n.NodeId)
+			if err != nil {
+				return nil, err
+			}
+			node = loaded
+		case *RootNode:
+			node = n.NodeValue
+		case *LWWValueNode:
+			node = n.NodeValue
+		case *ConstantNode:
+			id, ok := n.NodeValue.(common.LogicalTimestamp)
+			if !ok {
+				return n, nil
+			}
+			loaded, err := d.GetNode(id)
+			if err != nil {
+				return nil, err
+			}
+			node = loaded
+		default:
+			return node, nil
+		}
+	}
+}
+
+// childAt returns the child of node at a path segment, or nil if there is none.
+func (d *Document) childAt(node Node, key string) (Node, error) {
+	if obj, ok := node.(*LWWObjectNode); ok {
+		return obj.Get(key), nil
+	}
+
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return nil, common.ErrInvalidOperation{Message: fmt.Sprintf("invalid path segment %q for node %s", key, node.ID())}
+	}
+
+	switch n := node.(type) {
+	case *LWWVectorNode:
+		return n.Get(index), nil
+	case *RGAArrayNode:
+		id, err := n.Get(index)
+		if err != nil {
+			return nil, nil
+		}
+		return d.GetNode(id)
+	case *MovableListNode:
+		elem, err := n.Get(index)
+		if err != nil {
+			return nil, nil
+		}
+		if id, ok := elem.ElementValue.(common.LogicalTimestamp); ok {
+			return d.GetNode(id)
+		}
+		return nil, nil
+	default:
+		return nil, nil
+	}
+}
+
+// loadAll loads every node below node.
+func (d *Document) loadAll(node Node, visited map[Node]bool) error {
+	if node == nil || visited[node] {
+		return nil
+	}
+	visited[node] = true
+
+	loadChild := func(child Node) error {
+		if lazy, ok := child.(*LazyNode); ok {
+			loaded, err := d.GetNode(lazy.NodeId)
+			if err != nil {
+				return err
+			}
+			child = loaded
+		}
+		return d.loadAll(child, visited)
+	}
+	loadValue := func(value interface{}) error {
+		id, ok := value.(common.LogicalTimestamp)
+		if !ok {
+			return nil
+		}
+		child, err := d.GetNode(id)
+		if errors.As(err, &common.ErrNodeNotFound{}) {
+			// 노드를 가리키지 않는 타임스탬프 값
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return d.loadAll(child, visited)
+	}
+
+	switch n := node.(type) {
+	case *RootNode:
+		return loadChild(n.NodeValue)
+	case *LWWValueNode:
+		return loadChild(n.NodeValue)
+	case *ConstantNode:
+		return loadValue(n.NodeValue)
+	case *LWWObjectNode:
+		for _, key := range n.Keys() {
+			if err := loadChild(n.NodeFields[key].NodeValue); err != nil {
+				return err
+			}
+		}
+	case *LWWVectorNode:
+		for _, index := range n.Indices() {
+			if err := loadChild(n.NodeFields[index].NodeValue); err != nil {
+				return err
+			}
+		}
+	case *RGAArrayNode:
+		for _, elem := range n.NodeElements {
+			if err := loadValue(elem.NodeValue); err != nil {
+				return err
+			}
+		}
+	case *MovableListNode:
+		for _, elem := range n.NodeItems {
+			if err := loadValue(elem.ElementValue); err != nil {
+				return err
+			}
+		}
+	case *MVRegisterNode:
+		for _, entry := range n.NodeEntries {
+			if err := loadValue(entry.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SnapshotLoader is a NodeLoader that serves the nodes of a binary snapshot
+// written by Document.MarshalBinary, e.g. one read by a persistence adapter.
+// Child nodes other than constants are returned as placeholders.
+type SnapshotLoader struct {
+	doc *Document
+}
+
+// NewSnapshotLoader creates a loader for a binary snapshot.
+func NewSnapshotLoader(snapshot []byte) (*SnapshotLoader, error) {
+	doc := NewDocument(common.NilSessionID)
+	if err := doc.UnmarshalBinary(snapshot); err != nil {
+		return nil, err
+	}
+	return &SnapshotLoader{doc: doc}, nil
+}
+
+// LoadNode returns a copy of the node id of the snapshot.
+func (l *SnapshotLoader) LoadNode(id common.LogicalTimestamp) (Node, error) {
+	node, err := l.doc.GetNode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholder := func(child Node) Node {
+		switch c := child.(type) {
+		case nil:
+			return nil
+		case *ConstantNode:
+			return NewConstantNode(c.NodeId, c.NodeValue)
+		default:
+			return NewLazyNode(child.ID(), child.Type())
+		}
+	}
+
+	switch n := node.(type) {
+	case *RootNode:
+		return &RootNode{LWWValueNode: LWWValueNode{NodeId: n.NodeId, NodeTimestamp: n.NodeTimestamp, NodeValue: placeholder(n.NodeValue)}}, nil
+	case *LWWValueNode:
+		return NewLWWValueNode(n.NodeId, n.NodeTimestamp, placeholder(n.NodeValue)), nil
+	case *LWWObjectNode:
+		obj := NewLWWObjectNode(n.NodeId)
+		for key, field := range n.NodeFields {
+			obj.NodeFields[key] = &LWWObjectField{NodeTimestamp: field.NodeTimestamp, NodeValue: placeholder(field.NodeValue)}
+		}
+		return obj, nil
+	case *LWWVectorNode:
+		vec := NewLWWVectorNode(n.NodeId)
+		for index, field := range n.NodeFields {
+			vec.NodeFields[index] = &LWWVectorField{NodeTimestamp: field.NodeTimestamp, NodeValue: placeholder(field.NodeValue)}
+		}
+		return vec, nil
+	default:
+		return copyNode(node)
+	}
+}
+
+// copyNode returns a deep copy of a node.
+func copyNode(node Node) (Node, error) {
+	enc := newBinaryEncoder()
+	if err := enc.node(node); err != nil {
+		return nil, err
+	}
+	dec := &binaryDecoder{
+		r:        bytes.NewReader(enc.buf.Bytes()),
+		sessions: enc.sessionList,
+		strings:  enc.stringList,
+	}
+	return dec.node()
+}
@@ -0,0 +1,304 @@
+package crdt
+
+import (
+	"encoding/json"
+	"tictactoe/luvjson/common"
+)
+
+// MovableListNode represents a list whose elements can be moved without losing
+// their identity.
+//
+// Elements are identified by the ID of the operation that inserted them. Their
+// order is given by an RGA sequence of slots: inserting an element creates a slot,
+// and moving an element creates a new slot at the destination and points the
+// element at it. The slot an element points to is a last-writer-wins register, so
+// concurrent moves of the same element place it exactly once, and edits of the
+// element's value are kept regardless of where it was moved.
+type MovableListNode struct {
+	NodeId    common.LogicalTimestamp                         `json:"id"`
+	NodeSlots []*RGAElement                                   `json:"slots,omitempty"`
+	NodeItems map[common.LogicalTimestamp]*MovableListElement `json:"elements,omitempty"`
+}
+
+// MovableListElement is an element of a MovableListNode.
+type MovableListElement struct {
+	// ElementId is the ID of the operation that inserted the element
+	ElementId common.LogicalTimestamp `json:"id"`
+	// ElementValue is the value of the element, usually the ID of a node
+	ElementValue interface{} `json:"value"`
+	// ElementSlot is the slot the element is displayed at
+	ElementSlot common.LogicalTimestamp `json:"slot"`
+	// ElementDeleted reports whether the element was deleted
+	ElementDeleted bool `json:"deleted"`
+}
+
+// NewMovableListNode creates a new movable list node.
+func NewMovableListNode(id common.LogicalTimestamp) *MovableListNode {
+	return &MovableListNode{
+		NodeId:    id,
+		NodeSlots: make([]*RGAElement, 0),
+		NodeItems: make(map[common.LogicalTimestamp]*MovableListElement),
+	}
+}
+
+// ID returns the unique identifier of the node.
+func (n *MovableListNode) ID() common.LogicalTimestamp {
+	return n.NodeId
+}
+
+// Type returns the type of the node.
+func (n *MovableListNode) Type() common.NodeType {
+	return common.NodeTypeList
+}
+
+// Value returns the values of the visible elements in order.
+func (n *MovableListNode) Value() interface{} {
+	result := make([]interface{}, 0, len(n.NodeItems))
+	for _, elem := range n.Elements() {
+		result = append(result, elem.ElementValue)
+	}
+	return result
+}
+
+// Length returns the number of visible elements.
+func (n *MovableListNode) Length() int {
+	return len(n.Elements())
+}
+
+// Elements returns the visible elements in order.
+func (n *MovableListNode) Elements() []*MovableListElement {
+	result := make([]*MovableListElement, 0, len(n.NodeItems))
+	for _, slot := range n.NodeSlots {
+		if elem, ok := n.visibleAt(slot); ok {
+			result = append(result, elem)
+		}
+	}
+	return result
+}
+
+// Get returns the visible element at the specified index.
+func (n *MovableListNode) Get(index int) (*MovableListElement, error) {
+	elements := n.Elements()
+	if index < 0 || index >= len(elements) {
+		return nil, common.ErrInvalidOperation{Message: "index out of bounds"}
+	}
+	return elements[index], nil
+}
+
+// SlotAt returns the ID of the slot displaying the visible element at index.
+// Inserts and moves after that element reference this slot.
+func (n *MovableListNode) SlotAt(index int) (common.LogicalTimestamp, error) {
+	elem, err := n.Get(index)
+	if err != nil {
+		return common.LogicalTimestamp{}, err
+	}
+	return elem.ElementSlot, nil
+}
+
+// Insert inserts an element with the given value after the slot afterID
+// (common.RootID inserts at the beginning). The element and its slot get the ID id.
+func (n *MovableListNode) Insert(afterID common.LogicalTimestamp, id common.LogicalTimestamp, value interface{}) bool {
+	if _, exists := n.NodeItems[id]; exists {
+		return false
+	}
+	if !n.insertSlot(afterID, id, id) {
+		return false
+	}
+
+	n.NodeItems[id] = &MovableListElement{
+		ElementId:    id,
+		ElementValue: value,
+		ElementSlot:  id,
+	}
+	return true
+}
+
+// Move moves the element elemID after the slot afterID (common.RootID moves it to
+// the beginning). The new slot gets the ID id. If the element was moved
+// concurrently, the move with the greater ID wins.
+func (n *MovableListNode) Move(elemID, afterID, id common.LogicalTimestamp) bool {
+	elem, ok := n.NodeItems[elemID]
+	if !ok {
+		return false
+	}
+	if !n.insertSlot(afterID, id, elemID) {
+		return false
+	}
+
+	// 슬롯 지정은 LWW 레지스터
+	if id.Compare(elem.ElementSlot) > 0 {
+		elem.ElementSlot = id
+	}
+	return true
+}
+
+// Set replaces the value of the element elemID.
+func (n *MovableListNode) Set(elemID common.LogicalTimestamp, value interface{}) bool {
+	elem, ok := n.NodeItems[elemID]
+	if !ok {
+		return false
+	}
+	elem.ElementValue = value
+	return true
+}
+
+// Delete marks the element elemID as deleted wherever it was moved.
+func (n *MovableListNode) Delete(elemID common.LogicalTimestamp) bool {
+	elem, ok := n.NodeItems[elemID]
+	if !ok {
+		return false
+	}
+	elem.ElementDeleted = true
+	return true
+}
+
+// insertSlot inserts a slot for elemID after the slot afterID.
+func (n *MovableListNode) insertSlot(afterID, id, elemID common.LogicalTimestamp) bool {
+	pos := -1
+	for i, slot := range n.NodeSlots {
+		if slot.NodeId.Compare(id) == 0 {
+			// 이미 적용된 슬롯
+			return false
+		}
+		if slot.NodeId.Compare(afterID) == 0 {
+			pos = i
+		}
+	}
+
+	if pos == -1 && afterID.Compare(common.RootID) != 0 {
+		return false
+	}
+
+	// Skip slots inserted concurrently at the same position with a greater ID
+	// so that all replicas order concurrent inserts the same way
+	insertPos := pos + 1
+	for insertPos < len(n.NodeSlots) && rgaIDGreater(n.NodeSlots[insertPos].NodeId, id) {
+		insertPos++
+	}
+
+	slot := &RGAElement{NodeId: id, NodeValue: elemID}
+	n.NodeSlots = append(n.NodeSlots[:insertPos], append([]*RGAElement{slot}, n.NodeSlots[insertPos:]...)...)
+	return true
+}
+
+// visibleAt returns the element displayed at a slot, if any.
+func (n *MovableListNode) visibleAt(slot *RGAElement) (*MovableListElement, bool) {
+	elemID, ok := slot.NodeValue.(common.LogicalTimestamp)
+	if !ok {
+		return nil, false
+	}
+	elem, ok := n.NodeItems[elemID]
+	if !ok || elem.ElementDeleted || elem.ElementSlot != slot.NodeId {
+		return nil, false
+	}
+	return elem, true
+}
+
+// MarshalJSON returns a JSON representation of the node.
+func (n *MovableListNode) MarshalJSON() ([]byte, error) {
+	type jsonSlot struct {
+		ID      common.LogicalTimestamp `json:"id"`
+		Element common.LogicalTimestamp `json:"element"`
+	}
+
+	type jsonNode struct {
+		Type     string                  `json:"type"`
+		ID       common.LogicalTimestamp `json:"id"`
+		Slots    []jsonSlot              `json:"slots,omitempty"`
+		Elements []*MovableListElement   `json:"elements,omitempty"`
+	}
+
+	node := jsonNode{
+		Type:  string(n.Type()),
+		ID:    n.NodeId,
+		Slots: make([]jsonSlot, 0, len(n.NodeSlots)),
+	}
+
+	// 요소는 최초 슬롯 순서로 기록해 출력을 결정적으로 유지
+	for _, slot := range n.NodeSlots {
+		elemID, _ := slot.NodeValue.(common.LogicalTimestamp)
+		node.Slots = append(node.Slots, jsonSlot{ID: slot.NodeId, Element: elemID})
+		if slot.NodeId == elemID {
+			if elem, ok := n.NodeItems[elemID]; ok {
+				node.Elements = append(node.Elements, elem)
+			}
+		}
+	}
+
+	return json.Marshal(node)
+}
+
+// UnmarshalJSON parses a JSON representation of the node.
+// Element values that are node IDs are decoded as common.LogicalTimestamp.
+func (n *MovableListNode) UnmarshalJSON(data []byte) error {
+	type jsonSlot struct {
+		ID      common.LogicalTimestamp `json:"id"`
+		Element common.LogicalTimestamp `json:"element"`
+	}
+
+	type jsonElement struct {
+		ID      common.LogicalTimestamp `json:"id"`
+		Value   json.RawMessage         `json:"value"`
+		Slot    common.LogicalTimestamp `json:"slot"`
+		Deleted bool                    `json:"deleted"`
+	}
+
+	type jsonNode struct {
+		Type     string                  `json:"type"`
+		ID       common.LogicalTimestamp `json:"id"`
+		Slots    []jsonSlot              `json:"slots,omitempty"`
+		Elements []jsonElement           `json:"elements,omitempty"`
+	}
+
+	var node jsonNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	if node.Type != string(common.NodeTypeList) {
+		return common.ErrInvalidNodeType{Type: node.Type}
+	}
+
+	n.NodeId = node.ID
+	n.NodeSlots = make([]*RGAElement, len(node.Slots))
+	n.NodeItems = make(map[common.LogicalTimestamp]*MovableListElement, len(node.Elements))
+
+	for i, slot := range node.Slots {
+		n.NodeSlots[i] = &RGAElement{NodeId: slot.ID, NodeValue: slot.Element}
+	}
+
+	for _, elem := range node.Elements {
+		value, err := decodeElementValue(elem.Value)
+		if err != nil {
+			return err
+		}
+		n.NodeItems[elem.ID] = &MovableListElement{
+			ElementId:      elem.ID,
+			ElementValue:   value,
+			ElementSlot:    elem.Slot,
+			ElementDeleted: elem.Deleted,
+		}
+	}
+
+	return nil
+}
+
+// decodeElementValue decodes a JSON element value, restoring node IDs.
+func decodeElementValue(data json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	if obj, ok := value.(map[string]interface{}); ok && len(obj) == 2 {
+		_, hasSID := obj["sid"]
+		_, hasCnt := obj["cnt"]
+		if hasSID && hasCnt {
+			var id common.LogicalTimestamp
+			if err := id.UnmarshalJSON(data); err == nil {
+				return id, nil
+			}
+		}
+	}
+	return value, nil
+}
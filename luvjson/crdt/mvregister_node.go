@@ -0,0 +1,184 @@
+package crdt
+
+import (
+	"encoding/json"
+	"sort"
+	"tictactoe/luvjson/common"
+)
+
+// MVRegisterNode represents a multi-value register.
+//
+// A write replaces the values its writer had observed. Writes made concurrently
+// by different sessions did not observe each other, so all of them are kept until
+// a later write resolves the conflict. Value returns the value with the greatest
+// timestamp; ConflictingValues returns all of them so the application can choose.
+type MVRegisterNode struct {
+	NodeId         common.LogicalTimestamp          `json:"id"`
+	NodeEntries    []*MVRegisterEntry               `json:"entries,omitempty"`
+	NodeSuperseded map[common.LogicalTimestamp]bool `json:"superseded,omitempty"`
+}
+
+// MVRegisterEntry is a value written to a multi-value register.
+type MVRegisterEntry struct {
+	// Timestamp is the ID of the write
+	Timestamp common.LogicalTimestamp `json:"id"`
+	// Value is the written value
+	Value interface{} `json:"value"`
+}
+
+// NewMVRegisterNode creates a new multi-value register node.
+func NewMVRegisterNode(id common.LogicalTimestamp) *MVRegisterNode {
+	return &MVRegisterNode{
+		NodeId:         id,
+		NodeEntries:    make([]*MVRegisterEntry, 0),
+		NodeSuperseded: make(map[common.LogicalTimestamp]bool),
+	}
+}
+
+// ID returns the unique identifier of the node.
+func (n *MVRegisterNode) ID() common.LogicalTimestamp {
+	return n.NodeId
+}
+
+// Type returns the type of the node.
+func (n *MVRegisterNode) Type() common.NodeType {
+	return common.NodeTypeMVReg
+}
+
+// Value returns the value with the greatest timestamp, or nil if nothing was written.
+func (n *MVRegisterNode) Value() interface{} {
+	if len(n.NodeEntries) == 0 {
+		return nil
+	}
+	return n.NodeEntries[len(n.NodeEntries)-1].Value
+}
+
+// ConflictingValues returns the concurrently written values ordered by timestamp.
+// It returns a single entry when there is no conflict.
+func (n *MVRegisterNode) ConflictingValues() []MVRegisterEntry {
+	entries := make([]MVRegisterEntry, len(n.NodeEntries))
+	for i, entry := range n.NodeEntries {
+		entries[i] = *entry
+	}
+	return entries
+}
+
+// HasConflict reports whether the register holds more than one value.
+func (n *MVRegisterNode) HasConflict() bool {
+	return len(n.NodeEntries) > 1
+}
+
+// Observed returns the timestamps of the current values.
+// A write that replaces all of them must pass these to Set.
+func (n *MVRegisterNode) Observed() []common.LogicalTimestamp {
+	observed := make([]common.LogicalTimestamp, len(n.NodeEntries))
+	for i, entry := range n.NodeEntries {
+		observed[i] = entry.Timestamp
+	}
+	return observed
+}
+
+// Set writes value with timestamp id, replacing the values whose timestamps are
+// in observed. Values written concurrently, i.e. not in observed, are kept.
+// It returns false if the write was already applied or has been replaced.
+func (n *MVRegisterNode) Set(id common.LogicalTimestamp, value interface{}, observed []common.LogicalTimestamp) bool {
+	if n.NodeSuperseded[id] {
+		return false
+	}
+	for _, entry := range n.NodeEntries {
+		if entry.Timestamp == id {
+			return false
+		}
+	}
+
+	// 관찰한 값은 이후에 도착하더라도 다시 나타나지 않도록 기록
+	for _, ts := range observed {
+		n.NodeSuperseded[ts] = true
+	}
+
+	entries := n.NodeEntries[:0]
+	for _, entry := range n.NodeEntries {
+		if !n.NodeSuperseded[entry.Timestamp] {
+			entries = append(entries, entry)
+		}
+	}
+	entries = append(entries, &MVRegisterEntry{Timestamp: id, Value: value})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Compare(entries[j].Timestamp) < 0
+	})
+	n.NodeEntries = entries
+
+	return true
+}
+
+// Assign writes value with timestamp id, replacing all current values.
+// It is used to resolve a conflict.
+func (n *MVRegisterNode) Assign(id common.LogicalTimestamp, value interface{}) bool {
+	return n.Set(id, value, n.Observed())
+}
+
+// MarshalJSON returns a JSON representation of the node.
+func (n *MVRegisterNode) MarshalJSON() ([]byte, error) {
+	type jsonNode struct {
+		Type       string                    `json:"type"`
+		ID         common.LogicalTimestamp   `json:"id"`
+		Entries    []*MVRegisterEntry        `json:"entries,omitempty"`
+		Superseded []common.LogicalTimestamp `json:"superseded,omitempty"`
+	}
+
+	node := jsonNode{
+		Type:    string(n.Type()),
+		ID:      n.NodeId,
+		Entries: n.NodeEntries,
+	}
+	for ts := range n.NodeSuperseded {
+		node.Superseded = append(node.Superseded, ts)
+	}
+	sort.Slice(node.Superseded, func(i, j int) bool {
+		return node.Superseded[i].Compare(node.Superseded[j]) < 0
+	})
+
+	return json.Marshal(node)
+}
+
+// UnmarshalJSON parses a JSON representation of the node.
+// Values that are node IDs are decoded as common.LogicalTimestamp.
+func (n *MVRegisterNode) UnmarshalJSON(data []byte) error {
+	type jsonEntry struct {
+		ID    common.LogicalTimestamp `json:"id"`
+		Value json.RawMessage         `json:"value"`
+	}
+
+	type jsonNode struct {
+		Type       string                    `json:"type"`
+		ID         common.LogicalTimestamp   `json:"id"`
+		Entries    []jsonEntry               `json:"entries,omitempty"`
+		Superseded []common.LogicalTimestamp `json:"superseded,omitempty"`
+	}
+
+	var node jsonNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	if node.Type != string(common.NodeTypeMVReg) {
+		return common.ErrInvalidNodeType{Type: node.Type}
+	}
+
+	n.NodeId = node.ID
+	n.NodeEntries = make([]*MVRegisterEntry, len(node.Entries))
+	n.NodeSuperseded = make(map[common.LogicalTimestamp]bool, len(node.Superseded))
+
+	for i, entry := range node.Entries {
+		value, err := decodeElementValue(entry.Value)
+		if err != nil {
+			return err
+		}
+		n.NodeEntries[i] = &MVRegisterEntry{Timestamp: entry.ID, Value: value}
+	}
+	for _, ts := range node.Superseded {
+		n.NodeSuperseded[ts] = true
+	}
+
+	return nil
+}
@@ -222,3 +222,239 @@ func TestRGAStringNode(t *testing.T) {
 	// assert.NoError(t, err)
 	// assert.Equal(t, node.ID(), newNode.ID())
 }
+
+// TestRGAStringNode_Chunks tests chunk splitting, merging and concurrent inserts
+func TestRGAStringNode_Chunks(t *testing.T) {
+	base := common.NewSessionID()
+	sidA := common.NewSessionID()
+	sidB := common.NewSessionID()
+
+	newReplica := func() *RGAStringNode {
+		node := NewRGAStringNode(common.LogicalTimestamp{SID: base, Counter: 1})
+		assert.True(t, node.Insert(common.RootID, common.LogicalTimestamp{SID: base, Counter: 2}, "Hello"))
+		return node
+	}
+
+	// Sequential typing extends the same chunk
+	node := newReplica()
+	assert.True(t, node.Insert(common.LogicalTimestamp{SID: base, Counter: 6}, common.LogicalTimestamp{SID: base, Counter: 7}, "!"))
+	assert.Equal(t, "Hello!", node.Value())
+	assert.Len(t, node.NodeElements, 1)
+
+	// Concurrent edits: A appends after "Hello", B inserts inside "Hello"
+	insertA := func(n *RGAStringNode) {
+		assert.True(t, n.Insert(common.LogicalTimestamp{SID: base, Counter: 6}, common.LogicalTimestamp{SID: sidA, Counter: 10}, " there"))
+	}
+	insertB := func(n *RGAStringNode) {
+		assert.True(t, n.Insert(common.LogicalTimestamp{SID: base, Counter: 4}, common.LogicalTimestamp{SID: sidB, Counter: 10}, "LL"))
+	}
+
+	replicaA := newReplica()
+	insertA(replicaA)
+	insertB(replicaA)
+
+	replicaB := newReplica()
+	insertB(replicaB)
+	insertA(replicaB)
+
+	assert.Equal(t, "HelLLlo there", replicaA.Value())
+	assert.Equal(t, replicaA.Value(), replicaB.Value())
+	assert.Equal(t, 13, replicaA.Length())
+
+	// Delete a range that spans several chunks
+	assert.True(t, replicaA.Delete(common.LogicalTimestamp{SID: sidB, Counter: 11}, common.LogicalTimestamp{SID: sidA, Counter: 10}))
+	assert.Equal(t, "HelLthere", replicaA.Value())
+	assert.False(t, replicaA.Delete(common.LogicalTimestamp{SID: sidA, Counter: 10}, common.LogicalTimestamp{SID: base, Counter: 2}))
+
+	// Character IDs are derived from chunk IDs
+	ids := replicaA.VisibleIDs()
+	assert.Len(t, ids, 9)
+	assert.Equal(t, common.LogicalTimestamp{SID: sidB, Counter: 10}, ids[3])
+	assert.Len(t, replicaA.Characters(), 13)
+
+	// Multi-byte characters survive a JSON round trip
+	text := NewRGAStringNode(common.LogicalTimestamp{SID: base, Counter: 1})
+	assert.True(t, text.Insert(common.RootID, common.LogicalTimestamp{SID: base, Counter: 2}, "보스전"))
+	assert.True(t, text.Delete(common.LogicalTimestamp{SID: base, Counter: 3}, common.LogicalTimestamp{SID: base, Counter: 3}))
+	assert.Equal(t, "보전", text.Value())
+
+	data, err := text.MarshalJSON()
+	assert.NoError(t, err)
+	decoded := &RGAStringNode{}
+	assert.NoError(t, decoded.UnmarshalJSON(data))
+	assert.Equal(t, "보전", decoded.Value())
+	assert.Equal(t, 2, decoded.Length())
+
+	// Compact merges chunks split by a deletion once they have the same state
+	assert.True(t, decoded.Delete(common.LogicalTimestamp{SID: base, Counter: 2}, common.LogicalTimestamp{SID: base, Counter: 4}))
+	decoded.Compact()
+	assert.Len(t, decoded.NodeElements, 1)
+	assert.Equal(t, "", decoded.Value())
+}
+
+// TestMovableListNode tests that moves keep element identity under concurrency
+func TestMovableListNode(t *testing.T) {
+	base := common.NewSessionID()
+	sidA := common.NewSessionID()
+	sidB := common.NewSessionID()
+	ts := func(sid common.SessionID, counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	// Each replica starts with [a, b, c]
+	newReplica := func() *MovableListNode {
+		node := NewMovableListNode(ts(base, 1))
+		assert.True(t, node.Insert(common.RootID, ts(base, 2), "a"))
+		assert.True(t, node.Insert(ts(base, 2), ts(base, 3), "b"))
+		assert.True(t, node.Insert(ts(base, 3), ts(base, 4), "c"))
+		return node
+	}
+
+	node := newReplica()
+	assert.Equal(t, common.NodeTypeList, node.Type())
+	assert.Equal(t, []interface{}{"a", "b", "c"}, node.Value())
+
+	// A moves "c" to the front, B moves "c" after "a" and edits it
+	moveA := func(n *MovableListNode) {
+		assert.True(t, n.Move(ts(base, 4), common.RootID, ts(sidA, 10)))
+	}
+	moveB := func(n *MovableListNode) {
+		assert.True(t, n.Move(ts(base, 4), ts(base, 2), ts(sidB, 10)))
+		assert.True(t, n.Set(ts(base, 4), "C"))
+	}
+
+	replicaA := newReplica()
+	moveA(replicaA)
+	moveB(replicaA)
+
+	replicaB := newReplica()
+	moveB(replicaB)
+	moveA(replicaB)
+
+	// The element appears once, at the winning destination, with its edit
+	assert.Equal(t, 3, replicaA.Length())
+	assert.Equal(t, replicaA.Value(), replicaB.Value())
+	winner := ts(sidA, 10)
+	if ts(sidB, 10).Compare(winner) > 0 {
+		winner = ts(sidB, 10)
+	}
+	elem, err := replicaA.Get(0)
+	assert.NoError(t, err)
+	if winner.SID == sidA {
+		assert.Equal(t, []interface{}{"C", "a", "b"}, replicaA.Value())
+	} else {
+		assert.Equal(t, []interface{}{"a", "C", "b"}, replicaA.Value())
+		elem, err = replicaA.Get(1)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, ts(base, 4), elem.ElementId)
+	assert.Equal(t, winner, elem.ElementSlot)
+
+	// Replaying a move is a no-op
+	assert.False(t, replicaA.Move(ts(base, 4), common.RootID, ts(sidA, 10)))
+
+	// Deleting a moved element removes it wherever it is
+	assert.True(t, replicaA.Delete(ts(base, 4)))
+	assert.Equal(t, []interface{}{"a", "b"}, replicaA.Value())
+	assert.False(t, replicaA.Delete(ts(sidA, 99)))
+
+	// JSON round trip keeps slots, elements and node ID values
+	assert.True(t, replicaA.Insert(ts(base, 3), ts(sidA, 11), ts(base, 42)))
+	data, err := replicaA.MarshalJSON()
+	assert.NoError(t, err)
+	decoded := &MovableListNode{}
+	assert.NoError(t, decoded.UnmarshalJSON(data))
+	assert.Equal(t, replicaA.Value(), decoded.Value())
+	assert.Equal(t, []interface{}{"a", "b", ts(base, 42)}, decoded.Value())
+}
+
+// TestCounterNodes tests the GCounterNode and PNCounterNode implementations
+func TestCounterNodes(t *testing.T) {
+	sidA := common.NewSessionID()
+	sidB := common.NewSessionID()
+	id := common.LogicalTimestamp{SID: sidA, Counter: 1}
+
+	// Grow-only counter
+	g := NewGCounterNode(id)
+	assert.Equal(t, common.NodeTypeGCounter, g.Type())
+	assert.Equal(t, uint64(5), g.Inc(sidA, 5))
+	g.Merge(sidB, 3, 0)
+	g.Merge(sidB, 2, 0) // lower totals are ignored
+	assert.Equal(t, int64(8), g.Value())
+
+	// PN-counter sums increments and decrements of all sessions
+	pn := NewPNCounterNode(id)
+	assert.Equal(t, common.NodeTypePNCounter, pn.Type())
+	pn.Inc(sidA, 100)
+	pn.Dec(sidA, 30)
+	pn.Merge(sidB, 50, 0)
+	pn.Merge(sidB, 50, 0) // merging is idempotent
+	assert.Equal(t, int64(120), pn.Value())
+
+	increments, decrements := pn.Totals(sidA)
+	assert.Equal(t, uint64(100), increments)
+	assert.Equal(t, uint64(30), decrements)
+
+	// JSON round trip
+	data, err := pn.MarshalJSON()
+	assert.NoError(t, err)
+	decodedPN := &PNCounterNode{}
+	assert.NoError(t, decodedPN.UnmarshalJSON(data))
+	assert.Equal(t, int64(120), decodedPN.Count())
+
+	data, err = g.MarshalJSON()
+	assert.NoError(t, err)
+	decodedG := &GCounterNode{}
+	assert.NoError(t, decodedG.UnmarshalJSON(data))
+	assert.Equal(t, int64(8), decodedG.Count())
+	assert.Error(t, decodedG.UnmarshalJSON([]byte(`{"type":"pncnt"}`)))
+}
+
+// TestMVRegisterNode tests that concurrent writes are kept as conflicts
+func TestMVRegisterNode(t *testing.T) {
+	base := common.NewSessionID()
+	sidA := common.NewSessionID()
+	sidB := common.NewSessionID()
+	ts := func(sid common.SessionID, counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	node := NewMVRegisterNode(ts(base, 1))
+	assert.Equal(t, common.NodeTypeMVReg, node.Type())
+	assert.Nil(t, node.Value())
+
+	assert.True(t, node.Assign(ts(base, 2), "novice"))
+	assert.False(t, node.HasConflict())
+	observed := node.Observed()
+
+	// Two sessions overwrite the same value concurrently
+	assert.True(t, node.Set(ts(sidA, 3), "knight", observed))
+	assert.True(t, node.Set(ts(sidB, 3), "mage", observed))
+	assert.False(t, node.Set(ts(sidB, 3), "mage", observed))
+
+	assert.True(t, node.HasConflict())
+	conflicts := node.ConflictingValues()
+	assert.Len(t, conflicts, 2)
+	values := []interface{}{conflicts[0].Value, conflicts[1].Value}
+	assert.ElementsMatch(t, []interface{}{"knight", "mage"}, values)
+	assert.Equal(t, conflicts[1].Value, node.Value())
+
+	// A replaced write arriving late does not reappear
+	late := NewMVRegisterNode(ts(base, 1))
+	assert.True(t, late.Set(ts(sidA, 3), "knight", []common.LogicalTimestamp{ts(base, 2)}))
+	assert.False(t, late.Set(ts(base, 2), "novice", nil))
+	assert.Equal(t, "knight", late.Value())
+
+	// Resolving the conflict replaces every value
+	assert.True(t, node.Assign(ts(sidA, 4), "paladin"))
+	assert.False(t, node.HasConflict())
+	assert.Equal(t, "paladin", node.Value())
+
+	// JSON round trip keeps entries and replaced timestamps
+	data, err := node.MarshalJSON()
+	assert.NoError(t, err)
+	decoded := &MVRegisterNode{}
+	assert.NoError(t, decoded.UnmarshalJSON(data))
+	assert.Equal(t, "paladin", decoded.Value())
+	assert.False(t, decoded.Set(ts(sidB, 3), "mage", nil))
+}
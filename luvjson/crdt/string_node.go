@@ -2,10 +2,18 @@ package crdt
 
 import (
 	"encoding/json"
+	"strings"
 	"tictactoe/luvjson/common"
+	"unicode/utf8"
 )
 
 // RGAStringNode represents a Replicated Growable Array string node.
+//
+// Every character has its own ID, so concurrent insertions at different positions
+// merge instead of overwriting each other. Characters are stored in chunks: an
+// element holds a run of characters whose IDs share the session of the element ID
+// and have consecutive counters starting at it. Chunks are split when text is
+// inserted or deleted inside them and merged again by Insert and Compact.
 type RGAStringNode struct {
 	NodeId       common.LogicalTimestamp `json:"id"`
 	NodeElements []*RGAElement           `json:"elements,omitempty"`
@@ -29,8 +37,15 @@ func (n *RGAStringNode) Type() common.NodeType {
 	return common.NodeTypeStr
 }
 
+// Length returns the number of visible characters.
 func (n *RGAStringNode) Length() int {
-	return len(n.value())
+	length := 0
+	for _, elem := range n.NodeElements {
+		if !elem.NodeDeleted {
+			length += utf8.RuneCountInString(chunkText(elem))
+		}
+	}
+	return length
 }
 
 // Value returns the value of the node.
@@ -44,17 +59,13 @@ func (n *RGAStringNode) String() string {
 
 // value returns the string value of the node.
 func (n *RGAStringNode) value() string {
-	var result string
+	var result strings.Builder
 	for _, elem := range n.NodeElements {
 		if !elem.NodeDeleted {
-			if c, ok := elem.NodeValue.(rune); ok {
-				result += string(c)
-			} else if s, ok := elem.NodeValue.(string); ok && len(s) == 1 {
-				result += s
-			}
+			result.WriteString(chunkText(elem))
 		}
 	}
-	return result
+	return result.String()
 }
 
 // IsRoot returns true if this is a root node.
@@ -63,73 +74,188 @@ func (n *RGAStringNode) IsRoot() bool {
 	return n.NodeId.Compare(common.RootID) == 0
 }
 
-// Insert inserts a string after the specified position.
+// Insert inserts a string after the character afterID (common.RootID inserts at
+// the beginning). The characters of value get consecutive IDs starting at id.
 func (n *RGAStringNode) Insert(afterID common.LogicalTimestamp, id common.LogicalTimestamp, value string) bool {
 	// Find the position to insert
 	pos := -1
-	for i, elem := range n.NodeElements {
-		if elem.NodeId.Compare(afterID) == 0 {
-			pos = i
-			break
+	if afterID.Compare(common.RootID) != 0 {
+		chunk, offset, ok := n.findChar(afterID)
+		if !ok {
+			return false
 		}
+		// 청크 중간 문자 뒤에 삽입하면 청크 분할
+		n.splitChunk(chunk, offset+1)
+		pos = chunk
 	}
 
-	// Check if afterID is not the common.RootID
-	if pos == -1 && afterID.Compare(common.RootID) != 0 {
-		return false
+	if value == "" {
+		return true
 	}
 
-	// Create new elements
-	newElements := make([]*RGAElement, len(value))
-	for i, c := range value {
-		newElements[i] = &RGAElement{
-			NodeId: common.LogicalTimestamp{
-				SID:     id.SID,
-				Counter: id.Counter + uint64(i),
-			},
-			NodeValue:   c,
-			NodeDeleted: false,
-		}
+	// Skip elements inserted concurrently at the same position with a greater ID
+	// so that all replicas order concurrent inserts the same way
+	insertPos := pos + 1
+	for insertPos < len(n.NodeElements) && rgaIDGreater(n.NodeElements[insertPos].NodeId, id) {
+		insertPos++
 	}
 
-	// Insert the new elements
-	if pos == -1 {
-		n.NodeElements = append(newElements, n.NodeElements...)
-	} else {
-		n.NodeElements = append(n.NodeElements[:pos+1], append(newElements, n.NodeElements[pos+1:]...)...)
+	// 이어서 입력한 문자는 앞 청크에 병합
+	if insertPos == pos+1 && pos >= 0 && chunkContinues(n.NodeElements[pos], id, false) {
+		n.NodeElements[pos].NodeValue = chunkText(n.NodeElements[pos]) + value
+		return true
 	}
 
+	elem := &RGAElement{NodeId: id, NodeValue: value}
+	n.NodeElements = append(n.NodeElements[:insertPos], append([]*RGAElement{elem}, n.NodeElements[insertPos:]...)...)
+
 	return true
 }
 
-// Delete marks elements as deleted.
+// rgaIDGreater reports whether a orders before b among concurrent inserts.
+// Higher counters win; ties are broken by session ID.
+func rgaIDGreater(a, b common.LogicalTimestamp) bool {
+	if a.Counter != b.Counter {
+		return a.Counter > b.Counter
+	}
+	return a.SID.Compare(b.SID) > 0
+}
+
+// Delete marks the characters from startID to endID (inclusive) as deleted.
 func (n *RGAStringNode) Delete(startID, endID common.LogicalTimestamp) bool {
-	startPos := -1
-	endPos := -1
+	startChunk, startOffset, ok := n.findChar(startID)
+	if !ok {
+		return false
+	}
+	endChunk, endOffset, ok := n.findChar(endID)
+	if !ok || endChunk < startChunk || (endChunk == startChunk && endOffset < startOffset) {
+		return false
+	}
 
-	for i, elem := range n.NodeElements {
-		if elem.NodeId.Compare(startID) == 0 {
-			startPos = i
+	// 범위 경계에서 청크 분할 (끝 먼저 분할해야 시작 인덱스가 유지됨)
+	n.splitChunk(endChunk, endOffset+1)
+	if n.splitChunk(startChunk, startOffset) {
+		startChunk++
+		endChunk++
+	}
+
+	for i := startChunk; i <= endChunk; i++ {
+		n.NodeElements[i].NodeDeleted = true
+	}
+
+	return true
+}
+
+// Characters returns one element per character, including deleted characters,
+// in document order. The elements are copies; changing them does not affect the node.
+func (n *RGAStringNode) Characters() []*RGAElement {
+	chars := make([]*RGAElement, 0, len(n.NodeElements))
+	for _, elem := range n.NodeElements {
+		i := uint64(0)
+		for _, c := range chunkText(elem) {
+			chars = append(chars, &RGAElement{
+				NodeId:      common.LogicalTimestamp{SID: elem.NodeId.SID, Counter: elem.NodeId.Counter + i},
+				NodeValue:   c,
+				NodeDeleted: elem.NodeDeleted,
+			})
+			i++
 		}
-		if elem.NodeId.Compare(endID) == 0 {
-			endPos = i
+	}
+	return chars
+}
+
+// VisibleIDs returns the IDs of the characters that are not deleted, in order.
+func (n *RGAStringNode) VisibleIDs() []common.LogicalTimestamp {
+	ids := make([]common.LogicalTimestamp, 0, len(n.NodeElements))
+	for _, elem := range n.NodeElements {
+		if elem.NodeDeleted {
+			continue
 		}
-		if startPos != -1 && endPos != -1 {
-			break
+		count := utf8.RuneCountInString(chunkText(elem))
+		for i := 0; i < count; i++ {
+			ids = append(ids, common.LogicalTimestamp{SID: elem.NodeId.SID, Counter: elem.NodeId.Counter + uint64(i)})
 		}
 	}
+	return ids
+}
 
-	if startPos == -1 || endPos == -1 || startPos > endPos {
-		return false
+// Compact merges adjacent chunks that continue each other, i.e. chunks of the
+// same session with consecutive character IDs and the same deleted state.
+func (n *RGAStringNode) Compact() {
+	compacted := make([]*RGAElement, 0, len(n.NodeElements))
+	for _, elem := range n.NodeElements {
+		last := len(compacted) - 1
+		if last >= 0 && chunkContinues(compacted[last], elem.NodeId, elem.NodeDeleted) {
+			compacted[last] = &RGAElement{
+				NodeId:      compacted[last].NodeId,
+				NodeValue:   chunkText(compacted[last]) + chunkText(elem),
+				NodeDeleted: elem.NodeDeleted,
+			}
+			continue
+		}
+		compacted = append(compacted, elem)
+	}
+	n.NodeElements = compacted
+}
+
+// findChar returns the chunk containing the character id and its offset in the chunk.
+func (n *RGAStringNode) findChar(id common.LogicalTimestamp) (int, int, bool) {
+	for i, elem := range n.NodeElements {
+		if elem.NodeId.SID != id.SID || id.Counter < elem.NodeId.Counter {
+			continue
+		}
+		offset := id.Counter - elem.NodeId.Counter
+		if offset < uint64(utf8.RuneCountInString(chunkText(elem))) {
+			return i, int(offset), true
+		}
 	}
+	return -1, 0, false
+}
 
-	for i := startPos; i <= endPos; i++ {
-		n.NodeElements[i].NodeDeleted = true
+// splitChunk splits the chunk at index i after offset characters.
+// It reports whether the chunk was split.
+func (n *RGAStringNode) splitChunk(i, offset int) bool {
+	elem := n.NodeElements[i]
+	runes := []rune(chunkText(elem))
+	if offset <= 0 || offset >= len(runes) {
+		return false
 	}
 
+	tail := &RGAElement{
+		NodeId:      common.LogicalTimestamp{SID: elem.NodeId.SID, Counter: elem.NodeId.Counter + uint64(offset)},
+		NodeValue:   string(runes[offset:]),
+		NodeDeleted: elem.NodeDeleted,
+	}
+	n.NodeElements[i] = &RGAElement{
+		NodeId:      elem.NodeId,
+		NodeValue:   string(runes[:offset]),
+		NodeDeleted: elem.NodeDeleted,
+	}
+	n.NodeElements = append(n.NodeElements[:i+1], append([]*RGAElement{tail}, n.NodeElements[i+1:]...)...)
 	return true
 }
 
+// chunkContinues reports whether a chunk starting at id with the given deleted
+// state directly continues elem.
+func chunkContinues(elem *RGAElement, id common.LogicalTimestamp, deleted bool) bool {
+	length := uint64(utf8.RuneCountInString(chunkText(elem)))
+	return elem.NodeDeleted == deleted &&
+		elem.NodeId.SID == id.SID &&
+		elem.NodeId.Counter+length == id.Counter
+}
+
+// chunkText returns the characters stored in a string element.
+func chunkText(elem *RGAElement) string {
+	switch v := elem.NodeValue.(type) {
+	case rune:
+		return string(v)
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
+
 // MarshalJSON returns a JSON representation of the node.
 func (n *RGAStringNode) MarshalJSON() ([]byte, error) {
 	type jsonElement struct {
@@ -151,16 +277,9 @@ func (n *RGAStringNode) MarshalJSON() ([]byte, error) {
 	}
 
 	for i, elem := range n.NodeElements {
-		var value string
-		if c, ok := elem.NodeValue.(rune); ok {
-			value = string(c)
-		} else if s, ok := elem.NodeValue.(string); ok {
-			value = s
-		}
-
 		node.Elements[i] = jsonElement{
 			ID:      elem.NodeId,
-			Value:   value,
+			Value:   chunkText(elem),
 			Deleted: elem.NodeDeleted,
 		}
 	}
@@ -169,6 +288,7 @@ func (n *RGAStringNode) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON parses a JSON representation of the node.
+// Elements may hold single characters or chunks of several characters.
 func (n *RGAStringNode) UnmarshalJSON(data []byte) error {
 	type jsonElement struct {
 		ID      common.LogicalTimestamp `json:"id"`
@@ -192,22 +312,21 @@ func (n *RGAStringNode) UnmarshalJSON(data []byte) error {
 	}
 
 	n.NodeId = node.ID
-	n.NodeElements = make([]*RGAElement, len(node.Elements))
-
-	for i, elem := range node.Elements {
-		var value interface{}
-		if len(elem.Value) == 1 {
-			value = rune(elem.Value[0])
-		} else {
-			value = elem.Value
-		}
+	n.NodeElements = make([]*RGAElement, 0, len(node.Elements))
 
-		n.NodeElements[i] = &RGAElement{
+	for _, elem := range node.Elements {
+		if elem.Value == "" {
+			continue
+		}
+		n.NodeElements = append(n.NodeElements, &RGAElement{
 			NodeId:      elem.ID,
-			NodeValue:   value,
+			NodeValue:   elem.Value,
 			NodeDeleted: elem.Deleted,
-		}
+		})
 	}
 
+	// 문자 단위로 저장된 이전 형식은 청크로 병합
+	n.Compact()
+
 	return nil
 }
@@ -208,6 +208,14 @@ func (n *LWWVectorNode) UnmarshalJSON(data []byte) error {
 			fieldNode = &RGAStringNode{}
 		case common.NodeTypeArr:
 			fieldNode = &RGAArrayNode{}
+		case common.NodeTypeList:
+			fieldNode = &MovableListNode{}
+		case common.NodeTypeGCounter:
+			fieldNode = &GCounterNode{}
+		case common.NodeTypePNCounter:
+			fieldNode = &PNCounterNode{}
+		case common.NodeTypeMVReg:
+			fieldNode = &MVRegisterNode{}
 		case common.NodeTypeVec:
 			fieldNode = &LWWVectorNode{}
 		case common.NodeTypeBin:
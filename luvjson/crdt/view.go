@@ -0,0 +1,210 @@
+package crdt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"tictactoe/luvjson/common"
+)
+
+// KeyValue is a field of an object in an ordered view.
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedObject is an object in an ordered view. Its fields are sorted by key.
+type OrderedObject []KeyValue
+
+// MarshalJSON writes the fields in order.
+func (o OrderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Get returns the value of the field key.
+func (o OrderedObject) Get(key string) (interface{}, bool) {
+	i := sort.Search(len(o), func(i int) bool { return o[i].Key >= key })
+	if i < len(o) && o[i].Key == key {
+		return o[i].Value, true
+	}
+	return nil, false
+}
+
+// ViewOrdered returns the same view as View, except that objects are returned as
+// OrderedObject with their fields sorted by key, so the view can be iterated and
+// encoded the same way on every replica.
+func (d *Document) ViewOrdered() (interface{}, error) {
+	if d.root == nil {
+		return nil, nil
+	}
+	return d.viewOf(d.root, true, make(map[Node]bool)), nil
+}
+
+// StateHash returns a hex-encoded SHA-256 hash of the ordered view of the
+// document. Replicas that converged have the same hash, regardless of the order
+// in which they applied operations or of their tombstones.
+func (d *Document) StateHash() (string, error) {
+	view, err := d.ViewOrdered()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(view)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// viewOf returns the view of a node. Node IDs held by constants and sequences are
+// resolved to the view of the node they refer to.
+func (d *Document) viewOf(node Node, ordered bool, visiting map[Node]bool) interface{} {
+	if node == nil || visiting[node] {
+		return nil
+	}
+	visiting[node] = true
+	defer delete(visiting, node)
+
+	switch n := node.(type) {
+	case *RootNode:
+		return d.viewOf(n.NodeValue, ordered, visiting)
+	case *LWWValueNode:
+		return d.viewOf(n.NodeValue, ordered, visiting)
+	case *ConstantNode:
+		return d.viewValue(n.NodeValue, ordered, visiting)
+	case *LWWObjectNode:
+		keys := n.Keys()
+		sort.Strings(keys)
+		if ordered {
+			obj := make(OrderedObject, 0, len(keys))
+			for _, key := range keys {
+				obj = append(obj, KeyValue{Key: key, Value: d.viewOf(n.NodeFields[key].NodeValue, ordered, visiting)})
+			}
+			return obj
+		}
+		obj := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			obj[key] = d.viewOf(n.NodeFields[key].NodeValue, ordered, visiting)
+		}
+		return obj
+	case *LWWVectorNode:
+		result := make([]interface{}, n.Length())
+		for index, field := range n.NodeFields {
+			if index >= 0 {
+				result[index] = d.viewOf(field.NodeValue, ordered, visiting)
+			}
+		}
+		return result
+	case *RGAArrayNode:
+		result := make([]interface{}, 0, len(n.NodeElements))
+		for _, elem := range n.NodeElements {
+			if !elem.NodeDeleted {
+				result = append(result, d.viewValue(elem.NodeValue, ordered, visiting))
+			}
+		}
+		return result
+	case *MovableListNode:
+		elements := n.Elements()
+		result := make([]interface{}, 0, len(elements))
+		for _, elem := range elements {
+			result = append(result, d.viewValue(elem.ElementValue, ordered, visiting))
+		}
+		return result
+	case *MVRegisterNode:
+		return d.viewValue(n.Value(), ordered, visiting)
+	default:
+		return node.Value()
+	}
+}
+
+// viewValue returns the view of a value held by a node.
+func (d *Document) viewValue(value interface{}, ordered bool, visiting map[Node]bool) interface{} {
+	switch v := value.(type) {
+	case Node:
+		return d.viewOf(v, ordered, visiting)
+	case common.LogicalTimestamp:
+		if node, ok := d.index[v]; ok {
+			return d.viewOf(node, ordered, visiting)
+		}
+		return v
+	case map[string]interface{}:
+		if !ordered {
+			obj := make(map[string]interface{}, len(v))
+			for key, elem := range v {
+				obj[key] = d.viewValue(elem, ordered, visiting)
+			}
+			return obj
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		obj := make(OrderedObject, 0, len(keys))
+		for _, key := range keys {
+			obj = append(obj, KeyValue{Key: key, Value: d.viewValue(v[key], ordered, visiting)})
+		}
+		return obj
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			result[i] = d.viewValue(elem, ordered, visiting)
+		}
+		return result
+	default:
+		return jsonNumber(v)
+	}
+}
+
+// jsonNumber returns integers as float64, the way they read back from JSON, so
+// the view of a constant does not depend on whether it was decoded or built.
+func jsonNumber(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	default:
+		return value
+	}
+}
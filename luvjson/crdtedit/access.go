@@ -0,0 +1,202 @@
+package crdtedit
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+)
+
+// ErrAccessDenied is returned when the access policy does not allow an operation on a path
+var ErrAccessDenied = errors.New("access denied")
+
+// AccessOperation is a set of operations controlled by an AccessPolicy
+type AccessOperation uint8
+
+const (
+	// AccessRead allows querying values
+	AccessRead AccessOperation = 1 << iota
+	// AccessWrite allows setting values, inserting elements and editing text
+	AccessWrite
+	// AccessDelete allows deleting object keys and array elements
+	AccessDelete
+
+	// AccessAll allows every operation
+	AccessAll = AccessRead | AccessWrite | AccessDelete
+)
+
+// String returns the operation names joined with "|"
+func (op AccessOperation) String() string {
+	names := make([]string, 0, 3)
+	if op&AccessRead != 0 {
+		names = append(names, "read")
+	}
+	if op&AccessWrite != 0 {
+		names = append(names, "write")
+	}
+	if op&AccessDelete != 0 {
+		names = append(names, "delete")
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// AccessRule allows or denies operations on a path and all paths below it
+type AccessRule struct {
+	// PathPrefix is the path the rule applies to; an empty prefix applies to the whole document
+	PathPrefix string
+	// Operations are the operations the rule allows or denies
+	Operations AccessOperation
+	// Deny makes the rule deny the operations instead of allowing them
+	Deny bool
+}
+
+// matches reports whether the rule applies to op on path.
+// Allow rules cover their prefix and the paths below it. Deny rules also cover
+// ancestors, since writing or reading an ancestor includes the denied path.
+func (r AccessRule) matches(path string, op AccessOperation) bool {
+	if r.Operations&op == 0 {
+		return false
+	}
+	if r.Deny {
+		return pathsOverlap(r.PathPrefix, path)
+	}
+	return r.PathPrefix == "" || r.PathPrefix == path || isPathPrefix(r.PathPrefix, path)
+}
+
+// AccessPolicy maps sessions and roles to the paths and operations they may use.
+//
+// An operation is allowed if a rule of the session or one of its roles allows it
+// and no such rule denies it. Operations not allowed by any rule are denied.
+type AccessPolicy struct {
+	mu           sync.RWMutex
+	roles        map[string][]AccessRule
+	sessions     map[common.SessionID][]AccessRule
+	sessionRoles map[common.SessionID][]string
+}
+
+// NewAccessPolicy creates an AccessPolicy that denies everything until rules are added
+func NewAccessPolicy() *AccessPolicy {
+	return &AccessPolicy{
+		roles:        make(map[string][]AccessRule),
+		sessions:     make(map[common.SessionID][]AccessRule),
+		sessionRoles: make(map[common.SessionID][]string),
+	}
+}
+
+// AllowRole allows the role to perform ops on pathPrefix and the paths below it
+func (p *AccessPolicy) AllowRole(role, pathPrefix string, ops AccessOperation) {
+	p.addRoleRule(role, AccessRule{PathPrefix: pathPrefix, Operations: ops})
+}
+
+// DenyRole denies the role ops on pathPrefix, the paths below it and its ancestors
+func (p *AccessPolicy) DenyRole(role, pathPrefix string, ops AccessOperation) {
+	p.addRoleRule(role, AccessRule{PathPrefix: pathPrefix, Operations: ops, Deny: true})
+}
+
+// AllowSession allows the session to perform ops on pathPrefix and the paths below it
+func (p *AccessPolicy) AllowSession(sessionID common.SessionID, pathPrefix string, ops AccessOperation) {
+	p.addSessionRule(sessionID, AccessRule{PathPrefix: pathPrefix, Operations: ops})
+}
+
+// DenySession denies the session ops on pathPrefix, the paths below it and its ancestors
+func (p *AccessPolicy) DenySession(sessionID common.SessionID, pathPrefix string, ops AccessOperation) {
+	p.addSessionRule(sessionID, AccessRule{PathPrefix: pathPrefix, Operations: ops, Deny: true})
+}
+
+// AssignRoles replaces the roles of the session
+func (p *AccessPolicy) AssignRoles(sessionID common.SessionID, roles ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(roles) == 0 {
+		delete(p.sessionRoles, sessionID)
+		return
+	}
+	p.sessionRoles[sessionID] = append([]string(nil), roles...)
+}
+
+// Allowed reports whether the session may perform op on path
+func (p *AccessPolicy) Allowed(sessionID common.SessionID, path string, op AccessOperation) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	rules := append([]AccessRule(nil), p.sessions[sessionID]...)
+	for _, role := range p.sessionRoles[sessionID] {
+		rules = append(rules, p.roles[role]...)
+	}
+
+	allowed := false
+	for _, rule := range rules {
+		if !rule.matches(path, op) {
+			continue
+		}
+		// 거부 규칙이 우선
+		if rule.Deny {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
+
+// Check returns ErrAccessDenied if the session may not perform op on path
+func (p *AccessPolicy) Check(sessionID common.SessionID, path string, op AccessOperation) error {
+	if p.Allowed(sessionID, path, op) {
+		return nil
+	}
+	return errors.Wrapf(ErrAccessDenied, "session %s may not %s path %q", sessionID, op, path)
+}
+
+// addRoleRule adds a rule to a role
+func (p *AccessPolicy) addRoleRule(role string, rule AccessRule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roles[role] = append(p.roles[role], rule)
+}
+
+// addSessionRule adds a rule to a session
+func (p *AccessPolicy) addSessionRule(sessionID common.SessionID, rule AccessRule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sessions[sessionID] = append(p.sessions[sessionID], rule)
+}
+
+// SetAccessPolicy makes the editor check policy for sessionID before generating
+// operations or answering queries. Edits and queries that are not allowed return
+// ErrAccessDenied. A nil policy disables access control.
+//
+// Query, QueryJSONPath and the As* editors require read access. Whole-document
+// exports (GetJSON, GetStruct, ExportBSON) require read access to the root path.
+func (e *DocumentEditor) SetAccessPolicy(policy *AccessPolicy, sessionID common.SessionID) {
+	e.accessPolicy = policy
+	e.accessSession = sessionID
+}
+
+// checkAccess returns an error if the editor may not perform op on path,
+// either because of the access policy or because of another session's edit intent
+func (e *DocumentEditor) checkAccess(path string, op AccessOperation) error {
+	if e.accessPolicy != nil {
+		if err := e.accessPolicy.Check(e.accessSession, path, op); err != nil {
+			return err
+		}
+	}
+	if op == AccessRead {
+		return nil
+	}
+	return e.checkIntent(path)
+}
+
+// checkWrite returns an error if the editor may not write path
+func (e *DocumentEditor) checkWrite(path string) error {
+	return e.checkAccess(path, AccessWrite)
+}
+
+// canRead reports whether the access policy allows reading path
+func (e *DocumentEditor) canRead(path string) bool {
+	return e.accessPolicy == nil || e.accessPolicy.Allowed(e.accessSession, path, AccessRead)
+}
@@ -0,0 +1,109 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+)
+
+// TestAccessPolicy tests rule matching for sessions and roles
+func TestAccessPolicy(t *testing.T) {
+	policy := NewAccessPolicy()
+	player := common.NewSessionID()
+	server := common.NewSessionID()
+	stranger := common.NewSessionID()
+
+	policy.AllowRole("player", "players.alice", AccessRead|AccessWrite)
+	policy.DenyRole("player", "players.alice.gold", AccessWrite)
+	policy.AllowRole("server", "", AccessAll)
+	policy.AssignRoles(player, "player")
+	policy.AssignRoles(server, "server")
+	policy.AllowSession(stranger, "items", AccessRead)
+
+	assert.True(t, policy.Allowed(player, "players.alice.title", AccessWrite))
+	assert.True(t, policy.Allowed(player, "players.alice.gold", AccessRead))
+	assert.False(t, policy.Allowed(player, "players.alice.gold", AccessWrite))
+	// 거부된 경로를 포함하는 상위 경로도 쓰기 불가
+	assert.False(t, policy.Allowed(player, "players.alice", AccessWrite))
+	assert.False(t, policy.Allowed(player, "players.alice.title", AccessDelete))
+	assert.False(t, policy.Allowed(player, "players.alicex", AccessRead))
+	assert.False(t, policy.Allowed(player, "players.bob", AccessRead))
+
+	assert.True(t, policy.Allowed(server, "players.alice.gold", AccessWrite))
+	assert.True(t, policy.Allowed(stranger, "items[0].name", AccessRead))
+	assert.False(t, policy.Allowed(stranger, "items[0].name", AccessWrite))
+
+	err := policy.Check(player, "players.alice.gold", AccessWrite)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	assert.Equal(t, "read|write", (AccessRead | AccessWrite).String())
+
+	// 역할 해제
+	policy.AssignRoles(player)
+	assert.False(t, policy.Allowed(player, "players.alice.title", AccessWrite))
+}
+
+// TestDocumentEditor_AccessPolicy tests that the editor rejects disallowed edits and reads
+func TestDocumentEditor_AccessPolicy(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	player := common.NewSessionID()
+	policy := NewAccessPolicy()
+	policy.AllowRole("player", "players.alice", AccessRead|AccessWrite)
+	policy.AllowRole("player", "items", AccessRead|AccessDelete)
+	policy.DenyRole("player", "players.alice.gold", AccessWrite)
+	policy.AssignRoles(player, "player")
+	editor.SetAccessPolicy(policy, player)
+
+	// 서버 권한 필드는 수정 불가
+	err := editor.SetValue("players.alice.gold", 999.0)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.SetKey("players.alice", "gold", 999.0)
+	})
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	_, err = editor.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/players/alice/gold", Value: 999.0},
+	})
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), gold)
+
+	// 허용된 필드는 수정 가능
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.SetKey("players.alice", "title", "hero")
+	})
+	require.NoError(t, err)
+
+	// 삭제 권한 확인
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.DeleteKey("players.alice", "title")
+	})
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.DeleteArrayElement("items", 0)
+	})
+	require.NoError(t, err)
+
+	// 읽기 권한이 없는 경로는 조회 불가
+	_, err = editor.Query("players.bob.gold")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	_, err = editor.AsObject("players.bob")
+	assert.ErrorIs(t, err, ErrAccessDenied)
+	_, err = editor.GetJSON()
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	results, err := editor.QueryJSONPath("$.players.*.gold")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "players.alice.gold", results[0].Path)
+
+	// 정책 해제
+	editor.SetAccessPolicy(nil, player)
+	require.NoError(t, editor.SetValue("players.alice.gold", 999.0))
+}
@@ -0,0 +1,268 @@
+package crdtedit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// FieldChange describes a change to a top-level field of a bound struct
+type FieldChange struct {
+	// Field is the JSON name of the changed field
+	Field string
+	// OldValue is the JSON-decoded value before the change (nil if the field was added)
+	OldValue any
+	// NewValue is the JSON-decoded value after the change (nil if the field was removed)
+	NewValue any
+	// Remote is true when the change came from an incoming patch
+	Remote bool
+}
+
+// ChangeCallback is called for every field changed by Sync or ApplyRemote
+type ChangeCallback func(change FieldChange)
+
+// Binding keeps a Go struct and a CRDT document in sync in both directions.
+//
+// Local writes to the struct are turned into a patch by Sync, and remote patches
+// passed to ApplyRemote update the struct fields and fire change callbacks.
+// The bound struct must not be modified concurrently with Sync, ApplyRemote or Refresh.
+type Binding struct {
+	mu        sync.Mutex
+	editor    *DocumentEditor
+	target    any
+	snapshot  map[string]any
+	callbacks []ChangeCallback
+}
+
+// Bind binds a struct pointer to a document.
+// If the document is empty it is initialized from the struct, otherwise the struct
+// is populated from the document.
+func Bind(doc *crdt.Document, v any) (*Binding, error) {
+	if doc == nil {
+		return nil, errors.New("document cannot be nil")
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("bind target must be a non-nil pointer to struct")
+	}
+
+	b := &Binding{
+		editor: NewDocumentEditor(doc),
+		target: v,
+	}
+
+	if _, err := rootObjectID(doc); err != nil {
+		// 빈 문서는 구조체로 초기화
+		if err := b.editor.InitFromStruct(v); err != nil {
+			return nil, errors.Wrap(err, "failed to initialize document from struct")
+		}
+	} else if err := b.loadStruct(); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := structSnapshot(v)
+	if err != nil {
+		return nil, err
+	}
+	b.snapshot = snapshot
+
+	return b, nil
+}
+
+// OnChange registers a callback that is called for every changed field
+func (b *Binding) OnChange(callback ChangeCallback) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.callbacks = append(b.callbacks, callback)
+}
+
+// Sync compares the struct with the last synchronized state and applies the
+// changed fields to the document as a single patch.
+// It returns nil if nothing changed.
+func (b *Binding) Sync() (*crdtpatch.Patch, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current, err := structSnapshot(b.target)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffSnapshots(b.snapshot, current, false)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	doc := b.editor.GetDocument()
+	objID, err := rootObjectID(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	patchBuilder := newDocumentPatchBuilder(doc)
+	for _, change := range changes {
+		if _, exists := current[change.Field]; !exists {
+			if err := patchBuilder.AddObjectDeleteOperation(objID, change.Field); err != nil {
+				return nil, errors.Wrapf(err, "failed to delete field %s", change.Field)
+			}
+			continue
+		}
+
+		if err := patchBuilder.AddObjectInsertOperation(objID, change.Field, change.NewValue); err != nil {
+			return nil, errors.Wrapf(err, "failed to set field %s", change.Field)
+		}
+	}
+
+	patch := patchBuilder.CreatePatch(doc.NextTimestamp())
+	if err := patch.Apply(doc); err != nil {
+		return nil, errors.Wrap(err, "failed to apply binding patch")
+	}
+
+	b.snapshot = current
+	b.notify(changes)
+	return patch, nil
+}
+
+// ApplyRemote applies an incoming patch to the document and updates the struct
+func (b *Binding) ApplyRemote(patch *crdtpatch.Patch) error {
+	if patch == nil {
+		return errors.New("patch cannot be nil")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := patch.Apply(b.editor.GetDocument()); err != nil {
+		return errors.Wrap(err, "failed to apply remote patch")
+	}
+
+	return b.refresh()
+}
+
+// Refresh re-reads the struct from the document, e.g. after the document was
+// modified outside of the binding, and fires change callbacks for changed fields
+func (b *Binding) Refresh() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.refresh()
+}
+
+// refresh reloads the struct and notifies callbacks; the caller must hold the lock
+func (b *Binding) refresh() error {
+	if err := b.loadStruct(); err != nil {
+		return err
+	}
+
+	current, err := structSnapshot(b.target)
+	if err != nil {
+		return err
+	}
+
+	changes := diffSnapshots(b.snapshot, current, true)
+	b.snapshot = current
+	b.notify(changes)
+	return nil
+}
+
+// loadStruct replaces the struct contents with the document contents
+func (b *Binding) loadStruct() error {
+	// 문서에 없는 필드가 이전 값으로 남지 않도록 초기화
+	rv := reflect.ValueOf(b.target).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+
+	value, err := b.editor.queryEngine.decodeNode(b.editor.GetDocument().Root())
+	if err != nil {
+		return errors.Wrap(err, "failed to decode document")
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal document value")
+	}
+
+	if err := json.Unmarshal(data, b.target); err != nil {
+		return errors.Wrap(err, "failed to load struct from document")
+	}
+	return nil
+}
+
+// notify calls all registered callbacks for the given changes
+func (b *Binding) notify(changes []FieldChange) {
+	for _, change := range changes {
+		for _, callback := range b.callbacks {
+			callback(change)
+		}
+	}
+}
+
+// structSnapshot converts a struct into its JSON field representation
+func structSnapshot(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal struct")
+	}
+
+	snapshot := make(map[string]any)
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal struct snapshot")
+	}
+	return snapshot, nil
+}
+
+// diffSnapshots returns the changed fields between two snapshots in key order
+func diffSnapshots(old, current map[string]any, remote bool) []FieldChange {
+	keys := make(map[string]struct{}, len(old)+len(current))
+	for key := range old {
+		keys[key] = struct{}{}
+	}
+	for key := range current {
+		keys[key] = struct{}{}
+	}
+
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	changes := make([]FieldChange, 0)
+	for _, key := range sortedKeys {
+		oldValue, oldExists := old[key]
+		newValue, newExists := current[key]
+		if oldExists == newExists && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{
+			Field:    key,
+			OldValue: oldValue,
+			NewValue: newValue,
+			Remote:   remote,
+		})
+	}
+	return changes
+}
+
+// rootObjectID returns the ID of the object node the document root points to
+func rootObjectID(doc *crdt.Document) (common.LogicalTimestamp, error) {
+	rootNode, ok := doc.Root().(*crdt.RootNode)
+	if !ok {
+		return common.NilID, errors.Errorf("unexpected root node type: %T", doc.Root())
+	}
+
+	objNode, ok := rootNode.NodeValue.(*crdt.LWWObjectNode)
+	if !ok {
+		return common.NilID, errors.New("document root is not an object")
+	}
+	return objNode.ID(), nil
+}
@@ -0,0 +1,76 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/crdt"
+)
+
+// raidState is a struct used for binding tests
+type raidState struct {
+	BossHP float64 `json:"bossHp"`
+	Phase  string  `json:"phase"`
+}
+
+// TestBinding tests two-way synchronization between a struct and a document
+func TestBinding(t *testing.T) {
+	doc := setupTestDocument(t)
+	root := doc.Root().(*crdt.RootNode).NodeValue.(*crdt.LWWObjectNode)
+
+	// 헬퍼: 루트 객체에 상수 필드 설정
+	setField := func(key string, value any) {
+		node := crdt.NewConstantNode(doc.NextTimestamp(), value)
+		doc.AddNode(node)
+		root.Set(key, node.ID(), node)
+	}
+	setField("bossHp", float64(1000))
+	setField("phase", "start")
+
+	// 기존 문서 내용으로 구조체 초기화
+	var state raidState
+	binding, err := Bind(doc, &state)
+	require.NoError(t, err)
+	assert.Equal(t, raidState{BossHP: 1000, Phase: "start"}, state)
+
+	var changes []FieldChange
+	binding.OnChange(func(change FieldChange) {
+		changes = append(changes, change)
+	})
+
+	// 원격 변경 → 구조체 갱신 및 콜백
+	setField("bossHp", float64(900))
+	require.NoError(t, binding.Refresh())
+	assert.Equal(t, float64(900), state.BossHP)
+	require.Len(t, changes, 1)
+	assert.Equal(t, FieldChange{Field: "bossHp", OldValue: float64(1000), NewValue: float64(900), Remote: true}, changes[0])
+
+	// 변경 없음
+	patch, err := binding.Sync()
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+
+	// 로컬 구조체 변경 → 패치
+	changes = nil
+	state.Phase = "enraged"
+	patch, err = binding.Sync()
+	require.NoError(t, err)
+	require.NotNil(t, patch)
+	assert.NotEmpty(t, patch.Operations())
+	require.Len(t, changes, 1)
+	assert.Equal(t, "phase", changes[0].Field)
+	assert.False(t, changes[0].Remote)
+}
+
+// TestBind_InvalidTarget tests that only struct pointers can be bound
+func TestBind_InvalidTarget(t *testing.T) {
+	doc := setupTestDocument(t)
+
+	_, err := Bind(doc, raidState{})
+	assert.Error(t, err)
+
+	_, err = Bind(doc, &map[string]any{})
+	assert.Error(t, err)
+}
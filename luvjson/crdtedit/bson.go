@@ -0,0 +1,118 @@
+package crdtedit
+
+import (
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// InitFromBSON initializes the document from a BSON document (e.g. a raw MongoDB
+// document loaded by nodestorage).
+//
+// Unlike InitFromJSON, BSON-specific types are kept as they are: ObjectIDs stay
+// primitive.ObjectID, datetimes become time.Time and integers keep their int32/int64 type.
+func (e *DocumentEditor) InitFromBSON(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("input BSON cannot be empty")
+	}
+
+	var value bson.D
+	if err := bson.Unmarshal(data, &value); err != nil {
+		return errors.Wrap(err, "failed to parse BSON")
+	}
+
+	rootID, err := e.buildBSONNode(value)
+	if err != nil {
+		return errors.Wrap(err, "failed to build document from BSON")
+	}
+
+	if err := e.doc.SetRoot(rootID); err != nil {
+		return errors.Wrap(err, "failed to set root node")
+	}
+	return e.NotifyChanges()
+}
+
+// ExportBSON returns the document as a BSON document.
+// The document root must be an object.
+func (e *DocumentEditor) ExportBSON() ([]byte, error) {
+	if err := e.checkAccess("", AccessRead); err != nil {
+		return nil, err
+	}
+
+	value, err := e.queryEngine.decodeNode(e.doc.Root())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode document")
+	}
+
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.Errorf("document root must be an object to export as BSON, got %T", value)
+	}
+
+	data, err := bson.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal document to BSON")
+	}
+	return data, nil
+}
+
+// buildBSONNode creates the nodes for a decoded BSON value and returns the ID of the top node
+func (e *DocumentEditor) buildBSONNode(value any) (common.LogicalTimestamp, error) {
+	switch v := value.(type) {
+	case bson.D:
+		obj := crdt.NewLWWObjectNode(e.doc.NextTimestamp())
+		e.doc.AddNode(obj)
+
+		for _, elem := range v {
+			childID, err := e.buildBSONNode(elem.Value)
+			if err != nil {
+				return common.NilID, errors.Wrapf(err, "failed to build field %s", elem.Key)
+			}
+
+			child, err := e.doc.GetNode(childID)
+			if err != nil {
+				return common.NilID, errors.Wrapf(err, "failed to get node for field %s", elem.Key)
+			}
+			obj.Set(elem.Key, e.doc.NextTimestamp(), child)
+		}
+		return obj.ID(), nil
+	case bson.M:
+		// bson.M은 순서가 없으므로 bson.D로 변환하여 처리
+		doc := make(bson.D, 0, len(v))
+		for key, elem := range v {
+			doc = append(doc, bson.E{Key: key, Value: elem})
+		}
+		return e.buildBSONNode(doc)
+	case bson.A:
+		arr := crdt.NewRGAArrayNode(e.doc.NextTimestamp())
+		e.doc.AddNode(arr)
+
+		// RGA 배열은 이전 요소 뒤에 삽입 (첫 요소는 RootID 뒤)
+		afterID := common.RootID
+		for i, elem := range v {
+			childID, err := e.buildBSONNode(elem)
+			if err != nil {
+				return common.NilID, errors.Wrapf(err, "failed to build element at index %d", i)
+			}
+
+			elemID := e.doc.NextTimestamp()
+			arr.Insert(afterID, elemID, childID)
+			afterID = elemID
+		}
+		return arr.ID(), nil
+	case primitive.DateTime:
+		return e.addConstant(v.Time()), nil
+	default:
+		return e.addConstant(v), nil
+	}
+}
+
+// addConstant adds a constant node holding the value and returns its ID
+func (e *DocumentEditor) addConstant(value any) common.LogicalTimestamp {
+	node := crdt.NewConstantNode(e.doc.NextTimestamp(), value)
+	e.doc.AddNode(node)
+	return node.ID()
+}
@@ -0,0 +1,86 @@
+package crdtedit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// TestBSONRoundTrip tests importing and exporting BSON without losing types
+func TestBSONRoundTrip(t *testing.T) {
+	id := primitive.NewObjectID()
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	data, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: id},
+		{Key: "createdAt", Value: createdAt},
+		{Key: "gold", Value: int64(9007199254740993)},
+		{Key: "level", Value: int32(7)},
+		{Key: "name", Value: "raid"},
+		{Key: "stats", Value: bson.D{{Key: "hp", Value: 1.5}}},
+		{Key: "tags", Value: bson.A{"boss", "fire"}},
+	})
+	require.NoError(t, err)
+
+	doc := crdt.NewDocument(common.NewSessionID())
+	editor := NewDocumentEditor(doc)
+	require.NoError(t, editor.InitFromBSON(data))
+
+	// 타입 보존 확인
+	value, err := editor.Query("_id")
+	require.NoError(t, err)
+	assert.Equal(t, id, value)
+
+	value, err = editor.Query("createdAt")
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(value.(time.Time)))
+
+	value, err = editor.Query("gold")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), value)
+
+	value, err = editor.Query("stats.hp")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+
+	// 내보내기 후 다시 디코딩
+	exported, err := editor.ExportBSON()
+	require.NoError(t, err)
+
+	var result struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		CreatedAt time.Time          `bson:"createdAt"`
+		Gold      int64              `bson:"gold"`
+		Level     int32              `bson:"level"`
+		Name      string             `bson:"name"`
+		Stats     struct {
+			HP float64 `bson:"hp"`
+		} `bson:"stats"`
+		Tags []string `bson:"tags"`
+	}
+	require.NoError(t, bson.Unmarshal(exported, &result))
+
+	assert.Equal(t, id, result.ID)
+	assert.True(t, createdAt.Equal(result.CreatedAt))
+	assert.Equal(t, int64(9007199254740993), result.Gold)
+	assert.Equal(t, int32(7), result.Level)
+	assert.Equal(t, "raid", result.Name)
+	assert.Equal(t, 1.5, result.Stats.HP)
+	assert.Equal(t, []string{"boss", "fire"}, result.Tags)
+}
+
+// TestInitFromBSON_Invalid tests error handling for invalid BSON input
+func TestInitFromBSON_Invalid(t *testing.T) {
+	doc := crdt.NewDocument(common.NewSessionID())
+	editor := NewDocumentEditor(doc)
+
+	assert.Error(t, editor.InitFromBSON(nil))
+	assert.Error(t, editor.InitFromBSON([]byte{0x01, 0x02}))
+}
@@ -0,0 +1,176 @@
+package crdtedit
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ComputeFunc calculates a derived value from the values of its dependency paths.
+// inputs maps every document path matching a dependency pattern to its value.
+type ComputeFunc func(inputs map[string]any) (any, error)
+
+// computedField is a registered derived field
+type computedField struct {
+	name     string
+	patterns [][]string
+	compute  ComputeFunc
+	value    any
+	err      error
+}
+
+// computedRegistry holds the derived fields of a document editor
+type computedRegistry struct {
+	mu     sync.RWMutex
+	fields map[string]*computedField
+}
+
+// newComputedRegistry creates a new computedRegistry
+func newComputedRegistry() *computedRegistry {
+	return &computedRegistry{
+		fields: make(map[string]*computedField),
+	}
+}
+
+// get returns the current value of a derived field
+func (r *computedRegistry) get(name string) (any, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	field, ok := r.fields[name]
+	if !ok {
+		return nil, false, nil
+	}
+	return field.value, true, field.err
+}
+
+// values returns the current values of all derived fields that computed successfully
+func (r *computedRegistry) values() map[string]any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]any, len(r.fields))
+	for name, field := range r.fields {
+		if field.err == nil {
+			result[name] = field.value
+		}
+	}
+	return result
+}
+
+// RegisterComputed registers a derived field calculated from the given dependency
+// path patterns (same glob syntax as Subscribe, e.g. "players.*.gold").
+//
+// The field is calculated immediately and recalculated whenever a change to a
+// matching path is published. Its value is not stored in the CRDT document; it is
+// exposed through Query, QueryJSONPath and Computed.
+func (e *DocumentEditor) RegisterComputed(name string, dependencies []string, compute ComputeFunc) error {
+	if name == "" {
+		return errors.New("computed field name cannot be empty")
+	}
+	if strings.ContainsAny(name, ".[]") {
+		return errors.Errorf("computed field name %s must be a single path segment", name)
+	}
+	if len(dependencies) == 0 {
+		return errors.New("computed field requires at least one dependency")
+	}
+	if compute == nil {
+		return errors.New("compute function cannot be nil")
+	}
+
+	field := &computedField{
+		name:    name,
+		compute: compute,
+	}
+	for _, dependency := range dependencies {
+		field.patterns = append(field.patterns, splitPathSegments(dependency))
+	}
+
+	r := e.computed
+	r.mu.Lock()
+	if _, exists := r.fields[name]; exists {
+		r.mu.Unlock()
+		return errors.Errorf("computed field %s is already registered", name)
+	}
+	r.fields[name] = field
+	r.mu.Unlock()
+
+	if err := e.recompute(field); err != nil {
+		return err
+	}
+
+	_, err := e.subscribeBatch(func(events []ChangeEvent) {
+		for _, event := range events {
+			if field.dependsOn(event.Path) {
+				// 계산 오류는 필드에 저장되어 조회 시 반환됨
+				_ = e.recompute(field)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to dependency changes")
+	}
+	return nil
+}
+
+// Computed returns the current value of a derived field
+func (e *DocumentEditor) Computed(name string) (any, error) {
+	value, ok, err := e.computed.get(name)
+	if !ok {
+		return nil, errors.Errorf("computed field %s is not registered", name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compute field %s", name)
+	}
+	return value, nil
+}
+
+// recompute recalculates a derived field from the current document state
+func (e *DocumentEditor) recompute(field *computedField) error {
+	snapshot, err := e.flattenDocument()
+	if err != nil {
+		return errors.Wrap(err, "failed to read document state")
+	}
+
+	inputs := make(map[string]any)
+	for path, value := range snapshot {
+		if field.dependsOn(path) {
+			inputs[path] = value
+		}
+	}
+
+	value, err := field.compute(inputs)
+
+	e.computed.mu.Lock()
+	field.value, field.err = value, err
+	e.computed.mu.Unlock()
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to compute field %s", field.name)
+	}
+	return nil
+}
+
+// dependsOn reports whether the path matches one of the field's dependency patterns
+func (f *computedField) dependsOn(path string) bool {
+	segments := splitPathSegments(path)
+	for _, pattern := range f.patterns {
+		if matchPathPattern(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// SumNumbers is a ComputeFunc that adds up all numeric inputs
+func SumNumbers(inputs map[string]any) (any, error) {
+	var sum float64
+	for _, value := range inputs {
+		if number, ok := toFloat64(value); ok {
+			sum += number
+		}
+	}
+	return sum, nil
+}
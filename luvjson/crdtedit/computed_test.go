@@ -0,0 +1,76 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/crdt"
+)
+
+// TestRegisterComputed tests derived fields recalculated from dependency paths
+func TestRegisterComputed(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	calls := 0
+	err := editor.RegisterComputed("totalGold", []string{"players.*.gold"}, func(inputs map[string]any) (any, error) {
+		calls++
+		return SumNumbers(inputs)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// 등록 시 즉시 계산
+	value, err := editor.Computed("totalGold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(140), value)
+
+	// 쿼리 API로 조회
+	value, err = editor.Query("totalGold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(140), value)
+
+	results, err := editor.QueryJSONPath("$.totalGold")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, float64(140), results[0].Value)
+
+	// 의존 경로 변경 시 재계산
+	root := doc.Root().(*crdt.RootNode).NodeValue.(*crdt.LWWObjectNode)
+	bob := root.Get("players").(*crdt.LWWObjectNode).Get("bob").(*crdt.LWWObjectNode)
+	gold := crdt.NewConstantNode(doc.NextTimestamp(), float64(60))
+	doc.AddNode(gold)
+	bob.Set("gold", gold.ID(), gold)
+	require.NoError(t, editor.NotifyChanges())
+
+	value, err = editor.Computed("totalGold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(160), value)
+	assert.Equal(t, 2, calls)
+
+	// 의존하지 않는 경로 변경은 재계산하지 않음
+	name := crdt.NewConstantNode(doc.NextTimestamp(), "shield")
+	doc.AddNode(name)
+	bob.Set("title", name.ID(), name)
+	require.NoError(t, editor.NotifyChanges())
+	assert.Equal(t, 2, calls)
+}
+
+// TestRegisterComputed_Invalid tests validation of computed field registration
+func TestRegisterComputed_Invalid(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	assert.Error(t, editor.RegisterComputed("", []string{"players"}, SumNumbers))
+	assert.Error(t, editor.RegisterComputed("a.b", []string{"players"}, SumNumbers))
+	assert.Error(t, editor.RegisterComputed("total", nil, SumNumbers))
+	assert.Error(t, editor.RegisterComputed("total", []string{"players"}, nil))
+
+	require.NoError(t, editor.RegisterComputed("total", []string{"players.*.gold"}, SumNumbers))
+	assert.Error(t, editor.RegisterComputed("total", []string{"players.*.gold"}, SumNumbers))
+
+	_, err := editor.Computed("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,235 @@
+package crdtedit
+
+import (
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+const (
+	// counterTypeKey is the object key that marks an object node as a counter
+	counterTypeKey = "_crdt"
+	// counterTypePN is the counterTypeKey value of PN-counters
+	counterTypePN = "pncounter"
+)
+
+// CounterEditor edits PN-counters stored in a document.
+//
+// A counter is an object node holding one entry per session with the total
+// increments (p) and decrements (n) made by that session. Each session only
+// writes its own entry, so concurrent increments from different sessions are
+// summed instead of overwriting each other. Queries return the counter value.
+//
+// Increment creates a missing counter, but counters created concurrently at the
+// same path by different sessions are resolved last-writer-wins, so shared
+// counters should be created with Create before being distributed.
+type CounterEditor struct {
+	editor *DocumentEditor
+}
+
+// NewCounterEditor creates a CounterEditor for the editor's document
+func NewCounterEditor(editor *DocumentEditor) *CounterEditor {
+	return &CounterEditor{editor: editor}
+}
+
+// Create creates a counter with value 0 at the given path.
+// The parent of the path must be an object.
+func (c *CounterEditor) Create(path string) (*crdtpatch.Patch, error) {
+	if _, err := c.counterNode(path); err == nil {
+		return nil, errors.Errorf("counter already exists at path %s", path)
+	}
+	return c.apply(path, 0)
+}
+
+// Increment adds delta to the counter at the given path, creating the counter if needed
+func (c *CounterEditor) Increment(path string, delta int64) (*crdtpatch.Patch, error) {
+	if delta == 0 {
+		return nil, errors.New("delta cannot be zero")
+	}
+	return c.apply(path, delta)
+}
+
+// Decrement subtracts delta from the counter at the given path
+func (c *CounterEditor) Decrement(path string, delta int64) (*crdtpatch.Patch, error) {
+	return c.Increment(path, -delta)
+}
+
+// Value returns the current value of the counter at the given path
+func (c *CounterEditor) Value(path string) (int64, error) {
+	if err := c.editor.checkAccess(path, AccessRead); err != nil {
+		return 0, err
+	}
+
+	node, err := c.counterNode(path)
+	if err != nil {
+		return 0, err
+	}
+
+	value, _ := counterValue(node)
+	return value, nil
+}
+
+// apply records delta for the local session, creating the counter node if needed
+func (c *CounterEditor) apply(path string, delta int64) (*crdtpatch.Patch, error) {
+	e := c.editor
+	if err := e.checkWrite(path); err != nil {
+		return nil, err
+	}
+
+	doc := e.GetDocument()
+	patch := crdtpatch.NewPatch(doc.NextTimestamp())
+
+	var counterID common.LogicalTimestamp
+	var p, n int64
+
+	node, err := c.counterNode(path)
+	if err == nil {
+		counterID = node.ID()
+		p, n = counterEntry(node, doc.GetSessionID())
+	} else {
+		parentID, key, err := c.parentObject(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// 카운터 객체 생성 후 부모에 연결
+		counterID = doc.NextTimestamp()
+		patch.AddOperation(&crdtpatch.NewOperation{
+			ID:       counterID,
+			NodeType: common.NodeTypeObj,
+		})
+		patch.AddOperation(&crdtpatch.InsOperation{
+			ID:       doc.NextTimestamp(),
+			TargetID: counterID,
+			Value:    map[string]any{counterTypeKey: counterTypePN},
+		})
+		patch.AddOperation(&crdtpatch.InsOperation{
+			ID:       doc.NextTimestamp(),
+			TargetID: parentID,
+			Value:    map[string]any{key: counterID},
+		})
+	}
+
+	if delta > 0 {
+		p += delta
+	} else {
+		n -= delta
+	}
+
+	if delta != 0 {
+		// 세션별 항목은 해당 세션만 기록하므로 LWW로 덮어써도 안전
+		patch.AddOperation(&crdtpatch.InsOperation{
+			ID:       doc.NextTimestamp(),
+			TargetID: counterID,
+			Value: map[string]any{
+				doc.GetSessionID().String(): map[string]any{"p": p, "n": n},
+			},
+		})
+	}
+
+	if err := patch.Apply(doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to apply counter patch at path %s", path)
+	}
+	e.recordPatch(patch)
+
+	if err := e.NotifyChanges(); err != nil {
+		return patch, err
+	}
+	return patch, nil
+}
+
+// counterNode returns the counter object at the given path
+func (c *CounterEditor) counterNode(path string) (*crdt.LWWObjectNode, error) {
+	e := c.editor
+	nodeID, err := e.pathResolver.ResolveNodePath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
+	}
+
+	node, err := e.GetDocument().GetNode(nodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get node at path %s", path)
+	}
+
+	obj, ok := derefValueNode(node).(*crdt.LWWObjectNode)
+	if !ok || !isCounter(obj) {
+		return nil, errors.Errorf("node at path %s is not a counter", path)
+	}
+	return obj, nil
+}
+
+// parentObject resolves the object that holds the last segment of path
+func (c *CounterEditor) parentObject(path string) (common.LogicalTimestamp, string, error) {
+	parentPath, key := splitLastSegment(path)
+	if key == "" {
+		return common.NilID, "", errors.New("counter path cannot be empty")
+	}
+
+	var parentID common.LogicalTimestamp
+	var err error
+	if parentPath == "" {
+		parentID, err = rootObjectID(c.editor.GetDocument())
+	} else {
+		parentID, err = c.editor.pathResolver.ResolveNodePath(parentPath)
+	}
+	if err != nil {
+		return common.NilID, "", errors.Wrapf(err, "failed to resolve parent of path %s", path)
+	}
+
+	node, err := c.editor.GetDocument().GetNode(parentID)
+	if err != nil {
+		return common.NilID, "", errors.Wrapf(err, "failed to get parent of path %s", path)
+	}
+
+	parent, ok := derefValueNode(node).(*crdt.LWWObjectNode)
+	if !ok {
+		return common.NilID, "", errors.Errorf("parent of path %s is not an object", path)
+	}
+	return parent.ID(), key, nil
+}
+
+// isCounter reports whether the object node is a PN-counter
+func isCounter(obj *crdt.LWWObjectNode) bool {
+	marker, ok := obj.Get(counterTypeKey).(*crdt.ConstantNode)
+	return ok && marker.Value() == counterTypePN
+}
+
+// counterValue returns the value of a PN-counter object
+func counterValue(obj *crdt.LWWObjectNode) (int64, bool) {
+	if !isCounter(obj) {
+		return 0, false
+	}
+
+	var value int64
+	for _, key := range obj.Keys() {
+		if key == counterTypeKey {
+			continue
+		}
+		p, n := counterEntryValue(obj.Get(key))
+		value += p - n
+	}
+	return value, true
+}
+
+// counterEntry returns the increments and decrements recorded by a session
+func counterEntry(obj *crdt.LWWObjectNode, sessionID common.SessionID) (int64, int64) {
+	return counterEntryValue(obj.Get(sessionID.String()))
+}
+
+// counterEntryValue decodes a per-session counter entry
+func counterEntryValue(node crdt.Node) (int64, int64) {
+	if node == nil {
+		return 0, 0
+	}
+
+	entry, ok := node.Value().(map[string]any)
+	if !ok {
+		return 0, 0
+	}
+
+	p, _ := toFloat64(entry["p"])
+	n, _ := toFloat64(entry["n"])
+	return int64(p), int64(n)
+}
@@ -0,0 +1,117 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// setupCounterDocument creates a document with an empty players.alice object using fixed IDs
+func setupCounterDocument(t *testing.T, baseSID common.SessionID) *crdt.Document {
+	doc := crdt.NewDocument(common.NewSessionID())
+
+	root := crdt.NewLWWObjectNode(common.LogicalTimestamp{SID: baseSID, Counter: 1})
+	doc.AddNode(root)
+
+	players := crdt.NewLWWObjectNode(common.LogicalTimestamp{SID: baseSID, Counter: 2})
+	doc.AddNode(players)
+	root.Set("players", players.ID(), players)
+
+	alice := crdt.NewLWWObjectNode(common.LogicalTimestamp{SID: baseSID, Counter: 3})
+	doc.AddNode(alice)
+	players.Set("alice", alice.ID(), alice)
+
+	require.NoError(t, doc.SetRoot(root.ID()))
+	return doc
+}
+
+// TestCounterEditor tests increments and decrements on a single replica
+func TestCounterEditor(t *testing.T) {
+	doc := setupCounterDocument(t, common.NewSessionID())
+	editor := NewDocumentEditor(doc)
+	counters := NewCounterEditor(editor)
+
+	// 없는 카운터는 자동 생성
+	_, err := counters.Increment("players.alice.gold", 100)
+	require.NoError(t, err)
+	_, err = counters.Increment("players.alice.gold", 50)
+	require.NoError(t, err)
+	_, err = counters.Decrement("players.alice.gold", 30)
+	require.NoError(t, err)
+
+	value, err := counters.Value("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), value)
+
+	// 쿼리는 카운터 값을 반환
+	queried, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), queried)
+
+	// 이미 존재하는 카운터는 생성 불가
+	_, err = counters.Create("players.alice.gold")
+	assert.Error(t, err)
+
+	_, err = counters.Increment("players.alice.gold", 0)
+	assert.Error(t, err)
+
+	// 카운터가 아닌 노드
+	_, err = counters.Value("players.alice")
+	assert.Error(t, err)
+	_, err = counters.Increment("players.nobody.gold", 1)
+	assert.Error(t, err)
+}
+
+// TestCounterEditor_ConcurrentIncrements tests that concurrent increments from two sessions are summed
+func TestCounterEditor_ConcurrentIncrements(t *testing.T) {
+	baseSID := common.NewSessionID()
+	editorA := NewDocumentEditor(setupCounterDocument(t, baseSID))
+	editorB := NewDocumentEditor(setupCounterDocument(t, baseSID))
+	countersA := NewCounterEditor(editorA)
+	countersB := NewCounterEditor(editorB)
+
+	// A가 카운터를 생성하고 B에 전달
+	patch, err := countersA.Create("players.alice.gold")
+	require.NoError(t, err)
+	require.NoError(t, editorB.ApplyPatch(patch))
+
+	// 동시 증가
+	patchesA := make([]*crdtpatch.Patch, 0)
+	patchesB := make([]*crdtpatch.Patch, 0)
+	for i := 0; i < 3; i++ {
+		patch, err := countersA.Increment("players.alice.gold", 10)
+		require.NoError(t, err)
+		patchesA = append(patchesA, patch)
+
+		patch, err = countersB.Increment("players.alice.gold", 5)
+		require.NoError(t, err)
+		patchesB = append(patchesB, patch)
+	}
+	patch, err = countersB.Decrement("players.alice.gold", 3)
+	require.NoError(t, err)
+	patchesB = append(patchesB, patch)
+
+	// 네트워크 전송을 가정하여 JSON으로 직렬화 후 교환
+	for _, patch := range patchesA {
+		data, err := patch.MarshalJSON()
+		require.NoError(t, err)
+		received := &crdtpatch.Patch{}
+		require.NoError(t, received.UnmarshalJSON(data))
+		require.NoError(t, editorB.ApplyPatch(received))
+	}
+	for _, patch := range patchesB {
+		require.NoError(t, editorA.ApplyPatch(patch))
+	}
+
+	valueA, err := countersA.Value("players.alice.gold")
+	require.NoError(t, err)
+	valueB, err := countersB.Value("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), valueA)
+	assert.Equal(t, valueA, valueB)
+}
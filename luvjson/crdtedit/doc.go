@@ -8,7 +8,19 @@
 // Key features:
 // - Path-based access to document nodes
 // - Type-specific editors for different node types
+// - Character-level text editing that merges concurrent edits
+// - PN-counters whose concurrent increments are summed
 // - Structured editing with automatic operation generation
-// - Support for initializing documents from structs or JSON
+// - Support for initializing documents from structs, JSON (also streamed) or BSON
+// - Typed views that read and edit documents through Go structs
+// - Transactions that commit several edits as a single patch
+// - Path templates for applying one edit to many paths in a single patch
+// - Advisory edit intents (path locks) recorded in the document
+// - Access policies restricting which sessions and roles may read, write or delete paths
 // - Query capabilities for retrieving document data
+// - JSONPath queries with wildcards, recursive descent and filters
+// - Diffing and patching documents with RFC 6902 JSON Patch
+// - Three-way merging of offline branches with conflict reporting
+// - Computed fields derived from other document paths
+// - Operation history per path for debugging divergent replicas
 package crdtedit
@@ -5,31 +5,50 @@ import (
 
 	"github.com/pkg/errors"
 
+	"tictactoe/luvjson/common"
 	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
 )
 
 // DocumentEditor provides methods for editing a CRDT document
 type DocumentEditor struct {
-	doc          *crdt.Document
-	pathResolver *PathResolver
-	queryEngine  *QueryEngine
-	modelBuilder *ModelBuilder
+	doc           *crdt.Document
+	pathResolver  *PathResolver
+	queryEngine   *QueryEngine
+	modelBuilder  *ModelBuilder
+	subscriptions *subscriptionManager
+	computed      *computedRegistry
+
+	enforceIntents bool
+	accessPolicy   *AccessPolicy
+	accessSession  common.SessionID
+	history        *historyLog
 }
 
 // NewDocumentEditor creates a new DocumentEditor for the given document
 func NewDocumentEditor(doc *crdt.Document) *DocumentEditor {
 	pathResolver := NewPathResolver(doc)
+	computed := newComputedRegistry()
+
+	queryEngine := NewQueryEngine(doc, pathResolver)
+	queryEngine.computed = computed
 
 	return &DocumentEditor{
-		doc:          doc,
-		pathResolver: pathResolver,
-		queryEngine:  NewQueryEngine(doc, pathResolver),
-		modelBuilder: NewModelBuilder(doc, nil), // PatchBuilder는 필요할 때 생성
+		doc:           doc,
+		pathResolver:  pathResolver,
+		queryEngine:   queryEngine,
+		modelBuilder:  NewModelBuilder(doc, nil), // PatchBuilder는 필요할 때 생성
+		subscriptions: newSubscriptionManager(),
+		computed:      computed,
 	}
 }
 
 // AsObject returns an ObjectEditor for the object at the given path
 func (e *DocumentEditor) AsObject(path string) (ObjectEditor, error) {
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
+
 	nodeID, err := e.pathResolver.ResolveNodePath(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
@@ -47,11 +66,16 @@ func (e *DocumentEditor) AsObject(path string) (ObjectEditor, error) {
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
+	ctx.checkAccess = e.checkAccess
 	return newObjectEditor(ctx, path, nodeID), nil
 }
 
 // AsArray returns an ArrayEditor for the array at the given path
 func (e *DocumentEditor) AsArray(path string) (ArrayEditor, error) {
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
+
 	nodeID, err := e.pathResolver.ResolveNodePath(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
@@ -69,11 +93,16 @@ func (e *DocumentEditor) AsArray(path string) (ArrayEditor, error) {
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
+	ctx.checkAccess = e.checkAccess
 	return newArrayEditor(ctx, path, nodeID), nil
 }
 
 // AsString returns a StringEditor for the string at the given path
 func (e *DocumentEditor) AsString(path string) (StringEditor, error) {
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
+
 	nodeID, err := e.pathResolver.ResolveNodePath(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
@@ -91,11 +120,16 @@ func (e *DocumentEditor) AsString(path string) (StringEditor, error) {
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
+	ctx.checkAccess = e.checkAccess
 	return newStringEditor(ctx, path, nodeID), nil
 }
 
 // AsNumber returns a NumberEditor for the number at the given path
 func (e *DocumentEditor) AsNumber(path string) (NumberEditor, error) {
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
+
 	nodeID, err := e.pathResolver.ResolveNodePath(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
@@ -113,11 +147,16 @@ func (e *DocumentEditor) AsNumber(path string) (NumberEditor, error) {
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
+	ctx.checkAccess = e.checkAccess
 	return newNumberEditor(ctx, path, nodeID), nil
 }
 
 // AsBoolean returns a BooleanEditor for the boolean at the given path
 func (e *DocumentEditor) AsBoolean(path string) (BooleanEditor, error) {
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
+
 	nodeID, err := e.pathResolver.ResolveNodePath(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
@@ -135,35 +174,87 @@ func (e *DocumentEditor) AsBoolean(path string) (BooleanEditor, error) {
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
+	ctx.checkAccess = e.checkAccess
 	return newBooleanEditor(ctx, path, nodeID), nil
 }
 
 // CreateObject creates an object at the given path
 func (e *DocumentEditor) CreateObject(path string) error {
+	if err := e.checkWrite(path); err != nil {
+		return err
+	}
+
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
-	return ctx.CreateObject(path)
+	if err := ctx.CreateObject(path); err != nil {
+		return err
+	}
+	return e.NotifyChanges()
 }
 
 // CreateArray creates an array at the given path
 func (e *DocumentEditor) CreateArray(path string) error {
+	if err := e.checkWrite(path); err != nil {
+		return err
+	}
+
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
-	return ctx.CreateArray(path)
+	if err := ctx.CreateArray(path); err != nil {
+		return err
+	}
+	return e.NotifyChanges()
 }
 
 // SetValue sets a value at the given path
 func (e *DocumentEditor) SetValue(path string, value any) error {
+	if err := e.checkWrite(path); err != nil {
+		return err
+	}
+
 	// 각 에디터 호출마다 새로운 PatchBuilder 생성
 	patchBuilder := NewPatchBuilder(e.doc.GetSessionID())
 	ctx := NewEditContext(e.doc, e.pathResolver, patchBuilder)
-	return ctx.SetValue(path, value)
+	if err := ctx.SetValue(path, value); err != nil {
+		return err
+	}
+	return e.NotifyChanges()
+}
+
+// Begin starts a new transaction on the document.
+// Mutations recorded on the transaction are not applied until Commit is called.
+func (e *DocumentEditor) Begin() *Transaction {
+	tx := newTransaction(e.doc, e.pathResolver)
+	tx.checkAccess = e.checkAccess
+	tx.onApply = e.recordPatch
+	tx.afterCommit = e.NotifyChanges
+	return tx
+}
+
+// Transaction runs fn inside a transaction and commits the result as a single patch.
+// If fn returns an error, all recorded mutations are rolled back.
+func (e *DocumentEditor) Transaction(fn TxFunc) (*crdtpatch.Patch, error) {
+	tx := e.Begin()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return nil, errors.Wrap(err, "transaction rolled back")
+	}
+
+	patch, err := tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+	return patch, nil
 }
 
 // Query retrieves a value from the document at the given path
 func (e *DocumentEditor) Query(path string) (any, error) {
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
 	return e.queryEngine.GetValue(path)
 }
 
@@ -176,7 +267,7 @@ func (e *DocumentEditor) InitFromStruct(v any) error {
 	if err := e.modelBuilder.BuildFromStruct(v); err != nil {
 		return errors.Wrap(err, "failed to build document from struct")
 	}
-	return nil
+	return e.NotifyChanges()
 }
 
 // InitFromJSON initializes the document from JSON data
@@ -188,11 +279,15 @@ func (e *DocumentEditor) InitFromJSON(data []byte) error {
 	if err := e.modelBuilder.BuildFromJSON(data); err != nil {
 		return errors.Wrap(err, "failed to build document from JSON")
 	}
-	return nil
+	return e.NotifyChanges()
 }
 
 // GetJSON returns the document as JSON
 func (e *DocumentEditor) GetJSON() ([]byte, error) {
+	if err := e.checkAccess("", AccessRead); err != nil {
+		return nil, err
+	}
+
 	data, err := e.modelBuilder.ToJSON(e.doc)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert document to JSON")
@@ -211,6 +306,10 @@ func (e *DocumentEditor) GetStruct(v any) error {
 		return errors.New("output value must be a non-nil pointer")
 	}
 
+	if err := e.checkAccess("", AccessRead); err != nil {
+		return err
+	}
+
 	if err := e.modelBuilder.ToStruct(e.doc, v); err != nil {
 		return errors.Wrap(err, "failed to convert document to struct")
 	}
@@ -221,3 +320,20 @@ func (e *DocumentEditor) GetStruct(v any) error {
 func (e *DocumentEditor) GetDocument() *crdt.Document {
 	return e.doc
 }
+
+// QueryJSONPath evaluates a JSONPath expression against the document
+// Results the access policy does not allow reading are left out.
+func (e *DocumentEditor) QueryJSONPath(expr string) ([]QueryResult, error) {
+	results, err := e.queryEngine.QueryJSONPath(expr)
+	if err != nil || e.accessPolicy == nil {
+		return results, err
+	}
+
+	allowed := make([]QueryResult, 0, len(results))
+	for _, result := range results {
+		if e.canRead(result.Path) {
+			allowed = append(allowed, result)
+		}
+	}
+	return allowed, nil
+}
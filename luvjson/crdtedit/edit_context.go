@@ -13,6 +13,7 @@ type EditContext struct {
 	doc          *crdt.Document
 	pathResolver *PathResolver
 	patchBuilder *PatchBuilder
+	checkAccess  func(path string, op AccessOperation) error
 }
 
 // NewEditContext creates a new EditContext
@@ -24,6 +25,14 @@ func NewEditContext(doc *crdt.Document, pathResolver *PathResolver, patchBuilder
 	}
 }
 
+// check runs the access check for op on the path, if one is configured
+func (ctx *EditContext) check(path string, op AccessOperation) error {
+	if ctx.checkAccess == nil {
+		return nil
+	}
+	return ctx.checkAccess(path, op)
+}
+
 // GetRootNode returns the root node of the document
 func (ctx *EditContext) GetRootNode() (crdt.Node, error) {
 	return ctx.doc.GetNode(ctx.doc.GetRootID())
@@ -0,0 +1,282 @@
+package crdtedit
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// HistoryEntry is an operation recorded by the editor history
+type HistoryEntry struct {
+	// PatchID is the ID of the patch that contained the operation
+	PatchID common.LogicalTimestamp
+	// ID is the logical timestamp of the operation
+	ID common.LogicalTimestamp
+	// SessionID is the session that created the operation
+	SessionID common.SessionID
+	// Type is the operation type (new, ins or del)
+	Type common.OperationType
+	// TargetID is the node the operation was applied to; zero for new operations
+	TargetID common.LogicalTimestamp
+	// Key is the object key or array index written or deleted, if any
+	Key string
+	// Value is the written value. Node references are replaced by the value the
+	// node was created with when that creation is in the history.
+	Value any
+}
+
+// historyLog stores the operations applied through an editor
+type historyLog struct {
+	mu      sync.RWMutex
+	limit   int
+	entries []HistoryEntry
+}
+
+// EnableHistory starts recording the operations of patches applied through the
+// editor: transactions, JSON patches, batch edits, text and counter edits, intents
+// and patches passed to ApplyPatch. Edits made through the As* type editors are not
+// recorded. At most limit entries are kept; limit <= 0 keeps all of them.
+func (e *DocumentEditor) EnableHistory(limit int) {
+	e.history = &historyLog{limit: limit}
+}
+
+// History returns the recorded operations that produced the current value at path,
+// in the order they were applied: the operations that assigned or deleted the path
+// in its parent object or array and all operations on the node at the path and its
+// descendants. The empty path returns the whole history.
+func (e *DocumentEditor) History(path string) ([]HistoryEntry, error) {
+	if e.history == nil {
+		return nil, errors.New("history is not enabled")
+	}
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
+
+	entries := e.history.snapshot()
+	if path == "" {
+		return e.resolveHistoryValues(entries, entries), nil
+	}
+
+	// 경로의 현재 노드와 하위 노드 수집
+	ids := make(map[common.LogicalTimestamp]bool)
+	parentID := common.NilID
+	key := ""
+
+	nodeID, err := e.pathResolver.ResolveNodePath(path)
+	if err == nil {
+		node, err := e.doc.GetNode(nodeID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get node at path %s", path)
+		}
+		e.collectNodeIDs(node, ids)
+	}
+
+	// 객체 필드는 부모에서의 할당/삭제도 포함
+	if parentPath, last := splitLastSegment(path); last != "" && last[len(last)-1] != ']' {
+		if id, err := e.objectIDAt(parentPath); err == nil {
+			parentID, key = id, last
+		}
+	}
+	if len(ids) == 0 && parentID == common.NilID {
+		return nil, errors.Errorf("failed to resolve path %s", path)
+	}
+
+	matched := make([]HistoryEntry, 0)
+	for _, entry := range entries {
+		ref, isRef := historyRef(entry.Value)
+		switch {
+		case entry.Type == common.OperationTypeNew && ids[entry.ID]:
+		case entry.Type != common.OperationTypeNew && ids[entry.TargetID]:
+		case isRef && ids[ref]:
+		case parentID != common.NilID && entry.TargetID == parentID && entry.Key == key:
+		default:
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	return e.resolveHistoryValues(matched, entries), nil
+}
+
+// recordPatch appends the operations of an applied patch to the history
+func (e *DocumentEditor) recordPatch(patch *crdtpatch.Patch) {
+	if e.history == nil || patch == nil {
+		return
+	}
+	e.history.record(patch)
+}
+
+// record appends the operations of a patch, dropping the oldest beyond the limit
+func (h *historyLog) record(patch *crdtpatch.Patch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, op := range patch.Operations() {
+		h.entries = append(h.entries, historyEntries(patch.ID(), op)...)
+	}
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = append([]HistoryEntry(nil), h.entries[len(h.entries)-h.limit:]...)
+	}
+}
+
+// snapshot returns a copy of the recorded entries
+func (h *historyLog) snapshot() []HistoryEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]HistoryEntry(nil), h.entries...)
+}
+
+// historyEntries converts an operation into history entries.
+// Inserts of several object keys or array indexes produce one entry per key.
+func historyEntries(patchID common.LogicalTimestamp, op crdtpatch.Operation) []HistoryEntry {
+	entry := HistoryEntry{
+		PatchID:   patchID,
+		ID:        op.GetID(),
+		SessionID: op.GetID().SID,
+		Type:      op.Type(),
+	}
+
+	switch o := op.(type) {
+	case *crdtpatch.NewOperation:
+		entry.Value = o.Value
+	case *crdtpatch.InsOperation:
+		entry.TargetID = o.TargetID
+		values, ok := o.Value.(map[string]any)
+		if _, isRef := historyRef(o.Value); !ok || isRef {
+			entry.Value = o.Value
+			break
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		entries := make([]HistoryEntry, len(keys))
+		for i, key := range keys {
+			entries[i] = entry
+			entries[i].Key = key
+			entries[i].Value = values[key]
+		}
+		return entries
+	case *crdtpatch.DelOperation:
+		entry.TargetID = o.TargetID
+		entry.Key = o.Key
+	case *crdtpatch.NopOperation:
+		return nil
+	}
+	return []HistoryEntry{entry}
+}
+
+// resolveHistoryValues replaces node references in entries with the value the
+// referenced node was created with. Creations dropped from the history are looked
+// up in the document, where only constant nodes are resolved.
+func (e *DocumentEditor) resolveHistoryValues(entries, history []HistoryEntry) []HistoryEntry {
+	created := make(map[common.LogicalTimestamp]any)
+	for _, entry := range history {
+		if entry.Type == common.OperationTypeNew && entry.Value != nil {
+			created[entry.ID] = entry.Value
+		}
+	}
+
+	result := make([]HistoryEntry, len(entries))
+	for i, entry := range entries {
+		if ref, ok := historyRef(entry.Value); ok {
+			if value, ok := created[ref]; ok {
+				entry.Value = value
+			} else if node, err := e.doc.GetNode(ref); err == nil {
+				if constant, ok := node.(*crdt.ConstantNode); ok {
+					entry.Value = constant.Value()
+				}
+			}
+		}
+		result[i] = entry
+	}
+	return result
+}
+
+// historyRef returns the node ID a value refers to.
+// The value may be a LogicalTimestamp or its JSON form ({"sid": ..., "cnt": ...}).
+func historyRef(value any) (common.LogicalTimestamp, bool) {
+	switch v := value.(type) {
+	case common.LogicalTimestamp:
+		return v, true
+	case map[string]any:
+		if len(v) != 2 {
+			return common.NilID, false
+		}
+		if _, ok := v["sid"]; !ok {
+			return common.NilID, false
+		}
+		if _, ok := v["cnt"]; !ok {
+			return common.NilID, false
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return common.NilID, false
+		}
+		var id common.LogicalTimestamp
+		if err := id.UnmarshalJSON(data); err != nil {
+			return common.NilID, false
+		}
+		return id, true
+	default:
+		return common.NilID, false
+	}
+}
+
+// collectNodeIDs adds the IDs of node and all of its descendants to ids
+func (e *DocumentEditor) collectNodeIDs(node crdt.Node, ids map[common.LogicalTimestamp]bool) {
+	if node == nil || ids[node.ID()] {
+		return
+	}
+	ids[node.ID()] = true
+
+	switch n := node.(type) {
+	case *crdt.RootNode:
+		e.collectNodeIDs(n.NodeValue, ids)
+	case *crdt.LWWValueNode:
+		e.collectNodeIDs(n.NodeValue, ids)
+	case *crdt.LWWObjectNode:
+		for _, field := range n.NodeFields {
+			e.collectNodeIDs(field.NodeValue, ids)
+		}
+	case *crdt.RGAArrayNode:
+		for _, elem := range n.NodeElements {
+			if id, ok := elem.NodeValue.(common.LogicalTimestamp); ok {
+				if child, err := e.doc.GetNode(id); err == nil {
+					e.collectNodeIDs(child, ids)
+				}
+			}
+		}
+	}
+}
+
+// objectIDAt returns the ID of the object at path
+func (e *DocumentEditor) objectIDAt(path string) (common.LogicalTimestamp, error) {
+	if path == "" {
+		return rootObjectID(e.doc)
+	}
+
+	nodeID, err := e.pathResolver.ResolveNodePath(path)
+	if err != nil {
+		return common.NilID, err
+	}
+	node, err := e.doc.GetNode(nodeID)
+	if err != nil {
+		return common.NilID, err
+	}
+
+	obj, ok := derefValueNode(node).(*crdt.LWWObjectNode)
+	if !ok {
+		return common.NilID, errors.Errorf("node at path %s is not an object", path)
+	}
+	return obj.ID(), nil
+}
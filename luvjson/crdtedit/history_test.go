@@ -0,0 +1,143 @@
+package crdtedit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// TestHistory tests recording local and remote operations per path
+func TestHistory(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	_, err := editor.History("players.alice.gold")
+	assert.Error(t, err)
+
+	editor.EnableHistory(0)
+
+	// 로컬 트랜잭션 두 번과 다른 경로 수정
+	for _, gold := range []float64{90, 80} {
+		_, err := editor.Transaction(func(tx *Transaction) error {
+			return tx.SetKey("players.alice", "gold", gold)
+		})
+		require.NoError(t, err)
+	}
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.SetKey("players.bob", "gold", float64(10))
+	})
+	require.NoError(t, err)
+
+	// 원격 세션의 패치
+	aliceID, err := editor.pathResolver.ResolveNodePath("players.alice")
+	require.NoError(t, err)
+	remote := common.NewSessionID()
+	patch := crdtpatch.NewPatch(common.LogicalTimestamp{SID: remote, Counter: 1})
+	patch.AddOperation(&crdtpatch.NewOperation{
+		ID:       common.LogicalTimestamp{SID: remote, Counter: 1},
+		NodeType: common.NodeTypeCon,
+		Value:    float64(70),
+	})
+	patch.AddOperation(&crdtpatch.InsOperation{
+		ID:       common.LogicalTimestamp{SID: remote, Counter: 2},
+		TargetID: aliceID,
+		Value:    map[string]any{"gold": common.LogicalTimestamp{SID: remote, Counter: 1}},
+	})
+	// JSON으로 전달된 패치의 노드 참조도 해석
+	data, err := patch.MarshalJSON()
+	require.NoError(t, err)
+	received := &crdtpatch.Patch{}
+	require.NoError(t, received.UnmarshalJSON(data))
+	require.NoError(t, editor.ApplyPatch(received))
+
+	// 필드 할당만 추려서 확인
+	history, err := editor.History("players.alice.gold")
+	require.NoError(t, err)
+	var writes []HistoryEntry
+	for _, entry := range history {
+		if entry.Type == common.OperationTypeIns {
+			writes = append(writes, entry)
+		}
+	}
+	require.Len(t, writes, 3)
+	assert.Equal(t, float64(90), writes[0].Value)
+	assert.Equal(t, float64(80), writes[1].Value)
+	assert.Equal(t, float64(70), writes[2].Value)
+	assert.Equal(t, doc.GetSessionID(), writes[0].SessionID)
+	assert.Equal(t, remote, writes[2].SessionID)
+	assert.Equal(t, "gold", writes[2].Key)
+	assert.Equal(t, aliceID, writes[2].TargetID)
+	assert.True(t, writes[0].ID.Compare(writes[1].ID) < 0)
+
+	// 다른 경로의 연산은 포함되지 않음
+	for _, entry := range history {
+		assert.NotEqual(t, float64(10), entry.Value)
+	}
+
+	// 상위 경로는 하위 경로의 연산을 포함
+	parent, err := editor.History("players.alice")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(parent), len(writes))
+
+	all, err := editor.History("")
+	require.NoError(t, err)
+	assert.Greater(t, len(all), len(history))
+
+	_, err = editor.History("players.carol.gold")
+	assert.Error(t, err)
+}
+
+// TestHistory_Limit tests that the oldest entries are dropped beyond the limit
+func TestHistory_Limit(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+	editor.EnableHistory(1)
+
+	for _, gold := range []float64{90, 80} {
+		_, err := editor.Transaction(func(tx *Transaction) error {
+			return tx.SetKey("players.alice", "gold", gold)
+		})
+		require.NoError(t, err)
+	}
+
+	history, err := editor.History("")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, common.OperationTypeIns, history[0].Type)
+	assert.Equal(t, float64(80), history[0].Value)
+}
+
+// TestHistoryRef tests resolving node references in their Go and JSON forms
+func TestHistoryRef(t *testing.T) {
+	id := common.LogicalTimestamp{SID: common.NewSessionID(), Counter: 42}
+
+	ref, ok := historyRef(id)
+	require.True(t, ok)
+	assert.Equal(t, id, ref)
+
+	// 패치를 JSON으로 주고받으면 참조는 map으로 디코딩됨
+	data, err := json.Marshal(id)
+	require.NoError(t, err)
+	var decoded any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	ref, ok = historyRef(decoded)
+	require.True(t, ok)
+	assert.Equal(t, id, ref)
+
+	// 참조가 아닌 값
+	for _, value := range []any{
+		float64(42),
+		"gold",
+		map[string]any{"sid": "x"},
+		map[string]any{"sid": "x", "cnt": float64(1), "extra": true},
+		map[string]any{"gold": float64(1), "silver": float64(2)},
+	} {
+		_, ok := historyRef(value)
+		assert.False(t, ok, "%v", value)
+	}
+}
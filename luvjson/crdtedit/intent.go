@@ -0,0 +1,281 @@
+package crdtedit
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// ErrPathLocked is returned when a path is covered by another session's edit intent
+var ErrPathLocked = errors.New("path is locked by another session")
+
+// IntentsKey is the root object key under which edit intents are stored.
+// Subscribing to this key notifies about intents acquired or released by any session.
+const IntentsKey = "_intents"
+
+// EditIntent is an advisory lock on a document path recorded inside the document
+type EditIntent struct {
+	// Path is the locked path; the intent also covers all paths below it
+	Path string
+	// SessionID is the session that holds the intent
+	SessionID common.SessionID
+	// ExpiresAt is the time after which the intent is no longer active
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the intent is no longer active at the given time
+func (i EditIntent) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// covers reports whether the intent overlaps the path (same path, ancestor or descendant)
+func (i EditIntent) covers(path string) bool {
+	return pathsOverlap(i.Path, path)
+}
+
+// AcquireIntent records that sessionID is editing path for the duration of ttl.
+// Acquiring an intent already held by the same session renews it.
+// It returns ErrPathLocked if an active intent of another session overlaps the path.
+// The returned patch should be broadcast so that other sessions see the intent.
+func (e *DocumentEditor) AcquireIntent(path string, sessionID common.SessionID, ttl time.Duration) (*crdtpatch.Patch, error) {
+	if path == "" {
+		return nil, errors.New("intent path cannot be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("intent ttl must be positive")
+	}
+
+	holder, err := e.lockingIntent(path, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if holder != nil {
+		return nil, errors.Wrapf(ErrPathLocked, "path %s is being edited by session %s", path, holder.SessionID)
+	}
+
+	record := map[string]any{
+		"session":   sessionID.String(),
+		"expiresAt": time.Now().Add(ttl).UnixMilli(),
+	}
+
+	return e.writeIntents(func(intentsID common.LogicalTimestamp) crdtpatch.Operation {
+		return &crdtpatch.InsOperation{
+			ID:       e.doc.NextTimestamp(),
+			TargetID: intentsID,
+			Value:    map[string]any{path: record},
+		}
+	})
+}
+
+// ReleaseIntent removes the intent of sessionID on path
+func (e *DocumentEditor) ReleaseIntent(path string, sessionID common.SessionID) (*crdtpatch.Patch, error) {
+	intents, err := e.readIntents()
+	if err != nil {
+		return nil, err
+	}
+
+	intent, ok := intents[path]
+	if !ok {
+		return nil, errors.Errorf("no intent on path %s", path)
+	}
+	if intent.SessionID != sessionID {
+		return nil, errors.Errorf("intent on path %s is held by session %s", path, intent.SessionID)
+	}
+
+	return e.writeIntents(func(intentsID common.LogicalTimestamp) crdtpatch.Operation {
+		return &crdtpatch.DelOperation{
+			ID:       e.doc.NextTimestamp(),
+			TargetID: intentsID,
+			Key:      path,
+		}
+	})
+}
+
+// Intents returns all active intents ordered by path
+func (e *DocumentEditor) Intents() ([]EditIntent, error) {
+	intents, err := e.readIntents()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]EditIntent, 0, len(intents))
+	for _, intent := range intents {
+		if !intent.Expired(now) {
+			result = append(result, intent)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+// IntentFor returns the active intent overlapping path, or nil if the path is free
+func (e *DocumentEditor) IntentFor(path string) (*EditIntent, error) {
+	intents, err := e.Intents()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, intent := range intents {
+		if intent.covers(path) {
+			return &intent, nil
+		}
+	}
+	return nil, nil
+}
+
+// SetEnforceIntents enables or disables rejection of local writes to paths locked
+// by other sessions. When enabled, SetValue, CreateObject, CreateArray and
+// transaction mutations return ErrPathLocked for such paths.
+func (e *DocumentEditor) SetEnforceIntents(enforce bool) {
+	e.enforceIntents = enforce
+}
+
+// checkIntent returns ErrPathLocked if intents are enforced and another session holds the path
+func (e *DocumentEditor) checkIntent(path string) error {
+	if !e.enforceIntents {
+		return nil
+	}
+
+	holder, err := e.lockingIntent(path, e.doc.GetSessionID())
+	if err != nil {
+		return err
+	}
+	if holder != nil {
+		return errors.Wrapf(ErrPathLocked, "path %s is being edited by session %s", path, holder.SessionID)
+	}
+	return nil
+}
+
+// lockingIntent returns an active intent of another session overlapping path
+func (e *DocumentEditor) lockingIntent(path string, sessionID common.SessionID) (*EditIntent, error) {
+	intents, err := e.Intents()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, intent := range intents {
+		if intent.SessionID != sessionID && intent.covers(path) {
+			return &intent, nil
+		}
+	}
+	return nil, nil
+}
+
+// readIntents decodes all recorded intents, including expired ones, keyed by path
+func (e *DocumentEditor) readIntents() (map[string]EditIntent, error) {
+	result := make(map[string]EditIntent)
+
+	intentsNode, err := e.intentsNode()
+	if err != nil || intentsNode == nil {
+		return result, err
+	}
+
+	for _, path := range intentsNode.Keys() {
+		record, ok := intentsNode.Get(path).Value().(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// 손상된 기록은 무시
+		sessionStr, _ := record["session"].(string)
+		var sessionID common.SessionID
+		if err := sessionID.UnmarshalText([]byte(sessionStr)); err != nil {
+			continue
+		}
+
+		expiresAt, ok := toFloat64(record["expiresAt"])
+		if !ok {
+			continue
+		}
+
+		result[path] = EditIntent{
+			Path:      path,
+			SessionID: sessionID,
+			ExpiresAt: time.UnixMilli(int64(expiresAt)),
+		}
+	}
+	return result, nil
+}
+
+// intentsNode returns the object holding intents, or nil if none were recorded yet
+func (e *DocumentEditor) intentsNode() (*crdt.LWWObjectNode, error) {
+	rootID, err := rootObjectID(e.doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "edit intents require an object root")
+	}
+
+	node, err := e.doc.GetNode(rootID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get root object")
+	}
+
+	intentsNode, _ := node.(*crdt.LWWObjectNode).Get(IntentsKey).(*crdt.LWWObjectNode)
+	return intentsNode, nil
+}
+
+// writeIntents applies the operation built by op to the intents object,
+// creating the object first if needed, and returns the applied patch
+func (e *DocumentEditor) writeIntents(op func(intentsID common.LogicalTimestamp) crdtpatch.Operation) (*crdtpatch.Patch, error) {
+	intentsNode, err := e.intentsNode()
+	if err != nil {
+		return nil, err
+	}
+
+	patch := crdtpatch.NewPatch(e.doc.NextTimestamp())
+
+	var intentsID common.LogicalTimestamp
+	if intentsNode != nil {
+		intentsID = intentsNode.ID()
+	} else {
+		rootID, _ := rootObjectID(e.doc)
+		intentsID = e.doc.NextTimestamp()
+		patch.AddOperation(&crdtpatch.NewOperation{
+			ID:       intentsID,
+			NodeType: common.NodeTypeObj,
+		})
+		patch.AddOperation(&crdtpatch.InsOperation{
+			ID:       e.doc.NextTimestamp(),
+			TargetID: rootID,
+			Value:    map[string]any{IntentsKey: intentsID},
+		})
+	}
+	patch.AddOperation(op(intentsID))
+
+	if err := patch.Apply(e.doc); err != nil {
+		return nil, errors.Wrap(err, "failed to apply intent patch")
+	}
+	e.recordPatch(patch)
+
+	if err := e.NotifyChanges(); err != nil {
+		return patch, err
+	}
+	return patch, nil
+}
+
+// pathsOverlap reports whether one path equals or contains the other
+func pathsOverlap(a, b string) bool {
+	// 루트 경로는 모든 경로와 겹침
+	if a == b || a == "" || b == "" {
+		return true
+	}
+	return isPathPrefix(a, b) || isPathPrefix(b, a)
+}
+
+// isPathPrefix reports whether prefix is an ancestor path of path
+func isPathPrefix(prefix, path string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	next := path[len(prefix)]
+	return next == '.' || next == '['
+}
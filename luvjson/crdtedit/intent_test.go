@@ -0,0 +1,127 @@
+package crdtedit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+)
+
+// TestAcquireIntent tests acquiring, renewing and releasing edit intents
+func TestAcquireIntent(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	alice := common.NewSessionID()
+	bob := common.NewSessionID()
+
+	patch, err := editor.AcquireIntent("players.alice", alice, time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, patch.Operations())
+
+	// 같은 세션은 갱신 가능
+	_, err = editor.AcquireIntent("players.alice", alice, time.Minute)
+	require.NoError(t, err)
+
+	// 다른 세션은 겹치는 경로를 잠글 수 없음
+	_, err = editor.AcquireIntent("players.alice.gold", bob, time.Minute)
+	assert.ErrorIs(t, err, ErrPathLocked)
+	_, err = editor.AcquireIntent("players", bob, time.Minute)
+	assert.ErrorIs(t, err, ErrPathLocked)
+
+	// 겹치지 않는 경로는 가능
+	_, err = editor.AcquireIntent("players.bob", bob, time.Minute)
+	require.NoError(t, err)
+
+	intents, err := editor.Intents()
+	require.NoError(t, err)
+	require.Len(t, intents, 2)
+	assert.Equal(t, "players.alice", intents[0].Path)
+	assert.Equal(t, alice, intents[0].SessionID)
+	assert.Equal(t, "players.bob", intents[1].Path)
+
+	intent, err := editor.IntentFor("players.alice.gold")
+	require.NoError(t, err)
+	require.NotNil(t, intent)
+	assert.Equal(t, alice, intent.SessionID)
+
+	// 다른 세션은 해제할 수 없음
+	_, err = editor.ReleaseIntent("players.alice", bob)
+	assert.Error(t, err)
+
+	_, err = editor.ReleaseIntent("players.alice", alice)
+	require.NoError(t, err)
+
+	intent, err = editor.IntentFor("players.alice.gold")
+	require.NoError(t, err)
+	assert.Nil(t, intent)
+
+	_, err = editor.AcquireIntent("players.alice.gold", bob, time.Minute)
+	require.NoError(t, err)
+}
+
+// TestAcquireIntent_Expiry tests that expired intents no longer lock paths
+func TestAcquireIntent_Expiry(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	alice := common.NewSessionID()
+	bob := common.NewSessionID()
+
+	_, err := editor.AcquireIntent("items", alice, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	intents, err := editor.Intents()
+	require.NoError(t, err)
+	assert.Empty(t, intents)
+
+	_, err = editor.AcquireIntent("items", bob, time.Minute)
+	require.NoError(t, err)
+}
+
+// TestEnforceIntents tests rejection of local writes to paths locked by other sessions
+func TestEnforceIntents(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	remote := common.NewSessionID()
+	_, err := editor.AcquireIntent("players.alice", remote, time.Minute)
+	require.NoError(t, err)
+
+	// 기본값은 권고용이므로 쓰기 허용
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.SetKey("players.alice", "title", "hero")
+	})
+	require.NoError(t, err)
+
+	editor.SetEnforceIntents(true)
+
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.SetKey("players.alice", "title", "knight")
+	})
+	assert.ErrorIs(t, err, ErrPathLocked)
+	assert.ErrorIs(t, editor.SetValue("players.alice.gold", 1), ErrPathLocked)
+
+	// 잠기지 않은 경로는 허용
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.SetKey("players.bob", "title", "knight")
+	})
+	require.NoError(t, err)
+
+	title, err := editor.Query("players.bob.title")
+	require.NoError(t, err)
+	assert.Equal(t, "knight", title)
+
+	// 자신의 세션이 잡은 의도는 쓰기를 막지 않음
+	_, err = editor.AcquireIntent("items", doc.GetSessionID(), time.Minute)
+	require.NoError(t, err)
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.DeleteArrayElement("items", 0)
+	})
+	assert.NotErrorIs(t, err, ErrPathLocked)
+}
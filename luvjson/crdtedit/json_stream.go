@@ -0,0 +1,113 @@
+package crdtedit
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// InitFromJSONStream initializes the document from the JSON value read from r.
+//
+// The input is decoded token by token and nodes are created as values are read,
+// so large documents are never held in memory as generic Go values first.
+// Numbers are stored as float64, as with encoding/json.
+func (e *DocumentEditor) InitFromJSONStream(r io.Reader) error {
+	if r == nil {
+		return errors.New("input reader cannot be nil")
+	}
+
+	dec := json.NewDecoder(r)
+	rootID, err := e.buildJSONStreamNode(dec)
+	if err == io.EOF {
+		return errors.New("input JSON cannot be empty")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to build document from JSON stream")
+	}
+
+	// 하나의 JSON 값만 허용
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after JSON value")
+	}
+
+	if err := e.doc.SetRoot(rootID); err != nil {
+		return errors.Wrap(err, "failed to set root node")
+	}
+	return e.NotifyChanges()
+}
+
+// buildJSONStreamNode reads the next JSON value from dec and returns the ID of its node
+func (e *DocumentEditor) buildJSONStreamNode(dec *json.Decoder) (common.LogicalTimestamp, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return common.NilID, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		// 문자열, 숫자, 불리언, null
+		return e.addConstant(tok), nil
+	}
+
+	switch delim {
+	case '{':
+		obj := crdt.NewLWWObjectNode(e.doc.NextTimestamp())
+		e.doc.AddNode(obj)
+
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return common.NilID, errors.Wrap(err, "failed to read object key")
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return common.NilID, errors.Errorf("unexpected object key %v", keyTok)
+			}
+
+			childID, err := e.buildJSONStreamNode(dec)
+			if err != nil {
+				return common.NilID, errors.Wrapf(err, "failed to build field %s", key)
+			}
+
+			child, err := e.doc.GetNode(childID)
+			if err != nil {
+				return common.NilID, errors.Wrapf(err, "failed to get node for field %s", key)
+			}
+			obj.Set(key, e.doc.NextTimestamp(), child)
+		}
+
+		// 닫는 '}' 소비
+		if _, err := dec.Token(); err != nil {
+			return common.NilID, errors.Wrap(err, "failed to read end of object")
+		}
+		return obj.ID(), nil
+	case '[':
+		arr := crdt.NewRGAArrayNode(e.doc.NextTimestamp())
+		e.doc.AddNode(arr)
+
+		// RGA 배열은 이전 요소 뒤에 삽입 (첫 요소는 RootID 뒤)
+		afterID := common.RootID
+		for i := 0; dec.More(); i++ {
+			childID, err := e.buildJSONStreamNode(dec)
+			if err != nil {
+				return common.NilID, errors.Wrapf(err, "failed to build element at index %d", i)
+			}
+
+			elemID := e.doc.NextTimestamp()
+			arr.Insert(afterID, elemID, childID)
+			afterID = elemID
+		}
+
+		// 닫는 ']' 소비
+		if _, err := dec.Token(); err != nil {
+			return common.NilID, errors.Wrap(err, "failed to read end of array")
+		}
+		return arr.ID(), nil
+	default:
+		return common.NilID, errors.Errorf("unexpected delimiter %v", delim)
+	}
+}
@@ -0,0 +1,66 @@
+package crdtedit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// TestInitFromJSONStream tests building a document from a streamed JSON value
+func TestInitFromJSONStream(t *testing.T) {
+	input := `{
+		"name": "raid",
+		"players": {"alice": {"gold": 100, "online": true}, "bob": {"gold": 40.5, "guild": null}},
+		"items": [{"name": "potion", "qty": 5}, "sword", [1, 2]],
+		"empty": {"list": [], "obj": {}}
+	}`
+
+	editor := NewDocumentEditor(crdt.NewDocument(common.NewSessionID()))
+	// 한 바이트씩 읽어도 동일하게 동작
+	require.NoError(t, editor.InitFromJSONStream(iotest.OneByteReader(strings.NewReader(input))))
+
+	var expected any
+	require.NoError(t, json.Unmarshal([]byte(input), &expected))
+
+	value, err := editor.queryEngine.decodeNode(editor.GetDocument().Root())
+	require.NoError(t, err)
+	assert.Equal(t, expected, value)
+
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), gold)
+
+	name, err := editor.Query("items[0].name")
+	require.NoError(t, err)
+	assert.Equal(t, "potion", name)
+
+	// 최상위 배열
+	editor = NewDocumentEditor(crdt.NewDocument(common.NewSessionID()))
+	require.NoError(t, editor.InitFromJSONStream(strings.NewReader(`[1, "two"]`)))
+	value, err = editor.queryEngine.decodeNode(editor.GetDocument().Root())
+	require.NoError(t, err)
+	assert.Equal(t, []any{float64(1), "two"}, value)
+}
+
+// TestInitFromJSONStream_Errors tests rejection of invalid input
+func TestInitFromJSONStream_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":     "",
+		"invalid":   `{"a": }`,
+		"truncated": `{"a": [1, 2`,
+		"trailing":  `{"a": 1} {"b": 2}`,
+	} {
+		editor := NewDocumentEditor(crdt.NewDocument(common.NewSessionID()))
+		assert.Error(t, editor.InitFromJSONStream(strings.NewReader(input)), name)
+	}
+
+	editor := NewDocumentEditor(crdt.NewDocument(common.NewSessionID()))
+	assert.Error(t, editor.InitFromJSONStream(nil))
+}
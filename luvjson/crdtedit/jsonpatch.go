@@ -0,0 +1,490 @@
+package crdtedit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// JSON Patch (RFC 6902) operation names
+const (
+	JSONPatchAdd     = "add"
+	JSONPatchRemove  = "remove"
+	JSONPatchReplace = "replace"
+	JSONPatchMove    = "move"
+	JSONPatchCopy    = "copy"
+	JSONPatchTest    = "test"
+)
+
+// JSONPatchOperation is a single RFC 6902 JSON Patch operation
+type JSONPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON encodes the operation, always including value for add, replace and test
+// so that explicit null values are preserved
+func (o JSONPatchOperation) MarshalJSON() ([]byte, error) {
+	switch o.Op {
+	case JSONPatchAdd, JSONPatchReplace, JSONPatchTest:
+		return json.Marshal(struct {
+			Op    string `json:"op"`
+			Path  string `json:"path"`
+			Value any    `json:"value"`
+		}{o.Op, o.Path, o.Value})
+	case JSONPatchMove, JSONPatchCopy:
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			From string `json:"from"`
+			Path string `json:"path"`
+		}{o.Op, o.From, o.Path})
+	default:
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+}
+
+// DiffAsJSONPatch returns the RFC 6902 operations that transform the view of docA into the view of docB.
+// Object keys are compared in sorted order so the result is deterministic.
+func DiffAsJSONPatch(docA, docB *crdt.Document) ([]JSONPatchOperation, error) {
+	if docA == nil || docB == nil {
+		return nil, errors.New("documents cannot be nil")
+	}
+
+	a, err := NewQueryEngine(docA, NewPathResolver(docA)).decodeNode(docA.Root())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode first document")
+	}
+	b, err := NewQueryEngine(docB, NewPathResolver(docB)).decodeNode(docB.Root())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode second document")
+	}
+
+	ops := make([]JSONPatchOperation, 0)
+	diffJSONValues("", normalizeJSONValue(a), normalizeJSONValue(b), &ops)
+	return ops, nil
+}
+
+// diffJSONValues appends the operations that transform a into b at pointer
+func diffJSONValues(pointer string, a, b any, ops *[]JSONPatchOperation) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(av)+len(bv))
+		for key := range av {
+			keys = append(keys, key)
+		}
+		for key := range bv {
+			if _, exists := av[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPointer := pointer + "/" + escapeJSONPointer(key)
+			oldValue, oldExists := av[key]
+			newValue, newExists := bv[key]
+			switch {
+			case !newExists:
+				*ops = append(*ops, JSONPatchOperation{Op: JSONPatchRemove, Path: childPointer})
+			case !oldExists:
+				*ops = append(*ops, JSONPatchOperation{Op: JSONPatchAdd, Path: childPointer, Value: newValue})
+			default:
+				diffJSONValues(childPointer, oldValue, newValue, ops)
+			}
+		}
+		return
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+
+		shared := len(av)
+		if len(bv) < shared {
+			shared = len(bv)
+		}
+		for i := 0; i < shared; i++ {
+			diffJSONValues(pointer+"/"+strconv.Itoa(i), av[i], bv[i], ops)
+		}
+		// 남는 요소는 뒤에서부터 제거하여 인덱스가 유지되도록 함
+		for i := len(av) - 1; i >= shared; i-- {
+			*ops = append(*ops, JSONPatchOperation{Op: JSONPatchRemove, Path: pointer + "/" + strconv.Itoa(i)})
+		}
+		for i := shared; i < len(bv); i++ {
+			*ops = append(*ops, JSONPatchOperation{Op: JSONPatchAdd, Path: pointer + "/" + strconv.Itoa(i), Value: bv[i]})
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*ops = append(*ops, JSONPatchOperation{Op: JSONPatchReplace, Path: pointer, Value: b})
+	}
+}
+
+// ApplyJSONPatch translates RFC 6902 operations into CRDT operations and applies them
+// to the document in order. The whole patch is first validated against the current
+// document view, so if any operation fails (including a failed test) the document
+// is left untouched.
+// It returns a single patch containing all generated CRDT operations.
+func (e *DocumentEditor) ApplyJSONPatch(ops []JSONPatchOperation) (*crdtpatch.Patch, error) {
+	root, err := e.queryEngine.decodeNode(e.doc.Root())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode document")
+	}
+
+	// 문서를 건드리기 전에 JSON 값에 먼저 적용하여 검증
+	simulated := normalizeJSONValue(root)
+	for i, op := range ops {
+		simulated, err = applyJSONPatchToValue(simulated, op)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON patch operation %d (%s %s)", i, op.Op, op.Path)
+		}
+	}
+
+	var result *crdtpatch.Patch
+	for i, op := range ops {
+		patch, err := e.applyJSONPatchOperation(op)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply JSON patch operation %d (%s %s)", i, op.Op, op.Path)
+		}
+
+		if patch == nil {
+			continue
+		}
+		if result == nil {
+			result = crdtpatch.NewPatch(patch.ID())
+		}
+		for _, crdtOp := range patch.Operations() {
+			result.AddOperation(crdtOp)
+		}
+	}
+
+	if result == nil {
+		result = crdtpatch.NewPatch(e.doc.NextTimestamp())
+	}
+
+	if err := e.NotifyChanges(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// applyJSONPatchToValue applies a JSON Patch operation to a decoded JSON value and
+// returns the new value; the input value may be modified
+func applyJSONPatchToValue(root any, op JSONPatchOperation) (any, error) {
+	switch op.Op {
+	case JSONPatchTest:
+		current, err := jsonPointerValue(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, normalizeJSONValue(op.Value)) {
+			return nil, errors.Errorf("test failed at path %s", op.Path)
+		}
+		return root, nil
+	case JSONPatchAdd, JSONPatchRemove, JSONPatchReplace:
+		return mutateJSONValue(root, op.Op, op.Path, normalizeJSONValue(op.Value))
+	case JSONPatchCopy, JSONPatchMove:
+		value, err := jsonPointerValue(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == JSONPatchMove {
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, errors.Errorf("cannot move %s into its own child %s", op.From, op.Path)
+			}
+			if root, err = mutateJSONValue(root, JSONPatchRemove, op.From, nil); err != nil {
+				return nil, err
+			}
+		}
+		return mutateJSONValue(root, JSONPatchAdd, op.Path, normalizeJSONValue(value))
+	default:
+		return nil, errors.Errorf("unsupported JSON patch operation %q", op.Op)
+	}
+}
+
+// mutateJSONValue performs an add, remove or replace on a decoded JSON value
+func mutateJSONValue(root any, op, pointer string, value any) (any, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("replacing the document root is not supported")
+	}
+
+	_, parent, err := resolveJSONPointer(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		if _, exists := p[last]; op != JSONPatchAdd && !exists {
+			return nil, errors.Errorf("path %s does not exist", pointer)
+		}
+		if op == JSONPatchRemove {
+			delete(p, last)
+		} else {
+			p[last] = value
+		}
+		return root, nil
+	case []any:
+		index, err := jsonPatchArrayIndex(p, op, last, pointer)
+		if err != nil {
+			return nil, err
+		}
+
+		updated := append([]any{}, p[:index]...)
+		if op != JSONPatchRemove {
+			updated = append(updated, value)
+		}
+		if op == JSONPatchAdd {
+			updated = append(updated, p[index:]...)
+		} else {
+			updated = append(updated, p[index+1:]...)
+		}
+		return replaceJSONValue(root, tokens[:len(tokens)-1], updated)
+	default:
+		return nil, errors.Errorf("parent of path %s is not a container", pointer)
+	}
+}
+
+// replaceJSONValue replaces the value at tokens and returns the new root
+func replaceJSONValue(root any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	_, parent, err := resolveJSONPointer(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[last] = value
+	case []any:
+		index, _ := strconv.Atoi(last)
+		p[index] = value
+	}
+	return root, nil
+}
+
+// jsonPatchArrayIndex parses and bounds-checks the array index token of an operation
+func jsonPatchArrayIndex(arr []any, op, token, pointer string) (int, error) {
+	if token == "-" {
+		if op != JSONPatchAdd {
+			return 0, errors.Errorf("'-' is only valid for add in path %s", pointer)
+		}
+		return len(arr), nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, errors.Errorf("invalid array index %q in path %s", token, pointer)
+	}
+
+	limit := len(arr)
+	if op != JSONPatchAdd {
+		limit--
+	}
+	if index > limit {
+		return 0, errors.Errorf("array index %d out of bounds in path %s", index, pointer)
+	}
+	return index, nil
+}
+
+// applyJSONPatchOperation applies a single JSON Patch operation as its own transaction
+func (e *DocumentEditor) applyJSONPatchOperation(op JSONPatchOperation) (*crdtpatch.Patch, error) {
+	root, err := e.queryEngine.decodeNode(e.doc.Root())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode document")
+	}
+	root = normalizeJSONValue(root)
+
+	switch op.Op {
+	case JSONPatchTest:
+		// test는 검증 단계에서 이미 확인됨
+		return nil, nil
+	case JSONPatchAdd, JSONPatchRemove, JSONPatchReplace:
+		return e.transactionWithoutNotify(func(tx *Transaction) error {
+			return applyJSONPatchMutation(tx, root, op.Op, op.Path, op.Value)
+		})
+	case JSONPatchCopy, JSONPatchMove:
+		value, err := jsonPointerValue(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == JSONPatchMove && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.Errorf("cannot move %s into its own child %s", op.From, op.Path)
+		}
+
+		return e.transactionWithoutNotify(func(tx *Transaction) error {
+			if op.Op == JSONPatchMove {
+				if err := applyJSONPatchMutation(tx, root, JSONPatchRemove, op.From, nil); err != nil {
+					return err
+				}
+			}
+			return applyJSONPatchMutation(tx, root, JSONPatchAdd, op.Path, value)
+		})
+	default:
+		return nil, errors.Errorf("unsupported JSON patch operation %q", op.Op)
+	}
+}
+
+// transactionWithoutNotify runs fn in a transaction without publishing changes on commit
+func (e *DocumentEditor) transactionWithoutNotify(fn TxFunc) (*crdtpatch.Patch, error) {
+	tx := newTransaction(e.doc, e.pathResolver)
+	tx.checkAccess = e.checkAccess
+	tx.onApply = e.recordPatch
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx.Commit()
+}
+
+// applyJSONPatchMutation records the transaction operations for an add, remove or replace
+func applyJSONPatchMutation(tx *Transaction, root any, op, pointer string, value any) error {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errors.New("replacing the document root is not supported")
+	}
+
+	parentPath, parent, err := resolveJSONPointer(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		_, exists := p[last]
+		if op != JSONPatchAdd && !exists {
+			return errors.Errorf("path %s does not exist", pointer)
+		}
+		if op == JSONPatchRemove {
+			return tx.DeleteKey(parentPath, last)
+		}
+		return tx.SetKey(parentPath, last, value)
+	case []any:
+		index, err := jsonPatchArrayIndex(p, op, last, pointer)
+		if err != nil {
+			return err
+		}
+
+		if op != JSONPatchAdd {
+			if err := tx.DeleteArrayElement(parentPath, index); err != nil {
+				return err
+			}
+		}
+		if op != JSONPatchRemove {
+			return tx.InsertArrayElement(parentPath, index, value)
+		}
+		return nil
+	default:
+		return errors.Errorf("parent of path %s is not a container", pointer)
+	}
+}
+
+// resolveJSONPointer walks the decoded document along tokens and returns the
+// equivalent crdtedit path and the value found there
+func resolveJSONPointer(root any, tokens []string) (string, any, error) {
+	path := ""
+	current := root
+	for _, token := range tokens {
+		switch v := current.(type) {
+		case map[string]any:
+			child, ok := v[token]
+			if !ok {
+				return "", nil, errors.Errorf("key %s not found", token)
+			}
+			path = childKeyPath(path, token)
+			current = child
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return "", nil, errors.Errorf("invalid array index %q", token)
+			}
+			path = childIndexPath(path, index)
+			current = v[index]
+		default:
+			return "", nil, errors.Errorf("cannot descend into %T with token %s", current, token)
+		}
+	}
+	return path, current, nil
+}
+
+// jsonPointerValue returns the decoded value at a JSON pointer
+func jsonPointerValue(root any, pointer string) (any, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	_, value, err := resolveJSONPointer(root, tokens)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve path %s", pointer)
+	}
+	return value, nil
+}
+
+// parseJSONPointer splits an RFC 6901 JSON pointer into unescaped reference tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// escapeJSONPointer escapes a reference token for use in a JSON pointer
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// normalizeJSONValue converts a value to its JSON representation (numbers become float64)
+// so that values decoded from documents compare equal to values parsed from JSON
+func normalizeJSONValue(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
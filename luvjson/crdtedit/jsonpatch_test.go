@@ -0,0 +1,131 @@
+package crdtedit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/crdt"
+)
+
+// TestDiffAsJSONPatch tests producing and applying RFC 6902 patches between documents
+func TestDiffAsJSONPatch(t *testing.T) {
+	docA := setupJSONPathDocument(t)
+	docB := setupJSONPathDocument(t)
+
+	// docB 수정: alice 골드 변경, bob 제거, carol 추가
+	root := docB.Root().(*crdt.RootNode).NodeValue.(*crdt.LWWObjectNode)
+	players := root.Get("players").(*crdt.LWWObjectNode)
+	alice := players.Get("alice").(*crdt.LWWObjectNode)
+	gold := crdt.NewConstantNode(docB.NextTimestamp(), float64(150))
+	docB.AddNode(gold)
+	alice.Set("gold", gold.ID(), gold)
+	players.Delete("bob", docB.NextTimestamp())
+	carol := crdt.NewConstantNode(docB.NextTimestamp(), map[string]any{"gold": float64(10)})
+	docB.AddNode(carol)
+	players.Set("car/ol", carol.ID(), carol)
+
+	ops, err := DiffAsJSONPatch(docA, docB)
+	require.NoError(t, err)
+	assert.Equal(t, []JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/players/alice/gold", Value: float64(150)},
+		{Op: JSONPatchRemove, Path: "/players/bob"},
+		{Op: JSONPatchAdd, Path: "/players/car~1ol", Value: map[string]any{"gold": float64(10)}},
+	}, ops)
+
+	data, err := json.Marshal(ops)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"replace","path":"/players/alice/gold","value":150},
+		{"op":"remove","path":"/players/bob"},
+		{"op":"add","path":"/players/car~1ol","value":{"gold":10}}
+	]`, string(data))
+
+	// JSON으로 받은 패치를 docA에 적용하면 docB와 같아짐
+	var received []JSONPatchOperation
+	require.NoError(t, json.Unmarshal(data, &received))
+
+	editor := NewDocumentEditor(docA)
+	patch, err := editor.ApplyJSONPatch(received)
+	require.NoError(t, err)
+	assert.NotEmpty(t, patch.Operations())
+
+	ops, err = DiffAsJSONPatch(docA, docB)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
+
+// TestApplyJSONPatch tests test, copy and move operations and rollback on failure
+func TestApplyJSONPatch(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	_, err := editor.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchTest, Path: "/players/alice/gold", Value: 100},
+		{Op: JSONPatchCopy, From: "/players/alice/gold", Path: "/players/bob/bonus"},
+		{Op: JSONPatchMove, From: "/players/bob/gold", Path: "/players/bob/savings"},
+	})
+	require.NoError(t, err)
+
+	bonus, err := editor.Query("players.bob.bonus")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), bonus)
+
+	savings, err := editor.Query("players.bob.savings")
+	require.NoError(t, err)
+	assert.Equal(t, float64(40), savings)
+
+	_, err = editor.Query("players.bob.gold")
+	assert.Error(t, err)
+
+	// test 실패 시 앞선 변경도 적용되지 않음
+	_, err = editor.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchAdd, Path: "/players/alice/title", Value: "hero"},
+		{Op: JSONPatchTest, Path: "/players/alice/gold", Value: 1},
+	})
+	assert.Error(t, err)
+
+	_, err = editor.Query("players.alice.title")
+	assert.Error(t, err)
+
+	// 잘못된 연산
+	_, err = editor.ApplyJSONPatch([]JSONPatchOperation{{Op: "merge", Path: "/players"}})
+	assert.Error(t, err)
+	_, err = editor.ApplyJSONPatch([]JSONPatchOperation{{Op: JSONPatchRemove, Path: "/players/nobody"}})
+	assert.Error(t, err)
+}
+
+// TestApplyJSONPatchToValue tests JSON Patch evaluation on plain JSON values
+func TestApplyJSONPatchToValue(t *testing.T) {
+	var root any
+	require.NoError(t, json.Unmarshal([]byte(`{"items":["a","b"],"a/b":{"~x":1}}`), &root))
+
+	ops := []JSONPatchOperation{
+		{Op: JSONPatchAdd, Path: "/items/1", Value: "c"},
+		{Op: JSONPatchAdd, Path: "/items/-", Value: "d"},
+		{Op: JSONPatchRemove, Path: "/items/0"},
+		{Op: JSONPatchReplace, Path: "/a~1b/~0x", Value: 2},
+		{Op: JSONPatchMove, From: "/items/2", Path: "/last"},
+	}
+
+	var err error
+	for _, op := range ops {
+		root, err = applyJSONPatchToValue(root, op)
+		require.NoError(t, err, "%s %s", op.Op, op.Path)
+	}
+
+	assert.Equal(t, map[string]any{
+		"items": []any{"c", "b"},
+		"a/b":   map[string]any{"~x": float64(2)},
+		"last":  "d",
+	}, root)
+
+	_, err = applyJSONPatchToValue(root, JSONPatchOperation{Op: JSONPatchRemove, Path: "/items/5"})
+	assert.Error(t, err)
+	_, err = applyJSONPatchToValue(root, JSONPatchOperation{Op: JSONPatchReplace, Path: "/items/-", Value: 1})
+	assert.Error(t, err)
+	_, err = applyJSONPatchToValue(root, JSONPatchOperation{Op: JSONPatchMove, From: "/a~1b", Path: "/a~1b/child"})
+	assert.Error(t, err)
+}
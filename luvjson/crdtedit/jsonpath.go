@@ -0,0 +1,554 @@
+package crdtedit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// QueryResult represents a single match of a JSONPath query
+type QueryResult struct {
+	// Path is the matched node path in crdtedit path syntax (e.g. "players.alice.gold", "items[2]")
+	Path string
+	// Value is the decoded Go value of the matched node
+	Value any
+}
+
+// jsonPathSegmentKind identifies the selector of a JSONPath segment
+type jsonPathSegmentKind int
+
+const (
+	jsonPathSelectKey jsonPathSegmentKind = iota
+	jsonPathSelectIndex
+	jsonPathSelectWildcard
+	jsonPathSelectSlice
+	jsonPathSelectFilter
+)
+
+// jsonPathSegment is a single parsed step of a JSONPath expression
+type jsonPathSegment struct {
+	kind      jsonPathSegmentKind
+	recursive bool
+	key       string
+	index     int
+	start     *int
+	end       *int
+	filter    *jsonPathFilter
+}
+
+// jsonPathFilter is a parsed filter expression: OR of ANDs of comparisons
+type jsonPathFilter struct {
+	or [][]jsonPathCondition
+}
+
+// jsonPathCondition is a single comparison inside a filter expression
+type jsonPathCondition struct {
+	path    []string
+	op      string
+	operand any
+}
+
+// QueryJSONPath evaluates a JSONPath expression against the document.
+// Supported syntax:
+//   - $                      document root
+//   - .key, ['key']          child by name
+//   - [n], [-n]              array index
+//   - [start:end]            array slice
+//   - .*, [*]                wildcard
+//   - ..key, ..*             recursive descent
+//   - [?(@.a.b > 3)]         filter (==, !=, <, <=, >, >=, existence, &&, ||)
+func (e *QueryEngine) QueryJSONPath(expr string) ([]QueryResult, error) {
+	segments, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse JSONPath %s", expr)
+	}
+
+	root, err := e.decodeNode(e.doc.Root())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode document")
+	}
+
+	// 계산 필드를 루트 객체에 노출 (문서 필드가 우선)
+	if obj, ok := root.(map[string]any); ok && e.computed != nil {
+		for name, value := range e.computed.values() {
+			if _, exists := obj[name]; !exists {
+				obj[name] = value
+			}
+		}
+	}
+
+	current := []QueryResult{{Path: "", Value: root}}
+	for _, segment := range segments {
+		if segment.recursive {
+			current = descendants(current)
+		}
+
+		next := make([]QueryResult, 0)
+		for _, match := range current {
+			next = append(next, segment.apply(match)...)
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+// apply applies the segment selector to a single match
+func (s *jsonPathSegment) apply(match QueryResult) []QueryResult {
+	results := make([]QueryResult, 0)
+
+	switch s.kind {
+	case jsonPathSelectKey:
+		if obj, ok := match.Value.(map[string]any); ok {
+			if value, exists := obj[s.key]; exists {
+				results = append(results, QueryResult{Path: childKeyPath(match.Path, s.key), Value: value})
+			}
+		}
+	case jsonPathSelectIndex:
+		if arr, ok := match.Value.([]any); ok {
+			index := s.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				results = append(results, QueryResult{Path: childIndexPath(match.Path, index), Value: arr[index]})
+			}
+		}
+	case jsonPathSelectSlice:
+		if arr, ok := match.Value.([]any); ok {
+			start, end := 0, len(arr)
+			if s.start != nil {
+				start = normalizeSliceBound(*s.start, len(arr))
+			}
+			if s.end != nil {
+				end = normalizeSliceBound(*s.end, len(arr))
+			}
+			for i := start; i < end; i++ {
+				results = append(results, QueryResult{Path: childIndexPath(match.Path, i), Value: arr[i]})
+			}
+		}
+	case jsonPathSelectWildcard, jsonPathSelectFilter:
+		for _, child := range children(match) {
+			if s.kind == jsonPathSelectFilter && !s.filter.matches(child.Value) {
+				continue
+			}
+			results = append(results, child)
+		}
+	}
+
+	return results
+}
+
+// children returns the direct children of a match in a deterministic order
+func children(match QueryResult) []QueryResult {
+	switch v := match.Value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		results := make([]QueryResult, 0, len(keys))
+		for _, key := range keys {
+			results = append(results, QueryResult{Path: childKeyPath(match.Path, key), Value: v[key]})
+		}
+		return results
+	case []any:
+		results := make([]QueryResult, 0, len(v))
+		for i, elem := range v {
+			results = append(results, QueryResult{Path: childIndexPath(match.Path, i), Value: elem})
+		}
+		return results
+	default:
+		return nil
+	}
+}
+
+// descendants returns the matches and all of their descendants (pre-order)
+func descendants(matches []QueryResult) []QueryResult {
+	results := make([]QueryResult, 0)
+	for _, match := range matches {
+		results = append(results, match)
+		results = append(results, descendants(children(match))...)
+	}
+	return results
+}
+
+// childKeyPath builds the path of an object child
+func childKeyPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// childIndexPath builds the path of an array element
+func childIndexPath(parent string, index int) string {
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
+
+// normalizeSliceBound converts a possibly negative slice bound into a valid index
+func normalizeSliceBound(bound, length int) int {
+	if bound < 0 {
+		bound += length
+	}
+	if bound < 0 {
+		return 0
+	}
+	if bound > length {
+		return length
+	}
+	return bound
+}
+
+// parseJSONPath parses a JSONPath expression into segments
+func parseJSONPath(expr string) ([]jsonPathSegment, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, errors.New("expression must start with $")
+	}
+
+	segments := make([]jsonPathSegment, 0)
+	i := 1
+	for i < len(expr) {
+		recursive := false
+		switch {
+		case strings.HasPrefix(expr[i:], ".."):
+			recursive = true
+			i += 2
+		case expr[i] == '.':
+			i++
+		case expr[i] == '[':
+			// 대괄호 선택자는 아래에서 처리
+		default:
+			return nil, errors.Errorf("unexpected character %q at position %d", expr[i], i)
+		}
+
+		if i >= len(expr) {
+			return nil, errors.New("unexpected end of expression")
+		}
+
+		if expr[i] == '[' {
+			end, err := findClosingBracket(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			segment, err := parseBracketSelector(expr[i+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			segment.recursive = recursive
+			segments = append(segments, segment)
+			i = end + 1
+			continue
+		}
+
+		// 점 표기법 이름
+		start := i
+		for i < len(expr) && expr[i] != '.' && expr[i] != '[' {
+			i++
+		}
+		name := expr[start:i]
+		if name == "" {
+			return nil, errors.Errorf("empty name at position %d", start)
+		}
+
+		if name == "*" {
+			segments = append(segments, jsonPathSegment{kind: jsonPathSelectWildcard, recursive: recursive})
+		} else {
+			segments = append(segments, jsonPathSegment{kind: jsonPathSelectKey, key: name, recursive: recursive})
+		}
+	}
+
+	return segments, nil
+}
+
+// findClosingBracket finds the matching ']' for the '[' at position open, skipping quoted strings
+func findClosingBracket(expr string, open int) (int, error) {
+	depth := 0
+	var quote byte
+	for i := open; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.Errorf("unclosed bracket at position %d", open)
+}
+
+// parseBracketSelector parses the contents of a bracket selector
+func parseBracketSelector(content string) (jsonPathSegment, error) {
+	content = strings.TrimSpace(content)
+
+	switch {
+	case content == "*":
+		return jsonPathSegment{kind: jsonPathSelectWildcard}, nil
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		filter, err := parseJSONPathFilter(content[2 : len(content)-1])
+		if err != nil {
+			return jsonPathSegment{}, err
+		}
+		return jsonPathSegment{kind: jsonPathSelectFilter, filter: filter}, nil
+	case isQuoted(content):
+		return jsonPathSegment{kind: jsonPathSelectKey, key: content[1 : len(content)-1]}, nil
+	case strings.Contains(content, ":"):
+		parts := strings.SplitN(content, ":", 2)
+		segment := jsonPathSegment{kind: jsonPathSelectSlice}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			bound, err := strconv.Atoi(part)
+			if err != nil {
+				return jsonPathSegment{}, errors.Errorf("invalid slice bound: %s", part)
+			}
+			if i == 0 {
+				segment.start = &bound
+			} else {
+				segment.end = &bound
+			}
+		}
+		return segment, nil
+	default:
+		index, err := strconv.Atoi(content)
+		if err != nil {
+			return jsonPathSegment{}, errors.Errorf("invalid bracket selector: %s", content)
+		}
+		return jsonPathSegment{kind: jsonPathSelectIndex, index: index}, nil
+	}
+}
+
+// parseJSONPathFilter parses a filter expression such as "@.qty > 3 && @.name == 'sword'"
+func parseJSONPathFilter(expr string) (*jsonPathFilter, error) {
+	filter := &jsonPathFilter{}
+	for _, orPart := range splitOutsideQuotes(expr, "||") {
+		conditions := make([]jsonPathCondition, 0)
+		for _, andPart := range splitOutsideQuotes(orPart, "&&") {
+			condition, err := parseJSONPathCondition(strings.TrimSpace(andPart))
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, condition)
+		}
+		filter.or = append(filter.or, conditions)
+	}
+	return filter, nil
+}
+
+// parseJSONPathCondition parses a single comparison inside a filter
+func parseJSONPathCondition(expr string) (jsonPathCondition, error) {
+	operators := []string{"==", "!=", "<=", ">=", "<", ">"}
+
+	left, op, right := expr, "", ""
+	for _, candidate := range operators {
+		if idx := indexOutsideQuotes(expr, candidate); idx >= 0 {
+			left = strings.TrimSpace(expr[:idx])
+			op = candidate
+			right = strings.TrimSpace(expr[idx+len(candidate):])
+			break
+		}
+	}
+
+	if left != "@" && !strings.HasPrefix(left, "@.") {
+		return jsonPathCondition{}, errors.Errorf("filter operand must start with @: %s", left)
+	}
+
+	condition := jsonPathCondition{op: op}
+	if left != "@" {
+		condition.path = strings.Split(left[2:], ".")
+	}
+
+	if op != "" {
+		operand, err := parseJSONPathLiteral(right)
+		if err != nil {
+			return jsonPathCondition{}, err
+		}
+		condition.operand = operand
+	}
+
+	return condition, nil
+}
+
+// parseJSONPathLiteral parses a literal value used in a filter comparison
+func parseJSONPathLiteral(literal string) (any, error) {
+	switch {
+	case isQuoted(literal):
+		return literal[1 : len(literal)-1], nil
+	case literal == "true":
+		return true, nil
+	case literal == "false":
+		return false, nil
+	case literal == "null":
+		return nil, nil
+	}
+
+	number, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid filter literal: %s", literal)
+	}
+	return number, nil
+}
+
+// matches reports whether the value satisfies the filter
+func (f *jsonPathFilter) matches(value any) bool {
+	for _, conditions := range f.or {
+		matched := true
+		for _, condition := range conditions {
+			if !condition.matches(value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the value satisfies the condition
+func (c *jsonPathCondition) matches(value any) bool {
+	current := value
+	for _, key := range c.path {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return false
+		}
+		current, ok = obj[key]
+		if !ok {
+			return false
+		}
+	}
+
+	// 연산자가 없으면 존재 여부만 확인
+	if c.op == "" {
+		return true
+	}
+
+	if lf, lok := toFloat64(current); lok {
+		if rf, rok := toFloat64(c.operand); rok {
+			switch c.op {
+			case "==":
+				return lf == rf
+			case "!=":
+				return lf != rf
+			case "<":
+				return lf < rf
+			case "<=":
+				return lf <= rf
+			case ">":
+				return lf > rf
+			case ">=":
+				return lf >= rf
+			}
+		}
+	}
+
+	if ls, lok := current.(string); lok {
+		if rs, rok := c.operand.(string); rok {
+			switch c.op {
+			case "==":
+				return ls == rs
+			case "!=":
+				return ls != rs
+			case "<":
+				return ls < rs
+			case "<=":
+				return ls <= rs
+			case ">":
+				return ls > rs
+			case ">=":
+				return ls >= rs
+			}
+		}
+	}
+
+	switch c.op {
+	case "==":
+		return current == c.operand
+	case "!=":
+		return current != c.operand
+	default:
+		return false
+	}
+}
+
+// toFloat64 converts a numeric value to float64
+func toFloat64(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// isQuoted reports whether s is wrapped in single or double quotes
+func isQuoted(s string) bool {
+	return len(s) >= 2 && ((s[0] == '\'' && s[len(s)-1] == '\'') || (s[0] == '"' && s[len(s)-1] == '"'))
+}
+
+// indexOutsideQuotes returns the index of sep in s ignoring quoted sections, or -1
+func indexOutsideQuotes(s, sep string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitOutsideQuotes splits s by sep ignoring quoted sections
+func splitOutsideQuotes(s, sep string) []string {
+	parts := make([]string, 0)
+	for {
+		idx := indexOutsideQuotes(s, sep)
+		if idx < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:idx])
+		s = s[idx+len(sep):]
+	}
+}
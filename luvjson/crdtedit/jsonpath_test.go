@@ -0,0 +1,111 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// setupJSONPathDocument creates a document with nested objects and arrays
+// built directly from nodes so that the query tests do not depend on patch application
+func setupJSONPathDocument(t *testing.T) *crdt.Document {
+	doc := setupTestDocument(t)
+	root := doc.Root().(*crdt.RootNode).NodeValue.(*crdt.LWWObjectNode)
+
+	// 헬퍼: 객체에 상수 필드 추가
+	setConst := func(obj *crdt.LWWObjectNode, key string, value any) {
+		node := crdt.NewConstantNode(doc.NextTimestamp(), value)
+		doc.AddNode(node)
+		obj.Set(key, node.ID(), node)
+	}
+
+	// items 배열 생성
+	items := crdt.NewRGAArrayNode(doc.NextTimestamp())
+	doc.AddNode(items)
+	root.Set("items", items.ID(), items)
+
+	// RGA 배열은 이전 요소 뒤에 삽입 (첫 요소는 RootID 뒤)
+	afterID := common.RootID
+	for _, item := range []struct {
+		name string
+		qty  float64
+	}{{"potion", 5}, {"sword", 1}, {"arrow", 20}} {
+		obj := crdt.NewLWWObjectNode(doc.NextTimestamp())
+		doc.AddNode(obj)
+		setConst(obj, "name", item.name)
+		setConst(obj, "qty", item.qty)
+
+		elemID := doc.NextTimestamp()
+		items.Insert(afterID, elemID, obj.ID())
+		afterID = elemID
+	}
+
+	// players 객체 생성
+	players := crdt.NewLWWObjectNode(doc.NextTimestamp())
+	doc.AddNode(players)
+	root.Set("players", players.ID(), players)
+
+	for name, gold := range map[string]float64{"alice": 100, "bob": 40} {
+		player := crdt.NewLWWObjectNode(doc.NextTimestamp())
+		doc.AddNode(player)
+		setConst(player, "gold", gold)
+		players.Set(name, player.ID(), player)
+	}
+
+	return doc
+}
+
+// TestQueryJSONPath tests JSONPath evaluation over a document
+func TestQueryJSONPath(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	tests := []struct {
+		name  string
+		expr  string
+		paths []string
+		want  []any
+	}{
+		{"child", "$.players.alice.gold", []string{"players.alice.gold"}, []any{float64(100)}},
+		{"bracket key", "$['players']['bob'].gold", []string{"players.bob.gold"}, []any{float64(40)}},
+		{"wildcard", "$.players.*.gold", []string{"players.alice.gold", "players.bob.gold"}, []any{float64(100), float64(40)}},
+		{"index", "$.items[1].name", []string{"items[1].name"}, []any{"sword"}},
+		{"negative index", "$.items[-1].name", []string{"items[2].name"}, []any{"arrow"}},
+		{"slice", "$.items[0:2].name", []string{"items[0].name", "items[1].name"}, []any{"potion", "sword"}},
+		{"filter", "$.items[?(@.qty>3)].name", []string{"items[0].name", "items[2].name"}, []any{"potion", "arrow"}},
+		{"filter string", "$.items[?(@.name == 'sword')].qty", []string{"items[1].qty"}, []any{float64(1)}},
+		{"filter and", "$.items[?(@.qty > 3 && @.qty < 10)].name", []string{"items[0].name"}, []any{"potion"}},
+		{"recursive descent", "$..gold", []string{"players.alice.gold", "players.bob.gold"}, []any{float64(100), float64(40)}},
+		{"no match", "$.players.carol", []string{}, []any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := editor.QueryJSONPath(tt.expr)
+			require.NoError(t, err)
+
+			paths := make([]string, 0, len(results))
+			values := make([]any, 0, len(results))
+			for _, result := range results {
+				paths = append(paths, result.Path)
+				values = append(values, result.Value)
+			}
+			assert.Equal(t, tt.paths, paths)
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+// TestQueryJSONPath_InvalidExpression tests parsing errors
+func TestQueryJSONPath_InvalidExpression(t *testing.T) {
+	editor := NewDocumentEditor(setupJSONPathDocument(t))
+
+	for _, expr := range []string{"players", "$.items[", "$.items[abc]", "$.items[?(qty > 1)]"} {
+		_, err := editor.QueryJSONPath(expr)
+		assert.Error(t, err, "expression %s should be rejected", expr)
+	}
+}
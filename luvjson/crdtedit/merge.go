@@ -0,0 +1,606 @@
+package crdtedit
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// MergeConflictKind describes why a merge had to arbitrate between branches
+type MergeConflictKind string
+
+const (
+	// MergeConflictUpdate means both branches changed the same value differently.
+	// The write with the greater timestamp wins, as in LWW registers.
+	MergeConflictUpdate MergeConflictKind = "update"
+	// MergeConflictDelete means one branch deleted a value the other branch changed.
+	// Object fields keep the change; array elements stay deleted.
+	MergeConflictDelete MergeConflictKind = "delete"
+)
+
+// MergeConflict describes a path where the merge picked one branch over the other
+type MergeConflict struct {
+	// Path is the path of the conflicting value in the merged document
+	Path string
+	// Kind is the kind of conflict
+	Kind MergeConflictKind
+	// Base is the value in the common ancestor, or nil if it did not exist
+	Base any
+	// ValueA is the value in branch A, or nil if it was deleted
+	ValueA any
+	// ValueB is the value in branch B, or nil if it was deleted
+	ValueB any
+	// Resolved is the value kept in the merged document, or nil if it was deleted
+	Resolved any
+}
+
+// MergeResult is the result of merging two branches of a document
+type MergeResult struct {
+	// Document is the merged document. It uses the session ID of branch A.
+	Document *crdt.Document
+	// Conflicts lists the paths where merge semantics required arbitration
+	Conflicts []MergeConflict
+}
+
+// Merge merges two independently edited copies of the same document.
+//
+// base is the common ancestor the branches were copied from; it is used to tell
+// additions from deletions and to detect which branch changed a value. Nodes keep
+// their IDs, so the merged document can keep exchanging patches with both branches.
+// The inputs are not modified.
+func Merge(base, branchA, branchB *crdt.Document) (*MergeResult, error) {
+	if base == nil || branchA == nil || branchB == nil {
+		return nil, errors.New("documents to merge cannot be nil")
+	}
+
+	m := &merger{
+		base:     base,
+		a:        branchA,
+		b:        branchB,
+		result:   crdt.NewDocument(branchA.GetSessionID()),
+		decoders: make(map[*crdt.Document]*QueryEngine),
+	}
+
+	root, err := m.mergeRegister("", rootRegister(base), rootRegister(branchA), rootRegister(branchB))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge documents")
+	}
+	if root != nil && root.node != nil {
+		if err := m.result.SetRoot(root.node.ID()); err != nil {
+			return nil, errors.Wrap(err, "failed to set merged root")
+		}
+	}
+
+	// 병합된 ID 이후의 타임스탬프를 생성하도록 로컬 시계 전진
+	next := m.result.NextTimestamp()
+	for next.Counter < m.maxCounter {
+		next = m.result.NextTimestamp()
+	}
+
+	// 충돌 해결 값은 병합 문서 기준으로 디코딩
+	for i := range m.conflicts {
+		if m.conflicts[i].resolved != nil {
+			m.conflicts[i].Resolved = m.decode(m.result, m.conflicts[i].resolved)
+		}
+	}
+
+	conflicts := make([]MergeConflict, len(m.conflicts))
+	for i, c := range m.conflicts {
+		conflicts[i] = c.MergeConflict
+	}
+	return &MergeResult{Document: m.result, Conflicts: conflicts}, nil
+}
+
+// merger holds the state of a three-way merge
+type merger struct {
+	base, a, b *crdt.Document
+	result     *crdt.Document
+	decoders   map[*crdt.Document]*QueryEngine
+	conflicts  []pendingConflict
+	maxCounter uint64
+}
+
+// pendingConflict is a conflict whose resolved value is decoded once the merge completes
+type pendingConflict struct {
+	MergeConflict
+	resolved crdt.Node
+}
+
+// register is a last-writer-wins slot: an object field, a value node or the document root
+type register struct {
+	timestamp common.LogicalTimestamp
+	node      crdt.Node
+}
+
+// rootRegister returns the register holding the document root value
+func rootRegister(doc *crdt.Document) *register {
+	root, ok := doc.Root().(*crdt.RootNode)
+	if !ok || root.NodeValue == nil {
+		return nil
+	}
+	// 루트는 새 노드로 교체되므로 값 노드의 ID를 쓰기 시각으로 사용
+	return &register{timestamp: root.NodeValue.ID(), node: root.NodeValue}
+}
+
+// fieldRegister returns the register of an object field, or nil if the field does not exist
+func fieldRegister(obj *crdt.LWWObjectNode, key string) *register {
+	if obj == nil {
+		return nil
+	}
+	field, ok := obj.NodeFields[key]
+	if !ok {
+		return nil
+	}
+	return &register{timestamp: field.NodeTimestamp, node: field.NodeValue}
+}
+
+// mergeRegister merges one register of the base and both branches
+func (m *merger) mergeRegister(path string, base, a, b *register) (*register, error) {
+	switch {
+	case a == nil && b == nil:
+		return nil, nil
+	case a == nil || b == nil:
+		kept, keptDoc := a, m.a
+		if a == nil {
+			kept, keptDoc = b, m.b
+		}
+
+		// 한쪽에서만 추가됨
+		if base == nil {
+			return m.copyRegister(kept, keptDoc)
+		}
+
+		// 다른 쪽에서 삭제되었고 이쪽은 변경 없음
+		if !m.changed(base, kept, keptDoc) {
+			return nil, nil
+		}
+
+		// 삭제는 툼스톤을 남기지 않으므로 변경된 값을 유지
+		copied, err := m.copyRegister(kept, keptDoc)
+		if err != nil {
+			return nil, err
+		}
+		m.addConflict(path, MergeConflictDelete, base, a, b, copied.node)
+		return copied, nil
+	case a.timestamp.Compare(b.timestamp) == 0:
+		// 같은 쓰기이므로 하위 노드 병합
+		node, err := m.mergeNode(path, a.node, b.node)
+		if err != nil {
+			return nil, err
+		}
+		return &register{timestamp: a.timestamp, node: node}, nil
+	}
+
+	aWritten := base == nil || base.timestamp.Compare(a.timestamp) != 0
+	bWritten := base == nil || base.timestamp.Compare(b.timestamp) != 0
+
+	var winner, loser *register
+	var winnerDoc, loserDoc *crdt.Document
+	switch {
+	case !aWritten:
+		winner, winnerDoc, loser, loserDoc = b, m.b, a, m.a
+	case !bWritten:
+		winner, winnerDoc, loser, loserDoc = a, m.a, b, m.b
+	case a.timestamp.Compare(b.timestamp) > 0:
+		winner, winnerDoc, loser, loserDoc = a, m.a, b, m.b
+	default:
+		winner, winnerDoc, loser, loserDoc = b, m.b, a, m.a
+	}
+
+	copied, err := m.copyRegister(winner, winnerDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	// 진 쪽이 값을 바꾸지 않았거나 같은 값이면 충돌 아님
+	if (base != nil && !m.changed(base, loser, loserDoc)) ||
+		reflect.DeepEqual(m.decode(winnerDoc, winner.node), m.decode(loserDoc, loser.node)) {
+		return copied, nil
+	}
+
+	m.addConflict(path, MergeConflictUpdate, base, a, b, copied.node)
+	return copied, nil
+}
+
+// mergeNode merges the same node as it exists in both branches
+func (m *merger) mergeNode(path string, a, b crdt.Node) (crdt.Node, error) {
+	if a == nil {
+		return nil, nil
+	}
+	if existing, ok := m.copied(a.ID()); ok {
+		return existing, nil
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return nil, errors.Errorf("node %v at path %s has different types: %T and %T", a.ID(), path, a, b)
+	}
+
+	var base crdt.Node
+	if node, err := m.base.GetNode(a.ID()); err == nil {
+		base = node
+	}
+
+	switch an := a.(type) {
+	case *crdt.LWWObjectNode:
+		bn := b.(*crdt.LWWObjectNode)
+		baseObj, _ := base.(*crdt.LWWObjectNode)
+
+		result := crdt.NewLWWObjectNode(an.ID())
+		m.add(result)
+
+		for _, key := range mergedKeys(an, bn) {
+			reg, err := m.mergeRegister(childKeyPath(path, key), fieldRegister(baseObj, key), fieldRegister(an, key), fieldRegister(bn, key))
+			if err != nil {
+				return nil, err
+			}
+			if reg != nil {
+				result.NodeFields[key] = &crdt.LWWObjectField{NodeTimestamp: reg.timestamp, NodeValue: reg.node}
+				m.observe(reg.timestamp)
+			}
+		}
+		return result, nil
+	case *crdt.LWWValueNode:
+		bn := b.(*crdt.LWWValueNode)
+		var baseReg *register
+		if baseVal, ok := base.(*crdt.LWWValueNode); ok {
+			baseReg = &register{timestamp: baseVal.NodeTimestamp, node: baseVal.NodeValue}
+		}
+
+		result := crdt.NewLWWValueNode(an.ID(), an.NodeTimestamp, nil)
+		m.add(result)
+
+		reg, err := m.mergeRegister(path, baseReg,
+			&register{timestamp: an.NodeTimestamp, node: an.NodeValue},
+			&register{timestamp: bn.NodeTimestamp, node: bn.NodeValue})
+		if err != nil {
+			return nil, err
+		}
+		if reg != nil {
+			result.NodeTimestamp = reg.timestamp
+			result.NodeValue = reg.node
+			m.observe(reg.timestamp)
+		}
+		return result, nil
+	case *crdt.RGAArrayNode:
+		result := crdt.NewRGAArrayNode(an.ID())
+		m.add(result)
+
+		elements := mergeRGAElements(an.NodeElements, b.(*crdt.RGAArrayNode).NodeElements)
+		index := 0
+		for _, elem := range elements {
+			if err := m.mergeArrayElement(childIndexPath(path, index), elem); err != nil {
+				return nil, err
+			}
+			result.NodeElements = append(result.NodeElements, elem.RGAElement)
+			m.observe(elem.NodeId)
+			if !elem.NodeDeleted {
+				index++
+			}
+		}
+		return result, nil
+	case *crdt.RGAStringNode:
+		result := crdt.NewRGAStringNode(an.ID())
+		m.add(result)
+
+		// 청크 경계는 복제본마다 다르므로 문자 단위로 병합
+		for _, elem := range mergeRGAElements(an.Characters(), b.(*crdt.RGAStringNode).Characters()) {
+			result.NodeElements = append(result.NodeElements, elem.RGAElement)
+			m.observe(elem.NodeId)
+		}
+		result.Compact()
+		return result, nil
+	default:
+		return m.copyNode(a, m.a)
+	}
+}
+
+// mergeArrayElement merges the node referenced by an array element
+func (m *merger) mergeArrayElement(path string, elem mergedElement) error {
+	nodeID, ok := elem.NodeValue.(common.LogicalTimestamp)
+	if !ok {
+		return nil
+	}
+
+	aNode, aErr := m.a.GetNode(nodeID)
+	bNode, bErr := m.b.GetNode(nodeID)
+
+	switch {
+	case elem.inA && elem.inB && aErr == nil && bErr == nil:
+		// 한쪽에서 삭제된 요소를 다른 쪽에서 수정했으면 보고 (요소는 삭제 유지)
+		if elem.deletedA != elem.deletedB {
+			if baseNode, err := m.base.GetNode(nodeID); err == nil {
+				kept, keptDoc := aNode, m.a
+				if elem.deletedA {
+					kept, keptDoc = bNode, m.b
+				}
+				if !reflect.DeepEqual(m.decode(m.base, baseNode), m.decode(keptDoc, kept)) {
+					var valueA, valueB any
+					if !elem.deletedA {
+						valueA = m.decode(m.a, aNode)
+					}
+					if !elem.deletedB {
+						valueB = m.decode(m.b, bNode)
+					}
+					m.conflicts = append(m.conflicts, pendingConflict{MergeConflict: MergeConflict{
+						Path:   path,
+						Kind:   MergeConflictDelete,
+						Base:   m.decode(m.base, baseNode),
+						ValueA: valueA,
+						ValueB: valueB,
+					}})
+				}
+			}
+		}
+		_, err := m.mergeNode(path, aNode, bNode)
+		return err
+	case aErr == nil && elem.inA:
+		_, err := m.copyNode(aNode, m.a)
+		return err
+	case bErr == nil && elem.inB:
+		_, err := m.copyNode(bNode, m.b)
+		return err
+	default:
+		return errors.Errorf("node %v of array element at path %s not found", nodeID, path)
+	}
+}
+
+// copyRegister copies the register's value subtree from doc into the merged document
+func (m *merger) copyRegister(reg *register, doc *crdt.Document) (*register, error) {
+	node, err := m.copyNode(reg.node, doc)
+	if err != nil {
+		return nil, err
+	}
+	return &register{timestamp: reg.timestamp, node: node}, nil
+}
+
+// copyNode copies a node and its descendants from doc into the merged document
+func (m *merger) copyNode(node crdt.Node, doc *crdt.Document) (crdt.Node, error) {
+	if node == nil {
+		return nil, nil
+	}
+	if existing, ok := m.copied(node.ID()); ok {
+		return existing, nil
+	}
+
+	switch n := node.(type) {
+	case *crdt.ConstantNode:
+		result := crdt.NewConstantNode(n.NodeId, n.NodeValue)
+		m.add(result)
+		return result, nil
+	case *crdt.LWWValueNode:
+		result := crdt.NewLWWValueNode(n.NodeId, n.NodeTimestamp, nil)
+		m.add(result)
+		m.observe(n.NodeTimestamp)
+
+		value, err := m.copyNode(n.NodeValue, doc)
+		if err != nil {
+			return nil, err
+		}
+		result.NodeValue = value
+		return result, nil
+	case *crdt.LWWObjectNode:
+		result := crdt.NewLWWObjectNode(n.NodeId)
+		m.add(result)
+
+		for key, field := range n.NodeFields {
+			value, err := m.copyNode(field.NodeValue, doc)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to copy field %s", key)
+			}
+			result.NodeFields[key] = &crdt.LWWObjectField{NodeTimestamp: field.NodeTimestamp, NodeValue: value}
+			m.observe(field.NodeTimestamp)
+		}
+		return result, nil
+	case *crdt.RGAArrayNode:
+		result := crdt.NewRGAArrayNode(n.NodeId)
+		m.add(result)
+
+		for _, elem := range n.NodeElements {
+			if nodeID, ok := elem.NodeValue.(common.LogicalTimestamp); ok {
+				elemNode, err := doc.GetNode(nodeID)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to get node of array element %v", elem.NodeId)
+				}
+				if _, err := m.copyNode(elemNode, doc); err != nil {
+					return nil, err
+				}
+			}
+			copied := *elem
+			result.NodeElements = append(result.NodeElements, &copied)
+			m.observe(elem.NodeId)
+		}
+		return result, nil
+	case *crdt.RGAStringNode:
+		result := crdt.NewRGAStringNode(n.NodeId)
+		m.add(result)
+
+		for _, elem := range n.Characters() {
+			result.NodeElements = append(result.NodeElements, elem)
+			m.observe(elem.NodeId)
+		}
+		result.Compact()
+		return result, nil
+	default:
+		return nil, errors.Errorf("unsupported node type for merge: %T", node)
+	}
+}
+
+// copied returns the node already added to the merged document
+func (m *merger) copied(id common.LogicalTimestamp) (crdt.Node, bool) {
+	if id.Compare(common.RootID) == 0 {
+		return nil, false
+	}
+	node, err := m.result.GetNode(id)
+	return node, err == nil
+}
+
+// add adds a node to the merged document
+func (m *merger) add(node crdt.Node) {
+	m.result.AddNode(node)
+	m.observe(node.ID())
+}
+
+// observe records a timestamp so that the merged clock moves past it
+func (m *merger) observe(ts common.LogicalTimestamp) {
+	if ts.SID == m.result.GetSessionID() && ts.Counter > m.maxCounter {
+		m.maxCounter = ts.Counter
+	}
+}
+
+// changed reports whether a register was rewritten or its value changed since base
+func (m *merger) changed(base, reg *register, doc *crdt.Document) bool {
+	if base.timestamp.Compare(reg.timestamp) != 0 {
+		return true
+	}
+	return !reflect.DeepEqual(m.decode(m.base, base.node), m.decode(doc, reg.node))
+}
+
+// decode converts a node of doc into plain Go values, or nil if it cannot be decoded
+func (m *merger) decode(doc *crdt.Document, node crdt.Node) any {
+	engine, ok := m.decoders[doc]
+	if !ok {
+		engine = NewQueryEngine(doc, nil)
+		m.decoders[doc] = engine
+	}
+
+	value, err := engine.decodeNode(node)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// addConflict records a conflict on a register
+func (m *merger) addConflict(path string, kind MergeConflictKind, base, a, b *register, resolved crdt.Node) {
+	conflict := pendingConflict{
+		MergeConflict: MergeConflict{Path: path, Kind: kind},
+		resolved:      resolved,
+	}
+	if base != nil {
+		conflict.Base = m.decode(m.base, base.node)
+	}
+	if a != nil {
+		conflict.ValueA = m.decode(m.a, a.node)
+	}
+	if b != nil {
+		conflict.ValueB = m.decode(m.b, b.node)
+	}
+	m.conflicts = append(m.conflicts, conflict)
+}
+
+// mergedKeys returns the union of the keys of two objects in sorted order
+func mergedKeys(a, b *crdt.LWWObjectNode) []string {
+	seen := make(map[string]bool, len(a.NodeFields)+len(b.NodeFields))
+	keys := make([]string, 0, len(a.NodeFields)+len(b.NodeFields))
+	for _, obj := range []*crdt.LWWObjectNode{a, b} {
+		for key := range obj.NodeFields {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergedElement is a copied RGA element with the branches it appears in
+type mergedElement struct {
+	*crdt.RGAElement
+	inA, inB           bool
+	deletedA, deletedB bool
+}
+
+// mergeRGAElements merges the element sequences of an RGA array or string.
+//
+// Elements present in both branches keep their relative order and stay deleted if
+// either branch deleted them. Runs of elements inserted in only one branch are
+// placed after the shared element they follow; when both branches inserted after
+// the same element, the run with the greater first ID goes first, as RGA does.
+func mergeRGAElements(a, b []*crdt.RGAElement) []mergedElement {
+	inA := make(map[common.LogicalTimestamp]*crdt.RGAElement, len(a))
+	for _, elem := range a {
+		inA[elem.NodeId] = elem
+	}
+	inB := make(map[common.LogicalTimestamp]*crdt.RGAElement, len(b))
+	for _, elem := range b {
+		inB[elem.NodeId] = elem
+	}
+
+	// 공통 요소 뒤에 이어지는 한쪽 전용 요소 묶음
+	runs := func(elems []*crdt.RGAElement, other map[common.LogicalTimestamp]*crdt.RGAElement) map[common.LogicalTimestamp][]*crdt.RGAElement {
+		result := make(map[common.LogicalTimestamp][]*crdt.RGAElement)
+		anchor := common.RootID
+		for _, elem := range elems {
+			if _, shared := other[elem.NodeId]; shared {
+				anchor = elem.NodeId
+				continue
+			}
+			result[anchor] = append(result[anchor], elem)
+		}
+		return result
+	}
+	runsA := runs(a, inB)
+	runsB := runs(b, inA)
+
+	result := make([]mergedElement, 0, len(a)+len(b))
+	emitRuns := func(anchor common.LogicalTimestamp) {
+		runA, runB := runsA[anchor], runsB[anchor]
+		first, second := runA, runB
+		firstInA := true
+		if len(runA) > 0 && len(runB) > 0 && rgaOrdersBefore(runB[0].NodeId, runA[0].NodeId) {
+			first, second = runB, runA
+			firstInA = false
+		}
+		for _, elem := range first {
+			result = append(result, newMergedElement(elem, firstInA))
+		}
+		for _, elem := range second {
+			result = append(result, newMergedElement(elem, !firstInA))
+		}
+	}
+
+	emitRuns(common.RootID)
+	for _, elem := range a {
+		other, shared := inB[elem.NodeId]
+		if !shared {
+			continue
+		}
+
+		copied := *elem
+		copied.NodeDeleted = elem.NodeDeleted || other.NodeDeleted
+		result = append(result, mergedElement{
+			RGAElement: &copied,
+			inA:        true,
+			inB:        true,
+			deletedA:   elem.NodeDeleted,
+			deletedB:   other.NodeDeleted,
+		})
+		emitRuns(elem.NodeId)
+	}
+	return result
+}
+
+// newMergedElement copies an element that appears in only one branch
+func newMergedElement(elem *crdt.RGAElement, fromA bool) mergedElement {
+	copied := *elem
+	return mergedElement{
+		RGAElement: &copied,
+		inA:        fromA,
+		inB:        !fromA,
+		deletedA:   fromA && elem.NodeDeleted,
+		deletedB:   !fromA && elem.NodeDeleted,
+	}
+}
+
+// rgaOrdersBefore reports whether an element inserted with ID a orders before a
+// concurrent insert with ID b at the same position
+func rgaOrdersBefore(a, b common.LogicalTimestamp) bool {
+	if a.Counter != b.Counter {
+		return a.Counter > b.Counter
+	}
+	return a.SID.Compare(b.SID) > 0
+}
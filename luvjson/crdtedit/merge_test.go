@@ -0,0 +1,193 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// setupMergeDocument creates a document with fixed IDs so that copies share node identities
+func setupMergeDocument(t *testing.T, baseSID common.SessionID) *crdt.Document {
+	doc := crdt.NewDocument(common.NewSessionID())
+
+	var counter uint64
+	nextID := func() common.LogicalTimestamp {
+		counter++
+		return common.LogicalTimestamp{SID: baseSID, Counter: counter}
+	}
+	setConst := func(obj *crdt.LWWObjectNode, key string, value any) {
+		node := crdt.NewConstantNode(nextID(), value)
+		doc.AddNode(node)
+		obj.Set(key, node.ID(), node)
+	}
+
+	root := crdt.NewLWWObjectNode(nextID())
+	doc.AddNode(root)
+
+	players := crdt.NewLWWObjectNode(nextID())
+	doc.AddNode(players)
+	root.Set("players", players.ID(), players)
+
+	for _, name := range []string{"alice", "bob"} {
+		player := crdt.NewLWWObjectNode(nextID())
+		doc.AddNode(player)
+		setConst(player, "gold", float64(100))
+		players.Set(name, player.ID(), player)
+	}
+
+	items := crdt.NewRGAArrayNode(nextID())
+	doc.AddNode(items)
+	root.Set("items", items.ID(), items)
+
+	afterID := common.RootID
+	for _, name := range []string{"potion", "sword"} {
+		node := crdt.NewConstantNode(nextID(), name)
+		doc.AddNode(node)
+
+		elemID := nextID()
+		items.Insert(afterID, elemID, node.ID())
+		afterID = elemID
+	}
+
+	require.NoError(t, doc.SetRoot(root.ID()))
+	return doc
+}
+
+// TestMerge tests merging non-conflicting edits from two offline branches
+func TestMerge(t *testing.T) {
+	baseSID := common.NewSessionID()
+	base := setupMergeDocument(t, baseSID)
+	branchA := setupMergeDocument(t, baseSID)
+	branchB := setupMergeDocument(t, baseSID)
+	editorA := NewDocumentEditor(branchA)
+	editorB := NewDocumentEditor(branchB)
+
+	_, err := editorA.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/players/alice/gold", Value: float64(150)},
+		{Op: JSONPatchAdd, Path: "/items/-", Value: "shield"},
+	})
+	require.NoError(t, err)
+
+	_, err = editorB.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/players/bob/gold", Value: float64(60)},
+		{Op: JSONPatchAdd, Path: "/players/carol", Value: map[string]any{"gold": float64(10)}},
+		{Op: JSONPatchRemove, Path: "/items/0"},
+	})
+	require.NoError(t, err)
+
+	result, err := Merge(base, branchA, branchB)
+	require.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, branchA.GetSessionID(), result.Document.GetSessionID())
+
+	merged := NewDocumentEditor(result.Document)
+	value, err := merged.queryEngine.decodeNode(result.Document.Root())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"players": map[string]any{
+			"alice": map[string]any{"gold": float64(150)},
+			"bob":   map[string]any{"gold": float64(60)},
+			"carol": map[string]any{"gold": float64(10)},
+		},
+		"items": []any{"sword", "shield"},
+	}, value)
+
+	// 입력 문서는 변경되지 않음
+	gold, err := editorB.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), gold)
+
+	// 병합 문서에서 계속 편집 가능
+	_, err = merged.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/players/alice/gold", Value: float64(20)},
+	})
+	require.NoError(t, err)
+	gold, err = merged.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(20), gold)
+}
+
+// TestMerge_Conflicts tests that arbitrated paths are reported
+func TestMerge_Conflicts(t *testing.T) {
+	baseSID := common.NewSessionID()
+	base := setupMergeDocument(t, baseSID)
+	branchA := setupMergeDocument(t, baseSID)
+	branchB := setupMergeDocument(t, baseSID)
+	editorA := NewDocumentEditor(branchA)
+	editorB := NewDocumentEditor(branchB)
+
+	// 같은 필드를 서로 다르게 수정, bob은 A에서 삭제하고 B에서 수정
+	_, err := editorA.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/players/alice/gold", Value: float64(150)},
+		{Op: JSONPatchRemove, Path: "/players/bob"},
+	})
+	require.NoError(t, err)
+	_, err = editorB.ApplyJSONPatch([]JSONPatchOperation{
+		{Op: JSONPatchReplace, Path: "/players/alice/gold", Value: float64(50)},
+		{Op: JSONPatchReplace, Path: "/players/bob/gold", Value: float64(70)},
+	})
+	require.NoError(t, err)
+
+	result, err := Merge(base, branchA, branchB)
+	require.NoError(t, err)
+	require.Len(t, result.Conflicts, 2)
+
+	merged := NewDocumentEditor(result.Document)
+
+	goldConflict := result.Conflicts[0]
+	assert.Equal(t, "players.alice.gold", goldConflict.Path)
+	assert.Equal(t, MergeConflictUpdate, goldConflict.Kind)
+	assert.Equal(t, float64(100), goldConflict.Base)
+	assert.Equal(t, float64(150), goldConflict.ValueA)
+	assert.Equal(t, float64(50), goldConflict.ValueB)
+	gold, err := merged.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Contains(t, []any{float64(150), float64(50)}, gold)
+	assert.Equal(t, gold, goldConflict.Resolved)
+
+	// 삭제와 수정이 충돌하면 수정 유지
+	bobConflict := result.Conflicts[1]
+	assert.Equal(t, "players.bob", bobConflict.Path)
+	assert.Equal(t, MergeConflictDelete, bobConflict.Kind)
+	assert.Nil(t, bobConflict.ValueA)
+	assert.Equal(t, map[string]any{"gold": float64(70)}, bobConflict.ValueB)
+	gold, err = merged.Query("players.bob.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(70), gold)
+
+	// 양쪽 변경이 같으면 충돌 아님
+	result, err = Merge(base, branchA, branchA)
+	require.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+}
+
+// TestMerge_Counters tests that counter increments from both branches are summed
+func TestMerge_Counters(t *testing.T) {
+	baseSID := common.NewSessionID()
+	base := setupMergeDocument(t, baseSID)
+	branchA := setupMergeDocument(t, baseSID)
+	branchB := setupMergeDocument(t, baseSID)
+
+	// 카운터 생성 후 두 브랜치에 전달
+	patch, err := NewCounterEditor(NewDocumentEditor(base)).Create("players.alice.score")
+	require.NoError(t, err)
+	require.NoError(t, NewDocumentEditor(branchA).ApplyPatch(patch))
+	require.NoError(t, NewDocumentEditor(branchB).ApplyPatch(patch))
+
+	_, err = NewCounterEditor(NewDocumentEditor(branchA)).Increment("players.alice.score", 10)
+	require.NoError(t, err)
+	_, err = NewCounterEditor(NewDocumentEditor(branchB)).Increment("players.alice.score", 5)
+	require.NoError(t, err)
+
+	result, err := Merge(base, branchA, branchB)
+	require.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+
+	value, err := NewCounterEditor(NewDocumentEditor(result.Document)).Value("players.alice.score")
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), value)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/pkg/errors"
 
 	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
 	"tictactoe/luvjson/crdtpatch"
 )
 
@@ -12,6 +13,9 @@ type PatchBuilder struct {
 	operations []crdtpatch.Operation
 	sessionID  common.SessionID
 	counter    uint64
+	// ordered is true when counter follows the document clock, so operation IDs
+	// can be used as LWW timestamps without colliding with existing nodes
+	ordered bool
 }
 
 // NewPatchBuilder creates a new PatchBuilder
@@ -23,6 +27,15 @@ func NewPatchBuilder(sessionID common.SessionID) *PatchBuilder {
 	}
 }
 
+// newDocumentPatchBuilder creates a PatchBuilder whose IDs continue from the
+// document's local clock so that new nodes do not collide with existing ones
+func newDocumentPatchBuilder(doc *crdt.Document) *PatchBuilder {
+	b := NewPatchBuilder(doc.GetSessionID())
+	b.counter = doc.NextTimestamp().Counter
+	b.ordered = true
+	return b
+}
+
 // NextID generates the next ID for operations
 func (b *PatchBuilder) NextID() common.LogicalTimestamp {
 	b.counter++
@@ -40,6 +53,7 @@ func (b *PatchBuilder) AddSetOperation(targetID common.LogicalTimestamp, value a
 		if err != nil {
 			return errors.Wrap(err, "failed to create set operation")
 		}
+		b.assignID(op)
 		b.operations = append(b.operations, op)
 		return nil
 	}
@@ -60,6 +74,7 @@ func (b *PatchBuilder) AddSetOperation(targetID common.LogicalTimestamp, value a
 	if err != nil {
 		return errors.Wrap(err, "failed to create set operation")
 	}
+	b.assignID(op)
 
 	b.operations = append(b.operations, op)
 	return nil
@@ -73,6 +88,7 @@ func (b *PatchBuilder) AddObjectInsertOperation(targetID common.LogicalTimestamp
 		if err != nil {
 			return errors.Wrap(err, "failed to create object insert operation")
 		}
+		b.assignID(op)
 		b.operations = append(b.operations, op)
 		return nil
 	}
@@ -93,6 +109,7 @@ func (b *PatchBuilder) AddObjectInsertOperation(targetID common.LogicalTimestamp
 	if err != nil {
 		return errors.Wrap(err, "failed to create object insert operation")
 	}
+	b.assignID(op)
 
 	b.operations = append(b.operations, op)
 	return nil
@@ -104,6 +121,7 @@ func (b *PatchBuilder) AddObjectDeleteOperation(targetID common.LogicalTimestamp
 	if err != nil {
 		return errors.Wrap(err, "failed to create object delete operation")
 	}
+	b.assignID(op)
 
 	b.operations = append(b.operations, op)
 	return nil
@@ -117,6 +135,7 @@ func (b *PatchBuilder) AddArrayInsertOperation(targetID common.LogicalTimestamp,
 		if err != nil {
 			return errors.Wrap(err, "failed to create array insert operation")
 		}
+		b.assignID(op)
 		b.operations = append(b.operations, op)
 		return nil
 	}
@@ -136,6 +155,7 @@ func (b *PatchBuilder) AddArrayInsertOperation(targetID common.LogicalTimestamp,
 	if err != nil {
 		return errors.Wrap(err, "failed to create array insert operation")
 	}
+	b.assignID(op)
 
 	b.operations = append(b.operations, op)
 	return nil
@@ -147,6 +167,7 @@ func (b *PatchBuilder) AddArrayDeleteOperation(targetID common.LogicalTimestamp,
 	if err != nil {
 		return errors.Wrap(err, "failed to create array delete operation")
 	}
+	b.assignID(op)
 
 	b.operations = append(b.operations, op)
 	return nil
@@ -174,6 +195,49 @@ func (b *PatchBuilder) AddStringDeleteOperation(targetID common.LogicalTimestamp
 	return nil
 }
 
+// AddTextInsertOperation adds a character-level insert operation that inserts text
+// after the character refID (common.RootID inserts at the beginning).
+// Characters are assigned consecutive IDs starting at id.
+func (b *PatchBuilder) AddTextInsertOperation(id, targetID, refID common.LogicalTimestamp, text string) error {
+	if text == "" {
+		return errors.New("text cannot be empty")
+	}
+
+	b.operations = append(b.operations, &crdtpatch.InsOperation{
+		ID:       id,
+		TargetID: targetID,
+		RefID:    refID,
+		Value:    text,
+	})
+	return nil
+}
+
+// AddTextDeleteOperation adds a character-level delete operation for the character charID
+func (b *PatchBuilder) AddTextDeleteOperation(id, targetID, charID common.LogicalTimestamp) error {
+	b.operations = append(b.operations, &crdtpatch.DelOperation{
+		ID:       id,
+		TargetID: targetID,
+		StartID:  charID,
+		EndID:    charID,
+	})
+	return nil
+}
+
+// assignID replaces the placeholder ID set by crdtpatch constructors with the
+// builder's next ID so that LWW timestamps are ordered within the session
+func (b *PatchBuilder) assignID(op crdtpatch.Operation) {
+	if !b.ordered {
+		return
+	}
+
+	switch o := op.(type) {
+	case *crdtpatch.InsOperation:
+		o.ID = b.NextID()
+	case *crdtpatch.DelOperation:
+		o.ID = b.NextID()
+	}
+}
+
 // Build returns the operations in the patch
 func (b *PatchBuilder) Build() []crdtpatch.Operation {
 	return b.operations
@@ -71,6 +71,7 @@ func (r *PathResolver) ResolveNodePath(path string) (common.LogicalTimestamp, er
 			if err != nil {
 				return common.NilID, errors.Wrap(err, "failed to get node")
 			}
+			node = derefValueNode(node)
 
 			// 노드 타입에 따라 다르게 처리
 			switch n := node.(type) {
@@ -114,6 +115,7 @@ func (r *PathResolver) ResolveNodePath(path string) (common.LogicalTimestamp, er
 			if err != nil {
 				return common.NilID, errors.Wrap(err, "failed to get node")
 			}
+			node = derefValueNode(node)
 
 			objNode, ok := node.(*crdt.LWWObjectNode)
 			if !ok {
@@ -135,6 +137,27 @@ func (r *PathResolver) ResolveNodePath(path string) (common.LogicalTimestamp, er
 	return currentID, nil
 }
 
+// derefValueNode follows root and LWW value nodes to the node they hold.
+// Other nodes are returned as is.
+func derefValueNode(node crdt.Node) crdt.Node {
+	for {
+		switch n := node.(type) {
+		case *crdt.RootNode:
+			if n.NodeValue == nil {
+				return node
+			}
+			node = n.NodeValue
+		case *crdt.LWWValueNode:
+			if n.NodeValue == nil {
+				return node
+			}
+			node = n.NodeValue
+		default:
+			return node
+		}
+	}
+}
+
 // GetNodeType returns the type of a node
 func (r *PathResolver) GetNodeType(nodeID common.LogicalTimestamp) (NodeType, error) {
 	node, err := r.doc.GetNode(nodeID)
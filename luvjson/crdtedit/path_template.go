@@ -0,0 +1,119 @@
+package crdtedit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// BindingValueFunc computes the value to write for one set of template bindings
+type BindingValueFunc func(binding map[string]any) (any, error)
+
+// ExpandPathTemplate replaces the {name} placeholders of a path template with the
+// values of binding, e.g. "players.{id}.items[{idx}]" with {"id": "alice", "idx": 2}
+// expands to "players.alice.items[2]".
+func ExpandPathTemplate(template string, binding map[string]any) (string, error) {
+	var sb strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return "", errors.Errorf("unexpected '}' in path template %s", template)
+			}
+			sb.WriteString(rest)
+			return sb.String(), nil
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", errors.Errorf("unclosed placeholder in path template %s", template)
+		}
+		end += start
+
+		name := rest[start+1 : end]
+		if name == "" || strings.ContainsAny(name, "{.[]") {
+			return "", errors.Errorf("invalid placeholder {%s} in path template %s", name, template)
+		}
+
+		value, ok := binding[name]
+		if !ok {
+			return "", errors.Errorf("no binding for placeholder {%s} in path template %s", name, template)
+		}
+
+		sb.WriteString(rest[:start])
+		sb.WriteString(fmt.Sprint(value))
+		rest = rest[end+1:]
+	}
+}
+
+// BatchApply expands the path template once per binding and writes value to every
+// resulting path in a single transaction, e.g. to grant a reward to all raid participants.
+//
+// value is written as is to every path, unless it is a BindingValueFunc, in which
+// case it is called with each binding to compute the value for that path.
+// If any path cannot be written, nothing is applied.
+func (e *DocumentEditor) BatchApply(template string, bindings []map[string]any, value any) (*crdtpatch.Patch, error) {
+	if len(bindings) == 0 {
+		return nil, errors.New("bindings cannot be empty")
+	}
+
+	valueFn, _ := value.(BindingValueFunc)
+	if fn, ok := value.(func(binding map[string]any) (any, error)); ok {
+		valueFn = fn
+	}
+
+	return e.Transaction(func(tx *Transaction) error {
+		for i, binding := range bindings {
+			path, err := ExpandPathTemplate(template, binding)
+			if err != nil {
+				return errors.Wrapf(err, "failed to expand binding %d", i)
+			}
+
+			v := value
+			if valueFn != nil {
+				if v, err = valueFn(binding); err != nil {
+					return errors.Wrapf(err, "failed to compute value for path %s", path)
+				}
+			}
+
+			if err := setPathValue(tx, path, v); err != nil {
+				return errors.Wrapf(err, "failed to set path %s", path)
+			}
+		}
+		return nil
+	})
+}
+
+// setPathValue records the transaction operations that write value at path,
+// replacing an array element or setting an object key
+func setPathValue(tx *Transaction, path string, value any) error {
+	if strings.HasSuffix(path, "]") {
+		open := strings.LastIndexByte(path, '[')
+		if open < 0 {
+			return errors.Errorf("invalid path %s", path)
+		}
+
+		index, err := strconv.Atoi(path[open+1 : len(path)-1])
+		if err != nil || index < 0 {
+			return errors.Errorf("invalid array index in path %s", path)
+		}
+
+		// 요소 교체: 삭제 후 같은 위치에 삽입
+		parentPath := path[:open]
+		if err := tx.DeleteArrayElement(parentPath, index); err != nil {
+			return err
+		}
+		return tx.InsertArrayElement(parentPath, index, value)
+	}
+
+	parentPath, key := splitLastSegment(path)
+	if key == "" {
+		return errors.New("path cannot be empty")
+	}
+	return tx.SetKey(parentPath, key, value)
+}
@@ -0,0 +1,84 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExpandPathTemplate tests placeholder substitution and template validation
+func TestExpandPathTemplate(t *testing.T) {
+	path, err := ExpandPathTemplate("players.{id}.inventory.items[{idx}]", map[string]any{"id": "alice", "idx": 2})
+	require.NoError(t, err)
+	assert.Equal(t, "players.alice.inventory.items[2]", path)
+
+	path, err = ExpandPathTemplate("players.alice.gold", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "players.alice.gold", path)
+
+	for _, template := range []string{"players.{id", "players.id}", "players.{}", "players.{a.b}", "players.{missing}"} {
+		_, err := ExpandPathTemplate(template, map[string]any{"id": "alice"})
+		assert.Error(t, err, template)
+	}
+}
+
+// TestBatchApply tests applying a value to many template paths in one patch
+func TestBatchApply(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	participants := []map[string]any{{"id": "alice"}, {"id": "bob"}}
+
+	// 참가자별 현재 값에 보상 추가
+	reward := BindingValueFunc(func(binding map[string]any) (any, error) {
+		gold, err := editor.Query("players." + binding["id"].(string) + ".gold")
+		if err != nil {
+			return nil, err
+		}
+		return gold.(float64) + 50, nil
+	})
+	patch, err := editor.BatchApply("players.{id}.gold", participants, reward)
+	require.NoError(t, err)
+	assert.NotEmpty(t, patch.Operations())
+
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(150), gold)
+	gold, err = editor.Query("players.bob.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(90), gold)
+
+	// 같은 값을 배열 요소 필드에 적용
+	_, err = editor.BatchApply("items[{idx}].qty", []map[string]any{{"idx": 0}, {"idx": 2}}, float64(0))
+	require.NoError(t, err)
+	qty, err := editor.Query("items[0].qty")
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), qty)
+	qty, err = editor.Query("items[1].qty")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), qty)
+	qty, err = editor.Query("items[2].qty")
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), qty)
+
+	// 배열 요소 교체
+	_, err = editor.BatchApply("items[{idx}]", []map[string]any{{"idx": 1}}, "shield")
+	require.NoError(t, err)
+	item, err := editor.Query("items[1]")
+	require.NoError(t, err)
+	assert.Equal(t, "shield", item)
+	items, err := editor.Query("items")
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+
+	// 하나라도 실패하면 아무것도 적용되지 않음
+	_, err = editor.BatchApply("players.{id}.gold", []map[string]any{{"id": "alice"}, {"id": "nobody"}}, float64(1))
+	assert.Error(t, err)
+	gold, err = editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(150), gold)
+
+	_, err = editor.BatchApply("players.{id}.gold", nil, float64(1))
+	assert.Error(t, err)
+}
@@ -10,6 +10,7 @@ import (
 type QueryEngine struct {
 	doc      *crdt.Document
 	resolver *PathResolver
+	computed *computedRegistry
 }
 
 // NewQueryEngine creates a new QueryEngine
@@ -22,6 +23,16 @@ func NewQueryEngine(doc *crdt.Document, resolver *PathResolver) *QueryEngine {
 
 // GetValue returns the value at the given path
 func (e *QueryEngine) GetValue(path string) (any, error) {
+	// 계산 필드 우선 조회
+	if e.computed != nil {
+		if value, ok, err := e.computed.get(path); ok {
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to compute field %s", path)
+			}
+			return value, nil
+		}
+	}
+
 	nodeID, err := e.resolver.ResolveNodePath(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
@@ -32,6 +43,13 @@ func (e *QueryEngine) GetValue(path string) (any, error) {
 		return nil, errors.Wrapf(err, "failed to get node at path %s", path)
 	}
 
+	// 카운터 객체는 합계 값 반환
+	if obj, ok := derefValueNode(node).(*crdt.LWWObjectNode); ok {
+		if value, ok := counterValue(obj); ok {
+			return value, nil
+		}
+	}
+
 	value, err := e.doc.GetNodeValue(node)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get node value at path %s", path)
@@ -164,3 +182,54 @@ func (e *QueryEngine) GetBoolean(path string) (bool, error) {
 
 	return b, nil
 }
+
+// decodeNode converts a node and all of its descendants into plain Go values.
+// Value nodes are dereferenced and array elements are resolved through the document index.
+func (e *QueryEngine) decodeNode(node crdt.Node) (any, error) {
+	switch n := node.(type) {
+	case nil:
+		return nil, nil
+	case *crdt.RootNode:
+		return e.decodeNode(n.NodeValue)
+	case *crdt.LWWValueNode:
+		return e.decodeNode(n.NodeValue)
+	case *crdt.LWWObjectNode:
+		// 카운터 객체는 합계 값으로 표시
+		if value, ok := counterValue(n); ok {
+			return value, nil
+		}
+
+		result := make(map[string]any)
+		for _, key := range n.Keys() {
+			value, err := e.decodeNode(n.Get(key))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decode key %s", key)
+			}
+			result[key] = value
+		}
+		return result, nil
+	case *crdt.RGAArrayNode:
+		length := n.Length()
+		result := make([]any, length)
+		for i := 0; i < length; i++ {
+			elemID, err := n.Get(i)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get element at index %d", i)
+			}
+
+			elemNode, err := e.doc.GetNode(elemID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get node for element at index %d", i)
+			}
+
+			value, err := e.decodeNode(elemNode)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decode element at index %d", i)
+			}
+			result[i] = value
+		}
+		return result, nil
+	default:
+		return e.doc.GetNodeValue(node)
+	}
+}
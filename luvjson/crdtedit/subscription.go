@@ -0,0 +1,368 @@
+package crdtedit
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// ChangeKind identifies whether a value was added, updated or removed
+type ChangeKind string
+
+const (
+	// ChangeAdded means the path did not exist before the change
+	ChangeAdded ChangeKind = "added"
+	// ChangeUpdated means the value at the path was replaced
+	ChangeUpdated ChangeKind = "updated"
+	// ChangeRemoved means the path no longer exists after the change
+	ChangeRemoved ChangeKind = "removed"
+)
+
+// ChangeEvent describes a change to a single value in the document
+type ChangeEvent struct {
+	// Path is the changed value path (e.g. "players.alice.gold")
+	Path string
+	// Kind is the kind of change
+	Kind ChangeKind
+	// OldValue is the value before the change (nil if the value was added)
+	OldValue any
+	// NewValue is the value after the change (nil if the value was removed)
+	NewValue any
+	// Origin is the session ID that produced the change
+	Origin common.SessionID
+}
+
+// SubscriptionCallback is called for every change matching a subscription pattern
+type SubscriptionCallback func(event ChangeEvent)
+
+// subscription is a registered path pattern and its callback
+type subscription struct {
+	id       uint64
+	pattern  []string
+	callback SubscriptionCallback
+}
+
+// batchListener receives all events of a single publish at once
+type batchListener struct {
+	id       uint64
+	callback func(events []ChangeEvent)
+}
+
+// subscriptionManager tracks subscriptions and the last published document state
+type subscriptionManager struct {
+	mu             sync.Mutex
+	nextID         uint64
+	subscriptions  []*subscription
+	batchListeners []*batchListener
+	snapshot       map[string]any
+}
+
+// newSubscriptionManager creates a new subscriptionManager
+func newSubscriptionManager() *subscriptionManager {
+	return &subscriptionManager{}
+}
+
+// Subscribe registers a callback for changes to paths matching pattern and
+// returns a function that cancels the subscription.
+//
+// Patterns are dot-separated globs: "*" inside a segment matches any characters
+// in that segment and a "**" segment matches any number of segments.
+// A change matches when the pattern matches the changed path or one of its
+// ancestors, so "players.*" also receives changes to "players.alice.gold".
+//
+// Events are published after DocumentEditor mutations, ApplyPatch, and
+// NotifyChanges (for edits made through type editors or directly on the document).
+func (e *DocumentEditor) Subscribe(pattern string, callback SubscriptionCallback) (func(), error) {
+	if pattern == "" {
+		return nil, errors.New("subscription pattern cannot be empty")
+	}
+	if callback == nil {
+		return nil, errors.New("subscription callback cannot be nil")
+	}
+
+	m := e.subscriptions
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := e.ensureSnapshot(); err != nil {
+		return nil, err
+	}
+
+	m.nextID++
+	sub := &subscription{
+		id:       m.nextID,
+		pattern:  strings.Split(pattern, "."),
+		callback: callback,
+	}
+	m.subscriptions = append(m.subscriptions, sub)
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for i, s := range m.subscriptions {
+			if s.id == sub.id {
+				m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+				break
+			}
+		}
+	}, nil
+}
+
+// subscribeBatch registers a listener that receives every published change set as a whole
+func (e *DocumentEditor) subscribeBatch(callback func(events []ChangeEvent)) (func(), error) {
+	m := e.subscriptions
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := e.ensureSnapshot(); err != nil {
+		return nil, err
+	}
+
+	m.nextID++
+	listener := &batchListener{id: m.nextID, callback: callback}
+	m.batchListeners = append(m.batchListeners, listener)
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for i, l := range m.batchListeners {
+			if l.id == listener.id {
+				m.batchListeners = append(m.batchListeners[:i], m.batchListeners[i+1:]...)
+				break
+			}
+		}
+	}, nil
+}
+
+// ensureSnapshot stores the current document state as the baseline for change
+// detection if it has not been taken yet; the caller must hold the lock
+func (e *DocumentEditor) ensureSnapshot() error {
+	// 첫 구독 시 현재 상태를 기준으로 저장
+	if e.subscriptions.snapshot != nil {
+		return nil
+	}
+
+	snapshot, err := e.flattenDocument()
+	if err != nil {
+		return errors.Wrap(err, "failed to snapshot document")
+	}
+	e.subscriptions.snapshot = snapshot
+	return nil
+}
+
+// ApplyPatch applies a patch (typically received from a remote session) to the
+// document and notifies subscribers with the patch's session as origin
+func (e *DocumentEditor) ApplyPatch(patch *crdtpatch.Patch) error {
+	if patch == nil {
+		return errors.New("patch cannot be nil")
+	}
+
+	if err := patch.Apply(e.doc); err != nil {
+		return errors.Wrap(err, "failed to apply patch")
+	}
+	e.recordPatch(patch)
+
+	return e.publishChanges(patch.ID().SID)
+}
+
+// NotifyChanges publishes changes made outside of DocumentEditor methods
+// (e.g. through type editors) with the local session as origin
+func (e *DocumentEditor) NotifyChanges() error {
+	return e.publishChanges(e.doc.GetSessionID())
+}
+
+// publishChanges diffs the document against the last published state and
+// dispatches matching events to subscribers
+func (e *DocumentEditor) publishChanges(origin common.SessionID) error {
+	m := e.subscriptions
+	m.mu.Lock()
+
+	// 구독자가 없으면 비교하지 않음
+	if m.snapshot == nil {
+		m.mu.Unlock()
+		return nil
+	}
+
+	current, err := e.flattenDocument()
+	if err != nil {
+		m.mu.Unlock()
+		return errors.Wrap(err, "failed to snapshot document")
+	}
+
+	events := diffFlattened(m.snapshot, current, origin)
+	m.snapshot = current
+
+	type delivery struct {
+		callback SubscriptionCallback
+		event    ChangeEvent
+	}
+	deliveries := make([]delivery, 0)
+	for _, event := range events {
+		segments := splitPathSegments(event.Path)
+		for _, sub := range m.subscriptions {
+			if matchPathPattern(sub.pattern, segments) {
+				deliveries = append(deliveries, delivery{callback: sub.callback, event: event})
+			}
+		}
+	}
+	listeners := make([]*batchListener, len(m.batchListeners))
+	copy(listeners, m.batchListeners)
+	m.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	// 콜백 안에서 구독/편집할 수 있도록 잠금 해제 후 호출
+	for _, listener := range listeners {
+		listener.callback(events)
+	}
+	for _, d := range deliveries {
+		d.callback(d.event)
+	}
+	return nil
+}
+
+// flattenDocument returns all leaf values of the document keyed by path
+func (e *DocumentEditor) flattenDocument() (map[string]any, error) {
+	root, err := e.queryEngine.decodeNode(e.doc.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any)
+	flattenValue("", root, result)
+	return result, nil
+}
+
+// flattenValue collects leaf values of a decoded value into result.
+// Empty containers are recorded as leaves so that their creation is observable.
+func flattenValue(path string, value any, result map[string]any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && path != "" {
+			result[path] = v
+			return
+		}
+		for key, child := range v {
+			flattenValue(childKeyPath(path, key), child, result)
+		}
+	case []any:
+		if len(v) == 0 && path != "" {
+			result[path] = v
+			return
+		}
+		for i, child := range v {
+			flattenValue(childIndexPath(path, i), child, result)
+		}
+	default:
+		if path != "" {
+			result[path] = v
+		}
+	}
+}
+
+// diffFlattened returns change events between two flattened snapshots in path order
+func diffFlattened(old, current map[string]any, origin common.SessionID) []ChangeEvent {
+	paths := make([]string, 0, len(old)+len(current))
+	for path := range old {
+		paths = append(paths, path)
+	}
+	for path := range current {
+		if _, exists := old[path]; !exists {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	events := make([]ChangeEvent, 0)
+	for _, path := range paths {
+		oldValue, oldExists := old[path]
+		newValue, newExists := current[path]
+		if oldExists == newExists && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		kind := ChangeUpdated
+		switch {
+		case !oldExists:
+			kind = ChangeAdded
+		case !newExists:
+			kind = ChangeRemoved
+		}
+
+		events = append(events, ChangeEvent{
+			Path:     path,
+			Kind:     kind,
+			OldValue: oldValue,
+			NewValue: newValue,
+			Origin:   origin,
+		})
+	}
+	return events
+}
+
+// splitPathSegments splits a path into dot-separated segments
+func splitPathSegments(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// matchPathPattern reports whether the pattern matches the path or one of its ancestors
+func matchPathPattern(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		// "**"는 0개 이상의 세그먼트와 일치
+		for i := 0; i <= len(segments); i++ {
+			if matchPathPattern(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if !matchSegment(pattern[0], segments[0]) {
+		return false
+	}
+	return matchPathPattern(pattern[1:], segments[1:])
+}
+
+// matchSegment matches a single path segment against a pattern where '*' matches any characters.
+// Other characters, including '[' and ']' of array indices, match literally.
+func matchSegment(pattern, segment string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return pattern == segment
+	}
+
+	prefix := pattern[:star]
+	if !strings.HasPrefix(segment, prefix) {
+		return false
+	}
+
+	rest := pattern[star+1:]
+	for i := len(prefix); i <= len(segment); i++ {
+		if matchSegment(rest, segment[i:]) {
+			return true
+		}
+	}
+	return false
+}
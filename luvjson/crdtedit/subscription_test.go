@@ -0,0 +1,79 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/crdt"
+)
+
+// TestSubscribe tests path-scoped change notifications
+func TestSubscribe(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	var goldEvents, allEvents []ChangeEvent
+	unsubscribe, err := editor.Subscribe("players.*.gold", func(event ChangeEvent) {
+		goldEvents = append(goldEvents, event)
+	})
+	require.NoError(t, err)
+
+	_, err = editor.Subscribe("players", func(event ChangeEvent) {
+		allEvents = append(allEvents, event)
+	})
+	require.NoError(t, err)
+
+	// alice의 골드를 직접 변경
+	root := doc.Root().(*crdt.RootNode).NodeValue.(*crdt.LWWObjectNode)
+	alice := root.Get("players").(*crdt.LWWObjectNode).Get("alice").(*crdt.LWWObjectNode)
+	gold := crdt.NewConstantNode(doc.NextTimestamp(), float64(150))
+	doc.AddNode(gold)
+	alice.Set("gold", gold.ID(), gold)
+	alice.Set("title", gold.ID(), crdt.NewConstantNode(gold.ID(), "hero"))
+
+	require.NoError(t, editor.NotifyChanges())
+
+	require.Len(t, goldEvents, 1)
+	assert.Equal(t, "players.alice.gold", goldEvents[0].Path)
+	assert.Equal(t, float64(100), goldEvents[0].OldValue)
+	assert.Equal(t, float64(150), goldEvents[0].NewValue)
+	assert.Equal(t, doc.GetSessionID(), goldEvents[0].Origin)
+
+	// 상위 경로 구독은 하위 변경도 수신
+	assert.Len(t, allEvents, 2)
+
+	// 구독 해제 후에는 수신하지 않음
+	unsubscribe()
+	gold = crdt.NewConstantNode(doc.NextTimestamp(), float64(200))
+	doc.AddNode(gold)
+	alice.Set("gold", gold.ID(), gold)
+	require.NoError(t, editor.NotifyChanges())
+	assert.Len(t, goldEvents, 1)
+	assert.Len(t, allEvents, 3)
+}
+
+// TestMatchPathPattern tests glob matching of paths
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"players.*.gold", "players.alice.gold", true},
+		{"players.*.gold", "players.alice.level", false},
+		{"players", "players.alice.gold", true},
+		{"players.a*", "players.alice.gold", true},
+		{"players.b*", "players.alice.gold", false},
+		{"**.gold", "players.alice.gold", true},
+		{"items[1].name", "items[1].name", true},
+		{"items[*].name", "items[12].name", true},
+		{"items.*", "players.alice", false},
+	}
+
+	for _, tt := range tests {
+		got := matchPathPattern(splitPathSegments(tt.pattern), splitPathSegments(tt.path))
+		assert.Equal(t, tt.want, got, "pattern %s path %s", tt.pattern, tt.path)
+	}
+}
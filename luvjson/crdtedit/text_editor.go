@@ -0,0 +1,182 @@
+package crdtedit
+
+import (
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// AsText returns a TextEditor for the RGA string at the given path
+func (e *DocumentEditor) AsText(path string) (TextEditor, error) {
+	if err := e.checkAccess(path, AccessRead); err != nil {
+		return nil, err
+	}
+
+	nodeID, err := e.pathResolver.ResolveNodePath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve path %s", path)
+	}
+
+	node, err := e.doc.GetNode(nodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get node at path %s", path)
+	}
+
+	strNode, ok := derefValueNode(node).(*crdt.RGAStringNode)
+	if !ok {
+		return nil, errors.Errorf("node at path %s is not a text node", path)
+	}
+
+	return newTextEditor(e, path, strNode.ID()), nil
+}
+
+// textEditor implements the TextEditor interface
+type textEditor struct {
+	editor *DocumentEditor
+	path   string
+	nodeID common.LogicalTimestamp
+}
+
+// newTextEditor creates a new textEditor
+func newTextEditor(editor *DocumentEditor, path string, nodeID common.LogicalTimestamp) *textEditor {
+	return &textEditor{
+		editor: editor,
+		path:   path,
+		nodeID: nodeID,
+	}
+}
+
+// InsertAt inserts text before the character at pos
+func (e *textEditor) InsertAt(pos int, text string) (*crdtpatch.Patch, error) {
+	return e.ReplaceRange(pos, pos, text)
+}
+
+// DeleteRange removes the characters in [start, end)
+func (e *textEditor) DeleteRange(start, end int) (*crdtpatch.Patch, error) {
+	if end <= start {
+		return nil, errors.Errorf("invalid range: [%d, %d)", start, end)
+	}
+	return e.ReplaceRange(start, end, "")
+}
+
+// ReplaceRange replaces the characters in [start, end) with text
+func (e *textEditor) ReplaceRange(start, end int, text string) (*crdtpatch.Patch, error) {
+	if err := e.editor.checkWrite(e.path); err != nil {
+		return nil, err
+	}
+
+	strNode, err := e.stringNode()
+	if err != nil {
+		return nil, err
+	}
+
+	chars := strNode.VisibleIDs()
+	if start < 0 || end < start || end > len(chars) {
+		return nil, errors.Errorf("invalid range: [%d, %d)", start, end)
+	}
+	if start == end && text == "" {
+		return nil, errors.New("nothing to replace")
+	}
+
+	doc := e.editor.GetDocument()
+	patchBuilder := NewPatchBuilder(doc.GetSessionID())
+
+	var patchID common.LogicalTimestamp
+	nextID := func(span int) common.LogicalTimestamp {
+		id := reserveTextIDs(doc, strNode, span)
+		if patchID.SID == common.NilSessionID {
+			patchID = id
+		}
+		return id
+	}
+
+	// 삭제는 문자 단위로 기록 (동시 삽입된 문자가 함께 지워지지 않도록)
+	for _, charID := range chars[start:end] {
+		if err := patchBuilder.AddTextDeleteOperation(nextID(1), e.nodeID, charID); err != nil {
+			return nil, errors.Wrapf(err, "failed to delete text from range [%d, %d)", start, end)
+		}
+	}
+
+	if text != "" {
+		refID := common.RootID
+		if start > 0 {
+			refID = chars[start-1]
+		}
+
+		span := len([]rune(text))
+		if err := patchBuilder.AddTextInsertOperation(nextID(span), e.nodeID, refID, text); err != nil {
+			return nil, errors.Wrapf(err, "failed to insert text at index %d", start)
+		}
+	}
+
+	patch := patchBuilder.CreatePatch(patchID)
+	if err := patch.Apply(doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to apply text patch for range [%d, %d)", start, end)
+	}
+	e.editor.recordPatch(patch)
+
+	if err := e.editor.NotifyChanges(); err != nil {
+		return patch, err
+	}
+	return patch, nil
+}
+
+// GetLength returns the number of characters
+func (e *textEditor) GetLength() (int, error) {
+	strNode, err := e.stringNode()
+	if err != nil {
+		return 0, err
+	}
+	return strNode.Length(), nil
+}
+
+// GetValue returns the entire string value
+func (e *textEditor) GetValue() (string, error) {
+	strNode, err := e.stringNode()
+	if err != nil {
+		return "", err
+	}
+	return strNode.String(), nil
+}
+
+// GetPath returns the path to this string
+func (e *textEditor) GetPath() string {
+	return e.path
+}
+
+// stringNode returns the RGA string node being edited
+func (e *textEditor) stringNode() (*crdt.RGAStringNode, error) {
+	node, err := e.editor.GetDocument().GetNode(e.nodeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get node")
+	}
+
+	strNode, ok := node.(*crdt.RGAStringNode)
+	if !ok {
+		return nil, errors.New("node is not a text node")
+	}
+	return strNode, nil
+}
+
+// reserveTextIDs reserves span consecutive local timestamps and returns the first.
+// The clock is advanced past every character ID already in the string so that new
+// characters order after the ones they were typed next to.
+func reserveTextIDs(doc *crdt.Document, strNode *crdt.RGAStringNode, span int) common.LogicalTimestamp {
+	var maxCounter uint64
+	for _, elem := range strNode.Characters() {
+		if elem.NodeId.Counter > maxCounter {
+			maxCounter = elem.NodeId.Counter
+		}
+	}
+
+	id := doc.NextTimestamp()
+	for id.Counter <= maxCounter {
+		id = doc.NextTimestamp()
+	}
+	for i := 1; i < span; i++ {
+		doc.NextTimestamp()
+	}
+	return id
+}
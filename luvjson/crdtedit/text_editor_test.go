@@ -0,0 +1,116 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// setupTextDocument creates a document whose "content" field is an RGA string.
+// All replicas built from the same baseSID share identical node IDs.
+func setupTextDocument(t *testing.T, baseSID common.SessionID, content string) *crdt.Document {
+	doc := crdt.NewDocument(common.NewSessionID())
+
+	root := crdt.NewLWWObjectNode(common.LogicalTimestamp{SID: baseSID, Counter: 1})
+	doc.AddNode(root)
+
+	text := crdt.NewRGAStringNode(common.LogicalTimestamp{SID: baseSID, Counter: 2})
+	text.Insert(common.RootID, common.LogicalTimestamp{SID: baseSID, Counter: 3}, content)
+	doc.AddNode(text)
+	root.Set("content", text.ID(), text)
+
+	require.NoError(t, doc.SetRoot(root.ID()))
+	return doc
+}
+
+// TestTextEditor tests character-level edits on a single replica
+func TestTextEditor(t *testing.T) {
+	doc := setupTextDocument(t, common.NewSessionID(), "Hello")
+	editor := NewDocumentEditor(doc)
+
+	text, err := editor.AsText("content")
+	require.NoError(t, err)
+	assert.Equal(t, "content", text.GetPath())
+
+	_, err = text.InsertAt(5, " World")
+	require.NoError(t, err)
+	value, _ := text.GetValue()
+	assert.Equal(t, "Hello World", value)
+
+	_, err = text.DeleteRange(0, 6)
+	require.NoError(t, err)
+	value, _ = text.GetValue()
+	assert.Equal(t, "World", value)
+
+	_, err = text.ReplaceRange(0, 5, "안녕하세요")
+	require.NoError(t, err)
+	value, _ = text.GetValue()
+	assert.Equal(t, "안녕하세요", value)
+
+	length, err := text.GetLength()
+	require.NoError(t, err)
+	assert.Equal(t, 5, length)
+
+	// 범위 오류
+	_, err = text.InsertAt(6, "!")
+	assert.Error(t, err)
+	_, err = text.DeleteRange(3, 3)
+	assert.Error(t, err)
+
+	// 문자열 노드가 아닌 경로
+	_, err = editor.AsText("missing")
+	assert.Error(t, err)
+}
+
+// TestTextEditor_ConcurrentEdits tests that concurrent edits from two sessions merge
+func TestTextEditor_ConcurrentEdits(t *testing.T) {
+	baseSID := common.NewSessionID()
+	docA := setupTextDocument(t, baseSID, "Hello")
+	docB := setupTextDocument(t, baseSID, "Hello")
+	editorA := NewDocumentEditor(docA)
+	editorB := NewDocumentEditor(docB)
+
+	textA, err := editorA.AsText("content")
+	require.NoError(t, err)
+	textB, err := editorB.AsText("content")
+	require.NoError(t, err)
+
+	// 서로 다른 위치에 동시 편집
+	patchA, err := textA.InsertAt(5, " World")
+	require.NoError(t, err)
+	patchB, err := textB.ReplaceRange(0, 1, "J")
+	require.NoError(t, err)
+
+	// 네트워크 전송을 가정하여 JSON으로 직렬화 후 적용
+	data, err := patchA.MarshalJSON()
+	require.NoError(t, err)
+	received := &crdtpatch.Patch{}
+	require.NoError(t, received.UnmarshalJSON(data))
+
+	require.NoError(t, editorA.ApplyPatch(patchB))
+	require.NoError(t, editorB.ApplyPatch(received))
+
+	valueA, _ := textA.GetValue()
+	valueB, _ := textB.GetValue()
+	assert.Equal(t, "Jello World", valueA)
+	assert.Equal(t, valueA, valueB)
+
+	// 같은 위치에 동시 삽입해도 두 복제본이 수렴
+	patchA, err = textA.InsertAt(5, "X")
+	require.NoError(t, err)
+	patchB, err = textB.InsertAt(5, "Y")
+	require.NoError(t, err)
+
+	require.NoError(t, editorA.ApplyPatch(patchB))
+	require.NoError(t, editorB.ApplyPatch(patchA))
+
+	valueA, _ = textA.GetValue()
+	valueB, _ = textB.GetValue()
+	assert.Equal(t, valueA, valueB)
+	assert.Contains(t, []string{"JelloXY World", "JelloYX World"}, valueA)
+}
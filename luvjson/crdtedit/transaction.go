@@ -0,0 +1,235 @@
+package crdtedit
+
+import (
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// ErrTransactionClosed is returned when a committed or rolled back transaction is used
+var ErrTransactionClosed = errors.New("transaction is already closed")
+
+// TxFunc is a function that performs edits on a document through a Transaction
+type TxFunc func(tx *Transaction) error
+
+// Transaction batches path-based mutations into a single patch.
+// Operations are collected without touching the document and are applied
+// atomically on Commit. Rollback discards all collected operations.
+type Transaction struct {
+	doc          *crdt.Document
+	pathResolver *PathResolver
+	patchBuilder *PatchBuilder
+	closed       bool
+	checkAccess  func(path string, op AccessOperation) error
+	onApply      func(patch *crdtpatch.Patch)
+	afterCommit  func() error
+}
+
+// newTransaction creates a new Transaction
+func newTransaction(doc *crdt.Document, pathResolver *PathResolver) *Transaction {
+	return &Transaction{
+		doc:          doc,
+		pathResolver: pathResolver,
+		patchBuilder: newDocumentPatchBuilder(doc),
+	}
+}
+
+// SetValue records a set operation for the node at the given path
+func (tx *Transaction) SetValue(path string, value any) error {
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	if err := tx.check(path, AccessWrite); err != nil {
+		return err
+	}
+
+	nodeID, err := tx.pathResolver.ResolveNodePath(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve path %s", path)
+	}
+
+	if err := tx.patchBuilder.AddSetOperation(nodeID, value); err != nil {
+		return errors.Wrapf(err, "failed to set value at path %s", path)
+	}
+	return nil
+}
+
+// SetKey records an insert operation for a key in the object at the given path
+func (tx *Transaction) SetKey(path string, key string, value any) error {
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	if err := tx.check(childKeyPath(path, key), AccessWrite); err != nil {
+		return err
+	}
+
+	nodeID, err := tx.resolveNodeOfType(path, NodeTypeObject)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.patchBuilder.AddObjectInsertOperation(nodeID, key, value); err != nil {
+		return errors.Wrapf(err, "failed to set key %s at path %s", key, path)
+	}
+	return nil
+}
+
+// DeleteKey records a delete operation for a key in the object at the given path
+func (tx *Transaction) DeleteKey(path string, key string) error {
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	if err := tx.check(childKeyPath(path, key), AccessDelete); err != nil {
+		return err
+	}
+
+	nodeID, err := tx.resolveNodeOfType(path, NodeTypeObject)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.patchBuilder.AddObjectDeleteOperation(nodeID, key); err != nil {
+		return errors.Wrapf(err, "failed to delete key %s at path %s", key, path)
+	}
+	return nil
+}
+
+// InsertArrayElement records an insert operation for the array at the given path
+func (tx *Transaction) InsertArrayElement(path string, index int, value any) error {
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	if err := tx.check(path, AccessWrite); err != nil {
+		return err
+	}
+
+	nodeID, err := tx.resolveNodeOfType(path, NodeTypeArray)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.patchBuilder.AddArrayInsertOperation(nodeID, index, value); err != nil {
+		return errors.Wrapf(err, "failed to insert element at index %d in array at path %s", index, path)
+	}
+	return nil
+}
+
+// DeleteArrayElement records a delete operation for the array at the given path
+func (tx *Transaction) DeleteArrayElement(path string, index int) error {
+	if tx.closed {
+		return ErrTransactionClosed
+	}
+	if err := tx.check(childIndexPath(path, index), AccessDelete); err != nil {
+		return err
+	}
+
+	nodeID, err := tx.resolveNodeOfType(path, NodeTypeArray)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.patchBuilder.AddArrayDeleteOperation(nodeID, index); err != nil {
+		return errors.Wrapf(err, "failed to delete element at index %d in array at path %s", index, path)
+	}
+	return nil
+}
+
+// Operations returns the operations collected so far
+func (tx *Transaction) Operations() []crdtpatch.Operation {
+	return tx.patchBuilder.Build()
+}
+
+// Commit applies all collected operations to the document as a single patch.
+// If any operation fails, the document, including its clock, is left untouched.
+func (tx *Transaction) Commit() (*crdtpatch.Patch, error) {
+	if tx.closed {
+		return nil, ErrTransactionClosed
+	}
+	tx.closed = true
+
+	if len(tx.patchBuilder.Build()) == 0 {
+		return tx.patchBuilder.CreatePatch(tx.doc.NextTimestamp()), nil
+	}
+
+	// 복사본에 먼저 적용해 보고, 실패하면 문서와 시계를 건드리지 않음
+	// (적용 후 되돌리면 실패한 연산의 ID가 적용된 것으로 남아 재사용할 수 없음)
+	trial, err := tx.trialDocument()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.patchBuilder.CreatePatch(trial.NextTimestamp()).Apply(trial); err != nil {
+		return nil, errors.Wrap(err, "failed to apply transaction patch")
+	}
+
+	patch := tx.patchBuilder.CreatePatch(tx.doc.NextTimestamp())
+	if err := patch.Apply(tx.doc); err != nil {
+		return nil, errors.Wrap(err, "failed to apply transaction patch")
+	}
+
+	if tx.onApply != nil {
+		tx.onApply(patch)
+	}
+
+	if tx.afterCommit != nil {
+		if err := tx.afterCommit(); err != nil {
+			return patch, errors.Wrap(err, "failed to run commit hook")
+		}
+	}
+
+	return patch, nil
+}
+
+// Rollback discards all collected operations without touching the document
+func (tx *Transaction) Rollback() {
+	tx.closed = true
+	tx.patchBuilder = newDocumentPatchBuilder(tx.doc)
+}
+
+// trialDocument returns a copy of the document to try the patch on.
+// The binary snapshot keeps the nodes only referenced by ID, such as array elements.
+func (tx *Transaction) trialDocument() (*crdt.Document, error) {
+	snapshot, err := tx.doc.MarshalBinary()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to snapshot document")
+	}
+	trial := crdt.NewDocument(tx.doc.GetSessionID())
+	if err := trial.UnmarshalBinary(snapshot); err != nil {
+		return nil, errors.Wrap(err, "failed to copy document")
+	}
+	return trial, nil
+}
+
+// check runs the access check for op on the path, if one is configured
+func (tx *Transaction) check(path string, op AccessOperation) error {
+	if tx.checkAccess == nil {
+		return nil
+	}
+	return tx.checkAccess(path, op)
+}
+
+// resolveNodeOfType resolves the path and checks that the node has the expected type
+func (tx *Transaction) resolveNodeOfType(path string, expected NodeType) (common.LogicalTimestamp, error) {
+	nodeID, err := tx.pathResolver.ResolveNodePath(path)
+	if err != nil {
+		return common.LogicalTimestamp{}, errors.Wrapf(err, "failed to resolve path %s", path)
+	}
+
+	// 루트 경로는 루트가 가리키는 노드로 변환
+	node, err := tx.doc.GetNode(nodeID)
+	if err != nil {
+		return common.LogicalTimestamp{}, errors.Wrapf(err, "failed to get node at path %s", path)
+	}
+	nodeID = derefValueNode(node).ID()
+
+	nodeType, err := tx.pathResolver.GetNodeType(nodeID)
+	if err != nil {
+		return common.LogicalTimestamp{}, errors.Wrapf(err, "failed to get node type at path %s", path)
+	}
+
+	if nodeType != expected {
+		return common.LogicalTimestamp{}, errors.Errorf("node at path %s is not %s", path, expected)
+	}
+	return nodeID, nil
+}
@@ -0,0 +1,144 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+)
+
+// TestTransaction_Commit tests applying several operations as a single patch
+func TestTransaction_Commit(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+	before := doc.Clock()
+
+	tx := editor.Begin()
+	require.NoError(t, tx.SetKey("players.alice", "gold", float64(90)))
+	require.NoError(t, tx.SetKey("players.bob", "gold", float64(50)))
+	require.NoError(t, tx.InsertArrayElement("items", 3, "shield"))
+	require.NoError(t, tx.DeleteArrayElement("items", 0))
+
+	// 커밋 전에는 문서가 바뀌지 않음
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), gold)
+
+	patch, err := tx.Commit()
+	require.NoError(t, err)
+	require.Len(t, patch.Operations(), 7)
+
+	// 새 ID는 세션 내에서 증가하고 기존 시계보다 뒤에 옴
+	var last common.LogicalTimestamp
+	for _, c := range before {
+		if c.SID == doc.GetSessionID() {
+			last = c
+		}
+	}
+	for _, op := range patch.Operations() {
+		id := op.GetID()
+		assert.Equal(t, doc.GetSessionID(), id.SID)
+		assert.True(t, last.Compare(id) < 0, "%s should come after %s", id, last)
+		last = id
+	}
+
+	for path, want := range map[string]any{
+		"players.alice.gold": float64(90),
+		"players.bob.gold":   float64(50),
+		"items[0].name":      "sword",
+		"items[2]":           "shield",
+	} {
+		got, err := editor.Query(path)
+		require.NoError(t, err, path)
+		assert.Equal(t, want, got, path)
+	}
+}
+
+// TestTransaction_CommitFailure tests that a failing operation leaves the document untouched
+func TestTransaction_CommitFailure(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	editor.EnableHistory(0)
+
+	tx := editor.Begin()
+	require.NoError(t, tx.SetKey("players.alice", "gold", float64(90)))
+	require.NoError(t, tx.DeleteArrayElement("items", 10))
+
+	beforeJSON, err := editor.GetJSON()
+	require.NoError(t, err)
+	beforeClock := doc.Clock()
+
+	_, err = tx.Commit()
+	require.Error(t, err)
+
+	afterJSON, err := editor.GetJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(beforeJSON), string(afterJSON))
+	assert.Equal(t, beforeClock, doc.Clock())
+	history, err := editor.History("")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	// 복원된 문서는 계속 편집 가능
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		return tx.SetKey("players.alice", "gold", float64(80))
+	})
+	require.NoError(t, err)
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(80), gold)
+}
+
+// TestTransaction_Rollback tests discarding recorded operations
+func TestTransaction_Rollback(t *testing.T) {
+	editor := NewDocumentEditor(setupJSONPathDocument(t))
+
+	tx := editor.Begin()
+	require.NoError(t, tx.SetKey("players.alice", "gold", float64(90)))
+	tx.Rollback()
+
+	assert.Empty(t, tx.Operations())
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), gold)
+
+	// 함수가 에러를 반환하면 롤백
+	_, err = editor.Transaction(func(tx *Transaction) error {
+		require.NoError(t, tx.SetKey("players.alice", "gold", float64(90)))
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	gold, err = editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), gold)
+}
+
+// TestTransaction_Closed tests that a transaction cannot be used after commit or rollback
+func TestTransaction_Closed(t *testing.T) {
+	editor := NewDocumentEditor(setupJSONPathDocument(t))
+
+	committed := editor.Begin()
+	require.NoError(t, committed.SetKey("players.alice", "gold", float64(90)))
+	_, err := committed.Commit()
+	require.NoError(t, err)
+
+	rolledBack := editor.Begin()
+	rolledBack.Rollback()
+
+	for _, tx := range []*Transaction{committed, rolledBack} {
+		assert.ErrorIs(t, tx.SetValue("players.alice.gold", float64(1)), ErrTransactionClosed)
+		assert.ErrorIs(t, tx.SetKey("players.alice", "gold", float64(1)), ErrTransactionClosed)
+		assert.ErrorIs(t, tx.DeleteKey("players.alice", "gold"), ErrTransactionClosed)
+		assert.ErrorIs(t, tx.InsertArrayElement("items", 0, "bow"), ErrTransactionClosed)
+		assert.ErrorIs(t, tx.DeleteArrayElement("items", 0), ErrTransactionClosed)
+		_, err := tx.Commit()
+		assert.ErrorIs(t, err, ErrTransactionClosed)
+	}
+
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(90), gold)
+}
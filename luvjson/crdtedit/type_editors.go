@@ -1,6 +1,8 @@
 package crdtedit
 
 import (
+	"sort"
+
 	"github.com/pkg/errors"
 
 	"tictactoe/luvjson/common"
@@ -25,6 +27,10 @@ func newObjectEditor(ctx *EditContext, path string, nodeID common.LogicalTimesta
 
 // SetKey implements ObjectEditor.SetKey
 func (e *objectEditor) SetKey(key string, value any) (ObjectEditor, error) {
+	if err := e.ctx.check(childKeyPath(e.path, key), AccessWrite); err != nil {
+		return nil, err
+	}
+
 	err := e.ctx.patchBuilder.AddObjectInsertOperation(e.nodeID, key, value)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to set key %s", key)
@@ -45,6 +51,10 @@ func (e *objectEditor) SetKey(key string, value any) (ObjectEditor, error) {
 
 // DeleteKey implements ObjectEditor.DeleteKey
 func (e *objectEditor) DeleteKey(key string) (ObjectEditor, error) {
+	if err := e.ctx.check(childKeyPath(e.path, key), AccessDelete); err != nil {
+		return nil, err
+	}
+
 	err := e.ctx.patchBuilder.AddObjectDeleteOperation(e.nodeID, key)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to delete key %s", key)
@@ -141,6 +151,10 @@ func newArrayEditor(ctx *EditContext, path string, nodeID common.LogicalTimestam
 
 // Append implements ArrayEditor.Append
 func (e *arrayEditor) Append(value any) (ArrayEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
 	node, err := e.ctx.doc.GetNode(e.nodeID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get node")
@@ -172,6 +186,10 @@ func (e *arrayEditor) Append(value any) (ArrayEditor, error) {
 
 // Insert implements ArrayEditor.Insert
 func (e *arrayEditor) Insert(index int, value any) (ArrayEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
 	node, err := e.ctx.doc.GetNode(e.nodeID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get node")
@@ -206,6 +224,10 @@ func (e *arrayEditor) Insert(index int, value any) (ArrayEditor, error) {
 
 // Delete implements ArrayEditor.Delete
 func (e *arrayEditor) Delete(index int) (ArrayEditor, error) {
+	if err := e.ctx.check(childIndexPath(e.path, index), AccessDelete); err != nil {
+		return nil, err
+	}
+
 	node, err := e.ctx.doc.GetNode(e.nodeID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get node")
@@ -286,6 +308,246 @@ func (e *arrayEditor) GetElement(index int) (any, error) {
 	return value, nil
 }
 
+// Splice implements ArrayEditor.Splice
+func (e *arrayEditor) Splice(start, deleteCount int, items ...any) (ArrayEditor, error) {
+	arrNode, err := e.arrayNode()
+	if err != nil {
+		return nil, err
+	}
+
+	length := arrNode.Length()
+	if start < 0 || start > length {
+		return nil, errors.Errorf("index out of bounds: %d", start)
+	}
+	if deleteCount < 0 || start+deleteCount > length {
+		return nil, errors.Errorf("invalid delete count %d at index %d", deleteCount, start)
+	}
+
+	for i := 0; i < deleteCount; i++ {
+		if err := e.ctx.check(childIndexPath(e.path, start+i), AccessDelete); err != nil {
+			return nil, err
+		}
+	}
+	if len(items) > 0 {
+		if err := e.ctx.check(e.path, AccessWrite); err != nil {
+			return nil, err
+		}
+	}
+
+	// 한 번의 패치로 삭제와 삽입을 함께 적용
+	patchBuilder := newDocumentPatchBuilder(e.ctx.doc)
+	for i := 0; i < deleteCount; i++ {
+		// 삭제 후 뒤의 요소가 앞으로 당겨지므로 항상 같은 인덱스를 삭제
+		if err := patchBuilder.AddArrayDeleteOperation(e.nodeID, start); err != nil {
+			return nil, errors.Wrapf(err, "failed to delete element at index %d", start+i)
+		}
+	}
+	for i, item := range items {
+		if err := patchBuilder.AddArrayInsertOperation(e.nodeID, start+i, item); err != nil {
+			return nil, errors.Wrapf(err, "failed to insert element at index %d", start+i)
+		}
+	}
+
+	if err := e.applyPatch(patchBuilder); err != nil {
+		return nil, errors.Wrapf(err, "failed to apply patch for splice at index %d", start)
+	}
+	return e, nil
+}
+
+// Move implements ArrayEditor.Move
+func (e *arrayEditor) Move(from, to int) (ArrayEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	arrNode, err := e.arrayNode()
+	if err != nil {
+		return nil, err
+	}
+
+	length := arrNode.Length()
+	if from < 0 || from >= length {
+		return nil, errors.Errorf("index out of bounds: %d", from)
+	}
+	if to < 0 || to >= length {
+		return nil, errors.Errorf("index out of bounds: %d", to)
+	}
+	if from == to {
+		return e, nil
+	}
+
+	elemID, err := arrNode.Get(from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get element at index %d", from)
+	}
+
+	// 기존 요소 노드를 재사용하여 다른 필드에 대한 동시 편집을 보존
+	patchBuilder := newDocumentPatchBuilder(e.ctx.doc)
+	if err := e.addMoveOperations(patchBuilder, from, to, elemID); err != nil {
+		return nil, err
+	}
+
+	if err := e.applyPatch(patchBuilder); err != nil {
+		return nil, errors.Wrapf(err, "failed to apply patch for move from %d to %d", from, to)
+	}
+	return e, nil
+}
+
+// SortBy implements ArrayEditor.SortBy
+func (e *arrayEditor) SortBy(less func(a, b any) bool) (ArrayEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	if less == nil {
+		return nil, errors.New("less function cannot be nil")
+	}
+
+	arrNode, err := e.arrayNode()
+	if err != nil {
+		return nil, err
+	}
+
+	length := arrNode.Length()
+	elemIDs := make([]common.LogicalTimestamp, length)
+	values := make([]any, length)
+	for i := 0; i < length; i++ {
+		elemIDs[i], err = arrNode.Get(i)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get element at index %d", i)
+		}
+		if values[i], err = e.GetElement(i); err != nil {
+			return nil, err
+		}
+	}
+
+	// 정렬 후 각 위치에 올 원래 인덱스
+	order := make([]int, length)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return less(values[order[a]], values[order[b]])
+	})
+
+	// 최장 증가 부분 수열에 속한 요소는 제자리에 두고 나머지만 이동
+	stay := longestIncreasingSubsequence(order)
+
+	current := make([]int, length)
+	for i := range current {
+		current[i] = i
+	}
+
+	patchBuilder := newDocumentPatchBuilder(e.ctx.doc)
+	for rank, original := range order {
+		if stay[original] {
+			continue
+		}
+
+		from := indexOf(current, original)
+		to := 0
+		if rank > 0 {
+			to = indexOf(current, order[rank-1]) + 1
+		}
+		if from < to {
+			to--
+		}
+		if from == to {
+			continue
+		}
+
+		if err := e.addMoveOperations(patchBuilder, from, to, elemIDs[original]); err != nil {
+			return nil, err
+		}
+
+		current = append(current[:from], current[from+1:]...)
+		current = append(current[:to], append([]int{original}, current[to:]...)...)
+	}
+
+	if len(patchBuilder.Build()) == 0 {
+		return e, nil
+	}
+
+	if err := e.applyPatch(patchBuilder); err != nil {
+		return nil, errors.Wrap(err, "failed to apply patch for sort")
+	}
+	return e, nil
+}
+
+// arrayNode returns the underlying RGA array node
+func (e *arrayEditor) arrayNode() (*crdt.RGAArrayNode, error) {
+	node, err := e.ctx.doc.GetNode(e.nodeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get node")
+	}
+
+	arrNode, ok := node.(*crdt.RGAArrayNode)
+	if !ok {
+		return nil, errors.New("node is not an array")
+	}
+	return arrNode, nil
+}
+
+// addMoveOperations adds the delete and re-insert operations that move an existing element
+func (e *arrayEditor) addMoveOperations(patchBuilder *PatchBuilder, from, to int, elemID common.LogicalTimestamp) error {
+	if err := patchBuilder.AddArrayDeleteOperation(e.nodeID, from); err != nil {
+		return errors.Wrapf(err, "failed to delete element at index %d", from)
+	}
+	if err := patchBuilder.AddArrayInsertOperation(e.nodeID, to, elemID); err != nil {
+		return errors.Wrapf(err, "failed to insert element at index %d", to)
+	}
+	return nil
+}
+
+// applyPatch creates a patch from the builder and applies it to the document
+func (e *arrayEditor) applyPatch(patchBuilder *PatchBuilder) error {
+	patchID := common.LogicalTimestamp{
+		SID:     common.NewSessionID(),
+		Counter: 1,
+	}
+	patch := patchBuilder.CreatePatch(patchID)
+	return patch.Apply(e.ctx.doc)
+}
+
+// longestIncreasingSubsequence returns the set of values in seq that form
+// a longest strictly increasing subsequence
+func longestIncreasingSubsequence(seq []int) map[int]bool {
+	tails := make([]int, 0, len(seq))
+	prev := make([]int, len(seq))
+	for i, v := range seq {
+		pos := sort.Search(len(tails), func(j int) bool { return seq[tails[j]] >= v })
+		if pos > 0 {
+			prev[i] = tails[pos-1]
+		} else {
+			prev[i] = -1
+		}
+		if pos == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[pos] = i
+		}
+	}
+
+	result := make(map[int]bool, len(tails))
+	if len(tails) == 0 {
+		return result
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		result[seq[i]] = true
+	}
+	return result
+}
+
+// indexOf returns the index of v in values, or -1
+func indexOf(values []int, v int) int {
+	for i, value := range values {
+		if value == v {
+			return i
+		}
+	}
+	return -1
+}
+
 // GetPath implements ArrayEditor.GetPath
 func (e *arrayEditor) GetPath() string {
 	return e.path
@@ -309,6 +571,10 @@ func newStringEditor(ctx *EditContext, path string, nodeID common.LogicalTimesta
 
 // Append implements StringEditor.Append
 func (e *stringEditor) Append(text string) (StringEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
 	node, err := e.ctx.doc.GetNode(e.nodeID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get node")
@@ -341,6 +607,10 @@ func (e *stringEditor) Append(text string) (StringEditor, error) {
 
 // Insert implements StringEditor.Insert
 func (e *stringEditor) Insert(index int, text string) (StringEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
 	node, err := e.ctx.doc.GetNode(e.nodeID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get node")
@@ -377,6 +647,10 @@ func (e *stringEditor) Insert(index int, text string) (StringEditor, error) {
 
 // Delete implements StringEditor.Delete
 func (e *stringEditor) Delete(start, end int) (StringEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
 	node, err := e.ctx.doc.GetNode(e.nodeID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get node")
@@ -497,6 +771,10 @@ func (e *numberEditor) Increment(delta float64) (NumberEditor, error) {
 
 // SetValue implements NumberEditor.SetValue
 func (e *numberEditor) SetValue(value float64) (NumberEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
 	err := e.ctx.patchBuilder.AddSetOperation(e.nodeID, value)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to set number value")
@@ -574,6 +852,10 @@ func (e *booleanEditor) Toggle() (BooleanEditor, error) {
 
 // SetValue implements BooleanEditor.SetValue
 func (e *booleanEditor) SetValue(value bool) (BooleanEditor, error) {
+	if err := e.ctx.check(e.path, AccessWrite); err != nil {
+		return nil, err
+	}
+
 	err := e.ctx.patchBuilder.AddSetOperation(e.nodeID, value)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to set boolean value")
@@ -288,3 +288,177 @@ func TestBooleanEditor(t *testing.T) {
 	// 경로 확인
 	assert.Equal(t, "flag", boolEditor.GetPath(), "Path should match")
 }
+
+// itemNames returns the names of the items in the array
+func itemNames(t *testing.T, arr ArrayEditor) []any {
+	length, err := arr.GetLength()
+	require.NoError(t, err)
+
+	names := make([]any, length)
+	for i := range names {
+		value, err := arr.GetElement(i)
+		require.NoError(t, err)
+		if item, ok := value.(map[string]any); ok {
+			names[i] = item["name"]
+		} else {
+			names[i] = value
+		}
+	}
+	return names
+}
+
+// TestArrayEditor_Splice tests deleting and inserting elements in one patch
+func TestArrayEditor_Splice(t *testing.T) {
+	editor := NewDocumentEditor(setupJSONPathDocument(t))
+	arr, err := editor.AsArray("items")
+	require.NoError(t, err)
+
+	arr, err = arr.Splice(1, 1, "shield", "bow")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"potion", "shield", "bow", "arrow"}, itemNames(t, arr))
+
+	// 끝에 삽입만
+	arr, err = arr.Splice(4, 0, "rope")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"potion", "shield", "bow", "arrow", "rope"}, itemNames(t, arr))
+
+	// 처음부터 삭제만
+	arr, err = arr.Splice(0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"bow", "arrow", "rope"}, itemNames(t, arr))
+
+	// 범위를 벗어난 인덱스
+	for _, tt := range []struct{ start, deleteCount int }{{-1, 0}, {4, 0}, {2, 2}, {0, -1}} {
+		_, err := arr.Splice(tt.start, tt.deleteCount, "x")
+		assert.Error(t, err, "splice(%d, %d)", tt.start, tt.deleteCount)
+	}
+	assert.Equal(t, []any{"bow", "arrow", "rope"}, itemNames(t, arr))
+}
+
+// TestArrayEditor_Move tests moving elements without recreating them
+func TestArrayEditor_Move(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+	arr, err := editor.AsArray("items")
+	require.NoError(t, err)
+
+	before := arrayValueIDs(t, editor, "items")
+
+	arr, err = arr.Move(0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"sword", "arrow", "potion"}, itemNames(t, arr))
+
+	// 요소 노드는 그대로 재사용
+	after := arrayValueIDs(t, editor, "items")
+	assert.Equal(t, []common.LogicalTimestamp{before[1], before[2], before[0]}, after)
+
+	// 같은 위치로의 이동은 아무것도 바꾸지 않음
+	clock := doc.Clock()
+	arr, err = arr.Move(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"sword", "arrow", "potion"}, itemNames(t, arr))
+	assert.Equal(t, clock, doc.Clock())
+
+	for _, tt := range []struct{ from, to int }{{-1, 0}, {3, 0}, {0, 3}, {0, -1}} {
+		_, err := arr.Move(tt.from, tt.to)
+		assert.Error(t, err, "move(%d, %d)", tt.from, tt.to)
+	}
+}
+
+// TestArrayEditor_SortBy tests that sorting is stable
+func TestArrayEditor_SortBy(t *testing.T) {
+	editor := NewDocumentEditor(setupJSONPathDocument(t))
+	arr, err := editor.AsArray("items")
+	require.NoError(t, err)
+
+	arr, err = arr.Splice(3, 0, map[string]any{"name": "bow", "qty": float64(5)})
+	require.NoError(t, err)
+
+	qty := func(v any) float64 {
+		item, _ := v.(map[string]any)
+		qty, _ := item["qty"].(float64)
+		return qty
+	}
+	arr, err = arr.SortBy(func(a, b any) bool { return qty(a) < qty(b) })
+	require.NoError(t, err)
+
+	// potion과 bow는 수량이 같으므로 원래 순서를 유지
+	assert.Equal(t, []any{"sword", "potion", "bow", "arrow"}, itemNames(t, arr))
+
+	// 이미 정렬된 배열은 바뀌지 않음
+	ids := arrayValueIDs(t, editor, "items")
+	arr, err = arr.SortBy(func(a, b any) bool { return qty(a) < qty(b) })
+	require.NoError(t, err)
+	assert.Equal(t, ids, arrayValueIDs(t, editor, "items"))
+
+	_, err = arr.SortBy(nil)
+	assert.Error(t, err)
+}
+
+// TestArrayEditor_NewNodeIDs tests that Splice, Move and SortBy do not reuse the IDs of existing nodes
+func TestArrayEditor_NewNodeIDs(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+	arr, err := editor.AsArray("items")
+	require.NoError(t, err)
+
+	// 기존 노드와 배열 요소의 ID
+	existing := make(map[common.LogicalTimestamp]bool)
+	editor.collectNodeIDs(doc.Root(), existing)
+	itemsNode := arrayNodeAt(t, editor, "items")
+	elems := make(map[common.LogicalTimestamp]bool)
+	for _, elem := range itemsNode.NodeElements {
+		existing[elem.NodeId] = true
+		elems[elem.NodeId] = true
+	}
+
+	_, err = arr.Splice(0, 0, "shield", "bow")
+	require.NoError(t, err)
+	for _, id := range arrayValueIDs(t, editor, "items")[:2] {
+		assert.False(t, existing[id], "inserted value %s reuses an existing ID", id)
+	}
+
+	_, err = arr.Move(0, 4)
+	require.NoError(t, err)
+	_, err = arr.SortBy(func(a, b any) bool {
+		_, aString := a.(string)
+		_, bString := b.(string)
+		return aString && !bString
+	})
+	require.NoError(t, err)
+
+	// 삽입과 이동으로 생긴 요소는 모두 새 ID를 사용
+	for _, elem := range itemsNode.NodeElements {
+		if !elems[elem.NodeId] {
+			assert.False(t, existing[elem.NodeId], "element %s reuses an existing ID", elem.NodeId)
+		}
+	}
+
+	// 기존 데이터는 손상되지 않음
+	assert.Equal(t, []any{"bow", "shield", "potion", "sword", "arrow"}, itemNames(t, arr))
+	gold, err := editor.Query("players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), gold)
+}
+
+// arrayNodeAt returns the RGA array node at path
+func arrayNodeAt(t *testing.T, editor *DocumentEditor, path string) *crdt.RGAArrayNode {
+	nodeID, err := editor.pathResolver.ResolveNodePath(path)
+	require.NoError(t, err)
+	node, err := editor.GetDocument().GetNode(nodeID)
+	require.NoError(t, err)
+	arr, ok := derefValueNode(node).(*crdt.RGAArrayNode)
+	require.True(t, ok)
+	return arr
+}
+
+// arrayValueIDs returns the IDs of the value nodes of the visible elements of the array at path
+func arrayValueIDs(t *testing.T, editor *DocumentEditor, path string) []common.LogicalTimestamp {
+	var ids []common.LogicalTimestamp
+	for _, elem := range arrayNodeAt(t, editor, path).NodeElements {
+		if !elem.NodeDeleted {
+			ids = append(ids, elem.NodeValue.(common.LogicalTimestamp))
+		}
+	}
+	return ids
+}
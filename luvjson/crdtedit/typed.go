@@ -0,0 +1,179 @@
+package crdtedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// Typed is a statically typed view of a document whose root is an object
+// described by the struct T.
+//
+// Document paths are derived from the `json` tags of T, so callers read and edit
+// the document through T instead of string paths:
+//
+//	state := crdtedit.NewTyped[RaidState](editor)
+//	patch, err := state.Set(func(s *RaidState) { s.BossHP -= 10 })
+//
+// Set only writes the fields that changed, so concurrent edits of other fields
+// by other sessions are preserved.
+type Typed[T any] struct {
+	editor *DocumentEditor
+}
+
+// NewTyped creates a typed view of the editor's document.
+// It panics if T is not a struct type.
+func NewTyped[T any](editor *DocumentEditor) *Typed[T] {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Struct {
+		panic("crdtedit: Typed requires a struct type, got " + t.String())
+	}
+	return &Typed[T]{editor: editor}
+}
+
+// Editor returns the underlying document editor
+func (t *Typed[T]) Editor() *DocumentEditor {
+	return t.editor
+}
+
+// Init initializes the document from value using the same JSON encoding as Get
+func (t *Typed[T]) Init(value T) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %T", value)
+	}
+	return t.editor.InitFromJSONStream(bytes.NewReader(data))
+}
+
+// Get returns the current document contents as a T
+func (t *Typed[T]) Get() (T, error) {
+	var value T
+	if err := t.editor.checkAccess("", AccessRead); err != nil {
+		return value, err
+	}
+
+	decoded, err := t.editor.queryEngine.decodeNode(t.editor.GetDocument().Root())
+	if err != nil {
+		return value, errors.Wrap(err, "failed to decode document")
+	}
+
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return value, errors.Wrap(err, "failed to marshal document value")
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, errors.Wrapf(err, "failed to decode document into %T", value)
+	}
+	return value, nil
+}
+
+// Set passes the current contents to fn and writes the fields fn changed to the
+// document as a single patch. Nested structs stored as objects are compared field
+// by field; other changed values, including slices, are replaced as a whole.
+// It returns nil if fn changed nothing.
+func (t *Typed[T]) Set(fn func(*T)) (*crdtpatch.Patch, error) {
+	if fn == nil {
+		return nil, errors.New("update function cannot be nil")
+	}
+
+	value, err := t.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := structSnapshot(&value)
+	if err != nil {
+		return nil, err
+	}
+	fn(&value)
+	current, err := structSnapshot(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	if reflect.DeepEqual(old, current) {
+		return nil, nil
+	}
+	return t.editor.Transaction(func(tx *Transaction) error {
+		return t.writeChanges(tx, "", old, current)
+	})
+}
+
+// Path returns the document path of a field of T given as a dot-separated list of
+// Go field names, e.g. "Boss.HP" becomes "boss.hp" for fields tagged `json:"boss"`
+// and `json:"hp"`
+func (t *Typed[T]) Path(field string) (string, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	segments := make([]string, 0)
+	for _, name := range strings.Split(field, ".") {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return "", errors.Errorf("field %s of %s is not a struct field", name, field)
+		}
+
+		sf, ok := typ.FieldByName(name)
+		if !ok || !sf.IsExported() {
+			return "", errors.Errorf("no exported field %s in %s", name, typ)
+		}
+
+		key := jsonFieldName(sf)
+		if key == "-" {
+			return "", errors.Errorf("field %s is not stored in the document", name)
+		}
+		segments = append(segments, key)
+		typ = sf.Type
+	}
+	return strings.Join(segments, "."), nil
+}
+
+// writeChanges records the transaction operations that turn the object at path
+// from old into current
+func (t *Typed[T]) writeChanges(tx *Transaction, path string, old, current map[string]any) error {
+	for _, change := range diffSnapshots(old, current, false) {
+		if _, exists := current[change.Field]; !exists {
+			if err := tx.DeleteKey(path, change.Field); err != nil {
+				return errors.Wrapf(err, "failed to delete field %s", childKeyPath(path, change.Field))
+			}
+			continue
+		}
+
+		// 객체로 저장된 중첩 구조체는 변경된 필드만 기록
+		oldObj, oldOK := change.OldValue.(map[string]any)
+		newObj, newOK := change.NewValue.(map[string]any)
+		childPath := childKeyPath(path, change.Field)
+		if oldOK && newOK {
+			if _, err := t.editor.objectIDAt(childPath); err == nil {
+				if err := t.writeChanges(tx, childPath, oldObj, newObj); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := tx.SetKey(path, change.Field, change.NewValue); err != nil {
+			return errors.Wrapf(err, "failed to set field %s", childPath)
+		}
+	}
+	return nil
+}
+
+// jsonFieldName returns the JSON object key of a struct field
+func jsonFieldName(sf reflect.StructField) string {
+	tag := sf.Tag.Get("json")
+	if tag == "" {
+		return sf.Name
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,96 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+type typedBoss struct {
+	Name string `json:"name"`
+	HP   int    `json:"hp"`
+}
+
+type typedRaidState struct {
+	Phase   string    `json:"phase"`
+	Boss    typedBoss `json:"boss"`
+	Players []string  `json:"players"`
+	Round   int       `json:"round,omitempty"`
+}
+
+// TestTyped tests typed reads and writes of a document
+func TestTyped(t *testing.T) {
+	doc := crdt.NewDocument(common.NewSessionID())
+	state := NewTyped[typedRaidState](NewDocumentEditor(doc))
+
+	require.NoError(t, state.Init(typedRaidState{
+		Phase:   "lobby",
+		Boss:    typedBoss{Name: "dragon", HP: 100},
+		Players: []string{"alice"},
+	}))
+
+	// 변경된 필드만 패치에 포함
+	patch, err := state.Set(func(s *typedRaidState) {
+		s.Boss.HP -= 10
+	})
+	require.NoError(t, err)
+	require.NotNil(t, patch)
+
+	var keys []string
+	for _, op := range patch.Operations() {
+		if ins, ok := op.(*crdtpatch.InsOperation); ok {
+			for key := range ins.Value.(map[string]any) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	assert.Equal(t, []string{"hp"}, keys)
+
+	_, err = state.Set(func(s *typedRaidState) {
+		s.Phase = "battle"
+		s.Players = append(s.Players, "bob")
+		s.Round = 1
+	})
+	require.NoError(t, err)
+
+	value, err := state.Get()
+	require.NoError(t, err)
+	assert.Equal(t, typedRaidState{
+		Phase:   "battle",
+		Boss:    typedBoss{Name: "dragon", HP: 90},
+		Players: []string{"alice", "bob"},
+		Round:   1,
+	}, value)
+
+	// omitempty 필드를 비우면 삭제
+	_, err = state.Set(func(s *typedRaidState) { s.Round = 0 })
+	require.NoError(t, err)
+	_, err = state.Editor().Query("round")
+	assert.Error(t, err)
+
+	// 변경이 없으면 nil
+	patch, err = state.Set(func(s *typedRaidState) {})
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+}
+
+// TestTyped_Path tests mapping Go field names to document paths
+func TestTyped_Path(t *testing.T) {
+	state := NewTyped[typedRaidState](NewDocumentEditor(crdt.NewDocument(common.NewSessionID())))
+
+	path, err := state.Path("Boss.HP")
+	require.NoError(t, err)
+	assert.Equal(t, "boss.hp", path)
+
+	_, err = state.Path("Boss.Armor")
+	assert.Error(t, err)
+	_, err = state.Path("Phase.Length")
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { NewTyped[int](nil) })
+}
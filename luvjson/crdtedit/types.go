@@ -1,5 +1,7 @@
 package crdtedit
 
+import "tictactoe/luvjson/crdtpatch"
+
 // NodeType represents the type of a CRDT node
 type NodeType string
 
@@ -51,6 +53,12 @@ type ArrayEditor interface {
 	GetLength() (int, error)
 	// GetElement returns the element at the given index
 	GetElement(index int) (any, error)
+	// Splice removes deleteCount elements starting at start and inserts items in their place
+	Splice(start, deleteCount int, items ...any) (ArrayEditor, error)
+	// Move moves the element at index from to index to without recreating it
+	Move(from, to int) (ArrayEditor, error)
+	// SortBy reorders the array using less, moving as few elements as possible
+	SortBy(less func(a, b any) bool) (ArrayEditor, error)
 	// GetPath returns the path to this array
 	GetPath() string
 }
@@ -73,6 +81,25 @@ type StringEditor interface {
 	GetPath() string
 }
 
+// TextEditor provides character-level editing of string nodes.
+// Positions are counted in characters (runes). Edits reference the IDs of the
+// surrounding characters rather than whole-string values, so concurrent edits
+// from different sessions merge instead of overwriting each other.
+type TextEditor interface {
+	// InsertAt inserts text before the character at pos and returns the applied patch
+	InsertAt(pos int, text string) (*crdtpatch.Patch, error)
+	// DeleteRange removes the characters in [start, end) and returns the applied patch
+	DeleteRange(start, end int) (*crdtpatch.Patch, error)
+	// ReplaceRange replaces the characters in [start, end) with text and returns the applied patch
+	ReplaceRange(start, end int, text string) (*crdtpatch.Patch, error)
+	// GetLength returns the number of characters
+	GetLength() (int, error)
+	// GetValue returns the entire string value
+	GetValue() (string, error)
+	// GetPath returns the path to this string
+	GetPath() string
+}
+
 // NumberEditor provides methods for manipulating number nodes
 type NumberEditor interface {
 	// Increment increases the number by the given delta
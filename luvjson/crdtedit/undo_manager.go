@@ -0,0 +1,255 @@
+package crdtedit
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+var (
+	// ErrNothingToUndo is returned when the undo stack is empty
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo is returned when the redo stack is empty
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// DefaultUndoDepth is the default maximum number of undo steps kept per session
+const DefaultUndoDepth = 100
+
+// undoMode tells the UndoManager how to record the next local change set
+type undoMode int
+
+const (
+	undoModeRecord undoMode = iota
+	undoModeUndo
+	undoModeRedo
+)
+
+// UndoManager records local changes of a session and produces compensating
+// patches for Undo and Redo.
+//
+// Only changes whose origin is the manager's session are recorded. When undoing,
+// a path is only restored if it still holds the value written by this session;
+// paths that were overwritten by a concurrent remote operation are left untouched.
+type UndoManager struct {
+	mu          sync.Mutex
+	editor      *DocumentEditor
+	sessionID   common.SessionID
+	maxDepth    int
+	undoStack   [][]ChangeEvent
+	redoStack   [][]ChangeEvent
+	mode        undoMode
+	unsubscribe func()
+}
+
+// NewUndoManager creates an UndoManager for the editor's local session.
+// maxDepth limits the number of undo steps; values <= 0 use DefaultUndoDepth.
+func NewUndoManager(editor *DocumentEditor, maxDepth int) (*UndoManager, error) {
+	if editor == nil {
+		return nil, errors.New("editor cannot be nil")
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultUndoDepth
+	}
+
+	m := &UndoManager{
+		editor:    editor,
+		sessionID: editor.GetDocument().GetSessionID(),
+		maxDepth:  maxDepth,
+	}
+
+	unsubscribe, err := editor.subscribeBatch(m.record)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to subscribe to document changes")
+	}
+	m.unsubscribe = unsubscribe
+
+	return m, nil
+}
+
+// CanUndo reports whether there is a change to undo
+func (m *UndoManager) CanUndo() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.undoStack) > 0
+}
+
+// CanRedo reports whether there is an undone change to redo
+func (m *UndoManager) CanRedo() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.redoStack) > 0
+}
+
+// Undo reverts the most recent local change set and returns the compensating patch
+func (m *UndoManager) Undo() (*crdtpatch.Patch, error) {
+	return m.revert(undoModeUndo)
+}
+
+// Redo re-applies the most recently undone change set and returns the compensating patch
+func (m *UndoManager) Redo() (*crdtpatch.Patch, error) {
+	return m.revert(undoModeRedo)
+}
+
+// Clear discards the undo and redo history
+func (m *UndoManager) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.undoStack = nil
+	m.redoStack = nil
+}
+
+// Close stops recording changes
+func (m *UndoManager) Close() {
+	if m.unsubscribe != nil {
+		m.unsubscribe()
+	}
+}
+
+// revert pops a change set from the undo or redo stack and applies its inverse
+func (m *UndoManager) revert(mode undoMode) (*crdtpatch.Patch, error) {
+	m.mu.Lock()
+	stack := &m.undoStack
+	emptyErr := ErrNothingToUndo
+	if mode == undoModeRedo {
+		stack = &m.redoStack
+		emptyErr = ErrNothingToRedo
+	}
+
+	if len(*stack) == 0 {
+		m.mu.Unlock()
+		return nil, emptyErr
+	}
+
+	changes := (*stack)[len(*stack)-1]
+	*stack = (*stack)[:len(*stack)-1]
+	m.mode = mode
+	m.mu.Unlock()
+
+	// 보상 패치 적용 중 발생하는 변경은 record에서 반대 스택으로 기록됨
+	patch, err := m.applyInverse(changes)
+
+	m.mu.Lock()
+	m.mode = undoModeRecord
+	if err != nil {
+		*stack = append(*stack, changes)
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// applyInverse applies compensating operations for the given change set in a single transaction
+func (m *UndoManager) applyInverse(changes []ChangeEvent) (*crdtpatch.Patch, error) {
+	current, err := m.editor.flattenDocument()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read document state")
+	}
+
+	return m.editor.Transaction(func(tx *Transaction) error {
+		// 나중 변경부터 역순으로 되돌림
+		for i := len(changes) - 1; i >= 0; i-- {
+			change := changes[i]
+
+			// 다른 세션이 이후에 덮어쓴 경로는 건너뜀
+			value, exists := current[change.Path]
+			if change.Kind == ChangeRemoved {
+				if exists {
+					continue
+				}
+			} else if !exists || !reflect.DeepEqual(value, change.NewValue) {
+				continue
+			}
+
+			if err := applyInverseChange(tx, change); err != nil {
+				return errors.Wrapf(err, "failed to revert change at path %s", change.Path)
+			}
+		}
+		return nil
+	})
+}
+
+// applyInverseChange records the operation that reverts a single change
+func applyInverseChange(tx *Transaction, change ChangeEvent) error {
+	parentPath, last := splitLastSegment(change.Path)
+
+	// 배열 요소 경로 (예: items[2])
+	if open := strings.Index(last, "["); open >= 0 && strings.HasSuffix(last, "]") {
+		index, err := strconv.Atoi(last[open+1 : len(last)-1])
+		if err != nil {
+			return errors.Errorf("invalid array index in path %s", change.Path)
+		}
+		arrayPath := childKeyPath(parentPath, last[:open])
+
+		switch change.Kind {
+		case ChangeAdded:
+			return tx.DeleteArrayElement(arrayPath, index)
+		case ChangeRemoved:
+			return tx.InsertArrayElement(arrayPath, index, change.OldValue)
+		default:
+			return tx.SetValue(change.Path, change.OldValue)
+		}
+	}
+
+	if change.Kind == ChangeAdded {
+		return tx.DeleteKey(parentPath, last)
+	}
+	return tx.SetKey(parentPath, last, change.OldValue)
+}
+
+// splitLastSegment splits a path into its parent path and last segment
+func splitLastSegment(path string) (string, string) {
+	idx := strings.LastIndex(path, ".")
+	if idx < 0 {
+		return "", path
+	}
+	return path[:idx], path[idx+1:]
+}
+
+// record receives published change sets and pushes local ones onto the proper stack
+func (m *UndoManager) record(events []ChangeEvent) {
+	local := make([]ChangeEvent, 0, len(events))
+	for _, event := range events {
+		if event.Origin == m.sessionID {
+			local = append(local, event)
+		}
+	}
+	if len(local) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch m.mode {
+	case undoModeUndo:
+		m.redoStack = m.push(m.redoStack, local)
+	case undoModeRedo:
+		m.undoStack = m.push(m.undoStack, local)
+	default:
+		// 새로운 변경은 redo 기록을 무효화
+		m.undoStack = m.push(m.undoStack, local)
+		m.redoStack = nil
+	}
+}
+
+// push appends a change set to a stack, dropping the oldest entries beyond maxDepth
+func (m *UndoManager) push(stack [][]ChangeEvent, changes []ChangeEvent) [][]ChangeEvent {
+	stack = append(stack, changes)
+	if len(stack) > m.maxDepth {
+		stack = stack[len(stack)-m.maxDepth:]
+	}
+	return stack
+}
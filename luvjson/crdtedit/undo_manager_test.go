@@ -0,0 +1,77 @@
+package crdtedit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// TestUndoManager tests recording and reverting local changes
+func TestUndoManager(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	undo, err := NewUndoManager(editor, 0)
+	require.NoError(t, err)
+	defer undo.Close()
+
+	_, err = undo.Undo()
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+	_, err = undo.Redo()
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+
+	root := doc.Root().(*crdt.RootNode).NodeValue.(*crdt.LWWObjectNode)
+	alice := root.Get("players").(*crdt.LWWObjectNode).Get("alice").(*crdt.LWWObjectNode)
+	setGold := func(value float64) {
+		node := crdt.NewConstantNode(doc.NextTimestamp(), value)
+		doc.AddNode(node)
+		alice.Set("gold", node.ID(), node)
+	}
+
+	// 로컬 변경은 기록됨
+	setGold(150)
+	require.NoError(t, editor.NotifyChanges())
+	assert.True(t, undo.CanUndo())
+
+	patch, err := undo.Undo()
+	require.NoError(t, err)
+	assert.NotEmpty(t, patch.Operations())
+	assert.False(t, undo.CanUndo())
+}
+
+// TestUndoManager_ConcurrentRemoteChange tests that undo does not clobber remote writes
+func TestUndoManager_ConcurrentRemoteChange(t *testing.T) {
+	doc := setupJSONPathDocument(t)
+	editor := NewDocumentEditor(doc)
+
+	undo, err := NewUndoManager(editor, 0)
+	require.NoError(t, err)
+	defer undo.Close()
+
+	root := doc.Root().(*crdt.RootNode).NodeValue.(*crdt.LWWObjectNode)
+	alice := root.Get("players").(*crdt.LWWObjectNode).Get("alice").(*crdt.LWWObjectNode)
+	setGold := func(value float64) {
+		node := crdt.NewConstantNode(doc.NextTimestamp(), value)
+		doc.AddNode(node)
+		alice.Set("gold", node.ID(), node)
+	}
+
+	setGold(150)
+	require.NoError(t, editor.NotifyChanges())
+
+	// 원격 세션이 같은 필드를 덮어씀 (기록되지 않음)
+	setGold(300)
+	require.NoError(t, editor.publishChanges(common.NewSessionID()))
+
+	patch, err := undo.Undo()
+	require.NoError(t, err)
+	assert.Empty(t, patch.Operations(), "remote write must not be reverted")
+
+	gold, err := editor.QueryJSONPath("$.players.alice.gold")
+	require.NoError(t, err)
+	assert.Equal(t, float64(300), gold[0].Value)
+}
@@ -135,6 +135,17 @@ func (ps *MockPubSub) SubscribeWithHandler(ctx context.Context, topic string, ha
 	return ps.Subscribe(ctx, topic, subscriberID, subscriberFunc)
 }
 
+// SubscribeSince subscribes to the specified topic. The mock retains no messages.
+func (ps *MockPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor crdtpubsub.Cursor, handler crdtpubsub.SubscriberFunc) error {
+	return ps.Subscribe(ctx, topic, subscriberID, handler)
+}
+
+// SubscribePattern subscribes to the topics matching the pattern. The mock only
+// delivers the messages of the topic equal to the pattern.
+func (ps *MockPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler crdtpubsub.SubscriberFunc) error {
+	return ps.Subscribe(ctx, pattern, subscriberID, handler)
+}
+
 // Unsubscribe unsubscribes from the specified topic.
 func (ps *MockPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
 	ps.mutex.Lock()
@@ -1,6 +1,10 @@
 package crdtpatch
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"tictactoe/luvjson/common"
 	"tictactoe/luvjson/crdt"
 )
@@ -19,6 +23,10 @@ type PatchBuilder struct {
 
 	// pendingOperations is the list of operations to be added to the next patch.
 	pendingOperations []Operation
+
+	// pathNodes maps the containers created by InsertValueAtPath to the
+	// containers created below them, so later paths reuse them.
+	pathNodes map[common.LogicalTimestamp]map[pathSegment]*NewOperation
 }
 
 // NewPatchBuilder creates a new PatchBuilder with the given session ID and initial counter.
@@ -98,6 +106,16 @@ func (b *PatchBuilder) NewString() *NewOperation {
 	return op
 }
 
+// NewVector creates a new LWW-Vector node operation.
+func (b *PatchBuilder) NewVector() *NewOperation {
+	op := &NewOperation{
+		ID:       b.NextTimestamp(),
+		NodeType: common.NodeTypeVec,
+	}
+	b.AddOperation(op)
+	return op
+}
+
 // InsertValue inserts a value into a LWW-Value node.
 func (b *PatchBuilder) InsertValue(targetID common.LogicalTimestamp, value interface{}) *InsOperation {
 	op := &InsOperation{
@@ -120,6 +138,126 @@ func (b *PatchBuilder) InsertObjectField(targetID common.LogicalTimestamp, key s
 	return op
 }
 
+// InsertVectorElement sets an element of a LWW-Vector node.
+func (b *PatchBuilder) InsertVectorElement(targetID common.LogicalTimestamp, index int, value interface{}) *InsOperation {
+	op := &InsOperation{
+		ID:       b.NextTimestamp(),
+		TargetID: targetID,
+		Value:    map[string]interface{}{strconv.Itoa(index): value},
+	}
+	b.AddOperation(op)
+	return op
+}
+
+// InsertValueAtPath sets the value at path below the node rootID, creating the
+// intermediate containers: an object for a key and a vector for an index. path is
+// a dot-separated list of keys, each followed by any number of [index] segments,
+// e.g. "a.b.c[2]"; rootID is an object, or a vector if path starts with an index.
+//
+// The containers created by earlier calls of the builder are reused, so that
+// "a.b" and "a.c" share the object at "a". Nodes of a document are not looked up:
+// a container already in the document is replaced by a new one.
+func (b *PatchBuilder) InsertValueAtPath(rootID common.LogicalTimestamp, path string, value interface{}) (*InsOperation, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := rootID
+	for i, segment := range segments[:len(segments)-1] {
+		nodeType := common.NodeTypeObj
+		if segments[i+1].isIndex {
+			nodeType = common.NodeTypeVec
+		}
+		if child, ok := b.pathNodes[parentID][segment]; ok && child.NodeType == nodeType {
+			parentID = child.ID
+			continue
+		}
+
+		var child *NewOperation
+		if nodeType == common.NodeTypeVec {
+			child = b.NewVector()
+		} else {
+			child = b.NewObject()
+		}
+		b.insertAt(parentID, segment, child.ID)
+		b.rememberPathNode(parentID, segment, child)
+		parentID = child.ID
+	}
+
+	last := segments[len(segments)-1]
+	// 경로의 마지막 값이 덮어쓰이므로 그 아래의 컨테이너는 재사용하지 않음
+	delete(b.pathNodes[parentID], last)
+	return b.insertAt(parentID, last, value), nil
+}
+
+// insertAt sets the field or element segment of the container parentID.
+func (b *PatchBuilder) insertAt(parentID common.LogicalTimestamp, segment pathSegment, value interface{}) *InsOperation {
+	if segment.isIndex {
+		return b.InsertVectorElement(parentID, segment.index, value)
+	}
+	return b.InsertObjectField(parentID, segment.key, value)
+}
+
+// rememberPathNode records the container child created at segment of parentID.
+func (b *PatchBuilder) rememberPathNode(parentID common.LogicalTimestamp, segment pathSegment, child *NewOperation) {
+	if b.pathNodes == nil {
+		b.pathNodes = make(map[common.LogicalTimestamp]map[pathSegment]*NewOperation)
+	}
+	children, ok := b.pathNodes[parentID]
+	if !ok {
+		children = make(map[pathSegment]*NewOperation)
+		b.pathNodes[parentID] = children
+	}
+	children[segment] = child
+}
+
+// pathSegment is an object key or a vector index of a path.
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath splits a path such as "a.b.c[2]" into its segments.
+func parsePath(path string) ([]pathSegment, error) {
+	invalid := func() error {
+		return common.ErrInvalidOperation{Message: fmt.Sprintf("invalid path %q", path)}
+	}
+	if path == "" {
+		return nil, invalid()
+	}
+
+	var segments []pathSegment
+	for i, part := range strings.Split(path, ".") {
+		key := part
+		if open := strings.IndexByte(part, '['); open >= 0 {
+			key = part[:open]
+		}
+		// 인덱스로 시작하는 경로만 첫 키를 생략할 수 있음
+		if (key == "" && (i > 0 || key == part)) || strings.Contains(key, "]") {
+			return nil, invalid()
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+
+		for rest := part[len(key):]; rest != ""; {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return nil, invalid()
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, invalid()
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+			rest = rest[end+1:]
+		}
+	}
+	return segments, nil
+}
+
 // InsertString inserts a string into a RGA-String node.
 func (b *PatchBuilder) InsertString(targetID, refID common.LogicalTimestamp, value string) *InsOperation {
 	op := &InsOperation{
@@ -601,3 +601,52 @@ func TestPatchBuilder_Collaborative(t *testing.T) {
 	_, hasContent := objView["content"]
 	assert.True(t, hasContent)
 }
+
+// TestPatchBuilder_InsertValueAtPath demonstrates how to create a nested structure in one call
+func TestPatchBuilder_InsertValueAtPath(t *testing.T) {
+	sid := common.NewSessionID()
+	builder := NewPatchBuilder(sid, 1)
+
+	// Create the root object and set the nested values by path
+	rootOp := builder.NewObject()
+	builder.InsertValue(common.RootID, rootOp.ID)
+	_, err := builder.InsertValueAtPath(rootOp.ID, "a.b.c[2]", "sword")
+	assert.NoError(t, err)
+	_, err = builder.InsertValueAtPath(rootOp.ID, "a.b.c[0]", "shield")
+	assert.NoError(t, err)
+	_, err = builder.InsertValueAtPath(rootOp.ID, "a.name", "hero")
+	assert.NoError(t, err)
+	_, err = builder.InsertValueAtPath(rootOp.ID, "grid[1][0]", 7)
+	assert.NoError(t, err)
+
+	doc := crdt.NewDocument(sid)
+	assert.NoError(t, builder.Flush().Apply(doc))
+
+	view, err := doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{
+			"b":    map[string]interface{}{"c": []interface{}{"shield", nil, "sword"}},
+			"name": "hero",
+		},
+		"grid": []interface{}{nil, []interface{}{float64(7)}},
+	}, view)
+
+	// Overwriting an intermediate value starts a new container
+	_, err = builder.InsertValueAtPath(rootOp.ID, "a", "gone")
+	assert.NoError(t, err)
+	_, err = builder.InsertValueAtPath(rootOp.ID, "a.b", true)
+	assert.NoError(t, err)
+	assert.NoError(t, builder.Flush().Apply(doc))
+
+	view, err = doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"b": true}, view.(map[string]interface{})["a"])
+
+	// Invalid paths
+	for _, path := range []string{"", "a..b", "a.[0]", "a[x]", "a[-1]", "a[0", "a]"} {
+		_, err := builder.InsertValueAtPath(rootOp.ID, path, 1)
+		assert.Error(t, err, path)
+	}
+	assert.Nil(t, builder.Flush())
+}
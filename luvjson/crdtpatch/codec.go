@@ -0,0 +1,654 @@
+package crdtpatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// MarshalCBOR returns a CBOR (RFC 8949) representation of the patch. It has the
+// structure of the verbose JSON representation, with numbers encoded as CBOR
+// integers and floats, so it is smaller on the wire.
+func (p *Patch) MarshalCBOR() ([]byte, error) {
+	value, err := p.genericValue()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := encodeCBOR(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCBOR parses a CBOR representation of the patch written by MarshalCBOR.
+func (p *Patch) UnmarshalCBOR(data []byte) error {
+	d := &codecDecoder{data: data}
+	value, err := d.cbor()
+	if err != nil {
+		return fmt.Errorf("invalid CBOR patch: %w", err)
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("invalid CBOR patch: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return p.fromGenericValue(value)
+}
+
+// MarshalMsgpack returns a MessagePack representation of the patch. It has the
+// structure of the verbose JSON representation, like MarshalCBOR.
+func (p *Patch) MarshalMsgpack() ([]byte, error) {
+	value, err := p.genericValue()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := encodeMsgpack(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMsgpack parses a MessagePack representation of the patch written by
+// MarshalMsgpack.
+func (p *Patch) UnmarshalMsgpack(data []byte) error {
+	d := &codecDecoder{data: data}
+	value, err := d.msgpack()
+	if err != nil {
+		return fmt.Errorf("invalid MessagePack patch: %w", err)
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("invalid MessagePack patch: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return p.fromGenericValue(value)
+}
+
+// genericValue returns the verbose JSON representation of the patch decoded into
+// maps, slices and scalars. Numbers are kept as json.Number.
+func (p *Patch) genericValue() (interface{}, error) {
+	data, err := p.toVerboseJSON()
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// fromGenericValue parses a patch from the value returned by genericValue.
+func (p *Patch) fromGenericValue(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return p.fromVerboseJSON(data)
+}
+
+// sortedKeys returns the keys of a map in order, so that encoding is deterministic.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// numberValue converts a JSON number to int64, uint64 or float64.
+func numberValue(n json.Number) (interface{}, error) {
+	if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u, nil
+	}
+	return strconv.ParseFloat(n.String(), 64)
+}
+
+// CBOR major types.
+const (
+	cborUint byte = iota << 5
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// cborHead writes the head of a CBOR data item.
+func cborHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		buf.WriteByte(major | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+// encodeCBOR writes a generic value as CBOR.
+func encodeCBOR(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(cborSimple | 22)
+	case bool:
+		if v {
+			buf.WriteByte(cborSimple | 21)
+		} else {
+			buf.WriteByte(cborSimple | 20)
+		}
+	case json.Number:
+		n, err := numberValue(v)
+		if err != nil {
+			return err
+		}
+		return encodeCBOR(buf, n)
+	case int64:
+		if v >= 0 {
+			cborHead(buf, cborUint, uint64(v))
+		} else {
+			cborHead(buf, cborNegInt, uint64(-(v + 1)))
+		}
+	case uint64:
+		cborHead(buf, cborUint, v)
+	case float64:
+		if f := float32(v); float64(f) == v {
+			buf.WriteByte(cborSimple | 26)
+			buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f)))
+		} else {
+			buf.WriteByte(cborSimple | 27)
+			buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+		}
+	case string:
+		cborHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		cborHead(buf, cborArray, uint64(len(v)))
+		for _, elem := range v {
+			if err := encodeCBOR(buf, elem); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		cborHead(buf, cborMap, uint64(len(v)))
+		for _, key := range sortedKeys(v) {
+			cborHead(buf, cborText, uint64(len(key)))
+			buf.WriteString(key)
+			if err := encodeCBOR(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+	return nil
+}
+
+// encodeMsgpack writes a generic value as MessagePack.
+func encodeMsgpack(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		n, err := numberValue(v)
+		if err != nil {
+			return err
+		}
+		return encodeMsgpack(buf, n)
+	case int64:
+		switch {
+		case v >= 0:
+			return encodeMsgpack(buf, uint64(v))
+		case v >= -32:
+			buf.WriteByte(byte(v))
+		case v >= math.MinInt8:
+			buf.WriteByte(0xd0)
+			buf.WriteByte(byte(v))
+		case v >= math.MinInt16:
+			buf.WriteByte(0xd1)
+			buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+		case v >= math.MinInt32:
+			buf.WriteByte(0xd2)
+			buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+		default:
+			buf.WriteByte(0xd3)
+			buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+		}
+	case uint64:
+		switch {
+		case v <= 0x7f:
+			buf.WriteByte(byte(v))
+		case v <= math.MaxUint8:
+			buf.WriteByte(0xcc)
+			buf.WriteByte(byte(v))
+		case v <= math.MaxUint16:
+			buf.WriteByte(0xcd)
+			buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+		case v <= math.MaxUint32:
+			buf.WriteByte(0xce)
+			buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+		default:
+			buf.WriteByte(0xcf)
+			buf.Write(binary.BigEndian.AppendUint64(nil, v))
+		}
+	case float64:
+		if f := float32(v); float64(f) == v {
+			buf.WriteByte(0xca)
+			buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f)))
+		} else {
+			buf.WriteByte(0xcb)
+			buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+		}
+	case string:
+		msgpackString(buf, v)
+	case []interface{}:
+		msgpackContainer(buf, len(v), 0x90, 0xdc)
+		for _, elem := range v {
+			if err := encodeMsgpack(buf, elem); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		msgpackContainer(buf, len(v), 0x80, 0xde)
+		for _, key := range sortedKeys(v) {
+			msgpackString(buf, key)
+			if err := encodeMsgpack(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+	return nil
+}
+
+// msgpackString writes a MessagePack string.
+func msgpackString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(0xdb)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+	buf.WriteString(s)
+}
+
+// msgpackContainer writes the head of a MessagePack array or map of n items.
+// fixed is the first byte of the fixed form and head16 that of the 16-bit form;
+// the 32-bit form follows it.
+func msgpackContainer(buf *bytes.Buffer, n int, fixed, head16 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fixed | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(head16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(head16 + 1)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// codecDecoder reads CBOR and MessagePack data items into generic values.
+type codecDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// maxCodecDepth limits the nesting of decoded values.
+const maxCodecDepth = 512
+
+func (d *codecDecoder) next(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("unexpected end of data at offset %d", d.pos)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *codecDecoder) uint(size int) (uint64, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// length checks that a string, array or map of n items can fit in the rest of
+// the data, where each item takes at least one byte.
+func (d *codecDecoder) length(n uint64) (int, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, fmt.Errorf("length %d exceeds data at offset %d", n, d.pos)
+	}
+	return int(n), nil
+}
+
+func (d *codecDecoder) enter() error {
+	d.depth++
+	if d.depth > maxCodecDepth {
+		return fmt.Errorf("nesting exceeds %d levels", maxCodecDepth)
+	}
+	return nil
+}
+
+// cbor reads a CBOR data item.
+func (d *codecDecoder) cbor() (interface{}, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	major, info := b[0]&0xe0, b[0]&0x1f
+
+	if major == cborSimple {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			bits, err := d.uint(2)
+			if err != nil {
+				return nil, err
+			}
+			return halfFloat(uint16(bits)), nil
+		case 26:
+			bits, err := d.uint(4)
+			if err != nil {
+				return nil, err
+			}
+			return float64(math.Float32frombits(uint32(bits))), nil
+		case 27:
+			bits, err := d.uint(8)
+			if err != nil {
+				return nil, err
+			}
+			return math.Float64frombits(bits), nil
+		default:
+			return nil, fmt.Errorf("unsupported simple value %d", info)
+		}
+	}
+
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		if n, err = d.uint(1 << (info - 24)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported additional information %d", info)
+	}
+
+	switch major {
+	case cborUint:
+		return n, nil
+	case cborNegInt:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer out of range")
+		}
+		return -int64(n) - 1, nil
+	case cborBytes, cborText:
+		size, err := d.length(n)
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.next(size)
+		if err != nil {
+			return nil, err
+		}
+		return string(s), nil
+	case cborArray:
+		size, err := d.length(n)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { d.depth-- }()
+		result := make([]interface{}, size)
+		for i := range result {
+			if result[i], err = d.cbor(); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case cborMap:
+		size, err := d.length(n)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { d.depth-- }()
+		result := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			key, err := d.cbor()
+			if err != nil {
+				return nil, err
+			}
+			s, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("map key must be a string, got %T", key)
+			}
+			if result[s], err = d.cbor(); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported major type %d", major>>5)
+	}
+}
+
+// halfFloat converts an IEEE 754 half-precision float to float64.
+func halfFloat(bits uint16) float64 {
+	exp := int(bits>>10) & 0x1f
+	mant := float64(bits & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if bits&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// msgpack reads a MessagePack data item.
+func (d *codecDecoder) msgpack() (interface{}, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return uint64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.msgpackMap(uint64(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.msgpackArray(uint64(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.msgpackString(uint64(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6, 0xd9, 0xda, 0xdb:
+		size := 1 << ((c - 0xc4) % 3)
+		if c >= 0xd9 {
+			size = 1 << (c - 0xd9)
+		}
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		return d.msgpackString(n)
+	case 0xca:
+		bits, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(bits))), nil
+	case 0xcb:
+		bits, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n, err := d.uint(1 << (c - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		switch c {
+		case 0xd0:
+			return int64(int8(n)), nil
+		case 0xd1:
+			return int64(int16(n)), nil
+		case 0xd2:
+			return int64(int32(n)), nil
+		default:
+			return int64(n), nil
+		}
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.msgpackArray(n)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.msgpackMap(n)
+	default:
+		return nil, fmt.Errorf("unsupported type byte 0x%02x", c)
+	}
+}
+
+func (d *codecDecoder) msgpackString(n uint64) (interface{}, error) {
+	size, err := d.length(n)
+	if err != nil {
+		return nil, err
+	}
+	s, err := d.next(size)
+	if err != nil {
+		return nil, err
+	}
+	return string(s), nil
+}
+
+func (d *codecDecoder) msgpackArray(n uint64) (interface{}, error) {
+	size, err := d.length(n)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	result := make([]interface{}, size)
+	for i := range result {
+		if result[i], err = d.msgpack(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (d *codecDecoder) msgpackMap(n uint64) (interface{}, error) {
+	size, err := d.length(n)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	result := make(map[string]interface{}, size)
+	for i := 0; i < size; i++ {
+		key, err := d.msgpack()
+		if err != nil {
+			return nil, err
+		}
+		s, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be a string, got %T", key)
+		}
+		if result[s], err = d.msgpack(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
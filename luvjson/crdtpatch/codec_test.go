@@ -0,0 +1,134 @@
+package crdtpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tictactoe/luvjson/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// codecTestPatch returns a patch with the kinds of values patches carry.
+func codecTestPatch() *Patch {
+	sid := common.NewSessionID()
+	ts := func(counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	p := NewPatch(ts(1))
+	p.SetMetadata(map[string]interface{}{"author": "alice", "retry": float64(2)})
+	p.AddOperation(&NewOperation{ID: ts(1), NodeType: common.NodeTypeObj})
+	p.AddOperation(&InsOperation{ID: ts(2), TargetID: common.RootID, Value: ts(1)})
+	p.AddOperation(&NewOperation{ID: ts(3), NodeType: common.NodeTypeStr})
+	p.AddOperation(&InsOperation{ID: ts(4), TargetID: ts(3), Value: strings.Repeat("dragon ", 40)})
+	p.AddOperation(&InsOperation{ID: ts(284), TargetID: ts(1), Value: map[string]interface{}{
+		"name":  ts(3),
+		"hp":    float64(1 << 40),
+		"armor": float64(-300),
+		"speed": 1.25,
+		"ratio": 0.1,
+		"boss":  true,
+		"loot":  nil,
+		"tags":  []interface{}{"fire", float64(-1), map[string]interface{}{"rare": false}},
+	}})
+	p.AddOperation(&DelOperation{ID: ts(285), TargetID: ts(1), Key: "old"})
+	p.AddOperation(&NopOperation{ID: ts(286), SpanValue: 70000})
+	return p
+}
+
+func TestCBORAndMsgpackCodecs(t *testing.T) {
+	p := codecTestPatch()
+	jsonData, err := p.MarshalJSON()
+	assert.NoError(t, err)
+
+	cborData, err := p.MarshalCBOR()
+	assert.NoError(t, err)
+	decoded := &Patch{}
+	assert.NoError(t, decoded.UnmarshalCBOR(cborData))
+	decodedJSON, err := decoded.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(jsonData), string(decodedJSON))
+	assert.Less(t, len(cborData), len(jsonData))
+
+	msgpackData, err := p.MarshalMsgpack()
+	assert.NoError(t, err)
+	decoded = &Patch{}
+	assert.NoError(t, decoded.UnmarshalMsgpack(msgpackData))
+	decodedJSON, err = decoded.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(jsonData), string(decodedJSON))
+	assert.Less(t, len(msgpackData), len(jsonData))
+
+	// 인코딩은 결정적
+	again, err := p.MarshalCBOR()
+	assert.NoError(t, err)
+	assert.Equal(t, cborData, again)
+
+	// 잘린 데이터와 남는 데이터는 오류
+	assert.Error(t, (&Patch{}).UnmarshalCBOR(cborData[:len(cborData)-1]))
+	assert.Error(t, (&Patch{}).UnmarshalCBOR(append(cborData, 0)))
+	assert.Error(t, (&Patch{}).UnmarshalMsgpack(msgpackData[:len(msgpackData)-1]))
+	assert.Error(t, (&Patch{}).UnmarshalMsgpack([]byte{0xdf, 0xff, 0xff, 0xff, 0xff}))
+}
+
+func TestCodecValueEncoding(t *testing.T) {
+	value := map[string]interface{}{"a": []interface{}{json.Number("1"), json.Number("-2"), json.Number("1.5"), "x", true, nil}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, encodeCBOR(&buf, value))
+	assert.Equal(t, []byte{0xa1, 0x61, 'a', 0x86, 0x01, 0x21, 0xfa, 0x3f, 0xc0, 0x00, 0x00, 0x61, 'x', 0xf5, 0xf6}, buf.Bytes())
+	decoded, err := (&codecDecoder{data: buf.Bytes()}).cbor()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": []interface{}{uint64(1), int64(-2), 1.5, "x", true, nil}}, decoded)
+
+	buf.Reset()
+	assert.NoError(t, encodeMsgpack(&buf, value))
+	assert.Equal(t, []byte{0x81, 0xa1, 'a', 0x96, 0x01, 0xfe, 0xca, 0x3f, 0xc0, 0x00, 0x00, 0xa1, 'x', 0xc3, 0xc0}, buf.Bytes())
+	decoded, err = (&codecDecoder{data: buf.Bytes()}).msgpack()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": []interface{}{uint64(1), int64(-2), 1.5, "x", true, nil}}, decoded)
+
+	// 반정밀도 부동소수점
+	decoded, err = (&codecDecoder{data: []byte{0xf9, 0x3e, 0x00}}).cbor()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, decoded)
+}
+
+func BenchmarkPatchCodecs(b *testing.B) {
+	p := codecTestPatch()
+	codecs := []struct {
+		name      string
+		marshal   func() ([]byte, error)
+		unmarshal func(data []byte) error
+	}{
+		{"JSON", p.MarshalJSON, func(data []byte) error { return (&Patch{}).UnmarshalJSON(data) }},
+		{"CBOR", p.MarshalCBOR, func(data []byte) error { return (&Patch{}).UnmarshalCBOR(data) }},
+		{"MessagePack", p.MarshalMsgpack, func(data []byte) error { return (&Patch{}).UnmarshalMsgpack(data) }},
+	}
+
+	for _, codec := range codecs {
+		data, err := codec.marshal()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(codec.name+"/Encode", func(b *testing.B) {
+			b.ReportMetric(float64(len(data)), "bytes/patch")
+			for i := 0; i < b.N; i++ {
+				if _, err := codec.marshal(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(codec.name+"/Decode", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := codec.unmarshal(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package crdtpatch
+
+import (
+	"fmt"
+	"strconv"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// Compact merges a sequence of patches of one session into a single patch with
+// the same effect, e.g. to replace the small patches buffered by a server.
+//
+// Writes that are superseded by a later write of the sequence are dropped: object
+// field writes and deletions, counter totals, and writes of value registers
+// created by the patches, since other register writes cannot be told apart from
+// list insertions. Text typed character by character is merged into one
+// insertion. Operations whose effect depends on the state they were created
+// against, such as array insertions and multi-value register writes, are kept.
+//
+// The patch has the ID of the first patch and the metadata of all patches, later
+// patches taking precedence.
+func Compact(patches []*Patch) (*Patch, error) {
+	if len(patches) == 0 {
+		return nil, common.ErrInvalidOperation{Message: "no patches to compact"}
+	}
+
+	var ops []Operation
+	for _, p := range patches {
+		if p.ID().SID != patches[0].ID().SID {
+			return nil, common.ErrInvalidOperation{Message: fmt.Sprintf("cannot compact patches of sessions %s and %s", patches[0].ID().SID, p.ID().SID)}
+		}
+		ops = append(ops, p.Operations()...)
+	}
+
+	c := &compaction{
+		types:  map[common.LogicalTimestamp]common.NodeType{common.RootID: common.NodeTypeRoot},
+		whole:  make(map[common.LogicalTimestamp]bool),
+		fields: make(map[common.LogicalTimestamp]map[string]bool),
+	}
+	for _, op := range ops {
+		if newOp, ok := op.(*NewOperation); ok {
+			c.types[newOp.ID] = newOp.NodeType
+		}
+	}
+
+	// 뒤에서부터 보면서 이후 쓰기에 덮어쓰이는 쓰기를 제거
+	kept := make([]Operation, 0, len(ops))
+	var end common.LogicalTimestamp
+	for i := len(ops) - 1; i >= 0; i-- {
+		if last := lastID(ops[i]); end.Compare(last) < 0 {
+			end = last
+		}
+		if op := c.reduce(ops[i]); op != nil {
+			kept = append(kept, op)
+		}
+	}
+
+	result := NewPatch(patches[0].ID())
+	for _, p := range patches {
+		for key, value := range p.Metadata() {
+			result.metadata[key] = value
+		}
+	}
+
+	var prev *InsOperation
+	for i := len(kept) - 1; i >= 0; i-- {
+		op := kept[i]
+		if ins, ok := op.(*InsOperation); ok && prev != nil && c.appendsText(prev, ins) {
+			prev.Value = prev.Value.(string) + ins.Value.(string)
+			continue
+		}
+
+		prev = nil
+		if ins, ok := op.(*InsOperation); ok && c.isText(ins) {
+			// 병합 시 원본 패치가 바뀌지 않도록 복사
+			prev = &InsOperation{ID: ins.ID, TargetID: ins.TargetID, RefID: ins.RefID, Value: ins.Value}
+			op = prev
+		}
+		result.AddOperation(op)
+	}
+
+	// 제거된 연산이 사용하던 시계를 수신 측에서도 진행시킴
+	if covered := coveredEnd(result.Operations()); covered.Compare(end) < 0 {
+		start := covered.Next()
+		if len(result.Operations()) == 0 {
+			start = ops[0].GetID()
+		}
+		result.AddOperation(&NopOperation{ID: start, SpanValue: end.Counter - start.Counter + 1})
+	}
+	return result, nil
+}
+
+// Rebase returns the operations of patch that are not included in a state with
+// the frontier onto, e.g. the patches to replay after restoring a snapshot whose
+// clock is onto. An operation is included if its last ID is covered by onto; an
+// operation that is only partly covered cannot come from the same history and is
+// an error. The patch keeps its ID and metadata even if no operation is left.
+func Rebase(patch *Patch, onto crdt.StabilityFrontier) (*Patch, error) {
+	result := NewPatch(patch.ID())
+	for key, value := range patch.Metadata() {
+		result.metadata[key] = value
+	}
+
+	for _, op := range patch.Operations() {
+		if op.Span() == 0 {
+			continue
+		}
+		covered := onto.Covers(op.GetID())
+		if covered != onto.Covers(lastID(op)) {
+			return nil, common.ErrInvalidOperation{Message: fmt.Sprintf("operation %s is partly covered by the frontier", op.GetID())}
+		}
+		if !covered {
+			result.AddOperation(op)
+		}
+	}
+	return result, nil
+}
+
+// compaction tracks the writes seen while scanning operations from the last one.
+type compaction struct {
+	// types holds the types of the nodes created by the operations
+	types map[common.LogicalTimestamp]common.NodeType
+	// whole holds the value registers written by a later operation
+	whole map[common.LogicalTimestamp]bool
+	// fields holds the object fields written or deleted by a later operation
+	fields map[common.LogicalTimestamp]map[string]bool
+}
+
+// reduce returns op without the writes superseded by the operations after it,
+// or nil if nothing is left. Nops are dropped; Compact covers their IDs.
+func (c *compaction) reduce(op Operation) Operation {
+	switch o := op.(type) {
+	case *NopOperation:
+		return nil
+	case *InsOperation:
+		if c.isRegisterWrite(o) {
+			if c.whole[o.TargetID] {
+				return nil
+			}
+			c.whole[o.TargetID] = true
+			return o
+		}
+
+		obj, ok := o.Value.(map[string]interface{})
+		if !ok || !c.isFieldWrite(o.TargetID, obj) {
+			return o
+		}
+		value := make(map[string]interface{}, len(obj))
+		for key, val := range obj {
+			if !c.markField(o.TargetID, key) {
+				value[key] = val
+			}
+		}
+		switch len(value) {
+		case 0:
+			return nil
+		case len(obj):
+			return o
+		default:
+			return &InsOperation{ID: o.ID, TargetID: o.TargetID, RefID: o.RefID, Value: value}
+		}
+	case *DelOperation:
+		if o.Key == "" || !c.isFieldWrite(o.TargetID, map[string]interface{}{o.Key: nil}) {
+			return o
+		}
+		if c.markField(o.TargetID, o.Key) {
+			return nil
+		}
+		return o
+	default:
+		return op
+	}
+}
+
+// markField records a write of an object field and reports whether a later
+// operation already wrote it.
+func (c *compaction) markField(target common.LogicalTimestamp, key string) bool {
+	fields, ok := c.fields[target]
+	if !ok {
+		fields = make(map[string]bool)
+		c.fields[target] = fields
+	}
+	if fields[key] {
+		return true
+	}
+	fields[key] = true
+	return false
+}
+
+// isRegisterWrite reports whether op replaces the whole value of its target.
+func (c *compaction) isRegisterWrite(op *InsOperation) bool {
+	switch c.types[op.TargetID] {
+	case common.NodeTypeRoot, common.NodeTypeVal, common.NodeTypeCon:
+		return true
+	default:
+		return false
+	}
+}
+
+// isFieldWrite reports whether a write of the keys of value to target is a
+// last-writer-wins write of each key: an object or vector write, or a counter
+// write, which carries the totals of the session.
+func (c *compaction) isFieldWrite(target common.LogicalTimestamp, value map[string]interface{}) bool {
+	switch c.types[target] {
+	case common.NodeTypeObj, common.NodeTypeVec, common.NodeTypeGCounter, common.NodeTypePNCounter:
+		return true
+	case "":
+		// 타입을 모르면 배열 삽입(숫자 키), 레지스터 쓰기와 리스트 이동을 제외
+		if _, ok := value[RegisterObservedKey]; ok {
+			return false
+		}
+		if _, ok := value[ListMoveKey]; ok {
+			return false
+		}
+		for key := range value {
+			if _, err := strconv.Atoi(key); err == nil {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// isText reports whether op may insert text into a string.
+func (c *compaction) isText(op *InsOperation) bool {
+	str, ok := op.Value.(string)
+	if !ok || str == "" {
+		return false
+	}
+	nodeType := c.types[op.TargetID]
+	return nodeType == common.NodeTypeStr || nodeType == ""
+}
+
+// appendsText reports whether next inserts text right after the text inserted by
+// prev, with the IDs following those of prev, so that both can be one insertion.
+// Only string and list insertions reference an element, and list insertions made
+// with NewListInsertOperation hold node IDs, so both insert text.
+func (c *compaction) appendsText(prev, next *InsOperation) bool {
+	if !c.isText(next) || next.TargetID != prev.TargetID {
+		return false
+	}
+	last := lastID(prev)
+	return next.RefID == last && next.ID == last.Next()
+}
+
+// lastID returns the last ID used by op.
+func lastID(op Operation) common.LogicalTimestamp {
+	if span := op.Span(); span > 1 {
+		return op.GetID().Increment(span - 1)
+	}
+	return op.GetID()
+}
+
+// coveredEnd returns the greatest ID used by ops.
+func coveredEnd(ops []Operation) common.LogicalTimestamp {
+	var end common.LogicalTimestamp
+	for _, op := range ops {
+		if last := lastID(op); end.Compare(last) < 0 {
+			end = last
+		}
+	}
+	return end
+}
@@ -0,0 +1,219 @@
+package crdtpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+
+	"github.com/pkg/errors"
+)
+
+// EnvelopeKey is the metadata key holding the envelope of a patch.
+const EnvelopeKey = "envelope"
+
+// Envelope describes where a patch comes from and what it depends on. It is
+// carried in the metadata of the patch, so every patch encoding keeps it.
+type Envelope struct {
+	// Origin is the ID of the client that created the patch
+	Origin string `json:"origin,omitempty"`
+	// Deps holds the greatest ID of each session seen by the origin when it
+	// created the patch. The patch is applied only after them.
+	Deps []common.LogicalTimestamp `json:"deps,omitempty"`
+	// SchemaVersion is the version of the document schema the patch was made for
+	SchemaVersion int `json:"schema,omitempty"`
+	// Tags holds user-defined values
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// NewEnvelope creates an envelope for a patch created by origin against doc. It
+// must be created before the patch is applied to doc.
+func NewEnvelope(origin string, doc *crdt.Document) *Envelope {
+	return &Envelope{Origin: origin, Deps: doc.Clock()}
+}
+
+// SetEnvelope stores the envelope in the metadata of the patch.
+func (p *Patch) SetEnvelope(envelope *Envelope) {
+	if p.metadata == nil {
+		p.metadata = make(map[string]interface{})
+	}
+	p.metadata[EnvelopeKey] = envelope
+}
+
+// Envelope returns the envelope of the patch, or nil if it has none.
+func (p *Patch) Envelope() (*Envelope, error) {
+	switch v := p.metadata[EnvelopeKey].(type) {
+	case nil:
+		return nil, nil
+	case *Envelope:
+		return v, nil
+	case Envelope:
+		return &v, nil
+	default:
+		// 디코딩된 패치의 메타데이터는 일반 값으로 들어옴
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode patch envelope")
+		}
+		var envelope Envelope
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return nil, errors.Wrap(err, "failed to decode patch envelope")
+		}
+		return &envelope, nil
+	}
+}
+
+// CausalityError is returned when a patch is applied before its dependencies.
+type CausalityError struct {
+	// Missing holds the dependencies the document has not seen
+	Missing []common.LogicalTimestamp
+}
+
+func (e CausalityError) Error() string {
+	ids := make([]string, len(e.Missing))
+	for i, id := range e.Missing {
+		ids[i] = id.String()
+	}
+	return fmt.Sprintf("patch depends on operations not applied yet: %s", strings.Join(ids, ", "))
+}
+
+// MissingDependencies returns the dependencies of the patch that doc has not
+// applied. A patch without envelope has no dependencies.
+func (p *Patch) MissingDependencies(doc *crdt.Document) ([]common.LogicalTimestamp, error) {
+	envelope, err := p.Envelope()
+	if err != nil || envelope == nil {
+		return nil, err
+	}
+
+	var missing []common.LogicalTimestamp
+	for _, dep := range envelope.Deps {
+		// 패치 자신의 연산은 의존성이 아님
+		if dep.SID == p.id.SID && dep.Counter >= p.id.Counter {
+			continue
+		}
+		// 세션의 가장 큰 카운터보다 작아도 건너뛴 연산일 수 있으므로 정확히 확인
+		if !doc.HasApplied(dep) {
+			missing = append(missing, dep)
+		}
+	}
+	return missing, nil
+}
+
+// checkCausality returns a CausalityError if doc has not seen the dependencies
+// of the patch.
+func (p *Patch) checkCausality(doc *crdt.Document) error {
+	missing, err := p.MissingDependencies(doc)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return CausalityError{Missing: missing}
+	}
+	return nil
+}
+
+// PatchBuffer holds the patches received before their dependencies.
+type PatchBuffer interface {
+	// Add buffers a patch. It returns an error if the buffer cannot take it.
+	Add(patch *Patch) error
+	// Patches returns the buffered patches in the order they were added.
+	Patches() []*Patch
+	// Remove removes a buffered patch.
+	Remove(patch *Patch)
+}
+
+// MemoryPatchBuffer is an in-memory PatchBuffer.
+type MemoryPatchBuffer struct {
+	limit   int
+	patches []*Patch
+}
+
+// NewMemoryPatchBuffer creates a buffer holding up to limit patches, or any
+// number of patches if limit is 0.
+func NewMemoryPatchBuffer(limit int) *MemoryPatchBuffer {
+	return &MemoryPatchBuffer{limit: limit}
+}
+
+// Add buffers a patch. It returns an error if the buffer is full.
+func (b *MemoryPatchBuffer) Add(patch *Patch) error {
+	if b.limit > 0 && len(b.patches) >= b.limit {
+		return common.ErrInvalidOperation{Message: fmt.Sprintf("patch buffer is full (%d patches)", b.limit)}
+	}
+	b.patches = append(b.patches, patch)
+	return nil
+}
+
+// Patches returns the buffered patches in the order they were added.
+func (b *MemoryPatchBuffer) Patches() []*Patch {
+	return append([]*Patch(nil), b.patches...)
+}
+
+// Remove removes a buffered patch.
+func (b *MemoryPatchBuffer) Remove(patch *Patch) {
+	for i, p := range b.patches {
+		if p == patch {
+			b.patches = append(b.patches[:i], b.patches[i+1:]...)
+			return
+		}
+	}
+}
+
+// CausalApplier applies patches to a document in causal order: a patch received
+// before its dependencies is buffered until they are applied.
+type CausalApplier struct {
+	doc    *crdt.Document
+	buffer PatchBuffer
+}
+
+// NewCausalApplier creates an applier for doc. If buffer is nil, an unlimited
+// MemoryPatchBuffer is used.
+func NewCausalApplier(doc *crdt.Document, buffer PatchBuffer) *CausalApplier {
+	if buffer == nil {
+		buffer = NewMemoryPatchBuffer(0)
+	}
+	return &CausalApplier{doc: doc, buffer: buffer}
+}
+
+// Apply applies the patch, or buffers it if its dependencies are missing, and
+// then applies the buffered patches that became ready. It returns the applied
+// patches in the order they were applied.
+func (a *CausalApplier) Apply(patch *Patch) ([]*Patch, error) {
+	err := patch.Apply(a.doc)
+	if errors.As(err, &CausalityError{}) {
+		return nil, a.buffer.Add(patch)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []*Patch{patch}
+	for progress := true; progress; {
+		progress = false
+		for _, p := range a.buffer.Patches() {
+			missing, err := p.MissingDependencies(a.doc)
+			if err != nil {
+				return applied, err
+			}
+			if len(missing) > 0 {
+				continue
+			}
+			a.buffer.Remove(p)
+			if err := p.Apply(a.doc); err != nil {
+				return applied, err
+			}
+			applied = append(applied, p)
+			progress = true
+		}
+	}
+	return applied, nil
+}
+
+// Pending returns the buffered patches, ordered by ID.
+func (a *CausalApplier) Pending() []*Patch {
+	patches := a.buffer.Patches()
+	sort.SliceStable(patches, func(i, j int) bool { return patches[i].ID().Compare(patches[j].ID()) < 0 })
+	return patches
+}
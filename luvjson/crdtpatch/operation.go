@@ -3,7 +3,9 @@ package crdtpatch
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"tictactoe/luvjson/common"
 	"tictactoe/luvjson/crdt"
@@ -80,6 +82,14 @@ func (o *NewOperation) Apply(doc *crdt.Document) error {
 		node = crdt.NewLWWObjectNode(o.ID)
 	case common.NodeTypeArr:
 		node = crdt.NewRGAArrayNode(o.ID)
+	case common.NodeTypeList:
+		node = crdt.NewMovableListNode(o.ID)
+	case common.NodeTypeGCounter:
+		node = crdt.NewGCounterNode(o.ID)
+	case common.NodeTypePNCounter:
+		node = crdt.NewPNCounterNode(o.ID)
+	case common.NodeTypeMVReg:
+		node = crdt.NewMVRegisterNode(o.ID)
 	case common.NodeTypeStr:
 		node = crdt.NewRGAStringNode(o.ID)
 	case common.NodeTypeRoot:
@@ -207,7 +217,10 @@ func (o *NewOperation) UnmarshalJSON(data []byte) error {
 type InsOperation struct {
 	ID       common.LogicalTimestamp
 	TargetID common.LogicalTimestamp
-	Value    interface{}
+	// RefID is the element after which text is inserted into an RGA string.
+	// The zero value (RootID) inserts at the beginning of the string.
+	RefID common.LogicalTimestamp
+	Value interface{}
 }
 
 // Type returns the type of the operation.
@@ -261,15 +274,23 @@ func (o *InsOperation) Apply(doc *crdt.Document) error {
 		// Update a field
 		if obj, ok := o.Value.(map[string]interface{}); ok {
 			for key, val := range obj {
+				// 기존 노드를 가리키는 ID이면 해당 노드를 필드에 연결
+				if refNode, ok := referencedNode(doc, val); ok {
+					node.Set(key, o.ID, refNode)
+					continue
+				}
+
 				valueNode := crdt.NewConstantNode(o.ID, val)
 				node.Set(key, o.ID, valueNode)
 				doc.AddNode(valueNode)
 			}
 		}
 	case *crdt.RGAStringNode:
-		// Insert a string
+		// Insert a string after the reference character
 		if str, ok := o.Value.(string); ok {
-			node.Insert(o.TargetID, o.ID, str)
+			if !node.Insert(o.RefID, o.ID, str) {
+				return common.ErrInvalidOperation{Message: fmt.Sprintf("reference element %s not found in string", o.RefID)}
+			}
 		}
 	case *crdt.RGAArrayNode:
 		// Insert an element
@@ -279,15 +300,58 @@ func (o *InsOperation) Apply(doc *crdt.Document) error {
 				var index int
 				fmt.Sscanf(indexStr, "%d", &index)
 
-				// Create a new node for the value
-				valueNode := crdt.NewConstantNode(o.ID, val)
-				doc.AddNode(valueNode)
+				// Link an existing node or create a new node for the value
+				valueNode, ok := referencedNode(doc, val)
+				if !ok {
+					valueNode = crdt.NewConstantNode(o.ID, val)
+					doc.AddNode(valueNode)
+				}
 
-				// Insert the node at the specified index
-				// Use the existing Insert method with afterID = RootID
-				node.Insert(common.RootID, o.ID, valueNode.ID())
+				// Insert the node after the element currently at index-1
+				afterID, err := visibleElementID(node, index-1)
+				if err != nil {
+					return err
+				}
+				node.Insert(afterID, o.ID, valueNode.ID())
+			}
+		}
+	case *crdt.MovableListNode:
+		// Move an element or insert a new one after the reference slot
+		if obj, ok := o.Value.(map[string]interface{}); ok && len(obj) == 1 {
+			if elemID, ok := timestampValue(obj[ListMoveKey]); ok {
+				if !node.Move(elemID, o.RefID, o.ID) {
+					return common.ErrInvalidOperation{Message: fmt.Sprintf("cannot move list element %s after slot %s", elemID, o.RefID)}
+				}
+				break
 			}
 		}
+
+		valueNode, ok := referencedNode(doc, o.Value)
+		if !ok {
+			valueNode = crdt.NewConstantNode(o.ID, o.Value)
+			doc.AddNode(valueNode)
+		}
+		if !node.Insert(o.RefID, o.ID, valueNode.ID()) {
+			return common.ErrInvalidOperation{Message: fmt.Sprintf("reference slot %s not found in list", o.RefID)}
+		}
+	case *crdt.MVRegisterNode:
+		// Write a value replacing the observed values
+		obj, ok := o.Value.(map[string]interface{})
+		if !ok {
+			return common.ErrInvalidOperation{Message: "register 'ins' operation requires a value object"}
+		}
+		observed, ok := timestampList(obj[RegisterObservedKey])
+		if !ok {
+			return common.ErrInvalidOperation{Message: "invalid observed timestamps in register 'ins' operation"}
+		}
+		node.Set(o.ID, obj[RegisterValueKey], observed)
+	case crdt.CounterNode:
+		// Raise the totals of the operation's session
+		increments, decrements, ok := counterTotals(o.Value)
+		if !ok {
+			return common.ErrInvalidOperation{Message: "counter 'ins' operation requires a value with p and n totals"}
+		}
+		node.Merge(o.ID.SID, increments, decrements)
 	// Add other node types as needed
 	default:
 		return common.ErrInvalidOperation{Message: fmt.Sprintf("unsupported node type %s for 'ins' operation", node.Type())}
@@ -296,18 +360,127 @@ func (o *InsOperation) Apply(doc *crdt.Document) error {
 	return nil
 }
 
+// referencedNode returns the existing node a field value refers to.
+// The value may be a LogicalTimestamp or its JSON form ({"sid": ..., "cnt": ...}).
+func referencedNode(doc *crdt.Document, val interface{}) (crdt.Node, bool) {
+	id, ok := timestampValue(val)
+	if !ok || id.Compare(common.RootID) == 0 {
+		return nil, false
+	}
+
+	node, err := doc.GetNode(id)
+	if err != nil {
+		return nil, false
+	}
+	return node, true
+}
+
+// timestampValue decodes a LogicalTimestamp or its JSON form ({"sid": ..., "cnt": ...}).
+func timestampValue(val interface{}) (common.LogicalTimestamp, bool) {
+	var id common.LogicalTimestamp
+	switch v := val.(type) {
+	case common.LogicalTimestamp:
+		return v, true
+	case map[string]interface{}:
+		if len(v) != 2 {
+			return id, false
+		}
+		if _, ok := v["sid"]; !ok {
+			return id, false
+		}
+		if _, ok := v["cnt"]; !ok {
+			return id, false
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return id, false
+		}
+		if err := id.UnmarshalJSON(data); err != nil {
+			return id, false
+		}
+		return id, true
+	default:
+		return id, false
+	}
+}
+
+// timestampList decodes a list of timestamps; nil is an empty list.
+func timestampList(val interface{}) ([]common.LogicalTimestamp, bool) {
+	switch v := val.(type) {
+	case nil:
+		return nil, true
+	case []common.LogicalTimestamp:
+		return v, true
+	case []interface{}:
+		result := make([]common.LogicalTimestamp, len(v))
+		for i, item := range v {
+			id, ok := timestampValue(item)
+			if !ok {
+				return nil, false
+			}
+			result[i] = id
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// counterTotals decodes the {"p": increments, "n": decrements} value of a counter operation.
+func counterTotals(val interface{}) (uint64, uint64, bool) {
+	obj, ok := val.(map[string]interface{})
+	if !ok {
+		return 0, 0, false
+	}
+	increments, ok := uint64Value(obj[CounterIncrementsKey])
+	if !ok {
+		return 0, 0, false
+	}
+	decrements, ok := uint64Value(obj[CounterDecrementsKey])
+	if !ok {
+		return 0, 0, false
+	}
+	return increments, decrements, true
+}
+
+// uint64Value converts a decoded JSON number to uint64; nil is treated as 0.
+func uint64Value(val interface{}) (uint64, bool) {
+	switch v := val.(type) {
+	case nil:
+		return 0, true
+	case uint64:
+		return v, true
+	case int:
+		return uint64(v), v >= 0
+	case int64:
+		return uint64(v), v >= 0
+	case float64:
+		return uint64(v), v >= 0
+	case json.Number:
+		n, err := strconv.ParseUint(v.String(), 10, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
 // Span returns the number of logical clock cycles the operation takes.
 func (o *InsOperation) Span() uint64 {
+	// 문자열 삽입은 문자마다 ID를 하나씩 사용
+	if str, ok := o.Value.(string); ok && str != "" {
+		return uint64(utf8.RuneCountInString(str))
+	}
 	return 1
 }
 
 // MarshalJSON returns a JSON representation of the operation.
 func (o *InsOperation) MarshalJSON() ([]byte, error) {
 	type jsonOp struct {
-		Op    string                  `json:"op"`
-		ID    common.LogicalTimestamp `json:"id"`
-		Obj   common.LogicalTimestamp `json:"obj"`
-		Value interface{}             `json:"value,omitempty"`
+		Op    string                   `json:"op"`
+		ID    common.LogicalTimestamp  `json:"id"`
+		Obj   common.LogicalTimestamp  `json:"obj"`
+		Ref   *common.LogicalTimestamp `json:"ref,omitempty"`
+		Value interface{}              `json:"value,omitempty"`
 	}
 
 	op := jsonOp{
@@ -316,6 +489,11 @@ func (o *InsOperation) MarshalJSON() ([]byte, error) {
 		Obj: o.TargetID,
 	}
 
+	if o.RefID != common.RootID {
+		ref := o.RefID
+		op.Ref = &ref
+	}
+
 	if mapVal, ok := o.Value.(map[string]interface{}); ok {
 		// 맵 내부의 time.Time 값을 처리
 		processedMap := make(map[string]interface{})
@@ -449,8 +627,33 @@ func (o *InsOperation) UnmarshalJSON(data []byte) error {
 	// Set the target ID
 	o.TargetID = common.LogicalTimestamp{SID: objSid, Counter: objCounter}
 
+	// Parse the optional ref field (string insert position)
+	if refJSON, ok := op["ref"]; ok {
+		refData, err := json.Marshal(refJSON)
+		if err != nil {
+			return common.ErrInvalidOperation{Message: "invalid 'ref' field"}
+		}
+		if err := o.RefID.UnmarshalJSON(refData); err != nil {
+			return common.ErrInvalidOperation{Message: "ref must be an object with sid and cnt fields"}
+		}
+	}
+
 	o.Value = op["value"]
 
+	// A root insert points the root at an existing node, so its value is a timestamp
+	if o.TargetID == common.RootID {
+		if valueMap, ok := o.Value.(map[string]interface{}); ok {
+			valueJSON, err := json.Marshal(valueMap)
+			if err != nil {
+				return common.ErrInvalidOperation{Message: "invalid 'value' field"}
+			}
+			var valueID common.LogicalTimestamp
+			if err := valueID.UnmarshalJSON(valueJSON); err == nil {
+				o.Value = valueID
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -487,6 +690,25 @@ func (o *DelOperation) Apply(doc *crdt.Document) error {
 	case *crdt.RGAStringNode:
 		// Delete a range of characters
 		node.Delete(o.StartID, o.EndID)
+	case *crdt.RGAArrayNode:
+		// Delete the element at an index or a range of elements
+		if o.Key == "" {
+			node.DeleteRange(o.StartID, o.EndID)
+			break
+		}
+
+		var index int
+		if _, err := fmt.Sscanf(o.Key, "%d", &index); err != nil {
+			return common.ErrInvalidOperation{Message: fmt.Sprintf("invalid array index %q for 'del' operation", o.Key)}
+		}
+		elemID, err := visibleElementID(node, index)
+		if err != nil {
+			return err
+		}
+		node.Delete(elemID)
+	case *crdt.MovableListNode:
+		// Delete the element wherever it is
+		node.Delete(o.StartID)
 	// Add other node types as needed
 	default:
 		return common.ErrInvalidOperation{Message: "unsupported node type for 'del' operation"}
@@ -495,6 +717,26 @@ func (o *DelOperation) Apply(doc *crdt.Document) error {
 	return nil
 }
 
+// visibleElementID returns the ID of the non-deleted array element at index.
+// Index -1 returns common.RootID, the position before the first element.
+func visibleElementID(node *crdt.RGAArrayNode, index int) (common.LogicalTimestamp, error) {
+	if index == -1 {
+		return common.RootID, nil
+	}
+
+	visible := 0
+	for _, elem := range node.NodeElements {
+		if elem.NodeDeleted {
+			continue
+		}
+		if visible == index {
+			return elem.NodeId, nil
+		}
+		visible++
+	}
+	return common.LogicalTimestamp{}, common.ErrInvalidOperation{Message: fmt.Sprintf("array index %d out of bounds", index)}
+}
+
 // Span returns the number of logical clock cycles the operation takes.
 func (o *DelOperation) Span() uint64 {
 	return 1
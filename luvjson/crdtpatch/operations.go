@@ -3,6 +3,7 @@ package crdtpatch
 import (
 	"fmt"
 	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
 )
 
 // NewSetOperation creates a new operation that sets a value.
@@ -70,6 +71,96 @@ func NewArrayDeleteOperation(targetID common.LogicalTimestamp, index int) (Opera
 	}, nil
 }
 
+// ListMoveKey is the value key of an 'ins' operation that moves a movable list element.
+const ListMoveKey = "move"
+
+// NewListInsertOperation creates an operation that inserts the node valueID into a
+// movable list after the slot afterID (common.RootID inserts at the beginning).
+func NewListInsertOperation(id, targetID, afterID, valueID common.LogicalTimestamp) Operation {
+	return &InsOperation{
+		ID:       id,
+		TargetID: targetID,
+		RefID:    afterID,
+		Value:    valueID,
+	}
+}
+
+// NewListMoveOperation creates an operation that moves the movable list element
+// elemID after the slot afterID (common.RootID moves it to the beginning).
+func NewListMoveOperation(id, targetID, elemID, afterID common.LogicalTimestamp) Operation {
+	return &InsOperation{
+		ID:       id,
+		TargetID: targetID,
+		RefID:    afterID,
+		Value:    map[string]interface{}{ListMoveKey: elemID},
+	}
+}
+
+// NewListDeleteOperation creates an operation that deletes the movable list element elemID.
+func NewListDeleteOperation(id, targetID, elemID common.LogicalTimestamp) Operation {
+	return &DelOperation{
+		ID:       id,
+		TargetID: targetID,
+		StartID:  elemID,
+		EndID:    elemID,
+	}
+}
+
+const (
+	// CounterIncrementsKey is the value key holding the increments total of a counter operation.
+	CounterIncrementsKey = "p"
+	// CounterDecrementsKey is the value key holding the decrements total of a counter operation.
+	CounterDecrementsKey = "n"
+)
+
+// NewCounterIncOperation creates an operation that adds delta to the counter for
+// the session of id. The operation carries the session's new totals, so applying
+// it more than once has no further effect.
+func NewCounterIncOperation(id common.LogicalTimestamp, counter crdt.CounterNode, delta uint64) Operation {
+	increments, decrements := counter.Totals(id.SID)
+	return newCounterOperation(id, counter.ID(), increments+delta, decrements)
+}
+
+// NewCounterDecOperation creates an operation that subtracts delta from a PN-counter
+// for the session of id.
+func NewCounterDecOperation(id common.LogicalTimestamp, counter *crdt.PNCounterNode, delta uint64) Operation {
+	increments, decrements := counter.Totals(id.SID)
+	return newCounterOperation(id, counter.ID(), increments, decrements+delta)
+}
+
+// newCounterOperation creates an operation that sets the totals of a counter for the session of id.
+func newCounterOperation(id, targetID common.LogicalTimestamp, increments, decrements uint64) Operation {
+	return &InsOperation{
+		ID:       id,
+		TargetID: targetID,
+		Value: map[string]interface{}{
+			CounterIncrementsKey: increments,
+			CounterDecrementsKey: decrements,
+		},
+	}
+}
+
+const (
+	// RegisterValueKey is the value key holding the written value of a register operation.
+	RegisterValueKey = "value"
+	// RegisterObservedKey is the value key holding the timestamps a register write replaces.
+	RegisterObservedKey = "observed"
+)
+
+// NewMVRegisterSetOperation creates an operation that writes value to a multi-value
+// register, replacing the values currently in it. Values written concurrently by
+// other sessions are kept as conflicts.
+func NewMVRegisterSetOperation(id common.LogicalTimestamp, register *crdt.MVRegisterNode, value interface{}) Operation {
+	return &InsOperation{
+		ID:       id,
+		TargetID: register.ID(),
+		Value: map[string]interface{}{
+			RegisterValueKey:    value,
+			RegisterObservedKey: register.Observed(),
+		},
+	}
+}
+
 // NewStringInsertOperation creates a new operation that inserts text into a string.
 func NewStringInsertOperation(targetID common.LogicalTimestamp, index int, text string) (Operation, error) {
 	if index < 0 {
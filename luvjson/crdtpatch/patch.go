@@ -55,12 +55,27 @@ func (p *Patch) AddOperation(op Operation) {
 	p.operations = append(p.operations, op)
 }
 
-// Apply applies the patch to the document.
+// Apply applies the patch to the document. Operations the document has already
+// applied, e.g. of a patch delivered twice, are skipped. A patch with an envelope
+// whose dependencies the document has not seen is not applied and a
+// CausalityError is returned; see CausalApplier.
 func (p *Patch) Apply(doc *crdt.Document) error {
+	if err := p.checkCausality(doc); err != nil {
+		return err
+	}
 	for _, op := range p.operations {
+		span := op.Span()
+		if span > 0 && doc.HasApplied(op.GetID()) {
+			// 중복 전달된 연산은 무시
+			continue
+		}
 		if err := op.Apply(doc); err != nil {
 			return errors.Wrap(err, "failed to apply operation")
 		}
+		if span > 0 {
+			doc.UpdateClock(op.GetID().Increment(span - 1))
+			doc.MarkApplied(op.GetID(), span)
+		}
 	}
 	return nil
 }
@@ -257,6 +272,16 @@ func (p *Patch) RewriteTime(newID common.LogicalTimestamp) *Patch {
 				newInsOp.TargetID = origOp.TargetID
 			}
 
+			// Adjust the string reference ID if it is from the same session
+			if origOp.RefID.SID.Compare(p.id.SID) == 0 {
+				newInsOp.RefID = common.LogicalTimestamp{
+					SID:     newID.SID,
+					Counter: uint64(int64(origOp.RefID.Counter) + offsetCounter),
+				}
+			} else {
+				newInsOp.RefID = origOp.RefID
+			}
+
 		case *DelOperation:
 			newDelOp := newOp.(*DelOperation)
 			newDelOp.Key = origOp.Key
@@ -3,6 +3,7 @@ package crdtpatch
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 	"testing"
 
 	"tictactoe/luvjson/common"
@@ -216,6 +217,30 @@ func TestPatchMarshalUnmarshalJSON(t *testing.T) {
 	assert.Equal(t, common.OperationTypeNew, p2.Operations()[0].Type())
 }
 
+func TestPatchJSONRootInsert(t *testing.T) {
+	// A root insert references the new root node by timestamp
+	sid := common.NewSessionID()
+	rootID := common.LogicalTimestamp{SID: sid, Counter: 1}
+	p := NewPatch(rootID)
+	p.AddOperation(&NewOperation{ID: rootID, NodeType: common.NodeTypeCon, Value: "root"})
+	p.AddOperation(&InsOperation{ID: rootID.Increment(1), TargetID: common.RootID, Value: rootID})
+
+	jsonData, err := json.Marshal(p)
+	assert.NoError(t, err)
+
+	// The timestamp value survives the round trip
+	p2 := NewPatch(common.LogicalTimestamp{})
+	assert.NoError(t, json.Unmarshal(jsonData, p2))
+	assert.Equal(t, rootID, p2.Operations()[1].(*InsOperation).Value)
+
+	// The decoded patch builds the same document
+	doc := crdt.NewDocument(common.NewSessionID())
+	assert.NoError(t, p2.Apply(doc))
+	view, err := doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, "root", view)
+}
+
 func TestCreateOperation(t *testing.T) {
 	// Create a new operation
 	sid := common.NewSessionID()
@@ -724,3 +749,497 @@ func TestClone(t *testing.T) {
 	// Verify the cloned patch is not affected
 	assert.Equal(t, "John Doe", clonedPatch.Metadata()["author"])
 }
+
+func TestListOperations(t *testing.T) {
+	doc := crdt.NewDocument(common.NewSessionID())
+	sid := common.NewSessionID()
+	ts := func(counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	// Create a list with two elements
+	p := NewPatch(ts(1))
+	p.AddOperation(&NewOperation{ID: ts(1), NodeType: common.NodeTypeList})
+	p.AddOperation(&NewOperation{ID: ts(2), NodeType: common.NodeTypeCon, Value: "sword"})
+	p.AddOperation(&NewOperation{ID: ts(3), NodeType: common.NodeTypeCon, Value: "shield"})
+	p.AddOperation(NewListInsertOperation(ts(4), ts(1), common.RootID, ts(2)))
+	p.AddOperation(NewListInsertOperation(ts(5), ts(1), ts(4), ts(3)))
+	assert.NoError(t, p.Apply(doc))
+
+	node, err := doc.GetNode(ts(1))
+	assert.NoError(t, err)
+	list := node.(*crdt.MovableListNode)
+	assert.Equal(t, []interface{}{ts(2), ts(3)}, list.Value())
+
+	// Move the shield to the front through a JSON encoded patch
+	move := NewPatch(ts(6))
+	move.AddOperation(NewListMoveOperation(ts(6), ts(1), ts(5), common.RootID))
+	data, err := move.MarshalJSON()
+	assert.NoError(t, err)
+	decoded := &Patch{}
+	assert.NoError(t, decoded.UnmarshalJSON(data))
+	assert.NoError(t, decoded.Apply(doc))
+	assert.Equal(t, []interface{}{ts(3), ts(2)}, list.Value())
+
+	// Delete the moved element by its ID
+	del := NewPatch(ts(7))
+	del.AddOperation(NewListDeleteOperation(ts(7), ts(1), ts(5)))
+	assert.NoError(t, del.Apply(doc))
+	assert.Equal(t, []interface{}{ts(2)}, list.Value())
+
+	// Unknown reference slots are rejected
+	bad := NewPatch(ts(8))
+	bad.AddOperation(NewListInsertOperation(ts(8), ts(1), ts(99), ts(2)))
+	assert.Error(t, bad.Apply(doc))
+}
+
+func TestCounterOperations(t *testing.T) {
+	base := common.NewSessionID()
+	counterID := common.LogicalTimestamp{SID: base, Counter: 1}
+
+	newReplica := func() (*crdt.Document, *crdt.PNCounterNode) {
+		doc := crdt.NewDocument(common.NewSessionID())
+		p := NewPatch(counterID)
+		p.AddOperation(&NewOperation{ID: counterID, NodeType: common.NodeTypePNCounter})
+		assert.NoError(t, p.Apply(doc))
+
+		node, err := doc.GetNode(counterID)
+		assert.NoError(t, err)
+		return doc, node.(*crdt.PNCounterNode)
+	}
+
+	// Each replica records its own increment concurrently
+	docA, counterA := newReplica()
+	docB, counterB := newReplica()
+
+	idA := common.LogicalTimestamp{SID: docA.GetSessionID(), Counter: 10}
+	patchA := NewPatch(idA)
+	patchA.AddOperation(NewCounterIncOperation(idA, counterA, 30))
+	assert.NoError(t, patchA.Apply(docA))
+
+	idB := common.LogicalTimestamp{SID: docB.GetSessionID(), Counter: 10}
+	patchB := NewPatch(idB)
+	patchB.AddOperation(NewCounterIncOperation(idB, counterB, 20))
+	patchB.AddOperation(NewCounterDecOperation(idB.Next(), counterB, 5))
+	assert.NoError(t, patchB.Apply(docB))
+
+	// Exchange the patches as JSON; applying twice has no further effect
+	exchange := func(p *Patch, doc *crdt.Document) {
+		data, err := p.MarshalJSON()
+		assert.NoError(t, err)
+		decoded := &Patch{}
+		assert.NoError(t, decoded.UnmarshalJSON(data))
+		assert.NoError(t, decoded.Apply(doc))
+		assert.NoError(t, decoded.Apply(doc))
+	}
+	exchange(patchB, docA)
+	exchange(patchA, docB)
+
+	assert.Equal(t, int64(45), counterA.Count())
+	assert.Equal(t, int64(45), counterB.Count())
+
+	// Counter operations require totals
+	bad := NewPatch(idA.Next())
+	bad.AddOperation(&InsOperation{ID: idA.Next(), TargetID: counterID, Value: "x"})
+	assert.Error(t, bad.Apply(docA))
+}
+
+func TestMVRegisterOperations(t *testing.T) {
+	base := common.NewSessionID()
+	registerID := common.LogicalTimestamp{SID: base, Counter: 1}
+
+	newReplica := func() (*crdt.Document, *crdt.MVRegisterNode) {
+		doc := crdt.NewDocument(common.NewSessionID())
+		p := NewPatch(registerID)
+		p.AddOperation(&NewOperation{ID: registerID, NodeType: common.NodeTypeMVReg})
+		assert.NoError(t, p.Apply(doc))
+
+		node, err := doc.GetNode(registerID)
+		assert.NoError(t, err)
+		return doc, node.(*crdt.MVRegisterNode)
+	}
+
+	docA, registerA := newReplica()
+	docB, registerB := newReplica()
+
+	// Both replicas write concurrently
+	idA := common.LogicalTimestamp{SID: docA.GetSessionID(), Counter: 2}
+	patchA := NewPatch(idA)
+	patchA.AddOperation(NewMVRegisterSetOperation(idA, registerA, float64(10)))
+	assert.NoError(t, patchA.Apply(docA))
+
+	idB := common.LogicalTimestamp{SID: docB.GetSessionID(), Counter: 2}
+	patchB := NewPatch(idB)
+	patchB.AddOperation(NewMVRegisterSetOperation(idB, registerB, float64(20)))
+	assert.NoError(t, patchB.Apply(docB))
+
+	exchange := func(p *Patch, doc *crdt.Document) {
+		data, err := p.MarshalJSON()
+		assert.NoError(t, err)
+		decoded := &Patch{}
+		assert.NoError(t, decoded.UnmarshalJSON(data))
+		assert.NoError(t, decoded.Apply(doc))
+	}
+	exchange(patchB, docA)
+	exchange(patchA, docB)
+
+	assert.True(t, registerA.HasConflict())
+	assert.Equal(t, registerA.ConflictingValues(), registerB.ConflictingValues())
+
+	// A resolves the conflict by keeping the higher value
+	resolveID := idA.Next()
+	resolve := NewPatch(resolveID)
+	resolve.AddOperation(NewMVRegisterSetOperation(resolveID, registerA, float64(20)))
+	assert.NoError(t, resolve.Apply(docA))
+	exchange(resolve, docB)
+
+	assert.False(t, registerB.HasConflict())
+	assert.Equal(t, float64(20), registerB.Value())
+}
+
+func TestCompactAndRebase(t *testing.T) {
+	sid := common.NewSessionID()
+	ts := func(counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+	patch := func(ops ...Operation) *Patch {
+		p := NewPatch(ops[0].GetID())
+		for _, op := range ops {
+			p.AddOperation(op)
+		}
+		return p
+	}
+
+	// {"name": "", "hp": <counter>} 생성 후 작은 패치들로 편집
+	setup := patch(
+		&NewOperation{ID: ts(1), NodeType: common.NodeTypeObj},
+		&InsOperation{ID: ts(2), TargetID: common.RootID, Value: ts(1)},
+		&NewOperation{ID: ts(3), NodeType: common.NodeTypeStr},
+		&NewOperation{ID: ts(4), NodeType: common.NodeTypePNCounter},
+		&InsOperation{ID: ts(5), TargetID: ts(1), Value: map[string]interface{}{"name": ts(3), "hp": ts(4)}},
+	)
+	setup.SetMetadata(map[string]interface{}{"author": "alice"})
+	edits := []*Patch{
+		patch(&InsOperation{ID: ts(6), TargetID: ts(3), Value: "b"}),
+		patch(&InsOperation{ID: ts(7), TargetID: ts(3), RefID: ts(6), Value: "os"}),
+		patch(&InsOperation{ID: ts(9), TargetID: ts(3), RefID: ts(8), Value: "s"}),
+		patch(&InsOperation{ID: ts(10), TargetID: ts(1), Value: map[string]interface{}{"phase": float64(1), "title": "a"}}),
+		patch(&InsOperation{ID: ts(11), TargetID: ts(4), Value: map[string]interface{}{CounterIncrementsKey: uint64(10), CounterDecrementsKey: uint64(0)}}),
+		patch(&InsOperation{ID: ts(12), TargetID: ts(4), Value: map[string]interface{}{CounterIncrementsKey: uint64(10), CounterDecrementsKey: uint64(3)}}),
+		patch(&InsOperation{ID: ts(13), TargetID: ts(1), Value: map[string]interface{}{"title": "b"}}),
+		patch(&DelOperation{ID: ts(14), TargetID: ts(1), Key: "phase"}),
+		patch(&NopOperation{ID: ts(15), SpanValue: 2}),
+	}
+	edits[len(edits)-1].SetMetadata(map[string]interface{}{"author": "bob"})
+	patches := append([]*Patch{setup}, edits...)
+
+	expected := crdt.NewDocument(common.NewSessionID())
+	for _, p := range patches {
+		assert.NoError(t, p.Apply(expected))
+	}
+	expectedView, err := expected.View()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "boss", "hp": int64(7), "title": "b"}, expectedView)
+
+	compacted, err := Compact(patches)
+	assert.NoError(t, err)
+	assert.Equal(t, ts(1), compacted.ID())
+	assert.Equal(t, map[string]interface{}{"author": "bob"}, compacted.Metadata())
+
+	// 글자 입력은 하나로 합쳐지고 덮어쓰인 쓰기는 제거됨
+	ops := compacted.Operations()
+	assert.Len(t, ops, len(setup.Operations())+5)
+	assert.Equal(t, &InsOperation{ID: ts(6), TargetID: ts(3), Value: "boss"}, ops[5])
+	assert.Equal(t, ts(12), ops[6].GetID())
+	assert.Equal(t, ts(13), ops[7].GetID())
+	assert.Equal(t, ts(14), ops[8].GetID())
+	assert.Equal(t, &NopOperation{ID: ts(15), SpanValue: 2}, ops[9])
+	assert.Equal(t, "b", edits[0].Operations()[0].(*InsOperation).Value)
+
+	clock := func(doc *crdt.Document) map[string]uint64 {
+		data, err := doc.MarshalJSON()
+		assert.NoError(t, err)
+		var state struct {
+			Time map[string]uint64 `json:"time"`
+		}
+		assert.NoError(t, json.Unmarshal(data, &state))
+		return state.Time
+	}
+
+	doc := crdt.NewDocument(common.NewSessionID())
+	assert.NoError(t, compacted.Apply(doc))
+	view, err := doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedView, view)
+	assert.Equal(t, uint64(16), clock(doc)[sid.String()])
+
+	_, err = Compact(nil)
+	assert.Error(t, err)
+	other := NewPatch(common.LogicalTimestamp{SID: common.NewSessionID(), Counter: 1})
+	_, err = Compact([]*Patch{setup, other})
+	assert.Error(t, err)
+
+	// 스냅샷에 포함된 연산을 건너뛰고 나머지를 재생
+	restored := crdt.NewDocument(common.NewSessionID())
+	for _, p := range patches[:5] {
+		assert.NoError(t, p.Apply(restored))
+	}
+	onto := crdt.StabilityFrontier{sid: clock(restored)[sid.String()]}
+	replayed := 0
+	for _, p := range patches {
+		rebased, err := Rebase(p, onto)
+		assert.NoError(t, err)
+		assert.Equal(t, p.ID(), rebased.ID())
+		replayed += len(rebased.Operations())
+		assert.NoError(t, rebased.Apply(restored))
+	}
+	assert.Equal(t, 5, replayed)
+	view, err = restored.View()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedView, view)
+
+	// 일부만 포함된 연산은 오류
+	_, err = Rebase(edits[1], crdt.StabilityFrontier{sid: 7})
+	assert.Error(t, err)
+}
+
+func TestValidateAndDryRun(t *testing.T) {
+	sid := common.NewSessionID()
+	ts := func(counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	// {"todos": [], "title": ""}
+	doc := crdt.NewDocument(common.NewSessionID())
+	setup := NewPatch(ts(1))
+	setup.AddOperation(&NewOperation{ID: ts(1), NodeType: common.NodeTypeObj})
+	setup.AddOperation(&InsOperation{ID: ts(2), TargetID: common.RootID, Value: ts(1)})
+	setup.AddOperation(&NewOperation{ID: ts(3), NodeType: common.NodeTypeArr})
+	setup.AddOperation(&NewOperation{ID: ts(4), NodeType: common.NodeTypeStr})
+	setup.AddOperation(&InsOperation{ID: ts(5), TargetID: ts(1), Value: map[string]interface{}{"todos": ts(3), "title": ts(4)}})
+	assert.NoError(t, setup.Validate(doc))
+	assert.NoError(t, setup.DryRun(doc))
+	assert.NoError(t, setup.Apply(doc))
+
+	before, err := doc.MarshalJSON()
+	assert.NoError(t, err)
+
+	valid := NewPatch(ts(6))
+	valid.AddOperation(&NewOperation{ID: ts(6), NodeType: common.NodeTypeObj})
+	valid.AddOperation(&InsOperation{ID: ts(7), TargetID: ts(3), Value: map[string]interface{}{"0": ts(6)}})
+	valid.AddOperation(&InsOperation{ID: ts(8), TargetID: ts(6), Value: map[string]interface{}{"done": false}})
+	valid.AddOperation(&InsOperation{ID: ts(9), TargetID: ts(4), Value: "todo"})
+	assert.NoError(t, valid.Validate(doc))
+	assert.NoError(t, valid.DryRun(doc))
+
+	invalid := NewPatch(ts(10))
+	invalid.AddOperation(&InsOperation{ID: ts(10), TargetID: ts(99), Value: "x"})
+	invalid.AddOperation(&InsOperation{ID: ts(11), TargetID: ts(4), Value: map[string]interface{}{"a": "b"}})
+	invalid.AddOperation(&InsOperation{ID: ts(12), TargetID: ts(3), Value: map[string]interface{}{"0": ts(1)}})
+	invalid.AddOperation(&DelOperation{ID: ts(13), TargetID: ts(1)})
+	invalid.AddOperation(&NewOperation{ID: ts(3), NodeType: common.NodeTypeArr})
+	invalid.AddOperation(&NopOperation{ID: ts(14), SpanValue: 1})
+
+	err = invalid.Validate(doc)
+	var validationErr ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	indices := make([]int, len(validationErr.Errors))
+	for i, opErr := range validationErr.Errors {
+		indices[i] = opErr.Index
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, indices)
+	assert.ErrorAs(t, validationErr.Errors[0], &common.ErrNodeNotFound{})
+	assert.Contains(t, validationErr.Errors[2].Error(), "cycle")
+
+	// 내용에 따라 달라지는 오류는 DryRun에서만 발견
+	outOfBounds := NewPatch(ts(15))
+	outOfBounds.AddOperation(&DelOperation{ID: ts(15), TargetID: ts(3), Key: "5"})
+	outOfBounds.AddOperation(&InsOperation{ID: ts(16), TargetID: ts(4), RefID: ts(77), Value: "x"})
+	assert.NoError(t, outOfBounds.Validate(doc))
+	err = outOfBounds.DryRun(doc)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Errors, 2)
+
+	after, err := doc.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(before), string(after))
+}
+
+func TestApplyDuplicate(t *testing.T) {
+	sid := common.NewSessionID()
+	ts := func(counter uint64) common.LogicalTimestamp {
+		return common.LogicalTimestamp{SID: sid, Counter: counter}
+	}
+
+	setup := NewPatch(ts(1))
+	setup.AddOperation(&NewOperation{ID: ts(1), NodeType: common.NodeTypeStr})
+	setup.AddOperation(&InsOperation{ID: ts(2), TargetID: common.RootID, Value: ts(1)})
+	setup.AddOperation(&InsOperation{ID: ts(3), TargetID: ts(1), Value: "hi"})
+	edit := NewPatch(ts(6))
+	edit.AddOperation(&InsOperation{ID: ts(6), TargetID: ts(1), RefID: ts(4), Value: "!"})
+
+	doc := crdt.NewDocument(common.NewSessionID())
+	assert.False(t, doc.HasApplied(ts(1)))
+
+	// 순서가 뒤바뀌고 중복된 전달
+	assert.NoError(t, setup.Apply(doc))
+	assert.NoError(t, edit.Apply(doc))
+	assert.NoError(t, setup.Apply(doc))
+	assert.NoError(t, edit.Apply(doc))
+
+	view, err := doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, "hi!", view)
+
+	for _, counter := range []uint64{1, 2, 3, 4, 6} {
+		assert.True(t, doc.HasApplied(ts(counter)), "counter %d", counter)
+	}
+	assert.False(t, doc.HasApplied(ts(5)))
+	assert.False(t, doc.HasApplied(ts(7)))
+
+	// 다른 저장소를 사용하면 그 저장소의 기록을 따름
+	store := crdt.NewAppliedRanges()
+	store.MarkApplied(ts(1), 4)
+	store.MarkApplied(ts(6), 1)
+	other := crdt.NewDocument(common.NewSessionID())
+	other.SetAppliedStore(store)
+	assert.NoError(t, setup.Apply(other))
+	view, err = other.View()
+	assert.NoError(t, err)
+	assert.Nil(t, view)
+
+	store.MarkApplied(ts(5), 1)
+	assert.True(t, store.HasApplied(ts(5)))
+	assert.False(t, store.HasApplied(ts(7)))
+	assert.False(t, store.HasApplied(common.LogicalTimestamp{SID: common.NewSessionID(), Counter: 1}))
+}
+
+func TestAppliedRangesConcurrent(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := crdt.NewDocument(common.NewSessionID())
+
+	// 원격 패치와 로컬 편집이 동시에 적용 기록을 읽고 씀
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id := common.LogicalTimestamp{SID: sid, Counter: uint64(i*4 + w + 1)}
+				doc.MarkApplied(id, 1)
+				doc.HasApplied(id)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for counter := uint64(1); counter <= 400; counter++ {
+		assert.True(t, doc.HasApplied(common.LogicalTimestamp{SID: sid, Counter: counter}), "counter %d", counter)
+	}
+}
+
+func TestEnvelopeCausalOrder(t *testing.T) {
+	alice := crdt.NewDocument(common.NewSessionID())
+	bob := crdt.NewDocument(common.NewSessionID())
+	send := func(doc *crdt.Document, origin string, build func(b *PatchBuilder)) *Patch {
+		envelope := NewEnvelope(origin, doc)
+		builder := NewPatchBuilder(doc.GetSessionID(), doc.NextTimestamp().Counter)
+		build(builder)
+		p := builder.Flush()
+		p.SetEnvelope(envelope)
+		assert.NoError(t, p.Apply(doc))
+		return p
+	}
+
+	var obj common.LogicalTimestamp
+	first := send(alice, "alice", func(b *PatchBuilder) {
+		obj = b.NewObject().ID
+		b.InsertValue(common.RootID, obj)
+		b.InsertObjectField(obj, "hp", float64(100))
+	})
+	assert.NoError(t, first.Apply(bob))
+	second := send(bob, "bob", func(b *PatchBuilder) {
+		b.InsertObjectField(obj, "hp", float64(80))
+	})
+	third := send(alice, "alice", func(b *PatchBuilder) {
+		b.InsertObjectField(obj, "name", "boss")
+	})
+
+	// 인코딩된 패치도 봉투를 유지
+	data, err := second.MarshalJSON()
+	assert.NoError(t, err)
+	decoded := &Patch{}
+	assert.NoError(t, decoded.UnmarshalJSON(data))
+	envelope, err := decoded.Envelope()
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", envelope.Origin)
+	assert.Contains(t, envelope.Deps, lastID(first.Operations()[len(first.Operations())-1]))
+
+	// 의존하는 패치보다 먼저 도착한 패치는 적용되지 않음
+	doc := crdt.NewDocument(common.NewSessionID())
+	err = second.Apply(doc)
+	var causalityErr CausalityError
+	assert.ErrorAs(t, err, &causalityErr)
+	assert.NotEmpty(t, causalityErr.Missing)
+
+	applier := NewCausalApplier(doc, NewMemoryPatchBuffer(2))
+	applied, err := applier.Apply(second)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	applied, err = applier.Apply(third)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Len(t, applier.Pending(), 2)
+
+	// 버퍼가 가득 차면 오류
+	_, err = applier.Apply(third.Clone())
+	assert.Error(t, err)
+
+	applied, err = applier.Apply(first)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Patch{first, second, third}, applied)
+	assert.Empty(t, applier.Pending())
+
+	view, err := doc.View()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hp": float64(80), "name": "boss"}, view)
+
+	// 봉투가 없는 패치는 바로 적용됨
+	plain := NewPatch(common.LogicalTimestamp{SID: common.NewSessionID(), Counter: 1})
+	plain.AddOperation(&InsOperation{ID: plain.ID(), TargetID: obj, Value: map[string]interface{}{"phase": float64(2)}})
+	applied, err = applier.Apply(plain)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+}
+
+func TestEnvelopeDependencyInClockGap(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := crdt.NewDocument(common.NewSessionID())
+
+	// 세션의 카운터 1-2와 10만 적용되어 3-9가 비어 있음
+	builder := NewPatchBuilder(sid, 1)
+	obj := builder.NewObject().ID
+	builder.InsertValue(common.RootID, obj)
+	assert.NoError(t, builder.Flush().Apply(doc))
+	builder = NewPatchBuilder(sid, 10)
+	builder.InsertObjectField(obj, "hp", float64(100))
+	assert.NoError(t, builder.Flush().Apply(doc))
+
+	skipped := common.LogicalTimestamp{SID: sid, Counter: 5}
+	assert.True(t, doc.ClockCovers(skipped))
+	assert.False(t, doc.HasApplied(skipped))
+
+	// 비어 있는 카운터에 의존하는 패치는 적용되지 않음
+	dependent := NewPatch(common.LogicalTimestamp{SID: common.NewSessionID(), Counter: 1})
+	dependent.AddOperation(&InsOperation{ID: dependent.ID(), TargetID: obj, Value: map[string]interface{}{"phase": float64(2)}})
+	dependent.SetEnvelope(&Envelope{Origin: "bob", Deps: []common.LogicalTimestamp{{SID: sid, Counter: 2}, skipped}})
+	missing, err := dependent.MissingDependencies(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.LogicalTimestamp{skipped}, missing)
+
+	applier := NewCausalApplier(doc, nil)
+	applied, err := applier.Apply(dependent)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Len(t, applier.Pending(), 1)
+}
@@ -0,0 +1,323 @@
+package crdtpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// OperationError is the error of an operation of a patch.
+type OperationError struct {
+	// Index is the position of the operation in the patch
+	Index int
+	// ID is the ID of the operation
+	ID  common.LogicalTimestamp
+	Err error
+}
+
+func (e OperationError) Error() string {
+	return fmt.Sprintf("operation %d (%s): %v", e.Index, e.ID, e.Err)
+}
+
+// Unwrap returns the error of the operation.
+func (e OperationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists the invalid operations of a patch.
+type ValidationError struct {
+	Errors []OperationError
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid patch: %s", strings.Join(messages, "; "))
+}
+
+// Validate checks the operations of the patch against doc without modifying it:
+// the nodes they target must exist in doc or be created earlier in the patch,
+// the operations must be supported by the type of their target, and nodes must
+// not be linked below themselves. It returns a ValidationError listing the
+// invalid operations, or nil.
+func (p *Patch) Validate(doc *crdt.Document) error {
+	v := &validation{
+		doc:     doc,
+		created: make(map[common.LogicalTimestamp]common.NodeType),
+		links:   make(map[common.LogicalTimestamp][]common.LogicalTimestamp),
+	}
+
+	var errs []OperationError
+	for i, op := range p.operations {
+		if err := v.check(op); err != nil {
+			errs = append(errs, OperationError{Index: i, ID: op.GetID(), Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// DryRun validates the patch and applies it to a copy of doc, which catches the
+// errors that depend on the content of the nodes, such as unknown reference
+// elements or out of bounds indices. doc is not modified. Operations that fail
+// are skipped and the following operations are still tried. It returns a
+// ValidationError listing the failed operations, or nil.
+func (p *Patch) DryRun(doc *crdt.Document) error {
+	if err := p.Validate(doc); err != nil {
+		return err
+	}
+
+	data, err := doc.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	scratch := crdt.NewDocument(doc.GetSessionID())
+	if err := scratch.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	var errs []OperationError
+	for i, op := range p.operations {
+		if err := op.Apply(scratch); err != nil {
+			errs = append(errs, OperationError{Index: i, ID: op.GetID(), Err: err})
+			continue
+		}
+		if span := op.Span(); span > 0 {
+			scratch.UpdateClock(op.GetID().Increment(span - 1))
+		}
+	}
+	if len(errs) > 0 {
+		return ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validation tracks the nodes created and linked by the operations checked so far.
+type validation struct {
+	doc *crdt.Document
+	// created holds the types of the nodes created by the patch
+	created map[common.LogicalTimestamp]common.NodeType
+	// links holds the nodes linked below a node by the patch
+	links map[common.LogicalTimestamp][]common.LogicalTimestamp
+}
+
+// check returns the error of an operation, or nil.
+func (v *validation) check(op Operation) error {
+	switch o := op.(type) {
+	case *NewOperation:
+		if _, exists := v.nodeType(o.ID); exists {
+			return common.ErrInvalidOperation{Message: fmt.Sprintf("node %s already exists", o.ID)}
+		}
+		switch o.NodeType {
+		case common.NodeTypeCon, common.NodeTypeVal, common.NodeTypeObj, common.NodeTypeVec,
+			common.NodeTypeStr, common.NodeTypeArr, common.NodeTypeBin, common.NodeTypeList,
+			common.NodeTypeGCounter, common.NodeTypePNCounter, common.NodeTypeMVReg, common.NodeTypeRoot:
+		default:
+			return common.ErrInvalidNodeType{Type: string(o.NodeType)}
+		}
+		v.created[o.ID] = o.NodeType
+		return nil
+	case *InsOperation:
+		return v.checkIns(o)
+	case *DelOperation:
+		return v.checkDel(o)
+	case *NopOperation:
+		return nil
+	default:
+		return common.ErrInvalidOperationType{Type: string(op.Type())}
+	}
+}
+
+// checkIns returns the error of an 'ins' operation, or nil.
+func (v *validation) checkIns(o *InsOperation) error {
+	nodeType, ok := v.nodeType(o.TargetID)
+	if !ok {
+		return common.ErrNodeNotFound{ID: o.TargetID}
+	}
+
+	obj, isMap := o.Value.(map[string]interface{})
+	switch nodeType {
+	case common.NodeTypeCon, common.NodeTypeVal, common.NodeTypeRoot:
+		return v.link(o.TargetID, o.Value)
+	case common.NodeTypeObj:
+		if !isMap {
+			return common.ErrInvalidOperation{Message: "object 'ins' operation requires a value object"}
+		}
+		for _, val := range obj {
+			if err := v.link(o.TargetID, val); err != nil {
+				return err
+			}
+		}
+	case common.NodeTypeVec, common.NodeTypeArr:
+		if !isMap {
+			return common.ErrInvalidOperation{Message: fmt.Sprintf("%s 'ins' operation requires a value object", nodeType)}
+		}
+		for key, val := range obj {
+			if index, err := strconv.Atoi(key); err != nil || index < 0 {
+				return common.ErrInvalidOperation{Message: fmt.Sprintf("invalid index %q for %s 'ins' operation", key, nodeType)}
+			}
+			if err := v.link(o.TargetID, val); err != nil {
+				return err
+			}
+		}
+	case common.NodeTypeStr:
+		if _, ok := o.Value.(string); !ok {
+			return common.ErrInvalidOperation{Message: "string 'ins' operation requires a string value"}
+		}
+	case common.NodeTypeList:
+		if isMap && len(obj) == 1 {
+			if _, ok := timestampValue(obj[ListMoveKey]); ok {
+				return nil
+			}
+		}
+		return v.link(o.TargetID, o.Value)
+	case common.NodeTypeMVReg:
+		if !isMap {
+			return common.ErrInvalidOperation{Message: "register 'ins' operation requires a value object"}
+		}
+		if _, ok := timestampList(obj[RegisterObservedKey]); !ok {
+			return common.ErrInvalidOperation{Message: "invalid observed timestamps in register 'ins' operation"}
+		}
+		return v.link(o.TargetID, obj[RegisterValueKey])
+	case common.NodeTypeGCounter, common.NodeTypePNCounter:
+		if _, _, ok := counterTotals(o.Value); !ok {
+			return common.ErrInvalidOperation{Message: "counter 'ins' operation requires a value with p and n totals"}
+		}
+	default:
+		return common.ErrInvalidOperation{Message: fmt.Sprintf("unsupported node type %s for 'ins' operation", nodeType)}
+	}
+	return nil
+}
+
+// checkDel returns the error of a 'del' operation, or nil.
+func (v *validation) checkDel(o *DelOperation) error {
+	nodeType, ok := v.nodeType(o.TargetID)
+	if !ok {
+		return common.ErrNodeNotFound{ID: o.TargetID}
+	}
+
+	switch nodeType {
+	case common.NodeTypeObj:
+		if o.Key == "" {
+			return common.ErrInvalidOperation{Message: "object 'del' operation requires a key"}
+		}
+	case common.NodeTypeArr:
+		if o.Key != "" {
+			if index, err := strconv.Atoi(o.Key); err != nil || index < 0 {
+				return common.ErrInvalidOperation{Message: fmt.Sprintf("invalid array index %q for 'del' operation", o.Key)}
+			}
+		}
+	case common.NodeTypeStr, common.NodeTypeList:
+	default:
+		return common.ErrInvalidOperation{Message: fmt.Sprintf("unsupported node type %s for 'del' operation", nodeType)}
+	}
+	return nil
+}
+
+// nodeType returns the type of the node id of the document or created by the patch.
+func (v *validation) nodeType(id common.LogicalTimestamp) (common.NodeType, bool) {
+	if nodeType, ok := v.created[id]; ok {
+		return nodeType, true
+	}
+	node, err := v.doc.GetNode(id)
+	if err != nil {
+		return "", false
+	}
+	return node.Type(), true
+}
+
+// link records that value, if it refers to a node, is linked below target. It
+// returns an error if value refers to a missing node or to a node containing target.
+func (v *validation) link(target common.LogicalTimestamp, value interface{}) error {
+	id, ok := timestampValue(value)
+	if !ok || id == common.RootID {
+		return nil
+	}
+	if _, exists := v.nodeType(id); !exists {
+		return common.ErrNodeNotFound{ID: id}
+	}
+	if v.contains(id, target, make(map[common.LogicalTimestamp]bool)) {
+		return common.ErrInvalidOperation{Message: fmt.Sprintf("linking node %s below node %s creates a cycle", id, target)}
+	}
+	v.links[target] = append(v.links[target], id)
+	return nil
+}
+
+// contains reports whether the node target is id or below it.
+func (v *validation) contains(id, target common.LogicalTimestamp, visited map[common.LogicalTimestamp]bool) bool {
+	if id == target {
+		return true
+	}
+	if visited[id] {
+		return false
+	}
+	visited[id] = true
+
+	for _, child := range v.links[id] {
+		if v.contains(child, target, visited) {
+			return true
+		}
+	}
+	node, err := v.doc.GetNode(id)
+	if err != nil {
+		return false
+	}
+	for _, child := range childIDs(node) {
+		if v.contains(child, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// childIDs returns the IDs of the nodes held by a node.
+func childIDs(node crdt.Node) []common.LogicalTimestamp {
+	var ids []common.LogicalTimestamp
+	addNode := func(child crdt.Node) {
+		if child != nil {
+			ids = append(ids, child.ID())
+		}
+	}
+	addValue := func(value interface{}) {
+		if id, ok := value.(common.LogicalTimestamp); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	switch n := node.(type) {
+	case *crdt.RootNode:
+		addNode(n.NodeValue)
+	case *crdt.LWWValueNode:
+		addNode(n.NodeValue)
+	case *crdt.ConstantNode:
+		addValue(n.NodeValue)
+	case *crdt.LWWObjectNode:
+		for _, field := range n.NodeFields {
+			addNode(field.NodeValue)
+		}
+	case *crdt.LWWVectorNode:
+		for _, field := range n.NodeFields {
+			addNode(field.NodeValue)
+		}
+	case *crdt.RGAArrayNode:
+		for _, elem := range n.NodeElements {
+			addValue(elem.NodeValue)
+		}
+	case *crdt.MovableListNode:
+		for _, elem := range n.NodeItems {
+			addValue(elem.ElementValue)
+		}
+	case *crdt.MVRegisterNode:
+		for _, entry := range n.NodeEntries {
+			addValue(entry.Value)
+		}
+	}
+	return ids
+}
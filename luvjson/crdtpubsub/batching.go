@@ -0,0 +1,263 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// BatchOptions represents the flush policy of a BatchingPubSub.
+// A zero field means no limit.
+type BatchOptions struct {
+	// MaxPatches is the maximum number of patches in a batch.
+	MaxPatches int
+	// MaxBytes is the maximum encoded size of the patches in a batch.
+	MaxBytes int
+	// MaxDelay is how long the first patch of a batch waits before the batch is published.
+	MaxDelay time.Duration
+}
+
+// BatchingPubSub wraps a PubSub and coalesces the patches published to a topic into
+// a single message holding a combined patch (see crdtpatch.Compact).
+// A batch holds the patches of one session published in one format: a patch of
+// another session or format publishes the pending batch first.
+// Raw data is not batched, and subscriptions go to the inner PubSub.
+type BatchingPubSub struct {
+	// inner is the wrapped PubSub.
+	inner PubSub
+	// options contains the flush policy.
+	options BatchOptions
+	// batches is a map of topic to pending batch.
+	batches map[string]*patchBatch
+	// mutex protects the batches map.
+	mutex sync.Mutex
+	// closed indicates whether the PubSub has been closed.
+	closed bool
+}
+
+// patchBatch represents the pending patches of a topic.
+type patchBatch struct {
+	// patches are the pending patches, in publishing order.
+	patches []*crdtpatch.Patch
+	// sid is the session of the patches.
+	sid common.SessionID
+	// format is the encoding format of the patches.
+	format EncodingFormat
+	// size is the encoded size of the patches.
+	size int
+	// timer publishes the batch after MaxDelay.
+	timer *time.Timer
+}
+
+// NewBatchingPubSub creates a new BatchingPubSub wrapping the specified PubSub.
+func NewBatchingPubSub(inner PubSub, options BatchOptions) (*BatchingPubSub, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("inner pubsub cannot be nil")
+	}
+
+	return &BatchingPubSub{
+		inner:   inner,
+		options: options,
+		batches: make(map[string]*patchBatch),
+	}, nil
+}
+
+// Publish adds a patch to the batch of the specified topic. The batch is published
+// once it reaches MaxPatches or MaxBytes, or MaxDelay after its first patch.
+func (ps *BatchingPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	size := 0
+	if ps.options.MaxBytes > 0 {
+		encodeFormat := format
+		if encodeFormat == "" {
+			encodeFormat = NewOptions().DefaultFormat
+		}
+		encoder, err := GetEncoderDecoder(encodeFormat)
+		if err != nil {
+			return err
+		}
+		data, err := encoder.Encode(patch)
+		if err != nil {
+			return fmt.Errorf("failed to encode patch: %w", err)
+		}
+		size = len(data)
+	}
+
+	ps.mutex.Lock()
+	var ready []*patchBatch
+	batch := ps.batches[topic]
+	if batch != nil && (batch.sid != patch.ID().SID || batch.format != format ||
+		(ps.options.MaxBytes > 0 && batch.size+size > ps.options.MaxBytes)) {
+		// 다른 세션이나 형식의 패치는 합칠 수 없으므로 먼저 보냄
+		ready = append(ready, ps.take(topic))
+		batch = nil
+	}
+	if batch == nil {
+		batch = &patchBatch{sid: patch.ID().SID, format: format}
+		ps.batches[topic] = batch
+		if ps.options.MaxDelay > 0 {
+			batch.timer = time.AfterFunc(ps.options.MaxDelay, func() { ps.flushExpired(topic, batch) })
+		}
+	}
+	batch.patches = append(batch.patches, patch)
+	batch.size += size
+	if (ps.options.MaxPatches > 0 && len(batch.patches) >= ps.options.MaxPatches) ||
+		(ps.options.MaxBytes > 0 && batch.size >= ps.options.MaxBytes) {
+		ready = append(ready, ps.take(topic))
+	}
+	ps.mutex.Unlock()
+
+	for _, b := range ready {
+		if err := ps.publishBatch(ctx, topic, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishRaw publishes the pending batch of the specified topic and then the raw data,
+// so that the topic keeps the publishing order.
+func (ps *BatchingPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	if err := ps.FlushTopic(ctx, topic); err != nil {
+		return err
+	}
+	return ps.inner.PublishRaw(ctx, topic, data, format)
+}
+
+// FlushTopic publishes the pending batch of the specified topic.
+func (ps *BatchingPubSub) FlushTopic(ctx context.Context, topic string) error {
+	ps.mutex.Lock()
+	batch := ps.take(topic)
+	ps.mutex.Unlock()
+
+	if batch == nil {
+		return nil
+	}
+	return ps.publishBatch(ctx, topic, batch)
+}
+
+// Flush publishes the pending batches of all topics.
+func (ps *BatchingPubSub) Flush(ctx context.Context) error {
+	ps.mutex.Lock()
+	batches := make(map[string]*patchBatch, len(ps.batches))
+	for topic := range ps.batches {
+		batches[topic] = ps.take(topic)
+	}
+	ps.mutex.Unlock()
+
+	var firstErr error
+	for topic, batch := range batches {
+		if err := ps.publishBatch(ctx, topic, batch); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// take removes the pending batch of a topic and stops its timer. The caller must hold the mutex.
+func (ps *BatchingPubSub) take(topic string) *patchBatch {
+	batch, ok := ps.batches[topic]
+	if !ok {
+		return nil
+	}
+	delete(ps.batches, topic)
+	if batch.timer != nil {
+		batch.timer.Stop()
+	}
+	return batch
+}
+
+// flushExpired publishes a batch whose MaxDelay has passed, unless it was already published.
+func (ps *BatchingPubSub) flushExpired(topic string, batch *patchBatch) {
+	ps.mutex.Lock()
+	if ps.batches[topic] != batch {
+		ps.mutex.Unlock()
+		return
+	}
+	ps.take(topic)
+	ps.mutex.Unlock()
+
+	if err := ps.publishBatch(context.Background(), topic, batch); err != nil {
+		// Log the error, since there is no caller to return it to
+		fmt.Printf("failed to publish batch to topic %s: %v\n", topic, err)
+	}
+}
+
+// publishBatch combines the patches of a batch and publishes them to the inner PubSub.
+func (ps *BatchingPubSub) publishBatch(ctx context.Context, topic string, batch *patchBatch) error {
+	patch := batch.patches[0]
+	if len(batch.patches) > 1 {
+		combined, err := crdtpatch.Compact(batch.patches)
+		if err != nil {
+			return fmt.Errorf("failed to combine patches: %w", err)
+		}
+		patch = combined
+	}
+	return ps.inner.Publish(ctx, topic, patch, batch.format)
+}
+
+// Subscribe subscribes to the specified topic of the inner PubSub.
+// This method implements the Subscriber interface.
+func (ps *BatchingPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.Subscribe(ctx, topic, subscriberID, handler)
+}
+
+// SubscribeSince subscribes to the specified topic of the inner PubSub, replaying the
+// messages retained after the cursor.
+// This method implements the Subscriber interface.
+func (ps *BatchingPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.SubscribeSince(ctx, topic, subscriberID, cursor, handler)
+}
+
+// SubscribePattern subscribes to every topic of the inner PubSub matching the pattern.
+// This method implements the Subscriber interface.
+func (ps *BatchingPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.SubscribePattern(ctx, pattern, subscriberID, handler)
+}
+
+// Unsubscribe unsubscribes from the specified topic of the inner PubSub.
+// This method implements the Subscriber interface.
+func (ps *BatchingPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.Unsubscribe(ctx, topic, subscriberID)
+}
+
+// Close publishes the pending batches and closes the inner PubSub.
+func (ps *BatchingPubSub) Close() error {
+	if ps.closed {
+		return nil
+	}
+
+	flushErr := ps.Flush(context.Background())
+
+	ps.mutex.Lock()
+	ps.closed = true
+	ps.mutex.Unlock()
+
+	if err := ps.inner.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
@@ -0,0 +1,143 @@
+package crdtpubsub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// recordingPubSub records the patches published to it.
+type recordingPubSub struct {
+	mutex   sync.Mutex
+	patches []*crdtpatch.Patch
+	raw     [][]byte
+}
+
+func (ps *recordingPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.patches = append(ps.patches, patch)
+	return nil
+}
+
+func (ps *recordingPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.raw = append(ps.raw, data)
+	return nil
+}
+
+func (ps *recordingPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	return nil
+}
+
+func (ps *recordingPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	return nil
+}
+
+func (ps *recordingPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	return nil
+}
+
+func (ps *recordingPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	return nil
+}
+
+func (ps *recordingPubSub) Close() error {
+	return nil
+}
+
+func (ps *recordingPubSub) published() []*crdtpatch.Patch {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	return append([]*crdtpatch.Patch(nil), ps.patches...)
+}
+
+// constantPatch creates a patch creating a constant node.
+func constantPatch(sid common.SessionID, counter uint64) *crdtpatch.Patch {
+	id := common.LogicalTimestamp{SID: sid, Counter: counter}
+	patch := crdtpatch.NewPatch(id)
+	patch.AddOperation(&crdtpatch.NewOperation{ID: id, NodeType: common.NodeTypeCon, Value: counter})
+	return patch
+}
+
+func TestBatchingPubSub(t *testing.T) {
+	ctx := context.Background()
+	inner := &recordingPubSub{}
+	pubsub, err := NewBatchingPubSub(inner, BatchOptions{MaxPatches: 3})
+	if err != nil {
+		t.Fatalf("Failed to create batching PubSub: %v", err)
+	}
+
+	sid := common.NewSessionID()
+	for i := uint64(1); i <= 4; i++ {
+		if err := pubsub.Publish(ctx, "doc", constantPatch(sid, i), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish patch: %v", err)
+		}
+	}
+
+	// The first three patches are published as one patch
+	published := inner.published()
+	if len(published) != 1 {
+		t.Fatalf("Expected 1 published patch, got %d", len(published))
+	}
+	if len(published[0].Operations()) != 3 {
+		t.Errorf("Expected 3 operations, got %d", len(published[0].Operations()))
+	}
+	if published[0].ID().Counter != 1 {
+		t.Errorf("Expected the ID of the first patch, got %s", published[0].ID())
+	}
+
+	// A patch of another session publishes the pending batch first
+	if err := pubsub.Publish(ctx, "doc", constantPatch(common.NewSessionID(), 1), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish patch: %v", err)
+	}
+	if published = inner.published(); len(published) != 2 || published[1].ID().Counter != 4 {
+		t.Fatalf("Expected the pending batch to be published, got %d patches", len(published))
+	}
+
+	// Raw data is published after the pending batch
+	if err := pubsub.PublishRaw(ctx, "doc", []byte("raw"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish raw data: %v", err)
+	}
+	if published = inner.published(); len(published) != 3 || len(inner.raw) != 1 {
+		t.Fatalf("Expected 3 patches and 1 raw message, got %d and %d", len(published), len(inner.raw))
+	}
+
+	if err := pubsub.Close(); err != nil {
+		t.Fatalf("Failed to close batching PubSub: %v", err)
+	}
+}
+
+func TestBatchingPubSubMaxDelay(t *testing.T) {
+	ctx := context.Background()
+	inner := &recordingPubSub{}
+	pubsub, err := NewBatchingPubSub(inner, BatchOptions{MaxDelay: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create batching PubSub: %v", err)
+	}
+	defer pubsub.Close()
+
+	sid := common.NewSessionID()
+	for i := uint64(1); i <= 2; i++ {
+		if err := pubsub.Publish(ctx, "doc", constantPatch(sid, i), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish patch: %v", err)
+		}
+	}
+	if published := inner.published(); len(published) != 0 {
+		t.Fatalf("Expected no published patch before MaxDelay, got %d", len(published))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(inner.published()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	published := inner.published()
+	if len(published) != 1 || len(published[0].Operations()) != 2 {
+		t.Fatalf("Expected 1 published patch with 2 operations after MaxDelay, got %d patches", len(published))
+	}
+}
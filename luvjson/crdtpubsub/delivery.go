@@ -0,0 +1,386 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// DeliveryMetrics receives the delivery events of a PubSub, e.g. to export them
+// to a monitoring system. Its methods must be safe for concurrent use.
+type DeliveryMetrics interface {
+	// Published is called after a message is published, with the publishing latency.
+	Published(topic string, latency time.Duration, err error)
+	// Handled is called after a handler returns, with the handling latency.
+	Handled(topic string, subscriberID string, latency time.Duration, err error)
+	// Dropped is called when a message is not delivered to a subscriber.
+	Dropped(topic string, reason string)
+	// DeadLettered is called when a message is moved to the dead-letter topic.
+	DeadLettered(topic string, subscriberID string)
+}
+
+// DeliveryStats represents the counters of a DeliveryCounters.
+type DeliveryStats struct {
+	// Published is the number of published messages.
+	Published int64
+	// PublishFailures is the number of messages that failed to publish.
+	PublishFailures int64
+	// PublishLatency is the total publishing latency.
+	PublishLatency time.Duration
+	// Handled is the number of handler calls.
+	Handled int64
+	// HandleFailures is the number of handler calls that returned an error.
+	HandleFailures int64
+	// HandleLatency is the total handling latency.
+	HandleLatency time.Duration
+	// Dropped is a map of reason to the number of dropped messages.
+	Dropped map[string]int64
+	// DeadLettered is the number of messages moved to the dead-letter topic.
+	DeadLettered int64
+}
+
+// DeliveryCounters is a DeliveryMetrics counting the delivery events.
+type DeliveryCounters struct {
+	// stats contains the counters.
+	stats DeliveryStats
+	// mutex protects the counters.
+	mutex sync.Mutex
+}
+
+// NewDeliveryCounters creates a new DeliveryCounters.
+func NewDeliveryCounters() *DeliveryCounters {
+	return &DeliveryCounters{
+		stats: DeliveryStats{Dropped: make(map[string]int64)},
+	}
+}
+
+// Published counts a published message.
+func (c *DeliveryCounters) Published(topic string, latency time.Duration, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stats.Published++
+	c.stats.PublishLatency += latency
+	if err != nil {
+		c.stats.PublishFailures++
+	}
+}
+
+// Handled counts a handler call.
+func (c *DeliveryCounters) Handled(topic string, subscriberID string, latency time.Duration, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stats.Handled++
+	c.stats.HandleLatency += latency
+	if err != nil {
+		c.stats.HandleFailures++
+	}
+}
+
+// Dropped counts a dropped message.
+func (c *DeliveryCounters) Dropped(topic string, reason string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stats.Dropped[reason]++
+}
+
+// DeadLettered counts a message moved to the dead-letter topic.
+func (c *DeliveryCounters) DeadLettered(topic string, subscriberID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stats.DeadLettered++
+}
+
+// Stats returns a copy of the counters.
+func (c *DeliveryCounters) Stats() DeliveryStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Dropped = make(map[string]int64, len(c.stats.Dropped))
+	for reason, n := range c.stats.Dropped {
+		stats.Dropped[reason] = n
+	}
+	return stats
+}
+
+// DeliveryOptions represents configuration options for DeliveryPubSub.
+type DeliveryOptions struct {
+	// Metrics receives the delivery events, or is nil.
+	Metrics DeliveryMetrics
+	// MaxAttempts is the number of times a handler is called for a message before
+	// the message is moved to the dead-letter topic.
+	MaxAttempts int
+	// RetryDelay is how long to wait before calling a failed handler again.
+	RetryDelay time.Duration
+	// DeadLetterSuffix is appended to a topic to name its dead-letter topic.
+	DeadLetterSuffix string
+}
+
+// NewDeliveryOptions creates a new DeliveryOptions with default values.
+func NewDeliveryOptions() *DeliveryOptions {
+	return &DeliveryOptions{
+		MaxAttempts:      3,
+		RetryDelay:       100 * time.Millisecond,
+		DeadLetterSuffix: ".dead-letter",
+	}
+}
+
+// DeadLetter represents a message whose handler failed MaxAttempts times.
+type DeadLetter struct {
+	// ID identifies the dead letter in the DeliveryPubSub.
+	ID uint64
+	// Topic is the topic the message was published to.
+	Topic string
+	// SubscriberID is the subscriber whose handler failed.
+	SubscriberID string
+	// Payload is the encoded patch data.
+	Payload []byte
+	// Format is the encoding format used for the payload.
+	Format EncodingFormat
+	// Err is the error of the last attempt.
+	Err string
+	// Time is when the message was moved to the dead-letter topic.
+	Time time.Time
+}
+
+// DeliveryPubSub wraps a PubSub, reports its publish and handle latencies and
+// failures to DeliveryMetrics, and retries failed handlers. A message whose handler
+// keeps failing is published to the dead-letter topic of its topic and kept as a
+// DeadLetter until it is requeued.
+type DeliveryPubSub struct {
+	// inner is the wrapped PubSub.
+	inner PubSub
+	// deliveryOptions contains the delivery configuration options.
+	deliveryOptions *DeliveryOptions
+	// deadLetters are the dead letters, in the order they were added.
+	deadLetters []DeadLetter
+	// lastID is the ID of the last dead letter.
+	lastID uint64
+	// mutex protects the dead letters.
+	mutex sync.Mutex
+	// closed indicates whether the PubSub has been closed.
+	closed bool
+}
+
+// NewDeliveryPubSub creates a new DeliveryPubSub wrapping the specified PubSub.
+func NewDeliveryPubSub(inner PubSub, deliveryOptions *DeliveryOptions) (*DeliveryPubSub, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("inner pubsub cannot be nil")
+	}
+
+	if deliveryOptions == nil {
+		deliveryOptions = NewDeliveryOptions()
+	}
+
+	return &DeliveryPubSub{
+		inner:           inner,
+		deliveryOptions: deliveryOptions,
+	}, nil
+}
+
+// Publish publishes a patch to the specified topic.
+func (ps *DeliveryPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	start := time.Now()
+	err := ps.inner.Publish(ctx, topic, patch, format)
+	ps.published(topic, start, err)
+	return err
+}
+
+// PublishRaw publishes raw data to the specified topic.
+func (ps *DeliveryPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	start := time.Now()
+	err := ps.inner.PublishRaw(ctx, topic, data, format)
+	ps.published(topic, start, err)
+	return err
+}
+
+// published reports a published message.
+func (ps *DeliveryPubSub) published(topic string, start time.Time, err error) {
+	if ps.deliveryOptions.Metrics != nil {
+		ps.deliveryOptions.Metrics.Published(topic, time.Since(start), err)
+	}
+}
+
+// Subscribe subscribes to the specified topic of the inner PubSub.
+// This method implements the Subscriber interface.
+func (ps *DeliveryPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.Subscribe(ctx, topic, subscriberID, ps.deliver(subscriberID, handler))
+}
+
+// SubscribeSince subscribes to the specified topic of the inner PubSub, replaying the
+// messages retained after the cursor.
+// This method implements the Subscriber interface.
+func (ps *DeliveryPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.SubscribeSince(ctx, topic, subscriberID, cursor, ps.deliver(subscriberID, handler))
+}
+
+// deliver returns a handler calling handler up to MaxAttempts times and moving the
+// message to the dead-letter topic if it keeps failing. The message is then
+// acknowledged, so that the transport does not deliver it again.
+func (ps *DeliveryPubSub) deliver(subscriberID string, handler SubscriberFunc) SubscriberFunc {
+	return func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		var err error
+		for attempt := 1; ; attempt++ {
+			start := time.Now()
+			err = handler(ctx, topic, data, format)
+			if ps.deliveryOptions.Metrics != nil {
+				ps.deliveryOptions.Metrics.Handled(topic, subscriberID, time.Since(start), err)
+			}
+			if err == nil || attempt >= ps.deliveryOptions.MaxAttempts {
+				break
+			}
+
+			timer := time.NewTimer(ps.deliveryOptions.RetryDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err == nil {
+			return nil
+		}
+
+		if deadErr := ps.deadLetter(ctx, topic, subscriberID, data, format, err); deadErr != nil {
+			// 데드 레터로 옮기지 못한 메시지는 전송 계층이 다시 전달하도록 오류 반환
+			return fmt.Errorf("failed to move message to dead-letter topic: %v: %w", deadErr, err)
+		}
+		return nil
+	}
+}
+
+// deadLetter publishes a message to the dead-letter topic of its topic and keeps it.
+func (ps *DeliveryPubSub) deadLetter(ctx context.Context, topic string, subscriberID string, data []byte, format EncodingFormat, handleErr error) error {
+	if err := ps.inner.PublishRaw(ctx, ps.DeadLetterTopic(topic), data, format); err != nil {
+		return err
+	}
+
+	ps.mutex.Lock()
+	ps.lastID++
+	ps.deadLetters = append(ps.deadLetters, DeadLetter{
+		ID:           ps.lastID,
+		Topic:        topic,
+		SubscriberID: subscriberID,
+		Payload:      append([]byte(nil), data...),
+		Format:       format,
+		Err:          handleErr.Error(),
+		Time:         time.Now(),
+	})
+	ps.mutex.Unlock()
+
+	if ps.deliveryOptions.Metrics != nil {
+		ps.deliveryOptions.Metrics.DeadLettered(topic, subscriberID)
+	}
+	return nil
+}
+
+// DeadLetterTopic returns the dead-letter topic of the specified topic.
+func (ps *DeliveryPubSub) DeadLetterTopic(topic string) string {
+	return topic + ps.deliveryOptions.DeadLetterSuffix
+}
+
+// DeadLetters returns the dead letters of the specified topic, or of every topic if
+// topic is empty, in the order they were added.
+func (ps *DeliveryPubSub) DeadLetters(topic string) []DeadLetter {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	var deadLetters []DeadLetter
+	for _, deadLetter := range ps.deadLetters {
+		if topic == "" || deadLetter.Topic == topic {
+			deadLetters = append(deadLetters, deadLetter)
+		}
+	}
+	return deadLetters
+}
+
+// Requeue publishes the dead letters with the specified IDs to their topic again and
+// removes them, e.g. once the cause of the failures is fixed. Every subscriber of the
+// topic receives them again. Without IDs, every dead letter is requeued. It returns the
+// number of requeued dead letters.
+func (ps *DeliveryPubSub) Requeue(ctx context.Context, ids ...uint64) (int, error) {
+	if ps.closed {
+		return 0, fmt.Errorf("pubsub is closed")
+	}
+
+	selected := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	requeued := 0
+	for _, deadLetter := range ps.DeadLetters("") {
+		if len(ids) > 0 && !selected[deadLetter.ID] {
+			continue
+		}
+		if err := ps.PublishRaw(ctx, deadLetter.Topic, deadLetter.Payload, deadLetter.Format); err != nil {
+			return requeued, fmt.Errorf("failed to requeue dead letter %d: %w", deadLetter.ID, err)
+		}
+		ps.removeDeadLetter(deadLetter.ID)
+		requeued++
+	}
+	return requeued, nil
+}
+
+// removeDeadLetter removes the dead letter with the specified ID.
+func (ps *DeliveryPubSub) removeDeadLetter(id uint64) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	for i, deadLetter := range ps.deadLetters {
+		if deadLetter.ID == id {
+			ps.deadLetters = append(ps.deadLetters[:i], ps.deadLetters[i+1:]...)
+			return
+		}
+	}
+}
+
+// SubscribePattern subscribes to every topic of the inner PubSub matching the pattern.
+// This method implements the Subscriber interface.
+func (ps *DeliveryPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.SubscribePattern(ctx, pattern, subscriberID, ps.deliver(subscriberID, handler))
+}
+
+// Unsubscribe unsubscribes from the specified topic of the inner PubSub.
+// This method implements the Subscriber interface.
+func (ps *DeliveryPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.Unsubscribe(ctx, topic, subscriberID)
+}
+
+// Close closes the inner PubSub.
+func (ps *DeliveryPubSub) Close() error {
+	if ps.closed {
+		return nil
+	}
+
+	ps.closed = true
+	return ps.inner.Close()
+}
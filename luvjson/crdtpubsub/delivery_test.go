@@ -0,0 +1,98 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeliveryPubSubDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counters := NewDeliveryCounters()
+	options := NewOptions()
+	options.Metrics = counters
+	inner, err := NewMemoryPubSub(options)
+	if err != nil {
+		t.Fatalf("Failed to create Memory PubSub: %v", err)
+	}
+	deliveryOptions := NewDeliveryOptions()
+	deliveryOptions.Metrics = counters
+	deliveryOptions.MaxAttempts = 2
+	deliveryOptions.RetryDelay = time.Millisecond
+	pubsub, err := NewDeliveryPubSub(inner, deliveryOptions)
+	if err != nil {
+		t.Fatalf("Failed to create delivery PubSub: %v", err)
+	}
+	defer pubsub.Close()
+
+	// A message published without subscribers is dropped
+	if err := pubsub.PublishRaw(ctx, "doc", []byte("lost"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if dropped := counters.Stats().Dropped["no subscribers"]; dropped != 1 {
+		t.Errorf("Expected 1 dropped message, got %d", dropped)
+	}
+
+	var failing atomic.Bool
+	failing.Store(true)
+	handled := make(chan string, 10)
+	if err := pubsub.Subscribe(ctx, "doc", "applier", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		if failing.Load() {
+			return fmt.Errorf("cannot apply patch")
+		}
+		handled <- string(data)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	deadLettered := make(chan string, 10)
+	if err := inner.Subscribe(ctx, pubsub.DeadLetterTopic("doc"), "admin", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		deadLettered <- string(data)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe to dead-letter topic: %v", err)
+	}
+
+	if err := pubsub.PublishRaw(ctx, "doc", []byte("patch"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	select {
+	case data := <-deadLettered:
+		if data != "patch" {
+			t.Errorf("Expected patch in the dead-letter topic, got %s", data)
+		}
+	case <-ctx.Done():
+		t.Fatalf("Timed out waiting for the dead letter")
+	}
+
+	deadLetters := pubsub.DeadLetters("doc")
+	if len(deadLetters) != 1 || deadLetters[0].SubscriberID != "applier" || deadLetters[0].Err != "cannot apply patch" {
+		t.Fatalf("Unexpected dead letters: %+v", deadLetters)
+	}
+	stats := counters.Stats()
+	if stats.DeadLettered != 1 || stats.HandleFailures < 2 {
+		t.Errorf("Expected 1 dead letter and 2 failures, got %d and %d", stats.DeadLettered, stats.HandleFailures)
+	}
+
+	// Requeued dead letters are delivered again
+	failing.Store(false)
+	requeued, err := pubsub.Requeue(ctx, deadLetters[0].ID)
+	if err != nil || requeued != 1 {
+		t.Fatalf("Failed to requeue: %d, %v", requeued, err)
+	}
+	select {
+	case data := <-handled:
+		if data != "patch" {
+			t.Errorf("Expected patch, got %s", data)
+		}
+	case <-ctx.Done():
+		t.Fatalf("Timed out waiting for the requeued message")
+	}
+	if deadLetters := pubsub.DeadLetters(""); len(deadLetters) != 0 {
+		t.Errorf("Expected no dead letters after requeue, got %d", len(deadLetters))
+	}
+}
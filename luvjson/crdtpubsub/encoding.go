@@ -128,6 +128,40 @@ func (ed *Base64EncoderDecoder) Decode(data []byte) (*crdtpatch.Patch, error) {
 	return ed.underlying.Decode(decoded[:n])
 }
 
+// CBOREncoderDecoder implements the EncoderDecoder interface using CBOR encoding.
+type CBOREncoderDecoder struct{}
+
+// Encode encodes a CRDT patch into a CBOR byte array.
+func (ed *CBOREncoderDecoder) Encode(patch *crdtpatch.Patch) ([]byte, error) {
+	return patch.MarshalCBOR()
+}
+
+// Decode decodes a CBOR byte array into a CRDT patch.
+func (ed *CBOREncoderDecoder) Decode(data []byte) (*crdtpatch.Patch, error) {
+	var patch crdtpatch.Patch
+	if err := patch.UnmarshalCBOR(data); err != nil {
+		return nil, err
+	}
+	return &patch, nil
+}
+
+// MsgPackEncoderDecoder implements the EncoderDecoder interface using MessagePack encoding.
+type MsgPackEncoderDecoder struct{}
+
+// Encode encodes a CRDT patch into a MessagePack byte array.
+func (ed *MsgPackEncoderDecoder) Encode(patch *crdtpatch.Patch) ([]byte, error) {
+	return patch.MarshalMsgpack()
+}
+
+// Decode decodes a MessagePack byte array into a CRDT patch.
+func (ed *MsgPackEncoderDecoder) Decode(data []byte) (*crdtpatch.Patch, error) {
+	var patch crdtpatch.Patch
+	if err := patch.UnmarshalMsgpack(data); err != nil {
+		return nil, err
+	}
+	return &patch, nil
+}
+
 // GetEncoderDecoder returns an EncoderDecoder for the specified format.
 func GetEncoderDecoder(format EncodingFormat) (EncoderDecoder, error) {
 	switch format {
@@ -139,6 +173,10 @@ func GetEncoderDecoder(format EncodingFormat) (EncoderDecoder, error) {
 		return &TextEncoderDecoder{}, nil
 	case EncodingFormatBase64:
 		return NewBase64EncoderDecoder(&JSONEncoderDecoder{}), nil
+	case EncodingFormatCBOR:
+		return &CBOREncoderDecoder{}, nil
+	case EncodingFormatMsgPack:
+		return &MsgPackEncoderDecoder{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported encoding format: %s", format)
 	}
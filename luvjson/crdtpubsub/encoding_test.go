@@ -121,6 +121,8 @@ func TestGetEncoderDecoder(t *testing.T) {
 		{EncodingFormatBinary, "*crdtpubsub.BinaryEncoderDecoder", false, ""},
 		{EncodingFormatText, "*crdtpubsub.TextEncoderDecoder", false, ""},
 		{EncodingFormatBase64, "*crdtpubsub.Base64EncoderDecoder", false, ""},
+		{EncodingFormatCBOR, "*crdtpubsub.CBOREncoderDecoder", false, ""},
+		{EncodingFormatMsgPack, "*crdtpubsub.MsgPackEncoderDecoder", false, ""},
 		{"invalid-format", "", true, "unsupported encoding format"},
 	}
 
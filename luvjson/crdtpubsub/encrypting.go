@@ -0,0 +1,345 @@
+package crdtpubsub
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"tictactoe/luvjson/crdtpatch"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// sealedVersion is the first byte of a sealed payload.
+const sealedVersion byte = 1
+
+// Keyring holds the symmetric keys of the documents, one topic per document.
+// A topic may have several keys: the current key seals new messages and the
+// older keys open the messages sealed before a rotation.
+type Keyring interface {
+	// CurrentKey returns the ID and the key sealing new messages of the topic.
+	CurrentKey(ctx context.Context, topic string) (string, []byte, error)
+	// Key returns the key of the topic with the specified ID.
+	Key(ctx context.Context, topic string, keyID string) ([]byte, error)
+}
+
+// MemoryKeyring is an in-memory Keyring.
+type MemoryKeyring struct {
+	// topics is a map of topic to keys.
+	topics map[string]*topicKeys
+	// mutex protects the topics map.
+	mutex sync.RWMutex
+}
+
+// topicKeys represents the keys of a topic.
+type topicKeys struct {
+	// current is the ID of the current key.
+	current string
+	// keys is a map of key ID to key.
+	keys map[string][]byte
+	// rotations is the number of keys generated by Rotate.
+	rotations int
+}
+
+// NewMemoryKeyring creates a new empty MemoryKeyring.
+func NewMemoryKeyring() *MemoryKeyring {
+	return &MemoryKeyring{
+		topics: make(map[string]*topicKeys),
+	}
+}
+
+// AddKey adds a key of chacha20poly1305.KeySize bytes to the topic and makes it the current key.
+func (k *MemoryKeyring) AddKey(topic string, keyID string, key []byte) error {
+	if len(key) != chacha20poly1305.KeySize {
+		return fmt.Errorf("invalid key size: %d, expected %d", len(key), chacha20poly1305.KeySize)
+	}
+	if keyID == "" || len(keyID) > 255 {
+		return fmt.Errorf("invalid key ID: %q", keyID)
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	keys := k.keys(topic)
+	keys.keys[keyID] = append([]byte(nil), key...)
+	keys.current = keyID
+	return nil
+}
+
+// Rotate generates a random key for the topic, makes it the current key and returns its ID.
+// The previous keys are kept to open the messages sealed with them.
+func (k *MemoryKeyring) Rotate(topic string) (string, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	keys := k.keys(topic)
+	for {
+		keys.rotations++
+		keyID := "k" + strconv.Itoa(keys.rotations)
+		if _, ok := keys.keys[keyID]; ok {
+			continue
+		}
+		keys.keys[keyID] = key
+		keys.current = keyID
+		return keyID, nil
+	}
+}
+
+// RemoveKey removes a key of the topic, e.g. once the messages sealed with it have expired.
+// The current key cannot be removed.
+func (k *MemoryKeyring) RemoveKey(topic string, keyID string) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	keys, ok := k.topics[topic]
+	if !ok {
+		return nil
+	}
+	if keys.current == keyID {
+		return fmt.Errorf("cannot remove the current key %s of topic %s", keyID, topic)
+	}
+	delete(keys.keys, keyID)
+	return nil
+}
+
+// keys returns the keys of a topic, creating them if needed. The caller must hold the mutex.
+func (k *MemoryKeyring) keys(topic string) *topicKeys {
+	keys, ok := k.topics[topic]
+	if !ok {
+		keys = &topicKeys{keys: make(map[string][]byte)}
+		k.topics[topic] = keys
+	}
+	return keys
+}
+
+// CurrentKey returns the ID and the key sealing new messages of the topic.
+func (k *MemoryKeyring) CurrentKey(ctx context.Context, topic string) (string, []byte, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	keys, ok := k.topics[topic]
+	if !ok || keys.current == "" {
+		return "", nil, fmt.Errorf("no key for topic: %s", topic)
+	}
+	return keys.current, keys.keys[keys.current], nil
+}
+
+// Key returns the key of the topic with the specified ID.
+func (k *MemoryKeyring) Key(ctx context.Context, topic string, keyID string) ([]byte, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	keys, ok := k.topics[topic]
+	if !ok {
+		return nil, fmt.Errorf("no key for topic: %s", topic)
+	}
+	key, ok := keys.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s for topic: %s", keyID, topic)
+	}
+	return key, nil
+}
+
+// EncryptingPubSub wraps a PubSub and seals the payloads with the key of their topic
+// using XChaCha20-Poly1305, so that the broker and the relays never see the patches.
+// A sealed payload holds the ID of its key, so messages sealed before a key rotation
+// can still be opened, and is bound to its topic and format.
+type EncryptingPubSub struct {
+	// inner is the wrapped PubSub.
+	inner PubSub
+	// keyring holds the keys of the topics.
+	keyring Keyring
+	// closed indicates whether the PubSub has been closed.
+	closed bool
+}
+
+// NewEncryptingPubSub creates a new EncryptingPubSub wrapping the specified PubSub.
+func NewEncryptingPubSub(inner PubSub, keyring Keyring) (*EncryptingPubSub, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("inner pubsub cannot be nil")
+	}
+	if keyring == nil {
+		return nil, fmt.Errorf("keyring cannot be nil")
+	}
+
+	return &EncryptingPubSub{
+		inner:   inner,
+		keyring: keyring,
+	}, nil
+}
+
+// Publish encodes a patch and publishes it sealed to the specified topic.
+func (ps *EncryptingPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = NewOptions().DefaultFormat
+	}
+
+	// Get the encoder for the format
+	encoder, err := GetEncoderDecoder(format)
+	if err != nil {
+		return err
+	}
+
+	// Encode the patch
+	data, err := encoder.Encode(patch)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	return ps.PublishRaw(ctx, topic, data, format)
+}
+
+// PublishRaw publishes raw data sealed to the specified topic.
+func (ps *EncryptingPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// 형식은 봉인에 포함되므로 내부 PubSub이 기본 형식을 정하지 않도록 여기서 정함
+	if format == "" {
+		format = NewOptions().DefaultFormat
+	}
+
+	sealed, err := ps.seal(ctx, topic, data, format)
+	if err != nil {
+		return err
+	}
+	return ps.inner.PublishRaw(ctx, topic, sealed, format)
+}
+
+// seal encrypts data with the current key of the topic.
+// The result is the version, the key ID length and key ID, the nonce and the ciphertext.
+func (ps *EncryptingPubSub) seal(ctx context.Context, topic string, data []byte, format EncodingFormat) ([]byte, error) {
+	keyID, key, err := ps.keyring.CurrentKey(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	if len(keyID) == 0 || len(keyID) > 255 {
+		return nil, fmt.Errorf("invalid key ID: %q", keyID)
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	header := make([]byte, 0, 2+len(keyID)+aead.NonceSize())
+	header = append(header, sealedVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header = append(header, nonce...)
+
+	return aead.Seal(header, nonce, data, sealedData(topic, format, header[:2+len(keyID)])), nil
+}
+
+// open decrypts a payload sealed by seal.
+func (ps *EncryptingPubSub) open(ctx context.Context, topic string, sealed []byte, format EncodingFormat) ([]byte, error) {
+	if len(sealed) < 2 || sealed[0] != sealedVersion {
+		return nil, fmt.Errorf("payload is not sealed")
+	}
+	idEnd := 2 + int(sealed[1])
+	nonceEnd := idEnd + chacha20poly1305.NonceSizeX
+	if len(sealed) < nonceEnd+chacha20poly1305.Overhead {
+		return nil, fmt.Errorf("sealed payload is too short")
+	}
+
+	key, err := ps.keyring.Key(ctx, topic, string(sealed[2:idEnd]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	data, err := aead.Open(nil, sealed[idEnd:nonceEnd], sealed[nonceEnd:], sealedData(topic, format, sealed[:idEnd]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return data, nil
+}
+
+// sealedData returns the additional data authenticated with a payload, so that a
+// sealed payload cannot be passed off as a message of another topic or format.
+func sealedData(topic string, format EncodingFormat, header []byte) []byte {
+	data := make([]byte, 0, len(header)+len(topic)+len(format)+2)
+	data = append(data, header...)
+	data = append(data, topic...)
+	data = append(data, 0)
+	data = append(data, format...)
+	return data
+}
+
+// opener returns a handler opening the payloads before calling handler.
+func (ps *EncryptingPubSub) opener(handler SubscriberFunc) SubscriberFunc {
+	return func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		plain, err := ps.open(ctx, topic, data, format)
+		if err != nil {
+			return fmt.Errorf("failed to open message of topic %s: %w", topic, err)
+		}
+		return handler(ctx, topic, plain, format)
+	}
+}
+
+// Subscribe subscribes to the specified topic of the inner PubSub and calls the
+// handler with the opened payloads.
+// This method implements the Subscriber interface.
+func (ps *EncryptingPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.Subscribe(ctx, topic, subscriberID, ps.opener(handler))
+}
+
+// SubscribeSince subscribes to the specified topic of the inner PubSub, replaying the
+// messages retained after the cursor, and calls the handler with the opened payloads.
+// This method implements the Subscriber interface.
+func (ps *EncryptingPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.SubscribeSince(ctx, topic, subscriberID, cursor, ps.opener(handler))
+}
+
+// SubscribePattern subscribes to every topic of the inner PubSub matching the pattern.
+// This method implements the Subscriber interface.
+func (ps *EncryptingPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.SubscribePattern(ctx, pattern, subscriberID, ps.opener(handler))
+}
+
+// Unsubscribe unsubscribes from the specified topic of the inner PubSub.
+// This method implements the Subscriber interface.
+func (ps *EncryptingPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return ps.inner.Unsubscribe(ctx, topic, subscriberID)
+}
+
+// Close closes the inner PubSub.
+func (ps *EncryptingPubSub) Close() error {
+	if ps.closed {
+		return nil
+	}
+
+	ps.closed = true
+	return ps.inner.Close()
+}
@@ -0,0 +1,83 @@
+package crdtpubsub
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEncryptingPubSub(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	inner, err := NewMemoryPubSub(NewOptions())
+	if err != nil {
+		t.Fatalf("Failed to create Memory PubSub: %v", err)
+	}
+	keyring := NewMemoryKeyring()
+	key := bytes.Repeat([]byte{7}, 32)
+	if err := keyring.AddKey("doc", "k1", key); err != nil {
+		t.Fatalf("Failed to add key: %v", err)
+	}
+	pubsub, err := NewEncryptingPubSub(inner, keyring)
+	if err != nil {
+		t.Fatalf("Failed to create encrypting PubSub: %v", err)
+	}
+	defer pubsub.Close()
+
+	// The broker only sees sealed payloads
+	sealed := make(chan []byte, 2)
+	if err := inner.Subscribe(ctx, "doc", "broker", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		sealed <- data
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	opened := make(chan []byte, 2)
+	if err := pubsub.Subscribe(ctx, "doc", "client", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		opened <- data
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	plain := []byte(`{"hp":100}`)
+	if err := pubsub.PublishRaw(ctx, "doc", plain, EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	var first []byte
+	select {
+	case first = <-sealed:
+		if bytes.Contains(first, plain) {
+			t.Errorf("Sealed payload contains the plaintext")
+		}
+	case <-ctx.Done():
+		t.Fatalf("Timed out waiting for the sealed payload")
+	}
+	select {
+	case data := <-opened:
+		if !bytes.Equal(data, plain) {
+			t.Errorf("Expected %s, got %s", plain, data)
+		}
+	case <-ctx.Done():
+		t.Fatalf("Timed out waiting for the opened payload")
+	}
+
+	// Messages sealed before a rotation can still be opened
+	if _, err := keyring.Rotate("doc"); err != nil {
+		t.Fatalf("Failed to rotate key: %v", err)
+	}
+	data, err := pubsub.open(ctx, "doc", first, EncodingFormatJSON)
+	if err != nil || !bytes.Equal(data, plain) {
+		t.Errorf("Failed to open payload sealed with the previous key: %v", err)
+	}
+
+	// A payload is bound to its topic, even if another topic has the same key
+	if err := keyring.AddKey("other", "k1", key); err != nil {
+		t.Fatalf("Failed to add key: %v", err)
+	}
+	if _, err := pubsub.open(ctx, "other", first, EncodingFormatJSON); err == nil {
+		t.Errorf("Expected an error opening a payload of another topic")
+	}
+}
@@ -0,0 +1,404 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaOptions represents configuration options for KafkaPubSub.
+type KafkaOptions struct {
+	// Brokers are the addresses of the Kafka brokers.
+	Brokers []string
+	// Topic is the Kafka topic holding the patches of every document.
+	Topic string
+	// StartOffset is where a new consumer group starts: kafka.FirstOffset for the
+	// whole topic or kafka.LastOffset for new messages only.
+	StartOffset int64
+	// MaxWait is how long a fetch waits for new messages.
+	MaxWait time.Duration
+	// MaxRetries is the number of times a message whose handler failed is handled
+	// again before it is skipped, and the number of times a failed commit is retried.
+	MaxRetries int
+	// RetryDelay is how long to wait before handling a message again.
+	RetryDelay time.Duration
+}
+
+// NewKafkaOptions creates a new KafkaOptions with default values.
+func NewKafkaOptions(brokers ...string) *KafkaOptions {
+	return &KafkaOptions{
+		Brokers:     brokers,
+		Topic:       "crdt-patches",
+		StartOffset: kafka.FirstOffset,
+		MaxWait:     time.Second,
+		MaxRetries:  3,
+		RetryDelay:  time.Second,
+	}
+}
+
+// KafkaPubSub implements the PubSub interface using Kafka.
+// All documents share one Kafka topic and the PubSub topic, the document ID, is
+// the message key, so the patches of a document go to one partition and keep
+// their order. Each subscriber ID is a consumer group: processes subscribing with
+// the same ID share the partitions, which scales the appliers horizontally.
+type KafkaPubSub struct {
+	// writer is the Kafka writer.
+	writer *kafka.Writer
+	// options contains the configuration options.
+	options *Options
+	// kafkaOptions contains the Kafka configuration options.
+	kafkaOptions *KafkaOptions
+	// groups is a map of subscriber ID to consumer group.
+	groups map[string]*kafkaGroup
+	// mutex protects the groups map.
+	mutex sync.RWMutex
+	// closed indicates whether the PubSub has been closed.
+	closed bool
+	// commit commits the offset of a handled message.
+	commit func(ctx context.Context, reader *kafka.Reader, msg kafka.Message) error
+}
+
+// kafkaGroup represents the subscriptions of a consumer group.
+type kafkaGroup struct {
+	// reader is the Kafka reader of the group.
+	reader *kafka.Reader
+	// handlers is a map of topic or topic pattern to handler; "" handles every topic.
+	handlers map[string]SubscriberFunc
+	// mutex protects the handlers map.
+	mutex sync.RWMutex
+	// ctx is the context for the group.
+	ctx context.Context
+	// cancel is the cancel function for the context.
+	cancel context.CancelFunc
+	// done is a channel that is closed when the group is done.
+	done chan struct{}
+}
+
+// NewKafkaPubSub creates a new KafkaPubSub with the specified options.
+func NewKafkaPubSub(options *Options, kafkaOptions *KafkaOptions) (*KafkaPubSub, error) {
+	if kafkaOptions == nil || len(kafkaOptions.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers cannot be empty")
+	}
+
+	if options == nil {
+		options = NewOptions()
+	}
+
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  kafkaOptions.Brokers,
+		Topic:    kafkaOptions.Topic,
+		Balancer: &kafka.Hash{},
+		// 메시지가 저장된 후에 반환
+		RequiredAcks: -1,
+	})
+
+	return &KafkaPubSub{
+		writer:       writer,
+		options:      options,
+		kafkaOptions: kafkaOptions,
+		groups:       make(map[string]*kafkaGroup),
+		commit: func(ctx context.Context, reader *kafka.Reader, msg kafka.Message) error {
+			return reader.CommitMessages(ctx, msg)
+		},
+	}, nil
+}
+
+// Publish publishes a patch to the specified topic.
+func (ps *KafkaPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Get the encoder for the format
+	encoder, err := GetEncoderDecoder(format)
+	if err != nil {
+		return err
+	}
+
+	// Encode the patch
+	data, err := encoder.Encode(patch)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	return ps.PublishRaw(ctx, topic, data, format)
+}
+
+// PublishRaw publishes raw data to the specified topic.
+func (ps *KafkaPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:     []byte(topic),
+		Value:   data,
+		Headers: []kafka.Header{{Key: "format", Value: []byte(format)}},
+	}
+	if cursor != "" {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: cursorMetadataKey, Value: []byte(cursor)})
+	}
+	if err := ps.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the specified topic and calls the handler for each received message.
+// The subscriber ID is the consumer group. A group receives the messages of the partitions
+// assigned to it and skips those of topics it has no handler for, so processes sharing a
+// subscriber ID should subscribe to the same topics, or use SubscribeAll.
+// This method implements the Subscriber interface.
+func (ps *KafkaPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	group, ok := ps.groups[subscriberID]
+	if !ok {
+		group = ps.newGroup(ctx, subscriberID)
+		ps.groups[subscriberID] = group
+		go ps.consume(group)
+	}
+
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	if _, ok := group.handlers[topic]; ok {
+		return fmt.Errorf("already subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+	group.handlers[topic] = handler
+	return nil
+}
+
+// SubscribeSince subscribes to the specified topic and first calls the handler for the
+// retained messages published after the cursor.
+// This method implements the Subscriber interface.
+func (ps *KafkaPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return SubscribeWithReplay(ctx, ps, ps.options.RetentionStore, topic, subscriberID, cursor, handler)
+}
+
+// SubscribePattern subscribes to every topic matching the pattern. Since all topics share
+// the Kafka topic, the group receives every message and skips those not matching.
+// This method implements the Subscriber interface.
+func (ps *KafkaPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	return ps.Subscribe(ctx, pattern, subscriberID, handler)
+}
+
+// SubscribeAll subscribes to every topic and calls the handler for each received message,
+// e.g. in appliers that share the documents among the processes of a consumer group.
+func (ps *KafkaPubSub) SubscribeAll(ctx context.Context, subscriberID string, handler SubscriberFunc) error {
+	return ps.Subscribe(ctx, "", subscriberID, handler)
+}
+
+// newGroup creates the reader of a consumer group.
+func (ps *KafkaPubSub) newGroup(ctx context.Context, subscriberID string) *kafkaGroup {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     ps.kafkaOptions.Brokers,
+		GroupID:     subscriberID,
+		Topic:       ps.kafkaOptions.Topic,
+		MaxWait:     ps.kafkaOptions.MaxWait,
+		StartOffset: ps.kafkaOptions.StartOffset,
+	})
+
+	groupCtx, cancel := context.WithCancel(ctx)
+	return &kafkaGroup{
+		reader:   reader,
+		handlers: make(map[string]SubscriberFunc),
+		ctx:      groupCtx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// consume reads the messages of a consumer group until it is cancelled.
+// Messages are committed once they are handled, so a group that restarts
+// resumes after the last handled message.
+func (ps *KafkaPubSub) consume(group *kafkaGroup) {
+	defer close(group.done)
+
+	for {
+		msg, err := group.reader.FetchMessage(group.ctx)
+		if err != nil {
+			if group.ctx.Err() != nil {
+				return
+			}
+			fmt.Printf("failed to fetch message: %v\n", err)
+			ps.wait(group, ps.kafkaOptions.RetryDelay)
+			continue
+		}
+
+		ps.handle(group, msg)
+		ps.commitMessage(group, msg)
+	}
+}
+
+// commitMessage commits the offset of a handled message. A failed commit is
+// retried, since the group would otherwise handle the message again when it
+// restarts, unless a later message of the partition is committed first.
+func (ps *KafkaPubSub) commitMessage(group *kafkaGroup, msg kafka.Message) {
+	for attempt := 0; ; attempt++ {
+		err := ps.commit(group.ctx, group.reader, msg)
+		if err == nil || group.ctx.Err() != nil {
+			return
+		}
+		if attempt >= ps.kafkaOptions.MaxRetries {
+			// Log the error and leave the message to be handled again
+			fmt.Printf("failed to commit message at offset %d of partition %d: %v\n", msg.Offset, msg.Partition, err)
+			return
+		}
+		ps.wait(group, ps.kafkaOptions.RetryDelay)
+	}
+}
+
+// handle calls the handlers of a group for a message. A failed handler is
+// retried in place, since later messages of the partition must wait for it.
+func (ps *KafkaPubSub) handle(group *kafkaGroup, msg kafka.Message) {
+	topic := string(msg.Key)
+	var format EncodingFormat
+	ctx := group.ctx
+	for _, header := range msg.Headers {
+		switch header.Key {
+		case "format":
+			format = EncodingFormat(header.Value)
+		case cursorMetadataKey:
+			ctx = withCursor(ctx, string(header.Value))
+		}
+	}
+
+	group.mutex.RLock()
+	handlers := make([]SubscriberFunc, 0, 2)
+	for _, key := range []string{topic, ""} {
+		if handler, ok := group.handlers[key]; ok {
+			handlers = append(handlers, handler)
+		}
+	}
+	for key, handler := range group.handlers {
+		if key != topic && IsTopicPattern(key) && MatchTopic(key, topic) {
+			handlers = append(handlers, handler)
+		}
+	}
+	group.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		for attempt := 0; ; attempt++ {
+			err := handler(ctx, topic, msg.Value, format)
+			if err == nil || group.ctx.Err() != nil {
+				break
+			}
+			if attempt >= ps.kafkaOptions.MaxRetries {
+				// Log the error and skip the message
+				fmt.Printf("failed to handle message at offset %d of partition %d: %v\n", msg.Offset, msg.Partition, err)
+				break
+			}
+			ps.wait(group, ps.kafkaOptions.RetryDelay)
+		}
+	}
+}
+
+// wait waits for the specified duration or until the group is cancelled.
+func (ps *KafkaPubSub) wait(group *kafkaGroup, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-group.ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// Unsubscribe unsubscribes from the specified topic.
+// The reader of the consumer group is closed when its last topic is unsubscribed.
+// This method implements the Subscriber interface.
+func (ps *KafkaPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// Check if subscribed with this subscriberID
+	group, ok := ps.groups[subscriberID]
+	if !ok {
+		return fmt.Errorf("not subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+	group.mutex.Lock()
+	_, ok = group.handlers[topic]
+	delete(group.handlers, topic)
+	remaining := len(group.handlers)
+	group.mutex.Unlock()
+	if !ok {
+		return fmt.Errorf("not subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+
+	if remaining == 0 {
+		delete(ps.groups, subscriberID)
+		return ps.closeGroup(group)
+	}
+	return nil
+}
+
+// closeGroup stops a consumer group and closes its reader.
+func (ps *KafkaPubSub) closeGroup(group *kafkaGroup) error {
+	group.cancel()
+	<-group.done
+	if err := group.reader.Close(); err != nil {
+		return fmt.Errorf("failed to close Kafka reader: %w", err)
+	}
+	return nil
+}
+
+// Close closes the PubSub.
+func (ps *KafkaPubSub) Close() error {
+	if ps.closed {
+		return nil
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// Mark as closed
+	ps.closed = true
+
+	// Stop all consumer groups
+	var firstErr error
+	for subscriberID, group := range ps.groups {
+		if err := ps.closeGroup(group); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(ps.groups, subscriberID)
+	}
+
+	// Close the Kafka writer
+	if err := ps.writer.Close(); err != nil && firstErr == nil {
+		firstErr = fmt.Errorf("failed to close Kafka writer: %w", err)
+	}
+
+	return firstErr
+}
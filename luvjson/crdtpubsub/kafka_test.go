@@ -0,0 +1,152 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// newTestKafkaPubSub creates a KafkaPubSub on a local Kafka broker with a topic of its own.
+// The test is skipped if Kafka is not available.
+func newTestKafkaPubSub(t *testing.T, ctx context.Context) *KafkaPubSub {
+	t.Helper()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	conn, err := kafka.DialContext(dialCtx, "tcp", "localhost:9092")
+	if err != nil {
+		t.Skipf("Kafka is not available: %v", err)
+	}
+	defer conn.Close()
+
+	// Topics are created on the controller
+	broker, err := conn.Controller()
+	if err != nil {
+		t.Fatalf("Failed to get the Kafka controller: %v", err)
+	}
+	controller, err := kafka.DialContext(ctx, "tcp", net.JoinHostPort(broker.Host, strconv.Itoa(broker.Port)))
+	if err != nil {
+		t.Fatalf("Failed to connect to the Kafka controller: %v", err)
+	}
+	defer controller.Close()
+
+	kafkaOptions := NewKafkaOptions("localhost:9092")
+	kafkaOptions.Topic = fmt.Sprintf("crdtpubsub-test-%d", time.Now().UnixNano())
+	kafkaOptions.MaxWait = 100 * time.Millisecond
+	kafkaOptions.MaxRetries = 1
+	kafkaOptions.RetryDelay = 100 * time.Millisecond
+	if err := controller.CreateTopics(kafka.TopicConfig{Topic: kafkaOptions.Topic, NumPartitions: 1, ReplicationFactor: 1}); err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	t.Cleanup(func() {
+		if conn, err := kafka.Dial("tcp", net.JoinHostPort(broker.Host, strconv.Itoa(broker.Port))); err == nil {
+			conn.DeleteTopics(kafkaOptions.Topic)
+			conn.Close()
+		}
+	})
+
+	ps, err := NewKafkaPubSub(NewOptions(), kafkaOptions)
+	if err != nil {
+		t.Fatalf("Failed to create Kafka PubSub: %v", err)
+	}
+	t.Cleanup(func() { ps.Close() })
+	return ps
+}
+
+func TestKafkaPubSubFailedCommit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	ps := newTestKafkaPubSub(t, ctx)
+
+	// failing is the number of commits to fail, or -1 to fail them all
+	var failing int32
+	commits := make(chan error, 100)
+	ps.commit = func(ctx context.Context, reader *kafka.Reader, msg kafka.Message) error {
+		var err error
+		if n := atomic.LoadInt32(&failing); n != 0 {
+			if n > 0 {
+				atomic.AddInt32(&failing, -1)
+			}
+			err = fmt.Errorf("commit failed")
+		} else {
+			err = reader.CommitMessages(ctx, msg)
+		}
+		commits <- err
+		return err
+	}
+	// expectCommits waits for the commit attempts of a message and checks whether they succeeded
+	expectCommits := func(succeeded ...bool) {
+		t.Helper()
+		for i, want := range succeeded {
+			select {
+			case err := <-commits:
+				if (err == nil) != want {
+					t.Fatalf("Unexpected result of commit attempt %d: %v", i, err)
+				}
+			case <-ctx.Done():
+				t.Fatalf("Timed out waiting for commit attempt %d", i)
+			}
+		}
+	}
+
+	received := make(chan string, 100)
+	handler := func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		received <- topic + ":" + string(data)
+		return nil
+	}
+	restart := func() {
+		t.Helper()
+		if err := ps.Unsubscribe(ctx, "doc-1", "worker"); err != nil {
+			t.Fatalf("Failed to unsubscribe: %v", err)
+		}
+		if err := ps.Subscribe(ctx, "doc-1", "worker", handler); err != nil {
+			t.Fatalf("Failed to subscribe again: %v", err)
+		}
+	}
+	publish := func(payload string) {
+		t.Helper()
+		if err := ps.PublishRaw(ctx, "doc-1", []byte(payload), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+	}
+
+	if err := ps.Subscribe(ctx, "doc-1", "worker", handler); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	// A failed commit is retried, so the group does not handle the message again when it restarts
+	atomic.StoreInt32(&failing, 1)
+	publish("first")
+	if got := receiveMessage(t, ctx, received); got != "doc-1:first" {
+		t.Fatalf("Expected doc-1:first, got %s", got)
+	}
+	expectCommits(false, true)
+	restart()
+	publish("second")
+	if got := receiveMessage(t, ctx, received); got != "doc-1:second" {
+		t.Fatalf("Expected doc-1:second, got %s", got)
+	}
+	expectCommits(true)
+
+	// A message whose commits all fail is handled again when the group restarts
+	atomic.StoreInt32(&failing, -1)
+	publish("third")
+	if got := receiveMessage(t, ctx, received); got != "doc-1:third" {
+		t.Fatalf("Expected doc-1:third, got %s", got)
+	}
+	expectCommits(false, false)
+	atomic.StoreInt32(&failing, 0)
+	restart()
+	if got := receiveMessage(t, ctx, received); got != "doc-1:third" {
+		t.Errorf("Expected doc-1:third again, got %s", got)
+	}
+	expectCommits(true)
+	expectNoMessage(t, received, "")
+}
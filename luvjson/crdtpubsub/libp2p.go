@@ -0,0 +1,485 @@
+package crdtpubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
+	"tictactoe/luvjson/p2phost"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// libp2pAnnounceTopic is the topic on which peers announce the topics they publish to,
+	// so that pattern subscriptions can join them.
+	libp2pAnnounceTopic = "crdt-topics"
+	// libp2pAnnounceInterval is how often a peer announces a topic it keeps publishing to.
+	libp2pAnnounceInterval = 30 * time.Second
+)
+
+// Libp2pPubSub implements the PubSub interface using libp2p GossipSub, so that
+// peers sync documents without a central broker. Messages are not stored:
+// a peer only receives the messages published while it is subscribed.
+type Libp2pPubSub struct {
+	// host is the libp2p host.
+	host host.Host
+	// ps is the GossipSub router.
+	ps *pubsub.PubSub
+	// options contains the configuration options.
+	options *Options
+	// topics is a map of topic name to joined topic.
+	topics map[string]*pubsub.Topic
+	// subscriptions is a map of topic and subscriber ID to subscription.
+	subscriptions map[streamSubscriptionKey]*libp2pSubscription
+	// announced is a map of topic to the time it was last announced.
+	announced map[string]time.Time
+	// mutex protects the topics, subscriptions and announced maps.
+	mutex sync.RWMutex
+	// closed indicates whether the PubSub has been closed.
+	closed bool
+	// ownsHost indicates whether the host was created by the PubSub and is closed with it.
+	ownsHost bool
+}
+
+// libp2pSubscription represents a subscription to a GossipSub topic.
+type libp2pSubscription struct {
+	// subscription is the GossipSub subscription.
+	subscription *pubsub.Subscription
+	// handler is the subscriber function.
+	handler SubscriberFunc
+	// ctx is the context for the subscription.
+	ctx context.Context
+	// cancel is the cancel function for the context.
+	cancel context.CancelFunc
+	// done is a channel that is closed when the subscription is done.
+	done chan struct{}
+	// pattern is the topic pattern of a pattern subscription, which reads the announce topic.
+	pattern string
+	// children is a map of topic to subscription, for a pattern subscription.
+	children map[string]*libp2pSubscription
+}
+
+// NewLibp2pPubSub creates a new Libp2pPubSub with a GossipSub router on the specified host.
+// The host is created and configured by the application, e.g. with p2phost.New.
+func NewLibp2pPubSub(ctx context.Context, h host.Host, options *Options) (*Libp2pPubSub, error) {
+	if h == nil {
+		return nil, fmt.Errorf("libp2p host cannot be nil")
+	}
+
+	ps, err := pubsub.NewGossipSub(ctx, h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gossipsub: %w", err)
+	}
+	return NewLibp2pPubSubWithRouter(h, ps, options)
+}
+
+// ListenLibp2pPubSub creates a host with p2phost.New, the host setup shared with crdtserver,
+// and a new Libp2pPubSub with a GossipSub router on it. The host is closed with the PubSub.
+func ListenLibp2pPubSub(ctx context.Context, hostOptions p2phost.Options, options *Options) (*Libp2pPubSub, error) {
+	h, err := p2phost.New(hostOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	ps, err := NewLibp2pPubSub(ctx, h, options)
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+	ps.ownsHost = true
+	return ps, nil
+}
+
+// Host returns the libp2p host of the PubSub.
+func (ps *Libp2pPubSub) Host() host.Host {
+	return ps.host
+}
+
+// NewLibp2pPubSubWithRouter creates a new Libp2pPubSub using an existing router of the host,
+// e.g. one that the application also uses for other topics.
+func NewLibp2pPubSubWithRouter(h host.Host, ps *pubsub.PubSub, options *Options) (*Libp2pPubSub, error) {
+	if h == nil || ps == nil {
+		return nil, fmt.Errorf("libp2p host and router cannot be nil")
+	}
+
+	if options == nil {
+		options = NewOptions()
+	}
+
+	return &Libp2pPubSub{
+		host:          h,
+		ps:            ps,
+		options:       options,
+		topics:        make(map[string]*pubsub.Topic),
+		subscriptions: make(map[streamSubscriptionKey]*libp2pSubscription),
+		announced:     make(map[string]time.Time),
+	}, nil
+}
+
+// Connect connects to the peer with the specified multiaddress, which must end with /p2p/<peer ID>.
+func (ps *Libp2pPubSub) Connect(ctx context.Context, addr string) error {
+	info, err := peer.AddrInfoFromString(addr)
+	if err != nil {
+		return fmt.Errorf("invalid peer address: %w", err)
+	}
+	if err := ps.host.Connect(ctx, *info); err != nil {
+		return fmt.Errorf("failed to connect to peer %s: %w", info.ID, err)
+	}
+	return nil
+}
+
+// topic returns the joined topic, joining it if needed. The caller must hold the mutex.
+func (ps *Libp2pPubSub) topic(name string) (*pubsub.Topic, error) {
+	if topic, ok := ps.topics[name]; ok {
+		return topic, nil
+	}
+
+	topic, err := ps.ps.Join(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join topic: %w", err)
+	}
+	ps.topics[name] = topic
+	return topic, nil
+}
+
+// Publish publishes a patch to the specified topic.
+func (ps *Libp2pPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Get the encoder for the format
+	encoder, err := GetEncoderDecoder(format)
+	if err != nil {
+		return err
+	}
+
+	// Encode the patch
+	data, err := encoder.Encode(patch)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	return ps.PublishRaw(ctx, topic, data, format)
+}
+
+// PublishRaw publishes raw data to the specified topic.
+func (ps *Libp2pPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
+	// Create message
+	msg := PatchMessage{
+		Topic:   topic,
+		Payload: data,
+		Format:  format,
+		Metadata: map[string]string{
+			"format": string(format),
+		},
+	}
+	if cursor != "" {
+		msg.Metadata[cursorMetadataKey] = cursor
+	}
+
+	// Encode the message
+	msgData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	ps.mutex.Lock()
+	t, err := ps.topic(topic)
+	ps.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Publish the message
+	if err := t.Publish(ctx, msgData); err != nil {
+		return err
+	}
+	return ps.announce(ctx, topic)
+}
+
+// announce announces a topic on the announce topic, unless it was announced during
+// the last libp2pAnnounceInterval.
+func (ps *Libp2pPubSub) announce(ctx context.Context, topic string) error {
+	ps.mutex.Lock()
+	if time.Since(ps.announced[topic]) < libp2pAnnounceInterval {
+		ps.mutex.Unlock()
+		return nil
+	}
+	ps.announced[topic] = time.Now()
+	t, err := ps.topic(libp2pAnnounceTopic)
+	ps.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := t.Publish(ctx, []byte(topic)); err != nil {
+		return fmt.Errorf("failed to announce topic: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the specified topic and calls the handler for each received message.
+// This method implements the Subscriber interface.
+func (ps *Libp2pPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	key := streamSubscriptionKey{topic: topic, subscriberID: subscriberID}
+	if _, ok := ps.subscriptions[key]; ok {
+		return fmt.Errorf("already subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+
+	t, err := ps.topic(topic)
+	if err != nil {
+		return err
+	}
+	sub, err := t.Subscribe()
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to topic: %w", err)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	subscription := &libp2pSubscription{
+		subscription: sub,
+		handler:      handler,
+		ctx:          subCtx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+	ps.subscriptions[key] = subscription
+
+	// Start a goroutine to handle messages
+	go ps.handleMessages(subscription)
+
+	return nil
+}
+
+// SubscribeSince subscribes to the specified topic and first calls the handler for the
+// retained messages published after the cursor.
+// This method implements the Subscriber interface.
+func (ps *Libp2pPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return SubscribeWithReplay(ctx, ps, ps.options.RetentionStore, topic, subscriberID, cursor, handler)
+}
+
+// SubscribePattern subscribes to every topic matching the pattern. Publishers announce
+// their topics when they start publishing to them and then every libp2pAnnounceInterval,
+// and the subscription joins the matching ones. As with Subscribe, only the messages
+// published after a topic is joined are received.
+// This method implements the Subscriber interface.
+func (ps *Libp2pPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	key := streamSubscriptionKey{topic: pattern, subscriberID: subscriberID}
+	if _, ok := ps.subscriptions[key]; ok {
+		return fmt.Errorf("already subscribed to topic: %s with subscriberID: %s", pattern, subscriberID)
+	}
+
+	t, err := ps.topic(libp2pAnnounceTopic)
+	if err != nil {
+		return err
+	}
+	sub, err := t.Subscribe()
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to announce topic: %w", err)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	subscription := &libp2pSubscription{
+		subscription: sub,
+		handler:      handler,
+		ctx:          subCtx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+		pattern:      pattern,
+		children:     make(map[string]*libp2pSubscription),
+	}
+	ps.subscriptions[key] = subscription
+
+	// Start a goroutine to join the announced topics
+	go ps.handleAnnouncements(subscription)
+
+	return nil
+}
+
+// handleAnnouncements joins the announced topics matching the pattern of a subscription.
+func (ps *Libp2pPubSub) handleAnnouncements(subscription *libp2pSubscription) {
+	defer close(subscription.done)
+	defer func() {
+		for _, child := range subscription.children {
+			ps.stopSubscription(child)
+		}
+	}()
+
+	for {
+		msg, err := subscription.subscription.Next(subscription.ctx)
+		if err != nil {
+			// The subscription was cancelled
+			return
+		}
+
+		topic := string(msg.Data)
+		if _, ok := subscription.children[topic]; ok || !MatchTopic(subscription.pattern, topic) {
+			continue
+		}
+
+		ps.mutex.Lock()
+		t, err := ps.topic(topic)
+		ps.mutex.Unlock()
+		if err != nil {
+			fmt.Printf("failed to join topic %s: %v\n", topic, err)
+			continue
+		}
+		sub, err := t.Subscribe()
+		if err != nil {
+			fmt.Printf("failed to subscribe to topic %s: %v\n", topic, err)
+			continue
+		}
+
+		childCtx, cancel := context.WithCancel(subscription.ctx)
+		child := &libp2pSubscription{
+			subscription: sub,
+			handler:      subscription.handler,
+			ctx:          childCtx,
+			cancel:       cancel,
+			done:         make(chan struct{}),
+		}
+		subscription.children[topic] = child
+		go ps.handleMessages(child)
+	}
+}
+
+// handleMessages handles messages for a subscription.
+func (ps *Libp2pPubSub) handleMessages(subscription *libp2pSubscription) {
+	defer close(subscription.done)
+
+	for {
+		msg, err := subscription.subscription.Next(subscription.ctx)
+		if err != nil {
+			// The subscription was cancelled
+			return
+		}
+
+		// Decode the message
+		var patchMsg PatchMessage
+		if err := json.Unmarshal(msg.Data, &patchMsg); err != nil {
+			// Log the error but continue
+			fmt.Printf("failed to decode message from %s: %v\n", msg.ReceivedFrom, err)
+			continue
+		}
+
+		// Call the handler
+		if err := subscription.handler(withCursor(subscription.ctx, patchMsg.Metadata[cursorMetadataKey]), patchMsg.Topic, patchMsg.Payload, patchMsg.Format); err != nil {
+			// Log the error but continue
+			fmt.Printf("failed to handle message: %v\n", err)
+		}
+	}
+}
+
+// Unsubscribe unsubscribes from the specified topic.
+// This method implements the Subscriber interface.
+func (ps *Libp2pPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+
+	// Check if subscribed with this subscriberID
+	key := streamSubscriptionKey{topic: topic, subscriberID: subscriberID}
+	subscription, ok := ps.subscriptions[key]
+	if !ok {
+		ps.mutex.Unlock()
+		return fmt.Errorf("not subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+	delete(ps.subscriptions, key)
+
+	// 패턴 구독은 주제에 참여할 때 잠금을 사용하므로 잠금을 푼 후 기다림
+	ps.mutex.Unlock()
+	ps.stopSubscription(subscription)
+	return nil
+}
+
+// stopSubscription cancels a subscription and waits for it to finish.
+func (ps *Libp2pPubSub) stopSubscription(subscription *libp2pSubscription) {
+	subscription.subscription.Cancel()
+	subscription.cancel()
+	<-subscription.done
+}
+
+// Close closes the PubSub. The host is not closed, since it may be shared.
+func (ps *Libp2pPubSub) Close() error {
+	if ps.closed {
+		return nil
+	}
+
+	ps.mutex.Lock()
+
+	// Mark as closed
+	ps.closed = true
+	subscriptions := ps.subscriptions
+	ps.subscriptions = make(map[streamSubscriptionKey]*libp2pSubscription)
+	ps.mutex.Unlock()
+
+	// Cancel all subscriptions
+	for _, subscription := range subscriptions {
+		ps.stopSubscription(subscription)
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// Leave all topics
+	for name, topic := range ps.topics {
+		if err := topic.Close(); err != nil {
+			return fmt.Errorf("failed to leave topic %s: %w", name, err)
+		}
+	}
+
+	// Close the host created by ListenLibp2pPubSub
+	if ps.ownsHost {
+		if err := ps.host.Close(); err != nil {
+			return fmt.Errorf("failed to close libp2p host: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package crdtpubsub
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"tictactoe/luvjson/p2phost"
+
+	"github.com/libp2p/go-libp2p/core/host"
+)
+
+// newTestLibp2pPubSub creates a Libp2pPubSub on a host listening on a random loopback port.
+func newTestLibp2pPubSub(t *testing.T, ctx context.Context) (*Libp2pPubSub, host.Host) {
+	t.Helper()
+
+	ps, err := ListenLibp2pPubSub(ctx, p2phost.Options{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}}, NewOptions())
+	if err != nil {
+		t.Fatalf("Failed to create libp2p PubSub: %v", err)
+	}
+	t.Cleanup(func() { ps.Close() })
+	return ps, ps.Host()
+}
+
+// connectLibp2pPubSub connects the publisher to the subscriber's host.
+func connectLibp2pPubSub(t *testing.T, ctx context.Context, publisher *Libp2pPubSub, subscriber host.Host) {
+	t.Helper()
+
+	addr := subscriber.Addrs()[0].String() + "/p2p/" + subscriber.ID().String()
+	if err := publisher.Connect(ctx, addr); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+}
+
+// publishUntilReceived publishes to the topic until a message of the topic arrives, since
+// GossipSub drops the messages published before the peers have exchanged their subscriptions.
+func publishUntilReceived(t *testing.T, ctx context.Context, publisher *Libp2pPubSub, topic string, received <-chan string) string {
+	t.Helper()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		// Announce the topic again for pattern subscriptions that joined late
+		publisher.mutex.Lock()
+		delete(publisher.announced, topic)
+		publisher.mutex.Unlock()
+
+		if err := publisher.PublishRaw(ctx, topic, []byte("patch"), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+		select {
+		case got := <-received:
+			if strings.HasPrefix(got, topic) {
+				return got
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for a message of %s", topic)
+		}
+	}
+}
+
+// expectNoMessage fails if a message starting with the prefix arrives within a short time.
+// Other messages, e.g. late copies of the messages published by publishUntilReceived, are ignored.
+func expectNoMessage(t *testing.T, received <-chan string, prefix string) {
+	t.Helper()
+
+	timeout := time.After(500 * time.Millisecond)
+	for {
+		select {
+		case got := <-received:
+			if strings.HasPrefix(got, prefix) {
+				t.Errorf("Unexpected message: %s", got)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestLibp2pPubSubTwoHosts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	publisher, _ := newTestLibp2pPubSub(t, ctx)
+	subscriber, subscriberHost := newTestLibp2pPubSub(t, ctx)
+	connectLibp2pPubSub(t, ctx, publisher, subscriberHost)
+
+	received := make(chan string, 100)
+	if err := subscriber.Subscribe(ctx, "doc-1", "worker", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		if format != EncodingFormatJSON {
+			t.Errorf("Expected format %s, got %s", EncodingFormatJSON, format)
+		}
+		received <- topic + ":" + string(data)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	// Subscribing twice with the same ID fails
+	if err := subscriber.Subscribe(ctx, "doc-1", "worker", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		return nil
+	}); err == nil {
+		t.Errorf("Expected error when subscribing twice")
+	}
+
+	if got := publishUntilReceived(t, ctx, publisher, "doc-1", received); got != "doc-1:patch" {
+		t.Errorf("Expected doc-1:patch, got %s", got)
+	}
+
+	// Messages of other topics are not delivered
+	if err := publisher.PublishRaw(ctx, "doc-2", []byte("patch"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	expectNoMessage(t, received, "doc-2")
+
+	// No messages are delivered after unsubscribing
+	if err := subscriber.Unsubscribe(ctx, "doc-1", "worker"); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if err := subscriber.Unsubscribe(ctx, "doc-1", "worker"); err == nil {
+		t.Errorf("Expected error when unsubscribing twice")
+	}
+	for len(received) > 0 {
+		<-received
+	}
+	if err := publisher.PublishRaw(ctx, "doc-1", []byte("patch"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	expectNoMessage(t, received, "")
+}
+
+func TestLibp2pPubSubPatternTwoHosts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	publisher, _ := newTestLibp2pPubSub(t, ctx)
+	subscriber, subscriberHost := newTestLibp2pPubSub(t, ctx)
+	connectLibp2pPubSub(t, ctx, publisher, subscriberHost)
+
+	received := make(chan string, 100)
+	if err := subscriber.SubscribePattern(ctx, "doc-*-updates", "worker", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		received <- topic
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	// The subscription joins the announced topics matching the pattern
+	for _, topic := range []string{"doc-1-updates", "doc-2-updates"} {
+		if got := publishUntilReceived(t, ctx, publisher, topic, received); got != topic {
+			t.Errorf("Expected a message of %s, got %s", topic, got)
+		}
+	}
+
+	// Topics not matching the pattern are not joined
+	if err := publisher.PublishRaw(ctx, "doc-1-presence", []byte("patch"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	expectNoMessage(t, received, "doc-1-presence")
+
+	// Closing stops the pattern subscription and its topics
+	if err := subscriber.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if err := subscriber.PublishRaw(ctx, "doc-1-updates", []byte("patch"), EncodingFormatJSON); err == nil {
+		t.Errorf("Expected error when publishing after close")
+	}
+	for len(received) > 0 {
+		<-received
+	}
+	if err := publisher.PublishRaw(ctx, "doc-1-updates", []byte("patch"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	expectNoMessage(t, received, "")
+}
+
+func TestListenLibp2pPubSubClosesHost(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := ListenLibp2pPubSub(ctx, p2phost.Options{Security: []string{"quic"}}, NewOptions()); err == nil {
+		t.Errorf("Expected error for an unknown security protocol")
+	}
+
+	ps, err := ListenLibp2pPubSub(ctx, p2phost.Options{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}, Security: []string{"noise"}}, NewOptions())
+	if err != nil {
+		t.Fatalf("Failed to create libp2p PubSub: %v", err)
+	}
+	if len(ps.Host().Addrs()) == 0 {
+		t.Fatalf("Expected the host to listen")
+	}
+	if err := ps.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if len(ps.Host().Network().ListenAddresses()) != 0 {
+		t.Errorf("Expected the host to be closed with the PubSub")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 	"tictactoe/luvjson/crdtpatch"
+	"time"
 )
 
 // MemoryPubSub implements the PubSub interface using in-memory channels.
@@ -72,10 +73,19 @@ func (ps *MemoryPubSub) Publish(ctx context.Context, topic string, patch *crdtpa
 		return fmt.Errorf("failed to encode patch: %w", err)
 	}
 
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
 	// Create metadata
 	metadata := map[string]string{
 		"format": string(format),
 	}
+	if cursor != "" {
+		metadata[cursorMetadataKey] = cursor
+	}
 
 	// Create message
 	msg := PatchMessage{
@@ -100,10 +110,19 @@ func (ps *MemoryPubSub) PublishRaw(ctx context.Context, topic string, data []byt
 		format = ps.options.DefaultFormat
 	}
 
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
 	// Create metadata
 	metadata := map[string]string{
 		"format": string(format),
 	}
+	if cursor != "" {
+		metadata[cursorMetadataKey] = cursor
+	}
 
 	// Create message
 	msg := PatchMessage{
@@ -126,10 +145,16 @@ func (ps *MemoryPubSub) deliverMessage(ctx context.Context, msg PatchMessage) er
 		return fmt.Errorf("pubsub is closed")
 	}
 
-	// Get the subscribers for the topic
-	subscribers, ok := ps.subscriptions[msg.Topic]
-	if !ok || len(subscribers) == 0 {
+	// Get the subscribers for the topic and for the patterns matching it
+	subscribers := append([]*memorySubscription(nil), ps.subscriptions[msg.Topic]...)
+	for pattern, patternSubscribers := range ps.subscriptions {
+		if pattern != msg.Topic && IsTopicPattern(pattern) && MatchTopic(pattern, msg.Topic) {
+			subscribers = append(subscribers, patternSubscribers...)
+		}
+	}
+	if len(subscribers) == 0 {
 		// No subscribers, message is dropped
+		ps.dropped(msg.Topic, "no subscribers")
 		return nil
 	}
 
@@ -138,6 +163,7 @@ func (ps *MemoryPubSub) deliverMessage(ctx context.Context, msg PatchMessage) er
 		// Check if the subscription context is done
 		select {
 		case <-sub.ctx.Done():
+			ps.dropped(msg.Topic, "subscription cancelled")
 			continue
 		default:
 			// Call the handler in a goroutine to avoid blocking
@@ -145,9 +171,15 @@ func (ps *MemoryPubSub) deliverMessage(ctx context.Context, msg PatchMessage) er
 				// Check context again before calling handler
 				select {
 				case <-s.ctx.Done():
+					ps.dropped(m.Topic, "subscription cancelled")
 					return
 				default:
-					if err := s.handler(m); err != nil {
+					start := time.Now()
+					err := s.handler(m)
+					if ps.options.Metrics != nil {
+						ps.options.Metrics.Handled(m.Topic, s.subscriberID, time.Since(start), err)
+					}
+					if err != nil {
 						// Log the error but continue
 						fmt.Printf("failed to handle message: %v\n", err)
 					}
@@ -159,6 +191,13 @@ func (ps *MemoryPubSub) deliverMessage(ctx context.Context, msg PatchMessage) er
 	return nil
 }
 
+// dropped reports a message that is not delivered to a subscriber.
+func (ps *MemoryPubSub) dropped(topic string, reason string) {
+	if ps.options.Metrics != nil {
+		ps.options.Metrics.Dropped(topic, reason)
+	}
+}
+
 // Subscribe subscribes to the specified topic and calls the handler for each received message.
 // This method implements the Subscriber interface.
 func (ps *MemoryPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
@@ -177,7 +216,7 @@ func (ps *MemoryPubSub) Subscribe(ctx context.Context, topic string, subscriberI
 		subscriberFunc: handler,
 		handler: func(msg PatchMessage) error {
 			// Convert MessageHandler to SubscriberFunc
-			return handler(ctx, msg.Topic, msg.Payload, msg.Format)
+			return handler(withCursor(ctx, msg.Metadata[cursorMetadataKey]), msg.Topic, msg.Payload, msg.Format)
 		},
 		ctx:    subCtx,
 		cancel: cancel,
@@ -189,6 +228,23 @@ func (ps *MemoryPubSub) Subscribe(ctx context.Context, topic string, subscriberI
 	return nil
 }
 
+// SubscribeSince subscribes to the specified topic and first calls the handler for the
+// retained messages published after the cursor.
+// This method implements the Subscriber interface.
+func (ps *MemoryPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return SubscribeWithReplay(ctx, ps, ps.options.RetentionStore, topic, subscriberID, cursor, handler)
+}
+
+// SubscribePattern subscribes to every topic matching the pattern.
+// This method implements the Subscriber interface.
+func (ps *MemoryPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	// 패턴 구독도 같은 맵에 저장하고 전달할 때 주제와 비교
+	return ps.Subscribe(ctx, pattern, subscriberID, handler)
+}
+
 // SubscribeWithHandler subscribes to the specified topic with a MessageHandler.
 // This is a convenience method that wraps the Subscribe method.
 func (ps *MemoryPubSub) SubscribeWithHandler(ctx context.Context, topic string, handler MessageHandler) error {
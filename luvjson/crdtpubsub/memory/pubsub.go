@@ -17,6 +17,9 @@ type PubSub struct {
 	// encoderDecoders is a map of encoding format to encoder/decoder.
 	encoderDecoders map[crdtpubsub.EncodingFormat]crdtpubsub.EncoderDecoder
 
+	// retention keeps the published messages for SubscribeSince, or is nil.
+	retention crdtpubsub.RetentionStore
+
 	// mutex is used to protect access to the subscribers map.
 	mutex sync.RWMutex
 
@@ -33,6 +36,8 @@ func NewPubSub() (*PubSub, error) {
 		crdtpubsub.EncodingFormatBinary,
 		crdtpubsub.EncodingFormatText,
 		crdtpubsub.EncodingFormatBase64,
+		crdtpubsub.EncodingFormatCBOR,
+		crdtpubsub.EncodingFormatMsgPack,
 	} {
 		encoderDecoder, err := crdtpubsub.GetEncoderDecoder(format)
 		if err != nil {
@@ -48,12 +53,22 @@ func NewPubSub() (*PubSub, error) {
 	}, nil
 }
 
+// SetRetentionStore sets the store keeping the published messages for SubscribeSince.
+func (p *PubSub) SetRetentionStore(store crdtpubsub.RetentionStore) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.retention = store
+}
+
 // Publish publishes a patch to a topic.
 func (p *PubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format crdtpubsub.EncodingFormat) error {
+	// PublishRaw takes the lock again; holding it here deadlocks with a pending Subscribe
 	p.mutex.RLock()
-	defer p.mutex.RUnlock()
+	closed := p.closed
+	p.mutex.RUnlock()
 
-	if p.closed {
+	if closed {
 		return fmt.Errorf("pubsub is closed")
 	}
 
@@ -82,9 +97,25 @@ func (p *PubSub) PublishRaw(ctx context.Context, topic string, data []byte, form
 		return fmt.Errorf("pubsub is closed")
 	}
 
-	// Get the subscribers for the topic
-	subscribers, ok := p.subscribers[topic]
-	if !ok || len(subscribers) == 0 {
+	// Keep the message for SubscribeSince
+	if p.retention != nil {
+		cursor, err := p.retention.Append(ctx, topic, data, format)
+		if err != nil {
+			return fmt.Errorf("failed to retain message: %w", err)
+		}
+		ctx = crdtpubsub.ContextWithCursor(ctx, cursor)
+	}
+
+	// Get the subscribers for the topic and for the patterns matching it
+	var subscribers []crdtpubsub.SubscriberFunc
+	for key, keySubscribers := range p.subscribers {
+		if key == topic || (crdtpubsub.IsTopicPattern(key) && crdtpubsub.MatchTopic(key, topic)) {
+			for _, subscriber := range keySubscribers {
+				subscribers = append(subscribers, subscriber)
+			}
+		}
+	}
+	if len(subscribers) == 0 {
 		// No subscribers, nothing to do
 		return nil
 	}
@@ -129,6 +160,21 @@ func (p *PubSub) Subscribe(ctx context.Context, topic string, subscriberID strin
 	return nil
 }
 
+// SubscribeSince subscribes to a topic and first calls the subscriber for the retained
+// messages published after the cursor.
+func (p *PubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor crdtpubsub.Cursor, subscriber crdtpubsub.SubscriberFunc) error {
+	p.mutex.RLock()
+	store := p.retention
+	p.mutex.RUnlock()
+
+	return crdtpubsub.SubscribeWithReplay(ctx, p, store, topic, subscriberID, cursor, subscriber)
+}
+
+// SubscribePattern subscribes to every topic matching the pattern.
+func (p *PubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, subscriber crdtpubsub.SubscriberFunc) error {
+	return p.Subscribe(ctx, pattern, subscriberID, subscriber)
+}
+
 // Unsubscribe unsubscribes from a topic.
 func (p *PubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
 	p.mutex.Lock()
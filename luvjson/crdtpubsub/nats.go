@@ -0,0 +1,377 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSOptions represents configuration options for NATSPubSub.
+type NATSOptions struct {
+	// StreamName is the name of the JetStream stream holding the patches.
+	StreamName string
+	// SubjectPrefix is the prefix of the subjects: a topic is published to
+	// SubjectPrefix.topic, so each document has its own subject.
+	SubjectPrefix string
+	// MaxAge is how long the stream keeps messages, or 0 for no limit.
+	MaxAge time.Duration
+	// DeliverNew makes new durable consumers start with new messages instead of
+	// the whole stream.
+	DeliverNew bool
+	// AckWait is how long the server waits for an acknowledgement before it
+	// delivers a message again.
+	AckWait time.Duration
+	// MaxAckPending is the maximum number of unacknowledged messages of a
+	// consumer. The server stops delivering when it is reached.
+	MaxAckPending int
+	// MaxBuffered is the maximum number of messages buffered by the client.
+	MaxBuffered int
+	// MaxDeliver is the maximum number of deliveries of a message, or 0 for no limit.
+	MaxDeliver int
+	// NakDelay is how long to wait before a message whose handler failed is
+	// delivered again.
+	NakDelay time.Duration
+}
+
+// NewNATSOptions creates a new NATSOptions with default values.
+func NewNATSOptions() *NATSOptions {
+	return &NATSOptions{
+		StreamName:    "CRDT_PATCHES",
+		SubjectPrefix: "crdt.patches",
+		AckWait:       30 * time.Second,
+		MaxAckPending: 1000,
+		MaxBuffered:   100,
+		NakDelay:      time.Second,
+	}
+}
+
+// NATSPubSub implements the PubSub interface using NATS JetStream.
+// Topics are subjects of one stream and each subscriber ID is a durable
+// consumer, so messages are kept until they are acknowledged and a subscriber
+// that restarts receives the messages published while it was down.
+type NATSPubSub struct {
+	// conn is the NATS connection.
+	conn *nats.Conn
+	// js is the JetStream context.
+	js jetstream.JetStream
+	// options contains the configuration options.
+	options *Options
+	// natsOptions contains the JetStream configuration options.
+	natsOptions *NATSOptions
+	// subscriptions is a map of topic and subscriber ID to subscription.
+	subscriptions map[streamSubscriptionKey]*natsSubscription
+	// mutex protects the subscriptions map.
+	mutex sync.RWMutex
+	// closed indicates whether the PubSub has been closed.
+	closed bool
+}
+
+// natsSubscription represents a subscription to a NATS subject.
+type natsSubscription struct {
+	// consumeCtx stops the consumer.
+	consumeCtx jetstream.ConsumeContext
+	// cancel is the cancel function for the context passed to the handler.
+	cancel context.CancelFunc
+}
+
+// ConnectNATS connects to the NATS server at url. The connection reconnects
+// automatically, without limit, and buffers messages published while it is
+// disconnected. The client ID of options is the connection name, and the "user",
+// "password" and "token" credentials are used if they are set.
+func ConnectNATS(url string, options *Options) (*nats.Conn, error) {
+	if options == nil {
+		options = NewOptions()
+	}
+
+	opts := []nats.Option{
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2 * time.Second),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				fmt.Printf("disconnected from NATS: %v\n", err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			fmt.Printf("reconnected to NATS at %s\n", nc.ConnectedUrl())
+		}),
+	}
+	if options.ClientID != "" {
+		opts = append(opts, nats.Name(options.ClientID))
+	}
+	if user, ok := options.Credentials["user"]; ok {
+		opts = append(opts, nats.UserInfo(user, options.Credentials["password"]))
+	}
+	if token, ok := options.Credentials["token"]; ok {
+		opts = append(opts, nats.Token(token))
+	}
+
+	conn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return conn, nil
+}
+
+// NewNATSPubSub creates a new NATSPubSub with the specified NATS connection and options.
+// The stream is created, or updated to the options, if needed.
+func NewNATSPubSub(conn *nats.Conn, options *Options, natsOptions *NATSOptions) (*NATSPubSub, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("nats connection cannot be nil")
+	}
+
+	if options == nil {
+		options = NewOptions()
+	}
+	if natsOptions == nil {
+		natsOptions = NewNATSOptions()
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     natsOptions.StreamName,
+		Subjects: []string{natsOptions.SubjectPrefix + ".>"},
+		MaxAge:   natsOptions.MaxAge,
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream: %w", err)
+	}
+
+	return &NATSPubSub{
+		conn:          conn,
+		js:            js,
+		options:       options,
+		natsOptions:   natsOptions,
+		subscriptions: make(map[streamSubscriptionKey]*natsSubscription),
+	}, nil
+}
+
+// Publish publishes a patch to the specified topic.
+func (ps *NATSPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Get the encoder for the format
+	encoder, err := GetEncoderDecoder(format)
+	if err != nil {
+		return err
+	}
+
+	// Encode the patch
+	data, err := encoder.Encode(patch)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	return ps.PublishRaw(ctx, topic, data, format)
+}
+
+// PublishRaw publishes raw data to the specified topic.
+// It returns once the stream has stored the message.
+func (ps *NATSPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(ps.subject(topic))
+	msg.Data = data
+	msg.Header.Set("format", string(format))
+	msg.Header.Set("topic", topic)
+	if cursor != "" {
+		msg.Header.Set(cursorMetadataKey, cursor)
+	}
+
+	if _, err := ps.js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the specified topic and calls the handler for each received message.
+// The subscriber ID names a durable consumer: processes subscribing with the same ID share
+// its messages. Messages are acknowledged once the handler returns nil, and are delivered
+// again after NakDelay otherwise. The handler is called for one message at a time.
+// This method implements the Subscriber interface.
+func (ps *NATSPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	key := streamSubscriptionKey{topic: topic, subscriberID: subscriberID}
+	if _, ok := ps.subscriptions[key]; ok {
+		return fmt.Errorf("already subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+
+	// 패턴은 모든 주제를 받아 원래 주제로 거름
+	filterSubject := ps.subject(topic)
+	pattern := IsTopicPattern(topic)
+	if pattern {
+		filterSubject = ps.natsOptions.SubjectPrefix + ".>"
+	}
+
+	config := jetstream.ConsumerConfig{
+		Durable:       natsName(subscriberID + "_" + topic),
+		FilterSubject: filterSubject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ps.natsOptions.AckWait,
+		MaxAckPending: ps.natsOptions.MaxAckPending,
+		MaxDeliver:    ps.natsOptions.MaxDeliver,
+	}
+	if ps.natsOptions.DeliverNew {
+		config.DeliverPolicy = jetstream.DeliverNewPolicy
+	}
+	consumer, err := ps.js.CreateOrUpdateConsumer(ctx, ps.natsOptions.StreamName, config)
+	if err != nil {
+		return fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		msgTopic := topic
+		if pattern {
+			msgTopic = msg.Headers().Get("topic")
+			if !MatchTopic(topic, msgTopic) {
+				if err := msg.Ack(); err != nil {
+					fmt.Printf("failed to acknowledge message: %v\n", err)
+				}
+				return
+			}
+		}
+
+		format := EncodingFormat(msg.Headers().Get("format"))
+		if err := handler(withCursor(subCtx, msg.Headers().Get(cursorMetadataKey)), msgTopic, msg.Data(), format); err != nil {
+			// Log the error and deliver the message again later
+			fmt.Printf("failed to handle message: %v\n", err)
+			if err := msg.NakWithDelay(ps.natsOptions.NakDelay); err != nil {
+				fmt.Printf("failed to reject message: %v\n", err)
+			}
+			return
+		}
+		if err := msg.Ack(); err != nil {
+			fmt.Printf("failed to acknowledge message: %v\n", err)
+		}
+	},
+		jetstream.PullMaxMessages(ps.natsOptions.MaxBuffered),
+		jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+			fmt.Printf("failed to consume topic %s: %v\n", topic, err)
+		}),
+	)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to consume topic: %w", err)
+	}
+
+	ps.subscriptions[key] = &natsSubscription{consumeCtx: consumeCtx, cancel: cancel}
+	return nil
+}
+
+// SubscribeSince subscribes to the specified topic and first calls the handler for the
+// retained messages published after the cursor.
+// This method implements the Subscriber interface.
+func (ps *NATSPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return SubscribeWithReplay(ctx, ps, ps.options.RetentionStore, topic, subscriberID, cursor, handler)
+}
+
+// SubscribePattern subscribes to every topic matching the pattern. The durable consumer
+// receives the messages of all topics and skips those not matching, which are
+// acknowledged, so a worker serving every document of a namespace needs one consumer.
+// This method implements the Subscriber interface.
+func (ps *NATSPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	return ps.Subscribe(ctx, pattern, subscriberID, handler)
+}
+
+// Unsubscribe unsubscribes from the specified topic.
+// The durable consumer is kept, so a later subscription resumes where this one stopped.
+// This method implements the Subscriber interface.
+func (ps *NATSPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// Check if subscribed with this subscriberID
+	key := streamSubscriptionKey{topic: topic, subscriberID: subscriberID}
+	subscription, ok := ps.subscriptions[key]
+	if !ok {
+		return fmt.Errorf("not subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+
+	subscription.consumeCtx.Stop()
+	subscription.cancel()
+	delete(ps.subscriptions, key)
+	return nil
+}
+
+// Close closes the PubSub and the NATS connection.
+func (ps *NATSPubSub) Close() error {
+	if ps.closed {
+		return nil
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// Mark as closed
+	ps.closed = true
+
+	// Stop all subscriptions
+	for _, subscription := range ps.subscriptions {
+		subscription.consumeCtx.Stop()
+		subscription.cancel()
+	}
+
+	// 전송 중인 메시지를 보낸 후 연결 종료
+	if err := ps.conn.Drain(); err != nil {
+		return fmt.Errorf("failed to close NATS connection: %w", err)
+	}
+
+	return nil
+}
+
+// subject returns the subject of a topic.
+func (ps *NATSPubSub) subject(topic string) string {
+	return ps.natsOptions.SubjectPrefix + "." + strings.NewReplacer(" ", "_", "*", "_", ">", "_").Replace(topic)
+}
+
+// natsName replaces the characters that JetStream does not accept in names.
+func natsName(name string) string {
+	return strings.NewReplacer(".", "_", " ", "_", "*", "_", ">", "_", "/", "_", "\\", "_").Replace(name)
+}
@@ -0,0 +1,180 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// newTestNATSPubSub creates a NATSPubSub on a local NATS server with a stream that is
+// deleted after the test. The test is skipped if NATS or JetStream is not available.
+func newTestNATSPubSub(t *testing.T, ctx context.Context) (*NATSPubSub, *nats.Conn) {
+	t.Helper()
+
+	// The PubSub drains its connection, so the stream is deleted with another one
+	admin, err := ConnectNATS(nats.DefaultURL, nil)
+	if err != nil {
+		t.Skipf("NATS is not available: %v", err)
+	}
+	t.Cleanup(admin.Close)
+	js, err := jetstream.New(admin)
+	if err != nil {
+		t.Fatalf("Failed to create JetStream context: %v", err)
+	}
+
+	conn, err := ConnectNATS(nats.DefaultURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to NATS: %v", err)
+	}
+
+	natsOptions := NewNATSOptions()
+	suffix := time.Now().UnixNano()
+	natsOptions.StreamName = fmt.Sprintf("CRDT_PUBSUB_TEST_%d", suffix)
+	natsOptions.SubjectPrefix = fmt.Sprintf("crdtpubsub.test.%d", suffix)
+	natsOptions.NakDelay = 100 * time.Millisecond
+
+	ps, err := NewNATSPubSub(conn, NewOptions(), natsOptions)
+	if err != nil {
+		conn.Close()
+		t.Skipf("JetStream is not available: %v", err)
+	}
+	t.Cleanup(func() {
+		ps.Close()
+		js.DeleteStream(context.Background(), natsOptions.StreamName)
+	})
+	return ps, conn
+}
+
+func TestNATSPubSubSubscribe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ps, _ := newTestNATSPubSub(t, ctx)
+
+	// The handler fails the first delivery of "second"
+	var attempts int32
+	received := make(chan string, 100)
+	handler := func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		if format != EncodingFormatJSON {
+			t.Errorf("Expected format %s, got %s", EncodingFormatJSON, format)
+		}
+		received <- topic + ":" + string(data)
+		if string(data) == "second" && atomic.AddInt32(&attempts, 1) == 1 {
+			return fmt.Errorf("handler failed")
+		}
+		return nil
+	}
+	if err := ps.Subscribe(ctx, "doc-1", "worker", handler); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if err := ps.Subscribe(ctx, "doc-1", "worker", handler); err == nil {
+		t.Errorf("Expected error when subscribing twice")
+	}
+
+	for _, payload := range []string{"first", "second"} {
+		if err := ps.PublishRaw(ctx, "doc-1", []byte(payload), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+	}
+	if err := ps.PublishRaw(ctx, "doc-2", []byte("other"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	// The failed message is delivered again after NakDelay, and other topics are not delivered
+	for _, want := range []string{"doc-1:first", "doc-1:second", "doc-1:second"} {
+		if got := receiveMessage(t, ctx, received); got != want {
+			t.Fatalf("Expected %s, got %s", want, got)
+		}
+	}
+	expectNoMessage(t, received, "")
+}
+
+func TestNATSPubSubUnsubscribe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ps, _ := newTestNATSPubSub(t, ctx)
+
+	received := make(chan string, 100)
+	handler := func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		received <- string(data)
+		return nil
+	}
+	if err := ps.Subscribe(ctx, "doc-1", "worker", handler); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if err := ps.PublishRaw(ctx, "doc-1", []byte("first"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if got := receiveMessage(t, ctx, received); got != "first" {
+		t.Fatalf("Expected first, got %s", got)
+	}
+
+	// No messages are delivered after unsubscribing
+	if err := ps.Unsubscribe(ctx, "doc-1", "worker"); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if err := ps.Unsubscribe(ctx, "doc-1", "worker"); err == nil {
+		t.Errorf("Expected error when unsubscribing twice")
+	}
+	if err := ps.PublishRaw(ctx, "doc-1", []byte("second"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	expectNoMessage(t, received, "")
+
+	// The durable consumer resumes with the messages published while it was down
+	if err := ps.Subscribe(ctx, "doc-1", "worker", handler); err != nil {
+		t.Fatalf("Failed to subscribe again: %v", err)
+	}
+	if got := receiveMessage(t, ctx, received); got != "second" {
+		t.Errorf("Expected second, got %s", got)
+	}
+	expectNoMessage(t, received, "")
+}
+
+func TestNATSPubSubReconnect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ps, conn := newTestNATSPubSub(t, ctx)
+
+	var reconnected int32
+	conn.SetReconnectHandler(func(*nats.Conn) { atomic.AddInt32(&reconnected, 1) })
+
+	received := make(chan string, 100)
+	if err := ps.Subscribe(ctx, "doc-1", "worker", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		received <- string(data)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if err := ps.PublishRaw(ctx, "doc-1", []byte("before"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if got := receiveMessage(t, ctx, received); got != "before" {
+		t.Fatalf("Expected before, got %s", got)
+	}
+
+	// The subscription keeps receiving after the connection is dropped and reconnects
+	if err := conn.ForceReconnect(); err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	for atomic.LoadInt32(&reconnected) == 0 || !conn.IsConnected() {
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for the connection to reconnect")
+		}
+	}
+	if err := ps.PublishRaw(ctx, "doc-1", []byte("after"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish after reconnecting: %v", err)
+	}
+	if got := receiveMessage(t, ctx, received); got != "after" {
+		t.Errorf("Expected after, got %s", got)
+	}
+}
@@ -0,0 +1,33 @@
+package crdtpubsub
+
+import "strings"
+
+// IsTopicPattern reports whether the topic is a pattern, i.e. contains '*'.
+func IsTopicPattern(topic string) bool {
+	return strings.Contains(topic, "*")
+}
+
+// MatchTopic reports whether the topic matches the pattern, in which '*' matches
+// any sequence of characters, e.g. "doc-*-updates" matches "doc-42-updates".
+// A pattern without '*' only matches itself.
+func MatchTopic(pattern string, topic string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == topic
+	}
+
+	// 첫 부분과 마지막 부분은 고정, 가운데 부분은 순서대로 찾음
+	first, last := parts[0], parts[len(parts)-1]
+	if len(topic) < len(first)+len(last) || !strings.HasPrefix(topic, first) || !strings.HasSuffix(topic, last) {
+		return false
+	}
+	rest := topic[len(first) : len(topic)-len(last)]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package crdtpubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{"doc-*-updates", "doc-42-updates", true},
+		{"doc-*-updates", "doc--updates", true},
+		{"doc-*-updates", "doc-42-presence", false},
+		{"doc-*", "doc-42-updates", true},
+		{"*-updates", "doc-42-updates", true},
+		{"*", "anything", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxcyyb", false},
+		{"ab*ba", "aba", false},
+		{"doc", "doc", true},
+		{"doc", "doc-42", false},
+	}
+
+	for _, test := range tests {
+		if got := MatchTopic(test.pattern, test.topic); got != test.want {
+			t.Errorf("MatchTopic(%q, %q) = %v, expected %v", test.pattern, test.topic, got, test.want)
+		}
+	}
+}
+
+func TestMemoryPubSubSubscribePattern(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pubsub, err := NewMemoryPubSub(NewOptions())
+	if err != nil {
+		t.Fatalf("Failed to create Memory PubSub: %v", err)
+	}
+	defer pubsub.Close()
+
+	received := make(chan string, 10)
+	if err := pubsub.SubscribePattern(ctx, "doc-*-updates", "worker", func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		received <- topic
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	for _, topic := range []string{"doc-1-updates", "doc-1-presence", "doc-2-updates"} {
+		if err := pubsub.PublishRaw(ctx, topic, []byte("patch"), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+	}
+
+	// Messages of different topics may be delivered in any order
+	topics := make(map[string]bool)
+	for len(topics) < 2 {
+		select {
+		case topic := <-received:
+			if topic != "doc-1-updates" && topic != "doc-2-updates" {
+				t.Fatalf("Unexpected message of %s", topic)
+			}
+			topics[topic] = true
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for messages, received %v", topics)
+		}
+	}
+	select {
+	case topic := <-received:
+		t.Errorf("Unexpected message of %s", topic)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Unsubscribing with the pattern removes the subscription
+	if err := pubsub.Unsubscribe(ctx, "doc-*-updates", "worker"); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if err := pubsub.PublishRaw(ctx, "doc-3-updates", []byte("patch"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	select {
+	case topic := <-received:
+		t.Errorf("Expected no message after unsubscribe, got %s", topic)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -17,6 +17,10 @@ const (
 	EncodingFormatText EncodingFormat = "text"
 	// EncodingFormatBase64 represents base64 encoding.
 	EncodingFormatBase64 EncodingFormat = "base64"
+	// EncodingFormatCBOR represents CBOR encoding.
+	EncodingFormatCBOR EncodingFormat = "cbor"
+	// EncodingFormatMsgPack represents MessagePack encoding.
+	EncodingFormatMsgPack EncodingFormat = "msgpack"
 )
 
 // PatchMessage represents a message containing a CRDT patch.
@@ -51,6 +55,15 @@ type Publisher interface {
 type Subscriber interface {
 	// Subscribe subscribes to the specified topic and calls the handler for each received message.
 	Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error
+	// SubscribeSince subscribes to the specified topic like Subscribe, and first calls the
+	// handler for the retained messages published after the cursor, e.g. the ones a
+	// reconnecting subscriber missed. It needs the RetentionStore of the options.
+	SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error
+	// SubscribePattern subscribes to every topic matching the pattern, in which '*' matches
+	// any sequence of characters (see MatchTopic), and calls the handler with the topic of
+	// each received message. '*' is reserved for patterns. The subscription is removed with
+	// Unsubscribe and the pattern.
+	SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error
 	// Unsubscribe unsubscribes from the specified topic.
 	Unsubscribe(ctx context.Context, topic string, subscriberID string) error
 	// Close closes the subscriber.
@@ -75,6 +88,12 @@ type Options struct {
 	Credentials map[string]string
 	// AdditionalOptions contains additional implementation-specific options.
 	AdditionalOptions map[string]interface{}
+	// RetentionStore keeps the published messages for SubscribeSince, or is nil.
+	// Publishers and subscribers of a topic must share it.
+	RetentionStore RetentionStore
+	// Metrics receives the delivery events of MemoryPubSub, or is nil. Other
+	// implementations are wrapped in a DeliveryPubSub to report them.
+	Metrics DeliveryMetrics
 }
 
 // NewOptions creates a new Options with default values.
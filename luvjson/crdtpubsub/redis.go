@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -96,10 +97,19 @@ func (ps *RedisPubSub) Publish(ctx context.Context, topic string, patch *crdtpat
 		return fmt.Errorf("failed to encode patch: %w", err)
 	}
 
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
 	// Create metadata
 	metadata := map[string]string{
 		"format": string(format),
 	}
+	if cursor != "" {
+		metadata[cursorMetadataKey] = cursor
+	}
 
 	// Create message
 	msg := PatchMessage{
@@ -130,10 +140,19 @@ func (ps *RedisPubSub) PublishRaw(ctx context.Context, topic string, data []byte
 		format = ps.options.DefaultFormat
 	}
 
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
 	// Create metadata
 	metadata := map[string]string{
 		"format": string(format),
 	}
+	if cursor != "" {
+		metadata[cursorMetadataKey] = cursor
+	}
 
 	// Create message
 	msg := PatchMessage{
@@ -173,14 +192,18 @@ func (ps *RedisPubSub) Subscribe(ctx context.Context, topic string, subscriberID
 		ps.pubsub = ps.client.Subscribe(ctx)
 	}
 
-	// Subscribe to the topic
-	if err := ps.pubsub.Subscribe(ctx, topic); err != nil {
+	// Subscribe to the topic, or to the channels matching the pattern
+	if IsTopicPattern(topic) {
+		if err := ps.pubsub.PSubscribe(ctx, redisPattern(topic)); err != nil {
+			return fmt.Errorf("failed to subscribe to pattern: %w", err)
+		}
+	} else if err := ps.pubsub.Subscribe(ctx, topic); err != nil {
 		return fmt.Errorf("failed to subscribe to topic: %w", err)
 	}
 
 	// Create a message handler that calls the subscriber function
 	messageHandler := func(msg PatchMessage) error {
-		return handler(ctx, msg.Topic, msg.Payload, msg.Format)
+		return handler(withCursor(ctx, msg.Metadata[cursorMetadataKey]), msg.Topic, msg.Payload, msg.Format)
 	}
 
 	// Create a new subscription
@@ -202,6 +225,36 @@ func (ps *RedisPubSub) Subscribe(ctx context.Context, topic string, subscriberID
 	return nil
 }
 
+// SubscribeSince subscribes to the specified topic and first calls the handler for the
+// retained messages published after the cursor.
+// This method implements the Subscriber interface.
+func (ps *RedisPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return SubscribeWithReplay(ctx, ps, ps.options.RetentionStore, topic, subscriberID, cursor, handler)
+}
+
+// SubscribePattern subscribes to every topic matching the pattern with PSUBSCRIBE.
+// This method implements the Subscriber interface.
+func (ps *RedisPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	return ps.Subscribe(ctx, pattern, subscriberID, handler)
+}
+
+// redisPattern returns the Redis glob pattern of a topic pattern, escaping the
+// glob characters other than '*'.
+func redisPattern(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(pattern)
+}
+
+// unsubscribeChannel unsubscribes the Redis client from a topic or pattern.
+func (ps *RedisPubSub) unsubscribeChannel(ctx context.Context, topic string) error {
+	if IsTopicPattern(topic) {
+		return ps.pubsub.PUnsubscribe(ctx, redisPattern(topic))
+	}
+	return ps.pubsub.Unsubscribe(ctx, topic)
+}
+
 // SubscribeWithHandler subscribes to the specified topic with a MessageHandler.
 // This is a convenience method that wraps the Subscribe method.
 func (ps *RedisPubSub) SubscribeWithHandler(ctx context.Context, topic string, handler MessageHandler) error {
@@ -241,7 +294,7 @@ func (ps *RedisPubSub) handleMessages(subscription *redisSubscription) {
 			if !ok {
 				return
 			}
-			if msg.Channel != subscription.topic {
+			if msg.Channel != subscription.topic && msg.Pattern != redisPattern(subscription.topic) {
 				continue
 			}
 
@@ -280,7 +333,7 @@ func (ps *RedisPubSub) Unsubscribe(ctx context.Context, topic string, subscriber
 	}
 
 	// Unsubscribe from the topic
-	if err := ps.pubsub.Unsubscribe(ctx, topic); err != nil {
+	if err := ps.unsubscribeChannel(ctx, topic); err != nil {
 		return fmt.Errorf("failed to unsubscribe from topic: %w", err)
 	}
 
@@ -313,7 +366,7 @@ func (ps *RedisPubSub) UnsubscribeAll(ctx context.Context, topic string) error {
 	}
 
 	// Unsubscribe from the topic
-	if err := ps.pubsub.Unsubscribe(ctx, topic); err != nil {
+	if err := ps.unsubscribeChannel(ctx, topic); err != nil {
 		return fmt.Errorf("failed to unsubscribe from topic: %w", err)
 	}
 
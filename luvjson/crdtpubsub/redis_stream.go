@@ -0,0 +1,551 @@
+package crdtpubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisStreamOptions represents configuration options for RedisStreamPubSub.
+type RedisStreamOptions struct {
+	// Consumer is the name of this process within the consumer groups.
+	// Processes subscribing with the same subscriber ID share its messages.
+	Consumer string
+	// StartID is the ID from which a new consumer group reads: "$" for new
+	// messages only, "0" for the whole stream.
+	StartID string
+	// MaxLen is the approximate maximum length of the streams, or 0 for no limit.
+	MaxLen int64
+	// BatchSize is the maximum number of messages read at once.
+	BatchSize int64
+	// BlockTimeout is how long a read waits for new messages.
+	BlockTimeout time.Duration
+	// ClaimIdle is how long a message stays unacknowledged before it is delivered
+	// again, to this or another consumer of the group, or 0 to never deliver it
+	// again while the subscription runs.
+	ClaimIdle time.Duration
+	// RetryDelay is how long to wait after a failed read.
+	RetryDelay time.Duration
+	// DiscoverInterval is how often pattern subscriptions look for new streams.
+	DiscoverInterval time.Duration
+}
+
+// NewRedisStreamOptions creates a new RedisStreamOptions with default values.
+func NewRedisStreamOptions() *RedisStreamOptions {
+	return &RedisStreamOptions{
+		Consumer:         "consumer",
+		StartID:          "$",
+		BatchSize:        100,
+		BlockTimeout:     time.Second,
+		ClaimIdle:        30 * time.Second,
+		RetryDelay:       time.Second,
+		DiscoverInterval: 5 * time.Second,
+	}
+}
+
+// RedisStreamPubSub implements the PubSub interface using Redis Streams.
+// Each topic is a stream and each subscriber ID is a consumer group, so messages
+// are kept until they are acknowledged and a subscriber that restarts receives
+// the messages published while it was down.
+type RedisStreamPubSub struct {
+	// client is the Redis client.
+	client *redis.Client
+	// options contains the configuration options.
+	options *Options
+	// streamOptions contains the stream configuration options.
+	streamOptions *RedisStreamOptions
+	// subscriptions is a map of topic and subscriber ID to subscription.
+	subscriptions map[streamSubscriptionKey]*redisStreamSubscription
+	// mutex protects the subscriptions map.
+	mutex sync.RWMutex
+	// closed indicates whether the PubSub has been closed.
+	closed bool
+}
+
+// streamSubscriptionKey identifies a subscription to a stream.
+type streamSubscriptionKey struct {
+	topic        string
+	subscriberID string
+}
+
+// redisStreamSubscription represents a subscription to a Redis stream.
+type redisStreamSubscription struct {
+	// topic is the stream being read.
+	topic string
+	// group is the consumer group, the subscriber ID.
+	group string
+	// handler is the subscriber function.
+	handler SubscriberFunc
+	// ctx is the context for the subscription.
+	ctx context.Context
+	// cancel is the cancel function for the context.
+	cancel context.CancelFunc
+	// done is a channel that is closed when the subscription is done.
+	done chan struct{}
+	// children is a map of stream to subscription, for a pattern subscription.
+	children map[string]*redisStreamSubscription
+}
+
+// NewRedisStreamPubSub creates a new RedisStreamPubSub with the specified Redis client and options.
+// If streamOptions is nil, default options are used, with the client ID of options as consumer name.
+func NewRedisStreamPubSub(client *redis.Client, options *Options, streamOptions *RedisStreamOptions) (*RedisStreamPubSub, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+
+	if options == nil {
+		options = NewOptions()
+	}
+	if streamOptions == nil {
+		streamOptions = NewRedisStreamOptions()
+		if options.ClientID != "" {
+			streamOptions.Consumer = options.ClientID
+		}
+	}
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &RedisStreamPubSub{
+		client:        client,
+		options:       options,
+		streamOptions: streamOptions,
+		subscriptions: make(map[streamSubscriptionKey]*redisStreamSubscription),
+	}, nil
+}
+
+// Publish publishes a patch to the specified topic.
+func (ps *RedisStreamPubSub) Publish(ctx context.Context, topic string, patch *crdtpatch.Patch, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Get the encoder for the format
+	encoder, err := GetEncoderDecoder(format)
+	if err != nil {
+		return err
+	}
+
+	// Encode the patch
+	data, err := encoder.Encode(patch)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	return ps.PublishRaw(ctx, topic, data, format)
+}
+
+// PublishRaw publishes raw data to the specified topic.
+func (ps *RedisStreamPubSub) PublishRaw(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	// Use the specified format or the default format
+	if format == "" {
+		format = ps.options.DefaultFormat
+	}
+
+	// Keep the message for SubscribeSince
+	cursor, err := retain(ctx, ps.options, topic, data, format)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"format":  string(format),
+		"payload": data,
+	}
+	if cursor != "" {
+		values[cursorMetadataKey] = cursor
+	}
+
+	args := &redis.XAddArgs{
+		Stream: topic,
+		Values: values,
+	}
+	if ps.streamOptions.MaxLen > 0 {
+		args.MaxLen = ps.streamOptions.MaxLen
+		args.Approx = true
+	}
+
+	if err := ps.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to add message to stream: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the specified topic and calls the handler for each received message.
+// The subscriber ID is the consumer group: messages are acknowledged once the handler
+// returns nil, and are delivered again after ClaimIdle or when the subscriber restarts otherwise.
+// This method implements the Subscriber interface.
+func (ps *RedisStreamPubSub) Subscribe(ctx context.Context, topic string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	key := streamSubscriptionKey{topic: topic, subscriberID: subscriberID}
+	if _, ok := ps.subscriptions[key]; ok {
+		return fmt.Errorf("already subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+
+	subscription, err := ps.newSubscription(ctx, topic, subscriberID, ps.streamOptions.StartID, handler)
+	if err != nil {
+		return err
+	}
+	ps.subscriptions[key] = subscription
+
+	// Start a goroutine to read the stream
+	go ps.consume(subscription)
+
+	return nil
+}
+
+// newSubscription creates the consumer group of a stream, unless it already exists,
+// and returns a subscription reading it.
+func (ps *RedisStreamPubSub) newSubscription(ctx context.Context, topic string, subscriberID string, startID string, handler SubscriberFunc) (*redisStreamSubscription, error) {
+	err := ps.client.XGroupCreateMkStream(ctx, topic, subscriberID, startID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	return &redisStreamSubscription{
+		topic:   topic,
+		group:   subscriberID,
+		handler: handler,
+		ctx:     subCtx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// SubscribePattern subscribes to every stream matching the pattern. The streams are
+// looked for every DiscoverInterval; those created after the subscription are read
+// from their first message.
+// This method implements the Subscriber interface.
+func (ps *RedisStreamPubSub) SubscribePattern(ctx context.Context, pattern string, subscriberID string, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	key := streamSubscriptionKey{topic: pattern, subscriberID: subscriberID}
+	if _, ok := ps.subscriptions[key]; ok {
+		return fmt.Errorf("already subscribed to topic: %s with subscriberID: %s", pattern, subscriberID)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	subscription := &redisStreamSubscription{
+		topic:    pattern,
+		group:    subscriberID,
+		handler:  handler,
+		ctx:      subCtx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		children: make(map[string]*redisStreamSubscription),
+	}
+	ps.subscriptions[key] = subscription
+
+	// Start a goroutine to look for the streams
+	go ps.discover(subscription)
+
+	return nil
+}
+
+// discover looks for the streams of a pattern subscription until it is cancelled,
+// and reads each of them.
+func (ps *RedisStreamPubSub) discover(subscription *redisStreamSubscription) {
+	defer close(subscription.done)
+
+	ticker := time.NewTicker(ps.streamOptions.DiscoverInterval)
+	defer ticker.Stop()
+
+	// 구독 이후에 생긴 스트림은 처음부터 읽음
+	startID := ps.streamOptions.StartID
+	for {
+		ps.discoverStreams(subscription, startID)
+		startID = "0"
+
+		select {
+		case <-subscription.ctx.Done():
+			for _, child := range subscription.children {
+				<-child.done
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discoverStreams starts reading the streams matching the pattern that are not read yet.
+func (ps *RedisStreamPubSub) discoverStreams(subscription *redisStreamSubscription, startID string) {
+	iter := ps.client.ScanType(subscription.ctx, 0, redisPattern(subscription.topic), ps.streamOptions.BatchSize, "stream").Iterator()
+	for iter.Next(subscription.ctx) {
+		stream := iter.Val()
+		if _, ok := subscription.children[stream]; ok || !MatchTopic(subscription.topic, stream) {
+			continue
+		}
+
+		child, err := ps.newSubscription(subscription.ctx, stream, subscription.group, startID, subscription.handler)
+		if err != nil {
+			fmt.Printf("failed to subscribe to stream %s: %v\n", stream, err)
+			continue
+		}
+		subscription.children[stream] = child
+		go ps.consume(child)
+	}
+	if err := iter.Err(); err != nil && subscription.ctx.Err() == nil {
+		fmt.Printf("failed to look for streams matching %s: %v\n", subscription.topic, err)
+	}
+}
+
+// consume reads the stream of a subscription until it is cancelled.
+func (ps *RedisStreamPubSub) consume(subscription *redisStreamSubscription) {
+	defer close(subscription.done)
+
+	// 이전에 전달되었지만 확인되지 않은 메시지부터 다시 처리
+	pendingID := "0"
+	var lastClaim time.Time
+	for subscription.ctx.Err() == nil {
+		if idle := ps.streamOptions.ClaimIdle; idle > 0 && time.Since(lastClaim) >= idle {
+			ps.claim(subscription)
+			lastClaim = time.Now()
+		}
+
+		args := &redis.XReadGroupArgs{
+			Group:    subscription.group,
+			Consumer: ps.streamOptions.Consumer,
+			Streams:  []string{subscription.topic, ">"},
+			Count:    ps.streamOptions.BatchSize,
+			Block:    ps.streamOptions.BlockTimeout,
+		}
+		if pendingID != "" {
+			args.Streams[1] = pendingID
+			args.Block = -1
+		}
+
+		streams, err := ps.client.XReadGroup(subscription.ctx, args).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if subscription.ctx.Err() != nil {
+				return
+			}
+			fmt.Printf("failed to read stream %s: %v\n", subscription.topic, err)
+			ps.wait(subscription, ps.streamOptions.RetryDelay)
+			continue
+		}
+
+		var messages []redis.XMessage
+		if len(streams) > 0 {
+			messages = streams[0].Messages
+		}
+		if pendingID != "" {
+			if len(messages) == 0 {
+				pendingID = ""
+			} else {
+				pendingID = messages[len(messages)-1].ID
+			}
+		}
+		for _, msg := range messages {
+			ps.handle(subscription, msg)
+		}
+	}
+}
+
+// claim takes over the messages of the group that stayed unacknowledged for
+// ClaimIdle, e.g. because their consumer died or their handler failed, and
+// handles them again.
+func (ps *RedisStreamPubSub) claim(subscription *redisStreamSubscription) {
+	start := "0-0"
+	for subscription.ctx.Err() == nil {
+		messages, next, err := ps.client.XAutoClaim(subscription.ctx, &redis.XAutoClaimArgs{
+			Stream:   subscription.topic,
+			Group:    subscription.group,
+			Consumer: ps.streamOptions.Consumer,
+			MinIdle:  ps.streamOptions.ClaimIdle,
+			Start:    start,
+			Count:    ps.streamOptions.BatchSize,
+		}).Result()
+		if err != nil {
+			if subscription.ctx.Err() == nil {
+				fmt.Printf("failed to claim messages of stream %s: %v\n", subscription.topic, err)
+			}
+			return
+		}
+		for _, msg := range messages {
+			ps.handle(subscription, msg)
+		}
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
+
+// handle calls the handler of a subscription for a message and acknowledges it
+// if the handler succeeds.
+func (ps *RedisStreamPubSub) handle(subscription *redisStreamSubscription, msg redis.XMessage) {
+	data, format, err := streamMessage(msg)
+	if err != nil {
+		// 처리할 수 없는 메시지는 다시 전달되지 않도록 확인 처리
+		fmt.Printf("failed to decode message %s: %v\n", msg.ID, err)
+	} else if err := subscription.handler(withCursor(subscription.ctx, streamCursor(msg)), subscription.topic, data, format); err != nil {
+		// Log the error and leave the message pending
+		fmt.Printf("failed to handle message %s: %v\n", msg.ID, err)
+		return
+	}
+
+	if err := ps.client.XAck(subscription.ctx, subscription.topic, subscription.group, msg.ID).Err(); err != nil && subscription.ctx.Err() == nil {
+		fmt.Printf("failed to acknowledge message %s: %v\n", msg.ID, err)
+	}
+}
+
+// wait waits for the specified duration or until the subscription is cancelled.
+func (ps *RedisStreamPubSub) wait(subscription *redisStreamSubscription, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-subscription.ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// Replay calls the handler for the messages of the topic from the message fromID,
+// included, to the last one, without a consumer group. "0" replays the whole
+// stream. It returns the ID of the last message handled, from which a later replay
+// can continue, and stops at the first error of the handler.
+func (ps *RedisStreamPubSub) Replay(ctx context.Context, topic string, fromID string, handler SubscriberFunc) (string, error) {
+	if ps.closed {
+		return "", fmt.Errorf("pubsub is closed")
+	}
+
+	lastID := ""
+	start := fromID
+	for {
+		messages, err := ps.client.XRangeN(ctx, topic, start, "+", ps.streamOptions.BatchSize).Result()
+		if err != nil {
+			return lastID, fmt.Errorf("failed to read stream: %w", err)
+		}
+		if len(messages) == 0 {
+			return lastID, nil
+		}
+
+		for _, msg := range messages {
+			data, format, err := streamMessage(msg)
+			if err != nil {
+				return lastID, err
+			}
+			if err := handler(ctx, topic, data, format); err != nil {
+				return lastID, err
+			}
+			lastID = msg.ID
+		}
+		start = "(" + lastID
+	}
+}
+
+// streamMessage returns the payload and format of a stream message.
+func streamMessage(msg redis.XMessage) ([]byte, EncodingFormat, error) {
+	payload, ok := msg.Values["payload"].(string)
+	if !ok {
+		return nil, "", fmt.Errorf("message %s has no payload", msg.ID)
+	}
+	format, _ := msg.Values["format"].(string)
+	return []byte(payload), EncodingFormat(format), nil
+}
+
+// streamCursor returns the retention cursor of a stream message, or "" if it has none.
+func streamCursor(msg redis.XMessage) string {
+	cursor, _ := msg.Values[cursorMetadataKey].(string)
+	return cursor
+}
+
+// SubscribeSince subscribes to the specified topic and first calls the handler for the
+// retained messages published after the cursor.
+// This method implements the Subscriber interface.
+func (ps *RedisStreamPubSub) SubscribeSince(ctx context.Context, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+	return SubscribeWithReplay(ctx, ps, ps.options.RetentionStore, topic, subscriberID, cursor, handler)
+}
+
+// Unsubscribe unsubscribes from the specified topic.
+// The consumer group is kept, so a later subscription resumes where this one stopped.
+// This method implements the Subscriber interface.
+func (ps *RedisStreamPubSub) Unsubscribe(ctx context.Context, topic string, subscriberID string) error {
+	if ps.closed {
+		return fmt.Errorf("pubsub is closed")
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// Check if subscribed with this subscriberID
+	key := streamSubscriptionKey{topic: topic, subscriberID: subscriberID}
+	subscription, ok := ps.subscriptions[key]
+	if !ok {
+		return fmt.Errorf("not subscribed to topic: %s with subscriberID: %s", topic, subscriberID)
+	}
+
+	// Cancel the subscription and wait for it to finish
+	subscription.cancel()
+	<-subscription.done
+
+	delete(ps.subscriptions, key)
+	return nil
+}
+
+// Close closes the PubSub.
+func (ps *RedisStreamPubSub) Close() error {
+	if ps.closed {
+		return nil
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// Mark as closed
+	ps.closed = true
+
+	// Cancel all subscriptions
+	for _, subscription := range ps.subscriptions {
+		subscription.cancel()
+	}
+
+	// Wait for all subscriptions to finish
+	for _, subscription := range ps.subscriptions {
+		<-subscription.done
+	}
+
+	// Close the Redis client
+	if err := ps.client.Close(); err != nil {
+		return fmt.Errorf("failed to close Redis client: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package crdtpubsub
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// newTestRedisStreamPubSub creates a RedisStreamPubSub on a local Redis server and a stream
+// name that is deleted after the test. The test is skipped if Redis is not available.
+func newTestRedisStreamPubSub(t *testing.T, ctx context.Context) (*RedisStreamPubSub, *redis.Client, string) {
+	t.Helper()
+
+	// The PubSub closes its client, so the stream is inspected and deleted with another one
+	admin := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { admin.Close() })
+	if err := admin.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis is not available: %v", err)
+	}
+
+	topic := fmt.Sprintf("crdtpubsub-test-%d", time.Now().UnixNano())
+	t.Cleanup(func() { admin.Del(context.Background(), topic) })
+
+	streamOptions := NewRedisStreamOptions()
+	streamOptions.StartID = "0"
+	streamOptions.BlockTimeout = 100 * time.Millisecond
+	streamOptions.ClaimIdle = 200 * time.Millisecond
+	streamOptions.RetryDelay = 100 * time.Millisecond
+
+	ps, err := NewRedisStreamPubSub(redis.NewClient(&redis.Options{Addr: "localhost:6379"}), NewOptions(), streamOptions)
+	if err != nil {
+		t.Fatalf("Failed to create Redis stream PubSub: %v", err)
+	}
+	t.Cleanup(func() { ps.Close() })
+	return ps, admin, topic
+}
+
+// receiveMessage waits for the next message delivered to the handler.
+func receiveMessage(t *testing.T, ctx context.Context, received <-chan string) string {
+	t.Helper()
+
+	select {
+	case got := <-received:
+		return got
+	case <-ctx.Done():
+		t.Fatalf("Timed out waiting for a message")
+		return ""
+	}
+}
+
+func TestRedisStreamPubSubAckAndRedelivery(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ps, admin, topic := newTestRedisStreamPubSub(t, ctx)
+
+	// The handler fails the first delivery of "second"
+	var attempts int32
+	received := make(chan string, 100)
+	handler := func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		received <- string(data)
+		if string(data) == "second" && atomic.AddInt32(&attempts, 1) == 1 {
+			return fmt.Errorf("handler failed")
+		}
+		return nil
+	}
+	if err := ps.Subscribe(ctx, topic, "worker", handler); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	for _, payload := range []string{"first", "second", "third"} {
+		if err := ps.PublishRaw(ctx, topic, []byte(payload), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+	}
+
+	// Messages are delivered in order, and the failed one again after ClaimIdle
+	for _, want := range []string{"first", "second", "third", "second"} {
+		if got := receiveMessage(t, ctx, received); got != want {
+			t.Fatalf("Expected %s, got %s", want, got)
+		}
+	}
+
+	// Every message of the group is acknowledged once handled
+	for {
+		pending, err := admin.XPending(ctx, topic, "worker").Result()
+		if err != nil {
+			t.Fatalf("Failed to get pending messages: %v", err)
+		}
+		if pending.Count == 0 {
+			break
+		}
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatalf("Expected no pending messages, got %d", pending.Count)
+		}
+	}
+	expectNoMessage(t, received, "")
+
+	// A subscriber that restarts receives only the messages published while it was down
+	if err := ps.Unsubscribe(ctx, topic, "worker"); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if err := ps.PublishRaw(ctx, topic, []byte("fourth"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	expectNoMessage(t, received, "")
+	if err := ps.Subscribe(ctx, topic, "worker", handler); err != nil {
+		t.Fatalf("Failed to subscribe again: %v", err)
+	}
+	if got := receiveMessage(t, ctx, received); got != "fourth" {
+		t.Errorf("Expected fourth, got %s", got)
+	}
+	expectNoMessage(t, received, "")
+}
@@ -0,0 +1,269 @@
+package crdtpubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Cursor is the position of a message among the retained messages of a topic.
+// Cursors of a topic increase with each published message; the zero cursor is
+// before the first message.
+type Cursor uint64
+
+// String returns the cursor as a decimal number.
+func (c Cursor) String() string {
+	return strconv.FormatUint(uint64(c), 10)
+}
+
+// ParseCursor parses a cursor returned by Cursor.String.
+func ParseCursor(s string) (Cursor, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q: %w", s, err)
+	}
+	return Cursor(n), nil
+}
+
+// cursorMetadataKey is the message metadata key holding the cursor of a message.
+const cursorMetadataKey = "cursor"
+
+// ErrCursorExpired is returned when the messages after a cursor are no longer
+// retained. The subscriber must then get the whole document again.
+var ErrCursorExpired = errors.New("cursor is older than the retained messages")
+
+// RetainedMessage represents a message kept by a RetentionStore.
+type RetainedMessage struct {
+	// Cursor is the position of the message in its topic.
+	Cursor Cursor
+	// Topic is the topic the message was published to.
+	Topic string
+	// Payload is the encoded patch data.
+	Payload []byte
+	// Format is the encoding format used for the payload.
+	Format EncodingFormat
+}
+
+// RetentionStore keeps the messages published to each topic, so that
+// subscribers that reconnect receive the messages they missed.
+type RetentionStore interface {
+	// Append keeps a message and returns its cursor.
+	Append(ctx context.Context, topic string, data []byte, format EncodingFormat) (Cursor, error)
+	// Since returns the messages of the topic after the cursor, in publishing order.
+	// It returns ErrCursorExpired if some of them are no longer retained.
+	Since(ctx context.Context, topic string, cursor Cursor) ([]RetainedMessage, error)
+}
+
+// MemoryRetentionStore is an in-memory RetentionStore keeping the last messages of each topic.
+type MemoryRetentionStore struct {
+	// limit is the maximum number of messages kept per topic, or 0 for no limit.
+	limit int
+	// topics is a map of topic to retained messages.
+	topics map[string]*retainedTopic
+	// mutex protects the topics map.
+	mutex sync.RWMutex
+}
+
+// retainedTopic represents the retained messages of a topic.
+type retainedTopic struct {
+	// messages are the retained messages, in publishing order.
+	messages []RetainedMessage
+	// last is the cursor of the last published message.
+	last Cursor
+}
+
+// NewMemoryRetentionStore creates a new MemoryRetentionStore keeping up to limit
+// messages per topic, or every message if limit is 0.
+func NewMemoryRetentionStore(limit int) *MemoryRetentionStore {
+	return &MemoryRetentionStore{
+		limit:  limit,
+		topics: make(map[string]*retainedTopic),
+	}
+}
+
+// Append keeps a message and returns its cursor.
+func (s *MemoryRetentionStore) Append(ctx context.Context, topic string, data []byte, format EncodingFormat) (Cursor, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, ok := s.topics[topic]
+	if !ok {
+		t = &retainedTopic{}
+		s.topics[topic] = t
+	}
+
+	t.last++
+	t.messages = append(t.messages, RetainedMessage{
+		Cursor:  t.last,
+		Topic:   topic,
+		Payload: append([]byte(nil), data...),
+		Format:  format,
+	})
+	if s.limit > 0 && len(t.messages) > s.limit {
+		t.messages = append([]RetainedMessage(nil), t.messages[len(t.messages)-s.limit:]...)
+	}
+	return t.last, nil
+}
+
+// Since returns the messages of the topic after the cursor, in publishing order.
+func (s *MemoryRetentionStore) Since(ctx context.Context, topic string, cursor Cursor) ([]RetainedMessage, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	t, ok := s.topics[topic]
+	if !ok || cursor >= t.last {
+		return nil, nil
+	}
+	if cursor+1 < t.messages[0].Cursor {
+		return nil, ErrCursorExpired
+	}
+	start := int(cursor + 1 - t.messages[0].Cursor)
+	return append([]RetainedMessage(nil), t.messages[start:]...), nil
+}
+
+// cursorContextKey is the context key holding the cursor of a delivered message.
+type cursorContextKey struct{}
+
+// ContextWithCursor returns a context holding the cursor of a delivered message.
+func ContextWithCursor(ctx context.Context, cursor Cursor) context.Context {
+	return context.WithValue(ctx, cursorContextKey{}, cursor)
+}
+
+// CursorFromContext returns the cursor of the message passed to a SubscriberFunc.
+// A subscriber stores it to resume with SubscribeSince after reconnecting.
+// It returns false if the message was not retained.
+func CursorFromContext(ctx context.Context) (Cursor, bool) {
+	cursor, ok := ctx.Value(cursorContextKey{}).(Cursor)
+	return cursor, ok
+}
+
+// retain keeps a message in the retention store of options, if any, and returns
+// its cursor as a metadata value, or "" if it was not retained.
+func retain(ctx context.Context, options *Options, topic string, data []byte, format EncodingFormat) (string, error) {
+	if options.RetentionStore == nil {
+		return "", nil
+	}
+	cursor, err := options.RetentionStore.Append(ctx, topic, data, format)
+	if err != nil {
+		return "", fmt.Errorf("failed to retain message: %w", err)
+	}
+	return cursor.String(), nil
+}
+
+// withCursor returns a context holding the cursor of a metadata value, if it is set.
+func withCursor(ctx context.Context, value string) context.Context {
+	if value == "" {
+		return ctx
+	}
+	cursor, err := ParseCursor(value)
+	if err != nil {
+		return ctx
+	}
+	return ContextWithCursor(ctx, cursor)
+}
+
+// SubscribeWithReplay subscribes to the specified topic of subscriber and first calls the
+// handler for the messages of store after the cursor. The messages published during the
+// replay are delivered after it, and those already replayed are skipped, so the handler
+// receives each retained message once and in order. It implements SubscribeSince for the
+// PubSub implementations.
+func SubscribeWithReplay(ctx context.Context, subscriber Subscriber, store RetentionStore, topic string, subscriberID string, cursor Cursor, handler SubscriberFunc) error {
+	if store == nil {
+		return fmt.Errorf("retention store is not configured")
+	}
+
+	type pendingMessage struct {
+		ctx    context.Context
+		data   []byte
+		format EncodingFormat
+	}
+
+	var (
+		mutex     sync.Mutex
+		replaying = true
+		pending   []pendingMessage
+		last      = cursor
+	)
+
+	// seen reports whether the message of ctx was already delivered, and records
+	// its cursor otherwise. The caller must hold the mutex.
+	seen := func(ctx context.Context) bool {
+		c, ok := CursorFromContext(ctx)
+		if !ok {
+			return false
+		}
+		if c <= last {
+			return true
+		}
+		last = c
+		return false
+	}
+
+	// 재생이 끝날 때까지 새 메시지를 보관
+	err := subscriber.Subscribe(ctx, topic, subscriberID, func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		mutex.Lock()
+		if replaying {
+			pending = append(pending, pendingMessage{ctx: ctx, data: data, format: format})
+			mutex.Unlock()
+			return nil
+		}
+		skip := seen(ctx)
+		mutex.Unlock()
+		if skip {
+			return nil
+		}
+		return handler(ctx, topic, data, format)
+	})
+	if err != nil {
+		return err
+	}
+
+	messages, err := store.Since(ctx, topic, cursor)
+	if err != nil {
+		if unsubscribeErr := subscriber.Unsubscribe(ctx, topic, subscriberID); unsubscribeErr != nil {
+			fmt.Printf("failed to unsubscribe from topic %s: %v\n", topic, unsubscribeErr)
+		}
+		return fmt.Errorf("failed to read retained messages: %w", err)
+	}
+	for _, msg := range messages {
+		msgCtx := ContextWithCursor(ctx, msg.Cursor)
+		mutex.Lock()
+		skip := seen(msgCtx)
+		mutex.Unlock()
+		if skip {
+			continue
+		}
+		if err := handler(msgCtx, topic, msg.Payload, msg.Format); err != nil {
+			// Log the error but continue
+			fmt.Printf("failed to handle retained message %s: %v\n", msg.Cursor, err)
+		}
+	}
+
+	// 재생 중에 받은 메시지를 전달한 후 실시간 전달로 전환
+	for {
+		mutex.Lock()
+		if len(pending) == 0 {
+			replaying = false
+			mutex.Unlock()
+			return nil
+		}
+		batch := pending
+		pending = nil
+		mutex.Unlock()
+
+		for _, msg := range batch {
+			mutex.Lock()
+			skip := seen(msg.ctx)
+			mutex.Unlock()
+			if skip {
+				continue
+			}
+			if err := handler(msg.ctx, topic, msg.data, msg.format); err != nil {
+				// Log the error but continue
+				fmt.Printf("failed to handle message: %v\n", err)
+			}
+		}
+	}
+}
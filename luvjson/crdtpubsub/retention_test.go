@@ -0,0 +1,90 @@
+package crdtpubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryRetentionStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRetentionStore(2)
+
+	for i := 1; i <= 3; i++ {
+		cursor, err := store.Append(ctx, "doc", []byte(fmt.Sprintf("patch-%d", i)), EncodingFormatJSON)
+		if err != nil {
+			t.Fatalf("Failed to append message: %v", err)
+		}
+		if cursor != Cursor(i) {
+			t.Errorf("Expected cursor %d, got %s", i, cursor)
+		}
+	}
+
+	messages, err := store.Since(ctx, "doc", 1)
+	if err != nil {
+		t.Fatalf("Failed to read messages: %v", err)
+	}
+	if len(messages) != 2 || string(messages[0].Payload) != "patch-2" || messages[1].Cursor != 3 {
+		t.Errorf("Unexpected messages: %+v", messages)
+	}
+
+	if messages, err := store.Since(ctx, "doc", 3); err != nil || len(messages) != 0 {
+		t.Errorf("Expected no messages after the last cursor, got %d, %v", len(messages), err)
+	}
+
+	// The first message is no longer retained
+	if _, err := store.Since(ctx, "doc", 0); !errors.Is(err, ErrCursorExpired) {
+		t.Errorf("Expected ErrCursorExpired, got %v", err)
+	}
+}
+
+func TestMemoryPubSubSubscribeSince(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := NewOptions()
+	options.RetentionStore = NewMemoryRetentionStore(0)
+	pubsub, err := NewMemoryPubSub(options)
+	if err != nil {
+		t.Fatalf("Failed to create Memory PubSub: %v", err)
+	}
+	defer pubsub.Close()
+
+	// Messages published while the subscriber is away
+	for i := 1; i <= 3; i++ {
+		if err := pubsub.PublishRaw(ctx, "doc", []byte(fmt.Sprintf("patch-%d", i)), EncodingFormatJSON); err != nil {
+			t.Fatalf("Failed to publish message: %v", err)
+		}
+	}
+
+	type received struct {
+		data   string
+		cursor Cursor
+	}
+	ch := make(chan received, 10)
+	err = pubsub.SubscribeSince(ctx, "doc", "client", 1, func(ctx context.Context, topic string, data []byte, format EncodingFormat) error {
+		cursor, _ := CursorFromContext(ctx)
+		ch <- received{data: string(data), cursor: cursor}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	if err := pubsub.PublishRaw(ctx, "doc", []byte("patch-4"), EncodingFormatJSON); err != nil {
+		t.Fatalf("Failed to publish message: %v", err)
+	}
+
+	for i := 2; i <= 4; i++ {
+		select {
+		case msg := <-ch:
+			if msg.data != fmt.Sprintf("patch-%d", i) || msg.cursor != Cursor(i) {
+				t.Errorf("Expected patch-%d at cursor %d, got %s at cursor %s", i, i, msg.data, msg.cursor)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for patch-%d", i)
+		}
+	}
+}
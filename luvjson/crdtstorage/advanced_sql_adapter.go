@@ -396,6 +396,10 @@ func (a *AdvancedSQLAdapter) saveSnapshotInTransaction(ctx context.Context, tx *
 	if err != nil {
 		return fmt.Errorf("failed to marshal snapshot data: %w", err)
 	}
+	dataJSON, err = compressSnapshotData(a.snapshotOptions.Compression, dataJSON)
+	if err != nil {
+		return err
+	}
 
 	// 메타데이터 직렬화
 	metadataJSON, err := json.Marshal(snapshot.Metadata)
@@ -445,8 +449,12 @@ func (a *AdvancedSQLAdapter) LoadSnapshot(ctx context.Context, documentID string
 	}
 
 	// 데이터 역직렬화
+	decompressed, err := decompressSnapshotData([]byte(dataJSON))
+	if err != nil {
+		return nil, err
+	}
 	var data interface{}
-	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+	if err := json.Unmarshal(decompressed, &data); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal snapshot data: %w", err)
 	}
 
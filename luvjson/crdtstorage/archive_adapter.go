@@ -0,0 +1,368 @@
+package crdtstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// documentArchiver는 유휴 문서를 콜드 저장소로 옮길 수 있는 영구 저장소 어댑터입니다.
+type documentArchiver interface {
+	Archive(ctx context.Context) (int, error)
+}
+
+// ArchiveOptions는 보관 어댑터 옵션을 나타냅니다.
+type ArchiveOptions struct {
+	// Prefix는 보관된 문서 객체 키 접두사입니다.
+	Prefix string
+
+	// IdleAfter는 문서를 콜드 저장소로 옮기기까지의 유휴 기간입니다.
+	// 이 기간 동안 로드되거나 저장되지 않은 문서가 Archive에서 보관됩니다.
+	IdleAfter time.Duration
+}
+
+// DefaultArchiveOptions는 기본 보관 어댑터 옵션을 반환합니다.
+func DefaultArchiveOptions() *ArchiveOptions {
+	return &ArchiveOptions{
+		Prefix:    "archive",
+		IdleAfter: 30 * 24 * time.Hour,
+	}
+}
+
+// ArchiveStats는 보관 어댑터의 문서 로드 통계입니다.
+type ArchiveStats struct {
+	// HotHits는 기본 영구 저장소에서 로드된 횟수입니다.
+	HotHits int64
+
+	// ColdHits는 콜드 저장소에서 로드된 횟수입니다.
+	ColdHits int64
+
+	// Misses는 어느 저장소에서도 문서를 찾지 못한 횟수입니다.
+	Misses int64
+
+	// Archived는 콜드 저장소로 옮겨진 문서 수입니다.
+	Archived int64
+
+	// Rehydrated는 콜드 저장소에서 기본 영구 저장소로 되돌아온 문서 수입니다.
+	Rehydrated int64
+}
+
+// HotHitRate는 로드된 문서 중 기본 영구 저장소에서 로드된 비율을 반환합니다.
+func (s ArchiveStats) HotHitRate() float64 {
+	if hits := s.HotHits + s.ColdHits; hits > 0 {
+		return float64(s.HotHits) / float64(hits)
+	}
+	return 0
+}
+
+// ColdHitRate는 로드된 문서 중 콜드 저장소에서 로드된 비율을 반환합니다.
+func (s ArchiveStats) ColdHitRate() float64 {
+	if hits := s.HotHits + s.ColdHits; hits > 0 {
+		return float64(s.ColdHits) / float64(hits)
+	}
+	return 0
+}
+
+// ArchiveAdapter는 오래 사용되지 않은 문서를 파일이나 S3 같은 콜드 저장소로 옮기는 어댑터입니다.
+// 보관된 문서는 LoadDocument에서 콜드 저장소로부터 그대로 로드되고,
+// 다음에 저장될 때 기본 영구 저장소로 되돌아오며 콜드 저장소에서 삭제됩니다.
+type ArchiveAdapter struct {
+	// persistence는 자주 사용되는 문서가 저장될 기본 영구 저장소입니다.
+	persistence PersistenceAdapter
+
+	// store는 보관된 문서가 저장될 콜드 저장소입니다.
+	store ObjectStore
+
+	// prefix는 보관된 문서 객체 키 접두사입니다.
+	prefix string
+
+	// idleAfter는 문서를 보관하기까지의 유휴 기간입니다.
+	idleAfter time.Duration
+
+	// moveMutex는 문서를 옮기는 동안 저장과 로드를 막습니다.
+	// 저장과 로드는 읽기 락을, 문서 이동은 쓰기 락을 사용합니다.
+	moveMutex sync.RWMutex
+
+	// mutex는 접근 시간, 보관 상태, 통계에 대한 동시 접근을 보호합니다.
+	mutex sync.Mutex
+
+	// lastAccess는 문서 ID에서 마지막으로 로드되거나 저장된 시간으로의 맵입니다.
+	lastAccess map[string]time.Time
+
+	// archived는 콜드 저장소에 보관된 것으로 알려진 문서 집합입니다.
+	archived map[string]bool
+
+	// stats는 문서 로드 통계입니다.
+	stats ArchiveStats
+
+	// now는 현재 시간을 반환합니다.
+	now func() time.Time
+}
+
+// NewArchiveAdapter는 새 보관 어댑터를 생성합니다.
+func NewArchiveAdapter(persistence PersistenceAdapter, store ObjectStore, options *ArchiveOptions) (*ArchiveAdapter, error) {
+	if persistence == nil {
+		return nil, fmt.Errorf("persistence cannot be nil")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("object store cannot be nil")
+	}
+	if options == nil {
+		options = DefaultArchiveOptions()
+	}
+	if options.IdleAfter <= 0 {
+		return nil, fmt.Errorf("idle period must be positive")
+	}
+
+	return &ArchiveAdapter{
+		persistence: persistence,
+		store:       store,
+		prefix:      strings.TrimSuffix(options.Prefix, "/"),
+		idleAfter:   options.IdleAfter,
+		lastAccess:  make(map[string]time.Time),
+		archived:    make(map[string]bool),
+		now:         time.Now,
+	}, nil
+}
+
+// getArchiveKey는 보관된 문서의 객체 키를 반환합니다.
+func (a *ArchiveAdapter) getArchiveKey(documentID string) string {
+	return a.prefix + "/" + url.PathEscape(documentID) + ".json"
+}
+
+// parseArchiveKey는 보관된 문서의 객체 키에서 문서 ID를 추출합니다.
+func (a *ArchiveAdapter) parseArchiveKey(key string) (string, bool) {
+	name, ok := strings.CutPrefix(key, a.prefix+"/")
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutSuffix(name, ".json")
+	if !ok {
+		return "", false
+	}
+	documentID, err := url.PathUnescape(name)
+	if err != nil {
+		return "", false
+	}
+	return documentID, true
+}
+
+// SaveDocument는 문서를 기본 영구 저장소에 저장합니다.
+// 콜드 저장소에서 로드된 문서면 콜드 저장소의 사본을 삭제합니다.
+func (a *ArchiveAdapter) SaveDocument(ctx context.Context, doc *Document) error {
+	a.moveMutex.RLock()
+	defer a.moveMutex.RUnlock()
+
+	if err := a.persistence.SaveDocument(ctx, doc); err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	a.lastAccess[doc.ID] = a.now()
+	rehydrated := a.archived[doc.ID]
+	delete(a.archived, doc.ID)
+	a.mutex.Unlock()
+
+	// 기본 영구 저장소로 되돌아온 문서의 사본 삭제
+	if rehydrated {
+		if err := a.store.DeleteObject(ctx, a.getArchiveKey(doc.ID)); err != nil {
+			return fmt.Errorf("failed to delete archived document: %w", err)
+		}
+		a.mutex.Lock()
+		a.stats.Rehydrated++
+		a.mutex.Unlock()
+	}
+
+	return nil
+}
+
+// LoadDocument는 문서를 기본 영구 저장소에서 로드합니다.
+// 기본 영구 저장소에 문서가 없으면 콜드 저장소에서 로드합니다.
+func (a *ArchiveAdapter) LoadDocument(ctx context.Context, documentID string) ([]byte, error) {
+	a.moveMutex.RLock()
+	defer a.moveMutex.RUnlock()
+
+	data, err := a.persistence.LoadDocument(ctx, documentID)
+	if err == nil {
+		a.mutex.Lock()
+		a.lastAccess[documentID] = a.now()
+		a.stats.HotHits++
+		a.mutex.Unlock()
+		return data, nil
+	}
+
+	// 콜드 저장소에서 로드
+	data, coldErr := a.store.GetObject(ctx, a.getArchiveKey(documentID))
+	if coldErr != nil {
+		if !errors.Is(coldErr, ErrObjectNotFound) {
+			return nil, fmt.Errorf("failed to load archived document: %w", coldErr)
+		}
+		a.mutex.Lock()
+		a.stats.Misses++
+		a.mutex.Unlock()
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	a.lastAccess[documentID] = a.now()
+	a.archived[documentID] = true
+	a.stats.ColdHits++
+	a.mutex.Unlock()
+	return data, nil
+}
+
+// ListDocuments는 기본 영구 저장소의 문서와 콜드 저장소에 보관된 문서의 목록을 반환합니다.
+func (a *ArchiveAdapter) ListDocuments(ctx context.Context) ([]string, error) {
+	ids, err := a.persistence.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 보관된 문서 추가
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	infos, err := a.store.ListObjects(ctx, a.prefix+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived documents: %w", err)
+	}
+	for _, info := range infos {
+		documentID, ok := a.parseArchiveKey(info.Key)
+		if ok && !seen[documentID] {
+			seen[documentID] = true
+			ids = append(ids, documentID)
+		}
+	}
+
+	return ids, nil
+}
+
+// DeleteDocument는 문서를 기본 영구 저장소와 콜드 저장소에서 삭제합니다.
+func (a *ArchiveAdapter) DeleteDocument(ctx context.Context, documentID string) error {
+	a.moveMutex.Lock()
+	defer a.moveMutex.Unlock()
+
+	// 보관된 문서는 기본 영구 저장소에 없을 수 있음
+	key := a.getArchiveKey(documentID)
+	_, err := a.store.GetObject(ctx, key)
+	archived := err == nil
+	if err := a.persistence.DeleteDocument(ctx, documentID); err != nil && !archived {
+		return err
+	}
+	if err := a.store.DeleteObject(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete archived document: %w", err)
+	}
+
+	a.mutex.Lock()
+	delete(a.lastAccess, documentID)
+	delete(a.archived, documentID)
+	a.mutex.Unlock()
+	return nil
+}
+
+// Archive는 유휴 기간 동안 사용되지 않은 문서를 콜드 저장소로 옮기고 옮긴 문서 수를 반환합니다.
+// 이 어댑터로 접근한 적이 없는 문서는 저장된 마지막 수정 시간을 기준으로 판단합니다.
+func (a *ArchiveAdapter) Archive(ctx context.Context) (int, error) {
+	ids, err := a.persistence.ListDocuments(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	archived := 0
+	for _, id := range ids {
+		moved, err := a.archiveDocument(ctx, id)
+		if err != nil {
+			return archived, err
+		}
+		if moved {
+			archived++
+		}
+	}
+
+	return archived, nil
+}
+
+// archiveDocument는 문서가 유휴 상태면 콜드 저장소로 옮깁니다.
+func (a *ArchiveAdapter) archiveDocument(ctx context.Context, documentID string) (bool, error) {
+	a.moveMutex.Lock()
+	defer a.moveMutex.Unlock()
+
+	now := a.now()
+	a.mutex.Lock()
+	lastAccess, known := a.lastAccess[documentID]
+	a.mutex.Unlock()
+	if known && now.Sub(lastAccess) < a.idleAfter {
+		return false, nil
+	}
+
+	data, err := a.persistence.LoadDocument(ctx, documentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load document %s: %w", documentID, err)
+	}
+
+	// 접근 기록이 없으면 마지막 수정 시간 사용
+	if !known {
+		var documentData DocumentData
+		if err := json.Unmarshal(data, &documentData); err != nil || documentData.LastModified.IsZero() {
+			// 마지막 수정 시간을 알 수 없으면 지금부터 유휴 기간 계산
+			documentData.LastModified = now
+		}
+		if now.Sub(documentData.LastModified) < a.idleAfter {
+			a.mutex.Lock()
+			a.lastAccess[documentID] = documentData.LastModified
+			a.mutex.Unlock()
+			return false, nil
+		}
+	}
+
+	// 콜드 저장소에 먼저 저장한 뒤 기본 영구 저장소에서 삭제
+	if err := a.store.PutObject(ctx, a.getArchiveKey(documentID), data); err != nil {
+		return false, fmt.Errorf("failed to archive document %s: %w", documentID, err)
+	}
+	if err := a.persistence.DeleteDocument(ctx, documentID); err != nil {
+		return false, fmt.Errorf("failed to delete archived document %s: %w", documentID, err)
+	}
+
+	a.mutex.Lock()
+	delete(a.lastAccess, documentID)
+	a.archived[documentID] = true
+	a.stats.Archived++
+	a.mutex.Unlock()
+	return true, nil
+}
+
+// Stats는 문서 로드 통계를 반환합니다.
+func (a *ArchiveAdapter) Stats() ArchiveStats {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.stats
+}
+
+// Close는 기본 영구 저장소를 닫습니다.
+func (a *ArchiveAdapter) Close() error {
+	return a.persistence.Close()
+}
+
+// archiveLoop는 저장소 컨텍스트가 취소될 때까지 주기적으로 유휴 문서를 보관합니다.
+func (s *storageImpl) archiveLoop(archiver documentArchiver, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := archiver.Archive(s.ctx); err != nil {
+				fmt.Printf("Warning: Failed to archive idle documents: %v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package crdtstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// TestArchiveAdapter는 유휴 문서 보관, 콜드 저장소 로드, 재저장 시 복귀와 통계를 테스트합니다.
+func TestArchiveAdapter(t *testing.T) {
+	ctx := context.Background()
+
+	// 파일 객체 저장소를 콜드 저장소로 사용하는 어댑터
+	primary := NewMemoryAdapter()
+	store, err := NewFileObjectStore(t.TempDir())
+	assert.NoError(t, err)
+	adapter, err := NewArchiveAdapter(primary, store, &ArchiveOptions{Prefix: "archive", IdleAfter: 24 * time.Hour})
+	assert.NoError(t, err)
+	now := time.Now()
+	adapter.now = func() time.Time { return now }
+
+	// 두 문서 저장
+	idle := newContentDocument(t, "raid/1", `{"hp":100}`)
+	active := newContentDocument(t, "raid/2", `{"hp":50}`)
+	assert.NoError(t, adapter.SaveDocument(ctx, idle))
+	assert.NoError(t, adapter.SaveDocument(ctx, active))
+
+	// 유휴 기간이 지나지 않은 문서는 보관하지 않음
+	archived, err := adapter.Archive(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, archived)
+
+	// 유휴 기간 동안 사용되지 않은 문서만 콜드 저장소로 이동
+	now = now.Add(25 * time.Hour)
+	_, err = adapter.LoadDocument(ctx, "raid/2")
+	assert.NoError(t, err)
+	archived, err = adapter.Archive(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	_, err = primary.LoadDocument(ctx, "raid/1")
+	assert.Error(t, err)
+	ids, err := adapter.ListDocuments(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"raid/1", "raid/2"}, ids)
+
+	// 보관된 문서는 콜드 저장소에서 로드
+	data, err := adapter.LoadDocument(ctx, "raid/1")
+	assert.NoError(t, err)
+	restored := &Document{CRDTDoc: crdt.NewDocument(common.NewSessionID())}
+	assert.NoError(t, NewDefaultDocumentSerializer().Deserialize(restored, data))
+	content, err := restored.GetContent()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, content)
+
+	// 다시 저장하면 기본 영구 저장소로 돌아오고 콜드 저장소에서 삭제
+	assert.NoError(t, adapter.SaveDocument(ctx, idle))
+	_, err = primary.LoadDocument(ctx, "raid/1")
+	assert.NoError(t, err)
+	_, err = store.GetObject(ctx, adapter.getArchiveKey("raid/1"))
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	// 없는 문서
+	_, err = adapter.LoadDocument(ctx, "raid/3")
+	assert.Error(t, err)
+
+	stats := adapter.Stats()
+	assert.Equal(t, ArchiveStats{HotHits: 1, ColdHits: 1, Misses: 1, Archived: 1, Rehydrated: 1}, stats)
+	assert.Equal(t, 0.5, stats.HotHitRate())
+	assert.Equal(t, 0.5, stats.ColdHitRate())
+
+	// 보관된 문서 삭제
+	now = now.Add(25 * time.Hour)
+	archived, err = adapter.Archive(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, archived)
+	assert.NoError(t, adapter.DeleteDocument(ctx, "raid/1"))
+	ids, err = adapter.ListDocuments(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"raid/2"}, ids)
+}
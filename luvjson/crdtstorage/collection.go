@@ -0,0 +1,402 @@
+package crdtstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 컬렉션이 인덱싱하는 문서 메타데이터 키입니다.
+const (
+	// MetadataTitle은 문서 제목 메타데이터 키입니다.
+	MetadataTitle = "title"
+
+	// MetadataTags는 문서 태그 메타데이터 키입니다.
+	MetadataTags = "tags"
+)
+
+// collectionSeparator는 컬렉션 이름과 문서 ID를 구분하는 문자입니다.
+const collectionSeparator = ":"
+
+// DocumentInfo는 컬렉션 인덱스에 저장된 문서 정보입니다.
+type DocumentInfo struct {
+	// ID는 컬렉션 내 문서 ID입니다.
+	ID string
+
+	// Title은 문서 제목입니다.
+	Title string
+
+	// Tags는 문서 태그 목록입니다.
+	Tags []string
+
+	// UpdatedAt은 문서가 마지막으로 저장된 시간입니다.
+	UpdatedAt time.Time
+
+	// Version은 문서 버전입니다.
+	Version int64
+}
+
+// DocumentFilter는 컬렉션 문서 목록 조회 조건입니다. 비어 있는 조건은 무시합니다.
+type DocumentFilter struct {
+	// TitlePrefix는 제목 접두사입니다. 대소문자를 구분하지 않습니다.
+	TitlePrefix string
+
+	// Tags는 문서가 모두 가지고 있어야 하는 태그 목록입니다.
+	Tags []string
+
+	// UpdatedAfter는 이 시간 이후에 저장된 문서만 조회합니다.
+	UpdatedAfter time.Time
+
+	// UpdatedBefore는 이 시간 이전에 저장된 문서만 조회합니다.
+	UpdatedBefore time.Time
+}
+
+// Page는 목록 조회 페이지입니다.
+type Page struct {
+	// Offset은 건너뛸 문서 수입니다.
+	Offset int
+
+	// Limit은 최대 문서 수입니다. 0 이하이면 제한하지 않습니다.
+	Limit int
+}
+
+// DocumentPage는 목록 조회 결과입니다.
+type DocumentPage struct {
+	// Documents는 최근에 저장된 순서로 정렬된 문서 정보 목록입니다.
+	Documents []DocumentInfo
+
+	// TotalCount는 필터에 일치하는 총 문서 수입니다.
+	TotalCount int
+
+	// HasMore는 다음 페이지가 있는지 여부입니다.
+	HasMore bool
+}
+
+// titleEntry는 제목 인덱스 항목입니다.
+type titleEntry struct {
+	// title은 소문자로 변환된 제목입니다.
+	title string
+
+	// id는 문서 ID입니다.
+	id string
+}
+
+// Collection은 저장소 위의 문서 묶음입니다.
+// 문서 ID에 컬렉션 이름을 접두사로 붙여 저장하고, 제목, 태그, 수정 시간 인덱스를 메모리에 유지하여
+// 별도의 저장소 없이 문서 목록 조회, 페이지 처리, 제목 접두사 검색을 제공합니다.
+type Collection struct {
+	// name은 컬렉션 이름입니다.
+	name string
+
+	// storage는 문서를 저장하는 저장소입니다.
+	storage Storage
+
+	// documents는 문서 ID에서 문서 정보로의 인덱스입니다.
+	documents map[string]*DocumentInfo
+
+	// tags는 태그에서 문서 ID 집합으로의 인덱스입니다.
+	tags map[string]map[string]struct{}
+
+	// titles는 제목 순서로 정렬된 제목 인덱스입니다.
+	titles []titleEntry
+
+	// mutex는 인덱스에 대한 동시 접근을 보호합니다.
+	mutex sync.RWMutex
+}
+
+// NewCollection은 새 컬렉션을 생성하고 저장소에 있는 컬렉션 문서로 인덱스를 구성합니다.
+func NewCollection(ctx context.Context, storage Storage, name string) (*Collection, error) {
+	if name == "" {
+		return nil, fmt.Errorf("collection name is required")
+	}
+
+	c := &Collection{
+		name:      name,
+		storage:   storage,
+		documents: make(map[string]*DocumentInfo),
+		tags:      make(map[string]map[string]struct{}),
+	}
+
+	// 저장소의 문서 목록 가져오기
+	storageIDs, err := storage.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	// 컬렉션 문서 인덱싱
+	for _, storageID := range storageIDs {
+		id, ok := strings.CutPrefix(storageID, c.prefix())
+		if !ok {
+			continue
+		}
+		doc, err := storage.GetDocument(ctx, storageID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load document %s: %w", storageID, err)
+		}
+		c.index(id, doc)
+	}
+
+	return c, nil
+}
+
+// Name은 컬렉션 이름을 반환합니다.
+func (c *Collection) Name() string {
+	return c.name
+}
+
+// prefix는 컬렉션 문서의 저장소 ID 접두사를 반환합니다.
+func (c *Collection) prefix() string {
+	return c.name + collectionSeparator
+}
+
+// StorageID는 컬렉션 내 문서 ID에 해당하는 저장소 문서 ID를 반환합니다.
+func (c *Collection) StorageID(id string) string {
+	return c.prefix() + id
+}
+
+// CreateDocument는 제목과 태그를 가진 문서를 컬렉션에 생성합니다.
+func (c *Collection) CreateDocument(ctx context.Context, id string, title string, tags ...string) (*Document, error) {
+	// 문서 생성
+	doc, err := c.storage.CreateDocument(ctx, c.StorageID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	// 메타데이터 설정 후 저장
+	doc.SetMetadata(MetadataTitle, title)
+	doc.SetMetadata(MetadataTags, tags)
+	if err := c.SaveDocument(ctx, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// GetDocument는 컬렉션 문서를 가져옵니다.
+func (c *Collection) GetDocument(ctx context.Context, id string) (*Document, error) {
+	return c.storage.GetDocument(ctx, c.StorageID(id))
+}
+
+// SaveDocument는 컬렉션 문서를 저장하고 인덱스를 갱신합니다.
+// 제목이나 태그를 바꾸려면 문서 메타데이터를 수정한 뒤 호출합니다.
+func (c *Collection) SaveDocument(ctx context.Context, doc *Document) error {
+	id, ok := strings.CutPrefix(doc.ID, c.prefix())
+	if !ok {
+		return fmt.Errorf("document %s does not belong to collection %s", doc.ID, c.name)
+	}
+
+	if err := c.storage.SaveDocument(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.index(id, doc)
+	return nil
+}
+
+// DeleteDocument는 컬렉션 문서를 삭제하고 인덱스에서 제거합니다.
+func (c *Collection) DeleteDocument(ctx context.Context, id string) error {
+	if err := c.storage.DeleteDocument(ctx, c.StorageID(id)); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.unindex(id)
+	return nil
+}
+
+// ListDocuments는 필터에 일치하는 문서를 최근에 저장된 순서로 페이지 단위로 반환합니다.
+func (c *Collection) ListDocuments(filter DocumentFilter, page Page) *DocumentPage {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	// 후보 문서 선택 (태그 인덱스 사용)
+	var candidates []*DocumentInfo
+	if len(filter.Tags) > 0 {
+		for id := range c.tags[filter.Tags[0]] {
+			candidates = append(candidates, c.documents[id])
+		}
+	} else {
+		for _, info := range c.documents {
+			candidates = append(candidates, info)
+		}
+	}
+
+	// 나머지 조건 적용
+	titlePrefix := strings.ToLower(filter.TitlePrefix)
+	var matched []*DocumentInfo
+	for _, info := range candidates {
+		if !strings.HasPrefix(strings.ToLower(info.Title), titlePrefix) {
+			continue
+		}
+		if !filter.UpdatedAfter.IsZero() && !info.UpdatedAt.After(filter.UpdatedAfter) {
+			continue
+		}
+		if !filter.UpdatedBefore.IsZero() && !info.UpdatedAt.Before(filter.UpdatedBefore) {
+			continue
+		}
+		if !c.hasTags(info.ID, filter.Tags) {
+			continue
+		}
+		matched = append(matched, info)
+	}
+
+	// 수정 시간 역순, 같으면 ID 순으로 정렬
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].UpdatedAt.Equal(matched[j].UpdatedAt) {
+			return matched[i].UpdatedAt.After(matched[j].UpdatedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	return paginate(matched, page)
+}
+
+// SearchTitles는 제목이 접두사로 시작하는 문서를 제목 순서로 최대 limit개 반환합니다.
+// 대소문자를 구분하지 않으며, limit이 0 이하이면 제한하지 않습니다.
+func (c *Collection) SearchTitles(prefix string, limit int) []DocumentInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	prefix = strings.ToLower(prefix)
+	start := sort.Search(len(c.titles), func(i int) bool {
+		return c.titles[i].title >= prefix
+	})
+
+	var results []DocumentInfo
+	for i := start; i < len(c.titles) && strings.HasPrefix(c.titles[i].title, prefix); i++ {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+		results = append(results, copyDocumentInfo(c.documents[c.titles[i].id]))
+	}
+	return results
+}
+
+// hasTags는 문서가 모든 태그를 가지고 있는지 확인합니다.
+func (c *Collection) hasTags(id string, tags []string) bool {
+	for _, tag := range tags {
+		if _, ok := c.tags[tag][id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// index는 문서 메타데이터로 인덱스를 갱신합니다. 호출자가 잠금을 보유해야 합니다.
+func (c *Collection) index(id string, doc *Document) {
+	c.unindex(id)
+
+	info := &DocumentInfo{
+		ID:        id,
+		Tags:      metadataStrings(doc.Metadata[MetadataTags]),
+		UpdatedAt: doc.LastModified,
+		Version:   doc.Version,
+	}
+	if title, ok := doc.Metadata[MetadataTitle].(string); ok {
+		info.Title = title
+	}
+	c.documents[id] = info
+
+	// 태그 인덱스 갱신
+	for _, tag := range info.Tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][id] = struct{}{}
+	}
+
+	// 제목 인덱스에 정렬 순서 유지하며 삽입
+	entry := titleEntry{title: strings.ToLower(info.Title), id: id}
+	i := sort.Search(len(c.titles), func(i int) bool { return !titleLess(c.titles[i], entry) })
+	c.titles = append(c.titles, titleEntry{})
+	copy(c.titles[i+1:], c.titles[i:])
+	c.titles[i] = entry
+}
+
+// unindex는 문서를 인덱스에서 제거합니다. 호출자가 잠금을 보유해야 합니다.
+func (c *Collection) unindex(id string) {
+	info, ok := c.documents[id]
+	if !ok {
+		return
+	}
+	delete(c.documents, id)
+
+	for _, tag := range info.Tags {
+		delete(c.tags[tag], id)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+
+	entry := titleEntry{title: strings.ToLower(info.Title), id: id}
+	i := sort.Search(len(c.titles), func(i int) bool { return !titleLess(c.titles[i], entry) })
+	if i < len(c.titles) && c.titles[i] == entry {
+		c.titles = append(c.titles[:i], c.titles[i+1:]...)
+	}
+}
+
+// titleLess는 제목 인덱스 항목의 정렬 순서를 비교합니다.
+func titleLess(a, b titleEntry) bool {
+	if a.title != b.title {
+		return a.title < b.title
+	}
+	return a.id < b.id
+}
+
+// paginate는 정렬된 문서 목록에서 한 페이지를 잘라냅니다.
+func paginate(matched []*DocumentInfo, page Page) *DocumentPage {
+	result := &DocumentPage{TotalCount: len(matched)}
+
+	start := page.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := len(matched)
+	if page.Limit > 0 && start+page.Limit < end {
+		end = start + page.Limit
+		result.HasMore = true
+	}
+
+	result.Documents = make([]DocumentInfo, 0, end-start)
+	for _, info := range matched[start:end] {
+		result.Documents = append(result.Documents, copyDocumentInfo(info))
+	}
+	return result
+}
+
+// copyDocumentInfo는 인덱스 밖으로 반환할 문서 정보 사본을 만듭니다.
+func copyDocumentInfo(info *DocumentInfo) DocumentInfo {
+	result := *info
+	result.Tags = append([]string(nil), info.Tags...)
+	return result
+}
+
+// metadataStrings는 메타데이터 값을 문자열 목록으로 변환합니다.
+// JSON으로 역직렬화된 메타데이터는 []interface{}이므로 두 형식을 모두 처리합니다.
+func metadataStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	case string:
+		return []string{v}
+	default:
+		return nil
+	}
+}
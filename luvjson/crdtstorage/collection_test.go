@@ -0,0 +1,96 @@
+package crdtstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// documentIDs는 문서 정보 목록의 ID를 반환합니다.
+func documentIDs(infos []DocumentInfo) []string {
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		ids = append(ids, info.ID)
+	}
+	return ids
+}
+
+// TestCollection은 컬렉션의 문서 목록 조회, 필터, 페이지, 제목 검색을 테스트합니다.
+func TestCollection(t *testing.T) {
+	ctx := context.Background()
+
+	// 파일 저장소 생성
+	options := DefaultStorageOptions()
+	options.PersistenceType = "file"
+	options.PersistencePath = t.TempDir()
+	options.AutoSave = false
+	storage, err := NewStorage(ctx, options)
+	assert.NoError(t, err)
+
+	// 컬렉션에 문서 생성
+	todos, err := NewCollection(ctx, storage, "todos")
+	assert.NoError(t, err)
+	_, err = todos.CreateDocument(ctx, "groceries", "Groceries", "home")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = todos.CreateDocument(ctx, "gym", "Gym plan", "health", "home")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = todos.CreateDocument(ctx, "release", "Release checklist", "work")
+	assert.NoError(t, err)
+
+	// 다른 컬렉션의 문서는 포함하지 않음
+	_, err = storage.CreateDocument(ctx, "notes:groceries")
+	assert.NoError(t, err)
+
+	// 최근에 저장된 순서로 목록 조회
+	page := todos.ListDocuments(DocumentFilter{}, Page{Limit: 2})
+	assert.Equal(t, 3, page.TotalCount)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, []string{"release", "gym"}, documentIDs(page.Documents))
+	page = todos.ListDocuments(DocumentFilter{}, Page{Offset: 2, Limit: 2})
+	assert.False(t, page.HasMore)
+	assert.Equal(t, []string{"groceries"}, documentIDs(page.Documents))
+
+	// 태그와 제목 필터
+	page = todos.ListDocuments(DocumentFilter{Tags: []string{"home"}}, Page{})
+	assert.Equal(t, []string{"gym", "groceries"}, documentIDs(page.Documents))
+	page = todos.ListDocuments(DocumentFilter{Tags: []string{"home", "health"}, TitlePrefix: "gym"}, Page{})
+	assert.Equal(t, []string{"gym"}, documentIDs(page.Documents))
+
+	// 제목 접두사 검색
+	assert.Equal(t, []string{"groceries", "gym"}, documentIDs(todos.SearchTitles("g", 0)))
+	assert.Equal(t, []string{"groceries"}, documentIDs(todos.SearchTitles("G", 1)))
+
+	// 메타데이터 변경 후 저장하면 인덱스 갱신
+	doc, err := todos.GetDocument(ctx, "groceries")
+	assert.NoError(t, err)
+	doc.SetMetadata(MetadataTitle, "Weekly shopping")
+	doc.SetMetadata(MetadataTags, []string{"home", "weekly"})
+	before := time.Now()
+	assert.NoError(t, todos.SaveDocument(ctx, doc))
+	assert.Empty(t, todos.SearchTitles("gro", 0))
+	assert.Equal(t, []string{"groceries"}, documentIDs(todos.SearchTitles("week", 0)))
+	page = todos.ListDocuments(DocumentFilter{UpdatedAfter: before}, Page{})
+	assert.Equal(t, []string{"groceries"}, documentIDs(page.Documents))
+
+	// 삭제하면 인덱스에서 제거
+	assert.NoError(t, todos.DeleteDocument(ctx, "release"))
+	assert.Empty(t, todos.ListDocuments(DocumentFilter{Tags: []string{"work"}}, Page{}).Documents)
+	assert.NoError(t, storage.Close())
+
+	// 저장소를 다시 열면 저장된 메타데이터로 인덱스 재구성
+	storage, err = NewStorage(ctx, options)
+	assert.NoError(t, err)
+	defer storage.Close()
+	todos, err = NewCollection(ctx, storage, "todos")
+	assert.NoError(t, err)
+	page = todos.ListDocuments(DocumentFilter{Tags: []string{"weekly"}}, Page{})
+	if assert.Len(t, page.Documents, 1) {
+		assert.Equal(t, "Weekly shopping", page.Documents[0].Title)
+		assert.Equal(t, []string{"home", "weekly"}, page.Documents[0].Tags)
+	}
+	assert.Equal(t, 2, todos.ListDocuments(DocumentFilter{}, Page{}).TotalCount)
+}
@@ -37,6 +37,28 @@ func (d *Document) Save(ctx context.Context) error {
 	return fmt.Errorf("direct save is no longer supported, use Storage.SaveDocument instead")
 }
 
+// applyPatch는 패치를 패치 로그에 먼저 기록한 뒤 문서에 적용합니다.
+// 패치 로그가 없으면 바로 적용합니다.
+func (d *Document) applyPatch(ctx context.Context, patch *crdtpatch.Patch) error {
+	d.logMutex.Lock()
+	defer d.logMutex.Unlock()
+
+	if d.patchLog != nil {
+		if err := d.patchLog.Append(ctx, d.ID, patch); err != nil {
+			return fmt.Errorf("failed to append patch log: %w", err)
+		}
+	}
+	if err := patch.Apply(d.CRDTDoc); err != nil {
+		return err
+	}
+
+	// 버전 비교 저장 시 함께 저장할 패치 기록
+	if d.trackUnsaved {
+		d.unsaved = append(d.unsaved, patch)
+	}
+	return nil
+}
+
 // startAutoSave는 자동 저장을 시작합니다.
 // 이 메서드는 하위 호환성을 위해 유지되지만, 실제 자동 저장은 Storage에서 처리해야 합니다.
 func (d *Document) startAutoSave() {
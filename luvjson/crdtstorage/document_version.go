@@ -0,0 +1,119 @@
+package crdtstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// VersionedAdapter는 저장된 문서 버전을 비교하여 저장하고, 버전 사이에 저장된 패치를 제공하는 영구 저장소 어댑터입니다.
+// 영구 저장소가 이 인터페이스를 구현하면 저장소는 문서를 로드한 후 다른 인스턴스가 먼저 저장한 경우
+// 덮어쓰지 않고 누락된 패치를 가져와 병합한 뒤 저장을 재시도합니다.
+//
+// 문서 버전은 새 문서를 처음 저장할 때 1이고, 이후 저장할 때마다 마지막 저장 이후 적용된 패치 수만큼 증가합니다.
+// 각 패치에는 패치가 반영된 문서 버전이 매겨집니다.
+type VersionedAdapter interface {
+	// SaveDocumentVersion은 저장된 문서 버전이 baseVersion과 같을 때만 doc.Version으로 문서를 저장하고,
+	// 마지막 저장 이후 적용된 patches를 패치 기록에 추가합니다.
+	// 저장된 버전이 다르면 ErrVersionConflict를 반환합니다. 저장된 문서가 없으면 버전은 0입니다.
+	SaveDocumentVersion(ctx context.Context, doc *Document, baseVersion int64, patches []*crdtpatch.Patch) error
+
+	// LoadPatchesSince는 version 이후 저장된 패치를 오래된 순서로 반환하고, 현재 저장된 문서 버전을 함께 반환합니다.
+	LoadPatchesSince(ctx context.Context, documentID string, version int64) ([]*crdtpatch.Patch, int64, error)
+}
+
+// patchRecord는 패치 기록에 저장되는 패치입니다.
+type patchRecord struct {
+	// Version은 패치가 반영된 문서 버전입니다.
+	Version int64 `json:"version"`
+
+	// Patch는 JSON으로 인코딩된 패치입니다.
+	Patch json.RawMessage `json:"patch"`
+}
+
+// newPatchRecords는 version까지 저장되는 패치에 버전을 매겨 패치 기록을 생성합니다.
+func newPatchRecords(version int64, patches []*crdtpatch.Patch) ([]patchRecord, error) {
+	records := make([]patchRecord, len(patches))
+	first := version - int64(len(patches)) + 1
+	for i, patch := range patches {
+		data, err := patch.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal patch: %w", err)
+		}
+		records[i] = patchRecord{Version: first + int64(i), Patch: data}
+	}
+	return records, nil
+}
+
+// decodePatch는 패치 기록의 패치를 역직렬화합니다.
+func (r patchRecord) decodePatch() (*crdtpatch.Patch, error) {
+	patch := &crdtpatch.Patch{}
+	if err := patch.UnmarshalJSON(r.Patch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal patch %d: %w", r.Version, err)
+	}
+	return patch, nil
+}
+
+// trackVersion은 영구 저장소가 버전 비교 저장을 지원하면 문서의 저장된 버전을 기록하고
+// 저장되지 않은 패치를 모으기 시작합니다.
+func (s *storageImpl) trackVersion(doc *Document, savedVersion int64) {
+	if _, ok := s.persistence.(VersionedAdapter); !ok {
+		return
+	}
+	doc.savedVersion = savedVersion
+	doc.trackUnsaved = true
+}
+
+// saveVersioned는 문서를 마지막으로 로드하거나 저장한 버전을 기준으로 저장합니다.
+// 그 사이에 다른 인스턴스가 문서를 저장했으면 누락된 패치를 병합한 뒤 MaxSaveRetries번까지 재시도합니다.
+// doc.logMutex를 잡은 상태에서 호출해야 합니다.
+func (s *storageImpl) saveVersioned(ctx context.Context, adapter VersionedAdapter, doc *Document) error {
+	for attempt := 0; ; attempt++ {
+		// 마지막 저장 이후의 패치 수만큼 버전 증가 (새 문서는 버전 1부터 시작)
+		doc.Version = doc.savedVersion + int64(len(doc.unsaved))
+		if doc.savedVersion == 0 {
+			doc.Version++
+		}
+
+		err := adapter.SaveDocumentVersion(ctx, doc, doc.savedVersion, doc.unsaved)
+		if err == nil {
+			doc.savedVersion = doc.Version
+			doc.unsaved = nil
+			return nil
+		}
+		if !errors.Is(err, ErrVersionConflict) || attempt >= s.options.MaxSaveRetries {
+			return err
+		}
+
+		// 다른 인스턴스가 저장한 패치 병합 후 재시도
+		if err := s.mergeSavedPatches(ctx, adapter, doc); err != nil {
+			return err
+		}
+	}
+}
+
+// mergeSavedPatches는 문서의 저장된 버전 이후 다른 인스턴스가 저장한 패치를 가져와 문서에 적용합니다.
+func (s *storageImpl) mergeSavedPatches(ctx context.Context, adapter VersionedAdapter, doc *Document) error {
+	patches, version, err := adapter.LoadPatchesSince(ctx, doc.ID, doc.savedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to load saved patches: %w", err)
+	}
+
+	// 버전 1은 패치 없이 생성된 문서이므로 그 이후의 패치가 모두 있어야 병합 가능
+	if int64(len(patches)) != version-max(doc.savedVersion, 1) {
+		return fmt.Errorf("failed to merge document %s: patches between versions %d and %d are no longer available", doc.ID, doc.savedVersion, version)
+	}
+
+	for _, patch := range patches {
+		if err := patch.Apply(doc.CRDTDoc); err != nil {
+			return fmt.Errorf("failed to apply saved patch to document %s: %w", doc.ID, err)
+		}
+		s.emit(WatchEvent{Type: WatchEventPatch, DocumentID: doc.ID, Patch: patch, Remote: true})
+	}
+	doc.savedVersion = version
+
+	return nil
+}
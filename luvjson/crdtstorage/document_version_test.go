@@ -0,0 +1,104 @@
+package crdtstorage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// setRootObject는 루트를 새 객체 노드로 교체하는 편집 함수를 반환합니다.
+func setRootObject() EditFunc {
+	return func(crdtDoc *crdt.Document, patchBuilder *crdtpatch.PatchBuilder) error {
+		objectID := crdtDoc.NextTimestamp()
+		patchBuilder.AddOperation(&crdtpatch.NewOperation{
+			ID:       objectID,
+			NodeType: common.NodeTypeObj,
+		})
+		patchBuilder.AddOperation(&crdtpatch.InsOperation{
+			ID:       crdtDoc.NextTimestamp(),
+			TargetID: common.RootID,
+			Value:    objectID,
+		})
+		return nil
+	}
+}
+
+// setRootField는 루트 객체의 필드를 설정하는 편집 함수를 반환합니다.
+func setRootField(key string, value interface{}) EditFunc {
+	return func(crdtDoc *crdt.Document, patchBuilder *crdtpatch.PatchBuilder) error {
+		// 루트 값은 객체 노드 ID를 가리키는 상수 노드
+		root := crdtDoc.Root().(*crdt.RootNode)
+		objectID, ok := root.NodeValue.(*crdt.ConstantNode).NodeValue.(common.LogicalTimestamp)
+		if !ok {
+			return fmt.Errorf("root is not an object")
+		}
+		patchBuilder.AddOperation(&crdtpatch.InsOperation{
+			ID:       crdtDoc.NextTimestamp(),
+			TargetID: objectID,
+			Value:    map[string]interface{}{key: value},
+		})
+		return nil
+	}
+}
+
+// TestStorage_SaveMergesSavedPatches는 다른 인스턴스가 먼저 저장한 경우 저장된 패치를 병합한 뒤 저장하는지 테스트합니다.
+func TestStorage_SaveMergesSavedPatches(t *testing.T) {
+	ctx := context.Background()
+
+	// 같은 영구 저장소를 공유하는 두 저장소
+	adapter := NewMemoryAdapter()
+	options := DefaultStorageOptions()
+	options.AutoSave = false
+	options.EnableSnapshots = false
+	first, err := NewStorageWithCustomPersistence(ctx, options, adapter)
+	assert.NoError(t, err)
+	defer first.Close()
+	second, err := NewStorageWithCustomPersistence(ctx, options, adapter)
+	assert.NoError(t, err)
+	defer second.Close()
+
+	// 첫 번째 저장소에서 문서 생성 후 저장
+	doc, err := first.CreateDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), doc.Version)
+	assert.True(t, doc.Edit(ctx, setRootObject()).Success)
+	assert.True(t, doc.Edit(ctx, setRootField("hp", 100)).Success)
+	assert.NoError(t, first.SaveDocument(ctx, doc))
+	assert.Equal(t, int64(3), doc.Version)
+
+	// 두 번째 저장소에서 같은 문서를 생성하면 저장된 패치를 병합
+	replica, err := second.CreateDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), replica.Version)
+	var content map[string]interface{}
+	assert.NoError(t, replica.GetContentAs(&content))
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, content)
+
+	// 양쪽에서 편집한 뒤 첫 번째 저장소가 먼저 저장
+	assert.True(t, doc.Edit(ctx, setRootField("hp", 40)).Success)
+	assert.True(t, replica.Edit(ctx, setRootField("mp", 5)).Success)
+	assert.NoError(t, first.SaveDocument(ctx, doc))
+	assert.Equal(t, int64(4), doc.Version)
+
+	// 두 번째 저장소는 덮어쓰지 않고 병합 후 저장
+	assert.NoError(t, second.SaveDocument(ctx, replica))
+	assert.Equal(t, int64(5), replica.Version)
+	assert.NoError(t, replica.GetContentAs(&content))
+	assert.Equal(t, map[string]interface{}{"hp": float64(40), "mp": float64(5)}, content)
+
+	// 첫 번째 저장소도 다음 저장에서 병합
+	assert.NoError(t, first.SaveDocument(ctx, doc))
+	assert.Equal(t, int64(5), doc.Version)
+	assert.NoError(t, doc.GetContentAs(&content))
+	assert.Equal(t, map[string]interface{}{"hp": float64(40), "mp": float64(5)}, content)
+
+	// 오래된 버전을 기준으로 저장하면 충돌
+	err = adapter.SaveDocumentVersion(ctx, doc, 3, nil)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+}
@@ -100,8 +100,8 @@ func (d *Document) Edit(ctx context.Context, editFunc EditFunc, opts ...EditOpti
 		metadata["retryCount"] = i
 		patch.SetMetadata(metadata)
 
-		// 패치 직접 적용
-		if err = patch.Apply(d.CRDTDoc); err != nil {
+		// 패치 로그에 기록 후 적용
+		if err = d.applyPatch(ctx, patch); err != nil {
 			d.mutex.Unlock()
 			result.Error = fmt.Errorf("failed to apply patch: %w", err)
 
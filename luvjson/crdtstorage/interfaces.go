@@ -38,6 +38,15 @@ type Storage interface {
 	// SaveDocument는 문서를 저장합니다.
 	SaveDocument(ctx context.Context, doc *Document) error
 
+	// RecoverDocuments는 패치 로그를 마지막 체크포인트 위에 재생하여 저장되지 않은 변경사항을 복구합니다.
+	// 복구된 문서 ID 목록을 반환합니다. 패치 로그가 설정되지 않았으면 아무 작업도 하지 않습니다.
+	RecoverDocuments(ctx context.Context) ([]string, error)
+
+	// WatchDocument는 문서에 적용된 패치와 저장된 스냅샷을 전달하는 채널을 반환합니다.
+	// 같은 PubSub을 사용하는 다른 프로세스의 저장소에서 적용된 패치는 이 저장소의 문서에도 적용됩니다.
+	// 채널은 ctx가 취소되거나 저장소가 닫히면 닫힙니다.
+	WatchDocument(ctx context.Context, documentID string) (<-chan WatchEvent, error)
+
 	// CreateSnapshot은 문서의 스냅샷을 생성합니다.
 	CreateSnapshot(ctx context.Context, doc *Document) (*DocumentSnapshot, error)
 
@@ -123,6 +132,23 @@ type Document struct {
 	// activeTransaction은 현재 진행 중인 트랜잭션 ID입니다.
 	// 하나의 문서에 대해 한 번에 하나의 트랜잭션만 허용됩니다.
 	activeTransaction string
+
+	// patchLog는 패치를 적용하기 전에 기록하는 패치 로그입니다.
+	// nil이면 패치를 기록하지 않습니다.
+	patchLog PatchLog
+
+	// logMutex는 패치 기록과 적용, 저장과 체크포인트가 서로 끼어들지 않도록 보호합니다.
+	logMutex sync.Mutex
+
+	// savedVersion은 마지막으로 로드하거나 저장한 영구 저장소의 문서 버전입니다.
+	// 영구 저장소가 VersionedAdapter를 구현할 때만 사용됩니다.
+	savedVersion int64
+
+	// trackUnsaved는 저장되지 않은 패치를 unsaved에 모을지 여부입니다.
+	trackUnsaved bool
+
+	// unsaved는 마지막 저장 이후 적용된 패치 목록입니다.
+	unsaved []*crdtpatch.Patch
 }
 
 // DocumentOptions는 문서 옵션을 나타냅니다.
@@ -219,6 +245,10 @@ type SnapshotOptions struct {
 	// SnapshotOnSave는 저장 시 스냅샷 생성 여부입니다.
 	// true인 경우 문서가 저장될 때마다 스냅샷이 생성됩니다.
 	SnapshotOnSave bool
+
+	// Compression은 스냅샷 데이터의 압축 방식입니다.
+	// 압축된 데이터에는 코덱 태그가 붙으므로 압축 방식을 바꿔도 기존 스냅샷을 로드할 수 있습니다.
+	Compression SnapshotCompression
 }
 
 // DocumentSnapshot은 문서 스냅샷을 나타냅니다.
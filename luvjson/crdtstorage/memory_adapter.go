@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"sync"
+
+	"tictactoe/luvjson/crdtpatch"
 )
 
 // MemoryAdapter는 메모리 기반 영구 저장소 어댑터입니다.
@@ -11,6 +13,12 @@ type MemoryAdapter struct {
 	// documents는 문서 ID에서 문서 데이터로의 맵입니다.
 	documents map[string][]byte
 
+	// versions는 문서 ID에서 저장된 문서 버전으로의 맵입니다.
+	versions map[string]int64
+
+	// history는 문서 ID에서 저장된 패치 기록으로의 맵입니다.
+	history map[string][]patchRecord
+
 	// mutex는 문서 맵에 대한 동시 접근을 보호합니다.
 	mutex sync.RWMutex
 
@@ -22,6 +30,8 @@ type MemoryAdapter struct {
 func NewMemoryAdapter() *MemoryAdapter {
 	return &MemoryAdapter{
 		documents:  make(map[string][]byte),
+		versions:   make(map[string]int64),
+		history:    make(map[string][]patchRecord),
 		serializer: NewDefaultDocumentSerializer(),
 	}
 }
@@ -43,10 +53,62 @@ func (a *MemoryAdapter) SaveDocument(ctx context.Context, doc *Document) error {
 
 	// 문서 저장
 	a.documents[doc.ID] = dataCopy
+	a.versions[doc.ID] = doc.Version
+
+	return nil
+}
+
+// SaveDocumentVersion은 저장된 문서 버전이 baseVersion과 같을 때만 문서를 저장하고 패치 기록에 patches를 추가합니다.
+// 저장된 버전이 다르면 ErrVersionConflict를 반환합니다.
+func (a *MemoryAdapter) SaveDocumentVersion(ctx context.Context, doc *Document, baseVersion int64, patches []*crdtpatch.Patch) error {
+	// 패치 기록 생성
+	records, err := newPatchRecords(doc.Version, patches)
+	if err != nil {
+		return err
+	}
+
+	// 문서 직렬화
+	data, err := a.serializer.Serialize(doc)
+	if err != nil {
+		return fmt.Errorf("failed to serialize document: %w", err)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// 저장된 버전 확인
+	if a.versions[doc.ID] != baseVersion {
+		return fmt.Errorf("%w: document %s was saved by another instance", ErrVersionConflict, doc.ID)
+	}
+
+	// 문서와 패치 기록 저장
+	a.documents[doc.ID] = data
+	a.versions[doc.ID] = doc.Version
+	a.history[doc.ID] = append(a.history[doc.ID], records...)
 
 	return nil
 }
 
+// LoadPatchesSince는 version 이후 저장된 패치와 현재 저장된 문서 버전을 반환합니다.
+func (a *MemoryAdapter) LoadPatchesSince(ctx context.Context, documentID string, version int64) ([]*crdtpatch.Patch, int64, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	var patches []*crdtpatch.Patch
+	for _, record := range a.history[documentID] {
+		if record.Version <= version {
+			continue
+		}
+		patch, err := record.decodePatch()
+		if err != nil {
+			return nil, 0, err
+		}
+		patches = append(patches, patch)
+	}
+
+	return patches, a.versions[documentID], nil
+}
+
 // LoadDocument는 문서를 메모리에서 로드합니다.
 func (a *MemoryAdapter) LoadDocument(ctx context.Context, documentID string) ([]byte, error) {
 	a.mutex.RLock()
@@ -86,6 +148,8 @@ func (a *MemoryAdapter) DeleteDocument(ctx context.Context, documentID string) e
 
 	// 문서 삭제
 	delete(a.documents, documentID)
+	delete(a.versions, documentID)
+	delete(a.history, documentID)
 
 	return nil
 }
@@ -97,6 +161,8 @@ func (a *MemoryAdapter) Close() error {
 
 	// 메모리 정리
 	a.documents = make(map[string][]byte)
+	a.versions = make(map[string]int64)
+	a.history = make(map[string][]patchRecord)
 
 	return nil
 }
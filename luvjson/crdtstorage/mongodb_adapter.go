@@ -2,72 +2,185 @@ package crdtstorage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// MongoDBAdapter는 MongoDB 기반 영구 저장소 어댑터입니다.
+// mongoDocument는 MongoDB 문서 컬렉션에 저장되는 문서입니다.
+type mongoDocument struct {
+	// ID는 문서의 고유 식별자입니다.
+	ID string `bson:"_id"`
+
+	// Data는 직렬화된 문서 데이터입니다.
+	Data []byte `bson:"data"`
+
+	// Content는 문서 내용입니다. 다른 컬렉션의 데이터처럼 쿼리할 수 있도록 저장합니다.
+	Content interface{} `bson:"content"`
+
+	// LastModified는 문서가 마지막으로 수정된 시간입니다.
+	LastModified time.Time `bson:"lastModified"`
+
+	// Metadata는 문서 메타데이터입니다.
+	Metadata map[string]interface{} `bson:"metadata"`
+
+	// Version은 문서 버전입니다.
+	Version int64 `bson:"version"`
+
+	// Revision은 저장 횟수로, 낙관적 동시성 제어에 사용됩니다.
+	Revision int64 `bson:"revision"`
+}
+
+// mongoSnapshot은 MongoDB 스냅샷 컬렉션에 저장되는 스냅샷입니다.
+type mongoSnapshot struct {
+	// DocumentID는 문서의 고유 식별자입니다.
+	DocumentID string `bson:"documentId"`
+
+	// Version은 스냅샷의 버전 번호입니다.
+	Version int64 `bson:"version"`
+
+	// Timestamp는 스냅샷이 생성된 시간입니다.
+	Timestamp time.Time `bson:"timestamp"`
+
+	// Data는 스냅샷 데이터의 JSON 표현입니다.
+	Data string `bson:"data"`
+
+	// Metadata는 스냅샷 메타데이터의 JSON 표현입니다.
+	Metadata string `bson:"metadata"`
+}
+
+// MongoDBAdapter는 문서와 스냅샷을 분리된 컬렉션에 저장하는 MongoDB 기반 영구 저장소 어댑터입니다.
+// 문서를 저장할 때 마지막으로 로드하거나 저장한 리비전을 확인하여, 다른 인스턴스가 그 사이에
+// 문서를 저장한 경우 ErrVersionConflict를 반환합니다.
 type MongoDBAdapter struct {
-	// collection은 MongoDB 컬렉션입니다.
+	// collection은 문서가 저장될 MongoDB 컬렉션입니다.
 	collection *mongo.Collection
 
+	// snapshotCollection은 스냅샷이 저장될 MongoDB 컬렉션입니다.
+	snapshotCollection *mongo.Collection
+
+	// revisions는 문서 ID에서 마지막으로 로드하거나 저장한 리비전으로의 맵입니다.
+	revisions map[string]int64
+
 	// mutex는 MongoDB 작업에 대한 동시 접근을 보호합니다.
 	mutex sync.RWMutex
 
 	// serializer는 문서 직렬화/역직렬화를 담당합니다.
 	serializer DocumentSerializer
+
+	// snapshotOptions는 스냅샷 옵션입니다.
+	snapshotOptions *SnapshotOptions
 }
 
 // NewMongoDBAdapter는 새 MongoDB 어댑터를 생성합니다.
-func NewMongoDBAdapter(collection *mongo.Collection) *MongoDBAdapter {
-	return &MongoDBAdapter{
-		collection: collection,
-		serializer: NewDefaultDocumentSerializer(),
+// 스냅샷 컬렉션에는 문서 ID와 버전의 고유 인덱스를 생성합니다.
+func NewMongoDBAdapter(ctx context.Context, collection *mongo.Collection, snapshotCollection *mongo.Collection) (*MongoDBAdapter, error) {
+	if collection == nil || snapshotCollection == nil {
+		return nil, fmt.Errorf("collection and snapshot collection cannot be nil")
+	}
+
+	adapter := &MongoDBAdapter{
+		collection:         collection,
+		snapshotCollection: snapshotCollection,
+		revisions:          make(map[string]int64),
+		serializer:         NewDefaultDocumentSerializer(),
+		snapshotOptions: &SnapshotOptions{
+			Enabled:      true,
+			Interval:     time.Hour,
+			MaxSnapshots: 10,
+		},
 	}
+
+	// 스냅샷 인덱스 생성
+	_, err := snapshotCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "documentId", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot index: %w", err)
+	}
+
+	return adapter, nil
 }
 
 // SaveDocument는 문서를 MongoDB에 저장합니다.
+// 마지막으로 로드하거나 저장한 후 다른 인스턴스가 문서를 저장한 경우 ErrVersionConflict를 반환합니다.
 func (a *MongoDBAdapter) SaveDocument(ctx context.Context, doc *Document) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
+	// 문서 직렬화
+	data, err := a.serializer.Serialize(doc)
+	if err != nil {
+		return fmt.Errorf("failed to serialize document: %w", err)
+	}
+
 	// 문서 내용 가져오기
-	content, err := doc.CRDTDoc.View()
+	content, err := doc.GetContent()
 	if err != nil {
-		return fmt.Errorf("failed to get document view: %w", err)
+		return fmt.Errorf("failed to get document content: %w", err)
 	}
 
 	// MongoDB 문서 생성
-	mongoDoc := bson.M{
-		"_id":          doc.ID,
-		"content":      content,
-		"lastModified": doc.LastModified,
-		"metadata":     doc.Metadata,
-		"version":      doc.Version,
+	revision, known := a.revisions[doc.ID]
+	mongoDoc := mongoDocument{
+		ID:           doc.ID,
+		Data:         data,
+		Content:      content,
+		LastModified: doc.LastModified,
+		Metadata:     doc.Metadata,
+		Version:      doc.Version,
+		Revision:     revision + 1,
 	}
 
-	// MongoDB에 저장
-	opts := options.Replace().SetUpsert(true)
-	_, err = a.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID}, mongoDoc, opts)
+	if !known {
+		// 이 어댑터가 모르는 문서는 새 문서로 삽입
+		_, err = a.collection.InsertOne(ctx, mongoDoc)
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: document %s already exists", ErrVersionConflict, doc.ID)
+		}
+	} else {
+		// 리비전이 변경되지 않은 경우에만 교체
+		var result *mongo.UpdateResult
+		result, err = a.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID, "revision": revision}, mongoDoc)
+		if err == nil && result.MatchedCount == 0 {
+			return fmt.Errorf("%w: document %s was saved by another instance", ErrVersionConflict, doc.ID)
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to save document: %w", err)
 	}
+	a.revisions[doc.ID] = mongoDoc.Revision
+
+	// 스냅샷 생성 (필요한 경우)
+	if a.snapshotOptions.Enabled && a.snapshotOptions.SnapshotOnSave {
+		snapshot, err := a.CreateSnapshot(ctx, doc)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot: %w", err)
+		}
+
+		// 스냅샷 저장
+		if err := a.saveSnapshot(ctx, snapshot); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+	}
 
 	return nil
 }
 
 // LoadDocument는 문서를 MongoDB에서 로드합니다.
 func (a *MongoDBAdapter) LoadDocument(ctx context.Context, documentID string) ([]byte, error) {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
 
 	// MongoDB에서 문서 가져오기
-	var result bson.M
-	err := a.collection.FindOne(ctx, bson.M{"_id": documentID}).Decode(&result)
+	var mongoDoc mongoDocument
+	err := a.collection.FindOne(ctx, bson.M{"_id": documentID}).Decode(&mongoDoc)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("document not found: %s", documentID)
@@ -75,15 +188,10 @@ func (a *MongoDBAdapter) LoadDocument(ctx context.Context, documentID string) ([
 		return nil, fmt.Errorf("failed to load document: %w", err)
 	}
 
-	// MongoDB 문서를 바이트 배열로 변환
-	// 이 부분은 실제 구현에서는 더 복잡할 수 있음
-	// 여기서는 간단히 BSON을 JSON으로 변환
-	data, err := bson.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal document: %w", err)
-	}
+	// 다음 저장 시 확인할 리비전 기록
+	a.revisions[documentID] = mongoDoc.Revision
 
-	return data, nil
+	return mongoDoc.Data, nil
 }
 
 // ListDocuments는 모든 문서 목록을 반환합니다.
@@ -118,7 +226,7 @@ func (a *MongoDBAdapter) ListDocuments(ctx context.Context) ([]string, error) {
 	return ids, nil
 }
 
-// DeleteDocument는 문서를 MongoDB에서 삭제합니다.
+// DeleteDocument는 문서와 문서의 스냅샷을 MongoDB에서 삭제합니다.
 func (a *MongoDBAdapter) DeleteDocument(ctx context.Context, documentID string) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
@@ -128,6 +236,13 @@ func (a *MongoDBAdapter) DeleteDocument(ctx context.Context, documentID string)
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
+	delete(a.revisions, documentID)
+
+	// 스냅샷 삭제
+	_, err = a.snapshotCollection.DeleteMany(ctx, bson.M{"documentId": documentID})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshots: %w", err)
+	}
 
 	return nil
 }
@@ -137,3 +252,227 @@ func (a *MongoDBAdapter) Close() error {
 	// MongoDB 컬렉션은 외부에서 관리하므로 여기서 닫지 않음
 	return nil
 }
+
+// SaveSnapshot은 문서 스냅샷을 저장합니다.
+func (a *MongoDBAdapter) SaveSnapshot(ctx context.Context, snapshot *DocumentSnapshot) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.saveSnapshot(ctx, snapshot)
+}
+
+// saveSnapshot은 스냅샷을 저장하고 오래된 스냅샷을 정리합니다. 호출자는 뮤텍스를 보유해야 합니다.
+func (a *MongoDBAdapter) saveSnapshot(ctx context.Context, snapshot *DocumentSnapshot) error {
+	// 스냅샷 데이터 직렬화
+	dataJSON, err := json.Marshal(snapshot.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot data: %w", err)
+	}
+	dataJSON, err = compressSnapshotData(a.snapshotOptions.Compression, dataJSON)
+	if err != nil {
+		return err
+	}
+
+	// 메타데이터 직렬화
+	metadataJSON, err := json.Marshal(snapshot.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot metadata: %w", err)
+	}
+
+	// 스냅샷 저장
+	mongoSnap := mongoSnapshot{
+		DocumentID: snapshot.DocumentID,
+		Version:    snapshot.Version,
+		Timestamp:  snapshot.Timestamp,
+		Data:       string(dataJSON),
+		Metadata:   string(metadataJSON),
+	}
+	filter := bson.M{"documentId": snapshot.DocumentID, "version": snapshot.Version}
+	_, err = a.snapshotCollection.ReplaceOne(ctx, filter, mongoSnap, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	// 오래된 스냅샷 정리
+	if a.snapshotOptions.MaxSnapshots > 0 {
+		if err := a.cleanupOldSnapshots(ctx, snapshot.DocumentID); err != nil {
+			return fmt.Errorf("failed to cleanup old snapshots: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cleanupOldSnapshots은 최대 스냅샷 수를 초과하는 오래된 스냅샷을 삭제합니다.
+func (a *MongoDBAdapter) cleanupOldSnapshots(ctx context.Context, documentID string) error {
+	// 유지할 스냅샷 다음으로 최신인 스냅샷 찾기
+	var oldest mongoSnapshot
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetSkip(int64(a.snapshotOptions.MaxSnapshots)).
+		SetProjection(bson.M{"version": 1})
+	err := a.snapshotCollection.FindOne(ctx, bson.M{"documentId": documentID}, opts).Decode(&oldest)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find old snapshots: %w", err)
+	}
+
+	// 해당 스냅샷과 그보다 오래된 스냅샷 삭제
+	_, err = a.snapshotCollection.DeleteMany(ctx, bson.M{"documentId": documentID, "version": bson.M{"$lte": oldest.Version}})
+	if err != nil {
+		return fmt.Errorf("failed to delete old snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// LoadSnapshot은 문서 스냅샷을 로드합니다.
+func (a *MongoDBAdapter) LoadSnapshot(ctx context.Context, documentID string, version int64) (*DocumentSnapshot, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	// 특정 버전 요청이 아니면 최신 버전 로드
+	filter := bson.M{"documentId": documentID}
+	if version > 0 {
+		filter["version"] = version
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	// 스냅샷 가져오기
+	var mongoSnap mongoSnapshot
+	err := a.snapshotCollection.FindOne(ctx, filter, opts).Decode(&mongoSnap)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("snapshot not found for document: %s, version: %d", documentID, version)
+		}
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	// 데이터 역직렬화
+	dataJSON, err := decompressSnapshotData([]byte(mongoSnap.Data))
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if err := json.Unmarshal(dataJSON, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot data: %w", err)
+	}
+
+	// 메타데이터 역직렬화
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(mongoSnap.Metadata), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot metadata: %w", err)
+	}
+
+	return &DocumentSnapshot{
+		DocumentID: documentID,
+		Version:    mongoSnap.Version,
+		Timestamp:  mongoSnap.Timestamp,
+		Data:       data,
+		Metadata:   metadata,
+	}, nil
+}
+
+// ListSnapshots은 문서의 모든 스냅샷 버전 목록을 최신 버전부터 반환합니다.
+func (a *MongoDBAdapter) ListSnapshots(ctx context.Context, documentID string) ([]int64, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	// 스냅샷 버전 목록 가져오기
+	opts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"version": 1})
+	cursor, err := a.snapshotCollection.Find(ctx, bson.M{"documentId": documentID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find snapshots: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	// 스냅샷 버전 목록 생성
+	var versions []int64
+	for cursor.Next(ctx) {
+		var mongoSnap mongoSnapshot
+		if err := cursor.Decode(&mongoSnap); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		versions = append(versions, mongoSnap.Version)
+	}
+
+	// 오류 확인
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return versions, nil
+}
+
+// DeleteSnapshot은 문서 스냅샷을 삭제합니다.
+func (a *MongoDBAdapter) DeleteSnapshot(ctx context.Context, documentID string, version int64) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// 스냅샷 삭제
+	_, err := a.snapshotCollection.DeleteOne(ctx, bson.M{"documentId": documentID, "version": version})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAllSnapshots은 문서의 모든 스냅샷을 삭제합니다.
+func (a *MongoDBAdapter) DeleteAllSnapshots(ctx context.Context, documentID string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// 모든 스냅샷 삭제
+	_, err := a.snapshotCollection.DeleteMany(ctx, bson.M{"documentId": documentID})
+	if err != nil {
+		return fmt.Errorf("failed to delete all snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshotOptions는 스냅샷 옵션을 반환합니다.
+func (a *MongoDBAdapter) GetSnapshotOptions() *SnapshotOptions {
+	return a.snapshotOptions
+}
+
+// SetSnapshotOptions는 스냅샷 옵션을 설정합니다.
+func (a *MongoDBAdapter) SetSnapshotOptions(options *SnapshotOptions) {
+	a.snapshotOptions = options
+}
+
+// CreateSnapshot은 문서의 스냅샷을 생성합니다.
+func (a *MongoDBAdapter) CreateSnapshot(ctx context.Context, doc *Document) (*DocumentSnapshot, error) {
+	// 문서 내용 가져오기
+	content, err := doc.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document content: %w", err)
+	}
+
+	// 스냅샷 생성
+	snapshot := &DocumentSnapshot{
+		DocumentID: doc.ID,
+		Version:    doc.Version,
+		Timestamp:  time.Now(),
+		Data:       content,
+		Metadata:   doc.Metadata,
+	}
+
+	return snapshot, nil
+}
+
+// RestoreFromSnapshot은 스냅샷에서 문서를 복원합니다.
+func (a *MongoDBAdapter) RestoreFromSnapshot(ctx context.Context, documentID string, version int64) (interface{}, error) {
+	// 스냅샷 로드
+	snapshot, err := a.LoadSnapshot(ctx, documentID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	return snapshot.Data, nil
+}
@@ -0,0 +1,91 @@
+package crdtstorage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// TestMongoDBAdapter는 MongoDB 어댑터의 낙관적 동시성 제어와 스냅샷을 테스트합니다.
+// MongoDB 서버에 연결할 수 없으면 건너뜁니다.
+func TestMongoDBAdapter(t *testing.T) {
+	// 컨텍스트 생성
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// MongoDB 연결
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetServerSelectionTimeout(time.Second))
+	if err != nil {
+		t.Skipf("MongoDB is not available: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Skipf("MongoDB is not available: %v", err)
+	}
+
+	// 테스트용 데이터베이스 생성
+	db := client.Database(fmt.Sprintf("crdtstorage_test_%d", time.Now().UnixNano()))
+	defer db.Drop(context.Background())
+
+	// 같은 컬렉션을 사용하는 두 어댑터 생성
+	first, err := NewMongoDBAdapter(ctx, db.Collection("documents"), db.Collection("snapshots"))
+	assert.NoError(t, err)
+	second, err := NewMongoDBAdapter(ctx, db.Collection("documents"), db.Collection("snapshots"))
+	assert.NoError(t, err)
+	first.SetSnapshotOptions(&SnapshotOptions{Enabled: true, SnapshotOnSave: true, MaxSnapshots: 2})
+
+	// 문서 저장
+	doc := &Document{
+		ID:           "test-doc",
+		CRDTDoc:      crdt.NewDocument(common.NewSessionID()),
+		LastModified: time.Now(),
+		Metadata:     map[string]interface{}{"test": "metadata"},
+		Version:      1,
+	}
+	assert.NoError(t, first.SaveDocument(ctx, doc))
+
+	// 다른 어댑터에서 로드
+	data, err := second.LoadDocument(ctx, "test-doc")
+	assert.NoError(t, err)
+	loaded := &Document{CRDTDoc: crdt.NewDocument(common.NewSessionID())}
+	assert.NoError(t, NewDefaultDocumentSerializer().Deserialize(loaded, data))
+	assert.Equal(t, "test-doc", loaded.ID)
+
+	// 첫 번째 어댑터가 저장한 후 두 번째 어댑터의 저장은 충돌
+	doc.Version = 2
+	assert.NoError(t, first.SaveDocument(ctx, doc))
+	err = second.SaveDocument(ctx, loaded)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	// 다시 로드한 후에는 저장 가능
+	_, err = second.LoadDocument(ctx, "test-doc")
+	assert.NoError(t, err)
+	assert.NoError(t, second.SaveDocument(ctx, loaded))
+
+	// 최대 스냅샷 수만큼만 유지
+	doc.Version = 3
+	err = first.SaveDocument(ctx, doc)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	_, err = first.LoadDocument(ctx, "test-doc")
+	assert.NoError(t, err)
+	assert.NoError(t, first.SaveDocument(ctx, doc))
+	versions, err := first.ListSnapshots(ctx, "test-doc")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, versions)
+
+	// 문서 삭제 시 스냅샷도 삭제
+	assert.NoError(t, first.DeleteDocument(ctx, "test-doc"))
+	versions, err = first.ListSnapshots(ctx, "test-doc")
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+	_, err = second.LoadDocument(ctx, "test-doc")
+	assert.Error(t, err)
+}
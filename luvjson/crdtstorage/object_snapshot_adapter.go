@@ -0,0 +1,458 @@
+package crdtstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObjectSnapshotOptions는 객체 저장소 스냅샷 어댑터 옵션을 나타냅니다.
+type ObjectSnapshotOptions struct {
+	// Prefix는 스냅샷 객체 키 접두사입니다.
+	Prefix string
+
+	// MaxAge는 스냅샷을 유지할 최대 기간입니다. 0이면 기간으로 삭제하지 않습니다.
+	// ApplyLifecycle이 이보다 오래된 스냅샷을 삭제합니다.
+	MaxAge time.Duration
+}
+
+// DefaultObjectSnapshotOptions는 기본 객체 저장소 스냅샷 어댑터 옵션을 반환합니다.
+func DefaultObjectSnapshotOptions() *ObjectSnapshotOptions {
+	return &ObjectSnapshotOptions{
+		Prefix: "snapshots",
+		MaxAge: 0,
+	}
+}
+
+// objectSnapshot은 객체 저장소에 저장되는 스냅샷입니다.
+type objectSnapshot struct {
+	// DocumentID는 문서의 고유 식별자입니다.
+	DocumentID string `json:"documentId"`
+
+	// Version은 스냅샷의 버전 번호입니다.
+	Version int64 `json:"version"`
+
+	// Timestamp는 스냅샷이 생성된 시간입니다.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Data는 스냅샷 데이터입니다.
+	Data json.RawMessage `json:"data"`
+
+	// Metadata는 스냅샷 메타데이터입니다.
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// ObjectSnapshotAdapter는 문서는 기본 영구 저장소에 두고 스냅샷은 객체 저장소에 저장하는 어댑터입니다.
+// 큰 문서의 스냅샷을 기본 데이터베이스에서 분리하고, Offload로 사용하지 않는 문서를
+// 기본 저장소에서 내보낼 수 있습니다. 내보낸 문서는 처음 로드될 때 최신 스냅샷에서 복원됩니다.
+type ObjectSnapshotAdapter struct {
+	// persistence는 문서가 저장될 기본 영구 저장소입니다.
+	persistence PersistenceAdapter
+
+	// store는 스냅샷이 저장될 객체 저장소입니다.
+	store ObjectStore
+
+	// prefix는 스냅샷 객체 키 접두사입니다.
+	prefix string
+
+	// maxAge는 스냅샷을 유지할 최대 기간입니다.
+	maxAge time.Duration
+
+	// mutex는 스냅샷 옵션에 대한 동시 접근을 보호합니다.
+	mutex sync.RWMutex
+
+	// snapshotOptions는 스냅샷 옵션입니다.
+	snapshotOptions *SnapshotOptions
+}
+
+// NewObjectSnapshotAdapter는 새 객체 저장소 스냅샷 어댑터를 생성합니다.
+func NewObjectSnapshotAdapter(persistence PersistenceAdapter, store ObjectStore, options *ObjectSnapshotOptions) (*ObjectSnapshotAdapter, error) {
+	if persistence == nil {
+		return nil, fmt.Errorf("persistence cannot be nil")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("object store cannot be nil")
+	}
+	if options == nil {
+		options = DefaultObjectSnapshotOptions()
+	}
+
+	return &ObjectSnapshotAdapter{
+		persistence: persistence,
+		store:       store,
+		prefix:      strings.TrimSuffix(options.Prefix, "/"),
+		maxAge:      options.MaxAge,
+		snapshotOptions: &SnapshotOptions{
+			Enabled:      true,
+			Interval:     time.Hour,
+			MaxSnapshots: 10,
+		},
+	}, nil
+}
+
+// getDocumentPrefix는 문서의 스냅샷 객체 키 접두사를 반환합니다.
+func (a *ObjectSnapshotAdapter) getDocumentPrefix(documentID string) string {
+	return a.prefix + "/" + url.PathEscape(documentID) + "/"
+}
+
+// getSnapshotKey는 스냅샷 객체 키를 반환합니다.
+// 버전을 0으로 채워 키 순서가 버전 순서와 같도록 합니다.
+func (a *ObjectSnapshotAdapter) getSnapshotKey(documentID string, version int64) string {
+	return fmt.Sprintf("%s%020d.json", a.getDocumentPrefix(documentID), version)
+}
+
+// parseSnapshotKey는 스냅샷 객체 키에서 문서 ID와 버전을 추출합니다.
+func (a *ObjectSnapshotAdapter) parseSnapshotKey(key string) (string, int64, bool) {
+	rest, ok := strings.CutPrefix(key, a.prefix+"/")
+	if !ok {
+		return "", 0, false
+	}
+	escapedID, name, ok := strings.Cut(rest, "/")
+	if !ok {
+		return "", 0, false
+	}
+	documentID, err := url.PathUnescape(escapedID)
+	if err != nil {
+		return "", 0, false
+	}
+	version, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return documentID, version, true
+}
+
+// SaveDocument는 문서를 기본 영구 저장소에 저장합니다.
+// 저장 시 스냅샷 생성이 설정된 경우 스냅샷을 객체 저장소에 저장합니다.
+func (a *ObjectSnapshotAdapter) SaveDocument(ctx context.Context, doc *Document) error {
+	if err := a.persistence.SaveDocument(ctx, doc); err != nil {
+		return err
+	}
+
+	// 스냅샷 생성 (필요한 경우)
+	options := a.GetSnapshotOptions()
+	if options.Enabled && options.SnapshotOnSave {
+		snapshot, err := a.CreateSnapshot(ctx, doc)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot: %w", err)
+		}
+		if err := a.SaveSnapshot(ctx, snapshot); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// LoadDocument는 문서를 기본 영구 저장소에서 로드합니다.
+// 기본 영구 저장소에 문서가 없으면 객체 저장소의 최신 스냅샷에서 문서 데이터를 복원합니다.
+// 복원된 문서는 다음에 저장될 때 기본 영구 저장소에 다시 저장됩니다.
+func (a *ObjectSnapshotAdapter) LoadDocument(ctx context.Context, documentID string) ([]byte, error) {
+	data, err := a.persistence.LoadDocument(ctx, documentID)
+	if err == nil {
+		return data, nil
+	}
+
+	// 최신 스냅샷에서 복원
+	snapshot, snapshotErr := a.loadSnapshot(ctx, documentID, 0)
+	if snapshotErr != nil {
+		return nil, err
+	}
+	content, marshalErr := json.Marshal(snapshot.Data)
+	if marshalErr != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot data: %w", marshalErr)
+	}
+
+	// DefaultDocumentSerializer 형식으로 문서 데이터 생성
+	data, err = json.Marshal(DocumentData{
+		ID:           documentID,
+		Content:      content,
+		LastModified: snapshot.Timestamp,
+		Metadata:     snapshot.Metadata,
+		Version:      snapshot.Version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document data: %w", err)
+	}
+
+	return data, nil
+}
+
+// ListDocuments는 기본 영구 저장소의 문서와 객체 저장소로 내보낸 문서의 목록을 반환합니다.
+func (a *ObjectSnapshotAdapter) ListDocuments(ctx context.Context) ([]string, error) {
+	ids, err := a.persistence.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 스냅샷이 있는 문서 추가
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	infos, err := a.store.ListObjects(ctx, a.prefix+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	for _, info := range infos {
+		documentID, _, ok := a.parseSnapshotKey(info.Key)
+		if ok && !seen[documentID] {
+			seen[documentID] = true
+			ids = append(ids, documentID)
+		}
+	}
+
+	return ids, nil
+}
+
+// DeleteDocument는 문서를 기본 영구 저장소에서 삭제하고 스냅샷을 객체 저장소에서 삭제합니다.
+func (a *ObjectSnapshotAdapter) DeleteDocument(ctx context.Context, documentID string) error {
+	if err := a.persistence.DeleteDocument(ctx, documentID); err != nil {
+		return err
+	}
+	return a.DeleteAllSnapshots(ctx, documentID)
+}
+
+// Offload는 문서의 스냅샷을 객체 저장소에 저장한 후 기본 영구 저장소에서 문서를 삭제합니다.
+// 내보낸 문서는 LoadDocument에서 스냅샷으로 복원됩니다.
+func (a *ObjectSnapshotAdapter) Offload(ctx context.Context, doc *Document) error {
+	snapshot, err := a.CreateSnapshot(ctx, doc)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	if err := a.SaveSnapshot(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	if err := a.persistence.DeleteDocument(ctx, doc.ID); err != nil {
+		return fmt.Errorf("failed to delete offloaded document: %w", err)
+	}
+
+	return nil
+}
+
+// Close는 기본 영구 저장소를 닫습니다.
+func (a *ObjectSnapshotAdapter) Close() error {
+	return a.persistence.Close()
+}
+
+// SaveSnapshot은 문서 스냅샷을 객체 저장소에 저장하고 오래된 스냅샷을 정리합니다.
+func (a *ObjectSnapshotAdapter) SaveSnapshot(ctx context.Context, snapshot *DocumentSnapshot) error {
+	// 스냅샷 직렬화
+	data, err := json.Marshal(snapshot.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot data: %w", err)
+	}
+	object, err := json.Marshal(objectSnapshot{
+		DocumentID: snapshot.DocumentID,
+		Version:    snapshot.Version,
+		Timestamp:  snapshot.Timestamp,
+		Data:       data,
+		Metadata:   snapshot.Metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	object, err = compressSnapshotData(a.GetSnapshotOptions().Compression, object)
+	if err != nil {
+		return err
+	}
+
+	// 스냅샷 저장
+	if err := a.store.PutObject(ctx, a.getSnapshotKey(snapshot.DocumentID, snapshot.Version), object); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	// 오래된 스냅샷 정리
+	if maxSnapshots := a.GetSnapshotOptions().MaxSnapshots; maxSnapshots > 0 {
+		versions, err := a.ListSnapshots(ctx, snapshot.DocumentID)
+		if err != nil {
+			return fmt.Errorf("failed to cleanup old snapshots: %w", err)
+		}
+		for len(versions) > maxSnapshots {
+			if err := a.DeleteSnapshot(ctx, snapshot.DocumentID, versions[len(versions)-1]); err != nil {
+				return fmt.Errorf("failed to cleanup old snapshots: %w", err)
+			}
+			versions = versions[:len(versions)-1]
+		}
+	}
+
+	return nil
+}
+
+// LoadSnapshot은 문서 스냅샷을 객체 저장소에서 로드합니다.
+func (a *ObjectSnapshotAdapter) LoadSnapshot(ctx context.Context, documentID string, version int64) (*DocumentSnapshot, error) {
+	return a.loadSnapshot(ctx, documentID, version)
+}
+
+// loadSnapshot은 스냅샷을 로드합니다. 버전이 0이면 최신 버전을 로드합니다.
+func (a *ObjectSnapshotAdapter) loadSnapshot(ctx context.Context, documentID string, version int64) (*DocumentSnapshot, error) {
+	// 최신 버전 요청인 경우
+	if version <= 0 {
+		versions, err := a.ListSnapshots(ctx, documentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("snapshot not found for document: %s, version: %d", documentID, version)
+		}
+		version = versions[0]
+	}
+
+	// 스냅샷 가져오기
+	object, err := a.store.GetObject(ctx, a.getSnapshotKey(documentID, version))
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, fmt.Errorf("snapshot not found for document: %s, version: %d", documentID, version)
+		}
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	// 스냅샷 역직렬화
+	object, err = decompressSnapshotData(object)
+	if err != nil {
+		return nil, err
+	}
+	var stored objectSnapshot
+	if err := json.Unmarshal(object, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	var data interface{}
+	if err := json.Unmarshal(stored.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot data: %w", err)
+	}
+
+	return &DocumentSnapshot{
+		DocumentID: documentID,
+		Version:    stored.Version,
+		Timestamp:  stored.Timestamp,
+		Data:       data,
+		Metadata:   stored.Metadata,
+	}, nil
+}
+
+// ListSnapshots은 문서의 모든 스냅샷 버전 목록을 최신 버전부터 반환합니다.
+func (a *ObjectSnapshotAdapter) ListSnapshots(ctx context.Context, documentID string) ([]int64, error) {
+	infos, err := a.store.ListObjects(ctx, a.getDocumentPrefix(documentID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	// 키 순서가 버전 순서이므로 역순으로 추가
+	versions := make([]int64, 0, len(infos))
+	for i := len(infos) - 1; i >= 0; i-- {
+		if _, version, ok := a.parseSnapshotKey(infos[i].Key); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
+
+// DeleteSnapshot은 문서 스냅샷을 객체 저장소에서 삭제합니다.
+func (a *ObjectSnapshotAdapter) DeleteSnapshot(ctx context.Context, documentID string, version int64) error {
+	if err := a.store.DeleteObject(ctx, a.getSnapshotKey(documentID, version)); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// DeleteAllSnapshots은 문서의 모든 스냅샷을 객체 저장소에서 삭제합니다.
+func (a *ObjectSnapshotAdapter) DeleteAllSnapshots(ctx context.Context, documentID string) error {
+	versions, err := a.ListSnapshots(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete all snapshots: %w", err)
+	}
+	for _, version := range versions {
+		if err := a.DeleteSnapshot(ctx, documentID, version); err != nil {
+			return fmt.Errorf("failed to delete all snapshots: %w", err)
+		}
+	}
+	return nil
+}
+
+// ApplyLifecycle은 MaxAge보다 오래된 스냅샷을 삭제하고 삭제한 스냅샷 수를 반환합니다.
+// 내보낸 문서를 복원할 수 있도록 각 문서의 최신 스냅샷은 유지합니다.
+// 객체 저장소의 수명 주기 규칙(S3ObjectStore.SetExpiration)과 달리 최신 스냅샷을 보호합니다.
+func (a *ObjectSnapshotAdapter) ApplyLifecycle(ctx context.Context) (int, error) {
+	if a.maxAge <= 0 {
+		return 0, nil
+	}
+
+	infos, err := a.store.ListObjects(ctx, a.prefix+"/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	// 키 순서가 문서별 버전 순서이므로, 다음 키가 같은 문서이면 최신 스냅샷이 아님
+	cutoff := time.Now().Add(-a.maxAge)
+	deleted := 0
+	for i, info := range infos {
+		documentID, _, ok := a.parseSnapshotKey(info.Key)
+		if !ok || !info.LastModified.Before(cutoff) || i == len(infos)-1 {
+			continue
+		}
+		if nextID, _, ok := a.parseSnapshotKey(infos[i+1].Key); !ok || nextID != documentID {
+			continue
+		}
+		if err := a.store.DeleteObject(ctx, info.Key); err != nil {
+			return deleted, fmt.Errorf("failed to delete snapshot: %w", err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// GetSnapshotOptions는 스냅샷 옵션을 반환합니다.
+func (a *ObjectSnapshotAdapter) GetSnapshotOptions() *SnapshotOptions {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.snapshotOptions
+}
+
+// SetSnapshotOptions는 스냅샷 옵션을 설정합니다.
+func (a *ObjectSnapshotAdapter) SetSnapshotOptions(options *SnapshotOptions) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.snapshotOptions = options
+}
+
+// CreateSnapshot은 문서의 스냅샷을 생성합니다.
+func (a *ObjectSnapshotAdapter) CreateSnapshot(ctx context.Context, doc *Document) (*DocumentSnapshot, error) {
+	// 문서 내용 가져오기
+	content, err := doc.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document content: %w", err)
+	}
+
+	// 스냅샷 생성
+	snapshot := &DocumentSnapshot{
+		DocumentID: doc.ID,
+		Version:    doc.Version,
+		Timestamp:  time.Now(),
+		Data:       content,
+		Metadata:   doc.Metadata,
+	}
+
+	return snapshot, nil
+}
+
+// RestoreFromSnapshot은 스냅샷에서 문서를 복원합니다.
+func (a *ObjectSnapshotAdapter) RestoreFromSnapshot(ctx context.Context, documentID string, version int64) (interface{}, error) {
+	// 스냅샷 로드
+	snapshot, err := a.LoadSnapshot(ctx, documentID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	return snapshot.Data, nil
+}
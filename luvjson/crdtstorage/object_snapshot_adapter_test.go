@@ -0,0 +1,80 @@
+package crdtstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+)
+
+// newContentDocument는 지정된 JSON 내용을 가진 테스트용 문서를 생성합니다.
+func newContentDocument(t *testing.T, documentID string, content string) *Document {
+	doc := &Document{
+		ID:        documentID,
+		CRDTDoc:   crdt.NewDocument(common.NewSessionID()),
+		SessionID: common.NewSessionID(),
+	}
+	data := `{"id":"` + documentID + `","content":` + content + `,"metadata":{},"version":1}`
+	assert.NoError(t, NewDefaultDocumentSerializer().Deserialize(doc, []byte(data)))
+	return doc
+}
+
+// TestObjectSnapshotAdapter는 객체 저장소 스냅샷, 내보내기, 지연 복원, 수명 주기를 테스트합니다.
+func TestObjectSnapshotAdapter(t *testing.T) {
+	ctx := context.Background()
+
+	// 어댑터 생성
+	primary := NewMemoryAdapter()
+	store := NewMemoryObjectStore()
+	options := DefaultObjectSnapshotOptions()
+	options.MaxAge = time.Millisecond
+	adapter, err := NewObjectSnapshotAdapter(primary, store, options)
+	assert.NoError(t, err)
+	adapter.SetSnapshotOptions(&SnapshotOptions{Enabled: true, SnapshotOnSave: true, MaxSnapshots: 2})
+
+	// 저장할 때마다 스냅샷 생성, 최대 스냅샷 수만큼만 유지
+	doc := newContentDocument(t, "raid/1", `{"hp":100}`)
+	for version := int64(1); version <= 3; version++ {
+		doc.Version = version
+		assert.NoError(t, adapter.SaveDocument(ctx, doc))
+	}
+	versions, err := adapter.ListSnapshots(ctx, "raid/1")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, versions)
+
+	// 내보낸 문서는 기본 저장소에서 삭제되지만 목록에 남음
+	assert.NoError(t, adapter.Offload(ctx, doc))
+	_, err = primary.LoadDocument(ctx, "raid/1")
+	assert.Error(t, err)
+	ids, err := adapter.ListDocuments(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"raid/1"}, ids)
+
+	// 처음 로드할 때 최신 스냅샷에서 복원
+	data, err := adapter.LoadDocument(ctx, "raid/1")
+	assert.NoError(t, err)
+	restored := &Document{CRDTDoc: crdt.NewDocument(common.NewSessionID())}
+	assert.NoError(t, NewDefaultDocumentSerializer().Deserialize(restored, data))
+	content, err := restored.GetContent()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, content)
+	assert.Equal(t, int64(3), restored.Version)
+
+	// 수명 주기는 최신 스냅샷을 유지
+	time.Sleep(5 * time.Millisecond)
+	deleted, err := adapter.ApplyLifecycle(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	versions, err = adapter.ListSnapshots(ctx, "raid/1")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, versions)
+
+	// 문서 삭제 시 스냅샷도 삭제
+	assert.NoError(t, adapter.DeleteDocument(ctx, "raid/1"))
+	_, err = adapter.LoadDocument(ctx, "raid/1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package crdtstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrObjectNotFound는 객체 저장소에 객체가 없음을 나타냅니다.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo는 객체 저장소에 저장된 객체의 정보를 나타냅니다.
+type ObjectInfo struct {
+	// Key는 객체 키입니다.
+	Key string
+
+	// Size는 객체 크기(바이트)입니다.
+	Size int64
+
+	// LastModified는 객체가 마지막으로 수정된 시간입니다.
+	LastModified time.Time
+}
+
+// ObjectStore는 S3, MinIO, GCS 같은 객체 저장소 인터페이스입니다.
+type ObjectStore interface {
+	// PutObject는 객체를 저장합니다.
+	PutObject(ctx context.Context, key string, data []byte) error
+
+	// GetObject는 객체를 가져옵니다. 객체가 없으면 ErrObjectNotFound를 반환합니다.
+	GetObject(ctx context.Context, key string) ([]byte, error)
+
+	// DeleteObject는 객체를 삭제합니다. 객체가 없어도 오류를 반환하지 않습니다.
+	DeleteObject(ctx context.Context, key string) error
+
+	// ListObjects는 접두사로 시작하는 객체 목록을 키 순서로 반환합니다.
+	ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// memoryObject는 메모리 객체 저장소의 객체입니다.
+type memoryObject struct {
+	// data는 객체 데이터입니다.
+	data []byte
+
+	// lastModified는 객체가 마지막으로 수정된 시간입니다.
+	lastModified time.Time
+}
+
+// MemoryObjectStore는 메모리 기반 객체 저장소입니다. 테스트와 단일 노드 환경에서 사용합니다.
+type MemoryObjectStore struct {
+	// objects는 객체 키에서 객체로의 맵입니다.
+	objects map[string]memoryObject
+
+	// mutex는 객체 맵에 대한 동시 접근을 보호합니다.
+	mutex sync.RWMutex
+}
+
+// NewMemoryObjectStore는 새 메모리 객체 저장소를 생성합니다.
+func NewMemoryObjectStore() *MemoryObjectStore {
+	return &MemoryObjectStore{
+		objects: make(map[string]memoryObject),
+	}
+}
+
+// PutObject는 객체를 메모리에 저장합니다.
+func (s *MemoryObjectStore) PutObject(ctx context.Context, key string, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.objects[key] = memoryObject{
+		data:         append([]byte(nil), data...),
+		lastModified: time.Now(),
+	}
+	return nil
+}
+
+// GetObject는 객체를 메모리에서 가져옵니다.
+func (s *MemoryObjectStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return append([]byte(nil), object.data...), nil
+}
+
+// DeleteObject는 객체를 메모리에서 삭제합니다.
+func (s *MemoryObjectStore) DeleteObject(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+// ListObjects는 접두사로 시작하는 객체 목록을 키 순서로 반환합니다.
+func (s *MemoryObjectStore) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var infos []ObjectInfo
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, ObjectInfo{
+				Key:          key,
+				Size:         int64(len(object.data)),
+				LastModified: object.lastModified,
+			})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, nil
+}
+
+// FileObjectStore는 파일 시스템 기반 객체 저장소입니다.
+// 객체 키의 "/"는 하위 디렉토리로 저장됩니다. 단일 노드 환경의 콜드 저장소로 사용합니다.
+type FileObjectStore struct {
+	// basePath는 객체 파일이 저장될 디렉토리입니다.
+	basePath string
+
+	// mutex는 파일 작업에 대한 동시 접근을 보호합니다.
+	mutex sync.RWMutex
+}
+
+// NewFileObjectStore는 새 파일 객체 저장소를 생성합니다.
+func NewFileObjectStore(basePath string) (*FileObjectStore, error) {
+	// 디렉토리 생성
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	return &FileObjectStore{
+		basePath: basePath,
+	}, nil
+}
+
+// getObjectPath는 객체 키에 대한 파일 경로를 반환합니다.
+func (s *FileObjectStore) getObjectPath(key string) string {
+	return filepath.Join(s.basePath, filepath.FromSlash(key))
+}
+
+// PutObject는 임시 파일에 객체를 쓴 뒤 이름을 변경하여 원자적으로 저장합니다.
+func (s *FileObjectStore) PutObject(ctx context.Context, key string, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := s.getObjectPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename object: %w", err)
+	}
+	return nil
+}
+
+// GetObject는 파일에서 객체를 가져옵니다.
+func (s *FileObjectStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data, err := os.ReadFile(s.getObjectPath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	return data, nil
+}
+
+// DeleteObject는 객체 파일을 삭제합니다.
+func (s *FileObjectStore) DeleteObject(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.Remove(s.getObjectPath(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// ListObjects는 접두사로 시작하는 객체 목록을 키 순서로 반환합니다.
+func (s *FileObjectStore) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var infos []ObjectInfo
+	err := filepath.WalkDir(s.basePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.basePath, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		infos = append(infos, ObjectInfo{
+			Key:          key,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, nil
+}
@@ -2,6 +2,8 @@ package crdtstorage
 
 import (
 	"time"
+
+	"tictactoe/luvjson/crdtpubsub"
 )
 
 // StorageOptions는 저장소 옵션을 나타냅니다.
@@ -10,6 +12,10 @@ type StorageOptions struct {
 	// 지원되는 값: "memory", "redis"
 	PubSubType string
 
+	// PubSub은 사용자 정의 PubSub입니다. 설정하면 PubSubType 대신 사용합니다.
+	// 같은 문서를 여러 프로세스에서 호스팅할 때 문서 이벤트를 주고받는 데 사용됩니다.
+	PubSub crdtpubsub.PubSub
+
 	// PersistenceType은 영구 저장소 유형입니다.
 	// 지원되는 값: "memory", "file", "redis"
 	PersistenceType string
@@ -26,9 +32,20 @@ type StorageOptions struct {
 	// KeyPrefix는 Redis 키 접두사입니다.
 	KeyPrefix string
 
+	// PersistenceTTL은 Redis 영구 저장소에 저장된 문서의 만료 시간입니다.
+	// 0이면 만료되지 않습니다. 매치 문서처럼 일시적인 문서에 사용합니다.
+	PersistenceTTL time.Duration
+
 	// PersistencePath는 파일 기반 영구 저장소의 경로입니다.
 	PersistencePath string
 
+	// PatchLogPath는 파일 기반 패치 로그(write-ahead log)의 경로입니다.
+	// 설정하면 편집 패치를 적용 전에 기록하고, 저장소 생성 시 저장되지 않은 변경사항을 복구합니다.
+	PatchLogPath string
+
+	// PatchLog는 사용자 정의 패치 로그입니다. 설정하면 PatchLogPath보다 우선합니다.
+	PatchLog PatchLog
+
 	// AutoSave는 자동 저장 활성화 여부입니다.
 	AutoSave bool
 
@@ -61,11 +78,32 @@ type StorageOptions struct {
 	SnapshotInterval time.Duration
 
 	// MaxSnapshots는 유지할 최대 스냅샷 수입니다.
+	// 자동 스냅샷이 활성화되면 최근 MaxSnapshots개를 유지하고 나머지는 SnapshotKeepDaily에 따라 정리합니다.
 	MaxSnapshots int
 
+	// SnapshotEveryPatches는 자동 스냅샷을 생성할 패치 수입니다.
+	// 0이면 SnapshotInterval마다 변경된 문서만 스냅샷을 생성합니다.
+	SnapshotEveryPatches int
+
+	// SnapshotKeepDaily는 최근 MaxSnapshots개 외에 하루에 하나씩 스냅샷을 보존할 일 수입니다.
+	SnapshotKeepDaily int
+
 	// SnapshotOnSave는 저장 시 스냅샷 생성 여부입니다.
 	SnapshotOnSave bool
 
+	// SnapshotCompression은 영구 저장소에 저장되는 스냅샷 데이터의 압축 방식입니다.
+	// 지원되는 값: SnapshotCompressionNone, SnapshotCompressionGzip, SnapshotCompressionZstd
+	SnapshotCompression SnapshotCompression
+
 	// SnapshotTableName은 SQL 어댑터에서 사용할 스냅샷 테이블 이름입니다.
 	SnapshotTableName string
+
+	// ArchiveInterval은 영구 저장소가 ArchiveAdapter처럼 문서 보관을 지원할 때
+	// 유휴 문서를 콜드 저장소로 옮기는 간격입니다. 0이면 자동으로 보관하지 않습니다.
+	ArchiveInterval time.Duration
+
+	// MaxSaveRetries는 문서를 로드한 후 다른 인스턴스가 먼저 저장했을 때
+	// 저장된 패치를 병합하고 저장을 재시도할 최대 횟수입니다.
+	// 영구 저장소가 VersionedAdapter를 구현할 때만 사용됩니다.
+	MaxSaveRetries int
 }
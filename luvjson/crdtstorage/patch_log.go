@@ -0,0 +1,321 @@
+package crdtstorage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// PatchLog는 문서별 추가 전용 패치 로그(write-ahead log) 인터페이스입니다.
+// 패치는 문서에 적용되기 전에 로그에 기록되며, 비정상 종료 후 마지막 체크포인트 위에 재생하여 문서를 복구합니다.
+type PatchLog interface {
+	// Append는 문서의 패치를 로그 끝에 추가합니다.
+	Append(ctx context.Context, documentID string, patch *crdtpatch.Patch) error
+
+	// Checkpoint는 문서의 CRDT 상태를 저장하고 그 이전의 패치를 로그에서 제거합니다.
+	Checkpoint(ctx context.Context, documentID string, state []byte) error
+
+	// Load는 문서의 마지막 체크포인트 상태와 그 이후 기록된 패치 목록을 반환합니다.
+	// 체크포인트가 없으면 state는 nil입니다.
+	Load(ctx context.Context, documentID string) (state []byte, patches []*crdtpatch.Patch, err error)
+
+	// ListDocuments는 로그가 있는 문서 ID 목록을 반환합니다.
+	ListDocuments(ctx context.Context) ([]string, error)
+
+	// Delete는 문서의 체크포인트와 패치 로그를 삭제합니다.
+	Delete(ctx context.Context, documentID string) error
+
+	// Close는 패치 로그를 닫습니다.
+	Close() error
+}
+
+// memoryPatchLogEntry는 메모리 패치 로그의 문서별 항목입니다.
+type memoryPatchLogEntry struct {
+	// state는 마지막 체크포인트 상태입니다.
+	state []byte
+
+	// patches는 직렬화된 패치 목록입니다.
+	patches [][]byte
+}
+
+// MemoryPatchLog는 메모리 기반 패치 로그입니다. 테스트에서 사용합니다.
+type MemoryPatchLog struct {
+	// entries는 문서 ID에서 로그 항목으로의 맵입니다.
+	entries map[string]*memoryPatchLogEntry
+
+	// mutex는 로그 항목에 대한 동시 접근을 보호합니다.
+	mutex sync.Mutex
+}
+
+// NewMemoryPatchLog는 새 메모리 패치 로그를 생성합니다.
+func NewMemoryPatchLog() *MemoryPatchLog {
+	return &MemoryPatchLog{
+		entries: make(map[string]*memoryPatchLogEntry),
+	}
+}
+
+// entry는 문서의 로그 항목을 반환합니다. 없으면 생성합니다.
+func (l *MemoryPatchLog) entry(documentID string) *memoryPatchLogEntry {
+	entry, ok := l.entries[documentID]
+	if !ok {
+		entry = &memoryPatchLogEntry{}
+		l.entries[documentID] = entry
+	}
+	return entry
+}
+
+// Append는 문서의 패치를 로그 끝에 추가합니다.
+func (l *MemoryPatchLog) Append(ctx context.Context, documentID string, patch *crdtpatch.Patch) error {
+	data, err := patch.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry := l.entry(documentID)
+	entry.patches = append(entry.patches, data)
+	return nil
+}
+
+// Checkpoint는 문서의 CRDT 상태를 저장하고 패치 로그를 비웁니다.
+func (l *MemoryPatchLog) Checkpoint(ctx context.Context, documentID string, state []byte) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry := l.entry(documentID)
+	entry.state = append([]byte(nil), state...)
+	entry.patches = nil
+	return nil
+}
+
+// Load는 문서의 마지막 체크포인트 상태와 패치 목록을 반환합니다.
+func (l *MemoryPatchLog) Load(ctx context.Context, documentID string) ([]byte, []*crdtpatch.Patch, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry, ok := l.entries[documentID]
+	if !ok {
+		return nil, nil, nil
+	}
+
+	patches := make([]*crdtpatch.Patch, 0, len(entry.patches))
+	for _, data := range entry.patches {
+		patch := &crdtpatch.Patch{}
+		if err := patch.UnmarshalJSON(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal patch: %w", err)
+		}
+		patches = append(patches, patch)
+	}
+	return append([]byte(nil), entry.state...), patches, nil
+}
+
+// ListDocuments는 로그가 있는 문서 ID 목록을 반환합니다.
+func (l *MemoryPatchLog) ListDocuments(ctx context.Context) ([]string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	ids := make([]string, 0, len(l.entries))
+	for id := range l.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete는 문서의 로그를 삭제합니다.
+func (l *MemoryPatchLog) Delete(ctx context.Context, documentID string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.entries, documentID)
+	return nil
+}
+
+// Close는 패치 로그를 닫습니다.
+func (l *MemoryPatchLog) Close() error {
+	return nil
+}
+
+// FilePatchLog는 파일 기반 패치 로그입니다.
+// 문서마다 체크포인트 상태 파일(<id>.state)과 한 줄에 패치 하나씩 기록하는 로그 파일(<id>.wal)을 유지합니다.
+type FilePatchLog struct {
+	// basePath는 로그 파일이 저장될 디렉토리입니다.
+	basePath string
+
+	// mutex는 파일 작업에 대한 동시 접근을 보호합니다.
+	mutex sync.Mutex
+}
+
+// NewFilePatchLog는 새 파일 패치 로그를 생성합니다.
+func NewFilePatchLog(basePath string) (*FilePatchLog, error) {
+	// 디렉토리 생성
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	return &FilePatchLog{
+		basePath: basePath,
+	}, nil
+}
+
+// getLogPath는 문서의 패치 로그 파일 경로를 반환합니다.
+func (l *FilePatchLog) getLogPath(documentID string) string {
+	return filepath.Join(l.basePath, url.PathEscape(documentID)+".wal")
+}
+
+// getStatePath는 문서의 체크포인트 상태 파일 경로를 반환합니다.
+func (l *FilePatchLog) getStatePath(documentID string) string {
+	return filepath.Join(l.basePath, url.PathEscape(documentID)+".state")
+}
+
+// Append는 패치를 로그 파일 끝에 추가하고 디스크에 동기화합니다.
+func (l *FilePatchLog) Append(ctx context.Context, documentID string, patch *crdtpatch.Patch) error {
+	data, err := patch.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	file, err := os.OpenFile(l.getLogPath(documentID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open patch log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write patch log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync patch log: %w", err)
+	}
+	return nil
+}
+
+// Checkpoint는 상태 파일을 원자적으로 교체한 뒤 로그 파일을 비웁니다.
+func (l *FilePatchLog) Checkpoint(ctx context.Context, documentID string, state []byte) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// 임시 파일에 상태 저장 후 이름 변경
+	statePath := l.getStatePath(documentID)
+	tmpPath := statePath + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	if _, err := file.Write(state); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync checkpoint: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, statePath); err != nil {
+		return fmt.Errorf("failed to rename checkpoint: %w", err)
+	}
+
+	// 체크포인트에 반영된 패치 제거
+	if err := os.Truncate(l.getLogPath(documentID), 0); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to truncate patch log: %w", err)
+	}
+	return nil
+}
+
+// Load는 상태 파일과 로그 파일을 읽습니다.
+// 기록 도중 종료되어 잘린 마지막 줄은 무시합니다.
+func (l *FilePatchLog) Load(ctx context.Context, documentID string) ([]byte, []*crdtpatch.Patch, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// 체크포인트 상태 읽기
+	state, err := os.ReadFile(l.getStatePath(documentID))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	// 패치 로그 읽기
+	file, err := os.Open(l.getLogPath(documentID))
+	if os.IsNotExist(err) {
+		return state, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open patch log: %w", err)
+	}
+	defer file.Close()
+
+	var patches []*crdtpatch.Patch
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// 줄바꿈 없이 끝난 마지막 줄은 완료되지 않은 기록
+			break
+		}
+		patch := &crdtpatch.Patch{}
+		if err := json.Unmarshal(line, patch); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal patch: %w", err)
+		}
+		patches = append(patches, patch)
+	}
+	return state, patches, nil
+}
+
+// ListDocuments는 로그 파일이 있는 문서 ID 목록을 반환합니다.
+func (l *FilePatchLog) ListDocuments(ctx context.Context) ([]string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entries, err := os.ReadDir(l.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".wal")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		id, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Delete는 문서의 상태 파일과 로그 파일을 삭제합니다.
+func (l *FilePatchLog) Delete(ctx context.Context, documentID string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, path := range []string{l.getLogPath(documentID), l.getStatePath(documentID)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete patch log: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close는 패치 로그를 닫습니다.
+func (l *FilePatchLog) Close() error {
+	return nil
+}
@@ -0,0 +1,131 @@
+package crdtstorage
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// setRootContent는 문서 루트를 주어진 값으로 설정하는 편집 함수를 반환합니다.
+func setRootContent(value interface{}) EditFunc {
+	return func(crdtDoc *crdt.Document, patchBuilder *crdtpatch.PatchBuilder) error {
+		rootID := crdtDoc.NextTimestamp()
+		patchBuilder.AddOperation(&crdtpatch.NewOperation{
+			ID:       rootID,
+			NodeType: common.NodeTypeCon,
+			Value:    value,
+		})
+		patchBuilder.AddOperation(&crdtpatch.InsOperation{
+			ID:       crdtDoc.NextTimestamp(),
+			TargetID: common.RootID,
+			Value:    rootID,
+		})
+		return nil
+	}
+}
+
+// TestStorage_RecoverDocuments는 저장 전 비정상 종료 후 패치 로그로 문서를 복구하는지 테스트합니다.
+func TestStorage_RecoverDocuments(t *testing.T) {
+	ctx := context.Background()
+
+	// 파일 저장소와 파일 패치 로그를 사용하는 저장소 옵션
+	options := DefaultStorageOptions()
+	options.PersistenceType = "file"
+	options.PersistencePath = t.TempDir()
+	options.PatchLogPath = t.TempDir()
+	options.AutoSave = false
+
+	// 문서 생성 후 저장
+	storage, err := NewStorage(ctx, options)
+	assert.NoError(t, err)
+	doc, err := storage.CreateDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	assert.True(t, doc.Edit(ctx, setRootContent(map[string]interface{}{"hp": 100})).Success)
+	assert.NoError(t, storage.SaveDocument(ctx, doc))
+
+	// 저장하지 않은 편집 후 종료 (저장 없이 닫으면 비정상 종료와 같음)
+	assert.True(t, doc.Edit(ctx, setRootContent(map[string]interface{}{"hp": 40})).Success)
+	assert.True(t, doc.Edit(ctx, setRootContent(map[string]interface{}{"hp": 10})).Success)
+	version := doc.Version
+	assert.NoError(t, storage.Close())
+
+	// 같은 경로로 저장소를 다시 열면 패치 로그 재생
+	storage, err = NewStorage(ctx, options)
+	assert.NoError(t, err)
+	defer storage.Close()
+
+	recovered, err := storage.GetDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	var content map[string]interface{}
+	assert.NoError(t, recovered.GetContentAs(&content))
+	assert.Equal(t, float64(10), content["hp"])
+	assert.Equal(t, version, recovered.Version)
+
+	// 복구 후 로그는 비어 있으므로 다시 복구할 문서가 없음
+	ids, err := storage.RecoverDocuments(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	// 로드 후 편집도 기록되어 복구 가능
+	assert.True(t, recovered.Edit(ctx, setRootContent("defeated")).Success)
+	assert.NoError(t, storage.Close())
+
+	storage, err = NewStorage(ctx, options)
+	assert.NoError(t, err)
+	defer storage.Close()
+	recovered, err = storage.GetDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	value, err := recovered.GetContent()
+	assert.NoError(t, err)
+	assert.Equal(t, "defeated", value)
+
+	// 문서 삭제 시 패치 로그도 삭제
+	assert.NoError(t, storage.DeleteDocument(ctx, "raid-1"))
+	log, err := NewFilePatchLog(options.PatchLogPath)
+	assert.NoError(t, err)
+	ids, err = log.ListDocuments(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+// TestFilePatchLog_TornWrite는 기록 도중 잘린 마지막 패치를 무시하는지 테스트합니다.
+func TestFilePatchLog_TornWrite(t *testing.T) {
+	ctx := context.Background()
+
+	log, err := NewFilePatchLog(t.TempDir())
+	assert.NoError(t, err)
+
+	// 체크포인트 후 패치 기록
+	assert.NoError(t, log.Checkpoint(ctx, "match-1", []byte("state")))
+	sid := common.NewSessionID()
+	for i := 1; i <= 2; i++ {
+		assert.NoError(t, log.Append(ctx, "match-1", crdtpatch.NewPatch(common.LogicalTimestamp{SID: sid, Counter: uint64(i)})))
+	}
+
+	// 마지막 줄이 잘린 상태 흉내
+	file, err := os.OpenFile(log.getLogPath("match-1"), os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte(`{"id":`))
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	state, patches, err := log.Load(ctx, "match-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "state", string(state))
+	if assert.Len(t, patches, 2) {
+		assert.Equal(t, uint64(2), patches[1].ID().Counter)
+	}
+
+	// 체크포인트하면 로그가 비워짐
+	assert.NoError(t, log.Checkpoint(ctx, "match-1", []byte("next")))
+	state, patches, err = log.Load(ctx, "match-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "next", string(state))
+	assert.Empty(t, patches)
+}
@@ -2,8 +2,13 @@ package crdtstorage
 
 import (
 	"context"
+	"errors"
 )
 
+// ErrVersionConflict는 문서를 마지막으로 로드하거나 저장한 후 다른 인스턴스가 문서를 저장하여
+// 저장에 실패했음을 나타냅니다. 문서를 다시 로드한 후 편집을 재시도해야 합니다.
+var ErrVersionConflict = errors.New("document version conflict")
+
 // PersistenceAdapter는 영구 저장소 어댑터 인터페이스입니다.
 // 이 인터페이스는 Document 객체와 저장소 간의 변환을 담당합니다.
 // SQL, NoSQL 등 다양한 저장소 타입에 맞게 구현할 수 있습니다.
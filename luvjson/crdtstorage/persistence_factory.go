@@ -34,9 +34,13 @@ func NewAdvancedSQLPersistence(db *sql.DB, tableName string, snapshotTableName s
 	return NewAdvancedSQLAdapter(db, tableName, snapshotTableName)
 }
 
-// NewMongoDBPersistence는 MongoDB 기반 영구 저장소를 생성합니다.
-func NewMongoDBPersistence(collection *mongo.Collection) PersistenceAdapter {
-	return NewMongoDBAdapter(collection)
+// NewMongoDBPersistence는 문서와 스냅샷을 분리된 컬렉션에 저장하는 MongoDB 기반 영구 저장소를 생성합니다.
+func NewMongoDBPersistence(ctx context.Context, collection *mongo.Collection, snapshotCollection *mongo.Collection) (PersistenceAdapter, error) {
+	adapter, err := NewMongoDBAdapter(ctx, collection, snapshotCollection)
+	if err != nil {
+		return nil, err
+	}
+	return adapter, nil
 }
 
 // createPersistenceAdapter는 영구 저장소 어댑터를 생성합니다.
@@ -65,7 +69,10 @@ func createPersistenceAdapter(ctx context.Context, options *StorageOptions, cust
 			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 		}
 
-		return NewRedisPersistence(redisClient, options.KeyPrefix), nil
+		adapterOptions := DefaultRedisAdapterOptions()
+		adapterOptions.KeyPrefix = options.KeyPrefix
+		adapterOptions.TTL = options.PersistenceTTL
+		return NewRedisAdapterWithOptions(redisClient, adapterOptions), nil
 	case "sql":
 		// SQL 어댑터는 외부에서 생성해야 함
 		return nil, fmt.Errorf("SQL persistence type requires a custom persistence adapter")
@@ -2,13 +2,55 @@ package crdtstorage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// RedisAdapterOptions는 Redis 어댑터 옵션을 나타냅니다.
+type RedisAdapterOptions struct {
+	// KeyPrefix는 Redis 키 접두사입니다.
+	KeyPrefix string
+
+	// TTL은 문서 상태, 패치 로그, 스냅샷 키의 만료 시간입니다. 0이면 만료되지 않습니다.
+	// 저장할 때마다 갱신되므로, 끝난 매치처럼 더 이상 저장되지 않는 문서는 자동으로 삭제됩니다.
+	TTL time.Duration
+
+	// MaxPatches는 문서별 패치 로그에 유지할 최대 패치 수입니다. 0이면 제한하지 않습니다.
+	MaxPatches int64
+}
+
+// DefaultRedisAdapterOptions는 기본 Redis 어댑터 옵션을 반환합니다.
+func DefaultRedisAdapterOptions() *RedisAdapterOptions {
+	return &RedisAdapterOptions{
+		KeyPrefix:  "luvjson",
+		TTL:        0,
+		MaxPatches: 1000,
+	}
+}
+
+// redisSnapshot은 Redis에 저장되는 스냅샷입니다.
+type redisSnapshot struct {
+	// Timestamp는 스냅샷이 생성된 시간입니다.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Data는 스냅샷 데이터입니다.
+	Data interface{} `json:"data"`
+
+	// Metadata는 스냅샷 메타데이터입니다.
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
 // RedisAdapter는 Redis 기반 영구 저장소 어댑터입니다.
+// 문서 상태, 패치 로그, 스냅샷을 각각의 키에 저장하고, 여러 명령을 파이프라인으로 전송합니다.
 type RedisAdapter struct {
 	// client는 Redis 클라이언트입니다.
 	client *redis.Client
@@ -16,19 +58,46 @@ type RedisAdapter struct {
 	// keyPrefix는 Redis 키 접두사입니다.
 	keyPrefix string
 
+	// ttl은 문서 키의 만료 시간입니다.
+	ttl time.Duration
+
+	// maxPatches는 문서별로 유지할 최대 패치 수입니다.
+	maxPatches int64
+
 	// mutex는 Redis 작업에 대한 동시 접근을 보호합니다.
 	mutex sync.RWMutex
 
 	// serializer는 문서 직렬화/역직렬화를 담당합니다.
 	serializer DocumentSerializer
+
+	// snapshotOptions는 스냅샷 옵션입니다.
+	snapshotOptions *SnapshotOptions
 }
 
 // NewRedisAdapter는 새 Redis 어댑터를 생성합니다.
 func NewRedisAdapter(client *redis.Client, keyPrefix string) *RedisAdapter {
+	options := DefaultRedisAdapterOptions()
+	options.KeyPrefix = keyPrefix
+	return NewRedisAdapterWithOptions(client, options)
+}
+
+// NewRedisAdapterWithOptions는 지정된 옵션으로 새 Redis 어댑터를 생성합니다.
+func NewRedisAdapterWithOptions(client *redis.Client, options *RedisAdapterOptions) *RedisAdapter {
+	if options == nil {
+		options = DefaultRedisAdapterOptions()
+	}
+
 	return &RedisAdapter{
 		client:     client,
-		keyPrefix:  keyPrefix,
+		keyPrefix:  options.KeyPrefix,
+		ttl:        options.TTL,
+		maxPatches: options.MaxPatches,
 		serializer: NewDefaultDocumentSerializer(),
+		snapshotOptions: &SnapshotOptions{
+			Enabled:      true,
+			Interval:     time.Hour,
+			MaxSnapshots: 10,
+		},
 	}
 }
 
@@ -37,6 +106,26 @@ func (a *RedisAdapter) getDocumentKey(documentID string) string {
 	return fmt.Sprintf("%s:doc:%s", a.keyPrefix, documentID)
 }
 
+// getPatchLogKey는 문서의 패치 로그에 대한 Redis 키를 반환합니다.
+func (a *RedisAdapter) getPatchLogKey(documentID string) string {
+	return fmt.Sprintf("%s:patches:%s", a.keyPrefix, documentID)
+}
+
+// getSnapshotKey는 문서의 스냅샷 해시에 대한 Redis 키를 반환합니다.
+func (a *RedisAdapter) getSnapshotKey(documentID string) string {
+	return fmt.Sprintf("%s:snapshots:%s", a.keyPrefix, documentID)
+}
+
+// getVersionKey는 문서의 저장된 버전에 대한 Redis 키를 반환합니다.
+func (a *RedisAdapter) getVersionKey(documentID string) string {
+	return fmt.Sprintf("%s:version:%s", a.keyPrefix, documentID)
+}
+
+// getHistoryKey는 버전별로 저장된 문서 패치 기록(정렬된 집합)에 대한 Redis 키를 반환합니다.
+func (a *RedisAdapter) getHistoryKey(documentID string) string {
+	return fmt.Sprintf("%s:history:%s", a.keyPrefix, documentID)
+}
+
 // getDocumentListKey는 문서 목록에 대한 Redis 키를 반환합니다.
 func (a *RedisAdapter) getDocumentListKey() string {
 	return fmt.Sprintf("%s:docs", a.keyPrefix)
@@ -44,31 +133,204 @@ func (a *RedisAdapter) getDocumentListKey() string {
 
 // SaveDocument는 문서를 Redis에 저장합니다.
 func (a *RedisAdapter) SaveDocument(ctx context.Context, doc *Document) error {
+	return a.SaveDocuments(ctx, doc)
+}
+
+// SaveDocuments는 여러 문서를 하나의 파이프라인으로 Redis에 저장합니다.
+// TTL이 설정된 경우 문서의 패치 로그와 스냅샷의 만료 시간도 갱신합니다.
+func (a *RedisAdapter) SaveDocuments(ctx context.Context, docs ...*Document) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
+	// 문서 직렬화
+	data := make([][]byte, len(docs))
+	for i, doc := range docs {
+		serialized, err := a.serializer.Serialize(doc)
+		if err != nil {
+			return fmt.Errorf("failed to serialize document %s: %w", doc.ID, err)
+		}
+		data[i] = serialized
+	}
+
+	// 스냅샷 생성 (필요한 경우)
+	var snapshots []*DocumentSnapshot
+	if a.snapshotOptions.Enabled && a.snapshotOptions.SnapshotOnSave {
+		for _, doc := range docs {
+			snapshot, err := a.CreateSnapshot(ctx, doc)
+			if err != nil {
+				return fmt.Errorf("failed to create snapshot: %w", err)
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	// 문서 저장
+	_, err := a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, doc := range docs {
+			pipe.Set(ctx, a.getDocumentKey(doc.ID), data[i], a.ttl)
+			pipe.Set(ctx, a.getVersionKey(doc.ID), doc.Version, a.ttl)
+			pipe.SAdd(ctx, a.getDocumentListKey(), doc.ID)
+			if a.ttl > 0 {
+				pipe.Expire(ctx, a.getPatchLogKey(doc.ID), a.ttl)
+				pipe.Expire(ctx, a.getSnapshotKey(doc.ID), a.ttl)
+				pipe.Expire(ctx, a.getHistoryKey(doc.ID), a.ttl)
+			}
+		}
+		for _, snapshot := range snapshots {
+			if err := a.pipeSnapshot(ctx, pipe, snapshot); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save documents: %w", err)
+	}
+
+	// 오래된 스냅샷 정리
+	if a.snapshotOptions.MaxSnapshots > 0 {
+		for _, snapshot := range snapshots {
+			if err := a.cleanupOldSnapshots(ctx, snapshot.DocumentID); err != nil {
+				return fmt.Errorf("failed to cleanup old snapshots: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SaveDocumentVersion은 저장된 문서 버전이 baseVersion과 같을 때만 문서를 저장하고 패치 기록에 patches를 추가합니다.
+// 버전 키를 WATCH하는 트랜잭션으로 저장하므로, 저장된 버전이 다르거나 그 사이에 다른 인스턴스가 저장하면
+// ErrVersionConflict를 반환합니다. 최대 패치 수가 설정된 경우 오래된 패치 기록은 정리됩니다.
+func (a *RedisAdapter) SaveDocumentVersion(ctx context.Context, doc *Document, baseVersion int64, patches []*crdtpatch.Patch) error {
 	// 문서 직렬화
 	data, err := a.serializer.Serialize(doc)
 	if err != nil {
-		return fmt.Errorf("failed to serialize document: %w", err)
+		return fmt.Errorf("failed to serialize document %s: %w", doc.ID, err)
 	}
 
-	// 문서 키 가져오기
-	docKey := a.getDocumentKey(doc.ID)
+	// 패치 기록 생성
+	records, err := newPatchRecords(doc.Version, patches)
+	if err != nil {
+		return err
+	}
+	members := make([]*redis.Z, len(records))
+	for i, record := range records {
+		member, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal patch record: %w", err)
+		}
+		members[i] = &redis.Z{Score: float64(record.Version), Member: member}
+	}
 
-	// 문서 저장
-	if err := a.client.Set(ctx, docKey, data, 0).Err(); err != nil {
+	// 스냅샷 생성 (필요한 경우)
+	var snapshot *DocumentSnapshot
+	if a.snapshotOptions.Enabled && a.snapshotOptions.SnapshotOnSave {
+		snapshot, err = a.CreateSnapshot(ctx, doc)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot: %w", err)
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	versionKey := a.getVersionKey(doc.ID)
+	historyKey := a.getHistoryKey(doc.ID)
+	err = a.client.Watch(ctx, func(tx *redis.Tx) error {
+		// 저장된 버전 확인
+		current, err := tx.Get(ctx, versionKey).Int64()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to get document version: %w", err)
+		}
+		if current != baseVersion {
+			return fmt.Errorf("%w: document %s was saved by another instance", ErrVersionConflict, doc.ID)
+		}
+
+		// 문서, 버전, 패치 기록 저장
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, a.getDocumentKey(doc.ID), data, a.ttl)
+			pipe.Set(ctx, versionKey, doc.Version, a.ttl)
+			pipe.SAdd(ctx, a.getDocumentListKey(), doc.ID)
+			if len(members) > 0 {
+				pipe.ZAdd(ctx, historyKey, members...)
+				if a.maxPatches > 0 {
+					pipe.ZRemRangeByRank(ctx, historyKey, 0, -a.maxPatches-1)
+				}
+			}
+			if a.ttl > 0 {
+				pipe.Expire(ctx, historyKey, a.ttl)
+				pipe.Expire(ctx, a.getPatchLogKey(doc.ID), a.ttl)
+				pipe.Expire(ctx, a.getSnapshotKey(doc.ID), a.ttl)
+			}
+			if snapshot != nil {
+				return a.pipeSnapshot(ctx, pipe, snapshot)
+			}
+			return nil
+		})
+		return err
+	}, versionKey)
+	if err == redis.TxFailedErr {
+		return fmt.Errorf("%w: document %s was saved by another instance", ErrVersionConflict, doc.ID)
+	}
+	if err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return err
+		}
 		return fmt.Errorf("failed to save document: %w", err)
 	}
 
-	// 문서 목록에 추가
-	if err := a.client.SAdd(ctx, a.getDocumentListKey(), doc.ID).Err(); err != nil {
-		return fmt.Errorf("failed to add document to list: %w", err)
+	// 오래된 스냅샷 정리
+	if snapshot != nil && a.snapshotOptions.MaxSnapshots > 0 {
+		if err := a.cleanupOldSnapshots(ctx, doc.ID); err != nil {
+			return fmt.Errorf("failed to cleanup old snapshots: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// LoadPatchesSince는 version 이후 저장된 패치와 현재 저장된 문서 버전을 반환합니다.
+func (a *RedisAdapter) LoadPatchesSince(ctx context.Context, documentID string, version int64) ([]*crdtpatch.Patch, int64, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	// 현재 버전과 이후 패치 기록을 함께 조회
+	var current *redis.StringCmd
+	var values *redis.StringSliceCmd
+	_, err := a.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		current = pipe.Get(ctx, a.getVersionKey(documentID))
+		values = pipe.ZRangeByScore(ctx, a.getHistoryKey(documentID), &redis.ZRangeBy{
+			Min: "(" + strconv.FormatInt(version, 10),
+			Max: "+inf",
+		})
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, 0, fmt.Errorf("failed to get patch history: %w", err)
+	}
+	currentVersion, err := current.Int64()
+	if err != nil && err != redis.Nil {
+		return nil, 0, fmt.Errorf("failed to get document version: %w", err)
+	}
+
+	// 패치 기록 역직렬화
+	patches := make([]*crdtpatch.Patch, 0, len(values.Val()))
+	for _, value := range values.Val() {
+		var record patchRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal patch record: %w", err)
+		}
+		patch, err := record.decodePatch()
+		if err != nil {
+			return nil, 0, err
+		}
+		patches = append(patches, patch)
+	}
+
+	return patches, currentVersion, nil
+}
+
 // LoadDocument는 문서를 Redis에서 로드합니다.
 func (a *RedisAdapter) LoadDocument(ctx context.Context, documentID string) ([]byte, error) {
 	a.mutex.RLock()
@@ -90,6 +352,7 @@ func (a *RedisAdapter) LoadDocument(ctx context.Context, documentID string) ([]b
 }
 
 // ListDocuments는 모든 문서 목록을 반환합니다.
+// 만료된 문서는 목록에서 제거됩니다.
 func (a *RedisAdapter) ListDocuments(ctx context.Context) ([]string, error) {
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
@@ -99,26 +362,130 @@ func (a *RedisAdapter) ListDocuments(ctx context.Context) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get document list: %w", err)
 	}
+	if a.ttl <= 0 || len(members) == 0 {
+		return members, nil
+	}
 
-	return members, nil
+	// 문서 존재 여부 확인
+	exists := make([]*redis.IntCmd, len(members))
+	_, err = a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range members {
+			exists[i] = pipe.Exists(ctx, a.getDocumentKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check documents: %w", err)
+	}
+
+	// 만료된 문서 제거
+	ids := make([]string, 0, len(members))
+	var expired []interface{}
+	for i, id := range members {
+		if exists[i].Val() > 0 {
+			ids = append(ids, id)
+		} else {
+			expired = append(expired, id)
+		}
+	}
+	if len(expired) > 0 {
+		if err := a.client.SRem(ctx, a.getDocumentListKey(), expired...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to remove expired documents from list: %w", err)
+		}
+	}
+
+	return ids, nil
 }
 
-// DeleteDocument는 문서를 Redis에서 삭제합니다.
+// DeleteDocument는 문서와 문서의 패치 로그, 스냅샷을 Redis에서 삭제합니다.
 func (a *RedisAdapter) DeleteDocument(ctx context.Context, documentID string) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	// 문서 키 가져오기
-	docKey := a.getDocumentKey(documentID)
-
-	// 문서 삭제
-	if err := a.client.Del(ctx, docKey).Err(); err != nil {
+	// 문서 삭제 및 문서 목록에서 제거
+	_, err := a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, a.getDocumentKey(documentID), a.getPatchLogKey(documentID), a.getSnapshotKey(documentID),
+			a.getVersionKey(documentID), a.getHistoryKey(documentID))
+		pipe.SRem(ctx, a.getDocumentListKey(), documentID)
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
 
-	// 문서 목록에서 제거
-	if err := a.client.SRem(ctx, a.getDocumentListKey(), documentID).Err(); err != nil {
-		return fmt.Errorf("failed to remove document from list: %w", err)
+	return nil
+}
+
+// AppendPatches는 문서의 패치 로그에 패치를 추가합니다.
+// 문서의 OnChange 콜백에서 호출하여 문서 상태 이후의 변경을 기록할 수 있습니다.
+func (a *RedisAdapter) AppendPatches(ctx context.Context, documentID string, patches ...*crdtpatch.Patch) error {
+	if len(patches) == 0 {
+		return nil
+	}
+
+	// 패치 직렬화
+	values := make([]interface{}, len(patches))
+	for i, patch := range patches {
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return fmt.Errorf("failed to marshal patch: %w", err)
+		}
+		values[i] = data
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// 패치 추가
+	patchKey := a.getPatchLogKey(documentID)
+	_, err := a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, patchKey, values...)
+		if a.maxPatches > 0 {
+			pipe.LTrim(ctx, patchKey, -a.maxPatches, -1)
+		}
+		if a.ttl > 0 {
+			pipe.Expire(ctx, patchKey, a.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append patches: %w", err)
+	}
+
+	return nil
+}
+
+// LoadPatches는 문서의 패치 로그를 오래된 순서로 반환합니다.
+func (a *RedisAdapter) LoadPatches(ctx context.Context, documentID string) ([]*crdtpatch.Patch, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	// 패치 가져오기
+	values, err := a.client.LRange(ctx, a.getPatchLogKey(documentID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patches: %w", err)
+	}
+
+	// 패치 역직렬화
+	patches := make([]*crdtpatch.Patch, len(values))
+	for i, value := range values {
+		patch := &crdtpatch.Patch{}
+		if err := json.Unmarshal([]byte(value), patch); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal patch: %w", err)
+		}
+		patches[i] = patch
+	}
+
+	return patches, nil
+}
+
+// ClearPatches는 문서의 패치 로그를 삭제합니다. 예를 들어 패치가 반영된 스냅샷을 저장한 후 호출합니다.
+func (a *RedisAdapter) ClearPatches(ctx context.Context, documentID string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err := a.client.Del(ctx, a.getPatchLogKey(documentID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete patches: %w", err)
 	}
 
 	return nil
@@ -129,3 +496,214 @@ func (a *RedisAdapter) Close() error {
 	// Redis 클라이언트는 외부에서 관리하므로 여기서 닫지 않음
 	return nil
 }
+
+// SaveSnapshot은 문서 스냅샷을 저장합니다.
+func (a *RedisAdapter) SaveSnapshot(ctx context.Context, snapshot *DocumentSnapshot) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// 스냅샷 저장
+	_, err := a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		return a.pipeSnapshot(ctx, pipe, snapshot)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	// 오래된 스냅샷 정리
+	if a.snapshotOptions.MaxSnapshots > 0 {
+		if err := a.cleanupOldSnapshots(ctx, snapshot.DocumentID); err != nil {
+			return fmt.Errorf("failed to cleanup old snapshots: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// pipeSnapshot은 스냅샷 저장 명령을 파이프라인에 추가합니다.
+func (a *RedisAdapter) pipeSnapshot(ctx context.Context, pipe redis.Pipeliner, snapshot *DocumentSnapshot) error {
+	// 스냅샷 직렬화
+	data, err := json.Marshal(redisSnapshot{
+		Timestamp: snapshot.Timestamp,
+		Data:      snapshot.Data,
+		Metadata:  snapshot.Metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	data, err = compressSnapshotData(a.snapshotOptions.Compression, data)
+	if err != nil {
+		return err
+	}
+
+	snapshotKey := a.getSnapshotKey(snapshot.DocumentID)
+	pipe.HSet(ctx, snapshotKey, strconv.FormatInt(snapshot.Version, 10), data)
+	if a.ttl > 0 {
+		pipe.Expire(ctx, snapshotKey, a.ttl)
+	}
+	return nil
+}
+
+// cleanupOldSnapshots은 최대 스냅샷 수를 초과하는 오래된 스냅샷을 삭제합니다.
+func (a *RedisAdapter) cleanupOldSnapshots(ctx context.Context, documentID string) error {
+	versions, err := a.listSnapshots(ctx, documentID)
+	if err != nil {
+		return err
+	}
+	if len(versions) <= a.snapshotOptions.MaxSnapshots {
+		return nil
+	}
+
+	// 최신 버전부터 정렬되어 있으므로 뒤쪽의 스냅샷 삭제
+	fields := make([]string, 0, len(versions)-a.snapshotOptions.MaxSnapshots)
+	for _, version := range versions[a.snapshotOptions.MaxSnapshots:] {
+		fields = append(fields, strconv.FormatInt(version, 10))
+	}
+	if err := a.client.HDel(ctx, a.getSnapshotKey(documentID), fields...).Err(); err != nil {
+		return fmt.Errorf("failed to delete old snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// LoadSnapshot은 문서 스냅샷을 로드합니다.
+func (a *RedisAdapter) LoadSnapshot(ctx context.Context, documentID string, version int64) (*DocumentSnapshot, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	// 최신 버전 요청인 경우
+	if version <= 0 {
+		versions, err := a.listSnapshots(ctx, documentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("snapshot not found for document: %s, version: %d", documentID, version)
+		}
+		version = versions[0]
+	}
+
+	// 스냅샷 가져오기
+	data, err := a.client.HGet(ctx, a.getSnapshotKey(documentID), strconv.FormatInt(version, 10)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("snapshot not found for document: %s, version: %d", documentID, version)
+		}
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	// 스냅샷 역직렬화
+	data, err = decompressSnapshotData(data)
+	if err != nil {
+		return nil, err
+	}
+	var stored redisSnapshot
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	return &DocumentSnapshot{
+		DocumentID: documentID,
+		Version:    version,
+		Timestamp:  stored.Timestamp,
+		Data:       stored.Data,
+		Metadata:   stored.Metadata,
+	}, nil
+}
+
+// ListSnapshots은 문서의 모든 스냅샷 버전 목록을 최신 버전부터 반환합니다.
+func (a *RedisAdapter) ListSnapshots(ctx context.Context, documentID string) ([]int64, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.listSnapshots(ctx, documentID)
+}
+
+// listSnapshots은 문서의 스냅샷 버전 목록을 최신 버전부터 반환합니다.
+func (a *RedisAdapter) listSnapshots(ctx context.Context, documentID string) ([]int64, error) {
+	// 스냅샷 버전 목록 가져오기
+	fields, err := a.client.HKeys(ctx, a.getSnapshotKey(documentID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+
+	// 스냅샷 버전 목록 생성
+	versions := make([]int64, 0, len(fields))
+	for _, field := range fields {
+		version, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot version %q: %w", field, err)
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	return versions, nil
+}
+
+// DeleteSnapshot은 문서 스냅샷을 삭제합니다.
+func (a *RedisAdapter) DeleteSnapshot(ctx context.Context, documentID string, version int64) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// 스냅샷 삭제
+	if err := a.client.HDel(ctx, a.getSnapshotKey(documentID), strconv.FormatInt(version, 10)).Err(); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAllSnapshots은 문서의 모든 스냅샷을 삭제합니다.
+func (a *RedisAdapter) DeleteAllSnapshots(ctx context.Context, documentID string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// 모든 스냅샷 삭제
+	if err := a.client.Del(ctx, a.getSnapshotKey(documentID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete all snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshotOptions는 스냅샷 옵션을 반환합니다.
+func (a *RedisAdapter) GetSnapshotOptions() *SnapshotOptions {
+	return a.snapshotOptions
+}
+
+// SetSnapshotOptions는 스냅샷 옵션을 설정합니다.
+func (a *RedisAdapter) SetSnapshotOptions(options *SnapshotOptions) {
+	a.snapshotOptions = options
+}
+
+// CreateSnapshot은 문서의 스냅샷을 생성합니다.
+func (a *RedisAdapter) CreateSnapshot(ctx context.Context, doc *Document) (*DocumentSnapshot, error) {
+	// 문서 내용 가져오기
+	content, err := doc.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document content: %w", err)
+	}
+
+	// 스냅샷 생성
+	snapshot := &DocumentSnapshot{
+		DocumentID: doc.ID,
+		Version:    doc.Version,
+		Timestamp:  time.Now(),
+		Data:       content,
+		Metadata:   doc.Metadata,
+	}
+
+	return snapshot, nil
+}
+
+// RestoreFromSnapshot은 스냅샷에서 문서를 복원합니다.
+func (a *RedisAdapter) RestoreFromSnapshot(ctx context.Context, documentID string, version int64) (interface{}, error) {
+	// 스냅샷 로드
+	snapshot, err := a.LoadSnapshot(ctx, documentID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	return snapshot.Data, nil
+}
@@ -0,0 +1,94 @@
+package crdtstorage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// TestRedisAdapter는 Redis 어댑터의 일괄 저장, TTL, 패치 로그, 스냅샷을 테스트합니다.
+// Redis 서버에 연결할 수 없으면 건너뜁니다.
+func TestRedisAdapter(t *testing.T) {
+	// 컨텍스트 생성
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Redis 연결
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis is not available: %v", err)
+	}
+
+	// 테스트용 키 접두사로 어댑터 생성
+	options := DefaultRedisAdapterOptions()
+	options.KeyPrefix = fmt.Sprintf("crdtstorage-test-%d", time.Now().UnixNano())
+	options.TTL = time.Minute
+	options.MaxPatches = 2
+	adapter := NewRedisAdapterWithOptions(client, options)
+	adapter.SetSnapshotOptions(&SnapshotOptions{Enabled: true, SnapshotOnSave: true, MaxSnapshots: 2})
+
+	// 여러 문서 일괄 저장
+	docs := make([]*Document, 2)
+	for i := range docs {
+		docs[i] = &Document{
+			ID:           fmt.Sprintf("match-%d", i),
+			CRDTDoc:      crdt.NewDocument(common.NewSessionID()),
+			LastModified: time.Now(),
+			Metadata:     map[string]interface{}{},
+			Version:      1,
+		}
+	}
+	assert.NoError(t, adapter.SaveDocuments(ctx, docs...))
+	defer adapter.DeleteDocument(context.Background(), "match-0")
+
+	// 저장된 문서에 TTL 설정 확인
+	ttl, err := client.TTL(ctx, adapter.getDocumentKey("match-0")).Result()
+	assert.NoError(t, err)
+	assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+	// 만료된 문서는 목록에서 제거
+	assert.NoError(t, client.Del(ctx, adapter.getDocumentKey("match-1")).Err())
+	ids, err := adapter.ListDocuments(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"match-0"}, ids)
+
+	// 패치 로그는 최대 패치 수만큼만 유지
+	sid := common.NewSessionID()
+	for i := 1; i <= 3; i++ {
+		patch := crdtpatch.NewPatch(common.LogicalTimestamp{SID: sid, Counter: uint64(i)})
+		assert.NoError(t, adapter.AppendPatches(ctx, "match-0", patch))
+	}
+	patches, err := adapter.LoadPatches(ctx, "match-0")
+	assert.NoError(t, err)
+	if assert.Len(t, patches, 2) {
+		assert.Equal(t, uint64(2), patches[0].ID().Counter)
+		assert.Equal(t, uint64(3), patches[1].ID().Counter)
+	}
+
+	// 최대 스냅샷 수만큼만 유지
+	for version := int64(2); version <= 3; version++ {
+		docs[0].Version = version
+		assert.NoError(t, adapter.SaveDocument(ctx, docs[0]))
+	}
+	versions, err := adapter.ListSnapshots(ctx, "match-0")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, versions)
+	snapshot, err := adapter.LoadSnapshot(ctx, "match-0", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), snapshot.Version)
+
+	// 문서 삭제 시 패치 로그와 스냅샷도 삭제
+	assert.NoError(t, adapter.DeleteDocument(ctx, "match-0"))
+	n, err := client.Exists(ctx, adapter.getPatchLogKey("match-0"), adapter.getSnapshotKey("match-0")).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+}
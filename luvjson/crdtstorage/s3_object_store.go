@@ -0,0 +1,372 @@
+package crdtstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Options는 S3 호환 객체 저장소 옵션을 나타냅니다.
+type S3Options struct {
+	// Endpoint는 객체 저장소 주소입니다. 예: "https://s3.ap-northeast-2.amazonaws.com",
+	// "http://localhost:9000" (MinIO), "https://storage.googleapis.com" (GCS HMAC 키 사용)
+	Endpoint string
+
+	// Region은 서명에 사용할 리전입니다. GCS와 MinIO는 보통 "us-east-1"이나 "auto"를 사용합니다.
+	Region string
+
+	// Bucket은 버킷 이름입니다.
+	Bucket string
+
+	// AccessKeyID는 액세스 키 ID입니다.
+	AccessKeyID string
+
+	// SecretAccessKey는 비밀 액세스 키입니다.
+	SecretAccessKey string
+
+	// VirtualHostedStyle은 버킷을 경로 대신 호스트 이름에 넣을지 여부입니다.
+	// MinIO는 기본적으로 경로 방식을 사용합니다.
+	VirtualHostedStyle bool
+
+	// HTTPClient는 요청에 사용할 HTTP 클라이언트입니다. nil이면 http.DefaultClient를 사용합니다.
+	HTTPClient *http.Client
+}
+
+// S3ObjectStore는 AWS Signature Version 4로 서명하는 S3 호환 객체 저장소 클라이언트입니다.
+// AWS S3, MinIO, GCS의 XML API에서 사용할 수 있습니다.
+type S3ObjectStore struct {
+	// options는 객체 저장소 옵션입니다.
+	options S3Options
+
+	// endpoint는 파싱된 객체 저장소 주소입니다.
+	endpoint *url.URL
+
+	// client는 HTTP 클라이언트입니다.
+	client *http.Client
+
+	// now는 서명 시간을 반환합니다.
+	now func() time.Time
+}
+
+// NewS3ObjectStore는 새 S3 호환 객체 저장소 클라이언트를 생성합니다.
+func NewS3ObjectStore(options S3Options) (*S3ObjectStore, error) {
+	if options.Bucket == "" {
+		return nil, fmt.Errorf("bucket cannot be empty")
+	}
+	if options.Region == "" {
+		options.Region = "us-east-1"
+	}
+
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint: %q", options.Endpoint)
+	}
+
+	client := options.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &S3ObjectStore{
+		options:  options,
+		endpoint: endpoint,
+		client:   client,
+		now:      time.Now,
+	}, nil
+}
+
+// objectURL은 객체 키에 대한 URL을 반환합니다. 키가 비어 있으면 버킷 URL을 반환합니다.
+func (s *S3ObjectStore) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.options.VirtualHostedStyle {
+		u.Host = s.options.Bucket + "." + u.Host
+		path += "/" + key
+	} else {
+		path += "/" + s.options.Bucket
+		if key != "" {
+			path += "/" + key
+		}
+	}
+	u.Path = path
+	u.RawPath = s3EscapePath(path)
+	u.RawQuery = s3CanonicalQuery(query)
+	return &u
+}
+
+// PutObject는 객체를 저장합니다.
+func (s *S3ObjectStore) PutObject(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, nil, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetObject는 객체를 가져옵니다. 객체가 없으면 ErrObjectNotFound를 반환합니다.
+func (s *S3ObjectStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// DeleteObject는 객체를 삭제합니다.
+func (s *S3ObjectStore) DeleteObject(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil && err != ErrObjectNotFound {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// s3ListResult는 ListObjectsV2 응답입니다.
+type s3ListResult struct {
+	// Contents는 객체 목록입니다.
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+
+	// IsTruncated는 다음 페이지가 있는지 여부입니다.
+	IsTruncated bool `xml:"IsTruncated"`
+
+	// NextContinuationToken은 다음 페이지를 요청할 토큰입니다.
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// ListObjects는 접두사로 시작하는 객체 목록을 키 순서로 반환합니다.
+func (s *S3ObjectStore) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var infos []ObjectInfo
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			infos = append(infos, ObjectInfo{
+				Key:          content.Key,
+				Size:         content.Size,
+				LastModified: content.LastModified,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return infos, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// SetExpiration은 접두사로 시작하는 객체를 지정된 일수 후 삭제하는 버킷 수명 주기 규칙을 설정합니다.
+// 버킷의 기존 수명 주기 규칙은 대체됩니다.
+func (s *S3ObjectStore) SetExpiration(ctx context.Context, prefix string, days int) error {
+	if days <= 0 {
+		return fmt.Errorf("invalid expiration days: %d", days)
+	}
+
+	type expiration struct {
+		Days int `xml:"Days"`
+	}
+	type filter struct {
+		Prefix string `xml:"Prefix"`
+	}
+	type rule struct {
+		ID         string     `xml:"ID"`
+		Filter     filter     `xml:"Filter"`
+		Status     string     `xml:"Status"`
+		Expiration expiration `xml:"Expiration"`
+	}
+	type lifecycleConfiguration struct {
+		XMLName xml.Name `xml:"LifecycleConfiguration"`
+		Rules   []rule   `xml:"Rule"`
+	}
+
+	body, err := xml.Marshal(lifecycleConfiguration{
+		Rules: []rule{{
+			ID:         "crdtstorage-expiration",
+			Filter:     filter{Prefix: prefix},
+			Status:     "Enabled",
+			Expiration: expiration{Days: days},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal lifecycle configuration: %w", err)
+	}
+
+	// 수명 주기 요청에는 Content-MD5가 필요함
+	sum := md5.Sum(body)
+	header := http.Header{"Content-Md5": {base64.StdEncoding.EncodeToString(sum[:])}}
+	resp, err := s.do(ctx, http.MethodPut, "", url.Values{"lifecycle": {""}}, body, header)
+	if err != nil {
+		return fmt.Errorf("failed to set lifecycle configuration: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do는 서명된 요청을 보내고 응답을 반환합니다. 응답 상태가 2xx가 아니면 오류를 반환합니다.
+func (s *S3ObjectStore) do(ctx context.Context, method string, key string, query url.Values, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key, query).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound && key != "" {
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// sign은 요청에 AWS Signature Version 4 Authorization 헤더를 추가합니다.
+// host, content-md5, content-type, range, x-amz-* 헤더에 서명합니다.
+func (s *S3ObjectStore) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	// 서명할 헤더 수집
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-md5" || lower == "content-type" || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// 정규 요청 생성
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+
+	// 서명 생성
+	scope := date + "/" + s.options.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+	key := hmacSHA256([]byte("AWS4"+s.options.SecretAccessKey), date)
+	key = hmacSHA256(key, s.options.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.options.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// hmacSHA256은 HMAC-SHA256 값을 반환합니다.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape는 S3 서명 규칙에 따라 문자열을 인코딩합니다.
+// 예약되지 않은 문자(A-Z, a-z, 0-9, '-', '.', '_', '~')를 제외한 모든 바이트를 인코딩합니다.
+func s3Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3EscapePath는 경로를 인코딩합니다.
+func s3EscapePath(path string) string {
+	return s3Escape(path, true)
+}
+
+// s3CanonicalQuery는 키 순서로 정렬하고 인코딩한 쿼리 문자열을 반환합니다.
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key, false)+"="+s3Escape(value, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,162 @@
+package crdtstorage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// SnapshotCompression은 저장되는 스냅샷 데이터의 압축 방식입니다.
+type SnapshotCompression string
+
+const (
+	// SnapshotCompressionNone은 스냅샷 데이터를 압축하지 않습니다.
+	SnapshotCompressionNone SnapshotCompression = ""
+
+	// SnapshotCompressionGzip은 스냅샷 데이터를 gzip으로 압축합니다.
+	SnapshotCompressionGzip SnapshotCompression = "gzip"
+
+	// SnapshotCompressionZstd는 스냅샷 데이터를 zstd로 압축합니다.
+	SnapshotCompressionZstd SnapshotCompression = "zstd"
+)
+
+// snapshotOptionsAccessor는 스냅샷 옵션을 조회하고 변경할 수 있는 영구 저장소 어댑터입니다.
+type snapshotOptionsAccessor interface {
+	GetSnapshotOptions() *SnapshotOptions
+	SetSnapshotOptions(options *SnapshotOptions)
+}
+
+var (
+	// zstdEncoder와 zstdDecoder는 스냅샷 압축에 공유되는 zstd 인코더와 디코더입니다.
+	// EncodeAll과 DecodeAll은 동시에 호출해도 안전합니다.
+	zstdEncoder     *zstd.Encoder
+	zstdDecoder     *zstd.Decoder
+	zstdInitOnce    sync.Once
+	zstdInitFailure error
+)
+
+// initZstd는 공유 zstd 인코더와 디코더를 생성합니다.
+func initZstd() error {
+	zstdInitOnce.Do(func() {
+		zstdEncoder, zstdInitFailure = zstd.NewWriter(nil)
+		if zstdInitFailure != nil {
+			return
+		}
+		zstdDecoder, zstdInitFailure = zstd.NewReader(nil)
+	})
+	return zstdInitFailure
+}
+
+// validateSnapshotCompression은 지원되는 압축 방식인지 확인합니다.
+func validateSnapshotCompression(compression SnapshotCompression) error {
+	switch compression {
+	case SnapshotCompressionNone, SnapshotCompressionGzip, SnapshotCompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("unsupported snapshot compression: %s", compression)
+	}
+}
+
+// configureSnapshotCompression은 영구 저장소 어댑터가 스냅샷 옵션을 지원하면 스냅샷 압축 방식을 설정합니다.
+func configureSnapshotCompression(persistence PersistenceAdapter, compression SnapshotCompression) error {
+	if err := validateSnapshotCompression(compression); err != nil {
+		return err
+	}
+	if compression == SnapshotCompressionNone {
+		return nil
+	}
+
+	accessor, ok := persistence.(snapshotOptionsAccessor)
+	if !ok {
+		return nil
+	}
+	adapterOptions := *accessor.GetSnapshotOptions()
+	adapterOptions.Compression = compression
+	accessor.SetSnapshotOptions(&adapterOptions)
+	return nil
+}
+
+// compressSnapshotData는 JSON으로 직렬화된 스냅샷 데이터를 압축합니다.
+// 압축된 데이터는 "<코덱>:" 태그 뒤에 base64로 인코딩되므로 텍스트 컬럼에도 저장할 수 있습니다.
+// 압축하지 않으면 데이터를 그대로 반환합니다.
+func compressSnapshotData(compression SnapshotCompression, data []byte) ([]byte, error) {
+	var compressed []byte
+	switch compression {
+	case SnapshotCompressionNone:
+		return data, nil
+	case SnapshotCompressionGzip:
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to compress snapshot data: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress snapshot data: %w", err)
+		}
+		compressed = buf.Bytes()
+	case SnapshotCompressionZstd:
+		if err := initZstd(); err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		compressed = zstdEncoder.EncodeAll(data, nil)
+	default:
+		return nil, fmt.Errorf("unsupported snapshot compression: %s", compression)
+	}
+
+	// 코덱 태그와 base64 인코딩
+	tag := string(compression) + ":"
+	encoded := make([]byte, len(tag)+base64.StdEncoding.EncodedLen(len(compressed)))
+	copy(encoded, tag)
+	base64.StdEncoding.Encode(encoded[len(tag):], compressed)
+	return encoded, nil
+}
+
+// decompressSnapshotData는 코덱 태그에 따라 스냅샷 데이터의 압축을 해제합니다.
+// JSON 값은 코덱 태그로 시작할 수 없으므로, 태그가 없는 데이터는 압축되지 않은 기존 스냅샷으로 보고 그대로 반환합니다.
+func decompressSnapshotData(data []byte) ([]byte, error) {
+	codec, encoded, ok := bytes.Cut(data, []byte(":"))
+	if !ok {
+		return data, nil
+	}
+
+	compression := SnapshotCompression(codec)
+	if compression != SnapshotCompressionGzip && compression != SnapshotCompressionZstd {
+		return data, nil
+	}
+
+	// base64 디코딩
+	compressed := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(compressed, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s snapshot data: %w", compression, err)
+	}
+	compressed = compressed[:n]
+
+	switch compression {
+	case SnapshotCompressionGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snapshot data: %w", err)
+		}
+		defer reader.Close()
+		decompressed, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snapshot data: %w", err)
+		}
+		return decompressed, nil
+	default:
+		if err := initZstd(); err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		decompressed, err := zstdDecoder.DecodeAll(compressed, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snapshot data: %w", err)
+		}
+		return decompressed, nil
+	}
+}
@@ -0,0 +1,91 @@
+package crdtstorage
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSnapshotCompression은 코덱별 스냅샷 데이터 압축과 해제를 테스트합니다.
+func TestSnapshotCompression(t *testing.T) {
+	data := []byte(`{"players":[` + strings.Repeat(`{"hp":100,"mp":50,"status":"alive"},`, 100) + `{}]}`)
+
+	for _, compression := range []SnapshotCompression{SnapshotCompressionGzip, SnapshotCompressionZstd} {
+		// 코덱 태그가 붙고 반복되는 데이터는 작아짐
+		compressed, err := compressSnapshotData(compression, data)
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(compressed, []byte(string(compression)+":")))
+		assert.Less(t, len(compressed), len(data)/4)
+
+		decompressed, err := decompressSnapshotData(compressed)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decompressed)
+	}
+
+	// 압축하지 않은 기존 데이터는 그대로 반환
+	uncompressed, err := compressSnapshotData(SnapshotCompressionNone, data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, uncompressed)
+	decompressed, err := decompressSnapshotData(data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+
+	// 지원하지 않는 압축 방식
+	_, err = compressSnapshotData("lz4", data)
+	assert.Error(t, err)
+	assert.Error(t, validateSnapshotCompression("lz4"))
+}
+
+// TestStorage_SnapshotCompression은 저장소 옵션의 압축 방식으로 스냅샷을 저장하고 기존 스냅샷도 로드하는지 테스트합니다.
+func TestStorage_SnapshotCompression(t *testing.T) {
+	ctx := context.Background()
+
+	// zstd 압축을 사용하는 저장소
+	store := NewMemoryObjectStore()
+	adapter, err := NewObjectSnapshotAdapter(NewMemoryAdapter(), store, nil)
+	assert.NoError(t, err)
+	options := DefaultStorageOptions()
+	options.AutoSave = false
+	options.EnableSnapshots = false
+	options.SnapshotCompression = SnapshotCompressionZstd
+	storage, err := NewStorageWithCustomPersistence(ctx, options, adapter)
+	assert.NoError(t, err)
+	defer storage.Close()
+	assert.Equal(t, SnapshotCompressionZstd, adapter.GetSnapshotOptions().Compression)
+
+	// 압축하지 않고 저장된 기존 스냅샷
+	assert.NoError(t, adapter.SaveSnapshot(ctx, &DocumentSnapshot{
+		DocumentID: "raid-1",
+		Version:    1,
+		Timestamp:  time.Now(),
+		Data:       map[string]interface{}{"hp": float64(100)},
+	}))
+
+	// 새 스냅샷은 압축되어 저장
+	doc, err := storage.CreateDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	assert.True(t, doc.Edit(ctx, setRootObject()).Success)
+	assert.True(t, doc.Edit(ctx, setRootField("hp", 40)).Success)
+	doc.Version = 2
+	assert.NoError(t, storage.SaveSnapshot(ctx, doc))
+	object, err := store.GetObject(ctx, adapter.getSnapshotKey("raid-1", 2))
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(object, []byte("zstd:")))
+
+	// 압축 여부와 관계없이 로드
+	snapshot, err := storage.LoadSnapshot(ctx, "raid-1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hp": float64(40)}, snapshot.Data)
+	snapshot, err = storage.LoadSnapshot(ctx, "raid-1", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hp": float64(100)}, snapshot.Data)
+
+	// 지원하지 않는 압축 방식은 저장소 생성 실패
+	options.SnapshotCompression = "lz4"
+	_, err = NewStorageWithCustomPersistence(ctx, options, NewMemoryAdapter())
+	assert.Error(t, err)
+}
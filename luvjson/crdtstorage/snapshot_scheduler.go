@@ -0,0 +1,245 @@
+package crdtstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// snapshotPersistence는 자동 스냅샷에 필요한 영구 저장소 기능입니다.
+type snapshotPersistence interface {
+	CreateSnapshot(ctx context.Context, doc *Document) (*DocumentSnapshot, error)
+	SaveSnapshot(ctx context.Context, snapshot *DocumentSnapshot) error
+	LoadSnapshot(ctx context.Context, documentID string, version int64) (*DocumentSnapshot, error)
+	ListSnapshots(ctx context.Context, documentID string) ([]int64, error)
+	DeleteSnapshot(ctx context.Context, documentID string, version int64) error
+}
+
+// snapshotState는 문서별 자동 스냅샷 상태입니다.
+type snapshotState struct {
+	// doc은 추적 중인 문서입니다.
+	doc *Document
+
+	// pending은 마지막 스냅샷 이후 적용된 패치 수입니다.
+	pending int
+}
+
+// snapshotScheduler는 저장소 수준에서 문서 스냅샷을 주기적으로 생성하고 보존 정책에 따라 정리합니다.
+// N개 패치마다 또는 SnapshotInterval마다 변경된 문서의 스냅샷을 만들고,
+// 최근 MaxSnapshots개와 최근 SnapshotKeepDaily일 동안 하루에 하나씩의 스냅샷만 유지합니다.
+type snapshotScheduler struct {
+	// persistence는 스냅샷을 저장하는 영구 저장소입니다.
+	persistence snapshotPersistence
+
+	// options는 저장소 옵션입니다.
+	options *StorageOptions
+
+	// documents는 문서 ID에서 스냅샷 상태로의 맵입니다.
+	documents map[string]*snapshotState
+
+	// mutex는 문서 상태 맵에 대한 동시 접근을 보호합니다.
+	mutex sync.Mutex
+
+	// trigger는 패치 수 기준을 넘은 문서를 전달합니다.
+	trigger chan string
+
+	// done은 스케줄러 고루틴이 종료되면 닫힙니다.
+	done chan struct{}
+
+	// now는 현재 시간을 반환합니다. 테스트에서 교체할 수 있습니다.
+	now func() time.Time
+
+	// onSnapshot은 스냅샷이 저장되면 호출됩니다. nil이면 호출하지 않습니다.
+	onSnapshot func(snapshot *DocumentSnapshot)
+}
+
+// newSnapshotScheduler는 옵션과 영구 저장소가 자동 스냅샷을 지원하면 스케줄러를 생성합니다.
+// 그렇지 않으면 nil을 반환합니다.
+func newSnapshotScheduler(persistence PersistenceAdapter, options *StorageOptions) *snapshotScheduler {
+	if !options.EnableSnapshots || (options.SnapshotInterval <= 0 && options.SnapshotEveryPatches <= 0) {
+		return nil
+	}
+	snapshots, ok := persistence.(snapshotPersistence)
+	if !ok {
+		return nil
+	}
+
+	// 보존 정책은 저장소가 관리하므로 어댑터의 개수 기반 정리는 끔
+	if configurable, ok := persistence.(snapshotOptionsAccessor); ok {
+		adapterOptions := *configurable.GetSnapshotOptions()
+		adapterOptions.MaxSnapshots = 0
+		configurable.SetSnapshotOptions(&adapterOptions)
+	}
+
+	return &snapshotScheduler{
+		persistence: snapshots,
+		options:     options,
+		documents:   make(map[string]*snapshotState),
+		trigger:     make(chan string, 64),
+		done:        make(chan struct{}),
+		now:         time.Now,
+	}
+}
+
+// track은 문서를 자동 스냅샷 대상으로 등록합니다.
+func (s *snapshotScheduler) track(doc *Document) {
+	s.mutex.Lock()
+	s.documents[doc.ID] = &snapshotState{doc: doc}
+	s.mutex.Unlock()
+
+	doc.OnChange(func(d *Document, patch *crdtpatch.Patch) {
+		s.recordPatch(d.ID)
+	})
+}
+
+// untrack은 문서를 자동 스냅샷 대상에서 제거합니다.
+func (s *snapshotScheduler) untrack(documentID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.documents, documentID)
+}
+
+// recordPatch는 문서에 패치가 적용되었음을 기록합니다.
+// 편집 중 호출되므로 스냅샷은 스케줄러 고루틴에서 생성합니다.
+func (s *snapshotScheduler) recordPatch(documentID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.documents[documentID]
+	if !ok {
+		return
+	}
+	state.pending++
+
+	if s.options.SnapshotEveryPatches > 0 && state.pending == s.options.SnapshotEveryPatches {
+		select {
+		case s.trigger <- documentID:
+		default:
+			// 대기열이 가득 차면 다음 주기에 처리
+		}
+	}
+}
+
+// run은 저장소 컨텍스트가 취소될 때까지 스냅샷을 생성합니다.
+func (s *snapshotScheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	var tick <-chan time.Time
+	if s.options.SnapshotInterval > 0 {
+		ticker := time.NewTicker(s.options.SnapshotInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case documentID := <-s.trigger:
+			s.snapshotDocument(ctx, documentID, s.options.SnapshotEveryPatches)
+		case <-tick:
+			// 주기마다 변경된 모든 문서의 스냅샷 생성
+			s.mutex.Lock()
+			documentIDs := make([]string, 0, len(s.documents))
+			for documentID := range s.documents {
+				documentIDs = append(documentIDs, documentID)
+			}
+			s.mutex.Unlock()
+
+			for _, documentID := range documentIDs {
+				s.snapshotDocument(ctx, documentID, 1)
+			}
+		}
+	}
+}
+
+// snapshotDocument는 마지막 스냅샷 이후 minPending개 이상의 패치가 적용된 문서의 스냅샷을 생성하고 정리합니다.
+func (s *snapshotScheduler) snapshotDocument(ctx context.Context, documentID string, minPending int) {
+	s.mutex.Lock()
+	state, ok := s.documents[documentID]
+	if !ok || state.pending < minPending {
+		s.mutex.Unlock()
+		return
+	}
+	doc := state.doc
+	state.pending = 0
+	s.mutex.Unlock()
+
+	if err := s.saveSnapshot(ctx, doc); err != nil {
+		fmt.Printf("Warning: Failed to create snapshot for document %s: %v\n", documentID, err)
+		return
+	}
+	if err := s.prune(ctx, documentID); err != nil {
+		fmt.Printf("Warning: Failed to prune snapshots for document %s: %v\n", documentID, err)
+	}
+}
+
+// saveSnapshot은 패치 적용과 겹치지 않도록 문서 스냅샷을 생성하여 저장합니다.
+func (s *snapshotScheduler) saveSnapshot(ctx context.Context, doc *Document) error {
+	doc.logMutex.Lock()
+	defer doc.logMutex.Unlock()
+
+	snapshot, err := s.persistence.CreateSnapshot(ctx, doc)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	snapshot.Timestamp = s.now()
+	if err := s.persistence.SaveSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+
+	if s.onSnapshot != nil {
+		s.onSnapshot(snapshot)
+	}
+	return nil
+}
+
+// prune은 보존 정책에 해당하지 않는 스냅샷을 삭제합니다.
+func (s *snapshotScheduler) prune(ctx context.Context, documentID string) error {
+	keepLast := s.options.MaxSnapshots
+	keepDaily := s.options.SnapshotKeepDaily
+	if keepLast <= 0 && keepDaily <= 0 {
+		return nil
+	}
+
+	// 최신 버전 순으로 정렬된 스냅샷 목록
+	versions, err := s.persistence.ListSnapshots(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	if keepLast < 0 {
+		keepLast = 0
+	}
+	if len(versions) <= keepLast {
+		return nil
+	}
+
+	// 최근 keepDaily일 동안 하루에 가장 최신 스냅샷 하나씩 유지
+	cutoff := s.now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(keepDaily - 1))
+	days := make(map[time.Time]bool)
+	for _, version := range versions[keepLast:] {
+		if keepDaily > 0 {
+			snapshot, err := s.persistence.LoadSnapshot(ctx, documentID, version)
+			if err != nil {
+				return fmt.Errorf("failed to load snapshot %d: %w", version, err)
+			}
+			day := snapshot.Timestamp.UTC().Truncate(24 * time.Hour)
+			if !day.Before(cutoff) && !days[day] {
+				days[day] = true
+				continue
+			}
+		}
+
+		if err := s.persistence.DeleteSnapshot(ctx, documentID, version); err != nil {
+			return fmt.Errorf("failed to delete snapshot %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
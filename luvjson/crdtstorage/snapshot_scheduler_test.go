@@ -0,0 +1,85 @@
+package crdtstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSnapshotScheduler는 패치 수 기준 자동 스냅샷과 저장소 수준 보존 정책을 테스트합니다.
+func TestSnapshotScheduler(t *testing.T) {
+	ctx := context.Background()
+
+	// 객체 저장소 스냅샷 어댑터를 사용하는 저장소
+	adapter, err := NewObjectSnapshotAdapter(NewMemoryAdapter(), NewMemoryObjectStore(), nil)
+	assert.NoError(t, err)
+	options := DefaultStorageOptions()
+	options.AutoSave = false
+	options.SnapshotInterval = 0
+	options.SnapshotEveryPatches = 2
+	options.MaxSnapshots = 2
+	storage, err := NewStorageWithCustomPersistence(ctx, options, adapter)
+	assert.NoError(t, err)
+	defer storage.Close()
+
+	// 저장소가 보존 정책을 관리하므로 어댑터의 개수 기반 정리는 꺼짐
+	assert.Equal(t, 0, adapter.GetSnapshotOptions().MaxSnapshots)
+
+	// 2개 패치마다 스냅샷 생성, 최근 2개만 유지
+	doc, err := storage.CreateDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	var created []int64
+	for hp := 60; hp > 0; hp -= 20 {
+		assert.True(t, doc.Edit(ctx, setRootContent(map[string]interface{}{"hp": hp})).Success)
+		assert.True(t, doc.Edit(ctx, setRootContent(map[string]interface{}{"hp": hp - 10})).Success)
+		created = append([]int64{doc.Version}, created...)
+		assert.Eventually(t, func() bool {
+			versions, err := storage.ListSnapshots(ctx, "raid-1")
+			return err == nil && len(versions) > 0 && versions[0] == doc.Version
+		}, time.Second, 10*time.Millisecond)
+	}
+	assert.Eventually(t, func() bool {
+		versions, err := storage.ListSnapshots(ctx, "raid-1")
+		return err == nil && assert.ObjectsAreEqual(created[:2], versions)
+	}, time.Second, 10*time.Millisecond)
+
+	snapshot, err := storage.LoadSnapshot(ctx, "raid-1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hp": float64(10)}, snapshot.Data)
+}
+
+// TestSnapshotScheduler_KeepDaily는 최근 스냅샷 외에 하루에 하나씩 보존하는지 테스트합니다.
+func TestSnapshotScheduler_KeepDaily(t *testing.T) {
+	ctx := context.Background()
+
+	adapter, err := NewObjectSnapshotAdapter(NewMemoryAdapter(), NewMemoryObjectStore(), nil)
+	assert.NoError(t, err)
+	options := DefaultStorageOptions()
+	options.MaxSnapshots = 1
+	options.SnapshotKeepDaily = 2
+	scheduler := newSnapshotScheduler(adapter, options)
+	now := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return now }
+
+	// 사흘에 걸쳐 하루 두 번씩 생성된 스냅샷
+	version := int64(0)
+	for _, day := range []int{1, 2, 3} {
+		for _, hour := range []int{9, 18} {
+			version++
+			assert.NoError(t, adapter.SaveSnapshot(ctx, &DocumentSnapshot{
+				DocumentID: "raid-1",
+				Version:    version,
+				Timestamp:  time.Date(2024, 5, day, hour, 0, 0, 0, time.UTC),
+				Data:       map[string]interface{}{"version": version},
+			}))
+		}
+	}
+
+	// 최신 1개, 나머지 중 5월 3일과 2일의 가장 최신 스냅샷 유지
+	assert.NoError(t, scheduler.prune(ctx, "raid-1"))
+	versions, err := adapter.ListSnapshots(ctx, "raid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{6, 5, 4}, versions)
+}
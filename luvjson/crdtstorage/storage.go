@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 
 	"tictactoe/luvjson/common"
 	"tictactoe/luvjson/crdt"
@@ -25,6 +26,7 @@ func DefaultStorageOptions() *StorageOptions {
 		RedisPassword:             "",
 		RedisDB:                   0,
 		KeyPrefix:                 "luvjson",
+		PersistenceTTL:            0,
 		SyncInterval:              time.Minute,
 		AutoSave:                  true,
 		AutoSaveInterval:          time.Minute * 5,
@@ -38,8 +40,11 @@ func DefaultStorageOptions() *StorageOptions {
 		EnableSnapshots:           true,
 		SnapshotInterval:          time.Hour,
 		MaxSnapshots:              10,
+		SnapshotEveryPatches:      0,
+		SnapshotKeepDaily:         0,
 		SnapshotOnSave:            false,
 		SnapshotTableName:         "document_snapshots",
+		MaxSaveRetries:            3,
 	}
 }
 
@@ -95,6 +100,32 @@ type storageImpl struct {
 	// syncManagerRegistry는 동기화 매니저 레지스트리입니다.
 	// 여러 문서의 동기화를 관리합니다.
 	syncManagerRegistry *SyncManagerRegistry
+
+	// patchLog는 편집 패치를 적용 전에 기록하는 패치 로그입니다.
+	// nil이면 패치 로그를 사용하지 않습니다.
+	patchLog PatchLog
+
+	// snapshotScheduler는 자동 스냅샷 스케줄러입니다.
+	// 스냅샷이 비활성화되었거나 영구 저장소가 스냅샷을 지원하지 않으면 nil입니다.
+	snapshotScheduler *snapshotScheduler
+
+	// instanceID는 PubSub으로 보낸 문서 메시지의 출처를 구분하는 저장소 인스턴스 ID입니다.
+	instanceID string
+
+	// bridged는 PubSub 토픽에 연결된 문서 맵입니다.
+	bridged map[string]*Document
+
+	// watchers는 문서 ID에서 WatchDocument 구독자 목록으로의 맵입니다.
+	watchers map[string][]*documentWatcher
+
+	// watchMutex는 bridged와 watchers에 대한 동시 접근을 보호합니다.
+	watchMutex sync.RWMutex
+
+	// outbound는 PubSub으로 전송할 문서 메시지 대기열입니다.
+	outbound chan documentMessage
+
+	// publishDone은 전송 고루틴이 종료되면 닫힙니다.
+	publishDone chan struct{}
 }
 
 // NewStorage는 새 저장소를 생성합니다.
@@ -113,11 +144,16 @@ func NewStorageWithCustomPersistence(ctx context.Context, options *StorageOption
 
 	// 저장소 인스턴스 생성
 	storage := &storageImpl{
-		options:    options,
-		documents:  make(map[string]*Document),
-		ctx:        storageCtx,
-		cancel:     cancel,
-		serializer: NewDefaultDocumentSerializer(),
+		options:     options,
+		documents:   make(map[string]*Document),
+		ctx:         storageCtx,
+		cancel:      cancel,
+		serializer:  NewDefaultDocumentSerializer(),
+		instanceID:  uuid.New().String(),
+		bridged:     make(map[string]*Document),
+		watchers:    make(map[string][]*documentWatcher),
+		outbound:    make(chan documentMessage, 1024),
+		publishDone: make(chan struct{}),
 	}
 
 	// PubSub 생성
@@ -137,6 +173,14 @@ func NewStorageWithCustomPersistence(ctx context.Context, options *StorageOption
 	}
 	storage.persistence = persistence
 
+	// 스냅샷 압축 설정
+	if err := configureSnapshotCompression(persistence, options.SnapshotCompression); err != nil {
+		cancel()
+		pubsub.Close()
+		persistence.Close()
+		return nil, fmt.Errorf("failed to configure snapshot compression: %w", err)
+	}
+
 	// Redis 클라이언트 저장 (락 관리자와 트랜잭션 관리자에서 사용)
 	if options.PubSubType == "redis" || options.PersistenceType == "redis" {
 		// Redis 클라이언트 생성
@@ -219,11 +263,57 @@ func NewStorageWithCustomPersistence(ctx context.Context, options *StorageOption
 	}
 	storage.syncManagerRegistry = syncManagerRegistry
 
+	// 문서 메시지 전송 시작
+	go storage.publishLoop()
+
+	// 패치 로그 생성 (필요한 경우)
+	patchLog, err := createPatchLog(options)
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to create patch log: %w", err)
+	}
+	storage.patchLog = patchLog
+
+	// 비정상 종료로 저장되지 않은 변경사항 복구
+	if _, err := storage.RecoverDocuments(storageCtx); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("failed to recover documents: %w", err)
+	}
+
+	// 자동 스냅샷 스케줄러 시작 (필요한 경우)
+	if scheduler := newSnapshotScheduler(persistence, options); scheduler != nil {
+		scheduler.onSnapshot = storage.publishSnapshot
+		storage.snapshotScheduler = scheduler
+		go scheduler.run(storageCtx)
+	}
+
+	// 유휴 문서 보관 시작 (필요한 경우)
+	if archiver, ok := persistence.(documentArchiver); ok && options.ArchiveInterval > 0 {
+		go storage.archiveLoop(archiver, options.ArchiveInterval)
+	}
+
 	return storage, nil
 }
 
+// createPatchLog는 옵션에 따라 패치 로그를 생성합니다.
+// 패치 로그가 설정되지 않았으면 nil을 반환합니다.
+func createPatchLog(options *StorageOptions) (PatchLog, error) {
+	if options.PatchLog != nil {
+		return options.PatchLog, nil
+	}
+	if options.PatchLogPath == "" {
+		return nil, nil
+	}
+	return NewFilePatchLog(options.PatchLogPath)
+}
+
 // createPubSub은 PubSub 인스턴스를 생성합니다.
 func createPubSub(ctx context.Context, options *StorageOptions) (crdtpubsub.PubSub, error) {
+	// 사용자 정의 PubSub이 있는 경우 사용
+	if options.PubSub != nil {
+		return options.PubSub, nil
+	}
+
 	switch options.PubSubType {
 	case "memory":
 		return memory.NewPubSub()
@@ -290,14 +380,24 @@ func (s *storageImpl) CreateDocument(ctx context.Context, documentID string) (*D
 		lockManager:        s.lockManager,
 		transactionManager: s.transactionManager,
 		Version:            1,
+		patchLog:           s.patchLog,
 	}
 
+	// 새 문서의 저장된 버전 기록
+	s.trackVersion(doc, 0)
+
 	// 동기화 매니저 설정
 	if err := s.setupSyncManager(doc); err != nil {
 		docCancel()
 		return nil, fmt.Errorf("failed to setup sync manager: %w", err)
 	}
 
+	// PubSub 토픽 연결
+	if err := s.bridgeDocument(doc); err != nil {
+		docCancel()
+		return nil, err
+	}
+
 	// 자동 저장 설정
 	if doc.autoSave {
 		go doc.startAutoSave()
@@ -312,6 +412,11 @@ func (s *storageImpl) CreateDocument(ctx context.Context, documentID string) (*D
 	// 문서 맵에 추가
 	s.documents[documentID] = doc
 
+	// 자동 스냅샷 대상 등록
+	if s.snapshotScheduler != nil {
+		s.snapshotScheduler.track(doc)
+	}
+
 	return doc, nil
 }
 
@@ -371,6 +476,7 @@ func (s *storageImpl) GetDocument(ctx context.Context, documentID string) (*Docu
 		lockManager:        s.lockManager,
 		transactionManager: s.transactionManager,
 		Version:            1,
+		patchLog:           s.patchLog,
 	}
 
 	// 문서 데이터 역직렬화
@@ -379,12 +485,27 @@ func (s *storageImpl) GetDocument(ctx context.Context, documentID string) (*Docu
 		return nil, fmt.Errorf("failed to deserialize document: %w", err)
 	}
 
+	// 로드한 문서의 저장된 버전 기록
+	s.trackVersion(doc, doc.Version)
+
+	// 역직렬화된 상태를 패치 로그의 기준으로 기록
+	if err := s.checkpointDocument(ctx, doc); err != nil {
+		docCancel()
+		return nil, err
+	}
+
 	// 동기화 매니저 설정
 	if err := s.setupSyncManager(doc); err != nil {
 		docCancel()
 		return nil, fmt.Errorf("failed to setup sync manager: %w", err)
 	}
 
+	// PubSub 토픽 연결
+	if err := s.bridgeDocument(doc); err != nil {
+		docCancel()
+		return nil, err
+	}
+
 	// 자동 저장 설정
 	if doc.autoSave {
 		go doc.startAutoSave()
@@ -393,6 +514,11 @@ func (s *storageImpl) GetDocument(ctx context.Context, documentID string) (*Docu
 	// 문서 맵에 추가
 	s.documents[documentID] = doc
 
+	// 자동 스냅샷 대상 등록
+	if s.snapshotScheduler != nil {
+		s.snapshotScheduler.track(doc)
+	}
+
 	return doc, nil
 }
 
@@ -411,6 +537,17 @@ func (s *storageImpl) DeleteDocument(ctx context.Context, documentID string) err
 		doc.cancel()
 		delete(s.documents, documentID)
 	}
+	if s.snapshotScheduler != nil {
+		s.snapshotScheduler.untrack(documentID)
+	}
+	s.unbridgeDocument(ctx, documentID)
+
+	// 패치 로그 삭제
+	if s.patchLog != nil {
+		if err := s.patchLog.Delete(ctx, documentID); err != nil {
+			return fmt.Errorf("failed to delete patch log: %w", err)
+		}
+	}
 
 	// 영구 저장소에서 문서 삭제
 	return s.persistence.DeleteDocument(ctx, documentID)
@@ -479,6 +616,14 @@ func (s *storageImpl) Close() error {
 	// 컨텍스트 취소
 	s.cancel()
 
+	// 자동 스냅샷 스케줄러 종료 대기
+	if s.snapshotScheduler != nil {
+		<-s.snapshotScheduler.done
+	}
+
+	// 문서 메시지 전송 종료 대기
+	<-s.publishDone
+
 	// 모든 문서 닫기
 	for _, doc := range s.documents {
 		doc.cancel()
@@ -502,6 +647,13 @@ func (s *storageImpl) Close() error {
 		return fmt.Errorf("failed to close persistence: %w", err)
 	}
 
+	// 패치 로그 닫기
+	if s.patchLog != nil {
+		if err := s.patchLog.Close(); err != nil {
+			return fmt.Errorf("failed to close patch log: %w", err)
+		}
+	}
+
 	// Redis 클라이언트 닫기
 	if s.redisClient != nil {
 		if err := s.redisClient.Close(); err != nil {
@@ -514,12 +666,145 @@ func (s *storageImpl) Close() error {
 
 // SaveDocument는 문서를 저장합니다.
 func (s *storageImpl) SaveDocument(ctx context.Context, doc *Document) error {
+	// 저장과 체크포인트 사이에 패치가 기록되지 않도록 보호
+	doc.logMutex.Lock()
+	defer doc.logMutex.Unlock()
+
 	// 마지막 수정 시간 업데이트
 	doc.LastModified = time.Now()
 
+	// 영구 저장소가 버전 비교 저장을 지원하면 다른 인스턴스의 변경사항을 병합하여 저장
+	if versioned, ok := s.persistence.(VersionedAdapter); ok && doc.trackUnsaved {
+		if err := s.saveVersioned(ctx, versioned, doc); err != nil {
+			return err
+		}
+		return s.checkpointLocked(ctx, doc)
+	}
+
 	// 영구 저장소에 저장
 	// Document 객체를 직접 전달하여 사용자가 필요에 맞게 데이터를 인덱싱하고 저장 쿼리를 작성할 수 있도록 함
-	return s.persistence.SaveDocument(ctx, doc)
+	if err := s.persistence.SaveDocument(ctx, doc); err != nil {
+		return err
+	}
+
+	// 저장된 상태를 체크포인트로 기록하고 패치 로그 비우기
+	return s.checkpointLocked(ctx, doc)
+}
+
+// checkpointDocument는 문서의 현재 CRDT 상태를 패치 로그의 체크포인트로 기록합니다.
+func (s *storageImpl) checkpointDocument(ctx context.Context, doc *Document) error {
+	doc.logMutex.Lock()
+	defer doc.logMutex.Unlock()
+
+	return s.checkpointLocked(ctx, doc)
+}
+
+// checkpointLocked는 logMutex를 보유한 상태에서 체크포인트를 기록합니다.
+func (s *storageImpl) checkpointLocked(ctx context.Context, doc *Document) error {
+	if s.patchLog == nil {
+		return nil
+	}
+
+	state, err := doc.CRDTDoc.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal document state: %w", err)
+	}
+	if err := s.patchLog.Checkpoint(ctx, doc.ID, state); err != nil {
+		return fmt.Errorf("failed to checkpoint patch log: %w", err)
+	}
+	return nil
+}
+
+// RecoverDocuments는 패치 로그를 마지막 체크포인트 위에 재생하여 저장되지 않은 변경사항을 복구합니다.
+// 이미 로드된 문서는 메모리에 최신 상태가 있으므로 건너뜁니다.
+func (s *storageImpl) RecoverDocuments(ctx context.Context) ([]string, error) {
+	if s.patchLog == nil {
+		return nil, nil
+	}
+
+	// 패치 로그가 있는 문서 목록 가져오기
+	documentIDs, err := s.patchLog.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list patch logs: %w", err)
+	}
+
+	var recovered []string
+	for _, documentID := range documentIDs {
+		s.mutex.RLock()
+		_, loaded := s.documents[documentID]
+		s.mutex.RUnlock()
+		if loaded {
+			continue
+		}
+
+		ok, err := s.recoverDocument(ctx, documentID)
+		if err != nil {
+			return recovered, fmt.Errorf("failed to recover document %s: %w", documentID, err)
+		}
+		if ok {
+			recovered = append(recovered, documentID)
+		}
+	}
+
+	return recovered, nil
+}
+
+// recoverDocument는 문서 하나를 복구합니다. 재생할 패치가 없으면 false를 반환합니다.
+func (s *storageImpl) recoverDocument(ctx context.Context, documentID string) (bool, error) {
+	// 체크포인트와 패치 로드
+	state, patches, err := s.patchLog.Load(ctx, documentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load patch log: %w", err)
+	}
+	if len(patches) == 0 {
+		return false, nil
+	}
+
+	// 복구용 문서 생성
+	sessionID := common.NewSessionID()
+	doc := &Document{
+		ID:           documentID,
+		CRDTDoc:      crdt.NewDocument(sessionID),
+		SessionID:    sessionID,
+		LastModified: time.Now(),
+		Metadata:     make(map[string]interface{}),
+		Version:      1,
+	}
+
+	// 영구 저장소의 메타데이터와 버전 유지
+	data, err := s.persistence.LoadDocument(ctx, documentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load document: %w", err)
+	}
+	if err := s.serializer.Deserialize(doc, data); err != nil {
+		return false, fmt.Errorf("failed to deserialize document: %w", err)
+	}
+
+	// 체크포인트 상태 복원 (패치는 체크포인트의 노드 ID를 참조)
+	if state != nil {
+		if err := doc.CRDTDoc.UnmarshalBinary(state); err != nil {
+			return false, fmt.Errorf("failed to restore checkpoint: %w", err)
+		}
+	}
+
+	// 기록된 순서대로 패치 재생
+	s.trackVersion(doc, doc.Version)
+	for _, patch := range patches {
+		if err := patch.Apply(doc.CRDTDoc); err != nil {
+			return false, fmt.Errorf("failed to replay patch: %w", err)
+		}
+	}
+	doc.Version += int64(len(patches))
+	if doc.trackUnsaved {
+		doc.unsaved = patches
+	}
+
+	// 복구된 문서 저장 및 체크포인트 기록
+	if err := s.SaveDocument(ctx, doc); err != nil {
+		return false, fmt.Errorf("failed to save recovered document: %w", err)
+	}
+
+	return true, nil
 }
 
 // setupSyncManager는 문서의 동기화 매니저를 설정합니다.
@@ -36,7 +36,13 @@ func (s *storageImpl) SaveSnapshot(ctx context.Context, doc *Document) error {
 	}
 
 	// 스냅샷 저장
-	return advancedAdapter.SaveSnapshot(ctx, snapshot)
+	if err := advancedAdapter.SaveSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+
+	// 문서 구독자와 다른 저장소에 알림
+	s.publishSnapshot(snapshot)
+	return nil
 }
 
 // ListSnapshots은 문서의 모든 스냅샷 목록을 반환합니다.
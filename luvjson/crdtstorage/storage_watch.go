@@ -0,0 +1,260 @@
+package crdtstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tictactoe/luvjson/crdtpatch"
+	"tictactoe/luvjson/crdtpubsub"
+)
+
+// WatchEventType은 WatchDocument 이벤트 유형입니다.
+type WatchEventType string
+
+const (
+	// WatchEventPatch는 문서에 패치가 적용되었음을 나타냅니다.
+	WatchEventPatch WatchEventType = "patch"
+
+	// WatchEventSnapshot은 문서 스냅샷이 저장되었음을 나타냅니다.
+	WatchEventSnapshot WatchEventType = "snapshot"
+)
+
+// WatchEvent는 WatchDocument가 전달하는 문서 이벤트입니다.
+type WatchEvent struct {
+	// Type은 이벤트 유형입니다.
+	Type WatchEventType
+
+	// DocumentID는 문서 ID입니다.
+	DocumentID string
+
+	// Patch는 적용된 패치입니다. 패치 이벤트에서만 설정됩니다.
+	Patch *crdtpatch.Patch
+
+	// Snapshot은 저장된 스냅샷입니다. 스냅샷 이벤트에서만 설정됩니다.
+	Snapshot *DocumentSnapshot
+
+	// Remote는 다른 프로세스의 저장소에서 받은 이벤트인지 여부입니다.
+	Remote bool
+}
+
+// documentMessage는 문서 토픽으로 전송되는 메시지입니다.
+type documentMessage struct {
+	// Origin은 메시지를 보낸 저장소 인스턴스 ID입니다.
+	Origin string `json:"origin"`
+
+	// Type은 이벤트 유형입니다.
+	Type WatchEventType `json:"type"`
+
+	// DocumentID는 문서 ID입니다.
+	DocumentID string `json:"documentId"`
+
+	// Patch는 JSON으로 인코딩된 패치입니다.
+	Patch json.RawMessage `json:"patch,omitempty"`
+
+	// Snapshot은 저장된 스냅샷입니다.
+	Snapshot *DocumentSnapshot `json:"snapshot,omitempty"`
+}
+
+// documentWatcher는 WatchDocument 구독자입니다.
+type documentWatcher struct {
+	// events는 이벤트를 전달하는 채널입니다.
+	events chan WatchEvent
+}
+
+// watchBufferSize는 구독자별 이벤트 버퍼 크기입니다.
+const watchBufferSize = 256
+
+// WatchDocument는 문서에 적용된 패치와 저장된 스냅샷을 전달하는 채널을 반환합니다.
+// 다른 프로세스의 저장소에서 적용된 패치도 PubSub을 통해 이 저장소의 문서에 적용된 뒤 전달됩니다.
+// 채널은 ctx가 취소되거나 저장소가 닫히면 닫힙니다.
+func (s *storageImpl) WatchDocument(ctx context.Context, documentID string) (<-chan WatchEvent, error) {
+	// 문서를 로드하여 PubSub 연결
+	if _, err := s.GetDocument(ctx, documentID); err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	// 구독자 등록
+	watcher := &documentWatcher{events: make(chan WatchEvent, watchBufferSize)}
+	s.watchMutex.Lock()
+	s.watchers[documentID] = append(s.watchers[documentID], watcher)
+	s.watchMutex.Unlock()
+
+	// 취소되면 구독자 제거
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.ctx.Done():
+		}
+
+		s.watchMutex.Lock()
+		defer s.watchMutex.Unlock()
+		watchers := s.watchers[documentID]
+		for i, w := range watchers {
+			if w == watcher {
+				s.watchers[documentID] = append(watchers[:i], watchers[i+1:]...)
+				break
+			}
+		}
+		if len(s.watchers[documentID]) == 0 {
+			delete(s.watchers, documentID)
+		}
+		close(watcher.events)
+	}()
+
+	return watcher.events, nil
+}
+
+// documentTopic은 문서 이벤트가 전송되는 PubSub 토픽을 반환합니다.
+func (s *storageImpl) documentTopic(documentID string) string {
+	return fmt.Sprintf("%s:documents:%s", s.options.KeyPrefix, documentID)
+}
+
+// bridgeDocument는 문서를 PubSub 토픽에 연결합니다.
+// 로컬 편집 패치는 토픽으로 전송되고, 다른 저장소에서 받은 패치는 문서에 적용됩니다.
+func (s *storageImpl) bridgeDocument(doc *Document) error {
+	s.watchMutex.Lock()
+	s.bridged[doc.ID] = doc
+	s.watchMutex.Unlock()
+
+	// 문서 토픽 구독
+	if err := s.pubsub.Subscribe(s.ctx, s.documentTopic(doc.ID), s.instanceID, s.handleDocumentMessage); err != nil {
+		s.watchMutex.Lock()
+		delete(s.bridged, doc.ID)
+		s.watchMutex.Unlock()
+		return fmt.Errorf("failed to subscribe document topic: %w", err)
+	}
+
+	// 로컬 편집 패치 전송
+	doc.OnChange(func(d *Document, patch *crdtpatch.Patch) {
+		s.publishPatch(d.ID, patch)
+	})
+
+	return nil
+}
+
+// unbridgeDocument는 문서를 PubSub 토픽에서 분리합니다.
+func (s *storageImpl) unbridgeDocument(ctx context.Context, documentID string) {
+	s.watchMutex.Lock()
+	delete(s.bridged, documentID)
+	s.watchMutex.Unlock()
+
+	if err := s.pubsub.Unsubscribe(ctx, s.documentTopic(documentID), s.instanceID); err != nil {
+		fmt.Printf("Warning: Failed to unsubscribe document topic %s: %v\n", documentID, err)
+	}
+}
+
+// publishPatch는 로컬에서 적용된 패치를 구독자에게 전달하고 전송 대기열에 넣습니다.
+// 편집 중 호출되므로 실제 전송은 publishLoop에서 합니다.
+func (s *storageImpl) publishPatch(documentID string, patch *crdtpatch.Patch) {
+	s.emit(WatchEvent{Type: WatchEventPatch, DocumentID: documentID, Patch: patch})
+
+	data, err := patch.MarshalJSON()
+	if err != nil {
+		fmt.Printf("Warning: Failed to marshal patch for document %s: %v\n", documentID, err)
+		return
+	}
+	s.enqueue(documentMessage{Type: WatchEventPatch, DocumentID: documentID, Patch: data})
+}
+
+// publishSnapshot은 저장된 스냅샷을 구독자에게 전달하고 전송 대기열에 넣습니다.
+func (s *storageImpl) publishSnapshot(snapshot *DocumentSnapshot) {
+	s.emit(WatchEvent{Type: WatchEventSnapshot, DocumentID: snapshot.DocumentID, Snapshot: snapshot})
+	s.enqueue(documentMessage{Type: WatchEventSnapshot, DocumentID: snapshot.DocumentID, Snapshot: snapshot})
+}
+
+// enqueue는 메시지를 전송 대기열에 넣습니다.
+func (s *storageImpl) enqueue(msg documentMessage) {
+	msg.Origin = s.instanceID
+	select {
+	case s.outbound <- msg:
+	case <-s.ctx.Done():
+	}
+}
+
+// publishLoop는 저장소 컨텍스트가 취소될 때까지 대기열의 메시지를 순서대로 전송합니다.
+func (s *storageImpl) publishLoop() {
+	defer close(s.publishDone)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg := <-s.outbound:
+			data, err := json.Marshal(msg)
+			if err != nil {
+				fmt.Printf("Warning: Failed to marshal document message: %v\n", err)
+				continue
+			}
+			if err := s.pubsub.PublishRaw(s.ctx, s.documentTopic(msg.DocumentID), data, crdtpubsub.EncodingFormatJSON); err != nil {
+				fmt.Printf("Warning: Failed to publish document message: %v\n", err)
+			}
+		}
+	}
+}
+
+// handleDocumentMessage는 다른 저장소에서 받은 문서 메시지를 처리합니다.
+func (s *storageImpl) handleDocumentMessage(ctx context.Context, topic string, data []byte, format crdtpubsub.EncodingFormat) error {
+	var msg documentMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("failed to unmarshal document message: %w", err)
+	}
+
+	// 자신이 보낸 메시지는 무시
+	if msg.Origin == s.instanceID {
+		return nil
+	}
+
+	s.watchMutex.RLock()
+	doc, ok := s.bridged[msg.DocumentID]
+	s.watchMutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	switch msg.Type {
+	case WatchEventPatch:
+		patch := &crdtpatch.Patch{}
+		if err := patch.UnmarshalJSON(msg.Patch); err != nil {
+			return fmt.Errorf("failed to unmarshal patch: %w", err)
+		}
+
+		// 로컬 편집과 겹치지 않도록 문서 잠금 후 적용
+		doc.mutex.Lock()
+		err := doc.applyPatch(ctx, patch)
+		if err == nil {
+			doc.LastModified = time.Now()
+		}
+		doc.mutex.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to apply remote patch to document %s: %w", doc.ID, err)
+		}
+
+		if s.snapshotScheduler != nil {
+			s.snapshotScheduler.recordPatch(doc.ID)
+		}
+		s.emit(WatchEvent{Type: WatchEventPatch, DocumentID: doc.ID, Patch: patch, Remote: true})
+	case WatchEventSnapshot:
+		if msg.Snapshot != nil {
+			s.emit(WatchEvent{Type: WatchEventSnapshot, DocumentID: doc.ID, Snapshot: msg.Snapshot, Remote: true})
+		}
+	}
+
+	return nil
+}
+
+// emit는 문서 구독자에게 이벤트를 전달합니다.
+// 구독자의 버퍼가 가득 차면 이벤트를 버리고 경고를 남깁니다.
+func (s *storageImpl) emit(event WatchEvent) {
+	s.watchMutex.RLock()
+	defer s.watchMutex.RUnlock()
+
+	for _, watcher := range s.watchers[event.DocumentID] {
+		select {
+		case watcher.events <- event:
+		default:
+			fmt.Printf("Warning: Dropped %s event for slow watcher of document %s\n", event.Type, event.DocumentID)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package crdtstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tictactoe/luvjson/crdtpubsub/memory"
+)
+
+// nextWatchEvent는 구독 채널에서 다음 이벤트를 기다립니다.
+func nextWatchEvent(t *testing.T, events <-chan WatchEvent) WatchEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for watch event")
+		return WatchEvent{}
+	}
+}
+
+// TestStorage_WatchDocument는 같은 PubSub과 영구 저장소를 공유하는 두 저장소가 문서 패치와 스냅샷을 주고받는지 테스트합니다.
+func TestStorage_WatchDocument(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 두 프로세스가 공유하는 PubSub과 영구 저장소
+	pubsub, err := memory.NewPubSub()
+	assert.NoError(t, err)
+	adapter, err := NewObjectSnapshotAdapter(NewMemoryAdapter(), NewMemoryObjectStore(), nil)
+	assert.NoError(t, err)
+	options := DefaultStorageOptions()
+	options.AutoSave = false
+	options.PubSub = pubsub
+	options.EnableSnapshots = false
+
+	first, err := NewStorageWithCustomPersistence(ctx, options, adapter)
+	assert.NoError(t, err)
+	defer first.Close()
+	second, err := NewStorageWithCustomPersistence(ctx, options, adapter)
+	assert.NoError(t, err)
+	defer second.Close()
+
+	// 첫 번째 저장소에서 문서 생성 후 두 번째 저장소에서 로드
+	doc, err := first.CreateDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	assert.NoError(t, first.SaveDocument(ctx, doc))
+
+	localEvents, err := first.WatchDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	remoteEvents, err := second.WatchDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+
+	// 첫 번째 저장소의 편집이 두 번째 저장소의 문서에 적용
+	result := doc.Edit(ctx, setRootContent(map[string]interface{}{"hp": 40}))
+	assert.True(t, result.Success)
+
+	event := nextWatchEvent(t, localEvents)
+	assert.Equal(t, WatchEventPatch, event.Type)
+	assert.False(t, event.Remote)
+	assert.Equal(t, result.Patch, event.Patch)
+
+	event = nextWatchEvent(t, remoteEvents)
+	assert.Equal(t, WatchEventPatch, event.Type)
+	assert.True(t, event.Remote)
+	assert.Equal(t, result.Patch.ID(), event.Patch.ID())
+
+	replica, err := second.GetDocument(ctx, "raid-1")
+	assert.NoError(t, err)
+	var content map[string]interface{}
+	assert.NoError(t, replica.GetContentAs(&content))
+	assert.Equal(t, float64(40), content["hp"])
+
+	// 스냅샷 저장도 전달
+	assert.NoError(t, first.SaveSnapshot(ctx, doc))
+	assert.Equal(t, WatchEventSnapshot, nextWatchEvent(t, localEvents).Type)
+	event = nextWatchEvent(t, remoteEvents)
+	assert.Equal(t, WatchEventSnapshot, event.Type)
+	assert.True(t, event.Remote)
+	assert.Equal(t, doc.Version, event.Snapshot.Version)
+
+	// 취소하면 채널이 닫힘
+	cancel()
+	for range remoteEvents {
+	}
+}
@@ -146,8 +146,8 @@ func (d *Document) EditTransaction(ctx context.Context, editFunc EditFunc) *Tran
 	metadata["transactionId"] = transactionID
 	patch.SetMetadata(metadata)
 
-	// 패치 직접 적용
-	if err := patch.Apply(d.CRDTDoc); err != nil {
+	// 패치 로그에 기록 후 적용
+	if err := d.applyPatch(ctx, patch); err != nil {
 		result.Error = fmt.Errorf("failed to apply patch: %w", err)
 
 		// 트랜잭션 실패 마커 생성 및 적용
@@ -14,7 +14,12 @@ import (
 	"tictactoe/luvjson/tracker"
 )
 
+//go:generate go run tictactoe/luvjson/tracker/cmd/trackergen
+
 // Document represents a collaborative document.
+// Its tracker code is generated by trackergen, so patches are generated without reflection.
+//
+//tracker:generate
 type Document struct {
 	Title    string   `json:"title" crdt:"title"`
 	Content  string   `json:"content" crdt:"content"`
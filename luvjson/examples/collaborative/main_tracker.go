@@ -0,0 +1,100 @@
+// Code generated by trackergen. DO NOT EDIT.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"tictactoe/luvjson/tracker"
+)
+
+// Tracker paths of the fields of Document.
+const (
+	DocumentPathTitle    = "title"
+	DocumentPathContent  = "content"
+	DocumentPathAuthors  = "authors"
+	DocumentPathModified = "modified"
+)
+
+// TrackerValue returns the JSON representation of the struct.
+func (v *Document) TrackerValue() interface{} {
+	return v.trackerValue()
+}
+
+func (v Document) trackerValue() interface{} {
+	return map[string]interface{}{
+		"title":    v.Title,
+		"content":  v.Content,
+		"authors":  tracker.SliceValue(v.Authors, tracker.PlainValue[string]),
+		"modified": v.Modified,
+	}
+}
+
+// TrackerDiff returns the changes from old, a *Document, to the struct.
+func (v *Document) TrackerDiff(old interface{}) ([]tracker.Change, error) {
+	o, ok := old.(*Document)
+	if !ok || o == nil {
+		return nil, fmt.Errorf("expected *Document, got %T", old)
+	}
+	var changes []tracker.Change
+	v.trackerDiff(o, "", &changes)
+	return changes, nil
+}
+
+func (v *Document) trackerDiff(old *Document, prefix string, changes *[]tracker.Change) {
+	tracker.DiffPlain(&v.Title, &old.Title, tracker.JoinPath(prefix, "title"), changes)
+	tracker.DiffPlain(&v.Content, &old.Content, tracker.JoinPath(prefix, "content"), changes)
+	tracker.DiffSlice(&v.Authors, &old.Authors, tracker.JoinPath(prefix, "authors"), changes, tracker.DiffPlain[string], tracker.PlainValue[string])
+	tracker.DiffPlain(&v.Modified, &old.Modified, tracker.JoinPath(prefix, "modified"), changes)
+}
+
+// TrackerApply sets the value at the given path of the struct.
+func (v *Document) TrackerApply(path string, value interface{}) error {
+	segments, err := tracker.ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return v.trackerApply(segments, value)
+}
+
+func (v *Document) trackerApply(segments []tracker.PathSegment, value interface{}) error {
+	if len(segments) == 0 {
+		*v = Document{}
+		if value == nil {
+			return nil
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object for Document, got %T", value)
+		}
+		for key, field := range fields {
+			if err := v.trackerApply([]tracker.PathSegment{{Key: key}}, field); err != nil && !errors.Is(err, tracker.ErrUnknownField) {
+				return err
+			}
+		}
+		return nil
+	}
+	if segments[0].IsIndex {
+		return fmt.Errorf("cannot apply path %s to Document", segments[0])
+	}
+	switch segments[0].Key {
+	case "title":
+		return tracker.ApplyString(&v.Title, segments[1:], value)
+	case "content":
+		return tracker.ApplyString(&v.Content, segments[1:], value)
+	case "authors":
+		return tracker.ApplySlice(&v.Authors, segments[1:], value, tracker.ApplyString)
+	case "modified":
+		return tracker.ApplyString(&v.Modified, segments[1:], value)
+	default:
+		return fmt.Errorf("%w %s of Document", tracker.ErrUnknownField, segments[0])
+	}
+}
+
+// TrackerClone returns a deep copy of the struct.
+func (v *Document) TrackerClone() interface{} {
+	clone := &Document{}
+	_ = clone.trackerApply(nil, v.trackerValue())
+	return clone
+}
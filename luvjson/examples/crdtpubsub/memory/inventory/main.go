@@ -37,9 +37,6 @@ type Inventory struct {
 }
 
 func main() {
-	// Note: This example currently has issues with the new SessionID format
-	// The SessionID is now encoded as a byte array instead of a string
-	// This causes JSON encoding/decoding issues
 	// Create a context
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
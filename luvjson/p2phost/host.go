@@ -0,0 +1,92 @@
+// Package p2phost creates the libp2p hosts shared by crdtserver and the libp2p
+// transport of crdtpubsub, so both listen, announce and secure connections the
+// same way.
+package p2phost
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/p2p/security/noise"
+	libp2ptls "github.com/libp2p/go-libp2p/p2p/security/tls"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// DefaultListenAddrs are the addresses a host listens on when none are set:
+// random ports for TCP, QUIC and WebSocket.
+var DefaultListenAddrs = []string{
+	"/ip4/0.0.0.0/tcp/0",
+	"/ip4/0.0.0.0/udp/0/quic-v1",
+	"/ip4/0.0.0.0/tcp/0/ws",
+}
+
+// Options represents configuration options for a libp2p host.
+type Options struct {
+	// ListenAddrs are the multiaddresses to listen on. The transport follows the
+	// address: TCP (/tcp), QUIC (/udp/.../quic-v1) or WebSocket (/tcp/.../ws).
+	// DefaultListenAddrs are used if it is empty.
+	ListenAddrs []string
+	// AnnounceAddrs are announced to other peers instead of the listen addresses,
+	// e.g. when the host runs behind NAT or a load balancer.
+	AnnounceAddrs []string
+	// Security lists the connection security protocols, "noise" and "tls", in
+	// the order they are negotiated. The libp2p defaults are used if it is empty.
+	Security []string
+}
+
+// New creates a libp2p host with the specified options. Relays are disabled.
+func New(options Options) (host.Host, error) {
+	listenAddrs := options.ListenAddrs
+	if len(listenAddrs) == 0 {
+		listenAddrs = DefaultListenAddrs
+	}
+
+	opts := []libp2p.Option{
+		libp2p.ListenAddrStrings(listenAddrs...),
+		libp2p.DisableRelay(),
+	}
+
+	security, err := securityOptions(options.Security)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, security...)
+
+	if len(options.AnnounceAddrs) > 0 {
+		announce := make([]multiaddr.Multiaddr, 0, len(options.AnnounceAddrs))
+		for _, addr := range options.AnnounceAddrs {
+			maddr, err := multiaddr.NewMultiaddr(addr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid announce address %q: %w", addr, err)
+			}
+			announce = append(announce, maddr)
+		}
+		opts = append(opts, libp2p.AddrsFactory(func([]multiaddr.Multiaddr) []multiaddr.Multiaddr {
+			return announce
+		}))
+	}
+
+	h, err := libp2p.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create libp2p host: %w", err)
+	}
+	return h, nil
+}
+
+// securityOptions converts the names of connection security protocols to libp2p options.
+func securityOptions(names []string) ([]libp2p.Option, error) {
+	opts := make([]libp2p.Option, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "noise":
+			opts = append(opts, libp2p.Security(noise.ID, noise.New))
+		case "tls":
+			opts = append(opts, libp2p.Security(libp2ptls.ID, libp2ptls.New))
+		default:
+			return nil, fmt.Errorf("unknown p2p security protocol: %q", name)
+		}
+	}
+	return opts, nil
+}
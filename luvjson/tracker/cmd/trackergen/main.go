@@ -0,0 +1,551 @@
+// Command trackergen generates typed tracker code for structs.
+//
+// It generates the methods of tracker.Generated for each struct annotated with a
+// //tracker:generate comment, so that the tracker diffs, applies and copies these
+// structs without reflection, and constants with the tracker paths of their fields.
+//
+// Usage:
+//
+//	//go:generate go run tictactoe/luvjson/tracker/cmd/trackergen
+//
+//	//tracker:generate
+//	type Player struct {
+//		Name string `json:"name"`
+//		HP   int    `json:"hp"`
+//	}
+//
+// The code for the annotated structs of a file x.go is written to x_tracker.go.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// annotation marks the structs to generate code for.
+const annotation = "//tracker:generate"
+
+// trackerImport is the import path of the tracker package.
+const trackerImport = "tictactoe/luvjson/tracker"
+
+// numberTypes are the predeclared numeric types.
+var numberTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"byte": true, "rune": true, "float32": true, "float64": true,
+}
+
+// kind is the kind of a field type.
+type kind int
+
+const (
+	// kindJSON is a type the generated code handles by JSON encoding.
+	kindJSON kind = iota
+	// kindString is the string type.
+	kindString
+	// kindBool is the bool type.
+	kindBool
+	// kindNumber is a numeric type.
+	kindNumber
+	// kindStruct is a struct generated by trackergen.
+	kindStruct
+	// kindPointer is a pointer type.
+	kindPointer
+	// kindSlice is a slice type.
+	kindSlice
+	// kindMap is a map type with string keys.
+	kindMap
+)
+
+// field is a field of an annotated struct.
+type field struct {
+	// Name is the Go name of the field.
+	Name string
+
+	// Key is the JSON name of the field.
+	Key string
+
+	// Type is the type of the field.
+	Type ast.Expr
+}
+
+// structType is an annotated struct.
+type structType struct {
+	// Name is the name of the struct.
+	Name string
+
+	// Fields are the fields of the struct.
+	Fields []field
+}
+
+// generator generates the code for the annotated structs of a file.
+type generator struct {
+	// structs are the names of the annotated structs of the package.
+	structs map[string]bool
+
+	// numbers are the names of the numeric types of the package.
+	numbers map[string]bool
+
+	// buf is the generated code.
+	buf bytes.Buffer
+}
+
+func main() {
+	file := flag.String("file", os.Getenv("GOFILE"), "file with the annotated structs")
+	output := flag.String("output", "", "output file (default <file>_tracker.go)")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("trackergen: no file given; run it with go generate or set -file")
+	}
+	if *output == "" {
+		*output = outputName(*file)
+	}
+
+	if err := run(*file, *output); err != nil {
+		log.Fatalf("trackergen: %v", err)
+	}
+}
+
+// outputName returns the name of the file generated for the given file.
+func outputName(file string) string {
+	if base, ok := strings.CutSuffix(file, "_test.go"); ok {
+		return base + "_tracker_test.go"
+	}
+	return strings.TrimSuffix(file, ".go") + "_tracker.go"
+}
+
+// run generates the code for the annotated structs of the file.
+func run(file, output string) error {
+	fset := token.NewFileSet()
+	src, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	// Collect the annotated structs and numeric types of the package
+	g := &generator{structs: make(map[string]bool), numbers: make(map[string]bool)}
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(file), "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if filepath.Base(path) == filepath.Base(output) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		if f.Name.Name != src.Name.Name {
+			continue
+		}
+		g.collect(f)
+	}
+
+	structs, err := annotatedStructs(src)
+	if err != nil {
+		return err
+	}
+	if len(structs) == 0 {
+		return fmt.Errorf("no structs annotated with %s in %s", annotation, file)
+	}
+
+	code, err := g.generate(src, structs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, code, 0644)
+}
+
+// collect records the annotated structs and numeric types declared in the file.
+func (g *generator) collect(f *ast.File) {
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			switch t := typeSpec.Type.(type) {
+			case *ast.StructType:
+				if annotated(genDecl, typeSpec) {
+					g.structs[typeSpec.Name.Name] = true
+				}
+			case *ast.Ident:
+				if numberTypes[t.Name] {
+					g.numbers[typeSpec.Name.Name] = true
+				}
+			}
+		}
+	}
+}
+
+// annotated reports whether the type declaration is annotated.
+func annotated(genDecl *ast.GenDecl, typeSpec *ast.TypeSpec) bool {
+	for _, doc := range []*ast.CommentGroup{genDecl.Doc, typeSpec.Doc} {
+		if doc == nil {
+			continue
+		}
+		for _, comment := range doc.List {
+			if strings.TrimSpace(comment.Text) == annotation {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// annotatedStructs returns the annotated structs declared in the file.
+func annotatedStructs(f *ast.File) ([]structType, error) {
+	var structs []structType
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			st, ok := typeSpec.Type.(*ast.StructType)
+			if !ok || !annotated(genDecl, typeSpec) {
+				continue
+			}
+			if typeSpec.TypeParams != nil {
+				return nil, fmt.Errorf("generic struct %s is not supported", typeSpec.Name.Name)
+			}
+
+			s := structType{Name: typeSpec.Name.Name}
+			for _, f := range st.Fields.List {
+				if len(f.Names) == 0 {
+					return nil, fmt.Errorf("embedded field %s of %s is not supported", types.ExprString(f.Type), s.Name)
+				}
+				for _, name := range f.Names {
+					if !name.IsExported() {
+						continue
+					}
+					key := name.Name
+					if f.Tag != nil {
+						tag, err := strconv.Unquote(f.Tag.Value)
+						if err != nil {
+							return nil, err
+						}
+						jsonName, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+						if jsonName == "-" {
+							continue
+						}
+						if jsonName != "" {
+							key = jsonName
+						}
+					}
+					s.Fields = append(s.Fields, field{Name: name.Name, Key: key, Type: f.Type})
+				}
+			}
+			structs = append(structs, s)
+		}
+	}
+	return structs, nil
+}
+
+// kindOf returns the kind of the type.
+func (g *generator) kindOf(t ast.Expr) kind {
+	switch t := t.(type) {
+	case *ast.Ident:
+		switch {
+		case t.Name == "string":
+			return kindString
+		case t.Name == "bool":
+			return kindBool
+		case numberTypes[t.Name] || g.numbers[t.Name]:
+			return kindNumber
+		case g.structs[t.Name]:
+			return kindStruct
+		}
+	case *ast.StarExpr:
+		return kindPointer
+	case *ast.ArrayType:
+		// Byte slices are encoded as base64 strings
+		if elem, ok := t.Elt.(*ast.Ident); ok && elem.Name == "byte" {
+			return kindJSON
+		}
+		if t.Len == nil {
+			return kindSlice
+		}
+	case *ast.MapType:
+		if key, ok := t.Key.(*ast.Ident); ok && key.Name == "string" {
+			return kindMap
+		}
+	}
+	return kindJSON
+}
+
+// elemType returns the element type of a pointer, slice or map type.
+func elemType(t ast.Expr) ast.Expr {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		return t.X
+	case *ast.ArrayType:
+		return t.Elt
+	case *ast.MapType:
+		return t.Value
+	}
+	return nil
+}
+
+// valueExpr returns an expression for the JSON representation of x.
+func (g *generator) valueExpr(t ast.Expr, x string) string {
+	switch g.kindOf(t) {
+	case kindString, kindBool:
+		return x
+	case kindNumber:
+		return "float64(" + x + ")"
+	case kindStruct:
+		return x + ".trackerValue()"
+	case kindPointer:
+		return fmt.Sprintf("tracker.PointerValue(%s, %s)", x, g.valueFunc(elemType(t)))
+	case kindSlice:
+		return fmt.Sprintf("tracker.SliceValue(%s, %s)", x, g.valueFunc(elemType(t)))
+	case kindMap:
+		return fmt.Sprintf("tracker.MapValue(%s, %s)", x, g.valueFunc(elemType(t)))
+	default:
+		return "tracker.JSONValue(" + x + ")"
+	}
+}
+
+// valueFunc returns a function returning the JSON representation of a value of the type.
+func (g *generator) valueFunc(t ast.Expr) string {
+	typ := types.ExprString(t)
+	switch g.kindOf(t) {
+	case kindString, kindBool:
+		return "tracker.PlainValue[" + typ + "]"
+	case kindNumber:
+		return "tracker.NumberValue[" + typ + "]"
+	case kindStruct:
+		return typ + ".trackerValue"
+	case kindJSON:
+		return "tracker.JSONValue[" + typ + "]"
+	default:
+		return fmt.Sprintf("func(v %s) interface{} { return %s }", typ, g.valueExpr(t, "v"))
+	}
+}
+
+// diffCall returns a statement adding the changes from the value o points to
+// to the value n points to.
+func (g *generator) diffCall(t ast.Expr, n, o, path string) string {
+	switch g.kindOf(t) {
+	case kindString, kindBool:
+		return fmt.Sprintf("tracker.DiffPlain(%s, %s, %s, changes)", n, o, path)
+	case kindNumber:
+		return fmt.Sprintf("tracker.DiffNumber(%s, %s, %s, changes)", n, o, path)
+	case kindStruct:
+		return fmt.Sprintf("%s.trackerDiff(%s, %s, changes)", strings.TrimPrefix(n, "&"), o, path)
+	case kindPointer:
+		return fmt.Sprintf("tracker.DiffPointer(%s, %s, %s, changes, %s, %s)", n, o, path, g.diffFunc(elemType(t)), g.valueFunc(elemType(t)))
+	case kindSlice:
+		return fmt.Sprintf("tracker.DiffSlice(%s, %s, %s, changes, %s, %s)", n, o, path, g.diffFunc(elemType(t)), g.valueFunc(elemType(t)))
+	case kindMap:
+		return fmt.Sprintf("tracker.DiffMap(%s, %s, %s, changes, %s, %s)", n, o, path, g.diffFunc(elemType(t)), g.valueFunc(elemType(t)))
+	default:
+		return fmt.Sprintf("tracker.DiffJSON(%s, %s, %s, changes)", n, o, path)
+	}
+}
+
+// diffFunc returns a function adding the changes between values of the type.
+func (g *generator) diffFunc(t ast.Expr) string {
+	typ := types.ExprString(t)
+	switch g.kindOf(t) {
+	case kindString, kindBool:
+		return "tracker.DiffPlain[" + typ + "]"
+	case kindNumber:
+		return "tracker.DiffNumber[" + typ + "]"
+	case kindStruct:
+		return "(*" + typ + ").trackerDiff"
+	case kindJSON:
+		return "tracker.DiffJSON[" + typ + "]"
+	default:
+		return fmt.Sprintf("func(n, o *%s, path string, changes *[]tracker.Change) { %s }", typ, g.diffCall(t, "n", "o", "path"))
+	}
+}
+
+// applyCall returns an expression setting the value at segments below the value target points to.
+func (g *generator) applyCall(t ast.Expr, target, segments string) string {
+	switch g.kindOf(t) {
+	case kindString:
+		return fmt.Sprintf("tracker.ApplyString(%s, %s, value)", target, segments)
+	case kindBool:
+		return fmt.Sprintf("tracker.ApplyBool(%s, %s, value)", target, segments)
+	case kindNumber:
+		return fmt.Sprintf("tracker.ApplyNumber(%s, %s, value)", target, segments)
+	case kindStruct:
+		return fmt.Sprintf("%s.trackerApply(%s, value)", strings.TrimPrefix(target, "&"), segments)
+	case kindPointer:
+		return fmt.Sprintf("tracker.ApplyPointer(%s, %s, value, %s)", target, segments, g.applyFunc(elemType(t)))
+	case kindSlice:
+		return fmt.Sprintf("tracker.ApplySlice(%s, %s, value, %s)", target, segments, g.applyFunc(elemType(t)))
+	case kindMap:
+		return fmt.Sprintf("tracker.ApplyMap(%s, %s, value, %s)", target, segments, g.applyFunc(elemType(t)))
+	default:
+		return fmt.Sprintf("tracker.ApplyJSON(%s, %s, value)", target, segments)
+	}
+}
+
+// applyFunc returns a function setting the value at a path below a value of the type.
+func (g *generator) applyFunc(t ast.Expr) string {
+	typ := types.ExprString(t)
+	switch g.kindOf(t) {
+	case kindString:
+		return "tracker.ApplyString"
+	case kindBool:
+		return "tracker.ApplyBool"
+	case kindNumber:
+		return "tracker.ApplyNumber[" + typ + "]"
+	case kindStruct:
+		return "(*" + typ + ").trackerApply"
+	case kindJSON:
+		return "tracker.ApplyJSON[" + typ + "]"
+	default:
+		return fmt.Sprintf("func(target *%s, segments []tracker.PathSegment, value interface{}) error { return %s }", typ, g.applyCall(t, "target", "segments"))
+	}
+}
+
+// importPath returns the quoted path of an import spec.
+func importPath(spec string) string {
+	return spec[strings.Index(spec, `"`):]
+}
+
+// isStandard reports whether an import spec imports a standard library package.
+func isStandard(spec string) bool {
+	path, _ := strconv.Unquote(importPath(spec))
+	first, _, _ := strings.Cut(path, "/")
+	module, _, _ := strings.Cut(trackerImport, "/")
+	return !strings.Contains(first, ".") && first != module
+}
+
+// printf writes formatted code.
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// generate returns the formatted code for the structs of the file.
+func (g *generator) generate(src *ast.File, structs []structType) ([]byte, error) {
+	for _, s := range structs {
+		g.generateStruct(s)
+	}
+	body := g.buf.String()
+
+	// Import the packages the generated code refers to
+	imports := []string{strconv.Quote("errors"), strconv.Quote("fmt"), strconv.Quote(trackerImport)}
+	for _, spec := range src.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." || path == trackerImport || path == "errors" || path == "fmt" {
+			continue
+		}
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\.`).MatchString(body) {
+			if spec.Name != nil {
+				imports = append(imports, name+" "+spec.Path.Value)
+			} else {
+				imports = append(imports, spec.Path.Value)
+			}
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		if isStandard(imports[i]) != isStandard(imports[j]) {
+			return isStandard(imports[i])
+		}
+		return importPath(imports[i]) < importPath(imports[j])
+	})
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by trackergen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", src.Name.Name)
+	for i, imp := range imports {
+		if i > 0 && isStandard(imports[i-1]) && !isStandard(imp) {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "\t%s\n", imp)
+	}
+	fmt.Fprintf(&out, ")\n%s", body)
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// generateStruct writes the code for a struct.
+func (g *generator) generateStruct(s structType) {
+	// Paths of the fields
+	if len(s.Fields) > 0 {
+		g.printf("\n// Tracker paths of the fields of %s.\nconst (\n", s.Name)
+		for _, f := range s.Fields {
+			g.printf("\t%sPath%s = %q\n", s.Name, f.Name, f.Key)
+		}
+		g.printf(")\n")
+	}
+
+	// JSON representation
+	g.printf("\n// TrackerValue returns the JSON representation of the struct.\n")
+	g.printf("func (v *%s) TrackerValue() interface{} {\n\treturn v.trackerValue()\n}\n", s.Name)
+	g.printf("\nfunc (v %s) trackerValue() interface{} {\n\treturn map[string]interface{}{\n", s.Name)
+	for _, f := range s.Fields {
+		g.printf("\t\t%q: %s,\n", f.Key, g.valueExpr(f.Type, "v."+f.Name))
+	}
+	g.printf("\t}\n}\n")
+
+	// Diff
+	g.printf("\n// TrackerDiff returns the changes from old, a *%s, to the struct.\n", s.Name)
+	g.printf("func (v *%s) TrackerDiff(old interface{}) ([]tracker.Change, error) {\n", s.Name)
+	g.printf("\to, ok := old.(*%s)\n\tif !ok || o == nil {\n", s.Name)
+	g.printf("\t\treturn nil, fmt.Errorf(\"expected *%s, got %%T\", old)\n\t}\n", s.Name)
+	g.printf("\tvar changes []tracker.Change\n\tv.trackerDiff(o, \"\", &changes)\n\treturn changes, nil\n}\n")
+	g.printf("\nfunc (v *%s) trackerDiff(old *%s, prefix string, changes *[]tracker.Change) {\n", s.Name, s.Name)
+	for _, f := range s.Fields {
+		path := fmt.Sprintf("tracker.JoinPath(prefix, %q)", f.Key)
+		g.printf("\t%s\n", g.diffCall(f.Type, "&v."+f.Name, "&old."+f.Name, path))
+	}
+	g.printf("}\n")
+
+	// Apply
+	g.printf("\n// TrackerApply sets the value at the given path of the struct.\n")
+	g.printf("func (v *%s) TrackerApply(path string, value interface{}) error {\n", s.Name)
+	g.printf("\tsegments, err := tracker.ParsePath(path)\n\tif err != nil {\n\t\treturn err\n\t}\n")
+	g.printf("\treturn v.trackerApply(segments, value)\n}\n")
+	g.printf("\nfunc (v *%s) trackerApply(segments []tracker.PathSegment, value interface{}) error {\n", s.Name)
+	g.printf("\tif len(segments) == 0 {\n\t\t*v = %s{}\n\t\tif value == nil {\n\t\t\treturn nil\n\t\t}\n", s.Name)
+	g.printf("\t\tfields, ok := value.(map[string]interface{})\n\t\tif !ok {\n")
+	g.printf("\t\t\treturn fmt.Errorf(\"expected object for %s, got %%T\", value)\n\t\t}\n", s.Name)
+	g.printf("\t\tfor key, field := range fields {\n")
+	g.printf("\t\t\tif err := v.trackerApply([]tracker.PathSegment{{Key: key}}, field); err != nil && !errors.Is(err, tracker.ErrUnknownField) {\n")
+	g.printf("\t\t\t\treturn err\n\t\t\t}\n\t\t}\n\t\treturn nil\n\t}\n")
+	g.printf("\tif segments[0].IsIndex {\n\t\treturn fmt.Errorf(\"cannot apply path %%s to %s\", segments[0])\n\t}\n", s.Name)
+	g.printf("\tswitch segments[0].Key {\n")
+	for _, f := range s.Fields {
+		g.printf("\tcase %q:\n\t\treturn %s\n", f.Key, g.applyCall(f.Type, "&v."+f.Name, "segments[1:]"))
+	}
+	g.printf("\tdefault:\n\t\treturn fmt.Errorf(\"%%w %%s of %s\", tracker.ErrUnknownField, segments[0])\n\t}\n}\n", s.Name)
+
+	// Clone
+	g.printf("\n// TrackerClone returns a deep copy of the struct.\n")
+	g.printf("func (v *%s) TrackerClone() interface{} {\n\tclone := &%s{}\n", s.Name, s.Name)
+	g.printf("\t_ = clone.trackerApply(nil, v.trackerValue())\n\treturn clone\n}\n")
+}
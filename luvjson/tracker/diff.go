@@ -63,6 +63,16 @@ func Diff(old, new interface{}) (*DiffResult, error) {
 		Timestamp: time.Now(),
 	}
 
+	// Compare generated types with the generated diff
+	if generated, ok := new.(Generated); ok && reflect.TypeOf(old) == reflect.TypeOf(new) {
+		changes, err := generated.TrackerDiff(old)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes = append(result.Changes, changes...)
+		return result, nil
+	}
+
 	// Convert old and new to maps
 	oldMap, err := StructToMap(old)
 	if err != nil {
@@ -197,6 +207,16 @@ func diffSlices(oldSlice, newSlice []interface{}, prefix string, result *DiffRes
 
 // ApplyChanges applies a list of changes to a struct.
 func ApplyChanges(target interface{}, changes []Change) error {
+	// Apply changes to generated types with the generated setters
+	if generated, ok := target.(Generated); ok {
+		for _, change := range changes {
+			if err := generated.TrackerApply(change.Path, change.NewValue); err != nil {
+				return fmt.Errorf("failed to apply change to %s: %w", change.Path, err)
+			}
+		}
+		return nil
+	}
+
 	// Get the value of the target
 	targetValue := reflect.ValueOf(target)
 	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
@@ -0,0 +1,367 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnknownField is returned by generated code for a path to a field the struct does not have.
+var ErrUnknownField = errors.New("unknown field")
+
+// Generated is implemented by the types generated by trackergen.
+// The tracker uses the generated methods instead of reflection and JSON encoding
+// to track, diff and update values of these types.
+type Generated interface {
+	// TrackerValue returns the JSON representation of the struct as maps, slices and values.
+	TrackerValue() interface{}
+
+	// TrackerDiff returns the changes from old, a pointer to the same type, to the struct.
+	TrackerDiff(old interface{}) ([]Change, error)
+
+	// TrackerApply sets the value at the given path of the struct.
+	// A nil value deletes a map entry and resets other fields to their zero values.
+	TrackerApply(path string, value interface{}) error
+
+	// TrackerClone returns a deep copy of the struct.
+	TrackerClone() interface{}
+}
+
+// Number is the constraint of the numeric field types supported by generated code.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// PlainValue returns the JSON representation of a string or bool field.
+func PlainValue[T any](v T) interface{} {
+	return v
+}
+
+// NumberValue returns the JSON representation of a numeric field.
+func NumberValue[T Number](v T) interface{} {
+	return float64(v)
+}
+
+// JSONValue returns the JSON representation of a field of a type trackergen does not support,
+// by encoding and decoding it.
+func JSONValue[T any](v T) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return value
+}
+
+// PointerValue returns the JSON representation of a pointer field.
+func PointerValue[T any](p *T, value func(T) interface{}) interface{} {
+	if p == nil {
+		return nil
+	}
+	return value(*p)
+}
+
+// SliceValue returns the JSON representation of a slice field.
+func SliceValue[T any](s []T, value func(T) interface{}) interface{} {
+	if s == nil {
+		return nil
+	}
+	result := make([]interface{}, len(s))
+	for i, elem := range s {
+		result[i] = value(elem)
+	}
+	return result
+}
+
+// MapValue returns the JSON representation of a map field.
+func MapValue[T any](m map[string]T, value func(T) interface{}) interface{} {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for key, elem := range m {
+		result[key] = value(elem)
+	}
+	return result
+}
+
+// DiffPlain adds a change if a string or bool field changed.
+func DiffPlain[T comparable](n, o *T, path string, changes *[]Change) {
+	if *n != *o {
+		*changes = append(*changes, Change{Path: path, Type: ChangeTypeUpdate, OldValue: *o, NewValue: *n})
+	}
+}
+
+// DiffNumber adds a change if a numeric field changed.
+func DiffNumber[T Number](n, o *T, path string, changes *[]Change) {
+	if *n != *o {
+		*changes = append(*changes, Change{Path: path, Type: ChangeTypeUpdate, OldValue: float64(*o), NewValue: float64(*n)})
+	}
+}
+
+// DiffJSON adds a change if the JSON representation of a field of a type trackergen does not
+// support changed.
+func DiffJSON[T any](n, o *T, path string, changes *[]Change) {
+	newValue, oldValue := JSONValue(*n), JSONValue(*o)
+	if !reflect.DeepEqual(newValue, oldValue) {
+		*changes = append(*changes, Change{Path: path, Type: ChangeTypeUpdate, OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+// DiffPointer adds the changes of a pointer field.
+// A pointer that became nil or non-nil is replaced as a whole.
+func DiffPointer[T any](n, o **T, path string, changes *[]Change,
+	diff func(n, o *T, path string, changes *[]Change), value func(T) interface{}) {
+	switch {
+	case *n == nil && *o == nil:
+	case *n == nil || *o == nil:
+		*changes = append(*changes, Change{
+			Path:     path,
+			Type:     ChangeTypeUpdate,
+			OldValue: PointerValue(*o, value),
+			NewValue: PointerValue(*n, value),
+		})
+	default:
+		diff(*n, *o, path, changes)
+	}
+}
+
+// DiffSlice adds the changes of a slice field.
+// A slice whose length changed is replaced as a whole, like Diff does.
+func DiffSlice[T any](n, o *[]T, path string, changes *[]Change,
+	diff func(n, o *T, path string, changes *[]Change), value func(T) interface{}) {
+	if len(*n) != len(*o) || (*n == nil) != (*o == nil) {
+		*changes = append(*changes, Change{
+			Path:     path,
+			Type:     ChangeTypeUpdate,
+			OldValue: SliceValue(*o, value),
+			NewValue: SliceValue(*n, value),
+		})
+		return
+	}
+	for i := range *n {
+		diff(&(*n)[i], &(*o)[i], IndexPath(path, i), changes)
+	}
+}
+
+// DiffMap adds the changes of a map field.
+// A map that became nil or non-nil is replaced as a whole.
+func DiffMap[T any](n, o *map[string]T, path string, changes *[]Change,
+	diff func(n, o *T, path string, changes *[]Change), value func(T) interface{}) {
+	if (*n == nil) != (*o == nil) {
+		*changes = append(*changes, Change{
+			Path:     path,
+			Type:     ChangeTypeUpdate,
+			OldValue: MapValue(*o, value),
+			NewValue: MapValue(*n, value),
+		})
+		return
+	}
+
+	// Check for updated or deleted entries
+	for key, oldElem := range *o {
+		newElem, exists := (*n)[key]
+		if !exists {
+			*changes = append(*changes, Change{Path: JoinPath(path, key), Type: ChangeTypeDelete, OldValue: value(oldElem)})
+			continue
+		}
+		diff(&newElem, &oldElem, JoinPath(path, key), changes)
+	}
+
+	// Check for created entries
+	for key, newElem := range *n {
+		if _, exists := (*o)[key]; !exists {
+			*changes = append(*changes, Change{Path: JoinPath(path, key), Type: ChangeTypeCreate, NewValue: value(newElem)})
+		}
+	}
+}
+
+// ApplyString sets a string field.
+func ApplyString(target *string, segments []PathSegment, value interface{}) error {
+	if len(segments) > 0 {
+		return fmt.Errorf("cannot apply path %s to a string", segments[0])
+	}
+	switch v := value.(type) {
+	case nil:
+		*target = ""
+	case string:
+		*target = v
+	default:
+		return fmt.Errorf("expected string, got %T", value)
+	}
+	return nil
+}
+
+// ApplyBool sets a bool field.
+func ApplyBool(target *bool, segments []PathSegment, value interface{}) error {
+	if len(segments) > 0 {
+		return fmt.Errorf("cannot apply path %s to a bool", segments[0])
+	}
+	switch v := value.(type) {
+	case nil:
+		*target = false
+	case bool:
+		*target = v
+	default:
+		return fmt.Errorf("expected bool, got %T", value)
+	}
+	return nil
+}
+
+// ApplyNumber sets a numeric field.
+func ApplyNumber[T Number](target *T, segments []PathSegment, value interface{}) error {
+	if len(segments) > 0 {
+		return fmt.Errorf("cannot apply path %s to a number", segments[0])
+	}
+	switch v := value.(type) {
+	case nil:
+		*target = 0
+	case float64:
+		*target = T(v)
+	case float32:
+		*target = T(v)
+	case int:
+		*target = T(v)
+	case int64:
+		*target = T(v)
+	case int32:
+		*target = T(v)
+	case uint64:
+		*target = T(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %s: %w", v, err)
+		}
+		*target = T(f)
+	default:
+		return fmt.Errorf("expected number, got %T", value)
+	}
+	return nil
+}
+
+// ApplyJSON sets a field of a type trackergen does not support, by encoding and decoding
+// the value.
+func ApplyJSON[T any](target *T, segments []PathSegment, value interface{}) error {
+	if len(segments) > 0 {
+		return fmt.Errorf("cannot apply path %s to %T", segments[0], *target)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+	*target = result
+	return nil
+}
+
+// ApplyPointer sets a pointer field or the value at a path below it.
+func ApplyPointer[T any](target **T, segments []PathSegment, value interface{},
+	apply func(target *T, segments []PathSegment, value interface{}) error) error {
+	if len(segments) == 0 && value == nil {
+		*target = nil
+		return nil
+	}
+	elem := new(T)
+	if *target != nil && len(segments) > 0 {
+		elem = *target
+	}
+	if err := apply(elem, segments, value); err != nil {
+		return err
+	}
+	*target = elem
+	return nil
+}
+
+// ApplySlice sets a slice field or the value at a path below it.
+func ApplySlice[T any](target *[]T, segments []PathSegment, value interface{},
+	apply func(target *T, segments []PathSegment, value interface{}) error) error {
+	// Set the whole slice
+	if len(segments) == 0 {
+		if value == nil {
+			*target = nil
+			return nil
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected slice, got %T", value)
+		}
+		result := make([]T, len(values))
+		for i, elem := range values {
+			if err := apply(&result[i], nil, elem); err != nil {
+				return fmt.Errorf("failed to apply element %d: %w", i, err)
+			}
+		}
+		*target = result
+		return nil
+	}
+
+	// Set an element
+	if !segments[0].IsIndex {
+		return fmt.Errorf("cannot apply path %s to a slice", segments[0])
+	}
+	index := segments[0].Index
+	if index > len(*target) {
+		return fmt.Errorf("index %d out of range", index)
+	}
+	if index == len(*target) {
+		var zero T
+		*target = append(*target, zero)
+	}
+	return apply(&(*target)[index], segments[1:], value)
+}
+
+// ApplyMap sets a map field or the value at a path below it.
+// A nil value for an entry deletes the entry.
+func ApplyMap[T any](target *map[string]T, segments []PathSegment, value interface{},
+	apply func(target *T, segments []PathSegment, value interface{}) error) error {
+	// Set the whole map
+	if len(segments) == 0 {
+		if value == nil {
+			*target = nil
+			return nil
+		}
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected map, got %T", value)
+		}
+		result := make(map[string]T, len(values))
+		for key, elem := range values {
+			var v T
+			if err := apply(&v, nil, elem); err != nil {
+				return fmt.Errorf("failed to apply entry %s: %w", key, err)
+			}
+			result[key] = v
+		}
+		*target = result
+		return nil
+	}
+
+	// Set or delete an entry
+	if segments[0].IsIndex {
+		return fmt.Errorf("cannot apply path %s to a map", segments[0])
+	}
+	key := segments[0].Key
+	if len(segments) == 1 && value == nil {
+		delete(*target, key)
+		return nil
+	}
+	elem := (*target)[key]
+	if err := apply(&elem, segments[1:], value); err != nil {
+		return err
+	}
+	if *target == nil {
+		*target = make(map[string]T)
+	}
+	(*target)[key] = elem
+	return nil
+}
@@ -0,0 +1,174 @@
+package tracker_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/tracker"
+)
+
+//go:generate go run ./cmd/trackergen
+
+// GenPhase is a named numeric type for the generated code tests.
+type GenPhase int
+
+// GenPlayer is a test struct with generated tracker code.
+//
+//tracker:generate
+type GenPlayer struct {
+	Name  string   `json:"name"`
+	HP    int      `json:"hp"`
+	Alive bool     `json:"alive"`
+	Buffs []string `json:"buffs"`
+}
+
+// GenBoss is a test struct with generated tracker code.
+//
+//tracker:generate
+type GenBoss struct {
+	HP    float64  `json:"hp"`
+	Phase GenPhase `json:"phase"`
+}
+
+// GenRaid is a test struct with generated tracker code.
+//
+//tracker:generate
+type GenRaid struct {
+	Name    string                `json:"name"`
+	Boss    *GenBoss              `json:"boss"`
+	Loot    map[string]int        `json:"loot"`
+	Players []GenPlayer           `json:"players"`
+	Roles   map[string]*GenPlayer `json:"roles"`
+	Started time.Time             `json:"started"`
+	secret  string
+}
+
+// newGenRaid returns a raid for the generated code tests.
+func newGenRaid() *GenRaid {
+	return &GenRaid{
+		Name: "Dragon",
+		Boss: &GenBoss{HP: 1000, Phase: 1},
+		Loot: map[string]int{"gold": 10},
+		Players: []GenPlayer{
+			{Name: "alice", HP: 100, Alive: true, Buffs: []string{"haste"}},
+			{Name: "bob", HP: 80, Alive: true},
+		},
+		Roles:   map[string]*GenPlayer{"tank": {Name: "bob", HP: 80, Alive: true}},
+		Started: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// TestGenerated_Diff tests that the generated diff reports the changed paths like the reflection diff.
+func TestGenerated_Diff(t *testing.T) {
+	old := newGenRaid()
+	raid := old.TrackerClone().(*GenRaid)
+	if !reflect.DeepEqual(raid, old) {
+		t.Fatalf("Expected clone %+v, got %+v", old, raid)
+	}
+
+	// The clone does not share slices, maps or pointers
+	raid.Boss.HP = 900
+	raid.Boss.Phase = 2
+	raid.Loot["gem"] = 1
+	delete(raid.Loot, "gold")
+	raid.Players[1].HP = 60
+	raid.Players[0].Buffs[0] = "shield"
+	raid.Roles["tank"].Alive = false
+	raid.Started = raid.Started.Add(time.Hour)
+	if old.Boss.HP != 1000 || old.Loot["gold"] != 10 || old.Players[1].HP != 80 ||
+		old.Players[0].Buffs[0] != "haste" || !old.Roles["tank"].Alive {
+		t.Fatalf("Changing the clone changed the original: %+v", old)
+	}
+
+	result, err := tracker.Diff(old, raid)
+	if err != nil {
+		t.Fatalf("Failed to diff: %v", err)
+	}
+	var paths []string
+	for _, change := range result.Changes {
+		paths = append(paths, change.Path)
+	}
+	sort.Strings(paths)
+	expected := []string{
+		"boss.hp", "boss.phase", "loot.gem", "loot.gold", "players[0].buffs[0]",
+		"players[1].hp", "roles.tank.alive", "started",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected paths %v, got %v", expected, paths)
+	}
+
+	// Applying the changes to the old struct gives the new struct
+	if err := tracker.ApplyChanges(old, result.Changes); err != nil {
+		t.Fatalf("Failed to apply changes: %v", err)
+	}
+	if !reflect.DeepEqual(old, raid) {
+		t.Errorf("Expected %+v, got %+v", raid, old)
+	}
+
+	// Unknown paths and mismatched values
+	if err := raid.TrackerApply("unknown", 1); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+	if err := raid.TrackerApply("players[0].hp", "full"); err == nil {
+		t.Errorf("Expected error for mismatched type")
+	}
+	if _, err := raid.TrackerDiff(&GenBoss{}); err == nil {
+		t.Errorf("Expected error for a different type")
+	}
+
+	// The path constants match the JSON names
+	path := tracker.JoinPath(tracker.IndexPath(GenRaidPathPlayers, 1), GenPlayerPathHP)
+	if path != "players[1].hp" {
+		t.Errorf("Expected path players[1].hp, got %s", path)
+	}
+}
+
+// TestGenerated_Tracker tests tracking a generated type with the tracker.
+func TestGenerated_Tracker(t *testing.T) {
+	sid := common.NewSessionID()
+	doc := crdt.NewDocument(sid)
+	tr := tracker.NewTracker(doc, sid)
+
+	raid := newGenRaid()
+	if err := tr.InitializeDocument(raid); err != nil {
+		t.Fatalf("Failed to initialize document: %v", err)
+	}
+
+	// Change the raid and apply the patch
+	raid.Boss.HP = 750
+	raid.Players[0].Alive = false
+	raid.Players = append(raid.Players, GenPlayer{Name: "carol", HP: 50})
+	raid.Loot["gem"] = 3
+	patch, err := tr.Update(raid)
+	if err != nil {
+		t.Fatalf("Failed to update tracker: %v", err)
+	}
+	if len(patch.Operations()) == 0 {
+		t.Fatalf("Expected operations in the patch")
+	}
+	if err := tr.ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+
+	// The document holds the changed raid
+	var result GenRaid
+	if err := tr.ToStruct(&result); err != nil {
+		t.Fatalf("Failed to convert view to struct: %v", err)
+	}
+	if !reflect.DeepEqual(&result, raid) {
+		t.Errorf("Expected %+v, got %+v", raid, result)
+	}
+
+	// No changes give an empty patch
+	patch, err = tr.Update(raid)
+	if err != nil {
+		t.Fatalf("Failed to update tracker: %v", err)
+	}
+	if len(patch.Operations()) != 0 {
+		t.Errorf("Expected empty patch, got %d operations", len(patch.Operations()))
+	}
+}
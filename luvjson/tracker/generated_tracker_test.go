@@ -0,0 +1,284 @@
+// Code generated by trackergen. DO NOT EDIT.
+
+package tracker_test
+
+import (
+	"errors"
+	"fmt"
+
+	"tictactoe/luvjson/tracker"
+)
+
+// Tracker paths of the fields of GenPlayer.
+const (
+	GenPlayerPathName  = "name"
+	GenPlayerPathHP    = "hp"
+	GenPlayerPathAlive = "alive"
+	GenPlayerPathBuffs = "buffs"
+)
+
+// TrackerValue returns the JSON representation of the struct.
+func (v *GenPlayer) TrackerValue() interface{} {
+	return v.trackerValue()
+}
+
+func (v GenPlayer) trackerValue() interface{} {
+	return map[string]interface{}{
+		"name":  v.Name,
+		"hp":    float64(v.HP),
+		"alive": v.Alive,
+		"buffs": tracker.SliceValue(v.Buffs, tracker.PlainValue[string]),
+	}
+}
+
+// TrackerDiff returns the changes from old, a *GenPlayer, to the struct.
+func (v *GenPlayer) TrackerDiff(old interface{}) ([]tracker.Change, error) {
+	o, ok := old.(*GenPlayer)
+	if !ok || o == nil {
+		return nil, fmt.Errorf("expected *GenPlayer, got %T", old)
+	}
+	var changes []tracker.Change
+	v.trackerDiff(o, "", &changes)
+	return changes, nil
+}
+
+func (v *GenPlayer) trackerDiff(old *GenPlayer, prefix string, changes *[]tracker.Change) {
+	tracker.DiffPlain(&v.Name, &old.Name, tracker.JoinPath(prefix, "name"), changes)
+	tracker.DiffNumber(&v.HP, &old.HP, tracker.JoinPath(prefix, "hp"), changes)
+	tracker.DiffPlain(&v.Alive, &old.Alive, tracker.JoinPath(prefix, "alive"), changes)
+	tracker.DiffSlice(&v.Buffs, &old.Buffs, tracker.JoinPath(prefix, "buffs"), changes, tracker.DiffPlain[string], tracker.PlainValue[string])
+}
+
+// TrackerApply sets the value at the given path of the struct.
+func (v *GenPlayer) TrackerApply(path string, value interface{}) error {
+	segments, err := tracker.ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return v.trackerApply(segments, value)
+}
+
+func (v *GenPlayer) trackerApply(segments []tracker.PathSegment, value interface{}) error {
+	if len(segments) == 0 {
+		*v = GenPlayer{}
+		if value == nil {
+			return nil
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object for GenPlayer, got %T", value)
+		}
+		for key, field := range fields {
+			if err := v.trackerApply([]tracker.PathSegment{{Key: key}}, field); err != nil && !errors.Is(err, tracker.ErrUnknownField) {
+				return err
+			}
+		}
+		return nil
+	}
+	if segments[0].IsIndex {
+		return fmt.Errorf("cannot apply path %s to GenPlayer", segments[0])
+	}
+	switch segments[0].Key {
+	case "name":
+		return tracker.ApplyString(&v.Name, segments[1:], value)
+	case "hp":
+		return tracker.ApplyNumber(&v.HP, segments[1:], value)
+	case "alive":
+		return tracker.ApplyBool(&v.Alive, segments[1:], value)
+	case "buffs":
+		return tracker.ApplySlice(&v.Buffs, segments[1:], value, tracker.ApplyString)
+	default:
+		return fmt.Errorf("%w %s of GenPlayer", tracker.ErrUnknownField, segments[0])
+	}
+}
+
+// TrackerClone returns a deep copy of the struct.
+func (v *GenPlayer) TrackerClone() interface{} {
+	clone := &GenPlayer{}
+	_ = clone.trackerApply(nil, v.trackerValue())
+	return clone
+}
+
+// Tracker paths of the fields of GenBoss.
+const (
+	GenBossPathHP    = "hp"
+	GenBossPathPhase = "phase"
+)
+
+// TrackerValue returns the JSON representation of the struct.
+func (v *GenBoss) TrackerValue() interface{} {
+	return v.trackerValue()
+}
+
+func (v GenBoss) trackerValue() interface{} {
+	return map[string]interface{}{
+		"hp":    float64(v.HP),
+		"phase": float64(v.Phase),
+	}
+}
+
+// TrackerDiff returns the changes from old, a *GenBoss, to the struct.
+func (v *GenBoss) TrackerDiff(old interface{}) ([]tracker.Change, error) {
+	o, ok := old.(*GenBoss)
+	if !ok || o == nil {
+		return nil, fmt.Errorf("expected *GenBoss, got %T", old)
+	}
+	var changes []tracker.Change
+	v.trackerDiff(o, "", &changes)
+	return changes, nil
+}
+
+func (v *GenBoss) trackerDiff(old *GenBoss, prefix string, changes *[]tracker.Change) {
+	tracker.DiffNumber(&v.HP, &old.HP, tracker.JoinPath(prefix, "hp"), changes)
+	tracker.DiffNumber(&v.Phase, &old.Phase, tracker.JoinPath(prefix, "phase"), changes)
+}
+
+// TrackerApply sets the value at the given path of the struct.
+func (v *GenBoss) TrackerApply(path string, value interface{}) error {
+	segments, err := tracker.ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return v.trackerApply(segments, value)
+}
+
+func (v *GenBoss) trackerApply(segments []tracker.PathSegment, value interface{}) error {
+	if len(segments) == 0 {
+		*v = GenBoss{}
+		if value == nil {
+			return nil
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object for GenBoss, got %T", value)
+		}
+		for key, field := range fields {
+			if err := v.trackerApply([]tracker.PathSegment{{Key: key}}, field); err != nil && !errors.Is(err, tracker.ErrUnknownField) {
+				return err
+			}
+		}
+		return nil
+	}
+	if segments[0].IsIndex {
+		return fmt.Errorf("cannot apply path %s to GenBoss", segments[0])
+	}
+	switch segments[0].Key {
+	case "hp":
+		return tracker.ApplyNumber(&v.HP, segments[1:], value)
+	case "phase":
+		return tracker.ApplyNumber(&v.Phase, segments[1:], value)
+	default:
+		return fmt.Errorf("%w %s of GenBoss", tracker.ErrUnknownField, segments[0])
+	}
+}
+
+// TrackerClone returns a deep copy of the struct.
+func (v *GenBoss) TrackerClone() interface{} {
+	clone := &GenBoss{}
+	_ = clone.trackerApply(nil, v.trackerValue())
+	return clone
+}
+
+// Tracker paths of the fields of GenRaid.
+const (
+	GenRaidPathName    = "name"
+	GenRaidPathBoss    = "boss"
+	GenRaidPathLoot    = "loot"
+	GenRaidPathPlayers = "players"
+	GenRaidPathRoles   = "roles"
+	GenRaidPathStarted = "started"
+)
+
+// TrackerValue returns the JSON representation of the struct.
+func (v *GenRaid) TrackerValue() interface{} {
+	return v.trackerValue()
+}
+
+func (v GenRaid) trackerValue() interface{} {
+	return map[string]interface{}{
+		"name":    v.Name,
+		"boss":    tracker.PointerValue(v.Boss, GenBoss.trackerValue),
+		"loot":    tracker.MapValue(v.Loot, tracker.NumberValue[int]),
+		"players": tracker.SliceValue(v.Players, GenPlayer.trackerValue),
+		"roles":   tracker.MapValue(v.Roles, func(v *GenPlayer) interface{} { return tracker.PointerValue(v, GenPlayer.trackerValue) }),
+		"started": tracker.JSONValue(v.Started),
+	}
+}
+
+// TrackerDiff returns the changes from old, a *GenRaid, to the struct.
+func (v *GenRaid) TrackerDiff(old interface{}) ([]tracker.Change, error) {
+	o, ok := old.(*GenRaid)
+	if !ok || o == nil {
+		return nil, fmt.Errorf("expected *GenRaid, got %T", old)
+	}
+	var changes []tracker.Change
+	v.trackerDiff(o, "", &changes)
+	return changes, nil
+}
+
+func (v *GenRaid) trackerDiff(old *GenRaid, prefix string, changes *[]tracker.Change) {
+	tracker.DiffPlain(&v.Name, &old.Name, tracker.JoinPath(prefix, "name"), changes)
+	tracker.DiffPointer(&v.Boss, &old.Boss, tracker.JoinPath(prefix, "boss"), changes, (*GenBoss).trackerDiff, GenBoss.trackerValue)
+	tracker.DiffMap(&v.Loot, &old.Loot, tracker.JoinPath(prefix, "loot"), changes, tracker.DiffNumber[int], tracker.NumberValue[int])
+	tracker.DiffSlice(&v.Players, &old.Players, tracker.JoinPath(prefix, "players"), changes, (*GenPlayer).trackerDiff, GenPlayer.trackerValue)
+	tracker.DiffMap(&v.Roles, &old.Roles, tracker.JoinPath(prefix, "roles"), changes, func(n, o **GenPlayer, path string, changes *[]tracker.Change) {
+		tracker.DiffPointer(n, o, path, changes, (*GenPlayer).trackerDiff, GenPlayer.trackerValue)
+	}, func(v *GenPlayer) interface{} { return tracker.PointerValue(v, GenPlayer.trackerValue) })
+	tracker.DiffJSON(&v.Started, &old.Started, tracker.JoinPath(prefix, "started"), changes)
+}
+
+// TrackerApply sets the value at the given path of the struct.
+func (v *GenRaid) TrackerApply(path string, value interface{}) error {
+	segments, err := tracker.ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return v.trackerApply(segments, value)
+}
+
+func (v *GenRaid) trackerApply(segments []tracker.PathSegment, value interface{}) error {
+	if len(segments) == 0 {
+		*v = GenRaid{}
+		if value == nil {
+			return nil
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object for GenRaid, got %T", value)
+		}
+		for key, field := range fields {
+			if err := v.trackerApply([]tracker.PathSegment{{Key: key}}, field); err != nil && !errors.Is(err, tracker.ErrUnknownField) {
+				return err
+			}
+		}
+		return nil
+	}
+	if segments[0].IsIndex {
+		return fmt.Errorf("cannot apply path %s to GenRaid", segments[0])
+	}
+	switch segments[0].Key {
+	case "name":
+		return tracker.ApplyString(&v.Name, segments[1:], value)
+	case "boss":
+		return tracker.ApplyPointer(&v.Boss, segments[1:], value, (*GenBoss).trackerApply)
+	case "loot":
+		return tracker.ApplyMap(&v.Loot, segments[1:], value, tracker.ApplyNumber[int])
+	case "players":
+		return tracker.ApplySlice(&v.Players, segments[1:], value, (*GenPlayer).trackerApply)
+	case "roles":
+		return tracker.ApplyMap(&v.Roles, segments[1:], value, func(target **GenPlayer, segments []tracker.PathSegment, value interface{}) error {
+			return tracker.ApplyPointer(target, segments, value, (*GenPlayer).trackerApply)
+		})
+	case "started":
+		return tracker.ApplyJSON(&v.Started, segments[1:], value)
+	default:
+		return fmt.Errorf("%w %s of GenRaid", tracker.ErrUnknownField, segments[0])
+	}
+}
+
+// TrackerClone returns a deep copy of the struct.
+func (v *GenRaid) TrackerClone() interface{} {
+	clone := &GenRaid{}
+	_ = clone.trackerApply(nil, v.trackerValue())
+	return clone
+}
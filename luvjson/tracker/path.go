@@ -0,0 +1,288 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tictactoe/luvjson/common"
+	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// PathSegment is a segment of a change path: an object key or a slice index.
+type PathSegment struct {
+	// Key is the object key of the segment.
+	Key string
+
+	// Index is the slice index of the segment.
+	Index int
+
+	// IsIndex indicates whether the segment is a slice index.
+	IsIndex bool
+}
+
+// String returns the string representation of the segment.
+func (s PathSegment) String() string {
+	if s.IsIndex {
+		return fmt.Sprintf("[%d]", s.Index)
+	}
+	return s.Key
+}
+
+// fieldReplacement is an object field replaced as a whole by a patch.
+type fieldReplacement struct {
+	// obj is the object node holding the field.
+	obj *crdt.LWWObjectNode
+
+	// path is the path to the field.
+	path []PathSegment
+
+	// changes are the changes below the field.
+	changes []Change
+
+	// subpaths are the paths of the changes relative to the field.
+	subpaths [][]PathSegment
+}
+
+// JoinPath returns the path of the field with the given key below prefix.
+func JoinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// IndexPath returns the path of the slice element with the given index below path.
+func IndexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
+// ParsePath splits a change path such as "party.members[0].hp" into segments.
+// An empty path refers to the whole struct and has no segments.
+func ParsePath(path string) ([]PathSegment, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []PathSegment
+	for _, part := range strings.Split(path, ".") {
+		// Split off trailing slice indexes
+		name := part
+		var indexes []int
+		for strings.HasSuffix(name, "]") {
+			open := strings.LastIndex(name, "[")
+			if open < 0 {
+				return nil, fmt.Errorf("invalid path: %s", path)
+			}
+			index, err := strconv.Atoi(name[open+1 : len(name)-1])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in path: %s", path)
+			}
+			indexes = append([]int{index}, indexes...)
+			name = name[:open]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid path: %s", path)
+		}
+
+		segments = append(segments, PathSegment{Key: name})
+		for _, index := range indexes {
+			segments = append(segments, PathSegment{Index: index, IsIndex: true})
+		}
+	}
+	return segments, nil
+}
+
+// childNode returns the object or array node at the segment of the given node.
+// Constant nodes that refer to other nodes are followed.
+// It returns nil if there is no object or array node at the segment.
+func (t *Tracker) childNode(node crdt.Node, segment PathSegment) crdt.Node {
+	var child crdt.Node
+	switch n := node.(type) {
+	case *crdt.LWWObjectNode:
+		if segment.IsIndex {
+			return nil
+		}
+		child = n.Get(segment.Key)
+	case *crdt.RGAArrayNode:
+		if !segment.IsIndex {
+			return nil
+		}
+		id, err := n.Get(segment.Index)
+		if err != nil {
+			return nil
+		}
+		child, _ = t.doc.GetNode(id)
+	}
+
+	// Follow a constant node that refers to another node
+	if constant, ok := child.(*crdt.ConstantNode); ok {
+		id, ok := constant.NodeValue.(common.LogicalTimestamp)
+		if !ok {
+			return nil
+		}
+		child, _ = t.doc.GetNode(id)
+	}
+
+	switch child.(type) {
+	case *crdt.LWWObjectNode, *crdt.RGAArrayNode:
+		return child
+	default:
+		return nil
+	}
+}
+
+// valueOperations adds operations creating nodes for the nested objects and slices of
+// objects in value, so that later changes can target their fields, and returns the value
+// to insert: the ID of the created node, or value itself for other values.
+func (t *Tracker) valueOperations(patch *crdtpatch.Patch, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// Create an object node with a field for each key
+		objID := t.doc.NextTimestamp()
+		patch.AddOperation(&crdtpatch.NewOperation{
+			ID:       objID,
+			NodeType: common.NodeTypeObj,
+		})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldValue := t.valueOperations(patch, v[key])
+			patch.AddOperation(&crdtpatch.InsOperation{
+				ID:       t.doc.NextTimestamp(),
+				TargetID: objID,
+				Value:    map[string]interface{}{key: fieldValue},
+			})
+		}
+		return objID
+
+	case []interface{}:
+		// Slices without objects stay constant values
+		if !containsObject(v) {
+			return v
+		}
+
+		// Create an array node with an element for each value
+		arrID := t.doc.NextTimestamp()
+		patch.AddOperation(&crdtpatch.NewOperation{
+			ID:       arrID,
+			NodeType: common.NodeTypeArr,
+		})
+		for i, elem := range v {
+			elemValue := t.valueOperations(patch, elem)
+			patch.AddOperation(&crdtpatch.InsOperation{
+				ID:       t.doc.NextTimestamp(),
+				TargetID: arrID,
+				Value:    map[string]interface{}{strconv.Itoa(i): elemValue},
+			})
+		}
+		return arrID
+
+	default:
+		return value
+	}
+}
+
+// buildNode adds nodes for value to the document and returns the node holding it.
+// Nested objects become object nodes and slices of objects become array nodes.
+func (t *Tracker) buildNode(value interface{}) crdt.Node {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		objID := t.doc.NextTimestamp()
+		obj := crdt.NewLWWObjectNode(objID)
+		t.doc.AddNode(obj)
+		for key, fieldValue := range v {
+			field := t.buildNode(fieldValue)
+			obj.Set(key, field.ID(), field)
+		}
+		return obj
+
+	case []interface{}:
+		if !containsObject(v) {
+			break
+		}
+		arrID := t.doc.NextTimestamp()
+		arr := crdt.NewRGAArrayNode(arrID)
+		t.doc.AddNode(arr)
+		afterID := common.RootID
+		for _, elem := range v {
+			elemNode := t.buildNode(elem)
+			elemID := t.doc.NextTimestamp()
+			arr.Insert(afterID, elemID, elemNode.ID())
+			afterID = elemID
+		}
+		return arr
+	}
+
+	valueID := t.doc.NextTimestamp()
+	valueNode := crdt.NewConstantNode(valueID, value)
+	t.doc.AddNode(valueNode)
+	return valueNode
+}
+
+// containsObject reports whether any element of the slice is an object.
+func containsObject(values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// viewAtPath returns the value at the given segments of a document view.
+func viewAtPath(view interface{}, segments []PathSegment) interface{} {
+	for _, segment := range segments {
+		if segment.IsIndex {
+			slice, ok := view.([]interface{})
+			if !ok || segment.Index >= len(slice) {
+				return nil
+			}
+			view = slice[segment.Index]
+			continue
+		}
+		m, ok := view.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		view = m[segment.Key]
+	}
+	return view
+}
+
+// applyAtPath applies a change at the given segments below value and returns the result.
+func applyAtPath(value interface{}, segments []PathSegment, change Change) interface{} {
+	if len(segments) == 0 {
+		return change.NewValue
+	}
+
+	segment := segments[0]
+	last := len(segments) == 1 && change.Type == ChangeTypeDelete
+	if segment.IsIndex {
+		slice, _ := value.([]interface{})
+		for len(slice) <= segment.Index {
+			slice = append(slice, nil)
+		}
+		if last {
+			return append(slice[:segment.Index], slice[segment.Index+1:]...)
+		}
+		slice[segment.Index] = applyAtPath(slice[segment.Index], segments[1:], change)
+		return slice
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+	}
+	if last {
+		delete(m, segment.Key)
+		return m
+	}
+	m[segment.Key] = applyAtPath(m[segment.Key], segments[1:], change)
+	return m
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
-	"strings"
 	"time"
 
 	"tictactoe/luvjson/common"
@@ -15,12 +14,21 @@ import (
 // TrackedState represents a tracked state of a struct.
 type TrackedState struct {
 	// Data is the JSON representation of the struct.
+	// It is nil for types generated by trackergen.
 	Data []byte
 
+	// Value is a copy of the struct for types generated by trackergen.
+	Value interface{}
+
 	// Timestamp is when the state was captured.
 	Timestamp time.Time
 }
 
+// ChangeHandler is called for each field changed by an incoming patch.
+// The path is in the same format as Change.Path, and old or new is nil
+// if the field was created or deleted.
+type ChangeHandler func(path string, old, new interface{})
+
 // Tracker tracks changes to a struct and generates CRDT patches.
 type Tracker struct {
 	// doc is the CRDT document being tracked.
@@ -40,6 +48,9 @@ type Tracker struct {
 
 	// patches is a list of all patches applied to the document.
 	patches []*crdtpatch.Patch
+
+	// changeHandlers are called for fields changed by incoming patches.
+	changeHandlers []ChangeHandler
 }
 
 // NewTracker creates a new CRDT tracker for the given document.
@@ -89,6 +100,15 @@ func (t *Tracker) Track(data interface{}) error {
 		return fmt.Errorf("data must be a pointer to a struct, got pointer to %v", dataElem.Kind())
 	}
 
+	// Store a copy of a generated type
+	if generated, ok := data.(Generated); ok {
+		t.states[dataElem.Type()] = TrackedState{
+			Value:     generated.TrackerClone(),
+			Timestamp: time.Now(),
+		}
+		return nil
+	}
+
 	// Convert struct to JSON
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -130,8 +150,15 @@ func (t *Tracker) Update(data interface{}) (*crdtpatch.Patch, error) {
 		return crdtpatch.NewPatch(common.LogicalTimestamp{}), nil
 	}
 
-	// Get the changes
-	changes, err := t.GetChanges(prevState.Data, data)
+	// Get the changes, with the generated diff for generated types
+	var changes []Change
+	var err error
+	generated, isGenerated := data.(Generated)
+	if isGenerated && prevState.Value != nil {
+		changes, err = generated.TrackerDiff(prevState.Value)
+	} else {
+		changes, err = t.GetChanges(prevState.Data, data)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changes: %w", err)
 	}
@@ -148,6 +175,14 @@ func (t *Tracker) Update(data interface{}) (*crdtpatch.Patch, error) {
 	}
 
 	// Update the tracked state
+	if isGenerated {
+		t.states[dataElem.Type()] = TrackedState{
+			Value:     generated.TrackerClone(),
+			Timestamp: time.Now(),
+		}
+		return patch, nil
+	}
+
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal struct: %w", err)
@@ -194,24 +229,17 @@ func (t *Tracker) GetChanges(prevData []byte, currentData interface{}) ([]Change
 }
 
 // GeneratePatch generates a CRDT patch from the changes.
+// Each change targets the object node that holds the changed field, so changing a nested
+// field does not replace its parent. Where a path leaves the object and array nodes of
+// the document, the deepest object field on the path is replaced instead.
 func (t *Tracker) GeneratePatch(changes []Change) (*crdtpatch.Patch, error) {
 	// Create a new patch
 	patchID := t.builder.NextTimestamp()
 
 	patch := crdtpatch.NewPatch(patchID)
 
-	// Get the root node
-	rootNode := t.doc.Root()
-
 	// Get the root object
-	var rootObj *crdt.LWWObjectNode
-	if lwwVal, ok := rootNode.(*crdt.LWWValueNode); ok {
-		if lwwVal.NodeValue != nil {
-			if obj, ok := lwwVal.NodeValue.(*crdt.LWWObjectNode); ok {
-				rootObj = obj
-			}
-		}
-	}
+	rootObj := t.rootObject()
 
 	// If the root object doesn't exist, create it
 	if rootObj == nil {
@@ -221,143 +249,146 @@ func (t *Tracker) GeneratePatch(changes []Change) (*crdtpatch.Patch, error) {
 		t.doc.AddNode(rootObj)
 
 		// Set the root value
-		if lwwVal, ok := rootNode.(*crdt.LWWValueNode); ok {
-			lwwVal.SetValue(rootID, rootObj)
+		if err := t.doc.SetRoot(rootID); err != nil {
+			return nil, fmt.Errorf("failed to set root object: %w", err)
 		}
 	}
 
+	// Fields replaced as a whole, in the order of their first change
+	var replacements []*fieldReplacement
+	replacementsByField := make(map[string]*fieldReplacement)
+
 	// Add operations for each change
 	for _, change := range changes {
-		switch change.Type {
-		case ChangeTypeCreate, ChangeTypeUpdate:
-			// Create a constant node for the value
-			valueID := t.doc.NextTimestamp()
-			valueOp := &crdtpatch.NewOperation{
-				ID:       valueID,
-				NodeType: common.NodeTypeCon,
-				Value:    change.NewValue,
+		segments, err := ParsePath(change.Path)
+		if err != nil {
+			return nil, err
+		}
+		if len(segments) == 0 {
+			return nil, fmt.Errorf("change path cannot be empty")
+		}
+
+		// Find the deepest object node on the path
+		obj := rootObj
+		depth := 0
+		var node crdt.Node = rootObj
+		for i := 0; i < len(segments)-1; i++ {
+			node = t.childNode(node, segments[i])
+			if node == nil {
+				break
 			}
-			patch.AddOperation(valueOp)
-
-			// Create an insert operation to update the field
-			insID := t.doc.NextTimestamp()
-
-			// Handle nested paths
-			pathParts := strings.Split(change.Path, ".")
-			if len(pathParts) > 1 {
-				// This is a nested path, we need to get the actual object
-				// Get the root object from the document view
-				view, err := t.doc.View()
-				if err != nil {
-					return nil, fmt.Errorf("failed to get document view: %w", err)
-				}
-
-				// Convert view to map
-				viewMap, ok := view.(map[string]interface{})
-				if !ok {
-					return nil, fmt.Errorf("document view is not a map")
-				}
-
-				// Navigate to the parent object
-				parentPath := pathParts[0]
-				parentObj, exists := viewMap[parentPath]
-				if !exists {
-					// Parent object doesn't exist, create it
-					parentObj = make(map[string]interface{})
-					viewMap[parentPath] = parentObj
-				}
-
-				// Convert to map
-				parentMap, ok := parentObj.(map[string]interface{})
-				if !ok {
-					// Parent exists but is not a map, replace it
-					parentMap = make(map[string]interface{})
-					viewMap[parentPath] = parentMap
-				}
-
-				// Update the field in the parent object
-				parentMap[pathParts[1]] = change.NewValue
-
-				// Create an insert operation for the parent object
-				insOp := &crdtpatch.InsOperation{
-					ID:       insID,
-					TargetID: rootObj.ID(),
-					Value:    map[string]interface{}{pathParts[0]: parentMap},
-				}
-				patch.AddOperation(insOp)
-			} else {
-				// This is a top-level field
-				insOp := &crdtpatch.InsOperation{
-					ID:       insID,
-					TargetID: rootObj.ID(),
-					Value:    map[string]interface{}{change.Path: change.NewValue},
-				}
-				patch.AddOperation(insOp)
+			if nested, ok := node.(*crdt.LWWObjectNode); ok {
+				obj = nested
+				depth = i + 1
 			}
+		}
+
+		if segments[depth].IsIndex {
+			return nil, fmt.Errorf("path %s does not match the document", change.Path)
+		}
 
-		case ChangeTypeDelete:
-			// Create a delete operation
-			delID := t.doc.NextTimestamp()
-
-			// Handle nested paths
-			pathParts := strings.Split(change.Path, ".")
-			if len(pathParts) > 1 {
-				// This is a nested path, we need to handle it differently
-				// Get the root object from the document view
-				view, err := t.doc.View()
-				if err != nil {
-					return nil, fmt.Errorf("failed to get document view: %w", err)
-				}
-
-				// Convert view to map
-				viewMap, ok := view.(map[string]interface{})
-				if !ok {
-					return nil, fmt.Errorf("document view is not a map")
-				}
-
-				// Navigate to the parent object
-				parentPath := pathParts[0]
-				parentObj, exists := viewMap[parentPath]
-				if !exists {
-					// Parent object doesn't exist, nothing to delete
-					continue
-				}
-
-				// Convert to map
-				parentMap, ok := parentObj.(map[string]interface{})
-				if !ok {
-					// Parent exists but is not a map, nothing to delete
-					continue
-				}
-
-				// Delete the field from the parent object
-				delete(parentMap, pathParts[1])
-
-				// Create an insert operation for the updated parent object
-				insID := t.doc.NextTimestamp()
-				insOp := &crdtpatch.InsOperation{
-					ID:       insID,
-					TargetID: rootObj.ID(),
-					Value:    map[string]interface{}{pathParts[0]: parentMap},
-				}
-				patch.AddOperation(insOp)
-			} else {
-				// This is a top-level field
-				delOp := &crdtpatch.DelOperation{
-					ID:       delID,
-					TargetID: rootObj.ID(),
-					Key:      change.Path,
-				}
-				patch.AddOperation(delOp)
+		// The changed field is a field of the object node
+		if depth == len(segments)-1 {
+			key := segments[depth].Key
+			switch change.Type {
+			case ChangeTypeCreate, ChangeTypeUpdate:
+				value := t.valueOperations(patch, change.NewValue)
+				patch.AddOperation(&crdtpatch.InsOperation{
+					ID:       t.doc.NextTimestamp(),
+					TargetID: obj.ID(),
+					Value:    map[string]interface{}{key: value},
+				})
+			case ChangeTypeDelete:
+				patch.AddOperation(&crdtpatch.DelOperation{
+					ID:       t.doc.NextTimestamp(),
+					TargetID: obj.ID(),
+					Key:      key,
+				})
 			}
+			continue
 		}
+
+		// Otherwise the field of the object node on the path is replaced
+		field := obj.ID().String() + "/" + segments[depth].Key
+		replacement, ok := replacementsByField[field]
+		if !ok {
+			replacement = &fieldReplacement{obj: obj, path: segments[:depth+1]}
+			replacementsByField[field] = replacement
+			replacements = append(replacements, replacement)
+		}
+		replacement.changes = append(replacement.changes, change)
+		replacement.subpaths = append(replacement.subpaths, segments[depth+1:])
+	}
+	if len(replacements) == 0 {
+		return patch, nil
+	}
+
+	// Apply the changes to the current values of the replaced fields
+	view, err := t.doc.View()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document view: %w", err)
+	}
+	for _, replacement := range replacements {
+		value := viewAtPath(view, replacement.path)
+		for i, change := range replacement.changes {
+			value = applyAtPath(value, replacement.subpaths[i], change)
+		}
+
+		key := replacement.path[len(replacement.path)-1].Key
+		value = t.valueOperations(patch, value)
+		patch.AddOperation(&crdtpatch.InsOperation{
+			ID:       t.doc.NextTimestamp(),
+			TargetID: replacement.obj.ID(),
+			Value:    map[string]interface{}{key: value},
+		})
 	}
 
 	return patch, nil
 }
 
+// rootObject returns the object node the document root refers to.
+// It returns nil if the root does not refer to an object.
+func (t *Tracker) rootObject() *crdt.LWWObjectNode {
+	var value crdt.Node
+	switch root := t.doc.Root().(type) {
+	case *crdt.RootNode:
+		value = root.NodeValue
+	case *crdt.LWWValueNode:
+		value = root.NodeValue
+	}
+
+	// A root created by a patch holds the ID of the object in a constant node
+	if constant, ok := value.(*crdt.ConstantNode); ok {
+		id, ok := constant.NodeValue.(common.LogicalTimestamp)
+		if !ok {
+			return nil
+		}
+		node, err := t.doc.GetNode(id)
+		if err != nil {
+			return nil
+		}
+		value = node
+	}
+
+	obj, _ := value.(*crdt.LWWObjectNode)
+	return obj
+}
+
+// OnChange registers a handler that is called for each field changed by an incoming patch,
+// that is a patch applied with ApplyPatch that was not generated by this tracker.
+func (t *Tracker) OnChange(handler ChangeHandler) {
+	t.changeHandlers = append(t.changeHandlers, handler)
+}
+
 // ApplyPatch applies a CRDT patch to the document.
 func (t *Tracker) ApplyPatch(patch *crdtpatch.Patch) error {
+	// Capture the view before an incoming patch if there are change handlers
+	notify := len(t.changeHandlers) > 0 && patch.ID().SID != t.sessionID
+	var before map[string]interface{}
+	if notify {
+		before = t.viewMap()
+	}
+
 	// Apply the patch to the document
 	if err := patch.Apply(t.doc); err != nil {
 		return err
@@ -366,9 +397,34 @@ func (t *Tracker) ApplyPatch(patch *crdtpatch.Patch) error {
 	// Add the patch to the list of applied patches
 	t.patches = append(t.patches, patch.Clone())
 
+	// Notify the change handlers of the changed fields
+	if notify {
+		result := &DiffResult{}
+		diffMaps(before, t.viewMap(), "", result)
+		for _, change := range result.Changes {
+			for _, handler := range t.changeHandlers {
+				handler(change.Path, change.OldValue, change.NewValue)
+			}
+		}
+	}
+
 	return nil
 }
 
+// viewMap returns the view of the document as a map.
+// It returns an empty map if the document does not hold an object.
+func (t *Tracker) viewMap() map[string]interface{} {
+	view, err := t.doc.View()
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	viewMap, ok := view.(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return viewMap
+}
+
 // GetDocument returns the CRDT document being tracked.
 func (t *Tracker) GetDocument() *crdt.Document {
 	return t.doc
@@ -420,21 +476,18 @@ func (t *Tracker) InitializeDocument(data interface{}) error {
 	}
 
 	// Convert struct to map
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("failed to marshal struct: %w", err)
-	}
-
 	var dataMap map[string]interface{}
-	if err := json.Unmarshal(jsonData, &dataMap); err != nil {
-		return fmt.Errorf("failed to unmarshal to map: %w", err)
-	}
+	if generated, ok := data.(Generated); ok {
+		dataMap, _ = generated.TrackerValue().(map[string]interface{})
+	} else {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal struct: %w", err)
+		}
 
-	// Get the root node
-	zeroSID := common.SessionID{}
-	rootNode, err := t.doc.GetNode(common.LogicalTimestamp{SID: zeroSID, Counter: 0})
-	if err != nil {
-		return fmt.Errorf("failed to get root node: %w", err)
+		if err := json.Unmarshal(jsonData, &dataMap); err != nil {
+			return fmt.Errorf("failed to unmarshal to map: %w", err)
+		}
 	}
 
 	// Create a new object node
@@ -443,41 +496,14 @@ func (t *Tracker) InitializeDocument(data interface{}) error {
 	t.doc.AddNode(rootObj)
 
 	// Set the root value
-	if lwwVal, ok := rootNode.(*crdt.LWWValueNode); ok {
-		lwwVal.SetValue(rootID, rootObj)
+	if err := t.doc.SetRoot(rootID); err != nil {
+		return fmt.Errorf("failed to set root object: %w", err)
 	}
 
-	// Add fields to the object
+	// Add fields to the object, with nodes for nested objects and slices of objects
 	for key, value := range dataMap {
-		// Check if the value is a map (nested object)
-		if nestedMap, isMap := value.(map[string]interface{}); isMap {
-			// Create a new object node for the nested object
-			nestedID := t.doc.NextTimestamp()
-			nestedObj := crdt.NewLWWObjectNode(nestedID)
-			t.doc.AddNode(nestedObj)
-
-			// Add the nested object to the root object
-			rootObj.Set(key, nestedID, nestedObj)
-
-			// Add fields to the nested object
-			for nestedKey, nestedValue := range nestedMap {
-				// Create a constant node for the nested value
-				nestedValueID := t.doc.NextTimestamp()
-				nestedValueNode := crdt.NewConstantNode(nestedValueID, nestedValue)
-				t.doc.AddNode(nestedValueNode)
-
-				// Add the field to the nested object
-				nestedObj.Set(nestedKey, nestedValueID, nestedValueNode)
-			}
-		} else {
-			// Create a constant node for the value
-			valueID := t.doc.NextTimestamp()
-			valueNode := crdt.NewConstantNode(valueID, value)
-			t.doc.AddNode(valueNode)
-
-			// Add the field to the object
-			rootObj.Set(key, valueID, valueNode)
-		}
+		valueNode := t.buildNode(value)
+		rootObj.Set(key, valueNode.ID(), valueNode)
 	}
 
 	// Track the struct
@@ -598,6 +624,14 @@ func (t *Tracker) ToStruct(result interface{}) error {
 		return fmt.Errorf("failed to get document view: %w", err)
 	}
 
+	// Set the fields of a generated type from the view
+	if generated, ok := result.(Generated); ok {
+		if err := generated.TrackerApply("", view); err != nil {
+			return fmt.Errorf("failed to apply view to struct: %w", err)
+		}
+		return nil
+	}
+
 	// Convert view to JSON
 	jsonData, err := json.Marshal(view)
 	if err != nil {
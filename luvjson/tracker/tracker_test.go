@@ -3,11 +3,13 @@ package tracker
 import (
 	"encoding/json"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	"tictactoe/luvjson/common"
 	"tictactoe/luvjson/crdt"
+	"tictactoe/luvjson/crdtpatch"
 )
 
 // TestPerson is a test struct for the tracker.
@@ -281,6 +283,273 @@ func TestTrackableStruct(t *testing.T) {
 	}
 }
 
+// TestTrackableStruct_FieldWrites tests that only written fields are included in the patch.
+func TestTrackableStruct_FieldWrites(t *testing.T) {
+	// Create a test person
+	person := &TestPerson{
+		Name:    "John Doe",
+		Age:     30,
+		Email:   "john@example.com",
+		Created: time.Now(),
+	}
+	person.Address.City = "New York"
+
+	// Create a trackable struct
+	sid := common.NewSessionID()
+	trackable, err := NewTrackableStruct(person, sid)
+	if err != nil {
+		t.Fatalf("Failed to create trackable struct: %v", err)
+	}
+
+	// Same instant with a different JSON representation must not produce a change
+	person.Created = person.Created.In(time.FixedZone("KST", 9*60*60))
+
+	// Write fields through the trackable struct
+	if err := trackable.Set("name", "Jane Doe"); err != nil {
+		t.Fatalf("Failed to set name: %v", err)
+	}
+	if err := trackable.Set("age", 31.0); err != nil {
+		t.Fatalf("Failed to set age: %v", err)
+	}
+	if err := trackable.Set("email", "john@example.com"); err != nil {
+		t.Fatalf("Failed to set email: %v", err)
+	}
+	person.Address.City = "Seoul"
+	if err := trackable.MarkChanged("address.city"); err != nil {
+		t.Fatalf("Failed to mark address.city: %v", err)
+	}
+	if person.Name != "Jane Doe" || person.Age != 31 {
+		t.Fatalf("Set did not update the struct: %+v", person)
+	}
+
+	// Invalid writes
+	if err := trackable.Set("unknown", 1); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+	if err := trackable.Set("name", 1); err == nil {
+		t.Errorf("Expected error for mismatched type")
+	}
+
+	// Generate a patch from the written fields
+	patch, err := trackable.Update()
+	if err != nil {
+		t.Fatalf("Failed to update trackable struct: %v", err)
+	}
+
+	// Only the changed written fields are in the patch
+	var keys []string
+	for _, op := range patch.Operations() {
+		if ins, ok := op.(*crdtpatch.InsOperation); ok {
+			for key := range ins.Value.(map[string]interface{}) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"age", "city", "name"}) {
+		t.Errorf("Expected patch for age, city and name, got %v", keys)
+	}
+
+	// Apply the patch to the document
+	if err := trackable.GetTracker().ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	var result TestPerson
+	if err := trackable.GetTracker().ToStruct(&result); err != nil {
+		t.Fatalf("Failed to convert view to struct: %v", err)
+	}
+	if result.Name != "Jane Doe" || result.Age != 31 || result.Address.City != "Seoul" {
+		t.Errorf("Unexpected document state: %+v", result)
+	}
+
+	// Writes are cleared after the update
+	patch, err = trackable.Update()
+	if err != nil {
+		t.Fatalf("Failed to update trackable struct: %v", err)
+	}
+	if len(patch.Operations()) != 0 {
+		t.Errorf("Expected empty patch, got %d operations", len(patch.Operations()))
+	}
+}
+
+// TestRaidPlayer is a nested test struct for the tracker.
+type TestRaidPlayer struct {
+	Name string `crdt:"name" json:"name"`
+	HP   int    `crdt:"hp" json:"hp"`
+}
+
+// TestRaid is a test struct with nested structs, maps and slices of structs.
+type TestRaid struct {
+	Name  string `crdt:"name" json:"name"`
+	Stats struct {
+		Boss struct {
+			HP    int `crdt:"hp" json:"hp"`
+			Phase int `crdt:"phase" json:"phase"`
+		} `crdt:"boss" json:"boss"`
+	} `crdt:"stats" json:"stats"`
+	Loot    map[string]int   `crdt:"loot" json:"loot"`
+	Players []TestRaidPlayer `crdt:"players" json:"players"`
+}
+
+// TestTracker_NestedPaths tests that patches target the nested objects holding changed fields.
+func TestTracker_NestedPaths(t *testing.T) {
+	// Initialize a document with a raid
+	sid := common.NewSessionID()
+	doc := crdt.NewDocument(sid)
+	tracker := NewTracker(doc, sid)
+
+	raid := &TestRaid{
+		Name:    "Dragon",
+		Loot:    map[string]int{"gold": 10},
+		Players: []TestRaidPlayer{{Name: "alice", HP: 100}, {Name: "bob", HP: 80}},
+	}
+	raid.Stats.Boss.HP = 1000
+	if err := tracker.InitializeDocument(raid); err != nil {
+		t.Fatalf("Failed to initialize document: %v", err)
+	}
+	rootID := tracker.rootObject().ID()
+
+	// Change fields of a nested struct, a map and an element of a slice of structs
+	raid.Stats.Boss.HP = 900
+	raid.Loot["gem"] = 1
+	raid.Players[1].HP = 60
+	patch, err := tracker.Update(raid)
+	if err != nil {
+		t.Fatalf("Failed to update tracker: %v", err)
+	}
+
+	// Each change inserts a single field into a nested object
+	var keys []string
+	for _, op := range patch.Operations() {
+		ins, ok := op.(*crdtpatch.InsOperation)
+		if !ok {
+			t.Fatalf("Expected only insert operations, got %T", op)
+		}
+		for key := range ins.Value.(map[string]interface{}) {
+			keys = append(keys, key)
+			if ins.TargetID == rootID {
+				t.Errorf("Expected %s to target a nested object, got the root object", key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"gem", "hp", "hp"}) {
+		t.Errorf("Expected patch for gem and two hp fields, got %v", keys)
+	}
+
+	// Apply the patch to the document
+	if err := tracker.ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	var result TestRaid
+	if err := tracker.ToStruct(&result); err != nil {
+		t.Fatalf("Failed to convert view to struct: %v", err)
+	}
+	if !reflect.DeepEqual(&result, raid) {
+		t.Errorf("Expected %+v, got %+v", raid, result)
+	}
+
+	// Growing a slice of structs replaces the slice, after which its elements are tracked again
+	raid.Players = append(raid.Players, TestRaidPlayer{Name: "carol", HP: 50})
+	delete(raid.Loot, "gold")
+	patch, err = tracker.Update(raid)
+	if err != nil {
+		t.Fatalf("Failed to update tracker: %v", err)
+	}
+	if err := tracker.ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+
+	raid.Players[2].HP = 40
+	patch, err = tracker.Update(raid)
+	if err != nil {
+		t.Fatalf("Failed to update tracker: %v", err)
+	}
+	ops := patch.Operations()
+	if len(ops) != 1 {
+		t.Fatalf("Expected a single operation, got %d", len(ops))
+	}
+	if ins, ok := ops[0].(*crdtpatch.InsOperation); !ok || ins.TargetID == rootID {
+		t.Errorf("Expected an insert into the player object, got %v", ops[0])
+	}
+	if err := tracker.ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+
+	result = TestRaid{}
+	if err := tracker.ToStruct(&result); err != nil {
+		t.Fatalf("Failed to convert view to struct: %v", err)
+	}
+	if !reflect.DeepEqual(&result, raid) {
+		t.Errorf("Expected %+v, got %+v", raid, result)
+	}
+}
+
+// TestTracker_OnChange tests that change handlers are called for fields changed by incoming patches.
+func TestTracker_OnChange(t *testing.T) {
+	// Initialize a document with a raid
+	sid := common.NewSessionID()
+	doc := crdt.NewDocument(sid)
+	local := NewTracker(doc, sid)
+
+	raid := &TestRaid{
+		Name:    "Dragon",
+		Players: []TestRaidPlayer{{Name: "alice", HP: 100}},
+	}
+	if err := local.InitializeDocument(raid); err != nil {
+		t.Fatalf("Failed to initialize document: %v", err)
+	}
+
+	// A tracker of another session receives the patches of the local tracker
+	remote := NewTracker(doc, common.NewSessionID())
+	changes := make(map[string][2]interface{})
+	remote.OnChange(func(path string, old, new interface{}) {
+		changes[path] = [2]interface{}{old, new}
+	})
+	local.OnChange(func(path string, old, new interface{}) {
+		t.Errorf("Unexpected change of %s for a local patch", path)
+	})
+
+	raid.Name = "Hydra"
+	raid.Players[0].HP = 70
+	patch, err := local.Update(raid)
+	if err != nil {
+		t.Fatalf("Failed to update tracker: %v", err)
+	}
+	if err := local.ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Expected no changes before the remote tracker applies the patch, got %v", changes)
+	}
+
+	// Applying the same patch again in the remote tracker does not change the view
+	if err := remote.ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes for an already applied patch, got %v", changes)
+	}
+
+	// An incoming patch calls the handlers with the changed paths
+	raid.Players[0].HP = 40
+	raid.Loot = map[string]int{"gold": 5}
+	patch, err = local.Update(raid)
+	if err != nil {
+		t.Fatalf("Failed to update tracker: %v", err)
+	}
+	if err := remote.ApplyPatch(patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	expected := map[string][2]interface{}{
+		"players[0].hp": {float64(70), float64(40)},
+		"loot":          {nil, map[string]interface{}{"gold": float64(5)}},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, changes)
+	}
+}
+
 // TestCompareStructs tests the CompareStructs function.
 func TestCompareStructs(t *testing.T) {
 	// Create test persons
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"tictactoe/luvjson/common"
 	"tictactoe/luvjson/crdt"
@@ -140,12 +141,20 @@ func CloneStruct(src, dst interface{}) error {
 }
 
 // TrackableStruct is a struct that can be tracked by the CRDT tracker.
+// Fields written through Set or reported with MarkChanged are recorded, and Update
+// emits a patch containing only those fields instead of diffing the whole struct.
 type TrackableStruct struct {
 	// tracker is the CRDT tracker.
 	tracker *Tracker
 
 	// data is the struct being tracked.
 	data interface{}
+
+	// writes is the list of field paths written since the last update, in write order.
+	writes []string
+
+	// written is the set of field paths in writes.
+	written map[string]bool
 }
 
 // NewTrackableStruct creates a new trackable struct.
@@ -163,12 +172,210 @@ func NewTrackableStruct(data interface{}, sessionID common.SessionID) (*Trackabl
 	return &TrackableStruct{
 		tracker: tracker,
 		data:    data,
+		written: make(map[string]bool),
 	}, nil
 }
 
-// Update updates the tracked struct and returns the CRDT patch.
+// Set sets the field at the given dot-separated JSON path of the tracked struct
+// and records the write.
+func (ts *TrackableStruct) Set(path string, value interface{}) error {
+	field, err := fieldByJSONPath(reflect.ValueOf(ts.data), path)
+	if err != nil {
+		return err
+	}
+
+	// Convert the value to the field type
+	newValue := reflect.ValueOf(value)
+	if !newValue.IsValid() {
+		newValue = reflect.Zero(field.Type())
+	} else if !newValue.Type().AssignableTo(field.Type()) {
+		// Allow numbers decoded from JSON as float64 to be assigned to other numeric fields
+		if !isNumericKind(newValue.Kind()) || !isNumericKind(field.Kind()) {
+			return fmt.Errorf("cannot assign %v to field %s of type %v", newValue.Type(), path, field.Type())
+		}
+		newValue = newValue.Convert(field.Type())
+	}
+	field.Set(newValue)
+
+	ts.recordWrite(path)
+	return nil
+}
+
+// MarkChanged records writes to the fields at the given dot-separated JSON paths.
+// Use it after assigning the fields of the tracked struct directly.
+func (ts *TrackableStruct) MarkChanged(paths ...string) error {
+	for _, path := range paths {
+		if _, err := fieldByJSONPath(reflect.ValueOf(ts.data), path); err != nil {
+			return err
+		}
+		ts.recordWrite(path)
+	}
+	return nil
+}
+
+// recordWrite records a write to the field at the given path.
+func (ts *TrackableStruct) recordWrite(path string) {
+	if !ts.written[path] {
+		ts.written[path] = true
+		ts.writes = append(ts.writes, path)
+	}
+}
+
+// Update returns the CRDT patch for the changes to the tracked struct.
+// If fields were written through Set or MarkChanged, the patch contains only the
+// written fields whose values changed. Otherwise the whole struct is compared with
+// its previous state.
 func (ts *TrackableStruct) Update() (*crdtpatch.Patch, error) {
-	return ts.tracker.Update(ts.data)
+	if len(ts.writes) == 0 {
+		return ts.tracker.Update(ts.data)
+	}
+
+	changes, err := ts.writtenChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the current state and clear the writes
+	if err := ts.tracker.Track(ts.data); err != nil {
+		return nil, err
+	}
+	ts.writes = nil
+	ts.written = make(map[string]bool)
+
+	if len(changes) == 0 {
+		return crdtpatch.NewPatch(common.LogicalTimestamp{}), nil
+	}
+	patch, err := ts.tracker.GeneratePatch(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate patch: %w", err)
+	}
+	return patch, nil
+}
+
+// writtenChanges compares the written fields with their previous state.
+func (ts *TrackableStruct) writtenChanges() ([]Change, error) {
+	// Get the previous state
+	prevMap := make(map[string]interface{})
+	if state, ok := ts.tracker.GetPreviousState(reflect.TypeOf(ts.data).Elem()); ok {
+		if err := json.Unmarshal(state.Data, &prevMap); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal previous state: %w", err)
+		}
+	}
+
+	changes := make([]Change, 0, len(ts.writes))
+	for _, path := range ts.writes {
+		field, err := fieldByJSONPath(reflect.ValueOf(ts.data), path)
+		if err != nil {
+			return nil, err
+		}
+		newValue, err := toJSONValue(field.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %s: %w", path, err)
+		}
+
+		oldValue, exists := valueAtPath(prevMap, strings.Split(path, "."))
+		switch {
+		case !exists:
+			changes = append(changes, Change{Path: path, Type: ChangeTypeCreate, NewValue: newValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, Change{Path: path, Type: ChangeTypeUpdate, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	return changes, nil
+}
+
+// fieldByJSONPath returns the settable struct field at the given dot-separated JSON path.
+// The data parameter must be a pointer to a struct.
+func fieldByJSONPath(data reflect.Value, path string) (reflect.Value, error) {
+	value := data
+	for _, name := range strings.Split(path, ".") {
+		// Dereference pointers
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, fmt.Errorf("nil pointer on path %s", path)
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("path %s leads to a non-struct value", path)
+		}
+
+		index, ok := jsonFieldIndex(value.Type(), name)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("field %s not found on path %s", name, path)
+		}
+		value = value.Field(index)
+	}
+
+	if !value.CanSet() {
+		return reflect.Value{}, fmt.Errorf("field on path %s cannot be set", path)
+	}
+	return value, nil
+}
+
+// jsonFieldIndex returns the index of the exported field encoded with the given JSON name.
+func jsonFieldIndex(structType reflect.Type, name string) (int, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if jsonFieldName(field) == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// jsonFieldName returns the JSON name of a struct field, or "-" if the field is not encoded.
+func jsonFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "-"
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// isNumericKind reports whether the kind is an integer or floating-point kind.
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// toJSONValue converts a value to its JSON representation as decoded into an interface{},
+// so that it compares equal to values in a document view.
+func toJSONValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// valueAtPath returns the value at the given path of a decoded JSON object.
+func valueAtPath(m map[string]interface{}, path []string) (interface{}, bool) {
+	value, exists := m[path[0]]
+	if !exists || len(path) == 1 {
+		return value, exists
+	}
+	subMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return valueAtPath(subMap, path[1:])
 }
 
 // GetData returns the tracked struct.
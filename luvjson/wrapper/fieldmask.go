@@ -0,0 +1,274 @@
+package wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"tictactoe/luvjson/crdtpatch"
+)
+
+// FieldMask lists the fields applied by UpdateFields, like a gRPC field mask.
+// Each path is a dot-separated list of field names as they appear in the document,
+// e.g. "name" or "address.city".
+type FieldMask []string
+
+// UpdateFields applies the fields of a struct listed in the mask to the CRDT document,
+// leaving the other fields of the document unchanged.
+// The data parameter must be a pointer to a struct; only the masked fields need to be set.
+// Slice fields tagged with an array merge option are merged with the current array
+// instead of replacing it.
+// Returns the CRDT patch that was applied to the document, or nil if nothing changed.
+func (cd *CRDTDocument) UpdateFields(data interface{}, mask FieldMask) ([]byte, error) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	// Check if data is a pointer
+	dataValue := reflect.ValueOf(data)
+	if dataValue.Kind() != reflect.Ptr || dataValue.IsNil() {
+		return nil, fmt.Errorf("data must be a non-nil pointer to a struct")
+	}
+
+	// Check if data points to a struct
+	dataElem := dataValue.Elem()
+	if dataElem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("data must be a pointer to a struct, got pointer to %v", dataElem.Kind())
+	}
+
+	// Get the current view
+	view, err := cd.doc.View()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document view: %w", err)
+	}
+	viewMap, ok := view.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document view is not a map")
+	}
+
+	// Compute the new values of the top-level fields
+	updates := make(map[string]interface{})
+	for _, path := range mask {
+		parts := strings.Split(path, ".")
+
+		// Get the masked field
+		field, tags, err := maskedField(dataElem, parts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field mask path %s: %w", path, err)
+		}
+		mapValue, err := toMapValue(field, tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %s: %w", path, err)
+		}
+		value, err := toJSONValue(mapValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %s: %w", path, err)
+		}
+
+		// Merge tagged slices with the current array
+		if tags.ArrayMerge != ArrayMergeReplace {
+			current, _ := valueAtPath(viewMap, parts)
+			value = mergeArrays(current, value, tags)
+		}
+
+		// Set the value in the top-level field
+		if len(parts) == 1 {
+			updates[parts[0]] = value
+			continue
+		}
+		top, ok := updates[parts[0]].(map[string]interface{})
+		if !ok {
+			// Copy the current value, which may be shared with the document
+			current, err := toJSONValue(viewMap[parts[0]])
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy field %s: %w", parts[0], err)
+			}
+			top, ok = current.(map[string]interface{})
+			if !ok {
+				top = make(map[string]interface{})
+			}
+		}
+		setAtPath(top, parts[1:], value)
+		updates[parts[0]] = top
+	}
+
+	// Create a CRDT patch for the changed fields
+	keys := make([]string, 0, len(updates))
+	for key, value := range updates {
+		if current, exists := viewMap[key]; !exists || !reflect.DeepEqual(current, value) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	sort.Strings(keys)
+
+	patchID := cd.doc.NextTimestamp()
+	p := crdtpatch.NewPatch(patchID)
+	for _, key := range keys {
+		insOp := &crdtpatch.InsOperation{
+			ID:       cd.doc.NextTimestamp(),
+			TargetID: cd.rootID,
+			Value: map[string]interface{}{
+				key: updates[key],
+			},
+		}
+		p.AddOperation(insOp)
+	}
+
+	// Apply the patch
+	if err := p.Apply(cd.doc); err != nil {
+		return nil, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	// Convert the patch to JSON
+	patchData, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	return patchData, nil
+}
+
+// maskedField returns the struct field at the given path and its tags.
+func maskedField(value reflect.Value, parts []string) (reflect.Value, StructTags, error) {
+	var tags StructTags
+	for i, part := range parts {
+		// Dereference pointers to nested structs
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, tags, fmt.Errorf("field %s is nil", strings.Join(parts[:i], "."))
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, tags, fmt.Errorf("field %s is not a struct", strings.Join(parts[:i], "."))
+		}
+
+		// Find the field with the document field name
+		field, fieldTags, ok := findField(value, part)
+		if !ok {
+			return reflect.Value{}, tags, fmt.Errorf("unknown field %s", part)
+		}
+		tags = fieldTags
+		if tags.Ignore {
+			return reflect.Value{}, tags, fmt.Errorf("field %s is ignored", part)
+		}
+		if tags.ReadOnly {
+			return reflect.Value{}, tags, fmt.Errorf("field %s is read-only", part)
+		}
+		value = field
+	}
+	return value, tags, nil
+}
+
+// toJSONValue converts a value to its JSON representation as maps, slices and values.
+func toJSONValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// valueAtPath returns the value at the given path of a map.
+func valueAtPath(m map[string]interface{}, parts []string) (interface{}, bool) {
+	var current interface{} = m
+	for _, part := range parts {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = currentMap[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setAtPath sets the value at the given path of a map, creating nested maps as needed.
+func setAtPath(m map[string]interface{}, parts []string, value interface{}) {
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// mergeArrays merges the elements of a slice field into the current array
+// according to the array merge option of the field.
+func mergeArrays(current, value interface{}, tags StructTags) interface{} {
+	elems, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+	currentElems, _ := current.([]interface{})
+	result := append([]interface{}{}, currentElems...)
+
+	switch tags.ArrayMerge {
+	case ArrayMergeAppend:
+		result = append(result, elems...)
+
+	case ArrayMergeUnion:
+		for _, elem := range elems {
+			if !containsElement(result, elem) {
+				result = append(result, elem)
+			}
+		}
+
+	case ArrayMergeKey:
+		for _, elem := range elems {
+			index := indexOfKey(result, tags.MergeKey, elem)
+			if index < 0 {
+				result = append(result, elem)
+				continue
+			}
+			result[index] = elem
+		}
+
+	default:
+		return value
+	}
+
+	return result
+}
+
+// containsElement reports whether the array contains the element.
+func containsElement(elems []interface{}, elem interface{}) bool {
+	for _, e := range elems {
+		if reflect.DeepEqual(e, elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexOfKey returns the index of the object in the array whose key field equals
+// the key field of the element, or -1 if there is none.
+func indexOfKey(elems []interface{}, key string, elem interface{}) int {
+	elemMap, ok := elem.(map[string]interface{})
+	if !ok {
+		return -1
+	}
+	keyValue, ok := elemMap[key]
+	if !ok {
+		return -1
+	}
+	for i, e := range elems {
+		if eMap, ok := e.(map[string]interface{}); ok && reflect.DeepEqual(eMap[key], keyValue) {
+			return i
+		}
+	}
+	return -1
+}
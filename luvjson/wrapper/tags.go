@@ -1,9 +1,12 @@
 package wrapper
 
 import (
+	"encoding"
+	"encoding/base64"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // StructTags represents the tags for a struct field.
@@ -16,8 +19,36 @@ type StructTags struct {
 	
 	// ReadOnly indicates whether the field is read-only.
 	ReadOnly bool
+	
+	// ArrayMerge is how UpdateFields merges a slice field with the current array.
+	ArrayMerge ArrayMergeMode
+	
+	// MergeKey is the field name identifying the elements merged with ArrayMergeKey.
+	MergeKey string
+	
+	// TimeLayout is the layout of time.Time values of the field.
+	// An empty layout uses DefaultTimeLayout.
+	TimeLayout string
 }
 
+// ArrayMergeMode represents how a slice field is merged with the current array.
+type ArrayMergeMode string
+
+const (
+	// ArrayMergeReplace replaces the current array with the slice.
+	ArrayMergeReplace ArrayMergeMode = ""
+	
+	// ArrayMergeAppend appends the elements of the slice to the current array.
+	ArrayMergeAppend ArrayMergeMode = "append"
+	
+	// ArrayMergeUnion appends the elements of the slice that are not in the current array.
+	ArrayMergeUnion ArrayMergeMode = "union"
+	
+	// ArrayMergeKey replaces the elements of the current array with the same MergeKey value
+	// as an element of the slice, and appends the other elements.
+	ArrayMergeKey ArrayMergeMode = "mergekey"
+)
+
 // ParseStructTags parses the tags for a struct field.
 func ParseStructTags(field reflect.StructField) StructTags {
 	tags := StructTags{
@@ -31,7 +62,9 @@ func ParseStructTags(field reflect.StructField) StructTags {
 		jsonTag := field.Tag.Get("json")
 		if jsonTag != "" {
 			parts := strings.Split(jsonTag, ",")
-			if parts[0] != "" {
+			if parts[0] == "-" && len(parts) == 1 {
+				tags.Ignore = true
+			} else if parts[0] != "" {
 				tags.FieldName = parts[0]
 			}
 			for _, part := range parts[1:] {
@@ -55,13 +88,41 @@ func ParseStructTags(field reflect.StructField) StructTags {
 			tags.Ignore = true
 		case "readonly":
 			tags.ReadOnly = true
+		case "append":
+			tags.ArrayMerge = ArrayMergeAppend
+		case "union":
+			tags.ArrayMerge = ArrayMergeUnion
+		default:
+			if key, ok := strings.CutPrefix(part, "mergekey="); ok {
+				tags.ArrayMerge = ArrayMergeKey
+				tags.MergeKey = key
+			} else if layout, ok := strings.CutPrefix(part, "layout="); ok {
+				tags.TimeLayout = layout
+			}
 		}
 	}
 	
 	return tags
 }
 
+// timeLayout returns the layout of time.Time values of the field.
+func (t StructTags) timeLayout() string {
+	if t.TimeLayout != "" {
+		return t.TimeLayout
+	}
+	return DefaultTimeLayout
+}
+
+// DefaultTimeLayout is the layout of time.Time fields without a layout tag option.
+var DefaultTimeLayout = time.RFC3339Nano
+
+// timeType is the type of time.Time fields.
+var timeType = reflect.TypeOf(time.Time{})
+
 // StructToMap converts a struct to a map, respecting CRDT tags.
+// The fields of embedded structs are promoted to the map like encoding/json does,
+// time.Time fields are formatted with their layout, and encoding.TextMarshaler
+// values become strings.
 func StructToMap(data interface{}) (map[string]interface{}, error) {
 	// Get the value and type of the struct
 	value := reflect.ValueOf(data)
@@ -75,12 +136,26 @@ func StructToMap(data interface{}) (map[string]interface{}, error) {
 	
 	// Create a map to hold the result
 	result := make(map[string]interface{})
+	if err := structToMap(value, result); err != nil {
+		return nil, err
+	}
+	
+	return result, nil
+}
+
+// structToMap adds the fields of a struct value to the map.
+func structToMap(value reflect.Value, result map[string]interface{}) error {
+	// Fields of embedded structs do not replace fields of the outer struct
+	promoted := make(map[string]interface{})
 	
 	// Iterate over the struct fields
 	typ := value.Type()
 	for i := 0; i < value.NumField(); i++ {
 		field := value.Field(i)
 		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
 		
 		// Parse the tags
 		tags := ParseStructTags(fieldType)
@@ -88,43 +163,117 @@ func StructToMap(data interface{}) (map[string]interface{}, error) {
 			continue
 		}
 		
-		// Get the field value
-		fieldValue := field.Interface()
+		// Promote the fields of embedded structs
+		if isEmbedded(fieldType) {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+			if err := structToMap(field, promoted); err != nil {
+				return err
+			}
+			continue
+		}
 		
-		// If the field is a struct, recursively convert it
-		if field.Kind() == reflect.Struct {
-			nestedMap, err := StructToMap(fieldValue)
+		// Convert the field value
+		fieldValue, err := toMapValue(field, tags)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", tags.FieldName, err)
+		}
+		result[tags.FieldName] = fieldValue
+	}
+	
+	for key, fieldValue := range promoted {
+		if _, exists := result[key]; !exists {
+			result[key] = fieldValue
+		}
+	}
+	
+	return nil
+}
+
+// toMapValue converts a field value to its map representation.
+func toMapValue(value reflect.Value, tags StructTags) (interface{}, error) {
+	// Dereference pointers
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	
+	// Format times with the layout of the field
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).Format(tags.timeLayout()), nil
+	}
+	
+	// Use the text representation of text marshalers
+	if marshaler, ok := textMarshaler(value); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal text: %w", err)
+		}
+		return string(text), nil
+	}
+	
+	switch value.Kind() {
+	case reflect.Struct:
+		// If the value is a struct, recursively convert it
+		nestedMap := make(map[string]interface{})
+		if err := structToMap(value, nestedMap); err != nil {
+			return nil, err
+		}
+		return nestedMap, nil
+	
+	case reflect.Slice, reflect.Array:
+		// Byte slices are values like in encoding/json
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface(), nil
+		}
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil, nil
+		}
+		
+		// If the value is a slice or array, convert each element
+		sliceResult := make([]interface{}, value.Len())
+		for i := range sliceResult {
+			elem, err := toMapValue(value.Index(i), tags)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("element %d: %w", i, err)
 			}
-			result[tags.FieldName] = nestedMap
-		} else if field.Kind() == reflect.Slice || field.Kind() == reflect.Array {
-			// If the field is a slice or array, convert each element
-			sliceLen := field.Len()
-			sliceResult := make([]interface{}, sliceLen)
-			for j := 0; j < sliceLen; j++ {
-				elem := field.Index(j)
-				if elem.Kind() == reflect.Struct {
-					nestedMap, err := StructToMap(elem.Interface())
-					if err != nil {
-						return nil, err
-					}
-					sliceResult[j] = nestedMap
-				} else {
-					sliceResult[j] = elem.Interface()
-				}
+			sliceResult[i] = elem
+		}
+		return sliceResult, nil
+	
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String || value.IsNil() {
+			return value.Interface(), nil
+		}
+		
+		// If the value is a map with string keys, convert each entry
+		mapResult := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			elem, err := toMapValue(iter.Value(), tags)
+			if err != nil {
+				return nil, fmt.Errorf("entry %s: %w", iter.Key().String(), err)
 			}
-			result[tags.FieldName] = sliceResult
-		} else {
-			// Otherwise, just add the field value
-			result[tags.FieldName] = fieldValue
+			mapResult[iter.Key().String()] = elem
 		}
-	}
+		return mapResult, nil
 	
-	return result, nil
+	default:
+		// Otherwise, just use the value
+		return value.Interface(), nil
+	}
 }
 
 // MapToStruct converts a map to a struct, respecting CRDT tags.
+// It is the inverse of StructToMap: the fields of embedded structs are read from the
+// map itself, time.Time fields are parsed with their layout, and encoding.TextUnmarshaler
+// values are read from strings.
 func MapToStruct(data map[string]interface{}, result interface{}) error {
 	// Get the value and type of the struct
 	value := reflect.ValueOf(result)
@@ -137,11 +286,19 @@ func MapToStruct(data map[string]interface{}, result interface{}) error {
 		return fmt.Errorf("result must be a pointer to a struct")
 	}
 	
+	return mapToStruct(data, value)
+}
+
+// mapToStruct sets the fields of a struct value from the map.
+func mapToStruct(data map[string]interface{}, value reflect.Value) error {
 	// Iterate over the struct fields
 	typ := value.Type()
 	for i := 0; i < value.NumField(); i++ {
 		field := value.Field(i)
 		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
 		
 		// Parse the tags
 		tags := ParseStructTags(fieldType)
@@ -149,6 +306,20 @@ func MapToStruct(data map[string]interface{}, result interface{}) error {
 			continue
 		}
 		
+		// Set the fields of embedded structs from the same map
+		if isEmbedded(fieldType) {
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			if err := mapToStruct(data, field); err != nil {
+				return err
+			}
+			continue
+		}
+		
 		// Get the field value from the map
 		fieldValue, ok := data[tags.FieldName]
 		if !ok {
@@ -156,67 +327,211 @@ func MapToStruct(data map[string]interface{}, result interface{}) error {
 		}
 		
 		// Set the field value
-		fieldValueReflect := reflect.ValueOf(fieldValue)
-		if field.Kind() == reflect.Struct && fieldValueReflect.Kind() == reflect.Map {
-			// If the field is a struct and the value is a map, recursively convert it
-			mapValue, ok := fieldValue.(map[string]interface{})
-			if !ok {
-				return fmt.Errorf("field %s: expected map[string]interface{}, got %T", tags.FieldName, fieldValue)
-			}
-			
-			// Create a new instance of the struct
-			newStruct := reflect.New(field.Type()).Elem()
-			
-			// Recursively convert the map to the struct
-			if err := MapToStruct(mapValue, newStruct.Addr().Interface()); err != nil {
-				return err
+		if err := setMapValue(field, fieldValue, tags); err != nil {
+			return fmt.Errorf("field %s: %w", tags.FieldName, err)
+		}
+	}
+	
+	return nil
+}
+
+// setMapValue sets a field value from its map representation.
+func setMapValue(field reflect.Value, value interface{}, tags StructTags) error {
+	// Reset fields without a value
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	
+	// Allocate pointers
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setMapValue(elem.Elem(), value, tags); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	
+	// Parse times with the layout of the field
+	if field.Type() == timeType {
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected time string, got %T", value)
+		}
+		parsed, err := time.Parse(tags.timeLayout(), text)
+		if err != nil {
+			return fmt.Errorf("failed to parse time: %w", err)
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+	
+	// Read text unmarshalers from their text representation
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if text, ok := value.(string); ok {
+			if err := unmarshaler.UnmarshalText([]byte(text)); err != nil {
+				return fmt.Errorf("failed to unmarshal text: %w", err)
 			}
-			
-			// Set the field value
-			field.Set(newStruct)
-		} else if (field.Kind() == reflect.Slice || field.Kind() == reflect.Array) && fieldValueReflect.Kind() == reflect.Slice {
-			// If the field is a slice or array and the value is a slice, convert each element
-			sliceValue, ok := fieldValue.([]interface{})
-			if !ok {
-				return fmt.Errorf("field %s: expected []interface{}, got %T", tags.FieldName, fieldValue)
+			return nil
+		}
+	}
+	
+	valueReflect := reflect.ValueOf(value)
+	switch {
+	case field.Kind() == reflect.Struct && valueReflect.Kind() == reflect.Map:
+		// If the field is a struct and the value is a map, recursively convert it
+		mapValue, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected map[string]interface{}, got %T", value)
+		}
+		newStruct := reflect.New(field.Type()).Elem()
+		if err := mapToStruct(mapValue, newStruct); err != nil {
+			return err
+		}
+		field.Set(newStruct)
+	
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 && valueReflect.Kind() == reflect.String:
+		// Byte slices may be base64 strings after a JSON round trip
+		data, err := base64.StdEncoding.DecodeString(value.(string))
+		if err != nil {
+			return fmt.Errorf("failed to decode bytes: %w", err)
+		}
+		field.SetBytes(data)
+	
+	case (field.Kind() == reflect.Slice || field.Kind() == reflect.Array) && valueReflect.Kind() == reflect.Slice &&
+		valueReflect.Type().Elem().Kind() == reflect.Interface:
+		// If the field is a slice or array and the value is a slice, convert each element
+		sliceValue := value.([]interface{})
+		newSlice := reflect.New(field.Type()).Elem()
+		if field.Kind() == reflect.Slice {
+			newSlice = reflect.MakeSlice(field.Type(), len(sliceValue), len(sliceValue))
+		} else if len(sliceValue) > field.Len() {
+			return fmt.Errorf("expected at most %d elements, got %d", field.Len(), len(sliceValue))
+		}
+		for i, elem := range sliceValue {
+			if err := setMapValue(newSlice.Index(i), elem, tags); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
 			}
-			
-			// Create a new slice
-			newSlice := reflect.MakeSlice(field.Type(), len(sliceValue), len(sliceValue))
-			
-			// Convert each element
-			for j, elem := range sliceValue {
-				elemReflect := reflect.ValueOf(elem)
-				if newSlice.Index(j).Kind() == reflect.Struct && elemReflect.Kind() == reflect.Map {
-					// If the element is a struct and the value is a map, recursively convert it
-					mapValue, ok := elem.(map[string]interface{})
-					if !ok {
-						return fmt.Errorf("field %s[%d]: expected map[string]interface{}, got %T", tags.FieldName, j, elem)
-					}
-					
-					// Create a new instance of the struct
-					newStruct := reflect.New(newSlice.Index(j).Type()).Elem()
-					
-					// Recursively convert the map to the struct
-					if err := MapToStruct(mapValue, newStruct.Addr().Interface()); err != nil {
-						return err
-					}
-					
-					// Set the element value
-					newSlice.Index(j).Set(newStruct)
-				} else {
-					// Otherwise, just set the element value
-					newSlice.Index(j).Set(elemReflect)
-				}
+		}
+		field.Set(newSlice)
+	
+	case field.Kind() == reflect.Map && field.Type().Key().Kind() == reflect.String && valueReflect.Kind() == reflect.Map &&
+		valueReflect.Type().Elem().Kind() == reflect.Interface:
+		// If the field is a map and the value is a map, convert each entry
+		mapValue, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected map[string]interface{}, got %T", value)
+		}
+		newMap := reflect.MakeMapWithSize(field.Type(), len(mapValue))
+		for key, elem := range mapValue {
+			newElem := reflect.New(field.Type().Elem()).Elem()
+			if err := setMapValue(newElem, elem, tags); err != nil {
+				return fmt.Errorf("entry %s: %w", key, err)
 			}
-			
-			// Set the field value
-			field.Set(newSlice)
-		} else {
-			// Otherwise, just set the field value
-			field.Set(fieldValueReflect)
+			newMap.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), newElem)
 		}
+		field.Set(newMap)
+	
+	case valueReflect.Type().AssignableTo(field.Type()):
+		// Otherwise, just set the field value
+		field.Set(valueReflect)
+	
+	case isNumber(valueReflect.Kind()) && isNumber(field.Kind()),
+		valueReflect.Kind() == field.Kind() && valueReflect.Type().ConvertibleTo(field.Type()):
+		// Convert numbers, which are float64 after a JSON round trip, and named types
+		field.Set(valueReflect.Convert(field.Type()))
+	
+	default:
+		return fmt.Errorf("cannot set %s from %T", field.Type(), value)
 	}
 	
 	return nil
 }
+
+// findField returns the field of a struct value with the given document field name,
+// looking through embedded structs.
+func findField(value reflect.Value, name string) (reflect.Value, StructTags, bool) {
+	typ := value.Type()
+	var promoted reflect.Value
+	var promotedTags StructTags
+	for i := 0; i < value.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+		tags := ParseStructTags(fieldType)
+		
+		// Look for the field in embedded structs
+		if isEmbedded(fieldType) && !tags.Ignore {
+			field := value.Field(i)
+			if field.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+			if !promoted.IsValid() {
+				promoted, promotedTags, _ = findField(field, name)
+			}
+			continue
+		}
+		
+		if tags.FieldName == name {
+			return value.Field(i), tags, true
+		}
+	}
+	return promoted, promotedTags, promoted.IsValid()
+}
+
+// isEmbedded reports whether a struct field is an embedded struct whose fields are promoted.
+// Embedded structs with a field name tag, and embedded time.Time or text marshalers,
+// are regular fields.
+func isEmbedded(field reflect.StructField) bool {
+	if !field.Anonymous {
+		return false
+	}
+	typ := field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+	if reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return false
+	}
+	tag := field.Tag.Get("crdt")
+	if tag == "" {
+		tag = field.Tag.Get("json")
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name == ""
+}
+
+// textMarshalerType is the type of encoding.TextMarshaler.
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// textMarshaler returns the value as a text marshaler, using its address for methods
+// with pointer receivers.
+func textMarshaler(value reflect.Value) (encoding.TextMarshaler, bool) {
+	if value.Type().Implements(textMarshalerType) {
+		return value.Interface().(encoding.TextMarshaler), true
+	}
+	if value.CanAddr() && reflect.PointerTo(value.Type()).Implements(textMarshalerType) {
+		return value.Addr().Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+// isNumber reports whether the kind is a numeric kind.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
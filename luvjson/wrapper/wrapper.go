@@ -82,15 +82,17 @@ func (cd *CRDTDocument) FromStruct(data interface{}) error {
 	}
 
 	// Convert struct to map
-	jsonData, err := json.Marshal(data)
+	structMap, err := StructToMap(data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal struct: %w", err)
+		return fmt.Errorf("failed to convert struct to map: %w", err)
 	}
 
-	var dataMap map[string]interface{}
-	if err := json.Unmarshal(jsonData, &dataMap); err != nil {
-		return fmt.Errorf("failed to unmarshal to map: %w", err)
+	// Normalize the values to their JSON representation
+	jsonValue, err := toJSONValue(structMap)
+	if err != nil {
+		return fmt.Errorf("failed to convert struct values: %w", err)
 	}
+	dataMap, _ := jsonValue.(map[string]interface{})
 
 	// Create a patch to set all fields
 	patchID := cd.doc.NextTimestamp()
@@ -150,15 +152,13 @@ func (cd *CRDTDocument) ToStruct(result interface{}) error {
 		return fmt.Errorf("failed to get document view: %w", err)
 	}
 
-	// Convert view to JSON
-	jsonData, err := json.Marshal(view)
-	if err != nil {
-		return fmt.Errorf("failed to marshal view: %w", err)
+	// Convert the view to the struct
+	viewMap, ok := view.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("document view is not a map")
 	}
-
-	// Unmarshal JSON to struct
-	if err := json.Unmarshal(jsonData, result); err != nil {
-		return fmt.Errorf("failed to unmarshal to struct: %w", err)
+	if err := MapToStruct(viewMap, result); err != nil {
+		return fmt.Errorf("failed to convert view to struct: %w", err)
 	}
 
 	return nil
@@ -230,13 +230,13 @@ func (cd *CRDTDocument) UpdateStruct(data interface{}) ([]byte, error) {
 	tempStruct := reflect.New(reflect.TypeOf(data).Elem()).Interface()
 
 	// Convert the current view to the temporary struct
-	currentJSON, err := json.Marshal(currentView)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal current view: %w", err)
+	currentMap, ok := currentView.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("current view is not a map")
 	}
 
-	if err := json.Unmarshal(currentJSON, tempStruct); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal to temporary struct: %w", err)
+	if err := MapToStruct(currentMap, tempStruct); err != nil {
+		return nil, fmt.Errorf("failed to convert current view to temporary struct: %w", err)
 	}
 
 	// Generate JSON Patch (RFC6902)
@@ -1,7 +1,9 @@
 package wrapper
 
 import (
+	"net/netip"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -409,3 +411,137 @@ func TestApplyJSONPatchError(t *testing.T) {
 		assert.NoError(t, err)
 	*/
 }
+
+// TestRaid is a test struct with nested structs and tagged slices.
+type TestRaid struct {
+	Name string `json:"name"`
+	Boss struct {
+		HP    int `json:"hp"`
+		Phase int `json:"phase"`
+	} `json:"boss"`
+	Log     []string         `json:"log" crdt:"log,append"`
+	Tags    []string         `json:"tags" crdt:"tags,union"`
+	Players []TestRaidPlayer `json:"players" crdt:"players,mergekey=id"`
+	Owner   string           `json:"owner" crdt:"owner,readonly"`
+}
+
+// TestRaidPlayer is a test struct for the elements of a tagged slice.
+type TestRaidPlayer struct {
+	ID string `json:"id"`
+	HP int    `json:"hp"`
+}
+
+func TestUpdateFields(t *testing.T) {
+	doc := NewCRDTDocument(common.NewSessionID())
+
+	raid := &TestRaid{
+		Name:    "Dragon",
+		Log:     []string{"start"},
+		Tags:    []string{"hard"},
+		Players: []TestRaidPlayer{{ID: "alice", HP: 100}, {ID: "bob", HP: 80}},
+		Owner:   "alice",
+	}
+	raid.Boss.HP = 1000
+	raid.Boss.Phase = 1
+	assert.NoError(t, doc.FromStruct(raid))
+
+	// Apply a partial struct with only the masked fields set
+	partial := &TestRaid{
+		Name:    "ignored",
+		Log:     []string{"bob hit"},
+		Tags:    []string{"hard", "timed"},
+		Players: []TestRaidPlayer{{ID: "bob", HP: 60}, {ID: "carol", HP: 90}},
+	}
+	partial.Boss.HP = 900
+	patch, err := doc.UpdateFields(partial, FieldMask{"boss.hp", "log", "tags", "players"})
+	assert.NoError(t, err)
+	assert.NotNil(t, patch)
+
+	result := &TestRaid{}
+	assert.NoError(t, doc.ToStruct(result))
+	assert.Equal(t, "Dragon", result.Name)
+	assert.Equal(t, 900, result.Boss.HP)
+	assert.Equal(t, 1, result.Boss.Phase)
+	assert.Equal(t, []string{"start", "bob hit"}, result.Log)
+	assert.Equal(t, []string{"hard", "timed"}, result.Tags)
+	assert.Equal(t, []TestRaidPlayer{{ID: "alice", HP: 100}, {ID: "bob", HP: 60}, {ID: "carol", HP: 90}}, result.Players)
+
+	// Unchanged fields produce no patch
+	patch, err = doc.UpdateFields(partial, FieldMask{"boss.hp", "tags"})
+	assert.NoError(t, err)
+	assert.Nil(t, patch)
+
+	// Invalid masks
+	_, err = doc.UpdateFields(partial, FieldMask{"unknown"})
+	assert.Error(t, err)
+	_, err = doc.UpdateFields(partial, FieldMask{"name.first"})
+	assert.Error(t, err)
+	_, err = doc.UpdateFields(partial, FieldMask{"owner"})
+	assert.Error(t, err)
+	_, err = doc.UpdateFields(*partial, FieldMask{"name"})
+	assert.Error(t, err)
+}
+
+// TestAudit is a test struct embedded in another struct.
+type TestAudit struct {
+	CreatedBy string    `json:"createdBy"`
+	Created   time.Time `crdt:"created,layout=2006-01-02"`
+}
+
+// TestTicket is a test struct with embedded, time and text marshaler fields.
+type TestTicket struct {
+	TestAudit
+	Title   string     `json:"title"`
+	Host    netip.Addr `json:"host"`
+	Updated time.Time  `json:"updated"`
+}
+
+func TestStructMapping(t *testing.T) {
+	doc := NewCRDTDocument(common.NewSessionID())
+
+	ticket := &TestTicket{
+		TestAudit: TestAudit{
+			CreatedBy: "alice",
+			Created:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		Title:   "Boss stuck",
+		Host:    netip.MustParseAddr("10.0.0.1"),
+		Updated: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	assert.NoError(t, doc.FromStruct(ticket))
+
+	// Embedded fields are promoted and values are converted to text
+	view, err := doc.doc.View()
+	assert.NoError(t, err)
+	viewMap := view.(map[string]interface{})
+	assert.Equal(t, "alice", viewMap["createdBy"])
+	assert.Equal(t, "2024-01-02", viewMap["created"])
+	assert.Equal(t, "10.0.0.1", viewMap["host"])
+	assert.Equal(t, "2024-01-02T03:04:05.000000006Z", viewMap["updated"])
+	assert.NotContains(t, viewMap, "TestAudit")
+
+	result := &TestTicket{}
+	assert.NoError(t, doc.ToStruct(result))
+	assert.Equal(t, ticket, result)
+
+	// Changes to these fields are detected
+	updated := *ticket
+	updated.Created = time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+	updated.Host = netip.MustParseAddr("10.0.0.2")
+	patch, err := doc.UpdateStruct(&updated)
+	assert.NoError(t, err)
+	assert.NotNil(t, patch)
+
+	result = &TestTicket{}
+	assert.NoError(t, doc.ToStruct(result))
+	assert.Equal(t, &updated, result)
+
+	// Field masks name the promoted fields
+	updated.CreatedBy = "bob"
+	_, err = doc.UpdateFields(&updated, FieldMask{"createdBy"})
+	assert.NoError(t, err)
+
+	result = &TestTicket{}
+	assert.NoError(t, doc.ToStruct(result))
+	assert.Equal(t, "bob", result.CreatedBy)
+}
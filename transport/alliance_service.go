@@ -0,0 +1,287 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AllianceRole represents what a member is allowed to do in an alliance
+type AllianceRole string
+
+// Alliance role constants
+const (
+	AllianceRoleLeader  AllianceRole = "leader"  // 맹주
+	AllianceRoleOfficer AllianceRole = "officer" // 간부
+	AllianceRoleMember  AllianceRole = "member"  // 연합원
+)
+
+// rank orders roles from the least to the most privileged
+func (r AllianceRole) rank() int {
+	switch r {
+	case AllianceRoleLeader:
+		return 3
+	case AllianceRoleOfficer:
+		return 2
+	case AllianceRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AllianceService manages alliance membership and checks that players act on behalf of their own alliance.
+//
+// Leaders and officers add members; leaders and officers remove members of a lower role;
+// only the leader changes roles, including handing over leadership. Every member can develop
+// the alliance's mines and send out its transports.
+type AllianceService struct {
+	storage nodestorage.Storage[*AllianceMember]
+}
+
+// NewAllianceService creates a new AllianceService
+func NewAllianceService(storage nodestorage.Storage[*AllianceMember]) *AllianceService {
+	return &AllianceService{
+		storage: storage,
+	}
+}
+
+// FoundAlliance makes the player the leader of a new alliance
+func (s *AllianceService) FoundAlliance(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	leaderID primitive.ObjectID,
+	leaderName string,
+) (*AllianceMember, error) {
+	members, err := s.GetMembers(ctx, allianceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alliance members: %w", err)
+	}
+	if len(members) > 0 {
+		return nil, fmt.Errorf("alliance already exists")
+	}
+
+	return s.join(ctx, allianceID, leaderID, leaderName, AllianceRoleLeader)
+}
+
+// AddMember adds a player who is not in an alliance yet. Only leaders and officers can add members.
+func (s *AllianceService) AddMember(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	actorID primitive.ObjectID,
+	playerID primitive.ObjectID,
+	playerName string,
+) (*AllianceMember, error) {
+	if _, err := s.requireRole(ctx, allianceID, actorID, AllianceRoleOfficer); err != nil {
+		return nil, err
+	}
+
+	return s.join(ctx, allianceID, playerID, playerName, AllianceRoleMember)
+}
+
+// RemoveMember removes a player from the alliance.
+// Players can leave on their own, except the leader while other members remain. Otherwise the
+// actor must have a higher role than the player removed.
+func (s *AllianceService) RemoveMember(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	actorID primitive.ObjectID,
+	playerID primitive.ObjectID,
+) error {
+	member, err := s.requireRole(ctx, allianceID, playerID, AllianceRoleMember)
+	if err != nil {
+		return err
+	}
+
+	if actorID == playerID {
+		if member.Role == AllianceRoleLeader {
+			members, err := s.GetMembers(ctx, allianceID)
+			if err != nil {
+				return fmt.Errorf("failed to get alliance members: %w", err)
+			}
+			if len(members) > 1 {
+				return fmt.Errorf("leader must hand over leadership before leaving")
+			}
+		}
+	} else {
+		actor, err := s.requireRole(ctx, allianceID, actorID, AllianceRoleOfficer)
+		if err != nil {
+			return err
+		}
+		if actor.Role.rank() <= member.Role.rank() {
+			return fmt.Errorf("only members of a lower role can be removed")
+		}
+	}
+
+	if err := s.storage.DeleteOne(ctx, member.ID); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// SetMemberRole changes the role of a member. Only the leader can change roles.
+// Making another member the leader hands over leadership: the current leader becomes an officer.
+func (s *AllianceService) SetMemberRole(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	actorID primitive.ObjectID,
+	playerID primitive.ObjectID,
+	role AllianceRole,
+) (*AllianceMember, error) {
+	if role.rank() == 0 {
+		return nil, fmt.Errorf("invalid alliance role: %s", role)
+	}
+	if actorID == playerID {
+		return nil, fmt.Errorf("cannot change your own role")
+	}
+
+	leader, err := s.requireRole(ctx, allianceID, actorID, AllianceRoleLeader)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.requireRole(ctx, allianceID, playerID, AllianceRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != AllianceRoleLeader {
+		member, err = s.updateRole(ctx, allianceID, member.ID, role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to change role: %w", err)
+		}
+		return member, nil
+	}
+
+	// Hand over leadership in one transaction, so the alliance always has exactly one leader
+	err = s.storage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if _, err := s.updateRole(sessCtx, allianceID, leader.ID, AllianceRoleOfficer); err != nil {
+			return err
+		}
+		member, err = s.updateRole(sessCtx, allianceID, member.ID, AllianceRoleLeader)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hand over leadership: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetMember retrieves the membership of a player
+func (s *AllianceService) GetMember(ctx context.Context, playerID primitive.ObjectID) (*AllianceMember, error) {
+	return s.storage.FindOne(ctx, allianceMemberID(playerID))
+}
+
+// GetMembers retrieves all members of an alliance
+func (s *AllianceService) GetMembers(ctx context.Context, allianceID primitive.ObjectID) ([]*AllianceMember, error) {
+	return s.storage.FindMany(ctx, bson.M{"alliance_id": allianceID})
+}
+
+// CheckMember checks that the player is a member of the alliance.
+// A nil service lets every player act on behalf of every alliance.
+func (s *AllianceService) CheckMember(ctx context.Context, allianceID primitive.ObjectID, playerID primitive.ObjectID) error {
+	if s == nil {
+		return nil
+	}
+
+	_, err := s.requireRole(ctx, allianceID, playerID, AllianceRoleMember)
+	return err
+}
+
+// requireRole retrieves the membership of a player who must be a member of the alliance
+// with at least the given role
+func (s *AllianceService) requireRole(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	playerID primitive.ObjectID,
+	role AllianceRole,
+) (*AllianceMember, error) {
+	member, err := s.GetMember(ctx, playerID)
+	if errors.Is(err, nodestorage.ErrNotFound) {
+		return nil, fmt.Errorf("player is not a member of this alliance")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alliance member: %w", err)
+	}
+
+	if member.AllianceID != allianceID {
+		return nil, fmt.Errorf("player is not a member of this alliance")
+	}
+	if member.Role.rank() < role.rank() {
+		return nil, fmt.Errorf("alliance role %s is required", role)
+	}
+
+	return member, nil
+}
+
+// join stores the membership of a player who is not in an alliance yet
+func (s *AllianceService) join(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	playerID primitive.ObjectID,
+	playerName string,
+	role AllianceRole,
+) (*AllianceMember, error) {
+	// Timestamps are stored with millisecond precision
+	now := time.Now().Truncate(time.Millisecond)
+	member := &AllianceMember{
+		ID:          allianceMemberID(playerID),
+		AllianceID:  allianceID,
+		PlayerID:    playerID,
+		PlayerName:  playerName,
+		Role:        role,
+		JoinedAt:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	// The ID is derived from the player ID, so an existing membership is kept
+	stored, err := s.storage.FindOneAndUpsert(ctx, member)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	if !stored.JoinedAt.Equal(member.JoinedAt) {
+		if stored.AllianceID == allianceID {
+			return nil, fmt.Errorf("player is already a member of this alliance")
+		}
+		return nil, fmt.Errorf("player is already a member of another alliance")
+	}
+
+	return stored, nil
+}
+
+// updateRole changes the role of a member who must still be in the alliance
+func (s *AllianceService) updateRole(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	memberID primitive.ObjectID,
+	role AllianceRole,
+) (*AllianceMember, error) {
+	member, _, err := s.storage.FindOneAndUpdate(ctx, memberID, func(m *AllianceMember) (*AllianceMember, error) {
+		if m.AllianceID != allianceID {
+			return nil, fmt.Errorf("player is not a member of this alliance")
+		}
+		m.Role = role
+		m.UpdatedAt = time.Now()
+		return m, nil
+	})
+	return member, err
+}
+
+// allianceMemberID derives the ID of a player's alliance membership
+func allianceMemberID(playerID primitive.ObjectID) primitive.ObjectID {
+	sum := sha1.Sum([]byte(fmt.Sprintf("alliance-member:%s", playerID.Hex())))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
+}
@@ -0,0 +1,332 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// leaderboardPeriods lists the periods every contribution is counted in
+var leaderboardPeriods = []LeaderboardPeriod{
+	LeaderboardPeriodDaily,
+	LeaderboardPeriodWeekly,
+}
+
+// LeaderboardEntry represents a player's position on a leaderboard
+type LeaderboardEntry struct {
+	Rank       int // Players with the same score share a rank
+	PlayerID   primitive.ObjectID
+	PlayerName string
+	Score      float64
+}
+
+// AllianceStatsService aggregates player contributions into daily and weekly alliance leaderboards
+type AllianceStatsService struct {
+	storage          nodestorage.Storage[*AllianceLeaderboard]
+	transportService *TransportService
+	mineService      *MineService
+}
+
+// NewAllianceStatsService creates a new AllianceStatsService
+func NewAllianceStatsService(
+	storage nodestorage.Storage[*AllianceLeaderboard],
+	transportService *TransportService,
+	mineService *MineService,
+) *AllianceStatsService {
+	return &AllianceStatsService{
+		storage:          storage,
+		transportService: transportService,
+		mineService:      mineService,
+	}
+}
+
+// Start starts a background worker that updates the leaderboards from transport and mine
+// development events until ctx is cancelled. Arrivals count as transported gold, successful
+// defenses as defenses won and development progress as development points.
+// Only events emitted while the worker is running are counted.
+func (s *AllianceStatsService) Start(ctx context.Context) {
+	transportEvents := s.transportService.SubscribeEvents(ctx)
+	developmentEvents := s.mineService.SubscribeDevelopment(ctx)
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-transportEvents:
+				if !ok {
+					return
+				}
+				if err := s.applyTransportEvent(ctx, event); err != nil {
+					log.Printf("Failed to update leaderboards for transport %s: %v", event.Transport.ID.Hex(), err)
+				}
+			case event, ok := <-developmentEvents:
+				if !ok {
+					return
+				}
+				if err := s.applyDevelopmentEvent(ctx, event); err != nil {
+					log.Printf("Failed to update leaderboards for mine %s: %v", event.Mine.ID.Hex(), err)
+				}
+			}
+		}
+	}()
+}
+
+// RecordContribution adds to a player's contribution on the daily and weekly leaderboards
+// of the periods containing at
+func (s *AllianceStatsService) RecordContribution(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	playerID primitive.ObjectID,
+	playerName string,
+	metric ContributionMetric,
+	amount float64,
+	at time.Time,
+) error {
+	if err := validateContributionMetric(metric); err != nil {
+		return err
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	for _, period := range leaderboardPeriods {
+		leaderboard, err := s.getOrCreateLeaderboard(ctx, allianceID, period, at)
+		if err != nil {
+			return fmt.Errorf("failed to get %s leaderboard: %w", period, err)
+		}
+
+		_, _, err = s.storage.FindOneAndUpdate(ctx, leaderboard.ID, func(lb *AllianceLeaderboard) (*AllianceLeaderboard, error) {
+			entry := findContribution(lb, playerID)
+			if entry == nil {
+				lb.Entries = append(lb.Entries, PlayerContribution{PlayerID: playerID})
+				entry = &lb.Entries[len(lb.Entries)-1]
+			}
+
+			// Keep the latest known name
+			if playerName != "" {
+				entry.PlayerName = playerName
+			}
+
+			switch metric {
+			case ContributionMetricDevelopmentPoints:
+				entry.DevelopmentPoints += amount
+			case ContributionMetricTransportedGold:
+				entry.TransportedGold += int(amount)
+			case ContributionMetricDefensesWon:
+				entry.DefensesWon += int(amount)
+			}
+
+			lb.UpdatedAt = time.Now()
+			return lb, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update %s leaderboard: %w", period, err)
+		}
+	}
+
+	return nil
+}
+
+// GetLeaderboard retrieves the ranking of an alliance's players for the current period
+func (s *AllianceStatsService) GetLeaderboard(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	period LeaderboardPeriod,
+	metric ContributionMetric,
+) ([]LeaderboardEntry, error) {
+	return s.GetLeaderboardAt(ctx, allianceID, period, metric, time.Now())
+}
+
+// GetLeaderboardAt retrieves the ranking of an alliance's players for the period containing at.
+// Players who contributed nothing to the metric are left out.
+func (s *AllianceStatsService) GetLeaderboardAt(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	period LeaderboardPeriod,
+	metric ContributionMetric,
+	at time.Time,
+) ([]LeaderboardEntry, error) {
+	if err := validateContributionMetric(metric); err != nil {
+		return nil, err
+	}
+
+	start, _, err := periodBounds(period, at)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard, err := s.storage.FindOne(ctx, leaderboardID(allianceID, period, start))
+	if err != nil {
+		if errors.Is(err, nodestorage.ErrNotFound) {
+			return []LeaderboardEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+
+	return rankContributions(leaderboard.Entries, metric), nil
+}
+
+// applyTransportEvent records the contributions a transport event represents
+func (s *AllianceStatsService) applyTransportEvent(ctx context.Context, event TransportEvent) error {
+	t := event.Transport
+
+	switch event.Type {
+	case TransportEventArrived:
+		for _, reward := range t.Rewards {
+			err := s.RecordContribution(ctx, t.AllianceID, reward.PlayerID, reward.PlayerName,
+				ContributionMetricTransportedGold, float64(reward.GoldOre), event.OccurredAt)
+			if err != nil {
+				return err
+			}
+		}
+	case TransportEventRaidResolved:
+		if t.RaidStatus == nil || t.RaidStatus.DefenseResult == nil || !t.RaidStatus.DefenseResult.Successful {
+			return nil
+		}
+		result := t.RaidStatus.DefenseResult
+		return s.RecordContribution(ctx, t.AllianceID, result.DefenderID, result.DefenderName,
+			ContributionMetricDefensesWon, 1, event.OccurredAt)
+	}
+
+	return nil
+}
+
+// applyDevelopmentEvent records the development points a mine development event represents
+func (s *AllianceStatsService) applyDevelopmentEvent(ctx context.Context, event DevelopmentEvent) error {
+	for _, c := range event.Contributions {
+		err := s.RecordContribution(ctx, event.Mine.AllianceID, c.PlayerID, c.PlayerName,
+			ContributionMetricDevelopmentPoints, c.Points, event.OccurredAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getOrCreateLeaderboard retrieves the leaderboard of the period containing at, creating it if needed
+func (s *AllianceStatsService) getOrCreateLeaderboard(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	period LeaderboardPeriod,
+	at time.Time,
+) (*AllianceLeaderboard, error) {
+	start, end, err := periodBounds(period, at)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ID is derived from the alliance and period, so concurrent creations end up with the same document
+	now := time.Now()
+	return s.storage.FindOneAndUpsert(ctx, &AllianceLeaderboard{
+		ID:          leaderboardID(allianceID, period, start),
+		AllianceID:  allianceID,
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Entries:     []PlayerContribution{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	})
+}
+
+// findContribution returns the entry of a player on a leaderboard, or nil if the player has none
+func findContribution(lb *AllianceLeaderboard, playerID primitive.ObjectID) *PlayerContribution {
+	for i := range lb.Entries {
+		if lb.Entries[i].PlayerID == playerID {
+			return &lb.Entries[i]
+		}
+	}
+	return nil
+}
+
+// leaderboardID derives the ID of the leaderboard of an alliance for the period starting at start
+func leaderboardID(allianceID primitive.ObjectID, period LeaderboardPeriod, start time.Time) primitive.ObjectID {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s:%s:%d", allianceID.Hex(), period, start.Unix())))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
+}
+
+// periodBounds returns the start and end of the period containing at.
+// Days start at midnight UTC and weeks on Monday.
+func periodBounds(period LeaderboardPeriod, at time.Time) (time.Time, time.Time, error) {
+	at = at.UTC()
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case LeaderboardPeriodDaily:
+		return day, day.AddDate(0, 0, 1), nil
+	case LeaderboardPeriodWeekly:
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -daysSinceMonday)
+		return start, start.AddDate(0, 0, 7), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown leaderboard period: %s", period)
+	}
+}
+
+// validateContributionMetric checks that a metric is one of the known contribution metrics
+func validateContributionMetric(metric ContributionMetric) error {
+	switch metric {
+	case ContributionMetricDevelopmentPoints, ContributionMetricTransportedGold, ContributionMetricDefensesWon:
+		return nil
+	default:
+		return fmt.Errorf("unknown contribution metric: %s", metric)
+	}
+}
+
+// contributionScore returns a player's score for a metric
+func contributionScore(c PlayerContribution, metric ContributionMetric) float64 {
+	switch metric {
+	case ContributionMetricDevelopmentPoints:
+		return c.DevelopmentPoints
+	case ContributionMetricTransportedGold:
+		return float64(c.TransportedGold)
+	case ContributionMetricDefensesWon:
+		return float64(c.DefensesWon)
+	default:
+		return 0
+	}
+}
+
+// rankContributions ranks the players with a positive score for a metric, highest score first
+func rankContributions(contributions []PlayerContribution, metric ContributionMetric) []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, 0, len(contributions))
+	for _, c := range contributions {
+		score := contributionScore(c, metric)
+		if score <= 0 {
+			continue
+		}
+		entries = append(entries, LeaderboardEntry{
+			PlayerID:   c.PlayerID,
+			PlayerName: c.PlayerName,
+			Score:      score,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].PlayerName < entries[j].PlayerName
+	})
+
+	for i := range entries {
+		if i > 0 && entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CheatReason represents why a request was an impossible state transition
+type CheatReason string
+
+// Cheat reason constants
+const (
+	CheatReasonNegativeGoldOre   CheatReason = "negative_gold_ore"  // 음수 금광석
+	CheatReasonFinishedTransport CheatReason = "finished_transport" // 종료된 이송 참여
+	CheatReasonForeignGeneral    CheatReason = "foreign_general"    // 다른 플레이어의 장수 사용
+)
+
+// CheatAction represents the player request a violation was detected in
+type CheatAction string
+
+// Cheat action constants
+const (
+	CheatActionStartTransport CheatAction = "start_transport" // 이송 시작
+	CheatActionJoinTransport  CheatAction = "join_transport"  // 이송 참여
+	CheatActionAssignGeneral  CheatAction = "assign_general"  // 광산에 장수 배치
+	CheatActionCombat         CheatAction = "combat"          // 약탈 및 방어
+	CheatActionSendTrade      CheatAction = "send_trade"      // 선물 보내기
+)
+
+// Cheat report settings
+const (
+	maxCheatReports = 100 // Maximum reports returned by a query
+)
+
+// ErrCheatDetected is matched by the errors of requests rejected as impossible state transitions
+var ErrCheatDetected = errors.New("impossible state transition")
+
+// CheatViolation is an impossible state transition a player's request asked for.
+// Only a modified client sends such requests, so they are recorded for review besides being rejected.
+// It is the error the request is rejected with; errors.Is(err, ErrCheatDetected) matches it.
+type CheatViolation struct {
+	PlayerID primitive.ObjectID
+	Reason   CheatReason
+	Action   CheatAction
+	TargetID primitive.ObjectID
+	Message  string
+	Details  map[string]string
+}
+
+// Error returns the message the request is rejected with
+func (v *CheatViolation) Error() string {
+	return v.Message
+}
+
+// Is reports whether target is ErrCheatDetected
+func (v *CheatViolation) Is(target error) bool {
+	return target == ErrCheatDetected
+}
+
+// AntiCheatService records the impossible state transitions players ask for.
+//
+// The services validate requests with the detectors below and pass the result to Check before
+// changing any state, so a violation is recorded in the cheat_reports collection whichever
+// service detects it.
+type AntiCheatService struct {
+	storage nodestorage.Storage[*CheatReport]
+}
+
+// NewAntiCheatService creates a new AntiCheatService
+func NewAntiCheatService(storage nodestorage.Storage[*CheatReport]) *AntiCheatService {
+	return &AntiCheatService{
+		storage: storage,
+	}
+}
+
+// Check records a detected violation and returns it as the error to reject the request with.
+// It returns nil when nothing was detected. A nil service rejects violations without recording them.
+func (s *AntiCheatService) Check(ctx context.Context, violation *CheatViolation) error {
+	if violation == nil {
+		return nil
+	}
+	if s == nil {
+		return violation
+	}
+
+	// A failure to record must not let the request through
+	if _, err := s.Record(ctx, violation); err != nil {
+		log.Printf("Failed to record %s by player %s: %v", violation.Reason, violation.PlayerID.Hex(), err)
+	}
+	return violation
+}
+
+// Record stores a report of a violation
+func (s *AntiCheatService) Record(ctx context.Context, violation *CheatViolation) (*CheatReport, error) {
+	now := time.Now()
+	report := &CheatReport{
+		ID:          primitive.NewObjectID(),
+		PlayerID:    violation.PlayerID,
+		Reason:      violation.Reason,
+		Action:      violation.Action,
+		TargetID:    violation.TargetID,
+		Message:     violation.Message,
+		Details:     violation.Details,
+		ReportedAt:  now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	report, err := s.storage.FindOneAndUpsert(ctx, report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record cheat report: %w", err)
+	}
+	return report, nil
+}
+
+// GetPlayerReports retrieves the latest reports of a player, newest first
+func (s *AntiCheatService) GetPlayerReports(ctx context.Context, playerID primitive.ObjectID) ([]*CheatReport, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "reported_at", Value: -1}}).
+		SetLimit(maxCheatReports)
+	return s.storage.FindMany(ctx, bson.M{"player_id": playerID}, opts)
+}
+
+// GetReports retrieves the latest reports for a reason made since the given time, newest first
+func (s *AntiCheatService) GetReports(ctx context.Context, reason CheatReason, since time.Time) ([]*CheatReport, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "reported_at", Value: -1}}).
+		SetLimit(maxCheatReports)
+	return s.storage.FindMany(ctx, bson.M{
+		"reason":      reason,
+		"reported_at": bson.M{"$gte": since},
+	}, opts)
+}
+
+// detectNegativeGoldOre detects a request to move a negative amount of gold ore
+func detectNegativeGoldOre(playerID primitive.ObjectID, action CheatAction, targetID primitive.ObjectID, amount int) *CheatViolation {
+	if amount >= 0 {
+		return nil
+	}
+	return &CheatViolation{
+		PlayerID: playerID,
+		Reason:   CheatReasonNegativeGoldOre,
+		Action:   action,
+		TargetID: targetID,
+		Message:  "gold ore amount cannot be negative",
+		Details:  map[string]string{"amount": strconv.Itoa(amount)},
+	}
+}
+
+// detectFinishedTransport detects a request to join a transport that already arrived or was raided
+func detectFinishedTransport(playerID primitive.ObjectID, transport *Transport) *CheatViolation {
+	if transport.Status != TransportStatusCompleted && transport.Status != TransportStatusRaided {
+		return nil
+	}
+	return &CheatViolation{
+		PlayerID: playerID,
+		Reason:   CheatReasonFinishedTransport,
+		Action:   CheatActionJoinTransport,
+		TargetID: transport.ID,
+		Message:  "transport has already finished",
+		Details:  map[string]string{"status": string(transport.Status)},
+	}
+}
+
+// detectForeignGeneral detects a request to use a general owned by another player
+func detectForeignGeneral(playerID primitive.ObjectID, action CheatAction, general *General) *CheatViolation {
+	if general.PlayerID == playerID {
+		return nil
+	}
+	return &CheatViolation{
+		PlayerID: playerID,
+		Reason:   CheatReasonForeignGeneral,
+		Action:   action,
+		TargetID: general.ID,
+		Message:  fmt.Sprintf("general %s does not belong to this player", general.Name),
+		Details:  map[string]string{"owner_id": general.PlayerID.Hex()},
+	}
+}
@@ -0,0 +1,367 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Balance settings
+const (
+	defaultBalanceReloadInterval = 30 * time.Second // How often the reloader looks for a new version
+)
+
+// DefaultBalanceConfig returns the balance rules used until a version is published
+func DefaultBalanceConfig() *BalanceConfig {
+	return &BalanceConfig{
+		Contribution: ContributionBalance{
+			BaseRate:   10.0 / 60.0, // 10/60 points per hour
+			StarBonus:  0.02,        // 2% per star
+			LevelBonus: 0.0012658,   // 0.12658% per level above 1
+			RarityMultipliers: map[GeneralRarity]float64{
+				GeneralRarityCommon:    0.2,
+				GeneralRarityUncommon:  0.4,
+				GeneralRarityRare:      0.6,
+				GeneralRaritySoldier:   0.6,
+				GeneralRarityEpic:      0.8,
+				GeneralRarityLegendary: 1.0,
+			},
+			DefaultRarityMultiplier: 0.2,
+		},
+		MineLevels: []MineLevelBalance{
+			{Level: MineLevel1, RequiredPoints: 10000, TransportTicketMax: 5},  // 약 1일
+			{Level: MineLevel2, RequiredPoints: 30000, TransportTicketMax: 10}, // 약 3일
+			{Level: MineLevel3, RequiredPoints: 70000, TransportTicketMax: 15}, // 약 7일
+			{Level: MineLevel4, RequiredPoints: 40000, TransportTicketMax: 20},
+			{Level: MineLevel5, RequiredPoints: 50000, TransportTicketMax: 25},
+		},
+		TicketPrice: TicketPriceBalance{
+			BasePrice:     300,
+			PriceIncrease: 100,
+		},
+		Recruitment: defaultRecruitmentBalance(),
+	}
+}
+
+// defaultRecruitmentBalance returns the recruitment odds used until a version configures them
+func defaultRecruitmentBalance() RecruitmentBalance {
+	return RecruitmentBalance{
+		RarityWeights: map[GeneralRarity]int{
+			GeneralRarityCommon:    50, // 50%
+			GeneralRarityUncommon:  25, // 25%
+			GeneralRarityRare:      12, // 12%
+			GeneralRaritySoldier:   8,  // 8%
+			GeneralRarityEpic:      4,  // 4%
+			GeneralRarityLegendary: 1,  // 1%
+		},
+		DuplicateShards: map[GeneralRarity]int{
+			GeneralRarityCommon:    1,
+			GeneralRarityUncommon:  2,
+			GeneralRarityRare:      5,
+			GeneralRaritySoldier:   5,
+			GeneralRarityEpic:      10,
+			GeneralRarityLegendary: 20,
+		},
+		Pool: []RecruitableGeneral{
+			{Name: "Village Guard", Rarity: GeneralRarityCommon},
+			{Name: "Militia Captain", Rarity: GeneralRarityCommon},
+			{Name: "Cavalry Scout", Rarity: GeneralRarityUncommon},
+			{Name: "Archer Captain", Rarity: GeneralRarityUncommon},
+			{Name: "General Wang", Rarity: GeneralRarityRare},
+			{Name: "General Zhao", Rarity: GeneralRarityRare},
+			{Name: "Veteran Spearman", Rarity: GeneralRaritySoldier},
+			{Name: "General Li", Rarity: GeneralRarityEpic},
+			{Name: "General Ma", Rarity: GeneralRarityEpic},
+			{Name: "General Zhang", Rarity: GeneralRarityLegendary},
+			{Name: "General Guan", Rarity: GeneralRarityLegendary},
+		},
+	}
+}
+
+// ContributionRate returns the points per hour a general of the given level, stars and rarity
+// contributes to mine development
+func (bc *BalanceConfig) ContributionRate(level, stars int, rarity GeneralRarity) float64 {
+	c := bc.Contribution
+
+	rarityMultiplier, ok := c.RarityMultipliers[rarity]
+	if !ok {
+		rarityMultiplier = c.DefaultRarityMultiplier
+	}
+
+	starBonus := float64(stars) * c.StarBonus
+	levelBonus := float64(level-1) * c.LevelBonus
+
+	return c.BaseRate * (1 + starBonus + levelBonus) * rarityMultiplier
+}
+
+// MineLevel returns the development settings of a mine level.
+// Levels above the highest configured level use the settings of that level.
+func (bc *BalanceConfig) MineLevel(level MineLevel) (MineLevelBalance, bool) {
+	var found MineLevelBalance
+	ok := false
+	for _, l := range bc.MineLevels {
+		if l.Level <= level && (!ok || l.Level > found.Level) {
+			found = l
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+// PurchasePrice returns the price of a ticket after purchaseCount tickets were purchased today
+func (bc *BalanceConfig) PurchasePrice(purchaseCount int) int {
+	return bc.TicketPrice.BasePrice + purchaseCount*bc.TicketPrice.PriceIncrease
+}
+
+// RecruitmentRules returns the recruitment odds.
+// Versions published before recruitment was configurable use the default odds.
+func (bc *BalanceConfig) RecruitmentRules() RecruitmentBalance {
+	if len(bc.Recruitment.Pool) == 0 {
+		return defaultRecruitmentBalance()
+	}
+	return bc.Recruitment
+}
+
+// Validate checks that the balance rules can be put in effect
+func (bc *BalanceConfig) Validate() error {
+	c := bc.Contribution
+	if c.BaseRate <= 0 {
+		return fmt.Errorf("base contribution rate must be positive")
+	}
+	if c.StarBonus < 0 || c.LevelBonus < 0 {
+		return fmt.Errorf("contribution bonuses cannot be negative")
+	}
+	if c.DefaultRarityMultiplier < 0 {
+		return fmt.Errorf("default rarity multiplier cannot be negative")
+	}
+	for rarity, multiplier := range c.RarityMultipliers {
+		if multiplier < 0 {
+			return fmt.Errorf("rarity multiplier of %s cannot be negative", rarity)
+		}
+	}
+
+	if len(bc.MineLevels) == 0 {
+		return fmt.Errorf("at least one mine level must be configured")
+	}
+	if bc.MineLevels[0].Level != MineLevel1 {
+		return fmt.Errorf("mine levels must start at level %d", MineLevel1)
+	}
+	for i, l := range bc.MineLevels {
+		if i > 0 && l.Level <= bc.MineLevels[i-1].Level {
+			return fmt.Errorf("mine levels must be in increasing order")
+		}
+		if l.RequiredPoints <= 0 {
+			return fmt.Errorf("required points of mine level %d must be positive", l.Level)
+		}
+		if l.TransportTicketMax <= 0 {
+			return fmt.Errorf("transport ticket max of mine level %d must be positive", l.Level)
+		}
+	}
+
+	if bc.TicketPrice.BasePrice < 0 || bc.TicketPrice.PriceIncrease < 0 {
+		return fmt.Errorf("ticket prices cannot be negative")
+	}
+
+	r := bc.Recruitment
+	for rarity, weight := range r.RarityWeights {
+		if weight < 0 {
+			return fmt.Errorf("recruitment weight of %s cannot be negative", rarity)
+		}
+	}
+	for rarity, shards := range r.DuplicateShards {
+		if shards < 0 {
+			return fmt.Errorf("duplicate shards of %s cannot be negative", rarity)
+		}
+	}
+	names := make(map[string]bool, len(r.Pool))
+	for _, g := range r.Pool {
+		if g.Name == "" {
+			return fmt.Errorf("recruitable general name cannot be empty")
+		}
+		if !slices.Contains(generalRarities, g.Rarity) {
+			return fmt.Errorf("recruitable general %s has an unknown rarity %s", g.Name, g.Rarity)
+		}
+		if names[g.Name] {
+			return fmt.Errorf("recruitable general %s is listed more than once", g.Name)
+		}
+		names[g.Name] = true
+	}
+	if len(r.Pool) > 0 && recruitWeight(r) == 0 {
+		return fmt.Errorf("at least one recruitable general must have a positive weight")
+	}
+	return nil
+}
+
+// BalanceService provides the game-balance rules in effect and publishes new versions of them.
+//
+// The rules are kept in memory and reloaded from storage by Reload, so a version published by
+// any server takes effect everywhere once it is reloaded. Versions are never changed after
+// they are published: rolling back publishes an earlier version's rules again as a new version.
+type BalanceService struct {
+	storage nodestorage.Storage[*BalanceConfig]
+
+	mu      sync.RWMutex
+	current *BalanceConfig
+}
+
+// NewBalanceService creates a new BalanceService.
+// Until Reload finds a published version, DefaultBalanceConfig is in effect.
+func NewBalanceService(storage nodestorage.Storage[*BalanceConfig]) *BalanceService {
+	return &BalanceService{
+		storage: storage,
+		current: DefaultBalanceConfig(),
+	}
+}
+
+// Current returns the balance rules in effect. The returned config must not be modified.
+// A nil service returns DefaultBalanceConfig.
+func (s *BalanceService) Current() *BalanceConfig {
+	if s == nil {
+		return DefaultBalanceConfig()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Reload puts the latest published version in effect and returns it
+func (s *BalanceService) Reload(ctx context.Context) (*BalanceConfig, error) {
+	latest, err := s.getLatestVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balance config: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if latest != nil && latest.Version > s.current.Version {
+		log.Printf("Balance config version %d is now in effect", latest.Version)
+		s.current = latest
+	}
+	return s.current, nil
+}
+
+// StartReloader starts a background worker that reloads the balance rules every interval
+// until ctx is cancelled
+func (s *BalanceService) StartReloader(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultBalanceReloadInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.Reload(ctx); err != nil {
+				log.Printf("Failed to reload balance config: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PublishBalanceConfig validates the rules and publishes them as the next version.
+// The new version takes effect on this server right away and on the others when they reload.
+func (s *BalanceService) PublishBalanceConfig(ctx context.Context, config *BalanceConfig, note string) (*BalanceConfig, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid balance config: %w", err)
+	}
+
+	latest, err := s.getLatestVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balance config: %w", err)
+	}
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	// Timestamps are stored with millisecond precision
+	now := time.Now().Truncate(time.Millisecond)
+	published := config.Copy()
+	published.ID = balanceConfigID(version)
+	published.Version = version
+	published.Note = note
+	published.CreatedAt = now
+	published.UpdatedAt = now
+	published.VectorClock = 1 // Set initial version
+
+	// The ID is derived from the version, so only one of two concurrent publishes is stored
+	stored, err := s.storage.FindOneAndUpsert(ctx, published)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish balance config: %w", err)
+	}
+	if !stored.CreatedAt.Equal(published.CreatedAt) {
+		return nil, fmt.Errorf("balance config version %d was published concurrently", version)
+	}
+
+	if _, err := s.Reload(ctx); err != nil {
+		return stored, fmt.Errorf("balance config published but failed to reload: %w", err)
+	}
+
+	return stored, nil
+}
+
+// RollbackBalanceConfig publishes the rules of an earlier version again as the next version
+func (s *BalanceService) RollbackBalanceConfig(ctx context.Context, version int) (*BalanceConfig, error) {
+	config, err := s.GetBalanceConfig(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PublishBalanceConfig(ctx, config, fmt.Sprintf("rollback to version %d", version))
+}
+
+// GetBalanceConfig retrieves a published version of the balance rules
+func (s *BalanceService) GetBalanceConfig(ctx context.Context, version int) (*BalanceConfig, error) {
+	config, err := s.storage.FindOne(ctx, balanceConfigID(version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find balance config version %d: %w", version, err)
+	}
+	return config, nil
+}
+
+// GetBalanceVersions retrieves the most recently published versions, newest first
+func (s *BalanceService) GetBalanceVersions(ctx context.Context, limit int64) ([]*BalanceConfig, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetLimit(limit)
+	return s.storage.FindMany(ctx, bson.M{}, opts)
+}
+
+// getLatestVersion retrieves the highest published version, or nil if none was published
+func (s *BalanceService) getLatestVersion(ctx context.Context) (*BalanceConfig, error) {
+	configs, err := s.GetBalanceVersions(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	return configs[0], nil
+}
+
+// balanceConfigID derives the ID of a balance config version
+func balanceConfigID(version int) primitive.ObjectID {
+	sum := sha1.Sum([]byte(fmt.Sprintf("balance:%d", version)))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
+}
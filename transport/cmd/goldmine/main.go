@@ -151,7 +151,7 @@ func runGoldMineSimulation(ctx context.Context, mineService *transport.MineServi
 	simulateDevelopment(ctx, mineService, mine, generalService)
 
 	// 9. 광산 활성화
-	activateMine(ctx, mineService, mine)
+	activateMine(ctx, mineService, mine, player1ID)
 
 	// 10. 채광 시도
 	mineGold(ctx, mineService, mine)
@@ -539,12 +539,12 @@ func updateAssignedGeneralStats(ctx context.Context, mineService *transport.Mine
 	})
 }
 
-// activateMine 함수는 광산을 활성화합니다.
-func activateMine(ctx context.Context, mineService *transport.MineService, mine *transport.Mine) {
+// activateMine 함수는 연합원인 플레이어가 광산을 활성화합니다.
+func activateMine(ctx context.Context, mineService *transport.MineService, mine *transport.Mine, playerID primitive.ObjectID) {
 	fmt.Println("\n=== 광산 활성화 ===")
 
 	// 광산 활성화
-	mine, err := mineService.ActivateMine(ctx, mine.ID)
+	mine, err := mineService.ActivateMine(ctx, mine.ID, playerID)
 	if err != nil {
 		log.Fatalf("광산 활성화 실패: %v", err)
 	}
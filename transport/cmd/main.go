@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
+	"net"
 	"nodestorage/v2"
 	"os"
 	"os/signal"
@@ -14,8 +16,10 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
 
 	"nodestorage/v2/cache"
+	"tictactoe/luvjson/crdtpubsub"
 	"tictactoe/transport"
 )
 
@@ -24,6 +28,13 @@ func main() {
 	mongoURI := flag.String("mongo-uri", "mongodb://localhost:27017", "MongoDB connection URI")
 	dbName := flag.String("db-name", "transport_db", "Database name")
 	demoMode := flag.Bool("demo", false, "Run in demo mode with sample data")
+	schedulerInterval := flag.Duration("scheduler-interval", 5*time.Second, "How often to depart transports and resolve arrivals")
+	ticketRegenInterval := flag.Duration("ticket-regen-interval", 4*time.Hour, "How long it takes to regenerate one transport ticket (0 disables regeneration)")
+	outboxInterval := flag.Duration("outbox-interval", time.Second, "How often to publish recorded domain events to clients")
+	balanceReloadInterval := flag.Duration("balance-reload-interval", 30*time.Second, "How often to look for a newly published balance config")
+	developmentInterval := flag.Duration("development-interval", time.Minute, "How often to bring the development of all developing mines up to date")
+	workerID := flag.String("worker-id", "", "Unique ID of this server among the mine development workers (random if empty)")
+	grpcAddr := flag.String("grpc-addr", ":50051", "Address to serve the mine, transport and ticket gRPC services on (empty disables gRPC)")
 	envFile := flag.String("env", ".env", "Path to .env file")
 	flag.Parse()
 
@@ -66,6 +77,19 @@ func main() {
 	transportCollection := client.Database(*dbName).Collection("transports")
 	ticketCollection := client.Database(*dbName).Collection("tickets")
 	generalCollection := client.Database(*dbName).Collection("generals")
+	inventoryCollection := client.Database(*dbName).Collection("inventories")
+	reportCollection := client.Database(*dbName).Collection("battle_reports")
+	leaderboardCollection := client.Database(*dbName).Collection("alliance_leaderboards")
+	outboxCollection := client.Database(*dbName).Collection("outbox_events")
+	idempotencyCollection := client.Database(*dbName).Collection("idempotency_keys")
+	speedupCollection := client.Database(*dbName).Collection("speedup_spends")
+	balanceCollection := client.Database(*dbName).Collection("balance_configs")
+	allianceCollection := client.Database(*dbName).Collection("alliance_members")
+	mineSnapshotCollection := client.Database(*dbName).Collection("mine_snapshots")
+	shardLockCollection := client.Database(*dbName).Collection("shard_locks")
+	cheatReportCollection := client.Database(*dbName).Collection("cheat_reports")
+	tradeCollection := client.Database(*dbName).Collection("trades")
+	tradeLedgerCollection := client.Database(*dbName).Collection("trade_ledger")
 
 	// Create caches
 	mineCache := cache.NewMemoryCache[*transport.Mine](nil)
@@ -73,6 +97,19 @@ func main() {
 	transportCache := cache.NewMemoryCache[*transport.Transport](nil)
 	ticketCache := cache.NewMemoryCache[*transport.TransportTicket](nil)
 	generalCache := cache.NewMemoryCache[*transport.General](nil)
+	inventoryCache := cache.NewMemoryCache[*transport.PlayerInventory](nil)
+	reportCache := cache.NewMemoryCache[*transport.BattleReport](nil)
+	leaderboardCache := cache.NewMemoryCache[*transport.AllianceLeaderboard](nil)
+	outboxCache := cache.NewMemoryCache[*transport.OutboxEvent](nil)
+	idempotencyCache := cache.NewMemoryCache[*transport.IdempotencyKey](nil)
+	speedupCache := cache.NewMemoryCache[*transport.SpeedupSpend](nil)
+	balanceCache := cache.NewMemoryCache[*transport.BalanceConfig](nil)
+	allianceCache := cache.NewMemoryCache[*transport.AllianceMember](nil)
+	mineSnapshotCache := cache.NewMemoryCache[*transport.MineSnapshot](nil)
+	shardLockCache := cache.NewMemoryCache[*transport.ShardLock](nil)
+	cheatReportCache := cache.NewMemoryCache[*transport.CheatReport](nil)
+	tradeCache := cache.NewMemoryCache[*transport.Trade](nil)
+	tradeLedgerCache := cache.NewMemoryCache[*transport.TradeLedgerEntry](nil)
 
 	// Create storage options
 	storageOptions := &nodestorage.Options{
@@ -111,15 +148,179 @@ func main() {
 	}
 	defer generalStorage.Close()
 
+	inventoryStorage, err := nodestorage.NewStorage[*transport.PlayerInventory](ctx, client, inventoryCollection, inventoryCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create inventory storage: %v", err)
+	}
+	defer inventoryStorage.Close()
+
+	reportStorage, err := nodestorage.NewStorage[*transport.BattleReport](ctx, client, reportCollection, reportCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create battle report storage: %v", err)
+	}
+	defer reportStorage.Close()
+
+	leaderboardStorage, err := nodestorage.NewStorage[*transport.AllianceLeaderboard](ctx, client, leaderboardCollection, leaderboardCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create leaderboard storage: %v", err)
+	}
+	defer leaderboardStorage.Close()
+
+	outboxStorage, err := nodestorage.NewStorage[*transport.OutboxEvent](ctx, client, outboxCollection, outboxCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create outbox storage: %v", err)
+	}
+	defer outboxStorage.Close()
+
+	idempotencyStorage, err := nodestorage.NewStorage[*transport.IdempotencyKey](ctx, client, idempotencyCollection, idempotencyCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create idempotency key storage: %v", err)
+	}
+	defer idempotencyStorage.Close()
+
+	speedupStorage, err := nodestorage.NewStorage[*transport.SpeedupSpend](ctx, client, speedupCollection, speedupCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create speed-up storage: %v", err)
+	}
+	defer speedupStorage.Close()
+
+	balanceStorage, err := nodestorage.NewStorage[*transport.BalanceConfig](ctx, client, balanceCollection, balanceCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create balance config storage: %v", err)
+	}
+	defer balanceStorage.Close()
+
+	allianceStorage, err := nodestorage.NewStorage[*transport.AllianceMember](ctx, client, allianceCollection, allianceCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create alliance member storage: %v", err)
+	}
+	defer allianceStorage.Close()
+
+	mineSnapshotStorage, err := nodestorage.NewStorage[*transport.MineSnapshot](ctx, client, mineSnapshotCollection, mineSnapshotCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create mine snapshot storage: %v", err)
+	}
+	defer mineSnapshotStorage.Close()
+
+	shardLockStorage, err := nodestorage.NewStorage[*transport.ShardLock](ctx, client, shardLockCollection, shardLockCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create shard lock storage: %v", err)
+	}
+	defer shardLockStorage.Close()
+
+	cheatReportStorage, err := nodestorage.NewStorage[*transport.CheatReport](ctx, client, cheatReportCollection, cheatReportCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create cheat report storage: %v", err)
+	}
+	defer cheatReportStorage.Close()
+
+	tradeStorage, err := nodestorage.NewStorage[*transport.Trade](ctx, client, tradeCollection, tradeCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create trade storage: %v", err)
+	}
+	defer tradeStorage.Close()
+
+	tradeLedgerStorage, err := nodestorage.NewStorage[*transport.TradeLedgerEntry](ctx, client, tradeLedgerCollection, tradeLedgerCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create trade ledger storage: %v", err)
+	}
+	defer tradeLedgerStorage.Close()
+
 	// Create services
 	ticketService := transport.NewTicketService(ticketStorage)
+	ticketService.SetRegenInterval(*ticketRegenInterval)
 	generalService := transport.NewGeneralService(generalStorage)
 	mineService := transport.NewMineService(mineStorage, mineConfigStorage, generalService, ticketService)
-	transportService := transport.NewTransportService(transportStorage, mineService, ticketService)
+	transportService := transport.NewTransportService(transportStorage, inventoryStorage, reportStorage, mineService, ticketService)
+	statsService := transport.NewAllianceStatsService(leaderboardStorage, transportService, mineService)
+	speedupService := transport.NewSpeedupService(inventoryStorage, speedupStorage, mineService, transportService)
+	tradeService := transport.NewTradeService(tradeStorage, inventoryStorage, tradeLedgerStorage, ticketService)
+
+	// Record a domain event for every change to mines, tickets and transports
+	outboxService := transport.NewOutboxService(outboxStorage)
+	mineService.SetOutbox(outboxService)
+	ticketService.SetOutbox(outboxService)
+	transportService.SetOutbox(outboxService)
+
+	// Keep every version of every mine for audits
+	mineHistoryService := transport.NewMineHistoryService(mineSnapshotStorage)
+	mineService.SetHistory(mineHistoryService)
+
+	// Bring the development of every developing mine up to date without waiting for players
+	developmentProcessor := transport.NewMineDevelopmentProcessor(mineService, shardLockStorage, *workerID)
+
+	// Only members of the owning alliance can develop and activate mines, send out transports
+	// and trade with each other
+	allianceService := transport.NewAllianceService(allianceStorage)
+	mineService.SetAlliances(allianceService)
+	transportService.SetAlliances(allianceService)
+	tradeService.SetAlliances(allianceService)
+
+	// Reject and record requests for impossible state transitions
+	antiCheatService := transport.NewAntiCheatService(cheatReportStorage)
+	generalService.SetCheats(antiCheatService)
+	mineService.SetCheats(antiCheatService)
+	transportService.SetCheats(antiCheatService)
+	tradeService.SetCheats(antiCheatService)
+
+	// Take the balance rules from the latest published version instead of the defaults
+	balanceService := transport.NewBalanceService(balanceStorage)
+	if _, err := balanceService.Reload(ctx); err != nil {
+		log.Fatalf("Failed to load balance config: %v", err)
+	}
+	generalService.SetBalance(balanceService)
+	mineService.SetBalance(balanceService)
+	ticketService.SetBalance(balanceService)
+
+	// Let clients retry player actions with idempotency keys
+	idempotencyService := transport.NewIdempotencyService(idempotencyStorage)
+	mineService.SetIdempotency(idempotencyService)
+	ticketService.SetIdempotency(idempotencyService)
+	transportService.SetIdempotency(idempotencyService)
+
+	// Clients receive the domain events through pub/sub
+	pubsub, err := crdtpubsub.NewMemoryPubSub(nil)
+	if err != nil {
+		log.Fatalf("Failed to create pub/sub: %v", err)
+	}
+	defer pubsub.Close()
+
+	// Start the scheduler that departs transports and resolves arrivals, the worker that
+	// updates the alliance leaderboards, the mine development processor, the ticket, trade,
+	// idempotency key and mine snapshot sweepers, the outbox dispatcher and the balance reloader
+	// (all stopped before the storages are closed)
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+	transportService.StartScheduler(schedulerCtx, *schedulerInterval)
+	statsService.Start(schedulerCtx)
+	developmentProcessor.Start(schedulerCtx, *developmentInterval)
+	ticketService.StartSweeper(schedulerCtx, time.Minute)
+	tradeService.StartSweeper(schedulerCtx, time.Minute)
+	idempotencyService.StartSweeper(schedulerCtx, 10*time.Minute)
+	mineHistoryService.StartSweeper(schedulerCtx, time.Hour)
+	outboxService.StartDispatcher(schedulerCtx, pubsub, *outboxInterval)
+	balanceService.StartReloader(schedulerCtx, *balanceReloadInterval)
+
+	// Let the match and social servers call the services over gRPC
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", *grpcAddr, err)
+		}
+		grpcServer := grpc.NewServer()
+		transport.RegisterGRPCServices(grpcServer, mineService, transportService, ticketService, tradeService)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
+		defer grpcServer.GracefulStop()
+		log.Printf("Serving gRPC on %s", listener.Addr())
+	}
 
 	// Run in demo mode if requested
 	if *demoMode {
-		runDemo(ctx, mineService, ticketService, transportService)
+		runDemo(ctx, mineService, generalService, ticketService, transportService, statsService, speedupService, balanceService, allianceService, antiCheatService, pubsub)
 	} else {
 		// Start the application
 		log.Printf("Transport system started. Press Ctrl+C to exit.")
@@ -137,13 +338,23 @@ func main() {
 }
 
 // runDemo runs a demonstration of the transport system
-func runDemo(ctx context.Context, mineService *transport.MineService, ticketService *transport.TicketService, transportService *transport.TransportService) {
+func runDemo(ctx context.Context, mineService *transport.MineService, generalService *transport.GeneralService, ticketService *transport.TicketService, transportService *transport.TransportService, statsService *transport.AllianceStatsService, speedupService *transport.SpeedupService, balanceService *transport.BalanceService, allianceService *transport.AllianceService, antiCheatService *transport.AntiCheatService, subscriber crdtpubsub.Subscriber) {
 	log.Printf("Running in demo mode...")
 
 	// Create an alliance
 	allianceID := primitive.NewObjectID()
 	log.Printf("Created alliance with ID: %s", allianceID.Hex())
 
+	// Follow the alliance's domain events like a client would
+	err := subscriber.SubscribePattern(ctx, transport.DomainEventTopic(allianceID, "*"), "demo",
+		func(ctx context.Context, topic string, data []byte, format crdtpubsub.EncodingFormat) error {
+			log.Printf("Domain event on %s: %s", topic, data)
+			return nil
+		})
+	if err != nil {
+		log.Fatalf("Failed to subscribe to domain events: %v", err)
+	}
+
 	// Create mine configurations with development settings
 	for level := transport.MineLevel(1); level <= 5; level++ {
 		minTransport := 100 * int(level)
@@ -214,6 +425,21 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 	player3ID := primitive.NewObjectID()
 	player3Name := "Player Three"
 
+	// Player 1 founds the alliance, adds the others and makes player 2 an officer
+	if _, err := allianceService.FoundAlliance(ctx, allianceID, player1ID, player1Name); err != nil {
+		log.Fatalf("Failed to found alliance: %v", err)
+	}
+	if _, err := allianceService.AddMember(ctx, allianceID, player1ID, player2ID, player2Name); err != nil {
+		log.Fatalf("Failed to add alliance member: %v", err)
+	}
+	if _, err := allianceService.SetMemberRole(ctx, allianceID, player1ID, player2ID, transport.AllianceRoleOfficer); err != nil {
+		log.Fatalf("Failed to promote alliance member: %v", err)
+	}
+	if _, err := allianceService.AddMember(ctx, allianceID, player2ID, player3ID, player3Name); err != nil {
+		log.Fatalf("Failed to add alliance member: %v", err)
+	}
+	log.Printf("%s founded the alliance, %s (officer) and %s joined", player1Name, player2Name, player3Name)
+
 	// Create transport tickets for players
 	ticket1, err := ticketService.GetOrCreateTickets(ctx, player1ID, allianceID, 5)
 	if err != nil {
@@ -233,6 +459,14 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 	}
 	log.Printf("Created tickets for %s: %d/%d", player3Name, ticket3.CurrentTickets, ticket3.MaxTickets)
 
+	// Shorten the preparation time so the demo doesn't wait 30 minutes for departures
+	transportService.SetPrepTime(3 * time.Second)
+
+	// Subscribe to departures and arrivals from the scheduler
+	eventCtx, cancelEvents := context.WithTimeout(ctx, time.Minute)
+	defer cancelEvents()
+	transportEvents := transportService.SubscribeEvents(eventCtx)
+
 	// Start watching for transport changes
 	events, err := transportService.WatchAllTransports(ctx, allianceID)
 	if err != nil {
@@ -261,6 +495,10 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 		}
 	}()
 
+	// Players outside the alliance cannot send out its gold ore
+	_, err = transportService.StartTransport(ctx, primitive.NewObjectID(), "Outsider", mine1.ID, 200)
+	log.Printf("Outsider tried to start a transport: %v", err)
+
 	// Start a transport from mine 1
 	transport1, err := transportService.StartTransport(ctx, player1ID, player1Name, mine1.ID, 200)
 	if err != nil {
@@ -282,41 +520,104 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 	}
 	log.Printf("Started transport from %s with %d gold ore", transport2.MineName, transport2.GoldOreAmount)
 
-	// Simulate a raid on transport 2
-	raiderID := primitive.NewObjectID()
-	raiderName := "Raider X"
-
-	// Wait a bit to let the transport start
-	time.Sleep(2 * time.Second)
+	// Simulate a raid on transport 2 by a player from another alliance
+	raider := transport.CombatOrder{
+		PlayerID:   primitive.NewObjectID(),
+		PlayerName: "Raider X",
+		AllianceID: primitive.NewObjectID(),
+		Troops:     1000,
+	}
 
-	// Update transport status to in progress for demonstration purposes
-	_, err = transportService.UpdateTransportStatus(ctx, transport2.ID, transport.TransportStatusInProgress)
+	// Player 3 defends with a general leading the troops
+	defenderGeneral, err := mineService.GetGeneralService().CreateGeneral(ctx, player3ID, "Guan Yu", 40, 5, transport.GeneralRarityLegendary)
 	if err != nil {
-		log.Printf("Failed to update transport status: %v", err)
+		log.Fatalf("Failed to create general: %v", err)
+	}
+	defender := transport.CombatOrder{
+		PlayerID:   player3ID,
+		PlayerName: player3Name,
+		AllianceID: allianceID,
+		GeneralIDs: []primitive.ObjectID{defenderGeneral.ID},
+		Troops:     1000,
 	}
 
-	transport2, err = transportService.RaidTransport(ctx, transport2.ID, raiderID, raiderName)
+	// Wait for the scheduler to depart transport 2
+	for event := range transportEvents {
+		if event.Type == transport.TransportEventDeparted && event.Transport.ID == transport2.ID {
+			log.Printf("Transport from %s departed, arriving at %s",
+				event.Transport.MineName, event.Transport.EndTime.Format(time.Kitchen))
+			break
+		}
+	}
+
+	transport2, err = transportService.RaidTransport(ctx, transport2.ID, raider)
 	if err != nil {
 		log.Printf("Failed to raid transport: %v", err)
 	} else {
-		log.Printf("Transport from %s is being raided by %s", transport2.MineName, raiderName)
+		log.Printf("Transport from %s is being raided by %s with %d troops",
+			transport2.MineName, raider.PlayerName, raider.Troops)
 	}
 
-	// Defend the transport
-	transport2, err = transportService.DefendTransport(ctx, transport2.ID, player3ID, player3Name, true)
+	// Defend the transport; the battle is fought right away
+	transport2, err = transportService.DefendTransport(ctx, transport2.ID, defender)
 	if err != nil {
 		log.Printf("Failed to defend transport: %v", err)
 	} else {
-		log.Printf("Transport from %s was successfully defended by %s", transport2.MineName, player3Name)
+		result := transport2.RaidStatus.DefenseResult
+		report, err := transportService.GetBattleReport(ctx, result.BattleReportID)
+		if err != nil {
+			log.Printf("Failed to get battle report: %v", err)
+		} else {
+			log.Printf("Battle over transport from %s: %s after %d rounds, gold ore lost: %d, stolen: %d",
+				transport2.MineName, report.Outcome, len(report.Rounds), result.GoldOreLost, result.GoldOreStolen)
+		}
+	}
+
+	// The defense cost the general stamina
+	defenderGeneral, err = mineService.GetGeneralService().UpdateGeneralStamina(ctx, defenderGeneral.ID)
+	if err != nil {
+		log.Printf("Failed to get general: %v", err)
+	} else {
+		log.Printf("%s has %.0f stamina left after the battle (injured: %t)",
+			defenderGeneral.Name, defenderGeneral.Stamina, defenderGeneral.HealsAt != nil)
+	}
+
+	// A designer makes tickets cheaper; the new version takes effect without a redeploy
+	balance := balanceService.Current().Copy()
+	balance.TicketPrice.BasePrice = 200
+	balance, err = balanceService.PublishBalanceConfig(ctx, balance, "cheaper tickets for the weekend event")
+	if err != nil {
+		log.Printf("Failed to publish balance config: %v", err)
+	} else {
+		log.Printf("Published balance config version %d: %s", balance.Version, balance.Note)
 	}
 
-	// Purchase a ticket
-	ticket1, price, err := ticketService.PurchaseTicket(ctx, player1ID)
+	// Purchase a ticket with an idempotency key, then retry as a client would after a timeout
+	purchaseCtx := transport.WithIdempotencyKey(ctx, primitive.NewObjectID().Hex())
+	ticket1, price, err := ticketService.PurchaseTicket(purchaseCtx, player1ID)
 	if err != nil {
 		log.Printf("Failed to purchase ticket: %v", err)
 	} else {
 		log.Printf("Purchased a ticket for %s for %d gems, now has %d tickets",
 			player1Name, price, ticket1.CurrentTickets)
+
+		ticket1, price, err = ticketService.PurchaseTicket(purchaseCtx, player1ID)
+		if err != nil {
+			log.Printf("Failed to retry ticket purchase: %v", err)
+		} else {
+			log.Printf("Retried the purchase: still %d tickets, charged %d gems once",
+				ticket1.CurrentTickets, price)
+		}
+	}
+
+	// The event is over: publish the previous rules again
+	if balance != nil && balance.Version > 1 {
+		balance, err = balanceService.RollbackBalanceConfig(ctx, balance.Version-1)
+		if err != nil {
+			log.Printf("Failed to roll back balance config: %v", err)
+		} else {
+			log.Printf("Published balance config version %d: %s", balance.Version, balance.Note)
+		}
 	}
 
 	// Get all active transports
@@ -362,6 +663,19 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 	log.Printf("Created general for %s: %s (Level: %d, Stars: %d, Rarity: %s)",
 		player3Name, general3.Name, general3.Level, general3.Stars, general3.Rarity)
 
+	// A modified client asks to develop the mine with another player's general; the request is
+	// rejected and recorded for review
+	_, err = mineService.AssignGeneralToMine(ctx, mine3.ID, player2ID, player2Name, general1.ID)
+	log.Printf("%s tried to assign %s's general: %v (cheat detected: %t)",
+		player2Name, player1Name, err, errors.Is(err, transport.ErrCheatDetected))
+	reports, err := antiCheatService.GetPlayerReports(ctx, player2ID)
+	if err != nil {
+		log.Fatalf("Failed to get cheat reports: %v", err)
+	}
+	for _, r := range reports {
+		log.Printf("Cheat report for %s: %s during %s (%v)", player2Name, r.Reason, r.Action, r.Details)
+	}
+
 	// Assign generals to the mine for development
 	mine3, err = mineService.AssignGeneralToMine(ctx, mine3.ID, player1ID, player1Name, general1.ID)
 	if err != nil {
@@ -394,6 +708,35 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 	log.Printf("Updated mine development. Current points: %.2f/%.0f",
 		mine3.DevelopmentPoints, mine3.RequiredPoints)
 
+	// Simulate two hours of development so the generals' work shows up on the leaderboard
+	mine3, err = mineService.SimulateDevelopmentProgress(ctx, mine3.ID, 2)
+	if err != nil {
+		log.Fatalf("Failed to simulate mine development: %v", err)
+	}
+	log.Printf("Simulated 2 hours of development. Current points: %.2f/%.0f",
+		mine3.DevelopmentPoints, mine3.RequiredPoints)
+
+	// Speed up the development with speed-up items, then with gems
+	beforeSpeedup := time.Now()
+	_, err = speedupService.GrantSpeedupItems(ctx, player1ID, allianceID, 6)
+	if err != nil {
+		log.Fatalf("Failed to grant speed-up items: %v", err)
+	}
+	for _, payment := range []transport.SpeedupPayment{transport.SpeedupPaymentItems, transport.SpeedupPaymentGems} {
+		var spend *transport.SpeedupSpend
+		mine3, spend, err = speedupService.ApplySpeedup(ctx, mine3.ID, transport.SpeedupOrder{
+			PlayerID:   player1ID,
+			AllianceID: allianceID,
+			Duration:   30 * time.Minute,
+			Payment:    payment,
+		})
+		if err != nil {
+			log.Fatalf("Failed to speed up mine development: %v", err)
+		}
+		log.Printf("Skipped %s of development for %d items and %d gems. Current points: %.2f/%.0f",
+			spend.Skipped, spend.ItemsSpent, spend.GemsSpent, mine3.DevelopmentPoints, mine3.RequiredPoints)
+	}
+
 	// Force complete development for demo purposes
 	mine3, err = mineService.ForceCompleteDevelopment(ctx, mine3.ID)
 	if err != nil {
@@ -402,12 +745,21 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 	log.Printf("Mine development completed. Status: %s", mine3.Status)
 
 	// Activate the mine
-	mine3, err = mineService.ActivateMine(ctx, mine3.ID)
+	mine3, err = mineService.ActivateMine(ctx, mine3.ID, player1ID)
 	if err != nil {
 		log.Fatalf("Failed to activate mine: %v", err)
 	}
 	log.Printf("Mine activated. Status: %s", mine3.Status)
 
+	// Look back at the mine as it was before the speed-ups, as support would for a dispute
+	pastMine, err := mineService.GetMineAt(ctx, mine3.ID, beforeSpeedup)
+	if err != nil {
+		log.Printf("Failed to get mine history: %v", err)
+	} else {
+		log.Printf("Before the speed-ups the mine was %s with %.2f/%.0f points and %d generals",
+			pastMine.Status, pastMine.DevelopmentPoints, pastMine.RequiredPoints, len(pastMine.AssignedGenerals))
+	}
+
 	// Check if transport tickets were updated
 	ticket1, err = ticketService.GetOrCreateTickets(ctx, player1ID, allianceID, 5)
 	if err != nil {
@@ -416,5 +768,48 @@ func runDemo(ctx context.Context, mineService *transport.MineService, ticketServ
 	log.Printf("Player %s tickets after mine development: %d/%d",
 		player1Name, ticket1.CurrentTickets, ticket1.MaxTickets)
 
+	// Recruit generals; a general the player already owns is converted to shards
+	for i := 0; i < 10; i++ {
+		result, err := generalService.RecruitGeneral(ctx, player1ID)
+		if err != nil {
+			log.Fatalf("Failed to recruit general: %v", err)
+		}
+		if result.Duplicate {
+			log.Printf("%s recruited %s (%s) again: +%d shards (%d total)",
+				player1Name, result.General.Name, result.General.Rarity, result.Shards, result.General.Shards)
+		} else {
+			log.Printf("%s recruited %s (%s)", player1Name, result.General.Name, result.General.Rarity)
+		}
+	}
+
+	roster, err := generalService.GetRoster(ctx, player1ID, transport.RosterFilter{Status: transport.GeneralStatusIdle})
+	if err != nil {
+		log.Fatalf("Failed to get roster: %v", err)
+	}
+	log.Printf("%s has %d idle generals", player1Name, len(roster))
+
+	roster, err = generalService.GetRoster(ctx, player1ID, transport.RosterFilter{Rarity: transport.GeneralRarityCommon})
+	if err != nil {
+		log.Fatalf("Failed to get roster: %v", err)
+	}
+	log.Printf("%s has %d common generals", player1Name, len(roster))
+
+	// Show the alliance leaderboards (updated in the background from service events)
+	time.Sleep(time.Second)
+	for _, metric := range []transport.ContributionMetric{
+		transport.ContributionMetricDevelopmentPoints,
+		transport.ContributionMetricDefensesWon,
+	} {
+		entries, err := statsService.GetLeaderboard(ctx, allianceID, transport.LeaderboardPeriodDaily, metric)
+		if err != nil {
+			log.Printf("Failed to get %s leaderboard: %v", metric, err)
+			continue
+		}
+		log.Printf("Daily %s leaderboard:", metric)
+		for _, e := range entries {
+			log.Printf("  %d. %s: %.0f", e.Rank, e.PlayerName, e.Score)
+		}
+	}
+
 	log.Printf("Demo completed successfully")
 }
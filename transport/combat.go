@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"math/rand"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Combat settings
+const (
+	maxCombatRounds    = 5    // Battles end after this many rounds even if both sides still stand
+	maxCombatGenerals  = 3    // Maximum generals a player can bring to a battle
+	combatDamageRate   = 0.25 // Share of a side's effective strength dealt as troop losses per round
+	combatRollSpread   = 0.15 // Damage rolls vary by up to ±15%
+	defeatLossRate     = 0.3  // Gold ore lost when the defender loses the battle
+	undefendedLossRate = 0.5  // Gold ore lost when nobody defends before the defense window ends
+)
+
+// CombatOrder describes the generals and troops a player sends into a battle
+type CombatOrder struct {
+	PlayerID   primitive.ObjectID
+	PlayerName string
+	AllianceID primitive.ObjectID
+	GeneralIDs []primitive.ObjectID // Generals leading the troops (up to 3)
+	Troops     int
+}
+
+// CombatResult represents the outcome of a battle
+type CombatResult struct {
+	Outcome            BattleOutcome
+	Rounds             []BattleRound
+	AttackerTroopsLeft int
+	DefenderTroopsLeft int
+}
+
+// combatRarityMultiplier returns how strongly a general's rarity scales its combat bonus
+func combatRarityMultiplier(rarity GeneralRarity) float64 {
+	switch rarity {
+	case GeneralRarityUncommon:
+		return 0.75
+	case GeneralRarityRare, GeneralRaritySoldier:
+		return 1.0
+	case GeneralRarityEpic:
+		return 1.5
+	case GeneralRarityLegendary:
+		return 2.0
+	default:
+		return 0.5 // common
+	}
+}
+
+// generalBonus returns the damage bonus a general adds to its side
+// Formula: (1% per level + 5% per star) * rarity multiplier
+func generalBonus(g CombatGeneral) float64 {
+	return (float64(g.Level)*0.01 + float64(g.Stars)*0.05) * combatRarityMultiplier(g.Rarity)
+}
+
+// strength returns the damage multiplier of a force
+func (f CombatForce) strength() float64 {
+	strength := 1.0
+	for _, g := range f.Generals {
+		strength += generalBonus(g)
+	}
+	return strength
+}
+
+// combatDamage rolls the troop losses a side deals in one round
+func combatDamage(troops int, strength float64, rng *rand.Rand) int {
+	roll := 1 + (rng.Float64()*2-1)*combatRollSpread
+	damage := int(float64(troops) * strength * combatDamageRate * roll)
+	if damage < 1 {
+		damage = 1
+	}
+	return damage
+}
+
+// ResolveCombat fights a battle between an attacker and a defender.
+//
+// Both sides strike at the same time every round, dealing troop losses proportional to their
+// remaining troops and their generals' bonus, until one side has no troops left or
+// maxCombatRounds have been fought. The attacker wins by wiping out the defender or by ending
+// with a larger share of its troops left. The result depends only on the forces and the seed,
+// so a battle can always be replayed from its report.
+func ResolveCombat(attacker, defender CombatForce, seed int64) CombatResult {
+	rng := rand.New(rand.NewSource(seed))
+	attackerStrength := attacker.strength()
+	defenderStrength := defender.strength()
+
+	result := CombatResult{
+		Outcome:            BattleOutcomeDefenderWon,
+		AttackerTroopsLeft: attacker.Troops,
+		DefenderTroopsLeft: defender.Troops,
+	}
+
+	for round := 1; round <= maxCombatRounds && result.AttackerTroopsLeft > 0 && result.DefenderTroopsLeft > 0; round++ {
+		attackerDamage := combatDamage(result.AttackerTroopsLeft, attackerStrength, rng)
+		defenderDamage := combatDamage(result.DefenderTroopsLeft, defenderStrength, rng)
+
+		result.DefenderTroopsLeft = max(result.DefenderTroopsLeft-attackerDamage, 0)
+		result.AttackerTroopsLeft = max(result.AttackerTroopsLeft-defenderDamage, 0)
+		result.Rounds = append(result.Rounds, BattleRound{
+			Round:          round,
+			AttackerDamage: attackerDamage,
+			DefenderDamage: defenderDamage,
+			AttackerTroops: result.AttackerTroopsLeft,
+			DefenderTroops: result.DefenderTroopsLeft,
+		})
+	}
+
+	switch {
+	case result.AttackerTroopsLeft == 0:
+		// The defender holds if the attacker is wiped out, even if both sides fell
+	case result.DefenderTroopsLeft == 0:
+		result.Outcome = BattleOutcomeAttackerWon
+	case result.AttackerTroopsLeft*defender.Troops > result.DefenderTroopsLeft*attacker.Troops:
+		// Compare the share of troops each side has left (ties go to the defender)
+		result.Outcome = BattleOutcomeAttackerWon
+	}
+
+	return result
+}
+
+// raidLoss calculates the gold ore a transport loses to a raid and how much of it the raider carries away.
+// A lost battle costs defeatLossRate of the gold ore, or undefendedLossRate if nobody defended.
+// The raider carries away the lost gold ore in proportion to the share of its troops that survived.
+func raidLoss(result CombatResult, attackerTroops int, goldOre int, defended bool) (lost int, stolen int) {
+	if result.Outcome != BattleOutcomeAttackerWon || attackerTroops <= 0 {
+		return 0, 0
+	}
+
+	lossRate := defeatLossRate
+	if !defended {
+		lossRate = undefendedLossRate
+	}
+
+	lost = int(float64(goldOre) * lossRate)
+	stolen = lost * result.AttackerTroopsLeft / attackerTroops
+	return lost, stolen
+}
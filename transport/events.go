@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"context"
+	"sync"
+)
+
+// eventBufferSize is the number of events buffered per subscriber before new ones are dropped
+const eventBufferSize = 64
+
+// eventHub fans out service events to subscribers
+type eventHub[E any] struct {
+	mu          sync.Mutex
+	subscribers map[chan E]struct{}
+}
+
+// newEventHub creates a new eventHub
+func newEventHub[E any]() *eventHub[E] {
+	return &eventHub[E]{
+		subscribers: make(map[chan E]struct{}),
+	}
+}
+
+// subscribe returns a channel that receives events until ctx is cancelled
+func (h *eventHub[E]) subscribe(ctx context.Context) <-chan E {
+	ch := make(chan E, eventBufferSize)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		close(ch)
+		h.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// publish sends an event to every subscriber without blocking.
+// newEvent is called once per subscriber so that subscribers never share mutable state.
+// It returns the number of subscribers that missed the event because they were not keeping up.
+func (h *eventHub[E]) publish(newEvent func() E) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	dropped := 0
+	for ch := range h.subscribers {
+		select {
+		case ch <- newEvent():
+		default:
+			dropped++
+		}
+	}
+	return dropped
+}
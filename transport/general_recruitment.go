@@ -0,0 +1,161 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// generalRarities lists the rarities from the most to the least common, the order in which
+// recruitment weights are added up
+var generalRarities = []GeneralRarity{
+	GeneralRarityCommon,
+	GeneralRarityUncommon,
+	GeneralRarityRare,
+	GeneralRaritySoldier,
+	GeneralRarityEpic,
+	GeneralRarityLegendary,
+}
+
+// RecruitResult describes the outcome of a recruitment
+type RecruitResult struct {
+	General   *General // The recruited general, or the owned general a duplicate was converted for
+	Duplicate bool     // Whether the player already owned the general
+	Shards    int      // Shards added to the owned general for a duplicate
+}
+
+// RosterFilter selects generals of a player's roster. Zero fields match every general.
+type RosterFilter struct {
+	Rarity GeneralRarity
+	Status GeneralStatus
+}
+
+// RecruitGeneral recruits a random general for the player with the recruitment odds of the
+// balance rules in effect.
+//
+// A player owns each general at most once: recruiting a general the player already owns adds
+// the rarity's duplicate shards to the owned general instead.
+func (s *GeneralService) RecruitGeneral(ctx context.Context, playerID primitive.ObjectID) (*RecruitResult, error) {
+	recruitment := s.balance.Current().RecruitmentRules()
+	drawn, err := drawRecruit(recruitment, rand.New(rand.NewSource(rand.Int63())))
+	if err != nil {
+		return nil, err
+	}
+
+	// Timestamps are stored with millisecond precision
+	now := time.Now().Truncate(time.Millisecond)
+	general := &General{
+		ID:          recruitedGeneralID(playerID, drawn.Name),
+		PlayerID:    playerID,
+		Name:        drawn.Name,
+		Level:       1,
+		Stars:       0,
+		Rarity:      drawn.Rarity,
+		Status:      GeneralStatusIdle,
+		AssignedTo:  nil,
+		Stamina:     maxGeneralStamina,
+		RecoveredAt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	// The ID is derived from the player ID and the general's name, so an owned general is kept
+	stored, err := s.storage.FindOneAndUpsert(ctx, general)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recruit general: %w", err)
+	}
+	if stored.CreatedAt.Equal(general.CreatedAt) {
+		return &RecruitResult{General: stored}, nil
+	}
+
+	shards := recruitment.DuplicateShards[drawn.Rarity]
+	stored, _, err = s.storage.FindOneAndUpdate(ctx, stored.ID, func(g *General) (*General, error) {
+		g.Shards += shards
+		g.UpdatedAt = time.Now()
+		return g, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert duplicate general to shards: %w", err)
+	}
+
+	return &RecruitResult{General: stored, Duplicate: true, Shards: shards}, nil
+}
+
+// GetRoster gets the generals of a player matching the filter, highest level and stars first
+func (s *GeneralService) GetRoster(ctx context.Context, playerID primitive.ObjectID, filter RosterFilter) ([]*General, error) {
+	query := bson.M{"player_id": playerID}
+	if filter.Rarity != "" {
+		query["rarity"] = filter.Rarity
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "level", Value: -1}, {Key: "stars", Value: -1}, {Key: "_id", Value: 1}})
+	return s.storage.FindMany(ctx, query, opts)
+}
+
+// drawRecruit draws a rarity by weight among the rarities in the pool, then one of the pool's
+// generals of that rarity with equal chance
+func drawRecruit(recruitment RecruitmentBalance, rng *rand.Rand) (RecruitableGeneral, error) {
+	total := recruitWeight(recruitment)
+	if total == 0 {
+		return RecruitableGeneral{}, fmt.Errorf("no generals can be recruited")
+	}
+
+	roll := rng.Intn(total)
+	for _, rarity := range generalRarities {
+		candidates := recruitCandidates(recruitment, rarity)
+		weight := recruitment.RarityWeights[rarity]
+		if len(candidates) == 0 || weight <= 0 {
+			continue
+		}
+		if roll < weight {
+			return candidates[rng.Intn(len(candidates))], nil
+		}
+		roll -= weight
+	}
+
+	// Unreachable: the roll is below the total weight
+	return RecruitableGeneral{}, fmt.Errorf("no generals can be recruited")
+}
+
+// recruitWeight adds up the weights of the rarities that have generals in the pool
+func recruitWeight(recruitment RecruitmentBalance) int {
+	total := 0
+	for _, rarity := range generalRarities {
+		weight := recruitment.RarityWeights[rarity]
+		if weight > 0 && len(recruitCandidates(recruitment, rarity)) > 0 {
+			total += weight
+		}
+	}
+	return total
+}
+
+// recruitCandidates returns the generals of a rarity in the pool
+func recruitCandidates(recruitment RecruitmentBalance, rarity GeneralRarity) []RecruitableGeneral {
+	var candidates []RecruitableGeneral
+	for _, g := range recruitment.Pool {
+		if g.Rarity == rarity {
+			candidates = append(candidates, g)
+		}
+	}
+	return candidates
+}
+
+// recruitedGeneralID derives the ID of a recruited general from its owner and name
+func recruitedGeneralID(playerID primitive.ObjectID, name string) primitive.ObjectID {
+	sum := sha1.Sum([]byte(fmt.Sprintf("recruited-general:%s:%s", playerID.Hex(), name)))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
+}
@@ -13,6 +13,8 @@ import (
 // GeneralService handles operations related to generals
 type GeneralService struct {
 	storage nodestorage.Storage[*General]
+	balance *BalanceService
+	cheats  *AntiCheatService
 }
 
 // NewGeneralService creates a new GeneralService
@@ -22,6 +24,17 @@ func NewGeneralService(storage nodestorage.Storage[*General]) *GeneralService {
 	}
 }
 
+// SetBalance makes the service calculate contribution rates with the balance rules in effect
+// instead of the defaults
+func (s *GeneralService) SetBalance(balance *BalanceService) {
+	s.balance = balance
+}
+
+// SetCheats makes BuildCombatForce record orders that lead another player's generals
+func (s *GeneralService) SetCheats(cheats *AntiCheatService) {
+	s.cheats = cheats
+}
+
 // CreateGeneral creates a new general
 func (s *GeneralService) CreateGeneral(
 	ctx context.Context,
@@ -53,6 +66,8 @@ func (s *GeneralService) CreateGeneral(
 		Rarity:      rarity,
 		Status:      GeneralStatusIdle,
 		AssignedTo:  nil,
+		Stamina:     maxGeneralStamina,
+		RecoveredAt: now,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 		VectorClock: 1, // Set initial version
@@ -101,14 +116,22 @@ func (s *GeneralService) AssignGeneral(
 
 	// Update general status
 	general, _, err = s.storage.FindOneAndUpdate(ctx, generalID, func(g *General) (*General, error) {
+		// Injured or exhausted generals cannot be assigned
+		now := time.Now()
+		recoverStamina(g, now)
+		if err := checkGeneralReady(g, assignmentStaminaCost, now); err != nil {
+			return nil, err
+		}
+		g.Stamina -= assignmentStaminaCost
+
 		g.Status = GeneralStatusAssigned
 		g.AssignedTo = &AssignmentInfo{
 			Type:       assignmentType,
 			TargetID:   targetID,
 			TargetName: targetName,
-			AssignedAt: time.Now(),
+			AssignedAt: now,
 		}
-		g.UpdatedAt = time.Now()
+		g.UpdatedAt = now
 		return g, nil
 	})
 
@@ -134,6 +157,8 @@ func (s *GeneralService) UnassignGeneral(ctx context.Context, generalID primitiv
 
 	// Update general status
 	general, _, err = s.storage.FindOneAndUpdate(ctx, generalID, func(g *General) (*General, error) {
+		// Stamina only recovers from now on
+		recoverStamina(g, time.Now())
 		g.Status = GeneralStatusIdle
 		g.AssignedTo = nil
 		g.UpdatedAt = time.Now()
@@ -154,35 +179,57 @@ func (s *GeneralService) CalculateContributionRate(general *General) float64 {
 		return 0
 	}
 
-	// Base contribution rate: 10/60 points per hour
-	baseRate := 10.0 / 60.0
-
-	// Star bonus: 2% per star
-	starBonus := float64(general.Stars) * 0.02
-
-	// Level bonus: 0.12658% per level above 1
-	levelBonus := float64(general.Level-1) * 0.0012658
-
-	// Rarity multiplier
-	rarityMultiplier := 0.2 // Default for common
-	switch general.Rarity {
-	case GeneralRarityCommon:
-		rarityMultiplier = 0.2
-	case GeneralRarityUncommon:
-		rarityMultiplier = 0.4
-	case GeneralRarityRare, GeneralRaritySoldier:
-		rarityMultiplier = 0.6
-	case GeneralRarityEpic:
-		rarityMultiplier = 0.8
-	case GeneralRarityLegendary:
-		rarityMultiplier = 1.0
+	return s.balance.Current().ContributionRate(general.Level, general.Stars, general.Rarity)
+}
+
+// BuildCombatForce builds a combat force from the order, taking the stats of the player's generals
+func (s *GeneralService) BuildCombatForce(ctx context.Context, order CombatOrder) (CombatForce, error) {
+	if order.Troops <= 0 {
+		return CombatForce{}, fmt.Errorf("troops must be positive")
 	}
+	if len(order.GeneralIDs) > maxCombatGenerals {
+		return CombatForce{}, fmt.Errorf("too many generals (max %d)", maxCombatGenerals)
+	}
+
+	generals := make([]CombatGeneral, 0, len(order.GeneralIDs))
+	for _, generalID := range order.GeneralIDs {
+		general, err := s.GetGeneralByID(ctx, generalID)
+		if err != nil {
+			return CombatForce{}, fmt.Errorf("failed to find general: %w", err)
+		}
+		if err := s.cheats.Check(ctx, detectForeignGeneral(order.PlayerID, CheatActionCombat, general)); err != nil {
+			return CombatForce{}, err
+		}
+		for _, g := range generals {
+			if g.GeneralID == general.ID {
+				return CombatForce{}, fmt.Errorf("general %s is listed more than once", general.Name)
+			}
+		}
+
+		// Injured or exhausted generals cannot lead troops
+		now := time.Now()
+		rested := general.Copy()
+		recoverStamina(rested, now)
+		if err := checkGeneralReady(rested, battleStaminaCost, now); err != nil {
+			return CombatForce{}, err
+		}
 
-	// Calculate total contribution rate
-	// Formula: baseRate * (1 + starBonus + levelBonus) * rarityMultiplier
-	contributionRate := baseRate * (1 + starBonus + levelBonus) * rarityMultiplier
+		generals = append(generals, CombatGeneral{
+			GeneralID: general.ID,
+			Name:      general.Name,
+			Level:     general.Level,
+			Stars:     general.Stars,
+			Rarity:    general.Rarity,
+		})
+	}
 
-	return contributionRate
+	return CombatForce{
+		PlayerID:   order.PlayerID,
+		PlayerName: order.PlayerName,
+		AllianceID: order.AllianceID,
+		Generals:   generals,
+		Troops:     order.Troops,
+	}, nil
 }
 
 // UpdateGeneralWithFunction updates a general using the provided update function (for demo purposes)
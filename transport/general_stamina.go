@@ -0,0 +1,140 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// General stamina settings
+const (
+	maxGeneralStamina          = 100.0         // Stamina of a fully rested general
+	staminaRecoveryPerHour     = 10.0          // Stamina an idle general recovers per hour
+	assignmentStaminaCost      = 20.0          // Stamina spent when a general is assigned to a task
+	battleStaminaCost          = 15.0          // Stamina spent when a general leads troops into a battle
+	generalInjuryDuration      = 2 * time.Hour // How long generals who lost a battle are injured
+	staminaUpdateMinimumPeriod = time.Minute   // Recovery is not persisted more often than this
+)
+
+// UpdateGeneralStamina applies the stamina a general recovered since its last update.
+// Stamina is calculated lazily: it is only brought up to date when the general is read
+// through this method or when it is assigned or sent into battle.
+func (s *GeneralService) UpdateGeneralStamina(ctx context.Context, generalID primitive.ObjectID) (*General, error) {
+	general, err := s.GetGeneralByID(ctx, generalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find general: %w", err)
+	}
+
+	// If less than a minute has passed, don't update
+	now := time.Now()
+	if !general.RecoveredAt.IsZero() && now.Sub(general.RecoveredAt) < staminaUpdateMinimumPeriod {
+		return general, nil
+	}
+
+	general, _, err = s.storage.FindOneAndUpdate(ctx, generalID, func(g *General) (*General, error) {
+		recoverStamina(g, now)
+		g.UpdatedAt = now
+		return g, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update general: %w", err)
+	}
+
+	return general, nil
+}
+
+// SpendBattleStamina spends the stamina of the generals leading a force into a battle
+func (s *GeneralService) SpendBattleStamina(ctx context.Context, force CombatForce) error {
+	for _, cg := range force.Generals {
+		_, _, err := s.storage.FindOneAndUpdate(ctx, cg.GeneralID, func(g *General) (*General, error) {
+			now := time.Now()
+			spendStamina(g, battleStaminaCost, now)
+			g.UpdatedAt = now
+			return g, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to spend stamina of general %s: %w", cg.Name, err)
+		}
+	}
+	return nil
+}
+
+// InjureGenerals injures the generals of a force that lost a battle.
+// Injured generals cannot be assigned or sent into battle, and do not recover stamina,
+// until generalInjuryDuration has passed.
+func (s *GeneralService) InjureGenerals(ctx context.Context, force CombatForce) error {
+	for _, cg := range force.Generals {
+		_, _, err := s.storage.FindOneAndUpdate(ctx, cg.GeneralID, func(g *General) (*General, error) {
+			now := time.Now()
+			recoverStamina(g, now)
+			healsAt := now.Add(generalInjuryDuration)
+			g.HealsAt = &healsAt
+			g.UpdatedAt = now
+			return g, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to injure general %s: %w", cg.Name, err)
+		}
+	}
+	return nil
+}
+
+// applyBattleInjuries injures the generals of the side that lost a battle
+func (s *GeneralService) applyBattleInjuries(ctx context.Context, attacker, defender CombatForce, outcome BattleOutcome) error {
+	if outcome == BattleOutcomeDefenderWon {
+		return s.InjureGenerals(ctx, attacker)
+	}
+	return s.InjureGenerals(ctx, defender)
+}
+
+// recoverStamina brings a general's stamina up to date.
+// Generals recover staminaRecoveryPerHour while idle and not injured. Generals stored before
+// stamina existed are treated as fully rested.
+func recoverStamina(g *General, now time.Time) {
+	if g.RecoveredAt.IsZero() {
+		g.Stamina = maxGeneralStamina
+		g.RecoveredAt = now
+		return
+	}
+
+	from := g.RecoveredAt
+	if g.HealsAt != nil {
+		if now.Before(*g.HealsAt) {
+			// No recovery while injured
+			g.RecoveredAt = now
+			return
+		}
+
+		// Recovery starts once the injury has healed
+		if g.HealsAt.After(from) {
+			from = *g.HealsAt
+		}
+		g.HealsAt = nil
+	}
+
+	if g.Status == GeneralStatusIdle && now.After(from) {
+		g.Stamina = math.Min(g.Stamina+staminaRecoveryPerHour*now.Sub(from).Hours(), maxGeneralStamina)
+	}
+	g.RecoveredAt = now
+}
+
+// spendStamina brings a general's stamina up to date and spends cost from it
+func spendStamina(g *General, cost float64, now time.Time) {
+	recoverStamina(g, now)
+	g.Stamina = math.Max(g.Stamina-cost, 0)
+}
+
+// checkGeneralReady checks that a general is neither injured nor too exhausted for a task costing cost stamina.
+// The general's stamina must be up to date.
+func checkGeneralReady(g *General, cost float64, now time.Time) error {
+	if g.HealsAt != nil && now.Before(*g.HealsAt) {
+		return fmt.Errorf("general %s is injured until %s", g.Name, g.HealsAt.Format(time.RFC3339))
+	}
+	if g.Stamina < cost {
+		return fmt.Errorf("general %s is too exhausted (stamina %.0f, needs %.0f)", g.Name, g.Stamina, cost)
+	}
+	return nil
+}
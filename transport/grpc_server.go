@@ -0,0 +1,901 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"tictactoe/transport/transportpb"
+)
+
+// IdempotencyKeyMetadata is the gRPC metadata key carrying the idempotency key of a call
+// (see WithIdempotencyKey)
+const IdempotencyKeyMetadata = "idempotency-key"
+
+// RegisterGRPCServices serves the mine, transport, ticket and trade services on a gRPC server, so
+// the servers that don't access MongoDB themselves can call them through the transportpb clients.
+//
+// Domain errors are returned with their message: NOT_FOUND for missing documents,
+// PERMISSION_DENIED for impossible state transitions (see ErrCheatDetected), INVALID_ARGUMENT
+// for malformed IDs and UNKNOWN for everything else.
+func RegisterGRPCServices(
+	server *grpc.Server,
+	mineService *MineService,
+	transportService *TransportService,
+	ticketService *TicketService,
+	tradeService *TradeService,
+) {
+	transportpb.RegisterMineServiceServer(server, &grpcMineService{mines: mineService})
+	transportpb.RegisterTransportServiceServer(server, &grpcTransportService{transports: transportService})
+	transportpb.RegisterTicketServiceServer(server, &grpcTicketService{tickets: ticketService})
+	transportpb.RegisterTradeServiceServer(server, &grpcTradeService{trades: tradeService})
+}
+
+// grpcMineService serves MineService over gRPC
+type grpcMineService struct {
+	transportpb.UnimplementedMineServiceServer
+	mines *MineService
+}
+
+// CreateMine creates an undeveloped mine for an alliance
+func (g *grpcMineService) CreateMine(ctx context.Context, req *transportpb.CreateMineRequest) (*transportpb.MineResponse, error) {
+	allianceID, err := parseGRPCID("alliance_id", req.AllianceId)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.CreateMine(ctx, allianceID, req.Name, MineLevel(req.Level))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// GetMine gets a mine
+func (g *grpcMineService) GetMine(ctx context.Context, req *transportpb.GetMineRequest) (*transportpb.MineResponse, error) {
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.GetMine(ctx, mineID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// GetMinesByAlliance gets all mines of an alliance
+func (g *grpcMineService) GetMinesByAlliance(ctx context.Context, req *transportpb.GetMinesByAllianceRequest) (*transportpb.MinesResponse, error) {
+	allianceID, err := parseGRPCID("alliance_id", req.AllianceId)
+	if err != nil {
+		return nil, err
+	}
+
+	mines, err := g.mines.GetMinesByAlliance(ctx, allianceID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &transportpb.MinesResponse{Mines: make([]*transportpb.Mine, 0, len(mines))}
+	for _, mine := range mines {
+		resp.Mines = append(resp.Mines, toPBMine(mine))
+	}
+	return resp, nil
+}
+
+// GetMineConfig gets the settings of a mine level
+func (g *grpcMineService) GetMineConfig(ctx context.Context, req *transportpb.GetMineConfigRequest) (*transportpb.MineConfigResponse, error) {
+	config, err := g.mines.GetMineConfig(ctx, MineLevel(req.Level))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineConfigResponse{Config: toPBMineConfig(config)}, nil
+}
+
+// AssignGeneralToMine assigns a player's general to develop a mine
+func (g *grpcMineService) AssignGeneralToMine(ctx context.Context, req *transportpb.AssignGeneralToMineRequest) (*transportpb.MineResponse, error) {
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+	generalID, err := parseGRPCID("general_id", req.GeneralId)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.AssignGeneralToMine(grpcContext(ctx), mineID, playerID, req.PlayerName, generalID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// UnassignGeneralFromMine takes a player's general off a mine
+func (g *grpcMineService) UnassignGeneralFromMine(ctx context.Context, req *transportpb.UnassignGeneralFromMineRequest) (*transportpb.MineResponse, error) {
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+	generalID, err := parseGRPCID("general_id", req.GeneralId)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.UnassignGeneralFromMine(ctx, mineID, playerID, generalID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// UpdateMineDevelopment brings the development points of a developing mine up to date
+func (g *grpcMineService) UpdateMineDevelopment(ctx context.Context, req *transportpb.UpdateMineDevelopmentRequest) (*transportpb.MineResponse, error) {
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.UpdateMineDevelopment(ctx, mineID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// ActivateMine starts mining in a developed mine
+func (g *grpcMineService) ActivateMine(ctx context.Context, req *transportpb.ActivateMineRequest) (*transportpb.MineResponse, error) {
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.ActivateMine(ctx, mineID, playerID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// AttackMine attacks a mine of another alliance
+func (g *grpcMineService) AttackMine(ctx context.Context, req *transportpb.MineCombatRequest) (*transportpb.MineResponse, error) {
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+	order, err := fromPBCombatOrder(req.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.AttackMine(ctx, mineID, order)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// DefendMine defends a mine of the player's alliance under attack
+func (g *grpcMineService) DefendMine(ctx context.Context, req *transportpb.MineCombatRequest) (*transportpb.MineResponse, error) {
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+	order, err := fromPBCombatOrder(req.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	mine, err := g.mines.DefendMine(ctx, mineID, order)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.MineResponse{Mine: toPBMine(mine)}, nil
+}
+
+// grpcTransportService serves TransportService over gRPC
+type grpcTransportService struct {
+	transportpb.UnimplementedTransportServiceServer
+	transports *TransportService
+}
+
+// StartTransport starts a transport from a mine
+func (g *grpcTransportService) StartTransport(ctx context.Context, req *transportpb.StartTransportRequest) (*transportpb.TransportResponse, error) {
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+	mineID, err := parseGRPCID("mine_id", req.MineId)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := g.transports.StartTransport(grpcContext(ctx), playerID, req.PlayerName, mineID, int(req.GoldOreAmount))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TransportResponse{Transport: toPBTransport(transport)}, nil
+}
+
+// JoinTransport joins a transport in preparation
+func (g *grpcTransportService) JoinTransport(ctx context.Context, req *transportpb.JoinTransportRequest) (*transportpb.TransportResponse, error) {
+	transportID, err := parseGRPCID("transport_id", req.TransportId)
+	if err != nil {
+		return nil, err
+	}
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := g.transports.JoinTransport(grpcContext(ctx), transportID, playerID, req.PlayerName, int(req.GoldOreAmount))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TransportResponse{Transport: toPBTransport(transport)}, nil
+}
+
+// GetTransport gets a transport
+func (g *grpcTransportService) GetTransport(ctx context.Context, req *transportpb.GetTransportRequest) (*transportpb.TransportResponse, error) {
+	transportID, err := parseGRPCID("transport_id", req.TransportId)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := g.transports.GetTransport(ctx, transportID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TransportResponse{Transport: toPBTransport(transport)}, nil
+}
+
+// GetActiveTransports gets the transports of an alliance that have not arrived yet
+func (g *grpcTransportService) GetActiveTransports(ctx context.Context, req *transportpb.GetActiveTransportsRequest) (*transportpb.TransportsResponse, error) {
+	allianceID, err := parseGRPCID("alliance_id", req.AllianceId)
+	if err != nil {
+		return nil, err
+	}
+
+	transports, err := g.transports.GetActiveTransports(ctx, allianceID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPBTransports(transports), nil
+}
+
+// GetPlayerTransports gets the transports a player takes part in
+func (g *grpcTransportService) GetPlayerTransports(ctx context.Context, req *transportpb.GetPlayerTransportsRequest) (*transportpb.TransportsResponse, error) {
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	transports, err := g.transports.GetPlayerTransports(ctx, playerID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toPBTransports(transports), nil
+}
+
+// RaidTransport raids a transport of another alliance
+func (g *grpcTransportService) RaidTransport(ctx context.Context, req *transportpb.TransportCombatRequest) (*transportpb.TransportResponse, error) {
+	transportID, err := parseGRPCID("transport_id", req.TransportId)
+	if err != nil {
+		return nil, err
+	}
+	order, err := fromPBCombatOrder(req.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := g.transports.RaidTransport(ctx, transportID, order)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TransportResponse{Transport: toPBTransport(transport)}, nil
+}
+
+// DefendTransport defends a transport of the player's alliance being raided
+func (g *grpcTransportService) DefendTransport(ctx context.Context, req *transportpb.TransportCombatRequest) (*transportpb.TransportResponse, error) {
+	transportID, err := parseGRPCID("transport_id", req.TransportId)
+	if err != nil {
+		return nil, err
+	}
+	order, err := fromPBCombatOrder(req.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := g.transports.DefendTransport(ctx, transportID, order)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TransportResponse{Transport: toPBTransport(transport)}, nil
+}
+
+// GetBattleReport gets the report of a battle over a transport
+func (g *grpcTransportService) GetBattleReport(ctx context.Context, req *transportpb.GetBattleReportRequest) (*transportpb.BattleReportResponse, error) {
+	reportID, err := parseGRPCID("report_id", req.ReportId)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := g.transports.GetBattleReport(ctx, reportID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.BattleReportResponse{Report: toPBBattleReport(report)}, nil
+}
+
+// GetBattleReports gets the reports of all battles over a transport
+func (g *grpcTransportService) GetBattleReports(ctx context.Context, req *transportpb.GetBattleReportsRequest) (*transportpb.BattleReportsResponse, error) {
+	transportID, err := parseGRPCID("transport_id", req.TransportId)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := g.transports.GetBattleReports(ctx, transportID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &transportpb.BattleReportsResponse{Reports: make([]*transportpb.BattleReport, 0, len(reports))}
+	for _, report := range reports {
+		resp.Reports = append(resp.Reports, toPBBattleReport(report))
+	}
+	return resp, nil
+}
+
+// grpcTicketService serves TicketService over gRPC
+type grpcTicketService struct {
+	transportpb.UnimplementedTicketServiceServer
+	tickets *TicketService
+}
+
+// GetOrCreateTickets gets the tickets of a player, creating them on first use
+func (g *grpcTicketService) GetOrCreateTickets(ctx context.Context, req *transportpb.GetOrCreateTicketsRequest) (*transportpb.TicketResponse, error) {
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+	allianceID, err := parseGRPCID("alliance_id", req.AllianceId)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := g.tickets.GetOrCreateTickets(ctx, playerID, allianceID, int(req.MaxTickets))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TicketResponse{Ticket: toPBTicket(ticket)}, nil
+}
+
+// PurchaseTicket buys a ticket
+func (g *grpcTicketService) PurchaseTicket(ctx context.Context, req *transportpb.PurchaseTicketRequest) (*transportpb.PurchaseTicketResponse, error) {
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, price, err := g.tickets.PurchaseTicket(grpcContext(ctx), playerID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.PurchaseTicketResponse{Ticket: toPBTicket(ticket), Price: int64(price)}, nil
+}
+
+// GetTicketsByAlliance gets the tickets of all players of an alliance
+func (g *grpcTicketService) GetTicketsByAlliance(ctx context.Context, req *transportpb.GetTicketsByAllianceRequest) (*transportpb.TicketsResponse, error) {
+	allianceID, err := parseGRPCID("alliance_id", req.AllianceId)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := g.tickets.GetTicketsByAlliance(ctx, allianceID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &transportpb.TicketsResponse{Tickets: make([]*transportpb.TransportTicket, 0, len(tickets))}
+	for _, ticket := range tickets {
+		resp.Tickets = append(resp.Tickets, toPBTicket(ticket))
+	}
+	return resp, nil
+}
+
+// grpcTradeService serves TradeService over gRPC
+type grpcTradeService struct {
+	transportpb.UnimplementedTradeServiceServer
+	trades *TradeService
+}
+
+// SendTrade moves the sender's items into escrow until the receiver accepts or declines
+func (g *grpcTradeService) SendTrade(ctx context.Context, req *transportpb.SendTradeRequest) (*transportpb.TradeResponse, error) {
+	allianceID, err := parseGRPCID("alliance_id", req.AllianceId)
+	if err != nil {
+		return nil, err
+	}
+	senderID, err := parseGRPCID("sender_id", req.SenderId)
+	if err != nil {
+		return nil, err
+	}
+	receiverID, err := parseGRPCID("receiver_id", req.ReceiverId)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := g.trades.SendTrade(ctx, allianceID, senderID, req.SenderName, receiverID, req.ReceiverName,
+		TradeItemType(req.ItemType), int(req.Amount))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TradeResponse{Trade: toPBTrade(trade)}, nil
+}
+
+// AcceptTrade credits a pending trade to its receiver
+func (g *grpcTradeService) AcceptTrade(ctx context.Context, req *transportpb.ResolveTradeRequest) (*transportpb.TradeResponse, error) {
+	return g.resolve(ctx, req, g.trades.AcceptTrade)
+}
+
+// DeclineTrade declines a pending trade on behalf of its receiver and refunds the sender
+func (g *grpcTradeService) DeclineTrade(ctx context.Context, req *transportpb.ResolveTradeRequest) (*transportpb.TradeResponse, error) {
+	return g.resolve(ctx, req, g.trades.DeclineTrade)
+}
+
+// CancelTrade cancels a pending trade on behalf of its sender and refunds the items
+func (g *grpcTradeService) CancelTrade(ctx context.Context, req *transportpb.ResolveTradeRequest) (*transportpb.TradeResponse, error) {
+	return g.resolve(ctx, req, g.trades.CancelTrade)
+}
+
+// resolve parses the IDs of a resolve request and resolves the trade with fn
+func (g *grpcTradeService) resolve(
+	ctx context.Context,
+	req *transportpb.ResolveTradeRequest,
+	fn func(ctx context.Context, tradeID, playerID primitive.ObjectID) (*Trade, error),
+) (*transportpb.TradeResponse, error) {
+	tradeID, err := parseGRPCID("trade_id", req.TradeId)
+	if err != nil {
+		return nil, err
+	}
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := fn(ctx, tradeID, playerID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TradeResponse{Trade: toPBTrade(trade)}, nil
+}
+
+// GetTrade gets a trade
+func (g *grpcTradeService) GetTrade(ctx context.Context, req *transportpb.GetTradeRequest) (*transportpb.TradeResponse, error) {
+	tradeID, err := parseGRPCID("trade_id", req.TradeId)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := g.trades.GetTrade(ctx, tradeID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &transportpb.TradeResponse{Trade: toPBTrade(trade)}, nil
+}
+
+// GetPendingTrades gets the pending trades addressed to a player
+func (g *grpcTradeService) GetPendingTrades(ctx context.Context, req *transportpb.GetPendingTradesRequest) (*transportpb.TradesResponse, error) {
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := g.trades.GetPendingTrades(ctx, playerID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &transportpb.TradesResponse{Trades: make([]*transportpb.Trade, 0, len(trades))}
+	for _, trade := range trades {
+		resp.Trades = append(resp.Trades, toPBTrade(trade))
+	}
+	return resp, nil
+}
+
+// GetTradeLedger gets the most recent ledger entries of a player
+func (g *grpcTradeService) GetTradeLedger(ctx context.Context, req *transportpb.GetTradeLedgerRequest) (*transportpb.TradeLedgerResponse, error) {
+	playerID, err := parseGRPCID("player_id", req.PlayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := g.trades.GetLedger(ctx, playerID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &transportpb.TradeLedgerResponse{Entries: make([]*transportpb.TradeLedgerEntry, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, toPBTradeLedgerEntry(entry))
+	}
+	return resp, nil
+}
+
+// grpcContext carries the idempotency key of a call over to the services
+func grpcContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if keys := md.Get(IdempotencyKeyMetadata); len(keys) > 0 && keys[0] != "" {
+		return WithIdempotencyKey(ctx, keys[0])
+	}
+	return ctx
+}
+
+// grpcError converts a domain error into a gRPC status error
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, nodestorage.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrCheatDetected):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
+// parseGRPCID parses a hex ObjectID field of a request
+func parseGRPCID(field, hex string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return primitive.NilObjectID, status.Errorf(codes.InvalidArgument, "invalid %s: %q", field, hex)
+	}
+	return id, nil
+}
+
+// fromPBCombatOrder converts the combat order of a request
+func fromPBCombatOrder(o *transportpb.CombatOrder) (CombatOrder, error) {
+	if o == nil {
+		return CombatOrder{}, status.Error(codes.InvalidArgument, "order is required")
+	}
+
+	playerID, err := parseGRPCID("order.player_id", o.PlayerId)
+	if err != nil {
+		return CombatOrder{}, err
+	}
+	allianceID, err := parseGRPCID("order.alliance_id", o.AllianceId)
+	if err != nil {
+		return CombatOrder{}, err
+	}
+
+	generalIDs := make([]primitive.ObjectID, 0, len(o.GeneralIds))
+	for _, hex := range o.GeneralIds {
+		generalID, err := parseGRPCID("order.general_ids", hex)
+		if err != nil {
+			return CombatOrder{}, err
+		}
+		generalIDs = append(generalIDs, generalID)
+	}
+
+	return CombatOrder{
+		PlayerID:   playerID,
+		PlayerName: o.PlayerName,
+		AllianceID: allianceID,
+		GeneralIDs: generalIDs,
+		Troops:     int(o.Troops),
+	}, nil
+}
+
+// toPBMine converts a mine into its message
+func toPBMine(m *Mine) *transportpb.Mine {
+	generals := make([]*transportpb.AssignedGeneral, 0, len(m.AssignedGenerals))
+	for _, ag := range m.AssignedGenerals {
+		generals = append(generals, &transportpb.AssignedGeneral{
+			PlayerId:         ag.PlayerID.Hex(),
+			PlayerName:       ag.PlayerName,
+			GeneralId:        ag.GeneralID.Hex(),
+			GeneralName:      ag.GeneralName,
+			Level:            int32(ag.Level),
+			Stars:            int32(ag.Stars),
+			Rarity:           string(ag.Rarity),
+			AssignedAt:       pbTime(ag.AssignedAt),
+			ContributionRate: ag.ContributionRate,
+		})
+	}
+
+	return &transportpb.Mine{
+		Id:                m.ID.Hex(),
+		AllianceId:        m.AllianceID.Hex(),
+		Name:              m.Name,
+		Level:             int32(m.Level),
+		GoldOre:           int64(m.GoldOre),
+		Status:            string(m.Status),
+		DevelopmentPoints: m.DevelopmentPoints,
+		RequiredPoints:    m.RequiredPoints,
+		AssignedGenerals:  generals,
+		LastUpdatedAt:     pbTime(m.LastUpdatedAt),
+		Contest:           toPBMineContest(m.Contest),
+		CreatedAt:         pbTime(m.CreatedAt),
+		UpdatedAt:         pbTime(m.UpdatedAt),
+		VectorClock:       m.VectorClock,
+	}
+}
+
+// toPBMineContest converts the latest attack on a mine into its message
+func toPBMineContest(c *MineContest) *transportpb.MineContest {
+	if c == nil {
+		return nil
+	}
+
+	var defender *transportpb.CombatForce
+	if c.Defender != nil {
+		defender = toPBCombatForce(*c.Defender)
+	}
+
+	return &transportpb.MineContest{
+		AttackerAllianceId: c.AttackerAllianceID.Hex(),
+		Attacker:           toPBCombatForce(c.Attacker),
+		Defender:           defender,
+		Seed:               c.Seed,
+		PreviousStatus:     string(c.PreviousStatus),
+		AttackStartTime:    pbTime(c.AttackStartTime),
+		DefenseEndTime:     pbTime(c.DefenseEndTime),
+		Outcome:            string(c.Outcome),
+		Rounds:             toPBBattleRounds(c.Rounds),
+		DevelopmentLost:    c.DevelopmentLost,
+		ResolvedAt:         pbOptionalTime(c.ResolvedAt),
+	}
+}
+
+// toPBMineConfig converts the settings of a mine level into its message
+func toPBMineConfig(c *MineConfig) *transportpb.MineConfig {
+	return &transportpb.MineConfig{
+		Id:                   c.ID.Hex(),
+		Level:                int32(c.Level),
+		MinTransportAmount:   int64(c.MinTransportAmount),
+		MaxTransportAmount:   int64(c.MaxTransportAmount),
+		TransportTimeMinutes: int64(c.TransportTime),
+		MaxParticipants:      int32(c.MaxParticipants),
+		RequiredPoints:       c.RequiredPoints,
+		TransportTicketMax:   int32(c.TransportTicketMax),
+	}
+}
+
+// toPBCombatForce converts a combat force into its message
+func toPBCombatForce(f CombatForce) *transportpb.CombatForce {
+	generals := make([]*transportpb.CombatGeneral, 0, len(f.Generals))
+	for _, g := range f.Generals {
+		generals = append(generals, &transportpb.CombatGeneral{
+			GeneralId: g.GeneralID.Hex(),
+			Name:      g.Name,
+			Level:     int32(g.Level),
+			Stars:     int32(g.Stars),
+			Rarity:    string(g.Rarity),
+		})
+	}
+
+	return &transportpb.CombatForce{
+		PlayerId:   f.PlayerID.Hex(),
+		PlayerName: f.PlayerName,
+		AllianceId: f.AllianceID.Hex(),
+		Generals:   generals,
+		Troops:     int64(f.Troops),
+	}
+}
+
+// toPBBattleRounds converts the rounds of a battle into their messages
+func toPBBattleRounds(rounds []BattleRound) []*transportpb.BattleRound {
+	pbRounds := make([]*transportpb.BattleRound, 0, len(rounds))
+	for _, r := range rounds {
+		pbRounds = append(pbRounds, &transportpb.BattleRound{
+			Round:          int32(r.Round),
+			AttackerDamage: int64(r.AttackerDamage),
+			DefenderDamage: int64(r.DefenderDamage),
+			AttackerTroops: int64(r.AttackerTroops),
+			DefenderTroops: int64(r.DefenderTroops),
+		})
+	}
+	return pbRounds
+}
+
+// toPBTransport converts a transport into its message
+func toPBTransport(t *Transport) *transportpb.Transport {
+	participants := make([]*transportpb.TransportMember, 0, len(t.Participants))
+	for _, p := range t.Participants {
+		participants = append(participants, &transportpb.TransportMember{
+			PlayerId:      p.PlayerID.Hex(),
+			PlayerName:    p.PlayerName,
+			GoldOreAmount: int64(p.GoldOreAmount),
+			JoinedAt:      pbTime(p.JoinedAt),
+		})
+	}
+
+	rewards := make([]*transportpb.TransportReward, 0, len(t.Rewards))
+	for _, r := range t.Rewards {
+		rewards = append(rewards, &transportpb.TransportReward{
+			PlayerId:   r.PlayerID.Hex(),
+			PlayerName: r.PlayerName,
+			GoldOre:    int64(r.GoldOre),
+		})
+	}
+
+	return &transportpb.Transport{
+		Id:              t.ID.Hex(),
+		AllianceId:      t.AllianceID.Hex(),
+		MineId:          t.MineID.Hex(),
+		MineName:        t.MineName,
+		MineLevel:       int32(t.MineLevel),
+		Status:          string(t.Status),
+		GoldOreAmount:   int64(t.GoldOreAmount),
+		MaxParticipants: int32(t.MaxParticipants),
+		Participants:    participants,
+		PrepStartTime:   pbTime(t.PrepStartTime),
+		PrepEndTime:     pbTime(t.PrepEndTime),
+		TransportTime:   durationpb.New(t.TransportTime),
+		StartTime:       pbOptionalTime(t.StartTime),
+		EndTime:         pbOptionalTime(t.EndTime),
+		RaidStatus:      toPBRaidStatus(t.RaidStatus),
+		ArrivedAt:       pbOptionalTime(t.ArrivedAt),
+		Rewards:         rewards,
+		CreatedAt:       pbTime(t.CreatedAt),
+		UpdatedAt:       pbTime(t.UpdatedAt),
+		VectorClock:     t.VectorClock,
+	}
+}
+
+// toPBTransports converts a list of transports into its response
+func toPBTransports(transports []*Transport) *transportpb.TransportsResponse {
+	resp := &transportpb.TransportsResponse{Transports: make([]*transportpb.Transport, 0, len(transports))}
+	for _, t := range transports {
+		resp.Transports = append(resp.Transports, toPBTransport(t))
+	}
+	return resp
+}
+
+// toPBRaidStatus converts the raid on a transport into its message
+func toPBRaidStatus(r *RaidStatus) *transportpb.RaidStatus {
+	if r == nil {
+		return nil
+	}
+
+	var result *transportpb.DefenseResult
+	if r.DefenseResult != nil {
+		result = &transportpb.DefenseResult{
+			Successful:     r.DefenseResult.Successful,
+			DefenderId:     r.DefenseResult.DefenderID.Hex(),
+			DefenderName:   r.DefenseResult.DefenderName,
+			CompletedAt:    pbTime(r.DefenseResult.CompletedAt),
+			GoldOreLost:    int64(r.DefenseResult.GoldOreLost),
+			GoldOreStolen:  int64(r.DefenseResult.GoldOreStolen),
+			BattleReportId: r.DefenseResult.BattleReportID.Hex(),
+		}
+	}
+
+	return &transportpb.RaidStatus{
+		RaiderId:       r.RaiderID.Hex(),
+		RaiderName:     r.RaiderName,
+		RaidStartTime:  pbTime(r.RaidStartTime),
+		DefenseEndTime: pbTime(r.DefenseEndTime),
+		IsDefended:     r.IsDefended,
+		DefenseResult:  result,
+		Attacker:       toPBCombatForce(r.Attacker),
+		Seed:           r.Seed,
+	}
+}
+
+// toPBBattleReport converts a battle report into its message
+func toPBBattleReport(r *BattleReport) *transportpb.BattleReport {
+	return &transportpb.BattleReport{
+		Id:            r.ID.Hex(),
+		TransportId:   r.TransportID.Hex(),
+		AllianceId:    r.AllianceID.Hex(),
+		Attacker:      toPBCombatForce(r.Attacker),
+		Defender:      toPBCombatForce(r.Defender),
+		Defended:      r.Defended,
+		Seed:          r.Seed,
+		Rounds:        toPBBattleRounds(r.Rounds),
+		Outcome:       string(r.Outcome),
+		GoldOreBefore: int64(r.GoldOreBefore),
+		GoldOreLost:   int64(r.GoldOreLost),
+		GoldOreStolen: int64(r.GoldOreStolen),
+		CreatedAt:     pbTime(r.CreatedAt),
+	}
+}
+
+// toPBTicket converts the tickets of a player into their message
+func toPBTicket(t *TransportTicket) *transportpb.TransportTicket {
+	return &transportpb.TransportTicket{
+		Id:             t.ID.Hex(),
+		PlayerId:       t.PlayerID.Hex(),
+		AllianceId:     t.AllianceID.Hex(),
+		CurrentTickets: int32(t.CurrentTickets),
+		HeldTickets:    int32(t.HeldTickets),
+		MaxTickets:     int32(t.MaxTickets),
+		LastRefillTime: pbTime(t.LastRefillTime),
+		LastRegenTime:  pbTime(t.LastRegenTime),
+		PurchaseCount:  int32(t.PurchaseCount),
+		LastPurchaseAt: pbOptionalTime(t.LastPurchaseAt),
+		ResetTime:      pbTime(t.ResetTime),
+		CreatedAt:      pbTime(t.CreatedAt),
+		UpdatedAt:      pbTime(t.UpdatedAt),
+		VectorClock:    t.VectorClock,
+	}
+}
+
+// toPBTrade converts a trade into its message
+func toPBTrade(t *Trade) *transportpb.Trade {
+	return &transportpb.Trade{
+		Id:           t.ID.Hex(),
+		AllianceId:   t.AllianceID.Hex(),
+		SenderId:     t.SenderID.Hex(),
+		SenderName:   t.SenderName,
+		ReceiverId:   t.ReceiverID.Hex(),
+		ReceiverName: t.ReceiverName,
+		ItemType:     string(t.ItemType),
+		Amount:       int64(t.Amount),
+		Status:       string(t.Status),
+		ExpiresAt:    pbTime(t.ExpiresAt),
+		ResolvedAt:   pbOptionalTime(t.ResolvedAt),
+		CreatedAt:    pbTime(t.CreatedAt),
+		UpdatedAt:    pbTime(t.UpdatedAt),
+		VectorClock:  t.VectorClock,
+	}
+}
+
+// toPBTradeLedgerEntry converts a trade ledger entry into its message
+func toPBTradeLedgerEntry(e *TradeLedgerEntry) *transportpb.TradeLedgerEntry {
+	return &transportpb.TradeLedgerEntry{
+		Id:         e.ID.Hex(),
+		TradeId:    e.TradeID.Hex(),
+		PlayerId:   e.PlayerID.Hex(),
+		AllianceId: e.AllianceID.Hex(),
+		ItemType:   string(e.ItemType),
+		Delta:      int64(e.Delta),
+		Balance:    int64(e.Balance),
+		Reason:     e.Reason,
+		CreatedAt:  pbTime(e.CreatedAt),
+	}
+}
+
+// pbTime converts a time into a timestamp, leaving zero times unset
+func pbTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// pbOptionalTime converts an optional time into a timestamp
+func pbOptionalTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return pbTime(*t)
+}
@@ -0,0 +1,249 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"log"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Idempotency settings
+const (
+	idempotencyKeyRetention         = 24 * time.Hour   // How long processed keys are remembered
+	idempotencyClaimLease           = time.Minute      // How long a pending claim blocks other calls
+	maxIdempotencyKeyLength         = 128              // Longest key a client can provide
+	defaultIdempotencySweepInterval = 10 * time.Minute // How often expired keys are purged
+)
+
+// IdempotentAction represents a player action that accepts an idempotency key
+type IdempotentAction string
+
+// Idempotent action constants
+const (
+	IdempotentActionStartTransport IdempotentAction = "start_transport" // 이송 시작
+	IdempotentActionJoinTransport  IdempotentAction = "join_transport"  // 이송 참여
+	IdempotentActionPurchaseTicket IdempotentAction = "purchase_ticket" // 이송권 구매
+	IdempotentActionAssignGeneral  IdempotentAction = "assign_general"  // 광산에 장수 배치
+)
+
+// IdempotencyKeyStatus represents whether the action of a claimed key has finished
+type IdempotencyKeyStatus string
+
+// Idempotency key status constants
+const (
+	IdempotencyKeyPending   IdempotencyKeyStatus = "pending"   // 처리 중
+	IdempotencyKeyCompleted IdempotencyKeyStatus = "completed" // 처리 완료
+)
+
+// idempotencyKeyContextKey is the context key of client-provided idempotency keys
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx carrying a client-provided idempotency key.
+// StartTransport, JoinTransport, PurchaseTicket and AssignGeneralToMine called with the
+// returned context run at most once per player and key: a retry, e.g. after a network timeout,
+// returns the result of the first call instead of spending another ticket or joining again.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key carried by ctx, or "" if there is none
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// IdempotencyService persists the idempotency keys of player actions
+type IdempotencyService struct {
+	storage nodestorage.Storage[*IdempotencyKey]
+}
+
+// NewIdempotencyService creates a new IdempotencyService
+func NewIdempotencyService(storage nodestorage.Storage[*IdempotencyKey]) *IdempotencyService {
+	return &IdempotencyService{
+		storage: storage,
+	}
+}
+
+// do runs perform at most once for the idempotency key carried by ctx.
+//
+// The key is claimed before perform runs, so concurrent retries are rejected while the first
+// call is still in progress. If perform fails, the claim is released and the action can be
+// retried with the same key; otherwise the key is completed with the result perform sets on it.
+// If the key was completed by an earlier call, perform is not run and that key is returned so
+// the caller can return the earlier result. A key whose retention has ended, or whose claim is
+// still pending after idempotencyClaimLease (the claiming call crashed before completing or
+// releasing it), is taken over as if it were free. Without a key (or a service) perform always runs.
+func (s *IdempotencyService) do(
+	ctx context.Context,
+	playerID primitive.ObjectID,
+	action IdempotentAction,
+	request string,
+	perform func(result *IdempotencyKey) error,
+) (*IdempotencyKey, error) {
+	key := IdempotencyKeyFromContext(ctx)
+	if s == nil || key == "" {
+		return nil, perform(&IdempotencyKey{})
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("idempotency key is too long (max %d characters)", maxIdempotencyKeyLength)
+	}
+
+	// Claim the key
+	now := time.Now()
+	claim := &IdempotencyKey{
+		ID:          idempotencyKeyID(playerID, action, key),
+		PlayerID:    playerID,
+		Action:      action,
+		Key:         key,
+		Request:     request,
+		ClaimID:     primitive.NewObjectID(),
+		ClaimedAt:   now,
+		Status:      IdempotencyKeyPending,
+		ExpiresAt:   now.Add(idempotencyKeyRetention),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	existing, err := s.storage.FindOneAndUpsert(ctx, claim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	// The key was claimed by an earlier call
+	if existing.ClaimID != claim.ClaimID && !existing.claimable(now) {
+		if existing.Request != request {
+			return nil, fmt.Errorf("idempotency key %q was already used for a different request", key)
+		}
+		if existing.Status != IdempotencyKeyCompleted {
+			return nil, fmt.Errorf("request with idempotency key %q is still being processed", key)
+		}
+		return existing, nil
+	}
+
+	// The earlier claim expired, or its call never completed or released it
+	if existing.ClaimID != claim.ClaimID {
+		if err := s.takeOver(ctx, existing.ClaimID, claim); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := perform(claim); err != nil {
+		// Release the key so the action can be retried with it, unless another call took it over
+		if current, findErr := s.storage.FindOne(ctx, claim.ID); findErr == nil && current.ClaimID == claim.ClaimID {
+			if releaseErr := s.storage.DeleteOne(ctx, claim.ID); releaseErr != nil {
+				log.Printf("Failed to release idempotency key %q: %v", key, releaseErr)
+			}
+		}
+		return nil, err
+	}
+
+	_, _, err = s.storage.FindOneAndUpdate(ctx, claim.ID, func(k *IdempotencyKey) (*IdempotencyKey, error) {
+		if k.ClaimID != claim.ClaimID {
+			return k, fmt.Errorf("claim on idempotency key %q was taken over by another call", key)
+		}
+		k.Status = IdempotencyKeyCompleted
+		k.ResultID = claim.ResultID
+		k.Cost = claim.Cost
+		k.UpdatedAt = time.Now()
+		return k, nil
+	})
+	if err != nil {
+		// The action succeeded, so don't fail it; retries are rejected until the key expires
+		log.Printf("Failed to complete idempotency key %q: %v", key, err)
+	}
+
+	return nil, nil
+}
+
+// takeOver replaces the expired or stale claim previous on the key with claim. It fails if another
+// call took the key over first.
+func (s *IdempotencyService) takeOver(ctx context.Context, previous primitive.ObjectID, claim *IdempotencyKey) error {
+	_, _, err := s.storage.FindOneAndUpdate(ctx, claim.ID, func(k *IdempotencyKey) (*IdempotencyKey, error) {
+		if k.ClaimID != previous {
+			return k, fmt.Errorf("request with idempotency key %q is still being processed", claim.Key)
+		}
+		k.Request = claim.Request
+		k.ClaimID = claim.ClaimID
+		k.ClaimedAt = claim.ClaimedAt
+		k.Status = IdempotencyKeyPending
+		k.ResultID = primitive.NilObjectID
+		k.Cost = 0
+		k.ExpiresAt = claim.ExpiresAt
+		k.CreatedAt = claim.CreatedAt
+		k.UpdatedAt = claim.UpdatedAt
+		return k, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to take over idempotency key: %w", err)
+	}
+	return nil
+}
+
+// claimable reports whether a new call can take the key over: its retention has ended, or it is
+// still pending after the claim lease
+func (k *IdempotencyKey) claimable(now time.Time) bool {
+	if !now.Before(k.ExpiresAt) {
+		return true
+	}
+	return k.Status == IdempotencyKeyPending && now.Sub(k.ClaimedAt) > idempotencyClaimLease
+}
+
+// StartSweeper starts a background worker that purges expired idempotency keys every interval
+// until ctx is cancelled
+func (s *IdempotencyService) StartSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultIdempotencySweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if purged, err := s.PurgeExpiredKeys(ctx); err != nil {
+				log.Printf("Failed to purge idempotency keys (%d purged): %v", purged, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PurgeExpiredKeys deletes the idempotency keys whose retention has ended, after which the keys
+// can be used again. It returns the number of keys deleted.
+func (s *IdempotencyService) PurgeExpiredKeys(ctx context.Context) (int, error) {
+	keys, err := s.storage.FindMany(ctx, bson.M{"expires_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, k := range keys {
+		if err := s.storage.DeleteOne(ctx, k.ID); err != nil {
+			return purged, fmt.Errorf("failed to delete idempotency key %s: %w", k.ID.Hex(), err)
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// idempotencyKeyID derives the ID of a player's idempotency key for an action
+func idempotencyKeyID(playerID primitive.ObjectID, action IdempotentAction, key string) primitive.ObjectID {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s:%s:%s", playerID.Hex(), action, key)))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"nodestorage/v2/cache"
+	"tictactoe/luvjson/crdtpubsub"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -29,6 +30,19 @@ func Example() {
 	transportCollection := client.Database("transport_db").Collection("transports")
 	ticketCollection := client.Database("transport_db").Collection("tickets")
 	generalCollection := client.Database("transport_db").Collection("generals")
+	inventoryCollection := client.Database("transport_db").Collection("inventories")
+	reportCollection := client.Database("transport_db").Collection("battle_reports")
+	leaderboardCollection := client.Database("transport_db").Collection("alliance_leaderboards")
+	outboxCollection := client.Database("transport_db").Collection("outbox_events")
+	idempotencyCollection := client.Database("transport_db").Collection("idempotency_keys")
+	speedupCollection := client.Database("transport_db").Collection("speedup_spends")
+	balanceCollection := client.Database("transport_db").Collection("balance_configs")
+	allianceCollection := client.Database("transport_db").Collection("alliance_members")
+	mineSnapshotCollection := client.Database("transport_db").Collection("mine_snapshots")
+	shardLockCollection := client.Database("transport_db").Collection("shard_locks")
+	cheatReportCollection := client.Database("transport_db").Collection("cheat_reports")
+	tradeCollection := client.Database("transport_db").Collection("trades")
+	tradeLedgerCollection := client.Database("transport_db").Collection("trade_ledger")
 
 	// Create caches
 	mineCache := cache.NewMemoryCache[*Mine](nil)
@@ -36,6 +50,19 @@ func Example() {
 	transportCache := cache.NewMemoryCache[*Transport](nil)
 	ticketCache := cache.NewMemoryCache[*TransportTicket](nil)
 	generalCache := cache.NewMemoryCache[*General](nil)
+	inventoryCache := cache.NewMemoryCache[*PlayerInventory](nil)
+	reportCache := cache.NewMemoryCache[*BattleReport](nil)
+	leaderboardCache := cache.NewMemoryCache[*AllianceLeaderboard](nil)
+	outboxCache := cache.NewMemoryCache[*OutboxEvent](nil)
+	idempotencyCache := cache.NewMemoryCache[*IdempotencyKey](nil)
+	speedupCache := cache.NewMemoryCache[*SpeedupSpend](nil)
+	balanceCache := cache.NewMemoryCache[*BalanceConfig](nil)
+	allianceCache := cache.NewMemoryCache[*AllianceMember](nil)
+	mineSnapshotCache := cache.NewMemoryCache[*MineSnapshot](nil)
+	shardLockCache := cache.NewMemoryCache[*ShardLock](nil)
+	cheatReportCache := cache.NewMemoryCache[*CheatReport](nil)
+	tradeCache := cache.NewMemoryCache[*Trade](nil)
+	tradeLedgerCache := cache.NewMemoryCache[*TradeLedgerEntry](nil)
 
 	// Create storage options
 	storageOptions := &nodestorage.Options{
@@ -74,16 +101,175 @@ func Example() {
 	}
 	defer generalStorage.Close()
 
+	inventoryStorage, err := nodestorage.NewStorage[*PlayerInventory](ctx, inventoryCollection, inventoryCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create inventory storage: %v", err)
+	}
+	defer inventoryStorage.Close()
+
+	reportStorage, err := nodestorage.NewStorage[*BattleReport](ctx, reportCollection, reportCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create battle report storage: %v", err)
+	}
+	defer reportStorage.Close()
+
+	leaderboardStorage, err := nodestorage.NewStorage[*AllianceLeaderboard](ctx, leaderboardCollection, leaderboardCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create leaderboard storage: %v", err)
+	}
+	defer leaderboardStorage.Close()
+
+	outboxStorage, err := nodestorage.NewStorage[*OutboxEvent](ctx, outboxCollection, outboxCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create outbox storage: %v", err)
+	}
+	defer outboxStorage.Close()
+
+	idempotencyStorage, err := nodestorage.NewStorage[*IdempotencyKey](ctx, idempotencyCollection, idempotencyCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create idempotency key storage: %v", err)
+	}
+	defer idempotencyStorage.Close()
+
+	speedupStorage, err := nodestorage.NewStorage[*SpeedupSpend](ctx, speedupCollection, speedupCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create speed-up storage: %v", err)
+	}
+	defer speedupStorage.Close()
+
+	balanceStorage, err := nodestorage.NewStorage[*BalanceConfig](ctx, balanceCollection, balanceCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create balance config storage: %v", err)
+	}
+	defer balanceStorage.Close()
+
+	allianceStorage, err := nodestorage.NewStorage[*AllianceMember](ctx, allianceCollection, allianceCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create alliance member storage: %v", err)
+	}
+	defer allianceStorage.Close()
+
+	mineSnapshotStorage, err := nodestorage.NewStorage[*MineSnapshot](ctx, mineSnapshotCollection, mineSnapshotCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create mine snapshot storage: %v", err)
+	}
+	defer mineSnapshotStorage.Close()
+
+	shardLockStorage, err := nodestorage.NewStorage[*ShardLock](ctx, shardLockCollection, shardLockCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create shard lock storage: %v", err)
+	}
+	defer shardLockStorage.Close()
+
+	cheatReportStorage, err := nodestorage.NewStorage[*CheatReport](ctx, cheatReportCollection, cheatReportCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create cheat report storage: %v", err)
+	}
+	defer cheatReportStorage.Close()
+
+	tradeStorage, err := nodestorage.NewStorage[*Trade](ctx, tradeCollection, tradeCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create trade storage: %v", err)
+	}
+	defer tradeStorage.Close()
+
+	tradeLedgerStorage, err := nodestorage.NewStorage[*TradeLedgerEntry](ctx, tradeLedgerCollection, tradeLedgerCache, storageOptions)
+	if err != nil {
+		log.Fatalf("Failed to create trade ledger storage: %v", err)
+	}
+	defer tradeLedgerStorage.Close()
+
 	// Create services
 	ticketService := NewTicketService(ticketStorage)
 	generalService := NewGeneralService(generalStorage)
 	mineService := NewMineService(mineStorage, mineConfigStorage, generalService, ticketService)
-	transportService := NewTransportService(transportStorage, mineService, ticketService)
+	transportService := NewTransportService(transportStorage, inventoryStorage, reportStorage, mineService, ticketService)
+	statsService := NewAllianceStatsService(leaderboardStorage, transportService, mineService)
+	speedupService := NewSpeedupService(inventoryStorage, speedupStorage, mineService, transportService)
+	tradeService := NewTradeService(tradeStorage, inventoryStorage, tradeLedgerStorage, ticketService)
+
+	// Record a domain event for every change to mines, tickets and transports
+	outboxService := NewOutboxService(outboxStorage)
+	mineService.SetOutbox(outboxService)
+	ticketService.SetOutbox(outboxService)
+	transportService.SetOutbox(outboxService)
+
+	// Keep every version of every mine for audits
+	mineHistoryService := NewMineHistoryService(mineSnapshotStorage)
+	mineService.SetHistory(mineHistoryService)
+
+	// Bring the development of every developing mine up to date without waiting for players
+	developmentProcessor := NewMineDevelopmentProcessor(mineService, shardLockStorage, "")
+
+	// Only members of the owning alliance can develop and activate mines, send out transports
+	// and trade with each other
+	allianceService := NewAllianceService(allianceStorage)
+	mineService.SetAlliances(allianceService)
+	transportService.SetAlliances(allianceService)
+	tradeService.SetAlliances(allianceService)
+
+	// Reject and record requests for impossible state transitions
+	antiCheatService := NewAntiCheatService(cheatReportStorage)
+	generalService.SetCheats(antiCheatService)
+	mineService.SetCheats(antiCheatService)
+	transportService.SetCheats(antiCheatService)
+	tradeService.SetCheats(antiCheatService)
+
+	// Take the balance rules from the latest published version instead of the defaults
+	balanceService := NewBalanceService(balanceStorage)
+	if _, err := balanceService.Reload(ctx); err != nil {
+		log.Fatalf("Failed to load balance config: %v", err)
+	}
+	generalService.SetBalance(balanceService)
+	mineService.SetBalance(balanceService)
+	ticketService.SetBalance(balanceService)
+
+	// Let clients retry player actions with idempotency keys
+	idempotencyService := NewIdempotencyService(idempotencyStorage)
+	mineService.SetIdempotency(idempotencyService)
+	ticketService.SetIdempotency(idempotencyService)
+	transportService.SetIdempotency(idempotencyService)
+
+	// Clients receive the domain events through pub/sub
+	pubsub, err := crdtpubsub.NewMemoryPubSub(nil)
+	if err != nil {
+		log.Fatalf("Failed to create pub/sub: %v", err)
+	}
+	defer pubsub.Close()
+
+	// Start the scheduler that departs transports and resolves arrivals, the worker that
+	// updates the alliance leaderboards, the mine development processor, the ticket, trade,
+	// idempotency key and mine snapshot sweepers, the outbox dispatcher and the balance reloader
+	// (all stopped before the storages are closed)
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+	transportService.StartScheduler(schedulerCtx, time.Second)
+	statsService.Start(schedulerCtx)
+	developmentProcessor.Start(schedulerCtx, time.Minute)
+	ticketService.StartSweeper(schedulerCtx, time.Minute)
+	tradeService.StartSweeper(schedulerCtx, time.Minute)
+	idempotencyService.StartSweeper(schedulerCtx, 10*time.Minute)
+	mineHistoryService.StartSweeper(schedulerCtx, time.Hour)
+	outboxService.StartDispatcher(schedulerCtx, pubsub, time.Second)
+	balanceService.StartReloader(schedulerCtx, 30*time.Second)
+
+	// Shorten the preparation time so the example doesn't wait 30 minutes for departures
+	transportService.SetPrepTime(3 * time.Second)
 
 	// Create an alliance
 	allianceID := primitive.NewObjectID()
 	log.Printf("Created alliance with ID: %s", allianceID.Hex())
 
+	// Follow the alliance's domain events like a client would
+	err = pubsub.SubscribePattern(ctx, DomainEventTopic(allianceID, "*"), "example",
+		func(ctx context.Context, topic string, data []byte, format crdtpubsub.EncodingFormat) error {
+			log.Printf("Domain event on %s: %s", topic, data)
+			return nil
+		})
+	if err != nil {
+		log.Fatalf("Failed to subscribe to domain events: %v", err)
+	}
+
 	// Create mine configurations
 	for level := MineLevel(1); level <= 5; level++ {
 		minTransport := 100 * int(level)
@@ -135,6 +321,20 @@ func Example() {
 	player3ID := primitive.NewObjectID()
 	player3Name := "Player Three"
 
+	// Player 1 founds the alliance and adds the others
+	if _, err := allianceService.FoundAlliance(ctx, allianceID, player1ID, player1Name); err != nil {
+		log.Fatalf("Failed to found alliance: %v", err)
+	}
+	for _, p := range []struct {
+		id   primitive.ObjectID
+		name string
+	}{{player2ID, player2Name}, {player3ID, player3Name}} {
+		if _, err := allianceService.AddMember(ctx, allianceID, player1ID, p.id, p.name); err != nil {
+			log.Fatalf("Failed to add alliance member: %v", err)
+		}
+	}
+	log.Printf("%s founded the alliance with %s and %s", player1Name, player2Name, player3Name)
+
 	// Create transport tickets for players
 	ticket1, err := ticketService.GetOrCreateTickets(ctx, player1ID, allianceID, 5)
 	if err != nil {
@@ -148,6 +348,11 @@ func Example() {
 	}
 	log.Printf("Created tickets for %s: %d/%d", player2Name, ticket2.CurrentTickets, ticket2.MaxTickets)
 
+	// Subscribe to departures and arrivals from the scheduler
+	eventCtx, cancelEvents := context.WithTimeout(ctx, time.Minute)
+	defer cancelEvents()
+	transportEvents := transportService.SubscribeEvents(eventCtx)
+
 	// Start watching for transport changes
 	events, err := transportService.WatchAllTransports(ctx, allianceID)
 	if err != nil {
@@ -197,26 +402,83 @@ func Example() {
 	}
 	log.Printf("Started transport from %s with %d gold ore", transport2.MineName, transport2.GoldOreAmount)
 
-	// Simulate a raid on transport 2
-	raiderID := primitive.NewObjectID()
-	raiderName := "Raider X"
+	// Simulate a raid on transport 2 by a player from another alliance
+	raider := CombatOrder{
+		PlayerID:   primitive.NewObjectID(),
+		PlayerName: "Raider X",
+		AllianceID: primitive.NewObjectID(),
+		Troops:     1000,
+	}
+
+	// Player 3 defends with a general leading the troops
+	defenderGeneral, err := generalService.CreateGeneral(ctx, player3ID, "Guan Yu", 40, 5, GeneralRarityLegendary)
+	if err != nil {
+		log.Fatalf("Failed to create general: %v", err)
+	}
+	defender := CombatOrder{
+		PlayerID:   player3ID,
+		PlayerName: player3Name,
+		AllianceID: allianceID,
+		GeneralIDs: []primitive.ObjectID{defenderGeneral.ID},
+		Troops:     1000,
+	}
 
-	// Wait a bit to let the transport start
-	time.Sleep(2 * time.Second)
+	// Wait for the scheduler to depart transport 2
+	for event := range transportEvents {
+		if event.Type == TransportEventDeparted && event.Transport.ID == transport2.ID {
+			log.Printf("Transport from %s departed, arriving at %s",
+				event.Transport.MineName, event.Transport.EndTime.Format(time.Kitchen))
+			break
+		}
+	}
 
-	transport2, err = transportService.RaidTransport(ctx, transport2.ID, raiderID, raiderName)
+	transport2, err = transportService.RaidTransport(ctx, transport2.ID, raider)
 	if err != nil {
 		log.Printf("Failed to raid transport: %v", err)
 	} else {
-		log.Printf("Transport from %s is being raided by %s", transport2.MineName, raiderName)
+		log.Printf("Transport from %s is being raided by %s with %d troops",
+			transport2.MineName, raider.PlayerName, raider.Troops)
 	}
 
-	// Defend the transport
-	transport2, err = transportService.DefendTransport(ctx, transport2.ID, player3ID, player3Name, true)
+	// Defend the transport; the battle is fought right away
+	transport2, err = transportService.DefendTransport(ctx, transport2.ID, defender)
 	if err != nil {
 		log.Printf("Failed to defend transport: %v", err)
 	} else {
-		log.Printf("Transport from %s was successfully defended by %s", transport2.MineName, player3Name)
+		result := transport2.RaidStatus.DefenseResult
+		report, err := transportService.GetBattleReport(ctx, result.BattleReportID)
+		if err != nil {
+			log.Printf("Failed to get battle report: %v", err)
+		} else {
+			log.Printf("Battle over transport from %s: %s after %d rounds, gold ore lost: %d, stolen: %d",
+				transport2.MineName, report.Outcome, len(report.Rounds), result.GoldOreLost, result.GoldOreStolen)
+		}
+	}
+
+	// Player 3 skips the rest of the travel time with gems, so the transport arrives right away
+	if transport2 != nil {
+		transport2, spend, err := speedupService.ApplyTransportSpeedup(ctx, transport2.ID, SpeedupOrder{
+			PlayerID:   player3ID,
+			AllianceID: allianceID,
+			Duration:   2 * time.Hour,
+			Payment:    SpeedupPaymentGems,
+		})
+		if err != nil {
+			log.Printf("Failed to speed up transport: %v", err)
+		} else {
+			log.Printf("Skipped %s of travel for %d gems, transport from %s is %s",
+				spend.Skipped, spend.GemsSpent, transport2.MineName, transport2.Status)
+		}
+	}
+
+	// A designer makes tickets cheaper; the new version takes effect without a redeploy
+	balance := balanceService.Current().Copy()
+	balance.TicketPrice.BasePrice = 200
+	balance, err = balanceService.PublishBalanceConfig(ctx, balance, "cheaper tickets for the weekend event")
+	if err != nil {
+		log.Printf("Failed to publish balance config: %v", err)
+	} else {
+		log.Printf("Published balance config version %d: %s", balance.Version, balance.Note)
 	}
 
 	// Purchase a ticket
@@ -240,5 +502,16 @@ func Example() {
 		}
 	}
 
+	// Show the daily defense leaderboard (updated in the background from transport events)
+	time.Sleep(time.Second)
+	defenders, err := statsService.GetLeaderboard(ctx, allianceID, LeaderboardPeriodDaily, ContributionMetricDefensesWon)
+	if err != nil {
+		log.Printf("Failed to get leaderboard: %v", err)
+	} else {
+		for _, e := range defenders {
+			log.Printf("Defense leaderboard %d. %s: %.0f", e.Rank, e.PlayerName, e.Score)
+		}
+	}
+
 	log.Printf("Example completed successfully")
 }
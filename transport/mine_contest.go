@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mine contest settings
+const (
+	mineDefenseWindow = 30 * time.Minute // How long the owning alliance has to defend an attacked mine
+	minePillageRate   = 0.5              // Share of development points destroyed when a mine is pillaged
+)
+
+// AttackMine starts an attack by an enemy alliance on a developed mine.
+// The mine is contested until the battle is resolved: the owning alliance can defend it with
+// DefendMine until the defense window ends, after which the battle is fought without a defender.
+func (s *MineService) AttackMine(ctx context.Context, mineID primitive.ObjectID, attacker CombatOrder) (*Mine, error) {
+	force, err := s.generalService.BuildCombatForce(ctx, attacker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attack mine: %w", err)
+	}
+
+	var mine *Mine
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
+			// Only developed mines can be attacked
+			if m.Status != MineStatusDeveloped && m.Status != MineStatusActive {
+				return nil, fmt.Errorf("mine cannot be attacked in its current state (status: %s)", m.Status)
+			}
+
+			// Alliances cannot attack their own mines
+			if attacker.AllianceID == m.AllianceID {
+				return nil, fmt.Errorf("cannot attack a mine of your own alliance")
+			}
+
+			now := time.Now()
+			m.Contest = &MineContest{
+				AttackerAllianceID: attacker.AllianceID,
+				Attacker:           force,
+				Seed:               rand.Int63(),
+				PreviousStatus:     m.Status,
+				AttackStartTime:    now,
+				DefenseEndTime:     now.Add(mineDefenseWindow),
+			}
+			m.Status = MineStatusContested
+			m.UpdatedAt = now
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mineEvent(DomainEventMineAttacked, mine, attacker.PlayerID, bson.M{
+			"attacker_alliance_id": attacker.AllianceID,
+			"attacker_troops":      force.Troops,
+			"defense_end_time":     mine.Contest.DefenseEndTime,
+		}), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attack mine: %w", err)
+	}
+
+	// Resolve the battle once the defense window ends
+	go s.scheduleMineBattle(context.Background(), mineID, mine.Contest.DefenseEndTime)
+
+	if err := s.generalService.SpendBattleStamina(ctx, force); err != nil {
+		return mine, fmt.Errorf("attack started but failed to update generals: %w", err)
+	}
+
+	return mine, nil
+}
+
+// DefendMine sends an alliance member's force to defend a contested mine.
+// The battle is resolved right away.
+func (s *MineService) DefendMine(ctx context.Context, mineID primitive.ObjectID, defender CombatOrder) (*Mine, error) {
+	force, err := s.generalService.BuildCombatForce(ctx, defender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to defend mine: %w", err)
+	}
+
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		mine, _, err := s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
+			if m.Status != MineStatusContested || m.Contest == nil {
+				return nil, fmt.Errorf("mine is not under attack")
+			}
+
+			// Only the owning alliance can defend the mine, once, within the defense window
+			if defender.AllianceID != m.AllianceID {
+				return nil, fmt.Errorf("only alliance members can defend this mine")
+			}
+			if m.Contest.Defender != nil {
+				return nil, fmt.Errorf("mine is already being defended")
+			}
+			now := time.Now()
+			if now.After(m.Contest.DefenseEndTime) {
+				return nil, fmt.Errorf("defense window has expired")
+			}
+
+			m.Contest.Defender = &force
+			m.UpdatedAt = now
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mineEvent(DomainEventMineDefended, mine, defender.PlayerID, bson.M{
+			"defender_troops": force.Troops,
+		}), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to defend mine: %w", err)
+	}
+
+	if err := s.generalService.SpendBattleStamina(ctx, force); err != nil {
+		return nil, fmt.Errorf("failed to defend mine: %w", err)
+	}
+
+	return s.ResolveMineBattle(ctx, mineID)
+}
+
+// ResolveMineBattle fights the battle for a contested mine and applies its outcome.
+// It can be called once the mine has a defender or the defense window has ended.
+//
+// If the defender wins, the mine returns to its previous state. If the attacker wipes out the
+// defender (or nobody defended), the attacking alliance captures the mine. If the attacker wins
+// while the defender still stands, the mine is pillaged: minePillageRate of its development
+// points are destroyed and it has to be developed again.
+func (s *MineService) ResolveMineBattle(ctx context.Context, mineID primitive.ObjectID) (*Mine, error) {
+	var mine *Mine
+	err := s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var ownerAllianceID primitive.ObjectID
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
+			if m.Status != MineStatusContested || m.Contest == nil {
+				return nil, fmt.Errorf("mine is not under attack")
+			}
+
+			now := time.Now()
+			c := m.Contest
+			if c.Defender == nil && now.Before(c.DefenseEndTime) {
+				return nil, fmt.Errorf("defense window is still open")
+			}
+
+			var defender CombatForce
+			if c.Defender != nil {
+				defender = *c.Defender
+			}
+
+			// Fight the battle
+			ownerAllianceID = m.AllianceID
+			applyMineBattle(m, ResolveCombat(c.Attacker, defender, c.Seed), now)
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Both alliances learn the outcome, even if the mine changed hands
+		event := mineEvent(DomainEventMineBattleResolved, mine, primitive.NilObjectID, bson.M{
+			"outcome":              mine.Contest.Outcome,
+			"owner_alliance_id":    ownerAllianceID,
+			"attacker_alliance_id": mine.Contest.AttackerAllianceID,
+			"development_lost":     mine.Contest.DevelopmentLost,
+			"status":               mine.Status,
+		})
+		attackerEvent := event.Copy()
+		attackerEvent.AllianceID = mine.Contest.AttackerAllianceID
+		if err := s.outbox.Record(ctx, attackerEvent); err != nil {
+			return nil, err
+		}
+		event.AllianceID = ownerAllianceID
+		return event, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mine battle: %w", err)
+	}
+
+	// The new owner's players get the tickets the mine provides
+	if mine.Contest.Outcome == MineContestCaptured {
+		err = s.updateTransportTicketsForAlliance(ctx, mine.AllianceID, mine.Level)
+		if err != nil {
+			return mine, fmt.Errorf("mine captured but failed to update transport tickets: %w", err)
+		}
+	}
+
+	// Generals who lost the battle are injured
+	c := mine.Contest
+	outcome := BattleOutcomeAttackerWon
+	if c.Outcome == MineContestRepelled {
+		outcome = BattleOutcomeDefenderWon
+	}
+	var defender CombatForce
+	if c.Defender != nil {
+		defender = *c.Defender
+	}
+	if err := s.generalService.applyBattleInjuries(ctx, c.Attacker, defender, outcome); err != nil {
+		return mine, fmt.Errorf("mine battle resolved but failed to injure generals: %w", err)
+	}
+
+	return mine, nil
+}
+
+// applyMineBattle applies the result of the battle for a contested mine
+func applyMineBattle(m *Mine, result CombatResult, now time.Time) {
+	c := m.Contest
+	c.Rounds = result.Rounds
+	c.ResolvedAt = &now
+
+	switch {
+	case result.Outcome == BattleOutcomeDefenderWon:
+		c.Outcome = MineContestRepelled
+		m.Status = c.PreviousStatus
+	case result.DefenderTroopsLeft == 0:
+		c.Outcome = MineContestCaptured
+		m.AllianceID = c.AttackerAllianceID
+		m.Status = c.PreviousStatus
+	default:
+		c.Outcome = MineContestPillaged
+		c.DevelopmentLost = m.DevelopmentPoints * minePillageRate
+		m.DevelopmentPoints -= c.DevelopmentLost
+		m.Status = MineStatusUndeveloped
+	}
+
+	m.LastUpdatedAt = now
+	m.UpdatedAt = now
+}
+
+// scheduleMineBattle resolves a mine battle when the defense window ends if nobody defended
+func (s *MineService) scheduleMineBattle(ctx context.Context, mineID primitive.ObjectID, defenseEndTime time.Time) {
+	// Wait until defense end time
+	waitTime := time.Until(defenseEndTime)
+	if waitTime > 0 {
+		time.Sleep(waitTime)
+	}
+
+	// Resolve the battle if it is still open
+	mine, err := s.GetMine(ctx, mineID)
+	if err != nil || mine.Status != MineStatusContested {
+		return
+	}
+
+	if _, err := s.ResolveMineBattle(ctx, mineID); err != nil {
+		// Log error in real implementation
+		return
+	}
+}
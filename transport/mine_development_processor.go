@@ -0,0 +1,237 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mine development processor settings
+const (
+	mineDevelopmentJob             = "mine-development"
+	mineDevelopmentShards          = 16          // Number of shards mines are spread over
+	mineDevelopmentPageSize        = 100         // Mines loaded per query
+	mineDevelopmentLockTTL         = time.Minute // How long a shard lock lasts unless renewed
+	defaultMineDevelopmentInterval = time.Minute // How often all shards are processed
+)
+
+// errShardLocked is returned when another worker holds the lock of a shard
+var errShardLocked = errors.New("shard is locked by another worker")
+
+// MineDevelopmentProcessor periodically brings the development points of every developing mine
+// up to date, so completions, leaderboards and notifications don't wait for a player to look at
+// the mine.
+//
+// Mines are spread over shards (see Mine.Shard). Each shard is processed by one worker at a time,
+// which holds the shard's lock while it pages through the shard's developing mines, so any
+// number of servers can run a processor. Mines created before sharding belong to shard 0.
+type MineDevelopmentProcessor struct {
+	mineService *MineService
+	lockStorage nodestorage.Storage[*ShardLock]
+	workerID    string
+}
+
+// NewMineDevelopmentProcessor creates a new MineDevelopmentProcessor.
+// workerID must be unique among the workers; an empty ID is replaced by a random one.
+func NewMineDevelopmentProcessor(
+	mineService *MineService,
+	lockStorage nodestorage.Storage[*ShardLock],
+	workerID string,
+) *MineDevelopmentProcessor {
+	if workerID == "" {
+		workerID = primitive.NewObjectID().Hex()
+	}
+	return &MineDevelopmentProcessor{
+		mineService: mineService,
+		lockStorage: lockStorage,
+		workerID:    workerID,
+	}
+}
+
+// Start starts a background worker that processes all shards every interval until ctx is cancelled
+func (p *MineDevelopmentProcessor) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultMineDevelopmentInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if processed, err := p.ProcessAll(ctx); err != nil {
+				log.Printf("Failed to process mine development (%d mines updated): %v", processed, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ProcessAll processes every shard no other worker is processing.
+// Workers start at different shards, so they rarely wait on each other.
+// It returns the number of mines updated.
+func (p *MineDevelopmentProcessor) ProcessAll(ctx context.Context) (int, error) {
+	start := int(shardHash(p.workerID) % mineDevelopmentShards)
+
+	processed := 0
+	for i := 0; i < mineDevelopmentShards; i++ {
+		shard := (start + i) % mineDevelopmentShards
+		n, err := p.ProcessShard(ctx, shard)
+		processed += n
+		if errors.Is(err, errShardLocked) {
+			continue
+		}
+		if err != nil {
+			return processed, fmt.Errorf("failed to process shard %d: %w", shard, err)
+		}
+	}
+
+	return processed, nil
+}
+
+// ProcessShard updates the development of the developing mines of a shard, a page at a time.
+// It returns errShardLocked if another worker is processing the shard, and the number of
+// mines updated.
+func (p *MineDevelopmentProcessor) ProcessShard(ctx context.Context, shard int) (int, error) {
+	if _, err := p.acquire(ctx, shard); err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := p.release(ctx, shard); err != nil {
+			log.Printf("Failed to release mine development shard %d: %v", shard, err)
+		}
+	}()
+
+	processed := 0
+	lastID := primitive.NilObjectID
+	for {
+		mines, err := p.getDevelopingMines(ctx, shard, lastID)
+		if err != nil {
+			return processed, fmt.Errorf("failed to get developing mines: %w", err)
+		}
+
+		for _, mine := range mines {
+			// A player may have finished the mine meanwhile; the next mine is unaffected
+			if _, err := p.mineService.UpdateMineDevelopment(ctx, mine.ID); err != nil {
+				log.Printf("Failed to update development of mine %s: %v", mine.ID.Hex(), err)
+				continue
+			}
+			processed++
+		}
+
+		if len(mines) < mineDevelopmentPageSize {
+			return processed, nil
+		}
+		lastID = mines[len(mines)-1].ID
+
+		// Keep the lock for the next page
+		if _, err := p.acquire(ctx, shard); err != nil {
+			return processed, fmt.Errorf("lost lock of shard %d: %w", shard, err)
+		}
+	}
+}
+
+// getDevelopingMines retrieves a page of the developing mines of a shard, in ID order after lastID
+func (p *MineDevelopmentProcessor) getDevelopingMines(ctx context.Context, shard int, lastID primitive.ObjectID) ([]*Mine, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(mineDevelopmentPageSize)
+	return p.mineService.storage.FindMany(ctx, bson.M{
+		"status": MineStatusDeveloping,
+		"shard":  shard,
+		"_id":    bson.M{"$gt": lastID},
+	}, opts)
+}
+
+// acquire takes or renews the lock of a shard for mineDevelopmentLockTTL
+func (p *MineDevelopmentProcessor) acquire(ctx context.Context, shard int) (*ShardLock, error) {
+	now := time.Now()
+	lock := &ShardLock{
+		ID:          shardLockID(mineDevelopmentJob, shard),
+		Job:         mineDevelopmentJob,
+		Shard:       shard,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	// Create the lock released if this is the first time the shard is processed
+	if _, err := p.lockStorage.FindOneAndUpsert(ctx, lock); err != nil {
+		return nil, fmt.Errorf("failed to create shard lock: %w", err)
+	}
+
+	lock, _, err := p.lockStorage.FindOneAndUpdate(ctx, lock.ID, func(l *ShardLock) (*ShardLock, error) {
+		if l.heldByOther(p.workerID, now) {
+			return nil, errShardLocked
+		}
+		l.Owner = p.workerID
+		l.ExpiresAt = now.Add(mineDevelopmentLockTTL)
+		l.UpdatedAt = now
+		return l, nil
+	})
+	if errors.Is(err, errShardLocked) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock shard: %w", err)
+	}
+	return lock, nil
+}
+
+// release gives up the lock of a shard if the worker still holds it
+func (p *MineDevelopmentProcessor) release(ctx context.Context, shard int) error {
+	_, _, err := p.lockStorage.FindOneAndUpdate(ctx, shardLockID(mineDevelopmentJob, shard), func(l *ShardLock) (*ShardLock, error) {
+		if l.Owner != p.workerID {
+			return nil, errShardLocked
+		}
+		now := time.Now()
+		l.Owner = ""
+		l.ExpiresAt = now
+		l.UpdatedAt = now
+		return l, nil
+	})
+	if errors.Is(err, errShardLocked) {
+		return nil
+	}
+	return err
+}
+
+// heldByOther reports whether a worker other than owner holds the lock at the given time
+func (sl *ShardLock) heldByOther(owner string, now time.Time) bool {
+	return sl.Owner != "" && sl.Owner != owner && now.Before(sl.ExpiresAt)
+}
+
+// mineShard returns the development processor shard of a mine
+func mineShard(mineID primitive.ObjectID) int {
+	return int(shardHash(mineID.Hex()) % mineDevelopmentShards)
+}
+
+// shardHash spreads keys evenly over shards
+func shardHash(key string) uint32 {
+	sum := sha1.Sum([]byte(key))
+	return binary.BigEndian.Uint32(sum[:4])
+}
+
+// shardLockID derives the ID of the lock of a shard of a job
+func shardLockID(job string, shard int) primitive.ObjectID {
+	sum := sha1.Sum([]byte(fmt.Sprintf("shard-lock:%s:%d", job, shard)))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
+}
@@ -0,0 +1,308 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"log"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mine history settings
+const (
+	mineSnapshotRetention           = 90 * 24 * time.Hour // How long mine snapshots are kept for audits
+	defaultMineHistorySweepInterval = time.Hour           // How often expired snapshots are purged
+)
+
+// MineHistoryService keeps a snapshot of every version of every mine, so the state of a mine at
+// any past moment can be reconstructed when players dispute their development progress or rewards.
+//
+// Snapshots are recorded by the mine storage itself (see MineService.SetHistory) in the same
+// transaction as the change, so every write to a mine is covered, whichever service makes it.
+type MineHistoryService struct {
+	storage nodestorage.Storage[*MineSnapshot]
+}
+
+// NewMineHistoryService creates a new MineHistoryService
+func NewMineHistoryService(storage nodestorage.Storage[*MineSnapshot]) *MineHistoryService {
+	return &MineHistoryService{
+		storage: storage,
+	}
+}
+
+// Record stores a snapshot of a mine as it is after a change.
+// Recording the same version twice keeps the first snapshot.
+func (h *MineHistoryService) Record(ctx context.Context, mine *Mine) error {
+	now := time.Now()
+	snapshot := &MineSnapshot{
+		ID:          mineSnapshotID(mine.ID, mine.VectorClock),
+		MineID:      mine.ID,
+		AllianceID:  mine.AllianceID,
+		Version:     mine.VectorClock,
+		Mine:        mine.Copy(),
+		RecordedAt:  now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	if _, err := h.storage.FindOneAndUpsert(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to record snapshot of mine %s: %w", mine.ID.Hex(), err)
+	}
+	return nil
+}
+
+// GetSnapshotAt retrieves the latest snapshot of a mine recorded at or before the given time
+func (h *MineHistoryService) GetSnapshotAt(ctx context.Context, mineID primitive.ObjectID, at time.Time) (*MineSnapshot, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "recorded_at", Value: -1}, {Key: "version", Value: -1}}).
+		SetLimit(1)
+	snapshots, err := h.storage.FindMany(ctx, bson.M{
+		"mine_id":     mineID,
+		"recorded_at": bson.M{"$lte": at},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no history of mine %s at %s: %w", mineID.Hex(), at.Format(time.RFC3339), nodestorage.ErrNotFound)
+	}
+	return snapshots[0], nil
+}
+
+// GetMineHistory retrieves the snapshots of a mine recorded between from and to, oldest first
+func (h *MineHistoryService) GetMineHistory(ctx context.Context, mineID primitive.ObjectID, from, to time.Time) ([]*MineSnapshot, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "recorded_at", Value: 1}, {Key: "version", Value: 1}})
+	return h.storage.FindMany(ctx, bson.M{
+		"mine_id":     mineID,
+		"recorded_at": bson.M{"$gte": from, "$lte": to},
+	}, opts)
+}
+
+// StartSweeper starts a background worker that purges expired snapshots every interval
+// until ctx is cancelled
+func (h *MineHistoryService) StartSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultMineHistorySweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if purged, err := h.PurgeExpiredSnapshots(ctx, time.Now()); err != nil {
+				log.Printf("Failed to purge mine snapshots (%d purged): %v", purged, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PurgeExpiredSnapshots deletes the snapshots older than mineSnapshotRetention.
+// The latest snapshot of each mine is kept, so its current state can still be audited.
+// It returns the number of snapshots deleted.
+func (h *MineHistoryService) PurgeExpiredSnapshots(ctx context.Context, now time.Time) (int, error) {
+	expired, err := h.storage.FindMany(ctx, bson.M{"recorded_at": bson.M{"$lt": now.Add(-mineSnapshotRetention)}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired snapshots: %w", err)
+	}
+
+	purged := 0
+	for _, snapshot := range expired {
+		latest, err := h.GetSnapshotAt(ctx, snapshot.MineID, now)
+		if err != nil {
+			return purged, err
+		}
+		if latest.ID == snapshot.ID {
+			continue
+		}
+
+		if err := h.storage.DeleteOne(ctx, snapshot.ID); err != nil {
+			return purged, fmt.Errorf("failed to delete snapshot %s: %w", snapshot.ID.Hex(), err)
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// SetHistory makes the service record a snapshot of every change to mines, and enables GetMineAt
+func (s *MineService) SetHistory(history *MineHistoryService) {
+	if recording, ok := s.storage.(*recordingMineStorage); ok {
+		s.storage = recording.Storage
+	}
+	s.history = history
+	if history != nil {
+		s.storage = &recordingMineStorage{Storage: s.storage, history: history}
+	}
+}
+
+// GetMineAt reconstructs the state of a mine at a past moment from its snapshots.
+// Development progress is calculated lazily, so the development points of a developing mine
+// are brought up to the requested time the same way UpdateMineDevelopment would have.
+func (s *MineService) GetMineAt(ctx context.Context, mineID primitive.ObjectID, at time.Time) (*Mine, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("mine history is not recorded")
+	}
+
+	snapshot, err := s.history.GetSnapshotAt(ctx, mineID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mine history: %w", err)
+	}
+
+	mine := snapshot.Mine.Copy()
+	projectDevelopment(mine, at)
+	return mine, nil
+}
+
+// GetMineHistory retrieves the recorded versions of a mine between from and to, oldest first
+func (s *MineService) GetMineHistory(ctx context.Context, mineID primitive.ObjectID, from, to time.Time) ([]*MineSnapshot, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("mine history is not recorded")
+	}
+	return s.history.GetMineHistory(ctx, mineID, from, to)
+}
+
+// projectDevelopment adds the development points the assigned generals contributed between
+// the last update of a developing mine and the given time, up to the required points
+func projectDevelopment(m *Mine, at time.Time) {
+	if m.Status != MineStatusDeveloping || !at.After(m.LastUpdatedAt) {
+		return
+	}
+
+	hours := at.Sub(m.LastUpdatedAt).Hours()
+	for _, ag := range m.AssignedGenerals {
+		m.DevelopmentPoints += ag.ContributionRate * hours
+	}
+	if m.DevelopmentPoints > m.RequiredPoints {
+		m.DevelopmentPoints = m.RequiredPoints
+	}
+	m.LastUpdatedAt = at
+}
+
+// recordingMineStorage is a mine storage that records a snapshot of every mine it writes.
+// Outside a transaction the write and its snapshot run in one.
+type recordingMineStorage struct {
+	nodestorage.Storage[*Mine]
+	history *MineHistoryService
+}
+
+// FindOneAndUpsert creates a mine, or returns the existing one, and records it
+func (r *recordingMineStorage) FindOneAndUpsert(ctx context.Context, data *Mine) (*Mine, error) {
+	var mine *Mine
+	err := r.record(ctx, func(ctx context.Context) (*Mine, error) {
+		var err error
+		mine, err = r.Storage.FindOneAndUpsert(ctx, data)
+		return mine, err
+	})
+	return mine, err
+}
+
+// FindOneAndUpdate updates a mine and records the new version
+func (r *recordingMineStorage) FindOneAndUpdate(
+	ctx context.Context,
+	id primitive.ObjectID,
+	updateFn nodestorage.EditFunc[*Mine],
+	opts ...nodestorage.EditOption,
+) (*Mine, *nodestorage.Diff, error) {
+	var mine *Mine
+	var diff *nodestorage.Diff
+	err := r.record(ctx, func(ctx context.Context) (*Mine, error) {
+		var err error
+		mine, diff, err = r.Storage.FindOneAndUpdate(ctx, id, updateFn, opts...)
+		return mine, err
+	})
+	return mine, diff, err
+}
+
+// UpdateOne updates a mine with update operators and records the new version
+func (r *recordingMineStorage) UpdateOne(
+	ctx context.Context,
+	id primitive.ObjectID,
+	update bson.M,
+	opts ...nodestorage.EditOption,
+) (*Mine, error) {
+	var mine *Mine
+	err := r.record(ctx, func(ctx context.Context) (*Mine, error) {
+		var err error
+		mine, err = r.Storage.UpdateOne(ctx, id, update, opts...)
+		return mine, err
+	})
+	return mine, err
+}
+
+// UpdateOneWithPipeline updates a mine with an aggregation pipeline and records the new version
+func (r *recordingMineStorage) UpdateOneWithPipeline(
+	ctx context.Context,
+	id primitive.ObjectID,
+	pipeline mongo.Pipeline,
+	opts ...nodestorage.EditOption,
+) (*Mine, error) {
+	var mine *Mine
+	err := r.record(ctx, func(ctx context.Context) (*Mine, error) {
+		var err error
+		mine, err = r.Storage.UpdateOneWithPipeline(ctx, id, pipeline, opts...)
+		return mine, err
+	})
+	return mine, err
+}
+
+// UpdateSection updates a section of a mine and records the new version
+func (r *recordingMineStorage) UpdateSection(
+	ctx context.Context,
+	id primitive.ObjectID,
+	sectionPath string,
+	updateFn func(interface{}) (interface{}, error),
+	opts ...nodestorage.EditOption,
+) (*Mine, error) {
+	var mine *Mine
+	err := r.record(ctx, func(ctx context.Context) (*Mine, error) {
+		var err error
+		mine, err = r.Storage.UpdateSection(ctx, id, sectionPath, updateFn, opts...)
+		return mine, err
+	})
+	return mine, err
+}
+
+// record runs write and records the mine it returns in one transaction.
+// Inside an ongoing transaction both join it.
+func (r *recordingMineStorage) record(ctx context.Context, write func(ctx context.Context) (*Mine, error)) error {
+	writeAndRecord := func(ctx context.Context) error {
+		mine, err := write(ctx)
+		if err != nil {
+			return err
+		}
+		return r.history.Record(ctx, mine)
+	}
+
+	if mongo.SessionFromContext(ctx) != nil {
+		return writeAndRecord(ctx)
+	}
+
+	return r.Storage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		return writeAndRecord(sessCtx)
+	})
+}
+
+// mineSnapshotID derives the ID of the snapshot of a mine version
+func mineSnapshotID(mineID primitive.ObjectID, version int64) primitive.ObjectID {
+	sum := sha1.Sum([]byte(fmt.Sprintf("mine-snapshot:%s:%d", mineID.Hex(), version)))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+	return id
+}
@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"nodestorage/v2"
@@ -18,6 +19,13 @@ type MineService struct {
 	configStorage  nodestorage.Storage[*MineConfig]
 	generalService *GeneralService
 	ticketService  *TicketService
+	outbox         *OutboxService
+	idempotency    *IdempotencyService
+	balance        *BalanceService
+	alliances      *AllianceService
+	history        *MineHistoryService
+	cheats         *AntiCheatService
+	events         *eventHub[DevelopmentEvent]
 }
 
 // NewMineService creates a new MineService
@@ -32,48 +40,69 @@ func NewMineService(
 		configStorage:  configStorage,
 		generalService: generalService,
 		ticketService:  ticketService,
+		events:         newEventHub[DevelopmentEvent](),
 	}
 }
 
+// SetOutbox makes the service record a domain event for every change to mines
+func (s *MineService) SetOutbox(outbox *OutboxService) {
+	s.outbox = outbox
+}
+
+// SetIdempotency makes AssignGeneralToMine honor idempotency keys (see WithIdempotencyKey)
+func (s *MineService) SetIdempotency(idempotency *IdempotencyService) {
+	s.idempotency = idempotency
+}
+
+// SetBalance makes the service take the development settings of mine levels from the balance
+// rules in effect instead of the defaults
+func (s *MineService) SetBalance(balance *BalanceService) {
+	s.balance = balance
+}
+
+// SetAlliances makes the service check that only members of the owning alliance assign
+// generals to a mine or activate it
+func (s *MineService) SetAlliances(alliances *AllianceService) {
+	s.alliances = alliances
+}
+
+// SetCheats makes the service record requests to develop a mine with another player's general
+func (s *MineService) SetCheats(cheats *AntiCheatService) {
+	s.cheats = cheats
+}
+
 // CreateMine creates a new mine for an alliance
 func (s *MineService) CreateMine(ctx context.Context, allianceID primitive.ObjectID, name string, level MineLevel) (*Mine, error) {
 	// Get mine config for this level
+	balance, ok := s.balance.Current().MineLevel(level)
+	if !ok {
+		return nil, fmt.Errorf("no balance settings for mine level %d", level)
+	}
+
 	config, err := s.GetMineConfig(ctx, level)
 	if err != nil {
 		// If no config exists, create a default one based on level
-		requiredPoints := float64(10000)
-		transportTicketMax := 5
-
-		switch level {
-		case MineLevel1:
-			requiredPoints = 10000 // 약 1일
-			transportTicketMax = 5
-		case MineLevel2:
-			requiredPoints = 30000 // 약 3일
-			transportTicketMax = 10
-		case MineLevel3:
-			requiredPoints = 70000 // 약 7일
-			transportTicketMax = 15
-		default:
-			requiredPoints = float64(10000 * int(level))
-			transportTicketMax = 5 * int(level)
-		}
-
 		config, err = s.CreateOrUpdateMineConfigWithDevelopment(
 			ctx,
 			level,
-			100*int(level),     // minTransportAmount
-			500*int(level),     // maxTransportAmount
-			30+(10*int(level)), // transportTime
-			3+int(level),       // maxParticipants
-			requiredPoints,     // requiredPoints
-			transportTicketMax, // transportTicketMax
+			100*int(level),             // minTransportAmount
+			500*int(level),             // maxTransportAmount
+			30+(10*int(level)),         // transportTime
+			3+int(level),               // maxParticipants
+			balance.RequiredPoints,     // requiredPoints
+			balance.TransportTicketMax, // transportTicketMax
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create mine config: %w", err)
 		}
 	}
 
+	// Configs without development settings use the balance rules
+	requiredPoints := config.RequiredPoints
+	if requiredPoints <= 0 {
+		requiredPoints = balance.RequiredPoints
+	}
+
 	now := time.Now()
 	mine := &Mine{
 		ID:                primitive.NewObjectID(),
@@ -83,15 +112,31 @@ func (s *MineService) CreateMine(ctx context.Context, allianceID primitive.Objec
 		GoldOre:           0,
 		Status:            MineStatusUndeveloped,
 		DevelopmentPoints: 0,
-		RequiredPoints:    config.RequiredPoints,
+		RequiredPoints:    requiredPoints,
 		AssignedGenerals:  []AssignedGeneral{},
 		LastUpdatedAt:     now,
 		CreatedAt:         now,
 		UpdatedAt:         now,
 		VectorClock:       1, // Set initial version
 	}
+	mine.Shard = mineShard(mine.ID)
+
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, err = s.storage.FindOneAndUpsert(ctx, mine)
+		if err != nil {
+			return nil, err
+		}
+		return mineEvent(DomainEventMineCreated, mine, primitive.NilObjectID, bson.M{
+			"name":  mine.Name,
+			"level": mine.Level,
+		}), nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return s.storage.FindOneAndUpsert(ctx, mine)
+	return mine, nil
 }
 
 // GetMine retrieves a mine by ID
@@ -121,10 +166,20 @@ func (s *MineService) AddGoldOre(ctx context.Context, mineID primitive.ObjectID,
 		return nil, fmt.Errorf("cannot add gold ore to undeveloped mine (status: %s)", mine.Status)
 	}
 
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(mine *Mine) (*Mine, error) {
-		mine.GoldOre += amount
-		mine.UpdatedAt = time.Now()
-		return mine, nil
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(mine *Mine) (*Mine, error) {
+			mine.GoldOre += amount
+			mine.UpdatedAt = time.Now()
+			return mine, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mineEvent(DomainEventGoldOreAdded, mine, primitive.NilObjectID, bson.M{
+			"amount":   amount,
+			"gold_ore": mine.GoldOre,
+		}), nil
 	})
 
 	return mine, err
@@ -147,13 +202,23 @@ func (s *MineService) RemoveGoldOre(ctx context.Context, mineID primitive.Object
 		return nil, fmt.Errorf("cannot remove gold ore from undeveloped mine (status: %s)", mine.Status)
 	}
 
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(mine *Mine) (*Mine, error) {
-		if mine.GoldOre < amount {
-			return nil, fmt.Errorf("not enough gold ore in mine")
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(mine *Mine) (*Mine, error) {
+			if mine.GoldOre < amount {
+				return nil, fmt.Errorf("not enough gold ore in mine")
+			}
+			mine.GoldOre -= amount
+			mine.UpdatedAt = time.Now()
+			return mine, nil
+		})
+		if err != nil {
+			return nil, err
 		}
-		mine.GoldOre -= amount
-		mine.UpdatedAt = time.Now()
-		return mine, nil
+		return mineEvent(DomainEventGoldOreRemoved, mine, primitive.NilObjectID, bson.M{
+			"amount":   amount,
+			"gold_ore": mine.GoldOre,
+		}), nil
 	})
 
 	return mine, err
@@ -268,13 +333,46 @@ func (s *MineService) WatchAllMines(ctx context.Context, allianceID primitive.Ob
 	return s.storage.Watch(ctx, pipeline)
 }
 
-// AssignGeneralToMine assigns a general to a mine for development
+// AssignGeneralToMine assigns a general to a mine for development.
+// Called with a context from WithIdempotencyKey, retries return the mine instead of failing
+// because the general is already assigned.
 func (s *MineService) AssignGeneralToMine(
 	ctx context.Context,
 	mineID primitive.ObjectID,
 	playerID primitive.ObjectID,
 	playerName string,
 	generalID primitive.ObjectID,
+) (*Mine, error) {
+	var mine *Mine
+	earlier, err := s.idempotency.do(ctx, playerID, IdempotentActionAssignGeneral,
+		fmt.Sprintf("%s:%s", mineID.Hex(), generalID.Hex()),
+		func(result *IdempotencyKey) error {
+			var err error
+			mine, err = s.assignGeneralToMine(ctx, mineID, playerID, playerName, generalID)
+			if err != nil {
+				return err
+			}
+			result.ResultID = mine.ID
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if earlier != nil {
+		return s.GetMine(ctx, earlier.ResultID)
+	}
+
+	return mine, nil
+}
+
+// assignGeneralToMine assigns a general to a mine for development
+func (s *MineService) assignGeneralToMine(
+	ctx context.Context,
+	mineID primitive.ObjectID,
+	playerID primitive.ObjectID,
+	playerName string,
+	generalID primitive.ObjectID,
 ) (*Mine, error) {
 	// Get the mine
 	mine, err := s.GetMine(ctx, mineID)
@@ -282,6 +380,11 @@ func (s *MineService) AssignGeneralToMine(
 		return nil, fmt.Errorf("failed to get mine: %w", err)
 	}
 
+	// Only members of the owning alliance can develop the mine
+	if err := s.alliances.CheckMember(ctx, mine.AllianceID, playerID); err != nil {
+		return nil, err
+	}
+
 	// Check if mine is in a valid state for development
 	if mine.Status != MineStatusUndeveloped && mine.Status != MineStatusDeveloping {
 		return nil, fmt.Errorf("mine is not in a valid state for development")
@@ -306,8 +409,8 @@ func (s *MineService) AssignGeneralToMine(
 	}
 
 	// Check if general belongs to the player
-	if general.PlayerID != playerID {
-		return nil, fmt.Errorf("general does not belong to the player")
+	if err := s.cheats.Check(ctx, detectForeignGeneral(playerID, CheatActionAssignGeneral, general)); err != nil {
+		return nil, err
 	}
 
 	// Check if general is already assigned
@@ -325,30 +428,41 @@ func (s *MineService) AssignGeneralToMine(
 	}
 
 	// Update mine with assigned general
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
-		// Create assigned general record
-		assignedGeneral := AssignedGeneral{
-			PlayerID:         playerID,
-			PlayerName:       playerName,
-			GeneralID:        generalID,
-			GeneralName:      general.Name,
-			Level:            general.Level,
-			Stars:            general.Stars,
-			Rarity:           general.Rarity,
-			AssignedAt:       time.Now(),
-			ContributionRate: contributionRate,
-		}
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
+			// Create assigned general record
+			assignedGeneral := AssignedGeneral{
+				PlayerID:         playerID,
+				PlayerName:       playerName,
+				GeneralID:        generalID,
+				GeneralName:      general.Name,
+				Level:            general.Level,
+				Stars:            general.Stars,
+				Rarity:           general.Rarity,
+				AssignedAt:       time.Now(),
+				ContributionRate: contributionRate,
+			}
 
-		// Add to assigned generals list
-		m.AssignedGenerals = append(m.AssignedGenerals, assignedGeneral)
+			// Add to assigned generals list
+			m.AssignedGenerals = append(m.AssignedGenerals, assignedGeneral)
 
-		// Update mine status if this is the first general
-		if len(m.AssignedGenerals) == 1 {
-			m.Status = MineStatusDeveloping
-		}
+			// Update mine status if this is the first general
+			if len(m.AssignedGenerals) == 1 {
+				m.Status = MineStatusDeveloping
+			}
 
-		m.UpdatedAt = time.Now()
-		return m, nil
+			m.UpdatedAt = time.Now()
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mineEvent(DomainEventGeneralAssigned, mine, playerID, bson.M{
+			"general_id":        generalID,
+			"general_name":      general.Name,
+			"contribution_rate": contributionRate,
+		}), nil
 	})
 
 	if err != nil {
@@ -398,17 +512,26 @@ func (s *MineService) UnassignGeneralFromMine(
 	}
 
 	// Update mine
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
-		// Remove from assigned generals list
-		m.AssignedGenerals = append(m.AssignedGenerals[:foundIndex], m.AssignedGenerals[foundIndex+1:]...)
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
+			// Remove from assigned generals list
+			m.AssignedGenerals = append(m.AssignedGenerals[:foundIndex], m.AssignedGenerals[foundIndex+1:]...)
+
+			// Update mine status if no generals left
+			if len(m.AssignedGenerals) == 0 {
+				m.Status = MineStatusUndeveloped
+			}
 
-		// Update mine status if no generals left
-		if len(m.AssignedGenerals) == 0 {
-			m.Status = MineStatusUndeveloped
+			m.UpdatedAt = time.Now()
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		m.UpdatedAt = time.Now()
-		return m, nil
+		return mineEvent(DomainEventGeneralUnassigned, mine, playerID, bson.M{
+			"general_id": generalID,
+		}), nil
 	})
 
 	if err != nil {
@@ -448,32 +571,45 @@ func (s *MineService) UpdateMineDevelopment(ctx context.Context, mineID primitiv
 	}
 
 	// Update mine development points
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
-		m.DevelopmentPoints += totalPointsAdded
-		m.LastUpdatedAt = now
-
-		// Check if development is complete
-		if m.DevelopmentPoints >= m.RequiredPoints {
-			m.DevelopmentPoints = m.RequiredPoints
-			m.Status = MineStatusDeveloped
-
-			// Unassign all generals
-			for _, ag := range m.AssignedGenerals {
-				_, _ = s.generalService.UnassignGeneral(ctx, ag.GeneralID)
+	generals := mine.AssignedGenerals
+	var pointsAdded float64
+	err = s.outbox.run(ctx, func(sessCtx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(sessCtx, mineID, func(m *Mine) (*Mine, error) {
+			pointsBefore := m.DevelopmentPoints
+			m.DevelopmentPoints += totalPointsAdded
+			m.LastUpdatedAt = now
+
+			// Check if development is complete
+			if m.DevelopmentPoints >= m.RequiredPoints {
+				m.DevelopmentPoints = m.RequiredPoints
+				m.Status = MineStatusDeveloped
+
+				// Unassign all generals
+				for _, ag := range m.AssignedGenerals {
+					_, _ = s.generalService.UnassignGeneral(ctx, ag.GeneralID)
+				}
+
+				// Clear assigned generals list
+				m.AssignedGenerals = []AssignedGeneral{}
 			}
+			pointsAdded = m.DevelopmentPoints - pointsBefore
 
-			// Clear assigned generals list
-			m.AssignedGenerals = []AssignedGeneral{}
+			m.UpdatedAt = now
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		m.UpdatedAt = now
-		return m, nil
+		return developmentEvent(mine, pointsAdded), nil
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to update mine: %w", err)
 	}
 
+	s.emitDevelopment(mine, developmentContributions(generals, hoursSinceLastUpdate, pointsAdded))
+
 	// If mine development is complete, update transport tickets
 	if mine.Status == MineStatusDeveloped {
 		err = s.updateTransportTicketsForAlliance(ctx, mine.AllianceID, mine.Level)
@@ -510,24 +646,36 @@ func (s *MineService) updateTransportTicketsForAlliance(ctx context.Context, all
 	return nil
 }
 
-// ActivateMine activates a developed mine
-func (s *MineService) ActivateMine(ctx context.Context, mineID primitive.ObjectID) (*Mine, error) {
+// ActivateMine activates a developed mine on behalf of a member of the owning alliance
+func (s *MineService) ActivateMine(ctx context.Context, mineID primitive.ObjectID, playerID primitive.ObjectID) (*Mine, error) {
 	// Get the mine
 	mine, err := s.GetMine(ctx, mineID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get mine: %w", err)
 	}
 
+	// Only members of the owning alliance can activate the mine
+	if err := s.alliances.CheckMember(ctx, mine.AllianceID, playerID); err != nil {
+		return nil, err
+	}
+
 	// Check if mine is developed
 	if mine.Status != MineStatusDeveloped {
 		return nil, fmt.Errorf("mine is not developed")
 	}
 
 	// Update mine status
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
-		m.Status = MineStatusActive
-		m.UpdatedAt = time.Now()
-		return m, nil
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
+			m.Status = MineStatusActive
+			m.UpdatedAt = time.Now()
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mineEvent(DomainEventMineActivated, mine, playerID, nil), nil
 	})
 
 	if err != nil {
@@ -575,32 +723,45 @@ func (s *MineService) SimulateDevelopmentProgress(ctx context.Context, mineID pr
 	}
 
 	// Update mine development points
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
-		m.DevelopmentPoints += totalPointsAdded
-		m.LastUpdatedAt = time.Now()
-
-		// Check if development is complete
-		if m.DevelopmentPoints >= m.RequiredPoints {
-			m.DevelopmentPoints = m.RequiredPoints
-			m.Status = MineStatusDeveloped
-
-			// Unassign all generals
-			for _, ag := range m.AssignedGenerals {
-				_, _ = s.generalService.UnassignGeneral(ctx, ag.GeneralID)
+	generals := mine.AssignedGenerals
+	var pointsAdded float64
+	err = s.outbox.run(ctx, func(sessCtx context.Context) (*OutboxEvent, error) {
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(sessCtx, mineID, func(m *Mine) (*Mine, error) {
+			pointsBefore := m.DevelopmentPoints
+			m.DevelopmentPoints += totalPointsAdded
+			m.LastUpdatedAt = time.Now()
+
+			// Check if development is complete
+			if m.DevelopmentPoints >= m.RequiredPoints {
+				m.DevelopmentPoints = m.RequiredPoints
+				m.Status = MineStatusDeveloped
+
+				// Unassign all generals
+				for _, ag := range m.AssignedGenerals {
+					_, _ = s.generalService.UnassignGeneral(ctx, ag.GeneralID)
+				}
+
+				// Clear assigned generals list
+				m.AssignedGenerals = []AssignedGeneral{}
 			}
+			pointsAdded = m.DevelopmentPoints - pointsBefore
 
-			// Clear assigned generals list
-			m.AssignedGenerals = []AssignedGeneral{}
+			m.UpdatedAt = time.Now()
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		m.UpdatedAt = time.Now()
-		return m, nil
+		return developmentEvent(mine, pointsAdded), nil
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to update mine: %w", err)
 	}
 
+	s.emitDevelopment(mine, developmentContributions(generals, timeInHours, pointsAdded))
+
 	// If mine development is complete, update transport tickets
 	if mine.Status == MineStatusDeveloped {
 		err = s.updateTransportTicketsForAlliance(ctx, mine.AllianceID, mine.Level)
@@ -631,19 +792,28 @@ func (s *MineService) ForceCompleteDevelopment(ctx context.Context, mineID primi
 		return nil, fmt.Errorf("failed to get mine: %w", err)
 	}
 
-	mine, _, err = s.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
-		m.DevelopmentPoints = m.RequiredPoints
-		m.Status = MineStatusDeveloped
+	err = s.outbox.run(ctx, func(sessCtx context.Context) (*OutboxEvent, error) {
+		var pointsBefore float64
+		var err error
+		mine, _, err = s.storage.FindOneAndUpdate(sessCtx, mineID, func(m *Mine) (*Mine, error) {
+			pointsBefore = m.DevelopmentPoints
+			m.DevelopmentPoints = m.RequiredPoints
+			m.Status = MineStatusDeveloped
 
-		// Unassign all generals
-		for _, ag := range m.AssignedGenerals {
-			_, _ = s.generalService.UnassignGeneral(ctx, ag.GeneralID)
-		}
+			// Unassign all generals
+			for _, ag := range m.AssignedGenerals {
+				_, _ = s.generalService.UnassignGeneral(ctx, ag.GeneralID)
+			}
 
-		// Clear assigned generals list
-		m.AssignedGenerals = []AssignedGeneral{}
+			// Clear assigned generals list
+			m.AssignedGenerals = []AssignedGeneral{}
 
-		return m, nil
+			return m, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return developmentEvent(mine, mine.DevelopmentPoints-pointsBefore), nil
 	})
 
 	if err != nil {
@@ -669,3 +839,92 @@ func (s *MineService) UpdateMineWithFunction(ctx context.Context, mineID primiti
 func (s *MineService) GetGeneralService() *GeneralService {
 	return s.generalService
 }
+
+// DevelopmentContribution represents the development points a player's generals added to a mine
+type DevelopmentContribution struct {
+	PlayerID   primitive.ObjectID
+	PlayerName string
+	Points     float64
+}
+
+// DevelopmentEvent is emitted when generals add development points to a mine
+type DevelopmentEvent struct {
+	Mine          *Mine // Mine after the update
+	Contributions []DevelopmentContribution
+	OccurredAt    time.Time
+}
+
+// SubscribeDevelopment returns a channel that receives development events until ctx is cancelled.
+// Events are dropped for subscribers that fall more than eventBufferSize events behind.
+func (s *MineService) SubscribeDevelopment(ctx context.Context) <-chan DevelopmentEvent {
+	return s.events.subscribe(ctx)
+}
+
+// emitDevelopment sends a development event to every subscriber
+func (s *MineService) emitDevelopment(mine *Mine, contributions []DevelopmentContribution) {
+	if len(contributions) == 0 {
+		return
+	}
+
+	dropped := s.events.publish(func() DevelopmentEvent {
+		return DevelopmentEvent{
+			Mine:          mine.Copy(),
+			Contributions: append([]DevelopmentContribution(nil), contributions...),
+			OccurredAt:    time.Now(),
+		}
+	})
+	if dropped > 0 {
+		log.Printf("Dropped development event for mine %s: %d subscribers are not keeping up", mine.ID.Hex(), dropped)
+	}
+}
+
+// developmentContributions splits the development points added to a mine between the players
+// whose generals worked on it. When development completes, the points are capped at the
+// required points, so each player's share is scaled down to the points actually added.
+func developmentContributions(generals []AssignedGeneral, hours float64, pointsAdded float64) []DevelopmentContribution {
+	var total float64
+	for _, ag := range generals {
+		total += ag.ContributionRate * hours
+	}
+	if total <= 0 || pointsAdded <= 0 {
+		return nil
+	}
+
+	scale := pointsAdded / total
+	var contributions []DevelopmentContribution
+	for _, ag := range generals {
+		points := ag.ContributionRate * hours * scale
+
+		found := false
+		for i := range contributions {
+			if contributions[i].PlayerID == ag.PlayerID {
+				contributions[i].Points += points
+				found = true
+				break
+			}
+		}
+		if !found {
+			contributions = append(contributions, DevelopmentContribution{
+				PlayerID:   ag.PlayerID,
+				PlayerName: ag.PlayerName,
+				Points:     points,
+			})
+		}
+	}
+
+	return contributions
+}
+
+// developmentEvent creates the domain event for development points added to a mine
+func developmentEvent(mine *Mine, pointsAdded float64) *OutboxEvent {
+	eventType := DomainEventDevelopmentProgress
+	if mine.Status == MineStatusDeveloped {
+		eventType = DomainEventMineDeveloped
+	}
+
+	return mineEvent(eventType, mine, primitive.NilObjectID, bson.M{
+		"points_added":       pointsAdded,
+		"development_points": mine.DevelopmentPoints,
+		"required_points":    mine.RequiredPoints,
+	})
+}
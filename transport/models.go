@@ -3,6 +3,7 @@ package transport
 import (
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -28,6 +29,7 @@ const (
 	MineStatusDeveloped   MineStatus = "developed"   // 개발 완료
 	MineStatusActive      MineStatus = "active"      // 활성화 (채광 가능)
 	MineStatusInactive    MineStatus = "inactive"    // 비활성화
+	MineStatusContested   MineStatus = "contested"   // 분쟁 중 (적 연합의 공격을 받는 중)
 )
 
 // GeneralRarity represents the rarity of a general
@@ -64,6 +66,8 @@ type Mine struct {
 	RequiredPoints    float64            `bson:"required_points"`    // Required development points
 	AssignedGenerals  []AssignedGeneral  `bson:"assigned_generals"`  // Assigned generals for development
 	LastUpdatedAt     time.Time          `bson:"last_updated_at"`    // Last time development points were updated
+	Contest           *MineContest       `bson:"contest"`            // Latest attack by an enemy alliance
+	Shard             int                `bson:"shard"`              // Development processor shard the mine belongs to
 	CreatedAt         time.Time          `bson:"created_at"`
 	UpdatedAt         time.Time          `bson:"updated_at"`
 	VectorClock       int64              `bson:"vector_clock"` // For optimistic concurrency control
@@ -119,12 +123,78 @@ func (m *Mine) Copy() *Mine {
 		RequiredPoints:    m.RequiredPoints,
 		AssignedGenerals:  assignedGeneralsCopy,
 		LastUpdatedAt:     m.LastUpdatedAt,
+		Contest:           m.Contest.Copy(),
+		Shard:             m.Shard,
 		CreatedAt:         m.CreatedAt,
 		UpdatedAt:         m.UpdatedAt,
 		VectorClock:       m.VectorClock,
 	}
 }
 
+// MineContestOutcome represents how an attack on a mine ended
+type MineContestOutcome string
+
+// Mine contest outcome constants
+const (
+	MineContestRepelled MineContestOutcome = "repelled" // 방어 성공
+	MineContestPillaged MineContestOutcome = "pillaged" // 약탈됨 (개발 진행도 손실)
+	MineContestCaptured MineContestOutcome = "captured" // 점령됨 (소유권 이전)
+)
+
+// MineContest represents an attack by an enemy alliance on a developed mine
+type MineContest struct {
+	AttackerAllianceID primitive.ObjectID `bson:"attacker_alliance_id"`
+	Attacker           CombatForce        `bson:"attacker"`
+	Defender           *CombatForce       `bson:"defender"` // Nil until an alliance member defends
+	Seed               int64              `bson:"seed"`     // Random seed the battle is fought with
+	PreviousStatus     MineStatus         `bson:"previous_status"`
+	AttackStartTime    time.Time          `bson:"attack_start_time"`
+	DefenseEndTime     time.Time          `bson:"defense_end_time"`
+	Outcome            MineContestOutcome `bson:"outcome"` // Empty until the battle is resolved
+	Rounds             []BattleRound      `bson:"rounds"`
+	DevelopmentLost    float64            `bson:"development_lost"`
+	ResolvedAt         *time.Time         `bson:"resolved_at"`
+}
+
+// Copy creates a deep copy of the MineContest
+func (mc *MineContest) Copy() *MineContest {
+	if mc == nil {
+		return nil
+	}
+
+	var defenderCopy *CombatForce
+	if mc.Defender != nil {
+		d := mc.Defender.Copy()
+		defenderCopy = &d
+	}
+
+	var roundsCopy []BattleRound
+	if mc.Rounds != nil {
+		roundsCopy = make([]BattleRound, len(mc.Rounds))
+		copy(roundsCopy, mc.Rounds)
+	}
+
+	var resolvedAtCopy *time.Time
+	if mc.ResolvedAt != nil {
+		r := *mc.ResolvedAt
+		resolvedAtCopy = &r
+	}
+
+	return &MineContest{
+		AttackerAllianceID: mc.AttackerAllianceID,
+		Attacker:           mc.Attacker.Copy(),
+		Defender:           defenderCopy,
+		Seed:               mc.Seed,
+		PreviousStatus:     mc.PreviousStatus,
+		AttackStartTime:    mc.AttackStartTime,
+		DefenseEndTime:     mc.DefenseEndTime,
+		Outcome:            mc.Outcome,
+		Rounds:             roundsCopy,
+		DevelopmentLost:    mc.DevelopmentLost,
+		ResolvedAt:         resolvedAtCopy,
+	}
+}
+
 // TransportStatus represents the status of a transport
 type TransportStatus string
 
@@ -153,6 +223,8 @@ type Transport struct {
 	StartTime       *time.Time         `bson:"start_time"`       // When transport started
 	EndTime         *time.Time         `bson:"end_time"`         // When transport will end/ended
 	RaidStatus      *RaidStatus        `bson:"raid_status"`      // Raid status if being raided
+	ArrivedAt       *time.Time         `bson:"arrived_at"`       // When the arrival was resolved
+	Rewards         []TransportReward  `bson:"rewards"`          // Gold ore each participant received on arrival
 	CreatedAt       time.Time          `bson:"created_at"`
 	UpdatedAt       time.Time          `bson:"updated_at"`
 	VectorClock     int64              `bson:"vector_clock"` // For optimistic concurrency control
@@ -181,10 +253,16 @@ func (t *Transport) Copy() *Transport {
 		endTimeCopy = &et
 	}
 
-	var raidStatusCopy *RaidStatus
-	if t.RaidStatus != nil {
-		rs := *t.RaidStatus
-		raidStatusCopy = &rs
+	var arrivedAtCopy *time.Time
+	if t.ArrivedAt != nil {
+		at := *t.ArrivedAt
+		arrivedAtCopy = &at
+	}
+
+	var rewardsCopy []TransportReward
+	if t.Rewards != nil {
+		rewardsCopy = make([]TransportReward, len(t.Rewards))
+		copy(rewardsCopy, t.Rewards)
 	}
 
 	return &Transport{
@@ -202,7 +280,9 @@ func (t *Transport) Copy() *Transport {
 		TransportTime:   t.TransportTime,
 		StartTime:       startTimeCopy,
 		EndTime:         endTimeCopy,
-		RaidStatus:      raidStatusCopy,
+		RaidStatus:      t.RaidStatus.Copy(),
+		ArrivedAt:       arrivedAtCopy,
+		Rewards:         rewardsCopy,
 		CreatedAt:       t.CreatedAt,
 		UpdatedAt:       t.UpdatedAt,
 		VectorClock:     t.VectorClock,
@@ -227,6 +307,13 @@ func (tm TransportMember) Copy() TransportMember {
 	}
 }
 
+// TransportReward represents the gold ore a participant received when a transport arrived
+type TransportReward struct {
+	PlayerID   primitive.ObjectID `bson:"player_id"`
+	PlayerName string             `bson:"player_name"`
+	GoldOre    int                `bson:"gold_ore"` // Share of the delivered gold ore
+}
+
 // RaidStatus represents the status of a raid on a transport
 type RaidStatus struct {
 	RaiderID       primitive.ObjectID `bson:"raider_id"`
@@ -235,15 +322,146 @@ type RaidStatus struct {
 	DefenseEndTime time.Time          `bson:"defense_end_time"` // When the defense window ends
 	IsDefended     bool               `bson:"is_defended"`      // Whether the raid has been defended
 	DefenseResult  *DefenseResult     `bson:"defense_result"`   // Result of the defense, if completed
+	Attacker       CombatForce        `bson:"attacker"`         // Force the raider sent
+	Seed           int64              `bson:"seed"`             // Random seed of the battle, so the outcome can be replayed
+}
+
+// Copy creates a deep copy of the RaidStatus
+func (rs *RaidStatus) Copy() *RaidStatus {
+	if rs == nil {
+		return nil
+	}
+
+	var defenseResultCopy *DefenseResult
+	if rs.DefenseResult != nil {
+		dr := *rs.DefenseResult
+		defenseResultCopy = &dr
+	}
+
+	return &RaidStatus{
+		RaiderID:       rs.RaiderID,
+		RaiderName:     rs.RaiderName,
+		RaidStartTime:  rs.RaidStartTime,
+		DefenseEndTime: rs.DefenseEndTime,
+		IsDefended:     rs.IsDefended,
+		DefenseResult:  defenseResultCopy,
+		Attacker:       rs.Attacker.Copy(),
+		Seed:           rs.Seed,
+	}
 }
 
 // DefenseResult represents the result of a defense against a raid
 type DefenseResult struct {
-	Successful   bool               `bson:"successful"`    // Whether the defense was successful
-	DefenderID   primitive.ObjectID `bson:"defender_id"`   // ID of the player who defended
-	DefenderName string             `bson:"defender_name"` // Name of the player who defended
-	CompletedAt  time.Time          `bson:"completed_at"`  // When the defense was completed
-	GoldOreLost  int                `bson:"gold_ore_lost"` // Amount of gold ore lost if defense failed
+	Successful     bool               `bson:"successful"`       // Whether the defense was successful
+	DefenderID     primitive.ObjectID `bson:"defender_id"`      // ID of the player who defended
+	DefenderName   string             `bson:"defender_name"`    // Name of the player who defended
+	CompletedAt    time.Time          `bson:"completed_at"`     // When the defense was completed
+	GoldOreLost    int                `bson:"gold_ore_lost"`    // Amount of gold ore lost if defense failed
+	GoldOreStolen  int                `bson:"gold_ore_stolen"`  // Part of the lost gold ore the raider carried away
+	BattleReportID primitive.ObjectID `bson:"battle_report_id"` // Report of the battle that decided the raid
+}
+
+// CombatGeneral represents a general fighting in a battle, with the stats it had when the battle started
+type CombatGeneral struct {
+	GeneralID primitive.ObjectID `bson:"general_id"`
+	Name      string             `bson:"name"`
+	Level     int                `bson:"level"`
+	Stars     int                `bson:"stars"`
+	Rarity    GeneralRarity      `bson:"rarity"`
+}
+
+// CombatForce represents the generals and troops one side brings to a battle
+type CombatForce struct {
+	PlayerID   primitive.ObjectID `bson:"player_id"`
+	PlayerName string             `bson:"player_name"`
+	AllianceID primitive.ObjectID `bson:"alliance_id"`
+	Generals   []CombatGeneral    `bson:"generals"`
+	Troops     int                `bson:"troops"`
+}
+
+// Copy creates a deep copy of the CombatForce
+func (cf CombatForce) Copy() CombatForce {
+	var generalsCopy []CombatGeneral
+	if cf.Generals != nil {
+		generalsCopy = make([]CombatGeneral, len(cf.Generals))
+		copy(generalsCopy, cf.Generals)
+	}
+
+	return CombatForce{
+		PlayerID:   cf.PlayerID,
+		PlayerName: cf.PlayerName,
+		AllianceID: cf.AllianceID,
+		Generals:   generalsCopy,
+		Troops:     cf.Troops,
+	}
+}
+
+// BattleOutcome represents which side won a battle
+type BattleOutcome string
+
+// Battle outcome constants
+const (
+	BattleOutcomeAttackerWon BattleOutcome = "attacker_won" // 약탈 성공
+	BattleOutcomeDefenderWon BattleOutcome = "defender_won" // 방어 성공
+)
+
+// BattleRound represents one exchange of blows in a battle
+type BattleRound struct {
+	Round          int `bson:"round"`
+	AttackerDamage int `bson:"attacker_damage"` // Defender troops lost to the attacker
+	DefenderDamage int `bson:"defender_damage"` // Attacker troops lost to the defender
+	AttackerTroops int `bson:"attacker_troops"` // Attacker troops left after the round
+	DefenderTroops int `bson:"defender_troops"` // Defender troops left after the round
+}
+
+// BattleReport records how a raid on a transport was decided
+type BattleReport struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	TransportID   primitive.ObjectID `bson:"transport_id"`
+	AllianceID    primitive.ObjectID `bson:"alliance_id"` // Alliance that owns the transport
+	Attacker      CombatForce        `bson:"attacker"`
+	Defender      CombatForce        `bson:"defender"`        // Empty when nobody defended
+	Defended      bool               `bson:"defended"`        // Whether a defender fought the battle
+	Seed          int64              `bson:"seed"`            // Random seed the battle was resolved with
+	Rounds        []BattleRound      `bson:"rounds"`          // Rounds fought, in order
+	Outcome       BattleOutcome      `bson:"outcome"`         // Which side won
+	GoldOreBefore int                `bson:"gold_ore_before"` // Gold ore the transport carried before the battle
+	GoldOreLost   int                `bson:"gold_ore_lost"`   // Gold ore taken from the transport
+	GoldOreStolen int                `bson:"gold_ore_stolen"` // Part of the lost gold ore credited to the raider
+	CreatedAt     time.Time          `bson:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at"`
+	VectorClock   int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the BattleReport
+func (br *BattleReport) Copy() *BattleReport {
+	if br == nil {
+		return nil
+	}
+
+	var roundsCopy []BattleRound
+	if br.Rounds != nil {
+		roundsCopy = make([]BattleRound, len(br.Rounds))
+		copy(roundsCopy, br.Rounds)
+	}
+
+	return &BattleReport{
+		ID:            br.ID,
+		TransportID:   br.TransportID,
+		AllianceID:    br.AllianceID,
+		Attacker:      br.Attacker.Copy(),
+		Defender:      br.Defender.Copy(),
+		Defended:      br.Defended,
+		Seed:          br.Seed,
+		Rounds:        roundsCopy,
+		Outcome:       br.Outcome,
+		GoldOreBefore: br.GoldOreBefore,
+		GoldOreLost:   br.GoldOreLost,
+		GoldOreStolen: br.GoldOreStolen,
+		CreatedAt:     br.CreatedAt,
+		UpdatedAt:     br.UpdatedAt,
+		VectorClock:   br.VectorClock,
+	}
 }
 
 // TransportTicket represents a player's transport tickets
@@ -252,8 +470,10 @@ type TransportTicket struct {
 	PlayerID       primitive.ObjectID `bson:"player_id"`
 	AllianceID     primitive.ObjectID `bson:"alliance_id"`
 	CurrentTickets int                `bson:"current_tickets"`
+	HeldTickets    int                `bson:"held_tickets"` // Tickets held by transports that have not arrived yet
 	MaxTickets     int                `bson:"max_tickets"`
 	LastRefillTime time.Time          `bson:"last_refill_time"`
+	LastRegenTime  time.Time          `bson:"last_regen_time"`  // Start of the current regeneration interval
 	PurchaseCount  int                `bson:"purchase_count"`   // Number of purchases today
 	LastPurchaseAt *time.Time         `bson:"last_purchase_at"` // Time of last purchase
 	ResetTime      time.Time          `bson:"reset_time"`       // When purchase count resets
@@ -279,8 +499,10 @@ func (tt *TransportTicket) Copy() *TransportTicket {
 		PlayerID:       tt.PlayerID,
 		AllianceID:     tt.AllianceID,
 		CurrentTickets: tt.CurrentTickets,
+		HeldTickets:    tt.HeldTickets,
 		MaxTickets:     tt.MaxTickets,
 		LastRefillTime: tt.LastRefillTime,
+		LastRegenTime:  tt.LastRegenTime,
 		PurchaseCount:  tt.PurchaseCount,
 		LastPurchaseAt: lastPurchaseAtCopy,
 		ResetTime:      tt.ResetTime,
@@ -296,10 +518,14 @@ type General struct {
 	PlayerID    primitive.ObjectID `bson:"player_id"`
 	Name        string             `bson:"name"`
 	Level       int                `bson:"level"`
-	Stars       int                `bson:"stars"`       // 성급
-	Rarity      GeneralRarity      `bson:"rarity"`      // 희귀도
-	Status      GeneralStatus      `bson:"status"`      // 상태
-	AssignedTo  *AssignmentInfo    `bson:"assigned_to"` // 배치 정보
+	Stars       int                `bson:"stars"`              // 성급
+	Rarity      GeneralRarity      `bson:"rarity"`             // 희귀도
+	Status      GeneralStatus      `bson:"status"`             // 상태
+	AssignedTo  *AssignmentInfo    `bson:"assigned_to"`        // 배치 정보
+	Stamina     float64            `bson:"stamina"`            // 체력 (0-100)
+	RecoveredAt time.Time          `bson:"recovered_at"`       // 체력 회복 계산 시각
+	HealsAt     *time.Time         `bson:"heals_at,omitempty"` // 부상 회복 시각
+	Shards      int                `bson:"shards"`             // 중복 모집으로 얻은 조각
 	CreatedAt   time.Time          `bson:"created_at"`
 	UpdatedAt   time.Time          `bson:"updated_at"`
 	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
@@ -317,6 +543,12 @@ func (g *General) Copy() *General {
 		assignedToCopy = &ai
 	}
 
+	var healsAtCopy *time.Time
+	if g.HealsAt != nil {
+		t := *g.HealsAt
+		healsAtCopy = &t
+	}
+
 	return &General{
 		ID:          g.ID,
 		PlayerID:    g.PlayerID,
@@ -326,6 +558,10 @@ func (g *General) Copy() *General {
 		Rarity:      g.Rarity,
 		Status:      g.Status,
 		AssignedTo:  assignedToCopy,
+		Stamina:     g.Stamina,
+		RecoveredAt: g.RecoveredAt,
+		HealsAt:     healsAtCopy,
+		Shards:      g.Shards,
 		CreatedAt:   g.CreatedAt,
 		UpdatedAt:   g.UpdatedAt,
 		VectorClock: g.VectorClock,
@@ -384,3 +620,584 @@ func (mc *MineConfig) Copy() *MineConfig {
 		VectorClock:        mc.VectorClock,
 	}
 }
+
+// TradeItemType represents the kind of item that can be traded between players
+type TradeItemType string
+
+// Trade item type constants
+const (
+	TradeItemTicket  TradeItemType = "ticket"   // 이송권
+	TradeItemGoldOre TradeItemType = "gold_ore" // 금광석
+)
+
+// TradeStatus represents the status of a trade
+type TradeStatus string
+
+// Trade status constants
+const (
+	TradeStatusPending   TradeStatus = "pending"   // 수락 대기 중
+	TradeStatusAccepted  TradeStatus = "accepted"  // 수락됨
+	TradeStatusDeclined  TradeStatus = "declined"  // 거절됨
+	TradeStatusCancelled TradeStatus = "cancelled" // 취소됨
+	TradeStatusExpired   TradeStatus = "expired"   // 만료됨
+)
+
+// Trade represents a gift from one alliance member to another.
+// The sender's items are held in escrow while the trade is pending and are
+// either credited to the receiver (accept) or refunded to the sender (decline, cancel, expiry).
+type Trade struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	AllianceID   primitive.ObjectID `bson:"alliance_id"`
+	SenderID     primitive.ObjectID `bson:"sender_id"`
+	SenderName   string             `bson:"sender_name"`
+	ReceiverID   primitive.ObjectID `bson:"receiver_id"`
+	ReceiverName string             `bson:"receiver_name"`
+	ItemType     TradeItemType      `bson:"item_type"`
+	Amount       int                `bson:"amount"`
+	Status       TradeStatus        `bson:"status"`
+	ExpiresAt    time.Time          `bson:"expires_at"`  // When the pending trade expires
+	ResolvedAt   *time.Time         `bson:"resolved_at"` // When the trade left the pending state
+	CreatedAt    time.Time          `bson:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at"`
+	VectorClock  int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the Trade
+func (t *Trade) Copy() *Trade {
+	if t == nil {
+		return nil
+	}
+
+	var resolvedAtCopy *time.Time
+	if t.ResolvedAt != nil {
+		ra := *t.ResolvedAt
+		resolvedAtCopy = &ra
+	}
+
+	return &Trade{
+		ID:           t.ID,
+		AllianceID:   t.AllianceID,
+		SenderID:     t.SenderID,
+		SenderName:   t.SenderName,
+		ReceiverID:   t.ReceiverID,
+		ReceiverName: t.ReceiverName,
+		ItemType:     t.ItemType,
+		Amount:       t.Amount,
+		Status:       t.Status,
+		ExpiresAt:    t.ExpiresAt,
+		ResolvedAt:   resolvedAtCopy,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+		VectorClock:  t.VectorClock,
+	}
+}
+
+// PlayerInventory represents the gold ore held by a player
+type PlayerInventory struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	PlayerID    primitive.ObjectID `bson:"player_id"`
+	AllianceID  primitive.ObjectID `bson:"alliance_id"`
+	GoldOre     int                `bson:"gold_ore"`
+	Speedups    int                `bson:"speedups"` // Speed-up items, each skipping speedupItemDuration
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the PlayerInventory
+func (pi *PlayerInventory) Copy() *PlayerInventory {
+	if pi == nil {
+		return nil
+	}
+	return &PlayerInventory{
+		ID:          pi.ID,
+		PlayerID:    pi.PlayerID,
+		AllianceID:  pi.AllianceID,
+		GoldOre:     pi.GoldOre,
+		Speedups:    pi.Speedups,
+		CreatedAt:   pi.CreatedAt,
+		UpdatedAt:   pi.UpdatedAt,
+		VectorClock: pi.VectorClock,
+	}
+}
+
+// TradeLedgerEntry records a single balance movement caused by a trade.
+// Every trade produces one debit entry when it is created and one credit entry when it is resolved.
+type TradeLedgerEntry struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	TradeID     primitive.ObjectID `bson:"trade_id"`
+	PlayerID    primitive.ObjectID `bson:"player_id"`
+	AllianceID  primitive.ObjectID `bson:"alliance_id"`
+	ItemType    TradeItemType      `bson:"item_type"`
+	Delta       int                `bson:"delta"`   // Positive for credits, negative for debits
+	Balance     int                `bson:"balance"` // Player balance after the entry was applied
+	Reason      string             `bson:"reason"`  // "escrow", "accept", "refund"
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the TradeLedgerEntry
+func (le *TradeLedgerEntry) Copy() *TradeLedgerEntry {
+	if le == nil {
+		return nil
+	}
+	return &TradeLedgerEntry{
+		ID:          le.ID,
+		TradeID:     le.TradeID,
+		PlayerID:    le.PlayerID,
+		AllianceID:  le.AllianceID,
+		ItemType:    le.ItemType,
+		Delta:       le.Delta,
+		Balance:     le.Balance,
+		Reason:      le.Reason,
+		CreatedAt:   le.CreatedAt,
+		UpdatedAt:   le.UpdatedAt,
+		VectorClock: le.VectorClock,
+	}
+}
+
+// LeaderboardPeriod represents the time window a leaderboard covers
+type LeaderboardPeriod string
+
+// Leaderboard period constants
+const (
+	LeaderboardPeriodDaily  LeaderboardPeriod = "daily"  // 일간
+	LeaderboardPeriodWeekly LeaderboardPeriod = "weekly" // 주간
+)
+
+// ContributionMetric represents what a leaderboard ranks players by
+type ContributionMetric string
+
+// Contribution metric constants
+const (
+	ContributionMetricDevelopmentPoints ContributionMetric = "development_points" // 개발 점수
+	ContributionMetricTransportedGold   ContributionMetric = "transported_gold"   // 이송한 금광석
+	ContributionMetricDefensesWon       ContributionMetric = "defenses_won"       // 방어 성공 횟수
+)
+
+// AllianceLeaderboard holds the contributions of an alliance's players during one period.
+// There is one document per alliance, period and period start.
+type AllianceLeaderboard struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty"`
+	AllianceID  primitive.ObjectID   `bson:"alliance_id"`
+	Period      LeaderboardPeriod    `bson:"period"`
+	PeriodStart time.Time            `bson:"period_start"`
+	PeriodEnd   time.Time            `bson:"period_end"`
+	Entries     []PlayerContribution `bson:"entries"` // One entry per contributing player
+	CreatedAt   time.Time            `bson:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at"`
+	VectorClock int64                `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// PlayerContribution represents what a player contributed to the alliance during a period
+type PlayerContribution struct {
+	PlayerID          primitive.ObjectID `bson:"player_id"`
+	PlayerName        string             `bson:"player_name"`
+	DevelopmentPoints float64            `bson:"development_points"` // Mine development points added by the player's generals
+	TransportedGold   int                `bson:"transported_gold"`   // Gold ore delivered by the player's transports
+	DefensesWon       int                `bson:"defenses_won"`       // Raids the player defended successfully
+}
+
+// Copy creates a deep copy of the AllianceLeaderboard
+func (lb *AllianceLeaderboard) Copy() *AllianceLeaderboard {
+	if lb == nil {
+		return nil
+	}
+
+	entriesCopy := make([]PlayerContribution, len(lb.Entries))
+	copy(entriesCopy, lb.Entries)
+
+	return &AllianceLeaderboard{
+		ID:          lb.ID,
+		AllianceID:  lb.AllianceID,
+		Period:      lb.Period,
+		PeriodStart: lb.PeriodStart,
+		PeriodEnd:   lb.PeriodEnd,
+		Entries:     entriesCopy,
+		CreatedAt:   lb.CreatedAt,
+		UpdatedAt:   lb.UpdatedAt,
+		VectorClock: lb.VectorClock,
+	}
+}
+
+// OutboxEvent represents a domain event recorded with the state change it describes,
+// kept until it has been published to clients
+type OutboxEvent struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	Type         DomainEventType    `bson:"type"`
+	Aggregate    AggregateType      `bson:"aggregate"`    // 변경된 대상 종류
+	AggregateID  primitive.ObjectID `bson:"aggregate_id"` // 변경된 광산/이송권/이송 ID
+	AllianceID   primitive.ObjectID `bson:"alliance_id"`
+	PlayerID     primitive.ObjectID `bson:"player_id,omitempty"` // Player who caused the change, if any
+	Data         bson.M             `bson:"data,omitempty"`      // Event details, e.g. amounts and the resulting state
+	OccurredAt   time.Time          `bson:"occurred_at"`
+	DispatchedAt *time.Time         `bson:"dispatched_at"` // Nil until the event has been published
+	Attempts     int                `bson:"attempts"`
+	LastError    string             `bson:"last_error,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at"`
+	VectorClock  int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the OutboxEvent
+func (e *OutboxEvent) Copy() *OutboxEvent {
+	if e == nil {
+		return nil
+	}
+
+	var dataCopy bson.M
+	if e.Data != nil {
+		dataCopy = make(bson.M, len(e.Data))
+		for k, v := range e.Data {
+			dataCopy[k] = v
+		}
+	}
+
+	var dispatchedAtCopy *time.Time
+	if e.DispatchedAt != nil {
+		t := *e.DispatchedAt
+		dispatchedAtCopy = &t
+	}
+
+	return &OutboxEvent{
+		ID:           e.ID,
+		Type:         e.Type,
+		Aggregate:    e.Aggregate,
+		AggregateID:  e.AggregateID,
+		AllianceID:   e.AllianceID,
+		PlayerID:     e.PlayerID,
+		Data:         dataCopy,
+		OccurredAt:   e.OccurredAt,
+		DispatchedAt: dispatchedAtCopy,
+		Attempts:     e.Attempts,
+		LastError:    e.LastError,
+		CreatedAt:    e.CreatedAt,
+		UpdatedAt:    e.UpdatedAt,
+		VectorClock:  e.VectorClock,
+	}
+}
+
+// IdempotencyKey represents a client-provided idempotency key claimed by a player action.
+// Its ID is derived from the player, the action and the key, so each key can only be claimed once.
+type IdempotencyKey struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty"`
+	PlayerID    primitive.ObjectID   `bson:"player_id"`
+	Action      IdempotentAction     `bson:"action"`
+	Key         string               `bson:"key"`
+	Request     string               `bson:"request"`    // Parameters of the first request; retries must match
+	ClaimID     primitive.ObjectID   `bson:"claim_id"`   // Identifies the call that claimed the key
+	ClaimedAt   time.Time            `bson:"claimed_at"` // When that call claimed the key
+	Status      IdempotencyKeyStatus `bson:"status"`
+	ResultID    primitive.ObjectID   `bson:"result_id,omitempty"` // Transport, ticket or mine the action returned
+	Cost        int                  `bson:"cost,omitempty"`      // Gems paid, for ticket purchases
+	ExpiresAt   time.Time            `bson:"expires_at"`
+	CreatedAt   time.Time            `bson:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at"`
+	VectorClock int64                `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the IdempotencyKey
+func (k *IdempotencyKey) Copy() *IdempotencyKey {
+	if k == nil {
+		return nil
+	}
+	return &IdempotencyKey{
+		ID:          k.ID,
+		PlayerID:    k.PlayerID,
+		Action:      k.Action,
+		Key:         k.Key,
+		Request:     k.Request,
+		ClaimID:     k.ClaimID,
+		ClaimedAt:   k.ClaimedAt,
+		Status:      k.Status,
+		ResultID:    k.ResultID,
+		Cost:        k.Cost,
+		ExpiresAt:   k.ExpiresAt,
+		CreatedAt:   k.CreatedAt,
+		UpdatedAt:   k.UpdatedAt,
+		VectorClock: k.VectorClock,
+	}
+}
+
+// SpeedupSpend records the items or gems a player spent to speed up a mine or a transport,
+// for analytics
+type SpeedupSpend struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	PlayerID    primitive.ObjectID `bson:"player_id"`
+	AllianceID  primitive.ObjectID `bson:"alliance_id"`
+	Target      SpeedupTarget      `bson:"target"`
+	TargetID    primitive.ObjectID `bson:"target_id"` // Mine or transport that was sped up
+	Payment     SpeedupPayment     `bson:"payment"`
+	Requested   time.Duration      `bson:"requested"` // Time the player asked to skip
+	Skipped     time.Duration      `bson:"skipped"`   // Time actually skipped, never more than was left
+	ItemsSpent  int                `bson:"items_spent"`
+	GemsSpent   int                `bson:"gems_spent"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the SpeedupSpend
+func (ss *SpeedupSpend) Copy() *SpeedupSpend {
+	if ss == nil {
+		return nil
+	}
+	return &SpeedupSpend{
+		ID:          ss.ID,
+		PlayerID:    ss.PlayerID,
+		AllianceID:  ss.AllianceID,
+		Target:      ss.Target,
+		TargetID:    ss.TargetID,
+		Payment:     ss.Payment,
+		Requested:   ss.Requested,
+		Skipped:     ss.Skipped,
+		ItemsSpent:  ss.ItemsSpent,
+		GemsSpent:   ss.GemsSpent,
+		CreatedAt:   ss.CreatedAt,
+		UpdatedAt:   ss.UpdatedAt,
+		VectorClock: ss.VectorClock,
+	}
+}
+
+// BalanceConfig holds the game-balance rules designers can tune without a redeploy.
+// Every published change is stored as a new version; the highest version is in effect.
+type BalanceConfig struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty"`
+	Version      int                 `bson:"version"`
+	Contribution ContributionBalance `bson:"contribution"`
+	MineLevels   []MineLevelBalance  `bson:"mine_levels"` // Ordered by level
+	TicketPrice  TicketPriceBalance  `bson:"ticket_price"`
+	Recruitment  RecruitmentBalance  `bson:"recruitment"`
+	Note         string              `bson:"note,omitempty"` // Why the change was made
+	CreatedAt    time.Time           `bson:"created_at"`
+	UpdatedAt    time.Time           `bson:"updated_at"`
+	VectorClock  int64               `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// ContributionBalance holds how many development points per hour a general contributes to a mine:
+// BaseRate * (1 + Stars*StarBonus + (Level-1)*LevelBonus) * rarity multiplier
+type ContributionBalance struct {
+	BaseRate                float64                   `bson:"base_rate"`                 // Points per hour before bonuses
+	StarBonus               float64                   `bson:"star_bonus"`                // Bonus per star
+	LevelBonus              float64                   `bson:"level_bonus"`               // Bonus per level above 1
+	RarityMultipliers       map[GeneralRarity]float64 `bson:"rarity_multipliers"`        // Multiplier per rarity
+	DefaultRarityMultiplier float64                   `bson:"default_rarity_multiplier"` // Multiplier for rarities not listed
+}
+
+// MineLevelBalance holds the development settings of a mine level
+type MineLevelBalance struct {
+	Level              MineLevel `bson:"level"`
+	RequiredPoints     float64   `bson:"required_points"`      // Required development points
+	TransportTicketMax int       `bson:"transport_ticket_max"` // Max transport tickets after development
+}
+
+// TicketPriceBalance holds the price of a purchased ticket: BasePrice + purchases today * PriceIncrease
+type TicketPriceBalance struct {
+	BasePrice     int `bson:"base_price"`
+	PriceIncrease int `bson:"price_increase"` // Added for every ticket already purchased today
+}
+
+// RecruitmentBalance holds the odds of general recruitment: a rarity is drawn by weight among the
+// rarities in the pool, then one of the pool's generals of that rarity with equal chance
+type RecruitmentBalance struct {
+	RarityWeights   map[GeneralRarity]int `bson:"rarity_weights"`   // Relative chance of each rarity
+	DuplicateShards map[GeneralRarity]int `bson:"duplicate_shards"` // Shards given for a general already owned
+	Pool            []RecruitableGeneral  `bson:"pool"`             // Generals that can be recruited
+}
+
+// RecruitableGeneral is a general that can be recruited
+type RecruitableGeneral struct {
+	Name   string        `bson:"name"`
+	Rarity GeneralRarity `bson:"rarity"`
+}
+
+// Copy creates a deep copy of the BalanceConfig
+func (bc *BalanceConfig) Copy() *BalanceConfig {
+	if bc == nil {
+		return nil
+	}
+
+	contribution := bc.Contribution
+	if bc.Contribution.RarityMultipliers != nil {
+		contribution.RarityMultipliers = make(map[GeneralRarity]float64, len(bc.Contribution.RarityMultipliers))
+		for rarity, multiplier := range bc.Contribution.RarityMultipliers {
+			contribution.RarityMultipliers[rarity] = multiplier
+		}
+	}
+
+	var mineLevels []MineLevelBalance
+	if bc.MineLevels != nil {
+		mineLevels = make([]MineLevelBalance, len(bc.MineLevels))
+		copy(mineLevels, bc.MineLevels)
+	}
+
+	recruitment := RecruitmentBalance{}
+	if bc.Recruitment.RarityWeights != nil {
+		recruitment.RarityWeights = make(map[GeneralRarity]int, len(bc.Recruitment.RarityWeights))
+		for rarity, weight := range bc.Recruitment.RarityWeights {
+			recruitment.RarityWeights[rarity] = weight
+		}
+	}
+	if bc.Recruitment.DuplicateShards != nil {
+		recruitment.DuplicateShards = make(map[GeneralRarity]int, len(bc.Recruitment.DuplicateShards))
+		for rarity, shards := range bc.Recruitment.DuplicateShards {
+			recruitment.DuplicateShards[rarity] = shards
+		}
+	}
+	if bc.Recruitment.Pool != nil {
+		recruitment.Pool = make([]RecruitableGeneral, len(bc.Recruitment.Pool))
+		copy(recruitment.Pool, bc.Recruitment.Pool)
+	}
+
+	return &BalanceConfig{
+		ID:           bc.ID,
+		Version:      bc.Version,
+		Contribution: contribution,
+		MineLevels:   mineLevels,
+		TicketPrice:  bc.TicketPrice,
+		Recruitment:  recruitment,
+		Note:         bc.Note,
+		CreatedAt:    bc.CreatedAt,
+		UpdatedAt:    bc.UpdatedAt,
+		VectorClock:  bc.VectorClock,
+	}
+}
+
+// AllianceMember represents a player's membership of an alliance.
+// A player belongs to at most one alliance; the ID is derived from the player ID.
+type AllianceMember struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	AllianceID  primitive.ObjectID `bson:"alliance_id"`
+	PlayerID    primitive.ObjectID `bson:"player_id"`
+	PlayerName  string             `bson:"player_name"`
+	Role        AllianceRole       `bson:"role"`
+	JoinedAt    time.Time          `bson:"joined_at"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the AllianceMember
+func (am *AllianceMember) Copy() *AllianceMember {
+	if am == nil {
+		return nil
+	}
+	return &AllianceMember{
+		ID:          am.ID,
+		AllianceID:  am.AllianceID,
+		PlayerID:    am.PlayerID,
+		PlayerName:  am.PlayerName,
+		Role:        am.Role,
+		JoinedAt:    am.JoinedAt,
+		CreatedAt:   am.CreatedAt,
+		UpdatedAt:   am.UpdatedAt,
+		VectorClock: am.VectorClock,
+	}
+}
+
+// MineSnapshot records the state of a mine after one of its changes, for audits.
+// The ID is derived from the mine ID and version, so a change is recorded at most once.
+type MineSnapshot struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	MineID      primitive.ObjectID `bson:"mine_id"`
+	AllianceID  primitive.ObjectID `bson:"alliance_id"` // Owner at the time of the change
+	Version     int64              `bson:"version"`     // Vector clock of the mine after the change
+	Mine        *Mine              `bson:"mine"`
+	RecordedAt  time.Time          `bson:"recorded_at"` // When the change was made
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the MineSnapshot
+func (ms *MineSnapshot) Copy() *MineSnapshot {
+	if ms == nil {
+		return nil
+	}
+	return &MineSnapshot{
+		ID:          ms.ID,
+		MineID:      ms.MineID,
+		AllianceID:  ms.AllianceID,
+		Version:     ms.Version,
+		Mine:        ms.Mine.Copy(),
+		RecordedAt:  ms.RecordedAt,
+		CreatedAt:   ms.CreatedAt,
+		UpdatedAt:   ms.UpdatedAt,
+		VectorClock: ms.VectorClock,
+	}
+}
+
+// ShardLock records which worker processes a shard of a batch job until the lock expires.
+// The ID is derived from the job name and shard, so there is one lock per shard.
+type ShardLock struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Job         string             `bson:"job"`
+	Shard       int                `bson:"shard"`
+	Owner       string             `bson:"owner"`      // Worker holding the lock, empty if released
+	ExpiresAt   time.Time          `bson:"expires_at"` // When other workers can take the lock over
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the ShardLock
+func (sl *ShardLock) Copy() *ShardLock {
+	if sl == nil {
+		return nil
+	}
+	return &ShardLock{
+		ID:          sl.ID,
+		Job:         sl.Job,
+		Shard:       sl.Shard,
+		Owner:       sl.Owner,
+		ExpiresAt:   sl.ExpiresAt,
+		CreatedAt:   sl.CreatedAt,
+		UpdatedAt:   sl.UpdatedAt,
+		VectorClock: sl.VectorClock,
+	}
+}
+
+// CheatReport records a player request that asked for an impossible state transition
+type CheatReport struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	PlayerID    primitive.ObjectID `bson:"player_id"`
+	Reason      CheatReason        `bson:"reason"`
+	Action      CheatAction        `bson:"action"`
+	TargetID    primitive.ObjectID `bson:"target_id"`         // Mine, transport, general or trade receiver
+	Message     string             `bson:"message"`           // Error returned to the player
+	Details     map[string]string  `bson:"details,omitempty"` // Values that made the request impossible
+	ReportedAt  time.Time          `bson:"reported_at"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+	VectorClock int64              `bson:"vector_clock"` // For optimistic concurrency control
+}
+
+// Copy creates a deep copy of the CheatReport
+func (cr *CheatReport) Copy() *CheatReport {
+	if cr == nil {
+		return nil
+	}
+
+	var details map[string]string
+	if cr.Details != nil {
+		details = make(map[string]string, len(cr.Details))
+		for key, value := range cr.Details {
+			details[key] = value
+		}
+	}
+
+	return &CheatReport{
+		ID:          cr.ID,
+		PlayerID:    cr.PlayerID,
+		Reason:      cr.Reason,
+		Action:      cr.Action,
+		TargetID:    cr.TargetID,
+		Message:     cr.Message,
+		Details:     details,
+		ReportedAt:  cr.ReportedAt,
+		CreatedAt:   cr.CreatedAt,
+		UpdatedAt:   cr.UpdatedAt,
+		VectorClock: cr.VectorClock,
+	}
+}
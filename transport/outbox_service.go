@@ -0,0 +1,297 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"nodestorage/v2"
+	"tictactoe/luvjson/crdtpubsub"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Outbox dispatch settings
+const (
+	defaultOutboxDispatchInterval = time.Second // How often the dispatcher looks for pending events
+	outboxDispatchBatchSize       = 100         // Maximum events published per dispatch
+)
+
+// AggregateType represents the kind of document a domain event belongs to
+type AggregateType string
+
+// Aggregate type constants
+const (
+	AggregateMine      AggregateType = "mine"      // 광산
+	AggregateTicket    AggregateType = "ticket"    // 이송권
+	AggregateTransport AggregateType = "transport" // 이송
+)
+
+// DomainEventType represents why a mine, ticket or transport changed
+type DomainEventType string
+
+// Domain event type constants
+const (
+	DomainEventMineCreated           DomainEventType = "mine.created"              // 광산 생성
+	DomainEventGoldOreAdded          DomainEventType = "mine.gold_ore_added"       // 금광석 추가
+	DomainEventGoldOreRemoved        DomainEventType = "mine.gold_ore_removed"     // 금광석 제거
+	DomainEventGeneralAssigned       DomainEventType = "mine.general_assigned"     // 장수 배치
+	DomainEventGeneralUnassigned     DomainEventType = "mine.general_unassigned"   // 장수 배치 해제
+	DomainEventDevelopmentProgress   DomainEventType = "mine.development_progress" // 개발 진행
+	DomainEventMineDeveloped         DomainEventType = "mine.developed"            // 개발 완료
+	DomainEventMineActivated         DomainEventType = "mine.activated"            // 활성화
+	DomainEventMineAttacked          DomainEventType = "mine.attacked"             // 공격 받음
+	DomainEventMineDefended          DomainEventType = "mine.defended"             // 방어 병력 도착
+	DomainEventMineBattleResolved    DomainEventType = "mine.battle_resolved"      // 광산 전투 판정
+	DomainEventMineSpedUp            DomainEventType = "mine.sped_up"              // 개발 가속
+	DomainEventTicketsCreated        DomainEventType = "ticket.created"            // 이송권 생성
+	DomainEventTicketsRefilled       DomainEventType = "ticket.refilled"           // 충전 및 재생성
+	DomainEventTicketUsed            DomainEventType = "ticket.used"               // 사용
+	DomainEventTicketHeld            DomainEventType = "ticket.held"               // 보류
+	DomainEventTicketReleased        DomainEventType = "ticket.released"           // 보류 반환
+	DomainEventTicketConsumed        DomainEventType = "ticket.consumed"           // 보류 소모
+	DomainEventTicketPurchased       DomainEventType = "ticket.purchased"          // 구매
+	DomainEventMaxTicketsRaised      DomainEventType = "ticket.max_raised"         // 최대 이송권 증가
+	DomainEventTransportStarted      DomainEventType = "transport.started"         // 이송 시작
+	DomainEventTransportJoined       DomainEventType = "transport.joined"          // 이송 참여
+	DomainEventTransportDeparted     DomainEventType = "transport.departed"        // 출발
+	DomainEventTransportArrived      DomainEventType = "transport.arrived"         // 도착
+	DomainEventTransportRaided       DomainEventType = "transport.raided"          // 약탈 시작
+	DomainEventRaidResolved          DomainEventType = "transport.raid_resolved"   // 약탈 판정
+	DomainEventTransportStatusUpdate DomainEventType = "transport.status_update"   // 상태 변경
+	DomainEventTransportSpedUp       DomainEventType = "transport.sped_up"         // 이동 가속
+)
+
+// OutboxService records domain events in the same transaction as the state changes they
+// describe, and dispatches them to clients through a crdtpubsub publisher.
+//
+// Change streams only show what a document looks like after a change; domain events also say
+// why it changed (a transport was raided rather than just losing gold ore). Because an event is
+// only stored if its change is, and stays in the outbox until it is published, clients never
+// miss an event nor see one for a change that was rolled back. Events can be published more
+// than once if the dispatcher fails right after publishing, so clients should ignore event IDs
+// they have already seen.
+type OutboxService struct {
+	storage nodestorage.Storage[*OutboxEvent]
+}
+
+// NewOutboxService creates a new OutboxService
+func NewOutboxService(storage nodestorage.Storage[*OutboxEvent]) *OutboxService {
+	return &OutboxService{
+		storage: storage,
+	}
+}
+
+// Record stores a domain event. Called with the context of a transaction, the event is only
+// stored if the transaction commits. A nil outbox or event records nothing.
+func (o *OutboxService) Record(ctx context.Context, event *OutboxEvent) error {
+	if o == nil || event == nil {
+		return nil
+	}
+
+	now := time.Now()
+	event.ID = primitive.NewObjectID()
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = now
+	}
+	event.CreatedAt = now
+	event.UpdatedAt = now
+	event.VectorClock = 1 // Set initial version
+
+	if _, err := o.storage.FindOneAndUpsert(ctx, event); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// run runs write and records the domain event it returns in one transaction.
+// Inside an ongoing transaction both join it. Without an outbox write runs on its own.
+func (o *OutboxService) run(ctx context.Context, write func(ctx context.Context) (*OutboxEvent, error)) error {
+	if o == nil {
+		_, err := write(ctx)
+		return err
+	}
+
+	if mongo.SessionFromContext(ctx) != nil {
+		event, err := write(ctx)
+		if err != nil {
+			return err
+		}
+		return o.Record(ctx, event)
+	}
+
+	return o.storage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		event, err := write(sessCtx)
+		if err != nil {
+			return err
+		}
+		return o.Record(sessCtx, event)
+	})
+}
+
+// GetPendingEvents retrieves the events that have not been published yet, oldest first
+func (o *OutboxService) GetPendingEvents(ctx context.Context, limit int64) ([]*OutboxEvent, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit)
+	return o.storage.FindMany(ctx, bson.M{"dispatched_at": nil}, opts)
+}
+
+// StartDispatcher starts a background worker that publishes pending events every interval
+// until ctx is cancelled
+func (o *OutboxService) StartDispatcher(ctx context.Context, publisher crdtpubsub.Publisher, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultOutboxDispatchInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if dispatched, err := o.Dispatch(ctx, publisher); err != nil {
+				log.Printf("Failed to dispatch domain events (%d dispatched): %v", dispatched, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Dispatch publishes pending events in the order they occurred and marks them as dispatched.
+// It stops at the first event that fails to publish, so events are never published out of
+// order; the failure is kept on the event and it is retried on the next dispatch.
+// It returns the number of events published.
+func (o *OutboxService) Dispatch(ctx context.Context, publisher crdtpubsub.Publisher) (int, error) {
+	events, err := o.GetPendingEvents(ctx, outboxDispatchBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending events: %w", err)
+	}
+
+	dispatched := 0
+	for _, event := range events {
+		publishErr := publishDomainEvent(ctx, publisher, event)
+
+		_, _, err := o.storage.FindOneAndUpdate(ctx, event.ID, func(e *OutboxEvent) (*OutboxEvent, error) {
+			now := time.Now()
+			e.Attempts++
+			if publishErr != nil {
+				e.LastError = publishErr.Error()
+			} else {
+				e.DispatchedAt = &now
+				e.LastError = ""
+			}
+			e.UpdatedAt = now
+			return e, nil
+		})
+		if publishErr != nil {
+			return dispatched, fmt.Errorf("failed to publish event %s: %w", event.ID.Hex(), publishErr)
+		}
+		if err != nil {
+			return dispatched, fmt.Errorf("failed to mark event %s as dispatched: %w", event.ID.Hex(), err)
+		}
+		dispatched++
+	}
+
+	return dispatched, nil
+}
+
+// DomainEventTopic returns the topic the events of an alliance's aggregates are published to.
+// Clients can subscribe to all of an alliance's events with the pattern "transport-<alliance ID>-*".
+func DomainEventTopic(allianceID primitive.ObjectID, aggregate AggregateType) string {
+	return fmt.Sprintf("transport-%s-%s", allianceID.Hex(), aggregate)
+}
+
+// domainEventMessage is the JSON message clients receive for a domain event
+type domainEventMessage struct {
+	ID          primitive.ObjectID  `json:"id"`
+	Type        DomainEventType     `json:"type"`
+	Aggregate   AggregateType       `json:"aggregate"`
+	AggregateID primitive.ObjectID  `json:"aggregate_id"`
+	AllianceID  primitive.ObjectID  `json:"alliance_id"`
+	PlayerID    *primitive.ObjectID `json:"player_id,omitempty"` // Not set for events no player caused
+	Data        bson.M              `json:"data,omitempty"`
+	OccurredAt  time.Time           `json:"occurred_at"`
+}
+
+// publishDomainEvent publishes an event to its alliance's topic as JSON
+func publishDomainEvent(ctx context.Context, publisher crdtpubsub.Publisher, event *OutboxEvent) error {
+	message := domainEventMessage{
+		ID:          event.ID,
+		Type:        event.Type,
+		Aggregate:   event.Aggregate,
+		AggregateID: event.AggregateID,
+		AllianceID:  event.AllianceID,
+		Data:        event.Data,
+		OccurredAt:  event.OccurredAt,
+	}
+	if !event.PlayerID.IsZero() {
+		message.PlayerID = &event.PlayerID
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	topic := DomainEventTopic(event.AllianceID, event.Aggregate)
+	return publisher.PublishRaw(ctx, topic, data, crdtpubsub.EncodingFormatJSON)
+}
+
+// mineEvent creates a domain event for a change to a mine
+func mineEvent(eventType DomainEventType, m *Mine, playerID primitive.ObjectID, data bson.M) *OutboxEvent {
+	return &OutboxEvent{
+		Type:        eventType,
+		Aggregate:   AggregateMine,
+		AggregateID: m.ID,
+		AllianceID:  m.AllianceID,
+		PlayerID:    playerID,
+		Data:        data,
+	}
+}
+
+// ticketEvent creates a domain event for a change to a player's tickets
+func ticketEvent(eventType DomainEventType, t *TransportTicket, data bson.M) *OutboxEvent {
+	if data == nil {
+		data = bson.M{}
+	}
+	data["current_tickets"] = t.CurrentTickets
+	data["held_tickets"] = t.HeldTickets
+	data["max_tickets"] = t.MaxTickets
+
+	return &OutboxEvent{
+		Type:        eventType,
+		Aggregate:   AggregateTicket,
+		AggregateID: t.ID,
+		AllianceID:  t.AllianceID,
+		PlayerID:    t.PlayerID,
+		Data:        data,
+	}
+}
+
+// transportEvent creates a domain event for a change to a transport
+func transportEvent(eventType DomainEventType, t *Transport, playerID primitive.ObjectID, data bson.M) *OutboxEvent {
+	if data == nil {
+		data = bson.M{}
+	}
+	data["status"] = t.Status
+	data["gold_ore_amount"] = t.GoldOreAmount
+
+	return &OutboxEvent{
+		Type:        eventType,
+		Aggregate:   AggregateTransport,
+		AggregateID: t.ID,
+		AllianceID:  t.AllianceID,
+		PlayerID:    playerID,
+		Data:        data,
+	}
+}
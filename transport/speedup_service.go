@@ -0,0 +1,396 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Speed-up settings
+const (
+	speedupItemDuration  = 5 * time.Minute // Time skipped by one speed-up item
+	speedupGemsPerMinute = 1               // Gems charged per minute skipped with premium acceleration
+)
+
+// SpeedupPayment represents how a player pays for a speed-up
+type SpeedupPayment string
+
+// Speed-up payment constants
+const (
+	SpeedupPaymentItems SpeedupPayment = "items" // 가속 아이템
+	SpeedupPaymentGems  SpeedupPayment = "gems"  // 보석 (프리미엄 가속)
+)
+
+// SpeedupTarget represents what a speed-up was applied to
+type SpeedupTarget string
+
+// Speed-up target constants
+const (
+	SpeedupTargetMineDevelopment SpeedupTarget = "mine_development" // 광산 개발
+	SpeedupTargetTransportTravel SpeedupTarget = "transport_travel" // 이송 이동
+)
+
+// SpeedupOrder describes a speed-up a player pays for
+type SpeedupOrder struct {
+	PlayerID   primitive.ObjectID
+	AllianceID primitive.ObjectID
+	Duration   time.Duration // Time to skip
+	Payment    SpeedupPayment
+}
+
+// SpeedupService lets players spend speed-up items or gems to accelerate mine development and
+// transport travel. Gems are not stored here: like ticket purchases, the caller charges the
+// gems the returned spend reports.
+type SpeedupService struct {
+	inventoryStorage nodestorage.Storage[*PlayerInventory]
+	spendStorage     nodestorage.Storage[*SpeedupSpend]
+	mineService      *MineService
+	transportService *TransportService
+}
+
+// NewSpeedupService creates a new SpeedupService
+func NewSpeedupService(
+	inventoryStorage nodestorage.Storage[*PlayerInventory],
+	spendStorage nodestorage.Storage[*SpeedupSpend],
+	mineService *MineService,
+	transportService *TransportService,
+) *SpeedupService {
+	return &SpeedupService{
+		inventoryStorage: inventoryStorage,
+		spendStorage:     spendStorage,
+		mineService:      mineService,
+		transportService: transportService,
+	}
+}
+
+// GrantSpeedupItems gives speed-up items to a player, creating the inventory if needed
+func (s *SpeedupService) GrantSpeedupItems(
+	ctx context.Context,
+	playerID primitive.ObjectID,
+	allianceID primitive.ObjectID,
+	count int,
+) (*PlayerInventory, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("count must be positive")
+	}
+
+	inventories, err := s.inventoryStorage.FindMany(ctx, bson.M{"player_id": playerID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if len(inventories) == 0 {
+		return s.inventoryStorage.FindOneAndUpsert(ctx, &PlayerInventory{
+			ID:          primitive.NewObjectID(),
+			PlayerID:    playerID,
+			AllianceID:  allianceID,
+			Speedups:    count,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			VectorClock: 1, // Set initial version
+		})
+	}
+
+	inventory, _, err := s.inventoryStorage.FindOneAndUpdate(ctx, inventories[0].ID, func(pi *PlayerInventory) (*PlayerInventory, error) {
+		pi.Speedups += count
+		pi.UpdatedAt = now
+		return pi, nil
+	})
+	return inventory, err
+}
+
+// ApplySpeedup accelerates the development of a mine by skipping order.Duration of it.
+//
+// Development is calculated lazily from the time since the last update, so a speed-up simply
+// counts as extra elapsed time: the generals contribute the points they would have made in that
+// time. The payment, the development points and the spend record are stored in one transaction.
+// Players are never charged for more time than the development has left.
+func (s *SpeedupService) ApplySpeedup(ctx context.Context, mineID primitive.ObjectID, order SpeedupOrder) (*Mine, *SpeedupSpend, error) {
+	if err := validateSpeedupOrder(order); err != nil {
+		return nil, nil, err
+	}
+
+	var mine *Mine
+	var spend *SpeedupSpend
+	var generals []AssignedGeneral
+	var hours, pointsAdded float64
+	err := s.spendStorage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var skipped time.Duration
+		var items, gems int
+		err := s.mineService.outbox.run(sessCtx, func(ctx context.Context) (*OutboxEvent, error) {
+			var err error
+			mine, _, err = s.mineService.storage.FindOneAndUpdate(ctx, mineID, func(m *Mine) (*Mine, error) {
+				if m.Status != MineStatusDeveloping {
+					return nil, fmt.Errorf("mine is not in development")
+				}
+				if order.AllianceID != m.AllianceID {
+					return nil, fmt.Errorf("only alliance members can speed up this mine")
+				}
+
+				rate := 0.0
+				for _, ag := range m.AssignedGenerals {
+					rate += ag.ContributionRate
+				}
+				if rate <= 0 {
+					return nil, fmt.Errorf("no generals are developing this mine")
+				}
+
+				// Time left once the development since the last update is applied
+				now := time.Now()
+				elapsed := now.Sub(m.LastUpdatedAt)
+				remaining := time.Duration((m.RequiredPoints-m.DevelopmentPoints)/rate*float64(time.Hour)) - elapsed
+
+				skipped, items, gems = speedupCost(order.Duration, remaining, order.Payment)
+				if skipped <= 0 {
+					return nil, fmt.Errorf("mine development has no time left to skip")
+				}
+
+				generals = append([]AssignedGeneral(nil), m.AssignedGenerals...)
+				hours = (elapsed + skipped).Hours()
+				pointsBefore := m.DevelopmentPoints
+				m.DevelopmentPoints += rate * hours
+
+				// Skipping all the time left completes development, whatever the rounding
+				if skipped >= remaining || m.DevelopmentPoints >= m.RequiredPoints {
+					m.DevelopmentPoints = m.RequiredPoints
+					m.Status = MineStatusDeveloped
+					m.AssignedGenerals = []AssignedGeneral{}
+				}
+				pointsAdded = m.DevelopmentPoints - pointsBefore
+
+				m.LastUpdatedAt = now
+				m.UpdatedAt = now
+				return m, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			err = s.mineService.outbox.Record(ctx, mineEvent(DomainEventMineSpedUp, mine, order.PlayerID, bson.M{
+				"skipped_seconds": skipped.Seconds(),
+				"payment":         order.Payment,
+			}))
+			if err != nil {
+				return nil, err
+			}
+			return developmentEvent(mine, pointsAdded), nil
+		})
+		if err != nil {
+			return err
+		}
+
+		spend, err = s.charge(sessCtx, order, SpeedupTargetMineDevelopment, mineID, skipped, items, gems)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to speed up mine development: %w", err)
+	}
+
+	s.mineService.emitDevelopment(mine, developmentContributions(generals, hours, pointsAdded))
+
+	// Completed development frees the generals and raises the alliance's ticket limit
+	if mine.Status == MineStatusDeveloped {
+		for _, ag := range generals {
+			if _, err := s.mineService.generalService.UnassignGeneral(ctx, ag.GeneralID); err != nil {
+				return mine, spend, fmt.Errorf("mine development completed but failed to unassign general %s: %w", ag.GeneralName, err)
+			}
+		}
+
+		err = s.mineService.updateTransportTicketsForAlliance(ctx, mine.AllianceID, mine.Level)
+		if err != nil {
+			return mine, spend, fmt.Errorf("mine development completed but failed to update transport tickets: %w", err)
+		}
+	}
+
+	return mine, spend, nil
+}
+
+// ApplyTransportSpeedup shortens the travel time of an in-progress transport by order.Duration.
+// Only participants can speed up a transport, and not while it is being raided. A transport
+// whose travel time is skipped entirely arrives right away.
+func (s *SpeedupService) ApplyTransportSpeedup(ctx context.Context, transportID primitive.ObjectID, order SpeedupOrder) (*Transport, *SpeedupSpend, error) {
+	if err := validateSpeedupOrder(order); err != nil {
+		return nil, nil, err
+	}
+
+	var transport *Transport
+	var spend *SpeedupSpend
+	err := s.spendStorage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var skipped time.Duration
+		var items, gems int
+		err := s.transportService.outbox.run(sessCtx, func(ctx context.Context) (*OutboxEvent, error) {
+			var err error
+			transport, _, err = s.transportService.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
+				if t.Status != TransportStatusInProgress && t.Status != TransportStatusRaided {
+					return nil, fmt.Errorf("transport is not in progress (status: %s)", t.Status)
+				}
+				if t.EndTime == nil || t.ArrivedAt != nil {
+					return nil, fmt.Errorf("transport is not travelling")
+				}
+				if t.RaidStatus != nil && !t.RaidStatus.IsDefended {
+					return nil, fmt.Errorf("transport is being raided")
+				}
+
+				participating := false
+				for _, p := range t.Participants {
+					if p.PlayerID == order.PlayerID {
+						participating = true
+						break
+					}
+				}
+				if !participating {
+					return nil, fmt.Errorf("only participants can speed up this transport")
+				}
+
+				now := time.Now()
+				skipped, items, gems = speedupCost(order.Duration, t.EndTime.Sub(now), order.Payment)
+				if skipped <= 0 {
+					return nil, fmt.Errorf("transport has no travel time left to skip")
+				}
+
+				endTime := t.EndTime.Add(-skipped)
+				t.EndTime = &endTime
+				t.UpdatedAt = now
+				return t, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return transportEvent(DomainEventTransportSpedUp, transport, order.PlayerID, bson.M{
+				"skipped_seconds": skipped.Seconds(),
+				"payment":         order.Payment,
+				"end_time":        transport.EndTime,
+			}), nil
+		})
+		if err != nil {
+			return err
+		}
+
+		spend, err = s.charge(sessCtx, order, SpeedupTargetTransportTravel, transportID, skipped, items, gems)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to speed up transport: %w", err)
+	}
+
+	// Resolve the arrival now instead of waiting for the scheduler
+	if !time.Now().Before(*transport.EndTime) {
+		arrived, err := s.transportService.resolveArrival(ctx, transportID)
+		if err != nil {
+			return transport, spend, fmt.Errorf("transport sped up but failed to resolve arrival: %w", err)
+		}
+		transport = arrived
+	}
+
+	return transport, spend, nil
+}
+
+// GetPlayerSpends retrieves the speed-ups a player paid for, newest first
+func (s *SpeedupService) GetPlayerSpends(ctx context.Context, playerID primitive.ObjectID) ([]*SpeedupSpend, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return s.spendStorage.FindMany(ctx, bson.M{"player_id": playerID}, opts)
+}
+
+// charge spends the player's speed-up items and records the spend
+func (s *SpeedupService) charge(
+	ctx context.Context,
+	order SpeedupOrder,
+	target SpeedupTarget,
+	targetID primitive.ObjectID,
+	skipped time.Duration,
+	items int,
+	gems int,
+) (*SpeedupSpend, error) {
+	if items > 0 {
+		inventories, err := s.inventoryStorage.FindMany(ctx, bson.M{"player_id": order.PlayerID})
+		if err != nil {
+			return nil, err
+		}
+		if len(inventories) == 0 {
+			return nil, fmt.Errorf("player has no speed-up items")
+		}
+
+		_, _, err = s.inventoryStorage.FindOneAndUpdate(ctx, inventories[0].ID, func(pi *PlayerInventory) (*PlayerInventory, error) {
+			if pi.Speedups < items {
+				return nil, fmt.Errorf("not enough speed-up items (have %d, need %d)", pi.Speedups, items)
+			}
+			pi.Speedups -= items
+			pi.UpdatedAt = time.Now()
+			return pi, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	spend := &SpeedupSpend{
+		ID:          primitive.NewObjectID(),
+		PlayerID:    order.PlayerID,
+		AllianceID:  order.AllianceID,
+		Target:      target,
+		TargetID:    targetID,
+		Payment:     order.Payment,
+		Requested:   order.Duration,
+		Skipped:     skipped,
+		ItemsSpent:  items,
+		GemsSpent:   gems,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	spend, err := s.spendStorage.FindOneAndUpsert(ctx, spend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record speed-up: %w", err)
+	}
+	return spend, nil
+}
+
+// validateSpeedupOrder checks the duration and payment of a speed-up order
+func validateSpeedupOrder(order SpeedupOrder) error {
+	if order.Duration <= 0 {
+		return fmt.Errorf("speed-up duration must be positive")
+	}
+	if order.Payment != SpeedupPaymentItems && order.Payment != SpeedupPaymentGems {
+		return fmt.Errorf("unknown speed-up payment: %s", order.Payment)
+	}
+	return nil
+}
+
+// speedupCost calculates how much time a speed-up skips and what it costs.
+// Items skip speedupItemDuration each and gems are charged per started minute, so the requested
+// time is rounded up to whole items or minutes. Only the time remaining is charged and skipped.
+func speedupCost(requested, remaining time.Duration, payment SpeedupPayment) (skipped time.Duration, items int, gems int) {
+	d := requested
+	if remaining < d {
+		d = remaining
+	}
+	if d <= 0 {
+		return 0, 0, 0
+	}
+
+	unit := time.Minute
+	if payment == SpeedupPaymentItems {
+		unit = speedupItemDuration
+	}
+	units := int((d + unit - 1) / unit)
+
+	skipped = time.Duration(units) * unit
+	if skipped > remaining {
+		skipped = remaining
+	}
+
+	if payment == SpeedupPaymentItems {
+		return skipped, units, 0
+	}
+	return skipped, 0, units * speedupGemsPerMinute
+}
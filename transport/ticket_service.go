@@ -2,7 +2,9 @@ package transport
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"nodestorage/v2"
 	"time"
 
@@ -10,18 +12,51 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Ticket regeneration settings
+const (
+	defaultTicketRegenInterval = 4 * time.Hour // One ticket regenerates per interval, up to the maximum
+	defaultTicketSweepInterval = time.Minute   // How often the sweeper refills tickets in storage
+)
+
 // TicketService provides operations for managing transport tickets
 type TicketService struct {
-	storage nodestorage.Storage[*TransportTicket]
+	storage       nodestorage.Storage[*TransportTicket]
+	outbox        *OutboxService
+	idempotency   *IdempotencyService
+	balance       *BalanceService
+	regenInterval time.Duration
 }
 
 // NewTicketService creates a new TicketService
 func NewTicketService(storage nodestorage.Storage[*TransportTicket]) *TicketService {
 	return &TicketService{
-		storage: storage,
+		storage:       storage,
+		regenInterval: defaultTicketRegenInterval,
 	}
 }
 
+// SetRegenInterval changes how long it takes to regenerate one ticket.
+// A zero or negative interval disables regeneration, leaving only the daily reset.
+func (s *TicketService) SetRegenInterval(regenInterval time.Duration) {
+	s.regenInterval = regenInterval
+}
+
+// SetOutbox makes the service record a domain event for every change to tickets
+func (s *TicketService) SetOutbox(outbox *OutboxService) {
+	s.outbox = outbox
+}
+
+// SetIdempotency makes PurchaseTicket honor idempotency keys (see WithIdempotencyKey)
+func (s *TicketService) SetIdempotency(idempotency *IdempotencyService) {
+	s.idempotency = idempotency
+}
+
+// SetBalance makes the service price purchased tickets with the balance rules in effect
+// instead of the defaults
+func (s *TicketService) SetBalance(balance *BalanceService) {
+	s.balance = balance
+}
+
 // GetOrCreateTickets gets or creates transport tickets for a player
 func (s *TicketService) GetOrCreateTickets(
 	ctx context.Context,
@@ -51,6 +86,7 @@ func (s *TicketService) GetOrCreateTickets(
 		CurrentTickets: maxTickets, // Start with max tickets
 		MaxTickets:     maxTickets,
 		LastRefillTime: now,
+		LastRegenTime:  now,
 		PurchaseCount:  0,
 		LastPurchaseAt: nil,
 		ResetTime:      getNextResetTime(now),
@@ -59,11 +95,44 @@ func (s *TicketService) GetOrCreateTickets(
 		VectorClock:    1, // Set initial version
 	}
 
-	return s.storage.FindOneAndUpsert(ctx, ticket)
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		ticket, err = s.storage.FindOneAndUpsert(ctx, ticket)
+		if err != nil {
+			return nil, err
+		}
+		return ticketEvent(DomainEventTicketsCreated, ticket, nil), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
 }
 
 // UseTicket uses a transport ticket
 func (s *TicketService) UseTicket(ctx context.Context, playerID primitive.ObjectID) (*TransportTicket, error) {
+	return s.takeTicket(ctx, playerID, false)
+}
+
+// HoldTicket takes a transport ticket and holds it until the transport arrives.
+// A held ticket is consumed with ConsumeHeldTicket or returned with ReleaseTicket.
+func (s *TicketService) HoldTicket(ctx context.Context, playerID primitive.ObjectID) (*TransportTicket, error) {
+	return s.takeTicket(ctx, playerID, true)
+}
+
+// ReleaseTicket returns a held ticket to the player
+func (s *TicketService) ReleaseTicket(ctx context.Context, playerID primitive.ObjectID) (*TransportTicket, error) {
+	return s.settleHeldTicket(ctx, playerID, true)
+}
+
+// ConsumeHeldTicket consumes a held ticket once the transport has arrived
+func (s *TicketService) ConsumeHeldTicket(ctx context.Context, playerID primitive.ObjectID) (*TransportTicket, error) {
+	return s.settleHeldTicket(ctx, playerID, false)
+}
+
+// takeTicket takes a transport ticket, optionally holding it for a transport
+func (s *TicketService) takeTicket(ctx context.Context, playerID primitive.ObjectID, hold bool) (*TransportTicket, error) {
 	// Get player's tickets
 	tickets, err := s.storage.FindMany(ctx, bson.M{"player_id": playerID})
 	if err != nil {
@@ -85,17 +154,115 @@ func (s *TicketService) UseTicket(ctx context.Context, playerID primitive.Object
 		return nil, fmt.Errorf("no transport tickets available")
 	}
 
-	ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
-		t.CurrentTickets--
-		t.UpdatedAt = time.Now()
-		return t, nil
+	eventType := DomainEventTicketUsed
+	if hold {
+		eventType = DomainEventTicketHeld
+	}
+
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
+			now := time.Now()
+
+			// Regeneration starts when the first ticket is taken from a full stock
+			if t.CurrentTickets >= t.MaxTickets {
+				t.LastRegenTime = now
+			}
+
+			t.CurrentTickets--
+			if hold {
+				t.HeldTickets++
+			}
+			t.UpdatedAt = now
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return ticketEvent(eventType, ticket, nil), nil
+	})
+
+	return ticket, err
+}
+
+// settleHeldTicket removes a held ticket, returning it to the player if refund is set.
+// Players without held tickets are left unchanged, so transports created before tickets
+// were held can still be settled.
+func (s *TicketService) settleHeldTicket(ctx context.Context, playerID primitive.ObjectID, refund bool) (*TransportTicket, error) {
+	tickets, err := s.storage.FindMany(ctx, bson.M{"player_id": playerID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("player has no transport tickets")
+	}
+
+	eventType := DomainEventTicketConsumed
+	if refund {
+		eventType = DomainEventTicketReleased
+	}
+
+	var ticket *TransportTicket
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		settled := false
+		var err error
+		ticket, _, err = s.storage.FindOneAndUpdate(ctx, tickets[0].ID, func(t *TransportTicket) (*TransportTicket, error) {
+			settled = false
+			if t.HeldTickets <= 0 {
+				return t, nil
+			}
+			t.HeldTickets--
+			if refund {
+				t.CurrentTickets++
+			}
+			t.UpdatedAt = time.Now()
+			settled = true
+			return t, nil
+		})
+		if err != nil || !settled {
+			return nil, err
+		}
+		return ticketEvent(eventType, ticket, nil), nil
 	})
 
 	return ticket, err
 }
 
-// PurchaseTicket purchases a transport ticket
+// PurchaseTicket purchases a transport ticket.
+// Called with a context from WithIdempotencyKey, retries return the ticket and price of the
+// first purchase instead of buying again.
 func (s *TicketService) PurchaseTicket(ctx context.Context, playerID primitive.ObjectID) (*TransportTicket, int, error) {
+	var ticket *TransportTicket
+	var price int
+	earlier, err := s.idempotency.do(ctx, playerID, IdempotentActionPurchaseTicket, "",
+		func(result *IdempotencyKey) error {
+			var err error
+			ticket, price, err = s.purchaseTicket(ctx, playerID)
+			if err != nil {
+				return err
+			}
+			result.ResultID = ticket.ID
+			result.Cost = price
+			return nil
+		})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if earlier != nil {
+		ticket, err := s.storage.FindOne(ctx, earlier.ResultID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get tickets: %w", err)
+		}
+		return ticket, earlier.Cost, nil
+	}
+
+	return ticket, price, nil
+}
+
+// purchaseTicket purchases a transport ticket and returns its price
+func (s *TicketService) purchaseTicket(ctx context.Context, playerID primitive.ObjectID) (*TransportTicket, int, error) {
 	// Get player's tickets
 	tickets, err := s.storage.FindMany(ctx, bson.M{"player_id": playerID})
 	if err != nil {
@@ -112,53 +279,173 @@ func (s *TicketService) PurchaseTicket(ctx context.Context, playerID primitive.O
 		return nil, 0, err
 	}
 
-	// Check if purchase count needs to be reset
-	now := time.Now()
-	if now.After(ticket.ResetTime) {
+	var price int
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+
+		// Check if purchase count needs to be reset
+		now := time.Now()
+		if now.After(ticket.ResetTime) {
+			ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
+				t.PurchaseCount = 0
+				t.ResetTime = getNextResetTime(now)
+				t.UpdatedAt = now
+				return t, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Calculate purchase price
+		price = s.balance.Current().PurchasePrice(ticket.PurchaseCount)
+
+		// Purchase a ticket
 		ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
-			t.PurchaseCount = 0
-			t.ResetTime = getNextResetTime(now)
+			t.CurrentTickets++
+			t.PurchaseCount++
+			t.LastPurchaseAt = &now
 			t.UpdatedAt = now
 			return t, nil
 		})
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
-	}
-
-	// Calculate purchase price
-	price := calculatePurchasePrice(ticket.PurchaseCount)
-
-	// Purchase a ticket
-	ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
-		t.CurrentTickets++
-		t.PurchaseCount++
-		t.LastPurchaseAt = &now
-		t.UpdatedAt = now
-		return t, nil
+		return ticketEvent(DomainEventTicketPurchased, ticket, bson.M{
+			"price":          price,
+			"purchase_count": ticket.PurchaseCount,
+		}), nil
 	})
 
 	return ticket, price, err
 }
 
-// checkAndRefillTickets checks if tickets need to be refilled and does so if necessary
+// checkAndRefillTickets checks if tickets need to be refilled and does so if necessary.
+// Refills are computed from the stored times, so tickets are up to date whenever they are read.
 func (s *TicketService) checkAndRefillTickets(ctx context.Context, ticket *TransportTicket) (*TransportTicket, error) {
 	now := time.Now()
 
-	// Check if it's a new day (UTC+0 00:00)
-	if isNewDay(ticket.LastRefillTime, now) {
-		ticket, _, err := s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
-			t.CurrentTickets = t.MaxTickets
-			t.LastRefillTime = now
-			t.PurchaseCount = 0
-			t.ResetTime = getNextResetTime(now)
+	// Skip the write if nothing is due
+	if !refillTickets(ticket.Copy(), now, s.regenInterval) {
+		return ticket, nil
+	}
+
+	err := s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
+			refillTickets(t, now, s.regenInterval)
 			t.UpdatedAt = now
 			return t, nil
 		})
-		return ticket, err
+		if err != nil {
+			return nil, err
+		}
+		return ticketEvent(DomainEventTicketsRefilled, ticket, nil), nil
+	})
+	return ticket, err
+}
+
+// StartSweeper starts a background worker that refills the tickets of all players every interval
+// until ctx is cancelled, so stored tickets stay current even for players who are not playing.
+func (s *TicketService) StartSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultTicketSweepInterval
 	}
 
-	return ticket, nil
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if refilled, err := s.RefillTickets(ctx); err != nil {
+				log.Printf("Failed to refill tickets (%d refilled): %v", refilled, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RefillTickets applies the daily reset and regeneration to every player whose tickets are due.
+// It returns the number of players whose tickets changed.
+func (s *TicketService) RefillTickets(ctx context.Context) (int, error) {
+	now := time.Now()
+	today := getNextResetTime(now).AddDate(0, 0, -1)
+
+	// Players who missed the daily reset, or who are below the maximum and may have regenerated
+	tickets, err := s.storage.FindMany(ctx, bson.M{
+		"$or": bson.A{
+			bson.M{"last_refill_time": bson.M{"$lt": today}},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$current_tickets", "$max_tickets"}}},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	refilled := 0
+	var errs []error
+	for _, ticket := range tickets {
+		// Regeneration may not have produced a whole ticket yet
+		if !refillTickets(ticket.Copy(), now, s.regenInterval) {
+			continue
+		}
+
+		if _, err := s.checkAndRefillTickets(ctx, ticket); err != nil {
+			errs = append(errs, fmt.Errorf("player %s: %w", ticket.PlayerID.Hex(), err))
+			continue
+		}
+		refilled++
+	}
+
+	return refilled, errors.Join(errs...)
+}
+
+// refillTickets applies the daily reset and ticket regeneration that are due at now.
+// It returns whether the tickets changed.
+//
+// On a new day (UTC+0 00:00) tickets are reset to the maximum. Below the maximum, one ticket
+// regenerates per regenInterval since LastRegenTime; partial progress towards the next ticket
+// is kept, and regeneration stops once the maximum is reached.
+func refillTickets(t *TransportTicket, now time.Time, regenInterval time.Duration) bool {
+	changed := false
+
+	// Check if it's a new day (UTC+0 00:00)
+	if isNewDay(t.LastRefillTime, now) {
+		t.CurrentTickets = t.MaxTickets
+		t.LastRefillTime = now
+		t.LastRegenTime = now
+		t.PurchaseCount = 0
+		t.ResetTime = getNextResetTime(now)
+		changed = true
+	}
+
+	if regenInterval <= 0 || t.CurrentTickets >= t.MaxTickets {
+		return changed
+	}
+
+	// Tickets created before regeneration existed start from the last refill
+	since := t.LastRegenTime
+	if since.IsZero() {
+		since = t.LastRefillTime
+	}
+
+	regenerated := int(now.Sub(since) / regenInterval)
+	if regenerated <= 0 {
+		return changed
+	}
+
+	t.CurrentTickets = min(t.CurrentTickets+regenerated, t.MaxTickets)
+	if t.CurrentTickets >= t.MaxTickets {
+		t.LastRegenTime = now
+	} else {
+		t.LastRegenTime = since.Add(time.Duration(regenerated) * regenInterval)
+	}
+	return true
 }
 
 // isNewDay checks if the current time is a new day (UTC+0 00:00) compared to the last refill time
@@ -179,14 +466,6 @@ func getNextResetTime(now time.Time) time.Time {
 	return tomorrow
 }
 
-// calculatePurchasePrice calculates the price for purchasing a ticket
-func calculatePurchasePrice(purchaseCount int) int {
-	basePrice := 300
-	priceIncrease := 100
-
-	return basePrice + (purchaseCount * priceIncrease)
-}
-
 // GetTicketsByAlliance gets all transport tickets for an alliance
 func (s *TicketService) GetTicketsByAlliance(ctx context.Context, allianceID primitive.ObjectID) ([]*TransportTicket, error) {
 	return s.storage.FindMany(ctx, bson.M{"alliance_id": allianceID})
@@ -212,18 +491,25 @@ func (s *TicketService) UpdateMaxTickets(ctx context.Context, playerID primitive
 	}
 
 	// Update max tickets
-	ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
-		// Calculate how many tickets to add
-		ticketsToAdd := maxTickets - t.MaxTickets
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		ticket, _, err = s.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
+			// Calculate how many tickets to add
+			ticketsToAdd := maxTickets - t.MaxTickets
 
-		// Update max tickets
-		t.MaxTickets = maxTickets
+			// Update max tickets
+			t.MaxTickets = maxTickets
 
-		// Add the same number of current tickets (up to the new max)
-		t.CurrentTickets = min(t.CurrentTickets+ticketsToAdd, maxTickets)
+			// Add the same number of current tickets (up to the new max)
+			t.CurrentTickets = min(t.CurrentTickets+ticketsToAdd, maxTickets)
 
-		t.UpdatedAt = time.Now()
-		return t, nil
+			t.UpdatedAt = time.Now()
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return ticketEvent(DomainEventMaxTicketsRaised, ticket, nil), nil
 	})
 
 	return ticket, err
@@ -0,0 +1,559 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"nodestorage/v2"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Trade limits
+const (
+	tradeExpiry          = 24 * time.Hour   // How long a pending trade waits for the receiver
+	tradeCooldown        = 10 * time.Minute // Minimum time between two trades sent by the same player
+	maxTicketsPerTrade   = 2                // Maximum tickets in a single trade
+	maxGoldOrePerTrade   = 500              // Maximum gold ore in a single trade
+	maxTicketsSentPerDay = 3                // Maximum tickets a player can send per day (UTC)
+	maxGoldOreSentPerDay = 1000             // Maximum gold ore a player can send per day (UTC)
+	maxLedgerEntries     = 100              // Maximum ledger entries returned by GetLedger
+
+	defaultTradeSweepInterval = time.Minute // How often the sweeper expires pending trades
+)
+
+// Ledger entry reasons
+const (
+	ledgerReasonEscrow = "escrow"
+	ledgerReasonAccept = "accept"
+	ledgerReasonRefund = "refund"
+)
+
+// TradeService provides operations for gifting tickets and gold ore between alliance members
+type TradeService struct {
+	storage          nodestorage.Storage[*Trade]
+	inventoryStorage nodestorage.Storage[*PlayerInventory]
+	ledgerStorage    nodestorage.Storage[*TradeLedgerEntry]
+	ticketService    *TicketService
+	alliances        *AllianceService
+	cheats           *AntiCheatService
+}
+
+// NewTradeService creates a new TradeService
+func NewTradeService(
+	storage nodestorage.Storage[*Trade],
+	inventoryStorage nodestorage.Storage[*PlayerInventory],
+	ledgerStorage nodestorage.Storage[*TradeLedgerEntry],
+	ticketService *TicketService,
+) *TradeService {
+	return &TradeService{
+		storage:          storage,
+		inventoryStorage: inventoryStorage,
+		ledgerStorage:    ledgerStorage,
+		ticketService:    ticketService,
+	}
+}
+
+// SetAlliances makes SendTrade check that the sender and the receiver are members of the
+// alliance the trade is sent in
+func (s *TradeService) SetAlliances(alliances *AllianceService) {
+	s.alliances = alliances
+}
+
+// SetCheats makes SendTrade record gifts of negative gold ore
+func (s *TradeService) SetCheats(cheats *AntiCheatService) {
+	s.cheats = cheats
+}
+
+// GetOrCreateInventory gets or creates the gold ore inventory for a player
+func (s *TradeService) GetOrCreateInventory(
+	ctx context.Context,
+	playerID primitive.ObjectID,
+	allianceID primitive.ObjectID,
+) (*PlayerInventory, error) {
+	inventories, err := s.inventoryStorage.FindMany(ctx, bson.M{"player_id": playerID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inventories) > 0 {
+		return inventories[0], nil
+	}
+
+	now := time.Now()
+	inventory := &PlayerInventory{
+		ID:          primitive.NewObjectID(),
+		PlayerID:    playerID,
+		AllianceID:  allianceID,
+		GoldOre:     0,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	return s.inventoryStorage.FindOneAndUpsert(ctx, inventory)
+}
+
+// AddPlayerGoldOre adds gold ore to a player's inventory
+func (s *TradeService) AddPlayerGoldOre(ctx context.Context, playerID primitive.ObjectID, amount int) (*PlayerInventory, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	inventory, err := s.findInventory(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory, _, err = s.inventoryStorage.FindOneAndUpdate(ctx, inventory.ID, func(pi *PlayerInventory) (*PlayerInventory, error) {
+		pi.GoldOre += amount
+		pi.UpdatedAt = time.Now()
+		return pi, nil
+	})
+
+	return inventory, err
+}
+
+// SendTrade creates a pending trade from sender to receiver.
+// The sender's items are moved into escrow in the same transaction, so they cannot be spent twice.
+func (s *TradeService) SendTrade(
+	ctx context.Context,
+	allianceID primitive.ObjectID,
+	senderID primitive.ObjectID,
+	senderName string,
+	receiverID primitive.ObjectID,
+	receiverName string,
+	itemType TradeItemType,
+	amount int,
+) (*Trade, error) {
+	// Validate input
+	if senderID == receiverID {
+		return nil, fmt.Errorf("cannot trade with yourself")
+	}
+	if itemType == TradeItemGoldOre {
+		if err := s.cheats.Check(ctx, detectNegativeGoldOre(senderID, CheatActionSendTrade, receiverID, amount)); err != nil {
+			return nil, err
+		}
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	switch itemType {
+	case TradeItemTicket:
+		if amount > maxTicketsPerTrade {
+			return nil, fmt.Errorf("ticket amount exceeds maximum per trade (%d)", maxTicketsPerTrade)
+		}
+	case TradeItemGoldOre:
+		if amount > maxGoldOrePerTrade {
+			return nil, fmt.Errorf("gold ore amount exceeds maximum per trade (%d)", maxGoldOrePerTrade)
+		}
+	default:
+		return nil, fmt.Errorf("unknown trade item type: %s", itemType)
+	}
+
+	// Both players must belong to the alliance
+	if err := s.alliances.CheckMember(ctx, allianceID, senderID); err != nil {
+		return nil, fmt.Errorf("invalid sender: %w", err)
+	}
+	if err := s.alliances.CheckMember(ctx, allianceID, receiverID); err != nil {
+		return nil, fmt.Errorf("invalid receiver: %w", err)
+	}
+
+	now := time.Now()
+	trade := &Trade{
+		ID:           primitive.NewObjectID(),
+		AllianceID:   allianceID,
+		SenderID:     senderID,
+		SenderName:   senderName,
+		ReceiverID:   receiverID,
+		ReceiverName: receiverName,
+		ItemType:     itemType,
+		Amount:       amount,
+		Status:       TradeStatusPending,
+		ExpiresAt:    now.Add(tradeExpiry),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		VectorClock:  1, // Set initial version
+	}
+
+	err := s.storage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		// Move the sender's items into escrow. The cooldown and daily caps are checked in the same
+		// update, so concurrent trades from the same sender conflict on the sender's document and
+		// the retry sees the trade that won.
+		balance, err := s.applyDelta(sessCtx, senderID, itemType, -amount, func() error {
+			return s.checkSendLimits(sessCtx, senderID, itemType, amount, now)
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.recordLedgerEntry(sessCtx, trade, senderID, -amount, balance, ledgerReasonEscrow); err != nil {
+			return err
+		}
+
+		trade, err = s.storage.FindOneAndUpsert(sessCtx, trade)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send trade: %w", err)
+	}
+
+	return trade, nil
+}
+
+// AcceptTrade accepts a pending trade and credits the receiver
+func (s *TradeService) AcceptTrade(ctx context.Context, tradeID primitive.ObjectID, receiverID primitive.ObjectID) (*Trade, error) {
+	trade, err := s.resolveTrade(ctx, tradeID, TradeStatusAccepted, func(t *Trade) error {
+		if t.ReceiverID != receiverID {
+			return fmt.Errorf("trade is not addressed to this player")
+		}
+		if time.Now().After(t.ExpiresAt) {
+			return fmt.Errorf("trade has expired")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept trade: %w", err)
+	}
+
+	return trade, nil
+}
+
+// DeclineTrade declines a pending trade and refunds the sender
+func (s *TradeService) DeclineTrade(ctx context.Context, tradeID primitive.ObjectID, receiverID primitive.ObjectID) (*Trade, error) {
+	trade, err := s.resolveTrade(ctx, tradeID, TradeStatusDeclined, func(t *Trade) error {
+		if t.ReceiverID != receiverID {
+			return fmt.Errorf("trade is not addressed to this player")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decline trade: %w", err)
+	}
+
+	return trade, nil
+}
+
+// CancelTrade cancels a pending trade on behalf of the sender and refunds the items
+func (s *TradeService) CancelTrade(ctx context.Context, tradeID primitive.ObjectID, senderID primitive.ObjectID) (*Trade, error) {
+	trade, err := s.resolveTrade(ctx, tradeID, TradeStatusCancelled, func(t *Trade) error {
+		if t.SenderID != senderID {
+			return fmt.Errorf("trade was not sent by this player")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel trade: %w", err)
+	}
+
+	return trade, nil
+}
+
+// StartSweeper starts a background worker that expires overdue pending trades every interval
+// until ctx is cancelled.
+func (s *TradeService) StartSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultTradeSweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if expired, err := s.ExpireTrades(ctx); err != nil {
+				log.Printf("Failed to expire trades (%d expired): %v", expired, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ExpireTrades expires all pending trades whose expiry time has passed and refunds the senders.
+// It returns the number of trades that were expired.
+func (s *TradeService) ExpireTrades(ctx context.Context) (int, error) {
+	trades, err := s.storage.FindMany(ctx, bson.M{
+		"status":     TradeStatusPending,
+		"expires_at": bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var errs []error
+	for _, trade := range trades {
+		if _, err := s.expireTrade(ctx, trade.ID); err != nil {
+			errs = append(errs, fmt.Errorf("trade %s: %w", trade.ID.Hex(), err))
+			continue
+		}
+		expired++
+	}
+
+	return expired, errors.Join(errs...)
+}
+
+// GetTrade retrieves a trade by ID
+func (s *TradeService) GetTrade(ctx context.Context, tradeID primitive.ObjectID) (*Trade, error) {
+	return s.storage.FindOne(ctx, tradeID)
+}
+
+// GetPendingTrades retrieves all pending trades addressed to a player
+func (s *TradeService) GetPendingTrades(ctx context.Context, receiverID primitive.ObjectID) ([]*Trade, error) {
+	return s.storage.FindMany(ctx, bson.M{
+		"receiver_id": receiverID,
+		"status":      TradeStatusPending,
+	})
+}
+
+// GetLedger retrieves the most recent ledger entries for a player
+func (s *TradeService) GetLedger(ctx context.Context, playerID primitive.ObjectID) ([]*TradeLedgerEntry, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(maxLedgerEntries)
+
+	return s.ledgerStorage.FindMany(ctx, bson.M{"player_id": playerID}, opts)
+}
+
+// resolveTrade moves a pending trade into a final status and settles the escrowed items.
+// Accepted trades credit the receiver; every other status refunds the sender.
+func (s *TradeService) resolveTrade(
+	ctx context.Context,
+	tradeID primitive.ObjectID,
+	status TradeStatus,
+	check func(t *Trade) error,
+) (*Trade, error) {
+	var trade *Trade
+
+	err := s.storage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var err error
+		trade, _, err = s.storage.FindOneAndUpdate(sessCtx, tradeID, func(t *Trade) (*Trade, error) {
+			// Only pending trades can be resolved
+			if t.Status != TradeStatusPending {
+				return nil, fmt.Errorf("trade is not pending (status: %s)", t.Status)
+			}
+
+			if check != nil {
+				if err := check(t); err != nil {
+					return nil, err
+				}
+			}
+
+			now := time.Now()
+			t.Status = status
+			t.ResolvedAt = &now
+			t.UpdatedAt = now
+			return t, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		playerID := trade.SenderID
+		reason := ledgerReasonRefund
+		if status == TradeStatusAccepted {
+			playerID = trade.ReceiverID
+			reason = ledgerReasonAccept
+		}
+
+		balance, err := s.applyDelta(sessCtx, playerID, trade.ItemType, trade.Amount, nil)
+		if err != nil {
+			return err
+		}
+
+		return s.recordLedgerEntry(sessCtx, trade, playerID, trade.Amount, balance, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return trade, nil
+}
+
+// expireTrade expires a single pending trade if its expiry time has passed
+func (s *TradeService) expireTrade(ctx context.Context, tradeID primitive.ObjectID) (*Trade, error) {
+	return s.resolveTrade(ctx, tradeID, TradeStatusExpired, func(t *Trade) error {
+		if time.Now().Before(t.ExpiresAt) {
+			return fmt.Errorf("trade has not expired yet")
+		}
+		return nil
+	})
+}
+
+// applyDelta adds delta to the player's balance of the given item and returns the new balance.
+// check, if not nil, runs inside the update before the balance is changed.
+func (s *TradeService) applyDelta(
+	ctx context.Context,
+	playerID primitive.ObjectID,
+	itemType TradeItemType,
+	delta int,
+	check func() error,
+) (int, error) {
+	switch itemType {
+	case TradeItemTicket:
+		tickets, err := s.ticketService.storage.FindMany(ctx, bson.M{"player_id": playerID})
+		if err != nil {
+			return 0, err
+		}
+		if len(tickets) == 0 {
+			return 0, fmt.Errorf("player has no transport tickets")
+		}
+
+		ticket, _, err := s.ticketService.storage.FindOneAndUpdate(ctx, tickets[0].ID, func(t *TransportTicket) (*TransportTicket, error) {
+			if check != nil {
+				if err := check(); err != nil {
+					return nil, err
+				}
+			}
+			if t.CurrentTickets+delta < 0 {
+				return nil, fmt.Errorf("not enough transport tickets")
+			}
+			t.CurrentTickets += delta
+			t.UpdatedAt = time.Now()
+			return t, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		return ticket.CurrentTickets, nil
+
+	case TradeItemGoldOre:
+		inventory, err := s.findInventory(ctx, playerID)
+		if err != nil {
+			return 0, err
+		}
+
+		inventory, _, err = s.inventoryStorage.FindOneAndUpdate(ctx, inventory.ID, func(pi *PlayerInventory) (*PlayerInventory, error) {
+			if check != nil {
+				if err := check(); err != nil {
+					return nil, err
+				}
+			}
+			if pi.GoldOre+delta < 0 {
+				return nil, fmt.Errorf("not enough gold ore")
+			}
+			pi.GoldOre += delta
+			pi.UpdatedAt = time.Now()
+			return pi, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		return inventory.GoldOre, nil
+	}
+
+	return 0, fmt.Errorf("unknown trade item type: %s", itemType)
+}
+
+// recordLedgerEntry stores a ledger entry for a balance movement caused by a trade
+func (s *TradeService) recordLedgerEntry(
+	ctx context.Context,
+	trade *Trade,
+	playerID primitive.ObjectID,
+	delta int,
+	balance int,
+	reason string,
+) error {
+	now := time.Now()
+	entry := &TradeLedgerEntry{
+		ID:          primitive.NewObjectID(),
+		TradeID:     trade.ID,
+		PlayerID:    playerID,
+		AllianceID:  trade.AllianceID,
+		ItemType:    trade.ItemType,
+		Delta:       delta,
+		Balance:     balance,
+		Reason:      reason,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		VectorClock: 1, // Set initial version
+	}
+
+	_, err := s.ledgerStorage.FindOneAndUpsert(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to record ledger entry: %w", err)
+	}
+	return nil
+}
+
+// checkSendLimits checks the sender's cooldown and daily caps for the given item type
+func (s *TradeService) checkSendLimits(
+	ctx context.Context,
+	senderID primitive.ObjectID,
+	itemType TradeItemType,
+	amount int,
+	now time.Time,
+) error {
+	nowUTC := now.UTC()
+	dayStart := time.Date(nowUTC.Year(), nowUTC.Month(), nowUTC.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Check cooldown
+	trades, err := s.storage.FindMany(ctx, bson.M{
+		"sender_id":  senderID,
+		"created_at": bson.M{"$gte": now.Add(-tradeCooldown)},
+	})
+	if err != nil {
+		return err
+	}
+	if len(trades) > 0 {
+		return fmt.Errorf("trade cooldown is active, try again later")
+	}
+
+	// Trades that were declined, cancelled or expired were refunded and don't count towards the cap
+	trades, err = s.storage.FindMany(ctx, bson.M{
+		"sender_id":  senderID,
+		"item_type":  itemType,
+		"created_at": bson.M{"$gte": dayStart},
+		"status": bson.M{
+			"$in": []TradeStatus{
+				TradeStatusPending,
+				TradeStatusAccepted,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	sentToday := 0
+	for _, t := range trades {
+		sentToday += t.Amount
+	}
+
+	dailyCap := maxGoldOreSentPerDay
+	if itemType == TradeItemTicket {
+		dailyCap = maxTicketsSentPerDay
+	}
+
+	if sentToday+amount > dailyCap {
+		return fmt.Errorf("daily trade limit exceeded (%d/%d)", sentToday, dailyCap)
+	}
+
+	return nil
+}
+
+// findInventory finds the inventory of a player
+func (s *TradeService) findInventory(ctx context.Context, playerID primitive.ObjectID) (*PlayerInventory, error) {
+	inventories, err := s.inventoryStorage.FindMany(ctx, bson.M{"player_id": playerID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inventories) == 0 {
+		return nil, fmt.Errorf("player has no inventory")
+	}
+
+	return inventories[0], nil
+}
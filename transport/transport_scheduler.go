@@ -0,0 +1,322 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultSchedulerInterval is how often the scheduler looks for due transports
+const defaultSchedulerInterval = 5 * time.Second
+
+// TransportEventType represents the kind of transport event
+type TransportEventType string
+
+// Transport event type constants
+const (
+	TransportEventDeparted     TransportEventType = "departed"      // 출발
+	TransportEventArrived      TransportEventType = "arrived"       // 도착
+	TransportEventRaidResolved TransportEventType = "raid_resolved" // 약탈 판정
+)
+
+// TransportEvent is emitted when a transport departs, arrives or has a raid resolved
+type TransportEvent struct {
+	Type       TransportEventType
+	Transport  *Transport // Transport after the change; arrivals carry the distributed rewards, raids the defense result
+	OccurredAt time.Time
+}
+
+// SubscribeEvents returns a channel that receives transport events until ctx is cancelled.
+// Events are dropped for subscribers that fall more than eventBufferSize events behind.
+func (s *TransportService) SubscribeEvents(ctx context.Context) <-chan TransportEvent {
+	return s.events.subscribe(ctx)
+}
+
+// emit sends a transport event to every subscriber
+func (s *TransportService) emit(eventType TransportEventType, transport *Transport) {
+	dropped := s.events.publish(func() TransportEvent {
+		return TransportEvent{
+			Type:       eventType,
+			Transport:  transport.Copy(),
+			OccurredAt: time.Now(),
+		}
+	})
+	if dropped > 0 {
+		log.Printf("Dropped %s event for transport %s: %d subscribers are not keeping up", eventType, transport.ID.Hex(), dropped)
+	}
+}
+
+// StartScheduler starts a background worker that departs transports whose preparation time has
+// passed and resolves arrivals whose transport time has elapsed. The worker checks every interval
+// until ctx is cancelled. Due transports are looked up in storage, so transports that became due
+// while no scheduler was running are picked up on the first check.
+func (s *TransportService) StartScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.runSchedule(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runSchedule departs and resolves all transports that are due
+func (s *TransportService) runSchedule(ctx context.Context) {
+	if departed, err := s.DepartTransports(ctx); err != nil {
+		log.Printf("Failed to depart transports (%d departed): %v", departed, err)
+	}
+
+	if arrived, err := s.ResolveArrivals(ctx); err != nil {
+		log.Printf("Failed to resolve transport arrivals (%d arrived): %v", arrived, err)
+	}
+}
+
+// DepartTransports departs all preparing transports whose preparation time has passed.
+// It returns the number of transports that departed.
+func (s *TransportService) DepartTransports(ctx context.Context) (int, error) {
+	transports, err := s.storage.FindMany(ctx, bson.M{
+		"status":        TransportStatusPreparing,
+		"prep_end_time": bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	departed := 0
+	var errs []error
+	for _, transport := range transports {
+		if _, err := s.departTransport(ctx, transport.ID); err != nil {
+			errs = append(errs, fmt.Errorf("transport %s: %w", transport.ID.Hex(), err))
+			continue
+		}
+		departed++
+	}
+
+	return departed, errors.Join(errs...)
+}
+
+// ResolveArrivals resolves all in-progress transports whose transport time has elapsed.
+// Transports with an undefended raid are resolved once the raid is over.
+// It returns the number of transports that arrived.
+func (s *TransportService) ResolveArrivals(ctx context.Context) (int, error) {
+	transports, err := s.storage.FindMany(ctx, bson.M{
+		"status": bson.M{
+			"$in": []TransportStatus{
+				TransportStatusInProgress,
+				TransportStatusRaided,
+			},
+		},
+		"end_time":   bson.M{"$lte": time.Now()},
+		"arrived_at": nil,
+		"$or": bson.A{
+			bson.M{"raid_status": nil},
+			bson.M{"raid_status.is_defended": true},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	arrived := 0
+	var errs []error
+	for _, transport := range transports {
+		if _, err := s.resolveArrival(ctx, transport.ID); err != nil {
+			errs = append(errs, fmt.Errorf("transport %s: %w", transport.ID.Hex(), err))
+			continue
+		}
+		arrived++
+	}
+
+	return arrived, errors.Join(errs...)
+}
+
+// departTransport departs a single transport if its preparation time has passed
+func (s *TransportService) departTransport(ctx context.Context, transportID primitive.ObjectID) (*Transport, error) {
+	var transport *Transport
+	err := s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		transport, _, err = s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
+			// Only depart if still in preparation phase
+			if t.Status != TransportStatusPreparing {
+				return nil, fmt.Errorf("transport is not preparing (status: %s)", t.Status)
+			}
+
+			now := time.Now()
+			if now.Before(t.PrepEndTime) {
+				return nil, fmt.Errorf("transport is still preparing")
+			}
+
+			depart(t, now)
+			t.UpdatedAt = now
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return transportEvent(DomainEventTransportDeparted, transport, primitive.NilObjectID, bson.M{
+			"end_time": transport.EndTime,
+		}), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to depart transport: %w", err)
+	}
+
+	s.emit(TransportEventDeparted, transport)
+	return transport, nil
+}
+
+// resolveArrival completes a single transport whose transport time has elapsed.
+// The delivered gold ore is credited to the participants and their held tickets are consumed
+// in the same transaction, so an arrival is never paid out twice.
+func (s *TransportService) resolveArrival(ctx context.Context, transportID primitive.ObjectID) (*Transport, error) {
+	var transport *Transport
+
+	err := s.storage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var err error
+		transport, _, err = s.storage.FindOneAndUpdate(sessCtx, transportID, func(t *Transport) (*Transport, error) {
+			if t.ArrivedAt != nil {
+				return nil, fmt.Errorf("transport has already arrived")
+			}
+			if t.Status != TransportStatusInProgress && t.Status != TransportStatusRaided {
+				return nil, fmt.Errorf("transport is not in progress (status: %s)", t.Status)
+			}
+
+			now := time.Now()
+			if t.EndTime == nil || now.Before(*t.EndTime) {
+				return nil, fmt.Errorf("transport has not arrived yet")
+			}
+
+			// Wait for an ongoing raid to be defended or to expire
+			if t.RaidStatus != nil && !t.RaidStatus.IsDefended {
+				return nil, fmt.Errorf("transport is being raided")
+			}
+
+			// Raided transports keep their status; everything else has been delivered
+			if t.Status == TransportStatusInProgress {
+				t.Status = TransportStatusCompleted
+			}
+			t.Rewards = distributeGoldOre(t)
+			t.ArrivedAt = &now
+			t.UpdatedAt = now
+			return t, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, reward := range transport.Rewards {
+			if err := s.creditGoldOre(sessCtx, transport.AllianceID, reward.PlayerID, reward.GoldOre); err != nil {
+				return fmt.Errorf("failed to credit gold ore to %s: %w", reward.PlayerName, err)
+			}
+		}
+
+		for _, p := range transport.Participants {
+			if _, err := s.ticketService.ConsumeHeldTicket(sessCtx, p.PlayerID); err != nil {
+				return fmt.Errorf("failed to consume ticket of %s: %w", p.PlayerName, err)
+			}
+		}
+
+		rewards := bson.A{}
+		for _, reward := range transport.Rewards {
+			rewards = append(rewards, bson.M{"player_id": reward.PlayerID, "gold_ore": reward.GoldOre})
+		}
+		return s.outbox.Record(sessCtx, transportEvent(DomainEventTransportArrived, transport, primitive.NilObjectID, bson.M{
+			"rewards": rewards,
+		}))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve transport arrival: %w", err)
+	}
+
+	s.emit(TransportEventArrived, transport)
+	return transport, nil
+}
+
+// depart moves a transport into the in-progress state
+func depart(t *Transport, now time.Time) {
+	endTime := now.Add(t.TransportTime)
+	t.Status = TransportStatusInProgress
+	t.StartTime = &now
+	t.EndTime = &endTime
+}
+
+// distributeGoldOre splits the delivered gold ore between the participants in proportion to the
+// amount each of them loaded. Gold ore left over by rounding goes to the earliest participants.
+func distributeGoldOre(t *Transport) []TransportReward {
+	loaded := 0
+	for _, p := range t.Participants {
+		loaded += p.GoldOreAmount
+	}
+
+	rewards := make([]TransportReward, len(t.Participants))
+	distributed := 0
+	for i, p := range t.Participants {
+		share := 0
+		if loaded > 0 {
+			share = t.GoldOreAmount * p.GoldOreAmount / loaded
+		}
+		rewards[i] = TransportReward{
+			PlayerID:   p.PlayerID,
+			PlayerName: p.PlayerName,
+			GoldOre:    share,
+		}
+		distributed += share
+	}
+
+	for i := 0; distributed < t.GoldOreAmount && len(rewards) > 0; i = (i + 1) % len(rewards) {
+		rewards[i].GoldOre++
+		distributed++
+	}
+
+	return rewards
+}
+
+// creditGoldOre adds gold ore to a player's inventory, creating the inventory if needed
+func (s *TransportService) creditGoldOre(ctx context.Context, allianceID, playerID primitive.ObjectID, amount int) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	inventories, err := s.inventoryStorage.FindMany(ctx, bson.M{"player_id": playerID})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(inventories) == 0 {
+		_, err = s.inventoryStorage.FindOneAndUpsert(ctx, &PlayerInventory{
+			ID:          primitive.NewObjectID(),
+			PlayerID:    playerID,
+			AllianceID:  allianceID,
+			GoldOre:     amount,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			VectorClock: 1, // Set initial version
+		})
+		return err
+	}
+
+	_, _, err = s.inventoryStorage.FindOneAndUpdate(ctx, inventories[0].ID, func(pi *PlayerInventory) (*PlayerInventory, error) {
+		pi.GoldOre += amount
+		pi.UpdatedAt = now
+		return pi, nil
+	})
+	return err
+}
@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"time"
 
 	"nodestorage/v2"
@@ -13,27 +14,74 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// defaultPrepTime is how long a transport waits for participants before it departs
+const defaultPrepTime = 30 * time.Minute
+
 // TransportService provides operations for managing transports
 type TransportService struct {
-	storage       nodestorage.Storage[*Transport]
-	mineService   *MineService
-	ticketService *TicketService
+	storage          nodestorage.Storage[*Transport]
+	inventoryStorage nodestorage.Storage[*PlayerInventory]
+	reportStorage    nodestorage.Storage[*BattleReport]
+	mineService      *MineService
+	ticketService    *TicketService
+	outbox           *OutboxService
+	idempotency      *IdempotencyService
+	alliances        *AllianceService
+	cheats           *AntiCheatService
+	prepTime         time.Duration
+	events           *eventHub[TransportEvent]
 }
 
 // NewTransportService creates a new TransportService
 func NewTransportService(
 	storage nodestorage.Storage[*Transport],
+	inventoryStorage nodestorage.Storage[*PlayerInventory],
+	reportStorage nodestorage.Storage[*BattleReport],
 	mineService *MineService,
 	ticketService *TicketService,
 ) *TransportService {
 	return &TransportService{
-		storage:       storage,
-		mineService:   mineService,
-		ticketService: ticketService,
+		storage:          storage,
+		inventoryStorage: inventoryStorage,
+		reportStorage:    reportStorage,
+		mineService:      mineService,
+		ticketService:    ticketService,
+		prepTime:         defaultPrepTime,
+		events:           newEventHub[TransportEvent](),
 	}
 }
 
-// StartTransport starts a new transport from a mine
+// SetPrepTime changes the preparation time of transports started from now on.
+// This is mainly useful for demos and tests, where waiting 30 minutes is impractical.
+func (s *TransportService) SetPrepTime(prepTime time.Duration) {
+	s.prepTime = prepTime
+}
+
+// SetOutbox makes the service record a domain event for every change to transports
+func (s *TransportService) SetOutbox(outbox *OutboxService) {
+	s.outbox = outbox
+}
+
+// SetIdempotency makes StartTransport and JoinTransport honor idempotency keys (see WithIdempotencyKey)
+func (s *TransportService) SetIdempotency(idempotency *IdempotencyService) {
+	s.idempotency = idempotency
+}
+
+// SetAlliances makes the service check that only members of the owning alliance start or
+// join transports
+func (s *TransportService) SetAlliances(alliances *AllianceService) {
+	s.alliances = alliances
+}
+
+// SetCheats makes the service record requests to load negative gold ore or to join a
+// transport that already finished
+func (s *TransportService) SetCheats(cheats *AntiCheatService) {
+	s.cheats = cheats
+}
+
+// StartTransport starts a new transport from a mine.
+// Called with a context from WithIdempotencyKey, retries return the transport started by the
+// first call instead of starting another one.
 func (s *TransportService) StartTransport(
 	ctx context.Context,
 	playerID primitive.ObjectID,
@@ -41,12 +89,57 @@ func (s *TransportService) StartTransport(
 	mineID primitive.ObjectID,
 	goldOreAmount int,
 ) (*Transport, error) {
+	var transport *Transport
+	earlier, err := s.idempotency.do(ctx, playerID, IdempotentActionStartTransport,
+		fmt.Sprintf("%s:%d", mineID.Hex(), goldOreAmount),
+		func(result *IdempotencyKey) error {
+			var err error
+			transport, err = s.startTransport(ctx, playerID, playerName, mineID, goldOreAmount)
+			if err != nil {
+				return err
+			}
+			result.ResultID = transport.ID
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if earlier != nil {
+		return s.GetTransport(ctx, earlier.ResultID)
+	}
+
+	return transport, nil
+}
+
+// startTransport starts a new transport from a mine
+func (s *TransportService) startTransport(
+	ctx context.Context,
+	playerID primitive.ObjectID,
+	playerName string,
+	mineID primitive.ObjectID,
+	goldOreAmount int,
+) (*Transport, error) {
+	if err := s.cheats.Check(ctx, detectNegativeGoldOre(playerID, CheatActionStartTransport, mineID, goldOreAmount)); err != nil {
+		return nil, err
+	}
+
 	// Get the mine
 	mine, err := s.mineService.GetMine(ctx, mineID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get mine: %w", err)
 	}
 
+	// Contested mines cannot send out transports
+	if mine.Status == MineStatusContested {
+		return nil, fmt.Errorf("mine is under attack")
+	}
+
+	// Only members of the owning alliance can send out transports
+	if err := s.alliances.CheckMember(ctx, mine.AllianceID, playerID); err != nil {
+		return nil, err
+	}
+
 	// Get mine configuration
 	mineConfig, err := s.mineService.GetMineConfig(ctx, mine.Level)
 	if err != nil {
@@ -72,8 +165,8 @@ func (s *TransportService) StartTransport(
 		}
 	}
 
-	// Use a transport ticket
-	_, err = s.ticketService.UseTicket(ctx, playerID)
+	// Hold a transport ticket until the transport arrives
+	_, err = s.ticketService.HoldTicket(ctx, playerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to use transport ticket: %w", err)
 	}
@@ -84,13 +177,14 @@ func (s *TransportService) StartTransport(
 		if err != nil {
 			// Refund the ticket if we can't remove gold ore
 			// This is a simplification - in a real system, you'd use transactions
+			s.ticketService.ReleaseTicket(ctx, playerID)
 			return nil, fmt.Errorf("failed to remove gold ore from mine: %w", err)
 		}
 	}
 
 	// Create transport
 	now := time.Now()
-	prepEndTime := now.Add(30 * time.Minute)
+	prepEndTime := now.Add(s.prepTime)
 	transportTime := time.Duration(mineConfig.TransportTime) * time.Minute
 
 	transport := &Transport{
@@ -119,18 +213,29 @@ func (s *TransportService) StartTransport(
 	}
 
 	// Save the transport
-	transport, err = s.storage.FindOneAndUpsert(ctx, transport)
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		transport, err = s.storage.FindOneAndUpsert(ctx, transport)
+		if err != nil {
+			return nil, err
+		}
+		return transportEvent(DomainEventTransportStarted, transport, playerID, bson.M{
+			"loaded_gold_ore": actualAmount,
+			"prep_end_time":   transport.PrepEndTime,
+		}), nil
+	})
 	if err != nil {
+		s.ticketService.ReleaseTicket(ctx, playerID)
 		return nil, fmt.Errorf("failed to create transport: %w", err)
 	}
 
-	// Schedule transport start after prep time
-	go s.scheduleTransportStart(context.Background(), transport.ID, prepEndTime)
-
+	// The scheduler departs the transport once the preparation time has passed
 	return transport, nil
 }
 
-// JoinTransport joins an existing transport
+// JoinTransport joins an existing transport.
+// Called with a context from WithIdempotencyKey, retries return the transport instead of
+// holding another ticket.
 func (s *TransportService) JoinTransport(
 	ctx context.Context,
 	transportID primitive.ObjectID,
@@ -138,104 +243,174 @@ func (s *TransportService) JoinTransport(
 	playerName string,
 	goldOreAmount int,
 ) (*Transport, error) {
-	// Use a transport ticket
-	_, err := s.ticketService.UseTicket(ctx, playerID)
+	var transport *Transport
+	earlier, err := s.idempotency.do(ctx, playerID, IdempotentActionJoinTransport,
+		fmt.Sprintf("%s:%d", transportID.Hex(), goldOreAmount),
+		func(result *IdempotencyKey) error {
+			var err error
+			transport, err = s.joinTransport(ctx, transportID, playerID, playerName, goldOreAmount)
+			if err != nil {
+				return err
+			}
+			result.ResultID = transport.ID
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if earlier != nil {
+		return s.GetTransport(ctx, earlier.ResultID)
+	}
+
+	return transport, nil
+}
+
+// joinTransport joins an existing transport
+func (s *TransportService) joinTransport(
+	ctx context.Context,
+	transportID primitive.ObjectID,
+	playerID primitive.ObjectID,
+	playerName string,
+	goldOreAmount int,
+) (*Transport, error) {
+	if err := s.cheats.Check(ctx, detectNegativeGoldOre(playerID, CheatActionJoinTransport, transportID, goldOreAmount)); err != nil {
+		return nil, err
+	}
+
+	// Only members of the owning alliance can join the transport
+	transport, err := s.GetTransport(ctx, transportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transport: %w", err)
+	}
+	if err := s.alliances.CheckMember(ctx, transport.AllianceID, playerID); err != nil {
+		return nil, err
+	}
+	if err := s.cheats.Check(ctx, detectFinishedTransport(playerID, transport)); err != nil {
+		return nil, err
+	}
+
+	// Hold a transport ticket until the transport arrives
+	_, err = s.ticketService.HoldTicket(ctx, playerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to use transport ticket: %w", err)
 	}
 
 	// Join the transport
-	transport, _, err := s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
-		// Check if transport is still in preparation phase
-		if t.Status != TransportStatusPreparing {
-			return nil, fmt.Errorf("transport is no longer accepting participants")
-		}
+	var loaded int
+	err = s.outbox.run(ctx, func(sessCtx context.Context) (*OutboxEvent, error) {
+		var err error
+		transport, _, err = s.storage.FindOneAndUpdate(sessCtx, transportID, func(t *Transport) (*Transport, error) {
+			// Check if transport is still in preparation phase
+			if t.Status != TransportStatusPreparing {
+				return nil, fmt.Errorf("transport is no longer accepting participants")
+			}
 
-		// Check if player is already participating
-		for _, p := range t.Participants {
-			if p.PlayerID == playerID {
-				return nil, fmt.Errorf("player is already participating in this transport")
+			// Check if player is already participating
+			for _, p := range t.Participants {
+				if p.PlayerID == playerID {
+					return nil, fmt.Errorf("player is already participating in this transport")
+				}
 			}
-		}
 
-		// Check if transport is full
-		if len(t.Participants) >= t.MaxParticipants {
-			return nil, fmt.Errorf("transport is full")
-		}
+			// Check if transport is full
+			if len(t.Participants) >= t.MaxParticipants {
+				return nil, fmt.Errorf("transport is full")
+			}
 
-		// Get mine configuration
-		mineConfig, err := s.mineService.GetMineConfig(ctx, t.MineLevel)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get mine configuration: %w", err)
-		}
+			// Get mine configuration
+			mineConfig, err := s.mineService.GetMineConfig(ctx, t.MineLevel)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get mine configuration: %w", err)
+			}
 
-		// Validate gold ore amount
-		if goldOreAmount < mineConfig.MinTransportAmount {
-			return nil, fmt.Errorf("gold ore amount is below minimum (%d)", mineConfig.MinTransportAmount)
-		}
-		if goldOreAmount > mineConfig.MaxTransportAmount {
-			return nil, fmt.Errorf("gold ore amount exceeds maximum (%d)", mineConfig.MaxTransportAmount)
-		}
+			// Validate gold ore amount
+			if goldOreAmount < mineConfig.MinTransportAmount {
+				return nil, fmt.Errorf("gold ore amount is below minimum (%d)", mineConfig.MinTransportAmount)
+			}
+			if goldOreAmount > mineConfig.MaxTransportAmount {
+				return nil, fmt.Errorf("gold ore amount exceeds maximum (%d)", mineConfig.MaxTransportAmount)
+			}
 
-		// Get the mine
-		mine, err := s.mineService.GetMine(ctx, t.MineID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get mine: %w", err)
-		}
+			// Get the mine
+			mine, err := s.mineService.GetMine(ctx, t.MineID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get mine: %w", err)
+			}
+			if mine.Status == MineStatusContested {
+				return nil, fmt.Errorf("mine is under attack")
+			}
 
-		// Check if there's enough gold ore in the mine
-		actualAmount := goldOreAmount
-		if mine.GoldOre < goldOreAmount {
-			// If not enough gold ore, use minimum amount
-			if mine.GoldOre < mineConfig.MinTransportAmount {
-				actualAmount = mineConfig.MinTransportAmount
-			} else {
-				actualAmount = mine.GoldOre
+			// Check if there's enough gold ore in the mine
+			actualAmount := goldOreAmount
+			if mine.GoldOre < goldOreAmount {
+				// If not enough gold ore, use minimum amount
+				if mine.GoldOre < mineConfig.MinTransportAmount {
+					actualAmount = mineConfig.MinTransportAmount
+				} else {
+					actualAmount = mine.GoldOre
+				}
 			}
-		}
 
-		// Remove gold ore from mine if there's enough
-		if mine.GoldOre >= actualAmount {
-			_, err = s.mineService.RemoveGoldOre(ctx, t.MineID, actualAmount)
-			if err != nil {
-				return nil, fmt.Errorf("failed to remove gold ore from mine: %w", err)
+			// Remove gold ore from mine if there's enough
+			if mine.GoldOre >= actualAmount {
+				_, err = s.mineService.RemoveGoldOre(ctx, t.MineID, actualAmount)
+				if err != nil {
+					return nil, fmt.Errorf("failed to remove gold ore from mine: %w", err)
+				}
 			}
-		}
 
-		// Add player to participants
-		t.Participants = append(t.Participants, TransportMember{
-			PlayerID:      playerID,
-			PlayerName:    playerName,
-			GoldOreAmount: actualAmount,
-			JoinedAt:      time.Now(),
-		})
+			// Add player to participants
+			loaded = actualAmount
+			t.Participants = append(t.Participants, TransportMember{
+				PlayerID:      playerID,
+				PlayerName:    playerName,
+				GoldOreAmount: actualAmount,
+				JoinedAt:      time.Now(),
+			})
 
-		// Update total gold ore amount
-		t.GoldOreAmount += actualAmount
+			// Update total gold ore amount
+			t.GoldOreAmount += actualAmount
 
-		// Check if transport is now full
-		if len(t.Participants) >= t.MaxParticipants {
 			// Start transport immediately if full
-			now := time.Now()
-			endTime := now.Add(t.TransportTime)
-			t.Status = TransportStatusInProgress
-			t.StartTime = &now
-			t.EndTime = &endTime
+			if len(t.Participants) >= t.MaxParticipants {
+				depart(t, time.Now())
+			}
 
-			// Schedule transport completion
-			go s.scheduleTransportCompletion(context.Background(), t.ID, endTime)
+			t.UpdatedAt = time.Now()
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
 		}
 
-		t.UpdatedAt = time.Now()
-		return t, nil
+		// A full transport departs right away
+		if transport.Status == TransportStatusInProgress {
+			err = s.outbox.Record(sessCtx, transportEvent(DomainEventTransportDeparted, transport, primitive.NilObjectID, bson.M{
+				"end_time": transport.EndTime,
+			}))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return transportEvent(DomainEventTransportJoined, transport, playerID, bson.M{
+			"loaded_gold_ore": loaded,
+		}), nil
 	})
 
 	if err != nil {
 		// Refund the ticket if joining fails
 		// This is a simplification - in a real system, you'd use transactions
+		s.ticketService.ReleaseTicket(ctx, playerID)
 		return nil, fmt.Errorf("failed to join transport: %w", err)
 	}
 
+	// A full transport departs right away; the scheduler resolves its arrival later
+	if transport.Status == TransportStatusInProgress {
+		s.emit(TransportEventDeparted, transport)
+	}
+
 	return transport, nil
 }
 
@@ -264,38 +439,62 @@ func (s *TransportService) GetPlayerTransports(ctx context.Context, playerID pri
 	})
 }
 
-// RaidTransport initiates a raid on a transport
+// RaidTransport initiates a raid on a transport.
+// The raider's force is recorded now and fights the defender, if any, when the raid is resolved.
 func (s *TransportService) RaidTransport(
 	ctx context.Context,
 	transportID primitive.ObjectID,
-	raiderID primitive.ObjectID,
-	raiderName string,
+	raider CombatOrder,
 ) (*Transport, error) {
-	transport, _, err := s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
-		// Check if transport is in progress
-		if t.Status != TransportStatusInProgress {
-			return nil, fmt.Errorf("transport cannot be raided in its current state")
-		}
+	attacker, err := s.mineService.GetGeneralService().BuildCombatForce(ctx, raider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to raid transport: %w", err)
+	}
 
-		// Check if transport is already being raided
-		if t.RaidStatus != nil {
-			return nil, fmt.Errorf("transport is already being raided")
-		}
+	var transport *Transport
+	err = s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		transport, _, err = s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
+			// Check if transport is in progress
+			if t.Status != TransportStatusInProgress {
+				return nil, fmt.Errorf("transport cannot be raided in its current state")
+			}
 
-		// Create raid status
-		now := time.Now()
-		defenseEndTime := now.Add(30 * time.Minute)
-		t.RaidStatus = &RaidStatus{
-			RaiderID:       raiderID,
-			RaiderName:     raiderName,
-			RaidStartTime:  now,
-			DefenseEndTime: defenseEndTime,
-			IsDefended:     false,
-			DefenseResult:  nil,
-		}
+			// Check if transport is already being raided
+			if t.RaidStatus != nil {
+				return nil, fmt.Errorf("transport is already being raided")
+			}
+
+			// Alliance members cannot raid their own transports
+			if raider.AllianceID == t.AllianceID {
+				return nil, fmt.Errorf("cannot raid a transport of your own alliance")
+			}
 
-		t.UpdatedAt = now
-		return t, nil
+			// Create raid status
+			now := time.Now()
+			defenseEndTime := now.Add(30 * time.Minute)
+			t.RaidStatus = &RaidStatus{
+				RaiderID:       raider.PlayerID,
+				RaiderName:     raider.PlayerName,
+				RaidStartTime:  now,
+				DefenseEndTime: defenseEndTime,
+				IsDefended:     false,
+				DefenseResult:  nil,
+				Attacker:       attacker,
+				Seed:           rand.Int63(),
+			}
+
+			t.UpdatedAt = now
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return transportEvent(DomainEventTransportRaided, transport, raider.PlayerID, bson.M{
+			"raider_alliance_id": raider.AllianceID,
+			"raider_troops":      attacker.Troops,
+			"defense_end_time":   transport.RaidStatus.DefenseEndTime,
+		}), nil
 	})
 
 	if err != nil {
@@ -305,42 +504,89 @@ func (s *TransportService) RaidTransport(
 	// Schedule raid completion if not defended
 	go s.scheduleRaidCompletion(context.Background(), transportID, transport.RaidStatus.DefenseEndTime)
 
+	if err := s.mineService.GetGeneralService().SpendBattleStamina(ctx, attacker); err != nil {
+		return transport, fmt.Errorf("raid started but failed to update generals: %w", err)
+	}
+
 	return transport, nil
 }
 
-// DefendTransport defends a transport from a raid
+// DefendTransport defends a transport from a raid.
+// The defender's force fights the raider's force right away; see ResolveCombat.
 func (s *TransportService) DefendTransport(
 	ctx context.Context,
 	transportID primitive.ObjectID,
-	defenderID primitive.ObjectID,
-	defenderName string,
-	successful bool,
+	defender CombatOrder,
 ) (*Transport, error) {
-	transport, _, err := s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
-		// Check if transport is being raided
-		if t.RaidStatus == nil {
-			return nil, fmt.Errorf("transport is not being raided")
-		}
+	force, err := s.mineService.GetGeneralService().BuildCombatForce(ctx, defender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to defend transport: %w", err)
+	}
 
-		// Check if raid has already been defended
-		if t.RaidStatus.IsDefended {
-			return nil, fmt.Errorf("raid has already been defended")
-		}
+	transport, err := s.resolveRaid(ctx, transportID, &force)
+	if err != nil {
+		return nil, fmt.Errorf("failed to defend transport: %w", err)
+	}
 
-		// Check if defense window has expired
-		now := time.Now()
-		if now.After(t.RaidStatus.DefenseEndTime) {
-			return nil, fmt.Errorf("defense window has expired")
-		}
+	if err := s.mineService.GetGeneralService().SpendBattleStamina(ctx, force); err != nil {
+		return transport, fmt.Errorf("transport defended but failed to update generals: %w", err)
+	}
+
+	return transport, nil
+}
+
+// GetBattleReport retrieves a battle report by ID
+func (s *TransportService) GetBattleReport(ctx context.Context, reportID primitive.ObjectID) (*BattleReport, error) {
+	return s.reportStorage.FindOne(ctx, reportID)
+}
+
+// GetBattleReports retrieves the battle reports of a transport
+func (s *TransportService) GetBattleReports(ctx context.Context, transportID primitive.ObjectID) ([]*BattleReport, error) {
+	return s.reportStorage.FindMany(ctx, bson.M{"transport_id": transportID})
+}
+
+// resolveRaid fights the battle of an ongoing raid and applies its outcome.
+// A nil defender means nobody defended before the defense window ended.
+// The transport, the battle report and the raider's stolen gold ore are stored in one transaction.
+func (s *TransportService) resolveRaid(ctx context.Context, transportID primitive.ObjectID, defender *CombatForce) (*Transport, error) {
+	var transport *Transport
+	var report *BattleReport
+
+	err := s.storage.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var err error
+		transport, _, err = s.storage.FindOneAndUpdate(sessCtx, transportID, func(t *Transport) (*Transport, error) {
+			// Check if transport is being raided
+			if t.RaidStatus == nil {
+				return nil, fmt.Errorf("transport is not being raided")
+			}
+
+			// Check if raid has already been defended
+			if t.RaidStatus.IsDefended {
+				return nil, fmt.Errorf("raid has already been defended")
+			}
+
+			// Defenders must arrive within the defense window; undefended raids resolve after it
+			now := time.Now()
+			if defender != nil && now.After(t.RaidStatus.DefenseEndTime) {
+				return nil, fmt.Errorf("defense window has expired")
+			}
+			if defender == nil && now.Before(t.RaidStatus.DefenseEndTime) {
+				return nil, fmt.Errorf("defense window is still open")
+			}
+			if defender != nil && defender.AllianceID != t.AllianceID {
+				return nil, fmt.Errorf("only alliance members can defend this transport")
+			}
 
-		// Mark as defended
-		t.RaidStatus.IsDefended = true
+			var opponent CombatForce
+			if defender != nil {
+				opponent = *defender
+			}
 
-		// Calculate gold ore lost if defense failed
-		goldOreLost := 0
-		if !successful {
-			// If defense failed, lose 30% of gold ore
-			goldOreLost = int(float64(t.GoldOreAmount) * 0.3)
+			// Fight the battle
+			attacker := t.RaidStatus.Attacker
+			result := ResolveCombat(attacker, opponent, t.RaidStatus.Seed)
+			goldOreBefore := t.GoldOreAmount
+			goldOreLost, goldOreStolen := raidLoss(result, attacker.Troops, t.GoldOreAmount, defender != nil)
 			t.GoldOreAmount -= goldOreLost
 
 			// If all gold ore is lost, mark transport as raided
@@ -348,23 +594,78 @@ func (s *TransportService) DefendTransport(
 				t.Status = TransportStatusRaided
 				t.GoldOreAmount = 0
 			}
+
+			report = &BattleReport{
+				ID:            primitive.NewObjectID(),
+				TransportID:   t.ID,
+				AllianceID:    t.AllianceID,
+				Attacker:      attacker.Copy(),
+				Defender:      opponent.Copy(),
+				Defended:      defender != nil,
+				Seed:          t.RaidStatus.Seed,
+				Rounds:        result.Rounds,
+				Outcome:       result.Outcome,
+				GoldOreBefore: goldOreBefore,
+				GoldOreLost:   goldOreLost,
+				GoldOreStolen: goldOreStolen,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+				VectorClock:   1, // Set initial version
+			}
+
+			// Record defense result
+			t.RaidStatus.IsDefended = true
+			t.RaidStatus.DefenseResult = &DefenseResult{
+				Successful:     result.Outcome == BattleOutcomeDefenderWon,
+				DefenderID:     opponent.PlayerID,
+				DefenderName:   opponent.PlayerName,
+				CompletedAt:    now,
+				GoldOreLost:    goldOreLost,
+				GoldOreStolen:  goldOreStolen,
+				BattleReportID: report.ID,
+			}
+
+			t.UpdatedAt = now
+			return t, nil
+		})
+		if err != nil {
+			return err
 		}
 
-		// Record defense result
-		t.RaidStatus.DefenseResult = &DefenseResult{
-			Successful:   successful,
-			DefenderID:   defenderID,
-			DefenderName: defenderName,
-			CompletedAt:  now,
-			GoldOreLost:  goldOreLost,
+		if _, err := s.reportStorage.FindOneAndUpsert(sessCtx, report); err != nil {
+			return fmt.Errorf("failed to record battle report: %w", err)
+		}
+
+		// Both alliances learn the outcome
+		event := transportEvent(DomainEventRaidResolved, transport, report.Defender.PlayerID, bson.M{
+			"outcome":            report.Outcome,
+			"defended":           report.Defended,
+			"raider_alliance_id": report.Attacker.AllianceID,
+			"gold_ore_lost":      report.GoldOreLost,
+			"gold_ore_stolen":    report.GoldOreStolen,
+			"battle_report_id":   report.ID,
+		})
+		raiderEvent := event.Copy()
+		raiderEvent.AllianceID = report.Attacker.AllianceID
+		for _, e := range []*OutboxEvent{event, raiderEvent} {
+			if err := s.outbox.Record(sessCtx, e); err != nil {
+				return err
+			}
 		}
 
-		t.UpdatedAt = now
-		return t, nil
+		// Credit the raider with the gold ore they carried away
+		return s.creditGoldOre(sessCtx, report.Attacker.AllianceID, report.Attacker.PlayerID, report.GoldOreStolen)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.emit(TransportEventRaidResolved, transport)
 
+	// Generals who lost the battle are injured
+	err = s.mineService.GetGeneralService().applyBattleInjuries(ctx, report.Attacker, report.Defender, report.Outcome)
 	if err != nil {
-		return nil, fmt.Errorf("failed to defend transport: %w", err)
+		return transport, fmt.Errorf("raid resolved but failed to injure generals: %w", err)
 	}
 
 	return transport, nil
@@ -394,21 +695,29 @@ func (s *TransportService) WatchAllTransports(ctx context.Context, allianceID pr
 
 // UpdateTransportStatus updates the status of a transport
 func (s *TransportService) UpdateTransportStatus(ctx context.Context, transportID primitive.ObjectID, status TransportStatus) (*Transport, error) {
-	transport, _, err := s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
-		t.Status = status
-
-		// If status is in progress, set start time
-		if status == TransportStatusInProgress {
-			now := time.Now()
-			t.StartTime = &now
+	var transport *Transport
+	err := s.outbox.run(ctx, func(ctx context.Context) (*OutboxEvent, error) {
+		var err error
+		transport, _, err = s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
+			t.Status = status
+
+			// If status is in progress, set start time
+			if status == TransportStatusInProgress {
+				now := time.Now()
+				t.StartTime = &now
+
+				// Set end time based on transport time
+				endTime := now.Add(t.TransportTime)
+				t.EndTime = &endTime
+			}
 
-			// Set end time based on transport time
-			endTime := now.Add(t.TransportTime)
-			t.EndTime = &endTime
+			t.UpdatedAt = time.Now()
+			return t, nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		t.UpdatedAt = time.Now()
-		return t, nil
+		return transportEvent(DomainEventTransportStatusUpdate, transport, primitive.NilObjectID, nil), nil
 	})
 
 	if err != nil {
@@ -418,73 +727,6 @@ func (s *TransportService) UpdateTransportStatus(ctx context.Context, transportI
 	return transport, nil
 }
 
-// scheduleTransportStart schedules the start of a transport after preparation time
-func (s *TransportService) scheduleTransportStart(ctx context.Context, transportID primitive.ObjectID, startTime time.Time) {
-	// Wait until start time
-	waitTime := time.Until(startTime)
-	if waitTime > 0 {
-		time.Sleep(waitTime)
-	}
-
-	// Start the transport
-	transport, _, err := s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
-		// Only start if still in preparation phase
-		if t.Status != TransportStatusPreparing {
-			return t, nil
-		}
-
-		now := time.Now()
-		endTime := now.Add(t.TransportTime)
-		t.Status = TransportStatusInProgress
-		t.StartTime = &now
-		t.EndTime = &endTime
-		t.UpdatedAt = now
-		return t, nil
-	})
-
-	if err != nil {
-		// Log error in real implementation
-		return
-	}
-
-	// Schedule transport completion
-	if transport.Status == TransportStatusInProgress && transport.EndTime != nil {
-		go s.scheduleTransportCompletion(ctx, transportID, *transport.EndTime)
-	}
-}
-
-// scheduleTransportCompletion schedules the completion of a transport
-func (s *TransportService) scheduleTransportCompletion(ctx context.Context, transportID primitive.ObjectID, endTime time.Time) {
-	// Wait until end time
-	waitTime := time.Until(endTime)
-	if waitTime > 0 {
-		time.Sleep(waitTime)
-	}
-
-	// Complete the transport
-	_, _, err := s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
-		// Only complete if in progress and not raided
-		if t.Status != TransportStatusInProgress {
-			return t, nil
-		}
-
-		// If being raided, don't complete yet
-		if t.RaidStatus != nil && !t.RaidStatus.IsDefended {
-			return t, nil
-		}
-
-		now := time.Now()
-		t.Status = TransportStatusCompleted
-		t.UpdatedAt = now
-		return t, nil
-	})
-
-	if err != nil {
-		// Log error in real implementation
-		return
-	}
-}
-
 // scheduleRaidCompletion schedules the completion of a raid if not defended
 func (s *TransportService) scheduleRaidCompletion(ctx context.Context, transportID primitive.ObjectID, defenseEndTime time.Time) {
 	// Wait until defense end time
@@ -494,39 +736,12 @@ func (s *TransportService) scheduleRaidCompletion(ctx context.Context, transport
 	}
 
 	// Complete the raid if not defended
-	_, _, err := s.storage.FindOneAndUpdate(ctx, transportID, func(t *Transport) (*Transport, error) {
-		// Check if raid exists and hasn't been defended
-		if t.RaidStatus == nil || t.RaidStatus.IsDefended {
-			return t, nil
-		}
-
-		now := time.Now()
-
-		// Raid succeeded - lose 50% of gold ore
-		goldOreLost := int(float64(t.GoldOreAmount) * 0.5)
-		t.GoldOreAmount -= goldOreLost
-
-		// If all gold ore is lost, mark transport as raided
-		if t.GoldOreAmount <= 0 {
-			t.Status = TransportStatusRaided
-			t.GoldOreAmount = 0
-		}
-
-		// Record defense result (automatic failure)
-		t.RaidStatus.IsDefended = true
-		t.RaidStatus.DefenseResult = &DefenseResult{
-			Successful:   false,
-			DefenderID:   primitive.NilObjectID, // No defender
-			DefenderName: "",
-			CompletedAt:  now,
-			GoldOreLost:  goldOreLost,
-		}
-
-		t.UpdatedAt = now
-		return t, nil
-	})
+	transport, err := s.GetTransport(ctx, transportID)
+	if err != nil || transport.RaidStatus == nil || transport.RaidStatus.IsDefended {
+		return
+	}
 
-	if err != nil {
+	if _, err := s.resolveRaid(ctx, transportID, nil); err != nil {
 		// Log error in real implementation
 		return
 	}
@@ -2,17 +2,30 @@ package transport
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
 	"testing"
 	"time"
 
 	"nodestorage/v2"
 	"nodestorage/v2/cache"
+	"tictactoe/luvjson/crdtpubsub"
+	"tictactoe/transport/transportpb"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 // setupTestMongoDB sets up a MongoDB client and collections for testing
@@ -51,52 +64,71 @@ func setupTestMongoDB(t *testing.T) (*mongo.Client, *mongo.Collection, *mongo.Co
 	return client, mineCollection, configCollection, transportCollection, ticketCollection, cleanup
 }
 
+// newTestStorage creates a storage for a test collection that is closed when the test ends
+func newTestStorage[T nodestorage.Cachable[T]](t *testing.T, collection *mongo.Collection) *nodestorage.StorageImpl[T] {
+	t.Helper()
+
+	storage, err := nodestorage.NewStorage[T](context.Background(), collection, cache.NewMemoryCache[T](nil),
+		&nodestorage.Options{VersionField: "VectorClock", CacheTTL: time.Hour})
+	require.NoError(t, err, "Failed to create storage for %s", collection.Name())
+	t.Cleanup(func() { storage.Close() })
+
+	return storage
+}
+
 // setupTestServices sets up the services for testing
 func setupTestServices(t *testing.T) (*MineService, *TicketService, *TransportService, func()) {
 	// Set up MongoDB
 	client, mineCollection, configCollection, transportCollection, ticketCollection, cleanup := setupTestMongoDB(t)
-
-	// Create caches
-	mineCache := cache.NewMemoryCache[*Mine](nil)
-	configCache := cache.NewMemoryCache[*MineConfig](nil)
-	transportCache := cache.NewMemoryCache[*Transport](nil)
-	ticketCache := cache.NewMemoryCache[*TransportTicket](nil)
-
-	// Create storage options
-	storageOptions := &nodestorage.Options{
-		VersionField: "VectorClock",
-		CacheTTL:     time.Hour,
-	}
+	inventoryCollection := client.Database("test_db").Collection("test_inventories_" + primitive.NewObjectID().Hex())
+	reportCollection := client.Database("test_db").Collection("test_battle_reports_" + primitive.NewObjectID().Hex())
 
 	// Create storages
-	ctx := context.Background()
-	mineStorage, err := nodestorage.NewStorage[*Mine](ctx, client, mineCollection, mineCache, storageOptions)
-	require.NoError(t, err, "Failed to create mine storage")
-
-	configStorage, err := nodestorage.NewStorage[*MineConfig](ctx, client, configCollection, configCache, storageOptions)
-	require.NoError(t, err, "Failed to create config storage")
-
-	transportStorage, err := nodestorage.NewStorage[*Transport](ctx, client, transportCollection, transportCache, storageOptions)
-	require.NoError(t, err, "Failed to create transport storage")
-
-	ticketStorage, err := nodestorage.NewStorage[*TransportTicket](ctx, client, ticketCollection, ticketCache, storageOptions)
-	require.NoError(t, err, "Failed to create ticket storage")
+	mineStorage := newTestStorage[*Mine](t, mineCollection)
+	configStorage := newTestStorage[*MineConfig](t, configCollection)
+	transportStorage := newTestStorage[*Transport](t, transportCollection)
+	ticketStorage := newTestStorage[*TransportTicket](t, ticketCollection)
+	inventoryStorage := newTestStorage[*PlayerInventory](t, inventoryCollection)
+	reportStorage := newTestStorage[*BattleReport](t, reportCollection)
 
 	// Create services
-	mineService := NewMineService(mineStorage, configStorage, nil, nil)
 	ticketService := NewTicketService(ticketStorage)
-	transportService := NewTransportService(transportStorage, mineService, ticketService)
+	mineService := NewMineService(mineStorage, configStorage, nil, ticketService)
+	transportService := NewTransportService(transportStorage, inventoryStorage, reportStorage, mineService, ticketService)
 
 	// Return services and cleanup function
 	return mineService, ticketService, transportService, func() {
-		mineStorage.Close()
-		configStorage.Close()
-		transportStorage.Close()
-		ticketStorage.Close()
+		inventoryCollection.Drop(context.Background())
+		reportCollection.Drop(context.Background())
 		cleanup()
 	}
 }
 
+// setupTestTradeService sets up a TradeService on top of the ticket and transport services, checking
+// alliance membership with its own AllianceService
+func setupTestTradeService(t *testing.T, ticketService *TicketService, transportService *TransportService) *TradeService {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	tradeCollection := client.Database("test_db").Collection("test_trades_" + primitive.NewObjectID().Hex())
+	ledgerCollection := client.Database("test_db").Collection("test_trade_ledger_" + primitive.NewObjectID().Hex())
+	allianceCollection := client.Database("test_db").Collection("test_alliance_members_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		tradeCollection.Drop(context.Background())
+		ledgerCollection.Drop(context.Background())
+		allianceCollection.Drop(context.Background())
+	})
+
+	tradeStorage := newTestStorage[*Trade](t, tradeCollection)
+	ledgerStorage := newTestStorage[*TradeLedgerEntry](t, ledgerCollection)
+	allianceStorage := newTestStorage[*AllianceMember](t, allianceCollection)
+
+	tradeService := NewTradeService(tradeStorage, transportService.inventoryStorage, ledgerStorage, ticketService)
+	tradeService.SetAlliances(NewAllianceService(allianceStorage))
+	return tradeService
+}
+
 // TestMineService tests the MineService
 func TestMineService(t *testing.T) {
 	// Set up services
@@ -144,6 +176,90 @@ func TestMineService(t *testing.T) {
 	assert.Equal(t, config.MinTransportAmount, retrievedConfig.MinTransportAmount)
 }
 
+// TestMineContest tests attacking, defending and capturing a mine
+func TestMineContest(t *testing.T) {
+	// Set up services
+	mineService, ticketService, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	// Create test data
+	ctx := context.Background()
+	allianceID := primitive.NewObjectID()
+	enemyAllianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+
+	_, err := mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+
+	mine, err := mineService.CreateMine(ctx, allianceID, "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+
+	// Undeveloped mines cannot be attacked
+	attacker := CombatOrder{
+		PlayerID:   primitive.NewObjectID(),
+		PlayerName: "Attacker",
+		AllianceID: enemyAllianceID,
+		Troops:     100,
+	}
+	_, err = mineService.AttackMine(ctx, mine.ID, attacker)
+	assert.Error(t, err, "Attacking an undeveloped mine should fail")
+
+	mine, err = mineService.ForceCompleteDevelopment(ctx, mine.ID)
+	require.NoError(t, err, "Failed to complete development")
+
+	// Alliances cannot attack their own mines
+	_, err = mineService.AttackMine(ctx, mine.ID, CombatOrder{PlayerID: playerID, AllianceID: allianceID, Troops: 100})
+	assert.Error(t, err, "Attacking an own mine should fail")
+
+	// Test attacking a mine
+	mine, err = mineService.AttackMine(ctx, mine.ID, attacker)
+	require.NoError(t, err, "Failed to attack mine")
+	assert.Equal(t, MineStatusContested, mine.Status)
+	assert.Equal(t, MineStatusDeveloped, mine.Contest.PreviousStatus)
+
+	// Contested mines cannot send out transports
+	_, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+	_, err = transportService.StartTransport(ctx, playerID, "Player", mine.ID, 200)
+	assert.Error(t, err, "Starting a transport from a contested mine should fail")
+
+	// The battle waits for a defender until the defense window ends
+	_, err = mineService.ResolveMineBattle(ctx, mine.ID)
+	assert.Error(t, err, "Resolving before the defense window ends should fail")
+
+	// Test defending a mine with a much larger force
+	mine, err = mineService.DefendMine(ctx, mine.ID, CombatOrder{
+		PlayerID:   playerID,
+		PlayerName: "Player",
+		AllianceID: allianceID,
+		Troops:     1000,
+	})
+	require.NoError(t, err, "Failed to defend mine")
+	assert.Equal(t, MineContestRepelled, mine.Contest.Outcome)
+	assert.Equal(t, MineStatusDeveloped, mine.Status)
+	assert.Equal(t, allianceID, mine.AllianceID)
+
+	// An undefended mine is captured once the defense window ends
+	mine, err = mineService.AttackMine(ctx, mine.ID, attacker)
+	require.NoError(t, err, "Failed to attack mine")
+
+	_, err = mineService.UpdateMineWithFunction(ctx, mine.ID, func(m *Mine) (*Mine, error) {
+		m.Contest.DefenseEndTime = time.Now().Add(-time.Second)
+		return m, nil
+	})
+	require.NoError(t, err, "Failed to update defense end time")
+
+	mine, err = mineService.ResolveMineBattle(ctx, mine.ID)
+	require.NoError(t, err, "Failed to resolve mine battle")
+	assert.Equal(t, MineContestCaptured, mine.Contest.Outcome)
+	assert.Equal(t, MineStatusDeveloped, mine.Status)
+	assert.Equal(t, enemyAllianceID, mine.AllianceID)
+
+	// A resolved battle cannot be resolved again
+	_, err = mineService.ResolveMineBattle(ctx, mine.ID)
+	assert.Error(t, err, "Resolving a resolved battle should fail")
+}
+
 // TestTicketService tests the TicketService
 func TestTicketService(t *testing.T) {
 	// Set up services
@@ -182,6 +298,99 @@ func TestTicketService(t *testing.T) {
 	assert.Equal(t, 400, price) // Second purchase price
 }
 
+// TestTicketRegeneration tests lazy ticket regeneration and the refill sweep
+func TestTicketRegeneration(t *testing.T) {
+	// Set up services
+	_, ticketService, _, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	playerID := primitive.NewObjectID()
+	allianceID := primitive.NewObjectID()
+	ticketService.SetRegenInterval(4 * time.Hour)
+
+	ticket, err := ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+
+	_, err = ticketService.UseTicket(ctx, playerID)
+	require.NoError(t, err, "Failed to use ticket")
+	ticket, err = ticketService.UseTicket(ctx, playerID)
+	require.NoError(t, err, "Failed to use ticket")
+	assert.Equal(t, 3, ticket.CurrentTickets)
+
+	// Pretend the first ticket was used four and a half hours ago
+	regenStart := time.Now().Add(-4*time.Hour - 30*time.Minute)
+	_, _, err = ticketService.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
+		t.LastRegenTime = regenStart
+		return t, nil
+	})
+	require.NoError(t, err, "Failed to update regeneration time")
+
+	// One ticket regenerates when the tickets are read
+	ticket, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to get tickets")
+	assert.Equal(t, 4, ticket.CurrentTickets)
+	assert.WithinDuration(t, regenStart.Add(4*time.Hour), ticket.LastRegenTime, time.Second)
+
+	// The sweeper applies the daily reset to players who missed it
+	_, _, err = ticketService.storage.FindOneAndUpdate(ctx, ticket.ID, func(t *TransportTicket) (*TransportTicket, error) {
+		t.LastRefillTime = t.LastRefillTime.AddDate(0, 0, -1)
+		return t, nil
+	})
+	require.NoError(t, err, "Failed to update refill time")
+
+	refilled, err := ticketService.RefillTickets(ctx)
+	require.NoError(t, err, "Failed to refill tickets")
+	assert.Equal(t, 1, refilled)
+
+	ticket, err = ticketService.storage.FindOne(ctx, ticket.ID)
+	require.NoError(t, err, "Failed to get tickets")
+	assert.Equal(t, 5, ticket.CurrentTickets)
+
+	// Full tickets are left alone
+	refilled, err = ticketService.RefillTickets(ctx)
+	require.NoError(t, err, "Failed to refill tickets")
+	assert.Equal(t, 0, refilled)
+}
+
+// TestRefillTickets tests the daily reset and regeneration rules
+func TestRefillTickets(t *testing.T) {
+	now := time.Date(2025, time.March, 12, 15, 0, 0, 0, time.UTC)
+	ticket := &TransportTicket{
+		CurrentTickets: 1,
+		MaxTickets:     5,
+		LastRefillTime: now.Add(-10 * time.Hour),
+		LastRegenTime:  now.Add(-9 * time.Hour),
+		PurchaseCount:  2,
+	}
+
+	// Two tickets regenerate; progress towards the third is kept
+	assert.True(t, refillTickets(ticket, now, 4*time.Hour))
+	assert.Equal(t, 3, ticket.CurrentTickets)
+	assert.Equal(t, now.Add(-time.Hour), ticket.LastRegenTime)
+	assert.Equal(t, 2, ticket.PurchaseCount)
+
+	// Nothing is due until the next interval has passed
+	assert.False(t, refillTickets(ticket, now.Add(2*time.Hour), 4*time.Hour))
+	assert.True(t, refillTickets(ticket, now.Add(3*time.Hour), 4*time.Hour))
+	assert.Equal(t, 4, ticket.CurrentTickets)
+
+	// Regeneration stops at the maximum
+	assert.True(t, refillTickets(ticket, now.Add(8*time.Hour), 4*time.Hour))
+	assert.Equal(t, 5, ticket.CurrentTickets)
+	assert.False(t, refillTickets(ticket, now.Add(8*time.Hour+30*time.Minute), 4*time.Hour))
+
+	// Regeneration can be disabled
+	ticket.CurrentTickets = 1
+	assert.False(t, refillTickets(ticket, now.Add(8*time.Hour+30*time.Minute), 0))
+
+	// A new day resets tickets and purchases
+	assert.True(t, refillTickets(ticket, now.Add(10*time.Hour), 4*time.Hour))
+	assert.Equal(t, 5, ticket.CurrentTickets)
+	assert.Equal(t, 0, ticket.PurchaseCount)
+	assert.Equal(t, time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC), ticket.ResetTime)
+}
+
 // TestTransportService tests the TransportService
 func TestTransportService(t *testing.T) {
 	// Set up services
@@ -250,19 +459,2094 @@ func TestTransportService(t *testing.T) {
 	})
 	require.NoError(t, err, "Failed to update transport status")
 
-	raiderID := primitive.NewObjectID()
-	raiderName := "Test Raider"
-	transport, err = transportService.RaidTransport(ctx, transport.ID, raiderID, raiderName)
+	raider := CombatOrder{
+		PlayerID:   primitive.NewObjectID(),
+		PlayerName: "Test Raider",
+		AllianceID: primitive.NewObjectID(),
+		Troops:     100,
+	}
+
+	// Alliance members cannot raid their own transports
+	_, err = transportService.RaidTransport(ctx, transport.ID, CombatOrder{
+		PlayerID:   player2ID,
+		PlayerName: player2Name,
+		AllianceID: allianceID,
+		Troops:     100,
+	})
+	assert.Error(t, err, "Raiding an own alliance transport should fail")
+
+	transport, err = transportService.RaidTransport(ctx, transport.ID, raider)
 	require.NoError(t, err, "Failed to raid transport")
 	assert.NotNil(t, transport.RaidStatus)
-	assert.Equal(t, raiderID, transport.RaidStatus.RaiderID)
+	assert.Equal(t, raider.PlayerID, transport.RaidStatus.RaiderID)
+	assert.Equal(t, 100, transport.RaidStatus.Attacker.Troops)
 
-	// Test defending a transport
-	transport, err = transportService.DefendTransport(ctx, transport.ID, playerID, playerName, true)
+	// Test defending a transport with a much larger force
+	transport, err = transportService.DefendTransport(ctx, transport.ID, CombatOrder{
+		PlayerID:   playerID,
+		PlayerName: playerName,
+		AllianceID: allianceID,
+		Troops:     1000,
+	})
 	require.NoError(t, err, "Failed to defend transport")
 	assert.True(t, transport.RaidStatus.IsDefended)
-	assert.NotNil(t, transport.RaidStatus.DefenseResult)
+	require.NotNil(t, transport.RaidStatus.DefenseResult)
 	assert.True(t, transport.RaidStatus.DefenseResult.Successful)
+	assert.Equal(t, 0, transport.RaidStatus.DefenseResult.GoldOreLost)
+	assert.Equal(t, 350, transport.GoldOreAmount)
+
+	// Test the battle report
+	report, err := transportService.GetBattleReport(ctx, transport.RaidStatus.DefenseResult.BattleReportID)
+	require.NoError(t, err, "Failed to get battle report")
+	assert.Equal(t, transport.ID, report.TransportID)
+	assert.Equal(t, BattleOutcomeDefenderWon, report.Outcome)
+	assert.True(t, report.Defended)
+	assert.NotEmpty(t, report.Rounds)
+
+	// Replaying the battle from the report gives the same result
+	replay := ResolveCombat(report.Attacker, report.Defender, report.Seed)
+	assert.Equal(t, report.Rounds, replay.Rounds)
+
+	// A raid can only be defended once
+	_, err = transportService.DefendTransport(ctx, transport.ID, CombatOrder{
+		PlayerID:   player2ID,
+		PlayerName: player2Name,
+		AllianceID: allianceID,
+		Troops:     1000,
+	})
+	assert.Error(t, err, "Defending a resolved raid should fail")
+}
+
+// TestTransportScheduler tests scheduled departures and arrival resolution
+func TestTransportScheduler(t *testing.T) {
+	// Set up services
+	mineService, ticketService, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	// Create test data
+	ctx := context.Background()
+	allianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+	player2ID := primitive.NewObjectID()
+
+	_, err := mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+
+	mine, err := mineService.CreateMine(ctx, allianceID, "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+
+	_, err = mineService.AddGoldOre(ctx, mine.ID, 1000)
+	require.NoError(t, err, "Failed to add gold ore")
+
+	_, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets for player 1")
+
+	_, err = ticketService.GetOrCreateTickets(ctx, player2ID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets for player 2")
+
+	eventCtx, cancelEvents := context.WithCancel(ctx)
+	defer cancelEvents()
+	events := transportService.SubscribeEvents(eventCtx)
+
+	// Start a transport that departs immediately
+	transportService.SetPrepTime(0)
+	transport, err := transportService.StartTransport(ctx, playerID, "Test Player", mine.ID, 200)
+	require.NoError(t, err, "Failed to start transport")
+
+	transport, err = transportService.JoinTransport(ctx, transport.ID, player2ID, "Test Player 2", 100)
+	require.NoError(t, err, "Failed to join transport")
+
+	// Tickets are held until the transport arrives
+	ticket, err := ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to get tickets")
+	assert.Equal(t, 4, ticket.CurrentTickets)
+	assert.Equal(t, 1, ticket.HeldTickets)
+
+	// Test departure
+	departed, err := transportService.DepartTransports(ctx)
+	require.NoError(t, err, "Failed to depart transports")
+	assert.Equal(t, 1, departed)
+
+	event := <-events
+	assert.Equal(t, TransportEventDeparted, event.Type)
+	assert.Equal(t, TransportStatusInProgress, event.Transport.Status)
+	require.NotNil(t, event.Transport.EndTime)
+
+	// Not arrived until the transport time has elapsed
+	arrived, err := transportService.ResolveArrivals(ctx)
+	require.NoError(t, err, "Failed to resolve arrivals")
+	assert.Equal(t, 0, arrived)
+
+	// Move the end time into the past
+	_, _, err = transportService.storage.FindOneAndUpdate(ctx, transport.ID, func(t *Transport) (*Transport, error) {
+		endTime := time.Now().Add(-time.Second)
+		t.EndTime = &endTime
+		return t, nil
+	})
+	require.NoError(t, err, "Failed to update transport end time")
+
+	// Test arrival
+	arrived, err = transportService.ResolveArrivals(ctx)
+	require.NoError(t, err, "Failed to resolve arrivals")
+	assert.Equal(t, 1, arrived)
+
+	event = <-events
+	assert.Equal(t, TransportEventArrived, event.Type)
+	assert.Equal(t, TransportStatusCompleted, event.Transport.Status)
+	require.Len(t, event.Transport.Rewards, 2)
+	assert.Equal(t, 200, event.Transport.Rewards[0].GoldOre)
+	assert.Equal(t, 100, event.Transport.Rewards[1].GoldOre)
+
+	// Gold ore is credited and held tickets are consumed
+	inventories, err := transportService.inventoryStorage.FindMany(ctx, bson.M{"player_id": playerID})
+	require.NoError(t, err, "Failed to get inventory")
+	require.Len(t, inventories, 1)
+	assert.Equal(t, 200, inventories[0].GoldOre)
+
+	ticket, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to get tickets")
+	assert.Equal(t, 4, ticket.CurrentTickets)
+	assert.Equal(t, 0, ticket.HeldTickets)
+
+	// Arrivals are resolved only once
+	arrived, err = transportService.ResolveArrivals(ctx)
+	require.NoError(t, err, "Failed to resolve arrivals")
+	assert.Equal(t, 0, arrived)
+}
+
+// TestDistributeGoldOre tests splitting delivered gold ore between participants
+func TestDistributeGoldOre(t *testing.T) {
+	transport := &Transport{
+		GoldOreAmount: 245, // 350 loaded, 30% lost to a raid
+		Participants: []TransportMember{
+			{PlayerName: "A", GoldOreAmount: 200},
+			{PlayerName: "B", GoldOreAmount: 100},
+			{PlayerName: "C", GoldOreAmount: 50},
+		},
+	}
+
+	rewards := distributeGoldOre(transport)
+	require.Len(t, rewards, 3)
+	assert.Equal(t, 140, rewards[0].GoldOre)
+	assert.Equal(t, 70, rewards[1].GoldOre)
+	assert.Equal(t, 35, rewards[2].GoldOre)
+
+	// Rounding leftovers go to the earliest participants
+	transport.GoldOreAmount = 100
+	rewards = distributeGoldOre(transport)
+	assert.Equal(t, 58, rewards[0].GoldOre)
+	assert.Equal(t, 28, rewards[1].GoldOre)
+	assert.Equal(t, 14, rewards[2].GoldOre)
+}
+
+// TestResolveCombat tests the combat engine and raid losses
+func TestResolveCombat(t *testing.T) {
+	attacker := CombatForce{
+		PlayerName: "Raider",
+		Generals: []CombatGeneral{
+			{Name: "Lu Bu", Level: 50, Stars: 10, Rarity: GeneralRarityLegendary},
+		},
+		Troops: 1000,
+	}
+	defender := CombatForce{
+		PlayerName: "Defender",
+		Generals: []CombatGeneral{
+			{Name: "Recruit", Level: 1, Stars: 0, Rarity: GeneralRarityCommon},
+		},
+		Troops: 1000,
+	}
+
+	// The same forces and seed always give the same battle
+	result := ResolveCombat(attacker, defender, 42)
+	assert.Equal(t, result, ResolveCombat(attacker, defender, 42))
+	assert.NotEmpty(t, result.Rounds)
+	assert.LessOrEqual(t, len(result.Rounds), maxCombatRounds)
+
+	// Stronger generals win an even fight
+	assert.Equal(t, BattleOutcomeAttackerWon, result.Outcome)
+	assert.Equal(t, BattleOutcomeDefenderWon, ResolveCombat(defender, attacker, 42).Outcome)
+
+	// A much larger force wins regardless of generals
+	defender.Troops = 10000
+	result = ResolveCombat(attacker, defender, 42)
+	assert.Equal(t, BattleOutcomeDefenderWon, result.Outcome)
+	assert.Equal(t, 0, result.AttackerTroopsLeft)
+
+	lost, stolen := raidLoss(result, attacker.Troops, 500, true)
+	assert.Equal(t, 0, lost)
+	assert.Equal(t, 0, stolen)
+
+	// An undefended transport falls without a fight
+	result = ResolveCombat(attacker, CombatForce{}, 42)
+	assert.Equal(t, BattleOutcomeAttackerWon, result.Outcome)
+	assert.Empty(t, result.Rounds)
+	assert.Equal(t, attacker.Troops, result.AttackerTroopsLeft)
+
+	lost, stolen = raidLoss(result, attacker.Troops, 500, false)
+	assert.Equal(t, 250, lost)
+	assert.Equal(t, 250, stolen)
+
+	// A defeated defender loses less, and the raider carries away what its survivors can
+	result = CombatResult{Outcome: BattleOutcomeAttackerWon, AttackerTroopsLeft: 400}
+	lost, stolen = raidLoss(result, attacker.Troops, 500, true)
+	assert.Equal(t, 150, lost)
+	assert.Equal(t, 60, stolen)
+}
+
+// TestApplyMineBattle tests how battle results change a contested mine
+func TestApplyMineBattle(t *testing.T) {
+	allianceID := primitive.NewObjectID()
+	enemyAllianceID := primitive.NewObjectID()
+	now := time.Now()
+
+	newMine := func() *Mine {
+		return &Mine{
+			AllianceID:        allianceID,
+			Status:            MineStatusContested,
+			DevelopmentPoints: 1000,
+			RequiredPoints:    1000,
+			Contest: &MineContest{
+				AttackerAllianceID: enemyAllianceID,
+				PreviousStatus:     MineStatusActive,
+			},
+		}
+	}
+
+	// Repelled attacks restore the previous state
+	mine := newMine()
+	applyMineBattle(mine, CombatResult{Outcome: BattleOutcomeDefenderWon, AttackerTroopsLeft: 0, DefenderTroopsLeft: 10}, now)
+	assert.Equal(t, MineContestRepelled, mine.Contest.Outcome)
+	assert.Equal(t, MineStatusActive, mine.Status)
+	assert.Equal(t, allianceID, mine.AllianceID)
+	assert.Equal(t, 1000.0, mine.DevelopmentPoints)
+
+	// Wiping out the defenders captures the mine
+	mine = newMine()
+	applyMineBattle(mine, CombatResult{Outcome: BattleOutcomeAttackerWon, AttackerTroopsLeft: 10, DefenderTroopsLeft: 0}, now)
+	assert.Equal(t, MineContestCaptured, mine.Contest.Outcome)
+	assert.Equal(t, MineStatusActive, mine.Status)
+	assert.Equal(t, enemyAllianceID, mine.AllianceID)
+
+	// Winning while the defenders still stand pillages the mine
+	mine = newMine()
+	applyMineBattle(mine, CombatResult{Outcome: BattleOutcomeAttackerWon, AttackerTroopsLeft: 10, DefenderTroopsLeft: 5}, now)
+	assert.Equal(t, MineContestPillaged, mine.Contest.Outcome)
+	assert.Equal(t, MineStatusUndeveloped, mine.Status)
+	assert.Equal(t, allianceID, mine.AllianceID)
+	assert.Equal(t, 500.0, mine.DevelopmentPoints)
+	assert.Equal(t, 500.0, mine.Contest.DevelopmentLost)
+	require.NotNil(t, mine.Contest.ResolvedAt)
+}
+
+// TestGeneralStamina tests stamina costs, injuries and recovery of generals
+func TestGeneralStamina(t *testing.T) {
+	// Set up services
+	mineService, _, _, cleanup := setupTestServices(t)
+	defer cleanup()
+	generalService := mineService.GetGeneralService()
+
+	// Create test data
+	ctx := context.Background()
+	playerID := primitive.NewObjectID()
+	targetID := primitive.NewObjectID()
+
+	general, err := generalService.CreateGeneral(ctx, playerID, "Zhao Yun", 10, 2, GeneralRarityRare)
+	require.NoError(t, err, "Failed to create general")
+	assert.Equal(t, maxGeneralStamina, general.Stamina)
+
+	// Every assignment costs stamina until the general is exhausted
+	for i := 0; i < 5; i++ {
+		general, err = generalService.AssignGeneral(ctx, general.ID, "mine_development", targetID, "Test Mine")
+		require.NoError(t, err, "Failed to assign general")
+		general, err = generalService.UnassignGeneral(ctx, general.ID)
+		require.NoError(t, err, "Failed to unassign general")
+	}
+	assert.InDelta(t, 0.0, general.Stamina, 0.01)
+
+	_, err = generalService.AssignGeneral(ctx, general.ID, "mine_development", targetID, "Test Mine")
+	assert.Error(t, err, "Assigning an exhausted general should fail")
+
+	order := CombatOrder{PlayerID: playerID, GeneralIDs: []primitive.ObjectID{general.ID}, Troops: 100}
+	_, err = generalService.BuildCombatForce(ctx, order)
+	assert.Error(t, err, "Exhausted generals should not lead troops")
+
+	// Idle generals recover stamina over time
+	_, err = generalService.UpdateGeneralWithFunction(ctx, general.ID, func(g *General) (*General, error) {
+		g.RecoveredAt = g.RecoveredAt.Add(-3 * time.Hour)
+		return g, nil
+	})
+	require.NoError(t, err, "Failed to update general")
+
+	general, err = generalService.UpdateGeneralStamina(ctx, general.ID)
+	require.NoError(t, err, "Failed to update stamina")
+	assert.InDelta(t, 3*staminaRecoveryPerHour, general.Stamina, 0.01)
+
+	force, err := generalService.BuildCombatForce(ctx, order)
+	require.NoError(t, err, "Rested generals should lead troops")
+
+	// Battles cost stamina and losing injures the generals
+	require.NoError(t, generalService.SpendBattleStamina(ctx, force))
+	require.NoError(t, generalService.InjureGenerals(ctx, force))
+
+	general, err = generalService.GetGeneralByID(ctx, general.ID)
+	require.NoError(t, err, "Failed to get general")
+	assert.InDelta(t, 3*staminaRecoveryPerHour-battleStaminaCost, general.Stamina, 0.01)
+	require.NotNil(t, general.HealsAt)
+
+	_, err = generalService.BuildCombatForce(ctx, order)
+	assert.Error(t, err, "Injured generals should not lead troops")
+}
+
+// TestRecoverStamina tests the lazy stamina recovery calculation
+func TestRecoverStamina(t *testing.T) {
+	now := time.Now()
+
+	// Generals stored before stamina existed are fully rested
+	g := &General{Name: "Old", Status: GeneralStatusIdle}
+	recoverStamina(g, now)
+	assert.Equal(t, maxGeneralStamina, g.Stamina)
+	assert.Equal(t, now, g.RecoveredAt)
+
+	// Idle generals recover up to the maximum
+	g = &General{Status: GeneralStatusIdle, Stamina: 20, RecoveredAt: now.Add(-2 * time.Hour)}
+	recoverStamina(g, now)
+	assert.InDelta(t, 20+2*staminaRecoveryPerHour, g.Stamina, 0.01)
+
+	g = &General{Status: GeneralStatusIdle, Stamina: 20, RecoveredAt: now.Add(-24 * time.Hour)}
+	recoverStamina(g, now)
+	assert.Equal(t, maxGeneralStamina, g.Stamina)
+
+	// Assigned generals do not recover
+	g = &General{Status: GeneralStatusAssigned, Stamina: 20, RecoveredAt: now.Add(-2 * time.Hour)}
+	recoverStamina(g, now)
+	assert.Equal(t, 20.0, g.Stamina)
+
+	// Injured generals do not recover until they heal
+	healsAt := now.Add(time.Hour)
+	g = &General{Name: "Injured", Status: GeneralStatusIdle, Stamina: 50, RecoveredAt: now.Add(-2 * time.Hour), HealsAt: &healsAt}
+	recoverStamina(g, now)
+	assert.Equal(t, 50.0, g.Stamina)
+	assert.Error(t, checkGeneralReady(g, battleStaminaCost, now))
+
+	recoverStamina(g, now.Add(3*time.Hour))
+	assert.InDelta(t, 50+2*staminaRecoveryPerHour, g.Stamina, 0.01)
+	assert.Nil(t, g.HealsAt)
+	assert.NoError(t, checkGeneralReady(g, battleStaminaCost, now.Add(3*time.Hour)))
+
+	// Spending never goes below zero and exhausted generals are not ready
+	spendStamina(g, 1000, now.Add(3*time.Hour))
+	assert.Equal(t, 0.0, g.Stamina)
+	assert.Error(t, checkGeneralReady(g, assignmentStaminaCost, now.Add(3*time.Hour)))
+}
+
+// TestAllianceStatsService tests leaderboard aggregation from service events
+func TestAllianceStatsService(t *testing.T) {
+	// Set up services
+	mineService, _, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	leaderboardCollection := client.Database("test_db").Collection("test_leaderboards_" + primitive.NewObjectID().Hex())
+	defer leaderboardCollection.Drop(ctx)
+
+	leaderboardStorage := newTestStorage[*AllianceLeaderboard](t, leaderboardCollection)
+
+	statsService := NewAllianceStatsService(leaderboardStorage, transportService, mineService)
+
+	allianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+	player2ID := primitive.NewObjectID()
+	now := time.Now()
+
+	// Test arrivals and successful defenses
+	err = statsService.applyTransportEvent(ctx, TransportEvent{
+		Type: TransportEventArrived,
+		Transport: &Transport{
+			AllianceID: allianceID,
+			Rewards: []TransportReward{
+				{PlayerID: playerID, PlayerName: "Player 1", GoldOre: 200},
+				{PlayerID: player2ID, PlayerName: "Player 2", GoldOre: 300},
+			},
+		},
+		OccurredAt: now,
+	})
+	require.NoError(t, err, "Failed to apply arrival")
+
+	err = statsService.applyTransportEvent(ctx, TransportEvent{
+		Type: TransportEventRaidResolved,
+		Transport: &Transport{
+			AllianceID: allianceID,
+			RaidStatus: &RaidStatus{
+				IsDefended:    true,
+				DefenseResult: &DefenseResult{Successful: true, DefenderID: playerID, DefenderName: "Player 1"},
+			},
+		},
+		OccurredAt: now,
+	})
+	require.NoError(t, err, "Failed to apply raid result")
+
+	err = statsService.RecordContribution(ctx, allianceID, playerID, "Player 1", ContributionMetricTransportedGold, 150, now)
+	require.NoError(t, err, "Failed to record contribution")
+
+	entries, err := statsService.GetLeaderboard(ctx, allianceID, LeaderboardPeriodDaily, ContributionMetricTransportedGold)
+	require.NoError(t, err, "Failed to get leaderboard")
+	require.Len(t, entries, 2)
+	assert.Equal(t, playerID, entries[0].PlayerID)
+	assert.Equal(t, 350.0, entries[0].Score)
+	assert.Equal(t, 1, entries[0].Rank)
+	assert.Equal(t, 300.0, entries[1].Score)
+
+	entries, err = statsService.GetLeaderboard(ctx, allianceID, LeaderboardPeriodWeekly, ContributionMetricDefensesWon)
+	require.NoError(t, err, "Failed to get leaderboard")
+	require.Len(t, entries, 1)
+	assert.Equal(t, 1.0, entries[0].Score)
+
+	// Earlier periods are kept separately
+	entries, err = statsService.GetLeaderboardAt(ctx, allianceID, LeaderboardPeriodWeekly, ContributionMetricTransportedGold, now.AddDate(0, 0, -7))
+	require.NoError(t, err, "Failed to get leaderboard")
+	assert.Empty(t, entries)
+
+	// Unknown metrics are rejected
+	_, err = statsService.GetLeaderboard(ctx, allianceID, LeaderboardPeriodDaily, ContributionMetric("gems"))
+	assert.Error(t, err)
+}
+
+// TestLeaderboardHelpers tests period bounds, ranking and development contributions
+func TestLeaderboardHelpers(t *testing.T) {
+	// Wednesday afternoon
+	at := time.Date(2025, time.March, 12, 15, 30, 0, 0, time.UTC)
+
+	start, end, err := periodBounds(LeaderboardPeriodDaily, at)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.March, 12, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, time.March, 13, 0, 0, 0, 0, time.UTC), end)
+
+	start, end, err = periodBounds(LeaderboardPeriodWeekly, at)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, time.March, 17, 0, 0, 0, 0, time.UTC), end)
+
+	// Sundays belong to the week that started the Monday before
+	start, _, err = periodBounds(LeaderboardPeriodWeekly, time.Date(2025, time.March, 16, 23, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC), start)
+
+	_, _, err = periodBounds(LeaderboardPeriod("monthly"), at)
+	assert.Error(t, err)
+
+	// Ranking shares ranks between equal scores and leaves out players without a score
+	entries := rankContributions([]PlayerContribution{
+		{PlayerName: "A", DefensesWon: 1},
+		{PlayerName: "B", DefensesWon: 3},
+		{PlayerName: "C", DefensesWon: 1},
+		{PlayerName: "D", TransportedGold: 100},
+	}, ContributionMetricDefensesWon)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "B", entries[0].PlayerName)
+	assert.Equal(t, 1, entries[0].Rank)
+	assert.Equal(t, "A", entries[1].PlayerName)
+	assert.Equal(t, 2, entries[1].Rank)
+	assert.Equal(t, "C", entries[2].PlayerName)
+	assert.Equal(t, 2, entries[2].Rank)
+
+	// Development points are split per player and scaled down when development completes
+	playerID := primitive.NewObjectID()
+	player2ID := primitive.NewObjectID()
+	contributions := developmentContributions([]AssignedGeneral{
+		{PlayerID: playerID, PlayerName: "A", ContributionRate: 100},
+		{PlayerID: player2ID, PlayerName: "B", ContributionRate: 50},
+		{PlayerID: playerID, PlayerName: "A", ContributionRate: 50},
+	}, 2, 200)
+	require.Len(t, contributions, 2)
+	assert.InDelta(t, 150.0, contributions[0].Points, 0.001)
+	assert.InDelta(t, 50.0, contributions[1].Points, 0.001)
+}
+
+// TestOutboxService tests recording domain events with state changes and dispatching them
+func TestOutboxService(t *testing.T) {
+	// Set up services
+	mineService, ticketService, _, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	outboxCollection := client.Database("test_db").Collection("test_outbox_events_" + primitive.NewObjectID().Hex())
+	defer outboxCollection.Drop(ctx)
+
+	outboxStorage := newTestStorage[*OutboxEvent](t, outboxCollection)
+
+	outboxService := NewOutboxService(outboxStorage)
+	mineService.SetOutbox(outboxService)
+	ticketService.SetOutbox(outboxService)
+
+	allianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+
+	// Test that state changes record their events in order
+	mine, err := mineService.CreateMine(ctx, allianceID, "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+	_, err = mineService.AddGoldOre(ctx, mine.ID, 500)
+	require.NoError(t, err, "Failed to add gold ore")
+	_, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+	_, err = ticketService.UseTicket(ctx, playerID)
+	require.NoError(t, err, "Failed to use ticket")
+
+	// Failed changes record nothing
+	_, err = mineService.RemoveGoldOre(ctx, mine.ID, 10000)
+	assert.Error(t, err, "Removing more gold ore than the mine has should fail")
+
+	events, err := outboxService.GetPendingEvents(ctx, 10)
+	require.NoError(t, err, "Failed to get pending events")
+	require.Len(t, events, 4)
+	assert.Equal(t, DomainEventMineCreated, events[0].Type)
+	assert.Equal(t, DomainEventGoldOreAdded, events[1].Type)
+	assert.Equal(t, DomainEventTicketsCreated, events[2].Type)
+	assert.Equal(t, DomainEventTicketUsed, events[3].Type)
+	assert.Equal(t, mine.ID, events[1].AggregateID)
+	assert.Equal(t, playerID, events[3].PlayerID)
+
+	// Test dispatching the events to the alliance's topics
+	pubsub, err := crdtpubsub.NewMemoryPubSub(nil)
+	require.NoError(t, err, "Failed to create pub/sub")
+	defer pubsub.Close()
+
+	received := make(chan string, 10)
+	err = pubsub.SubscribePattern(ctx, DomainEventTopic(allianceID, "*"), "test",
+		func(ctx context.Context, topic string, data []byte, format crdtpubsub.EncodingFormat) error {
+			received <- topic
+			return nil
+		})
+	require.NoError(t, err, "Failed to subscribe")
+
+	dispatched, err := outboxService.Dispatch(ctx, pubsub)
+	require.NoError(t, err, "Failed to dispatch events")
+	assert.Equal(t, 4, dispatched)
+
+	for i := 0; i < 4; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatalf("Received only %d of 4 events", i)
+		}
+	}
+
+	// Dispatched events are not published again
+	events, err = outboxService.GetPendingEvents(ctx, 10)
+	require.NoError(t, err, "Failed to get pending events")
+	assert.Empty(t, events)
+}
+
+// TestPublishDomainEvent tests the topic and message clients receive for a domain event
+func TestPublishDomainEvent(t *testing.T) {
+	ctx := context.Background()
+	pubsub, err := crdtpubsub.NewMemoryPubSub(nil)
+	require.NoError(t, err, "Failed to create pub/sub")
+	defer pubsub.Close()
+
+	allianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+	transport := &Transport{
+		ID:            primitive.NewObjectID(),
+		AllianceID:    allianceID,
+		Status:        TransportStatusPreparing,
+		GoldOreAmount: 300,
+	}
+	event := transportEvent(DomainEventTransportJoined, transport, playerID, nil)
+	event.ID = primitive.NewObjectID()
+	event.OccurredAt = time.Now()
+
+	topic := DomainEventTopic(allianceID, AggregateTransport)
+	assert.Equal(t, "transport-"+allianceID.Hex()+"-transport", topic)
+
+	received := make(chan []byte, 1)
+	err = pubsub.Subscribe(ctx, topic, "test", func(ctx context.Context, topic string, data []byte, format crdtpubsub.EncodingFormat) error {
+		received <- data
+		return nil
+	})
+	require.NoError(t, err, "Failed to subscribe")
+
+	require.NoError(t, publishDomainEvent(ctx, pubsub, event))
+
+	var data []byte
+	select {
+	case data = <-received:
+	case <-time.After(time.Second):
+		t.Fatal("Event was not received")
+	}
+
+	var message map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &message))
+	assert.Equal(t, event.ID.Hex(), message["id"])
+	assert.Equal(t, "transport.joined", message["type"])
+	assert.Equal(t, "transport", message["aggregate"])
+	assert.Equal(t, transport.ID.Hex(), message["aggregate_id"])
+	assert.Equal(t, playerID.Hex(), message["player_id"])
+
+	payload, ok := message["data"].(map[string]interface{})
+	require.True(t, ok, "Event data should be an object")
+	assert.Equal(t, string(TransportStatusPreparing), payload["status"])
+	assert.Equal(t, 300.0, payload["gold_ore_amount"])
+}
+
+// TestIdempotencyService tests that retried player actions run only once
+func TestIdempotencyService(t *testing.T) {
+	// Set up services
+	mineService, ticketService, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	idempotencyCollection := client.Database("test_db").Collection("test_idempotency_keys_" + primitive.NewObjectID().Hex())
+	defer idempotencyCollection.Drop(ctx)
+
+	idempotencyStorage := newTestStorage[*IdempotencyKey](t, idempotencyCollection)
+
+	idempotencyService := NewIdempotencyService(idempotencyStorage)
+	ticketService.SetIdempotency(idempotencyService)
+	transportService.SetIdempotency(idempotencyService)
+
+	allianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+	player2ID := primitive.NewObjectID()
+
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+	mine, err := mineService.CreateMine(ctx, allianceID, "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+	_, err = mineService.AddGoldOre(ctx, mine.ID, 1000)
+	require.NoError(t, err, "Failed to add gold ore")
+	_, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+	_, err = ticketService.GetOrCreateTickets(ctx, player2ID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+
+	// Test that a retried start returns the first transport without holding another ticket
+	startCtx := WithIdempotencyKey(ctx, "start-1")
+	transport, err := transportService.StartTransport(startCtx, playerID, "Player 1", mine.ID, 200)
+	require.NoError(t, err, "Failed to start transport")
+	retried, err := transportService.StartTransport(startCtx, playerID, "Player 1", mine.ID, 200)
+	require.NoError(t, err, "Failed to retry start")
+	assert.Equal(t, transport.ID, retried.ID)
+
+	transports, err := transportService.GetPlayerTransports(ctx, playerID)
+	require.NoError(t, err, "Failed to get transports")
+	assert.Len(t, transports, 1)
+
+	// Test that a retried join doesn't add the player twice
+	joinCtx := WithIdempotencyKey(ctx, "join-1")
+	_, err = transportService.JoinTransport(joinCtx, transport.ID, player2ID, "Player 2", 150)
+	require.NoError(t, err, "Failed to join transport")
+	transport, err = transportService.JoinTransport(joinCtx, transport.ID, player2ID, "Player 2", 150)
+	require.NoError(t, err, "Failed to retry join")
+	assert.Len(t, transport.Participants, 2)
+
+	tickets, err := ticketService.GetOrCreateTickets(ctx, player2ID, allianceID, 5)
+	require.NoError(t, err, "Failed to get tickets")
+	assert.Equal(t, 4, tickets.CurrentTickets)
+	assert.Equal(t, 1, tickets.HeldTickets)
+
+	// Test that a retried purchase returns the first purchase
+	purchaseCtx := WithIdempotencyKey(ctx, "purchase-1")
+	ticket, price, err := ticketService.PurchaseTicket(purchaseCtx, player2ID)
+	require.NoError(t, err, "Failed to purchase ticket")
+	retriedTicket, retriedPrice, err := ticketService.PurchaseTicket(purchaseCtx, player2ID)
+	require.NoError(t, err, "Failed to retry purchase")
+	assert.Equal(t, ticket.CurrentTickets, retriedTicket.CurrentTickets)
+	assert.Equal(t, price, retriedPrice)
+	assert.Equal(t, 1, retriedTicket.PurchaseCount)
+
+	// Keys cannot be reused for a different request
+	_, err = transportService.StartTransport(startCtx, playerID, "Player 1", mine.ID, 300)
+	assert.Error(t, err, "Reusing a key for a different request should fail")
+
+	// Failed actions release their key so they can be retried
+	failCtx := WithIdempotencyKey(ctx, "start-2")
+	_, err = transportService.StartTransport(failCtx, playerID, "Player 1", mine.ID, 10000)
+	assert.Error(t, err, "Starting a transport above the maximum should fail")
+	_, err = idempotencyStorage.FindOne(ctx, idempotencyKeyID(playerID, IdempotentActionStartTransport, "start-2"))
+	assert.ErrorIs(t, err, nodestorage.ErrNotFound)
+
+	// Expired keys are purged
+	_, _, err = idempotencyStorage.FindOneAndUpdate(ctx, idempotencyKeyID(playerID, IdempotentActionStartTransport, "start-1"),
+		func(k *IdempotencyKey) (*IdempotencyKey, error) {
+			k.ExpiresAt = time.Now().Add(-time.Minute)
+			return k, nil
+		})
+	require.NoError(t, err, "Failed to expire key")
+	purged, err := idempotencyService.PurgeExpiredKeys(ctx)
+	require.NoError(t, err, "Failed to purge keys")
+	assert.Equal(t, 1, purged)
+}
+
+// TestIdempotencyClaimLease tests that stale claims and expired keys are taken over by new calls
+func TestIdempotencyClaimLease(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	idempotencyCollection := client.Database("test_db").Collection("test_idempotency_keys_" + primitive.NewObjectID().Hex())
+	defer idempotencyCollection.Drop(ctx)
+
+	idempotencyStorage := newTestStorage[*IdempotencyKey](t, idempotencyCollection)
+	idempotencyService := NewIdempotencyService(idempotencyStorage)
+
+	playerID := primitive.NewObjectID()
+	keyCtx := WithIdempotencyKey(ctx, "purchase-1")
+	keyID := idempotencyKeyID(playerID, IdempotentActionPurchaseTicket, "purchase-1")
+	runs := 0
+	perform := func(result *IdempotencyKey) error {
+		runs++
+		result.Cost = runs
+		return nil
+	}
+	age := func(update func(k *IdempotencyKey)) {
+		_, _, err := idempotencyStorage.FindOneAndUpdate(ctx, keyID, func(k *IdempotencyKey) (*IdempotencyKey, error) {
+			update(k)
+			return k, nil
+		})
+		require.NoError(t, err, "Failed to age key")
+	}
+
+	// A call that died holding the key blocks retries until the claim lease ends
+	var stuck *IdempotencyKey
+	_, err = idempotencyService.do(keyCtx, playerID, IdempotentActionPurchaseTicket, "", func(result *IdempotencyKey) error {
+		stuck = result
+		_, err := idempotencyService.do(keyCtx, playerID, IdempotentActionPurchaseTicket, "", perform)
+		assert.Error(t, err, "Retrying during the first call should fail")
+		return nil
+	})
+	require.NoError(t, err)
+	age(func(k *IdempotencyKey) {
+		k.Status = IdempotencyKeyPending
+		k.ClaimedAt = time.Now().Add(-idempotencyClaimLease / 2)
+	})
+	_, err = idempotencyService.do(keyCtx, playerID, IdempotentActionPurchaseTicket, "", perform)
+	assert.Error(t, err, "Retrying within the claim lease should fail")
+	assert.Equal(t, 0, runs)
+
+	age(func(k *IdempotencyKey) {
+		k.ClaimedAt = time.Now().Add(-2 * idempotencyClaimLease)
+	})
+	earlier, err := idempotencyService.do(keyCtx, playerID, IdempotentActionPurchaseTicket, "", perform)
+	require.NoError(t, err, "Retrying after the claim lease should take the key over")
+	assert.Nil(t, earlier)
+	assert.Equal(t, 1, runs)
+
+	key, err := idempotencyStorage.FindOne(ctx, keyID)
+	require.NoError(t, err, "Failed to get key")
+	assert.NotEqual(t, stuck.ClaimID, key.ClaimID)
+	assert.Equal(t, IdempotencyKeyCompleted, key.Status)
+	assert.Equal(t, 1, key.Cost)
+
+	// Completed keys are not taken over after the lease, only once their retention ends
+	age(func(k *IdempotencyKey) {
+		k.ClaimedAt = time.Now().Add(-2 * idempotencyClaimLease)
+	})
+	earlier, err = idempotencyService.do(keyCtx, playerID, IdempotentActionPurchaseTicket, "", perform)
+	require.NoError(t, err)
+	require.NotNil(t, earlier)
+	assert.Equal(t, 1, earlier.Cost)
+	assert.Equal(t, 1, runs)
+
+	// Expired keys are free before the sweeper purges them, even for a different request
+	age(func(k *IdempotencyKey) {
+		k.ExpiresAt = time.Now().Add(-time.Minute)
+	})
+	earlier, err = idempotencyService.do(keyCtx, playerID, IdempotentActionPurchaseTicket, "other", perform)
+	require.NoError(t, err, "Reusing an expired key should succeed")
+	assert.Nil(t, earlier)
+	assert.Equal(t, 2, runs)
+
+	key, err = idempotencyStorage.FindOne(ctx, keyID)
+	require.NoError(t, err, "Failed to get key")
+	assert.Equal(t, "other", key.Request)
+	assert.Equal(t, 2, key.Cost)
+	assert.True(t, key.ExpiresAt.After(time.Now()))
+
+	// A call whose key was taken over neither completes nor releases it
+	age(func(k *IdempotencyKey) {
+		k.ExpiresAt = time.Now().Add(-time.Minute)
+	})
+	_, err = idempotencyService.do(keyCtx, playerID, IdempotentActionPurchaseTicket, "", func(result *IdempotencyKey) error {
+		age(func(k *IdempotencyKey) {
+			k.ClaimID = primitive.NewObjectID()
+		})
+		return errors.New("purchase failed")
+	})
+	assert.Error(t, err)
+	_, err = idempotencyStorage.FindOne(ctx, keyID)
+	assert.NoError(t, err, "The key taken over should not be released")
+}
+
+// TestIdempotencyKeys tests idempotency key helpers
+func TestIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", IdempotencyKeyFromContext(ctx))
+	assert.Equal(t, "key-1", IdempotencyKeyFromContext(WithIdempotencyKey(ctx, "key-1")))
+
+	// Key IDs are stable and distinct per player, action and key
+	playerID := primitive.NewObjectID()
+	id := idempotencyKeyID(playerID, IdempotentActionStartTransport, "key-1")
+	assert.Equal(t, id, idempotencyKeyID(playerID, IdempotentActionStartTransport, "key-1"))
+	assert.NotEqual(t, id, idempotencyKeyID(primitive.NewObjectID(), IdempotentActionStartTransport, "key-1"))
+	assert.NotEqual(t, id, idempotencyKeyID(playerID, IdempotentActionJoinTransport, "key-1"))
+	assert.NotEqual(t, id, idempotencyKeyID(playerID, IdempotentActionStartTransport, "key-2"))
+
+	// Without a service or a key the action always runs
+	var service *IdempotencyService
+	runs := 0
+	perform := func(result *IdempotencyKey) error {
+		runs++
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		earlier, err := service.do(WithIdempotencyKey(ctx, "key-1"), playerID, IdempotentActionPurchaseTicket, "", perform)
+		require.NoError(t, err)
+		assert.Nil(t, earlier)
+	}
+	assert.Equal(t, 2, runs)
+}
+
+// TestSpeedupService tests speeding up mine development and transport travel
+func TestSpeedupService(t *testing.T) {
+	// Set up services
+	mineService, ticketService, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	speedupCollection := client.Database("test_db").Collection("test_speedup_spends_" + primitive.NewObjectID().Hex())
+	defer speedupCollection.Drop(ctx)
+
+	speedupStorage := newTestStorage[*SpeedupSpend](t, speedupCollection)
+
+	speedupService := NewSpeedupService(transportService.inventoryStorage, speedupStorage, mineService, transportService)
+
+	allianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+
+	// Create a mine developed by a general adding 100 points per hour
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+	mine, err := mineService.CreateMine(ctx, allianceID, "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+	mine, err = mineService.UpdateMineWithFunction(ctx, mine.ID, func(m *Mine) (*Mine, error) {
+		m.Status = MineStatusDeveloping
+		m.RequiredPoints = 1000
+		m.DevelopmentPoints = 0
+		m.LastUpdatedAt = time.Now()
+		m.AssignedGenerals = []AssignedGeneral{{PlayerID: playerID, GeneralID: primitive.NewObjectID(), ContributionRate: 100}}
+		return m, nil
+	})
+	require.NoError(t, err, "Failed to start development")
+
+	order := SpeedupOrder{PlayerID: playerID, AllianceID: allianceID, Duration: time.Hour, Payment: SpeedupPaymentItems}
+
+	// Speed-ups with items need enough items
+	_, _, err = speedupService.ApplySpeedup(ctx, mine.ID, order)
+	assert.Error(t, err, "Speeding up without items should fail")
+
+	inventory, err := speedupService.GrantSpeedupItems(ctx, playerID, allianceID, 20)
+	require.NoError(t, err, "Failed to grant speed-up items")
+	assert.Equal(t, 20, inventory.Speedups)
+
+	// Test skipping an hour of development with items
+	mine, spend, err := speedupService.ApplySpeedup(ctx, mine.ID, order)
+	require.NoError(t, err, "Failed to speed up mine")
+	assert.Equal(t, 12, spend.ItemsSpent)
+	assert.Equal(t, time.Hour, spend.Skipped)
+	assert.InDelta(t, 100.0, mine.DevelopmentPoints, 1.0)
+	assert.Equal(t, MineStatusDeveloping, mine.Status)
+
+	inventories, err := transportService.inventoryStorage.FindMany(ctx, bson.M{"player_id": playerID})
+	require.NoError(t, err, "Failed to get inventory")
+	require.Len(t, inventories, 1)
+	assert.Equal(t, 8, inventories[0].Speedups)
+
+	// Other alliances cannot speed up the mine
+	_, _, err = speedupService.ApplySpeedup(ctx, mine.ID, SpeedupOrder{
+		PlayerID: primitive.NewObjectID(), AllianceID: primitive.NewObjectID(), Duration: time.Hour, Payment: SpeedupPaymentGems,
+	})
+	assert.Error(t, err, "Other alliances should not speed up the mine")
+
+	// Test speeding up a transport's travel with gems
+	_, err = mineService.AddGoldOre(ctx, mine.ID, 1000)
+	require.NoError(t, err, "Failed to add gold ore")
+	_, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+
+	transport, err := transportService.StartTransport(ctx, playerID, "Player 1", mine.ID, 200)
+	require.NoError(t, err, "Failed to start transport")
+
+	_, _, err = speedupService.ApplyTransportSpeedup(ctx, transport.ID, SpeedupOrder{
+		PlayerID: playerID, AllianceID: allianceID, Duration: time.Hour, Payment: SpeedupPaymentGems,
+	})
+	assert.Error(t, err, "Transports cannot be sped up before they depart")
+
+	_, _, err = transportService.storage.FindOneAndUpdate(ctx, transport.ID, func(tr *Transport) (*Transport, error) {
+		depart(tr, time.Now())
+		return tr, nil
+	})
+	require.NoError(t, err, "Failed to depart transport")
+
+	transport, spend, err = speedupService.ApplyTransportSpeedup(ctx, transport.ID, SpeedupOrder{
+		PlayerID: playerID, AllianceID: allianceID, Duration: 10 * time.Minute, Payment: SpeedupPaymentGems,
+	})
+	require.NoError(t, err, "Failed to speed up transport")
+	assert.Equal(t, 10, spend.GemsSpent)
+	assert.Equal(t, TransportStatusInProgress, transport.Status)
+
+	// Skipping more than the time left only charges the time left and arrives right away
+	transport, spend, err = speedupService.ApplyTransportSpeedup(ctx, transport.ID, SpeedupOrder{
+		PlayerID: playerID, AllianceID: allianceID, Duration: 10 * time.Hour, Payment: SpeedupPaymentGems,
+	})
+	require.NoError(t, err, "Failed to speed up transport")
+	assert.Equal(t, 20, spend.GemsSpent)
+	assert.Equal(t, TransportStatusCompleted, transport.Status)
+
+	// Every spend is recorded for analytics
+	spends, err := speedupService.GetPlayerSpends(ctx, playerID)
+	require.NoError(t, err, "Failed to get spends")
+	require.Len(t, spends, 3)
+	assert.Equal(t, SpeedupTargetTransportTravel, spends[0].Target)
+	assert.Equal(t, SpeedupTargetMineDevelopment, spends[2].Target)
+}
+
+// TestSpeedupCost tests how much a speed-up skips and costs
+func TestSpeedupCost(t *testing.T) {
+	// Items skip whole items and gems whole minutes
+	skipped, items, gems := speedupCost(time.Hour, 10*time.Hour, SpeedupPaymentItems)
+	assert.Equal(t, time.Hour, skipped)
+	assert.Equal(t, 12, items)
+	assert.Equal(t, 0, gems)
+
+	skipped, items, gems = speedupCost(7*time.Minute, 10*time.Hour, SpeedupPaymentItems)
+	assert.Equal(t, 10*time.Minute, skipped)
+	assert.Equal(t, 2, items)
+	assert.Equal(t, 0, gems)
+
+	skipped, items, gems = speedupCost(90*time.Second, 10*time.Hour, SpeedupPaymentGems)
+	assert.Equal(t, 2*time.Minute, skipped)
+	assert.Equal(t, 0, items)
+	assert.Equal(t, 2*speedupGemsPerMinute, gems)
+
+	// Only the time left is skipped and charged
+	skipped, items, _ = speedupCost(time.Hour, 12*time.Minute, SpeedupPaymentItems)
+	assert.Equal(t, 12*time.Minute, skipped)
+	assert.Equal(t, 3, items)
+
+	skipped, _, gems = speedupCost(time.Hour, 90*time.Second, SpeedupPaymentGems)
+	assert.Equal(t, 90*time.Second, skipped)
+	assert.Equal(t, 2*speedupGemsPerMinute, gems)
+
+	// Nothing left, nothing charged
+	skipped, items, gems = speedupCost(time.Hour, -time.Minute, SpeedupPaymentGems)
+	assert.Equal(t, time.Duration(0), skipped)
+	assert.Equal(t, 0, items)
+	assert.Equal(t, 0, gems)
+}
+
+// TestBalanceService tests publishing, reloading and rolling back balance configs
+func TestBalanceService(t *testing.T) {
+	// Set up services
+	mineService, ticketService, _, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	balanceCollection := client.Database("test_db").Collection("test_balance_configs_" + primitive.NewObjectID().Hex())
+	defer balanceCollection.Drop(ctx)
+
+	newBalanceService := func() *BalanceService {
+		balanceStorage := newTestStorage[*BalanceConfig](t, balanceCollection)
+		return NewBalanceService(balanceStorage)
+	}
+	balanceService := newBalanceService()
+	otherServer := newBalanceService()
+	mineService.SetBalance(balanceService)
+	ticketService.SetBalance(balanceService)
+
+	// The defaults are in effect until a version is published
+	current, err := balanceService.Reload(ctx)
+	require.NoError(t, err, "Failed to reload balance config")
+	assert.Equal(t, 0, current.Version)
+	assert.Equal(t, 300, current.PurchasePrice(0))
+
+	// Invalid rules are rejected
+	invalid := DefaultBalanceConfig()
+	invalid.MineLevels = nil
+	_, err = balanceService.PublishBalanceConfig(ctx, invalid, "no mine levels")
+	assert.Error(t, err, "Publishing invalid rules should fail")
+
+	// Publish cheaper tickets and faster development
+	config := DefaultBalanceConfig()
+	config.TicketPrice.BasePrice = 200
+	config.MineLevels[0].RequiredPoints = 5000
+	published, err := balanceService.PublishBalanceConfig(ctx, config, "weekend event")
+	require.NoError(t, err, "Failed to publish balance config")
+	assert.Equal(t, 1, published.Version)
+	assert.Equal(t, "weekend event", published.Note)
+	assert.Equal(t, 1, balanceService.Current().Version)
+
+	// Services use the published rules right away
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+	mine, err := mineService.CreateMine(ctx, primitive.NewObjectID(), "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+	assert.Equal(t, 5000.0, mine.RequiredPoints)
+
+	playerID := primitive.NewObjectID()
+	_, err = ticketService.GetOrCreateTickets(ctx, playerID, primitive.NewObjectID(), 5)
+	require.NoError(t, err, "Failed to create tickets")
+	_, price, err := ticketService.PurchaseTicket(ctx, playerID)
+	require.NoError(t, err, "Failed to purchase ticket")
+	assert.Equal(t, 200, price)
+
+	// Other servers pick the new version up when they reload
+	assert.Equal(t, 0, otherServer.Current().Version)
+	current, err = otherServer.Reload(ctx)
+	require.NoError(t, err, "Failed to reload balance config")
+	assert.Equal(t, 1, current.Version)
+	assert.Equal(t, 200, current.TicketPrice.BasePrice)
+
+	// Rolling back publishes the earlier rules as a new version
+	config.TicketPrice.BasePrice = 250
+	_, err = balanceService.PublishBalanceConfig(ctx, config, "event price")
+	require.NoError(t, err, "Failed to publish balance config")
+	rolledBack, err := balanceService.RollbackBalanceConfig(ctx, 1)
+	require.NoError(t, err, "Failed to roll back balance config")
+	assert.Equal(t, 3, rolledBack.Version)
+	assert.Equal(t, 200, rolledBack.TicketPrice.BasePrice)
+	assert.Equal(t, "rollback to version 1", rolledBack.Note)
+
+	versions, err := balanceService.GetBalanceVersions(ctx, 10)
+	require.NoError(t, err, "Failed to get balance versions")
+	require.Len(t, versions, 3)
+	assert.Equal(t, 3, versions[0].Version)
+	assert.Equal(t, 1, versions[2].Version)
+
+	_, err = balanceService.RollbackBalanceConfig(ctx, 10)
+	assert.Error(t, err, "Rolling back to an unknown version should fail")
+}
+
+// TestBalanceConfig tests the balance formulas and their validation
+func TestBalanceConfig(t *testing.T) {
+	config := DefaultBalanceConfig()
+	require.NoError(t, config.Validate())
+
+	// Contribution rate: baseRate * (1 + starBonus + levelBonus) * rarityMultiplier
+	assert.InDelta(t, 10.0/60.0, config.ContributionRate(1, 0, GeneralRarityLegendary), 1e-9)
+	assert.InDelta(t, 10.0/60.0*(1+10*0.02+79*0.0012658)*0.8, config.ContributionRate(80, 10, GeneralRarityEpic), 1e-9)
+	assert.InDelta(t, config.ContributionRate(5, 3, GeneralRarityRare), config.ContributionRate(5, 3, GeneralRaritySoldier), 1e-9)
+	assert.InDelta(t, config.ContributionRate(5, 3, GeneralRarityCommon), config.ContributionRate(5, 3, "unknown"), 1e-9)
+
+	// The general service uses the defaults without a balance service
+	general := &General{Level: 10, Stars: 5, Rarity: GeneralRarityUncommon}
+	assert.InDelta(t, config.ContributionRate(10, 5, GeneralRarityUncommon), NewGeneralService(nil).CalculateContributionRate(general), 1e-9)
+
+	// Mine levels above the highest configured level use its settings
+	level, ok := config.MineLevel(MineLevel2)
+	require.True(t, ok)
+	assert.Equal(t, 30000.0, level.RequiredPoints)
+	assert.Equal(t, 10, level.TransportTicketMax)
+	config.MineLevels = config.MineLevels[:3]
+	level, ok = config.MineLevel(MineLevel5)
+	require.True(t, ok)
+	assert.Equal(t, MineLevel3, level.Level)
+	_, ok = config.MineLevel(0)
+	assert.False(t, ok)
+
+	// Ticket price rises with every purchase of the day
+	assert.Equal(t, 300, config.PurchasePrice(0))
+	assert.Equal(t, 500, config.PurchasePrice(2))
+
+	// Copies don't share rarity multipliers or mine levels
+	copied := config.Copy()
+	copied.Contribution.RarityMultipliers[GeneralRarityCommon] = 1
+	copied.MineLevels[0].RequiredPoints = 1
+	assert.Equal(t, 0.2, config.Contribution.RarityMultipliers[GeneralRarityCommon])
+	assert.Equal(t, 10000.0, config.MineLevels[0].RequiredPoints)
+
+	// Invalid rules
+	invalid := DefaultBalanceConfig()
+	invalid.Contribution.BaseRate = 0
+	assert.Error(t, invalid.Validate())
+
+	invalid = DefaultBalanceConfig()
+	invalid.MineLevels[1].Level = MineLevel1
+	assert.Error(t, invalid.Validate())
+
+	invalid = DefaultBalanceConfig()
+	invalid.MineLevels = invalid.MineLevels[1:]
+	assert.Error(t, invalid.Validate())
+
+	invalid = DefaultBalanceConfig()
+	invalid.MineLevels[2].RequiredPoints = 0
+	assert.Error(t, invalid.Validate())
+
+	invalid = DefaultBalanceConfig()
+	invalid.TicketPrice.PriceIncrease = -1
+	assert.Error(t, invalid.Validate())
+}
+
+// TestAllianceService tests alliance membership, roles and permission checks
+func TestAllianceService(t *testing.T) {
+	// Set up services
+	mineService, ticketService, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	allianceCollection := client.Database("test_db").Collection("test_alliance_members_" + primitive.NewObjectID().Hex())
+	defer allianceCollection.Drop(ctx)
+
+	allianceStorage := newTestStorage[*AllianceMember](t, allianceCollection)
+
+	allianceService := NewAllianceService(allianceStorage)
+	mineService.SetAlliances(allianceService)
+	transportService.SetAlliances(allianceService)
+
+	allianceID := primitive.NewObjectID()
+	otherAllianceID := primitive.NewObjectID()
+	leaderID := primitive.NewObjectID()
+	officerID := primitive.NewObjectID()
+	memberID := primitive.NewObjectID()
+	outsiderID := primitive.NewObjectID()
+
+	// Found the alliance and add members
+	leader, err := allianceService.FoundAlliance(ctx, allianceID, leaderID, "Leader")
+	require.NoError(t, err, "Failed to found alliance")
+	assert.Equal(t, AllianceRoleLeader, leader.Role)
+	_, err = allianceService.FoundAlliance(ctx, allianceID, outsiderID, "Outsider")
+	assert.Error(t, err, "Founding an existing alliance should fail")
+
+	_, err = allianceService.AddMember(ctx, allianceID, leaderID, officerID, "Officer")
+	require.NoError(t, err, "Failed to add member")
+	officer, err := allianceService.SetMemberRole(ctx, allianceID, leaderID, officerID, AllianceRoleOfficer)
+	require.NoError(t, err, "Failed to promote member")
+	assert.Equal(t, AllianceRoleOfficer, officer.Role)
+
+	member, err := allianceService.AddMember(ctx, allianceID, officerID, memberID, "Member")
+	require.NoError(t, err, "Officers should be able to add members")
+	assert.Equal(t, AllianceRoleMember, member.Role)
+
+	// Members cannot add members, players cannot be in two alliances
+	_, err = allianceService.AddMember(ctx, allianceID, memberID, outsiderID, "Outsider")
+	assert.Error(t, err, "Members should not be able to add members")
+	_, err = allianceService.AddMember(ctx, allianceID, leaderID, memberID, "Member")
+	assert.Error(t, err, "Adding a member twice should fail")
+	_, err = allianceService.FoundAlliance(ctx, otherAllianceID, memberID, "Member")
+	assert.Error(t, err, "Players should not be able to join a second alliance")
+
+	members, err := allianceService.GetMembers(ctx, allianceID)
+	require.NoError(t, err, "Failed to get members")
+	assert.Len(t, members, 3)
+
+	// Only the leader changes roles
+	_, err = allianceService.SetMemberRole(ctx, allianceID, officerID, memberID, AllianceRoleOfficer)
+	assert.Error(t, err, "Officers should not be able to change roles")
+
+	// Only members of the owning alliance can start or join transports
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+	mine, err := mineService.CreateMine(ctx, allianceID, "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+	_, err = mineService.AddGoldOre(ctx, mine.ID, 1000)
+	require.NoError(t, err, "Failed to add gold ore")
+	for _, playerID := range []primitive.ObjectID{leaderID, memberID, outsiderID} {
+		_, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+		require.NoError(t, err, "Failed to create tickets")
+	}
+
+	_, err = transportService.StartTransport(ctx, outsiderID, "Outsider", mine.ID, 200)
+	assert.Error(t, err, "Outsiders should not be able to start transports")
+	transport, err := transportService.StartTransport(ctx, leaderID, "Leader", mine.ID, 200)
+	require.NoError(t, err, "Failed to start transport")
+	_, err = transportService.JoinTransport(ctx, transport.ID, outsiderID, "Outsider", 100)
+	assert.Error(t, err, "Outsiders should not be able to join transports")
+	_, err = transportService.JoinTransport(ctx, transport.ID, memberID, "Member", 100)
+	require.NoError(t, err, "Failed to join transport")
+
+	tickets, err := ticketService.GetOrCreateTickets(ctx, outsiderID, allianceID, 5)
+	require.NoError(t, err, "Failed to get tickets")
+	assert.Equal(t, 0, tickets.HeldTickets, "Rejected players should not hold tickets")
+
+	// Only members of the owning alliance can assign generals or activate the mine
+	_, err = mineService.AssignGeneralToMine(ctx, mine.ID, outsiderID, "Outsider", primitive.NewObjectID())
+	assert.ErrorContains(t, err, "not a member")
+
+	mine, err = mineService.ForceCompleteDevelopment(ctx, mine.ID)
+	require.NoError(t, err, "Failed to complete development")
+	_, err = mineService.ActivateMine(ctx, mine.ID, outsiderID)
+	assert.Error(t, err, "Outsiders should not be able to activate the mine")
+	mine, err = mineService.ActivateMine(ctx, mine.ID, memberID)
+	require.NoError(t, err, "Failed to activate mine")
+	assert.Equal(t, MineStatusActive, mine.Status)
+
+	// Handing over leadership makes the leader an officer
+	officer, err = allianceService.SetMemberRole(ctx, allianceID, leaderID, officerID, AllianceRoleLeader)
+	require.NoError(t, err, "Failed to hand over leadership")
+	assert.Equal(t, AllianceRoleLeader, officer.Role)
+	leader, err = allianceService.GetMember(ctx, leaderID)
+	require.NoError(t, err, "Failed to get member")
+	assert.Equal(t, AllianceRoleOfficer, leader.Role)
+
+	// Members can only remove members of a lower role, and the leader cannot leave
+	err = allianceService.RemoveMember(ctx, allianceID, leaderID, officerID)
+	assert.Error(t, err, "Officers should not be able to remove the leader")
+	err = allianceService.RemoveMember(ctx, allianceID, officerID, officerID)
+	assert.Error(t, err, "The leader should not be able to leave while members remain")
+	err = allianceService.RemoveMember(ctx, allianceID, leaderID, memberID)
+	require.NoError(t, err, "Failed to remove member")
+	err = allianceService.RemoveMember(ctx, allianceID, leaderID, leaderID)
+	require.NoError(t, err, "Failed to leave alliance")
+
+	// Former members can no longer act for the alliance but can join another one
+	assert.Error(t, allianceService.CheckMember(ctx, allianceID, memberID))
+	_, err = allianceService.FoundAlliance(ctx, otherAllianceID, memberID, "Member")
+	require.NoError(t, err, "Failed to found alliance")
+	assert.Error(t, allianceService.CheckMember(ctx, allianceID, memberID))
+	assert.NoError(t, allianceService.CheckMember(ctx, otherAllianceID, memberID))
+}
+
+// TestAllianceRoles tests role ordering and membership IDs
+func TestAllianceRoles(t *testing.T) {
+	assert.Greater(t, AllianceRoleLeader.rank(), AllianceRoleOfficer.rank())
+	assert.Greater(t, AllianceRoleOfficer.rank(), AllianceRoleMember.rank())
+	assert.Equal(t, 0, AllianceRole("admin").rank())
+
+	// A player has one membership, whatever the alliance
+	playerID := primitive.NewObjectID()
+	assert.Equal(t, allianceMemberID(playerID), allianceMemberID(playerID))
+	assert.NotEqual(t, allianceMemberID(playerID), allianceMemberID(primitive.NewObjectID()))
+
+	// Without an alliance service every player passes
+	var alliances *AllianceService
+	assert.NoError(t, alliances.CheckMember(context.Background(), primitive.NewObjectID(), playerID))
+}
+
+// TestMineHistory tests recording mine versions and reconstructing past states
+func TestMineHistory(t *testing.T) {
+	// Set up services
+	mineService, _, _, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	snapshotCollection := client.Database("test_db").Collection("test_mine_snapshots_" + primitive.NewObjectID().Hex())
+	defer snapshotCollection.Drop(ctx)
+
+	snapshotStorage := newTestStorage[*MineSnapshot](t, snapshotCollection)
+
+	historyService := NewMineHistoryService(snapshotStorage)
+
+	// Without history there is nothing to look back at
+	_, err = mineService.GetMineAt(ctx, primitive.NewObjectID(), time.Now())
+	assert.Error(t, err, "GetMineAt should fail without history")
+
+	mineService.SetHistory(historyService)
+	mineService.SetHistory(historyService) // Setting the history again must not record twice
+
+	beforeCreation := time.Now()
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+	mine, err := mineService.CreateMine(ctx, primitive.NewObjectID(), "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+	created := time.Now()
+
+	// Start developing with a general adding 100 points per hour
+	startedAt := time.Now().Add(-time.Hour)
+	mine, err = mineService.UpdateMineWithFunction(ctx, mine.ID, func(m *Mine) (*Mine, error) {
+		m.Status = MineStatusDeveloping
+		m.RequiredPoints = 1000
+		m.LastUpdatedAt = startedAt
+		m.AssignedGenerals = []AssignedGeneral{{PlayerID: primitive.NewObjectID(), GeneralID: primitive.NewObjectID(), ContributionRate: 100}}
+		return m, nil
+	})
+	require.NoError(t, err, "Failed to start development")
+	developing := time.Now()
+
+	mine, err = mineService.AddGoldOre(ctx, mine.ID, 500)
+	require.NoError(t, err, "Failed to add gold ore")
+
+	// Every write was recorded once
+	history, err := mineService.GetMineHistory(ctx, mine.ID, beforeCreation, time.Now())
+	require.NoError(t, err, "Failed to get mine history")
+	require.Len(t, history, 3)
+	for i, snapshot := range history {
+		assert.Equal(t, int64(i+1), snapshot.Version)
+	}
+
+	// Past states are reconstructed from the snapshots
+	_, err = mineService.GetMineAt(ctx, mine.ID, beforeCreation)
+	assert.ErrorIs(t, err, nodestorage.ErrNotFound)
+
+	past, err := mineService.GetMineAt(ctx, mine.ID, created)
+	require.NoError(t, err, "Failed to get mine at creation")
+	assert.Equal(t, MineStatusUndeveloped, past.Status)
+	assert.Equal(t, 0, past.GoldOre)
+
+	past, err = mineService.GetMineAt(ctx, mine.ID, developing)
+	require.NoError(t, err, "Failed to get developing mine")
+	assert.Equal(t, MineStatusDeveloping, past.Status)
+	assert.Equal(t, 0, past.GoldOre)
+	assert.InDelta(t, 100.0, past.DevelopmentPoints, 1.0, "Development is brought up to the requested time")
+
+	past, err = mineService.GetMineAt(ctx, mine.ID, time.Now())
+	require.NoError(t, err, "Failed to get current mine")
+	assert.Equal(t, 500, past.GoldOre)
+
+	// Expired snapshots are purged, except the latest of each mine
+	purged, err := historyService.PurgeExpiredSnapshots(ctx, time.Now().Add(mineSnapshotRetention+time.Minute))
+	require.NoError(t, err, "Failed to purge snapshots")
+	assert.Equal(t, 2, purged)
+
+	past, err = mineService.GetMineAt(ctx, mine.ID, time.Now())
+	require.NoError(t, err, "Latest snapshot should be kept")
+	assert.Equal(t, 500, past.GoldOre)
+}
+
+// TestProjectDevelopment tests bringing development of a past mine state up to a given time
+func TestProjectDevelopment(t *testing.T) {
+	start := time.Now()
+	mine := &Mine{
+		Status:            MineStatusDeveloping,
+		DevelopmentPoints: 100,
+		RequiredPoints:    1000,
+		LastUpdatedAt:     start,
+		AssignedGenerals:  []AssignedGeneral{{ContributionRate: 100}, {ContributionRate: 50}},
+	}
+
+	projected := mine.Copy()
+	projectDevelopment(projected, start.Add(2*time.Hour))
+	assert.InDelta(t, 400.0, projected.DevelopmentPoints, 1e-9)
+	assert.Equal(t, start.Add(2*time.Hour), projected.LastUpdatedAt)
+
+	// Never more than required
+	projected = mine.Copy()
+	projectDevelopment(projected, start.Add(24*time.Hour))
+	assert.Equal(t, 1000.0, projected.DevelopmentPoints)
+
+	// Earlier times and mines not in development are left alone
+	projected = mine.Copy()
+	projectDevelopment(projected, start.Add(-time.Hour))
+	assert.Equal(t, 100.0, projected.DevelopmentPoints)
+
+	projected = mine.Copy()
+	projected.Status = MineStatusDeveloped
+	projectDevelopment(projected, start.Add(time.Hour))
+	assert.Equal(t, 100.0, projected.DevelopmentPoints)
+
+	// Each version of a mine has one snapshot
+	mineID := primitive.NewObjectID()
+	assert.Equal(t, mineSnapshotID(mineID, 3), mineSnapshotID(mineID, 3))
+	assert.NotEqual(t, mineSnapshotID(mineID, 3), mineSnapshotID(mineID, 4))
+}
+
+// TestMineDevelopmentProcessor tests bringing the development of all developing mines up to date
+func TestMineDevelopmentProcessor(t *testing.T) {
+	// Set up services
+	mineService, _, _, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	lockCollection := client.Database("test_db").Collection("test_shard_locks_" + primitive.NewObjectID().Hex())
+	defer lockCollection.Drop(ctx)
+
+	lockStorage := newTestStorage[*ShardLock](t, lockCollection)
+
+	processor := NewMineDevelopmentProcessor(mineService, lockStorage, "worker-1")
+	other := NewMineDevelopmentProcessor(mineService, lockStorage, "worker-2")
+
+	// Developing mines with a general adding 100 points per hour for the last hour
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+	var mines []*Mine
+	for i := 0; i < 5; i++ {
+		mine, err := mineService.CreateMine(ctx, primitive.NewObjectID(), "Test Mine", 1)
+		require.NoError(t, err, "Failed to create mine")
+		assert.Equal(t, mineShard(mine.ID), mine.Shard)
+
+		mine, err = mineService.UpdateMineWithFunction(ctx, mine.ID, func(m *Mine) (*Mine, error) {
+			m.Status = MineStatusDeveloping
+			m.RequiredPoints = 1000
+			m.LastUpdatedAt = time.Now().Add(-time.Hour)
+			m.AssignedGenerals = []AssignedGeneral{{PlayerID: primitive.NewObjectID(), GeneralID: primitive.NewObjectID(), ContributionRate: 100}}
+			return m, nil
+		})
+		require.NoError(t, err, "Failed to start development")
+		mines = append(mines, mine)
+	}
+
+	// A shard locked by another worker is skipped
+	lockedShard := mines[0].Shard
+	_, err = other.acquire(ctx, lockedShard)
+	require.NoError(t, err, "Failed to lock shard")
+
+	_, err = processor.ProcessShard(ctx, lockedShard)
+	assert.ErrorIs(t, err, errShardLocked)
+
+	_, err = processor.ProcessAll(ctx)
+	require.NoError(t, err, "Failed to process shards")
+
+	for _, mine := range mines {
+		updated, err := mineService.GetMine(ctx, mine.ID)
+		require.NoError(t, err, "Failed to get mine")
+		if mine.Shard == lockedShard {
+			assert.Equal(t, 0.0, updated.DevelopmentPoints, "Mines of a locked shard are left to its worker")
+		} else {
+			assert.InDelta(t, 100.0, updated.DevelopmentPoints, 1.0, "Development is brought up to date")
+		}
+	}
+
+	// Once released, the shard is processed by the next worker
+	require.NoError(t, other.release(ctx, lockedShard), "Failed to release shard")
+	processed, err := processor.ProcessShard(ctx, lockedShard)
+	require.NoError(t, err, "Failed to process shard")
+	assert.GreaterOrEqual(t, processed, 1)
+
+	updated, err := mineService.GetMine(ctx, mines[0].ID)
+	require.NoError(t, err, "Failed to get mine")
+	assert.InDelta(t, 100.0, updated.DevelopmentPoints, 1.0, "Development is brought up to date")
+
+	// The lock is released after processing
+	lock, err := lockStorage.FindOne(ctx, shardLockID(mineDevelopmentJob, lockedShard))
+	require.NoError(t, err, "Failed to get shard lock")
+	assert.Empty(t, lock.Owner)
+}
+
+// TestShardLocks tests how mines are spread over shards and when a shard lock can be taken
+func TestShardLocks(t *testing.T) {
+	// Every mine has a stable shard within range
+	for i := 0; i < 100; i++ {
+		mineID := primitive.NewObjectID()
+		shard := mineShard(mineID)
+		assert.Equal(t, shard, mineShard(mineID))
+		assert.GreaterOrEqual(t, shard, 0)
+		assert.Less(t, shard, mineDevelopmentShards)
+	}
+
+	// Each shard of a job has its own lock
+	assert.Equal(t, shardLockID(mineDevelopmentJob, 1), shardLockID(mineDevelopmentJob, 1))
+	assert.NotEqual(t, shardLockID(mineDevelopmentJob, 1), shardLockID(mineDevelopmentJob, 2))
+	assert.NotEqual(t, shardLockID(mineDevelopmentJob, 1), shardLockID("other-job", 1))
+
+	now := time.Now()
+	lock := &ShardLock{Owner: "worker-1", ExpiresAt: now.Add(time.Minute)}
+	assert.True(t, lock.heldByOther("worker-2", now), "Held until it expires")
+	assert.False(t, lock.heldByOther("worker-1", now), "The owner can renew its lock")
+	assert.False(t, lock.heldByOther("worker-2", now.Add(time.Minute)), "Expired locks can be taken over")
+
+	released := &ShardLock{ExpiresAt: now.Add(time.Minute)}
+	assert.False(t, released.heldByOther("worker-2", now), "Released locks can be taken")
+}
+
+// TestGeneralRecruitment tests recruiting generals, converting duplicates and querying rosters
+func TestGeneralRecruitment(t *testing.T) {
+	// Set up services
+	mineService, _, _, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	generalService := mineService.GetGeneralService()
+	playerID := primitive.NewObjectID()
+
+	// A pool with a single general: the second recruitment is a duplicate
+	config := DefaultBalanceConfig()
+	config.Recruitment.Pool = []RecruitableGeneral{{Name: "General Zhao", Rarity: GeneralRarityRare}}
+	generalService.SetBalance(&BalanceService{current: config})
+
+	result, err := generalService.RecruitGeneral(ctx, playerID)
+	require.NoError(t, err, "Failed to recruit general")
+	assert.False(t, result.Duplicate)
+	assert.Equal(t, "General Zhao", result.General.Name)
+	assert.Equal(t, GeneralRarityRare, result.General.Rarity)
+	assert.Equal(t, 1, result.General.Level)
+	assert.Equal(t, GeneralStatusIdle, result.General.Status)
+
+	result, err = generalService.RecruitGeneral(ctx, playerID)
+	require.NoError(t, err, "Failed to recruit general")
+	assert.True(t, result.Duplicate)
+	assert.Equal(t, 5, result.Shards)
+	assert.Equal(t, 5, result.General.Shards)
+
+	// Other players recruit their own copy
+	result, err = generalService.RecruitGeneral(ctx, primitive.NewObjectID())
+	require.NoError(t, err, "Failed to recruit general")
+	assert.False(t, result.Duplicate)
+
+	// Roster queries by rarity and status
+	_, err = generalService.CreateGeneral(ctx, playerID, "General Zhang", 50, 10, GeneralRarityLegendary)
+	require.NoError(t, err, "Failed to create general")
+
+	roster, err := generalService.GetRoster(ctx, playerID, RosterFilter{})
+	require.NoError(t, err, "Failed to get roster")
+	require.Len(t, roster, 2)
+	assert.Equal(t, "General Zhang", roster[0].Name, "Highest level first")
+
+	roster, err = generalService.GetRoster(ctx, playerID, RosterFilter{Rarity: GeneralRarityRare})
+	require.NoError(t, err, "Failed to get roster")
+	require.Len(t, roster, 1)
+	assert.Equal(t, "General Zhao", roster[0].Name)
+
+	_, err = generalService.AssignGeneral(ctx, roster[0].ID, "mine_development", primitive.NewObjectID(), "Test Mine")
+	require.NoError(t, err, "Failed to assign general")
+
+	roster, err = generalService.GetRoster(ctx, playerID, RosterFilter{Status: GeneralStatusIdle})
+	require.NoError(t, err, "Failed to get roster")
+	require.Len(t, roster, 1)
+	assert.Equal(t, "General Zhang", roster[0].Name)
+
+	roster, err = generalService.GetRoster(ctx, playerID, RosterFilter{Rarity: GeneralRarityRare, Status: GeneralStatusAssigned})
+	require.NoError(t, err, "Failed to get roster")
+	assert.Len(t, roster, 1)
+}
+
+// TestDrawRecruit tests drawing recruits by rarity weight
+func TestDrawRecruit(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// The odds follow the rarity weights
+	recruitment := DefaultBalanceConfig().Recruitment
+	counts := map[GeneralRarity]int{}
+	for i := 0; i < 10000; i++ {
+		drawn, err := drawRecruit(recruitment, rng)
+		require.NoError(t, err)
+		counts[drawn.Rarity]++
+	}
+	assert.InDelta(t, 5000, counts[GeneralRarityCommon], 300)
+	assert.InDelta(t, 400, counts[GeneralRarityEpic], 100)
+	assert.InDelta(t, 100, counts[GeneralRarityLegendary], 50)
+
+	// Rarities without generals in the pool or without weight are never drawn
+	recruitment = RecruitmentBalance{
+		RarityWeights: map[GeneralRarity]int{GeneralRarityCommon: 0, GeneralRarityEpic: 10, GeneralRarityLegendary: 90},
+		Pool: []RecruitableGeneral{
+			{Name: "Village Guard", Rarity: GeneralRarityCommon},
+			{Name: "General Li", Rarity: GeneralRarityEpic},
+		},
+	}
+	for i := 0; i < 100; i++ {
+		drawn, err := drawRecruit(recruitment, rng)
+		require.NoError(t, err)
+		assert.Equal(t, "General Li", drawn.Name)
+	}
+
+	recruitment.RarityWeights[GeneralRarityEpic] = 0
+	_, err := drawRecruit(recruitment, rng)
+	assert.Error(t, err, "Nothing can be recruited")
+
+	// Owning a general is decided by the player and the name
+	playerID := primitive.NewObjectID()
+	assert.Equal(t, recruitedGeneralID(playerID, "General Li"), recruitedGeneralID(playerID, "General Li"))
+	assert.NotEqual(t, recruitedGeneralID(playerID, "General Li"), recruitedGeneralID(playerID, "General Ma"))
+	assert.NotEqual(t, recruitedGeneralID(playerID, "General Li"), recruitedGeneralID(primitive.NewObjectID(), "General Li"))
+
+	// Versions published before recruitment was configurable use the default odds
+	config := DefaultBalanceConfig()
+	config.Recruitment = RecruitmentBalance{}
+	assert.Equal(t, DefaultBalanceConfig().Recruitment, config.RecruitmentRules())
+
+	// Invalid recruitment rules
+	invalid := DefaultBalanceConfig()
+	invalid.Recruitment.RarityWeights[GeneralRarityCommon] = -1
+	assert.Error(t, invalid.Validate())
+
+	invalid = DefaultBalanceConfig()
+	invalid.Recruitment.Pool = append(invalid.Recruitment.Pool, invalid.Recruitment.Pool[0])
+	assert.Error(t, invalid.Validate())
+
+	invalid = DefaultBalanceConfig()
+	invalid.Recruitment.Pool = []RecruitableGeneral{{Name: "Nobody", Rarity: "mythic"}}
+	assert.Error(t, invalid.Validate())
+
+	invalid = DefaultBalanceConfig()
+	invalid.Recruitment.RarityWeights = nil
+	assert.Error(t, invalid.Validate())
+}
+
+// TestAntiCheatService tests rejecting and recording impossible state transitions
+func TestAntiCheatService(t *testing.T) {
+	// Set up services
+	mineService, _, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	defer client.Disconnect(context.Background())
+
+	ctx := context.Background()
+	reportCollection := client.Database("test_db").Collection("test_cheat_reports_" + primitive.NewObjectID().Hex())
+	defer reportCollection.Drop(ctx)
+
+	reportStorage := newTestStorage[*CheatReport](t, reportCollection)
+
+	antiCheatService := NewAntiCheatService(reportStorage)
+	generalService := mineService.GetGeneralService()
+	generalService.SetCheats(antiCheatService)
+	mineService.SetCheats(antiCheatService)
+	transportService.SetCheats(antiCheatService)
+
+	ownerID := primitive.NewObjectID()
+	cheaterID := primitive.NewObjectID()
+	startedAt := time.Now()
+
+	// Developing a mine with another player's general
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+	mine, err := mineService.CreateMine(ctx, primitive.NewObjectID(), "Test Mine", 1)
+	require.NoError(t, err, "Failed to create mine")
+	general, err := generalService.CreateGeneral(ctx, ownerID, "General Zhang", 50, 10, GeneralRarityLegendary)
+	require.NoError(t, err, "Failed to create general")
+
+	_, err = mineService.AssignGeneralToMine(ctx, mine.ID, cheaterID, "Cheater", general.ID)
+	assert.ErrorIs(t, err, ErrCheatDetected)
+
+	var violation *CheatViolation
+	require.ErrorAs(t, err, &violation)
+	assert.Equal(t, CheatReasonForeignGeneral, violation.Reason)
+
+	// Leading another player's general into battle
+	_, err = generalService.BuildCombatForce(ctx, CombatOrder{PlayerID: cheaterID, GeneralIDs: []primitive.ObjectID{general.ID}, Troops: 100})
+	assert.ErrorIs(t, err, ErrCheatDetected)
+
+	// Loading negative gold ore
+	_, err = transportService.StartTransport(ctx, cheaterID, "Cheater", mine.ID, -100)
+	assert.ErrorIs(t, err, ErrCheatDetected)
+
+	// Joining a transport that already arrived
+	finished, err := transportService.storage.FindOneAndUpsert(ctx, &Transport{
+		ID:          primitive.NewObjectID(),
+		AllianceID:  mine.AllianceID,
+		MineID:      mine.ID,
+		Status:      TransportStatusCompleted,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		VectorClock: 1,
+	})
+	require.NoError(t, err, "Failed to create transport")
+
+	_, err = transportService.JoinTransport(ctx, finished.ID, cheaterID, "Cheater", 100)
+	assert.ErrorIs(t, err, ErrCheatDetected)
+
+	// Every violation was recorded with its reason
+	reports, err := antiCheatService.GetPlayerReports(ctx, cheaterID)
+	require.NoError(t, err, "Failed to get cheat reports")
+	require.Len(t, reports, 4)
+	assert.Equal(t, CheatReasonFinishedTransport, reports[0].Reason, "Newest first")
+	assert.Equal(t, CheatActionJoinTransport, reports[0].Action)
+	assert.Equal(t, finished.ID, reports[0].TargetID)
+	assert.Equal(t, "completed", reports[0].Details["status"])
+
+	reports, err = antiCheatService.GetReports(ctx, CheatReasonForeignGeneral, startedAt)
+	require.NoError(t, err, "Failed to get cheat reports")
+	require.Len(t, reports, 2)
+	for _, r := range reports {
+		assert.Equal(t, cheaterID, r.PlayerID)
+		assert.Equal(t, general.ID, r.TargetID)
+		assert.Equal(t, ownerID.Hex(), r.Details["owner_id"])
+	}
+
+	reports, err = antiCheatService.GetPlayerReports(ctx, ownerID)
+	require.NoError(t, err, "Failed to get cheat reports")
+	assert.Empty(t, reports)
+}
+
+// TestCheatDetectors tests detecting impossible state transitions
+func TestCheatDetectors(t *testing.T) {
+	playerID := primitive.NewObjectID()
+	targetID := primitive.NewObjectID()
+
+	// Negative gold ore
+	assert.Nil(t, detectNegativeGoldOre(playerID, CheatActionSendTrade, targetID, 0))
+	assert.Nil(t, detectNegativeGoldOre(playerID, CheatActionSendTrade, targetID, 100))
+	violation := detectNegativeGoldOre(playerID, CheatActionSendTrade, targetID, -5)
+	require.NotNil(t, violation)
+	assert.Equal(t, CheatReasonNegativeGoldOre, violation.Reason)
+	assert.Equal(t, "-5", violation.Details["amount"])
+
+	// Finished transports
+	transport := &Transport{ID: targetID}
+	for status, finished := range map[TransportStatus]bool{
+		TransportStatusPreparing:  false,
+		TransportStatusInProgress: false,
+		TransportStatusCompleted:  true,
+		TransportStatusRaided:     true,
+	} {
+		transport.Status = status
+		assert.Equal(t, finished, detectFinishedTransport(playerID, transport) != nil, "status %s", status)
+	}
+
+	// Generals of other players
+	general := &General{ID: targetID, PlayerID: playerID, Name: "General Li"}
+	assert.Nil(t, detectForeignGeneral(playerID, CheatActionCombat, general))
+	violation = detectForeignGeneral(primitive.NewObjectID(), CheatActionCombat, general)
+	require.NotNil(t, violation)
+	assert.Equal(t, CheatReasonForeignGeneral, violation.Reason)
+	assert.Equal(t, playerID.Hex(), violation.Details["owner_id"])
+
+	// Violations are rejected even without a service to record them
+	var service *AntiCheatService
+	assert.NoError(t, service.Check(context.Background(), nil))
+	err := service.Check(context.Background(), violation)
+	assert.ErrorIs(t, err, ErrCheatDetected)
+	assert.EqualError(t, err, "general General Li does not belong to this player")
+}
+
+// TestTradeService tests sending, resolving and expiring trades
+func TestTradeService(t *testing.T) {
+	// Set up services
+	_, ticketService, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+	tradeService := setupTestTradeService(t, ticketService, transportService)
+
+	ctx := context.Background()
+	allianceID := primitive.NewObjectID()
+	senderID := primitive.NewObjectID()
+	receiverID := primitive.NewObjectID()
+
+	for _, playerID := range []primitive.ObjectID{senderID, receiverID} {
+		_, err := tradeService.GetOrCreateInventory(ctx, playerID, allianceID)
+		require.NoError(t, err, "Failed to create inventory")
+		_, err = ticketService.GetOrCreateTickets(ctx, playerID, allianceID, 5)
+		require.NoError(t, err, "Failed to create tickets")
+	}
+	_, err := tradeService.alliances.FoundAlliance(ctx, allianceID, senderID, "Sender")
+	require.NoError(t, err, "Failed to found alliance")
+	_, err = tradeService.alliances.AddMember(ctx, allianceID, senderID, receiverID, "Receiver")
+	require.NoError(t, err, "Failed to add member")
+	_, err = tradeService.AddPlayerGoldOre(ctx, senderID, 1000)
+	require.NoError(t, err, "Failed to add gold ore")
+
+	goldOre := func(playerID primitive.ObjectID) int {
+		inventory, err := tradeService.findInventory(ctx, playerID)
+		require.NoError(t, err, "Failed to find inventory")
+		return inventory.GoldOre
+	}
+
+	// Test validation
+	_, err = tradeService.SendTrade(ctx, allianceID, senderID, "Sender", senderID, "Sender", TradeItemGoldOre, 100)
+	assert.EqualError(t, err, "cannot trade with yourself")
+	_, err = tradeService.SendTrade(ctx, allianceID, senderID, "Sender", receiverID, "Receiver", TradeItemGoldOre, maxGoldOrePerTrade+1)
+	assert.Error(t, err)
+	_, err = tradeService.SendTrade(ctx, allianceID, senderID, "Sender", primitive.NewObjectID(), "Outsider", TradeItemGoldOre, 100)
+	assert.ErrorContains(t, err, "invalid receiver")
+
+	// Membership comes from the alliance, not from the alliance the player's items were created for
+	outsiderID := primitive.NewObjectID()
+	_, err = tradeService.GetOrCreateInventory(ctx, outsiderID, allianceID)
+	require.NoError(t, err, "Failed to create inventory")
+	_, err = ticketService.GetOrCreateTickets(ctx, outsiderID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+	_, err = tradeService.AddPlayerGoldOre(ctx, outsiderID, 1000)
+	require.NoError(t, err, "Failed to add gold ore")
+	_, err = tradeService.SendTrade(ctx, allianceID, outsiderID, "Outsider", receiverID, "Receiver", TradeItemGoldOre, 100)
+	assert.ErrorContains(t, err, "invalid sender")
+	_, err = tradeService.SendTrade(ctx, allianceID, senderID, "Sender", outsiderID, "Outsider", TradeItemTicket, 1)
+	assert.ErrorContains(t, err, "invalid receiver")
+
+	// Test sending a trade, which moves the gold ore into escrow
+	trade, err := tradeService.SendTrade(ctx, allianceID, senderID, "Sender", receiverID, "Receiver", TradeItemGoldOre, 300)
+	require.NoError(t, err, "Failed to send trade")
+	assert.Equal(t, TradeStatusPending, trade.Status)
+	assert.Equal(t, 700, goldOre(senderID))
+
+	pending, err := tradeService.GetPendingTrades(ctx, receiverID)
+	require.NoError(t, err, "Failed to get pending trades")
+	require.Len(t, pending, 1)
+	assert.Equal(t, trade.ID, pending[0].ID)
+
+	// Test the cooldown, which rejects the trade without touching the sender's balance
+	_, err = tradeService.SendTrade(ctx, allianceID, senderID, "Sender", receiverID, "Receiver", TradeItemGoldOre, 100)
+	assert.ErrorContains(t, err, "cooldown")
+	assert.Equal(t, 700, goldOre(senderID))
+
+	// Test accepting the trade
+	_, err = tradeService.AcceptTrade(ctx, trade.ID, senderID)
+	assert.ErrorContains(t, err, "not addressed to this player")
+	accepted, err := tradeService.AcceptTrade(ctx, trade.ID, receiverID)
+	require.NoError(t, err, "Failed to accept trade")
+	assert.Equal(t, TradeStatusAccepted, accepted.Status)
+	assert.NotNil(t, accepted.ResolvedAt)
+	assert.Equal(t, 300, goldOre(receiverID))
+
+	_, err = tradeService.DeclineTrade(ctx, trade.ID, receiverID)
+	assert.ErrorContains(t, err, "not pending")
+
+	ledger, err := tradeService.GetLedger(ctx, receiverID)
+	require.NoError(t, err, "Failed to get ledger")
+	require.Len(t, ledger, 1)
+	assert.Equal(t, 300, ledger[0].Delta)
+	assert.Equal(t, 300, ledger[0].Balance)
+	assert.Equal(t, ledgerReasonAccept, ledger[0].Reason)
+
+	// Test the daily cap, counting trades sent earlier today that are past the cooldown
+	_, _, err = tradeService.storage.FindOneAndUpdate(ctx, trade.ID, func(tr *Trade) (*Trade, error) {
+		tr.CreatedAt = tr.CreatedAt.Add(-tradeCooldown)
+		tr.Amount = maxGoldOreSentPerDay - 100
+		return tr, nil
+	})
+	require.NoError(t, err, "Failed to backdate trade")
+	_, err = tradeService.SendTrade(ctx, allianceID, senderID, "Sender", receiverID, "Receiver", TradeItemGoldOre, 200)
+	assert.ErrorContains(t, err, "daily trade limit exceeded")
+	assert.Equal(t, 700, goldOre(senderID))
+
+	// Test concurrent trades from the same sender, of which only one passes the cooldown
+	player2ID := primitive.NewObjectID()
+	_, err = tradeService.alliances.AddMember(ctx, allianceID, senderID, player2ID, "Player 2")
+	require.NoError(t, err, "Failed to add member")
+	_, err = tradeService.GetOrCreateInventory(ctx, player2ID, allianceID)
+	require.NoError(t, err, "Failed to create inventory")
+	_, err = tradeService.AddPlayerGoldOre(ctx, player2ID, 1000)
+	require.NoError(t, err, "Failed to add gold ore")
+
+	const senders = 4
+	errs := make(chan error, senders)
+	for i := 0; i < senders; i++ {
+		go func() {
+			_, err := tradeService.SendTrade(ctx, allianceID, player2ID, "Player 2", receiverID, "Receiver", TradeItemGoldOre, 100)
+			errs <- err
+		}()
+	}
+	sent := 0
+	for i := 0; i < senders; i++ {
+		if err := <-errs; err == nil {
+			sent++
+		}
+	}
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 900, goldOre(player2ID))
+
+	// Test the sweeper expiring a trade and refunding the sender
+	pending, err = tradeService.GetPendingTrades(ctx, receiverID)
+	require.NoError(t, err, "Failed to get pending trades")
+	require.Len(t, pending, 1)
+	_, _, err = tradeService.storage.FindOneAndUpdate(ctx, pending[0].ID, func(tr *Trade) (*Trade, error) {
+		tr.ExpiresAt = time.Now().Add(-time.Second)
+		return tr, nil
+	})
+	require.NoError(t, err, "Failed to backdate trade expiry")
+
+	_, err = tradeService.AcceptTrade(ctx, pending[0].ID, receiverID)
+	assert.ErrorContains(t, err, "trade has expired")
+
+	sweepCtx, stopSweeper := context.WithCancel(ctx)
+	defer stopSweeper()
+	tradeService.StartSweeper(sweepCtx, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		expired, err := tradeService.GetTrade(ctx, pending[0].ID)
+		return err == nil && expired.Status == TradeStatusExpired
+	}, 5*time.Second, 10*time.Millisecond, "Trade was not expired")
+	assert.Equal(t, 1000, goldOre(player2ID))
+
+	ledger, err = tradeService.GetLedger(ctx, player2ID)
+	require.NoError(t, err, "Failed to get ledger")
+	require.Len(t, ledger, 2)
+	assert.Equal(t, ledgerReasonRefund, ledger[0].Reason)
+	assert.Equal(t, ledgerReasonEscrow, ledger[1].Reason)
+}
+
+// TestGRPCServices tests calling the services through gRPC clients
+func TestGRPCServices(t *testing.T) {
+	// Set up services
+	mineService, ticketService, transportService, cleanup := setupTestServices(t)
+	defer cleanup()
+	tradeService := setupTestTradeService(t, ticketService, transportService)
+
+	// Serve them on an in-memory listener
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterGRPCServices(server, mineService, transportService, ticketService, tradeService)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "Failed to create gRPC client")
+	defer conn.Close()
+
+	mines := transportpb.NewMineServiceClient(conn)
+	transports := transportpb.NewTransportServiceClient(conn)
+	tickets := transportpb.NewTicketServiceClient(conn)
+	trades := transportpb.NewTradeServiceClient(conn)
+
+	ctx := context.Background()
+	allianceID := primitive.NewObjectID()
+	playerID := primitive.NewObjectID()
+
+	// Mines
+	_, err = mineService.CreateOrUpdateMineConfig(ctx, 1, 100, 500, 30, 4)
+	require.NoError(t, err, "Failed to create mine config")
+
+	created, err := mines.CreateMine(ctx, &transportpb.CreateMineRequest{AllianceId: allianceID.Hex(), Name: "Test Mine", Level: 1})
+	require.NoError(t, err, "Failed to create mine")
+	assert.Equal(t, allianceID.Hex(), created.Mine.AllianceId)
+	assert.Equal(t, string(MineStatusUndeveloped), created.Mine.Status)
+	assert.Nil(t, created.Mine.Contest)
+
+	got, err := mines.GetMine(ctx, &transportpb.GetMineRequest{MineId: created.Mine.Id})
+	require.NoError(t, err, "Failed to get mine")
+	assert.Equal(t, "Test Mine", got.Mine.Name)
+
+	byAlliance, err := mines.GetMinesByAlliance(ctx, &transportpb.GetMinesByAllianceRequest{AllianceId: allianceID.Hex()})
+	require.NoError(t, err, "Failed to get mines by alliance")
+	require.Len(t, byAlliance.Mines, 1)
+	assert.Equal(t, created.Mine.Id, byAlliance.Mines[0].Id)
+
+	config, err := mines.GetMineConfig(ctx, &transportpb.GetMineConfigRequest{Level: 1})
+	require.NoError(t, err, "Failed to get mine config")
+	assert.Equal(t, int64(30), config.Config.TransportTimeMinutes)
+
+	// Errors carry status codes
+	_, err = mines.GetMine(ctx, &transportpb.GetMineRequest{MineId: primitive.NewObjectID().Hex()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = mines.GetMine(ctx, &transportpb.GetMineRequest{MineId: "not-an-id"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = transports.StartTransport(ctx, &transportpb.StartTransportRequest{
+		PlayerId:      playerID.Hex(),
+		PlayerName:    "Player",
+		MineId:        created.Mine.Id,
+		GoldOreAmount: -100,
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Tickets
+	ticket, err := tickets.GetOrCreateTickets(ctx, &transportpb.GetOrCreateTicketsRequest{
+		PlayerId:   playerID.Hex(),
+		AllianceId: allianceID.Hex(),
+		MaxTickets: 5,
+	})
+	require.NoError(t, err, "Failed to get tickets")
+	assert.Equal(t, int32(5), ticket.Ticket.MaxTickets)
+
+	byAllianceTickets, err := tickets.GetTicketsByAlliance(ctx, &transportpb.GetTicketsByAllianceRequest{AllianceId: allianceID.Hex()})
+	require.NoError(t, err, "Failed to get tickets by alliance")
+	require.Len(t, byAllianceTickets.Tickets, 1)
+	assert.Equal(t, playerID.Hex(), byAllianceTickets.Tickets[0].PlayerId)
+
+	// Transports
+	active, err := transports.GetActiveTransports(ctx, &transportpb.GetActiveTransportsRequest{AllianceId: allianceID.Hex()})
+	require.NoError(t, err, "Failed to get active transports")
+	assert.Empty(t, active.Transports)
+
+	// Trades
+	receiverID := primitive.NewObjectID()
+	_, err = ticketService.GetOrCreateTickets(ctx, receiverID, allianceID, 5)
+	require.NoError(t, err, "Failed to create tickets")
+	_, err = tradeService.alliances.FoundAlliance(ctx, allianceID, playerID, "Player")
+	require.NoError(t, err, "Failed to found alliance")
+	_, err = tradeService.alliances.AddMember(ctx, allianceID, playerID, receiverID, "Receiver")
+	require.NoError(t, err, "Failed to add member")
+
+	sent, err := trades.SendTrade(ctx, &transportpb.SendTradeRequest{
+		AllianceId:   allianceID.Hex(),
+		SenderId:     playerID.Hex(),
+		SenderName:   "Player",
+		ReceiverId:   receiverID.Hex(),
+		ReceiverName: "Receiver",
+		ItemType:     string(TradeItemTicket),
+		Amount:       2,
+	})
+	require.NoError(t, err, "Failed to send trade")
+	assert.Equal(t, string(TradeStatusPending), sent.Trade.Status)
+	assert.Nil(t, sent.Trade.ResolvedAt)
+
+	pendingTrades, err := trades.GetPendingTrades(ctx, &transportpb.GetPendingTradesRequest{PlayerId: receiverID.Hex()})
+	require.NoError(t, err, "Failed to get pending trades")
+	require.Len(t, pendingTrades.Trades, 1)
+
+	declined, err := trades.DeclineTrade(ctx, &transportpb.ResolveTradeRequest{TradeId: sent.Trade.Id, PlayerId: receiverID.Hex()})
+	require.NoError(t, err, "Failed to decline trade")
+	assert.Equal(t, string(TradeStatusDeclined), declined.Trade.Status)
+
+	ledger, err := trades.GetTradeLedger(ctx, &transportpb.GetTradeLedgerRequest{PlayerId: playerID.Hex()})
+	require.NoError(t, err, "Failed to get trade ledger")
+	require.Len(t, ledger.Entries, 2)
+	assert.Equal(t, int64(5), ledger.Entries[0].Balance)
+
+	_, err = trades.CancelTrade(ctx, &transportpb.ResolveTradeRequest{TradeId: "not-an-id", PlayerId: playerID.Hex()})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestGRPCConversions tests the conversions between the services and their gRPC messages
+func TestGRPCConversions(t *testing.T) {
+	// Domain errors map to status codes
+	assert.Equal(t, codes.NotFound, status.Code(grpcError(nodestorage.ErrNotFound)))
+	assert.Equal(t, codes.PermissionDenied, status.Code(grpcError(&CheatViolation{Message: "gold ore amount cannot be negative"})))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(grpcError(context.DeadlineExceeded)))
+	err := grpcError(errors.New("mine is not active"))
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.Equal(t, "mine is not active", status.Convert(err).Message())
+
+	// IDs
+	id := primitive.NewObjectID()
+	parsed, err := parseGRPCID("mine_id", id.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+	_, err = parseGRPCID("mine_id", "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Combat orders
+	_, err = fromPBCombatOrder(nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	order, err := fromPBCombatOrder(&transportpb.CombatOrder{
+		PlayerId:   id.Hex(),
+		PlayerName: "Player",
+		AllianceId: id.Hex(),
+		GeneralIds: []string{id.Hex()},
+		Troops:     300,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []primitive.ObjectID{id}, order.GeneralIDs)
+	assert.Equal(t, 300, order.Troops)
+
+	// Optional fields stay unset
+	now := time.Now()
+	transport := toPBTransport(&Transport{ID: id, TransportTime: 30 * time.Minute, PrepStartTime: now})
+	assert.Nil(t, transport.StartTime)
+	assert.Nil(t, transport.RaidStatus)
+	assert.Nil(t, transport.PrepEndTime)
+	assert.Equal(t, now.UnixNano(), transport.PrepStartTime.AsTime().UnixNano())
+	assert.Equal(t, 30*time.Minute, transport.TransportTime.AsDuration())
+
+	// Idempotency keys arrive as metadata
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyMetadata, "retry-1"))
+	assert.Equal(t, "retry-1", IdempotencyKeyFromContext(grpcContext(ctx)))
+	assert.Equal(t, "", IdempotencyKeyFromContext(grpcContext(context.Background())))
 }
 
 // TestTransportScenario tests a complete transport scenario
@@ -0,0 +1,7 @@
+// Package transportpb holds the messages and services of the transport gRPC API
+//
+// After changing transport.proto, install protoc, protoc-gen-go and protoc-gen-go-grpc and run
+// go generate in this directory to regenerate the code.
+package transportpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative transport.proto
@@ -0,0 +1,4386 @@
+// transport gRPC API
+//
+// Lets the servers that don't access MongoDB themselves (the match server, the social server)
+// develop mines, send out transports, manage tickets and trade between alliance members through
+// the transport server.
+// IDs are hex ObjectIDs; statuses, rarities and outcomes carry the values of the domain constants.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: transport.proto
+
+package transportpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Mine mirrors transport.Mine
+type Mine struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AllianceId        string                 `protobuf:"bytes,2,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	Name              string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Level             int32                  `protobuf:"varint,4,opt,name=level,proto3" json:"level,omitempty"`
+	GoldOre           int64                  `protobuf:"varint,5,opt,name=gold_ore,json=goldOre,proto3" json:"gold_ore,omitempty"`
+	Status            string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	DevelopmentPoints float64                `protobuf:"fixed64,7,opt,name=development_points,json=developmentPoints,proto3" json:"development_points,omitempty"`
+	RequiredPoints    float64                `protobuf:"fixed64,8,opt,name=required_points,json=requiredPoints,proto3" json:"required_points,omitempty"`
+	AssignedGenerals  []*AssignedGeneral     `protobuf:"bytes,9,rep,name=assigned_generals,json=assignedGenerals,proto3" json:"assigned_generals,omitempty"`
+	LastUpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_updated_at,json=lastUpdatedAt,proto3" json:"last_updated_at,omitempty"`
+	// contest is unset if the mine was never attacked
+	Contest       *MineContest           `protobuf:"bytes,11,opt,name=contest,proto3" json:"contest,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	VectorClock   int64                  `protobuf:"varint,14,opt,name=vector_clock,json=vectorClock,proto3" json:"vector_clock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mine) Reset() {
+	*x = Mine{}
+	mi := &file_transport_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mine) ProtoMessage() {}
+
+func (x *Mine) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mine.ProtoReflect.Descriptor instead.
+func (*Mine) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Mine) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Mine) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *Mine) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Mine) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *Mine) GetGoldOre() int64 {
+	if x != nil {
+		return x.GoldOre
+	}
+	return 0
+}
+
+func (x *Mine) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Mine) GetDevelopmentPoints() float64 {
+	if x != nil {
+		return x.DevelopmentPoints
+	}
+	return 0
+}
+
+func (x *Mine) GetRequiredPoints() float64 {
+	if x != nil {
+		return x.RequiredPoints
+	}
+	return 0
+}
+
+func (x *Mine) GetAssignedGenerals() []*AssignedGeneral {
+	if x != nil {
+		return x.AssignedGenerals
+	}
+	return nil
+}
+
+func (x *Mine) GetLastUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdatedAt
+	}
+	return nil
+}
+
+func (x *Mine) GetContest() *MineContest {
+	if x != nil {
+		return x.Contest
+	}
+	return nil
+}
+
+func (x *Mine) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Mine) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Mine) GetVectorClock() int64 {
+	if x != nil {
+		return x.VectorClock
+	}
+	return 0
+}
+
+// AssignedGeneral mirrors transport.AssignedGeneral
+type AssignedGeneral struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId         string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName       string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	GeneralId        string                 `protobuf:"bytes,3,opt,name=general_id,json=generalId,proto3" json:"general_id,omitempty"`
+	GeneralName      string                 `protobuf:"bytes,4,opt,name=general_name,json=generalName,proto3" json:"general_name,omitempty"`
+	Level            int32                  `protobuf:"varint,5,opt,name=level,proto3" json:"level,omitempty"`
+	Stars            int32                  `protobuf:"varint,6,opt,name=stars,proto3" json:"stars,omitempty"`
+	Rarity           string                 `protobuf:"bytes,7,opt,name=rarity,proto3" json:"rarity,omitempty"`
+	AssignedAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=assigned_at,json=assignedAt,proto3" json:"assigned_at,omitempty"`
+	ContributionRate float64                `protobuf:"fixed64,9,opt,name=contribution_rate,json=contributionRate,proto3" json:"contribution_rate,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AssignedGeneral) Reset() {
+	*x = AssignedGeneral{}
+	mi := &file_transport_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignedGeneral) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignedGeneral) ProtoMessage() {}
+
+func (x *AssignedGeneral) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignedGeneral.ProtoReflect.Descriptor instead.
+func (*AssignedGeneral) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AssignedGeneral) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *AssignedGeneral) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *AssignedGeneral) GetGeneralId() string {
+	if x != nil {
+		return x.GeneralId
+	}
+	return ""
+}
+
+func (x *AssignedGeneral) GetGeneralName() string {
+	if x != nil {
+		return x.GeneralName
+	}
+	return ""
+}
+
+func (x *AssignedGeneral) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *AssignedGeneral) GetStars() int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *AssignedGeneral) GetRarity() string {
+	if x != nil {
+		return x.Rarity
+	}
+	return ""
+}
+
+func (x *AssignedGeneral) GetAssignedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AssignedAt
+	}
+	return nil
+}
+
+func (x *AssignedGeneral) GetContributionRate() float64 {
+	if x != nil {
+		return x.ContributionRate
+	}
+	return 0
+}
+
+// MineContest mirrors transport.MineContest
+type MineContest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AttackerAllianceId string                 `protobuf:"bytes,1,opt,name=attacker_alliance_id,json=attackerAllianceId,proto3" json:"attacker_alliance_id,omitempty"`
+	Attacker           *CombatForce           `protobuf:"bytes,2,opt,name=attacker,proto3" json:"attacker,omitempty"`
+	// defender is unset until an alliance member defends
+	Defender        *CombatForce           `protobuf:"bytes,3,opt,name=defender,proto3" json:"defender,omitempty"`
+	Seed            int64                  `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	PreviousStatus  string                 `protobuf:"bytes,5,opt,name=previous_status,json=previousStatus,proto3" json:"previous_status,omitempty"`
+	AttackStartTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=attack_start_time,json=attackStartTime,proto3" json:"attack_start_time,omitempty"`
+	DefenseEndTime  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=defense_end_time,json=defenseEndTime,proto3" json:"defense_end_time,omitempty"`
+	// outcome is empty until the battle is resolved
+	Outcome         string                 `protobuf:"bytes,8,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	Rounds          []*BattleRound         `protobuf:"bytes,9,rep,name=rounds,proto3" json:"rounds,omitempty"`
+	DevelopmentLost float64                `protobuf:"fixed64,10,opt,name=development_lost,json=developmentLost,proto3" json:"development_lost,omitempty"`
+	ResolvedAt      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=resolved_at,json=resolvedAt,proto3" json:"resolved_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *MineContest) Reset() {
+	*x = MineContest{}
+	mi := &file_transport_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MineContest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MineContest) ProtoMessage() {}
+
+func (x *MineContest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MineContest.ProtoReflect.Descriptor instead.
+func (*MineContest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MineContest) GetAttackerAllianceId() string {
+	if x != nil {
+		return x.AttackerAllianceId
+	}
+	return ""
+}
+
+func (x *MineContest) GetAttacker() *CombatForce {
+	if x != nil {
+		return x.Attacker
+	}
+	return nil
+}
+
+func (x *MineContest) GetDefender() *CombatForce {
+	if x != nil {
+		return x.Defender
+	}
+	return nil
+}
+
+func (x *MineContest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *MineContest) GetPreviousStatus() string {
+	if x != nil {
+		return x.PreviousStatus
+	}
+	return ""
+}
+
+func (x *MineContest) GetAttackStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AttackStartTime
+	}
+	return nil
+}
+
+func (x *MineContest) GetDefenseEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DefenseEndTime
+	}
+	return nil
+}
+
+func (x *MineContest) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *MineContest) GetRounds() []*BattleRound {
+	if x != nil {
+		return x.Rounds
+	}
+	return nil
+}
+
+func (x *MineContest) GetDevelopmentLost() float64 {
+	if x != nil {
+		return x.DevelopmentLost
+	}
+	return 0
+}
+
+func (x *MineContest) GetResolvedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResolvedAt
+	}
+	return nil
+}
+
+// MineConfig mirrors transport.MineConfig
+type MineConfig struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Level              int32                  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	MinTransportAmount int64                  `protobuf:"varint,3,opt,name=min_transport_amount,json=minTransportAmount,proto3" json:"min_transport_amount,omitempty"`
+	MaxTransportAmount int64                  `protobuf:"varint,4,opt,name=max_transport_amount,json=maxTransportAmount,proto3" json:"max_transport_amount,omitempty"`
+	// transport_time_minutes how long a transport from a mine of this level takes
+	TransportTimeMinutes int64   `protobuf:"varint,5,opt,name=transport_time_minutes,json=transportTimeMinutes,proto3" json:"transport_time_minutes,omitempty"`
+	MaxParticipants      int32   `protobuf:"varint,6,opt,name=max_participants,json=maxParticipants,proto3" json:"max_participants,omitempty"`
+	RequiredPoints       float64 `protobuf:"fixed64,7,opt,name=required_points,json=requiredPoints,proto3" json:"required_points,omitempty"`
+	TransportTicketMax   int32   `protobuf:"varint,8,opt,name=transport_ticket_max,json=transportTicketMax,proto3" json:"transport_ticket_max,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *MineConfig) Reset() {
+	*x = MineConfig{}
+	mi := &file_transport_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MineConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MineConfig) ProtoMessage() {}
+
+func (x *MineConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MineConfig.ProtoReflect.Descriptor instead.
+func (*MineConfig) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MineConfig) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MineConfig) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *MineConfig) GetMinTransportAmount() int64 {
+	if x != nil {
+		return x.MinTransportAmount
+	}
+	return 0
+}
+
+func (x *MineConfig) GetMaxTransportAmount() int64 {
+	if x != nil {
+		return x.MaxTransportAmount
+	}
+	return 0
+}
+
+func (x *MineConfig) GetTransportTimeMinutes() int64 {
+	if x != nil {
+		return x.TransportTimeMinutes
+	}
+	return 0
+}
+
+func (x *MineConfig) GetMaxParticipants() int32 {
+	if x != nil {
+		return x.MaxParticipants
+	}
+	return 0
+}
+
+func (x *MineConfig) GetRequiredPoints() float64 {
+	if x != nil {
+		return x.RequiredPoints
+	}
+	return 0
+}
+
+func (x *MineConfig) GetTransportTicketMax() int32 {
+	if x != nil {
+		return x.TransportTicketMax
+	}
+	return 0
+}
+
+// CombatOrder mirrors transport.CombatOrder
+type CombatOrder struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId   string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	AllianceId string                 `protobuf:"bytes,3,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	// general_ids up to 3 generals leading the troops
+	GeneralIds    []string `protobuf:"bytes,4,rep,name=general_ids,json=generalIds,proto3" json:"general_ids,omitempty"`
+	Troops        int64    `protobuf:"varint,5,opt,name=troops,proto3" json:"troops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CombatOrder) Reset() {
+	*x = CombatOrder{}
+	mi := &file_transport_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CombatOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CombatOrder) ProtoMessage() {}
+
+func (x *CombatOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CombatOrder.ProtoReflect.Descriptor instead.
+func (*CombatOrder) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CombatOrder) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *CombatOrder) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *CombatOrder) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *CombatOrder) GetGeneralIds() []string {
+	if x != nil {
+		return x.GeneralIds
+	}
+	return nil
+}
+
+func (x *CombatOrder) GetTroops() int64 {
+	if x != nil {
+		return x.Troops
+	}
+	return 0
+}
+
+// CombatForce mirrors transport.CombatForce
+type CombatForce struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	AllianceId    string                 `protobuf:"bytes,3,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	Generals      []*CombatGeneral       `protobuf:"bytes,4,rep,name=generals,proto3" json:"generals,omitempty"`
+	Troops        int64                  `protobuf:"varint,5,opt,name=troops,proto3" json:"troops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CombatForce) Reset() {
+	*x = CombatForce{}
+	mi := &file_transport_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CombatForce) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CombatForce) ProtoMessage() {}
+
+func (x *CombatForce) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CombatForce.ProtoReflect.Descriptor instead.
+func (*CombatForce) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CombatForce) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *CombatForce) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *CombatForce) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *CombatForce) GetGenerals() []*CombatGeneral {
+	if x != nil {
+		return x.Generals
+	}
+	return nil
+}
+
+func (x *CombatForce) GetTroops() int64 {
+	if x != nil {
+		return x.Troops
+	}
+	return 0
+}
+
+// CombatGeneral mirrors transport.CombatGeneral
+type CombatGeneral struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GeneralId     string                 `protobuf:"bytes,1,opt,name=general_id,json=generalId,proto3" json:"general_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Level         int32                  `protobuf:"varint,3,opt,name=level,proto3" json:"level,omitempty"`
+	Stars         int32                  `protobuf:"varint,4,opt,name=stars,proto3" json:"stars,omitempty"`
+	Rarity        string                 `protobuf:"bytes,5,opt,name=rarity,proto3" json:"rarity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CombatGeneral) Reset() {
+	*x = CombatGeneral{}
+	mi := &file_transport_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CombatGeneral) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CombatGeneral) ProtoMessage() {}
+
+func (x *CombatGeneral) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CombatGeneral.ProtoReflect.Descriptor instead.
+func (*CombatGeneral) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CombatGeneral) GetGeneralId() string {
+	if x != nil {
+		return x.GeneralId
+	}
+	return ""
+}
+
+func (x *CombatGeneral) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CombatGeneral) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *CombatGeneral) GetStars() int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *CombatGeneral) GetRarity() string {
+	if x != nil {
+		return x.Rarity
+	}
+	return ""
+}
+
+// BattleRound mirrors transport.BattleRound
+type BattleRound struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Round          int32                  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	AttackerDamage int64                  `protobuf:"varint,2,opt,name=attacker_damage,json=attackerDamage,proto3" json:"attacker_damage,omitempty"`
+	DefenderDamage int64                  `protobuf:"varint,3,opt,name=defender_damage,json=defenderDamage,proto3" json:"defender_damage,omitempty"`
+	AttackerTroops int64                  `protobuf:"varint,4,opt,name=attacker_troops,json=attackerTroops,proto3" json:"attacker_troops,omitempty"`
+	DefenderTroops int64                  `protobuf:"varint,5,opt,name=defender_troops,json=defenderTroops,proto3" json:"defender_troops,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BattleRound) Reset() {
+	*x = BattleRound{}
+	mi := &file_transport_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleRound) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleRound) ProtoMessage() {}
+
+func (x *BattleRound) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleRound.ProtoReflect.Descriptor instead.
+func (*BattleRound) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BattleRound) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *BattleRound) GetAttackerDamage() int64 {
+	if x != nil {
+		return x.AttackerDamage
+	}
+	return 0
+}
+
+func (x *BattleRound) GetDefenderDamage() int64 {
+	if x != nil {
+		return x.DefenderDamage
+	}
+	return 0
+}
+
+func (x *BattleRound) GetAttackerTroops() int64 {
+	if x != nil {
+		return x.AttackerTroops
+	}
+	return 0
+}
+
+func (x *BattleRound) GetDefenderTroops() int64 {
+	if x != nil {
+		return x.DefenderTroops
+	}
+	return 0
+}
+
+// Transport mirrors transport.Transport
+type Transport struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AllianceId      string                 `protobuf:"bytes,2,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	MineId          string                 `protobuf:"bytes,3,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	MineName        string                 `protobuf:"bytes,4,opt,name=mine_name,json=mineName,proto3" json:"mine_name,omitempty"`
+	MineLevel       int32                  `protobuf:"varint,5,opt,name=mine_level,json=mineLevel,proto3" json:"mine_level,omitempty"`
+	Status          string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	GoldOreAmount   int64                  `protobuf:"varint,7,opt,name=gold_ore_amount,json=goldOreAmount,proto3" json:"gold_ore_amount,omitempty"`
+	MaxParticipants int32                  `protobuf:"varint,8,opt,name=max_participants,json=maxParticipants,proto3" json:"max_participants,omitempty"`
+	Participants    []*TransportMember     `protobuf:"bytes,9,rep,name=participants,proto3" json:"participants,omitempty"`
+	PrepStartTime   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=prep_start_time,json=prepStartTime,proto3" json:"prep_start_time,omitempty"`
+	PrepEndTime     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=prep_end_time,json=prepEndTime,proto3" json:"prep_end_time,omitempty"`
+	TransportTime   *durationpb.Duration   `protobuf:"bytes,12,opt,name=transport_time,json=transportTime,proto3" json:"transport_time,omitempty"`
+	StartTime       *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime         *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// raid_status is unset if the transport was never raided
+	RaidStatus    *RaidStatus            `protobuf:"bytes,15,opt,name=raid_status,json=raidStatus,proto3" json:"raid_status,omitempty"`
+	ArrivedAt     *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=arrived_at,json=arrivedAt,proto3" json:"arrived_at,omitempty"`
+	Rewards       []*TransportReward     `protobuf:"bytes,17,rep,name=rewards,proto3" json:"rewards,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	VectorClock   int64                  `protobuf:"varint,20,opt,name=vector_clock,json=vectorClock,proto3" json:"vector_clock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transport) Reset() {
+	*x = Transport{}
+	mi := &file_transport_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transport) ProtoMessage() {}
+
+func (x *Transport) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transport.ProtoReflect.Descriptor instead.
+func (*Transport) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Transport) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Transport) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *Transport) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+func (x *Transport) GetMineName() string {
+	if x != nil {
+		return x.MineName
+	}
+	return ""
+}
+
+func (x *Transport) GetMineLevel() int32 {
+	if x != nil {
+		return x.MineLevel
+	}
+	return 0
+}
+
+func (x *Transport) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Transport) GetGoldOreAmount() int64 {
+	if x != nil {
+		return x.GoldOreAmount
+	}
+	return 0
+}
+
+func (x *Transport) GetMaxParticipants() int32 {
+	if x != nil {
+		return x.MaxParticipants
+	}
+	return 0
+}
+
+func (x *Transport) GetParticipants() []*TransportMember {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+func (x *Transport) GetPrepStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PrepStartTime
+	}
+	return nil
+}
+
+func (x *Transport) GetPrepEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PrepEndTime
+	}
+	return nil
+}
+
+func (x *Transport) GetTransportTime() *durationpb.Duration {
+	if x != nil {
+		return x.TransportTime
+	}
+	return nil
+}
+
+func (x *Transport) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Transport) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *Transport) GetRaidStatus() *RaidStatus {
+	if x != nil {
+		return x.RaidStatus
+	}
+	return nil
+}
+
+func (x *Transport) GetArrivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArrivedAt
+	}
+	return nil
+}
+
+func (x *Transport) GetRewards() []*TransportReward {
+	if x != nil {
+		return x.Rewards
+	}
+	return nil
+}
+
+func (x *Transport) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Transport) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Transport) GetVectorClock() int64 {
+	if x != nil {
+		return x.VectorClock
+	}
+	return 0
+}
+
+// TransportMember mirrors transport.TransportMember
+type TransportMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	GoldOreAmount int64                  `protobuf:"varint,3,opt,name=gold_ore_amount,json=goldOreAmount,proto3" json:"gold_ore_amount,omitempty"`
+	JoinedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransportMember) Reset() {
+	*x = TransportMember{}
+	mi := &file_transport_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransportMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportMember) ProtoMessage() {}
+
+func (x *TransportMember) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportMember.ProtoReflect.Descriptor instead.
+func (*TransportMember) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TransportMember) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *TransportMember) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *TransportMember) GetGoldOreAmount() int64 {
+	if x != nil {
+		return x.GoldOreAmount
+	}
+	return 0
+}
+
+func (x *TransportMember) GetJoinedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.JoinedAt
+	}
+	return nil
+}
+
+// RaidStatus mirrors transport.RaidStatus
+type RaidStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	RaiderId       string                 `protobuf:"bytes,1,opt,name=raider_id,json=raiderId,proto3" json:"raider_id,omitempty"`
+	RaiderName     string                 `protobuf:"bytes,2,opt,name=raider_name,json=raiderName,proto3" json:"raider_name,omitempty"`
+	RaidStartTime  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=raid_start_time,json=raidStartTime,proto3" json:"raid_start_time,omitempty"`
+	DefenseEndTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=defense_end_time,json=defenseEndTime,proto3" json:"defense_end_time,omitempty"`
+	IsDefended     bool                   `protobuf:"varint,5,opt,name=is_defended,json=isDefended,proto3" json:"is_defended,omitempty"`
+	// defense_result is unset until the raid is resolved
+	DefenseResult *DefenseResult `protobuf:"bytes,6,opt,name=defense_result,json=defenseResult,proto3" json:"defense_result,omitempty"`
+	Attacker      *CombatForce   `protobuf:"bytes,7,opt,name=attacker,proto3" json:"attacker,omitempty"`
+	Seed          int64          `protobuf:"varint,8,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RaidStatus) Reset() {
+	*x = RaidStatus{}
+	mi := &file_transport_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RaidStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RaidStatus) ProtoMessage() {}
+
+func (x *RaidStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RaidStatus.ProtoReflect.Descriptor instead.
+func (*RaidStatus) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RaidStatus) GetRaiderId() string {
+	if x != nil {
+		return x.RaiderId
+	}
+	return ""
+}
+
+func (x *RaidStatus) GetRaiderName() string {
+	if x != nil {
+		return x.RaiderName
+	}
+	return ""
+}
+
+func (x *RaidStatus) GetRaidStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RaidStartTime
+	}
+	return nil
+}
+
+func (x *RaidStatus) GetDefenseEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DefenseEndTime
+	}
+	return nil
+}
+
+func (x *RaidStatus) GetIsDefended() bool {
+	if x != nil {
+		return x.IsDefended
+	}
+	return false
+}
+
+func (x *RaidStatus) GetDefenseResult() *DefenseResult {
+	if x != nil {
+		return x.DefenseResult
+	}
+	return nil
+}
+
+func (x *RaidStatus) GetAttacker() *CombatForce {
+	if x != nil {
+		return x.Attacker
+	}
+	return nil
+}
+
+func (x *RaidStatus) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// DefenseResult mirrors transport.DefenseResult
+type DefenseResult struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Successful     bool                   `protobuf:"varint,1,opt,name=successful,proto3" json:"successful,omitempty"`
+	DefenderId     string                 `protobuf:"bytes,2,opt,name=defender_id,json=defenderId,proto3" json:"defender_id,omitempty"`
+	DefenderName   string                 `protobuf:"bytes,3,opt,name=defender_name,json=defenderName,proto3" json:"defender_name,omitempty"`
+	CompletedAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	GoldOreLost    int64                  `protobuf:"varint,5,opt,name=gold_ore_lost,json=goldOreLost,proto3" json:"gold_ore_lost,omitempty"`
+	GoldOreStolen  int64                  `protobuf:"varint,6,opt,name=gold_ore_stolen,json=goldOreStolen,proto3" json:"gold_ore_stolen,omitempty"`
+	BattleReportId string                 `protobuf:"bytes,7,opt,name=battle_report_id,json=battleReportId,proto3" json:"battle_report_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DefenseResult) Reset() {
+	*x = DefenseResult{}
+	mi := &file_transport_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DefenseResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DefenseResult) ProtoMessage() {}
+
+func (x *DefenseResult) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DefenseResult.ProtoReflect.Descriptor instead.
+func (*DefenseResult) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DefenseResult) GetSuccessful() bool {
+	if x != nil {
+		return x.Successful
+	}
+	return false
+}
+
+func (x *DefenseResult) GetDefenderId() string {
+	if x != nil {
+		return x.DefenderId
+	}
+	return ""
+}
+
+func (x *DefenseResult) GetDefenderName() string {
+	if x != nil {
+		return x.DefenderName
+	}
+	return ""
+}
+
+func (x *DefenseResult) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *DefenseResult) GetGoldOreLost() int64 {
+	if x != nil {
+		return x.GoldOreLost
+	}
+	return 0
+}
+
+func (x *DefenseResult) GetGoldOreStolen() int64 {
+	if x != nil {
+		return x.GoldOreStolen
+	}
+	return 0
+}
+
+func (x *DefenseResult) GetBattleReportId() string {
+	if x != nil {
+		return x.BattleReportId
+	}
+	return ""
+}
+
+// TransportReward mirrors transport.TransportReward
+type TransportReward struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	GoldOre       int64                  `protobuf:"varint,3,opt,name=gold_ore,json=goldOre,proto3" json:"gold_ore,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransportReward) Reset() {
+	*x = TransportReward{}
+	mi := &file_transport_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransportReward) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportReward) ProtoMessage() {}
+
+func (x *TransportReward) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportReward.ProtoReflect.Descriptor instead.
+func (*TransportReward) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TransportReward) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *TransportReward) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *TransportReward) GetGoldOre() int64 {
+	if x != nil {
+		return x.GoldOre
+	}
+	return 0
+}
+
+// BattleReport mirrors transport.BattleReport
+type BattleReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TransportId   string                 `protobuf:"bytes,2,opt,name=transport_id,json=transportId,proto3" json:"transport_id,omitempty"`
+	AllianceId    string                 `protobuf:"bytes,3,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	Attacker      *CombatForce           `protobuf:"bytes,4,opt,name=attacker,proto3" json:"attacker,omitempty"`
+	Defender      *CombatForce           `protobuf:"bytes,5,opt,name=defender,proto3" json:"defender,omitempty"`
+	Defended      bool                   `protobuf:"varint,6,opt,name=defended,proto3" json:"defended,omitempty"`
+	Seed          int64                  `protobuf:"varint,7,opt,name=seed,proto3" json:"seed,omitempty"`
+	Rounds        []*BattleRound         `protobuf:"bytes,8,rep,name=rounds,proto3" json:"rounds,omitempty"`
+	Outcome       string                 `protobuf:"bytes,9,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	GoldOreBefore int64                  `protobuf:"varint,10,opt,name=gold_ore_before,json=goldOreBefore,proto3" json:"gold_ore_before,omitempty"`
+	GoldOreLost   int64                  `protobuf:"varint,11,opt,name=gold_ore_lost,json=goldOreLost,proto3" json:"gold_ore_lost,omitempty"`
+	GoldOreStolen int64                  `protobuf:"varint,12,opt,name=gold_ore_stolen,json=goldOreStolen,proto3" json:"gold_ore_stolen,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleReport) Reset() {
+	*x = BattleReport{}
+	mi := &file_transport_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleReport) ProtoMessage() {}
+
+func (x *BattleReport) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleReport.ProtoReflect.Descriptor instead.
+func (*BattleReport) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BattleReport) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BattleReport) GetTransportId() string {
+	if x != nil {
+		return x.TransportId
+	}
+	return ""
+}
+
+func (x *BattleReport) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *BattleReport) GetAttacker() *CombatForce {
+	if x != nil {
+		return x.Attacker
+	}
+	return nil
+}
+
+func (x *BattleReport) GetDefender() *CombatForce {
+	if x != nil {
+		return x.Defender
+	}
+	return nil
+}
+
+func (x *BattleReport) GetDefended() bool {
+	if x != nil {
+		return x.Defended
+	}
+	return false
+}
+
+func (x *BattleReport) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *BattleReport) GetRounds() []*BattleRound {
+	if x != nil {
+		return x.Rounds
+	}
+	return nil
+}
+
+func (x *BattleReport) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *BattleReport) GetGoldOreBefore() int64 {
+	if x != nil {
+		return x.GoldOreBefore
+	}
+	return 0
+}
+
+func (x *BattleReport) GetGoldOreLost() int64 {
+	if x != nil {
+		return x.GoldOreLost
+	}
+	return 0
+}
+
+func (x *BattleReport) GetGoldOreStolen() int64 {
+	if x != nil {
+		return x.GoldOreStolen
+	}
+	return 0
+}
+
+func (x *BattleReport) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// TransportTicket mirrors transport.TransportTicket
+type TransportTicket struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PlayerId       string                 `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	AllianceId     string                 `protobuf:"bytes,3,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	CurrentTickets int32                  `protobuf:"varint,4,opt,name=current_tickets,json=currentTickets,proto3" json:"current_tickets,omitempty"`
+	HeldTickets    int32                  `protobuf:"varint,5,opt,name=held_tickets,json=heldTickets,proto3" json:"held_tickets,omitempty"`
+	MaxTickets     int32                  `protobuf:"varint,6,opt,name=max_tickets,json=maxTickets,proto3" json:"max_tickets,omitempty"`
+	LastRefillTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_refill_time,json=lastRefillTime,proto3" json:"last_refill_time,omitempty"`
+	LastRegenTime  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_regen_time,json=lastRegenTime,proto3" json:"last_regen_time,omitempty"`
+	PurchaseCount  int32                  `protobuf:"varint,9,opt,name=purchase_count,json=purchaseCount,proto3" json:"purchase_count,omitempty"`
+	LastPurchaseAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_purchase_at,json=lastPurchaseAt,proto3" json:"last_purchase_at,omitempty"`
+	ResetTime      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=reset_time,json=resetTime,proto3" json:"reset_time,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	VectorClock    int64                  `protobuf:"varint,14,opt,name=vector_clock,json=vectorClock,proto3" json:"vector_clock,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TransportTicket) Reset() {
+	*x = TransportTicket{}
+	mi := &file_transport_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransportTicket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportTicket) ProtoMessage() {}
+
+func (x *TransportTicket) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportTicket.ProtoReflect.Descriptor instead.
+func (*TransportTicket) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TransportTicket) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransportTicket) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *TransportTicket) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *TransportTicket) GetCurrentTickets() int32 {
+	if x != nil {
+		return x.CurrentTickets
+	}
+	return 0
+}
+
+func (x *TransportTicket) GetHeldTickets() int32 {
+	if x != nil {
+		return x.HeldTickets
+	}
+	return 0
+}
+
+func (x *TransportTicket) GetMaxTickets() int32 {
+	if x != nil {
+		return x.MaxTickets
+	}
+	return 0
+}
+
+func (x *TransportTicket) GetLastRefillTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRefillTime
+	}
+	return nil
+}
+
+func (x *TransportTicket) GetLastRegenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRegenTime
+	}
+	return nil
+}
+
+func (x *TransportTicket) GetPurchaseCount() int32 {
+	if x != nil {
+		return x.PurchaseCount
+	}
+	return 0
+}
+
+func (x *TransportTicket) GetLastPurchaseAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastPurchaseAt
+	}
+	return nil
+}
+
+func (x *TransportTicket) GetResetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResetTime
+	}
+	return nil
+}
+
+func (x *TransportTicket) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *TransportTicket) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *TransportTicket) GetVectorClock() int64 {
+	if x != nil {
+		return x.VectorClock
+	}
+	return 0
+}
+
+// Trade mirrors transport.Trade
+type Trade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AllianceId    string                 `protobuf:"bytes,2,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	SenderId      string                 `protobuf:"bytes,3,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	SenderName    string                 `protobuf:"bytes,4,opt,name=sender_name,json=senderName,proto3" json:"sender_name,omitempty"`
+	ReceiverId    string                 `protobuf:"bytes,5,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	ReceiverName  string                 `protobuf:"bytes,6,opt,name=receiver_name,json=receiverName,proto3" json:"receiver_name,omitempty"`
+	ItemType      string                 `protobuf:"bytes,7,opt,name=item_type,json=itemType,proto3" json:"item_type,omitempty"`
+	Amount        int64                  `protobuf:"varint,8,opt,name=amount,proto3" json:"amount,omitempty"`
+	Status        string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	ResolvedAt    *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=resolved_at,json=resolvedAt,proto3" json:"resolved_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	VectorClock   int64                  `protobuf:"varint,14,opt,name=vector_clock,json=vectorClock,proto3" json:"vector_clock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trade) Reset() {
+	*x = Trade{}
+	mi := &file_transport_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trade) ProtoMessage() {}
+
+func (x *Trade) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trade.ProtoReflect.Descriptor instead.
+func (*Trade) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Trade) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Trade) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *Trade) GetSenderId() string {
+	if x != nil {
+		return x.SenderId
+	}
+	return ""
+}
+
+func (x *Trade) GetSenderName() string {
+	if x != nil {
+		return x.SenderName
+	}
+	return ""
+}
+
+func (x *Trade) GetReceiverId() string {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return ""
+}
+
+func (x *Trade) GetReceiverName() string {
+	if x != nil {
+		return x.ReceiverName
+	}
+	return ""
+}
+
+func (x *Trade) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *Trade) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Trade) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Trade) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Trade) GetResolvedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResolvedAt
+	}
+	return nil
+}
+
+func (x *Trade) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Trade) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Trade) GetVectorClock() int64 {
+	if x != nil {
+		return x.VectorClock
+	}
+	return 0
+}
+
+// TradeLedgerEntry mirrors transport.TradeLedgerEntry
+type TradeLedgerEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TradeId       string                 `protobuf:"bytes,2,opt,name=trade_id,json=tradeId,proto3" json:"trade_id,omitempty"`
+	PlayerId      string                 `protobuf:"bytes,3,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	AllianceId    string                 `protobuf:"bytes,4,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	ItemType      string                 `protobuf:"bytes,5,opt,name=item_type,json=itemType,proto3" json:"item_type,omitempty"`
+	Delta         int64                  `protobuf:"varint,6,opt,name=delta,proto3" json:"delta,omitempty"`
+	Balance       int64                  `protobuf:"varint,7,opt,name=balance,proto3" json:"balance,omitempty"`
+	Reason        string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TradeLedgerEntry) Reset() {
+	*x = TradeLedgerEntry{}
+	mi := &file_transport_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TradeLedgerEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TradeLedgerEntry) ProtoMessage() {}
+
+func (x *TradeLedgerEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TradeLedgerEntry.ProtoReflect.Descriptor instead.
+func (*TradeLedgerEntry) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TradeLedgerEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TradeLedgerEntry) GetTradeId() string {
+	if x != nil {
+		return x.TradeId
+	}
+	return ""
+}
+
+func (x *TradeLedgerEntry) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *TradeLedgerEntry) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *TradeLedgerEntry) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *TradeLedgerEntry) GetDelta() int64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *TradeLedgerEntry) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *TradeLedgerEntry) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *TradeLedgerEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateMineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AllianceId    string                 `protobuf:"bytes,1,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Level         int32                  `protobuf:"varint,3,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMineRequest) Reset() {
+	*x = CreateMineRequest{}
+	mi := &file_transport_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMineRequest) ProtoMessage() {}
+
+func (x *CreateMineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMineRequest.ProtoReflect.Descriptor instead.
+func (*CreateMineRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreateMineRequest) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *CreateMineRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateMineRequest) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+type GetMineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MineId        string                 `protobuf:"bytes,1,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMineRequest) Reset() {
+	*x = GetMineRequest{}
+	mi := &file_transport_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMineRequest) ProtoMessage() {}
+
+func (x *GetMineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMineRequest.ProtoReflect.Descriptor instead.
+func (*GetMineRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetMineRequest) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+type GetMinesByAllianceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AllianceId    string                 `protobuf:"bytes,1,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMinesByAllianceRequest) Reset() {
+	*x = GetMinesByAllianceRequest{}
+	mi := &file_transport_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMinesByAllianceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMinesByAllianceRequest) ProtoMessage() {}
+
+func (x *GetMinesByAllianceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMinesByAllianceRequest.ProtoReflect.Descriptor instead.
+func (*GetMinesByAllianceRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetMinesByAllianceRequest) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+type GetMineConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         int32                  `protobuf:"varint,1,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMineConfigRequest) Reset() {
+	*x = GetMineConfigRequest{}
+	mi := &file_transport_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMineConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMineConfigRequest) ProtoMessage() {}
+
+func (x *GetMineConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMineConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetMineConfigRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetMineConfigRequest) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+type AssignGeneralToMineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MineId        string                 `protobuf:"bytes,1,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	PlayerId      string                 `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,3,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	GeneralId     string                 `protobuf:"bytes,4,opt,name=general_id,json=generalId,proto3" json:"general_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignGeneralToMineRequest) Reset() {
+	*x = AssignGeneralToMineRequest{}
+	mi := &file_transport_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignGeneralToMineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignGeneralToMineRequest) ProtoMessage() {}
+
+func (x *AssignGeneralToMineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignGeneralToMineRequest.ProtoReflect.Descriptor instead.
+func (*AssignGeneralToMineRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AssignGeneralToMineRequest) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+func (x *AssignGeneralToMineRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *AssignGeneralToMineRequest) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *AssignGeneralToMineRequest) GetGeneralId() string {
+	if x != nil {
+		return x.GeneralId
+	}
+	return ""
+}
+
+type UnassignGeneralFromMineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MineId        string                 `protobuf:"bytes,1,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	PlayerId      string                 `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	GeneralId     string                 `protobuf:"bytes,3,opt,name=general_id,json=generalId,proto3" json:"general_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnassignGeneralFromMineRequest) Reset() {
+	*x = UnassignGeneralFromMineRequest{}
+	mi := &file_transport_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnassignGeneralFromMineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnassignGeneralFromMineRequest) ProtoMessage() {}
+
+func (x *UnassignGeneralFromMineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnassignGeneralFromMineRequest.ProtoReflect.Descriptor instead.
+func (*UnassignGeneralFromMineRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UnassignGeneralFromMineRequest) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+func (x *UnassignGeneralFromMineRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *UnassignGeneralFromMineRequest) GetGeneralId() string {
+	if x != nil {
+		return x.GeneralId
+	}
+	return ""
+}
+
+type UpdateMineDevelopmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MineId        string                 `protobuf:"bytes,1,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMineDevelopmentRequest) Reset() {
+	*x = UpdateMineDevelopmentRequest{}
+	mi := &file_transport_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMineDevelopmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMineDevelopmentRequest) ProtoMessage() {}
+
+func (x *UpdateMineDevelopmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMineDevelopmentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMineDevelopmentRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UpdateMineDevelopmentRequest) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+type ActivateMineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MineId        string                 `protobuf:"bytes,1,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	PlayerId      string                 `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActivateMineRequest) Reset() {
+	*x = ActivateMineRequest{}
+	mi := &file_transport_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivateMineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivateMineRequest) ProtoMessage() {}
+
+func (x *ActivateMineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActivateMineRequest.ProtoReflect.Descriptor instead.
+func (*ActivateMineRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ActivateMineRequest) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+func (x *ActivateMineRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+type MineCombatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MineId        string                 `protobuf:"bytes,1,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	Order         *CombatOrder           `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MineCombatRequest) Reset() {
+	*x = MineCombatRequest{}
+	mi := &file_transport_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MineCombatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MineCombatRequest) ProtoMessage() {}
+
+func (x *MineCombatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MineCombatRequest.ProtoReflect.Descriptor instead.
+func (*MineCombatRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *MineCombatRequest) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+func (x *MineCombatRequest) GetOrder() *CombatOrder {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type MineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mine          *Mine                  `protobuf:"bytes,1,opt,name=mine,proto3" json:"mine,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MineResponse) Reset() {
+	*x = MineResponse{}
+	mi := &file_transport_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MineResponse) ProtoMessage() {}
+
+func (x *MineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MineResponse.ProtoReflect.Descriptor instead.
+func (*MineResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *MineResponse) GetMine() *Mine {
+	if x != nil {
+		return x.Mine
+	}
+	return nil
+}
+
+type MinesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mines         []*Mine                `protobuf:"bytes,1,rep,name=mines,proto3" json:"mines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MinesResponse) Reset() {
+	*x = MinesResponse{}
+	mi := &file_transport_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MinesResponse) ProtoMessage() {}
+
+func (x *MinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MinesResponse.ProtoReflect.Descriptor instead.
+func (*MinesResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *MinesResponse) GetMines() []*Mine {
+	if x != nil {
+		return x.Mines
+	}
+	return nil
+}
+
+type MineConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        *MineConfig            `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MineConfigResponse) Reset() {
+	*x = MineConfigResponse{}
+	mi := &file_transport_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MineConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MineConfigResponse) ProtoMessage() {}
+
+func (x *MineConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MineConfigResponse.ProtoReflect.Descriptor instead.
+func (*MineConfigResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *MineConfigResponse) GetConfig() *MineConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type StartTransportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,2,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	MineId        string                 `protobuf:"bytes,3,opt,name=mine_id,json=mineId,proto3" json:"mine_id,omitempty"`
+	GoldOreAmount int64                  `protobuf:"varint,4,opt,name=gold_ore_amount,json=goldOreAmount,proto3" json:"gold_ore_amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTransportRequest) Reset() {
+	*x = StartTransportRequest{}
+	mi := &file_transport_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTransportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTransportRequest) ProtoMessage() {}
+
+func (x *StartTransportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTransportRequest.ProtoReflect.Descriptor instead.
+func (*StartTransportRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *StartTransportRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *StartTransportRequest) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *StartTransportRequest) GetMineId() string {
+	if x != nil {
+		return x.MineId
+	}
+	return ""
+}
+
+func (x *StartTransportRequest) GetGoldOreAmount() int64 {
+	if x != nil {
+		return x.GoldOreAmount
+	}
+	return 0
+}
+
+type JoinTransportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransportId   string                 `protobuf:"bytes,1,opt,name=transport_id,json=transportId,proto3" json:"transport_id,omitempty"`
+	PlayerId      string                 `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	PlayerName    string                 `protobuf:"bytes,3,opt,name=player_name,json=playerName,proto3" json:"player_name,omitempty"`
+	GoldOreAmount int64                  `protobuf:"varint,4,opt,name=gold_ore_amount,json=goldOreAmount,proto3" json:"gold_ore_amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinTransportRequest) Reset() {
+	*x = JoinTransportRequest{}
+	mi := &file_transport_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinTransportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinTransportRequest) ProtoMessage() {}
+
+func (x *JoinTransportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinTransportRequest.ProtoReflect.Descriptor instead.
+func (*JoinTransportRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *JoinTransportRequest) GetTransportId() string {
+	if x != nil {
+		return x.TransportId
+	}
+	return ""
+}
+
+func (x *JoinTransportRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *JoinTransportRequest) GetPlayerName() string {
+	if x != nil {
+		return x.PlayerName
+	}
+	return ""
+}
+
+func (x *JoinTransportRequest) GetGoldOreAmount() int64 {
+	if x != nil {
+		return x.GoldOreAmount
+	}
+	return 0
+}
+
+type GetTransportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransportId   string                 `protobuf:"bytes,1,opt,name=transport_id,json=transportId,proto3" json:"transport_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransportRequest) Reset() {
+	*x = GetTransportRequest{}
+	mi := &file_transport_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransportRequest) ProtoMessage() {}
+
+func (x *GetTransportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransportRequest.ProtoReflect.Descriptor instead.
+func (*GetTransportRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetTransportRequest) GetTransportId() string {
+	if x != nil {
+		return x.TransportId
+	}
+	return ""
+}
+
+type GetActiveTransportsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AllianceId    string                 `protobuf:"bytes,1,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActiveTransportsRequest) Reset() {
+	*x = GetActiveTransportsRequest{}
+	mi := &file_transport_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActiveTransportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActiveTransportsRequest) ProtoMessage() {}
+
+func (x *GetActiveTransportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActiveTransportsRequest.ProtoReflect.Descriptor instead.
+func (*GetActiveTransportsRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetActiveTransportsRequest) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+type GetPlayerTransportsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPlayerTransportsRequest) Reset() {
+	*x = GetPlayerTransportsRequest{}
+	mi := &file_transport_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPlayerTransportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPlayerTransportsRequest) ProtoMessage() {}
+
+func (x *GetPlayerTransportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPlayerTransportsRequest.ProtoReflect.Descriptor instead.
+func (*GetPlayerTransportsRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetPlayerTransportsRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+type TransportCombatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransportId   string                 `protobuf:"bytes,1,opt,name=transport_id,json=transportId,proto3" json:"transport_id,omitempty"`
+	Order         *CombatOrder           `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransportCombatRequest) Reset() {
+	*x = TransportCombatRequest{}
+	mi := &file_transport_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransportCombatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportCombatRequest) ProtoMessage() {}
+
+func (x *TransportCombatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportCombatRequest.ProtoReflect.Descriptor instead.
+func (*TransportCombatRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *TransportCombatRequest) GetTransportId() string {
+	if x != nil {
+		return x.TransportId
+	}
+	return ""
+}
+
+func (x *TransportCombatRequest) GetOrder() *CombatOrder {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type GetBattleReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReportId      string                 `protobuf:"bytes,1,opt,name=report_id,json=reportId,proto3" json:"report_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBattleReportRequest) Reset() {
+	*x = GetBattleReportRequest{}
+	mi := &file_transport_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBattleReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBattleReportRequest) ProtoMessage() {}
+
+func (x *GetBattleReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBattleReportRequest.ProtoReflect.Descriptor instead.
+func (*GetBattleReportRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetBattleReportRequest) GetReportId() string {
+	if x != nil {
+		return x.ReportId
+	}
+	return ""
+}
+
+type GetBattleReportsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransportId   string                 `protobuf:"bytes,1,opt,name=transport_id,json=transportId,proto3" json:"transport_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBattleReportsRequest) Reset() {
+	*x = GetBattleReportsRequest{}
+	mi := &file_transport_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBattleReportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBattleReportsRequest) ProtoMessage() {}
+
+func (x *GetBattleReportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBattleReportsRequest.ProtoReflect.Descriptor instead.
+func (*GetBattleReportsRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetBattleReportsRequest) GetTransportId() string {
+	if x != nil {
+		return x.TransportId
+	}
+	return ""
+}
+
+type TransportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transport     *Transport             `protobuf:"bytes,1,opt,name=transport,proto3" json:"transport,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransportResponse) Reset() {
+	*x = TransportResponse{}
+	mi := &file_transport_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportResponse) ProtoMessage() {}
+
+func (x *TransportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportResponse.ProtoReflect.Descriptor instead.
+func (*TransportResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *TransportResponse) GetTransport() *Transport {
+	if x != nil {
+		return x.Transport
+	}
+	return nil
+}
+
+type TransportsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transports    []*Transport           `protobuf:"bytes,1,rep,name=transports,proto3" json:"transports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransportsResponse) Reset() {
+	*x = TransportsResponse{}
+	mi := &file_transport_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransportsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportsResponse) ProtoMessage() {}
+
+func (x *TransportsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportsResponse.ProtoReflect.Descriptor instead.
+func (*TransportsResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *TransportsResponse) GetTransports() []*Transport {
+	if x != nil {
+		return x.Transports
+	}
+	return nil
+}
+
+type BattleReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Report        *BattleReport          `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleReportResponse) Reset() {
+	*x = BattleReportResponse{}
+	mi := &file_transport_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleReportResponse) ProtoMessage() {}
+
+func (x *BattleReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleReportResponse.ProtoReflect.Descriptor instead.
+func (*BattleReportResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *BattleReportResponse) GetReport() *BattleReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+type BattleReportsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reports       []*BattleReport        `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleReportsResponse) Reset() {
+	*x = BattleReportsResponse{}
+	mi := &file_transport_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleReportsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleReportsResponse) ProtoMessage() {}
+
+func (x *BattleReportsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleReportsResponse.ProtoReflect.Descriptor instead.
+func (*BattleReportsResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *BattleReportsResponse) GetReports() []*BattleReport {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+type GetOrCreateTicketsRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId   string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	AllianceId string                 `protobuf:"bytes,2,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	// max_tickets used when the tickets are created
+	MaxTickets    int32 `protobuf:"varint,3,opt,name=max_tickets,json=maxTickets,proto3" json:"max_tickets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrCreateTicketsRequest) Reset() {
+	*x = GetOrCreateTicketsRequest{}
+	mi := &file_transport_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrCreateTicketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrCreateTicketsRequest) ProtoMessage() {}
+
+func (x *GetOrCreateTicketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrCreateTicketsRequest.ProtoReflect.Descriptor instead.
+func (*GetOrCreateTicketsRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetOrCreateTicketsRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *GetOrCreateTicketsRequest) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *GetOrCreateTicketsRequest) GetMaxTickets() int32 {
+	if x != nil {
+		return x.MaxTickets
+	}
+	return 0
+}
+
+type PurchaseTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurchaseTicketRequest) Reset() {
+	*x = PurchaseTicketRequest{}
+	mi := &file_transport_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseTicketRequest) ProtoMessage() {}
+
+func (x *PurchaseTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseTicketRequest.ProtoReflect.Descriptor instead.
+func (*PurchaseTicketRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *PurchaseTicketRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+type GetTicketsByAllianceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AllianceId    string                 `protobuf:"bytes,1,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTicketsByAllianceRequest) Reset() {
+	*x = GetTicketsByAllianceRequest{}
+	mi := &file_transport_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTicketsByAllianceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTicketsByAllianceRequest) ProtoMessage() {}
+
+func (x *GetTicketsByAllianceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTicketsByAllianceRequest.ProtoReflect.Descriptor instead.
+func (*GetTicketsByAllianceRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetTicketsByAllianceRequest) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+type TicketResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        *TransportTicket       `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TicketResponse) Reset() {
+	*x = TicketResponse{}
+	mi := &file_transport_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TicketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TicketResponse) ProtoMessage() {}
+
+func (x *TicketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TicketResponse.ProtoReflect.Descriptor instead.
+func (*TicketResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *TicketResponse) GetTicket() *TransportTicket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+type PurchaseTicketResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Ticket *TransportTicket       `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	// price what the ticket cost
+	Price         int64 `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurchaseTicketResponse) Reset() {
+	*x = PurchaseTicketResponse{}
+	mi := &file_transport_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseTicketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseTicketResponse) ProtoMessage() {}
+
+func (x *PurchaseTicketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseTicketResponse.ProtoReflect.Descriptor instead.
+func (*PurchaseTicketResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *PurchaseTicketResponse) GetTicket() *TransportTicket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+func (x *PurchaseTicketResponse) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+type TicketsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tickets       []*TransportTicket     `protobuf:"bytes,1,rep,name=tickets,proto3" json:"tickets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TicketsResponse) Reset() {
+	*x = TicketsResponse{}
+	mi := &file_transport_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TicketsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TicketsResponse) ProtoMessage() {}
+
+func (x *TicketsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TicketsResponse.ProtoReflect.Descriptor instead.
+func (*TicketsResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *TicketsResponse) GetTickets() []*TransportTicket {
+	if x != nil {
+		return x.Tickets
+	}
+	return nil
+}
+
+type SendTradeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AllianceId    string                 `protobuf:"bytes,1,opt,name=alliance_id,json=allianceId,proto3" json:"alliance_id,omitempty"`
+	SenderId      string                 `protobuf:"bytes,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	SenderName    string                 `protobuf:"bytes,3,opt,name=sender_name,json=senderName,proto3" json:"sender_name,omitempty"`
+	ReceiverId    string                 `protobuf:"bytes,4,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	ReceiverName  string                 `protobuf:"bytes,5,opt,name=receiver_name,json=receiverName,proto3" json:"receiver_name,omitempty"`
+	ItemType      string                 `protobuf:"bytes,6,opt,name=item_type,json=itemType,proto3" json:"item_type,omitempty"`
+	Amount        int64                  `protobuf:"varint,7,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendTradeRequest) Reset() {
+	*x = SendTradeRequest{}
+	mi := &file_transport_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTradeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTradeRequest) ProtoMessage() {}
+
+func (x *SendTradeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTradeRequest.ProtoReflect.Descriptor instead.
+func (*SendTradeRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *SendTradeRequest) GetAllianceId() string {
+	if x != nil {
+		return x.AllianceId
+	}
+	return ""
+}
+
+func (x *SendTradeRequest) GetSenderId() string {
+	if x != nil {
+		return x.SenderId
+	}
+	return ""
+}
+
+func (x *SendTradeRequest) GetSenderName() string {
+	if x != nil {
+		return x.SenderName
+	}
+	return ""
+}
+
+func (x *SendTradeRequest) GetReceiverId() string {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return ""
+}
+
+func (x *SendTradeRequest) GetReceiverName() string {
+	if x != nil {
+		return x.ReceiverName
+	}
+	return ""
+}
+
+func (x *SendTradeRequest) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *SendTradeRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type ResolveTradeRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	TradeId string                 `protobuf:"bytes,1,opt,name=trade_id,json=tradeId,proto3" json:"trade_id,omitempty"`
+	// player_id the receiver for accept and decline, the sender for cancel
+	PlayerId      string `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveTradeRequest) Reset() {
+	*x = ResolveTradeRequest{}
+	mi := &file_transport_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveTradeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveTradeRequest) ProtoMessage() {}
+
+func (x *ResolveTradeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveTradeRequest.ProtoReflect.Descriptor instead.
+func (*ResolveTradeRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ResolveTradeRequest) GetTradeId() string {
+	if x != nil {
+		return x.TradeId
+	}
+	return ""
+}
+
+func (x *ResolveTradeRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+type GetTradeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradeId       string                 `protobuf:"bytes,1,opt,name=trade_id,json=tradeId,proto3" json:"trade_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTradeRequest) Reset() {
+	*x = GetTradeRequest{}
+	mi := &file_transport_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTradeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTradeRequest) ProtoMessage() {}
+
+func (x *GetTradeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTradeRequest.ProtoReflect.Descriptor instead.
+func (*GetTradeRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *GetTradeRequest) GetTradeId() string {
+	if x != nil {
+		return x.TradeId
+	}
+	return ""
+}
+
+type GetPendingTradesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPendingTradesRequest) Reset() {
+	*x = GetPendingTradesRequest{}
+	mi := &file_transport_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPendingTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPendingTradesRequest) ProtoMessage() {}
+
+func (x *GetPendingTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPendingTradesRequest.ProtoReflect.Descriptor instead.
+func (*GetPendingTradesRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetPendingTradesRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+type GetTradeLedgerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTradeLedgerRequest) Reset() {
+	*x = GetTradeLedgerRequest{}
+	mi := &file_transport_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTradeLedgerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTradeLedgerRequest) ProtoMessage() {}
+
+func (x *GetTradeLedgerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTradeLedgerRequest.ProtoReflect.Descriptor instead.
+func (*GetTradeLedgerRequest) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetTradeLedgerRequest) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+type TradeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trade         *Trade                 `protobuf:"bytes,1,opt,name=trade,proto3" json:"trade,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TradeResponse) Reset() {
+	*x = TradeResponse{}
+	mi := &file_transport_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TradeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TradeResponse) ProtoMessage() {}
+
+func (x *TradeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TradeResponse.ProtoReflect.Descriptor instead.
+func (*TradeResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *TradeResponse) GetTrade() *Trade {
+	if x != nil {
+		return x.Trade
+	}
+	return nil
+}
+
+type TradesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trades        []*Trade               `protobuf:"bytes,1,rep,name=trades,proto3" json:"trades,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TradesResponse) Reset() {
+	*x = TradesResponse{}
+	mi := &file_transport_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TradesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TradesResponse) ProtoMessage() {}
+
+func (x *TradesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TradesResponse.ProtoReflect.Descriptor instead.
+func (*TradesResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *TradesResponse) GetTrades() []*Trade {
+	if x != nil {
+		return x.Trades
+	}
+	return nil
+}
+
+type TradeLedgerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*TradeLedgerEntry    `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TradeLedgerResponse) Reset() {
+	*x = TradeLedgerResponse{}
+	mi := &file_transport_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TradeLedgerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TradeLedgerResponse) ProtoMessage() {}
+
+func (x *TradeLedgerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TradeLedgerResponse.ProtoReflect.Descriptor instead.
+func (*TradeLedgerResponse) Descriptor() ([]byte, []int) {
+	return file_transport_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *TradeLedgerResponse) GetEntries() []*TradeLedgerEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+var File_transport_proto protoreflect.FileDescriptor
+
+const file_transport_proto_rawDesc = "" +
+	"\n" +
+	"\x0ftransport.proto\x12\ftransport.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xca\x04\n" +
+	"\x04Mine\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\valliance_id\x18\x02 \x01(\tR\n" +
+	"allianceId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x14\n" +
+	"\x05level\x18\x04 \x01(\x05R\x05level\x12\x19\n" +
+	"\bgold_ore\x18\x05 \x01(\x03R\agoldOre\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12-\n" +
+	"\x12development_points\x18\a \x01(\x01R\x11developmentPoints\x12'\n" +
+	"\x0frequired_points\x18\b \x01(\x01R\x0erequiredPoints\x12J\n" +
+	"\x11assigned_generals\x18\t \x03(\v2\x1d.transport.v1.AssignedGeneralR\x10assignedGenerals\x12B\n" +
+	"\x0flast_updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\rlastUpdatedAt\x123\n" +
+	"\acontest\x18\v \x01(\v2\x19.transport.v1.MineContestR\acontest\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12!\n" +
+	"\fvector_clock\x18\x0e \x01(\x03R\vvectorClock\"\xbf\x02\n" +
+	"\x0fAssignedGeneral\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x1d\n" +
+	"\n" +
+	"general_id\x18\x03 \x01(\tR\tgeneralId\x12!\n" +
+	"\fgeneral_name\x18\x04 \x01(\tR\vgeneralName\x12\x14\n" +
+	"\x05level\x18\x05 \x01(\x05R\x05level\x12\x14\n" +
+	"\x05stars\x18\x06 \x01(\x05R\x05stars\x12\x16\n" +
+	"\x06rarity\x18\a \x01(\tR\x06rarity\x12;\n" +
+	"\vassigned_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"assignedAt\x12+\n" +
+	"\x11contribution_rate\x18\t \x01(\x01R\x10contributionRate\"\xad\x04\n" +
+	"\vMineContest\x120\n" +
+	"\x14attacker_alliance_id\x18\x01 \x01(\tR\x12attackerAllianceId\x125\n" +
+	"\battacker\x18\x02 \x01(\v2\x19.transport.v1.CombatForceR\battacker\x125\n" +
+	"\bdefender\x18\x03 \x01(\v2\x19.transport.v1.CombatForceR\bdefender\x12\x12\n" +
+	"\x04seed\x18\x04 \x01(\x03R\x04seed\x12'\n" +
+	"\x0fprevious_status\x18\x05 \x01(\tR\x0epreviousStatus\x12F\n" +
+	"\x11attack_start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0fattackStartTime\x12D\n" +
+	"\x10defense_end_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x0edefenseEndTime\x12\x18\n" +
+	"\aoutcome\x18\b \x01(\tR\aoutcome\x121\n" +
+	"\x06rounds\x18\t \x03(\v2\x19.transport.v1.BattleRoundR\x06rounds\x12)\n" +
+	"\x10development_lost\x18\n" +
+	" \x01(\x01R\x0fdevelopmentLost\x12;\n" +
+	"\vresolved_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"resolvedAt\"\xd2\x02\n" +
+	"\n" +
+	"MineConfig\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x05R\x05level\x120\n" +
+	"\x14min_transport_amount\x18\x03 \x01(\x03R\x12minTransportAmount\x120\n" +
+	"\x14max_transport_amount\x18\x04 \x01(\x03R\x12maxTransportAmount\x124\n" +
+	"\x16transport_time_minutes\x18\x05 \x01(\x03R\x14transportTimeMinutes\x12)\n" +
+	"\x10max_participants\x18\x06 \x01(\x05R\x0fmaxParticipants\x12'\n" +
+	"\x0frequired_points\x18\a \x01(\x01R\x0erequiredPoints\x120\n" +
+	"\x14transport_ticket_max\x18\b \x01(\x05R\x12transportTicketMax\"\xa5\x01\n" +
+	"\vCombatOrder\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x1f\n" +
+	"\valliance_id\x18\x03 \x01(\tR\n" +
+	"allianceId\x12\x1f\n" +
+	"\vgeneral_ids\x18\x04 \x03(\tR\n" +
+	"generalIds\x12\x16\n" +
+	"\x06troops\x18\x05 \x01(\x03R\x06troops\"\xbd\x01\n" +
+	"\vCombatForce\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x1f\n" +
+	"\valliance_id\x18\x03 \x01(\tR\n" +
+	"allianceId\x127\n" +
+	"\bgenerals\x18\x04 \x03(\v2\x1b.transport.v1.CombatGeneralR\bgenerals\x12\x16\n" +
+	"\x06troops\x18\x05 \x01(\x03R\x06troops\"\x86\x01\n" +
+	"\rCombatGeneral\x12\x1d\n" +
+	"\n" +
+	"general_id\x18\x01 \x01(\tR\tgeneralId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05level\x18\x03 \x01(\x05R\x05level\x12\x14\n" +
+	"\x05stars\x18\x04 \x01(\x05R\x05stars\x12\x16\n" +
+	"\x06rarity\x18\x05 \x01(\tR\x06rarity\"\xc7\x01\n" +
+	"\vBattleRound\x12\x14\n" +
+	"\x05round\x18\x01 \x01(\x05R\x05round\x12'\n" +
+	"\x0fattacker_damage\x18\x02 \x01(\x03R\x0eattackerDamage\x12'\n" +
+	"\x0fdefender_damage\x18\x03 \x01(\x03R\x0edefenderDamage\x12'\n" +
+	"\x0fattacker_troops\x18\x04 \x01(\x03R\x0eattackerTroops\x12'\n" +
+	"\x0fdefender_troops\x18\x05 \x01(\x03R\x0edefenderTroops\"\xbf\a\n" +
+	"\tTransport\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\valliance_id\x18\x02 \x01(\tR\n" +
+	"allianceId\x12\x17\n" +
+	"\amine_id\x18\x03 \x01(\tR\x06mineId\x12\x1b\n" +
+	"\tmine_name\x18\x04 \x01(\tR\bmineName\x12\x1d\n" +
+	"\n" +
+	"mine_level\x18\x05 \x01(\x05R\tmineLevel\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12&\n" +
+	"\x0fgold_ore_amount\x18\a \x01(\x03R\rgoldOreAmount\x12)\n" +
+	"\x10max_participants\x18\b \x01(\x05R\x0fmaxParticipants\x12A\n" +
+	"\fparticipants\x18\t \x03(\v2\x1d.transport.v1.TransportMemberR\fparticipants\x12B\n" +
+	"\x0fprep_start_time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\rprepStartTime\x12>\n" +
+	"\rprep_end_time\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vprepEndTime\x12@\n" +
+	"\x0etransport_time\x18\f \x01(\v2\x19.google.protobuf.DurationR\rtransportTime\x129\n" +
+	"\n" +
+	"start_time\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x129\n" +
+	"\vraid_status\x18\x0f \x01(\v2\x18.transport.v1.RaidStatusR\n" +
+	"raidStatus\x129\n" +
+	"\n" +
+	"arrived_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tarrivedAt\x127\n" +
+	"\arewards\x18\x11 \x03(\v2\x1d.transport.v1.TransportRewardR\arewards\x129\n" +
+	"\n" +
+	"created_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12!\n" +
+	"\fvector_clock\x18\x14 \x01(\x03R\vvectorClock\"\xb0\x01\n" +
+	"\x0fTransportMember\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12&\n" +
+	"\x0fgold_ore_amount\x18\x03 \x01(\x03R\rgoldOreAmount\x127\n" +
+	"\tjoined_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bjoinedAt\"\x84\x03\n" +
+	"\n" +
+	"RaidStatus\x12\x1b\n" +
+	"\traider_id\x18\x01 \x01(\tR\braiderId\x12\x1f\n" +
+	"\vraider_name\x18\x02 \x01(\tR\n" +
+	"raiderName\x12B\n" +
+	"\x0fraid_start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\rraidStartTime\x12D\n" +
+	"\x10defense_end_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x0edefenseEndTime\x12\x1f\n" +
+	"\vis_defended\x18\x05 \x01(\bR\n" +
+	"isDefended\x12B\n" +
+	"\x0edefense_result\x18\x06 \x01(\v2\x1b.transport.v1.DefenseResultR\rdefenseResult\x125\n" +
+	"\battacker\x18\a \x01(\v2\x19.transport.v1.CombatForceR\battacker\x12\x12\n" +
+	"\x04seed\x18\b \x01(\x03R\x04seed\"\xaa\x02\n" +
+	"\rDefenseResult\x12\x1e\n" +
+	"\n" +
+	"successful\x18\x01 \x01(\bR\n" +
+	"successful\x12\x1f\n" +
+	"\vdefender_id\x18\x02 \x01(\tR\n" +
+	"defenderId\x12#\n" +
+	"\rdefender_name\x18\x03 \x01(\tR\fdefenderName\x12=\n" +
+	"\fcompleted_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\"\n" +
+	"\rgold_ore_lost\x18\x05 \x01(\x03R\vgoldOreLost\x12&\n" +
+	"\x0fgold_ore_stolen\x18\x06 \x01(\x03R\rgoldOreStolen\x12(\n" +
+	"\x10battle_report_id\x18\a \x01(\tR\x0ebattleReportId\"j\n" +
+	"\x0fTransportReward\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x19\n" +
+	"\bgold_ore\x18\x03 \x01(\x03R\agoldOre\"\xfc\x03\n" +
+	"\fBattleReport\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\ftransport_id\x18\x02 \x01(\tR\vtransportId\x12\x1f\n" +
+	"\valliance_id\x18\x03 \x01(\tR\n" +
+	"allianceId\x125\n" +
+	"\battacker\x18\x04 \x01(\v2\x19.transport.v1.CombatForceR\battacker\x125\n" +
+	"\bdefender\x18\x05 \x01(\v2\x19.transport.v1.CombatForceR\bdefender\x12\x1a\n" +
+	"\bdefended\x18\x06 \x01(\bR\bdefended\x12\x12\n" +
+	"\x04seed\x18\a \x01(\x03R\x04seed\x121\n" +
+	"\x06rounds\x18\b \x03(\v2\x19.transport.v1.BattleRoundR\x06rounds\x12\x18\n" +
+	"\aoutcome\x18\t \x01(\tR\aoutcome\x12&\n" +
+	"\x0fgold_ore_before\x18\n" +
+	" \x01(\x03R\rgoldOreBefore\x12\"\n" +
+	"\rgold_ore_lost\x18\v \x01(\x03R\vgoldOreLost\x12&\n" +
+	"\x0fgold_ore_stolen\x18\f \x01(\x03R\rgoldOreStolen\x129\n" +
+	"\n" +
+	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x97\x05\n" +
+	"\x0fTransportTicket\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\valliance_id\x18\x03 \x01(\tR\n" +
+	"allianceId\x12'\n" +
+	"\x0fcurrent_tickets\x18\x04 \x01(\x05R\x0ecurrentTickets\x12!\n" +
+	"\fheld_tickets\x18\x05 \x01(\x05R\vheldTickets\x12\x1f\n" +
+	"\vmax_tickets\x18\x06 \x01(\x05R\n" +
+	"maxTickets\x12D\n" +
+	"\x10last_refill_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x0elastRefillTime\x12B\n" +
+	"\x0flast_regen_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\rlastRegenTime\x12%\n" +
+	"\x0epurchase_count\x18\t \x01(\x05R\rpurchaseCount\x12D\n" +
+	"\x10last_purchase_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0elastPurchaseAt\x129\n" +
+	"\n" +
+	"reset_time\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tresetTime\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12!\n" +
+	"\fvector_clock\x18\x0e \x01(\x03R\vvectorClock\"\x9a\x04\n" +
+	"\x05Trade\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\valliance_id\x18\x02 \x01(\tR\n" +
+	"allianceId\x12\x1b\n" +
+	"\tsender_id\x18\x03 \x01(\tR\bsenderId\x12\x1f\n" +
+	"\vsender_name\x18\x04 \x01(\tR\n" +
+	"senderName\x12\x1f\n" +
+	"\vreceiver_id\x18\x05 \x01(\tR\n" +
+	"receiverId\x12#\n" +
+	"\rreceiver_name\x18\x06 \x01(\tR\freceiverName\x12\x1b\n" +
+	"\titem_type\x18\a \x01(\tR\bitemType\x12\x16\n" +
+	"\x06amount\x18\b \x01(\x03R\x06amount\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"expires_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12;\n" +
+	"\vresolved_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"resolvedAt\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12!\n" +
+	"\fvector_clock\x18\x0e \x01(\x03R\vvectorClock\"\x9b\x02\n" +
+	"\x10TradeLedgerEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\btrade_id\x18\x02 \x01(\tR\atradeId\x12\x1b\n" +
+	"\tplayer_id\x18\x03 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\valliance_id\x18\x04 \x01(\tR\n" +
+	"allianceId\x12\x1b\n" +
+	"\titem_type\x18\x05 \x01(\tR\bitemType\x12\x14\n" +
+	"\x05delta\x18\x06 \x01(\x03R\x05delta\x12\x18\n" +
+	"\abalance\x18\a \x01(\x03R\abalance\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"^\n" +
+	"\x11CreateMineRequest\x12\x1f\n" +
+	"\valliance_id\x18\x01 \x01(\tR\n" +
+	"allianceId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05level\x18\x03 \x01(\x05R\x05level\")\n" +
+	"\x0eGetMineRequest\x12\x17\n" +
+	"\amine_id\x18\x01 \x01(\tR\x06mineId\"<\n" +
+	"\x19GetMinesByAllianceRequest\x12\x1f\n" +
+	"\valliance_id\x18\x01 \x01(\tR\n" +
+	"allianceId\",\n" +
+	"\x14GetMineConfigRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\x05R\x05level\"\x92\x01\n" +
+	"\x1aAssignGeneralToMineRequest\x12\x17\n" +
+	"\amine_id\x18\x01 \x01(\tR\x06mineId\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x03 \x01(\tR\n" +
+	"playerName\x12\x1d\n" +
+	"\n" +
+	"general_id\x18\x04 \x01(\tR\tgeneralId\"u\n" +
+	"\x1eUnassignGeneralFromMineRequest\x12\x17\n" +
+	"\amine_id\x18\x01 \x01(\tR\x06mineId\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\tR\bplayerId\x12\x1d\n" +
+	"\n" +
+	"general_id\x18\x03 \x01(\tR\tgeneralId\"7\n" +
+	"\x1cUpdateMineDevelopmentRequest\x12\x17\n" +
+	"\amine_id\x18\x01 \x01(\tR\x06mineId\"K\n" +
+	"\x13ActivateMineRequest\x12\x17\n" +
+	"\amine_id\x18\x01 \x01(\tR\x06mineId\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\tR\bplayerId\"]\n" +
+	"\x11MineCombatRequest\x12\x17\n" +
+	"\amine_id\x18\x01 \x01(\tR\x06mineId\x12/\n" +
+	"\x05order\x18\x02 \x01(\v2\x19.transport.v1.CombatOrderR\x05order\"6\n" +
+	"\fMineResponse\x12&\n" +
+	"\x04mine\x18\x01 \x01(\v2\x12.transport.v1.MineR\x04mine\"9\n" +
+	"\rMinesResponse\x12(\n" +
+	"\x05mines\x18\x01 \x03(\v2\x12.transport.v1.MineR\x05mines\"F\n" +
+	"\x12MineConfigResponse\x120\n" +
+	"\x06config\x18\x01 \x01(\v2\x18.transport.v1.MineConfigR\x06config\"\x96\x01\n" +
+	"\x15StartTransportRequest\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x02 \x01(\tR\n" +
+	"playerName\x12\x17\n" +
+	"\amine_id\x18\x03 \x01(\tR\x06mineId\x12&\n" +
+	"\x0fgold_ore_amount\x18\x04 \x01(\x03R\rgoldOreAmount\"\x9f\x01\n" +
+	"\x14JoinTransportRequest\x12!\n" +
+	"\ftransport_id\x18\x01 \x01(\tR\vtransportId\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\vplayer_name\x18\x03 \x01(\tR\n" +
+	"playerName\x12&\n" +
+	"\x0fgold_ore_amount\x18\x04 \x01(\x03R\rgoldOreAmount\"8\n" +
+	"\x13GetTransportRequest\x12!\n" +
+	"\ftransport_id\x18\x01 \x01(\tR\vtransportId\"=\n" +
+	"\x1aGetActiveTransportsRequest\x12\x1f\n" +
+	"\valliance_id\x18\x01 \x01(\tR\n" +
+	"allianceId\"9\n" +
+	"\x1aGetPlayerTransportsRequest\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\"l\n" +
+	"\x16TransportCombatRequest\x12!\n" +
+	"\ftransport_id\x18\x01 \x01(\tR\vtransportId\x12/\n" +
+	"\x05order\x18\x02 \x01(\v2\x19.transport.v1.CombatOrderR\x05order\"5\n" +
+	"\x16GetBattleReportRequest\x12\x1b\n" +
+	"\treport_id\x18\x01 \x01(\tR\breportId\"<\n" +
+	"\x17GetBattleReportsRequest\x12!\n" +
+	"\ftransport_id\x18\x01 \x01(\tR\vtransportId\"J\n" +
+	"\x11TransportResponse\x125\n" +
+	"\ttransport\x18\x01 \x01(\v2\x17.transport.v1.TransportR\ttransport\"M\n" +
+	"\x12TransportsResponse\x127\n" +
+	"\n" +
+	"transports\x18\x01 \x03(\v2\x17.transport.v1.TransportR\n" +
+	"transports\"J\n" +
+	"\x14BattleReportResponse\x122\n" +
+	"\x06report\x18\x01 \x01(\v2\x1a.transport.v1.BattleReportR\x06report\"M\n" +
+	"\x15BattleReportsResponse\x124\n" +
+	"\areports\x18\x01 \x03(\v2\x1a.transport.v1.BattleReportR\areports\"z\n" +
+	"\x19GetOrCreateTicketsRequest\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
+	"\valliance_id\x18\x02 \x01(\tR\n" +
+	"allianceId\x12\x1f\n" +
+	"\vmax_tickets\x18\x03 \x01(\x05R\n" +
+	"maxTickets\"4\n" +
+	"\x15PurchaseTicketRequest\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\">\n" +
+	"\x1bGetTicketsByAllianceRequest\x12\x1f\n" +
+	"\valliance_id\x18\x01 \x01(\tR\n" +
+	"allianceId\"G\n" +
+	"\x0eTicketResponse\x125\n" +
+	"\x06ticket\x18\x01 \x01(\v2\x1d.transport.v1.TransportTicketR\x06ticket\"e\n" +
+	"\x16PurchaseTicketResponse\x125\n" +
+	"\x06ticket\x18\x01 \x01(\v2\x1d.transport.v1.TransportTicketR\x06ticket\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x03R\x05price\"J\n" +
+	"\x0fTicketsResponse\x127\n" +
+	"\atickets\x18\x01 \x03(\v2\x1d.transport.v1.TransportTicketR\atickets\"\xec\x01\n" +
+	"\x10SendTradeRequest\x12\x1f\n" +
+	"\valliance_id\x18\x01 \x01(\tR\n" +
+	"allianceId\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\tR\bsenderId\x12\x1f\n" +
+	"\vsender_name\x18\x03 \x01(\tR\n" +
+	"senderName\x12\x1f\n" +
+	"\vreceiver_id\x18\x04 \x01(\tR\n" +
+	"receiverId\x12#\n" +
+	"\rreceiver_name\x18\x05 \x01(\tR\freceiverName\x12\x1b\n" +
+	"\titem_type\x18\x06 \x01(\tR\bitemType\x12\x16\n" +
+	"\x06amount\x18\a \x01(\x03R\x06amount\"M\n" +
+	"\x13ResolveTradeRequest\x12\x19\n" +
+	"\btrade_id\x18\x01 \x01(\tR\atradeId\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\tR\bplayerId\",\n" +
+	"\x0fGetTradeRequest\x12\x19\n" +
+	"\btrade_id\x18\x01 \x01(\tR\atradeId\"6\n" +
+	"\x17GetPendingTradesRequest\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\"4\n" +
+	"\x15GetTradeLedgerRequest\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\":\n" +
+	"\rTradeResponse\x12)\n" +
+	"\x05trade\x18\x01 \x01(\v2\x13.transport.v1.TradeR\x05trade\"=\n" +
+	"\x0eTradesResponse\x12+\n" +
+	"\x06trades\x18\x01 \x03(\v2\x13.transport.v1.TradeR\x06trades\"O\n" +
+	"\x13TradeLedgerResponse\x128\n" +
+	"\aentries\x18\x01 \x03(\v2\x1e.transport.v1.TradeLedgerEntryR\aentries2\xd8\x06\n" +
+	"\vMineService\x12I\n" +
+	"\n" +
+	"CreateMine\x12\x1f.transport.v1.CreateMineRequest\x1a\x1a.transport.v1.MineResponse\x12C\n" +
+	"\aGetMine\x12\x1c.transport.v1.GetMineRequest\x1a\x1a.transport.v1.MineResponse\x12Z\n" +
+	"\x12GetMinesByAlliance\x12'.transport.v1.GetMinesByAllianceRequest\x1a\x1b.transport.v1.MinesResponse\x12U\n" +
+	"\rGetMineConfig\x12\".transport.v1.GetMineConfigRequest\x1a .transport.v1.MineConfigResponse\x12[\n" +
+	"\x13AssignGeneralToMine\x12(.transport.v1.AssignGeneralToMineRequest\x1a\x1a.transport.v1.MineResponse\x12c\n" +
+	"\x17UnassignGeneralFromMine\x12,.transport.v1.UnassignGeneralFromMineRequest\x1a\x1a.transport.v1.MineResponse\x12_\n" +
+	"\x15UpdateMineDevelopment\x12*.transport.v1.UpdateMineDevelopmentRequest\x1a\x1a.transport.v1.MineResponse\x12M\n" +
+	"\fActivateMine\x12!.transport.v1.ActivateMineRequest\x1a\x1a.transport.v1.MineResponse\x12I\n" +
+	"\n" +
+	"AttackMine\x12\x1f.transport.v1.MineCombatRequest\x1a\x1a.transport.v1.MineResponse\x12I\n" +
+	"\n" +
+	"DefendMine\x12\x1f.transport.v1.MineCombatRequest\x1a\x1a.transport.v1.MineResponse2\xc9\x06\n" +
+	"\x10TransportService\x12V\n" +
+	"\x0eStartTransport\x12#.transport.v1.StartTransportRequest\x1a\x1f.transport.v1.TransportResponse\x12T\n" +
+	"\rJoinTransport\x12\".transport.v1.JoinTransportRequest\x1a\x1f.transport.v1.TransportResponse\x12R\n" +
+	"\fGetTransport\x12!.transport.v1.GetTransportRequest\x1a\x1f.transport.v1.TransportResponse\x12a\n" +
+	"\x13GetActiveTransports\x12(.transport.v1.GetActiveTransportsRequest\x1a .transport.v1.TransportsResponse\x12a\n" +
+	"\x13GetPlayerTransports\x12(.transport.v1.GetPlayerTransportsRequest\x1a .transport.v1.TransportsResponse\x12V\n" +
+	"\rRaidTransport\x12$.transport.v1.TransportCombatRequest\x1a\x1f.transport.v1.TransportResponse\x12X\n" +
+	"\x0fDefendTransport\x12$.transport.v1.TransportCombatRequest\x1a\x1f.transport.v1.TransportResponse\x12[\n" +
+	"\x0fGetBattleReport\x12$.transport.v1.GetBattleReportRequest\x1a\".transport.v1.BattleReportResponse\x12^\n" +
+	"\x10GetBattleReports\x12%.transport.v1.GetBattleReportsRequest\x1a#.transport.v1.BattleReportsResponse2\xab\x02\n" +
+	"\rTicketService\x12[\n" +
+	"\x12GetOrCreateTickets\x12'.transport.v1.GetOrCreateTicketsRequest\x1a\x1c.transport.v1.TicketResponse\x12[\n" +
+	"\x0ePurchaseTicket\x12#.transport.v1.PurchaseTicketRequest\x1a$.transport.v1.PurchaseTicketResponse\x12`\n" +
+	"\x14GetTicketsByAlliance\x12).transport.v1.GetTicketsByAllianceRequest\x1a\x1d.transport.v1.TicketsResponse2\xc1\x04\n" +
+	"\fTradeService\x12H\n" +
+	"\tSendTrade\x12\x1e.transport.v1.SendTradeRequest\x1a\x1b.transport.v1.TradeResponse\x12M\n" +
+	"\vAcceptTrade\x12!.transport.v1.ResolveTradeRequest\x1a\x1b.transport.v1.TradeResponse\x12N\n" +
+	"\fDeclineTrade\x12!.transport.v1.ResolveTradeRequest\x1a\x1b.transport.v1.TradeResponse\x12M\n" +
+	"\vCancelTrade\x12!.transport.v1.ResolveTradeRequest\x1a\x1b.transport.v1.TradeResponse\x12F\n" +
+	"\bGetTrade\x12\x1d.transport.v1.GetTradeRequest\x1a\x1b.transport.v1.TradeResponse\x12W\n" +
+	"\x10GetPendingTrades\x12%.transport.v1.GetPendingTradesRequest\x1a\x1c.transport.v1.TradesResponse\x12X\n" +
+	"\x0eGetTradeLedger\x12#.transport.v1.GetTradeLedgerRequest\x1a!.transport.v1.TradeLedgerResponseB!Z\x1ftictactoe/transport/transportpbb\x06proto3"
+
+var (
+	file_transport_proto_rawDescOnce sync.Once
+	file_transport_proto_rawDescData []byte
+)
+
+func file_transport_proto_rawDescGZIP() []byte {
+	file_transport_proto_rawDescOnce.Do(func() {
+		file_transport_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_transport_proto_rawDesc), len(file_transport_proto_rawDesc)))
+	})
+	return file_transport_proto_rawDescData
+}
+
+var file_transport_proto_msgTypes = make([]protoimpl.MessageInfo, 55)
+var file_transport_proto_goTypes = []any{
+	(*Mine)(nil),                           // 0: transport.v1.Mine
+	(*AssignedGeneral)(nil),                // 1: transport.v1.AssignedGeneral
+	(*MineContest)(nil),                    // 2: transport.v1.MineContest
+	(*MineConfig)(nil),                     // 3: transport.v1.MineConfig
+	(*CombatOrder)(nil),                    // 4: transport.v1.CombatOrder
+	(*CombatForce)(nil),                    // 5: transport.v1.CombatForce
+	(*CombatGeneral)(nil),                  // 6: transport.v1.CombatGeneral
+	(*BattleRound)(nil),                    // 7: transport.v1.BattleRound
+	(*Transport)(nil),                      // 8: transport.v1.Transport
+	(*TransportMember)(nil),                // 9: transport.v1.TransportMember
+	(*RaidStatus)(nil),                     // 10: transport.v1.RaidStatus
+	(*DefenseResult)(nil),                  // 11: transport.v1.DefenseResult
+	(*TransportReward)(nil),                // 12: transport.v1.TransportReward
+	(*BattleReport)(nil),                   // 13: transport.v1.BattleReport
+	(*TransportTicket)(nil),                // 14: transport.v1.TransportTicket
+	(*Trade)(nil),                          // 15: transport.v1.Trade
+	(*TradeLedgerEntry)(nil),               // 16: transport.v1.TradeLedgerEntry
+	(*CreateMineRequest)(nil),              // 17: transport.v1.CreateMineRequest
+	(*GetMineRequest)(nil),                 // 18: transport.v1.GetMineRequest
+	(*GetMinesByAllianceRequest)(nil),      // 19: transport.v1.GetMinesByAllianceRequest
+	(*GetMineConfigRequest)(nil),           // 20: transport.v1.GetMineConfigRequest
+	(*AssignGeneralToMineRequest)(nil),     // 21: transport.v1.AssignGeneralToMineRequest
+	(*UnassignGeneralFromMineRequest)(nil), // 22: transport.v1.UnassignGeneralFromMineRequest
+	(*UpdateMineDevelopmentRequest)(nil),   // 23: transport.v1.UpdateMineDevelopmentRequest
+	(*ActivateMineRequest)(nil),            // 24: transport.v1.ActivateMineRequest
+	(*MineCombatRequest)(nil),              // 25: transport.v1.MineCombatRequest
+	(*MineResponse)(nil),                   // 26: transport.v1.MineResponse
+	(*MinesResponse)(nil),                  // 27: transport.v1.MinesResponse
+	(*MineConfigResponse)(nil),             // 28: transport.v1.MineConfigResponse
+	(*StartTransportRequest)(nil),          // 29: transport.v1.StartTransportRequest
+	(*JoinTransportRequest)(nil),           // 30: transport.v1.JoinTransportRequest
+	(*GetTransportRequest)(nil),            // 31: transport.v1.GetTransportRequest
+	(*GetActiveTransportsRequest)(nil),     // 32: transport.v1.GetActiveTransportsRequest
+	(*GetPlayerTransportsRequest)(nil),     // 33: transport.v1.GetPlayerTransportsRequest
+	(*TransportCombatRequest)(nil),         // 34: transport.v1.TransportCombatRequest
+	(*GetBattleReportRequest)(nil),         // 35: transport.v1.GetBattleReportRequest
+	(*GetBattleReportsRequest)(nil),        // 36: transport.v1.GetBattleReportsRequest
+	(*TransportResponse)(nil),              // 37: transport.v1.TransportResponse
+	(*TransportsResponse)(nil),             // 38: transport.v1.TransportsResponse
+	(*BattleReportResponse)(nil),           // 39: transport.v1.BattleReportResponse
+	(*BattleReportsResponse)(nil),          // 40: transport.v1.BattleReportsResponse
+	(*GetOrCreateTicketsRequest)(nil),      // 41: transport.v1.GetOrCreateTicketsRequest
+	(*PurchaseTicketRequest)(nil),          // 42: transport.v1.PurchaseTicketRequest
+	(*GetTicketsByAllianceRequest)(nil),    // 43: transport.v1.GetTicketsByAllianceRequest
+	(*TicketResponse)(nil),                 // 44: transport.v1.TicketResponse
+	(*PurchaseTicketResponse)(nil),         // 45: transport.v1.PurchaseTicketResponse
+	(*TicketsResponse)(nil),                // 46: transport.v1.TicketsResponse
+	(*SendTradeRequest)(nil),               // 47: transport.v1.SendTradeRequest
+	(*ResolveTradeRequest)(nil),            // 48: transport.v1.ResolveTradeRequest
+	(*GetTradeRequest)(nil),                // 49: transport.v1.GetTradeRequest
+	(*GetPendingTradesRequest)(nil),        // 50: transport.v1.GetPendingTradesRequest
+	(*GetTradeLedgerRequest)(nil),          // 51: transport.v1.GetTradeLedgerRequest
+	(*TradeResponse)(nil),                  // 52: transport.v1.TradeResponse
+	(*TradesResponse)(nil),                 // 53: transport.v1.TradesResponse
+	(*TradeLedgerResponse)(nil),            // 54: transport.v1.TradeLedgerResponse
+	(*timestamppb.Timestamp)(nil),          // 55: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),            // 56: google.protobuf.Duration
+}
+var file_transport_proto_depIdxs = []int32{
+	1,  // 0: transport.v1.Mine.assigned_generals:type_name -> transport.v1.AssignedGeneral
+	55, // 1: transport.v1.Mine.last_updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: transport.v1.Mine.contest:type_name -> transport.v1.MineContest
+	55, // 3: transport.v1.Mine.created_at:type_name -> google.protobuf.Timestamp
+	55, // 4: transport.v1.Mine.updated_at:type_name -> google.protobuf.Timestamp
+	55, // 5: transport.v1.AssignedGeneral.assigned_at:type_name -> google.protobuf.Timestamp
+	5,  // 6: transport.v1.MineContest.attacker:type_name -> transport.v1.CombatForce
+	5,  // 7: transport.v1.MineContest.defender:type_name -> transport.v1.CombatForce
+	55, // 8: transport.v1.MineContest.attack_start_time:type_name -> google.protobuf.Timestamp
+	55, // 9: transport.v1.MineContest.defense_end_time:type_name -> google.protobuf.Timestamp
+	7,  // 10: transport.v1.MineContest.rounds:type_name -> transport.v1.BattleRound
+	55, // 11: transport.v1.MineContest.resolved_at:type_name -> google.protobuf.Timestamp
+	6,  // 12: transport.v1.CombatForce.generals:type_name -> transport.v1.CombatGeneral
+	9,  // 13: transport.v1.Transport.participants:type_name -> transport.v1.TransportMember
+	55, // 14: transport.v1.Transport.prep_start_time:type_name -> google.protobuf.Timestamp
+	55, // 15: transport.v1.Transport.prep_end_time:type_name -> google.protobuf.Timestamp
+	56, // 16: transport.v1.Transport.transport_time:type_name -> google.protobuf.Duration
+	55, // 17: transport.v1.Transport.start_time:type_name -> google.protobuf.Timestamp
+	55, // 18: transport.v1.Transport.end_time:type_name -> google.protobuf.Timestamp
+	10, // 19: transport.v1.Transport.raid_status:type_name -> transport.v1.RaidStatus
+	55, // 20: transport.v1.Transport.arrived_at:type_name -> google.protobuf.Timestamp
+	12, // 21: transport.v1.Transport.rewards:type_name -> transport.v1.TransportReward
+	55, // 22: transport.v1.Transport.created_at:type_name -> google.protobuf.Timestamp
+	55, // 23: transport.v1.Transport.updated_at:type_name -> google.protobuf.Timestamp
+	55, // 24: transport.v1.TransportMember.joined_at:type_name -> google.protobuf.Timestamp
+	55, // 25: transport.v1.RaidStatus.raid_start_time:type_name -> google.protobuf.Timestamp
+	55, // 26: transport.v1.RaidStatus.defense_end_time:type_name -> google.protobuf.Timestamp
+	11, // 27: transport.v1.RaidStatus.defense_result:type_name -> transport.v1.DefenseResult
+	5,  // 28: transport.v1.RaidStatus.attacker:type_name -> transport.v1.CombatForce
+	55, // 29: transport.v1.DefenseResult.completed_at:type_name -> google.protobuf.Timestamp
+	5,  // 30: transport.v1.BattleReport.attacker:type_name -> transport.v1.CombatForce
+	5,  // 31: transport.v1.BattleReport.defender:type_name -> transport.v1.CombatForce
+	7,  // 32: transport.v1.BattleReport.rounds:type_name -> transport.v1.BattleRound
+	55, // 33: transport.v1.BattleReport.created_at:type_name -> google.protobuf.Timestamp
+	55, // 34: transport.v1.TransportTicket.last_refill_time:type_name -> google.protobuf.Timestamp
+	55, // 35: transport.v1.TransportTicket.last_regen_time:type_name -> google.protobuf.Timestamp
+	55, // 36: transport.v1.TransportTicket.last_purchase_at:type_name -> google.protobuf.Timestamp
+	55, // 37: transport.v1.TransportTicket.reset_time:type_name -> google.protobuf.Timestamp
+	55, // 38: transport.v1.TransportTicket.created_at:type_name -> google.protobuf.Timestamp
+	55, // 39: transport.v1.TransportTicket.updated_at:type_name -> google.protobuf.Timestamp
+	55, // 40: transport.v1.Trade.expires_at:type_name -> google.protobuf.Timestamp
+	55, // 41: transport.v1.Trade.resolved_at:type_name -> google.protobuf.Timestamp
+	55, // 42: transport.v1.Trade.created_at:type_name -> google.protobuf.Timestamp
+	55, // 43: transport.v1.Trade.updated_at:type_name -> google.protobuf.Timestamp
+	55, // 44: transport.v1.TradeLedgerEntry.created_at:type_name -> google.protobuf.Timestamp
+	4,  // 45: transport.v1.MineCombatRequest.order:type_name -> transport.v1.CombatOrder
+	0,  // 46: transport.v1.MineResponse.mine:type_name -> transport.v1.Mine
+	0,  // 47: transport.v1.MinesResponse.mines:type_name -> transport.v1.Mine
+	3,  // 48: transport.v1.MineConfigResponse.config:type_name -> transport.v1.MineConfig
+	4,  // 49: transport.v1.TransportCombatRequest.order:type_name -> transport.v1.CombatOrder
+	8,  // 50: transport.v1.TransportResponse.transport:type_name -> transport.v1.Transport
+	8,  // 51: transport.v1.TransportsResponse.transports:type_name -> transport.v1.Transport
+	13, // 52: transport.v1.BattleReportResponse.report:type_name -> transport.v1.BattleReport
+	13, // 53: transport.v1.BattleReportsResponse.reports:type_name -> transport.v1.BattleReport
+	14, // 54: transport.v1.TicketResponse.ticket:type_name -> transport.v1.TransportTicket
+	14, // 55: transport.v1.PurchaseTicketResponse.ticket:type_name -> transport.v1.TransportTicket
+	14, // 56: transport.v1.TicketsResponse.tickets:type_name -> transport.v1.TransportTicket
+	15, // 57: transport.v1.TradeResponse.trade:type_name -> transport.v1.Trade
+	15, // 58: transport.v1.TradesResponse.trades:type_name -> transport.v1.Trade
+	16, // 59: transport.v1.TradeLedgerResponse.entries:type_name -> transport.v1.TradeLedgerEntry
+	17, // 60: transport.v1.MineService.CreateMine:input_type -> transport.v1.CreateMineRequest
+	18, // 61: transport.v1.MineService.GetMine:input_type -> transport.v1.GetMineRequest
+	19, // 62: transport.v1.MineService.GetMinesByAlliance:input_type -> transport.v1.GetMinesByAllianceRequest
+	20, // 63: transport.v1.MineService.GetMineConfig:input_type -> transport.v1.GetMineConfigRequest
+	21, // 64: transport.v1.MineService.AssignGeneralToMine:input_type -> transport.v1.AssignGeneralToMineRequest
+	22, // 65: transport.v1.MineService.UnassignGeneralFromMine:input_type -> transport.v1.UnassignGeneralFromMineRequest
+	23, // 66: transport.v1.MineService.UpdateMineDevelopment:input_type -> transport.v1.UpdateMineDevelopmentRequest
+	24, // 67: transport.v1.MineService.ActivateMine:input_type -> transport.v1.ActivateMineRequest
+	25, // 68: transport.v1.MineService.AttackMine:input_type -> transport.v1.MineCombatRequest
+	25, // 69: transport.v1.MineService.DefendMine:input_type -> transport.v1.MineCombatRequest
+	29, // 70: transport.v1.TransportService.StartTransport:input_type -> transport.v1.StartTransportRequest
+	30, // 71: transport.v1.TransportService.JoinTransport:input_type -> transport.v1.JoinTransportRequest
+	31, // 72: transport.v1.TransportService.GetTransport:input_type -> transport.v1.GetTransportRequest
+	32, // 73: transport.v1.TransportService.GetActiveTransports:input_type -> transport.v1.GetActiveTransportsRequest
+	33, // 74: transport.v1.TransportService.GetPlayerTransports:input_type -> transport.v1.GetPlayerTransportsRequest
+	34, // 75: transport.v1.TransportService.RaidTransport:input_type -> transport.v1.TransportCombatRequest
+	34, // 76: transport.v1.TransportService.DefendTransport:input_type -> transport.v1.TransportCombatRequest
+	35, // 77: transport.v1.TransportService.GetBattleReport:input_type -> transport.v1.GetBattleReportRequest
+	36, // 78: transport.v1.TransportService.GetBattleReports:input_type -> transport.v1.GetBattleReportsRequest
+	41, // 79: transport.v1.TicketService.GetOrCreateTickets:input_type -> transport.v1.GetOrCreateTicketsRequest
+	42, // 80: transport.v1.TicketService.PurchaseTicket:input_type -> transport.v1.PurchaseTicketRequest
+	43, // 81: transport.v1.TicketService.GetTicketsByAlliance:input_type -> transport.v1.GetTicketsByAllianceRequest
+	47, // 82: transport.v1.TradeService.SendTrade:input_type -> transport.v1.SendTradeRequest
+	48, // 83: transport.v1.TradeService.AcceptTrade:input_type -> transport.v1.ResolveTradeRequest
+	48, // 84: transport.v1.TradeService.DeclineTrade:input_type -> transport.v1.ResolveTradeRequest
+	48, // 85: transport.v1.TradeService.CancelTrade:input_type -> transport.v1.ResolveTradeRequest
+	49, // 86: transport.v1.TradeService.GetTrade:input_type -> transport.v1.GetTradeRequest
+	50, // 87: transport.v1.TradeService.GetPendingTrades:input_type -> transport.v1.GetPendingTradesRequest
+	51, // 88: transport.v1.TradeService.GetTradeLedger:input_type -> transport.v1.GetTradeLedgerRequest
+	26, // 89: transport.v1.MineService.CreateMine:output_type -> transport.v1.MineResponse
+	26, // 90: transport.v1.MineService.GetMine:output_type -> transport.v1.MineResponse
+	27, // 91: transport.v1.MineService.GetMinesByAlliance:output_type -> transport.v1.MinesResponse
+	28, // 92: transport.v1.MineService.GetMineConfig:output_type -> transport.v1.MineConfigResponse
+	26, // 93: transport.v1.MineService.AssignGeneralToMine:output_type -> transport.v1.MineResponse
+	26, // 94: transport.v1.MineService.UnassignGeneralFromMine:output_type -> transport.v1.MineResponse
+	26, // 95: transport.v1.MineService.UpdateMineDevelopment:output_type -> transport.v1.MineResponse
+	26, // 96: transport.v1.MineService.ActivateMine:output_type -> transport.v1.MineResponse
+	26, // 97: transport.v1.MineService.AttackMine:output_type -> transport.v1.MineResponse
+	26, // 98: transport.v1.MineService.DefendMine:output_type -> transport.v1.MineResponse
+	37, // 99: transport.v1.TransportService.StartTransport:output_type -> transport.v1.TransportResponse
+	37, // 100: transport.v1.TransportService.JoinTransport:output_type -> transport.v1.TransportResponse
+	37, // 101: transport.v1.TransportService.GetTransport:output_type -> transport.v1.TransportResponse
+	38, // 102: transport.v1.TransportService.GetActiveTransports:output_type -> transport.v1.TransportsResponse
+	38, // 103: transport.v1.TransportService.GetPlayerTransports:output_type -> transport.v1.TransportsResponse
+	37, // 104: transport.v1.TransportService.RaidTransport:output_type -> transport.v1.TransportResponse
+	37, // 105: transport.v1.TransportService.DefendTransport:output_type -> transport.v1.TransportResponse
+	39, // 106: transport.v1.TransportService.GetBattleReport:output_type -> transport.v1.BattleReportResponse
+	40, // 107: transport.v1.TransportService.GetBattleReports:output_type -> transport.v1.BattleReportsResponse
+	44, // 108: transport.v1.TicketService.GetOrCreateTickets:output_type -> transport.v1.TicketResponse
+	45, // 109: transport.v1.TicketService.PurchaseTicket:output_type -> transport.v1.PurchaseTicketResponse
+	46, // 110: transport.v1.TicketService.GetTicketsByAlliance:output_type -> transport.v1.TicketsResponse
+	52, // 111: transport.v1.TradeService.SendTrade:output_type -> transport.v1.TradeResponse
+	52, // 112: transport.v1.TradeService.AcceptTrade:output_type -> transport.v1.TradeResponse
+	52, // 113: transport.v1.TradeService.DeclineTrade:output_type -> transport.v1.TradeResponse
+	52, // 114: transport.v1.TradeService.CancelTrade:output_type -> transport.v1.TradeResponse
+	52, // 115: transport.v1.TradeService.GetTrade:output_type -> transport.v1.TradeResponse
+	53, // 116: transport.v1.TradeService.GetPendingTrades:output_type -> transport.v1.TradesResponse
+	54, // 117: transport.v1.TradeService.GetTradeLedger:output_type -> transport.v1.TradeLedgerResponse
+	89, // [89:118] is the sub-list for method output_type
+	60, // [60:89] is the sub-list for method input_type
+	60, // [60:60] is the sub-list for extension type_name
+	60, // [60:60] is the sub-list for extension extendee
+	0,  // [0:60] is the sub-list for field type_name
+}
+
+func init() { file_transport_proto_init() }
+func file_transport_proto_init() {
+	if File_transport_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transport_proto_rawDesc), len(file_transport_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   55,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_transport_proto_goTypes,
+		DependencyIndexes: file_transport_proto_depIdxs,
+		MessageInfos:      file_transport_proto_msgTypes,
+	}.Build()
+	File_transport_proto = out.File
+	file_transport_proto_goTypes = nil
+	file_transport_proto_depIdxs = nil
+}
@@ -0,0 +1,1460 @@
+// transport gRPC API
+//
+// Lets the servers that don't access MongoDB themselves (the match server, the social server)
+// develop mines, send out transports, manage tickets and trade between alliance members through
+// the transport server.
+// IDs are hex ObjectIDs; statuses, rarities and outcomes carry the values of the domain constants.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: transport.proto
+
+package transportpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MineService_CreateMine_FullMethodName              = "/transport.v1.MineService/CreateMine"
+	MineService_GetMine_FullMethodName                 = "/transport.v1.MineService/GetMine"
+	MineService_GetMinesByAlliance_FullMethodName      = "/transport.v1.MineService/GetMinesByAlliance"
+	MineService_GetMineConfig_FullMethodName           = "/transport.v1.MineService/GetMineConfig"
+	MineService_AssignGeneralToMine_FullMethodName     = "/transport.v1.MineService/AssignGeneralToMine"
+	MineService_UnassignGeneralFromMine_FullMethodName = "/transport.v1.MineService/UnassignGeneralFromMine"
+	MineService_UpdateMineDevelopment_FullMethodName   = "/transport.v1.MineService/UpdateMineDevelopment"
+	MineService_ActivateMine_FullMethodName            = "/transport.v1.MineService/ActivateMine"
+	MineService_AttackMine_FullMethodName              = "/transport.v1.MineService/AttackMine"
+	MineService_DefendMine_FullMethodName              = "/transport.v1.MineService/DefendMine"
+)
+
+// MineServiceClient is the client API for MineService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MineService mines, their development by generals and battles over them
+type MineServiceClient interface {
+	// CreateMine creates an undeveloped mine for an alliance
+	CreateMine(ctx context.Context, in *CreateMineRequest, opts ...grpc.CallOption) (*MineResponse, error)
+	// GetMine gets a mine (NOT_FOUND if it doesn't exist)
+	GetMine(ctx context.Context, in *GetMineRequest, opts ...grpc.CallOption) (*MineResponse, error)
+	// GetMinesByAlliance gets all mines of an alliance
+	GetMinesByAlliance(ctx context.Context, in *GetMinesByAllianceRequest, opts ...grpc.CallOption) (*MinesResponse, error)
+	// GetMineConfig gets the settings of a mine level
+	GetMineConfig(ctx context.Context, in *GetMineConfigRequest, opts ...grpc.CallOption) (*MineConfigResponse, error)
+	// AssignGeneralToMine assigns a player's general to develop a mine.
+	// Honors the idempotency-key metadata.
+	AssignGeneralToMine(ctx context.Context, in *AssignGeneralToMineRequest, opts ...grpc.CallOption) (*MineResponse, error)
+	// UnassignGeneralFromMine takes a player's general off a mine
+	UnassignGeneralFromMine(ctx context.Context, in *UnassignGeneralFromMineRequest, opts ...grpc.CallOption) (*MineResponse, error)
+	// UpdateMineDevelopment brings the development points of a developing mine up to date
+	UpdateMineDevelopment(ctx context.Context, in *UpdateMineDevelopmentRequest, opts ...grpc.CallOption) (*MineResponse, error)
+	// ActivateMine starts mining in a developed mine
+	ActivateMine(ctx context.Context, in *ActivateMineRequest, opts ...grpc.CallOption) (*MineResponse, error)
+	// AttackMine attacks a mine of another alliance
+	AttackMine(ctx context.Context, in *MineCombatRequest, opts ...grpc.CallOption) (*MineResponse, error)
+	// DefendMine defends a mine of the player's alliance under attack
+	DefendMine(ctx context.Context, in *MineCombatRequest, opts ...grpc.CallOption) (*MineResponse, error)
+}
+
+type mineServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMineServiceClient(cc grpc.ClientConnInterface) MineServiceClient {
+	return &mineServiceClient{cc}
+}
+
+func (c *mineServiceClient) CreateMine(ctx context.Context, in *CreateMineRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_CreateMine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) GetMine(ctx context.Context, in *GetMineRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_GetMine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) GetMinesByAlliance(ctx context.Context, in *GetMinesByAllianceRequest, opts ...grpc.CallOption) (*MinesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MinesResponse)
+	err := c.cc.Invoke(ctx, MineService_GetMinesByAlliance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) GetMineConfig(ctx context.Context, in *GetMineConfigRequest, opts ...grpc.CallOption) (*MineConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineConfigResponse)
+	err := c.cc.Invoke(ctx, MineService_GetMineConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) AssignGeneralToMine(ctx context.Context, in *AssignGeneralToMineRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_AssignGeneralToMine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) UnassignGeneralFromMine(ctx context.Context, in *UnassignGeneralFromMineRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_UnassignGeneralFromMine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) UpdateMineDevelopment(ctx context.Context, in *UpdateMineDevelopmentRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_UpdateMineDevelopment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) ActivateMine(ctx context.Context, in *ActivateMineRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_ActivateMine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) AttackMine(ctx context.Context, in *MineCombatRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_AttackMine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mineServiceClient) DefendMine(ctx context.Context, in *MineCombatRequest, opts ...grpc.CallOption) (*MineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MineResponse)
+	err := c.cc.Invoke(ctx, MineService_DefendMine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MineServiceServer is the server API for MineService service.
+// All implementations must embed UnimplementedMineServiceServer
+// for forward compatibility.
+//
+// MineService mines, their development by generals and battles over them
+type MineServiceServer interface {
+	// CreateMine creates an undeveloped mine for an alliance
+	CreateMine(context.Context, *CreateMineRequest) (*MineResponse, error)
+	// GetMine gets a mine (NOT_FOUND if it doesn't exist)
+	GetMine(context.Context, *GetMineRequest) (*MineResponse, error)
+	// GetMinesByAlliance gets all mines of an alliance
+	GetMinesByAlliance(context.Context, *GetMinesByAllianceRequest) (*MinesResponse, error)
+	// GetMineConfig gets the settings of a mine level
+	GetMineConfig(context.Context, *GetMineConfigRequest) (*MineConfigResponse, error)
+	// AssignGeneralToMine assigns a player's general to develop a mine.
+	// Honors the idempotency-key metadata.
+	AssignGeneralToMine(context.Context, *AssignGeneralToMineRequest) (*MineResponse, error)
+	// UnassignGeneralFromMine takes a player's general off a mine
+	UnassignGeneralFromMine(context.Context, *UnassignGeneralFromMineRequest) (*MineResponse, error)
+	// UpdateMineDevelopment brings the development points of a developing mine up to date
+	UpdateMineDevelopment(context.Context, *UpdateMineDevelopmentRequest) (*MineResponse, error)
+	// ActivateMine starts mining in a developed mine
+	ActivateMine(context.Context, *ActivateMineRequest) (*MineResponse, error)
+	// AttackMine attacks a mine of another alliance
+	AttackMine(context.Context, *MineCombatRequest) (*MineResponse, error)
+	// DefendMine defends a mine of the player's alliance under attack
+	DefendMine(context.Context, *MineCombatRequest) (*MineResponse, error)
+	mustEmbedUnimplementedMineServiceServer()
+}
+
+// UnimplementedMineServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMineServiceServer struct{}
+
+func (UnimplementedMineServiceServer) CreateMine(context.Context, *CreateMineRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMine not implemented")
+}
+func (UnimplementedMineServiceServer) GetMine(context.Context, *GetMineRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMine not implemented")
+}
+func (UnimplementedMineServiceServer) GetMinesByAlliance(context.Context, *GetMinesByAllianceRequest) (*MinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMinesByAlliance not implemented")
+}
+func (UnimplementedMineServiceServer) GetMineConfig(context.Context, *GetMineConfigRequest) (*MineConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMineConfig not implemented")
+}
+func (UnimplementedMineServiceServer) AssignGeneralToMine(context.Context, *AssignGeneralToMineRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignGeneralToMine not implemented")
+}
+func (UnimplementedMineServiceServer) UnassignGeneralFromMine(context.Context, *UnassignGeneralFromMineRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnassignGeneralFromMine not implemented")
+}
+func (UnimplementedMineServiceServer) UpdateMineDevelopment(context.Context, *UpdateMineDevelopmentRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMineDevelopment not implemented")
+}
+func (UnimplementedMineServiceServer) ActivateMine(context.Context, *ActivateMineRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateMine not implemented")
+}
+func (UnimplementedMineServiceServer) AttackMine(context.Context, *MineCombatRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AttackMine not implemented")
+}
+func (UnimplementedMineServiceServer) DefendMine(context.Context, *MineCombatRequest) (*MineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DefendMine not implemented")
+}
+func (UnimplementedMineServiceServer) mustEmbedUnimplementedMineServiceServer() {}
+func (UnimplementedMineServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeMineServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MineServiceServer will
+// result in compilation errors.
+type UnsafeMineServiceServer interface {
+	mustEmbedUnimplementedMineServiceServer()
+}
+
+func RegisterMineServiceServer(s grpc.ServiceRegistrar, srv MineServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMineServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MineService_ServiceDesc, srv)
+}
+
+func _MineService_CreateMine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).CreateMine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_CreateMine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).CreateMine(ctx, req.(*CreateMineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_GetMine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).GetMine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_GetMine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).GetMine(ctx, req.(*GetMineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_GetMinesByAlliance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMinesByAllianceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).GetMinesByAlliance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_GetMinesByAlliance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).GetMinesByAlliance(ctx, req.(*GetMinesByAllianceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_GetMineConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMineConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).GetMineConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_GetMineConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).GetMineConfig(ctx, req.(*GetMineConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_AssignGeneralToMine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignGeneralToMineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).AssignGeneralToMine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_AssignGeneralToMine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).AssignGeneralToMine(ctx, req.(*AssignGeneralToMineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_UnassignGeneralFromMine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnassignGeneralFromMineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).UnassignGeneralFromMine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_UnassignGeneralFromMine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).UnassignGeneralFromMine(ctx, req.(*UnassignGeneralFromMineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_UpdateMineDevelopment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMineDevelopmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).UpdateMineDevelopment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_UpdateMineDevelopment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).UpdateMineDevelopment(ctx, req.(*UpdateMineDevelopmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_ActivateMine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivateMineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).ActivateMine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_ActivateMine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).ActivateMine(ctx, req.(*ActivateMineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_AttackMine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MineCombatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).AttackMine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_AttackMine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).AttackMine(ctx, req.(*MineCombatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MineService_DefendMine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MineCombatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MineServiceServer).DefendMine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MineService_DefendMine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MineServiceServer).DefendMine(ctx, req.(*MineCombatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MineService_ServiceDesc is the grpc.ServiceDesc for MineService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MineService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transport.v1.MineService",
+	HandlerType: (*MineServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateMine",
+			Handler:    _MineService_CreateMine_Handler,
+		},
+		{
+			MethodName: "GetMine",
+			Handler:    _MineService_GetMine_Handler,
+		},
+		{
+			MethodName: "GetMinesByAlliance",
+			Handler:    _MineService_GetMinesByAlliance_Handler,
+		},
+		{
+			MethodName: "GetMineConfig",
+			Handler:    _MineService_GetMineConfig_Handler,
+		},
+		{
+			MethodName: "AssignGeneralToMine",
+			Handler:    _MineService_AssignGeneralToMine_Handler,
+		},
+		{
+			MethodName: "UnassignGeneralFromMine",
+			Handler:    _MineService_UnassignGeneralFromMine_Handler,
+		},
+		{
+			MethodName: "UpdateMineDevelopment",
+			Handler:    _MineService_UpdateMineDevelopment_Handler,
+		},
+		{
+			MethodName: "ActivateMine",
+			Handler:    _MineService_ActivateMine_Handler,
+		},
+		{
+			MethodName: "AttackMine",
+			Handler:    _MineService_AttackMine_Handler,
+		},
+		{
+			MethodName: "DefendMine",
+			Handler:    _MineService_DefendMine_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "transport.proto",
+}
+
+const (
+	TransportService_StartTransport_FullMethodName      = "/transport.v1.TransportService/StartTransport"
+	TransportService_JoinTransport_FullMethodName       = "/transport.v1.TransportService/JoinTransport"
+	TransportService_GetTransport_FullMethodName        = "/transport.v1.TransportService/GetTransport"
+	TransportService_GetActiveTransports_FullMethodName = "/transport.v1.TransportService/GetActiveTransports"
+	TransportService_GetPlayerTransports_FullMethodName = "/transport.v1.TransportService/GetPlayerTransports"
+	TransportService_RaidTransport_FullMethodName       = "/transport.v1.TransportService/RaidTransport"
+	TransportService_DefendTransport_FullMethodName     = "/transport.v1.TransportService/DefendTransport"
+	TransportService_GetBattleReport_FullMethodName     = "/transport.v1.TransportService/GetBattleReport"
+	TransportService_GetBattleReports_FullMethodName    = "/transport.v1.TransportService/GetBattleReports"
+)
+
+// TransportServiceClient is the client API for TransportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TransportService transports of gold ore from mines and raids on them
+type TransportServiceClient interface {
+	// StartTransport starts a transport from a mine. Honors the idempotency-key metadata.
+	StartTransport(ctx context.Context, in *StartTransportRequest, opts ...grpc.CallOption) (*TransportResponse, error)
+	// JoinTransport joins a transport in preparation. Honors the idempotency-key metadata.
+	JoinTransport(ctx context.Context, in *JoinTransportRequest, opts ...grpc.CallOption) (*TransportResponse, error)
+	// GetTransport gets a transport (NOT_FOUND if it doesn't exist)
+	GetTransport(ctx context.Context, in *GetTransportRequest, opts ...grpc.CallOption) (*TransportResponse, error)
+	// GetActiveTransports gets the transports of an alliance that have not arrived yet
+	GetActiveTransports(ctx context.Context, in *GetActiveTransportsRequest, opts ...grpc.CallOption) (*TransportsResponse, error)
+	// GetPlayerTransports gets the transports a player takes part in
+	GetPlayerTransports(ctx context.Context, in *GetPlayerTransportsRequest, opts ...grpc.CallOption) (*TransportsResponse, error)
+	// RaidTransport raids a transport of another alliance
+	RaidTransport(ctx context.Context, in *TransportCombatRequest, opts ...grpc.CallOption) (*TransportResponse, error)
+	// DefendTransport defends a transport of the player's alliance being raided
+	DefendTransport(ctx context.Context, in *TransportCombatRequest, opts ...grpc.CallOption) (*TransportResponse, error)
+	// GetBattleReport gets the report of a battle over a transport
+	GetBattleReport(ctx context.Context, in *GetBattleReportRequest, opts ...grpc.CallOption) (*BattleReportResponse, error)
+	// GetBattleReports gets the reports of all battles over a transport
+	GetBattleReports(ctx context.Context, in *GetBattleReportsRequest, opts ...grpc.CallOption) (*BattleReportsResponse, error)
+}
+
+type transportServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTransportServiceClient(cc grpc.ClientConnInterface) TransportServiceClient {
+	return &transportServiceClient{cc}
+}
+
+func (c *transportServiceClient) StartTransport(ctx context.Context, in *StartTransportRequest, opts ...grpc.CallOption) (*TransportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransportResponse)
+	err := c.cc.Invoke(ctx, TransportService_StartTransport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) JoinTransport(ctx context.Context, in *JoinTransportRequest, opts ...grpc.CallOption) (*TransportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransportResponse)
+	err := c.cc.Invoke(ctx, TransportService_JoinTransport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) GetTransport(ctx context.Context, in *GetTransportRequest, opts ...grpc.CallOption) (*TransportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransportResponse)
+	err := c.cc.Invoke(ctx, TransportService_GetTransport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) GetActiveTransports(ctx context.Context, in *GetActiveTransportsRequest, opts ...grpc.CallOption) (*TransportsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransportsResponse)
+	err := c.cc.Invoke(ctx, TransportService_GetActiveTransports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) GetPlayerTransports(ctx context.Context, in *GetPlayerTransportsRequest, opts ...grpc.CallOption) (*TransportsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransportsResponse)
+	err := c.cc.Invoke(ctx, TransportService_GetPlayerTransports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) RaidTransport(ctx context.Context, in *TransportCombatRequest, opts ...grpc.CallOption) (*TransportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransportResponse)
+	err := c.cc.Invoke(ctx, TransportService_RaidTransport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) DefendTransport(ctx context.Context, in *TransportCombatRequest, opts ...grpc.CallOption) (*TransportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransportResponse)
+	err := c.cc.Invoke(ctx, TransportService_DefendTransport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) GetBattleReport(ctx context.Context, in *GetBattleReportRequest, opts ...grpc.CallOption) (*BattleReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BattleReportResponse)
+	err := c.cc.Invoke(ctx, TransportService_GetBattleReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transportServiceClient) GetBattleReports(ctx context.Context, in *GetBattleReportsRequest, opts ...grpc.CallOption) (*BattleReportsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BattleReportsResponse)
+	err := c.cc.Invoke(ctx, TransportService_GetBattleReports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransportServiceServer is the server API for TransportService service.
+// All implementations must embed UnimplementedTransportServiceServer
+// for forward compatibility.
+//
+// TransportService transports of gold ore from mines and raids on them
+type TransportServiceServer interface {
+	// StartTransport starts a transport from a mine. Honors the idempotency-key metadata.
+	StartTransport(context.Context, *StartTransportRequest) (*TransportResponse, error)
+	// JoinTransport joins a transport in preparation. Honors the idempotency-key metadata.
+	JoinTransport(context.Context, *JoinTransportRequest) (*TransportResponse, error)
+	// GetTransport gets a transport (NOT_FOUND if it doesn't exist)
+	GetTransport(context.Context, *GetTransportRequest) (*TransportResponse, error)
+	// GetActiveTransports gets the transports of an alliance that have not arrived yet
+	GetActiveTransports(context.Context, *GetActiveTransportsRequest) (*TransportsResponse, error)
+	// GetPlayerTransports gets the transports a player takes part in
+	GetPlayerTransports(context.Context, *GetPlayerTransportsRequest) (*TransportsResponse, error)
+	// RaidTransport raids a transport of another alliance
+	RaidTransport(context.Context, *TransportCombatRequest) (*TransportResponse, error)
+	// DefendTransport defends a transport of the player's alliance being raided
+	DefendTransport(context.Context, *TransportCombatRequest) (*TransportResponse, error)
+	// GetBattleReport gets the report of a battle over a transport
+	GetBattleReport(context.Context, *GetBattleReportRequest) (*BattleReportResponse, error)
+	// GetBattleReports gets the reports of all battles over a transport
+	GetBattleReports(context.Context, *GetBattleReportsRequest) (*BattleReportsResponse, error)
+	mustEmbedUnimplementedTransportServiceServer()
+}
+
+// UnimplementedTransportServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTransportServiceServer struct{}
+
+func (UnimplementedTransportServiceServer) StartTransport(context.Context, *StartTransportRequest) (*TransportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTransport not implemented")
+}
+func (UnimplementedTransportServiceServer) JoinTransport(context.Context, *JoinTransportRequest) (*TransportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinTransport not implemented")
+}
+func (UnimplementedTransportServiceServer) GetTransport(context.Context, *GetTransportRequest) (*TransportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransport not implemented")
+}
+func (UnimplementedTransportServiceServer) GetActiveTransports(context.Context, *GetActiveTransportsRequest) (*TransportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActiveTransports not implemented")
+}
+func (UnimplementedTransportServiceServer) GetPlayerTransports(context.Context, *GetPlayerTransportsRequest) (*TransportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlayerTransports not implemented")
+}
+func (UnimplementedTransportServiceServer) RaidTransport(context.Context, *TransportCombatRequest) (*TransportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RaidTransport not implemented")
+}
+func (UnimplementedTransportServiceServer) DefendTransport(context.Context, *TransportCombatRequest) (*TransportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DefendTransport not implemented")
+}
+func (UnimplementedTransportServiceServer) GetBattleReport(context.Context, *GetBattleReportRequest) (*BattleReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBattleReport not implemented")
+}
+func (UnimplementedTransportServiceServer) GetBattleReports(context.Context, *GetBattleReportsRequest) (*BattleReportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBattleReports not implemented")
+}
+func (UnimplementedTransportServiceServer) mustEmbedUnimplementedTransportServiceServer() {}
+func (UnimplementedTransportServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeTransportServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransportServiceServer will
+// result in compilation errors.
+type UnsafeTransportServiceServer interface {
+	mustEmbedUnimplementedTransportServiceServer()
+}
+
+func RegisterTransportServiceServer(s grpc.ServiceRegistrar, srv TransportServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTransportServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TransportService_ServiceDesc, srv)
+}
+
+func _TransportService_StartTransport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTransportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).StartTransport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_StartTransport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).StartTransport(ctx, req.(*StartTransportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_JoinTransport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinTransportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).JoinTransport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_JoinTransport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).JoinTransport(ctx, req.(*JoinTransportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_GetTransport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).GetTransport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_GetTransport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).GetTransport(ctx, req.(*GetTransportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_GetActiveTransports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActiveTransportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).GetActiveTransports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_GetActiveTransports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).GetActiveTransports(ctx, req.(*GetActiveTransportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_GetPlayerTransports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlayerTransportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).GetPlayerTransports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_GetPlayerTransports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).GetPlayerTransports(ctx, req.(*GetPlayerTransportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_RaidTransport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransportCombatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).RaidTransport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_RaidTransport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).RaidTransport(ctx, req.(*TransportCombatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_DefendTransport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransportCombatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).DefendTransport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_DefendTransport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).DefendTransport(ctx, req.(*TransportCombatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_GetBattleReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBattleReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).GetBattleReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_GetBattleReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).GetBattleReport(ctx, req.(*GetBattleReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransportService_GetBattleReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBattleReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransportServiceServer).GetBattleReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransportService_GetBattleReports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransportServiceServer).GetBattleReports(ctx, req.(*GetBattleReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TransportService_ServiceDesc is the grpc.ServiceDesc for TransportService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TransportService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transport.v1.TransportService",
+	HandlerType: (*TransportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartTransport",
+			Handler:    _TransportService_StartTransport_Handler,
+		},
+		{
+			MethodName: "JoinTransport",
+			Handler:    _TransportService_JoinTransport_Handler,
+		},
+		{
+			MethodName: "GetTransport",
+			Handler:    _TransportService_GetTransport_Handler,
+		},
+		{
+			MethodName: "GetActiveTransports",
+			Handler:    _TransportService_GetActiveTransports_Handler,
+		},
+		{
+			MethodName: "GetPlayerTransports",
+			Handler:    _TransportService_GetPlayerTransports_Handler,
+		},
+		{
+			MethodName: "RaidTransport",
+			Handler:    _TransportService_RaidTransport_Handler,
+		},
+		{
+			MethodName: "DefendTransport",
+			Handler:    _TransportService_DefendTransport_Handler,
+		},
+		{
+			MethodName: "GetBattleReport",
+			Handler:    _TransportService_GetBattleReport_Handler,
+		},
+		{
+			MethodName: "GetBattleReports",
+			Handler:    _TransportService_GetBattleReports_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "transport.proto",
+}
+
+const (
+	TicketService_GetOrCreateTickets_FullMethodName   = "/transport.v1.TicketService/GetOrCreateTickets"
+	TicketService_PurchaseTicket_FullMethodName       = "/transport.v1.TicketService/PurchaseTicket"
+	TicketService_GetTicketsByAlliance_FullMethodName = "/transport.v1.TicketService/GetTicketsByAlliance"
+)
+
+// TicketServiceClient is the client API for TicketService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TicketService transport tickets
+type TicketServiceClient interface {
+	// GetOrCreateTickets gets the tickets of a player, creating them on first use
+	GetOrCreateTickets(ctx context.Context, in *GetOrCreateTicketsRequest, opts ...grpc.CallOption) (*TicketResponse, error)
+	// PurchaseTicket buys a ticket. Honors the idempotency-key metadata.
+	PurchaseTicket(ctx context.Context, in *PurchaseTicketRequest, opts ...grpc.CallOption) (*PurchaseTicketResponse, error)
+	// GetTicketsByAlliance gets the tickets of all players of an alliance
+	GetTicketsByAlliance(ctx context.Context, in *GetTicketsByAllianceRequest, opts ...grpc.CallOption) (*TicketsResponse, error)
+}
+
+type ticketServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTicketServiceClient(cc grpc.ClientConnInterface) TicketServiceClient {
+	return &ticketServiceClient{cc}
+}
+
+func (c *ticketServiceClient) GetOrCreateTickets(ctx context.Context, in *GetOrCreateTicketsRequest, opts ...grpc.CallOption) (*TicketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TicketResponse)
+	err := c.cc.Invoke(ctx, TicketService_GetOrCreateTickets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ticketServiceClient) PurchaseTicket(ctx context.Context, in *PurchaseTicketRequest, opts ...grpc.CallOption) (*PurchaseTicketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurchaseTicketResponse)
+	err := c.cc.Invoke(ctx, TicketService_PurchaseTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ticketServiceClient) GetTicketsByAlliance(ctx context.Context, in *GetTicketsByAllianceRequest, opts ...grpc.CallOption) (*TicketsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TicketsResponse)
+	err := c.cc.Invoke(ctx, TicketService_GetTicketsByAlliance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TicketServiceServer is the server API for TicketService service.
+// All implementations must embed UnimplementedTicketServiceServer
+// for forward compatibility.
+//
+// TicketService transport tickets
+type TicketServiceServer interface {
+	// GetOrCreateTickets gets the tickets of a player, creating them on first use
+	GetOrCreateTickets(context.Context, *GetOrCreateTicketsRequest) (*TicketResponse, error)
+	// PurchaseTicket buys a ticket. Honors the idempotency-key metadata.
+	PurchaseTicket(context.Context, *PurchaseTicketRequest) (*PurchaseTicketResponse, error)
+	// GetTicketsByAlliance gets the tickets of all players of an alliance
+	GetTicketsByAlliance(context.Context, *GetTicketsByAllianceRequest) (*TicketsResponse, error)
+	mustEmbedUnimplementedTicketServiceServer()
+}
+
+// UnimplementedTicketServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTicketServiceServer struct{}
+
+func (UnimplementedTicketServiceServer) GetOrCreateTickets(context.Context, *GetOrCreateTicketsRequest) (*TicketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrCreateTickets not implemented")
+}
+func (UnimplementedTicketServiceServer) PurchaseTicket(context.Context, *PurchaseTicketRequest) (*PurchaseTicketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurchaseTicket not implemented")
+}
+func (UnimplementedTicketServiceServer) GetTicketsByAlliance(context.Context, *GetTicketsByAllianceRequest) (*TicketsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTicketsByAlliance not implemented")
+}
+func (UnimplementedTicketServiceServer) mustEmbedUnimplementedTicketServiceServer() {}
+func (UnimplementedTicketServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeTicketServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TicketServiceServer will
+// result in compilation errors.
+type UnsafeTicketServiceServer interface {
+	mustEmbedUnimplementedTicketServiceServer()
+}
+
+func RegisterTicketServiceServer(s grpc.ServiceRegistrar, srv TicketServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTicketServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TicketService_ServiceDesc, srv)
+}
+
+func _TicketService_GetOrCreateTickets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrCreateTicketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TicketServiceServer).GetOrCreateTickets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TicketService_GetOrCreateTickets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TicketServiceServer).GetOrCreateTickets(ctx, req.(*GetOrCreateTicketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TicketService_PurchaseTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TicketServiceServer).PurchaseTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TicketService_PurchaseTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TicketServiceServer).PurchaseTicket(ctx, req.(*PurchaseTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TicketService_GetTicketsByAlliance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTicketsByAllianceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TicketServiceServer).GetTicketsByAlliance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TicketService_GetTicketsByAlliance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TicketServiceServer).GetTicketsByAlliance(ctx, req.(*GetTicketsByAllianceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TicketService_ServiceDesc is the grpc.ServiceDesc for TicketService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TicketService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transport.v1.TicketService",
+	HandlerType: (*TicketServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetOrCreateTickets",
+			Handler:    _TicketService_GetOrCreateTickets_Handler,
+		},
+		{
+			MethodName: "PurchaseTicket",
+			Handler:    _TicketService_PurchaseTicket_Handler,
+		},
+		{
+			MethodName: "GetTicketsByAlliance",
+			Handler:    _TicketService_GetTicketsByAlliance_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "transport.proto",
+}
+
+const (
+	TradeService_SendTrade_FullMethodName        = "/transport.v1.TradeService/SendTrade"
+	TradeService_AcceptTrade_FullMethodName      = "/transport.v1.TradeService/AcceptTrade"
+	TradeService_DeclineTrade_FullMethodName     = "/transport.v1.TradeService/DeclineTrade"
+	TradeService_CancelTrade_FullMethodName      = "/transport.v1.TradeService/CancelTrade"
+	TradeService_GetTrade_FullMethodName         = "/transport.v1.TradeService/GetTrade"
+	TradeService_GetPendingTrades_FullMethodName = "/transport.v1.TradeService/GetPendingTrades"
+	TradeService_GetTradeLedger_FullMethodName   = "/transport.v1.TradeService/GetTradeLedger"
+)
+
+// TradeServiceClient is the client API for TradeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TradeService gifts of tickets and gold ore between alliance members
+type TradeServiceClient interface {
+	// SendTrade moves the sender's items into escrow until the receiver accepts or declines
+	SendTrade(ctx context.Context, in *SendTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error)
+	// AcceptTrade credits a pending trade to its receiver
+	AcceptTrade(ctx context.Context, in *ResolveTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error)
+	// DeclineTrade declines a pending trade on behalf of its receiver and refunds the sender
+	DeclineTrade(ctx context.Context, in *ResolveTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error)
+	// CancelTrade cancels a pending trade on behalf of its sender and refunds the items
+	CancelTrade(ctx context.Context, in *ResolveTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error)
+	// GetTrade gets a trade (NOT_FOUND if it doesn't exist)
+	GetTrade(ctx context.Context, in *GetTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error)
+	// GetPendingTrades gets the pending trades addressed to a player
+	GetPendingTrades(ctx context.Context, in *GetPendingTradesRequest, opts ...grpc.CallOption) (*TradesResponse, error)
+	// GetTradeLedger gets the most recent ledger entries of a player
+	GetTradeLedger(ctx context.Context, in *GetTradeLedgerRequest, opts ...grpc.CallOption) (*TradeLedgerResponse, error)
+}
+
+type tradeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTradeServiceClient(cc grpc.ClientConnInterface) TradeServiceClient {
+	return &tradeServiceClient{cc}
+}
+
+func (c *tradeServiceClient) SendTrade(ctx context.Context, in *SendTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TradeResponse)
+	err := c.cc.Invoke(ctx, TradeService_SendTrade_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) AcceptTrade(ctx context.Context, in *ResolveTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TradeResponse)
+	err := c.cc.Invoke(ctx, TradeService_AcceptTrade_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) DeclineTrade(ctx context.Context, in *ResolveTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TradeResponse)
+	err := c.cc.Invoke(ctx, TradeService_DeclineTrade_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) CancelTrade(ctx context.Context, in *ResolveTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TradeResponse)
+	err := c.cc.Invoke(ctx, TradeService_CancelTrade_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) GetTrade(ctx context.Context, in *GetTradeRequest, opts ...grpc.CallOption) (*TradeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TradeResponse)
+	err := c.cc.Invoke(ctx, TradeService_GetTrade_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) GetPendingTrades(ctx context.Context, in *GetPendingTradesRequest, opts ...grpc.CallOption) (*TradesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TradesResponse)
+	err := c.cc.Invoke(ctx, TradeService_GetPendingTrades_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) GetTradeLedger(ctx context.Context, in *GetTradeLedgerRequest, opts ...grpc.CallOption) (*TradeLedgerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TradeLedgerResponse)
+	err := c.cc.Invoke(ctx, TradeService_GetTradeLedger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TradeServiceServer is the server API for TradeService service.
+// All implementations must embed UnimplementedTradeServiceServer
+// for forward compatibility.
+//
+// TradeService gifts of tickets and gold ore between alliance members
+type TradeServiceServer interface {
+	// SendTrade moves the sender's items into escrow until the receiver accepts or declines
+	SendTrade(context.Context, *SendTradeRequest) (*TradeResponse, error)
+	// AcceptTrade credits a pending trade to its receiver
+	AcceptTrade(context.Context, *ResolveTradeRequest) (*TradeResponse, error)
+	// DeclineTrade declines a pending trade on behalf of its receiver and refunds the sender
+	DeclineTrade(context.Context, *ResolveTradeRequest) (*TradeResponse, error)
+	// CancelTrade cancels a pending trade on behalf of its sender and refunds the items
+	CancelTrade(context.Context, *ResolveTradeRequest) (*TradeResponse, error)
+	// GetTrade gets a trade (NOT_FOUND if it doesn't exist)
+	GetTrade(context.Context, *GetTradeRequest) (*TradeResponse, error)
+	// GetPendingTrades gets the pending trades addressed to a player
+	GetPendingTrades(context.Context, *GetPendingTradesRequest) (*TradesResponse, error)
+	// GetTradeLedger gets the most recent ledger entries of a player
+	GetTradeLedger(context.Context, *GetTradeLedgerRequest) (*TradeLedgerResponse, error)
+	mustEmbedUnimplementedTradeServiceServer()
+}
+
+// UnimplementedTradeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTradeServiceServer struct{}
+
+func (UnimplementedTradeServiceServer) SendTrade(context.Context, *SendTradeRequest) (*TradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendTrade not implemented")
+}
+func (UnimplementedTradeServiceServer) AcceptTrade(context.Context, *ResolveTradeRequest) (*TradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcceptTrade not implemented")
+}
+func (UnimplementedTradeServiceServer) DeclineTrade(context.Context, *ResolveTradeRequest) (*TradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeclineTrade not implemented")
+}
+func (UnimplementedTradeServiceServer) CancelTrade(context.Context, *ResolveTradeRequest) (*TradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelTrade not implemented")
+}
+func (UnimplementedTradeServiceServer) GetTrade(context.Context, *GetTradeRequest) (*TradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrade not implemented")
+}
+func (UnimplementedTradeServiceServer) GetPendingTrades(context.Context, *GetPendingTradesRequest) (*TradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPendingTrades not implemented")
+}
+func (UnimplementedTradeServiceServer) GetTradeLedger(context.Context, *GetTradeLedgerRequest) (*TradeLedgerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTradeLedger not implemented")
+}
+func (UnimplementedTradeServiceServer) mustEmbedUnimplementedTradeServiceServer() {}
+func (UnimplementedTradeServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeTradeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TradeServiceServer will
+// result in compilation errors.
+type UnsafeTradeServiceServer interface {
+	mustEmbedUnimplementedTradeServiceServer()
+}
+
+func RegisterTradeServiceServer(s grpc.ServiceRegistrar, srv TradeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTradeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TradeService_ServiceDesc, srv)
+}
+
+func _TradeService_SendTrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).SendTrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_SendTrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).SendTrade(ctx, req.(*SendTradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_AcceptTrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveTradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).AcceptTrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_AcceptTrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).AcceptTrade(ctx, req.(*ResolveTradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_DeclineTrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveTradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).DeclineTrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_DeclineTrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).DeclineTrade(ctx, req.(*ResolveTradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_CancelTrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveTradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).CancelTrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_CancelTrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).CancelTrade(ctx, req.(*ResolveTradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_GetTrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).GetTrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_GetTrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).GetTrade(ctx, req.(*GetTradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_GetPendingTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPendingTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).GetPendingTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_GetPendingTrades_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).GetPendingTrades(ctx, req.(*GetPendingTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_GetTradeLedger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTradeLedgerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).GetTradeLedger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_GetTradeLedger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).GetTradeLedger(ctx, req.(*GetTradeLedgerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TradeService_ServiceDesc is the grpc.ServiceDesc for TradeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TradeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transport.v1.TradeService",
+	HandlerType: (*TradeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendTrade",
+			Handler:    _TradeService_SendTrade_Handler,
+		},
+		{
+			MethodName: "AcceptTrade",
+			Handler:    _TradeService_AcceptTrade_Handler,
+		},
+		{
+			MethodName: "DeclineTrade",
+			Handler:    _TradeService_DeclineTrade_Handler,
+		},
+		{
+			MethodName: "CancelTrade",
+			Handler:    _TradeService_CancelTrade_Handler,
+		},
+		{
+			MethodName: "GetTrade",
+			Handler:    _TradeService_GetTrade_Handler,
+		},
+		{
+			MethodName: "GetPendingTrades",
+			Handler:    _TradeService_GetPendingTrades_Handler,
+		},
+		{
+			MethodName: "GetTradeLedger",
+			Handler:    _TradeService_GetTradeLedger_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "transport.proto",
+}